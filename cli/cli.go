@@ -0,0 +1,555 @@
+// Package cli implements the admin subcommands of the server binary, run as `<binary> <command> [flags]`.
+// They work directly on the database so they can be used inside the server container without the HTTP API.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/database"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// command is a single admin subcommand
+type command struct {
+	Usage string
+	Short string
+	Run   func(cfg *config.Config, args []string) error
+}
+
+var commands = map[string]command{
+	"create-superadmin": {
+		Usage: "create-superadmin -username <username> -password <password> [-fullname <name>] [-email <email>]",
+		Short: "Create a user with the superadmin role",
+		Run:   runCreateSuperadmin,
+	},
+	"reset-password": {
+		Usage: "reset-password -username <username> -password <password>",
+		Short: "Set a new password for a user and log out all of their sessions",
+		Run:   runResetPassword,
+	},
+	"seed": {
+		Usage: "seed <" + strings.Join(seedNames(), "|") + ">",
+		Short: "Re-run a single initial data seed",
+		Run:   runSeed,
+	},
+	"seed-sandbox": {
+		Usage: "seed-sandbox [-orders <count>] [-days <days>]",
+		Short: "Generate fake orders, QC, attendance and complain data for sandbox environments",
+		Run:   runSeedSandbox,
+	},
+	"backfill-channels": {
+		Usage: "backfill-channels [-dry-run]",
+		Short: "Rewrite order channels to the canonical channel name they match by name or code",
+		Run:   runBackfillChannels,
+	},
+	"aggregates": {
+		Usage: "aggregates [-days <days>]",
+		Short: "Rebuild the daily dashboard aggregates of the last days",
+		Run:   runAggregates,
+	},
+	"retention-purge": {
+		Usage: "retention-purge [-dry-run]",
+		Short: "Run the data retention purge with the configured policy",
+		Run:   runRetentionPurge,
+	},
+	"data-repair": {
+		Usage: "data-repair -rule <" + strings.Join(dataRepairRuleNames(), "|") + "> [-apply] [-batch-size <rows>] [-max-rows <rows>] | data-repair -revert <run id>",
+		Short: "Report or fix historical status typos, identifier case and channel name variants in batches",
+		Run:   runDataRepair,
+	},
+	"import-legacy": {
+		Usage: "import-legacy -entity <" + strings.Join(models.LegacyEntities(), "|") + "> -file <path> [-template <name>] [-dry-run]",
+		Short: "Import orders, complains or attendances from a spreadsheet of the system used before go-live",
+		Run:   runImportLegacy,
+	},
+	"rotate-token-key": {
+		Usage: "rotate-token-key",
+		Short: "Add a new current token key to the token key file, issued tokens stay valid",
+		Run:   runRotateTokenKey,
+	},
+}
+
+var seeds = map[string]func() error{
+	"roles":       database.SeedInitialRole,
+	"boxes":       database.SeedInitialBox,
+	"channels":    database.SeedInitialChannel,
+	"expeditions": database.SeedInitialExpedition,
+	"stores":      database.SeedInitialStore,
+	"users":       database.SeedInitialUser,
+	"locations":   database.SeedInitialLocation,
+	"root-causes": database.SeedInitialComplainRootCause,
+}
+
+// IsCommand reports whether the argument names an admin subcommand
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok || name == "help" || name == "-h" || name == "--help"
+}
+
+// Run executes the admin subcommand in args[0] and returns the process exit code
+func Run(cfg *config.Config, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage()
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+		printUsage()
+		return 2
+	}
+
+	if err := database.ConnectDatabase(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+
+	if err := cmd.Run(cfg, args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		if errors.Is(err, flag.ErrHelp) || errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "Usage:", cmd.Usage)
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// errUsage is returned when a command is called with missing or invalid arguments
+var errUsage = errors.New("invalid arguments")
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Usage: <binary> [command] [flags]")
+	fmt.Println("Without a command the API server is started.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, name := range names {
+		fmt.Printf("  %-20s %s\n", name, commands[name].Short)
+		fmt.Printf("  %-20s   %s\n", "", commands[name].Usage)
+	}
+}
+
+func seedNames() []string {
+	names := make([]string, 0, len(seeds))
+	for name := range seeds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func runCreateSuperadmin(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("create-superadmin", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the new user")
+	password := flags.String("password", "", "password of the new user, at least 8 characters")
+	fullName := flags.String("fullname", "Super Administrator", "full name of the new user")
+	email := flags.String("email", "", "email of the new user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	*username = utils.NormalizeUsername(*username)
+	if *username == "" || *password == "" {
+		return fmt.Errorf("%w: username and password are required", errUsage)
+	}
+	if len(*password) < 8 {
+		return fmt.Errorf("%w: password must be at least 8 characters", errUsage)
+	}
+
+	var role models.Role
+	if err := database.DB.Where("role_name = ?", "superadmin").First(&role).Error; err != nil {
+		return fmt.Errorf("superadmin role not found, run `seed roles` first: %w", err)
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.User{}).Where("username = ?", *username).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("user %s already exists", *username)
+	}
+
+	hashedPassword, err := utils.HashPassword(*password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user := models.User{
+		Username: *username,
+		Password: hashedPassword,
+		FullName: *fullName,
+		Email:    utils.NormalizeEmail(*email),
+		IsActive: true,
+	}
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)", user.ID, role.ID).Error
+	}); err != nil {
+		return fmt.Errorf("failed to create user %s: %w", *username, err)
+	}
+
+	fmt.Printf("Superadmin %s created (id %d)\n", user.Username, user.ID)
+	return nil
+}
+
+func runResetPassword(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("reset-password", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the user")
+	password := flags.String("password", "", "new password, at least 8 characters")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	*username = utils.NormalizeUsername(*username)
+	if *username == "" || *password == "" {
+		return fmt.Errorf("%w: username and password are required", errUsage)
+	}
+	if len(*password) < 8 {
+		return fmt.Errorf("%w: password must be at least 8 characters", errUsage)
+	}
+
+	var user models.User
+	if err := database.DB.Where("username = ?", *username).First(&user).Error; err != nil {
+		return fmt.Errorf("user %s not found: %w", *username, err)
+	}
+
+	hashedPassword, err := utils.HashPassword(*password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// Log out every session, same as a self-service password reset
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.Session{}).Error
+	}); err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	fmt.Printf("Password of %s reset, all sessions logged out\n", user.Username)
+	return nil
+}
+
+func runSeed(cfg *config.Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: exactly one seed name is required", errUsage)
+	}
+
+	seed, ok := seeds[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown seed %q", errUsage, args[0])
+	}
+	if err := seed(); err != nil {
+		return fmt.Errorf("seed %s failed: %w", args[0], err)
+	}
+
+	fmt.Printf("Seed %s completed\n", args[0])
+	return nil
+}
+
+func runSeedSandbox(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("seed-sandbox", flag.ContinueOnError)
+	orders := flags.Int("orders", 200, "number of orders to generate")
+	days := flags.Int("days", 14, "number of past days the data is spread over")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if cfg.Env == "production" {
+		return errors.New("seed-sandbox refuses to run with ENV=production")
+	}
+	if *orders <= 0 || *days <= 0 {
+		return fmt.Errorf("%w: orders and days must be greater than zero", errUsage)
+	}
+
+	summary, err := database.SeedSandbox(*orders, *days)
+	if err != nil {
+		return fmt.Errorf("sandbox seed failed: %w", err)
+	}
+
+	fmt.Printf("Sandbox data created: %d users, %d products, %d orders, %d QC, %d outbounds, %d attendances, %d complains\n",
+		summary.Users, summary.Products, summary.Orders, summary.QC, summary.Outbounds, summary.Attendances, summary.Complains)
+	fmt.Printf("Sandbox users log in with password %s\n", database.SandboxPassword)
+	return nil
+}
+
+func runBackfillChannels(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("backfill-channels", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only count the orders that would be updated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var channels []models.Channel
+	if err := database.DB.Order("channel_name").Find(&channels).Error; err != nil {
+		return fmt.Errorf("failed to load channels: %w", err)
+	}
+
+	var total int64
+	for _, channel := range channels {
+		query := database.DB.Model(&models.Order{}).
+			Where("LOWER(TRIM(channel)) IN ?", []string{strings.ToLower(channel.ChannelName), strings.ToLower(channel.ChannelCode)}).
+			Where("channel <> ?", channel.ChannelName)
+
+		var affected int64
+		if *dryRun {
+			if err := query.Count(&affected).Error; err != nil {
+				return fmt.Errorf("failed to count orders of channel %s: %w", channel.ChannelName, err)
+			}
+		} else {
+			result := query.Update("channel", channel.ChannelName)
+			if result.Error != nil {
+				return fmt.Errorf("failed to backfill orders of channel %s: %w", channel.ChannelName, result.Error)
+			}
+			affected = result.RowsAffected
+		}
+
+		if affected > 0 {
+			fmt.Printf("%-30s %d orders\n", channel.ChannelName, affected)
+		}
+		total += affected
+	}
+
+	if *dryRun {
+		fmt.Printf("Dry run: %d orders would be updated\n", total)
+	} else {
+		fmt.Printf("%d orders updated\n", total)
+	}
+	return nil
+}
+
+func runRetentionPurge(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("retention-purge", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "only count the affected rows without modifying data")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	run, err := utils.RunRetentionPurge(database.DB, utils.RetentionPolicyFromConfig(cfg), *dryRun, utils.RetentionTriggerManual, nil)
+	if run != nil {
+		for _, detail := range run.Details {
+			fmt.Printf("%-20s %-10s older than %s: %d rows\n", detail.Category, detail.Action, detail.Cutoff.Format("2006-01-02"), detail.AffectedRows)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("retention purge failed: %w", err)
+	}
+
+	fmt.Printf("Purge run %d completed (dry run: %t)\n", run.ID, run.DryRun)
+	return nil
+}
+
+func dataRepairRuleNames() []string {
+	rules := utils.DataRepairRules()
+	names := make([]string, len(rules))
+	for i := range rules {
+		names[i] = rules[i].Name
+	}
+	return names
+}
+
+func runDataRepair(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("data-repair", flag.ContinueOnError)
+	ruleName := flags.String("rule", "", "rule to run: "+strings.Join(dataRepairRuleNames(), ", "))
+	apply := flags.Bool("apply", false, "change the rows instead of only reporting them")
+	batchSize := flags.Int("batch-size", utils.DefaultDataRepairBatchSize, "rows changed per transaction")
+	maxRows := flags.Int("max-rows", 0, "rows changed at most by this run, 0 for every row")
+	revertID := flags.Uint("revert", 0, "revert the run with this id instead")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *batchSize < 1 || *batchSize > utils.MaxDataRepairBatchSize {
+		return fmt.Errorf("%w: -batch-size must be between 1 and %d", errUsage, utils.MaxDataRepairBatchSize)
+	}
+
+	if *revertID != 0 {
+		var run models.DataRepairRun
+		if err := database.DB.First(&run, *revertID).Error; err != nil {
+			return fmt.Errorf("data repair run %d not found: %w", *revertID, err)
+		}
+		if run.DryRun {
+			return fmt.Errorf("data repair run %d is a dry run, nothing to revert", run.ID)
+		}
+
+		reverted, kept, err := utils.RevertDataRepair(database.DB, &run, *batchSize, nil)
+		fmt.Printf("%d values restored, %d changed since the run kept\n", reverted, kept)
+		if err != nil {
+			return fmt.Errorf("data repair revert failed: %w", err)
+		}
+		return nil
+	}
+
+	rule, ok := utils.FindDataRepairRule(*ruleName)
+	if !ok {
+		return fmt.Errorf("%w: a valid rule or -revert is required", errUsage)
+	}
+	if *maxRows < 0 {
+		return fmt.Errorf("%w: -max-rows must not be negative", errUsage)
+	}
+
+	run, previews, err := utils.RunDataRepair(database.DB, rule, !*apply, *batchSize, *maxRows, nil)
+	if run != nil {
+		for _, detail := range run.Details {
+			fmt.Printf("%-30s %d rows, %d conflicts\n", detail.Entity+"."+detail.Column, detail.AffectedRows, detail.ConflictRows)
+		}
+	}
+	for _, preview := range previews {
+		oldValue := "NULL"
+		if preview.OldValue != nil {
+			oldValue = strconv.Quote(*preview.OldValue)
+		}
+		fmt.Printf("  %s.%s %s -> %q: %d rows\n", preview.Entity, preview.Column, oldValue, preview.NewValue, preview.Rows)
+	}
+	if err != nil {
+		return fmt.Errorf("data repair failed: %w", err)
+	}
+
+	if run.DryRun {
+		fmt.Printf("Dry run %d: %d rows would be updated, run again with -apply to fix them\n", run.ID, run.AffectedRows)
+	} else {
+		fmt.Printf("Data repair run %d: %d rows updated in %d batches, revert with -revert %d\n", run.ID, run.AffectedRows, run.Batches, run.ID)
+	}
+	return nil
+}
+
+func runRotateTokenKey(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("rotate-token-key", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := utils.RotateTokenKeys(cfg)
+	if err != nil {
+		return fmt.Errorf("token key rotation failed: %w", err)
+	}
+
+	fmt.Printf("Token key %s is now current, restart or wait for other instances to reload %s\n", key.KeyID, cfg.TokenKeyFile)
+	return nil
+}
+
+func runAggregates(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("aggregates", flag.ContinueOnError)
+	days := flags.Int("days", 90, "number of days before today to rebuild")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *days < 0 {
+		return errors.New("-days must not be negative")
+	}
+
+	now := time.Now()
+	start := now.AddDate(0, 0, -*days)
+	if err := utils.RefreshDailyAggregates(database.DB, start, now); err != nil {
+		return fmt.Errorf("aggregate rebuild failed: %w", err)
+	}
+
+	fmt.Printf("Daily aggregates rebuilt from %s to %s\n", start.Format("2006-01-02"), now.Format("2006-01-02"))
+	return nil
+}
+
+func runImportLegacy(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import-legacy", flag.ContinueOnError)
+	entity := flags.String("entity", "", "entity of the rows: "+strings.Join(models.LegacyEntities(), ", "))
+	path := flags.String("file", "", "CSV or XLSX legacy spreadsheet")
+	templateName := flags.String("template", "", "name of the template mapping the spreadsheet headers")
+	dryRun := flags.Bool("dry-run", false, "only validate the rows and print the report")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !models.IsValidLegacyEntity(*entity) || *path == "" {
+		return fmt.Errorf("%w: a valid entity and a file are required", errUsage)
+	}
+
+	var template *models.LegacyImportTemplate
+	if *templateName != "" {
+		template = &models.LegacyImportTemplate{}
+		if err := database.DB.Where("name = ?", *templateName).First(template).Error; err != nil {
+			return fmt.Errorf("template %s not found: %w", *templateName, err)
+		}
+		if template.Entity != *entity {
+			return fmt.Errorf("%w: template %s maps %s, not %s", errUsage, template.Name, template.Entity, *entity)
+		}
+	}
+
+	cutoff, err := utils.LegacyImportCutoff(cfg)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, fileHash, err := utils.ReadLegacyImportFile(*path, file)
+	if err != nil {
+		return fmt.Errorf("invalid import file: %w", err)
+	}
+	rows, err := utils.ParseLegacyImportRows(*entity, template, records)
+	if err != nil {
+		return fmt.Errorf("invalid import file: %w", err)
+	}
+
+	// Warn when the same file was already imported, re-runs only create the records that were not
+	var previous models.LegacyImportRun
+	if err := database.DB.Where("file_hash = ? AND entity = ? AND dry_run = ?", fileHash, *entity, false).
+		Order("created_at DESC").First(&previous).Error; err == nil {
+		fmt.Printf("File was already imported by run %d on %s, imported records are skipped\n", previous.ID, previous.CreatedAt.Format("2006-01-02 15:04"))
+	}
+
+	run, err := utils.RunLegacyImport(database.DB, rows, utils.LegacyImportOptions{
+		Entity:          *entity,
+		Template:        template,
+		FileName:        filepath.Base(*path),
+		FileHash:        fileHash,
+		DryRun:          *dryRun,
+		Source:          models.LegacyImportSourceCLI,
+		DefaultCurrency: cfg.DefaultCurrency,
+		Cutoff:          cutoff,
+	})
+	if run == nil {
+		return fmt.Errorf("legacy import failed: %w", err)
+	}
+
+	for _, rowError := range run.ToResponse(true).Report {
+		field := rowError.Field
+		if field == "" {
+			field = "-"
+		}
+		fmt.Printf("row %-6d %-25s %-16s %s\n", rowError.Row, rowError.Key, field, rowError.Message)
+	}
+	if err != nil {
+		return fmt.Errorf("legacy import run %d failed: %w", run.ID, err)
+	}
+
+	if run.DryRun {
+		fmt.Printf("Dry run %d: %d %s to import, %d already imported, %d invalid\n", run.ID, run.Imported, run.Entity, run.Skipped, run.Invalid)
+	} else {
+		fmt.Printf("Run %d: %d %s imported, %d already imported, %d invalid\n", run.ID, run.Imported, run.Entity, run.Skipped, run.Invalid)
+	}
+	return nil
+}
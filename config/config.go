@@ -16,6 +16,13 @@ type Config struct {
 	DbSslMode string
 	DbTz      string
 
+	// Database connection pool settings
+	DbMaxOpenConns       int // connections
+	DbMaxIdleConns       int // connections
+	DbConnMaxLifetime    int // minutes
+	DbConnMaxIdleTime    int // minutes, 0 keeps idle connections until lifetime expires
+	DbSlowQueryThreshold int // milliseconds, 0 disables slow query logging
+
 	// Server settings
 	Env      string
 	Port     string
@@ -23,19 +30,230 @@ type Config struct {
 	AppName  string
 	LogLevel string
 
+	// Email settings (empty SMTP host logs emails instead of sending them)
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+	FrontendUrl  string // base URL of the web app used in emailed links
+
+	// Report settings
+	ReportScheduleCheckMinutes int // minutes between checks for scheduled report emails that are due, 0 disables delivery
+
+	// WhatsApp gateway settings (empty URL logs messages instead of sending them)
+	WhatsAppGatewayURL   string
+	WhatsAppGatewayToken string
+
+	// Address normalization settings (empty provider disables normalization)
+	AddressProvider        string // google, here or postcode
+	AddressProviderAPIKey  string // API key for the google and here providers
+	AddressPostcodeDataset string // CSV of postal_code,province,city,district used by the postcode provider
+
+	// Order settings
+	DefaultCurrency          string         // ISO 4217 currency of order prices when the order does not specify one
+	OrderNumberPrefix        string         // prefix of order numbers generated for orders without a marketplace order id, unless the store or channel sets one
+	OrderAgingThresholds     map[string]int // minutes an order may stay in a processing status before it counts as overdue
+	SLABreachCheckMinutes    int            // minutes between SentBefore breach checks, 0 disables the check
+	PickerAttendanceRequired bool           // only assign orders to pickers checked in at the order's warehouse location
+	PickerMaxOpenOrders      int            // orders a picker may have in picking progress before assignments need a coordinator override, 0 disables the quota
+	OrderEditLockTTLSeconds  int            // seconds an order edit lock stays held without a heartbeat
+	UnknownChannelPolicy     string         // keep, create or reject orders naming a channel that is neither set up nor aliased
+	UnknownStorePolicy       string         // keep, create or reject orders naming a store that is neither set up nor aliased
+
+	// Legacy import settings
+	LegacyGoLiveDate string // YYYY-MM-DD the system went live, legacy records on or after it are rejected, empty accepts any date
+
+	// Business calendar settings, SLA and aging durations only count warehouse operating hours
+	BusinessHours    []string // day=HH:MM-HH:MM entries, e.g. mon-fri=08:00-17:00, empty counts every hour
+	BusinessHolidays []string // YYYY-MM-DD dates the warehouse is closed
+
+	// Order volume alert settings, stores and channels without a threshold of their own use the defaults
+	OrderVolumeCheckMinutes int // minutes between order volume checks, 0 disables the alerts
+	OrderVolumeBaselineDays int // days before today the baseline daily order count is averaged over
+	OrderVolumeSpikePercent int // percent above the baseline the order count may reach before a spike alert, 0 disables spike alerts
+	OrderVolumeDropPercent  int // percent below the baseline the order count may fall before a drop alert, 0 disables drop alerts
+	OrderVolumeMinBaseline  int // baseline orders needed before deviations are alerted, keeps small stores and early hours quiet
+
+	// Picking anomaly settings
+	PickAnomalyFastSeconds int // seconds per unit below which an item pick is flagged as too fast, 0 disables
+	PickAnomalySlowSeconds int // seconds an item may take to pick before it is flagged as slow, 0 disables
+
+	// Export masking settings, roles in neither list export anonymized buyer data
+	MaskingFullRoles    []string // roles allowed to export buyer names and addresses unmasked
+	MaskingPartialRoles []string // roles allowed to export partially masked buyer names and addresses
+
+	// Dashboard settings
+	DailyAggregateIntervalMinutes int // minutes between refreshes of the daily dashboard aggregates, 0 disables the refresh
+	DailyAggregateLookbackDays    int // days before today recomputed on every refresh, catching late status changes
+
+	// Retraining settings
+	RetrainingComplaintThreshold int // users with more attributed complaints than this in the window are flagged for retraining, 0 disables flagging
+	RetrainingWindowDays         int // days of the rolling complaint window
+
+	// Complain assignment settings
+	ComplainAgentRoles []string // roles of the CS agents complains are assigned to
+	ComplainAutoAssign bool     // assign new complains round-robin over the agents
+
+	// Approval settings
+	ApprovalCodeTTLMinutes int // minutes a one-time approval code stays valid
+
+	// Onboarding settings
+	InvitationTTLHours int // hours an invitation link stays valid
+
+	// Password reset settings
+	PasswordResetTokenTTLMinutes int // minutes an emailed reset link stays valid
+	PasswordResetOTPTTLMinutes   int // minutes a WhatsApp OTP stays valid
+	PasswordResetMaxAttempts     int // wrong OTP attempts before the OTP is invalidated
+	PasswordResetLockoutMinutes  int // minutes wrong OTP attempts keep counting against newly requested OTPs
+
+	// Maintenance settings
+	MaintenanceMode    bool   // start the API in read-only mode
+	MaintenanceMessage string // message returned to clients while in maintenance mode
+
+	// Fault injection settings, staging only
+	FaultInjectionEnabled bool   // allow injecting latency and errors into the database and DeepFace per route
+	FaultInjectionRules   string // JSON list of fault rules applied at startup, can be replaced at runtime
+
+	// Tracing settings
+	OtelEnabled          bool
+	OtelServiceName      string
+	OtelExporterEndpoint string  // OTLP/HTTP collector host:port
+	OtelExporterInsecure bool    // use plain HTTP to the collector
+	OtelSampleRatio      float64 // fraction of new traces to sample, 0-1
+
+	// Error reporting to Sentry or GlitchTip (disabled without a DSN)
+	ErrorReportingDSN         string
+	ErrorReportingEnvironment string  // environment tag, defaults to Env
+	ErrorReportingRelease     string  // release tag, e.g. the deployed git commit
+	ErrorReportingSampleRate  float64 // fraction of errors reported, 0-1
+
+	// Request timeout settings
+	RequestTimeoutSeconds int // seconds, default for all routes, 0 disables
+	ReportTimeoutSeconds  int // seconds, /api/reports
+	KioskTimeoutSeconds   int // seconds, attendance kiosk and mobile attendance routes
+
+	// Upload settings
+	MaxBodySizeMB     int // megabytes, global request body limit
+	MaxImageUploadMB  int // megabytes, face/media image endpoints
+	MaxFaceBatchMB    int // megabytes, batch face check-in
+	MaxImportUploadMB int // megabytes, CSV/XLSX import endpoints
+
+	// File storage settings, media such as export files go to the storage driver, uploads waiting on DeepFace to the temp dir
+	StorageDriver              string // local or s3
+	StorageLocalDir            string // directory of the local driver
+	S3Endpoint                 string // e.g. https://s3.ap-southeast-1.amazonaws.com or a MinIO URL, objects are addressed path-style
+	S3Region                   string
+	S3Bucket                   string
+	S3AccessKeyID              string
+	S3SecretAccessKey          string
+	TempDir                    string // directory of temporary upload files, created on startup
+	TempFileMaxAgeMinutes      int    // minutes after which a temporary file counts as orphaned and is removed
+	TempCleanupIntervalMinutes int    // minutes between orphaned temporary file cleanups, 0 disables the cleanup
+
+	// Signed download URL settings, stored files are downloaded without a bearer token through short lived HMAC signed links
+	DownloadURLSecret          string // HMAC secret of download links, derived from PasetoSymmetricKey when empty
+	DownloadURLBase            string // origin the links point to, e.g. a CDN or nginx in front of the storage, empty serves them from the API
+	DownloadURLTTLMinutes      int    // minutes a download link stays valid
+	DownloadRateLimitPerMinute int    // downloads per IP
+
 	// Security settings
 	PasetoSymmetricKey string
+	PasetoKeyID        string   // key ID of PasetoSymmetricKey, written in the token footer
+	PasetoPreviousKeys []string // kid:key pairs still accepted for tokens issued before a manual rotation, env provider only
+	TokenKeyProvider   string   // env or file
+	TokenKeyFile       string   // key ring of the file provider, rotated keys are written back to it
 	CorsOrigins        []string
-	AccessTokenTTL     int // minutes
-	RefreshTokenTTL    int // days
+	AccessTokenTTL     int    // minutes
+	RefreshTokenTTL    int    // days
+	DocsAPIKey         string // grants access to the internal API docs without a token, empty disables
+	KioskAPIKey        string // authenticates the lobby attendance kiosk, empty disables the kiosk summary
+
+	// CORS settings per route group, CorsOrigins applies to the admin web app and every other route
+	CorsProfile           string   // dev, staging or prod, defaults from ENV
+	CorsMobileOrigins     []string // mobile app WebView (/api/mobile-*), empty uses the profile default
+	CorsMobileCredentials bool     // allow cookies on mobile routes
+	CorsKioskOrigins      []string // attendance kiosk, empty uses the profile default
+
+	// Global rate limit, every response reports the client's usage in RateLimit-* headers
+	RateLimitPerMinute int // requests per IP
+
+	// Access logs persisted for per-endpoint latency and error analytics
+	AccessLogEnabled       bool
+	AccessLogRetentionDays int // days, 0 keeps every entry
+
+	// Change feed of orders, QC, outbound and attendance read by the BI warehouse loader
+	ChangeFeedRetentionDays int // days change events are kept, 0 keeps every event
+	ChangeFeedSettleSeconds int // seconds a change event waits before it is served, so transactions open when it was written have committed
+
+	// Accounting sync of outbound scanned orders, for stores with an accounting mapping
+	AccountingSyncIntervalSeconds int // seconds between runs of the sync worker, 0 disables the worker
+	AccountingSyncMaxAttempts     int // attempts before a sync is marked failed and left for a manual retry
+	AccurateAPIURL                string
+	AccurateAccessToken           string
+	AccurateSessionID             string
+	JurnalAPIURL                  string
+	JurnalAPIKey                  string
+
+	// Brute-force protection on login, manual attendance and coordinator credential checks
+	AuthRateLimitPerMinute   int // requests per IP per endpoint
+	AuthMaxFailures          int // failed attempts per username + IP before a temporary ban
+	AuthFailureWindowMinutes int // minutes in which failed attempts are counted
+	AuthBanMinutes           int // minutes of the first ban, doubled on every repeated ban
+
+	// Security event alerts, raised to developers and superadmins (a threshold of 0 disables the rule)
+	SecurityAlertWindowMinutes     int // minutes in which security events are counted
+	SecurityAlertFailedLogins      int // failed logins on one account
+	SecurityAlertFailedLoginsPerIP int // failed logins from one IP, across accounts
+	SecurityAlertPermissionDenials int // permission-denied attempts by one user
+
+	// Feature flags
+	FeatureFlagCacheSeconds int // seconds flags are cached before being read again, changes through the API apply at once
+
+	// Inbound webhooks from Ginee, the marketplaces and 3PLs, signed with an HMAC of the timestamp and payload
+	WebhookSecrets                   map[string]string // HMAC secret per source, e.g. ginee, shopee, tokopedia, 3pl
+	WebhookRequireSignature          bool              // reject webhooks of sources without a secret (default) instead of accepting them unsigned
+	WebhookTimestampToleranceSeconds int               // seconds a signed webhook timestamp may differ from the server clock
+	WebhookEventRetentionDays        int               // days received webhooks are kept for reprocessing, 0 keeps every event
+
+	// Outbound order event webhooks delivered to the subscriptions of integrations, read from the change feed
+	WebhookDeliveryIntervalSeconds int // seconds between runs of the delivery worker, 0 disables the worker
+
+	// Courier booking APIs, booking the shipment of orders created without a tracking number
+	CourierBookingURLs    map[string]string // booking endpoint per expedition slug, couriers without one cannot be booked
+	CourierBookingAPIKeys map[string]string // bearer key per expedition slug
+
+	// Shadow mode of the unified QC service, which checks QC scans and validations alongside the lane handlers
+	QCShadowPercent int // percentage of QC scan and validate requests checked, 0 disables shadow mode
+
+	// Abuse protection on the public buyer tracking lookup
+	PublicTrackRateLimitPerMinute int // lookups per IP
+	PublicTrackMaxMisses          int // unknown tracking numbers per IP within the auth failure window before a temporary ban
+
+	// Data retention settings (0 disables the category)
+	RetentionBuyerPIIDays       int // days
+	RetentionFaceImageDays      int // days
+	RetentionAttendanceGPSDays  int // days
+	RetentionMediaPhotoDays     int // days, QC parcel photos and courier handover photos
+	RetentionPurgeIntervalHours int // hours, 0 disables the scheduled purge
+
+	// Client clock settings
+	ClockSkewToleranceSeconds int // seconds a device clock may differ from the server before its timestamps are flagged
+	OfflineCaptureWindowHours int // hours a device may capture offline actions after it last synced server time
+
+	// Face recognition settings
+	FaceMinConfidence    float64 // minimum confidence of a face match accepted for attendance, overridden per location
+	FaceReviewConfidence float64 // accepted face matches below this confidence are listed for review
+	FaceBatchMaxImages   int     // images accepted per batch check-in
+	FaceBatchConcurrency int     // face searches of a batch check-in sent to DeepFace at once
+
+	// Attendance settings
+	MissingCheckoutHour int  // hour of the day (0-23) the nightly missing checkout job runs, negative disables the job
+	AttendanceAutoClose bool // check out attendances left open at the shift end, flagged as auto-closed
+	MaxBreakMinutes     int  // total break minutes allowed per attendance, longer breaks are flagged for HR, 0 disables the check
 }
 
 func LoadConfig() *Config {
-	corsOrigins := os.Getenv("CORS_ORIGINS")
-	if corsOrigins == "" {
-		corsOrigins = "http://192.168.31.147:3000"
-	}
-
 	accessTokenTTL, err := strconv.Atoi(os.Getenv("ACCESS_TOKEN_TTL"))
 	if err != nil || accessTokenTTL <= 0 {
 		accessTokenTTL = 60 // default 60 minutes
@@ -56,6 +274,13 @@ func LoadConfig() *Config {
 		DbSslMode: getEnv("DB_SSLMODE", "disable"),
 		DbTz:      getEnv("DB_TZ", "UTC"),
 
+		// Database connection pool settings
+		DbMaxOpenConns:       getEnvInt("DB_MAX_OPEN_CONNS", 100),
+		DbMaxIdleConns:       getEnvInt("DB_MAX_IDLE_CONNS", 10),
+		DbConnMaxLifetime:    getEnvInt("DB_CONN_MAX_LIFETIME", 60),
+		DbConnMaxIdleTime:    getEnvInt("DB_CONN_MAX_IDLE_TIME", 0),
+		DbSlowQueryThreshold: getEnvInt("DB_SLOW_QUERY_THRESHOLD_MS", 500),
+
 		// Server settings
 		Env:      getEnv("ENV", "development"),
 		Port:     getEnv("PORT", "8040"),
@@ -63,11 +288,235 @@ func LoadConfig() *Config {
 		AppName:  getEnv("APP_NAME", "MyApp"),
 		LogLevel: getEnv("LOG_LEVEL", "debug"),
 
+		// Email settings
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@localhost"),
+		FrontendUrl:  getEnv("FRONTEND_URL", "http://localhost:3000"),
+
+		// Report settings
+		ReportScheduleCheckMinutes: getEnvInt("REPORT_SCHEDULE_CHECK_MINUTES", 5),
+
+		// WhatsApp gateway settings
+		WhatsAppGatewayURL:   getEnv("WHATSAPP_GATEWAY_URL", ""),
+		WhatsAppGatewayToken: getEnv("WHATSAPP_GATEWAY_TOKEN", ""),
+
+		// Address normalization settings
+		AddressProvider:        getEnv("ADDRESS_PROVIDER", ""),
+		AddressProviderAPIKey:  getEnv("ADDRESS_PROVIDER_API_KEY", ""),
+		AddressPostcodeDataset: getEnv("ADDRESS_POSTCODE_DATASET", "data/postcodes.csv"),
+
+		// Order settings
+		DefaultCurrency:   strings.ToUpper(getEnv("DEFAULT_CURRENCY", "IDR")),
+		OrderNumberPrefix: strings.ToUpper(getEnv("ORDER_NUMBER_PREFIX", "LIVO")),
+		OrderAgingThresholds: getEnvIntMap("ORDER_AGING_THRESHOLDS", map[string]int{
+			"ready_to_pick":     120,
+			"picking_progress":  240,
+			"picking_pending":   120,
+			"awaiting_stock":    1440,
+			"picking_completed": 120,
+			"qc_progress":       60,
+			"qc_completed":      1440,
+		}),
+		SLABreachCheckMinutes:    getEnvInt("SLA_BREACH_CHECK_MINUTES", 15),
+		PickerAttendanceRequired: getEnvBool("PICKER_ATTENDANCE_REQUIRED", true),
+		PickerMaxOpenOrders:      getEnvInt("PICKER_MAX_OPEN_ORDERS", 20),
+		OrderEditLockTTLSeconds:  getEnvInt("ORDER_EDIT_LOCK_TTL_SECONDS", 120),
+		UnknownChannelPolicy:     strings.ToLower(getEnv("ORDER_UNKNOWN_CHANNEL_POLICY", "keep")),
+		UnknownStorePolicy:       strings.ToLower(getEnv("ORDER_UNKNOWN_STORE_POLICY", "keep")),
+
+		// Legacy import settings
+		LegacyGoLiveDate: getEnv("LEGACY_GO_LIVE_DATE", ""),
+
+		// Business calendar settings
+		BusinessHours:    getEnvList("BUSINESS_HOURS", nil),
+		BusinessHolidays: getEnvList("BUSINESS_HOLIDAYS", nil),
+
+		// Order volume alert settings
+		OrderVolumeCheckMinutes: getEnvInt("ORDER_VOLUME_CHECK_MINUTES", 60),
+		OrderVolumeBaselineDays: getEnvInt("ORDER_VOLUME_BASELINE_DAYS", 28),
+		OrderVolumeSpikePercent: getEnvInt("ORDER_VOLUME_SPIKE_PERCENT", 100),
+		OrderVolumeDropPercent:  getEnvInt("ORDER_VOLUME_DROP_PERCENT", 50),
+		OrderVolumeMinBaseline:  getEnvInt("ORDER_VOLUME_MIN_BASELINE", 20),
+
+		// Picking anomaly settings
+		PickAnomalyFastSeconds: getEnvInt("PICK_ANOMALY_FAST_SECONDS", 2),
+		PickAnomalySlowSeconds: getEnvInt("PICK_ANOMALY_SLOW_SECONDS", 600),
+
+		// Export masking settings
+		MaskingFullRoles:    getEnvList("MASKING_FULL_ROLES", []string{"developer", "superadmin"}),
+		MaskingPartialRoles: getEnvList("MASKING_PARTIAL_ROLES", []string{"admin", "coordinator"}),
+
+		// Dashboard settings
+		DailyAggregateIntervalMinutes: getEnvInt("DAILY_AGGREGATE_INTERVAL_MINUTES", 15),
+		DailyAggregateLookbackDays:    getEnvInt("DAILY_AGGREGATE_LOOKBACK_DAYS", 7),
+
+		// Retraining settings
+		RetrainingComplaintThreshold: getEnvInt("RETRAINING_COMPLAINT_THRESHOLD", 3),
+		RetrainingWindowDays:         getEnvInt("RETRAINING_WINDOW_DAYS", 30),
+
+		// Complain assignment settings
+		ComplainAgentRoles: getEnvList("COMPLAIN_AGENT_ROLES", []string{"admin"}),
+		ComplainAutoAssign: getEnvBool("COMPLAIN_AUTO_ASSIGN", true),
+
+		// Approval settings
+		ApprovalCodeTTLMinutes: getEnvInt("APPROVAL_CODE_TTL_MINUTES", 5),
+
+		// Onboarding settings
+		InvitationTTLHours: getEnvInt("INVITATION_TTL_HOURS", 72),
+
+		// Password reset settings
+		PasswordResetTokenTTLMinutes: getEnvInt("PASSWORD_RESET_TOKEN_TTL_MINUTES", 30),
+		PasswordResetOTPTTLMinutes:   getEnvInt("PASSWORD_RESET_OTP_TTL_MINUTES", 10),
+		PasswordResetMaxAttempts:     getEnvInt("PASSWORD_RESET_MAX_ATTEMPTS", 5),
+		PasswordResetLockoutMinutes:  getEnvInt("PASSWORD_RESET_LOCKOUT_MINUTES", 60),
+
+		// Maintenance settings
+		MaintenanceMode:    getEnvBool("MAINTENANCE_MODE", false),
+		MaintenanceMessage: getEnv("MAINTENANCE_MESSAGE", ""),
+
+		// Fault injection settings
+		FaultInjectionEnabled: getEnvBool("FAULT_INJECTION_ENABLED", false),
+		FaultInjectionRules:   getEnv("FAULT_INJECTION_RULES", ""),
+
+		// Tracing settings
+		OtelEnabled:          getEnvBool("OTEL_ENABLED", false),
+		OtelServiceName:      getEnv("OTEL_SERVICE_NAME", "livo-fiber-backend"),
+		OtelExporterEndpoint: getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
+		OtelExporterInsecure: getEnvBool("OTEL_EXPORTER_OTLP_INSECURE", true),
+		OtelSampleRatio:      getEnvFloat("OTEL_SAMPLE_RATIO", 1),
+
+		// Error reporting
+		ErrorReportingDSN:         getEnv("SENTRY_DSN", ""),
+		ErrorReportingEnvironment: getEnv("SENTRY_ENVIRONMENT", ""),
+		ErrorReportingRelease:     getEnv("SENTRY_RELEASE", ""),
+		ErrorReportingSampleRate:  getEnvFloat("SENTRY_SAMPLE_RATE", 1),
+
+		// Request timeout settings
+		RequestTimeoutSeconds: getEnvInt("REQUEST_TIMEOUT_SECONDS", 30),
+		ReportTimeoutSeconds:  getEnvInt("REPORT_TIMEOUT_SECONDS", 120),
+		KioskTimeoutSeconds:   getEnvInt("KIOSK_TIMEOUT_SECONDS", 15),
+
+		// Upload settings
+		MaxBodySizeMB:     getEnvInt("MAX_BODY_SIZE_MB", 10),
+		MaxImageUploadMB:  getEnvInt("MAX_IMAGE_UPLOAD_MB", 5),
+		MaxFaceBatchMB:    getEnvInt("MAX_FACE_BATCH_MB", 10),
+		MaxImportUploadMB: getEnvInt("MAX_IMPORT_UPLOAD_MB", 10),
+
+		// File storage settings
+		StorageDriver:              strings.ToLower(getEnv("STORAGE_DRIVER", "local")),
+		StorageLocalDir:            getEnv("STORAGE_LOCAL_DIR", "storage"),
+		S3Endpoint:                 getEnv("S3_ENDPOINT", ""),
+		S3Region:                   getEnv("S3_REGION", "ap-southeast-1"),
+		S3Bucket:                   getEnv("S3_BUCKET", ""),
+		S3AccessKeyID:              getEnv("S3_ACCESS_KEY_ID", ""),
+		S3SecretAccessKey:          getEnv("S3_SECRET_ACCESS_KEY", ""),
+		TempDir:                    getEnv("TMP_DIR", "tmp"),
+		TempFileMaxAgeMinutes:      getEnvInt("TEMP_FILE_MAX_AGE_MINUTES", 60),
+		TempCleanupIntervalMinutes: getEnvInt("TEMP_CLEANUP_INTERVAL_MINUTES", 15),
+
+		// Signed download URL settings
+		DownloadURLSecret:          getEnv("DOWNLOAD_URL_SECRET", ""),
+		DownloadURLBase:            strings.TrimSuffix(getEnv("DOWNLOAD_URL_BASE", ""), "/"),
+		DownloadURLTTLMinutes:      getEnvInt("DOWNLOAD_URL_TTL_MINUTES", 15),
+		DownloadRateLimitPerMinute: getEnvInt("DOWNLOAD_RATE_LIMIT_PER_MINUTE", 30),
+
 		// Security settings
 		PasetoSymmetricKey: getEnv("PASETO_SYMMETRIC_KEY", "your-32-character-secret-key!!"), // Must be 32 chars
-		CorsOrigins:        strings.Split(corsOrigins, ","),
+		PasetoKeyID:        getEnv("PASETO_KEY_ID", "default"),
+		PasetoPreviousKeys: getEnvList("PASETO_PREVIOUS_KEYS", nil),
+		TokenKeyProvider:   getEnv("TOKEN_KEY_PROVIDER", "env"),
+		TokenKeyFile:       getEnv("TOKEN_KEY_FILE", "secrets/token_keys.json"),
+		CorsOrigins:        getEnvList("CORS_ORIGINS", []string{"http://192.168.31.147:3000"}),
 		AccessTokenTTL:     accessTokenTTL,  // 15 minutes
 		RefreshTokenTTL:    refreshTokenTTL, // 7 days
+		DocsAPIKey:         getEnv("DOCS_API_KEY", ""),
+		KioskAPIKey:        getEnv("KIOSK_API_KEY", ""),
+
+		// CORS settings
+		CorsProfile:           getEnv("CORS_PROFILE", defaultCorsProfile(getEnv("ENV", "development"))),
+		CorsMobileOrigins:     getEnvList("CORS_MOBILE_ORIGINS", nil),
+		CorsMobileCredentials: getEnvBool("CORS_MOBILE_CREDENTIALS", false),
+		CorsKioskOrigins:      getEnvList("CORS_KIOSK_ORIGINS", nil),
+
+		// Global rate limit
+		RateLimitPerMinute: getEnvInt("RATE_LIMIT_PER_MINUTE", 100),
+
+		// Access logs
+		AccessLogEnabled:       getEnvBool("ACCESS_LOG_ENABLED", true),
+		AccessLogRetentionDays: getEnvInt("ACCESS_LOG_RETENTION_DAYS", 30),
+
+		// Change feed
+		ChangeFeedRetentionDays: getEnvInt("CHANGE_FEED_RETENTION_DAYS", 30),
+		ChangeFeedSettleSeconds: getEnvInt("CHANGE_FEED_SETTLE_SECONDS", 10),
+
+		// Accounting sync
+		AccountingSyncIntervalSeconds: getEnvInt("ACCOUNTING_SYNC_INTERVAL_SECONDS", 60),
+		AccountingSyncMaxAttempts:     getEnvInt("ACCOUNTING_SYNC_MAX_ATTEMPTS", 8),
+		AccurateAPIURL:                getEnv("ACCURATE_API_URL", ""),
+		AccurateAccessToken:           getEnv("ACCURATE_ACCESS_TOKEN", ""),
+		AccurateSessionID:             getEnv("ACCURATE_SESSION_ID", ""),
+		JurnalAPIURL:                  getEnv("JURNAL_API_URL", "https://api.jurnal.id/core/api/v1"),
+		JurnalAPIKey:                  getEnv("JURNAL_API_KEY", ""),
+
+		// Brute-force protection
+		AuthRateLimitPerMinute:   getEnvInt("AUTH_RATE_LIMIT_PER_MINUTE", 10),
+		AuthMaxFailures:          getEnvInt("AUTH_MAX_FAILURES", 5),
+		AuthFailureWindowMinutes: getEnvInt("AUTH_FAILURE_WINDOW_MINUTES", 15),
+		AuthBanMinutes:           getEnvInt("AUTH_BAN_MINUTES", 15),
+
+		// Security event alerts
+		SecurityAlertWindowMinutes:     getEnvInt("SECURITY_ALERT_WINDOW_MINUTES", 5),
+		SecurityAlertFailedLogins:      getEnvInt("SECURITY_ALERT_FAILED_LOGINS", 10),
+		SecurityAlertFailedLoginsPerIP: getEnvInt("SECURITY_ALERT_FAILED_LOGINS_PER_IP", 30),
+		SecurityAlertPermissionDenials: getEnvInt("SECURITY_ALERT_PERMISSION_DENIALS", 20),
+
+		// Feature flags
+		FeatureFlagCacheSeconds: getEnvInt("FEATURE_FLAG_CACHE_SECONDS", 30),
+
+		// Inbound webhooks
+		WebhookSecrets:                   getEnvStringMap("WEBHOOK_SECRETS"),
+		WebhookRequireSignature:          getEnvBool("WEBHOOK_REQUIRE_SIGNATURE", true),
+		WebhookTimestampToleranceSeconds: getEnvInt("WEBHOOK_TIMESTAMP_TOLERANCE_SECONDS", 300),
+		WebhookEventRetentionDays:        getEnvInt("WEBHOOK_EVENT_RETENTION_DAYS", 30),
+
+		// Outbound webhooks
+		WebhookDeliveryIntervalSeconds: getEnvInt("WEBHOOK_DELIVERY_INTERVAL_SECONDS", 15),
+
+		// Courier booking
+		CourierBookingURLs:    getEnvStringMap("COURIER_BOOKING_URLS"),
+		CourierBookingAPIKeys: getEnvStringMap("COURIER_BOOKING_API_KEYS"),
+
+		// QC shadow mode
+		QCShadowPercent: getEnvInt("QC_SHADOW_PERCENT", 0),
+
+		// Public tracking abuse protection
+		PublicTrackRateLimitPerMinute: getEnvInt("PUBLIC_TRACK_RATE_LIMIT_PER_MINUTE", 20),
+		PublicTrackMaxMisses:          getEnvInt("PUBLIC_TRACK_MAX_MISSES", 10),
+
+		// Data retention settings
+		RetentionBuyerPIIDays:       getEnvInt("RETENTION_BUYER_PII_DAYS", 365),
+		RetentionFaceImageDays:      getEnvInt("RETENTION_FACE_IMAGE_DAYS", 90),
+		RetentionAttendanceGPSDays:  getEnvInt("RETENTION_ATTENDANCE_GPS_DAYS", 180),
+		RetentionMediaPhotoDays:     getEnvInt("RETENTION_MEDIA_PHOTO_DAYS", 365),
+		RetentionPurgeIntervalHours: getEnvInt("RETENTION_PURGE_INTERVAL_HOURS", 24),
+
+		// Client clock settings
+		ClockSkewToleranceSeconds: getEnvInt("CLOCK_SKEW_TOLERANCE_SECONDS", 120),
+		OfflineCaptureWindowHours: getEnvInt("OFFLINE_CAPTURE_WINDOW_HOURS", 24),
+
+		// Face recognition settings
+		FaceMinConfidence:    getEnvFloat("FACE_MIN_CONFIDENCE", 0),
+		FaceReviewConfidence: getEnvFloat("FACE_REVIEW_CONFIDENCE", 0.8),
+		FaceBatchMaxImages:   getEnvInt("FACE_BATCH_MAX_IMAGES", 40),
+		FaceBatchConcurrency: getEnvInt("FACE_BATCH_CONCURRENCY", 4),
+
+		// Attendance settings
+		MissingCheckoutHour: getEnvInt("MISSING_CHECKOUT_HOUR", 23),
+		AttendanceAutoClose: getEnvBool("ATTENDANCE_AUTO_CLOSE", false),
+		MaxBreakMinutes:     getEnvInt("ATTENDANCE_MAX_BREAK_MINUTES", 60),
 	}
 }
 
@@ -77,3 +526,88 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList parses a comma separated list, empty entries are dropped
+func getEnvList(key string, defaultValue []string) []string {
+	var result []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	if len(result) == 0 {
+		return defaultValue
+	}
+	return result
+}
+
+// defaultCorsProfile maps the environment name to the CORS profile used when CORS_PROFILE is not set
+func defaultCorsProfile(env string) string {
+	switch strings.ToLower(env) {
+	case "production", "prod":
+		return "prod"
+	case "staging":
+		return "staging"
+	default:
+		return "dev"
+	}
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvIntMap parses a comma separated list of key=value pairs over the defaults, invalid pairs are ignored
+func getEnvIntMap(key string, defaultValue map[string]int) map[string]int {
+	result := make(map[string]int, len(defaultValue))
+	for k, v := range defaultValue {
+		result[k] = v
+	}
+
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, rawValue, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		value, err := strconv.Atoi(strings.TrimSpace(rawValue))
+		if err != nil || value <= 0 {
+			continue
+		}
+		result[strings.TrimSpace(name)] = value
+	}
+	return result
+}
+
+// getEnvStringMap parses a comma separated list of key=value pairs, pairs without a key or value are ignored
+func getEnvStringMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		name, value, found := strings.Cut(pair, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !found || name == "" || value == "" {
+			continue
+		}
+		result[strings.ToLower(name)] = value
+	}
+	return result
+}
@@ -0,0 +1,405 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type AccessLogController struct {
+	DB *gorm.DB
+}
+
+func NewAccessLogController(db *gorm.DB) *AccessLogController {
+	return &AccessLogController{DB: db}
+}
+
+// Unique response structs
+// EndpointStats are the request count, error rates and latency of an endpoint over a period
+type EndpointStats struct {
+	Method          string  `json:"method"`
+	Route           string  `json:"route"`
+	Requests        int64   `json:"requests"`
+	ServerErrors    int64   `json:"serverErrors"` // 5xx responses
+	ClientErrors    int64   `json:"clientErrors"` // 4xx responses
+	ErrorRate       float64 `json:"errorRate"`    // percentage of 5xx responses
+	ClientErrorRate float64 `json:"clientErrorRate"`
+	AvgMs           float64 `json:"avgMs"`
+	P95Ms           float64 `json:"p95Ms"`
+	MaxMs           float64 `json:"maxMs"`
+}
+
+// EndpointTimelineBucket are the endpoint statistics of one hour or day
+type EndpointTimelineBucket struct {
+	Bucket string `json:"bucket"`
+	EndpointStats
+}
+
+// AccessLogAnalyticsResponse ranks the endpoints of a period by latency, error rate or traffic
+type AccessLogAnalyticsResponse struct {
+	StartDate string          `json:"startDate"`
+	EndDate   string          `json:"endDate"`
+	Sort      string          `json:"sort"`
+	Dropped   int64           `json:"dropped"` // entries dropped since startup because the writer could not keep up
+	Endpoints []EndpointStats `json:"endpoints"`
+}
+
+// AccessLogTimelineResponse is the evolution of an endpoint's latency and error rate over a period
+type AccessLogTimelineResponse struct {
+	Method    string                   `json:"method,omitempty"`
+	Route     string                   `json:"route"`
+	Interval  string                   `json:"interval"`
+	StartDate string                   `json:"startDate"`
+	EndDate   string                   `json:"endDate"`
+	Buckets   []EndpointTimelineBucket `json:"buckets"`
+}
+
+// endpointStatsColumns aggregates access logs into EndpointStats, errors rates are filled in afterwards
+const endpointStatsColumns = `COUNT(*) AS requests,
+	COUNT(*) FILTER (WHERE status >= 500) AS server_errors,
+	COUNT(*) FILTER (WHERE status >= 400 AND status < 500) AS client_errors,
+	COALESCE(AVG(duration_ms), 0) AS avg_ms,
+	COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY duration_ms), 0) AS p95_ms,
+	COALESCE(MAX(duration_ms), 0) AS max_ms`
+
+// accessLogSorts maps the sort query parameter of the analytics to its ORDER BY clause
+var accessLogSorts = map[string]string{
+	"p95":       "p95_ms DESC",
+	"avg":       "avg_ms DESC",
+	"requests":  "requests DESC",
+	"errorRate": "COUNT(*) FILTER (WHERE status >= 500)::float / COUNT(*) DESC, requests DESC",
+}
+
+// fillErrorRates computes the error percentages of endpoint statistics and rounds them to two decimals
+func (es *EndpointStats) fillErrorRates() {
+	if es.Requests == 0 {
+		return
+	}
+	es.ErrorRate = math.Round(float64(es.ServerErrors)*10000/float64(es.Requests)) / 100
+	es.ClientErrorRate = math.Round(float64(es.ClientErrors)*10000/float64(es.Requests)) / 100
+	es.AvgMs = math.Round(es.AvgMs*100) / 100
+	es.P95Ms = math.Round(es.P95Ms*100) / 100
+	es.MaxMs = math.Round(es.MaxMs*100) / 100
+}
+
+// accessLogPeriod parses the startDate and endDate query parameters, defaulting to the last 7 days.
+// The returned end is exclusive.
+func accessLogPeriod(c fiber.Ctx) (time.Time, time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	start, end := today.AddDate(0, 0, -6), today.AddDate(0, 0, 1)
+
+	if startDate := c.Query("startDate", ""); startDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid startDate format, use YYYY-MM-DD")
+		}
+		start = parsed
+	}
+	if endDate := c.Query("endDate", ""); endDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid endDate format, use YYYY-MM-DD")
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("endDate must not be before startDate")
+	}
+	return start, end, nil
+}
+
+// GetAccessLogs retrieves persisted API requests with pagination and filters
+// @Summary Get Access Logs
+// @Description Retrieve persisted API requests, newest first, filtered by route pattern, method, status, user, minimum duration and date range
+// @Tags Access Logs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of access logs per page" default(10)
+// @Param route query string false "Route pattern, e.g. /api/orders/:id"
+// @Param method query string false "HTTP method"
+// @Param status query string false "Status code (e.g. 500) or class (2xx, 4xx, 5xx)"
+// @Param userId query int false "Filter by user ID"
+// @Param minDurationMs query number false "Only requests slower than this many milliseconds"
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to 6 days ago"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AccessLogResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/access-logs [get]
+func (alc *AccessLogController) GetAccessLogs(c fiber.Ctx) error {
+	log.Println("GetAccessLogs called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	start, end, err := accessLogPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// Build base query
+	query := alc.DB.Model(&models.AccessLog{}).Where("created_at >= ? AND created_at < ?", start, end).Order("created_at DESC")
+	var filters []string
+
+	if route := strings.TrimSpace(c.Query("route", "")); route != "" {
+		query = query.Where("route = ?", route)
+		filters = append(filters, "route: "+route)
+	}
+	if method := strings.ToUpper(strings.TrimSpace(c.Query("method", ""))); method != "" {
+		query = query.Where("method = ?", method)
+		filters = append(filters, "method: "+method)
+	}
+	if status := strings.ToLower(strings.TrimSpace(c.Query("status", ""))); status != "" {
+		if len(status) == 3 && strings.HasSuffix(status, "xx") && status[0] >= '1' && status[0] <= '5' {
+			class := int(status[0]-'0') * 100
+			query = query.Where("status >= ? AND status < ?", class, class+100)
+		} else if code, err := strconv.Atoi(status); err == nil {
+			query = query.Where("status = ?", code)
+		} else {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status, use a status code or a class such as 5xx",
+			})
+		}
+		filters = append(filters, "status: "+status)
+	}
+	if userID := c.Query("userId", ""); userID != "" {
+		query = query.Where("user_id = ?", userID)
+		filters = append(filters, "userId: "+userID)
+	}
+	if minDuration := c.Query("minDurationMs", ""); minDuration != "" {
+		parsed, err := strconv.ParseFloat(minDuration, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid minDurationMs",
+			})
+		}
+		query = query.Where("duration_ms >= ?", parsed)
+		filters = append(filters, "minDurationMs: "+minDuration)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	var accessLogs []models.AccessLog
+	if err := query.Limit(limit).Offset(offset).Find(&accessLogs).Error; err != nil {
+		log.Println("GetAccessLogs - Failed to retrieve access logs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve access logs",
+		})
+	}
+
+	// Format response
+	accessLogList := make([]models.AccessLogResponse, len(accessLogs))
+	for i, accessLog := range accessLogs {
+		accessLogList[i] = *accessLog.ToResponse()
+	}
+
+	// Build success message
+	message := "Access logs retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetAccessLogs completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    accessLogList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetAccessLogAnalytics ranks endpoints by latency, error rate or traffic over a period
+// @Summary Get Access Log Analytics
+// @Description Retrieve the request count, 4xx and 5xx rates and average, p95 and max latency of each endpoint (method and route pattern) over a period, to prioritize optimization work
+// @Tags Access Logs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to 6 days ago"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param sort query string false "Ranking: p95, avg, requests or errorRate" default(p95)
+// @Param limit query int false "Number of endpoints returned" default(50)
+// @Param minRequests query int false "Ignore endpoints with fewer requests in the period" default(1)
+// @Success 200 {object} utils.SuccessResponse{data=AccessLogAnalyticsResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/access-logs/analytics [get]
+func (alc *AccessLogController) GetAccessLogAnalytics(c fiber.Ctx) error {
+	log.Println("GetAccessLogAnalytics called")
+
+	start, end, err := accessLogPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	sort := c.Query("sort", "p95")
+	orderBy, ok := accessLogSorts[sort]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid sort, use p95, avg, requests or errorRate",
+		})
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	if limit <= 0 {
+		limit = 50
+	}
+	minRequests, _ := strconv.Atoi(c.Query("minRequests", "1"))
+
+	endpoints := []EndpointStats{}
+	if err := alc.DB.Model(&models.AccessLog{}).
+		Select("method, route, "+endpointStatsColumns).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("method, route").
+		Having("COUNT(*) >= ?", minRequests).
+		Order(orderBy).
+		Limit(limit).
+		Scan(&endpoints).Error; err != nil {
+		log.Println("GetAccessLogAnalytics - Failed to aggregate access logs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to aggregate access logs",
+		})
+	}
+	for i := range endpoints {
+		endpoints[i].fillErrorRates()
+	}
+
+	log.Println("GetAccessLogAnalytics completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Access log analytics retrieved successfully",
+		Data: AccessLogAnalyticsResponse{
+			StartDate: start.Format("2006-01-02"),
+			EndDate:   end.AddDate(0, 0, -1).Format("2006-01-02"),
+			Sort:      sort,
+			Dropped:   utils.AccessLogDropped(),
+			Endpoints: endpoints,
+		},
+	})
+}
+
+// GetAccessLogTimeline retrieves the latency and error rate of an endpoint per hour or day
+// @Summary Get Access Log Timeline
+// @Description Retrieve the request count, error rates and latency of an endpoint per hour or day over a period, to see whether it got slower or started failing
+// @Tags Access Logs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param route query string true "Route pattern, e.g. /api/orders/:id"
+// @Param method query string false "HTTP method, all methods when empty"
+// @Param interval query string false "Bucket size: hour or day" default(day)
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to 6 days ago"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Success 200 {object} utils.SuccessResponse{data=AccessLogTimelineResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/access-logs/analytics/timeline [get]
+func (alc *AccessLogController) GetAccessLogTimeline(c fiber.Ctx) error {
+	log.Println("GetAccessLogTimeline called")
+
+	route := strings.TrimSpace(c.Query("route", ""))
+	if route == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "route is required",
+		})
+	}
+	interval := c.Query("interval", "day")
+	bucketFormat := "02-01-2006"
+	switch interval {
+	case "day":
+	case "hour":
+		bucketFormat = "02-01-2006 15:00"
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid interval, use hour or day",
+		})
+	}
+
+	start, end, err := accessLogPeriod(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// The interval is whitelisted above, date_trunc takes it as a literal so it can be grouped on
+	query := alc.DB.Model(&models.AccessLog{}).
+		Select(fmt.Sprintf("date_trunc('%s', created_at) AS bucket, ", interval)+endpointStatsColumns).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Where("route = ?", route)
+	method := strings.ToUpper(strings.TrimSpace(c.Query("method", "")))
+	if method != "" {
+		query = query.Where("method = ?", method)
+	}
+
+	var rows []struct {
+		Bucket time.Time
+		EndpointStats
+	}
+	if err := query.Group("bucket").Order("bucket ASC").Scan(&rows).Error; err != nil {
+		log.Println("GetAccessLogTimeline - Failed to aggregate access logs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to aggregate access logs",
+		})
+	}
+
+	buckets := make([]EndpointTimelineBucket, len(rows))
+	for i, row := range rows {
+		row.EndpointStats.Method = method
+		row.EndpointStats.Route = route
+		row.EndpointStats.fillErrorRates()
+		buckets[i] = EndpointTimelineBucket{
+			Bucket:        row.Bucket.Format(bucketFormat),
+			EndpointStats: row.EndpointStats,
+		}
+	}
+
+	log.Println("GetAccessLogTimeline completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Access log timeline retrieved successfully",
+		Data: AccessLogTimelineResponse{
+			Method:    method,
+			Route:     route,
+			Interval:  interval,
+			StartDate: start.Format("2006-01-02"),
+			EndDate:   end.AddDate(0, 0, -1).Format("2006-01-02"),
+			Buckets:   buckets,
+		},
+	})
+}
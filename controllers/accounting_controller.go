@@ -0,0 +1,526 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type AccountingController struct {
+	DB     *gorm.DB
+	Config *config.Config
+}
+
+func NewAccountingController(cfg *config.Config, db *gorm.DB) *AccountingController {
+	return &AccountingController{Config: cfg, DB: db}
+}
+
+// Request structs
+type SaveAccountingMappingRequest struct {
+	Provider      string `json:"provider" validate:"required" example:"accurate"` // accurate or jurnal
+	Enabled       *bool  `json:"enabled" example:"true"`                          // defaults to true
+	CustomerNo    string `json:"customerNo" validate:"required" example:"C.00012"`
+	WarehouseName string `json:"warehouseName" example:"Gudang Malang"`
+	BranchName    string `json:"branchName" example:"Malang"`
+	TaxIncluded   bool   `json:"taxIncluded" example:"false"`
+}
+
+// Response structs
+type AccountingProviderSyncStatus struct {
+	Provider        string  `json:"provider" example:"accurate"`
+	Configured      bool    `json:"configured" example:"true"` // credentials are set
+	MappedStores    int64   `json:"mappedStores" example:"3"`  // stores with an enabled mapping
+	Pending         int64   `json:"pending" example:"12"`
+	Retrying        int64   `json:"retrying" example:"2"` // pending after at least one failed attempt
+	Synced          int64   `json:"synced" example:"1520"`
+	Failed          int64   `json:"failed" example:"1"`
+	OldestPendingAt *string `json:"oldestPendingAt,omitempty" example:"16-10-2026 08:15:00"`
+	LastSyncedAt    *string `json:"lastSyncedAt,omitempty" example:"16-10-2026 09:02:11"`
+}
+
+type AccountingSyncDashboardResponse struct {
+	WorkerEnabled  bool                            `json:"workerEnabled" example:"true"`
+	Providers      []AccountingProviderSyncStatus  `json:"providers"`
+	RecentFailures []models.AccountingSyncResponse `json:"recentFailures"` // latest failed attempts, pending retries included
+}
+
+// GetAccountingMappings retrieves the accounting mapping of every store
+// @Summary Get Accounting Mappings
+// @Description Retrieve how the completed orders of each store are booked in the accounting system (Accurate Online or Jurnal)
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]models.AccountingMappingResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/accounting/mappings [get]
+func (ac *AccountingController) GetAccountingMappings(c fiber.Ctx) error {
+	log.Println("GetAccountingMappings called")
+
+	var mappings []models.AccountingMapping
+	if err := ac.DB.WithContext(c.Context()).Preload("Store").Preload("UpdateUser").Order("store_id ASC").Find(&mappings).Error; err != nil {
+		log.Println("GetAccountingMappings - Failed to retrieve mappings:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve accounting mappings",
+		})
+	}
+
+	mappingList := make([]models.AccountingMappingResponse, len(mappings))
+	for i, mapping := range mappings {
+		mappingList[i] = *mapping.ToResponse()
+	}
+
+	log.Println("GetAccountingMappings completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Accounting mappings retrieved successfully",
+		Data:    mappingList,
+	})
+}
+
+// SaveAccountingMapping creates or updates the accounting mapping of a store
+// @Summary Save Accounting Mapping
+// @Description Configure how the completed orders of a store are booked in the accounting system. Orders outbound scanned while the mapping is enabled are queued and pushed as sales invoices, order lines are booked under their SKU
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param storeId path int true "Store ID"
+// @Param request body SaveAccountingMappingRequest true "Accounting mapping"
+// @Success 200 {object} utils.SuccessResponse{data=models.AccountingMappingResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/accounting/mappings/{storeId} [put]
+func (ac *AccountingController) SaveAccountingMapping(c fiber.Ctx) error {
+	log.Println("SaveAccountingMapping called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("SaveAccountingMapping - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	storeID := c.Params("storeId")
+	var store models.Store
+	if err := ac.DB.WithContext(c.Context()).Where("id = ?", storeID).First(&store).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store with id " + storeID + " not found.",
+		})
+	}
+
+	// Parse request body
+	var req SaveAccountingMappingRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SaveAccountingMapping - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Provider = strings.ToLower(strings.TrimSpace(req.Provider))
+	if !models.IsValidAccountingProvider(req.Provider) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid provider. Use one of: " + strings.Join(models.AccountingProviders(), ", "),
+		})
+	}
+	req.CustomerNo = strings.TrimSpace(req.CustomerNo)
+	if req.CustomerNo == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Customer number is required",
+		})
+	}
+
+	var mapping models.AccountingMapping
+	err = ac.DB.WithContext(c.Context()).Where("store_id = ?", store.ID).First(&mapping).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("SaveAccountingMapping - Failed to retrieve mapping:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve accounting mapping",
+		})
+	}
+
+	mapping.StoreID = store.ID
+	mapping.Provider = req.Provider
+	mapping.Enabled = req.Enabled == nil || *req.Enabled
+	mapping.CustomerNo = req.CustomerNo
+	mapping.WarehouseName = strings.TrimSpace(req.WarehouseName)
+	mapping.BranchName = strings.TrimSpace(req.BranchName)
+	mapping.TaxIncluded = req.TaxIncluded
+	mapping.UpdatedBy = uint(userID)
+	if err := ac.DB.WithContext(c.Context()).Save(&mapping).Error; err != nil {
+		log.Println("SaveAccountingMapping - Failed to save mapping:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save accounting mapping",
+		})
+	}
+
+	// Reload mapping with its store and user for response
+	ac.DB.WithContext(c.Context()).Preload("Store").Preload("UpdateUser").Where("id = ?", mapping.ID).First(&mapping)
+
+	log.Println("SaveAccountingMapping completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Accounting mapping of store " + store.StoreName + " saved successfully",
+		Data:    mapping.ToResponse(),
+	})
+}
+
+// DeleteAccountingMapping removes the accounting mapping of a store
+// @Summary Delete Accounting Mapping
+// @Description Stop booking the completed orders of a store in the accounting system. Orders still queued fail until the store is mapped again and the syncs are retried
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param storeId path int true "Store ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/accounting/mappings/{storeId} [delete]
+func (ac *AccountingController) DeleteAccountingMapping(c fiber.Ctx) error {
+	log.Println("DeleteAccountingMapping called")
+	storeID := c.Params("storeId")
+
+	result := ac.DB.WithContext(c.Context()).Where("store_id = ?", storeID).Delete(&models.AccountingMapping{})
+	if result.Error != nil {
+		log.Println("DeleteAccountingMapping - Failed to delete mapping:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete accounting mapping",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store with id " + storeID + " has no accounting mapping.",
+		})
+	}
+
+	log.Println("DeleteAccountingMapping completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Accounting mapping deleted successfully",
+	})
+}
+
+// GetAccountingSyncs retrieves the accounting sync queue
+// @Summary Get Accounting Syncs
+// @Description Retrieve the completed orders queued for the accounting system with their status, attempts and last error, latest first, with pagination and filters
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of syncs per page" default(10)
+// @Param status query string false "Filter by status (pending, synced, failed)"
+// @Param provider query string false "Filter by provider (accurate, jurnal)"
+// @Param storeId query int false "Filter by store ID"
+// @Param search query string false "Search by order ginee ID or tracking number"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AccountingSyncResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/accounting/syncs [get]
+func (ac *AccountingController) GetAccountingSyncs(c fiber.Ctx) error {
+	log.Println("GetAccountingSyncs called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var syncs []models.AccountingSync
+
+	// Build base query
+	query := ac.DB.WithContext(c.Context()).Model(&models.AccountingSync{}).Preload("Order").Preload("Store").Order("created_at DESC, id DESC")
+
+	var filters []string
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		if status != models.AccountingSyncStatusPending && status != models.AccountingSyncStatusSynced && status != models.AccountingSyncStatusFailed {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status. Use pending, synced or failed.",
+			})
+		}
+		query = query.Where("status = ?", status)
+		filters = append(filters, "status: "+status)
+	}
+
+	// Provider filter if provided
+	provider := strings.ToLower(strings.TrimSpace(c.Query("provider", "")))
+	if provider != "" {
+		if !models.IsValidAccountingProvider(provider) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid provider. Use one of: " + strings.Join(models.AccountingProviders(), ", "),
+			})
+		}
+		query = query.Where("provider = ?", provider)
+		filters = append(filters, "provider: "+provider)
+	}
+
+	// Store filter if provided
+	storeID := strings.TrimSpace(c.Query("storeId", ""))
+	if storeID != "" {
+		if _, err := strconv.ParseUint(storeID, 10, 32); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid storeId",
+			})
+		}
+		query = query.Where("store_id = ?", storeID)
+		filters = append(filters, "store: "+storeID)
+	}
+
+	// Search by order identifiers if provided
+	search := models.NormalizeIdentifier(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("order_id IN (?)", ac.DB.Model(&models.Order{}).Select("id").
+			Where("order_ginee_id LIKE ? OR tracking_number LIKE ?", "%"+search+"%", "%"+search+"%"))
+		filters = append(filters, "search: "+search)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&syncs).Error; err != nil {
+		log.Println("GetAccountingSyncs - Failed to retrieve syncs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve accounting syncs",
+		})
+	}
+
+	// Format response
+	syncList := make([]models.AccountingSyncResponse, len(syncs))
+	for i, sync := range syncs {
+		syncList[i] = *sync.ToResponse()
+	}
+
+	// Build success message
+	message := "Accounting syncs retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetAccountingSyncs completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    syncList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetAccountingSyncDashboard retrieves the sync status per accounting system
+// @Summary Get Accounting Sync Dashboard
+// @Description Retrieve per accounting system whether its credentials are configured, the mapped stores, the queued, retrying, synced and failed orders, the oldest order still waiting and the last successful sync, with the latest failures
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=AccountingSyncDashboardResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/accounting/syncs/dashboard [get]
+func (ac *AccountingController) GetAccountingSyncDashboard(c fiber.Ctx) error {
+	log.Println("GetAccountingSyncDashboard called")
+	db := ac.DB.WithContext(c.Context())
+
+	var counts []struct {
+		Provider        string
+		Pending         int64
+		Retrying        int64
+		Synced          int64
+		Failed          int64
+		OldestPendingAt *time.Time
+		LastSyncedAt    *time.Time
+	}
+	if err := db.Model(&models.AccountingSync{}).
+		Select("provider, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS pending, "+
+			"COUNT(*) FILTER (WHERE status = ? AND attempts > 0) AS retrying, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS synced, "+
+			"COUNT(*) FILTER (WHERE status = ?) AS failed, "+
+			"MIN(created_at) FILTER (WHERE status = ?) AS oldest_pending_at, "+
+			"MAX(synced_at) AS last_synced_at",
+			models.AccountingSyncStatusPending, models.AccountingSyncStatusPending, models.AccountingSyncStatusSynced,
+			models.AccountingSyncStatusFailed, models.AccountingSyncStatusPending).
+		Group("provider").Scan(&counts).Error; err != nil {
+		log.Println("GetAccountingSyncDashboard - Failed to count syncs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve accounting sync status",
+		})
+	}
+
+	var mappedStores []struct {
+		Provider string
+		Stores   int64
+	}
+	if err := db.Model(&models.AccountingMapping{}).Select("provider, COUNT(*) AS stores").
+		Where("enabled = ?", true).Group("provider").Scan(&mappedStores).Error; err != nil {
+		log.Println("GetAccountingSyncDashboard - Failed to count mapped stores:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve accounting sync status",
+		})
+	}
+
+	formatTime := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		formatted := t.Format("02-01-2006 15:04:05")
+		return &formatted
+	}
+
+	providers := make([]AccountingProviderSyncStatus, len(models.AccountingProviders()))
+	for i, provider := range models.AccountingProviders() {
+		providers[i] = AccountingProviderSyncStatus{
+			Provider:   provider,
+			Configured: utils.IsAccountingProviderConfigured(ac.Config, provider),
+		}
+		for _, count := range counts {
+			if count.Provider == provider {
+				providers[i].Pending = count.Pending
+				providers[i].Retrying = count.Retrying
+				providers[i].Synced = count.Synced
+				providers[i].Failed = count.Failed
+				providers[i].OldestPendingAt = formatTime(count.OldestPendingAt)
+				providers[i].LastSyncedAt = formatTime(count.LastSyncedAt)
+			}
+		}
+		for _, mapped := range mappedStores {
+			if mapped.Provider == provider {
+				providers[i].MappedStores = mapped.Stores
+			}
+		}
+	}
+
+	var failures []models.AccountingSync
+	if err := db.Preload("Order").Preload("Store").
+		Where("status <> ? AND last_error <> ''", models.AccountingSyncStatusSynced).
+		Order("last_attempt_at DESC").Limit(10).Find(&failures).Error; err != nil {
+		log.Println("GetAccountingSyncDashboard - Failed to retrieve failures:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve accounting sync status",
+		})
+	}
+	failureList := make([]models.AccountingSyncResponse, len(failures))
+	for i, failure := range failures {
+		failureList[i] = *failure.ToResponse()
+	}
+
+	log.Println("GetAccountingSyncDashboard completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Accounting sync status retrieved successfully",
+		Data: AccountingSyncDashboardResponse{
+			WorkerEnabled:  ac.Config.AccountingSyncIntervalSeconds > 0,
+			Providers:      providers,
+			RecentFailures: failureList,
+		},
+	})
+}
+
+// RetryAccountingSync queues an order again for the accounting system
+// @Summary Retry Accounting Sync
+// @Description Queue a failed or pending order again for an immediate attempt with a fresh set of attempts, pushed to the accounting system the store is currently mapped to
+// @Tags Accounting
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Accounting Sync ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.AccountingSyncResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/accounting/syncs/{id}/retry [post]
+func (ac *AccountingController) RetryAccountingSync(c fiber.Ctx) error {
+	log.Println("RetryAccountingSync called")
+	id := c.Params("id")
+
+	var sync models.AccountingSync
+	if err := ac.DB.WithContext(c.Context()).Where("id = ?", id).First(&sync).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Accounting sync with id " + id + " not found.",
+		})
+	}
+
+	var mapping models.AccountingMapping
+	if err := ac.DB.WithContext(c.Context()).Where("store_id = ?", sync.StoreID).First(&mapping).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "The store of this order has no accounting mapping",
+		})
+	}
+
+	result := ac.DB.WithContext(c.Context()).Model(&models.AccountingSync{}).
+		Where("id = ? AND status <> ?", sync.ID, models.AccountingSyncStatusSynced).
+		Updates(map[string]interface{}{
+			"status":          models.AccountingSyncStatusPending,
+			"provider":        mapping.Provider,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		log.Println("RetryAccountingSync - Failed to queue sync:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retry accounting sync",
+		})
+	}
+	if result.RowsAffected == 0 {
+		log.Println("RetryAccountingSync - Sync already synced:", sync.ID)
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order is already booked in the accounting system",
+		})
+	}
+
+	// Reload sync with its order and store for response
+	ac.DB.WithContext(c.Context()).Preload("Order").Preload("Store").Where("id = ?", sync.ID).First(&sync)
+
+	log.Println("RetryAccountingSync completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Accounting sync queued for retry",
+		Data:    sync.ToResponse(),
+	})
+}
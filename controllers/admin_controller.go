@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"errors"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/utils"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type AdminController struct {
+	DB     *gorm.DB
+	Config *config.Config
+}
+
+func NewAdminController(cfg *config.Config, db *gorm.DB) *AdminController {
+	return &AdminController{DB: db, Config: cfg}
+}
+
+// Unique response structs
+// StuckEntitiesResponse represents the entities left in an inconsistent state, grouped by rule
+type StuckEntitiesResponse struct {
+	CheckedAt string                  `json:"checkedAt"`
+	Total     int64                   `json:"total"`
+	Rules     []utils.StuckRuleResult `json:"rules"`
+}
+
+// GetStuckEntities lists the entities left in an inconsistent state
+// @Summary Get Stuck Entities
+// @Description Run the rule checks for entities left in an inconsistent state: QC in progress for more than 24 hours (qc_in_progress), orders QC completed more than 48 hours ago without an outbound (qc_completed_without_outbound), both counted in warehouse business hours when configured, and outbounds matching no order (orphan_outbound). Each entity carries the suggested remediation endpoint. At most 200 entities are listed per rule, the count covers all of them
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param rule query string false "Only run one rule (qc_in_progress, qc_completed_without_outbound, orphan_outbound)"
+// @Success 200 {object} utils.SuccessResponse{data=StuckEntitiesResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/admin/stuck [get]
+func (ac *AdminController) GetStuckEntities(c fiber.Ctx) error {
+	log.Println("GetStuckEntities called")
+	rule := strings.TrimSpace(c.Query("rule", ""))
+	if rule != "" && !utils.IsStuckRule(rule) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid rule. Use " + strings.Join(utils.StuckRules, ", ") + ".",
+		})
+	}
+
+	now := time.Now()
+	results, err := utils.FindStuckEntities(ac.DB.WithContext(c.Context()), rule, now)
+	if err != nil {
+		log.Println("GetStuckEntities - Failed to run stuck entity checks:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to run stuck entity checks",
+		})
+	}
+
+	var total int64
+	for _, result := range results {
+		total += result.Count
+	}
+
+	message := "Stuck entities retrieved successfully"
+	if rule != "" {
+		message += " (filtered by rule: " + rule + ")"
+	}
+
+	log.Println("GetStuckEntities completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: StuckEntitiesResponse{
+			CheckedAt: now.Format("02-01-2006 15:04:05"),
+			Total:     total,
+			Rules:     results,
+		},
+	})
+}
+
+// GetTokenKeys lists the token encryption keys
+// @Summary Get Token Keys
+// @Description List the keys access and refresh tokens are encrypted with, without key material. New tokens use the current key, the other keys only validate tokens issued before the last rotation until their retirement time
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]utils.TokenKeyInfo}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/admin/token-keys [get]
+func (ac *AdminController) GetTokenKeys(c fiber.Ctx) error {
+	log.Println("GetTokenKeys called")
+	keys, err := utils.GetTokenKeys(ac.Config)
+	if err != nil {
+		log.Println("GetTokenKeys - Failed to load token keys:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load token keys",
+		})
+	}
+
+	log.Println("GetTokenKeys completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Token keys retrieved successfully (provider: " + ac.Config.TokenKeyProvider + ")",
+		Data:    keys,
+	})
+}
+
+// RotateTokenKey adds a new current token encryption key
+// @Summary Rotate Token Key
+// @Description Generate a new key for access and refresh tokens. Issued tokens stay valid, the previous key keeps validating them until the refresh token lifetime has passed. Only available with the file key provider, other instances sharing the key file pick up the new key on the first token they cannot validate
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=utils.TokenKeyInfo}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/admin/token-keys/rotate [post]
+func (ac *AdminController) RotateTokenKey(c fiber.Ctx) error {
+	log.Println("RotateTokenKey called")
+	key, err := utils.RotateTokenKeys(ac.Config)
+	if err != nil {
+		if errors.Is(err, utils.ErrTokenKeyRotationUnsupported) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+		log.Println("RotateTokenKey - Failed to rotate token key:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to rotate token key",
+		})
+	}
+
+	log.Println("RotateTokenKey completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Token key rotated successfully",
+		Data:    key,
+	})
+}
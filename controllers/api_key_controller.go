@@ -0,0 +1,675 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type APIKeyController struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyController(db *gorm.DB) *APIKeyController {
+	return &APIKeyController{DB: db}
+}
+
+// Request structs
+type CreateAPIKeyRequest struct {
+	Name               string   `json:"name" validate:"required,max=100" example:"ERP integration"`
+	Description        string   `json:"description" example:"Order import from the ERP"`
+	Scopes             []string `json:"scopes" validate:"required,min=1" example:"orders:read,outbounds:write"`
+	RateLimitPerMinute int      `json:"rateLimitPerMinute" example:"60"`
+	ExpiresAt          string   `json:"expiresAt" example:"2026-12-31"` // optional, YYYY-MM-DD
+}
+
+type UpdateAPIKeyRequest struct {
+	Name               *string  `json:"name,omitempty"`
+	Description        *string  `json:"description,omitempty"`
+	Scopes             []string `json:"scopes,omitempty"`
+	RateLimitPerMinute *int     `json:"rateLimitPerMinute,omitempty"`
+}
+
+// Unique response structs
+// APIKeySecretResponse represents an API key together with its plain key, only returned on creation and rotation
+type APIKeySecretResponse struct {
+	Key    string                 `json:"key"`
+	APIKey *models.APIKeyResponse `json:"apiKey"`
+}
+
+// APIKeyUsageStatsResponse represents the usage of an API key
+type APIKeyUsageStatsResponse struct {
+	APIKey             *models.APIKeyResponse       `json:"apiKey"`
+	CurrentMinuteUsage int                          `json:"currentMinuteUsage"`
+	TotalRequests      int64                        `json:"totalRequests"`
+	TotalRateLimited   int64                        `json:"totalRateLimited"`
+	TotalForbidden     int64                        `json:"totalForbidden"`
+	Daily              []models.APIKeyUsageResponse `json:"daily"`
+}
+
+// normalizeAPIKeyScopes validates scopes against the known resources and returns them deduplicated
+func normalizeAPIKeyScopes(scopes []string) (string, error) {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		parts := strings.Split(scope, ":")
+		if len(parts) != 2 || (parts[1] != "read" && parts[1] != "write") {
+			return "", fmt.Errorf("invalid scope %q, use resource:read or resource:write", scope)
+		}
+		known := false
+		for _, resource := range models.APIKeyScopeResources {
+			if parts[0] == resource {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return "", fmt.Errorf("unknown scope resource %q, use one of %s", parts[0], strings.Join(models.APIKeyScopeResources, ", "))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	if len(normalized) == 0 {
+		return "", fmt.Errorf("at least one scope is required")
+	}
+	return strings.Join(normalized, ","), nil
+}
+
+// loadAPIKey loads an API key by id with its users
+func (akc *APIKeyController) loadAPIKey(id string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := akc.DB.Preload("CreateUser").Preload("RevokeUser").Where("id = ?", id).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAPIKeys retrieves a list of API keys with pagination and filters
+// @Summary Get API Keys
+// @Description Retrieve a list of API keys issued to external systems. Plain keys are never returned.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param status query string false "Filter by status (active, revoked)"
+// @Param search query string false "Search by name or key prefix"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.APIKeyResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/api-keys [get]
+func (akc *APIKeyController) GetAPIKeys(c fiber.Ctx) error {
+	log.Println("GetAPIKeys called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var keys []models.APIKey
+
+	// Build base query
+	query := akc.DB.Model(&models.APIKey{}).Preload("CreateUser").Preload("RevokeUser").Order("created_at DESC")
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		if status != "active" && status != "revoked" {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status. Use active or revoked.",
+			})
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("name ILIKE ? OR key_prefix ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&keys).Error; err != nil {
+		log.Println("GetAPIKeys - Failed to retrieve API keys:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve API keys",
+		})
+	}
+
+	keyList := make([]models.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		keyList[i] = *key.ToResponse()
+	}
+
+	// Build success message
+	message := "API keys retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetAPIKeys completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    keyList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetAPIKey retrieves a single API key
+// @Summary Get API Key
+// @Description Retrieve a single API key by ID
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API Key ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.APIKeyResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/api-keys/{id} [get]
+func (akc *APIKeyController) GetAPIKey(c fiber.Ctx) error {
+	log.Println("GetAPIKey called")
+	// Parse id parameter
+	id := c.Params("id")
+	key, err := akc.loadAPIKey(id)
+	if err != nil {
+		log.Println("GetAPIKey - API key not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "API key with id " + id + " not found",
+		})
+	}
+
+	log.Println("GetAPIKey completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "API key retrieved successfully",
+		Data:    key.ToResponse(),
+	})
+}
+
+// CreateAPIKey issues a new API key
+// @Summary Create API Key
+// @Description Issue an API key for an external system. The key acts on behalf of the creating user, limited to its scopes. The plain key is only returned once.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateAPIKeyRequest true "API key details"
+// @Success 201 {object} utils.SuccessResponse{data=APIKeySecretResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/api-keys [post]
+func (akc *APIKeyController) CreateAPIKey(c fiber.Ctx) error {
+	log.Println("CreateAPIKey called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CreateAPIKey - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req CreateAPIKeyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateAPIKey - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Name is required",
+		})
+	}
+
+	scopes, err := normalizeAPIKeyScopes(req.Scopes)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	if req.RateLimitPerMinute < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Rate limit per minute must not be negative",
+		})
+	}
+	if req.RateLimitPerMinute == 0 {
+		req.RateLimitPerMinute = models.DefaultAPIKeyRateLimit
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresAt != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", req.ExpiresAt, time.Local)
+		if err != nil || !parsed.After(time.Now()) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid expiresAt. Use a future date in YYYY-MM-DD format.",
+			})
+		}
+		expiresAt = &parsed
+	}
+
+	plainKey, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		log.Println("CreateAPIKey - Failed to generate API key:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate API key",
+		})
+	}
+
+	key := models.APIKey{
+		Name:               req.Name,
+		Description:        strings.TrimSpace(req.Description),
+		KeyPrefix:          prefix,
+		KeyHash:            hash,
+		Scopes:             scopes,
+		RateLimitPerMinute: req.RateLimitPerMinute,
+		Status:             "active",
+		ExpiresAt:          expiresAt,
+		CreatedBy:          uint(userID),
+	}
+	if err := akc.DB.Create(&key).Error; err != nil {
+		log.Println("CreateAPIKey - Failed to create API key:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create API key",
+		})
+	}
+
+	created, err := akc.loadAPIKey(strconv.FormatUint(uint64(key.ID), 10))
+	if err != nil {
+		log.Println("CreateAPIKey - Failed to load API key:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load API key",
+		})
+	}
+
+	log.Println("CreateAPIKey completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "API key created successfully. Store the key now, it will not be shown again.",
+		Data: APIKeySecretResponse{
+			Key:    plainKey,
+			APIKey: created.ToResponse(),
+		},
+	})
+}
+
+// UpdateAPIKey updates the name, scopes or rate limit of an API key
+// @Summary Update API Key
+// @Description Update the name, description, scopes or rate limit of an active API key
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API Key ID"
+// @Param request body UpdateAPIKeyRequest true "API key changes"
+// @Success 200 {object} utils.SuccessResponse{data=models.APIKeyResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/api-keys/{id} [put]
+func (akc *APIKeyController) UpdateAPIKey(c fiber.Ctx) error {
+	log.Println("UpdateAPIKey called")
+	// Parse id parameter
+	id := c.Params("id")
+	key, err := akc.loadAPIKey(id)
+	if err != nil {
+		log.Println("UpdateAPIKey - API key not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "API key with id " + id + " not found",
+		})
+	}
+
+	if key.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Only active API keys can be updated",
+		})
+	}
+
+	// Binding request body
+	var req UpdateAPIKeyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateAPIKey - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Name must not be empty",
+			})
+		}
+		updates["name"] = name
+	}
+	if req.Description != nil {
+		updates["description"] = strings.TrimSpace(*req.Description)
+	}
+	if req.Scopes != nil {
+		scopes, err := normalizeAPIKeyScopes(req.Scopes)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+		updates["scopes"] = scopes
+	}
+	if req.RateLimitPerMinute != nil {
+		if *req.RateLimitPerMinute <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Rate limit per minute must be greater than 0",
+			})
+		}
+		updates["rate_limit_per_minute"] = *req.RateLimitPerMinute
+	}
+
+	if len(updates) > 0 {
+		if err := akc.DB.Model(key).Updates(updates).Error; err != nil {
+			log.Println("UpdateAPIKey - Failed to update API key:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to update API key",
+			})
+		}
+	}
+
+	updated, err := akc.loadAPIKey(id)
+	if err != nil {
+		log.Println("UpdateAPIKey - Failed to load API key:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load API key",
+		})
+	}
+
+	log.Println("UpdateAPIKey completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "API key updated successfully",
+		Data:    updated.ToResponse(),
+	})
+}
+
+// RotateAPIKey replaces the secret of an API key
+// @Summary Rotate API Key
+// @Description Issue a new secret for an active API key keeping its scopes and usage history. The previous key stops working immediately. The plain key is only returned once.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API Key ID"
+// @Success 200 {object} utils.SuccessResponse{data=APIKeySecretResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/api-keys/{id}/rotate [post]
+func (akc *APIKeyController) RotateAPIKey(c fiber.Ctx) error {
+	log.Println("RotateAPIKey called")
+	// Parse id parameter
+	id := c.Params("id")
+	key, err := akc.loadAPIKey(id)
+	if err != nil {
+		log.Println("RotateAPIKey - API key not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "API key with id " + id + " not found",
+		})
+	}
+
+	if key.Status != "active" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Only active API keys can be rotated",
+		})
+	}
+
+	plainKey, prefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		log.Println("RotateAPIKey - Failed to generate API key:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate API key",
+		})
+	}
+
+	result := akc.DB.Model(&models.APIKey{}).Where("id = ? AND status = ?", key.ID, "active").Updates(map[string]interface{}{
+		"key_prefix": prefix,
+		"key_hash":   hash,
+		"rotated_at": time.Now(),
+	})
+	if result.Error != nil {
+		log.Println("RotateAPIKey - Failed to rotate API key:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to rotate API key",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Only active API keys can be rotated",
+		})
+	}
+	utils.ResetAPIKeyLimit(key.ID)
+
+	rotated, err := akc.loadAPIKey(id)
+	if err != nil {
+		log.Println("RotateAPIKey - Failed to load API key:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load API key",
+		})
+	}
+
+	log.Println("RotateAPIKey completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "API key rotated successfully. Store the new key now, it will not be shown again.",
+		Data: APIKeySecretResponse{
+			Key:    plainKey,
+			APIKey: rotated.ToResponse(),
+		},
+	})
+}
+
+// RevokeAPIKey permanently disables an API key
+// @Summary Revoke API Key
+// @Description Permanently disable an API key. Requests made with it are rejected immediately.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API Key ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.APIKeyResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/api-keys/{id} [delete]
+func (akc *APIKeyController) RevokeAPIKey(c fiber.Ctx) error {
+	log.Println("RevokeAPIKey called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("RevokeAPIKey - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	key, err := akc.loadAPIKey(id)
+	if err != nil {
+		log.Println("RevokeAPIKey - API key not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "API key with id " + id + " not found",
+		})
+	}
+
+	revokedBy := uint(userID)
+	now := time.Now()
+	result := akc.DB.Model(&models.APIKey{}).Where("id = ? AND status = ?", key.ID, "active").Updates(map[string]interface{}{
+		"status":     "revoked",
+		"revoked_by": revokedBy,
+		"revoked_at": now,
+	})
+	if result.Error != nil {
+		log.Println("RevokeAPIKey - Failed to revoke API key:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to revoke API key",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "API key is already revoked",
+		})
+	}
+	utils.ResetAPIKeyLimit(key.ID)
+
+	revoked, err := akc.loadAPIKey(id)
+	if err != nil {
+		log.Println("RevokeAPIKey - Failed to load API key:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load API key",
+		})
+	}
+
+	log.Println("RevokeAPIKey completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "API key revoked successfully",
+		Data:    revoked.ToResponse(),
+	})
+}
+
+// GetAPIKeyUsage retrieves the usage statistics of an API key
+// @Summary Get API Key Usage
+// @Description Retrieve daily request, rate limited and forbidden counts of an API key and its usage in the current minute
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API Key ID"
+// @Param days query int false "Number of days of history" default(30)
+// @Success 200 {object} utils.SuccessResponse{data=APIKeyUsageStatsResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/api-keys/{id}/usage [get]
+func (akc *APIKeyController) GetAPIKeyUsage(c fiber.Ctx) error {
+	log.Println("GetAPIKeyUsage called")
+	// Parse id parameter
+	id := c.Params("id")
+	key, err := akc.loadAPIKey(id)
+	if err != nil {
+		log.Println("GetAPIKeyUsage - API key not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "API key with id " + id + " not found",
+		})
+	}
+
+	days, err := strconv.Atoi(c.Query("days", "30"))
+	if err != nil || days < 1 || days > 366 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid days. Use a number between 1 and 366.",
+		})
+	}
+
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(days - 1))
+
+	var usages []models.APIKeyUsage
+	if err := akc.DB.Where("api_key_id = ? AND date >= ?", key.ID, since).Order("date ASC").Find(&usages).Error; err != nil {
+		log.Println("GetAPIKeyUsage - Failed to retrieve API key usage:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve API key usage",
+		})
+	}
+
+	response := APIKeyUsageStatsResponse{
+		APIKey:             key.ToResponse(),
+		CurrentMinuteUsage: utils.GetAPIKeyCurrentUsage(key.ID, now),
+		Daily:              make([]models.APIKeyUsageResponse, len(usages)),
+	}
+	for i, usage := range usages {
+		response.Daily[i] = usage.ToResponse()
+		response.TotalRequests += usage.Requests
+		response.TotalRateLimited += usage.RateLimited
+		response.TotalForbidden += usage.Forbidden
+	}
+
+	log.Println("GetAPIKeyUsage completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("API key usage of the last %d days retrieved successfully", days),
+		Data:    response,
+	})
+}
@@ -0,0 +1,628 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// Delegations are meant to cover a coordinator's absence during a shift, never longer than a day
+const (
+	minApprovalDelegationMinutes = 15
+	maxApprovalDelegationMinutes = 24 * 60
+)
+
+// approvalDelegateRole is the role a coordinator may delegate approval rights to
+const approvalDelegateRole = "picker"
+
+type ApprovalController struct {
+	DB     *gorm.DB
+	Config *config.Config
+}
+
+func NewApprovalController(cfg *config.Config, db *gorm.DB) *ApprovalController {
+	return &ApprovalController{Config: cfg, DB: db}
+}
+
+// Request structs
+type CreateApprovalDelegationRequest struct {
+	DelegateID      uint   `json:"delegateId" validate:"required" example:"12"`
+	DurationMinutes int    `json:"durationMinutes" validate:"required,min=15,max=1440" example:"240"`
+	Reason          string `json:"reason" example:"Coordinator at the supplier meeting until 14:00"`
+}
+
+type CreateApprovalCodeRequest struct {
+	Action string `json:"action" example:"pending_pick"` // empty allows any approval action
+}
+
+// Unique response structs
+type ApprovalCodeCreatedResponse struct {
+	ID        uint   `json:"id"`
+	Code      string `json:"code" example:"482913"` // shown once, only its hash is stored
+	Action    string `json:"action,omitempty"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// approvalCodeActions are the actions a one-time approval code can be limited to
+var approvalCodeActions = []string{"pending_pick"}
+
+// isUnscopedApprover reports whether the roles see every delegation and approval code
+func isUnscopedApprover(roles []string) bool {
+	for _, role := range roles {
+		if role == "developer" || role == "superadmin" {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateApprovalDelegation grants a senior picker the right to approve on behalf of the current coordinator
+// @Summary Create Approval Delegation
+// @Description Grant a picker the right to approve pending picks on behalf of the current coordinator for a limited time. The delegation expires automatically.
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateApprovalDelegationRequest true "Delegation data"
+// @Success 201 {object} utils.SuccessResponse{data=models.ApprovalDelegationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/approvals/delegations [post]
+func (apc *ApprovalController) CreateApprovalDelegation(c fiber.Ctx) error {
+	log.Println("CreateApprovalDelegation called")
+	// Binding request body
+	var req CreateApprovalDelegationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateApprovalDelegation - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if req.DurationMinutes < minApprovalDelegationMinutes || req.DurationMinutes > maxApprovalDelegationMinutes {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("durationMinutes must be between %d and %d", minApprovalDelegationMinutes, maxApprovalDelegationMinutes),
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	if uint(userID) == req.DelegateID {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Approval rights cannot be delegated to yourself",
+		})
+	}
+
+	// Check if the delegate exists and is an active picker
+	var delegate models.User
+	if err := apc.DB.Preload("Roles").Where("id = ?", req.DelegateID).First(&delegate).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User with id " + strconv.FormatUint(uint64(req.DelegateID), 10) + " does not exist.",
+		})
+	}
+	isPicker := false
+	for _, role := range delegate.Roles {
+		if role.RoleName == approvalDelegateRole {
+			isPicker = true
+			break
+		}
+	}
+	if !isPicker || !delegate.IsActive {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Approval rights can only be delegated to an active picker",
+		})
+	}
+
+	// Coordinators with a team only delegate to members of their teams
+	userRoles, _ := c.Locals("userRoles").([]string)
+	canManage, err := utils.CanManageUser(apc.DB, uint(userID), userRoles, delegate.ID)
+	if err != nil {
+		log.Println("CreateApprovalDelegation - Failed to resolve team scope:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create approval delegation",
+		})
+	}
+	if !canManage {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Picker " + delegate.Username + " is not a member of your team.",
+		})
+	}
+
+	// A picker holds at most one active delegation so every approval maps to a single delegator
+	now := time.Now()
+	var active models.ApprovalDelegation
+	if err := apc.DB.Where("delegate_id = ? AND revoked_at IS NULL AND expires_at > ?", delegate.ID, now).First(&active).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Picker " + delegate.Username + " already holds an active approval delegation until " + active.ExpiresAt.Format("02-01-2006 15:04:05"),
+		})
+	}
+
+	delegation := models.ApprovalDelegation{
+		DelegatorID: uint(userID),
+		DelegateID:  delegate.ID,
+		Reason:      strings.TrimSpace(req.Reason),
+		ExpiresAt:   now.Add(time.Duration(req.DurationMinutes) * time.Minute),
+	}
+	err = apc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&delegation).Error; err != nil {
+			return err
+		}
+		return utils.NotifyUsers(tx, []uint{delegate.ID}, "approval_delegation", "Approval rights delegated to you",
+			"You can approve on behalf of your coordinator until "+delegation.ExpiresAt.Format("02-01-2006 15:04:05"), "approval_delegation", delegation.ID)
+	})
+	if err != nil {
+		log.Println("CreateApprovalDelegation - Failed to create approval delegation:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create approval delegation",
+		})
+	}
+
+	// Reload the data with fresh query
+	if err := apc.DB.Preload("Delegator").Preload("Delegate").Preload("RevokeUser").First(&delegation, delegation.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load approval delegation",
+		})
+	}
+
+	log.Println("CreateApprovalDelegation completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Approval rights delegated to " + delegate.FullName + " until " + delegation.ExpiresAt.Format("02-01-2006 15:04:05"),
+		Data:    delegation.ToResponse(),
+	})
+}
+
+// GetApprovalDelegations retrieves approval delegations
+// @Summary Get Approval Delegations
+// @Description Retrieve approval delegations with pagination. Coordinators see the delegations they granted, developers and superadmins see all.
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of delegations per page" default(10)
+// @Param active query bool false "Only active delegations" default(false)
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ApprovalDelegationResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/approvals/delegations [get]
+func (apc *ApprovalController) GetApprovalDelegations(c fiber.Ctx) error {
+	log.Println("GetApprovalDelegations called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var delegations []models.ApprovalDelegation
+
+	// Build base query
+	query := apc.DB.Model(&models.ApprovalDelegation{}).Preload("Delegator").Preload("Delegate").Preload("RevokeUser").Order("created_at DESC")
+
+	// Coordinators only see their own delegations
+	userRoles, _ := c.Locals("userRoles").([]string)
+	if !isUnscopedApprover(userRoles) {
+		query = query.Where("delegator_id = ?", c.Locals("userId").(string))
+	}
+
+	// Active filter if provided
+	active := c.Query("active", "false") == "true"
+	if active {
+		query = query.Where("revoked_at IS NULL AND expires_at > ?", time.Now())
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Offset(offset).Limit(limit).Find(&delegations).Error; err != nil {
+		log.Println("GetApprovalDelegations - Failed to retrieve approval delegations:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve approval delegations",
+		})
+	}
+
+	// Format response
+	delegationList := make([]models.ApprovalDelegationResponse, len(delegations))
+	for i, delegation := range delegations {
+		delegationList[i] = *delegation.ToResponse()
+	}
+
+	// Build success message
+	message := "Approval delegations retrieved successfully"
+	if active {
+		message += " (filtered by active)"
+	}
+
+	log.Println("GetApprovalDelegations completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    delegationList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// RevokeApprovalDelegation ends an approval delegation before it expires
+// @Summary Revoke Approval Delegation
+// @Description End an approval delegation before it expires, e.g. when the coordinator is back on site
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Delegation ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.ApprovalDelegationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/approvals/delegations/{id}/revoke [put]
+func (apc *ApprovalController) RevokeApprovalDelegation(c fiber.Ctx) error {
+	log.Println("RevokeApprovalDelegation called")
+	// Parse id parameter
+	id := c.Params("id")
+	var delegation models.ApprovalDelegation
+	if err := apc.DB.Where("id = ?", id).First(&delegation).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Approval delegation with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Only the delegator, developers and superadmins can revoke
+	userRoles, _ := c.Locals("userRoles").([]string)
+	if delegation.DelegatorID != uint(userID) && !isUnscopedApprover(userRoles) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Only the delegating coordinator can revoke this delegation",
+		})
+	}
+
+	now := time.Now()
+	if !delegation.IsActive(now) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Approval delegation is already expired or revoked",
+		})
+	}
+
+	userIDUint := uint(userID)
+	delegation.RevokedBy = &userIDUint
+	delegation.RevokedAt = &now
+	if err := apc.DB.Select("RevokedBy", "RevokedAt").Save(&delegation).Error; err != nil {
+		log.Println("RevokeApprovalDelegation - Failed to revoke approval delegation:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to revoke approval delegation",
+		})
+	}
+
+	// Reload the data with fresh query
+	if err := apc.DB.Preload("Delegator").Preload("Delegate").Preload("RevokeUser").First(&delegation, delegation.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load approval delegation",
+		})
+	}
+
+	log.Println("RevokeApprovalDelegation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Approval delegation revoked successfully",
+		Data:    delegation.ToResponse(),
+	})
+}
+
+// GetApprovals retrieves the audit trail of approvals
+// @Summary Get Approvals
+// @Description Retrieve the audit trail of approvals with the approver, the worker who requested it and the coordinator a delegate acted for
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of approvals per page" default(10)
+// @Param action query string false "Filter by action, e.g. pending_pick"
+// @Param method query string false "Filter by approval method"
+// @Param userId query int false "Filter by approver, requester or delegator user ID"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ApprovalResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/approvals [get]
+func (apc *ApprovalController) GetApprovals(c fiber.Ctx) error {
+	log.Println("GetApprovals called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var approvals []models.Approval
+
+	// Build base query
+	query := apc.DB.Model(&models.Approval{}).Preload("RequestUser").Preload("ApproveUser").Preload("Delegator").Order("created_at DESC")
+
+	// Filters if provided
+	action := strings.TrimSpace(c.Query("action", ""))
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+	method := strings.TrimSpace(c.Query("method", ""))
+	if method != "" {
+		query = query.Where("method = ?", method)
+	}
+	userID := strings.TrimSpace(c.Query("userId", ""))
+	if userID != "" {
+		query = query.Where("approved_by = ? OR requested_by = ? OR delegator_id = ?", userID, userID, userID)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Offset(offset).Limit(limit).Find(&approvals).Error; err != nil {
+		log.Println("GetApprovals - Failed to retrieve approvals:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve approvals",
+		})
+	}
+
+	// Format response
+	approvalList := make([]models.ApprovalResponse, len(approvals))
+	for i, approval := range approvals {
+		approvalList[i] = *approval.ToResponse()
+	}
+
+	// Build success message
+	message := "Approvals retrieved successfully"
+	var filters []string
+
+	if action != "" {
+		filters = append(filters, "action: "+action)
+	}
+	if method != "" {
+		filters = append(filters, "method: "+method)
+	}
+	if userID != "" {
+		filters = append(filters, "userId: "+userID)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetApprovals completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    approvalList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateApprovalCode generates a one-time approval code for the current coordinator
+// @Summary Create Approval Code
+// @Description Generate a short-lived single-use numeric code the coordinator reads out to a worker instead of typing their password on the worker's phone. The code is returned once and only its hash is stored.
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateApprovalCodeRequest false "Optional action the code is limited to"
+// @Success 201 {object} utils.SuccessResponse{data=ApprovalCodeCreatedResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/approvals/codes [post]
+func (apc *ApprovalController) CreateApprovalCode(c fiber.Ctx) error {
+	log.Println("CreateApprovalCode called")
+	// Binding request body, the body is optional
+	var req CreateApprovalCodeRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			log.Println("CreateApprovalCode - Invalid request body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid request body",
+			})
+		}
+	}
+
+	req.Action = strings.TrimSpace(req.Action)
+	if req.Action != "" {
+		validAction := false
+		for _, action := range approvalCodeActions {
+			if req.Action == action {
+				validAction = true
+				break
+			}
+		}
+		if !validAction {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid action. Use one of: " + strings.Join(approvalCodeActions, ", "),
+			})
+		}
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Codes are short, regenerate on the rare collision with another usable code
+	now := time.Now()
+	var code, codeHash string
+	for attempt := 0; attempt < 5 && code == ""; attempt++ {
+		candidate, err := utils.GenerateOTP(utils.ApprovalCodeDigits)
+		if err != nil {
+			log.Println("CreateApprovalCode - Failed to generate approval code:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate approval code",
+			})
+		}
+		candidateHash := utils.HashSecureToken(candidate)
+
+		var count int64
+		if err := apc.DB.Model(&models.ApprovalCode{}).Where("code_hash = ? AND used_at IS NULL AND expires_at > ?", candidateHash, now).Count(&count).Error; err != nil {
+			log.Println("CreateApprovalCode - Failed to check approval code:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate approval code",
+			})
+		}
+		if count == 0 {
+			code, codeHash = candidate, candidateHash
+		}
+	}
+	if code == "" {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate approval code",
+		})
+	}
+
+	approvalCode := models.ApprovalCode{
+		IssuedBy:  uint(userID),
+		CodeHash:  codeHash,
+		Action:    req.Action,
+		ExpiresAt: now.Add(time.Duration(apc.Config.ApprovalCodeTTLMinutes) * time.Minute),
+	}
+	if err := apc.DB.Create(&approvalCode).Error; err != nil {
+		log.Println("CreateApprovalCode - Failed to create approval code:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate approval code",
+		})
+	}
+
+	log.Println("CreateApprovalCode completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Approval code generated, valid for %d minutes and a single use", apc.Config.ApprovalCodeTTLMinutes),
+		Data: ApprovalCodeCreatedResponse{
+			ID:        approvalCode.ID,
+			Code:      code,
+			Action:    approvalCode.Action,
+			ExpiresAt: approvalCode.ExpiresAt.Format("02-01-2006 15:04:05"),
+		},
+	})
+}
+
+// GetApprovalCodes retrieves the one-time approval codes and who used them
+// @Summary Get Approval Codes
+// @Description Retrieve one-time approval codes with their status and who used them. Coordinators see the codes they generated, developers and superadmins see all. Codes themselves are never returned.
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of codes per page" default(10)
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ApprovalCodeResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/approvals/codes [get]
+func (apc *ApprovalController) GetApprovalCodes(c fiber.Ctx) error {
+	log.Println("GetApprovalCodes called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var approvalCodes []models.ApprovalCode
+
+	// Build base query
+	query := apc.DB.Model(&models.ApprovalCode{}).Preload("IssueUser").Preload("UseUser").Order("created_at DESC")
+
+	// Coordinators only see their own codes
+	userRoles, _ := c.Locals("userRoles").([]string)
+	if !isUnscopedApprover(userRoles) {
+		query = query.Where("issued_by = ?", c.Locals("userId").(string))
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Offset(offset).Limit(limit).Find(&approvalCodes).Error; err != nil {
+		log.Println("GetApprovalCodes - Failed to retrieve approval codes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve approval codes",
+		})
+	}
+
+	// Format response
+	codeList := make([]models.ApprovalCodeResponse, len(approvalCodes))
+	for i, approvalCode := range approvalCodes {
+		codeList[i] = *approvalCode.ToResponse()
+	}
+
+	log.Println("GetApprovalCodes completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: "Approval codes retrieved successfully",
+		Data:    codeList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
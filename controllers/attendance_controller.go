@@ -1,11 +1,16 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"livo-fiber-backend/config"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
+	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,13 +19,21 @@ import (
 	"gorm.io/gorm"
 )
 
+// kioskLocationID is the location kiosk face check-ins are recorded at
+const kioskLocationID uint = 1
+
 type AttendanceController struct {
 	DB *gorm.DB
+	// Minimum confidence of the face matches accepted at the kiosk
+	FaceThreshold utils.FaceThresholdPolicy
+	// Break duration allowed per attendance
+	Breaks utils.BreakPolicy
+	// Limits of the batch face check-ins at the kiosk
+	FaceBatch utils.FaceBatchPolicy
 }
 
-func NewAttendanceController(db *gorm.DB) *AttendanceController {
-	return &AttendanceController{DB: db}
-
+func NewAttendanceController(cfg *config.Config, db *gorm.DB) *AttendanceController {
+	return &AttendanceController{DB: db, FaceThreshold: utils.FaceThresholdPolicyFromConfig(cfg), Breaks: utils.BreakPolicyFromConfig(cfg), FaceBatch: utils.FaceBatchPolicyFromConfig(cfg)}
 }
 
 // Request structs
@@ -34,6 +47,38 @@ type CheckOutManualRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type ReviewOvertimeRequest struct {
+	Action  string `json:"action" validate:"required,oneof=approve adjust reject" example:"adjust"`
+	Minutes *int   `json:"minutes" validate:"omitempty,min=0" example:"45"` // required to adjust
+	Note    string `json:"note" example:"Stayed for the stock take until 17:45"`
+}
+
+type ExportAttendancesRequest struct {
+	Template  string `json:"template" validate:"required,oneof=raw payroll compliance" example:"compliance"`
+	StartDate string `json:"startDate" validate:"required" example:"2026-10-01"` // YYYY-MM-DD
+	EndDate   string `json:"endDate" validate:"required" example:"2026-10-31"`   // YYYY-MM-DD
+	TeamID    uint   `json:"teamId" example:"1"`
+}
+
+type BreakManualRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type ReviewFallbackAttendanceRequest struct {
+	Action string `json:"action" validate:"required,oneof=verify reject" example:"verify"`
+	Note   string `json:"note" example:"Confirmed on CCTV"`
+}
+
+type BackfillAttendancesRequest struct {
+	UserIDs           []uint `json:"userIds" validate:"required,min=1" example:"3,7,12"`
+	Date              string `json:"date" validate:"required" example:"2026-10-15"` // YYYY-MM-DD
+	CheckIn           string `json:"checkIn" validate:"required" example:"07:55"`   // HH:MM
+	CheckOut          string `json:"checkOut" example:"17:00"`                      // HH:MM, empty leaves the attendances open
+	IncidentReference string `json:"incidentReference" validate:"required,max=100" example:"INC-2026-041"`
+	Note              string `json:"note" example:"Face recognition server down from 07:30 to 08:45"`
+}
+
 // Unique response structs
 type CheckInResponse struct {
 	Matched    bool                       `json:"matched" example:"true"`
@@ -45,6 +90,22 @@ type CheckInResponse struct {
 	Late       int                        `json:"late" example:"2"`
 }
 
+// BatchCheckInFaceResult is the check-in result of one image of a batch
+type BatchCheckInFaceResult struct {
+	Index    int              `json:"index" example:"0"`
+	Filename string           `json:"filename" example:"kiosk-frame-0.jpg"`
+	Success  bool             `json:"success" example:"true"`
+	Error    string           `json:"error,omitempty" example:"Face not recognized"`
+	CheckIn  *CheckInResponse `json:"checkIn,omitempty"`
+}
+
+type BatchCheckInResponse struct {
+	Total     int                      `json:"total" example:"40"`
+	CheckedIn int                      `json:"checkedIn" example:"38"`
+	Failed    int                      `json:"failed" example:"2"`
+	Results   []BatchCheckInFaceResult `json:"results"`
+}
+
 type CheckInManualResponse struct {
 	Matched    bool                       `json:"matched" example:"true"`
 	User       *models.UserResponse       `json:"user"`
@@ -71,6 +132,80 @@ type CheckOutManualResponse struct {
 	Overtime   int                        `json:"overtime" example:"30"`
 }
 
+type BreakResponse struct {
+	User       *models.UserResponse           `json:"user"`
+	Attendance *models.AttendanceResponse     `json:"attendance"`
+	Break      models.AttendanceBreakResponse `json:"break"`
+}
+
+// KioskSummaryResponse represents today's attendance overview shown on the lobby kiosk
+type KioskSummaryResponse struct {
+	Date         string            `json:"date" example:"16-10-2026"`
+	CheckedIn    int               `json:"checkedIn"`  // everyone who checked in today
+	CheckedOut   int               `json:"checkedOut"` // of those, who already checked out
+	InBuilding   int               `json:"inBuilding"` // checked in and not checked out yet
+	LateCount    int               `json:"lateCount"`
+	Attendances  []KioskAttendance `json:"attendances"` // latest check in first
+	LateArrivals []KioskAttendance `json:"lateArrivals"`
+	GeneratedAt  string            `json:"generatedAt"`
+}
+
+// KioskAttendance represents one person on the kiosk attendance board
+type KioskAttendance struct {
+	UserID     uint    `json:"userId"`
+	FullName   string  `json:"fullName"`
+	Status     string  `json:"status"`
+	Late       int     `json:"late"` // in minutes
+	CheckedIn  string  `json:"checkedIn"`
+	CheckedOut *string `json:"checkedOut,omitempty"`
+	InBuilding bool    `json:"inBuilding"`
+}
+
+// FaceStatusResponse tells the kiosk whether face recognition works or manual entry should be offered
+type FaceStatusResponse struct {
+	Available      bool   `json:"available"`
+	ManualFallback bool   `json:"manualFallback"` // manual check-in and check-out are recorded as fallback entries
+	Error          string `json:"error,omitempty"`
+	CheckedAt      string `json:"checkedAt"`
+}
+
+// BackfillAttendanceResult is the backfill outcome of one requested user
+type BackfillAttendanceResult struct {
+	UserID     uint                       `json:"userId"`
+	Result     string                     `json:"result"` // created, completed or skipped
+	Reason     string                     `json:"reason,omitempty"`
+	Attendance *models.AttendanceResponse `json:"attendance,omitempty"`
+}
+
+// BackfillAttendancesResponse is a recorded attendance backfill with the outcome per user
+type BackfillAttendancesResponse struct {
+	Backfill *models.AttendanceBackfillResponse `json:"backfill"`
+	Results  []BackfillAttendanceResult         `json:"results"`
+}
+
+// AttendanceSummaryResponse is the attendance and approved overtime per user over a period, used for payroll
+type AttendanceSummaryResponse struct {
+	StartDate string                 `json:"startDate" example:"2026-10-01"`
+	EndDate   string                 `json:"endDate" example:"2026-10-16"`
+	Users     []AttendanceSummaryRow `json:"users"`
+}
+
+// AttendanceSummaryRow is the attendance of one user over the summary period
+type AttendanceSummaryRow struct {
+	UserID           uint   `json:"userId"`
+	Username         string `json:"username"`
+	FullName         string `json:"fullName"`
+	Days             int    `json:"days"`
+	Fullday          int    `json:"fullday"`
+	Halfday          int    `json:"halfday"`
+	Late             int    `json:"late"`             // in minutes
+	ApprovedOvertime int    `json:"approvedOvertime"` // in minutes, approved or adjusted overtime only
+	PendingOvertime  int    `json:"pendingOvertime"`  // in minutes, not paid until approved
+	BreakMinutes     int    `json:"breakMinutes"`     // in minutes, ended breaks
+	WorkedMinutes    int    `json:"workedMinutes"`    // in minutes, from check in to check out of checked out days, breaks deducted
+	BreakViolations  int    `json:"breakViolations"`  // days with more break than allowed
+}
+
 // SearchUsersByFace searches for users by face image
 // @Summary Search Users by Face
 // @Description Search for users by face image
@@ -92,28 +227,41 @@ func (ac *AttendanceController) SearchUsersByFace(c fiber.Ctx) error {
 		})
 	}
 
-	// Validate mime type
-	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
-		log.Println("Invalid image file type")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	tmpPath, err := utils.CreateTempFile("search_face_*.jpg")
+	if err != nil {
+		log.Println("Failed to save image file:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid image file type",
+			Error:   "Failed to save image file",
 		})
 	}
+	defer os.Remove(tmpPath)
 
-	tmpPath := "tmp/search_face.jpg"
-	if err := c.SaveFile(file, tmpPath); err != nil {
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			log.Println("Invalid image file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
 		log.Println("Failed to save image file:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to save image file",
 		})
 	}
-	defer os.Remove(tmpPath)
 
-	result, err := utils.SendToDeepFaceSearch(tmpPath)
+	result, err := utils.SendToDeepFaceSearch(c.Context(), tmpPath)
 	if err != nil {
 		log.Println("Face search failed:", err)
+		if !utils.CheckDeepFaceHealth(c.Context()).Available {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Face recognition is unavailable, use manual check-in or check-out",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   fmt.Sprintf("Face search failed: %v", err),
@@ -130,7 +278,7 @@ func (ac *AttendanceController) SearchUsersByFace(c fiber.Ctx) error {
 
 	// Fetch user data from database
 	var user models.User
-	if err := ac.DB.Preload("Roles").Where("id = ?", result.UserID).First(&user).Error; err != nil {
+	if err := ac.DB.WithContext(c.Context()).Preload("Roles").Where("id = ?", result.UserID).First(&user).Error; err != nil {
 		log.Println("User not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -168,28 +316,41 @@ func (ac *AttendanceController) CheckInUserByFace(c fiber.Ctx) error {
 		})
 	}
 
-	// Validate mime type
-	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
-		log.Println("Invalid image file type")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	tmpPath, err := utils.CreateTempFile("search_face_*.jpg")
+	if err != nil {
+		log.Println("Failed to save image file:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid image file type",
+			Error:   "Failed to save image file",
 		})
 	}
+	defer os.Remove(tmpPath)
 
-	tmpPath := "tmp/search_face.jpg"
-	if err := c.SaveFile(file, tmpPath); err != nil {
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			log.Println("Invalid image file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
 		log.Println("Failed to save image file:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to save image file",
 		})
 	}
-	defer os.Remove(tmpPath)
 
-	result, err := utils.SendToDeepFaceSearch(tmpPath)
+	result, err := utils.SendToDeepFaceSearch(c.Context(), tmpPath)
 	if err != nil {
 		log.Println("Face search failed:", err)
+		if !utils.CheckDeepFaceHealth(c.Context()).Available {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Face recognition is unavailable, use manual check-in or check-out",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   fmt.Sprintf("Face search failed: %v", err),
@@ -204,14 +365,48 @@ func (ac *AttendanceController) CheckInUserByFace(c fiber.Ctx) error {
 		})
 	}
 
+	response, err := ac.checkInMatchedFace(c.Context(), result)
+	if err != nil {
+		return checkInErrorResponse(c, err)
+	}
+
+	log.Println("User checked in successfully")
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "User checked in successfully",
+		Data:    response,
+	})
+}
+
+// checkInErrorResponse writes a check-in failure, *fiber.Error failures carry their own response status
+func checkInErrorResponse(c fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		fiberErr = fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.Status(fiberErr.Code).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   fiberErr.Message,
+	})
+}
+
+// checkInMatchedFace checks in the user of a face match at the kiosk location.
+// Failures are returned as a *fiber.Error carrying the response status.
+func (ac *AttendanceController) checkInMatchedFace(ctx context.Context, result *utils.SearchResult) (*CheckInResponse, error) {
 	// Fetch user data from database
 	var user models.User
-	if err := ac.DB.Preload("Roles").Where("id = ?", result.UserID).First(&user).Error; err != nil {
+	if err := ac.DB.WithContext(ctx).Preload("Roles").Where("id = ?", result.UserID).First(&user).Error; err != nil {
 		log.Println("User not found")
-		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "User not found",
-		})
+		return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+	}
+
+	// Reject face matches below the minimum confidence of the kiosk location
+	var kioskLocation models.Location
+	ac.DB.WithContext(ctx).Where("id = ?", kioskLocationID).First(&kioskLocation)
+	faceThreshold, err := ac.FaceThreshold.Check(result.Confidence, &kioskLocation)
+	if err != nil {
+		log.Printf("Face match rejected (userID=%d): %v\n", user.ID, err)
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Face not recognized with enough confidence, try again or use manual check-in")
 	}
 
 	// Check if user already checked in today
@@ -220,12 +415,9 @@ func (ac *AttendanceController) CheckInUserByFace(c fiber.Ctx) error {
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
-	if err := ac.DB.Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err == nil {
+	if err := ac.DB.WithContext(ctx).Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err == nil {
 		log.Println("User already checked in today")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "User already checked in today",
-		})
+		return nil, fiber.NewError(fiber.StatusBadRequest, "User already checked in today")
 	}
 
 	// Automatically determine status based on check-in time
@@ -252,10 +444,7 @@ func (ac *AttendanceController) CheckInUserByFace(c fiber.Ctx) error {
 
 		if checkedInTime.After(fulldayCheckInEnd) {
 			log.Println("Check-in time has expired for fullday shift. Deadline was", fulldayCheckInEnd.Format("15:04"))
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   fmt.Sprintf("Check-in time has expired for fullday shift. Deadline was %s", fulldayCheckInEnd.Format("15:04")),
-			})
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Check-in time has expired for fullday shift. Deadline was %s", fulldayCheckInEnd.Format("15:04")))
 		}
 
 		if checkedInTime.After(workStartTime) {
@@ -268,10 +457,7 @@ func (ac *AttendanceController) CheckInUserByFace(c fiber.Ctx) error {
 
 		if checkedInTime.After(halfdayCheckInEnd) {
 			log.Println("Check-in time has expired for halfday shift. Deadline was", halfdayCheckInEnd.Format("15:04"))
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   fmt.Sprintf("Check-in time has expired for halfday shift. Deadline was %s", halfdayCheckInEnd.Format("15:04")),
-			})
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Check-in time has expired for halfday shift. Deadline was %s", halfdayCheckInEnd.Format("15:04")))
 		}
 
 		if checkedInTime.After(workStartTime) {
@@ -281,12 +467,9 @@ func (ac *AttendanceController) CheckInUserByFace(c fiber.Ctx) error {
 		// Not within any valid check-in window
 		log.Println("Not within valid check-in time. Fullday:", fulldayCheckInStart.Format("15:04"), "-", fulldayCheckInEnd.Format("15:04"),
 			"Halfday:", halfdayCheckInStart.Format("15:04"), "-", halfdayCheckInEnd.Format("15:04"))
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-			Success: false,
-			Error: fmt.Sprintf("Not within valid check-in time. Fullday: %s-%s, Halfday: %s-%s",
-				fulldayCheckInStart.Format("15:04"), fulldayCheckInEnd.Format("15:04"),
-				halfdayCheckInStart.Format("15:04"), halfdayCheckInEnd.Format("15:04")),
-		})
+		return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Not within valid check-in time. Fullday: %s-%s, Halfday: %s-%s",
+			fulldayCheckInStart.Format("15:04"), fulldayCheckInEnd.Format("15:04"),
+			halfdayCheckInStart.Format("15:04"), halfdayCheckInEnd.Format("15:04")))
 	}
 
 	// Create attendance record
@@ -296,36 +479,156 @@ func (ac *AttendanceController) CheckInUserByFace(c fiber.Ctx) error {
 		Checked:    true,
 		Status:     status,
 		Late:       lateMinutes,
-		LocationID: 1,
+		LocationID: kioskLocationID,
 		Latitude:   -7.9484807,
 		Longitude:  112.6460763,
 		Accuracy:   1.0,
+
+		EntryMethod:    models.EntryMethodFace,
+		FaceConfidence: &result.Confidence,
+		FaceThreshold:  &faceThreshold,
 	}
 
-	if err := ac.DB.Create(&newAttendance).Error; err != nil {
+	if err := ac.DB.WithContext(ctx).Create(&newAttendance).Error; err != nil {
 		log.Println("Failed to create attendance record:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create attendance record")
+	}
+
+	// Reload attendace data and related data
+	ac.DB.WithContext(ctx).Preload("User").Preload("Location").Where("id = ?", newAttendance.ID).First(&newAttendance)
+
+	return &CheckInResponse{
+		Matched:    true,
+		UserID:     result.UserID,
+		Confidence: result.Confidence,
+		User:       user.ToResponse(),
+		Attendance: newAttendance.ToResponse(),
+		Status:     status,
+		Late:       lateMinutes,
+	}, nil
+}
+
+// CheckInUsersByFaceBatch checks in a group of users from a burst of face images
+// @Summary Batch Check In Users by Face
+// @Description Check in the people queueing at the kiosk from a burst of face images (or frames of a short video), one face per image. The faces are searched concurrently and every image gets its own result, a failed image does not fail the batch. Authenticated with the kiosk key.
+// @Tags Attendances
+// @Accept multipart/form-data
+// @Produce json
+// @Param X-Kiosk-Key header string true "Kiosk API key"
+// @Param images formData file true "Face images, one face per image"
+// @Success 200 {object} utils.SuccessResponse{data=BatchCheckInResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 413 {object} utils.ErrorResponse
+// @Failure 503 {object} utils.ErrorResponse
+// @Router /api/attendances/checkin/face/batch [post]
+func (ac *AttendanceController) CheckInUsersByFaceBatch(c fiber.Ctx) error {
+	log.Println("CheckInUsersByFaceBatch called")
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["images"]) == 0 {
+		log.Println("CheckInUsersByFaceBatch - at least one image is required")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to create attendance record",
+			Error:   "At least one image is required",
+		})
+	}
+	files := form.File["images"]
+	if ac.FaceBatch.MaxImages > 0 && len(files) > ac.FaceBatch.MaxImages {
+		log.Println("CheckInUsersByFaceBatch - too many images:", len(files))
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("At most %d images can be checked in at once", ac.FaceBatch.MaxImages),
 		})
 	}
 
-	// Reload attendace data and related data
-	ac.DB.Preload("User").Preload("Location").Where("id = ?", newAttendance.ID).First(&newAttendance)
+	// Fail fast instead of sending every image to an unreachable service
+	if !utils.CheckDeepFaceHealth(c.Context()).Available {
+		log.Println("CheckInUsersByFaceBatch - face recognition is unavailable")
+		return c.Status(fiber.StatusServiceUnavailable).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Face recognition is unavailable, use manual check-in or check-out",
+		})
+	}
 
-	log.Println("User checked in successfully")
+	results := make([]BatchCheckInFaceResult, len(files))
+	imagePaths := make([]string, len(files))
+	for i, file := range files {
+		results[i] = BatchCheckInFaceResult{Index: i, Filename: file.Filename}
+
+		// Every image gets its own file, the searches of the batch run at the same time
+		tmpPath, err := utils.CreateTempFile("batch_face_*.jpg")
+		if err != nil {
+			log.Println("CheckInUsersByFaceBatch - failed to create image file:", err)
+			results[i].Error = "Failed to save image file"
+			continue
+		}
+		defer os.Remove(tmpPath)
+
+		// Validate image content and strip metadata
+		if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+			log.Printf("CheckInUsersByFaceBatch - image %d rejected: %v\n", i, err)
+			if errors.Is(err, utils.ErrInvalidImage) {
+				results[i].Error = err.Error()
+			} else {
+				results[i].Error = "Failed to save image file"
+			}
+			continue
+		}
+		imagePaths[i] = tmpPath
+	}
+
+	outcomes := ac.FaceBatch.SearchFaces(c.Context(), imagePaths)
+
+	// Check in one by one, a user showing up in several images is checked in from the first that succeeds
+	checkedInUsers := make(map[string]bool)
+	response := BatchCheckInResponse{Total: len(files), Results: results}
+	for i, outcome := range outcomes {
+		if imagePaths[i] == "" {
+			continue
+		}
+		if outcome.Err != nil {
+			log.Printf("CheckInUsersByFaceBatch - face search of image %d failed: %v\n", i, outcome.Err)
+			results[i].Error = fmt.Sprintf("Face search failed: %v", outcome.Err)
+			continue
+		}
+		if !outcome.Result.Matched {
+			results[i].Error = "Face not recognized"
+			continue
+		}
+		if checkedInUsers[outcome.Result.UserID] {
+			results[i].Error = "User already checked in by an earlier image of the batch"
+			continue
+		}
+
+		checkIn, err := ac.checkInMatchedFace(c.Context(), outcome.Result)
+		if err != nil {
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				results[i].Error = fiberErr.Message
+			} else {
+				results[i].Error = err.Error()
+			}
+			continue
+		}
+		checkedInUsers[outcome.Result.UserID] = true
+		results[i].Success = true
+		results[i].CheckIn = checkIn
+	}
+
+	for _, result := range results {
+		if result.Success {
+			response.CheckedIn++
+		} else {
+			response.Failed++
+		}
+	}
+
+	log.Printf("CheckInUsersByFaceBatch completed successfully (%d checked in, %d failed)\n", response.CheckedIn, response.Failed)
 	return c.JSON(utils.SuccessResponse{
 		Success: true,
-		Message: "User checked in successfully",
-		Data: CheckInResponse{
-			Matched:    true,
-			UserID:     result.UserID,
-			Confidence: result.Confidence,
-			User:       user.ToResponse(),
-			Attendance: newAttendance.ToResponse(),
-			Status:     status,
-			Late:       lateMinutes,
-		},
+		Message: fmt.Sprintf("%d of %d users checked in", response.CheckedIn, response.Total),
+		Data:    response,
 	})
 }
 
@@ -350,28 +653,41 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 		})
 	}
 
-	// Validate mime type
-	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
-		log.Println("Invalid image file type")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	tmpPath, err := utils.CreateTempFile("search_face_*.jpg")
+	if err != nil {
+		log.Println("Failed to save image file:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid image file type",
+			Error:   "Failed to save image file",
 		})
 	}
+	defer os.Remove(tmpPath)
 
-	tmpPath := "tmp/search_face.jpg"
-	if err := c.SaveFile(file, tmpPath); err != nil {
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			log.Println("Invalid image file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
 		log.Println("Failed to save image file:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to save image file",
 		})
 	}
-	defer os.Remove(tmpPath)
 
-	result, err := utils.SendToDeepFaceSearch(tmpPath)
+	result, err := utils.SendToDeepFaceSearch(c.Context(), tmpPath)
 	if err != nil {
 		log.Println("Face search failed:", err)
+		if !utils.CheckDeepFaceHealth(c.Context()).Available {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Face recognition is unavailable, use manual check-in or check-out",
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   fmt.Sprintf("Face search failed: %v", err),
@@ -388,7 +704,7 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 
 	// Fetch user data from database
 	var user models.User
-	if err := ac.DB.Preload("Roles").Where("id = ?", result.UserID).First(&user).Error; err != nil {
+	if err := ac.DB.WithContext(c.Context()).Preload("Roles").Where("id = ?", result.UserID).First(&user).Error; err != nil {
 		log.Println("User not found")
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -401,7 +717,7 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 	now := time.Now()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
-	if err := ac.DB.Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err != nil {
+	if err := ac.DB.WithContext(c.Context()).Preload("Location").Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err != nil {
 		log.Println("Attendance record not found or user has not checked in today")
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -409,6 +725,18 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 		})
 	}
 
+	// Reject face matches below the minimum confidence of the check-in location
+	faceThreshold, err := ac.FaceThreshold.Check(result.Confidence, &attendance.Location)
+	if err != nil {
+		log.Printf("Face match rejected (userID=%d): %v\n", user.ID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Face not recognized with enough confidence, try again or use manual check-out",
+		})
+	}
+	attendance.CheckOutFaceConfidence = &result.Confidence
+	attendance.CheckOutFaceThreshold = &faceThreshold
+
 	// Automatically determine checkout behavior based on time
 	checkedOutTime := time.Now()
 
@@ -427,7 +755,7 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 		attendance.Status = "halfday"
 		attendance.CheckedOut = &checkedOutTime
 		attendance.Checked = false
-		attendance.Overtime = 0
+		attendance.SetOvertime(0)
 	} else if checkedOutTime.After(regularCheckOutStart) {
 		// Checking out around 17:00 or later
 		switch attendance.Status {
@@ -435,7 +763,7 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 			// Halfday status: just update checkout time, no overtime
 			attendance.CheckedOut = &checkedOutTime
 			attendance.Checked = false
-			attendance.Overtime = 0
+			attendance.SetOvertime(0)
 		case "fullday":
 			// Fullday status: update checkout and calculate overtime if after 17:00
 			attendance.CheckedOut = &checkedOutTime
@@ -444,7 +772,7 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 			if checkedOutTime.After(regularCheckOut) {
 				overtime = int(checkedOutTime.Sub(regularCheckOut).Minutes())
 			}
-			attendance.Overtime = overtime
+			attendance.SetOvertime(overtime)
 		}
 	} else {
 		// Not within valid checkout windows
@@ -458,8 +786,13 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 		})
 	}
 
-	// Update attendance record
-	if err := ac.DB.Save(&attendance).Error; err != nil {
+	// Update attendance record, ending the break still in progress at the checkout
+	if err := ac.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := ac.Breaks.CloseOpenBreak(tx, &attendance, checkedOutTime); err != nil {
+			return err
+		}
+		return tx.Save(&attendance).Error
+	}); err != nil {
 		log.Println("Failed to update attendance record:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -468,7 +801,7 @@ func (ac *AttendanceController) CheckOutUserByFace(c fiber.Ctx) error {
 	}
 
 	// Reload attendace data and related data
-	ac.DB.Preload("User").Preload("Location").Where("id = ?", attendance.ID).First(&attendance)
+	ac.DB.WithContext(c.Context()).Preload("User").Preload("Location").Where("id = ?", attendance.ID).First(&attendance)
 
 	log.Println("User checked out successfully")
 	return c.JSON(utils.SuccessResponse{
@@ -510,7 +843,7 @@ func (ac *AttendanceController) CheckInUserManual(c fiber.Ctx) error {
 
 	// Find user by username
 	var user models.User
-	if err := ac.DB.Preload("Roles").Where("username = ?", req.Username).First(&user).Error; err != nil {
+	if err := ac.DB.WithContext(c.Context()).Preload("Roles").Where("username = ?", utils.NormalizeUsername(req.Username)).First(&user).Error; err != nil {
 		log.Println("User not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -534,7 +867,7 @@ func (ac *AttendanceController) CheckInUserManual(c fiber.Ctx) error {
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
-	if err := ac.DB.Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err == nil {
+	if err := ac.DB.WithContext(c.Context()).Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err == nil {
 		log.Println("User already checked in today")
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -614,9 +947,17 @@ func (ac *AttendanceController) CheckInUserManual(c fiber.Ctx) error {
 		Latitude:   -7.9484807,
 		Longitude:  112.6460763,
 		Accuracy:   1.0,
+
+		EntryMethod: models.EntryMethodManual,
+	}
+
+	// Manual entries while face recognition is down are tagged for HR verification
+	if faceStatus := utils.CheckDeepFaceHealth(c.Context()); !faceStatus.Available {
+		log.Println("Face recognition unavailable, recording manual check-in as fallback:", faceStatus.Error)
+		newAttendance.MarkFallback("check-in: face recognition unavailable (" + faceStatus.Error + ")")
 	}
 
-	if err := ac.DB.Create(&newAttendance).Error; err != nil {
+	if err := ac.DB.WithContext(c.Context()).Create(&newAttendance).Error; err != nil {
 		log.Println("Failed to create attendance record:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -625,7 +966,7 @@ func (ac *AttendanceController) CheckInUserManual(c fiber.Ctx) error {
 	}
 
 	// Reload attendace data and related data
-	ac.DB.Preload("User").Preload("Location").Where("id = ?", newAttendance.ID).First(&newAttendance)
+	ac.DB.WithContext(c.Context()).Preload("User").Preload("Location").Where("id = ?", newAttendance.ID).First(&newAttendance)
 
 	log.Println("User checked in successfully")
 	return c.JSON(utils.SuccessResponse{
@@ -665,7 +1006,7 @@ func (ac *AttendanceController) CheckOutUserManual(c fiber.Ctx) error {
 
 	// Find user by username
 	var user models.User
-	if err := ac.DB.Preload("Roles").Where("username = ?", req.Username).First(&user).Error; err != nil {
+	if err := ac.DB.WithContext(c.Context()).Preload("Roles").Where("username = ?", utils.NormalizeUsername(req.Username)).First(&user).Error; err != nil {
 		log.Println("User not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -688,7 +1029,7 @@ func (ac *AttendanceController) CheckOutUserManual(c fiber.Ctx) error {
 	now := time.Now()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
-	if err := ac.DB.Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err != nil {
+	if err := ac.DB.WithContext(c.Context()).Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err != nil {
 		log.Println("Attendance record not found or user has not checked in today")
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -714,7 +1055,7 @@ func (ac *AttendanceController) CheckOutUserManual(c fiber.Ctx) error {
 		attendance.Status = "halfday"
 		attendance.CheckedOut = &checkedOutTime
 		attendance.Checked = false
-		attendance.Overtime = 0
+		attendance.SetOvertime(0)
 	} else if checkedOutTime.After(regularCheckOutStart) {
 		// Checking out around 17:00 or later
 		switch attendance.Status {
@@ -722,7 +1063,7 @@ func (ac *AttendanceController) CheckOutUserManual(c fiber.Ctx) error {
 			// Halfday status: just update checkout time, no overtime
 			attendance.CheckedOut = &checkedOutTime
 			attendance.Checked = false
-			attendance.Overtime = 0
+			attendance.SetOvertime(0)
 		case "fullday":
 			// Fullday status: update checkout and calculate overtime if after 17:00
 			attendance.CheckedOut = &checkedOutTime
@@ -731,7 +1072,7 @@ func (ac *AttendanceController) CheckOutUserManual(c fiber.Ctx) error {
 			if checkedOutTime.After(regularCheckOut) {
 				overtime = int(checkedOutTime.Sub(regularCheckOut).Minutes())
 			}
-			attendance.Overtime = overtime
+			attendance.SetOvertime(overtime)
 		}
 	} else {
 		// Not within valid checkout windows
@@ -745,8 +1086,19 @@ func (ac *AttendanceController) CheckOutUserManual(c fiber.Ctx) error {
 		})
 	}
 
-	// Update attendance record
-	if err := ac.DB.Save(&attendance).Error; err != nil {
+	// Manual entries while face recognition is down are tagged for HR verification
+	if faceStatus := utils.CheckDeepFaceHealth(c.Context()); !faceStatus.Available {
+		log.Println("Face recognition unavailable, recording manual check-out as fallback:", faceStatus.Error)
+		attendance.MarkFallback("check-out: face recognition unavailable (" + faceStatus.Error + ")")
+	}
+
+	// Update attendance record, ending the break still in progress at the checkout
+	if err := ac.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := ac.Breaks.CloseOpenBreak(tx, &attendance, checkedOutTime); err != nil {
+			return err
+		}
+		return tx.Save(&attendance).Error
+	}); err != nil {
 		log.Println("Failed to update attendance record:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -755,7 +1107,7 @@ func (ac *AttendanceController) CheckOutUserManual(c fiber.Ctx) error {
 	}
 
 	// Reload attendace data and related data
-	ac.DB.Preload("User").Preload("Location").Where("id = ?", attendance.ID).First(&attendance)
+	ac.DB.WithContext(c.Context()).Preload("User").Preload("Location").Where("id = ?", attendance.ID).First(&attendance)
 
 	log.Println("User checked out successfully")
 	return c.JSON(utils.SuccessResponse{
@@ -771,41 +1123,302 @@ func (ac *AttendanceController) CheckOutUserManual(c fiber.Ctx) error {
 	})
 }
 
-// GetAttendances retrieves all attendance records
-// @Summary Get All Attendances
-// @Description Retrieve all attendance records with pagination and search
+// StartBreakByFace starts a break for a user recognized by face image
+// @Summary Start Break by Face
+// @Description Start a break for the user recognized by face image, on today's attendance. The user must be checked in and not already on a break
 // @Tags Attendances
+// @Accept multipart/form-data
 // @Produce json
-// @Security BearerAuth
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Number of records per page" default(10)
-// @Param startDate query string false "Start date (YYYY-MM-DD format)"
-// @Param endDate query string false "End date (YYYY-MM-DD format)"
-// @Param search query string false "Search term for user name or username"
-// @Success 200 {object} utils.SuccessResponse{data=[]models.AttendanceResponse}
+// @Param image formData file true "Face image to search for"
+// @Success 200 {object} utils.SuccessResponse{data=BreakResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Failure 503 {object} utils.ErrorResponse
+// @Router /api/attendances/break/start/face [post]
+func (ac *AttendanceController) StartBreakByFace(c fiber.Ctx) error {
+	return ac.breakByFace(c, true)
+}
+
+// EndBreakByFace ends the break of a user recognized by face image
+// @Summary End Break by Face
+// @Description End the break in progress of the user recognized by face image. The break minutes are added to today's attendance and HR is notified when the total exceeds the allowed maximum
+// @Tags Attendances
+// @Accept multipart/form-data
+// @Produce json
+// @Param image formData file true "Face image to search for"
+// @Success 200 {object} utils.SuccessResponse{data=BreakResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Failure 503 {object} utils.ErrorResponse
+// @Router /api/attendances/break/end/face [put]
+func (ac *AttendanceController) EndBreakByFace(c fiber.Ctx) error {
+	return ac.breakByFace(c, false)
+}
+
+// StartBreakManual starts a break for a user by username and password
+// @Summary Manual Start Break
+// @Description Start a break for a user by username and password, on today's attendance. The user must be checked in and not already on a break
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Param body body BreakManualRequest true "Manual Break Request Body"
+// @Success 200 {object} utils.SuccessResponse{data=BreakResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
-// @Router /api/attendances [get]
-func (ac *AttendanceController) GetAttendances(c fiber.Ctx) error {
-	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	offset := (page - 1) * limit
+// @Router /api/attendances/break/start/manual [post]
+func (ac *AttendanceController) StartBreakManual(c fiber.Ctx) error {
+	return ac.breakManual(c, true)
+}
 
-	var attendances []models.Attendance
+// EndBreakManual ends the break of a user by username and password
+// @Summary Manual End Break
+// @Description End the break in progress of a user by username and password. The break minutes are added to today's attendance and HR is notified when the total exceeds the allowed maximum
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Param body body BreakManualRequest true "Manual Break Request Body"
+// @Success 200 {object} utils.SuccessResponse{data=BreakResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/break/end/manual [put]
+func (ac *AttendanceController) EndBreakManual(c fiber.Ctx) error {
+	return ac.breakManual(c, false)
+}
 
-	// Build base query
-	query := ac.DB.Model(&models.Attendance{}).Preload("User").Preload("Location").Order("checked_in DESC")
+// breakByFace recognizes the user by face image and starts or ends their break
+func (ac *AttendanceController) breakByFace(c fiber.Ctx, start bool) error {
+	file, err := c.FormFile("image")
+	if err != nil {
+		log.Println("Image file is required")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Image file is required",
+		})
+	}
 
-	// Date range filter if provided
-	startDate := c.Query("startDate", "")
-	endDate := c.Query("endDate", "")
-	if startDate != "" {
-		// Parse start date and set time to beginning of the day
-		parsedStartDate, err := time.Parse("2006-01-02", startDate)
-		if err != nil {
-			log.Println("Invalid start_date format. Use YYYY-MM-DD.")
+	tmpPath, err := utils.CreateTempFile("search_face_*.jpg")
+	if err != nil {
+		log.Println("Failed to save image file:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save image file",
+		})
+	}
+	defer os.Remove(tmpPath)
+
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			log.Println("Invalid image file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+		log.Println("Failed to save image file:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save image file",
+		})
+	}
+
+	result, err := utils.SendToDeepFaceSearch(c.Context(), tmpPath)
+	if err != nil {
+		log.Println("Face search failed:", err)
+		if !utils.CheckDeepFaceHealth(c.Context()).Available {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Face recognition is unavailable, use the manual break",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Face search failed: %v", err),
+		})
+	}
+
+	if !result.Matched {
+		log.Println("Face not recognized")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Face not recognized",
+		})
+	}
+
+	// Fetch user data from database
+	var user models.User
+	if err := ac.DB.WithContext(c.Context()).Preload("Roles").Where("id = ?", result.UserID).First(&user).Error; err != nil {
+		log.Println("User not found")
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+	}
+
+	attendance, err := ac.findOpenAttendance(c, user.ID)
+	if err != nil {
+		log.Println("Attendance record not found or user has not checked in today")
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance record not found or user has not checked in today",
+		})
+	}
+
+	// Reject face matches below the minimum confidence of the check-in location
+	if _, err := ac.FaceThreshold.Check(result.Confidence, &attendance.Location); err != nil {
+		log.Printf("Face match rejected (userID=%d): %v\n", user.ID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Face not recognized with enough confidence, try again or use the manual break",
+		})
+	}
+
+	return ac.recordBreak(c, &user, attendance, start, models.EntryMethodFace)
+}
+
+// breakManual authenticates the user by username and password and starts or ends their break
+func (ac *AttendanceController) breakManual(c fiber.Ctx, start bool) error {
+	// Binding request body
+	var req BreakManualRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Find user by username
+	var user models.User
+	if err := ac.DB.WithContext(c.Context()).Preload("Roles").Where("username = ?", utils.NormalizeUsername(req.Username)).First(&user).Error; err != nil {
+		log.Println("User not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+	}
+
+	// Verify password
+	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		log.Println("Invalid password")
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid password",
+		})
+	}
+
+	attendance, err := ac.findOpenAttendance(c, user.ID)
+	if err != nil {
+		log.Println("Attendance record not found or user has not checked in today")
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance record not found or user has not checked in today",
+		})
+	}
+
+	return ac.recordBreak(c, &user, attendance, start, models.EntryMethodManual)
+}
+
+// findOpenAttendance returns today's attendance of the user, checked in and not checked out yet
+func (ac *AttendanceController) findOpenAttendance(c fiber.Ctx, userID uint) (*models.Attendance, error) {
+	var attendance models.Attendance
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+	err := ac.DB.WithContext(c.Context()).Preload("Location").Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", userID, startOfDay, endOfDay, true).First(&attendance).Error
+	return &attendance, err
+}
+
+// recordBreak starts or ends the break of an identified user on their attendance
+func (ac *AttendanceController) recordBreak(c fiber.Ctx, user *models.User, attendance *models.Attendance, start bool, method string) error {
+	var attendanceBreak *models.AttendanceBreak
+	var err error
+	message := "Break started successfully"
+	if start {
+		attendanceBreak, err = utils.StartAttendanceBreak(ac.DB.WithContext(c.Context()), attendance, method)
+	} else {
+		attendanceBreak, err = ac.Breaks.EndAttendanceBreak(ac.DB.WithContext(c.Context()), attendance, method)
+		message = "Break ended successfully"
+	}
+	if errors.Is(err, utils.ErrBreakInProgress) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User is already on a break",
+		})
+	}
+	if errors.Is(err, utils.ErrNoBreakInProgress) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User is not on a break",
+		})
+	}
+	if err != nil {
+		log.Println("Failed to record break:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to record break",
+		})
+	}
+
+	// Reload attendace data and related data
+	ac.DB.WithContext(c.Context()).Preload("User").Preload("Location").Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("started_at ASC")
+	}).Where("id = ?", attendance.ID).First(attendance)
+
+	log.Println(message)
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: BreakResponse{
+			User:       user.ToResponse(),
+			Attendance: attendance.ToResponse(),
+			Break:      attendanceBreak.ToResponse(),
+		},
+	})
+}
+
+// GetAttendances retrieves all attendance records
+// @Summary Get All Attendances
+// @Description Retrieve all attendance records with pagination and search
+// @Tags Attendances
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Param startDate query string false "Start date (YYYY-MM-DD format)"
+// @Param endDate query string false "End date (YYYY-MM-DD format)"
+// @Param search query string false "Search term for user name or username"
+// @Param teamId query int false "Filter attendances by team ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances [get]
+func (ac *AttendanceController) GetAttendances(c fiber.Ctx) error {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var attendances []models.Attendance
+
+	// Build base query
+	query := ac.DB.WithContext(c.Context()).Model(&models.Attendance{}).Preload("User").Preload("Location").Order("checked_in DESC")
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		// Parse start date and set time to beginning of the day
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			log.Println("Invalid start_date format. Use YYYY-MM-DD.")
 			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 				Success: false,
 				Error:   "Invalid start_date format. Use YYYY-MM-DD.",
@@ -835,6 +1448,12 @@ func (ac *AttendanceController) GetAttendances(c fiber.Ctx) error {
 			Where("users.username ILIKE ? OR users.full_name ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	// Filter by team if provided
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	if teamID > 0 {
+		query = query.Where("attendances.user_id IN (?)", utils.TeamMembersQuery(ac.DB, uint(teamID)))
+	}
+
 	// Get total count for pagination
 	var total int64
 	query.Count(&total)
@@ -873,11 +1492,15 @@ func (ac *AttendanceController) GetAttendances(c fiber.Ctx) error {
 		filters = append(filters, "search: "+search)
 	}
 
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("team: %d", teamID))
+	}
+
 	if len(filters) > 0 {
 		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
 	}
 
-	log.Panicln(message)
+	log.Println(message)
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
 		Success: true,
 		Message: message,
@@ -890,35 +1513,2024 @@ func (ac *AttendanceController) GetAttendances(c fiber.Ctx) error {
 	})
 }
 
-// GetAttendanceByID retrieves a specific attendance record by ID
-// @Summary Get Attendance by ID
-// @Description Retrieve a specific attendance record by its ID
+// GetSuspiciousAttendances retrieves attendance records flagged by the fraud heuristics for HR review
+// @Summary Get Suspicious Attendances
+// @Description Retrieve attendance records flagged as suspicious with pagination, ordered by fraud score
 // @Tags Attendances
 // @Produce json
 // @Security BearerAuth
-// @Param id path int true "Attendance ID"
-// @Success 200 {object} utils.SuccessResponse{data=models.AttendanceResponse}
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Param startDate query string false "Start date (YYYY-MM-DD format)"
+// @Param endDate query string false "End date (YYYY-MM-DD format)"
+// @Param minScore query int false "Minimum fraud score"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AttendanceResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
-// @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
-// @Router /api/attendances/{id} [get]
-func (ac *AttendanceController) GetAttendanceByID(c fiber.Ctx) error {
-	// Parse id paramameter
-	id := c.Params("id")
-	var attendance models.Attendance
-	if err := ac.DB.Preload("User").Preload("Location").First(&attendance, id).Error; err != nil {
-		log.Println("Attendance record not found:", err)
-		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+// @Router /api/attendances/suspicious [get]
+func (ac *AttendanceController) GetSuspiciousAttendances(c fiber.Ctx) error {
+	log.Println("GetSuspiciousAttendances called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var attendances []models.Attendance
+
+	// Build base query
+	query := ac.DB.WithContext(c.Context()).Model(&models.Attendance{}).Preload("User").Preload("Location").Where("suspicious = ?", true).Order("fraud_score DESC, checked_in DESC")
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			log.Println("GetSuspiciousAttendances - Invalid start date format:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid start_date format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in >= ?", time.Date(parsedStartDate.Year(), parsedStartDate.Month(), parsedStartDate.Day(), 0, 0, 0, 0, time.Local))
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			log.Println("GetSuspiciousAttendances - Invalid end date format:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid end_date format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in < ?", time.Date(parsedEndDate.Year(), parsedEndDate.Month(), parsedEndDate.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1))
+	}
+
+	// Minimum score filter if provided
+	minScore, _ := strconv.Atoi(c.Query("minScore", "0"))
+	if minScore > 0 {
+		query = query.Where("fraud_score >= ?", minScore)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Offset(offset).Limit(limit).Find(&attendances).Error; err != nil {
+		log.Println("GetSuspiciousAttendances - Failed to retrieve attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Attendance record not found",
+			Error:   "Failed to retrieve suspicious attendances",
 		})
 	}
 
-	log.Println("Attendance record retrieved successfully")
-	return c.JSON(utils.SuccessResponse{
+	// Format response
+	attendanceList := make([]models.AttendanceResponse, len(attendances))
+	for i, attendance := range attendances {
+		attendanceList[i] = *attendance.ToResponse()
+	}
+
+	// Build success message
+	message := "Suspicious attendances retrieved successfully"
+	var filters []string
+
+	if startDate != "" {
+		filters = append(filters, "from: "+startDate)
+	}
+
+	if endDate != "" {
+		filters = append(filters, "to: "+endDate)
+	}
+
+	if minScore > 0 {
+		filters = append(filters, "minScore: "+strconv.Itoa(minScore))
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetSuspiciousAttendances completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
 		Success: true,
-		Message: "Attendance record retrieved successfully",
-		Data:    attendance.ToResponse(),
+		Message: message,
+		Data:    attendanceList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetLowConfidenceAttendances retrieves face attendances accepted with a low match confidence for review
+// @Summary Get Low Confidence Attendances
+// @Description Retrieve attendance records whose check-in or check-out face match confidence is below the review level, with pagination
+// @Tags Attendances
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Param startDate query string false "Start date (YYYY-MM-DD format)"
+// @Param endDate query string false "End date (YYYY-MM-DD format)"
+// @Param maxConfidence query number false "Review matches below this confidence, defaults to FACE_REVIEW_CONFIDENCE"
+// @Param locationId query int false "Filter by location ID"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/face-confidence [get]
+func (ac *AttendanceController) GetLowConfidenceAttendances(c fiber.Ctx) error {
+	log.Println("GetLowConfidenceAttendances called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Confidence below which accepted matches are reviewed
+	maxConfidence := ac.FaceThreshold.ReviewConfidence
+	maxConfidenceStr := c.Query("maxConfidence", "")
+	if maxConfidenceStr != "" {
+		parsed, err := strconv.ParseFloat(maxConfidenceStr, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			log.Println("GetLowConfidenceAttendances - Invalid max confidence:", maxConfidenceStr)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid maxConfidence. Use a number between 0 and 1.",
+			})
+		}
+		maxConfidence = parsed
+	}
+
+	var attendances []models.Attendance
+
+	// Build base query
+	query := ac.DB.WithContext(c.Context()).Model(&models.Attendance{}).Preload("User").Preload("Location").
+		Where("face_confidence < ? OR check_out_face_confidence < ?", maxConfidence, maxConfidence).
+		Order("LEAST(COALESCE(face_confidence, 1), COALESCE(check_out_face_confidence, 1)) ASC, checked_in DESC")
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			log.Println("GetLowConfidenceAttendances - Invalid start date format:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid start_date format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in >= ?", time.Date(parsedStartDate.Year(), parsedStartDate.Month(), parsedStartDate.Day(), 0, 0, 0, 0, time.Local))
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			log.Println("GetLowConfidenceAttendances - Invalid end date format:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid end_date format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in < ?", time.Date(parsedEndDate.Year(), parsedEndDate.Month(), parsedEndDate.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1))
+	}
+
+	// Location filter if provided
+	locationID := c.Query("locationId", "")
+	if locationID != "" {
+		query = query.Where("location_id = ?", locationID)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Offset(offset).Limit(limit).Find(&attendances).Error; err != nil {
+		log.Println("GetLowConfidenceAttendances - Failed to retrieve attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve low confidence attendances",
+		})
+	}
+
+	// Format response
+	attendanceList := make([]models.AttendanceResponse, len(attendances))
+	for i, attendance := range attendances {
+		attendanceList[i] = *attendance.ToResponse()
+	}
+
+	// Build success message
+	message := "Low confidence attendances retrieved successfully"
+	filters := []string{fmt.Sprintf("maxConfidence: %.2f", maxConfidence)}
+
+	if startDate != "" {
+		filters = append(filters, "from: "+startDate)
+	}
+
+	if endDate != "" {
+		filters = append(filters, "to: "+endDate)
+	}
+
+	if locationID != "" {
+		filters = append(filters, "locationId: "+locationID)
+	}
+
+	message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+
+	log.Println("GetLowConfidenceAttendances completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    attendanceList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetMissingCheckouts retrieves attendances whose shift ended without a checkout for HR follow-up
+// @Summary Get Missing Checkouts
+// @Description Retrieve attendance records that were checked in but never checked out, still open or auto-closed at the shift end, with pagination
+// @Tags Attendances
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Param state query string false "Filter by state (open, autoClosed)"
+// @Param startDate query string false "Start date (YYYY-MM-DD format)"
+// @Param endDate query string false "End date (YYYY-MM-DD format)"
+// @Param teamId query int false "Filter by team ID"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/missing-checkouts [get]
+func (ac *AttendanceController) GetMissingCheckouts(c fiber.Ctx) error {
+	log.Println("GetMissingCheckouts called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var attendances []models.Attendance
+
+	// Build base query, open attendances of earlier days count even before the nightly job flagged them
+	now := time.Now()
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	query := ac.DB.WithContext(c.Context()).Model(&models.Attendance{}).Preload("User").Preload("Location").
+		Where("missing_checkout = ? OR (checked_out IS NULL AND checked_in < ?)", true, startOfToday).Order("checked_in DESC")
+
+	// State filter if provided
+	state := strings.TrimSpace(c.Query("state", ""))
+	switch state {
+	case "":
+	case "open":
+		query = query.Where("checked_out IS NULL")
+	case "autoClosed":
+		query = query.Where("auto_closed_at IS NOT NULL")
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid state. Use open or autoClosed.",
+		})
+	}
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in >= ?", parsedStartDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in < ?", parsedEndDate.AddDate(0, 0, 1))
+	}
+
+	// Filter by team if provided
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	if teamID > 0 {
+		query = query.Where("attendances.user_id IN (?)", utils.TeamMembersQuery(ac.DB, uint(teamID)))
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&attendances).Error; err != nil {
+		log.Println("GetMissingCheckouts - Failed to retrieve attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve missing checkouts",
+		})
+	}
+
+	// Format response
+	attendanceList := make([]models.AttendanceResponse, len(attendances))
+	for i, attendance := range attendances {
+		attendanceList[i] = *attendance.ToResponse()
+	}
+
+	// Build success message
+	message := "Missing checkouts retrieved successfully"
+	var filters []string
+
+	if state != "" {
+		filters = append(filters, "state: "+state)
+	}
+
+	if startDate != "" {
+		filters = append(filters, "from: "+startDate)
+	}
+
+	if endDate != "" {
+		filters = append(filters, "to: "+endDate)
+	}
+
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("teamId: %d", teamID))
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetMissingCheckouts completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    attendanceList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetBreakViolations retrieves the attendances whose breaks exceeded the allowed maximum
+// @Summary Get Break Violations
+// @Description Retrieve the attendances whose total break minutes exceeded the allowed maximum, latest first, with their breaks, pagination and date and team filters
+// @Tags Attendances
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of records per page" default(10)
+// @Param startDate query string false "Start date (YYYY-MM-DD format)"
+// @Param endDate query string false "End date (YYYY-MM-DD format)"
+// @Param teamId query int false "Filter by team ID"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/break-violations [get]
+func (ac *AttendanceController) GetBreakViolations(c fiber.Ctx) error {
+	log.Println("GetBreakViolations called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var attendances []models.Attendance
+
+	// Build base query
+	query := ac.DB.WithContext(c.Context()).Model(&models.Attendance{}).Preload("User").Preload("Location").
+		Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+			return db.Order("started_at ASC")
+		}).
+		Where("break_violation = ?", true).Order("checked_in DESC")
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in >= ?", parsedStartDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in < ?", parsedEndDate.AddDate(0, 0, 1))
+	}
+
+	// Filter by team if provided
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	if teamID > 0 {
+		query = query.Where("attendances.user_id IN (?)", utils.TeamMembersQuery(ac.DB, uint(teamID)))
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&attendances).Error; err != nil {
+		log.Println("GetBreakViolations - Failed to retrieve attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve break violations",
+		})
+	}
+
+	// Format response
+	attendanceList := make([]models.AttendanceResponse, len(attendances))
+	for i, attendance := range attendances {
+		attendanceList[i] = *attendance.ToResponse()
+	}
+
+	// Build success message
+	message := "Break violations retrieved successfully"
+	var filters []string
+
+	if startDate != "" {
+		filters = append(filters, "from: "+startDate)
+	}
+
+	if endDate != "" {
+		filters = append(filters, "to: "+endDate)
+	}
+
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("teamId: %d", teamID))
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetBreakViolations completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    attendanceList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetAttendanceByID retrieves a specific attendance record by ID
+// @Summary Get Attendance by ID
+// @Description Retrieve a specific attendance record by its ID
+// @Tags Attendances
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Attendance ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/{id} [get]
+func (ac *AttendanceController) GetAttendanceByID(c fiber.Ctx) error {
+	// Parse id paramameter
+	id := c.Params("id")
+	var attendance models.Attendance
+	if err := ac.DB.WithContext(c.Context()).Preload("User").Preload("Location").Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("started_at ASC")
+	}).First(&attendance, id).Error; err != nil {
+		log.Println("Attendance record not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance record not found",
+		})
+	}
+
+	log.Println("Attendance record retrieved successfully")
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Attendance record retrieved successfully",
+		Data:    attendance.ToResponse(),
+	})
+}
+
+// GetKioskSummary retrieves today's attendance overview for the lobby kiosk
+// @Summary Get Kiosk Attendance Summary
+// @Description Retrieve who checked in and out today, how many people are in the building and the late arrivals. Authenticated with the kiosk key instead of a user token.
+// @Tags Attendances
+// @Produce json
+// @Param X-Kiosk-Key header string true "Kiosk API key"
+// @Param teamId query int false "Only show the members of this team"
+// @Success 200 {object} utils.SuccessResponse{data=KioskSummaryResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Failure 503 {object} utils.ErrorResponse
+// @Router /api/attendances/kiosk-summary [get]
+func (ac *AttendanceController) GetKioskSummary(c fiber.Ctx) error {
+	log.Println("GetKioskSummary called")
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	query := ac.DB.WithContext(c.Context()).Preload("User").
+		Where("checked_in >= ? AND checked_in < ? AND checked = ?", startOfDay, endOfDay, true)
+
+	// Team boards only show their own members
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	if teamID > 0 {
+		query = query.Where("user_id IN (?)", utils.TeamMembersQuery(ac.DB, uint(teamID)))
+	}
+
+	var attendances []models.Attendance
+	if err := query.Order("checked_in DESC").Find(&attendances).Error; err != nil {
+		log.Println("GetKioskSummary - Failed to retrieve attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve attendances",
+		})
+	}
+
+	// Only names and times are shown on the kiosk, never locations or fraud signals
+	response := KioskSummaryResponse{
+		Date:         startOfDay.Format("02-01-2006"),
+		CheckedIn:    len(attendances),
+		Attendances:  make([]KioskAttendance, 0, len(attendances)),
+		LateArrivals: []KioskAttendance{},
+		GeneratedAt:  now.Format("02-01-2006 15:04:05"),
+	}
+	for _, attendance := range attendances {
+		entry := KioskAttendance{
+			UserID:     attendance.UserID,
+			FullName:   attendance.User.FullName,
+			Status:     attendance.Status,
+			Late:       attendance.Late,
+			CheckedIn:  attendance.CheckedIn.Format("02-01-2006 15:04:05"),
+			InBuilding: attendance.CheckedOut == nil,
+		}
+		if attendance.CheckedOut != nil {
+			formatted := attendance.CheckedOut.Format("02-01-2006 15:04:05")
+			entry.CheckedOut = &formatted
+			response.CheckedOut++
+		} else {
+			response.InBuilding++
+		}
+
+		response.Attendances = append(response.Attendances, entry)
+		if attendance.Late > 0 {
+			response.LateArrivals = append(response.LateArrivals, entry)
+		}
+	}
+	response.LateCount = len(response.LateArrivals)
+
+	// Latest arrivals are shown first on the board, the late list is sorted by minutes late
+	sort.SliceStable(response.LateArrivals, func(i, j int) bool {
+		return response.LateArrivals[i].Late > response.LateArrivals[j].Late
+	})
+
+	log.Println("GetKioskSummary completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Kiosk attendance summary retrieved successfully",
+		Data:    response,
+	})
+}
+
+// GetPendingOvertimes retrieves the attendances with overtime waiting for approval
+// @Summary Get Pending Overtimes
+// @Description Retrieve the attendances whose overtime is waiting for approval, oldest first, with pagination and team and date filters
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of attendances per page" default(10)
+// @Param startDate query string false "Filter by check in date from (YYYY-MM-DD format)"
+// @Param endDate query string false "Filter by check in date until (YYYY-MM-DD format)"
+// @Param teamId query int false "Filter by team ID"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/overtime/pending [get]
+func (ac *AttendanceController) GetPendingOvertimes(c fiber.Ctx) error {
+	log.Println("GetPendingOvertimes called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var attendances []models.Attendance
+
+	// Build base query
+	query := ac.DB.WithContext(c.Context()).Model(&models.Attendance{}).Preload("User").Preload("Location").
+		Where("overtime_status = ?", models.OvertimeStatusPending).Order("checked_in ASC")
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in >= ?", parsedStartDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in < ?", parsedEndDate.AddDate(0, 0, 1))
+	}
+
+	// Filter by team if provided
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	if teamID > 0 {
+		query = query.Where("attendances.user_id IN (?)", utils.TeamMembersQuery(ac.DB, uint(teamID)))
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&attendances).Error; err != nil {
+		log.Println("GetPendingOvertimes - Failed to retrieve attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pending overtimes",
+		})
+	}
+
+	// Format response
+	attendanceList := make([]models.AttendanceResponse, len(attendances))
+	for i, attendance := range attendances {
+		attendanceList[i] = *attendance.ToResponse()
+	}
+
+	// Build success message
+	message := "Pending overtimes retrieved successfully"
+	var filters []string
+
+	if startDate != "" {
+		filters = append(filters, "startDate: "+startDate)
+	}
+
+	if endDate != "" {
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("teamId: %d", teamID))
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetPendingOvertimes completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    attendanceList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// ReviewOvertime approves, adjusts or rejects the overtime of an attendance
+// @Summary Review Overtime
+// @Description Approve, adjust or reject the overtime recorded at checkout. approve pays the recorded minutes, adjust pays the given minutes and reject pays none. The user is notified of the outcome
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Attendance ID"
+// @Param request body ReviewOvertimeRequest true "Review action, adjusted minutes and note"
+// @Success 200 {object} utils.SuccessResponse{data=models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/{id}/overtime [put]
+func (ac *AttendanceController) ReviewOvertime(c fiber.Ctx) error {
+	log.Println("ReviewOvertime called")
+	// Parse id parameter
+	id := c.Params("id")
+	var attendance models.Attendance
+	if err := ac.DB.WithContext(c.Context()).Where("id = ?", id).First(&attendance).Error; err != nil {
+		log.Println("ReviewOvertime - Attendance not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ReviewOvertime - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req ReviewOvertimeRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ReviewOvertime - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	req.Note = strings.TrimSpace(req.Note)
+
+	if attendance.OvertimeStatus != models.OvertimeStatusPending {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance has no overtime waiting for approval",
+		})
+	}
+
+	// Users cannot approve their own overtime
+	if attendance.UserID == uint(userID) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You cannot review your own overtime",
+		})
+	}
+
+	var status string
+	var approvedOvertime int
+	switch req.Action {
+	case "approve":
+		status, approvedOvertime = models.OvertimeStatusApproved, attendance.Overtime
+	case "adjust":
+		if req.Minutes == nil || *req.Minutes < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Adjusted minutes are required and cannot be negative",
+			})
+		}
+		if req.Note == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "A note is required to adjust overtime",
+			})
+		}
+		status, approvedOvertime = models.OvertimeStatusAdjusted, *req.Minutes
+	case "reject":
+		if req.Note == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "A note is required to reject overtime",
+			})
+		}
+		status, approvedOvertime = models.OvertimeStatusRejected, 0
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid action. Use approve, adjust or reject.",
+		})
+	}
+
+	now := time.Now()
+	reviewerID := uint(userID)
+	err = ac.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Attendance{}).Where("id = ? AND overtime_status = ?", attendance.ID, models.OvertimeStatusPending).Updates(map[string]interface{}{
+			"overtime_status":      status,
+			"approved_overtime":    approvedOvertime,
+			"overtime_reviewed_by": reviewerID,
+			"overtime_reviewed_at": now,
+			"overtime_review_note": req.Note,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOvertimeReviewConflict
+		}
+
+		return utils.NotifyUsers(tx, []uint{attendance.UserID}, "overtime_review", "Overtime "+status,
+			fmt.Sprintf("Your overtime of %d minutes on %s was %s, %d minutes are paid", attendance.Overtime, attendance.CheckedIn.Format("02-01-2006"), status, approvedOvertime), "attendance", attendance.ID)
+	})
+	if errors.Is(err, errOvertimeReviewConflict) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Overtime was reviewed in the meantime, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("ReviewOvertime - Failed to review overtime:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to review overtime",
+		})
+	}
+
+	// Reload the attendance with all relationships for response
+	if err := ac.DB.WithContext(c.Context()).Preload("User").Preload("Location").Preload("OvertimeReviewUser").First(&attendance, attendance.ID).Error; err != nil {
+		log.Println("ReviewOvertime - Failed to load attendance:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load attendance",
+		})
+	}
+
+	log.Println("ReviewOvertime completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Overtime " + status + " successfully",
+		Data:    attendance.ToResponse(),
+	})
+}
+
+// errOvertimeReviewConflict is returned when the overtime was reviewed by someone else in the meantime
+var errOvertimeReviewConflict = errors.New("overtime already reviewed")
+
+// GetAttendanceSummary summarizes attendance and approved overtime per user for payroll
+// @Summary Get Attendance Summary
+// @Description Summarize days present, late minutes, overtime, breaks and worked minutes per user over a period, optionally exported to XLSX for payroll. Only approved or adjusted overtime is counted as paid, pending overtime is listed separately. Break minutes are deducted from the worked minutes
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD format, default first day of the current month)"
+// @Param endDate query string false "End date (YYYY-MM-DD format, default today)"
+// @Param teamId query int false "Filter by team ID"
+// @Param format query string false "Response format (json or xlsx)" default(json)
+// @Success 200 {object} utils.SuccessResponse{data=AttendanceSummaryResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/summary [get]
+func (ac *AttendanceController) GetAttendanceSummary(c fiber.Ctx) error {
+	log.Println("GetAttendanceSummary called")
+	// Parse query parameters
+	now := time.Now()
+	startDate := c.Query("startDate", now.Format("2006-01")+"-01")
+	endDate := c.Query("endDate", now.Format("2006-01-02"))
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	format := strings.ToLower(c.Query("format", "json"))
+
+	// Parse dates and validate format
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	if format != "json" && format != "xlsx" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or xlsx.",
+		})
+	}
+
+	rows, err := summarizeAttendances(ac.DB.WithContext(c.Context()), start, end, uint(teamID))
+	if err != nil {
+		log.Println("GetAttendanceSummary - Failed to summarize attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to summarize attendances",
+		})
+	}
+
+	// Export to XLSX if requested
+	if format == "xlsx" {
+		headers := []string{"Username", "Full Name", "Days", "Fullday", "Halfday", "Late (min)", "Approved Overtime (min)", "Pending Overtime (min)", "Break (min)", "Worked (min)", "Break Violations"}
+		xlsxRows := make([][]interface{}, 0, len(rows))
+		for _, row := range rows {
+			xlsxRows = append(xlsxRows, []interface{}{row.Username, row.FullName, row.Days, row.Fullday, row.Halfday, row.Late, row.ApprovedOvertime, row.PendingOvertime,
+				row.BreakMinutes, row.WorkedMinutes, row.BreakViolations})
+		}
+
+		buffer, err := utils.BuildXLSX("Attendance "+startDate, headers, xlsxRows)
+		if err != nil {
+			log.Println("GetAttendanceSummary - Failed to build XLSX:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to export attendance summary",
+			})
+		}
+
+		log.Println("GetAttendanceSummary completed successfully")
+		c.Set(fiber.HeaderContentType, utils.XLSXContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"attendance-summary-%s-%s.xlsx\"", startDate, endDate))
+		return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+	}
+
+	// Build success message
+	message := "Attendance summary retrieved successfully"
+	filters := []string{"startDate: " + startDate, "endDate: " + endDate}
+
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("teamId: %d", teamID))
+	}
+
+	message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+
+	log.Println("GetAttendanceSummary completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: AttendanceSummaryResponse{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Users:     rows,
+		},
+	})
+}
+
+// summarizeAttendances aggregates attendances per user, only reviewed overtime counts as paid and breaks are deducted from the worked minutes
+func summarizeAttendances(db *gorm.DB, start, end time.Time, teamID uint) ([]AttendanceSummaryRow, error) {
+	query := db.Table("attendances").
+		Select(`attendances.user_id AS user_id, users.username AS username, users.full_name AS full_name,
+			COUNT(*) AS days,
+			COALESCE(SUM(CASE WHEN attendances.status = 'fullday' THEN 1 ELSE 0 END), 0) AS fullday,
+			COALESCE(SUM(CASE WHEN attendances.status = 'halfday' THEN 1 ELSE 0 END), 0) AS halfday,
+			COALESCE(SUM(attendances.late), 0) AS late,
+			COALESCE(SUM(CASE WHEN attendances.overtime_status IN (?, ?) THEN attendances.approved_overtime ELSE 0 END), 0) AS approved_overtime,
+			COALESCE(SUM(CASE WHEN attendances.overtime_status = ? THEN attendances.overtime ELSE 0 END), 0) AS pending_overtime,
+			COALESCE(SUM(attendances.break_minutes), 0) AS break_minutes,
+			COALESCE(SUM(CASE WHEN attendances.checked_out IS NOT NULL THEN FLOOR(EXTRACT(EPOCH FROM attendances.checked_out - attendances.checked_in) / 60) - attendances.break_minutes ELSE 0 END), 0) AS worked_minutes,
+			COALESCE(SUM(CASE WHEN attendances.break_violation THEN 1 ELSE 0 END), 0) AS break_violations`,
+			models.OvertimeStatusApproved, models.OvertimeStatusAdjusted, models.OvertimeStatusPending).
+		Joins("JOIN users ON users.id = attendances.user_id").
+		Where("attendances.checked_in >= ? AND attendances.checked_in < ?", start, end)
+
+	if teamID > 0 {
+		query = query.Where("attendances.user_id IN (?)", utils.TeamMembersQuery(db, teamID))
+	}
+
+	rows := []AttendanceSummaryRow{}
+	if err := query.Group("attendances.user_id, users.username, users.full_name").Order("users.full_name ASC").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Attendance export templates
+const (
+	AttendanceExportRaw        = "raw"        // every attendance record with all its fields
+	AttendanceExportPayroll    = "payroll"    // totals per employee for payroll
+	AttendanceExportCompliance = "compliance" // daily attendance register per employee for government and BPJS reporting
+)
+
+// maxAttendanceExportDays caps the date range of an attendance export
+const maxAttendanceExportDays = 366
+
+// Attendance codes of the compliance register
+const (
+	complianceCodePresent = "H"  // hadir, full day
+	complianceCodeHalfday = "HS" // hadir setengah hari
+	complianceCodeAbsent  = "TH" // tidak hadir, no attendance recorded
+)
+
+// buildAttendanceExport returns the builder generating the XLSX attendance export of a template
+func buildAttendanceExport(template string, start, end time.Time, teamID uint) utils.ExportBuilder {
+	return func(ctx context.Context, db *gorm.DB) (*utils.ExportResult, error) {
+		db = db.WithContext(ctx)
+		lastDate := end.AddDate(0, 0, -1).Format("2006-01-02")
+
+		var headers []string
+		var rows [][]interface{}
+		switch template {
+		case AttendanceExportPayroll:
+			summary, err := summarizeAttendances(db, start, end, teamID)
+			if err != nil {
+				return nil, err
+			}
+			headers = []string{"Employee ID", "Employee Name", "Working Days", "Fullday", "Halfday", "Late (min)", "Approved Overtime (min)", "Approved Overtime (hours)", "Pending Overtime (min)",
+				"Break (min)", "Worked (hours)", "Break Violations"}
+			for _, row := range summary {
+				rows = append(rows, []interface{}{row.Username, row.FullName, row.Days, row.Fullday, row.Halfday, row.Late,
+					row.ApprovedOvertime, math.Round(float64(row.ApprovedOvertime)/60*100) / 100, row.PendingOvertime,
+					row.BreakMinutes, math.Round(float64(row.WorkedMinutes)/60*100) / 100, row.BreakViolations})
+			}
+
+		case AttendanceExportRaw, AttendanceExportCompliance:
+			query := db.Preload("User").Preload("Location").
+				Where("checked_in >= ? AND checked_in < ?", start, end).
+				Order("user_id ASC, checked_in ASC")
+			if teamID > 0 {
+				query = query.Where("user_id IN (?)", utils.TeamMembersQuery(db, teamID))
+			}
+			var attendances []models.Attendance
+			if err := query.Find(&attendances).Error; err != nil {
+				return nil, err
+			}
+
+			if template == AttendanceExportRaw {
+				headers = []string{"Employee ID", "Employee Name", "Date", "Status", "Check In", "Check Out", "Late (min)", "Overtime (min)",
+					"Overtime Status", "Approved Overtime (min)", "Break (min)", "Break Violation", "Location", "Entry Method", "Fallback Status", "Suspicious"}
+				for _, attendance := range attendances {
+					checkedOut := ""
+					if attendance.CheckedOut != nil {
+						checkedOut = attendance.CheckedOut.Format("02-01-2006 15:04:05")
+					}
+					rows = append(rows, []interface{}{attendance.User.Username, attendance.User.FullName, attendance.CheckedIn.Format("02-01-2006"),
+						attendance.Status, attendance.CheckedIn.Format("02-01-2006 15:04:05"), checkedOut, attendance.Late, attendance.Overtime,
+						attendance.OvertimeStatus, attendance.ApprovedOvertime, attendance.BreakMinutes, attendance.BreakViolation, attendance.Location.Name, attendance.EntryMethod,
+						attendance.FallbackStatus, attendance.Suspicious})
+				}
+			} else {
+				headers = []string{"No", "Employee ID", "Employee Name", "Date", "Day", "Attendance Code", "Check In", "Check Out", "Working Hours", "Late (min)", "Approved Overtime (min)"}
+				rows = attendanceComplianceRows(attendances, start, end)
+			}
+
+		default:
+			return nil, fmt.Errorf("unknown attendance export template %s", template)
+		}
+
+		buffer, err := utils.BuildXLSX("Attendance "+template, headers, rows)
+		if err != nil {
+			return nil, err
+		}
+		return &utils.ExportResult{
+			FileName:    fmt.Sprintf("attendance-%s-%s-%s.xlsx", template, start.Format("2006-01-02"), lastDate),
+			ContentType: utils.XLSXContentType,
+			Content:     buffer.Bytes(),
+			RowCount:    len(rows),
+		}, nil
+	}
+}
+
+// attendanceComplianceRows lays out one row per employee per day of the range, days without attendance are marked absent.
+// Attendances must be ordered by user and check in time.
+func attendanceComplianceRows(attendances []models.Attendance, start, end time.Time) [][]interface{} {
+	// Group the attendances per employee and day
+	var users []models.User
+	days := make(map[uint]map[string][]models.Attendance)
+	for _, attendance := range attendances {
+		if _, ok := days[attendance.UserID]; !ok {
+			users = append(users, attendance.User)
+			days[attendance.UserID] = make(map[string][]models.Attendance)
+		}
+		date := attendance.CheckedIn.Format("2006-01-02")
+		days[attendance.UserID][date] = append(days[attendance.UserID][date], attendance)
+	}
+	sort.SliceStable(users, func(i, j int) bool { return users[i].FullName < users[j].FullName })
+
+	var rows [][]interface{}
+	for _, user := range users {
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			entries := days[user.ID][day.Format("2006-01-02")]
+			code, checkIn, checkOut := complianceCodeAbsent, "", ""
+			workingHours := 0.0
+			late, overtime := 0, 0
+			if len(entries) > 0 {
+				// First check in and last check out of the day
+				code = complianceCodePresent
+				checkIn = entries[0].CheckedIn.Format("15:04")
+				var lastCheckOut *time.Time
+				for _, entry := range entries {
+					if entry.Status == "halfday" {
+						code = complianceCodeHalfday
+					}
+					if entry.CheckedOut != nil {
+						lastCheckOut = entry.CheckedOut
+						workingHours += entry.CheckedOut.Sub(entry.CheckedIn).Hours() - float64(entry.BreakMinutes)/60
+					}
+					late += entry.Late
+					if entry.OvertimeStatus == models.OvertimeStatusApproved || entry.OvertimeStatus == models.OvertimeStatusAdjusted {
+						overtime += entry.ApprovedOvertime
+					}
+				}
+				if lastCheckOut != nil {
+					checkOut = lastCheckOut.Format("15:04")
+				}
+			}
+			rows = append(rows, []interface{}{len(rows) + 1, user.Username, user.FullName, day.Format("02-01-2006"), day.Weekday().String(),
+				code, checkIn, checkOut, math.Round(workingHours*100) / 100, late, overtime})
+		}
+	}
+	return rows
+}
+
+// ExportAttendances starts a background XLSX export of the attendances
+// @Summary Export Attendances
+// @Description Start a background XLSX export of the attendances over a date range (at most 366 days), optionally limited to a team. Templates: raw (every attendance record), payroll (totals per employee with approved overtime) and compliance (daily register per employee with attendance codes H = present, HS = half day, TH = absent, for government and BPJS reporting). Poll the returned export job and download it from /api/exports/{id}/download once completed
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ExportAttendancesRequest true "Export template and filters"
+// @Success 202 {object} utils.SuccessResponse{data=models.ExportJobResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/export [post]
+func (ac *AttendanceController) ExportAttendances(c fiber.Ctx) error {
+	log.Println("ExportAttendances called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("ExportAttendances - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req ExportAttendancesRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ExportAttendances - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Template = strings.ToLower(strings.TrimSpace(req.Template))
+	if req.Template != AttendanceExportRaw && req.Template != AttendanceExportPayroll && req.Template != AttendanceExportCompliance {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid template. Use raw, payroll or compliance.",
+		})
+	}
+
+	// Parse dates and validate format
+	start, err := time.ParseInLocation("2006-01-02", req.StartDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", req.EndDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+	if end.Sub(start) > maxAttendanceExportDays*24*time.Hour {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Date range must not exceed %d days", maxAttendanceExportDays),
+		})
+	}
+
+	// Validate team filter if provided
+	if req.TeamID > 0 {
+		var team models.Team
+		if err := ac.DB.Where("id = ?", req.TeamID).First(&team).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Team with id %d not found", req.TeamID),
+			})
+		}
+	}
+
+	filters := map[string]string{
+		"template":  req.Template,
+		"startDate": req.StartDate,
+		"endDate":   req.EndDate,
+	}
+	if req.TeamID > 0 {
+		filters["teamId"] = strconv.FormatUint(uint64(req.TeamID), 10)
+	}
+
+	job, err := utils.StartExportJob(ac.DB, "attendance", filters, uint(userID), buildAttendanceExport(req.Template, start, end, req.TeamID))
+	if err != nil {
+		log.Println("ExportAttendances - Failed to start export job:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to start attendance export",
+		})
+	}
+
+	log.Println("ExportAttendances completed successfully")
+	return c.Status(fiber.StatusAccepted).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Attendance export started, download it from export job " + strconv.FormatUint(uint64(job.ID), 10) + " once completed",
+		Data:    job.ToResponse(),
+	})
+}
+
+// GetFaceStatus reports whether face recognition is available
+// @Summary Get Face Recognition Status
+// @Description Report whether the face recognition service is available. When it is not, the kiosk switches to manual check-in and check-out, which are recorded as fallback entries for HR verification
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse{data=FaceStatusResponse}
+// @Router /api/attendances/face-status [get]
+func (ac *AttendanceController) GetFaceStatus(c fiber.Ctx) error {
+	status := utils.CheckDeepFaceHealth(c.Context())
+
+	message := "Face recognition is available"
+	if !status.Available {
+		message = "Face recognition is unavailable, manual check-in is enabled"
+	}
+
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: FaceStatusResponse{
+			Available:      status.Available,
+			ManualFallback: !status.Available,
+			Error:          status.Error,
+			CheckedAt:      status.CheckedAt.Format("02-01-2006 15:04:05"),
+		},
+	})
+}
+
+// GetFallbackAttendances retrieves the attendances recorded manually while face recognition was unavailable
+// @Summary Get Fallback Attendances
+// @Description Retrieve the manual check-ins and check-outs recorded while face recognition was unavailable, oldest first, for HR verification, optionally exported to XLSX
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of attendances per page" default(10)
+// @Param status query string false "Filter by fallback status (pending, verified, rejected)"
+// @Param startDate query string false "Filter by check in date from (YYYY-MM-DD format)"
+// @Param endDate query string false "Filter by check in date until (YYYY-MM-DD format)"
+// @Param teamId query int false "Filter by team ID"
+// @Param format query string false "Response format (json or xlsx)" default(json)
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/fallbacks [get]
+func (ac *AttendanceController) GetFallbackAttendances(c fiber.Ctx) error {
+	log.Println("GetFallbackAttendances called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+	format := strings.ToLower(c.Query("format", "json"))
+
+	if format != "json" && format != "xlsx" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or xlsx.",
+		})
+	}
+
+	var attendances []models.Attendance
+
+	// Build base query
+	query := ac.DB.WithContext(c.Context()).Model(&models.Attendance{}).Preload("User").Preload("Location").Preload("FallbackReviewUser").
+		Where("fallback_status <> ''").Order("checked_in ASC")
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		if status != models.FallbackStatusPending && status != models.FallbackStatusVerified && status != models.FallbackStatusRejected {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status. Use pending, verified or rejected.",
+			})
+		}
+		query = query.Where("fallback_status = ?", status)
+	}
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in >= ?", parsedStartDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("checked_in < ?", parsedEndDate.AddDate(0, 0, 1))
+	}
+
+	// Filter by team if provided
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	if teamID > 0 {
+		query = query.Where("attendances.user_id IN (?)", utils.TeamMembersQuery(ac.DB, uint(teamID)))
+	}
+
+	// Export every matching attendance to XLSX if requested
+	if format == "xlsx" {
+		if err := query.Find(&attendances).Error; err != nil {
+			log.Println("GetFallbackAttendances - Failed to retrieve attendances:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve fallback attendances",
+			})
+		}
+
+		headers := []string{"Attendance ID", "User", "Status", "Checked In", "Checked Out", "Fallback Reason", "Fallback Status", "Reviewed By", "Reviewed At", "Review Note"}
+		rows := make([][]interface{}, 0, len(attendances))
+		for _, attendance := range attendances {
+			response := attendance.ToResponse()
+			var reviewedBy, reviewedAt string
+			if response.FallbackReviewedBy != nil {
+				reviewedBy = *response.FallbackReviewedBy
+			}
+			if response.FallbackReviewedAt != nil {
+				reviewedAt = *response.FallbackReviewedAt
+			}
+			rows = append(rows, []interface{}{response.ID, response.User, response.Status, response.CheckedIn, response.CheckedOut,
+				response.FallbackReason, response.FallbackStatus, reviewedBy, reviewedAt, response.FallbackReviewNote})
+		}
+
+		buffer, err := utils.BuildXLSX("Fallback Attendances", headers, rows)
+		if err != nil {
+			log.Println("GetFallbackAttendances - Failed to build XLSX:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to export fallback attendances",
+			})
+		}
+
+		log.Println("GetFallbackAttendances completed successfully")
+		c.Set(fiber.HeaderContentType, utils.XLSXContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"fallback-attendances-%s.xlsx\"", time.Now().Format("2006-01-02")))
+		return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&attendances).Error; err != nil {
+		log.Println("GetFallbackAttendances - Failed to retrieve attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve fallback attendances",
+		})
+	}
+
+	// Format response
+	attendanceList := make([]models.AttendanceResponse, len(attendances))
+	for i, attendance := range attendances {
+		attendanceList[i] = *attendance.ToResponse()
+	}
+
+	// Build success message
+	message := "Fallback attendances retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if startDate != "" {
+		filters = append(filters, "startDate: "+startDate)
+	}
+
+	if endDate != "" {
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("teamId: %d", teamID))
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetFallbackAttendances completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    attendanceList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// ReviewFallbackAttendance verifies or rejects a fallback attendance
+// @Summary Review Fallback Attendance
+// @Description Verify or reject a manual attendance recorded while face recognition was unavailable. The reviewing supervisor and note are logged, rejected entries are flagged as suspicious
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Attendance ID"
+// @Param request body ReviewFallbackAttendanceRequest true "Review action and note"
+// @Success 200 {object} utils.SuccessResponse{data=models.AttendanceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/{id}/fallback-review [put]
+func (ac *AttendanceController) ReviewFallbackAttendance(c fiber.Ctx) error {
+	log.Println("ReviewFallbackAttendance called")
+	// Parse id parameter
+	id := c.Params("id")
+	var attendance models.Attendance
+	if err := ac.DB.WithContext(c.Context()).Where("id = ?", id).First(&attendance).Error; err != nil {
+		log.Println("ReviewFallbackAttendance - Attendance not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ReviewFallbackAttendance - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req ReviewFallbackAttendanceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ReviewFallbackAttendance - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	req.Note = strings.TrimSpace(req.Note)
+
+	if attendance.FallbackStatus != models.FallbackStatusPending {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance is not a fallback entry waiting for verification",
+		})
+	}
+
+	// Users cannot verify their own attendance
+	if attendance.UserID == uint(userID) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You cannot review your own attendance",
+		})
+	}
+
+	updates := map[string]interface{}{
+		"fallback_reviewed_by": uint(userID),
+		"fallback_reviewed_at": time.Now(),
+		"fallback_review_note": req.Note,
+	}
+	switch req.Action {
+	case "verify":
+		updates["fallback_status"] = models.FallbackStatusVerified
+	case "reject":
+		if req.Note == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "A note is required to reject a fallback attendance",
+			})
+		}
+		updates["fallback_status"] = models.FallbackStatusRejected
+		updates["suspicious"] = true
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid action. Use verify or reject.",
+		})
+	}
+
+	result := ac.DB.WithContext(c.Context()).Model(&models.Attendance{}).Where("id = ? AND fallback_status = ?", attendance.ID, models.FallbackStatusPending).Updates(updates)
+	if result.Error != nil {
+		log.Println("ReviewFallbackAttendance - Failed to review attendance:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to review fallback attendance",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance was reviewed in the meantime, please reload and try again.",
+		})
+	}
+
+	// Reload the attendance with all relationships for response
+	if err := ac.DB.WithContext(c.Context()).Preload("User").Preload("Location").Preload("FallbackReviewUser").First(&attendance, attendance.ID).Error; err != nil {
+		log.Println("ReviewFallbackAttendance - Failed to load attendance:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load attendance",
+		})
+	}
+
+	log.Println("ReviewFallbackAttendance completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Fallback attendance " + attendance.FallbackStatus + " successfully",
+		Data:    attendance.ToResponse(),
+	})
+}
+
+// maxBackfillUsers caps the users of one attendance backfill
+const maxBackfillUsers = 500
+
+// Backfill results per user
+const (
+	backfillResultCreated   = "created"
+	backfillResultCompleted = "completed" // an open attendance of the day was given the checkout
+	backfillResultSkipped   = "skipped"
+)
+
+// backfillAttendanceStatus derives the status and late minutes of a backfilled check-in with the kiosk shift rules,
+// without the check-in windows the kiosk enforces since the times are entered after the fact
+func backfillAttendanceStatus(checkIn time.Time) (string, int) {
+	fulldayWorkStart := time.Date(checkIn.Year(), checkIn.Month(), checkIn.Day(), 8, 0, 0, 0, checkIn.Location())
+	halfdayCheckInStart := time.Date(checkIn.Year(), checkIn.Month(), checkIn.Day(), 11, 30, 0, 0, checkIn.Location())
+	halfdayWorkStart := time.Date(checkIn.Year(), checkIn.Month(), checkIn.Day(), 12, 30, 0, 0, checkIn.Location())
+
+	status, workStart := "fullday", fulldayWorkStart
+	if !checkIn.Before(halfdayCheckInStart) {
+		status, workStart = "halfday", halfdayWorkStart
+	}
+	late := 0
+	if checkIn.After(workStart) {
+		late = int(checkIn.Sub(workStart).Minutes())
+	}
+	return status, late
+}
+
+// backfillAttendanceCheckOut checks out a backfilled attendance with the kiosk checkout rules: a fullday attendance
+// leaving before the regular checkout becomes halfday, after 17:00 it earns overtime up for approval
+func backfillAttendanceCheckOut(attendance *models.Attendance, checkOut time.Time) {
+	regularCheckOut := time.Date(checkOut.Year(), checkOut.Month(), checkOut.Day(), 17, 0, 0, 0, checkOut.Location())
+	regularCheckOutStart := regularCheckOut.Add(-5 * time.Minute)
+
+	attendance.CheckedOut = &checkOut
+	attendance.Checked = false
+	overtime := 0
+	if attendance.Status == "fullday" {
+		if checkOut.Before(regularCheckOutStart) {
+			attendance.Status = "halfday"
+		} else if checkOut.After(regularCheckOut) {
+			overtime = int(checkOut.Sub(regularCheckOut).Minutes())
+		}
+	}
+	attendance.SetOvertime(overtime)
+}
+
+// BackfillAttendances enters the attendance of a group of users for an incident the kiosk could not record
+// @Summary Backfill Attendances
+// @Description Enter the check-in and check-out of a group of users the kiosk could not record, e.g. while face recognition was down. An incident reference is required, every attendance is tagged with the backfill entry method and linked to the recorded backfill. Users already checked in that day are given the checkout when their attendance is still open, otherwise they are skipped
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BackfillAttendancesRequest true "Users, date, times and incident reference"
+// @Success 201 {object} utils.SuccessResponse{data=BackfillAttendancesResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/backfills [post]
+func (ac *AttendanceController) BackfillAttendances(c fiber.Ctx) error {
+	log.Println("BackfillAttendances called")
+	// Binding request body
+	var req BackfillAttendancesRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("BackfillAttendances - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.IncidentReference = strings.TrimSpace(req.IncidentReference)
+	if req.IncidentReference == "" || len(req.IncidentReference) > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Incident reference is required and must be at most 100 characters",
+		})
+	}
+
+	// Drop repeated user IDs, keeping the requested order
+	var userIDs []uint
+	seen := make(map[uint]bool)
+	for _, id := range req.UserIDs {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			userIDs = append(userIDs, id)
+		}
+	}
+	if len(userIDs) == 0 || len(userIDs) > maxBackfillUsers {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Between 1 and %d user IDs are required", maxBackfillUsers),
+		})
+	}
+
+	// Parse the date and times, in server local time like the kiosk
+	date, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid date format. Use YYYY-MM-DD.",
+		})
+	}
+	checkInClock, err := time.Parse("15:04", req.CheckIn)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid checkIn format. Use HH:MM.",
+		})
+	}
+	checkIn := date.Add(time.Duration(checkInClock.Hour())*time.Hour + time.Duration(checkInClock.Minute())*time.Minute)
+
+	var checkOut *time.Time
+	if req.CheckOut != "" {
+		checkOutClock, err := time.Parse("15:04", req.CheckOut)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid checkOut format. Use HH:MM.",
+			})
+		}
+		parsed := date.Add(time.Duration(checkOutClock.Hour())*time.Hour + time.Duration(checkOutClock.Minute())*time.Minute)
+		if !parsed.After(checkIn) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "checkOut must be after checkIn",
+			})
+		}
+		checkOut = &parsed
+	}
+
+	// Only what already happened can be backfilled
+	now := time.Now()
+	if checkIn.After(now) || (checkOut != nil && checkOut.After(now)) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Backfilled times cannot be in the future",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	currentUserID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	idStrings := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		idStrings[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	backfill := models.AttendanceBackfill{
+		IncidentReference: req.IncidentReference,
+		Date:              date,
+		CheckIn:           checkIn,
+		CheckOut:          checkOut,
+		Note:              strings.TrimSpace(req.Note),
+		UserIDs:           strings.Join(idStrings, ","),
+		CreatedBy:         uint(currentUserID),
+	}
+
+	results := make([]BackfillAttendanceResult, len(userIDs))
+	var attendanceIDs []uint
+	startOfDay := date
+	endOfDay := date.AddDate(0, 0, 1)
+	err = ac.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&backfill).Error; err != nil {
+			return err
+		}
+
+		var users []models.User
+		if err := tx.Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+			return err
+		}
+		usersByID := make(map[uint]models.User, len(users))
+		for _, user := range users {
+			usersByID[user.ID] = user
+		}
+
+		for i, id := range userIDs {
+			results[i] = BackfillAttendanceResult{UserID: id, Result: backfillResultSkipped}
+			user, ok := usersByID[id]
+			if !ok {
+				results[i].Reason = "User not found"
+				continue
+			}
+			if !user.IsActive {
+				results[i].Reason = "User is inactive"
+				continue
+			}
+
+			// A user the kiosk did record that day keeps their attendance, an open one is only given the checkout
+			var existing models.Attendance
+			err := tx.Where("user_id = ? AND checked_in >= ? AND checked_in < ?", id, startOfDay, endOfDay).Order("checked_in DESC").First(&existing).Error
+			if err == nil {
+				if existing.CheckedOut != nil || checkOut == nil {
+					results[i].Reason = "User already has an attendance on " + req.Date
+					continue
+				}
+				if !checkOut.After(existing.CheckedIn) {
+					results[i].Reason = "checkOut is before the recorded check-in at " + existing.CheckedIn.Format("15:04")
+					continue
+				}
+				backfillAttendanceCheckOut(&existing, *checkOut)
+				existing.BackfillID = &backfill.ID
+				if err := ac.Breaks.CloseOpenBreak(tx, &existing, *checkOut); err != nil {
+					return err
+				}
+				if err := tx.Save(&existing).Error; err != nil {
+					return err
+				}
+				results[i].Result = backfillResultCompleted
+				attendanceIDs = append(attendanceIDs, existing.ID)
+				backfill.Completed++
+				continue
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			status, late := backfillAttendanceStatus(checkIn)
+			attendance := models.Attendance{
+				UserID:      id,
+				CheckedIn:   checkIn,
+				Checked:     true,
+				Status:      status,
+				Late:        late,
+				LocationID:  kioskLocationID,
+				EntryMethod: models.EntryMethodBackfill,
+				BackfillID:  &backfill.ID,
+			}
+			if checkOut != nil {
+				backfillAttendanceCheckOut(&attendance, *checkOut)
+			}
+			if err := tx.Create(&attendance).Error; err != nil {
+				return err
+			}
+			results[i].Result = backfillResultCreated
+			attendanceIDs = append(attendanceIDs, attendance.ID)
+			backfill.Created++
+		}
+
+		backfill.Skipped = len(userIDs) - backfill.Created - backfill.Completed
+		return tx.Model(&backfill).Updates(map[string]interface{}{
+			"created":   backfill.Created,
+			"completed": backfill.Completed,
+			"skipped":   backfill.Skipped,
+		}).Error
+	})
+	if err != nil {
+		log.Println("BackfillAttendances - Failed to backfill attendances:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to backfill attendances",
+		})
+	}
+
+	// Reload the backfilled attendances for the results
+	var attendances []models.Attendance
+	if len(attendanceIDs) > 0 {
+		ac.DB.WithContext(c.Context()).Preload("User").Preload("Location").Preload("Backfill").Where("id IN ?", attendanceIDs).Find(&attendances)
+	}
+	attendancesByUser := make(map[uint]*models.AttendanceResponse, len(attendances))
+	for _, attendance := range attendances {
+		attendancesByUser[attendance.UserID] = attendance.ToResponse()
+	}
+	for i := range results {
+		if results[i].Result != backfillResultSkipped {
+			results[i].Attendance = attendancesByUser[results[i].UserID]
+		}
+	}
+	ac.DB.WithContext(c.Context()).Preload("CreateUser").Where("id = ?", backfill.ID).First(&backfill)
+
+	log.Println("BackfillAttendances completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Attendances backfilled for incident %s: %d created, %d completed, %d skipped", backfill.IncidentReference, backfill.Created, backfill.Completed, backfill.Skipped),
+		Data: BackfillAttendancesResponse{
+			Backfill: backfill.ToResponse(),
+			Results:  results,
+		},
+	})
+}
+
+// GetAttendanceBackfills retrieves the recorded attendance backfills
+// @Summary Get Attendance Backfills
+// @Description Retrieve the attendance backfills entered by HR with their incident reference, author and counts, latest first
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param search query string false "Search term for incident reference"
+// @Param startDate query string false "Filter by backfilled date from (YYYY-MM-DD)"
+// @Param endDate query string false "Filter by backfilled date to (YYYY-MM-DD)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.AttendanceBackfillResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/attendances/backfills [get]
+func (ac *AttendanceController) GetAttendanceBackfills(c fiber.Ctx) error {
+	log.Println("GetAttendanceBackfills called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var backfills []models.AttendanceBackfill
+
+	// Build base query
+	query := ac.DB.WithContext(c.Context()).Model(&models.AttendanceBackfill{}).Preload("CreateUser").Order("created_at DESC")
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("incident_reference ILIKE ?", "%"+search+"%")
+	}
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("date >= ?", parsedStartDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("date <= ?", parsedEndDate)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&backfills).Error; err != nil {
+		log.Println("GetAttendanceBackfills - Failed to retrieve attendance backfills:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve attendance backfills",
+		})
+	}
+
+	// Format response
+	backfillList := make([]models.AttendanceBackfillResponse, len(backfills))
+	for i, backfill := range backfills {
+		backfillList[i] = *backfill.ToResponse()
+	}
+
+	// Build success message
+	message := "Attendance backfills retrieved successfully"
+	var filters []string
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if startDate != "" {
+		filters = append(filters, "startDate: "+startDate)
+	}
+
+	if endDate != "" {
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetAttendanceBackfills completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    backfillList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetAttendanceBackfill retrieves a single attendance backfill with its attendances
+// @Summary Get Attendance Backfill
+// @Description Retrieve an attendance backfill by ID with the attendances it created or completed
+// @Tags Attendances
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Attendance Backfill ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.AttendanceBackfillResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/attendances/backfills/{id} [get]
+func (ac *AttendanceController) GetAttendanceBackfill(c fiber.Ctx) error {
+	log.Println("GetAttendanceBackfill called")
+	// Parse id parameter
+	id := c.Params("id")
+	var backfill models.AttendanceBackfill
+	if err := ac.DB.WithContext(c.Context()).Preload("CreateUser").
+		Preload("Attendances", func(db *gorm.DB) *gorm.DB { return db.Order("checked_in ASC") }).
+		Preload("Attendances.User").Preload("Attendances.Location").
+		Where("id = ?", id).First(&backfill).Error; err != nil {
+		log.Println("GetAttendanceBackfill - Attendance backfill not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Attendance backfill with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetAttendanceBackfill completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Attendance backfill retrieved successfully",
+		Data:    backfill.ToResponse(),
 	})
 }
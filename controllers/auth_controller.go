@@ -40,6 +40,19 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required" example:"v4.local.xxx"`
 }
 
+type ForgotPasswordRequest struct {
+	Identifier string `json:"identifier" validate:"required" example:"john_doe"` // username or email
+	Channel    string `json:"channel" validate:"omitempty,oneof=email whatsapp" example:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token              string `json:"token,omitempty" example:"3f1c..."`       // emailed reset token
+	Identifier         string `json:"identifier,omitempty" example:"john_doe"` // username or email, required with otp
+	OTP                string `json:"otp,omitempty" example:"123456"`          // WhatsApp one-time password
+	NewPassword        string `json:"newPassword" validate:"required,min=8" example:"SecurePass123"`
+	ConfirmNewPassword string `json:"confirmNewPassword" validate:"required,eqfield=NewPassword" example:"SecurePass123"`
+}
+
 // Register handles user registration
 // @Summary Register a new user
 // @Description Create a new user account with username, password, full name, and email
@@ -62,6 +75,9 @@ func (ac *AuthController) Register(c fiber.Ctx) error {
 		})
 	}
 
+	req.Username = utils.NormalizeUsername(req.Username)
+	req.Email = utils.NormalizeEmail(req.Email)
+
 	// Check if username exists
 	var existingUser models.User
 	if err := database.DB.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
@@ -127,6 +143,11 @@ func (ac *AuthController) Register(c fiber.Ctx) error {
 			return err
 		}
 
+		// Self registration has no one granting the role
+		if err := utils.RecordRoleChange(tx, guestRole, models.RoleAuditActionGrant, utils.RoleChangeSourceRegistration, nil, user.ID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -174,8 +195,9 @@ func (ac *AuthController) Login(c fiber.Ctx) error {
 
 	// Find user
 	var user models.User
-	if err := database.DB.Preload("Roles").Where("username = ?", req.Username).First(&user).Error; err != nil {
+	if err := database.DB.Preload("Roles").Where("username = ?", utils.NormalizeUsername(req.Username)).First(&user).Error; err != nil {
 		log.Println("Invalid credentials for user:", req.Username)
+		utils.RecordFailedLogin(database.DB, c, req.Username, nil, "unknown account")
 		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Invalid credentials",
@@ -185,6 +207,7 @@ func (ac *AuthController) Login(c fiber.Ctx) error {
 	// Check if user is active
 	if !user.IsActive {
 		log.Println("User account is disabled:", req.Username)
+		utils.RecordFailedLogin(database.DB, c, req.Username, &user.ID, "account disabled")
 		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "User account is disabled",
@@ -194,6 +217,7 @@ func (ac *AuthController) Login(c fiber.Ctx) error {
 	// Verify password
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
 		log.Println("Invalid credentials for user:", req.Username)
+		utils.RecordFailedLogin(database.DB, c, req.Username, &user.ID, "invalid password")
 		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Invalid credentials",
@@ -474,3 +498,259 @@ func (ac *AuthController) RefreshToken(c fiber.Ctx) error {
 	log.Println("Token refreshed successfully for userID:", session.UserID)
 	return c.JSON(response)
 }
+
+// forgotPasswordMessage is returned whether or not the account exists, so the endpoint cannot be used to discover users
+const forgotPasswordMessage = "If the account exists, password reset instructions have been sent"
+
+// ForgotPassword issues a password reset link by email or an OTP by WhatsApp
+// @Summary Forgot password
+// @Description Request a password reset. The email channel sends a time-limited reset link; the whatsapp channel sends a one-time password to the phone on file. The response is the same whether or not the account exists.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Username or email and delivery channel"
+// @Success 200 {object} utils.SuccessResponse "Reset instructions sent if the account exists"
+// @Failure 400 {object} utils.ErrorResponse "Invalid request body"
+// @Failure 429 {object} utils.ErrorResponse "Too many requests or wrong OTPs"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /api/auth/forgot-password [post]
+func (ac *AuthController) ForgotPassword(c fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := c.Bind().Body(&req); err != nil {
+		log.Println("Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Identifier = strings.TrimSpace(req.Identifier)
+	if req.Channel == "" {
+		req.Channel = "email"
+	}
+	if req.Identifier == "" || (req.Channel != "email" && req.Channel != "whatsapp") {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Identifier is required and channel must be email or whatsapp",
+		})
+	}
+
+	// Find active user, unknown accounts get the same response
+	var user models.User
+	if err := database.DB.Where("(username = ? OR email = ?) AND is_active = ?", utils.NormalizeUsername(req.Identifier), utils.NormalizeEmail(req.Identifier), true).First(&user).Error; err != nil {
+		log.Println("Password reset requested for unknown account:", req.Identifier)
+		return c.JSON(utils.SuccessResponse{
+			Success: true,
+			Message: forgotPasswordMessage,
+		})
+	}
+
+	if req.Channel == "whatsapp" && user.Phone == "" {
+		log.Println("Password reset by whatsapp requested without phone number, userID:", user.ID)
+		return c.JSON(utils.SuccessResponse{
+			Success: true,
+			Message: forgotPasswordMessage,
+		})
+	}
+
+	// Wrong OTP attempts carry over to a newly requested OTP, so requesting another one does not renew the guess budget
+	var carriedAttempts int
+	if req.Channel == "whatsapp" {
+		lockoutStart := time.Now().Add(-time.Duration(ac.Config.PasswordResetLockoutMinutes) * time.Minute)
+		if err := database.DB.Model(&models.PasswordReset{}).
+			Where("user_id = ? AND channel = ? AND created_at > ?", user.ID, "whatsapp", lockoutStart).
+			Select("COALESCE(MAX(attempts), 0)").Scan(&carriedAttempts).Error; err != nil {
+			log.Println("Failed to count wrong reset OTPs:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate reset OTP",
+			})
+		}
+		if carriedAttempts >= ac.Config.PasswordResetMaxAttempts {
+			log.Println("Password reset by whatsapp locked after too many wrong OTPs, userID:", user.ID)
+			return c.JSON(utils.SuccessResponse{
+				Success: true,
+				Message: forgotPasswordMessage,
+			})
+		}
+	}
+
+	// Generate the secret for the channel
+	var secret, secretHash string
+	var ttl time.Duration
+	if req.Channel == "email" {
+		token, tokenHash, err := utils.GenerateSecureToken()
+		if err != nil {
+			log.Println("Failed to generate reset token:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate reset token",
+			})
+		}
+		secret, secretHash = token, tokenHash
+		ttl = time.Duration(ac.Config.PasswordResetTokenTTLMinutes) * time.Minute
+	} else {
+		otp, err := utils.GenerateOTP(6)
+		if err != nil {
+			log.Println("Failed to generate reset OTP:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate reset OTP",
+			})
+		}
+		secret, secretHash = otp, utils.HashSecureToken(otp)
+		ttl = time.Duration(ac.Config.PasswordResetOTPTTLMinutes) * time.Minute
+	}
+
+	reset := models.PasswordReset{
+		UserID:    user.ID,
+		Channel:   req.Channel,
+		TokenHash: secretHash,
+		Attempts:  carriedAttempts,
+		ExpiresAt: time.Now().Add(ttl),
+		RequestIP: c.IP(),
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the latest reset request stays valid
+		now := time.Now()
+		if err := tx.Model(&models.PasswordReset{}).Where("user_id = ? AND used_at IS NULL", user.ID).Update("expires_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&reset).Error; err != nil {
+			return err
+		}
+
+		if req.Channel == "email" {
+			link := fmt.Sprintf("%s/reset-password?token=%s", strings.TrimRight(ac.Config.FrontendUrl, "/"), secret)
+			body := fmt.Sprintf("Hello %s,\n\nA password reset was requested for your %s account. Open the link below to choose a new password:\n\n%s\n\nThis link expires in %d minutes. If you did not request this, you can ignore this email.\n",
+				user.FullName, ac.Config.AppName, link, ac.Config.PasswordResetTokenTTLMinutes)
+			return utils.SendEmail(ac.Config, user.Email, "Reset your "+ac.Config.AppName+" password", body)
+		}
+
+		message := fmt.Sprintf("%s password reset code: %s. Valid for %d minutes. Do not share this code.", ac.Config.AppName, secret, ac.Config.PasswordResetOTPTTLMinutes)
+		return utils.SendWhatsAppMessage(c.Context(), ac.Config, user.Phone, message)
+	}); err != nil {
+		log.Println("Failed to send password reset:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to send password reset instructions",
+		})
+	}
+
+	log.Println("Password reset issued by", req.Channel, "for userID:", user.ID)
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: forgotPasswordMessage,
+	})
+}
+
+// ResetPassword sets a new password using an emailed token or a WhatsApp OTP
+// @Summary Reset password
+// @Description Set a new password with the emailed reset token, or with the identifier and WhatsApp OTP. All sessions of the user are logged out.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset token or OTP and the new password"
+// @Success 200 {object} utils.SuccessResponse "Password reset successfully"
+// @Failure 400 {object} utils.ErrorResponse "Invalid request body or invalid/expired token"
+// @Failure 429 {object} utils.ErrorResponse "Too many requests or wrong OTPs"
+// @Failure 500 {object} utils.ErrorResponse "Internal server error"
+// @Router /api/auth/reset-password [post]
+func (ac *AuthController) ResetPassword(c fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := c.Bind().Body(&req); err != nil {
+		log.Println("Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if len(req.NewPassword) < 8 || req.NewPassword != req.ConfirmNewPassword {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "New password must be at least 8 characters and match the confirmation",
+		})
+	}
+
+	now := time.Now()
+	invalidReset := func() error {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid or expired reset token",
+		})
+	}
+
+	var reset models.PasswordReset
+	switch {
+	case req.Token != "":
+		if err := database.DB.Where("token_hash = ? AND channel = ?", utils.HashSecureToken(req.Token), "email").First(&reset).Error; err != nil {
+			return invalidReset()
+		}
+	case req.OTP != "" && req.Identifier != "":
+		var user models.User
+		if err := database.DB.Where("username = ? OR email = ?", utils.NormalizeUsername(req.Identifier), utils.NormalizeEmail(req.Identifier)).First(&user).Error; err != nil {
+			return invalidReset()
+		}
+		if err := database.DB.Where("user_id = ? AND channel = ? AND used_at IS NULL", user.ID, "whatsapp").Order("created_at DESC").First(&reset).Error; err != nil {
+			return invalidReset()
+		}
+		if reset.IsUsable(now, ac.Config.PasswordResetMaxAttempts) && reset.TokenHash != utils.HashSecureToken(req.OTP) {
+			// Count the wrong attempt, the OTP is locked after too many
+			database.DB.Model(&reset).Update("attempts", gorm.Expr("attempts + 1"))
+			log.Println("Wrong password reset OTP for userID:", user.ID)
+			return invalidReset()
+		}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Either token, or identifier and otp are required",
+		})
+	}
+
+	if !reset.IsUsable(now, ac.Config.PasswordResetMaxAttempts) {
+		return invalidReset()
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to hash password",
+		})
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Mark used first so the token cannot be replayed concurrently
+		result := tx.Model(&models.PasswordReset{}).Where("id = ? AND used_at IS NULL", reset.ID).Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("reset token already used")
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", reset.UserID).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+
+		// Invalidate other pending resets and log out every session
+		if err := tx.Model(&models.PasswordReset{}).Where("user_id = ? AND used_at IS NULL", reset.UserID).Update("expires_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", reset.UserID).Delete(&models.Session{}).Error
+	}); err != nil {
+		log.Println("Failed to reset password:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reset password: " + err.Error(),
+		})
+	}
+
+	log.Println("Password reset successfully, userID:", reset.UserID)
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Password reset successfully, please log in with your new password",
+	})
+}
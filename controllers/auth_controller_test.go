@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/testutil"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Requesting a new WhatsApp OTP keeps the wrong attempts of the previous ones, and no OTP is sent once they are used up.
+// ForgotPassword uses the database package connection, so the test runs against the database itself.
+func TestForgotPasswordKeepsAttemptBudget(t *testing.T) {
+	testutil.RequireDatabase(t, testDB)
+
+	cfg := testutil.Config()
+	cfg.WhatsAppGatewayURL = ""
+	cfg.PasswordResetMaxAttempts = 3
+	cfg.PasswordResetLockoutMinutes = 60
+	authController := NewAuthController(cfg, testDB)
+
+	f := testutil.NewFactory(t, testDB)
+	user := f.User("picker")
+	if err := testDB.Model(&user).Update("phone", "6281234567890").Error; err != nil {
+		t.Fatalf("failed to set phone: %v", err)
+	}
+
+	requestOTP := func() {
+		t.Helper()
+		resp, result := callHandler(t, authController.ForgotPassword, testRequest{
+			Method: fiber.MethodPost, Route: "/api/auth/forgot-password", Path: "/api/auth/forgot-password",
+			Body: ForgotPasswordRequest{Identifier: user.Username, Channel: "whatsapp"},
+		})
+		expectStatus(t, "request OTP", resp, result, fiber.StatusOK)
+	}
+	guessOTP := func() {
+		t.Helper()
+		resp, result := callHandler(t, authController.ResetPassword, testRequest{
+			Method: fiber.MethodPost, Route: "/api/auth/reset-password", Path: "/api/auth/reset-password",
+			Body: ResetPasswordRequest{Identifier: user.Username, OTP: "wrong!", NewPassword: "NewPass123", ConfirmNewPassword: "NewPass123"},
+		})
+		expectStatus(t, "wrong OTP", resp, result, fiber.StatusBadRequest)
+	}
+	latestReset := func() (models.PasswordReset, int64) {
+		t.Helper()
+		var resets []models.PasswordReset
+		if err := testDB.Where("user_id = ?", user.ID).Order("id DESC").Find(&resets).Error; err != nil {
+			t.Fatalf("failed to load resets: %v", err)
+		}
+		if len(resets) == 0 {
+			t.Fatal("no reset issued")
+		}
+		return resets[0], int64(len(resets))
+	}
+
+	requestOTP()
+	guessOTP()
+	guessOTP()
+
+	requestOTP()
+	reset, issued := latestReset()
+	if issued != 2 {
+		t.Fatalf("resets issued = %d, want 2", issued)
+	}
+	if reset.Attempts != 2 {
+		t.Errorf("attempts of the new OTP = %d, want the 2 wrong attempts carried over", reset.Attempts)
+	}
+
+	// The last attempt of the budget locks the OTP, new requests issue nothing
+	guessOTP()
+	requestOTP()
+	if _, issued = latestReset(); issued != 2 {
+		t.Errorf("resets issued after the budget was used up = %d, want 2", issued)
+	}
+}
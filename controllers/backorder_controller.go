@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type BackorderController struct {
+	DB *gorm.DB
+}
+
+func NewBackorderController(db *gorm.DB) *BackorderController {
+	return &BackorderController{DB: db}
+}
+
+// Unique response structs
+// BackorderResponse represents an order waiting for stock of a SKU, in the order stock is allocated
+type BackorderResponse struct {
+	Position         int     `json:"position"` // allocation position within the SKU, 1 is served first
+	ShortageID       uint    `json:"shortageId"`
+	OrderID          uint    `json:"orderId"`
+	OrderGineeID     string  `json:"orderGineeId"`
+	TrackingNumber   string  `json:"trackingNumber"`
+	ProcessingStatus string  `json:"processingStatus"`
+	SKU              string  `json:"sku"`
+	ShortQuantity    int     `json:"shortQuantity"`
+	Reason           string  `json:"reason"`
+	PickedBy         *string `json:"pickedBy,omitempty"`
+	StockOnHand      int     `json:"stockOnHand"`
+	IncomingQuantity int     `json:"incomingQuantity"` // expected on open inbound shipments
+	AgingHours       float64 `json:"agingHours"`
+	DeclaredAt       string  `json:"declaredAt"`
+}
+
+// GetBackorders retrieves the backorder queue
+// @Summary Get Backorders
+// @Description Retrieve the orders awaiting stock after a pick shortage, oldest first, with the stock on hand and expected on open inbound shipments of their SKU. Received stock is allocated in this order, a backorder whose whole short quantity is covered is fulfilled and its order resumes picking
+// @Tags Backorders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of backorders per page" default(10)
+// @Param sku query string false "Filter by SKU"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]BackorderResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/backorders [get]
+func (bc *BackorderController) GetBackorders(c fiber.Ctx) error {
+	log.Println("GetBackorders called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Parse filter parameters
+	sku := strings.TrimSpace(c.Query("sku", ""))
+
+	query := bc.DB.WithContext(c.Context()).Model(&models.PickShortage{}).Where("status = ?", "open")
+	if sku != "" {
+		query = query.Where("sku ILIKE ?", "%"+sku+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var shortages []models.PickShortage
+	if err := query.Preload("Order.PickUser").Order("created_at ASC, id ASC").Offset(offset).Limit(limit).Find(&shortages).Error; err != nil {
+		log.Println("GetBackorders - Failed to retrieve backorders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve backorders",
+		})
+	}
+
+	skus := make([]string, 0, len(shortages))
+	for _, shortage := range shortages {
+		skus = append(skus, shortage.SKU)
+	}
+	supply, err := utils.GetBackorderSupply(bc.DB.WithContext(c.Context()), skus)
+	if err != nil {
+		log.Println("GetBackorders - Failed to retrieve stock:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve stock of the backorders",
+		})
+	}
+
+	// Position of each backorder within its SKU, counting the older backorders on previous pages
+	var positions []struct {
+		ID       uint
+		Position int
+	}
+	if len(shortages) > 0 {
+		ids := make([]uint, len(shortages))
+		for i, shortage := range shortages {
+			ids[i] = shortage.ID
+		}
+		if err := bc.DB.WithContext(c.Context()).Raw(`SELECT id, position FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY sku ORDER BY created_at ASC, id ASC) AS position
+			FROM pick_shortages WHERE status = ? AND sku IN ?
+		) ranked WHERE id IN ?`, "open", skus, ids).Scan(&positions).Error; err != nil {
+			log.Println("GetBackorders - Failed to rank backorders:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve backorders",
+			})
+		}
+	}
+	positionByID := make(map[uint]int, len(positions))
+	for _, position := range positions {
+		positionByID[position.ID] = position.Position
+	}
+
+	now := time.Now()
+	backorders := make([]BackorderResponse, len(shortages))
+	for i, shortage := range shortages {
+		backorder := BackorderResponse{
+			Position:         positionByID[shortage.ID],
+			ShortageID:       shortage.ID,
+			OrderID:          shortage.OrderID,
+			TrackingNumber:   shortage.TrackingNumber,
+			SKU:              shortage.SKU,
+			ShortQuantity:    shortage.RequiredQuantity - shortage.PickedQuantity,
+			Reason:           shortage.Reason,
+			StockOnHand:      supply[shortage.SKU].OnHand,
+			IncomingQuantity: supply[shortage.SKU].Incoming,
+			AgingHours:       float64(int(utils.PickShortageAging(&shortage, now).Hours()*10)) / 10,
+			DeclaredAt:       shortage.CreatedAt.Format("02-01-2006 15:04:05"),
+		}
+		if shortage.Order != nil {
+			backorder.OrderGineeID = shortage.Order.OrderGineeID
+			backorder.ProcessingStatus = shortage.Order.ProcessingStatus
+			if shortage.Order.PickUser != nil {
+				backorder.PickedBy = &shortage.Order.PickUser.FullName
+			}
+		}
+		backorders[i] = backorder
+	}
+
+	message := "Backorders retrieved successfully"
+	if sku != "" {
+		message += fmt.Sprintf(" (filtered by sku: %s)", sku)
+	}
+
+	log.Println("GetBackorders completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    backorders,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// maxChangeFeedLimit bounds the number of change events returned per request
+const maxChangeFeedLimit = 5000
+
+type ChangeFeedController struct {
+	DB            *gorm.DB
+	SettleSeconds int
+}
+
+func NewChangeFeedController(cfg *config.Config, db *gorm.DB) *ChangeFeedController {
+	return &ChangeFeedController{DB: db, SettleSeconds: cfg.ChangeFeedSettleSeconds}
+}
+
+// Unique request structs
+type ReplayChangeFeedRequest struct {
+	Entities []string `json:"entities" example:"orders"`  // empty replays every entity
+	Since    string   `json:"since" example:"2026-01-01"` // YYYY-MM-DD, rows changed since this date are replayed
+}
+
+// Unique response structs
+type ReplayChangeFeedResponse struct {
+	Queued map[string]int64 `json:"queued"` // snapshot events queued per entity
+}
+
+// parseChangeFeedEntities returns the entity names of a comma separated list, every entity when empty
+func parseChangeFeedEntities(values []string) ([]string, error) {
+	var entities []string
+	for _, value := range values {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" {
+				continue
+			}
+			if _, ok := utils.FindChangeFeedEntity(name); !ok {
+				return nil, fmt.Errorf("unknown entity %s", name)
+			}
+			entities = append(entities, name)
+		}
+	}
+	if len(entities) == 0 {
+		for _, entity := range utils.ChangeFeedEntities {
+			entities = append(entities, entity.Name)
+		}
+	}
+	return entities, nil
+}
+
+// GetChangeFeed streams the changes recorded after a cursor
+// @Summary Get Change Feed
+// @Description Read the incremental changes of orders, QC ribbons, QC onlines, outbounds and attendances recorded after a cursor as NDJSON, one change event per line in cursor order. Pass the X-Next-Cursor response header as cursor on the next request until X-Has-More is false. Events are served once they are a few seconds old so concurrent transactions cannot commit behind the cursor; rows are identified by entity and entityId, so loaders should upsert. Start from cursor 0, or queue a replay, to reload the warehouse. Buyer names, addresses and GPS positions are not exported, see /api/changes/schemas for the fields of each entity
+// @Tags Changes
+// @Produce application/x-ndjson
+// @Security BearerAuth
+// @Param cursor query int false "Return the events after this cursor" default(0)
+// @Param entities query string false "Comma separated entities (orders, qc_ribbons, qc_onlines, outbounds, attendances), defaults to every entity"
+// @Param limit query int false "Maximum number of events (at most 5000)" default(1000)
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/changes [get]
+func (cfc *ChangeFeedController) GetChangeFeed(c fiber.Ctx) error {
+	log.Println("GetChangeFeed called")
+	// Parse query parameters
+	cursor, err := strconv.ParseUint(c.Query("cursor", "0"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid cursor",
+		})
+	}
+	limit, _ := strconv.Atoi(c.Query("limit", "1000"))
+	if limit < 1 {
+		limit = 1000
+	}
+	if limit > maxChangeFeedLimit {
+		limit = maxChangeFeedLimit
+	}
+	entities, err := parseChangeFeedEntities([]string{c.Query("entities", "")})
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid entities, " + err.Error(),
+		})
+	}
+
+	// One more event than requested tells whether another page follows
+	var events []models.ChangeEvent
+	if err := cfc.DB.WithContext(c.Context()).
+		Where("id > ? AND entity IN ?", cursor, entities).
+		Where("changed_at < now() - make_interval(secs => ?)", cfc.SettleSeconds).
+		Order("id ASC").Limit(limit + 1).Find(&events).Error; err != nil {
+		log.Println("GetChangeFeed - Failed to retrieve change events:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve change feed",
+		})
+	}
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	nextCursor := cursor
+	for i := range events {
+		if err := encoder.Encode(events[i].ToResponse()); err != nil {
+			log.Println("GetChangeFeed - Failed to encode change event", events[i].ID, ":", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve change feed",
+			})
+		}
+		nextCursor = events[i].ID
+	}
+
+	log.Println("GetChangeFeed completed successfully")
+	c.Set(fiber.HeaderContentType, utils.NDJSONContentType)
+	c.Set("X-Next-Cursor", strconv.FormatUint(nextCursor, 10))
+	c.Set("X-Has-More", strconv.FormatBool(hasMore))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// GetChangeFeedSchemas lists the fields of every change feed entity
+// @Summary Get Change Feed Schemas
+// @Description List the change feed entities with their schema version and the fields of their data payload. The version is bumped whenever the fields of an entity change and is sent with every change event
+// @Tags Changes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]utils.ChangeFeedEntity}
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/changes/schemas [get]
+func (cfc *ChangeFeedController) GetChangeFeedSchemas(c fiber.Ctx) error {
+	log.Println("GetChangeFeedSchemas called")
+
+	log.Println("GetChangeFeedSchemas completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Change feed schemas retrieved successfully",
+		Data:    utils.ChangeFeedEntities,
+	})
+}
+
+// ReplayChangeFeed queues the current rows of entities as snapshot events
+// @Summary Replay Change Feed
+// @Description Queue a snapshot event with the current data of every row changed since a date, for the given entities or all of them, to rebuild warehouse tables without querying production. The snapshots are appended to the feed and read from the loader's current cursor
+// @Tags Changes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ReplayChangeFeedRequest true "Entities and start date to replay"
+// @Success 202 {object} utils.SuccessResponse{data=ReplayChangeFeedResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/changes/replay [post]
+func (cfc *ChangeFeedController) ReplayChangeFeed(c fiber.Ctx) error {
+	log.Println("ReplayChangeFeed called")
+	// Parse request body
+	var req ReplayChangeFeedRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ReplayChangeFeed - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	entities, err := parseChangeFeedEntities(req.Entities)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid entities, " + err.Error(),
+		})
+	}
+	since, err := time.ParseInLocation("2006-01-02", req.Since, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid since format. Use YYYY-MM-DD.",
+		})
+	}
+
+	queued := make(map[string]int64, len(entities))
+	if err := cfc.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		for _, name := range entities {
+			entity, _ := utils.FindChangeFeedEntity(name)
+			count, err := utils.ReplayChangeFeed(tx, entity, since)
+			if err != nil {
+				return err
+			}
+			queued[name] = count
+		}
+		return nil
+	}); err != nil {
+		log.Println("ReplayChangeFeed - Failed to queue snapshot events:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to replay change feed",
+		})
+	}
+
+	log.Println("ReplayChangeFeed completed successfully")
+	return c.Status(fiber.StatusAccepted).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Change feed replay queued since " + req.Since,
+		Data:    ReplayChangeFeedResponse{Queued: queued},
+	})
+}
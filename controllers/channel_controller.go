@@ -24,11 +24,32 @@ func NewChannelController(db *gorm.DB) *ChannelController {
 type CreateChannelRequest struct {
 	ChannelCode string `json:"channelCode" validate:"required,min=3,max=50"`
 	ChannelName string `json:"channelName" validate:"required,min=3,max=100"`
+	// Prefix of order numbers generated for orders without a marketplace order id, empty uses the default prefix
+	OrderNumberPrefix string `json:"orderNumberPrefix" validate:"max=20" example:"LIVO"`
+	// Source of the channel's orders when an order does not name one, defaults to marketplace
+	OrderSource string `json:"orderSource" validate:"omitempty,oneof=marketplace offline replacement internal" example:"marketplace"`
 }
 
 type UpdateChannelRequest struct {
 	ChannelCode string `json:"channelCode" validate:"required,min=3,max=50"`
 	ChannelName string `json:"channelName" validate:"required,min=3,max=100"`
+	// Prefix of order numbers generated for orders without a marketplace order id, empty uses the default prefix
+	OrderNumberPrefix string `json:"orderNumberPrefix" validate:"max=20" example:"LIVO"`
+	// Source of the channel's orders when an order does not name one, empty keeps the current source
+	OrderSource string `json:"orderSource" validate:"omitempty,oneof=marketplace offline replacement internal" example:"marketplace"`
+}
+
+type UpdateChannelQCLaneRequest struct {
+	QCLane string `json:"qcLane" validate:"required,oneof=ribbon online"`
+}
+
+type UpdateChannelInvoiceRequest struct {
+	InvoiceRequired bool   `json:"invoiceRequired"`
+	LabelLanguage   string `json:"labelLanguage" validate:"required,oneof=id en"`
+}
+
+type UpdateChannelDuplicationPolicyRequest struct {
+	DuplicationPolicy string `json:"duplicationPolicy" validate:"required,oneof=swap internal manual" example:"swap"`
 }
 
 // GetChannels retrieves a list of channels with pagination and search
@@ -170,6 +191,22 @@ func (bc *ChannelController) CreateChannel(c fiber.Ctx) error {
 	// Convert channel code to uppercase and trim spaces
 	req.ChannelCode = strings.ToUpper(strings.TrimSpace(req.ChannelCode))
 
+	orderNumberPrefix, ok := utils.NormalizeOrderNumberPrefix(req.OrderNumberPrefix)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid order number prefix. Use up to 20 letters, digits and dashes.",
+		})
+	}
+
+	req.OrderSource = strings.ToLower(strings.TrimSpace(req.OrderSource))
+	if req.OrderSource != "" && !models.IsValidOrderSource(req.OrderSource) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid order source. Use one of: " + strings.Join(models.OrderSources(), ", "),
+		})
+	}
+
 	// Check for existing channel with same code
 	var existingChannel models.Channel
 	if err := bc.DB.Where("channel_code = ?", req.ChannelCode).First(&existingChannel).Error; err == nil {
@@ -182,8 +219,13 @@ func (bc *ChannelController) CreateChannel(c fiber.Ctx) error {
 
 	// Create new channel
 	newChannel := models.Channel{
-		ChannelCode: req.ChannelCode,
-		ChannelName: req.ChannelName,
+		ChannelCode:       req.ChannelCode,
+		ChannelName:       req.ChannelName,
+		OrderNumberPrefix: orderNumberPrefix,
+		OrderSource:       req.OrderSource,
+	}
+	if newChannel.OrderSource == "" {
+		newChannel.OrderSource = models.OrderSourceMarketplace
 	}
 
 	if err := bc.DB.Create(&newChannel).Error; err != nil {
@@ -242,6 +284,22 @@ func (bc *ChannelController) UpdateChannel(c fiber.Ctx) error {
 	// Convert channel code to uppercase and trim spaces
 	req.ChannelCode = strings.ToUpper(strings.TrimSpace(req.ChannelCode))
 
+	orderNumberPrefix, ok := utils.NormalizeOrderNumberPrefix(req.OrderNumberPrefix)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid order number prefix. Use up to 20 letters, digits and dashes.",
+		})
+	}
+
+	req.OrderSource = strings.ToLower(strings.TrimSpace(req.OrderSource))
+	if req.OrderSource != "" && !models.IsValidOrderSource(req.OrderSource) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid order source. Use one of: " + strings.Join(models.OrderSources(), ", "),
+		})
+	}
+
 	// Check for existing channel with same code (excluding current channel)
 	var existingChannel models.Channel
 	if err := bc.DB.Where("channel_code = ? AND id != ?", req.ChannelCode, id).First(&existingChannel).Error; err == nil {
@@ -255,6 +313,10 @@ func (bc *ChannelController) UpdateChannel(c fiber.Ctx) error {
 	// Update channel fields
 	channel.ChannelCode = req.ChannelCode
 	channel.ChannelName = req.ChannelName
+	channel.OrderNumberPrefix = orderNumberPrefix
+	if req.OrderSource != "" {
+		channel.OrderSource = req.OrderSource
+	}
 
 	if err := bc.DB.Save(&channel).Error; err != nil {
 		log.Println("Failed to update channel:", err)
@@ -313,3 +375,201 @@ func (bc *ChannelController) DeleteChannel(c fiber.Ctx) error {
 		Message: "Channel deleted successfully",
 	})
 }
+
+// UpdateChannelQCLane updates the QC lane mapping (ribbon or online) of a channel
+// @Summary Update Channel QC Lane
+// @Description Update the QC lane used by the unified QC start endpoint for orders of this channel
+// @Tags Channels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Channel ID"
+// @Param request body UpdateChannelQCLaneRequest true "QC lane (ribbon or online)"
+// @Success 200 {object} utils.SuccessResponse{data=models.ChannelResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/channels/{id}/qc-lane [put]
+func (bc *ChannelController) UpdateChannelQCLane(c fiber.Ctx) error {
+	// Parse id parameter
+	id := c.Params("id")
+	var channel models.Channel
+	if err := bc.DB.Where("id = ?", id).First(&channel).Error; err != nil {
+		log.Println("Channel with id " + id + " not found.")
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Channel with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateChannelQCLaneRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Validate QC lane value
+	req.QCLane = strings.ToLower(strings.TrimSpace(req.QCLane))
+	if req.QCLane != "ribbon" && req.QCLane != "online" {
+		log.Println("Invalid QC lane:", req.QCLane)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC lane must be either ribbon or online",
+		})
+	}
+
+	channel.QCLane = req.QCLane
+	if err := bc.DB.Save(&channel).Error; err != nil {
+		log.Println("Failed to update channel QC lane:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update channel QC lane",
+		})
+	}
+
+	log.Println("Channel QC lane updated successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Channel " + channel.ChannelName + " now uses the " + channel.QCLane + " QC lane",
+		Data:    channel.ToResponse(),
+	})
+}
+
+// UpdateChannelInvoice updates whether orders of a channel ship with an invoice and the language of their printed documents
+// @Summary Update Channel Invoice Settings
+// @Description Toggle the invoice bundled into the QC print job for orders of this channel and set the packing slip and invoice language
+// @Tags Channels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Channel ID"
+// @Param request body UpdateChannelInvoiceRequest true "Invoice toggle and label language (id or en)"
+// @Success 200 {object} utils.SuccessResponse{data=models.ChannelResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/channels/{id}/invoice [put]
+func (bc *ChannelController) UpdateChannelInvoice(c fiber.Ctx) error {
+	// Parse id parameter
+	id := c.Params("id")
+	var channel models.Channel
+	if err := bc.DB.Where("id = ?", id).First(&channel).Error; err != nil {
+		log.Println("Channel with id " + id + " not found.")
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Channel with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateChannelInvoiceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Validate label language value
+	req.LabelLanguage = strings.ToLower(strings.TrimSpace(req.LabelLanguage))
+	if !utils.IsLabelLanguage(req.LabelLanguage) {
+		log.Println("Invalid label language:", req.LabelLanguage)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Label language must be either id or en",
+		})
+	}
+
+	channel.InvoiceRequired = req.InvoiceRequired
+	channel.LabelLanguage = req.LabelLanguage
+	if err := bc.DB.Save(&channel).Error; err != nil {
+		log.Println("Failed to update channel invoice settings:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update channel invoice settings",
+		})
+	}
+
+	message := "Channel " + channel.ChannelName + " orders ship without an invoice"
+	if channel.InvoiceRequired {
+		message = "Channel " + channel.ChannelName + " orders ship with an invoice"
+	}
+
+	log.Println("Channel invoice settings updated successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    channel.ToResponse(),
+	})
+}
+
+// UpdateChannelDuplicationPolicy updates how duplicating an order of a channel assigns tracking numbers
+// @Summary Update Channel Duplication Policy
+// @Description Set how duplicating an order of this channel assigns tracking numbers: swap (default) gives the duplicate the original tracking number and the original the same number with an X- prefix, internal gives the duplicate an internal tracking number, manual requires the duplicate's tracking number to be entered. With internal and manual the original order keeps its tracking number
+// @Tags Channels
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Channel ID"
+// @Param request body UpdateChannelDuplicationPolicyRequest true "Duplication policy (swap, internal or manual)"
+// @Success 200 {object} utils.SuccessResponse{data=models.ChannelResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/channels/{id}/duplication-policy [put]
+func (bc *ChannelController) UpdateChannelDuplicationPolicy(c fiber.Ctx) error {
+	// Parse id parameter
+	id := c.Params("id")
+	var channel models.Channel
+	if err := bc.DB.Where("id = ?", id).First(&channel).Error; err != nil {
+		log.Println("Channel with id " + id + " not found.")
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Channel with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateChannelDuplicationPolicyRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Validate duplication policy value
+	req.DuplicationPolicy = strings.ToLower(strings.TrimSpace(req.DuplicationPolicy))
+	if !models.IsValidDuplicationPolicy(req.DuplicationPolicy) {
+		log.Println("Invalid duplication policy:", req.DuplicationPolicy)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Duplication policy must be one of: " + strings.Join(models.DuplicationPolicies(), ", "),
+		})
+	}
+
+	channel.DuplicationPolicy = req.DuplicationPolicy
+	if err := bc.DB.Save(&channel).Error; err != nil {
+		log.Println("Failed to update channel duplication policy:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update channel duplication policy",
+		})
+	}
+
+	log.Println("Channel duplication policy updated successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Channel " + channel.ChannelName + " now duplicates orders with the " + channel.DuplicationPolicy + " policy",
+		Data:    channel.ToResponse(),
+	})
+}
@@ -0,0 +1,594 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ChartController struct {
+	DB *gorm.DB
+}
+
+func NewChartController(db *gorm.DB) *ChartController {
+	return &ChartController{DB: db}
+}
+
+// Maximum date range per granularity, keeps the number of buckets bounded
+var throughputMaxRangeDays = map[string]int{
+	"hour": 31,
+	"day":  366,
+	"week": 730,
+}
+
+// QC lanes counted by the QC throughput chart
+var (
+	qcRibbonThroughputSource = throughputSource{Table: "qc_ribbons", UserColumn: "qc_by",
+		AggregateMetric: models.DailyAggregateQCByUser, AggregateDimension: models.DailyAggregateLaneRibbon, AggregateByUser: true}
+	qcOnlineThroughputSource = throughputSource{Table: "qc_onlines", UserColumn: "qc_by",
+		AggregateMetric: models.DailyAggregateQCByUser, AggregateDimension: models.DailyAggregateLaneOnline, AggregateByUser: true}
+)
+
+// Unique response structs
+// ThroughputBucket represents the count of a metric in a single period
+type ThroughputBucket struct {
+	Period string `json:"period"`
+	Count  int64  `json:"count"`
+}
+
+// ThroughputUserSeries represents the throughput of a single user
+type ThroughputUserSeries struct {
+	UserID   uint               `json:"userId"`
+	FullName string             `json:"fullName"`
+	Total    int64              `json:"total"`
+	Buckets  []ThroughputBucket `json:"buckets"`
+}
+
+// ThroughputChartResponse represents the throughput of a metric over a date range
+type ThroughputChartResponse struct {
+	Metric      string                 `json:"metric"`
+	Granularity string                 `json:"granularity"`
+	StartDate   string                 `json:"startDate"`
+	EndDate     string                 `json:"endDate"`
+	Total       int64                  `json:"total"`
+	Buckets     []ThroughputBucket     `json:"buckets"`
+	Users       []ThroughputUserSeries `json:"users,omitempty"`
+}
+
+// throughputSource is a table counted by a throughput chart, one row per event
+type throughputSource struct {
+	Table      string
+	UserColumn string
+
+	// Daily aggregate holding the same counts, empty when the table is not aggregated
+	AggregateMetric    string
+	AggregateDimension string // empty for every dimension of the metric
+	AggregateByUser    bool   // the aggregate is kept per user
+}
+
+// throughputChartParams holds the parsed query parameters of a throughput chart
+type throughputChartParams struct {
+	Granularity string
+	StartDate   string
+	EndDate     string
+	Start       time.Time
+	End         time.Time // exclusive
+	ByUser      bool
+	TeamID      uint // 0 for all users
+	Live        bool // count the transactional tables instead of the daily aggregates
+}
+
+// parseThroughputChartParams parses and validates granularity, date range, team and user breakdown parameters
+func parseThroughputChartParams(c fiber.Ctx) (*throughputChartParams, error) {
+	today := time.Now().Format("2006-01-02")
+	params := &throughputChartParams{
+		Granularity: strings.ToLower(c.Query("granularity", "hour")),
+		StartDate:   c.Query("startDate", today),
+		EndDate:     c.Query("endDate", today),
+		ByUser:      c.Query("byUser", "false") == "true",
+		Live:        c.Query("live", "false") == "true",
+	}
+
+	teamID, err := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	if err != nil {
+		return nil, errors.New("Invalid teamId")
+	}
+	params.TeamID = uint(teamID)
+
+	maxDays, ok := throughputMaxRangeDays[params.Granularity]
+	if !ok {
+		return nil, errors.New("Invalid granularity. Use hour, day or week.")
+	}
+
+	start, err := time.ParseInLocation("2006-01-02", params.StartDate, time.Local)
+	if err != nil {
+		return nil, errors.New("Invalid startDate format. Use YYYY-MM-DD.")
+	}
+	end, err := time.ParseInLocation("2006-01-02", params.EndDate, time.Local)
+	if err != nil {
+		return nil, errors.New("Invalid endDate format. Use YYYY-MM-DD.")
+	}
+	if end.Before(start) {
+		return nil, errors.New("endDate must not be before startDate")
+	}
+	if end.Sub(start).Hours()/24 >= float64(maxDays) {
+		return nil, fmt.Errorf("Date range too large for %s granularity, maximum is %d days", params.Granularity, maxDays)
+	}
+
+	params.Start = start
+	params.End = end.AddDate(0, 0, 1)
+	return params, nil
+}
+
+// throughputPeriodStart truncates a time to the start of its period, weeks start on Monday
+func throughputPeriodStart(t time.Time, granularity string) time.Time {
+	t = t.In(time.Local)
+	switch granularity {
+	case "hour":
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.Local)
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.Local)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	}
+}
+
+// throughputPeriodLabel formats the start of a period
+func throughputPeriodLabel(t time.Time, granularity string) string {
+	if granularity == "hour" {
+		return t.Format("2006-01-02 15:00")
+	}
+	return t.Format("2006-01-02")
+}
+
+// throughputPeriods lists every period label of the range so charts have no gaps
+func throughputPeriods(params *throughputChartParams) []string {
+	var labels []string
+	for t := throughputPeriodStart(params.Start, params.Granularity); t.Before(params.End); {
+		labels = append(labels, throughputPeriodLabel(t, params.Granularity))
+		switch params.Granularity {
+		case "hour":
+			t = t.Add(time.Hour)
+		case "week":
+			t = t.AddDate(0, 0, 7)
+		default:
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+	return labels
+}
+
+// buildThroughputChart counts the events of the sources per period, and per user when requested
+func (chc *ChartController) buildThroughputChart(c fiber.Ctx, metric string, params *throughputChartParams, sources []throughputSource) (*ThroughputChartResponse, error) {
+	type periodCount struct {
+		Period time.Time
+		UserID uint
+		Count  int64
+	}
+
+	periods := throughputPeriods(params)
+	totals := make(map[string]int64)
+	userTotals := make(map[uint]map[string]int64)
+
+	for _, source := range sources {
+		var query *gorm.DB
+		if throughputFromAggregate(params, source) {
+			// Daily and weekly buckets are summed from the daily aggregates
+			selectClause := fmt.Sprintf("date_trunc('%s', date) as period, SUM(count) as count", params.Granularity)
+			groupClause := "period"
+			if params.ByUser {
+				selectClause += ", user_id"
+				groupClause += ", user_id"
+			}
+
+			query = utils.DailyAggregateQuery(chc.DB.WithContext(c.Context()), source.AggregateMetric, source.AggregateDimension, params.Start, params.End).Select(selectClause)
+			if params.TeamID > 0 {
+				query = query.Where("user_id IN (?)", utils.TeamMembersQuery(chc.DB, params.TeamID))
+			}
+			query = query.Group(groupClause)
+		} else {
+			// Table, column and granularity are never taken from user input
+			selectClause := fmt.Sprintf("date_trunc('%s', created_at) as period, COUNT(*) as count", params.Granularity)
+			groupClause := "period"
+			if params.ByUser {
+				selectClause += fmt.Sprintf(", %s as user_id", source.UserColumn)
+				groupClause += ", user_id"
+			}
+
+			query = chc.DB.WithContext(c.Context()).Table(source.Table).Select(selectClause).
+				Where("created_at >= ? AND created_at < ?", params.Start, params.End)
+			if params.TeamID > 0 {
+				query = query.Where(source.UserColumn+" IN (?)", utils.TeamMembersQuery(chc.DB, params.TeamID))
+			}
+			query = query.Group(groupClause)
+		}
+
+		var counts []periodCount
+		if err := query.Scan(&counts).Error; err != nil {
+			return nil, err
+		}
+
+		for _, count := range counts {
+			label := throughputPeriodLabel(throughputPeriodStart(count.Period, params.Granularity), params.Granularity)
+			totals[label] += count.Count
+			if params.ByUser {
+				if userTotals[count.UserID] == nil {
+					userTotals[count.UserID] = make(map[string]int64)
+				}
+				userTotals[count.UserID][label] += count.Count
+			}
+		}
+	}
+
+	response := &ThroughputChartResponse{
+		Metric:      metric,
+		Granularity: params.Granularity,
+		StartDate:   params.StartDate,
+		EndDate:     params.EndDate,
+		Buckets:     make([]ThroughputBucket, len(periods)),
+	}
+	for i, label := range periods {
+		response.Buckets[i] = ThroughputBucket{Period: label, Count: totals[label]}
+		response.Total += totals[label]
+	}
+
+	if params.ByUser {
+		userIDs := make([]uint, 0, len(userTotals))
+		for userID := range userTotals {
+			userIDs = append(userIDs, userID)
+		}
+
+		// Load user names for the series
+		names := make(map[uint]string)
+		if len(userIDs) > 0 {
+			var users []models.User
+			if err := chc.DB.WithContext(c.Context()).Select("id, full_name").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+				return nil, err
+			}
+			for _, user := range users {
+				names[user.ID] = user.FullName
+			}
+		}
+
+		response.Users = make([]ThroughputUserSeries, 0, len(userIDs))
+		for _, userID := range userIDs {
+			series := ThroughputUserSeries{
+				UserID:   userID,
+				FullName: names[userID],
+				Buckets:  make([]ThroughputBucket, len(periods)),
+			}
+			for i, label := range periods {
+				series.Buckets[i] = ThroughputBucket{Period: label, Count: userTotals[userID][label]}
+				series.Total += userTotals[userID][label]
+			}
+			response.Users = append(response.Users, series)
+		}
+
+		// Most productive users first
+		sort.Slice(response.Users, func(i, j int) bool {
+			if response.Users[i].Total != response.Users[j].Total {
+				return response.Users[i].Total > response.Users[j].Total
+			}
+			return response.Users[i].FullName < response.Users[j].FullName
+		})
+	}
+
+	return response, nil
+}
+
+// throughputFromAggregate reports whether a source is counted from the daily aggregates.
+// Hourly buckets, live requests and user breakdowns the aggregate is not kept for count the table itself.
+func throughputFromAggregate(params *throughputChartParams, source throughputSource) bool {
+	if params.Live || params.Granularity == "hour" || source.AggregateMetric == "" {
+		return false
+	}
+	if (params.ByUser || params.TeamID > 0) && !source.AggregateByUser {
+		return false
+	}
+	return true
+}
+
+// throughputChartMessage builds the success message of a throughput chart
+func throughputChartMessage(label string, params *throughputChartParams) string {
+	message := label + " chart data retrieved successfully"
+	var filters []string
+
+	filters = append(filters, "granularity: "+params.Granularity)
+	filters = append(filters, "date: "+params.StartDate+" to "+params.EndDate)
+
+	if params.ByUser {
+		filters = append(filters, "per user")
+	}
+
+	if params.TeamID > 0 {
+		filters = append(filters, fmt.Sprintf("team: %d", params.TeamID))
+	}
+
+	if params.Live {
+		filters = append(filters, "live")
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+	return message
+}
+
+// GetQCThroughputChart retrieves QC throughput for charting
+// @Summary Get QC Throughput Chart
+// @Description Retrieve the number of QC processed per hour, day or week over a date range, optionally per QC user and per lane
+// @Tags Charts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param granularity query string false "Bucket size (hour, day, week)" default(hour)
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to today"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param byUser query bool false "Include a series per user" default(false)
+// @Param teamId query int false "Only count the members of this team"
+// @Param live query bool false "Count the transactional tables instead of the daily aggregates, hourly buckets are always live" default(false)
+// @Param lane query string false "QC lane (all, ribbon, online)" default(all)
+// @Success 200 {object} utils.SuccessResponse{data=ThroughputChartResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/charts/qc [get]
+func (chc *ChartController) GetQCThroughputChart(c fiber.Ctx) error {
+	log.Println("GetQCThroughputChart called")
+	// Parse query parameters
+	params, err := parseThroughputChartParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	lane := strings.ToLower(c.Query("lane", "all"))
+	var sources []throughputSource
+	switch lane {
+	case "all":
+		sources = []throughputSource{qcRibbonThroughputSource, qcOnlineThroughputSource}
+	case "ribbon":
+		sources = []throughputSource{qcRibbonThroughputSource}
+	case "online":
+		sources = []throughputSource{qcOnlineThroughputSource}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid lane. Use all, ribbon or online.",
+		})
+	}
+
+	response, err := chc.buildThroughputChart(c, "qc", params, sources)
+	if err != nil {
+		log.Println("GetQCThroughputChart - Failed to retrieve QC throughput:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve QC chart data",
+		})
+	}
+
+	message := throughputChartMessage("QC", params)
+	if lane != "all" {
+		message = throughputChartMessage("QC "+lane, params)
+	}
+
+	log.Println("GetQCThroughputChart completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    response,
+	})
+}
+
+// GetPickingThroughputChart retrieves picking completion throughput for charting
+// @Summary Get Picking Throughput Chart
+// @Description Retrieve the number of orders completed by pickers per hour, day or week over a date range, optionally per picker
+// @Tags Charts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param granularity query string false "Bucket size (hour, day, week)" default(hour)
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to today"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param byUser query bool false "Include a series per user" default(false)
+// @Param teamId query int false "Only count the members of this team"
+// @Param live query bool false "Count the transactional tables instead of the daily aggregates, hourly buckets are always live" default(false)
+// @Success 200 {object} utils.SuccessResponse{data=ThroughputChartResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/charts/picking [get]
+func (chc *ChartController) GetPickingThroughputChart(c fiber.Ctx) error {
+	log.Println("GetPickingThroughputChart called")
+	// Parse query parameters
+	params, err := parseThroughputChartParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	response, err := chc.buildThroughputChart(c, "picking", params, []throughputSource{{Table: "picked_orders", UserColumn: "picked_by"}})
+	if err != nil {
+		log.Println("GetPickingThroughputChart - Failed to retrieve picking throughput:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picking chart data",
+		})
+	}
+
+	log.Println("GetPickingThroughputChart completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: throughputChartMessage("Picking", params),
+		Data:    response,
+	})
+}
+
+// GetOutboundThroughputChart retrieves outbound scan throughput for charting
+// @Summary Get Outbound Throughput Chart
+// @Description Retrieve the number of outbound scans per hour, day or week over a date range, optionally per scanning user
+// @Tags Charts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param granularity query string false "Bucket size (hour, day, week)" default(hour)
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to today"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param byUser query bool false "Include a series per user" default(false)
+// @Param teamId query int false "Only count the members of this team"
+// @Param live query bool false "Count the transactional tables instead of the daily aggregates, hourly buckets are always live" default(false)
+// @Success 200 {object} utils.SuccessResponse{data=ThroughputChartResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/charts/outbounds [get]
+func (chc *ChartController) GetOutboundThroughputChart(c fiber.Ctx) error {
+	log.Println("GetOutboundThroughputChart called")
+	// Parse query parameters
+	params, err := parseThroughputChartParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	response, err := chc.buildThroughputChart(c, "outbound", params, []throughputSource{{Table: "outbounds", UserColumn: "outbound_by", AggregateMetric: models.DailyAggregateOutboundByExpedition}})
+	if err != nil {
+		log.Println("GetOutboundThroughputChart - Failed to retrieve outbound throughput:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve outbound chart data",
+		})
+	}
+
+	log.Println("GetOutboundThroughputChart completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: throughputChartMessage("Outbound", params),
+		Data:    response,
+	})
+}
+
+// OrderStatusSeries represents the orders created per period that are in a processing status
+type OrderStatusSeries struct {
+	Status  string             `json:"status"`
+	Total   int64              `json:"total"`
+	Buckets []ThroughputBucket `json:"buckets"`
+}
+
+// OrderStatusChartResponse represents the orders created over a date range per processing status
+type OrderStatusChartResponse struct {
+	Granularity string              `json:"granularity"`
+	StartDate   string              `json:"startDate"`
+	EndDate     string              `json:"endDate"`
+	Total       int64               `json:"total"`
+	Buckets     []ThroughputBucket  `json:"buckets"`
+	Statuses    []OrderStatusSeries `json:"statuses"`
+}
+
+// GetOrderStatusChart retrieves the orders created per period by processing status for charting
+// @Summary Get Order Status Chart
+// @Description Retrieve the number of orders created per hour, day or week over a date range, split by their current processing status
+// @Tags Charts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param granularity query string false "Bucket size (hour, day, week)" default(hour)
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to today"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param live query bool false "Count the orders instead of the daily aggregates, hourly buckets are always live" default(false)
+// @Success 200 {object} utils.SuccessResponse{data=OrderStatusChartResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/charts/orders [get]
+func (chc *ChartController) GetOrderStatusChart(c fiber.Ctx) error {
+	log.Println("GetOrderStatusChart called")
+	// Parse query parameters
+	params, err := parseThroughputChartParams(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// Granularity is validated by parseThroughputChartParams
+	var query *gorm.DB
+	if throughputFromAggregate(params, throughputSource{AggregateMetric: models.DailyAggregateOrdersByStatus}) {
+		query = utils.DailyAggregateQuery(chc.DB.WithContext(c.Context()), models.DailyAggregateOrdersByStatus, "", params.Start, params.End).
+			Select(fmt.Sprintf("date_trunc('%s', date) as period, dimension as status, SUM(count) as count", params.Granularity))
+	} else {
+		query = chc.DB.WithContext(c.Context()).Model(&models.Order{}).
+			Select(fmt.Sprintf("date_trunc('%s', created_at) as period, processing_status as status, COUNT(*) as count", params.Granularity)).
+			Where("created_at >= ? AND created_at < ?", params.Start, params.End)
+	}
+
+	var counts []struct {
+		Period time.Time
+		Status string
+		Count  int64
+	}
+	if err := query.Group("period, status").Scan(&counts).Error; err != nil {
+		log.Println("GetOrderStatusChart - Failed to retrieve order counts:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order chart data",
+		})
+	}
+
+	periods := throughputPeriods(params)
+	totals := make(map[string]int64)
+	statusTotals := make(map[string]map[string]int64)
+	for _, count := range counts {
+		label := throughputPeriodLabel(throughputPeriodStart(count.Period, params.Granularity), params.Granularity)
+		totals[label] += count.Count
+		if statusTotals[count.Status] == nil {
+			statusTotals[count.Status] = make(map[string]int64)
+		}
+		statusTotals[count.Status][label] += count.Count
+	}
+
+	response := OrderStatusChartResponse{
+		Granularity: params.Granularity,
+		StartDate:   params.StartDate,
+		EndDate:     params.EndDate,
+		Buckets:     make([]ThroughputBucket, len(periods)),
+		Statuses:    make([]OrderStatusSeries, 0, len(statusTotals)),
+	}
+	for i, label := range periods {
+		response.Buckets[i] = ThroughputBucket{Period: label, Count: totals[label]}
+		response.Total += totals[label]
+	}
+	for status, statusCounts := range statusTotals {
+		series := OrderStatusSeries{Status: status, Buckets: make([]ThroughputBucket, len(periods))}
+		for i, label := range periods {
+			series.Buckets[i] = ThroughputBucket{Period: label, Count: statusCounts[label]}
+			series.Total += statusCounts[label]
+		}
+		response.Statuses = append(response.Statuses, series)
+	}
+	sort.Slice(response.Statuses, func(i, j int) bool { return response.Statuses[i].Status < response.Statuses[j].Status })
+
+	log.Println("GetOrderStatusChart completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: throughputChartMessage("Order", params),
+		Data:    response,
+	})
+}
@@ -1,24 +1,32 @@
 package controllers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"livo-fiber-backend/config"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ComplainController struct {
-	DB *gorm.DB
+	DB         *gorm.DB
+	Retraining utils.RetrainingPolicy
+	Masking    utils.MaskingPolicy
+	Assignment utils.ComplainAssignmentPolicy
 }
 
-func NewComplainController(db *gorm.DB) *ComplainController {
-	return &ComplainController{DB: db}
+func NewComplainController(cfg *config.Config, db *gorm.DB) *ComplainController {
+	return &ComplainController{DB: db, Retraining: utils.RetrainingPolicyFromConfig(cfg), Masking: utils.MaskingPolicyFromConfig(cfg), Assignment: utils.ComplainAssignmentPolicyFromConfig(cfg)}
 }
 
 // Request structs
@@ -27,10 +35,13 @@ type CreateComplainRequest struct {
 	ChannelID      uint   `json:"channelId" validate:"required"`
 	StoreID        uint   `json:"storeId" validate:"required"`
 	Reason         string `json:"reason" validate:"required"`
+	RootCauseID    uint   `json:"rootCauseId" validate:"required"`
+	SerialNumber   string `json:"serialNumber" validate:"omitempty,max=100"` // serial of the returned unit, must have been shipped with the order
 }
 
 type UpdateComplainRequest struct {
 	Solution    string                      `json:"solution" validate:"omitempty"`
+	RootCauseID uint                        `json:"rootCauseId" validate:"omitempty"` // required when the complain has no root cause yet
 	TotalFee    int                         `json:"totalFee" validate:"omitempty,min=0"`
 	UserDetails []ComplainUserDetailRequest `json:"userDetails" validate:"omitempty,dive"`
 }
@@ -44,6 +55,87 @@ type UpdateComplainCheckRequest struct {
 	Checked bool `json:"checked" validate:"required"`
 }
 
+type ReopenComplainRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type AssignComplainRequest struct {
+	UserID uint   `json:"userId" validate:"omitempty"` // CS agent, 0 picks the next agent round-robin
+	Reason string `json:"reason" validate:"omitempty"` // why the complain is reassigned
+}
+
+// complainQueueSupervisorRoles can view the queue of other agents and assign complains
+var complainQueueSupervisorRoles = []string{"developer", "superadmin", "coordinator"}
+
+// complainDisputeNotifyRoles are notified when a marketplace dispute opens a complain
+var complainDisputeNotifyRoles = []string{"admin", "coordinator"}
+
+// Unique response structs
+// ComplainDisputePackage is the dispute.json document of a marketplace dispute package
+type ComplainDisputePackage struct {
+	GeneratedAt string                   `json:"generatedAt"`
+	Complain    *models.ComplainResponse `json:"complain"`
+	Order       *models.OrderResponse    `json:"order,omitempty"`
+	QCRibbon    *models.QCRibbonResponse `json:"qcRibbon,omitempty"`
+	QCOnline    *models.QCOnlineResponse `json:"qcOnline,omitempty"`
+	Outbound    *models.OutboundResponse `json:"outbound,omitempty"`
+	Handover    *DisputeHandover         `json:"handover,omitempty"`
+	// Shipping address edits before outbound, with the address the parcel was originally going to
+	AddressChanges []models.OrderAddressChangeResponse `json:"addressChanges,omitempty"`
+	Timeline       []DisputeTimelineEvent              `json:"timeline"`
+	Photos         []string                            `json:"photos"` // file names inside the package
+}
+
+// DisputeHandover describes the courier handover of the complained parcel
+type DisputeHandover struct {
+	SessionCode string  `json:"sessionCode"`
+	Expedition  string  `json:"expedition"`
+	DriverName  string  `json:"driverName"`
+	ScannedBy   string  `json:"scannedBy"`
+	ScannedAt   string  `json:"scannedAt"`
+	ClosedAt    *string `json:"closedAt,omitempty"`
+}
+
+// ComplainImportResponse is the result of a marketplace case export import
+type ComplainImportResponse struct {
+	Summary          BulkCreateSummary     `json:"summary"`
+	CreatedComplains []ImportedComplain    `json:"createdComplains"`
+	SkippedComplains []SkippedComplainCase `json:"skippedComplains"`
+	FailedComplains  []FailedComplainCase  `json:"failedComplains"`
+}
+
+// ImportedComplain is a complain created from a row of a case export
+type ImportedComplain struct {
+	Row            int    `json:"row"`
+	CaseID         string `json:"caseId"`
+	TrackingNumber string `json:"trackingNumber"`
+	Code           string `json:"code"`
+}
+
+// SkippedComplainCase is a case of a case export already recorded as a complain
+type SkippedComplainCase struct {
+	Row            int    `json:"row"`
+	CaseID         string `json:"caseId"`
+	TrackingNumber string `json:"trackingNumber"`
+	Code           string `json:"code"`
+	Reason         string `json:"reason"`
+}
+
+// FailedComplainCase is a row of a case export no complain could be recorded for
+type FailedComplainCase struct {
+	Row            int    `json:"row"`
+	CaseID         string `json:"caseId"`
+	TrackingNumber string `json:"trackingNumber"`
+	Error          string `json:"error"`
+}
+
+// DisputeTimelineEvent is a single step of the parcel's warehouse journey
+type DisputeTimelineEvent struct {
+	Event string `json:"event"`
+	At    string `json:"at"`
+	By    string `json:"by,omitempty"`
+}
+
 // GetComplains retrieves a list of complains with pagination and search
 // @Summary Get Complains
 // @Description Retrieve a list of complains with pagination and search
@@ -56,6 +148,8 @@ type UpdateComplainCheckRequest struct {
 // @Param startDate query string false "Start date (YYYY-MM-DD format)"
 // @Param endDate query string false "End date (YYYY-MM-DD format)"
 // @Param search query string false "Search term for tracking number or order ginee ID"
+// @Param reopened query bool false "Filter by reopened (true) or never reopened (false) complains"
+// @Param assignedTo query string false "Filter by assigned CS agent ID, or none for unassigned complains"
 // @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ComplainResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -70,7 +164,7 @@ func (cc *ComplainController) GetComplains(c fiber.Ctx) error {
 	var complains []models.Complain
 
 	// Build base query
-	query := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Model(&models.Complain{}).Order("created_at DESC")
+	query := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Model(&models.Complain{}).Order("created_at DESC")
 
 	// Date range filter if provided
 	startDate := c.Query("startDate", "")
@@ -108,6 +202,35 @@ func (cc *ComplainController) GetComplains(c fiber.Ctx) error {
 		query = query.Where("tracking_number LIKE ? OR order_ginee_id LIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	// Reopened filter if provided
+	reopened := c.Query("reopened", "")
+	switch reopened {
+	case "":
+	case "true":
+		query = query.Where("reopen_count > 0")
+	case "false":
+		query = query.Where("reopen_count = 0")
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid reopened, must be true or false",
+		})
+	}
+
+	// Assigned agent filter if provided
+	assignedTo := c.Query("assignedTo", "")
+	if assignedTo == "none" {
+		query = query.Where("assigned_to IS NULL")
+	} else if assignedTo != "" {
+		if _, err := strconv.ParseUint(assignedTo, 10, 32); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid assignedTo, must be a user ID or none",
+			})
+		}
+		query = query.Where("assigned_to = ?", assignedTo)
+	}
+
 	// Get total count for pagination
 	var total int64
 	query.Count(&total)
@@ -146,6 +269,14 @@ func (cc *ComplainController) GetComplains(c fiber.Ctx) error {
 		filters = append(filters, "search: "+search)
 	}
 
+	if reopened != "" {
+		filters = append(filters, "reopened: "+reopened)
+	}
+
+	if assignedTo != "" {
+		filters = append(filters, "assignedTo: "+assignedTo)
+	}
+
 	if len(filters) > 0 {
 		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
 	}
@@ -182,7 +313,11 @@ func (cc *ComplainController) GetComplain(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var complain models.Complain
-	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Where("id = ?", id).First(&complain).Error; err != nil {
+	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Preload("Reopens", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Reopens.ReopenUser").Preload("Assignments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Assignments.FromUser").Preload("Assignments.ToUser").Preload("Assignments.AssignUser").Where("id = ?", id).First(&complain).Error; err != nil {
 		log.Println("Complain with id " + id + " not found.")
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -198,9 +333,58 @@ func (cc *ComplainController) GetComplain(c fiber.Ctx) error {
 	})
 }
 
+// GetComplainQCPhotos retrieves the packed parcel photos captured at QC for the complained tracking number
+// @Summary Get Complain QC Photos
+// @Description Retrieve the photos of the packed parcel captured when the QC Ribbon or QC Online of the complained tracking number was completed
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Complain ID"
+// @Success 200 {object} utils.SuccessTotaledResponse{data=[]models.QCParcelPhotoResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/{id}/qc-photos [get]
+func (cc *ComplainController) GetComplainQCPhotos(c fiber.Ctx) error {
+	log.Println("GetComplainQCPhotos called")
+	// Parse id parameter
+	id := c.Params("id")
+	var complain models.Complain
+	if err := cc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
+		log.Println("GetComplainQCPhotos - Complain not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain with id " + id + " not found.",
+		})
+	}
+
+	var photos []models.QCParcelPhoto
+	if err := cc.DB.Preload("CaptureUser").Where("tracking_number = ?", complain.TrackingNumber).Order("created_at DESC").Find(&photos).Error; err != nil {
+		log.Println("GetComplainQCPhotos - Failed to retrieve QC photos:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve QC photos",
+		})
+	}
+
+	photoList := make([]models.QCParcelPhotoResponse, len(photos))
+	for i, photo := range photos {
+		photoList[i] = *photo.ToResponse()
+	}
+
+	log.Println("GetComplainQCPhotos completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: "QC photos retrieved successfully",
+		Data:    photoList,
+		Total:   int64(len(photoList)),
+	})
+}
+
 // CreateComplain handles the creation of a new complain
 // @Summary Create Complain
-// @Description Create a new complain
+// @Description Create a new complain. The optional serial number of the returned unit must be one captured at QC for the parcel
 // @Tags Complains
 // @Accept json
 // @Produce json
@@ -237,6 +421,7 @@ func (cc *ComplainController) CreateComplain(c fiber.Ctx) error {
 			Error:   "Invalid request body",
 		})
 	}
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
 
 	// Check if tracking number already exists
 	var existingComplain models.Complain
@@ -249,6 +434,18 @@ func (cc *ComplainController) CreateComplain(c fiber.Ctx) error {
 	}
 	log.Println("Tracking number check passed - no duplicate found")
 
+	// Validate the selected root cause
+	if req.RootCauseID == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Root cause is required",
+		})
+	}
+	if err := findActiveComplainRootCause(cc.DB, req.RootCauseID); err != nil {
+		log.Println("Invalid root cause:", err)
+		return complainRootCauseErrorResponse(c, err)
+	}
+
 	// Generate complain code
 	complainCode := utils.GenerateComplainCode(cc.DB, username, "")
 	log.Printf("Generated complain code: %s\n", complainCode)
@@ -283,6 +480,28 @@ func (cc *ComplainController) CreateComplain(c fiber.Ctx) error {
 	}
 	log.Printf("Order found: ID=%d, OrderGineeID=%s, %d details\n", order.ID, order.OrderGineeID, len(order.OrderDetails))
 
+	// The serial of the returned unit must be one captured for the parcel at QC
+	var serialNumber *string
+	if normalized := models.NormalizeSerialNumber(req.SerialNumber); normalized != "" {
+		var shipped int64
+		if err := tx.Model(&models.OrderItemSerial{}).Where("tracking_number = ? AND serial_number = ?", req.TrackingNumber, normalized).Count(&shipped).Error; err != nil {
+			log.Printf("Failed to check serial number: %v\n", err)
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to check serial number",
+			})
+		}
+		if shipped == 0 {
+			tx.Rollback()
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Serial number " + normalized + " was not shipped with order " + req.TrackingNumber + ".",
+			})
+		}
+		serialNumber = &normalized
+	}
+
 	// Create complain record
 	complain := models.Complain{
 		Code:           complainCode,
@@ -292,6 +511,8 @@ func (cc *ComplainController) CreateComplain(c fiber.Ctx) error {
 		StoreID:        req.StoreID,
 		CreatedBy:      uint(userID),
 		Reason:         req.Reason,
+		RootCauseID:    &req.RootCauseID,
+		SerialNumber:   serialNumber,
 	}
 	log.Printf("Creating complain: %+v\n", complain)
 
@@ -305,112 +526,24 @@ func (cc *ComplainController) CreateComplain(c fiber.Ctx) error {
 	}
 	log.Printf("Complain created successfully with ID: %d\n", complain.ID)
 
-	// Populate complain product details from order
-	log.Printf("Creating %d complain product details\n", len(order.OrderDetails))
-	for i, orderDetail := range order.OrderDetails {
-		complainProductDetail := models.ComplainProductDetail{
-			ComplainID: complain.ID,
-			ProductSKU: orderDetail.SKU,
-			Quantity:   orderDetail.Quantity,
-			Price:      orderDetail.Price,
-		}
-
-		if err := tx.Create(&complainProductDetail).Error; err != nil {
-			log.Printf("Failed to create product detail %d: %v\n", i, err)
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Failed to create complain product details",
-			})
-		}
-		log.Printf("Product detail %d created: SKU=%s, Qty=%d\n", i, orderDetail.SKU, orderDetail.Quantity)
-	}
-	log.Println("All product details created successfully")
-
-	// Populate complain user details with zero fee charge initially
-	userIDs := make(map[uint]bool) // To avoid duplicate user details
-
-	// Check qc ribbon
-	var qcRibbon models.QCRibbon
-	if err := tx.Where("tracking_number = ?", req.TrackingNumber).First(&qcRibbon).Error; err == nil && qcRibbon.QCBy != 0 {
-		userIDs[qcRibbon.QCBy] = true
-		// Update qc ribbon complained status
-		if err := tx.Model(&qcRibbon).Update("complained", true).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Failed to update QC Ribbon complained status",
-			})
-		}
-	}
-
-	// Check qc online
-	var qcOnline models.QCOnline
-	if err := tx.Where("tracking_number = ?", req.TrackingNumber).First(&qcOnline).Error; err == nil && qcOnline.QCBy != 0 {
-		userIDs[qcOnline.QCBy] = true
-		// Update qc online complained status
-		if err := tx.Model(&qcOnline).Update("complained", true).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Failed to update QC Online complained status",
-			})
-		}
-	}
-
-	// Check Outbound
-	var outbound models.Outbound
-	if err := tx.Where("tracking_number = ?", req.TrackingNumber).First(&outbound).Error; err == nil && outbound.OutboundBy != 0 {
-		userIDs[outbound.OutboundBy] = true
-		// Update outbound complained status
-		if err := tx.Model(&outbound).Update("complained", true).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Failed to update Outbound complained status",
-			})
-		}
-	}
-
-	// Check Order Assigned User
-	var orderUser models.Order
-	if err := tx.Where("tracking_number = ?", req.TrackingNumber).First(&orderUser).Error; err == nil {
-		if orderUser.PickedBy != nil {
-			userIDs[*orderUser.PickedBy] = true
-		}
-		if orderUser.AssignedBy != nil {
-			userIDs[*orderUser.AssignedBy] = true
-		}
-		// Update order complained status
-		if err := tx.Model(&orderUser).Update("complained", true).Error; err != nil {
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Failed to update Order complained status",
-			})
-		}
+	// Populate complain product and user details
+	if err := populateComplainDetails(tx, &complain, &order); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
 	}
 
-	// Create user details for each unique user found
-	log.Printf("Creating %d user details\n", len(userIDs))
-	for userIDValue := range userIDs {
-		userDetail := models.ComplainUserDetail{
-			ComplainID: complain.ID,
-			UserID:     userIDValue,
-			FeeCharge:  0,
-		}
-
-		if err := tx.Create(&userDetail).Error; err != nil {
-			log.Printf("Failed to create user detail for userID=%d: %v\n", userIDValue, err)
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Failed to create complain user details",
-			})
-		}
-		log.Printf("User detail created for userID=%d\n", userIDValue)
+	// Route the complain to the next CS agent
+	if err := utils.AutoAssignComplain(tx, cc.Assignment, &complain, utils.FeatureSubjectFromContext(c)); err != nil {
+		log.Printf("Failed to assign complain: %v\n", err)
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to assign complain",
+		})
 	}
-	log.Println("All user details created successfully")
 
 	// Commit transaction
 	log.Println("Committing transaction...")
@@ -423,9 +556,12 @@ func (cc *ComplainController) CreateComplain(c fiber.Ctx) error {
 	}
 	log.Println("Transaction committed successfully!")
 
+	// Flag the attributed users who reached the retraining threshold
+	cc.flagRetraining(complain.ID)
+
 	// Load created complain with related data
 	log.Println("Loading created complain with related data...")
-	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Where("id = ?", complain.ID).First(&complain, complain.ID).Error; err != nil {
+	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Where("id = ?", complain.ID).First(&complain, complain.ID).Error; err != nil {
 		log.Printf("Failed to retrieve created complain: %v\n", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -444,159 +580,460 @@ func (cc *ComplainController) CreateComplain(c fiber.Ctx) error {
 	})
 }
 
-// UpdateComplain updates an existing complain by ID
-// @Summary Update Complain
-// @Description Update an existing complain by ID
+// ReceiveMarketplaceDispute creates a complain from a marketplace dispute webhook
+// @Summary Receive Marketplace Dispute
+// @Description Inbound marketplace endpoint (API key with complains:write scope) receiving dispute notifications in the marketplace's own payload format (shopee or tokopedia). A complain is created for the disputed parcel with the reason mapped from the case. Notifications are deduplicated by case ID, a complain already recorded for the parcel is linked to the case instead.
 // @Tags Complains
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param id path int true "Complain ID"
-// @Param complain body UpdateComplainRequest true "Updated complain details"
+// @Param marketplace path string true "Marketplace (shopee or tokopedia)"
+// @Param payload body object true "Marketplace dispute payload"
 // @Success 200 {object} utils.SuccessResponse{data=models.ComplainResponse}
+// @Success 201 {object} utils.SuccessResponse{data=models.ComplainResponse}
 // @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
-// @Router /api/complains/{id} [put]
-func (cc *ComplainController) UpdateComplain(c fiber.Ctx) error {
-	log.Println("UpdateComplain called")
-	// Parse id parameter
-	id := c.Params("id")
-	var complain models.Complain
-	if err := cc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
-		log.Println("UpdateComplain - Complain not found:", err)
+// @Router /api/complains/webhooks/{marketplace} [post]
+func (cc *ComplainController) ReceiveMarketplaceDispute(c fiber.Ctx) error {
+	log.Println("ReceiveMarketplaceDispute called")
+	// Disputes come from the marketplace integration, not from logged in users
+	if _, ok := c.Locals("apiKeyId").(uint); !ok {
+		log.Println("ReceiveMarketplaceDispute - Request not made with an API key")
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Marketplace disputes must be sent with an integration API key",
+		})
+	}
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("ReceiveMarketplaceDispute - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	username := c.Locals("username").(string)
+
+	marketplace := strings.ToLower(utils.WebhookParam(c, "marketplace"))
+	if !utils.SupportsMarketplaceDispute(marketplace) {
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Complain with id " + id + " not found.",
+			Error:   "Unsupported marketplace " + marketplace,
 		})
 	}
 
-	// Parse request body
-	var req UpdateComplainRequest
-	if err := c.Bind().JSON(&req); err != nil {
-		log.Println("UpdateComplain - Invalid request body:", err)
+	dispute, err := utils.ParseMarketplaceDispute(marketplace, c.Body())
+	if err != nil {
+		log.Println("ReceiveMarketplaceDispute - Invalid payload:", err)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid request body",
+			Error:   "Invalid " + marketplace + " dispute payload: " + err.Error(),
 		})
 	}
 
-	// Start transaction
-	tx := cc.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("UpdateComplain - Recovered from panic: %v\n", r)
-			tx.Rollback()
+	complain, outcome, err := cc.recordMarketplaceDispute(c, marketplace, dispute, uint(userID), username)
+	if err != nil {
+		log.Println("ReceiveMarketplaceDispute - Failed to record dispute:", err)
+		status := fiber.StatusInternalServerError
+		var recordErr *disputeRecordError
+		if errors.As(err, &recordErr) {
+			status = recordErr.Status
 		}
-	}()
+		return c.Status(status).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
 
-	// Update complain fields if provided
-	complain.Solution = &req.Solution
-	complain.TotalFee = &req.TotalFee
+	switch outcome {
+	case disputeAlreadyRecorded:
+		log.Println("ReceiveMarketplaceDispute - Case already recorded:", dispute.CaseID)
+		return cc.respondMarketplaceDispute(c, complain, fiber.StatusOK, "Dispute case "+dispute.CaseID+" already recorded as complain "+complain.Code)
+	case disputeLinked:
+		log.Println("ReceiveMarketplaceDispute completed successfully")
+		return cc.respondMarketplaceDispute(c, complain, fiber.StatusOK, "Dispute case "+dispute.CaseID+" linked to complain "+complain.Code)
+	}
 
-	if err := tx.Save(&complain).Error; err != nil {
-		log.Println("UpdateComplain - Failed to update complain:", err)
-		tx.Rollback()
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	log.Println("ReceiveMarketplaceDispute completed successfully")
+	return cc.respondMarketplaceDispute(c, complain, fiber.StatusCreated, "Complain "+complain.Code+" created from dispute case "+dispute.CaseID)
+}
+
+// ImportComplains creates complains from a marketplace case export
+// @Summary Import Complains
+// @Description Create complains from the weekly case export (CSV or XLSX) downloaded from the seller center of a marketplace (shopee or tokopedia). Each case is recorded like a dispute webhook: the reason is mapped from the marketplace reason, cases already recorded are skipped by their case ID, and a complain already filed for the parcel is linked to the case.
+// @Tags Complains
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param marketplace formData string true "Marketplace of the export (shopee or tokopedia)"
+// @Param file formData file true "Case export with the Shopee return ID, tracking number, return reason and reason description columns, or the Tokopedia resolution ID, AWB, trouble type and buyer complaint columns"
+// @Success 200 {object} utils.SuccessResponse{data=ComplainImportResponse}
+// @Success 201 {object} utils.SuccessResponse{data=ComplainImportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/complains/import [post]
+func (cc *ComplainController) ImportComplains(c fiber.Ctx) error {
+	log.Println("ImportComplains called")
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("ImportComplains - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to update complain",
+			Error:   "Invalid user ID",
 		})
 	}
-	log.Println("UpdateComplain - Complain updated successfully")
+	username := c.Locals("username").(string)
 
-	// handle user details update
-	if len(req.UserDetails) > 0 {
-		log.Printf("UpdateComplain - Updating %d user details\n", len(req.UserDetails))
-		// Clear existing user details
-		if err := tx.Where("complain_id = ?", complain.ID).Delete(&models.ComplainUserDetail{}).Error; err != nil {
-			log.Println("UpdateComplain - Failed to clear existing user details:", err)
-			tx.Rollback()
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Failed to clear existing complain user details",
-			})
-		}
-		log.Println("UpdateComplain - Existing user details cleared")
-
-		// Create new user details
-		for _, userDetailReq := range req.UserDetails {
-			userDetail := models.ComplainUserDetail{
-				ComplainID: complain.ID,
-				UserID:     userDetailReq.UserID,
-				FeeCharge:  userDetailReq.FeeCharge,
-			}
-
-			if err := tx.Create(&userDetail).Error; err != nil {
-				log.Println("UpdateComplain - Failed to create user detail:", err)
-				tx.Rollback()
-				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-					Success: false,
-					Error:   "Failed to create complain user details",
-				})
-			}
-		}
-		log.Println("UpdateComplain - User details created successfully")
+	marketplace := strings.ToLower(strings.TrimSpace(c.FormValue("marketplace")))
+	if !utils.SupportsMarketplaceCaseExport(marketplace) {
+		log.Println("ImportComplains - Unsupported marketplace:", marketplace)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Unsupported marketplace " + marketplace + ", use shopee or tokopedia",
+		})
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		log.Println("UpdateComplain - Failed to commit transaction:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	file, err := c.FormFile("file")
+	if err != nil {
+		log.Println("ImportComplains - Import file required:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to commit transaction",
+			Error:   "Import file is required",
 		})
 	}
-	log.Println("UpdateComplain - Transaction committed successfully")
+	src, err := file.Open()
+	if err != nil {
+		log.Println("ImportComplains - Failed to open import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to open import file",
+		})
+	}
+	defer src.Close()
 
-	// Load updated complain with related data
-	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Where("id = ?", complain.ID).First(&complain, complain.ID).Error; err != nil {
-		log.Println("UpdateComplain - Failed to retrieve updated complain:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	records, err := utils.ReadSpreadsheetRows(file.Filename, src)
+	if err != nil {
+		log.Println("ImportComplains - Invalid import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to retrieve updated complain",
+			Error:   "Invalid import file: " + err.Error(),
+		})
+	}
+	rows, err := utils.ParseMarketplaceCaseExport(marketplace, records)
+	if err != nil {
+		log.Println("ImportComplains - Invalid import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid " + marketplace + " case export: " + err.Error(),
+		})
+	}
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No cases to import",
 		})
 	}
 
-	// Load order data if tracking number exists
-	if complain.TrackingNumber != "" {
-		var order models.Order
-		if err := cc.DB.Where("tracking_number = ?", complain.TrackingNumber).First(&order).Error; err == nil {
-			complain.Order = &order
+	response := ComplainImportResponse{
+		CreatedComplains: []ImportedComplain{},
+		SkippedComplains: []SkippedComplainCase{},
+		FailedComplains:  []FailedComplainCase{},
+	}
+	for _, row := range rows {
+		dispute := row.Dispute
+		if row.Error != "" {
+			response.FailedComplains = append(response.FailedComplains, FailedComplainCase{Row: row.Row, CaseID: dispute.CaseID, TrackingNumber: dispute.TrackingNumber, Error: row.Error})
+			continue
+		}
+
+		complain, outcome, err := cc.recordMarketplaceDispute(c, marketplace, dispute, uint(userID), username)
+		if err != nil {
+			response.FailedComplains = append(response.FailedComplains, FailedComplainCase{Row: row.Row, CaseID: dispute.CaseID, TrackingNumber: dispute.TrackingNumber, Error: err.Error()})
+			continue
 		}
+		switch outcome {
+		case disputeAlreadyRecorded:
+			response.SkippedComplains = append(response.SkippedComplains, SkippedComplainCase{Row: row.Row, CaseID: dispute.CaseID, TrackingNumber: dispute.TrackingNumber, Code: complain.Code, Reason: "Case already recorded"})
+		case disputeLinked:
+			response.SkippedComplains = append(response.SkippedComplains, SkippedComplainCase{Row: row.Row, CaseID: dispute.CaseID, TrackingNumber: dispute.TrackingNumber, Code: complain.Code, Reason: "Linked to the complain already filed for the parcel"})
+		default:
+			response.CreatedComplains = append(response.CreatedComplains, ImportedComplain{Row: row.Row, CaseID: dispute.CaseID, TrackingNumber: dispute.TrackingNumber, Code: complain.Code})
+		}
+	}
+	response.Summary = BulkCreateSummary{
+		Total:   uint(len(rows)),
+		Created: uint(len(response.CreatedComplains)),
+		Skipped: uint(len(response.SkippedComplains)),
+		Failed:  uint(len(response.FailedComplains)),
 	}
 
-	log.Println("UpdateComplain completed successfully")
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+	statusCode := fiber.StatusCreated
+	message := "Complain import completed"
+	if response.Summary.Created == 0 {
+		statusCode = fiber.StatusOK
+		message = "No complains were created"
+	} else if response.Summary.Failed > 0 || response.Summary.Skipped > 0 {
+		message = "Complain import completed with some issues"
+	}
+
+	log.Printf("ImportComplains completed (marketplace=%s, created=%d, skipped=%d, failed=%d)\n", marketplace, response.Summary.Created, response.Summary.Skipped, response.Summary.Failed)
+	return c.Status(statusCode).JSON(utils.SuccessResponse{
 		Success: true,
-		Message: "Complain updated successfully",
+		Message: message,
+		Data:    response,
+	})
+}
+
+// Outcomes of recording a marketplace dispute
+const (
+	disputeCreated         = "created"          // a complain was created for the case
+	disputeAlreadyRecorded = "already_recorded" // the case was recorded before
+	disputeLinked          = "linked"           // the case was linked to the complain already filed for the parcel
+)
+
+// disputeRecordError is a marketplace dispute that cannot be recorded, with the status it is answered with
+type disputeRecordError struct {
+	Status  int
+	Message string
+}
+
+func (e *disputeRecordError) Error() string {
+	return e.Message
+}
+
+// recordMarketplaceDispute records a marketplace dispute as a complain of the disputed parcel. Cases are deduplicated
+// by case ID per channel, a complain already filed for the parcel is linked to the case instead.
+// Returned errors carry the message shown to the client.
+func (cc *ComplainController) recordMarketplaceDispute(c fiber.Ctx, marketplace string, dispute *utils.MarketplaceDispute, userID uint, username string) (*models.Complain, string, error) {
+	var order models.Order
+	if err := cc.DB.Preload("OrderDetails").Where("tracking_number = ?", dispute.TrackingNumber).First(&order).Error; err != nil {
+		return nil, "", &disputeRecordError{fiber.StatusNotFound, "Order with tracking number " + dispute.TrackingNumber + " not found"}
+	}
+
+	// Resolve the channel and store of the order, falling back to the marketplace for the channel
+	var channel models.Channel
+	for _, channelName := range []string{order.Channel, marketplace} {
+		if channelName == "" {
+			continue
+		}
+		if err := cc.DB.Where("LOWER(channel_name) = LOWER(?) OR LOWER(channel_code) = LOWER(?)", channelName, channelName).First(&channel).Error; err == nil {
+			break
+		}
+	}
+	if channel.ID == 0 {
+		return nil, "", &disputeRecordError{fiber.StatusBadRequest, "Channel " + order.Channel + " not found"}
+	}
+	var store models.Store
+	if err := cc.DB.Where("LOWER(store_name) = LOWER(?) OR LOWER(store_code) = LOWER(?)", order.Store, order.Store).First(&store).Error; err != nil {
+		return nil, "", &disputeRecordError{fiber.StatusBadRequest, "Store " + order.Store + " not found"}
+	}
+
+	// A case is recorded only once per channel
+	var complain models.Complain
+	if err := cc.DB.Where("channel_id = ? AND external_case_id = ?", channel.ID, dispute.CaseID).First(&complain).Error; err == nil {
+		return &complain, disputeAlreadyRecorded, nil
+	}
+
+	// A parcel has a single complain, link the case to a complain already filed by hand
+	if err := cc.DB.Where("tracking_number = ?", dispute.TrackingNumber).First(&complain).Error; err == nil {
+		if complain.ExternalCaseID != nil {
+			return nil, "", &disputeRecordError{fiber.StatusConflict, fmt.Sprintf("Complain %s is already linked to case %s", complain.Code, *complain.ExternalCaseID)}
+		}
+		if err := cc.DB.Model(&complain).Update("external_case_id", dispute.CaseID).Error; err != nil {
+			log.Println("Failed to link dispute case:", err)
+			return nil, "", errors.New("Failed to link dispute case")
+		}
+		return &complain, disputeLinked, nil
+	}
+
+	complain = models.Complain{
+		Code:           utils.GenerateComplainCode(cc.DB, username, ""),
+		TrackingNumber: dispute.TrackingNumber,
+		OrderGineeID:   order.OrderGineeID,
+		ChannelID:      channel.ID,
+		StoreID:        store.ID,
+		CreatedBy:      userID,
+		Reason:         dispute.Reason,
+		ExternalCaseID: &dispute.CaseID,
+	}
+	// Classify the complain with the mapped root cause when it is still selectable, left for the resolver otherwise
+	var rootCause models.ComplainRootCause
+	if err := cc.DB.Where("code = ? AND is_active = ?", dispute.RootCause, true).First(&rootCause).Error; err == nil {
+		complain.RootCauseID = &rootCause.ID
+	}
+	var detailsErr error
+	err := cc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&complain).Error; err != nil {
+			return err
+		}
+		if detailsErr = populateComplainDetails(tx, &complain, &order); detailsErr != nil {
+			return detailsErr
+		}
+		if err := utils.AutoAssignComplain(tx, cc.Assignment, &complain, utils.FeatureSubjectFromContext(c)); err != nil {
+			return err
+		}
+		message := fmt.Sprintf("%s dispute %s opened complain %s for %s: %s", channel.ChannelName, dispute.CaseID, complain.Code, complain.TrackingNumber, complain.Reason)
+		return utils.NotifyRoles(tx, complainDisputeNotifyRoles, "complain_dispute", "Marketplace dispute received", message, "complain", complain.ID)
+	})
+	if err != nil {
+		log.Println("Failed to create complain from dispute:", err)
+		if detailsErr != nil {
+			return nil, "", detailsErr
+		}
+		return nil, "", errors.New("Failed to create complain")
+	}
+	cc.flagRetraining(complain.ID)
+
+	return &complain, disputeCreated, nil
+}
+
+// respondMarketplaceDispute returns the complain recorded for a marketplace dispute
+func (cc *ComplainController) respondMarketplaceDispute(c fiber.Ctx, complain *models.Complain, status int, message string) error {
+	cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").First(complain, complain.ID)
+	return c.Status(status).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
 		Data:    complain.ToComplainResponse(),
 	})
 }
 
-// UpdateComplainCheck updates the 'checked' status of a complain
-// @Summary Update Complain Checked Status
-// @Description Update the 'checked' status of a complain
+// flagRetraining flags the users attributed to the complain for retraining when they reached the complaint threshold.
+// Failures are only logged, the complain itself is already saved.
+func (cc *ComplainController) flagRetraining(complainID uint) {
+	tasks, err := utils.FlagRetraining(cc.DB, cc.Retraining, complainID)
+	if err != nil {
+		log.Println("Failed to flag users for retraining:", err)
+	}
+	for _, task := range tasks {
+		log.Printf("User %d flagged for retraining by complain %d\n", task.UserID, complainID)
+	}
+}
+
+// populateComplainDetails copies the order lines into the complain, marks the order's QC, outbound and order
+// records as complained and adds everyone who handled the parcel to the complain with a zero fee charge.
+// Returned errors carry the message shown to the client.
+func populateComplainDetails(tx *gorm.DB, complain *models.Complain, order *models.Order) error {
+	// Populate complain product details from order
+	log.Printf("Creating %d complain product details\n", len(order.OrderDetails))
+	for i, orderDetail := range order.OrderDetails {
+		complainProductDetail := models.ComplainProductDetail{
+			ComplainID: complain.ID,
+			ProductSKU: orderDetail.SKU,
+			Quantity:   orderDetail.Quantity,
+			Price:      orderDetail.Price,
+		}
+
+		if err := tx.Create(&complainProductDetail).Error; err != nil {
+			log.Printf("Failed to create product detail %d: %v\n", i, err)
+			return errors.New("Failed to create complain product details")
+		}
+		log.Printf("Product detail %d created: SKU=%s, Qty=%d\n", i, orderDetail.SKU, orderDetail.Quantity)
+	}
+	log.Println("All product details created successfully")
+
+	// Populate complain user details with zero fee charge initially
+	userIDs := make(map[uint]bool) // To avoid duplicate user details
+
+	// Check qc ribbon
+	var qcRibbon models.QCRibbon
+	if err := tx.Where("tracking_number = ?", complain.TrackingNumber).First(&qcRibbon).Error; err == nil && qcRibbon.QCBy != 0 {
+		userIDs[qcRibbon.QCBy] = true
+		// Update qc ribbon complained status
+		if err := tx.Model(&qcRibbon).Update("complained", true).Error; err != nil {
+			return errors.New("Failed to update QC Ribbon complained status")
+		}
+	}
+
+	// Check qc online
+	var qcOnline models.QCOnline
+	if err := tx.Where("tracking_number = ?", complain.TrackingNumber).First(&qcOnline).Error; err == nil && qcOnline.QCBy != 0 {
+		userIDs[qcOnline.QCBy] = true
+		// Update qc online complained status
+		if err := tx.Model(&qcOnline).Update("complained", true).Error; err != nil {
+			return errors.New("Failed to update QC Online complained status")
+		}
+	}
+
+	// Check Outbound
+	var outbound models.Outbound
+	if err := tx.Where("tracking_number = ?", complain.TrackingNumber).First(&outbound).Error; err == nil && outbound.OutboundBy != 0 {
+		userIDs[outbound.OutboundBy] = true
+		// Update outbound complained status
+		if err := tx.Model(&outbound).Update("complained", true).Error; err != nil {
+			return errors.New("Failed to update Outbound complained status")
+		}
+	}
+
+	// Check Order Assigned User
+	var orderUser models.Order
+	if err := tx.Where("tracking_number = ?", complain.TrackingNumber).First(&orderUser).Error; err == nil {
+		if orderUser.PickedBy != nil {
+			userIDs[*orderUser.PickedBy] = true
+		}
+		if orderUser.AssignedBy != nil {
+			userIDs[*orderUser.AssignedBy] = true
+		}
+		// Update order complained status
+		if err := tx.Model(&orderUser).Update("complained", true).Error; err != nil {
+			return errors.New("Failed to update Order complained status")
+		}
+	}
+
+	// Create user details for each unique user found
+	log.Printf("Creating %d user details\n", len(userIDs))
+	attributedUserIDs := make([]uint, 0, len(userIDs))
+	for userIDValue := range userIDs {
+		attributedUserIDs = append(attributedUserIDs, userIDValue)
+		userDetail := models.ComplainUserDetail{
+			ComplainID: complain.ID,
+			UserID:     userIDValue,
+			FeeCharge:  0,
+		}
+
+		if err := tx.Create(&userDetail).Error; err != nil {
+			log.Printf("Failed to create user detail for userID=%d: %v\n", userIDValue, err)
+			return errors.New("Failed to create complain user details")
+		}
+		log.Printf("User detail created for userID=%d\n", userIDValue)
+	}
+	log.Println("All user details created successfully")
+
+	// Let everyone who handled the parcel know the complain is attributed to them
+	if err := utils.NotifyUsers(tx, attributedUserIDs, "complain_attributed", "Complain attributed to you",
+		fmt.Sprintf("Complain %s on parcel %s: %s", complain.Code, complain.TrackingNumber, complain.Reason), "complain", complain.ID); err != nil {
+		log.Println("Failed to notify complain users:", err)
+		return errors.New("Failed to notify complain users")
+	}
+
+	return nil
+}
+
+// UpdateComplain updates an existing complain by ID
+// @Summary Update Complain
+// @Description Update an existing complain by ID. The fees of a complain frozen in a settlement can no longer be changed
 // @Tags Complains
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Complain ID"
-// @Param checked body UpdateComplainCheckRequest true "Checked status"
+// @Param complain body UpdateComplainRequest true "Updated complain details"
 // @Success 200 {object} utils.SuccessResponse{data=models.ComplainResponse}
-// @Success 200 {object} utils.ErrorResponse
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
-// @Router /api/complains/{id}/check [put]
-func (cc *ComplainController) UpdateComplainCheck(c fiber.Ctx) error {
-	log.Println("UpdateComplainCheck called")
+// @Router /api/complains/{id} [put]
+func (cc *ComplainController) UpdateComplain(c fiber.Ctx) error {
+	log.Println("UpdateComplain called")
 	// Parse id parameter
 	id := c.Params("id")
 	var complain models.Complain
 	if err := cc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
-		log.Println("UpdateComplainCheck - Complain not found:", err)
+		log.Println("UpdateComplain - Complain not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Complain with id " + id + " not found.",
@@ -604,39 +1041,1016 @@ func (cc *ComplainController) UpdateComplainCheck(c fiber.Ctx) error {
 	}
 
 	// Parse request body
-	var req UpdateComplainCheckRequest
+	var req UpdateComplainRequest
 	if err := c.Bind().JSON(&req); err != nil {
-		log.Println("UpdateComplainCheck - Invalid request body:", err)
+		log.Println("UpdateComplain - Invalid request body:", err)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Invalid request body",
 		})
 	}
 
-	// Update checked status
-	complain.Checked = req.Checked
-	if err := cc.DB.Save(&complain).Error; err != nil {
-		log.Println("UpdateComplainCheck - Failed to update checked status:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("UpdateComplain - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to update complain checked status",
+			Error:   "Invalid user ID",
 		})
 	}
-	log.Println("UpdateComplainCheck - Checked status updated successfully")
 
-	// Load related data
-	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Where("id = ?", complain.ID).First(&complain, complain.ID).Error; err != nil {
-		log.Println("UpdateComplainCheck - Failed to retrieve updated complain:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	// Resolving a complain requires its root cause, kept when already classified
+	if req.RootCauseID != 0 {
+		if err := findActiveComplainRootCause(cc.DB, req.RootCauseID); err != nil {
+			log.Println("UpdateComplain - Invalid root cause:", err)
+			return complainRootCauseErrorResponse(c, err)
+		}
+		complain.RootCauseID = &req.RootCauseID
+	} else if complain.RootCauseID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to retrieve updated complain",
+			Error:   "Root cause is required",
 		})
 	}
 
-	log.Println("UpdateComplainCheck completed successfully")
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
-		Success: true,
-		Message: "Complain checked status updated successfully",
-		Data:    complain.ToComplainResponse(),
+	// Fees frozen in a settlement can no longer be changed
+	feeChanged := complain.TotalFee == nil || *complain.TotalFee != req.TotalFee || len(req.UserDetails) > 0
+	if complain.SettlementID != nil && feeChanged {
+		return complainSettledErrorResponse(c, &complain)
+	}
+
+	// Start transaction
+	tx := cc.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("UpdateComplain - Recovered from panic: %v\n", r)
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the complain so a settlement generated meanwhile cannot freeze the fees being changed
+	if complain.SettlementID == nil {
+		if err := utils.LockUnsettledComplain(tx, complain.ID); err != nil {
+			tx.Rollback()
+			if errors.Is(err, utils.ErrComplainSettled) {
+				return complainSettledErrorResponse(c, &complain)
+			}
+			log.Println("UpdateComplain - Failed to lock complain:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to update complain",
+			})
+		}
+	}
+
+	// Update complain fields if provided, the complain counts as resolved from its first solution
+	complain.Solution = &req.Solution
+	complain.TotalFee = &req.TotalFee
+	if strings.TrimSpace(req.Solution) == "" {
+		complain.ResolvedAt = nil
+	} else if complain.ResolvedAt == nil {
+		now := time.Now()
+		complain.ResolvedAt = &now
+	}
+
+	if err := tx.Save(&complain).Error; err != nil {
+		log.Println("UpdateComplain - Failed to update complain:", err)
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update complain",
+		})
+	}
+	log.Println("UpdateComplain - Complain updated successfully")
+
+	// handle user details update
+	if len(req.UserDetails) > 0 {
+		log.Printf("UpdateComplain - Updating %d user details\n", len(req.UserDetails))
+		// Keep the fees charged before the update for the fee audit
+		var previousDetails []models.ComplainUserDetail
+		if err := tx.Where("complain_id = ?", complain.ID).Find(&previousDetails).Error; err != nil {
+			log.Println("UpdateComplain - Failed to retrieve existing user details:", err)
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve existing complain user details",
+			})
+		}
+		previousFees := make(map[uint]int)
+		for _, detail := range previousDetails {
+			previousFees[detail.UserID] += detail.FeeCharge
+		}
+
+		// Clear existing user details
+		if err := tx.Where("complain_id = ?", complain.ID).Delete(&models.ComplainUserDetail{}).Error; err != nil {
+			log.Println("UpdateComplain - Failed to clear existing user details:", err)
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to clear existing complain user details",
+			})
+		}
+		log.Println("UpdateComplain - Existing user details cleared")
+
+		// Create new user details
+		for _, userDetailReq := range req.UserDetails {
+			userDetail := models.ComplainUserDetail{
+				ComplainID: complain.ID,
+				UserID:     userDetailReq.UserID,
+				FeeCharge:  userDetailReq.FeeCharge,
+			}
+
+			if err := tx.Create(&userDetail).Error; err != nil {
+				log.Println("UpdateComplain - Failed to create user detail:", err)
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Failed to create complain user details",
+				})
+			}
+
+			// Notify users about the fee they are charged
+			if userDetailReq.FeeCharge > 0 {
+				if err := utils.NotifyUsers(tx, []uint{userDetailReq.UserID}, "complain_attributed", "Complain fee charged to you",
+					fmt.Sprintf("Complain %s on parcel %s charges you a fee of %d", complain.Code, complain.TrackingNumber, userDetailReq.FeeCharge), "complain", complain.ID); err != nil {
+					log.Println("UpdateComplain - Failed to notify user:", err)
+					tx.Rollback()
+					return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+						Success: false,
+						Error:   "Failed to notify complain users",
+					})
+				}
+			}
+		}
+		log.Println("UpdateComplain - User details created successfully")
+
+		// Audit the fee changes per user, including users no longer charged
+		newFees := make(map[uint]int)
+		for _, userDetailReq := range req.UserDetails {
+			newFees[userDetailReq.UserID] += userDetailReq.FeeCharge
+		}
+		for chargedUserID := range previousFees {
+			if _, ok := newFees[chargedUserID]; !ok {
+				newFees[chargedUserID] = 0
+			}
+		}
+		for chargedUserID, newFee := range newFees {
+			if newFee == previousFees[chargedUserID] {
+				continue
+			}
+			if err := tx.Create(&models.ComplainFeeChange{
+				ComplainID:  complain.ID,
+				UserID:      chargedUserID,
+				Action:      models.FeeChangeActionCharge,
+				PreviousFee: previousFees[chargedUserID],
+				NewFee:      newFee,
+				ChangedBy:   uint(userID),
+			}).Error; err != nil {
+				log.Println("UpdateComplain - Failed to record fee change:", err)
+				tx.Rollback()
+				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Failed to record complain fee changes",
+				})
+			}
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		log.Println("UpdateComplain - Failed to commit transaction:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to commit transaction",
+		})
+	}
+	log.Println("UpdateComplain - Transaction committed successfully")
+	if len(req.UserDetails) > 0 {
+		cc.flagRetraining(complain.ID)
+	}
+
+	// Load updated complain with related data
+	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Where("id = ?", complain.ID).First(&complain, complain.ID).Error; err != nil {
+		log.Println("UpdateComplain - Failed to retrieve updated complain:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve updated complain",
+		})
+	}
+
+	// Load order data if tracking number exists
+	if complain.TrackingNumber != "" {
+		var order models.Order
+		if err := cc.DB.Where("tracking_number = ?", complain.TrackingNumber).First(&order).Error; err == nil {
+			complain.Order = &order
+		}
+	}
+
+	log.Println("UpdateComplain completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain updated successfully",
+		Data:    complain.ToComplainResponse(),
+	})
+}
+
+// UpdateComplainCheck updates the 'checked' status of a complain
+// @Summary Update Complain Checked Status
+// @Description Update the 'checked' status of a complain
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Complain ID"
+// @Param checked body UpdateComplainCheckRequest true "Checked status"
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainResponse}
+// @Success 200 {object} utils.ErrorResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/{id}/check [put]
+func (cc *ComplainController) UpdateComplainCheck(c fiber.Ctx) error {
+	log.Println("UpdateComplainCheck called")
+	// Parse id parameter
+	id := c.Params("id")
+	var complain models.Complain
+	if err := cc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
+		log.Println("UpdateComplainCheck - Complain not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain with id " + id + " not found.",
+		})
+	}
+
+	// Parse request body
+	var req UpdateComplainCheckRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateComplainCheck - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Update checked status
+	complain.Checked = req.Checked
+	if err := cc.DB.Save(&complain).Error; err != nil {
+		log.Println("UpdateComplainCheck - Failed to update checked status:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update complain checked status",
+		})
+	}
+	log.Println("UpdateComplainCheck - Checked status updated successfully")
+
+	// Load related data
+	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Where("id = ?", complain.ID).First(&complain, complain.ID).Error; err != nil {
+		log.Println("UpdateComplainCheck - Failed to retrieve updated complain:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve updated complain",
+		})
+	}
+
+	log.Println("UpdateComplainCheck completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain checked status updated successfully",
+		Data:    complain.ToComplainResponse(),
+	})
+}
+
+// errComplainNotResolved is returned when reopening a complain that has no solution yet
+var errComplainNotResolved = errors.New("complain is not resolved")
+
+// errComplainAlreadyAssigned is returned when assigning a complain to the agent it is already assigned to
+var errComplainAlreadyAssigned = errors.New("complain is already assigned to the agent")
+
+// ReopenComplain puts a resolved complain back under investigation
+// @Summary Reopen Complain
+// @Description Reopen a resolved complain with a reason. The solution is cleared and the complain is unchecked until it is resolved again. The fee of a complain not settled yet is cleared as well so it is not settled while under investigation, settled fees stay frozen. The replaced resolution is kept in the reopen history
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Complain ID"
+// @Param reopen body ReopenComplainRequest true "Reopen reason"
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/{id}/reopen [post]
+func (cc *ComplainController) ReopenComplain(c fiber.Ctx) error {
+	log.Println("ReopenComplain called")
+	// Parse id parameter
+	id := c.Params("id")
+	var complain models.Complain
+	if err := cc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
+		log.Println("ReopenComplain - Complain not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain with id " + id + " not found.",
+		})
+	}
+
+	// Parse request body
+	var req ReopenComplainRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ReopenComplain - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Reason is required",
+		})
+	}
+
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("ReopenComplain - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	err = cc.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the complain so it is reopened once and not settled meanwhile
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", complain.ID).First(&complain).Error; err != nil {
+			return err
+		}
+		if complain.Solution == nil {
+			return errComplainNotResolved
+		}
+
+		if err := tx.Create(&models.ComplainReopen{
+			ComplainID:       complain.ID,
+			Reason:           req.Reason,
+			PreviousSolution: complain.Solution,
+			PreviousTotalFee: complain.TotalFee,
+			ReopenedBy:       uint(userID),
+		}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		updates := map[string]interface{}{
+			"solution":         nil,
+			"checked":          false,
+			"reopen_count":     gorm.Expr("reopen_count + 1"),
+			"last_reopened_at": now,
+			"resolved_at":      nil,
+		}
+		if complain.SettlementID == nil {
+			updates["total_fee"] = nil
+		}
+		if err := tx.Model(&models.Complain{}).Where("id = ?", complain.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return utils.NotifyUsers(tx, []uint{complain.CreatedBy}, "complain_reopened", "Complain reopened",
+			fmt.Sprintf("Complain %s on parcel %s was reopened: %s", complain.Code, complain.TrackingNumber, req.Reason), "complain", complain.ID)
+	})
+	if errors.Is(err, errComplainNotResolved) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain " + complain.Code + " is not resolved, only resolved complains can be reopened",
+		})
+	}
+	if err != nil {
+		log.Println("ReopenComplain - Failed to reopen complain:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reopen complain",
+		})
+	}
+
+	// Load updated complain with related data
+	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Preload("Reopens", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Reopens.ReopenUser").Where("id = ?", complain.ID).First(&complain).Error; err != nil {
+		log.Println("ReopenComplain - Failed to retrieve reopened complain:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve reopened complain",
+		})
+	}
+
+	log.Println("ReopenComplain completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain reopened successfully",
+		Data:    complain.ToComplainResponse(),
+	})
+}
+
+// AssignComplain assigns or reassigns a complain to a CS agent
+// @Summary Assign Complain
+// @Description Assign a complain to a CS agent, or to the next agent round-robin when no user is given. Reassignments are kept in the assignment history and the new agent is notified
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Complain ID"
+// @Param assignment body AssignComplainRequest true "Agent to assign"
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/{id}/assign [put]
+func (cc *ComplainController) AssignComplain(c fiber.Ctx) error {
+	log.Println("AssignComplain called")
+	// Parse id parameter
+	id := c.Params("id")
+	var complain models.Complain
+	if err := cc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
+		log.Println("AssignComplain - Complain not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain with id " + id + " not found.",
+		})
+	}
+
+	// Parse request body
+	var req AssignComplainRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("AssignComplain - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("AssignComplain - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	assignedBy := uint(userID)
+
+	method := models.ComplainAssignmentManual
+	var reason *string
+	if trimmed := strings.TrimSpace(req.Reason); trimmed != "" {
+		reason = &trimmed
+	}
+
+	err = cc.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the complain so concurrent assignments are recorded one after the other
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", complain.ID).First(&complain).Error; err != nil {
+			return err
+		}
+
+		agentID := req.UserID
+		if agentID == 0 {
+			next, err := utils.NextComplainAgent(tx, cc.Assignment)
+			if err != nil {
+				return err
+			}
+			agentID = next
+			method = models.ComplainAssignmentAuto
+		} else if isAgent, err := utils.IsComplainAgent(tx, cc.Assignment, agentID); err != nil {
+			return err
+		} else if !isAgent {
+			return utils.ErrNotComplainAgent
+		}
+		if complain.AssignedTo != nil && *complain.AssignedTo == agentID {
+			return errComplainAlreadyAssigned
+		}
+
+		return utils.AssignComplain(tx, &complain, agentID, method, reason, &assignedBy)
+	})
+	switch {
+	case errors.Is(err, utils.ErrNoComplainAgent):
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No active CS agent to assign the complain to",
+		})
+	case errors.Is(err, utils.ErrNotComplainAgent):
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("User %d is not an active CS agent", req.UserID),
+		})
+	case errors.Is(err, errComplainAlreadyAssigned):
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain " + complain.Code + " is already assigned to this agent",
+		})
+	case err != nil:
+		log.Println("AssignComplain - Failed to assign complain:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to assign complain",
+		})
+	}
+
+	// Load assigned complain with related data
+	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Preload("Assignments", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Assignments.FromUser").Preload("Assignments.ToUser").Preload("Assignments.AssignUser").Where("id = ?", complain.ID).First(&complain).Error; err != nil {
+		log.Println("AssignComplain - Failed to retrieve assigned complain:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve assigned complain",
+		})
+	}
+
+	log.Println("AssignComplain completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain assigned successfully",
+		Data:    complain.ToComplainResponse(),
+	})
+}
+
+// GetComplainAssignments retrieves the assignment history of a complain
+// @Summary Get Complain Assignments
+// @Description Retrieve the CS agents a complain was assigned to, oldest first
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Complain ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.ComplainAssignmentResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/{id}/assignments [get]
+func (cc *ComplainController) GetComplainAssignments(c fiber.Ctx) error {
+	log.Println("GetComplainAssignments called")
+	// Parse id parameter
+	id := c.Params("id")
+	var complain models.Complain
+	if err := cc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
+		log.Println("GetComplainAssignments - Complain not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain with id " + id + " not found.",
+		})
+	}
+
+	var assignments []models.ComplainAssignment
+	if err := cc.DB.Preload("FromUser").Preload("ToUser").Preload("AssignUser").Where("complain_id = ?", complain.ID).Order("created_at ASC, id ASC").Find(&assignments).Error; err != nil {
+		log.Println("GetComplainAssignments - Failed to retrieve assignments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complain assignments",
+		})
+	}
+
+	// Format response
+	assignmentList := make([]models.ComplainAssignmentResponse, len(assignments))
+	for i, assignment := range assignments {
+		assignmentList[i] = assignment.ToResponse()
+	}
+
+	log.Println("GetComplainAssignments completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain assignments retrieved successfully",
+		Data:    assignmentList,
+	})
+}
+
+// GetComplainQueue retrieves the open complains assigned to a CS agent
+// @Summary Get Complain Queue
+// @Description Retrieve the unresolved complains assigned to the current user, oldest first, with pagination. Supervisors can view the queue of another agent
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of complains per page" default(10)
+// @Param userId query int false "Agent to view the queue of (developer, superadmin and coordinator only), defaults to the current user"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ComplainResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/queue [get]
+func (cc *ComplainController) GetComplainQueue(c fiber.Ctx) error {
+	log.Println("GetComplainQueue called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("GetComplainQueue - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Only supervisors can view the queue of another agent
+	agentID := userID
+	if requested := c.Query("userId", ""); requested != "" {
+		agentID, err = strconv.ParseUint(requested, 10, 32)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid userId",
+			})
+		}
+		if agentID != userID && !utils.HasPermission(c, complainQueueSupervisorRoles) {
+			return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "You can only view your own complain queue",
+			})
+		}
+	}
+
+	var complains []models.Complain
+	query := cc.DB.Model(&models.Complain{}).
+		Where("assigned_to = ? AND (solution IS NULL OR solution = '')", agentID)
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").
+		Order("created_at ASC, id ASC").Limit(limit).Offset(offset).Find(&complains).Error; err != nil {
+		log.Println("GetComplainQueue - Failed to retrieve complains:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complain queue",
+		})
+	}
+
+	// Format response
+	complainList := make([]models.ComplainResponse, len(complains))
+	for i, complain := range complains {
+		complainList[i] = *complain.ToComplainResponse()
+	}
+
+	message := "Complain queue retrieved successfully"
+	if agentID != userID {
+		message += fmt.Sprintf(" (filtered by user: %d)", agentID)
+	}
+
+	log.Println("GetComplainQueue completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    complainList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetComplainDisputePackage assembles everything needed for a marketplace dispute into a ZIP archive
+// @Summary Get Complain Dispute Package
+// @Description Download a ZIP archive for a marketplace dispute with a readable summary.txt, the complaint, order, address change, QC, outbound and handover records in dispute.json, the picker/packer timeline, the QC parcel photos and the courier handover photos
+// @Tags Complains
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path int true "Complain ID"
+// @Param masking query string false "Buyer data masking (full, partial, anonymized), defaults to the least masked profile allowed for your role"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/{id}/dispute-package [get]
+func (cc *ComplainController) GetComplainDisputePackage(c fiber.Ctx) error {
+	log.Println("GetComplainDisputePackage called")
+	// Parse id parameter
+	id := c.Params("id")
+	masking, err := resolveMasking(c, cc.Masking)
+	if err != nil {
+		return maskingErrorResponse(c, cc.Masking, err)
+	}
+	var complain models.Complain
+	if err := cc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Where("id = ?", id).First(&complain).Error; err != nil {
+		log.Println("GetComplainDisputePackage - Complain not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain with id " + id + " not found.",
+		})
+	}
+
+	failed := func(record string, err error) error {
+		log.Println("GetComplainDisputePackage - Failed to retrieve "+record+":", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to build dispute package",
+		})
+	}
+
+	pkg := ComplainDisputePackage{
+		GeneratedAt: time.Now().Format("02-01-2006 15:04:05"),
+		Complain:    complain.ToComplainResponse(),
+		Photos:      []string{},
+	}
+
+	// Timeline events are collected with their time so they can be sorted
+	type timelineEvent struct {
+		at    time.Time
+		event string
+		by    *models.User
+	}
+	var events []timelineEvent
+	addEvent := func(at *time.Time, event string, by *models.User) {
+		if at != nil && !at.IsZero() {
+			events = append(events, timelineEvent{at: *at, event: event, by: by})
+		}
+	}
+	addEvent(&complain.CreatedAt, "Complaint created", complain.CreateUser)
+
+	// Order with its picker
+	var orders []models.Order
+	if err := cc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("CancelUser").Where("tracking_number = ?", complain.TrackingNumber).Limit(1).Find(&orders).Error; err != nil {
+		return failed("order", err)
+	}
+	if len(orders) > 0 {
+		order := orders[0]
+		pkg.Order = order.ToOrderResponse()
+		utils.MaskOrderResponse(masking, pkg.Order)
+		addEvent(&order.CreatedAt, "Order received", nil)
+		addEvent(order.AssignedAt, "Picker assigned", order.AssignUser)
+		addEvent(order.PickedAt, "Picking completed", order.PickUser)
+		addEvent(order.CanceledAt, "Order canceled", order.CancelUser)
+
+		// Address edits, the previous address matters when the buyer disputes the destination
+		var addressChanges []models.OrderAddressChange
+		if err := cc.DB.Preload("ChangeUser").Where("order_id = ?", order.ID).Order("created_at ASC, id ASC").Find(&addressChanges).Error; err != nil {
+			return failed("address changes", err)
+		}
+		for _, change := range addressChanges {
+			response := change.ToResponse()
+			utils.MaskOrderAddressChangeResponse(masking, response)
+			pkg.AddressChanges = append(pkg.AddressChanges, *response)
+			addEvent(&change.CreatedAt, "Shipping address changed: "+change.Reason, change.ChangeUser)
+		}
+	}
+
+	// QC record with the packer and the boxes used
+	var qcRibbons []models.QCRibbon
+	if err := cc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Where("tracking_number = ?", complain.TrackingNumber).Limit(1).Find(&qcRibbons).Error; err != nil {
+		return failed("QC ribbon", err)
+	}
+	if len(qcRibbons) > 0 {
+		qcRibbon := qcRibbons[0]
+		pkg.QCRibbon = qcRibbon.ToResponse()
+		addEvent(&qcRibbon.CreatedAt, "QC ribbon started", qcRibbon.QCUser)
+		if qcRibbon.Status == models.QCStatusCompleted {
+			addEvent(&qcRibbon.UpdatedAt, "QC ribbon completed", qcRibbon.QCUser)
+		}
+	}
+
+	var qcOnlines []models.QCOnline
+	if err := cc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Where("tracking_number = ?", complain.TrackingNumber).Limit(1).Find(&qcOnlines).Error; err != nil {
+		return failed("QC online", err)
+	}
+	if len(qcOnlines) > 0 {
+		qcOnline := qcOnlines[0]
+		pkg.QCOnline = qcOnline.ToResponse()
+		addEvent(&qcOnline.CreatedAt, "QC online started", qcOnline.QCUser)
+		if qcOnline.Status == models.QCStatusCompleted {
+			addEvent(&qcOnline.UpdatedAt, "QC online completed", qcOnline.QCUser)
+		}
+	}
+
+	// Outbound scan
+	var outbounds []models.Outbound
+	if err := cc.DB.Preload("OutboundUser").Where("tracking_number = ?", complain.TrackingNumber).Limit(1).Find(&outbounds).Error; err != nil {
+		return failed("outbound", err)
+	}
+	if len(outbounds) > 0 {
+		outbound := outbounds[0]
+		pkg.Outbound = outbound.ToResponse()
+		addEvent(&outbound.CreatedAt, "Outbound scanned ("+outbound.Expedition+")", outbound.OutboundUser)
+	}
+
+	entries := []utils.ZIPEntry{}
+	addPhoto := func(name, dataURL string) {
+		data, extension, err := utils.DecodeImageDataURL(dataURL)
+		if err != nil {
+			log.Println("GetComplainDisputePackage - Skipping undecodable photo "+name+":", err)
+			return
+		}
+		fileName := "photos/" + name + "." + extension
+		entries = append(entries, utils.ZIPEntry{Name: fileName, Data: data})
+		pkg.Photos = append(pkg.Photos, fileName)
+	}
+
+	// Packed parcel photos captured at QC
+	var qcPhotos []models.QCParcelPhoto
+	if err := cc.DB.Where("tracking_number = ?", complain.TrackingNumber).Find(&qcPhotos).Error; err != nil {
+		return failed("QC parcel photos", err)
+	}
+	for _, photo := range qcPhotos {
+		addPhoto(fmt.Sprintf("qc-%s-parcel-%d", photo.Lane, photo.QCID), photo.Photo)
+	}
+
+	// Courier handover with the driver photo and signature
+	var handoverItems []models.HandoverSessionItem
+	if err := cc.DB.Preload("HandoverSession.CloseUser").Preload("ScanUser").Where("tracking_number = ?", complain.TrackingNumber).Order("created_at DESC").Limit(1).Find(&handoverItems).Error; err != nil {
+		return failed("handover", err)
+	}
+	if len(handoverItems) > 0 && handoverItems[0].HandoverSession != nil {
+		item := handoverItems[0]
+		session := item.HandoverSession
+
+		var scannedBy string
+		if item.ScanUser != nil {
+			scannedBy = item.ScanUser.FullName
+		}
+		pkg.Handover = &DisputeHandover{
+			SessionCode: session.SessionCode,
+			Expedition:  session.Expedition,
+			DriverName:  session.DriverName,
+			ScannedBy:   scannedBy,
+			ScannedAt:   item.CreatedAt.Format("02-01-2006 15:04:05"),
+		}
+		if session.ClosedAt != nil {
+			closedAt := session.ClosedAt.Format("02-01-2006 15:04:05")
+			pkg.Handover.ClosedAt = &closedAt
+		}
+		addEvent(&item.CreatedAt, "Handover scanned ("+session.SessionCode+")", item.ScanUser)
+		addEvent(session.ClosedAt, "Handed over to courier driver "+session.DriverName, session.CloseUser)
+
+		for name, dataURL := range map[string]string{"driver-photo": session.DriverPhoto, "driver-signature": session.DriverSignature} {
+			if dataURL != "" {
+				addPhoto(name, dataURL)
+			}
+		}
+	}
+	sort.Strings(pkg.Photos)
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].at.Before(events[j].at)
+	})
+	pkg.Timeline = make([]DisputeTimelineEvent, len(events))
+	for i, event := range events {
+		pkg.Timeline[i] = DisputeTimelineEvent{Event: event.event, At: event.at.Format("02-01-2006 15:04:05")}
+		if event.by != nil {
+			pkg.Timeline[i].By = event.by.FullName
+		}
+	}
+
+	document, err := json.MarshalIndent(pkg, "", "  ")
+	if err != nil {
+		return failed("dispute document", err)
+	}
+	entries = append([]utils.ZIPEntry{
+		{Name: "summary.txt", Data: []byte(buildDisputeSummary(&pkg))},
+		{Name: "dispute.json", Data: document},
+	}, entries...)
+
+	buffer, err := utils.BuildZIP(entries)
+	if err != nil {
+		return failed("dispute archive", err)
+	}
+
+	log.Println("GetComplainDisputePackage completed successfully")
+	c.Set(fiber.HeaderContentType, utils.ZIPContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"dispute-%s.zip\"", complain.Code))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// buildDisputeSummary renders the readable summary.txt of a dispute package
+func buildDisputeSummary(pkg *ComplainDisputePackage) string {
+	var summary strings.Builder
+	complain := pkg.Complain
+
+	fmt.Fprintf(&summary, "DISPUTE PACKAGE %s\n", complain.Code)
+	fmt.Fprintf(&summary, "Generated at: %s\n\n", pkg.GeneratedAt)
+
+	fmt.Fprintf(&summary, "COMPLAINT\n")
+	fmt.Fprintf(&summary, "Tracking number: %s\n", complain.TrackingNumber)
+	fmt.Fprintf(&summary, "Channel / store: %s / %s\n", complain.Channel, complain.Store)
+	fmt.Fprintf(&summary, "Reason: %s\n", complain.Reason)
+	if complain.Solution != nil {
+		fmt.Fprintf(&summary, "Solution: %s\n", *complain.Solution)
+	}
+	for _, detail := range complain.ProductDetails {
+		fmt.Fprintf(&summary, "- %s x%d @ %d\n", detail.ProductSKU, detail.Quantity, detail.Price)
+	}
+
+	if order := pkg.Order; order != nil {
+		fmt.Fprintf(&summary, "\nORDER\n")
+		fmt.Fprintf(&summary, "Order ID: %s\n", order.OrderGineeID)
+		fmt.Fprintf(&summary, "Buyer: %s\n", order.Buyer)
+		fmt.Fprintf(&summary, "Courier: %s\n", order.Courier)
+		fmt.Fprintf(&summary, "Status: %s / %s\n", order.ProcessingStatus, order.EventStatus)
+		for _, detail := range order.Details {
+			fmt.Fprintf(&summary, "- %s %s x%d\n", detail.SKU, detail.ProductName, detail.Quantity)
+		}
+	}
+
+	qcBoxes := func(qcBy string, boxes map[string]int) {
+		fmt.Fprintf(&summary, "Packed by: %s\n", qcBy)
+		names := make([]string, 0, len(boxes))
+		for box := range boxes {
+			names = append(names, box)
+		}
+		sort.Strings(names)
+		for _, box := range names {
+			fmt.Fprintf(&summary, "- Box %s x%d\n", box, boxes[box])
+		}
+	}
+	if qc := pkg.QCRibbon; qc != nil {
+		fmt.Fprintf(&summary, "\nQC RIBBON (%s)\n", qc.Status)
+		boxes := make(map[string]int)
+		for _, detail := range qc.Details {
+			boxes[detail.Box] += detail.Quantity
+		}
+		qcBoxes(qc.QCBy, boxes)
+	}
+	if qc := pkg.QCOnline; qc != nil {
+		fmt.Fprintf(&summary, "\nQC ONLINE (%s)\n", qc.Status)
+		boxes := make(map[string]int)
+		for _, detail := range qc.Details {
+			boxes[detail.Box] += detail.Quantity
+		}
+		qcBoxes(qc.QCBy, boxes)
+	}
+
+	if outbound := pkg.Outbound; outbound != nil {
+		fmt.Fprintf(&summary, "\nOUTBOUND\n")
+		fmt.Fprintf(&summary, "Expedition: %s\n", outbound.Expedition)
+		fmt.Fprintf(&summary, "Scanned by: %s at %s\n", outbound.OutboundBy, outbound.CreatedAt)
+	}
+
+	if handover := pkg.Handover; handover != nil {
+		fmt.Fprintf(&summary, "\nCOURIER HANDOVER\n")
+		fmt.Fprintf(&summary, "Session: %s (%s)\n", handover.SessionCode, handover.Expedition)
+		fmt.Fprintf(&summary, "Driver: %s\n", handover.DriverName)
+		if handover.ClosedAt != nil {
+			fmt.Fprintf(&summary, "Handed over at: %s\n", *handover.ClosedAt)
+		}
+	}
+
+	fmt.Fprintf(&summary, "\nTIMELINE\n")
+	for _, event := range pkg.Timeline {
+		if event.By != "" {
+			fmt.Fprintf(&summary, "%s  %s (%s)\n", event.At, event.Event, event.By)
+		} else {
+			fmt.Fprintf(&summary, "%s  %s\n", event.At, event.Event)
+		}
+	}
+
+	if len(pkg.Photos) > 0 {
+		fmt.Fprintf(&summary, "\nPHOTOS\n")
+		for _, photo := range pkg.Photos {
+			fmt.Fprintf(&summary, "- %s\n", photo)
+		}
+	}
+
+	return summary.String()
+}
+
+// findActiveComplainRootCause checks that the root cause exists and can still be selected
+func findActiveComplainRootCause(db *gorm.DB, rootCauseID uint) error {
+	var rootCause models.ComplainRootCause
+	if err := db.Where("id = ?", rootCauseID).First(&rootCause).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Root cause with id %d not found", rootCauseID))
+		}
+		return err
+	}
+	if !rootCause.IsActive {
+		return fiber.NewError(fiber.StatusBadRequest, "Root cause "+rootCause.Name+" is no longer active")
+	}
+	return nil
+}
+
+// complainRootCauseErrorResponse writes the root cause validation error, or a generic one for database failures
+func complainRootCauseErrorResponse(c fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fiberErr.Message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Failed to validate root cause",
 	})
 }
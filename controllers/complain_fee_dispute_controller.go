@@ -0,0 +1,591 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ComplainFeeDisputeController struct {
+	DB *gorm.DB
+}
+
+func NewComplainFeeDisputeController(db *gorm.DB) *ComplainFeeDisputeController {
+	return &ComplainFeeDisputeController{DB: db}
+}
+
+// Request structs
+type CreateComplainFeeDisputeRequest struct {
+	Note string `json:"note" validate:"required" example:"I was not on shift when this parcel was packed"`
+}
+
+type ReviewComplainFeeDisputeRequest struct {
+	Action string `json:"action" validate:"required,oneof=adjust waive reject" example:"adjust"`
+	Fee    *int   `json:"fee" validate:"omitempty,min=0" example:"5000"` // required to adjust
+	Note   string `json:"note" validate:"required" example:"Fee split with the QC user"`
+}
+
+// complainFeeDisputeReviewRoles review complain fee disputes and are notified when one is opened
+var complainFeeDisputeReviewRoles = []string{"coordinator", "hrd"}
+
+// errFeeDisputeConflict is returned when the dispute was reviewed by someone else in the meantime
+var errFeeDisputeConflict = errors.New("fee dispute already reviewed")
+
+// errFeeDisputeNotCharged is returned when the complain no longer charges the disputing user
+var errFeeDisputeNotCharged = errors.New("user is no longer charged by the complain")
+
+// CreateComplainFeeDispute disputes the fee a complain charges the logged in user
+// @Summary Create Complain Fee Dispute
+// @Description Dispute the fee a complain charges the logged in user with a note. Coordinators and HRD are notified to review it. Only one open dispute per complain and user is allowed, and the fees of a settled complain cannot be disputed
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Complain ID"
+// @Param request body CreateComplainFeeDisputeRequest true "Dispute note"
+// @Success 201 {object} utils.SuccessResponse{data=models.ComplainFeeDisputeResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/{id}/fee-disputes [post]
+func (cfdc *ComplainFeeDisputeController) CreateComplainFeeDispute(c fiber.Ctx) error {
+	log.Println("CreateComplainFeeDispute called")
+	// Parse id parameter
+	id := c.Params("id")
+	var complain models.Complain
+	if err := cfdc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
+		log.Println("CreateComplainFeeDispute - Complain not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CreateComplainFeeDispute - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req CreateComplainFeeDisputeRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateComplainFeeDispute - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Note == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Dispute note is required",
+		})
+	}
+
+	// Fees frozen in a settlement can no longer be disputed
+	if complain.SettlementID != nil {
+		return complainSettledErrorResponse(c, &complain)
+	}
+
+	// Only the fee charged to the logged in user can be disputed
+	fee, err := complainUserFee(cfdc.DB, complain.ID, uint(userID))
+	if err != nil {
+		log.Println("CreateComplainFeeDispute - Failed to retrieve charged fee:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve charged fee",
+		})
+	}
+	if fee <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain " + complain.Code + " does not charge you a fee",
+		})
+	}
+
+	var openDisputes int64
+	cfdc.DB.Model(&models.ComplainFeeDispute{}).Where("complain_id = ? AND user_id = ? AND status = ?", complain.ID, uint(userID), models.FeeDisputeStatusOpen).Count(&openDisputes)
+	if openDisputes > 0 {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You already have an open dispute for complain " + complain.Code,
+		})
+	}
+
+	dispute := models.ComplainFeeDispute{
+		ComplainID:  complain.ID,
+		UserID:      uint(userID),
+		DisputedFee: fee,
+		Note:        req.Note,
+		Status:      models.FeeDisputeStatusOpen,
+	}
+	err = cfdc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := utils.LockUnsettledComplain(tx, complain.ID); err != nil {
+			return err
+		}
+		if err := tx.Create(&dispute).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.ComplainFeeChange{
+			ComplainID:  complain.ID,
+			UserID:      uint(userID),
+			DisputeID:   &dispute.ID,
+			Action:      models.FeeChangeActionDispute,
+			PreviousFee: fee,
+			NewFee:      fee,
+			Note:        req.Note,
+			ChangedBy:   uint(userID),
+		}).Error; err != nil {
+			return err
+		}
+
+		return utils.NotifyRoles(tx, complainFeeDisputeReviewRoles, "complain_fee_dispute", "Complain fee disputed",
+			fmt.Sprintf("A fee of %d charged by complain %s on parcel %s is disputed", fee, complain.Code, complain.TrackingNumber), "complain_fee_dispute", dispute.ID)
+	})
+	if errors.Is(err, utils.ErrComplainSettled) {
+		return complainSettledErrorResponse(c, &complain)
+	}
+	if err != nil {
+		log.Println("CreateComplainFeeDispute - Failed to create fee dispute:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create fee dispute",
+		})
+	}
+
+	// Reload the dispute with all relationships for response
+	if err := cfdc.DB.Preload("Complain").Preload("User").First(&dispute, dispute.ID).Error; err != nil {
+		log.Println("CreateComplainFeeDispute - Failed to load fee dispute:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load fee dispute",
+		})
+	}
+
+	log.Println("CreateComplainFeeDispute completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Fee dispute created successfully",
+		Data:    dispute.ToResponse(),
+	})
+}
+
+// GetComplainFeeDisputes retrieves the complain fee disputes for review
+// @Summary Get Complain Fee Disputes
+// @Description Retrieve complain fee disputes, oldest open first, with pagination and status and user filters
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of disputes per page" default(10)
+// @Param status query string false "Filter by status (open, adjusted, waived, rejected)"
+// @Param userId query int false "Filter by disputing user ID"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ComplainFeeDisputeResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/fee-disputes [get]
+func (cfdc *ComplainFeeDisputeController) GetComplainFeeDisputes(c fiber.Ctx) error {
+	log.Println("GetComplainFeeDisputes called")
+	return cfdc.listComplainFeeDisputes(c, "GetComplainFeeDisputes", 0)
+}
+
+// GetMyComplainFeeDisputes retrieves the fee disputes of the logged in user
+// @Summary Get My Complain Fee Disputes
+// @Description Retrieve the complain fee disputes of the logged in user with their review outcome, with pagination and status filter
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of disputes per page" default(10)
+// @Param status query string false "Filter by status (open, adjusted, waived, rejected)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ComplainFeeDisputeResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/fee-disputes/mine [get]
+func (cfdc *ComplainFeeDisputeController) GetMyComplainFeeDisputes(c fiber.Ctx) error {
+	log.Println("GetMyComplainFeeDisputes called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("GetMyComplainFeeDisputes - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	return cfdc.listComplainFeeDisputes(c, "GetMyComplainFeeDisputes", uint(userID))
+}
+
+// listComplainFeeDisputes responds with the paginated fee disputes, limited to ownerID when set
+func (cfdc *ComplainFeeDisputeController) listComplainFeeDisputes(c fiber.Ctx, handler string, ownerID uint) error {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var disputes []models.ComplainFeeDispute
+
+	// Build base query, open disputes first so the review queue is on top
+	query := cfdc.DB.Model(&models.ComplainFeeDispute{}).Preload("Complain").Preload("User").Preload("ReviewUser").
+		Order("CASE WHEN status = 'open' THEN 0 ELSE 1 END, created_at ASC")
+
+	if ownerID > 0 {
+		query = query.Where("user_id = ?", ownerID)
+	}
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		if status != models.FeeDisputeStatusOpen && status != models.FeeDisputeStatusAdjusted && status != models.FeeDisputeStatusWaived && status != models.FeeDisputeStatusRejected {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status. Use open, adjusted, waived or rejected.",
+			})
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	// User filter if provided, only for the review queue
+	userIDFilter := strings.TrimSpace(c.Query("userId", ""))
+	if ownerID == 0 && userIDFilter != "" {
+		query = query.Where("user_id = ?", userIDFilter)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&disputes).Error; err != nil {
+		log.Println(handler+" - Failed to retrieve fee disputes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve fee disputes",
+		})
+	}
+
+	// Format response
+	disputeList := make([]models.ComplainFeeDisputeResponse, len(disputes))
+	for i, dispute := range disputes {
+		disputeList[i] = *dispute.ToResponse()
+	}
+
+	// Build success message
+	message := "Fee disputes retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if ownerID == 0 && userIDFilter != "" {
+		filters = append(filters, "userId: "+userIDFilter)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println(handler + " completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    disputeList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// ReviewComplainFeeDispute adjusts, waives or rejects a disputed complain fee
+// @Summary Review Complain Fee Dispute
+// @Description Review an open complain fee dispute. adjust changes the fee charged to the user, waive sets it to 0 and reject keeps it. The fee change is audited and the user is notified of the outcome. The fee of a settled complain can no longer be adjusted or waived
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Fee Dispute ID"
+// @Param request body ReviewComplainFeeDisputeRequest true "Review action, adjusted fee and note"
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainFeeDisputeResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/fee-disputes/{id}/review [put]
+func (cfdc *ComplainFeeDisputeController) ReviewComplainFeeDispute(c fiber.Ctx) error {
+	log.Println("ReviewComplainFeeDispute called")
+	// Parse id parameter
+	id := c.Params("id")
+	var dispute models.ComplainFeeDispute
+	if err := cfdc.DB.Preload("Complain").Where("id = ?", id).First(&dispute).Error; err != nil {
+		log.Println("ReviewComplainFeeDispute - Fee dispute not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Fee dispute with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ReviewComplainFeeDispute - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req ReviewComplainFeeDisputeRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ReviewComplainFeeDispute - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Note == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Review note is required",
+		})
+	}
+
+	if dispute.Status != models.FeeDisputeStatusOpen {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Fee dispute has already been " + dispute.Status,
+		})
+	}
+
+	// Users cannot review their own dispute
+	if dispute.UserID == uint(userID) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You cannot review your own fee dispute",
+		})
+	}
+
+	var status, action string
+	switch req.Action {
+	case "adjust":
+		if req.Fee == nil || *req.Fee < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Adjusted fee is required and cannot be negative",
+			})
+		}
+		status, action = models.FeeDisputeStatusAdjusted, models.FeeChangeActionAdjust
+	case "waive":
+		waived := 0
+		req.Fee = &waived
+		status, action = models.FeeDisputeStatusWaived, models.FeeChangeActionWaive
+	case "reject":
+		req.Fee = nil
+		status, action = models.FeeDisputeStatusRejected, models.FeeChangeActionReject
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid action. Use adjust, waive or reject.",
+		})
+	}
+
+	now := time.Now()
+	reviewerID := uint(userID)
+	err = cfdc.DB.Transaction(func(tx *gorm.DB) error {
+		previousFee, err := complainUserFee(tx, dispute.ComplainID, dispute.UserID)
+		if err != nil {
+			return err
+		}
+		newFee := previousFee
+		if req.Fee != nil {
+			newFee = *req.Fee
+		}
+
+		result := tx.Model(&models.ComplainFeeDispute{}).Where("id = ? AND status = ?", dispute.ID, models.FeeDisputeStatusOpen).Updates(map[string]interface{}{
+			"status":       status,
+			"resolved_fee": newFee,
+			"review_note":  req.Note,
+			"reviewed_by":  reviewerID,
+			"reviewed_at":  now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errFeeDisputeConflict
+		}
+
+		if newFee != previousFee {
+			if err := utils.LockUnsettledComplain(tx, dispute.ComplainID); err != nil {
+				return err
+			}
+			if err := setComplainUserFee(tx, dispute.ComplainID, dispute.UserID, newFee); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Create(&models.ComplainFeeChange{
+			ComplainID:  dispute.ComplainID,
+			UserID:      dispute.UserID,
+			DisputeID:   &dispute.ID,
+			Action:      action,
+			PreviousFee: previousFee,
+			NewFee:      newFee,
+			Note:        req.Note,
+			ChangedBy:   reviewerID,
+		}).Error; err != nil {
+			return err
+		}
+
+		return utils.NotifyUsers(tx, []uint{dispute.UserID}, "complain_fee_dispute", "Complain fee dispute "+status,
+			fmt.Sprintf("Your dispute of complain %s was %s, the fee charged to you is now %d", dispute.Complain.Code, status, newFee), "complain_fee_dispute", dispute.ID)
+	})
+	if errors.Is(err, errFeeDisputeConflict) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Fee dispute was reviewed in the meantime, please reload and try again.",
+		})
+	}
+	if errors.Is(err, utils.ErrComplainSettled) {
+		return complainSettledErrorResponse(c, dispute.Complain)
+	}
+	if errors.Is(err, errFeeDisputeNotCharged) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "The complain no longer charges this user, reject the dispute instead.",
+		})
+	}
+	if err != nil {
+		log.Println("ReviewComplainFeeDispute - Failed to review fee dispute:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to review fee dispute",
+		})
+	}
+
+	// Reload the dispute with all relationships for response
+	if err := cfdc.DB.Preload("Complain").Preload("User").Preload("ReviewUser").First(&dispute, dispute.ID).Error; err != nil {
+		log.Println("ReviewComplainFeeDispute - Failed to load fee dispute:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load fee dispute",
+		})
+	}
+
+	log.Println("ReviewComplainFeeDispute completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Fee dispute " + status + " successfully",
+		Data:    dispute.ToResponse(),
+	})
+}
+
+// GetComplainFeeChanges retrieves the fee audit trail of a complain
+// @Summary Get Complain Fee Changes
+// @Description Retrieve every change of the fees a complain charges its users, including disputes and their review, latest first
+// @Tags Complains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Complain ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.ComplainFeeChangeResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complains/{id}/fee-changes [get]
+func (cfdc *ComplainFeeDisputeController) GetComplainFeeChanges(c fiber.Ctx) error {
+	log.Println("GetComplainFeeChanges called")
+	// Parse id parameter
+	id := c.Params("id")
+	var complain models.Complain
+	if err := cfdc.DB.Where("id = ?", id).First(&complain).Error; err != nil {
+		log.Println("GetComplainFeeChanges - Complain not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain with id " + id + " not found.",
+		})
+	}
+
+	var changes []models.ComplainFeeChange
+	if err := cfdc.DB.Preload("User").Preload("ChangeUser").Where("complain_id = ?", complain.ID).Order("created_at DESC, id DESC").Find(&changes).Error; err != nil {
+		log.Println("GetComplainFeeChanges - Failed to retrieve fee changes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve fee changes",
+		})
+	}
+
+	changeList := make([]models.ComplainFeeChangeResponse, len(changes))
+	for i, change := range changes {
+		changeList[i] = *change.ToResponse()
+	}
+
+	log.Println("GetComplainFeeChanges completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain fee changes retrieved successfully",
+		Data:    changeList,
+	})
+}
+
+// complainUserFee returns the total fee the complain charges the user
+func complainUserFee(db *gorm.DB, complainID, userID uint) (int, error) {
+	var fee int
+	err := db.Model(&models.ComplainUserDetail{}).
+		Select("COALESCE(SUM(fee_charge), 0)").
+		Where("complain_id = ? AND user_id = ?", complainID, userID).
+		Scan(&fee).Error
+	return fee, err
+}
+
+// setComplainUserFee charges the user the fee on their first detail of the complain and removes their other details
+func setComplainUserFee(tx *gorm.DB, complainID, userID uint, fee int) error {
+	var details []models.ComplainUserDetail
+	if err := tx.Where("complain_id = ? AND user_id = ?", complainID, userID).Order("id ASC").Find(&details).Error; err != nil {
+		return err
+	}
+	if len(details) == 0 {
+		return errFeeDisputeNotCharged
+	}
+
+	if err := tx.Model(&details[0]).Update("fee_charge", fee).Error; err != nil {
+		return err
+	}
+	if len(details) > 1 {
+		return tx.Where("complain_id = ? AND user_id = ? AND id <> ?", complainID, userID, details[0].ID).Delete(&models.ComplainUserDetail{}).Error
+	}
+	return nil
+}
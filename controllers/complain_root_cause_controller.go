@@ -0,0 +1,334 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ComplainRootCauseController struct {
+	DB *gorm.DB
+}
+
+func NewComplainRootCauseController(db *gorm.DB) *ComplainRootCauseController {
+	return &ComplainRootCauseController{DB: db}
+}
+
+// Request structs
+type CreateComplainRootCauseRequest struct {
+	Code        string `json:"code" validate:"required,max=50" example:"wrong_item"`
+	Name        string `json:"name" validate:"required,max=100" example:"Wrong item"`
+	Description string `json:"description" example:"A different product or variant was packed"`
+	IsActive    *bool  `json:"isActive" example:"true"` // defaults to true
+}
+
+type UpdateComplainRootCauseRequest struct {
+	Code        string `json:"code" validate:"required,max=50" example:"wrong_item"`
+	Name        string `json:"name" validate:"required,max=100" example:"Wrong item"`
+	Description string `json:"description" example:"A different product or variant was packed"`
+	IsActive    bool   `json:"isActive" example:"true"`
+}
+
+// complainRootCauseCodePattern restricts root cause codes to lowercase snake case
+var complainRootCauseCodePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// validateComplainRootCauseRequest normalizes the root cause fields and checks the code is not taken
+func (crcc *ComplainRootCauseController) validateComplainRootCauseRequest(code, name, description *string, excludeID uint) error {
+	*code = strings.ToLower(strings.TrimSpace(*code))
+	*name = strings.TrimSpace(*name)
+	*description = strings.TrimSpace(*description)
+	if *code == "" || len(*code) > 50 || !complainRootCauseCodePattern.MatchString(*code) {
+		return fiber.NewError(fiber.StatusBadRequest, "Code is required, at most 50 characters of lowercase letters, digits and underscores")
+	}
+	if *code == unclassifiedRootCauseCode {
+		return fiber.NewError(fiber.StatusBadRequest, "Code "+unclassifiedRootCauseCode+" is reserved")
+	}
+	if *name == "" || len(*name) > 100 {
+		return fiber.NewError(fiber.StatusBadRequest, "Name is required and must be at most 100 characters")
+	}
+
+	var count int64
+	if err := crcc.DB.Model(&models.ComplainRootCause{}).Where("code = ? AND id != ?", *code, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fiber.NewError(fiber.StatusConflict, "Root cause with code "+*code+" already exists.")
+	}
+	return nil
+}
+
+// GetComplainRootCauses retrieves the complain root causes
+// @Summary Get Complain Root Causes
+// @Description Retrieve the taxonomy of complain root causes, selected when a complain is created or resolved
+// @Tags Complain Root Causes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Search term for code or name"
+// @Param active query bool false "Only active (true) or inactive (false) root causes"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.ComplainRootCauseResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-root-causes [get]
+func (crcc *ComplainRootCauseController) GetComplainRootCauses(c fiber.Ctx) error {
+	log.Println("GetComplainRootCauses called")
+	var rootCauses []models.ComplainRootCause
+
+	// Build base query
+	query := crcc.DB.Model(&models.ComplainRootCause{}).Order("name ASC")
+
+	// Search condition if provided
+	var filters []string
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("code ILIKE ? OR name ILIKE ?", "%"+search+"%", "%"+search+"%")
+		filters = append(filters, "search: "+search)
+	}
+
+	// Active filter if provided
+	if active := c.Query("active", ""); active != "" {
+		query = query.Where("is_active = ?", active == "true")
+		filters = append(filters, "active: "+active)
+	}
+
+	if err := query.Find(&rootCauses).Error; err != nil {
+		log.Println("GetComplainRootCauses - Failed to retrieve root causes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complain root causes",
+		})
+	}
+
+	// Format response
+	rootCauseList := make([]models.ComplainRootCauseResponse, len(rootCauses))
+	for i, rootCause := range rootCauses {
+		rootCauseList[i] = *rootCause.ToResponse()
+	}
+
+	// Build success message
+	message := "Complain root causes retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetComplainRootCauses completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    rootCauseList,
+	})
+}
+
+// GetComplainRootCause retrieves a single complain root cause by ID
+// @Summary Get Complain Root Cause
+// @Description Retrieve a single complain root cause by ID
+// @Tags Complain Root Causes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Root Cause ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainRootCauseResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/complain-root-causes/{id} [get]
+func (crcc *ComplainRootCauseController) GetComplainRootCause(c fiber.Ctx) error {
+	log.Println("GetComplainRootCause called")
+	// Parse id parameter
+	id := c.Params("id")
+	var rootCause models.ComplainRootCause
+	if err := crcc.DB.Where("id = ?", id).First(&rootCause).Error; err != nil {
+		log.Println("GetComplainRootCause - Root cause not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain root cause with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetComplainRootCause completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain root cause retrieved successfully",
+		Data:    rootCause.ToResponse(),
+	})
+}
+
+// CreateComplainRootCause creates a new complain root cause
+// @Summary Create Complain Root Cause
+// @Description Add a root cause to the complain taxonomy
+// @Tags Complain Root Causes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateComplainRootCauseRequest true "Root cause details"
+// @Success 201 {object} utils.SuccessResponse{data=models.ComplainRootCauseResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-root-causes [post]
+func (crcc *ComplainRootCauseController) CreateComplainRootCause(c fiber.Ctx) error {
+	log.Println("CreateComplainRootCause called")
+	// Binding request body
+	var req CreateComplainRootCauseRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateComplainRootCause - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := crcc.validateComplainRootCauseRequest(&req.Code, &req.Name, &req.Description, 0); err != nil {
+		log.Println("CreateComplainRootCause - Invalid root cause:", err)
+		return complainRootCauseErrorResponse(c, err)
+	}
+
+	rootCause := models.ComplainRootCause{
+		Code:        req.Code,
+		Name:        req.Name,
+		Description: req.Description,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if err := crcc.DB.Create(&rootCause).Error; err != nil {
+		log.Println("CreateComplainRootCause - Failed to create root cause:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create complain root cause",
+		})
+	}
+
+	log.Println("CreateComplainRootCause completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain root cause created successfully",
+		Data:    rootCause.ToResponse(),
+	})
+}
+
+// UpdateComplainRootCause updates an existing complain root cause by ID
+// @Summary Update Complain Root Cause
+// @Description Update a complain root cause by ID. Deactivated root causes stay on past complains but can no longer be selected
+// @Tags Complain Root Causes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Root Cause ID"
+// @Param request body UpdateComplainRootCauseRequest true "Updated root cause details"
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainRootCauseResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-root-causes/{id} [put]
+func (crcc *ComplainRootCauseController) UpdateComplainRootCause(c fiber.Ctx) error {
+	log.Println("UpdateComplainRootCause called")
+	// Parse id parameter
+	id := c.Params("id")
+	var rootCause models.ComplainRootCause
+	if err := crcc.DB.Where("id = ?", id).First(&rootCause).Error; err != nil {
+		log.Println("UpdateComplainRootCause - Root cause not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain root cause with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateComplainRootCauseRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateComplainRootCause - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := crcc.validateComplainRootCauseRequest(&req.Code, &req.Name, &req.Description, rootCause.ID); err != nil {
+		log.Println("UpdateComplainRootCause - Invalid root cause:", err)
+		return complainRootCauseErrorResponse(c, err)
+	}
+
+	rootCause.Code = req.Code
+	rootCause.Name = req.Name
+	rootCause.Description = req.Description
+	rootCause.IsActive = req.IsActive
+	if err := crcc.DB.Save(&rootCause).Error; err != nil {
+		log.Println("UpdateComplainRootCause - Failed to update root cause:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update complain root cause",
+		})
+	}
+
+	log.Println("UpdateComplainRootCause completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain root cause updated successfully",
+		Data:    rootCause.ToResponse(),
+	})
+}
+
+// DeleteComplainRootCause deletes an unused complain root cause by ID
+// @Summary Delete Complain Root Cause
+// @Description Delete a complain root cause by ID. Root causes already selected on complains cannot be deleted, deactivate them instead
+// @Tags Complain Root Causes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Root Cause ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-root-causes/{id} [delete]
+func (crcc *ComplainRootCauseController) DeleteComplainRootCause(c fiber.Ctx) error {
+	log.Println("DeleteComplainRootCause called")
+	// Parse id parameter
+	id := c.Params("id")
+	var rootCause models.ComplainRootCause
+	if err := crcc.DB.Where("id = ?", id).First(&rootCause).Error; err != nil {
+		log.Println("DeleteComplainRootCause - Root cause not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain root cause with id " + id + " not found.",
+		})
+	}
+
+	// Keep the classification of past complains intact
+	var usage int64
+	if err := crcc.DB.Model(&models.Complain{}).Where("root_cause_id = ?", rootCause.ID).Count(&usage).Error; err != nil {
+		log.Println("DeleteComplainRootCause - Failed to check root cause usage:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check complain root cause usage",
+		})
+	}
+	if usage > 0 {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Root cause %s is used by %d complains, deactivate it instead", rootCause.Name, usage),
+		})
+	}
+
+	if err := crcc.DB.Delete(&rootCause).Error; err != nil {
+		log.Println("DeleteComplainRootCause - Failed to delete root cause:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete complain root cause",
+		})
+	}
+
+	log.Println("DeleteComplainRootCause completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain root cause deleted successfully",
+	})
+}
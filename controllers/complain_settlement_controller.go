@@ -0,0 +1,464 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ComplainSettlementController struct {
+	DB *gorm.DB
+}
+
+func NewComplainSettlementController(db *gorm.DB) *ComplainSettlementController {
+	return &ComplainSettlementController{DB: db}
+}
+
+// Request structs
+type CreateComplainSettlementRequest struct {
+	Period string `json:"period" validate:"required" example:"2026-09"` // YYYY-MM, must have ended
+}
+
+type SettleComplainSettlementRequest struct {
+	Note string `json:"note" example:"Deducted in the October payroll"`
+}
+
+// complainSettlementManageRoles generate and settle complain settlements
+var complainSettlementManageRoles = []string{"developer", "superadmin", "hrd"}
+
+// findComplainSettlement loads a complain settlement with its lines and users
+func (csc *ComplainSettlementController) findComplainSettlement(c fiber.Ctx, id string) (*models.ComplainSettlement, error) {
+	var settlement models.ComplainSettlement
+	err := csc.DB.WithContext(c.Context()).Preload("GenerateUser").Preload("SettleUser").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB {
+			return db.Order("user_id ASC, complain_created_at ASC, id ASC")
+		}).Preload("Lines.User").
+		Where("id = ?", id).First(&settlement).Error
+	return &settlement, err
+}
+
+// CreateComplainSettlement generates the settlement of a month
+// @Summary Create Complain Settlement
+// @Description Freeze the fees of every resolved complain created up to the end of a finished month and not settled yet into per-user deduction statements. Complains with an open fee dispute are left for the next settlement. Settled complains can no longer have their fees edited or disputed. HRD is notified
+// @Tags Complain Settlements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateComplainSettlementRequest true "Settlement period"
+// @Success 201 {object} utils.SuccessResponse{data=models.ComplainSettlementResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-settlements [post]
+func (csc *ComplainSettlementController) CreateComplainSettlement(c fiber.Ctx) error {
+	log.Println("CreateComplainSettlement called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("CreateComplainSettlement - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req CreateComplainSettlementRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateComplainSettlement - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Period = strings.TrimSpace(req.Period)
+	periodEnd, err := utils.ComplainSettlementPeriodEnd(req.Period)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid period format. Use YYYY-MM.",
+		})
+	}
+	if periodEnd.After(time.Now()) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Period " + req.Period + " has not ended yet",
+		})
+	}
+
+	settlement, err := utils.GenerateComplainSettlement(csc.DB.WithContext(c.Context()), req.Period, uint(userID))
+	if errors.Is(err, utils.ErrComplainSettlementExists) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Settlement for " + req.Period + " has already been generated",
+		})
+	}
+	if err != nil {
+		log.Println("CreateComplainSettlement - Failed to generate settlement:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate complain settlement",
+		})
+	}
+
+	// Reload the settlement with all relationships for response
+	settlement, err = csc.findComplainSettlement(c, strconv.FormatUint(uint64(settlement.ID), 10))
+	if err != nil {
+		log.Println("CreateComplainSettlement - Failed to load settlement:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load complain settlement",
+		})
+	}
+
+	log.Println("CreateComplainSettlement completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain settlement " + req.Period + " generated successfully",
+		Data:    settlement.ToResponse(),
+	})
+}
+
+// GetComplainSettlements retrieves the complain settlements
+// @Summary Get Complain Settlements
+// @Description Retrieve the complain settlements with their totals, latest period first, with pagination and status filter
+// @Tags Complain Settlements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of settlements per page" default(10)
+// @Param status query string false "Filter by status (open, settled)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ComplainSettlementResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-settlements [get]
+func (csc *ComplainSettlementController) GetComplainSettlements(c fiber.Ctx) error {
+	log.Println("GetComplainSettlements called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var settlements []models.ComplainSettlement
+
+	// Build base query
+	query := csc.DB.Model(&models.ComplainSettlement{}).Preload("GenerateUser").Preload("SettleUser").Order("period DESC")
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		if status != models.ComplainSettlementStatusOpen && status != models.ComplainSettlementStatusSettled {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status. Use open or settled.",
+			})
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&settlements).Error; err != nil {
+		log.Println("GetComplainSettlements - Failed to retrieve settlements:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complain settlements",
+		})
+	}
+
+	// Format response
+	settlementList := make([]models.ComplainSettlementResponse, len(settlements))
+	for i, settlement := range settlements {
+		settlementList[i] = *settlement.ToResponse()
+	}
+
+	// Build success message
+	message := "Complain settlements retrieved successfully"
+	if status != "" {
+		message += fmt.Sprintf(" (filtered by status: %s)", status)
+	}
+
+	log.Println("GetComplainSettlements completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    settlementList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetComplainSettlement retrieves a complain settlement with its statements
+// @Summary Get Complain Settlement
+// @Description Retrieve a complain settlement with the deduction statement of every charged user, optionally exported to XLSX with one row per complain and user
+// @Tags Complain Settlements
+// @Accept json
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path int true "Complain Settlement ID"
+// @Param format query string false "Response format (json or xlsx)" default(json)
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainSettlementResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-settlements/{id} [get]
+func (csc *ComplainSettlementController) GetComplainSettlement(c fiber.Ctx) error {
+	log.Println("GetComplainSettlement called")
+	// Parse id parameter
+	id := c.Params("id")
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "xlsx" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or xlsx.",
+		})
+	}
+
+	settlement, err := csc.findComplainSettlement(c, id)
+	if err != nil {
+		log.Println("GetComplainSettlement - Settlement not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain settlement with id " + id + " not found.",
+		})
+	}
+
+	// Export to XLSX if requested
+	if format == "xlsx" {
+		headers := []string{"Period", "Status", "Username", "Full Name", "Complain", "Tracking Number", "Complain Created At", "Fee"}
+		var rows [][]interface{}
+		for _, statement := range settlement.Statements() {
+			for _, line := range statement.Lines {
+				rows = append(rows, []interface{}{settlement.Period, settlement.Status, statement.Username, statement.FullName, line.ComplainCode, line.TrackingNumber, line.ComplainCreatedAt, line.FeeCharge})
+			}
+		}
+		buffer, err := utils.BuildXLSX("Settlement "+settlement.Period, headers, rows)
+		if err != nil {
+			log.Println("GetComplainSettlement - Failed to build XLSX:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to export complain settlement",
+			})
+		}
+
+		log.Println("GetComplainSettlement completed successfully")
+		c.Set(fiber.HeaderContentType, utils.XLSXContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"complain-settlement-%s.xlsx\"", settlement.Period))
+		return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+	}
+
+	log.Println("GetComplainSettlement completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain settlement retrieved successfully",
+		Data:    settlement.ToResponse(),
+	})
+}
+
+// GetComplainSettlementStatement downloads the deduction statement of a user
+// @Summary Get Complain Settlement Statement
+// @Description Retrieve the deduction statement of a user in a complain settlement, optionally as a printable PDF. Users can retrieve their own statement, HRD and admins any statement
+// @Tags Complain Settlements
+// @Accept json
+// @Produce json
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Complain Settlement ID"
+// @Param userId path int true "User ID"
+// @Param format query string false "Response format (json or pdf)" default(json)
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainSettlementStatement}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-settlements/{id}/statements/{userId} [get]
+func (csc *ComplainSettlementController) GetComplainSettlementStatement(c fiber.Ctx) error {
+	log.Println("GetComplainSettlementStatement called")
+	// Get current logged in user from context
+	currentUserID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("GetComplainSettlementStatement - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse parameters
+	id := c.Params("id")
+	userID, err := strconv.ParseUint(c.Params("userId"), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "pdf" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or pdf.",
+		})
+	}
+
+	if userID != currentUserID && !utils.HasPermission(c, complainSettlementManageRoles) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You can only retrieve your own deduction statement",
+		})
+	}
+
+	settlement, err := csc.findComplainSettlement(c, id)
+	if err != nil {
+		log.Println("GetComplainSettlementStatement - Settlement not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain settlement with id " + id + " not found.",
+		})
+	}
+
+	var statement *models.ComplainSettlementStatement
+	for _, candidate := range settlement.Statements() {
+		if candidate.UserID == uint(userID) {
+			statement = &candidate
+			break
+		}
+	}
+	if statement == nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Complain settlement %s has no deductions for user %d", settlement.Period, userID),
+		})
+	}
+
+	// Render the printable statement if requested
+	if format == "pdf" {
+		buffer, err := utils.BuildTextPDF([]utils.PDFDocument{utils.BuildComplainSettlementStatementDocument(settlement, *statement)})
+		if err != nil {
+			log.Println("GetComplainSettlementStatement - Failed to build PDF:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to render deduction statement",
+			})
+		}
+
+		log.Println("GetComplainSettlementStatement completed successfully")
+		c.Set(fiber.HeaderContentType, utils.PDFContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"deduction-statement-%s-%s.pdf\"", settlement.Period, statement.Username))
+		return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+	}
+
+	log.Println("GetComplainSettlementStatement completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Deduction statement retrieved successfully",
+		Data:    statement,
+	})
+}
+
+// SettleComplainSettlement marks a complain settlement as deducted from payroll
+// @Summary Settle Complain Settlement
+// @Description Mark an open complain settlement as settled once its deductions are applied to payroll, with an optional note such as the payroll reference
+// @Tags Complain Settlements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Complain Settlement ID"
+// @Param request body SettleComplainSettlementRequest false "Settlement note"
+// @Success 200 {object} utils.SuccessResponse{data=models.ComplainSettlementResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/complain-settlements/{id}/settle [put]
+func (csc *ComplainSettlementController) SettleComplainSettlement(c fiber.Ctx) error {
+	log.Println("SettleComplainSettlement called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("SettleComplainSettlement - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body, the note is optional
+	var req SettleComplainSettlementRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			log.Println("SettleComplainSettlement - Invalid request body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid request body",
+			})
+		}
+	}
+
+	id := c.Params("id")
+	result := csc.DB.Model(&models.ComplainSettlement{}).Where("id = ? AND status = ?", id, models.ComplainSettlementStatusOpen).Updates(map[string]interface{}{
+		"status":      models.ComplainSettlementStatusSettled,
+		"settled_by":  uint(userID),
+		"settled_at":  time.Now(),
+		"settle_note": strings.TrimSpace(req.Note),
+	})
+	if result.Error != nil {
+		log.Println("SettleComplainSettlement - Failed to settle settlement:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to settle complain settlement",
+		})
+	}
+
+	settlement, err := csc.findComplainSettlement(c, id)
+	if err != nil {
+		log.Println("SettleComplainSettlement - Settlement not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain settlement with id " + id + " not found.",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Complain settlement " + settlement.Period + " has already been settled",
+		})
+	}
+
+	log.Println("SettleComplainSettlement completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Complain settlement " + settlement.Period + " settled successfully",
+		Data:    settlement.ToResponse(),
+	})
+}
+
+// complainSettledErrorResponse writes the response of a fee edit rejected because the complain is settled
+func complainSettledErrorResponse(c fiber.Ctx, complain *models.Complain) error {
+	return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Complain " + complain.Code + " is settled, its fees can no longer be changed",
+	})
+}
@@ -0,0 +1,389 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type DataRepairController struct {
+	DB *gorm.DB
+}
+
+func NewDataRepairController(db *gorm.DB) *DataRepairController {
+	return &DataRepairController{DB: db}
+}
+
+// Unique request structs
+type RunDataRepairRequest struct {
+	Rule      string `json:"rule" example:"status_spelling"`
+	DryRun    *bool  `json:"dryRun" example:"true"` // defaults to true to avoid accidental fixes
+	BatchSize int    `json:"batchSize" example:"500"`
+	MaxRows   int    `json:"maxRows" example:"0"` // rows changed at most, 0 changes every row
+}
+
+// Unique response structs
+// DataRepairRuleResponse describes a data repair rule
+type DataRepairRuleResponse struct {
+	Rule        string   `json:"rule"`
+	Description string   `json:"description"`
+	Columns     []string `json:"columns"`
+}
+
+// DataRepairRunResultResponse represents a data repair run with the value changes found by a dry run
+type DataRepairRunResultResponse struct {
+	Run     *models.DataRepairRunResponse `json:"run"`
+	Preview []utils.DataRepairPreview     `json:"preview,omitempty"`
+}
+
+// RevertDataRepairResponse represents the outcome of a data repair revert
+type RevertDataRepairResponse struct {
+	Run      *models.DataRepairRunResponse `json:"run"`
+	Reverted int64                         `json:"reverted"`
+	Kept     int64                         `json:"kept"` // values changed again since the run, left as they are
+}
+
+// GetDataRepairRules lists the data repair rules
+// @Summary Get Data Repair Rules
+// @Description List the data repair rules with the columns each of them changes
+// @Tags Data Repairs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]DataRepairRuleResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/data-repairs/rules [get]
+func (drc *DataRepairController) GetDataRepairRules(c fiber.Ctx) error {
+	log.Println("GetDataRepairRules called")
+
+	rules := utils.DataRepairRules()
+	ruleList := make([]DataRepairRuleResponse, len(rules))
+	for i := range rules {
+		ruleList[i] = DataRepairRuleResponse{
+			Rule:        rules[i].Name,
+			Description: rules[i].Description,
+			Columns:     rules[i].Columns(),
+		}
+	}
+
+	log.Println("GetDataRepairRules completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Data repair rules retrieved successfully",
+		Data:    ruleList,
+	})
+}
+
+// RunDataRepair runs a data repair rule
+// @Summary Run Data Repair
+// @Description Run a data repair rule. Runs as a dry-run by default, reporting the rows each column would change, the rows left for manual review and the most frequent value changes without modifying data. Otherwise rows are changed in batches, each in its own transaction, at most maxRows when set so a large fix can be applied progressively. Every changed value is backed up so the run can be reverted, and every run is recorded with its affected row counts
+// @Tags Data Repairs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RunDataRepairRequest true "Rule and batching"
+// @Success 200 {object} utils.SuccessResponse{data=DataRepairRunResultResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/data-repairs/runs [post]
+func (drc *DataRepairController) RunDataRepair(c fiber.Ctx) error {
+	log.Println("RunDataRepair called")
+	// Binding request body
+	var req RunDataRepairRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("RunDataRepair - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	rule, ok := utils.FindDataRepairRule(strings.TrimSpace(req.Rule))
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid rule " + req.Rule,
+		})
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+	if req.BatchSize == 0 {
+		req.BatchSize = utils.DefaultDataRepairBatchSize
+	}
+	if req.BatchSize < 1 || req.BatchSize > utils.MaxDataRepairBatchSize {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("batchSize must be between 1 and %d", utils.MaxDataRepairBatchSize),
+		})
+	}
+	if req.MaxRows < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "maxRows must not be negative",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	triggeredBy := uint(userID)
+	run, preview, err := utils.RunDataRepair(drc.DB.WithContext(c.Context()), rule, dryRun, req.BatchSize, req.MaxRows, &triggeredBy)
+	if err != nil {
+		log.Println("RunDataRepair - Failed to run data repair:", err)
+		message := "Failed to run data repair"
+		if run != nil && run.ID != 0 {
+			message += fmt.Sprintf(", run %d recorded the %d rows changed before the error", run.ID, run.AffectedRows)
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Reload data repair run with trigger user for response
+	if err := drc.DB.WithContext(c.Context()).Preload("Details").Preload("TriggerUser").First(run, run.ID).Error; err != nil {
+		log.Println("RunDataRepair - Failed to load data repair run:", err)
+	}
+
+	message := fmt.Sprintf("Data repair %s changed %d rows", rule.Name, run.AffectedRows)
+	if dryRun {
+		message = fmt.Sprintf("Data repair %s dry-run found %d rows to change", rule.Name, run.AffectedRows)
+	}
+
+	log.Println("RunDataRepair completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: DataRepairRunResultResponse{
+			Run:     run.ToResponse(),
+			Preview: preview,
+		},
+	})
+}
+
+// GetDataRepairRuns retrieves the audit log of data repair runs
+// @Summary Get Data Repair Runs
+// @Description Retrieve the audit log of data repair runs and dry-runs with their affected row counts per column
+// @Tags Data Repairs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of data repair runs per page" default(10)
+// @Param rule query string false "Filter by rule"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.DataRepairRunResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/data-repairs/runs [get]
+func (drc *DataRepairController) GetDataRepairRuns(c fiber.Ctx) error {
+	log.Println("GetDataRepairRuns called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var runs []models.DataRepairRun
+
+	// Build base query
+	query := drc.DB.WithContext(c.Context()).Model(&models.DataRepairRun{}).Preload("Details").Preload("TriggerUser").Preload("RevertUser").Order("created_at DESC")
+
+	// Rule filter if provided
+	rule := c.Query("rule", "")
+	if rule != "" {
+		query = query.Where("rule = ?", rule)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		log.Println("GetDataRepairRuns - Failed to retrieve data repair runs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve data repair runs",
+		})
+	}
+
+	// Format response
+	runList := make([]models.DataRepairRunResponse, len(runs))
+	for i, run := range runs {
+		runList[i] = *run.ToResponse()
+	}
+
+	message := "Data repair runs retrieved successfully"
+	if rule != "" {
+		message += " (filtered by rule: " + rule + ")"
+	}
+
+	log.Println("GetDataRepairRuns completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    runList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetDataRepairChanges retrieves the backed up values of a data repair run
+// @Summary Get Data Repair Changes
+// @Description Retrieve the values changed by a data repair run, with the value each row held before
+// @Tags Data Repairs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Data repair run ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of changes per page" default(50)
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.DataRepairChangeResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/data-repairs/runs/{id}/changes [get]
+func (drc *DataRepairController) GetDataRepairChanges(c fiber.Ctx) error {
+	log.Println("GetDataRepairChanges called")
+	// Parse id parameter
+	id := c.Params("id")
+	var run models.DataRepairRun
+	if err := drc.DB.WithContext(c.Context()).Where("id = ?", id).First(&run).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Data repair run with id " + id + " not found.",
+		})
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "50"))
+	offset := (page - 1) * limit
+
+	var changes []models.DataRepairChange
+	query := drc.DB.WithContext(c.Context()).Model(&models.DataRepairChange{}).Where("data_repair_run_id = ?", run.ID).Order("id ASC")
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&changes).Error; err != nil {
+		log.Println("GetDataRepairChanges - Failed to retrieve data repair changes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve data repair changes",
+		})
+	}
+
+	// Format response
+	changeList := make([]models.DataRepairChangeResponse, len(changes))
+	for i, change := range changes {
+		changeList[i] = *change.ToResponse()
+	}
+
+	log.Println("GetDataRepairChanges completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: "Data repair changes retrieved successfully",
+		Data:    changeList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// RevertDataRepair restores the values changed by a data repair run
+// @Summary Revert Data Repair
+// @Description Restore the backed up values of a data repair run in batches. Values changed again since the run are kept and reported, reverting the run again retries them
+// @Tags Data Repairs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Data repair run ID"
+// @Success 200 {object} utils.SuccessResponse{data=RevertDataRepairResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/data-repairs/runs/{id}/revert [post]
+func (drc *DataRepairController) RevertDataRepair(c fiber.Ctx) error {
+	log.Println("RevertDataRepair called")
+	// Parse id parameter
+	id := c.Params("id")
+	var run models.DataRepairRun
+	if err := drc.DB.WithContext(c.Context()).Where("id = ?", id).First(&run).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Data repair run with id " + id + " not found.",
+		})
+	}
+	if run.DryRun {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Dry-runs did not change any data",
+		})
+	}
+	// A run still applying its batches would keep changing rows behind the revert
+	if run.Status == models.DataRepairStatusRunning && time.Since(run.UpdatedAt) < time.Hour {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Data repair run is still running",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	revertedBy := uint(userID)
+	reverted, kept, err := utils.RevertDataRepair(drc.DB.WithContext(c.Context()), &run, run.BatchSize, &revertedBy)
+	if err != nil {
+		log.Println("RevertDataRepair - Failed to revert data repair:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Failed to revert data repair after restoring %d values", reverted),
+		})
+	}
+
+	// Reload data repair run with its users for response
+	if err := drc.DB.WithContext(c.Context()).Preload("Details").Preload("TriggerUser").Preload("RevertUser").First(&run, run.ID).Error; err != nil {
+		log.Println("RevertDataRepair - Failed to load data repair run:", err)
+	}
+
+	message := fmt.Sprintf("Data repair run %d reverted, %d values restored", run.ID, reverted)
+	if kept > 0 {
+		message += fmt.Sprintf(", %d values changed since the run were kept", kept)
+	}
+
+	log.Println("RevertDataRepair completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: RevertDataRepairResponse{
+			Run:      run.ToResponse(),
+			Reverted: reverted,
+			Kept:     kept,
+		},
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -22,15 +23,19 @@ func NewExpeditionController(db *gorm.DB) *ExpeditionController {
 
 // Request structs
 type CreateExpeditionRequest struct {
-	ExpeditionCode  string `json:"expeditionCode" validate:"required,min=1,max=4"`
-	ExpeditionName  string `json:"expeditionName" validate:"required,min=3,max=100"`
-	ExpeditionColor string `json:"expeditionColor" validate:"required,min=3,max=20"`
+	ExpeditionCode   string   `json:"expeditionCode" validate:"required,min=1,max=4"`
+	ExpeditionName   string   `json:"expeditionName" validate:"required,min=3,max=100"`
+	ExpeditionColor  string   `json:"expeditionColor" validate:"required,min=3,max=20"`
+	CutOffTime       string   `json:"cutOffTime" validate:"omitempty,datetime=15:04" example:"16:00"`
+	ServiceProvinces []string `json:"serviceProvinces" validate:"omitempty"` // provinces the courier delivers to, empty delivers everywhere
 }
 
 type UpdateExpeditionRequest struct {
-	ExpeditionCode  string `json:"expeditionCode" validate:"required,min=1,max=4"`
-	ExpeditionName  string `json:"expeditionName" validate:"required,min=3,max=100"`
-	ExpeditionColor string `json:"expeditionColor" validate:"required,min=3,max=20"`
+	ExpeditionCode   string   `json:"expeditionCode" validate:"required,min=1,max=4"`
+	ExpeditionName   string   `json:"expeditionName" validate:"required,min=3,max=100"`
+	ExpeditionColor  string   `json:"expeditionColor" validate:"required,min=3,max=20"`
+	CutOffTime       string   `json:"cutOffTime" validate:"omitempty,datetime=15:04" example:"16:00"`
+	ServiceProvinces []string `json:"serviceProvinces" validate:"omitempty"` // provinces the courier delivers to, empty delivers everywhere
 }
 
 // GetExpeditions retrieves a list of expeditions with pagination and search
@@ -172,6 +177,17 @@ func (bc *ExpeditionController) CreateExpedition(c fiber.Ctx) error {
 	// Convert expedition code to uppercase and trim spaces
 	req.ExpeditionCode = strings.ToUpper(strings.TrimSpace(req.ExpeditionCode))
 
+	// Cut-off time is a time of day in HH:MM
+	req.CutOffTime = strings.TrimSpace(req.CutOffTime)
+	if req.CutOffTime != "" {
+		if _, err := time.Parse("15:04", req.CutOffTime); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid cutOffTime format. Use HH:MM.",
+			})
+		}
+	}
+
 	// Check for existing expedition with same code
 	var existingExpedition models.Expedition
 	if err := bc.DB.Where("expedition_code = ?", req.ExpeditionCode).First(&existingExpedition).Error; err == nil {
@@ -184,10 +200,12 @@ func (bc *ExpeditionController) CreateExpedition(c fiber.Ctx) error {
 
 	// Create new expedition
 	newExpedition := models.Expedition{
-		ExpeditionCode:  req.ExpeditionCode,
-		ExpeditionName:  req.ExpeditionName,
-		ExpeditionSlug:  utils.GenerateSlug(req.ExpeditionName),
-		ExpeditionColor: req.ExpeditionColor,
+		ExpeditionCode:   req.ExpeditionCode,
+		ExpeditionName:   req.ExpeditionName,
+		ExpeditionSlug:   utils.GenerateSlug(req.ExpeditionName),
+		ExpeditionColor:  req.ExpeditionColor,
+		CutOffTime:       req.CutOffTime,
+		ServiceProvinces: joinServiceProvinces(req.ServiceProvinces),
 	}
 
 	if err := bc.DB.Create(&newExpedition).Error; err != nil {
@@ -246,6 +264,17 @@ func (bc *ExpeditionController) UpdateExpedition(c fiber.Ctx) error {
 	// Convert expedition code to uppercase and trim spaces
 	req.ExpeditionCode = strings.ToUpper(strings.TrimSpace(req.ExpeditionCode))
 
+	// Cut-off time is a time of day in HH:MM
+	req.CutOffTime = strings.TrimSpace(req.CutOffTime)
+	if req.CutOffTime != "" {
+		if _, err := time.Parse("15:04", req.CutOffTime); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid cutOffTime format. Use HH:MM.",
+			})
+		}
+	}
+
 	// Check for existing expedition with same code (excluding current expedition)
 	var existingExpedition models.Expedition
 	if err := bc.DB.Where("expedition_code = ? AND id != ?", req.ExpeditionCode, id).First(&existingExpedition).Error; err == nil {
@@ -261,6 +290,8 @@ func (bc *ExpeditionController) UpdateExpedition(c fiber.Ctx) error {
 	expedition.ExpeditionName = req.ExpeditionName
 	expedition.ExpeditionSlug = utils.GenerateSlug(req.ExpeditionName)
 	expedition.ExpeditionColor = req.ExpeditionColor
+	expedition.CutOffTime = req.CutOffTime
+	expedition.ServiceProvinces = joinServiceProvinces(req.ServiceProvinces)
 
 	if err := bc.DB.Save(&expedition).Error; err != nil {
 		log.Println("Failed to update expedition:", err)
@@ -319,3 +350,14 @@ func (bc *ExpeditionController) DeleteExpedition(c fiber.Ctx) error {
 		Message: "Expedition deleted successfully",
 	})
 }
+
+// joinServiceProvinces stores the provinces a courier delivers to as a comma separated list
+func joinServiceProvinces(provinces []string) string {
+	var cleaned []string
+	for _, province := range provinces {
+		if province = strings.Join(strings.Fields(strings.ReplaceAll(province, ",", " ")), " "); province != "" {
+			cleaned = append(cleaned, province)
+		}
+	}
+	return strings.Join(cleaned, ", ")
+}
@@ -0,0 +1,290 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ExportJobController struct {
+	DB     *gorm.DB
+	Config *config.Config
+}
+
+func NewExportJobController(cfg *config.Config, db *gorm.DB) *ExportJobController {
+	return &ExportJobController{DB: db, Config: cfg}
+}
+
+// findExportJob loads an export job of the current user, developers and superadmins can access every export job
+func (ejc *ExportJobController) findExportJob(c fiber.Ctx, id string) (*models.ExportJob, error) {
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid user ID")
+	}
+
+	query := ejc.DB.WithContext(c.Context()).Preload("RequestUser").Where("id = ?", id)
+	if !utils.HasPermission(c, []string{"developer", "superadmin"}) {
+		query = query.Where("requested_by = ?", userID)
+	}
+
+	var job models.ExportJob
+	if err := query.Omit("file").First(&job).Error; err != nil {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Export job with id "+id+" not found.")
+	}
+	return &job, nil
+}
+
+// exportJobErrorResponse writes the response of an export job lookup error
+func exportJobErrorResponse(c fiber.Ctx, err error) error {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return c.Status(fiberErr.Code).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fiberErr.Message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Failed to retrieve export job",
+	})
+}
+
+// GetExportJobs retrieves the export jobs of the current user
+// @Summary Get Export Jobs
+// @Description Retrieve the background export jobs requested by the current user, newest first. Developers and superadmins see every export job
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number for pagination (default is 1)"
+// @Param limit query int false "Number of items per page for pagination (default is 10)"
+// @Param exportType query string false "Filter by export type"
+// @Param status query string false "Filter by status (pending, running, completed, failed)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ExportJobResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/exports [get]
+func (ejc *ExportJobController) GetExportJobs(c fiber.Ctx) error {
+	log.Println("GetExportJobs called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Surface exports interrupted by a restart as failed
+	if err := utils.FailStaleExportJobs(ejc.DB); err != nil {
+		log.Println("GetExportJobs - Failed to expire stale export jobs:", err)
+	}
+
+	var jobs []models.ExportJob
+
+	// Build base query, the generated files are only loaded on download
+	query := ejc.DB.WithContext(c.Context()).Model(&models.ExportJob{}).Omit("file").Preload("RequestUser").Order("created_at DESC, id DESC")
+	if !utils.HasPermission(c, []string{"developer", "superadmin"}) {
+		query = query.Where("requested_by = ?", userID)
+	}
+
+	// Export type and status filters if provided
+	exportType := c.Query("exportType", "")
+	if exportType != "" {
+		query = query.Where("export_type = ?", exportType)
+	}
+	status := c.Query("status", "")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&jobs).Error; err != nil {
+		log.Println("GetExportJobs - Failed to retrieve export jobs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve export jobs",
+		})
+	}
+
+	// Format response
+	jobList := make([]models.ExportJobResponse, len(jobs))
+	for i, job := range jobs {
+		jobList[i] = *job.ToResponse()
+	}
+
+	// Build success message
+	message := "Export jobs retrieved successfully"
+	var filters []string
+
+	if exportType != "" {
+		filters = append(filters, "exportType: "+exportType)
+	}
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetExportJobs completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    jobList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetExportJob retrieves the status of a single export job
+// @Summary Get Export Job
+// @Description Retrieve the status of a background export job, poll it until it is completed or failed
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Export job ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.ExportJobResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/exports/{id} [get]
+func (ejc *ExportJobController) GetExportJob(c fiber.Ctx) error {
+	log.Println("GetExportJob called")
+	// Surface exports interrupted by a restart as failed
+	if err := utils.FailStaleExportJobs(ejc.DB); err != nil {
+		log.Println("GetExportJob - Failed to expire stale export jobs:", err)
+	}
+
+	job, err := ejc.findExportJob(c, c.Params("id"))
+	if err != nil {
+		log.Println("GetExportJob - Export job not found:", err)
+		return exportJobErrorResponse(c, err)
+	}
+
+	log.Println("GetExportJob completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Export job retrieved successfully",
+		Data:    job.ToResponse(),
+	})
+}
+
+// DownloadExportJob downloads the file generated by a completed export job
+// @Summary Download Export Job
+// @Description Download the file generated by a completed background export job
+// @Tags Exports
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path int true "Export job ID"
+// @Success 200 {file} file
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/exports/{id}/download [get]
+func (ejc *ExportJobController) DownloadExportJob(c fiber.Ctx) error {
+	log.Println("DownloadExportJob called")
+	job, err := ejc.findExportJob(c, c.Params("id"))
+	if err != nil {
+		log.Println("DownloadExportJob - Export job not found:", err)
+		return exportJobErrorResponse(c, err)
+	}
+	if job.Status != models.ExportJobStatusCompleted {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Export job is " + job.Status + ", only completed exports can be downloaded",
+		})
+	}
+
+	// Load the generated file, jobs completed before the file storage keep it in the database
+	var content []byte
+	if job.StorageKey != "" {
+		content, err = utils.GetStorage().Get(c.Context(), job.StorageKey)
+	} else {
+		var stored models.ExportJob
+		err = ejc.DB.WithContext(c.Context()).Select("file").Where("id = ?", job.ID).First(&stored).Error
+		content = stored.File
+	}
+	if err != nil {
+		log.Println("DownloadExportJob - Failed to load export file:", err)
+		if errors.Is(err, utils.ErrStorageObjectNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Export file not found, request the export again",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load export file",
+		})
+	}
+
+	log.Println("DownloadExportJob completed successfully")
+	c.Set(fiber.HeaderContentType, job.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", job.FileName))
+	return c.Status(fiber.StatusOK).Send(content)
+}
+
+// GetExportJobDownloadURL creates a signed download link for the file of a completed export job
+// @Summary Get Export Job Download URL
+// @Description Create a short lived signed link to the file generated by a completed background export job. The link works without a bearer token until it expires, so it can be opened directly by the browser or served by a CDN. Only exports kept in the file storage have links, older exports are downloaded through /api/exports/{id}/download
+// @Tags Exports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Export job ID"
+// @Success 200 {object} utils.SuccessResponse{data=utils.SignedDownload}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /api/exports/{id}/download-url [get]
+func (ejc *ExportJobController) GetExportJobDownloadURL(c fiber.Ctx) error {
+	log.Println("GetExportJobDownloadURL called")
+	job, err := ejc.findExportJob(c, c.Params("id"))
+	if err != nil {
+		log.Println("GetExportJobDownloadURL - Export job not found:", err)
+		return exportJobErrorResponse(c, err)
+	}
+	if job.Status != models.ExportJobStatusCompleted {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Export job is " + job.Status + ", only completed exports can be downloaded",
+		})
+	}
+	if job.StorageKey == "" {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Export file is kept in the database, download it through /api/exports/" + c.Params("id") + "/download",
+		})
+	}
+
+	log.Println("GetExportJobDownloadURL completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Export download link created successfully",
+		Data:    utils.SignDownloadURL(ejc.Config, job.StorageKey, job.FileName, time.Now()),
+	})
+}
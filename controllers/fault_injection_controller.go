@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/utils"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type FaultInjectionController struct{}
+
+func NewFaultInjectionController() *FaultInjectionController {
+	return &FaultInjectionController{}
+}
+
+// Request structs
+type SetFaultInjectionRequest struct {
+	Enabled bool              `json:"enabled"`
+	Rules   []utils.FaultRule `json:"rules"`
+}
+
+// GetFaultInjection retrieves the fault injection rules
+// @Summary Get Fault Injection
+// @Description Retrieve the latency and error rules injected into the database and DeepFace per route. Only available in staging with FAULT_INJECTION_ENABLED.
+// @Tags Fault Injection
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=utils.FaultInjectionState}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/fault-injection [get]
+func (fic *FaultInjectionController) GetFaultInjection(c fiber.Ctx) error {
+	log.Println("GetFaultInjection called")
+
+	if !utils.FaultInjectionAvailable() {
+		log.Println("GetFaultInjection - Fault injection is not available")
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Fault injection is only available in " + utils.FaultInjectionEnv,
+		})
+	}
+
+	state := utils.GetFaultInjection()
+
+	message := "Fault injection is off"
+	if state.Enabled {
+		message = fmt.Sprintf("Fault injection is on (%d rules)", len(state.Rules))
+	}
+
+	log.Println("GetFaultInjection completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    state,
+	})
+}
+
+// SetFaultInjection replaces the fault injection rules
+// @Summary Set Fault Injection
+// @Description Replace the fault rules and switch fault injection on or off. A rule matches a method and path prefix ("POST /api/mobile-orders"), a path prefix for any method, or "*" for every route. Database rules fail the request before the handler, or after it with afterHandler so its writes are committed; DeepFace rules fail the DeepFace calls of the request. Failed requests carry the X-Fault-Injected header. Only available in staging with FAULT_INJECTION_ENABLED.
+// @Tags Fault Injection
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SetFaultInjectionRequest true "Fault rules"
+// @Success 200 {object} utils.SuccessResponse{data=utils.FaultInjectionState}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/fault-injection [put]
+func (fic *FaultInjectionController) SetFaultInjection(c fiber.Ctx) error {
+	log.Println("SetFaultInjection called")
+
+	if !utils.FaultInjectionAvailable() {
+		log.Println("SetFaultInjection - Fault injection is not available")
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Fault injection is only available in " + utils.FaultInjectionEnv,
+		})
+	}
+
+	// Parse request body
+	var req SetFaultInjectionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SetFaultInjection - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	for i := range req.Rules {
+		req.Rules[i].Route = strings.TrimSpace(req.Rules[i].Route)
+		req.Rules[i].Target = strings.ToLower(strings.TrimSpace(req.Rules[i].Target))
+		if err := req.Rules[i].Validate(); err != nil {
+			log.Println("SetFaultInjection - Invalid fault rule:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Invalid rule %d: %s", i+1, err.Error()),
+			})
+		}
+	}
+
+	username, _ := c.Locals("username").(string)
+	state := utils.SetFaultInjection(req.Enabled, req.Rules, username)
+
+	message := "Fault injection switched off"
+	if state.Enabled {
+		message = fmt.Sprintf("Fault injection switched on (%d rules)", len(state.Rules))
+	}
+
+	log.Printf("SetFaultInjection completed successfully (enabled=%t, rules=%d, by=%s)\n", state.Enabled, len(state.Rules), username)
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    state,
+	})
+}
@@ -0,0 +1,452 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// featureFlagKeyPattern restricts flag keys to lower snake case so they read the same in code and in the API
+var featureFlagKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,99}$`)
+
+type FeatureFlagController struct {
+	DB *gorm.DB
+}
+
+func NewFeatureFlagController(db *gorm.DB) *FeatureFlagController {
+	return &FeatureFlagController{DB: db}
+}
+
+// Request structs
+type CreateFeatureFlagRequest struct {
+	FlagKey           string   `json:"flagKey" validate:"required,max=100" example:"complain_auto_assign"`
+	Description       string   `json:"description" example:"Round-robin assignment of new complains"`
+	Enabled           bool     `json:"enabled" example:"true"`
+	Environments      []string `json:"environments" example:"staging,production"` // empty for every environment
+	Roles             []string `json:"roles" example:"admin"`                     // empty for every role
+	RolloutPercentage *int     `json:"rolloutPercentage" example:"100"`           // defaults to 100
+}
+
+type UpdateFeatureFlagRequest struct {
+	Description       string   `json:"description" example:"Round-robin assignment of new complains"`
+	Enabled           bool     `json:"enabled" example:"true"`
+	Environments      []string `json:"environments" example:"staging,production"`
+	Roles             []string `json:"roles" example:"admin"`
+	RolloutPercentage *int     `json:"rolloutPercentage" example:"50"`
+}
+
+// joinFeatureFlagList stores environments or roles as a comma separated list
+func joinFeatureFlagList(values []string) string {
+	var cleaned []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			cleaned = append(cleaned, value)
+		}
+	}
+	return strings.Join(cleaned, ",")
+}
+
+// validateRolloutPercentage defaults a missing rollout to every user and checks it is a percentage
+func validateRolloutPercentage(percentage *int) (int, error) {
+	if percentage == nil {
+		return 100, nil
+	}
+	if *percentage < 0 || *percentage > 100 {
+		return 0, errors.New("rolloutPercentage must be between 0 and 100")
+	}
+	return *percentage, nil
+}
+
+// recordFeatureFlagAudit stores a flag change in the audit trail and drops the cached flags
+func recordFeatureFlagAudit(tx *gorm.DB, key, action, before, after string, changedBy uint) error {
+	if err := tx.Create(&models.FeatureFlagAudit{
+		FlagKey:   key,
+		Action:    action,
+		Before:    before,
+		After:     after,
+		ChangedBy: changedBy,
+	}).Error; err != nil {
+		return err
+	}
+	utils.InvalidateFeatureFlags()
+	return nil
+}
+
+// GetFeatureFlags retrieves every feature flag
+// @Summary Get Feature Flags
+// @Description Retrieve every feature flag with the environments, roles and rollout percentage it is on for
+// @Tags Feature Flags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]models.FeatureFlagResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/feature-flags [get]
+func (ffc *FeatureFlagController) GetFeatureFlags(c fiber.Ctx) error {
+	log.Println("GetFeatureFlags called")
+	var flags []models.FeatureFlag
+	if err := ffc.DB.Preload("UpdateUser").Order("flag_key ASC").Find(&flags).Error; err != nil {
+		log.Println("GetFeatureFlags - Failed to retrieve feature flags:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve feature flags",
+		})
+	}
+
+	flagList := make([]models.FeatureFlagResponse, len(flags))
+	for i, flag := range flags {
+		flagList[i] = *flag.ToResponse()
+	}
+
+	log.Println("GetFeatureFlags completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Feature flags retrieved successfully",
+		Data:    flagList,
+	})
+}
+
+// GetMyFeatureFlags retrieves the feature flags evaluated for the current user
+// @Summary Get My Feature Flags
+// @Description Retrieve whether each feature flag is on for the current user in this environment, so clients can hide features that are off
+// @Tags Feature Flags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=map[string]bool}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/feature-flags/me [get]
+func (ffc *FeatureFlagController) GetMyFeatureFlags(c fiber.Ctx) error {
+	log.Println("GetMyFeatureFlags called")
+	features, err := utils.EnabledFeatures(ffc.DB, utils.FeatureSubjectFromContext(c))
+	if err != nil {
+		log.Println("GetMyFeatureFlags - Failed to evaluate feature flags:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to evaluate feature flags",
+		})
+	}
+
+	log.Println("GetMyFeatureFlags completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Feature flags retrieved successfully",
+		Data:    features,
+	})
+}
+
+// CreateFeatureFlag creates a feature flag
+// @Summary Create Feature Flag
+// @Description Create a feature flag. Features check their flag by key, features without a flag keep their default behaviour
+// @Tags Feature Flags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateFeatureFlagRequest true "Feature flag details"
+// @Success 201 {object} utils.SuccessResponse{data=models.FeatureFlagResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/feature-flags [post]
+func (ffc *FeatureFlagController) CreateFeatureFlag(c fiber.Ctx) error {
+	log.Println("CreateFeatureFlag called")
+	// Getting current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req CreateFeatureFlagRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateFeatureFlag - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.FlagKey = strings.ToLower(strings.TrimSpace(req.FlagKey))
+	if !featureFlagKeyPattern.MatchString(req.FlagKey) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "flagKey must be 2 to 100 lowercase letters, digits or underscores, starting with a letter",
+		})
+	}
+	rollout, err := validateRolloutPercentage(req.RolloutPercentage)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// Check the key is not taken
+	var existing int64
+	ffc.DB.Model(&models.FeatureFlag{}).Where("flag_key = ?", req.FlagKey).Count(&existing)
+	if existing > 0 {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Feature flag " + req.FlagKey + " already exists",
+		})
+	}
+
+	changedBy := uint(userID)
+	flag := models.FeatureFlag{
+		FlagKey:           req.FlagKey,
+		Description:       strings.TrimSpace(req.Description),
+		Enabled:           req.Enabled,
+		Environments:      joinFeatureFlagList(req.Environments),
+		Roles:             joinFeatureFlagList(req.Roles),
+		RolloutPercentage: rollout,
+		UpdatedBy:         &changedBy,
+	}
+	if err := ffc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&flag).Error; err != nil {
+			return err
+		}
+		return recordFeatureFlagAudit(tx, flag.FlagKey, models.FeatureFlagAuditCreate, "", flag.Settings(), changedBy)
+	}); err != nil {
+		log.Println("CreateFeatureFlag - Failed to create feature flag:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create feature flag",
+		})
+	}
+
+	ffc.DB.Preload("UpdateUser").First(&flag, flag.ID)
+
+	log.Println("CreateFeatureFlag completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Feature flag created successfully",
+		Data:    flag.ToResponse(),
+	})
+}
+
+// UpdateFeatureFlag updates a feature flag
+// @Summary Update Feature Flag
+// @Description Switch a feature flag on or off and change the environments, roles and rollout percentage it is on for. The change is recorded in the audit trail and applies at once
+// @Tags Feature Flags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Feature flag key"
+// @Param request body UpdateFeatureFlagRequest true "Feature flag settings"
+// @Success 200 {object} utils.SuccessResponse{data=models.FeatureFlagResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/feature-flags/{key} [put]
+func (ffc *FeatureFlagController) UpdateFeatureFlag(c fiber.Ctx) error {
+	log.Println("UpdateFeatureFlag called")
+	// Parse key parameter
+	key := c.Params("key")
+	var flag models.FeatureFlag
+	if err := ffc.DB.Where("flag_key = ?", key).First(&flag).Error; err != nil {
+		log.Println("UpdateFeatureFlag - Feature flag not found:", key)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Feature flag " + key + " not found.",
+		})
+	}
+
+	// Getting current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req UpdateFeatureFlagRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateFeatureFlag - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	rollout, err := validateRolloutPercentage(req.RolloutPercentage)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	before := flag.Settings()
+	changedBy := uint(userID)
+	flag.Description = strings.TrimSpace(req.Description)
+	flag.Enabled = req.Enabled
+	flag.Environments = joinFeatureFlagList(req.Environments)
+	flag.Roles = joinFeatureFlagList(req.Roles)
+	flag.RolloutPercentage = rollout
+	flag.UpdatedBy = &changedBy
+	if err := ffc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&flag).Error; err != nil {
+			return err
+		}
+		return recordFeatureFlagAudit(tx, flag.FlagKey, models.FeatureFlagAuditUpdate, before, flag.Settings(), changedBy)
+	}); err != nil {
+		log.Println("UpdateFeatureFlag - Failed to update feature flag:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update feature flag",
+		})
+	}
+
+	ffc.DB.Preload("UpdateUser").First(&flag, flag.ID)
+
+	log.Printf("UpdateFeatureFlag completed successfully (%s: %s -> %s)\n", flag.FlagKey, before, flag.Settings())
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Feature flag updated successfully",
+		Data:    flag.ToResponse(),
+	})
+}
+
+// DeleteFeatureFlag deletes a feature flag
+// @Summary Delete Feature Flag
+// @Description Delete a feature flag, the feature it guards goes back to its default behaviour. The deletion is recorded in the audit trail
+// @Tags Feature Flags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key path string true "Feature flag key"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/feature-flags/{key} [delete]
+func (ffc *FeatureFlagController) DeleteFeatureFlag(c fiber.Ctx) error {
+	log.Println("DeleteFeatureFlag called")
+	// Parse key parameter
+	key := c.Params("key")
+	var flag models.FeatureFlag
+	if err := ffc.DB.Where("flag_key = ?", key).First(&flag).Error; err != nil {
+		log.Println("DeleteFeatureFlag - Feature flag not found:", key)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Feature flag " + key + " not found.",
+		})
+	}
+
+	// Getting current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	if err := ffc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&flag).Error; err != nil {
+			return err
+		}
+		return recordFeatureFlagAudit(tx, flag.FlagKey, models.FeatureFlagAuditDelete, flag.Settings(), "", uint(userID))
+	}); err != nil {
+		log.Println("DeleteFeatureFlag - Failed to delete feature flag:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete feature flag",
+		})
+	}
+
+	log.Println("DeleteFeatureFlag completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Feature flag deleted successfully",
+	})
+}
+
+// GetFeatureFlagAudits retrieves the feature flag audit trail
+// @Summary Get Feature Flag Audits
+// @Description Retrieve the feature flag changes with the settings before and after, with pagination, newest first
+// @Tags Feature Flags
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of changes per page" default(10)
+// @Param flagKey query string false "Filter by feature flag key"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.FeatureFlagAuditResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/feature-flags/audits [get]
+func (ffc *FeatureFlagController) GetFeatureFlagAudits(c fiber.Ctx) error {
+	log.Println("GetFeatureFlagAudits called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var audits []models.FeatureFlagAudit
+
+	// Build base query
+	query := ffc.DB.Model(&models.FeatureFlagAudit{}).Preload("ChangeUser").Order("created_at DESC, id DESC")
+
+	var filters []string
+
+	// Filter by flag key if provided
+	flagKey := strings.TrimSpace(c.Query("flagKey", ""))
+	if flagKey != "" {
+		query = query.Where("flag_key = ?", flagKey)
+		filters = append(filters, "flagKey: "+flagKey)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&audits).Error; err != nil {
+		log.Println("GetFeatureFlagAudits - Failed to retrieve feature flag audits:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve feature flag audits",
+		})
+	}
+
+	// Format response
+	auditList := make([]models.FeatureFlagAuditResponse, len(audits))
+	for i, audit := range audits {
+		auditList[i] = *audit.ToResponse()
+	}
+
+	// Build success message
+	message := "Feature flag audits retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetFeatureFlagAudits completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    auditList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
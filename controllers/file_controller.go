@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/utils"
+	"log"
+	"mime"
+	"path"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type FileController struct {
+	Config *config.Config
+}
+
+func NewFileController(cfg *config.Config) *FileController {
+	return &FileController{Config: cfg}
+}
+
+// DownloadSignedFile serves a stored file through a signed download link
+// @Summary Download File
+// @Description Download a stored file, e.g. an export file, through a signed link handed out by an authenticated endpoint. The link carries the storage key, the file name, its expiry and an HMAC signature, so it works without a bearer token until it expires and can also be validated by a CDN or nginx sharing the secret. Rate limited per IP
+// @Tags Files
+// @Produce octet-stream
+// @Param key query string true "Storage key"
+// @Param name query string false "File name of the download"
+// @Param expires query int true "Expiry as unix time"
+// @Param signature query string true "HMAC signature"
+// @Success 200 {file} file
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 410 {object} utils.ErrorResponse
+// @Failure 429 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/files/download [get]
+func (fc *FileController) DownloadSignedFile(c fiber.Ctx) error {
+	log.Println("DownloadSignedFile called")
+	key := c.Query("key")
+	fileName := c.Query("name")
+
+	expiresAt, err := utils.VerifyDownloadURL(fc.Config, key, fileName, c.Query("expires"), c.Query("signature"), time.Now())
+	if err != nil {
+		if errors.Is(err, utils.ErrDownloadLinkExpired) {
+			return c.Status(fiber.StatusGone).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Download link expired, request a new link",
+			})
+		}
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid download link",
+		})
+	}
+
+	content, err := utils.GetStorage().Get(c.Context(), key)
+	if err != nil {
+		log.Println("DownloadSignedFile - Failed to load file:", err)
+		if errors.Is(err, utils.ErrStorageObjectNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "File not found",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load file",
+		})
+	}
+
+	if fileName == "" {
+		fileName = path.Base(key)
+	}
+	contentType := mime.TypeByExtension(path.Ext(fileName))
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+
+	log.Println("DownloadSignedFile completed successfully")
+	// The link is the credential, private caches may keep the file until the link expires
+	c.Set(fiber.HeaderCacheControl, fmt.Sprintf("private, max-age=%d", int(time.Until(expiresAt).Seconds())))
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+	return c.Status(fiber.StatusOK).Send(content)
+}
@@ -0,0 +1,1134 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type HandoverController struct {
+	DB *gorm.DB
+}
+
+func NewHandoverController(db *gorm.DB) *HandoverController {
+	return &HandoverController{DB: db}
+}
+
+// Request structs
+type OpenHandoverSessionRequest struct {
+	ExpeditionSlug string `json:"expeditionSlug"`
+	Notes          string `json:"notes"`
+}
+
+type ScanHandoverOutboundRequest struct {
+	TrackingNumber string `json:"trackingNumber" validate:"required"`
+}
+
+type CloseHandoverSessionRequest struct {
+	DriverName      string `json:"driverName" validate:"required"`
+	DriverPhoto     string `json:"driverPhoto" validate:"required"`
+	DriverSignature string `json:"driverSignature" validate:"required"`
+	Notes           string `json:"notes"`
+}
+
+type HandoverAckRequest struct {
+	ManifestReference string   `json:"manifestReference" validate:"required"`
+	SessionCode       string   `json:"sessionCode"` // optional, otherwise the session holding most of the parcels is used
+	TrackingNumbers   []string `json:"trackingNumbers" validate:"required"`
+}
+
+// Unique response structs
+// HandoverExpeditionCount represents the number of parcels handed over per expedition
+type HandoverExpeditionCount struct {
+	Expedition string `json:"expedition"`
+	Count      int    `json:"count"`
+}
+
+// HandoverReportResponse represents the handover report of a single courier visit
+type HandoverReportResponse struct {
+	Session          *models.HandoverSessionResponse `json:"session"`
+	ExpeditionCounts []HandoverExpeditionCount       `json:"expeditionCounts"`
+	NotHandedOver    []string                        `json:"notHandedOver"`
+	GeneratedAt      string                          `json:"generatedAt"`
+}
+
+// HandoverCutoffRow represents the pickup countdown of a single expedition on the handover wallboard
+type HandoverCutoffRow struct {
+	ExpeditionID     uint   `json:"expeditionId"`
+	Expedition       string `json:"expedition"`
+	ExpeditionSlug   string `json:"expeditionSlug"`
+	ExpeditionColor  string `json:"expeditionColor"`
+	PickupTime       string `json:"pickupTime,omitempty"`       // configured cut-off, HH:MM, empty when not configured
+	PickupAt         string `json:"pickupAt,omitempty"`         // today's pickup moment, for client side countdowns
+	MinutesRemaining *int   `json:"minutesRemaining,omitempty"` // negative once the pickup time has passed
+	Ready            int    `json:"ready"`                      // QC completed, waiting for outbound
+	InPicking        int    `json:"inPicking"`                  // not picked yet or picking in progress
+	InQC             int    `json:"inQc"`                       // picked and waiting for or in QC
+	Outbound         int64  `json:"outbound"`                   // scanned out today
+	Total            int    `json:"total"`                      // parcels still in the pipeline
+}
+
+// HandoverCutoffResponse represents today's pickup countdowns per expedition
+type HandoverCutoffResponse struct {
+	GeneratedAt string              `json:"generatedAt"`
+	Expeditions []HandoverCutoffRow `json:"expeditions"` // earliest pickup first, expeditions without a pickup time last
+}
+
+// loadHandoverSession loads a handover session with all relationships needed for the response
+func (hc *HandoverController) loadHandoverSession(id interface{}) (*models.HandoverSession, error) {
+	var session models.HandoverSession
+	if err := hc.DB.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Items.Outbound").Preload("Items.ScanUser").Preload("OpenUser").Preload("CloseUser").Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetHandoverSessions retrieves a list of handover sessions with pagination and filters
+// @Summary Get Handover Sessions
+// @Description Retrieve a list of courier handover sessions with pagination, status filter and search
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of handover sessions per page" default(10)
+// @Param status query string false "Filter by status (open, closed)"
+// @Param search query string false "Search term for session code, driver name or expedition"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.HandoverSessionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handovers [get]
+func (hc *HandoverController) GetHandoverSessions(c fiber.Ctx) error {
+	log.Println("GetHandoverSessions called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var sessions []models.HandoverSession
+
+	// Build base query
+	query := hc.DB.Model(&models.HandoverSession{}).Preload("Items").Preload("OpenUser").Preload("CloseUser").Order("created_at DESC")
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("session_code ILIKE ? OR driver_name ILIKE ? OR expedition ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&sessions).Error; err != nil {
+		log.Println("GetHandoverSessions - Failed to retrieve handover sessions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve handover sessions",
+		})
+	}
+
+	// Format response without items, only totals
+	sessionList := make([]models.HandoverSessionResponse, len(sessions))
+	for i, session := range sessions {
+		sessionList[i] = *session.ToResponse()
+		sessionList[i].Items = nil
+		sessionList[i].DriverPhoto = ""
+		sessionList[i].DriverSignature = ""
+	}
+
+	// Build success message
+	message := "Handover sessions retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	// Return success response
+	log.Println("GetHandoverSessions completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    sessionList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetHandoverSession retrieves a single handover session by ID
+// @Summary Get Handover Session
+// @Description Retrieve a single courier handover session with its scanned outbounds
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Handover Session ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.HandoverSessionResponse}
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/handovers/{id} [get]
+func (hc *HandoverController) GetHandoverSession(c fiber.Ctx) error {
+	log.Println("GetHandoverSession called")
+	// Parse id parameter
+	id := c.Params("id")
+	session, err := hc.loadHandoverSession(id)
+	if err != nil {
+		log.Println("GetHandoverSession - Handover session not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetHandoverSession completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Handover session retrieved successfully",
+		Data:    session.ToResponse(),
+	})
+}
+
+// OpenHandoverSession opens a new handover session for a courier visit
+// @Summary Open Handover Session
+// @Description Open a new handover session for a courier pickup, optionally restricted to a single expedition
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param session body OpenHandoverSessionRequest true "Handover session data"
+// @Success 201 {object} utils.SuccessResponse{data=models.HandoverSessionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handovers [post]
+func (hc *HandoverController) OpenHandoverSession(c fiber.Ctx) error {
+	log.Println("OpenHandoverSession called")
+	// Binding request body
+	var req OpenHandoverSessionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("OpenHandoverSession - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	session := models.HandoverSession{
+		SessionCode: utils.GenerateHandoverCode(hc.DB),
+		Status:      "open",
+		OpenedBy:    uint(userID),
+		Notes:       strings.TrimSpace(req.Notes),
+	}
+
+	// Restrict the session to a single expedition if provided
+	req.ExpeditionSlug = strings.TrimSpace(req.ExpeditionSlug)
+	if req.ExpeditionSlug != "" {
+		var expedition models.Expedition
+		if err := hc.DB.Where("expedition_slug = ?", req.ExpeditionSlug).First(&expedition).Error; err != nil {
+			log.Println("OpenHandoverSession - Expedition not found:", req.ExpeditionSlug)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Expedition " + req.ExpeditionSlug + " not found",
+			})
+		}
+		session.Expedition = expedition.ExpeditionName
+		session.ExpeditionSlug = expedition.ExpeditionSlug
+		session.ExpeditionColor = expedition.ExpeditionColor
+	}
+
+	if err := hc.DB.Create(&session).Error; err != nil {
+		log.Println("OpenHandoverSession - Failed to create handover session:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to open handover session",
+		})
+	}
+
+	created, err := hc.loadHandoverSession(session.ID)
+	if err != nil {
+		log.Println("OpenHandoverSession - Failed to load created handover session:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve created handover session",
+		})
+	}
+
+	log.Println("OpenHandoverSession completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Handover session opened successfully",
+		Data:    created.ToResponse(),
+	})
+}
+
+// ScanHandoverOutbound scans an outbound into an open handover session
+// @Summary Scan Outbound Into Handover Session
+// @Description Scan an outbound tracking number into an open courier handover session
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Handover Session ID"
+// @Param scan body ScanHandoverOutboundRequest true "Outbound tracking number"
+// @Success 200 {object} utils.SuccessResponse{data=models.HandoverSessionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handovers/{id}/scan [post]
+func (hc *HandoverController) ScanHandoverOutbound(c fiber.Ctx) error {
+	log.Println("ScanHandoverOutbound called")
+	// Parse id parameter
+	id := c.Params("id")
+	var session models.HandoverSession
+	if err := hc.DB.Where("id = ?", id).First(&session).Error; err != nil {
+		log.Println("ScanHandoverOutbound - Handover session not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session with id " + id + " not found.",
+		})
+	}
+
+	if session.Status != "open" {
+		log.Println("ScanHandoverOutbound - Handover session is not open:", session.Status)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session " + session.SessionCode + " is already closed",
+		})
+	}
+
+	// Binding request body
+	var req ScanHandoverOutboundRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ScanHandoverOutbound - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Convert tracking number to uppercase and trim spaces
+	trackingNumber := models.NormalizeIdentifier(req.TrackingNumber)
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Outbound must exist before it can be handed over
+	var outbound models.Outbound
+	if err := hc.DB.Where("tracking_number = ?", trackingNumber).First(&outbound).Error; err != nil {
+		log.Println("ScanHandoverOutbound - Outbound not found:", trackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No outbound found with tracking number " + trackingNumber,
+		})
+	}
+
+	// Expedition must match the session expedition if restricted
+	if session.ExpeditionSlug != "" && outbound.ExpeditionSlug != session.ExpeditionSlug {
+		log.Println("ScanHandoverOutbound - Expedition mismatch:", outbound.ExpeditionSlug)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Outbound %s belongs to %s, not %s", trackingNumber, outbound.Expedition, session.Expedition),
+		})
+	}
+
+	// An outbound can only be handed over once
+	var existingItem models.HandoverSessionItem
+	if err := hc.DB.Preload("HandoverSession").Where("outbound_id = ?", outbound.ID).First(&existingItem).Error; err == nil {
+		sessionCode := ""
+		if existingItem.HandoverSession != nil {
+			sessionCode = existingItem.HandoverSession.SessionCode
+		}
+		log.Println("ScanHandoverOutbound - Outbound already handed over:", trackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Outbound " + trackingNumber + " already scanned in handover session " + sessionCode,
+		})
+	}
+
+	item := models.HandoverSessionItem{
+		HandoverSessionID: session.ID,
+		OutboundID:        outbound.ID,
+		TrackingNumber:    outbound.TrackingNumber,
+		ScannedBy:         uint(userID),
+	}
+	if err := hc.DB.Create(&item).Error; err != nil {
+		log.Println("ScanHandoverOutbound - Failed to create handover session item:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to scan outbound into handover session",
+		})
+	}
+
+	// Handed over parcels leave the staging area they waited in
+	if err := utils.ReleaseStagingPlacement(hc.DB, outbound.TrackingNumber, models.StagingRemovalHandover, uint(userID)); err != nil {
+		log.Println("ScanHandoverOutbound - Failed to release staging placement:", err)
+	}
+
+	updated, err := hc.loadHandoverSession(session.ID)
+	if err != nil {
+		log.Println("ScanHandoverOutbound - Failed to load handover session:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load handover session",
+		})
+	}
+
+	log.Println("ScanHandoverOutbound completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Outbound " + trackingNumber + " scanned into handover session successfully",
+		Data:    updated.ToResponse(),
+	})
+}
+
+// RemoveHandoverOutbound removes a mistakenly scanned outbound from an open handover session
+// @Summary Remove Outbound From Handover Session
+// @Description Remove a scanned outbound from an open courier handover session
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Handover Session ID"
+// @Param itemId path int true "Handover Session Item ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.HandoverSessionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handovers/{id}/items/{itemId} [delete]
+func (hc *HandoverController) RemoveHandoverOutbound(c fiber.Ctx) error {
+	log.Println("RemoveHandoverOutbound called")
+	// Parse id parameters
+	id := c.Params("id")
+	itemID := c.Params("itemId")
+	var session models.HandoverSession
+	if err := hc.DB.Where("id = ?", id).First(&session).Error; err != nil {
+		log.Println("RemoveHandoverOutbound - Handover session not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session with id " + id + " not found.",
+		})
+	}
+
+	if session.Status != "open" {
+		log.Println("RemoveHandoverOutbound - Handover session is not open:", session.Status)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session " + session.SessionCode + " is already closed",
+		})
+	}
+
+	result := hc.DB.Where("id = ? AND handover_session_id = ?", itemID, session.ID).Delete(&models.HandoverSessionItem{})
+	if result.Error != nil {
+		log.Println("RemoveHandoverOutbound - Failed to delete handover session item:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to remove outbound from handover session",
+		})
+	}
+	if result.RowsAffected == 0 {
+		log.Println("RemoveHandoverOutbound - Handover session item not found:", itemID)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session item with id " + itemID + " not found.",
+		})
+	}
+
+	updated, err := hc.loadHandoverSession(session.ID)
+	if err != nil {
+		log.Println("RemoveHandoverOutbound - Failed to load handover session:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load handover session",
+		})
+	}
+
+	log.Println("RemoveHandoverOutbound completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Outbound removed from handover session successfully",
+		Data:    updated.ToResponse(),
+	})
+}
+
+// CloseHandoverSession closes a handover session with the courier driver confirmation
+// @Summary Close Handover Session
+// @Description Close a courier handover session with driver name, photo and signature
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Handover Session ID"
+// @Param close body CloseHandoverSessionRequest true "Driver confirmation"
+// @Success 200 {object} utils.SuccessResponse{data=models.HandoverSessionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handovers/{id}/close [put]
+func (hc *HandoverController) CloseHandoverSession(c fiber.Ctx) error {
+	log.Println("CloseHandoverSession called")
+	// Parse id parameter
+	id := c.Params("id")
+	session, err := hc.loadHandoverSession(id)
+	if err != nil {
+		log.Println("CloseHandoverSession - Handover session not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session with id " + id + " not found.",
+		})
+	}
+
+	if session.Status != "open" {
+		log.Println("CloseHandoverSession - Handover session is not open:", session.Status)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session " + session.SessionCode + " is already closed",
+		})
+	}
+
+	if len(session.Items) == 0 {
+		log.Println("CloseHandoverSession - Handover session has no outbounds:", session.SessionCode)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Cannot close a handover session without scanned outbounds",
+		})
+	}
+
+	// Binding request body
+	var req CloseHandoverSessionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CloseHandoverSession - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.DriverName = strings.TrimSpace(req.DriverName)
+	if req.DriverName == "" || req.DriverPhoto == "" || req.DriverSignature == "" {
+		log.Println("CloseHandoverSession - Missing driver confirmation")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Driver name, photo and signature are required",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	closedBy := uint(userID)
+	closedAt := time.Now()
+	updates := map[string]interface{}{
+		"status":           "closed",
+		"closed_by":        closedBy,
+		"closed_at":        closedAt,
+		"driver_name":      req.DriverName,
+		"driver_photo":     req.DriverPhoto,
+		"driver_signature": req.DriverSignature,
+	}
+	if notes := strings.TrimSpace(req.Notes); notes != "" {
+		updates["notes"] = notes
+	}
+
+	// Only close the session if it is still open to avoid concurrent closes
+	result := hc.DB.Model(&models.HandoverSession{}).Where("id = ? AND status = ?", session.ID, "open").Updates(updates)
+	if result.Error != nil {
+		log.Println("CloseHandoverSession - Failed to close handover session:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to close handover session",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session " + session.SessionCode + " is already closed",
+		})
+	}
+
+	updated, err := hc.loadHandoverSession(session.ID)
+	if err != nil {
+		log.Println("CloseHandoverSession - Failed to load handover session:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load handover session",
+		})
+	}
+
+	log.Println("CloseHandoverSession completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Handover session closed successfully",
+		Data:    updated.ToResponse(),
+	})
+}
+
+// GetHandoverReport generates the handover report of a session
+// @Summary Get Handover Report
+// @Description Generate the handover report of a courier visit with parcel counts per expedition and same-day outbounds not handed over in any session
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Handover Session ID"
+// @Success 200 {object} utils.SuccessResponse{data=HandoverReportResponse}
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handovers/{id}/report [get]
+func (hc *HandoverController) GetHandoverReport(c fiber.Ctx) error {
+	log.Println("GetHandoverReport called")
+	// Parse id parameter
+	id := c.Params("id")
+	session, err := hc.loadHandoverSession(id)
+	if err != nil {
+		log.Println("GetHandoverReport - Handover session not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover session with id " + id + " not found.",
+		})
+	}
+
+	// Count parcels per expedition
+	expeditionCounts := []HandoverExpeditionCount{}
+	countIndex := make(map[string]int)
+	for _, item := range session.Items {
+		expedition := ""
+		if item.Outbound != nil {
+			expedition = item.Outbound.Expedition
+		}
+		if idx, ok := countIndex[expedition]; ok {
+			expeditionCounts[idx].Count++
+			continue
+		}
+		countIndex[expedition] = len(expeditionCounts)
+		expeditionCounts = append(expeditionCounts, HandoverExpeditionCount{Expedition: expedition, Count: 1})
+	}
+
+	// Find outbounds created on the session day that were not handed over in any session
+	startOfDay := time.Date(session.CreatedAt.Year(), session.CreatedAt.Month(), session.CreatedAt.Day(), 0, 0, 0, 0, session.CreatedAt.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+
+	query := hc.DB.Model(&models.Outbound{}).
+		Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay).
+		Where("id NOT IN (?)", hc.DB.Model(&models.HandoverSessionItem{}).Select("outbound_id"))
+	if session.ExpeditionSlug != "" {
+		query = query.Where("expedition_slug = ?", session.ExpeditionSlug)
+	}
+
+	notHandedOver := []string{}
+	if err := query.Order("created_at ASC").Pluck("tracking_number", &notHandedOver).Error; err != nil {
+		log.Println("GetHandoverReport - Failed to retrieve outbounds not handed over:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate handover report",
+		})
+	}
+
+	log.Println("GetHandoverReport completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Handover report generated successfully",
+		Data: HandoverReportResponse{
+			Session:          session.ToResponse(),
+			ExpeditionCounts: expeditionCounts,
+			NotHandedOver:    notHandedOver,
+			GeneratedAt:      time.Now().Format("02-01-2006 15:04:05"),
+		},
+	})
+}
+
+// handoverAckNotifyRoles are notified when a 3PL acknowledgment does not match our handover session
+var handoverAckNotifyRoles = []string{"coordinator", "outbound"}
+
+// AcknowledgeHandover records a 3PL's confirmation of the parcels they picked up
+// @Summary Acknowledge Handover
+// @Description Inbound 3PL endpoint (API key with handovers:write scope) confirming receipt of a pickup manifest. Acknowledged outbounds are marked as handed over and discrepancies against the handover session are recorded.
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param ack body HandoverAckRequest true "Manifest reference and received tracking numbers"
+// @Success 201 {object} utils.SuccessResponse{data=models.HandoverAckResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handover/ack [post]
+func (hc *HandoverController) AcknowledgeHandover(c fiber.Ctx) error {
+	log.Println("AcknowledgeHandover called")
+	// Acknowledgments come from the 3PL system, not from logged in users
+	apiKeyID, ok := c.Locals("apiKeyId").(uint)
+	if !ok {
+		log.Println("AcknowledgeHandover - Request not made with an API key")
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Handover acknowledgments must be sent with a 3PL API key",
+		})
+	}
+
+	// Binding request body
+	var req HandoverAckRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("AcknowledgeHandover - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	manifestReference := strings.TrimSpace(req.ManifestReference)
+	if manifestReference == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Manifest reference is required",
+		})
+	}
+
+	// Normalize tracking numbers and drop duplicates
+	trackingNumbers := []string{}
+	seen := make(map[string]bool)
+	for _, trackingNumber := range req.TrackingNumbers {
+		trackingNumber = models.NormalizeIdentifier(trackingNumber)
+		if trackingNumber == "" || seen[trackingNumber] {
+			continue
+		}
+		seen[trackingNumber] = true
+		trackingNumbers = append(trackingNumbers, trackingNumber)
+	}
+	if len(trackingNumbers) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one tracking number is required",
+		})
+	}
+
+	// A manifest is acknowledged only once per 3PL
+	var existingCount int64
+	hc.DB.Model(&models.HandoverAck{}).Where("manifest_reference = ? AND api_key_id = ?", manifestReference, apiKeyID).Count(&existingCount)
+	if existingCount > 0 {
+		log.Println("AcknowledgeHandover - Manifest already acknowledged:", manifestReference)
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Manifest " + manifestReference + " has already been acknowledged",
+		})
+	}
+
+	// Find the outbounds and the handover sessions they were scanned into
+	var outbounds []models.Outbound
+	if err := hc.DB.Where("tracking_number IN ?", trackingNumbers).Find(&outbounds).Error; err != nil {
+		log.Println("AcknowledgeHandover - Failed to retrieve outbounds:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to acknowledge handover",
+		})
+	}
+	outboundByTracking := make(map[string]models.Outbound, len(outbounds))
+	outboundIDs := make([]uint, len(outbounds))
+	for i, outbound := range outbounds {
+		outboundByTracking[outbound.TrackingNumber] = outbound
+		outboundIDs[i] = outbound.ID
+	}
+
+	var scannedItems []models.HandoverSessionItem
+	if len(outboundIDs) > 0 {
+		if err := hc.DB.Where("outbound_id IN ?", outboundIDs).Find(&scannedItems).Error; err != nil {
+			log.Println("AcknowledgeHandover - Failed to retrieve handover session items:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to acknowledge handover",
+			})
+		}
+	}
+	sessionByOutbound := make(map[uint]uint, len(scannedItems))
+	for _, item := range scannedItems {
+		sessionByOutbound[item.OutboundID] = item.HandoverSessionID
+	}
+
+	// Resolve the session being acknowledged, by code or by the session holding most of the parcels
+	var sessionID *uint
+	sessionCode := strings.TrimSpace(req.SessionCode)
+	if sessionCode != "" {
+		var session models.HandoverSession
+		if err := hc.DB.Where("session_code = ?", sessionCode).First(&session).Error; err != nil {
+			log.Println("AcknowledgeHandover - Handover session not found:", sessionCode)
+			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Handover session " + sessionCode + " not found",
+			})
+		}
+		sessionID = &session.ID
+	} else {
+		parcelsPerSession := make(map[uint]int)
+		for _, id := range sessionByOutbound {
+			parcelsPerSession[id]++
+		}
+		for id, count := range parcelsPerSession {
+			if sessionID == nil || count > parcelsPerSession[*sessionID] || (count == parcelsPerSession[*sessionID] && id < *sessionID) {
+				sessionID = &id
+			}
+		}
+	}
+
+	// Classify every acknowledged tracking number
+	ack := models.HandoverAck{
+		ManifestReference: manifestReference,
+		APIKeyID:          apiKeyID,
+		HandoverSessionID: sessionID,
+		ReceivedCount:     len(trackingNumbers),
+	}
+	handedOverIDs := []uint{}
+	for _, trackingNumber := range trackingNumbers {
+		item := models.HandoverAckItem{TrackingNumber: trackingNumber, Status: models.HandoverAckItemUnknown}
+		if outbound, ok := outboundByTracking[trackingNumber]; ok {
+			item.OutboundID = &outbound.ID
+			handedOverIDs = append(handedOverIDs, outbound.ID)
+			scannedSessionID, scanned := sessionByOutbound[outbound.ID]
+			switch {
+			case !scanned:
+				item.Status = models.HandoverAckItemNotScanned
+			case sessionID != nil && scannedSessionID == *sessionID:
+				item.Status = models.HandoverAckItemMatched
+			default:
+				item.Status = models.HandoverAckItemOtherSession
+			}
+		}
+		if item.Status == models.HandoverAckItemMatched {
+			ack.MatchedCount++
+		} else {
+			ack.DiscrepancyCount++
+		}
+		ack.Items = append(ack.Items, item)
+	}
+
+	// Parcels scanned into the session that the 3PL did not acknowledge
+	if sessionID != nil {
+		var sessionItems []models.HandoverSessionItem
+		if err := hc.DB.Where("handover_session_id = ?", *sessionID).Order("created_at ASC").Find(&sessionItems).Error; err != nil {
+			log.Println("AcknowledgeHandover - Failed to retrieve handover session items:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to acknowledge handover",
+			})
+		}
+		for _, sessionItem := range sessionItems {
+			if seen[sessionItem.TrackingNumber] {
+				continue
+			}
+			outboundID := sessionItem.OutboundID
+			ack.Items = append(ack.Items, models.HandoverAckItem{
+				TrackingNumber: sessionItem.TrackingNumber,
+				OutboundID:     &outboundID,
+				Status:         models.HandoverAckItemMissing,
+			})
+			ack.DiscrepancyCount++
+		}
+	}
+
+	err := hc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ack).Error; err != nil {
+			return err
+		}
+
+		// Mark acknowledged outbounds as handed over, keeping the first acknowledgment time
+		if len(handedOverIDs) > 0 {
+			if err := tx.Model(&models.Outbound{}).Where("id IN ? AND handed_over_at IS NULL", handedOverIDs).
+				Update("handed_over_at", ack.CreatedAt).Error; err != nil {
+				return err
+			}
+		}
+
+		if ack.DiscrepancyCount > 0 {
+			message := fmt.Sprintf("3PL manifest %s acknowledged %d of %d parcels with %d discrepancies",
+				manifestReference, ack.MatchedCount, ack.ReceivedCount, ack.DiscrepancyCount)
+			return utils.NotifyRoles(tx, handoverAckNotifyRoles, "handover_discrepancy", "Handover acknowledgment discrepancy", message, "handover_ack", ack.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("AcknowledgeHandover - Failed to record handover acknowledgment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to acknowledge handover",
+		})
+	}
+
+	hc.DB.Preload("Items").Preload("APIKey").Preload("HandoverSession").First(&ack, ack.ID)
+
+	message := "Handover acknowledged successfully"
+	if ack.DiscrepancyCount > 0 {
+		message = fmt.Sprintf("Handover acknowledged with %d discrepancies", ack.DiscrepancyCount)
+	}
+
+	log.Println("AcknowledgeHandover completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    ack.ToResponse(),
+	})
+}
+
+// GetHandoverAcks retrieves 3PL handover acknowledgments with pagination and filters
+// @Summary Get Handover Acknowledgments
+// @Description Retrieve 3PL handover acknowledgments with their discrepancies
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of acknowledgments per page" default(10)
+// @Param sessionId query int false "Filter by handover session ID"
+// @Param discrepancy query bool false "Only acknowledgments with discrepancies"
+// @Param search query string false "Search term for manifest reference"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.HandoverAckResponse}
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handovers/acks [get]
+func (hc *HandoverController) GetHandoverAcks(c fiber.Ctx) error {
+	log.Println("GetHandoverAcks called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var acks []models.HandoverAck
+
+	// Build base query
+	query := hc.DB.Model(&models.HandoverAck{}).Preload("Items").Preload("APIKey").Preload("HandoverSession").Order("created_at DESC")
+
+	// Session filter if provided
+	sessionID := strings.TrimSpace(c.Query("sessionId", ""))
+	if sessionID != "" {
+		query = query.Where("handover_session_id = ?", sessionID)
+	}
+
+	// Discrepancy filter if provided
+	discrepancy := c.Query("discrepancy", "") == "true"
+	if discrepancy {
+		query = query.Where("discrepancy_count > 0")
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("manifest_reference ILIKE ?", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&acks).Error; err != nil {
+		log.Println("GetHandoverAcks - Failed to retrieve handover acknowledgments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve handover acknowledgments",
+		})
+	}
+
+	ackList := make([]models.HandoverAckResponse, len(acks))
+	for i, ack := range acks {
+		ackList[i] = *ack.ToResponse()
+	}
+
+	// Build success message
+	message := "Handover acknowledgments retrieved successfully"
+	var filters []string
+
+	if sessionID != "" {
+		filters = append(filters, "sessionId: "+sessionID)
+	}
+
+	if discrepancy {
+		filters = append(filters, "discrepancy: true")
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetHandoverAcks completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    ackList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetHandoverCutoffs retrieves today's pickup countdowns per expedition for the wallboard
+// @Summary Get Handover Cutoffs
+// @Description Retrieve, for each expedition with parcels in the pipeline, the configured pickup time, the minutes remaining and the parcels ready vs still in picking or QC, earliest pickup first. Minutes remaining turn negative once the pickup time has passed.
+// @Tags Handovers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=HandoverCutoffResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/handovers/cutoffs [get]
+func (hc *HandoverController) GetHandoverCutoffs(c fiber.Ctx) error {
+	log.Println("GetHandoverCutoffs called")
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var expeditions []models.Expedition
+	if err := hc.DB.Order("expedition_name ASC").Find(&expeditions).Error; err != nil {
+		log.Println("GetHandoverCutoffs - Failed to retrieve expeditions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve expeditions",
+		})
+	}
+
+	// Parcels still to be handed over, by the courier of the order
+	var orders []models.Order
+	if err := hc.DB.Select("id, tracking_number, courier, processing_status").
+		Where("event_status = ? AND tracking_number <> ? AND processing_status IN ?", models.EventStatusInProgress, "",
+			[]string{"ready_to_pick", "picking_progress", "picking_pending", "picking_completed", "qc_progress", "qc_completed"}).
+		Find(&orders).Error; err != nil {
+		log.Println("GetHandoverCutoffs - Failed to retrieve pipeline orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pipeline orders",
+		})
+	}
+
+	// Parcels already scanned out today
+	var outbounds []struct {
+		ExpeditionSlug string
+		Count          int64
+	}
+	if err := hc.DB.Model(&models.Outbound{}).Select("expedition_slug, COUNT(*) AS count").
+		Where("created_at >= ?", startOfDay).Group("expedition_slug").Scan(&outbounds).Error; err != nil {
+		log.Println("GetHandoverCutoffs - Failed to count outbounds:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to count outbounds",
+		})
+	}
+	outboundBySlug := make(map[string]int64, len(outbounds))
+	for _, row := range outbounds {
+		outboundBySlug[row.ExpeditionSlug] = row.Count
+	}
+
+	rows := make(map[uint]*HandoverCutoffRow)
+	for i := range orders {
+		expedition := utils.MatchOrderExpedition(expeditions, &orders[i])
+		if expedition == nil {
+			continue
+		}
+
+		row, ok := rows[expedition.ID]
+		if !ok {
+			row = &HandoverCutoffRow{
+				ExpeditionID:    expedition.ID,
+				Expedition:      expedition.ExpeditionName,
+				ExpeditionSlug:  expedition.ExpeditionSlug,
+				ExpeditionColor: expedition.ExpeditionColor,
+				Outbound:        outboundBySlug[expedition.ExpeditionSlug],
+			}
+			if pickupAt, configured := expedition.CutOffOn(now); configured {
+				minutes := int(math.Floor(pickupAt.Sub(now).Minutes()))
+				row.PickupTime = pickupAt.Format("15:04")
+				row.PickupAt = pickupAt.Format("02-01-2006 15:04:05")
+				row.MinutesRemaining = &minutes
+			}
+			rows[expedition.ID] = row
+		}
+
+		row.Total++
+		switch orders[i].ProcessingStatus {
+		case "qc_completed":
+			row.Ready++
+		case "picking_completed", "qc_progress":
+			row.InQC++
+		default:
+			row.InPicking++
+		}
+	}
+
+	response := HandoverCutoffResponse{
+		GeneratedAt: now.Format("02-01-2006 15:04:05"),
+		Expeditions: make([]HandoverCutoffRow, 0, len(rows)),
+	}
+	for _, row := range rows {
+		response.Expeditions = append(response.Expeditions, *row)
+	}
+	sort.Slice(response.Expeditions, func(a, b int) bool {
+		left, right := response.Expeditions[a], response.Expeditions[b]
+		if (left.PickupTime == "") != (right.PickupTime == "") {
+			return left.PickupTime != ""
+		}
+		if left.PickupTime != right.PickupTime {
+			return left.PickupTime < right.PickupTime
+		}
+		return left.Expedition < right.Expedition
+	})
+
+	log.Println("GetHandoverCutoffs completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Handover cutoffs retrieved successfully",
+		Data:    response,
+	})
+}
@@ -0,0 +1,689 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type InboundController struct {
+	DB *gorm.DB
+}
+
+func NewInboundController(db *gorm.DB) *InboundController {
+	return &InboundController{DB: db}
+}
+
+// Request structs
+type CreateInboundShipmentRequest struct {
+	Supplier        string                       `json:"supplier" validate:"required"`
+	ReferenceNumber string                       `json:"referenceNumber"` // PO or manifest number
+	ExpectedAt      string                       `json:"expectedAt"`      // YYYY-MM-DD
+	Notes           string                       `json:"notes"`
+	Details         []CreateInboundDetailRequest `json:"details" validate:"required"`
+}
+
+type CreateInboundDetailRequest struct {
+	SKU              string `json:"sku" validate:"required"`
+	ExpectedQuantity int    `json:"expectedQuantity" validate:"required"`
+	LotNumber        string `json:"lotNumber"`
+	ExpiryDate       string `json:"expiryDate"` // YYYY-MM-DD
+}
+
+type ReceiveInboundShipmentRequest struct {
+	Details []ReceiveInboundDetailRequest `json:"details" validate:"required"`
+}
+
+type ReceiveInboundDetailRequest struct {
+	SKU              string `json:"sku" validate:"required"`
+	ReceivedQuantity int    `json:"receivedQuantity"`
+	DamagedQuantity  int    `json:"damagedQuantity"`
+	LotNumber        string `json:"lotNumber"`
+	ExpiryDate       string `json:"expiryDate"` // YYYY-MM-DD
+	DiscrepancyNote  string `json:"discrepancyNote"`
+}
+
+// loadInboundShipment loads an inbound shipment with all relationships needed for the response
+func (ibc *InboundController) loadInboundShipment(id interface{}) (*models.InboundShipment, error) {
+	var shipment models.InboundShipment
+	if err := ibc.DB.Preload("Details", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Preload("Details.ReceiveUser").Preload("CreateUser").Preload("CompleteUser").Preload("CancelUser").Where("id = ?", id).First(&shipment).Error; err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date, returning nil for an empty value
+func parseOptionalDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// GetInboundShipments retrieves a list of inbound shipments with pagination and filters
+// @Summary Get Inbound Shipments
+// @Description Retrieve a list of supplier inbound shipments with pagination, status filter and search
+// @Tags Inbounds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of inbound shipments per page" default(10)
+// @Param status query string false "Filter by status (registered, receiving, completed, canceled)"
+// @Param search query string false "Search term for code, supplier or reference number"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.InboundShipmentResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/inbounds [get]
+func (ibc *InboundController) GetInboundShipments(c fiber.Ctx) error {
+	log.Println("GetInboundShipments called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var shipments []models.InboundShipment
+
+	// Build base query
+	query := ibc.DB.Model(&models.InboundShipment{}).Preload("Details").Preload("CreateUser").Preload("CompleteUser").Preload("CancelUser").Order("created_at DESC")
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("code ILIKE ? OR supplier ILIKE ? OR reference_number ILIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&shipments).Error; err != nil {
+		log.Println("GetInboundShipments - Failed to retrieve inbound shipments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve inbound shipments",
+		})
+	}
+
+	// Format response without details, only the summary
+	shipmentList := make([]models.InboundShipmentResponse, len(shipments))
+	for i, shipment := range shipments {
+		shipmentList[i] = *shipment.ToResponse()
+		shipmentList[i].Details = nil
+	}
+
+	// Build success message
+	message := "Inbound shipments retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetInboundShipments completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    shipmentList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetInboundShipment retrieves a single inbound shipment by ID
+// @Summary Get Inbound Shipment
+// @Description Retrieve an inbound shipment with its lines and discrepancies
+// @Tags Inbounds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Inbound Shipment ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.InboundShipmentResponse}
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/inbounds/{id} [get]
+func (ibc *InboundController) GetInboundShipment(c fiber.Ctx) error {
+	log.Println("GetInboundShipment called")
+	// Parse id parameter
+	id := c.Params("id")
+	shipment, err := ibc.loadInboundShipment(id)
+	if err != nil {
+		log.Println("GetInboundShipment - Inbound shipment not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Inbound shipment with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetInboundShipment completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Inbound shipment retrieved successfully",
+		Data:    shipment.ToResponse(),
+	})
+}
+
+// CreateInboundShipment registers a supplier delivery with its expected lines
+// @Summary Create Inbound Shipment
+// @Description Register a supplier delivery against a PO or manifest with the expected quantity per SKU
+// @Tags Inbounds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param inbound body CreateInboundShipmentRequest true "Inbound shipment data"
+// @Success 201 {object} utils.SuccessResponse{data=models.InboundShipmentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/inbounds [post]
+func (ibc *InboundController) CreateInboundShipment(c fiber.Ctx) error {
+	log.Println("CreateInboundShipment called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CreateInboundShipment - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req CreateInboundShipmentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateInboundShipment - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Supplier = strings.TrimSpace(req.Supplier)
+	if req.Supplier == "" || len(req.Details) == 0 {
+		log.Println("CreateInboundShipment - Missing required fields")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Supplier and at least one detail line are required",
+		})
+	}
+
+	expectedAt, err := parseOptionalDate(req.ExpectedAt)
+	if err != nil {
+		log.Println("CreateInboundShipment - Invalid expected date:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid expected date format. Use YYYY-MM-DD",
+		})
+	}
+
+	// Validate detail lines against products
+	seen := make(map[string]bool)
+	details := make([]models.InboundDetail, 0, len(req.Details))
+	for _, line := range req.Details {
+		sku := strings.TrimSpace(line.SKU)
+		if sku == "" || line.ExpectedQuantity <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Each detail line requires a SKU and an expected quantity greater than 0",
+			})
+		}
+		if seen[sku] {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Duplicate SKU " + sku + " in detail lines",
+			})
+		}
+		seen[sku] = true
+
+		expiryDate, err := parseOptionalDate(line.ExpiryDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid expiry date format for SKU " + sku + ". Use YYYY-MM-DD",
+			})
+		}
+
+		var product models.Product
+		if err := ibc.DB.Where("sku = ?", sku).First(&product).Error; err != nil {
+			log.Println("CreateInboundShipment - Product not found:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Product with SKU " + sku + " not found",
+			})
+		}
+
+		details = append(details, models.InboundDetail{
+			SKU:              sku,
+			ProductName:      product.Name,
+			LotNumber:        strings.TrimSpace(line.LotNumber),
+			ExpiryDate:       expiryDate,
+			ExpectedQuantity: line.ExpectedQuantity,
+		})
+	}
+
+	shipment := models.InboundShipment{
+		Supplier:        req.Supplier,
+		ReferenceNumber: strings.TrimSpace(req.ReferenceNumber),
+		ExpectedAt:      expectedAt,
+		Status:          "registered",
+		Notes:           strings.TrimSpace(req.Notes),
+		CreatedBy:       uint(userID),
+		Details:         details,
+	}
+
+	if err := ibc.DB.Transaction(func(tx *gorm.DB) error {
+		shipment.Code = utils.GenerateInboundCode(tx)
+		return tx.Create(&shipment).Error
+	}); err != nil {
+		log.Println("CreateInboundShipment - Failed to create inbound shipment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create inbound shipment",
+		})
+	}
+
+	created, err := ibc.loadInboundShipment(shipment.ID)
+	if err != nil {
+		log.Println("CreateInboundShipment - Failed to reload inbound shipment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload inbound shipment",
+		})
+	}
+
+	log.Println("CreateInboundShipment completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Inbound shipment %s registered with %d lines", created.Code, len(created.Details)),
+		Data:    created.ToResponse(),
+	})
+}
+
+// ReceiveInboundShipment records received quantities per line of an inbound shipment
+// @Summary Receive Inbound Shipment
+// @Description Record the received and damaged quantity per SKU with lot and discrepancy notes. Lines can be received in several calls; SKUs not on the PO/manifest are added as unexpected lines.
+// @Tags Inbounds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Inbound Shipment ID"
+// @Param receipt body ReceiveInboundShipmentRequest true "Received lines"
+// @Success 200 {object} utils.SuccessResponse{data=models.InboundShipmentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/inbounds/{id}/receive [put]
+func (ibc *InboundController) ReceiveInboundShipment(c fiber.Ctx) error {
+	log.Println("ReceiveInboundShipment called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ReceiveInboundShipment - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req ReceiveInboundShipmentRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ReceiveInboundShipment - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if len(req.Details) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one received line is required",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var shipment models.InboundShipment
+	if err := ibc.DB.Where("id = ?", id).First(&shipment).Error; err != nil {
+		log.Println("ReceiveInboundShipment - Inbound shipment not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Inbound shipment with id " + id + " not found.",
+		})
+	}
+
+	if shipment.Status != "registered" && shipment.Status != "receiving" {
+		log.Println("ReceiveInboundShipment - Inbound shipment is not open")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Inbound shipment is already " + shipment.Status,
+		})
+	}
+
+	receivedBy := uint(userID)
+	if err := ibc.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, line := range req.Details {
+			sku := strings.TrimSpace(line.SKU)
+			if sku == "" || line.ReceivedQuantity < 0 || line.DamagedQuantity < 0 {
+				return fmt.Errorf("each line requires a SKU and non-negative quantities")
+			}
+			if line.DamagedQuantity > line.ReceivedQuantity {
+				return fmt.Errorf("damaged quantity of SKU %s cannot exceed the received quantity", sku)
+			}
+			expiryDate, err := parseOptionalDate(line.ExpiryDate)
+			if err != nil {
+				return fmt.Errorf("invalid expiry date format for SKU %s, use YYYY-MM-DD", sku)
+			}
+
+			var detail models.InboundDetail
+			if err := tx.Where("inbound_shipment_id = ? AND sku = ?", shipment.ID, sku).First(&detail).Error; err != nil {
+				if err != gorm.ErrRecordNotFound {
+					return err
+				}
+
+				// Delivered but not on the PO/manifest
+				var product models.Product
+				if err := tx.Where("sku = ?", sku).First(&product).Error; err != nil {
+					return fmt.Errorf("product with SKU %s not found", sku)
+				}
+				detail = models.InboundDetail{
+					InboundShipmentID: shipment.ID,
+					SKU:               sku,
+					ProductName:       product.Name,
+					Unexpected:        true,
+				}
+				if err := tx.Create(&detail).Error; err != nil {
+					return err
+				}
+			}
+
+			updates := map[string]interface{}{
+				"received_quantity": line.ReceivedQuantity,
+				"damaged_quantity":  line.DamagedQuantity,
+				"discrepancy_note":  strings.TrimSpace(line.DiscrepancyNote),
+				"received_by":       receivedBy,
+				"received_at":       now,
+			}
+			if lotNumber := strings.TrimSpace(line.LotNumber); lotNumber != "" {
+				updates["lot_number"] = lotNumber
+			}
+			if expiryDate != nil {
+				updates["expiry_date"] = expiryDate
+			}
+			if err := tx.Model(&detail).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&shipment).Update("status", "receiving").Error
+	}); err != nil {
+		log.Println("ReceiveInboundShipment - Failed to record received lines:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to record received lines: " + err.Error(),
+		})
+	}
+
+	updated, err := ibc.loadInboundShipment(shipment.ID)
+	if err != nil {
+		log.Println("ReceiveInboundShipment - Failed to reload inbound shipment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload inbound shipment",
+		})
+	}
+
+	log.Println("ReceiveInboundShipment completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Received %d lines for inbound shipment %s", len(req.Details), updated.Code),
+		Data:    updated.ToResponse(),
+	})
+}
+
+// CompleteInboundShipment completes an inbound shipment and adds the accepted quantities to inventory
+// @Summary Complete Inbound Shipment
+// @Description Complete an inbound shipment. The received quantity minus damaged of every line is added to inventory under its lot (the shipment code when no lot is given). Lines that were never received are recorded as received 0. Orders awaiting stock of a received SKU are fulfilled first, oldest first.
+// @Tags Inbounds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Inbound Shipment ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.InboundShipmentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/inbounds/{id}/complete [put]
+func (ibc *InboundController) CompleteInboundShipment(c fiber.Ctx) error {
+	log.Println("CompleteInboundShipment called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CompleteInboundShipment - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	shipment, err := ibc.loadInboundShipment(id)
+	if err != nil {
+		log.Println("CompleteInboundShipment - Inbound shipment not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Inbound shipment with id " + id + " not found.",
+		})
+	}
+
+	if shipment.Status != "registered" && shipment.Status != "receiving" {
+		log.Println("CompleteInboundShipment - Inbound shipment is not open")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Inbound shipment is already " + shipment.Status,
+		})
+	}
+
+	completedBy := uint(userID)
+	received := 0
+	discrepancies := 0
+	backorders := 0
+	if err := ibc.DB.Transaction(func(tx *gorm.DB) error {
+		// Guard against concurrent completion
+		result := tx.Model(&models.InboundShipment{}).Where("id = ? AND status IN ?", shipment.ID, []string{"registered", "receiving"}).Updates(map[string]interface{}{
+			"status":       "completed",
+			"completed_by": completedBy,
+			"completed_at": time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("inbound shipment is no longer open")
+		}
+
+		for i := range shipment.Details {
+			detail := &shipment.Details[i]
+
+			// Lines never received count as nothing delivered
+			if detail.ReceivedQuantity == nil {
+				zero := 0
+				detail.ReceivedQuantity = &zero
+				if err := tx.Model(detail).Update("received_quantity", 0).Error; err != nil {
+					return err
+				}
+			}
+
+			if detail.Discrepancy() != 0 || detail.DamagedQuantity > 0 {
+				discrepancies++
+			}
+
+			accepted := detail.AcceptedQuantity()
+			if accepted == 0 {
+				continue
+			}
+
+			lotNumber := detail.LotNumber
+			if lotNumber == "" {
+				lotNumber = shipment.Code
+			}
+			if _, err := utils.ReceiveInventoryBatch(tx, utils.BatchReceipt{
+				SKU:           detail.SKU,
+				Type:          utils.InventoryMovementInbound,
+				LotNumber:     lotNumber,
+				ExpiryDate:    detail.ExpiryDate,
+				Quantity:      accepted,
+				ReferenceType: "inbound_shipment",
+				ReferenceID:   shipment.ID,
+				Note:          fmt.Sprintf("Inbound %s from %s", shipment.Code, shipment.Supplier),
+				ReceivedBy:    &completedBy,
+			}); err != nil {
+				return err
+			}
+			received += accepted
+
+			// Orders waiting for the SKU get the stock first
+			fulfillments, err := utils.FulfillBackorders(tx, utils.BackorderRestock{
+				SKU:           detail.SKU,
+				Quantity:      accepted,
+				ReferenceType: "inbound_shipment",
+				ReferenceID:   shipment.ID,
+				ReceivedBy:    &completedBy,
+			})
+			if err != nil {
+				return err
+			}
+			backorders += len(fulfillments)
+		}
+
+		if discrepancies > 0 {
+			return utils.NotifyRoles(tx, utils.CoordinatorApprovalRoles, "inbound_discrepancy",
+				"Inbound discrepancy on "+shipment.Code,
+				fmt.Sprintf("Inbound %s from %s was completed with %d discrepant lines", shipment.Code, shipment.Supplier, discrepancies),
+				"inbound_shipment", shipment.ID)
+		}
+		return nil
+	}); err != nil {
+		log.Println("CompleteInboundShipment - Failed to complete inbound shipment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to complete inbound shipment: " + err.Error(),
+		})
+	}
+
+	completed, err := ibc.loadInboundShipment(shipment.ID)
+	if err != nil {
+		log.Println("CompleteInboundShipment - Failed to reload inbound shipment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload inbound shipment",
+		})
+	}
+
+	log.Println("CompleteInboundShipment completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Inbound shipment %s completed, %d units added to inventory, %d discrepant lines, %d backorders fulfilled", completed.Code, received, discrepancies, backorders),
+		Data:    completed.ToResponse(),
+	})
+}
+
+// CancelInboundShipment cancels an inbound shipment without touching inventory
+// @Summary Cancel Inbound Shipment
+// @Description Cancel an inbound shipment that has not been completed
+// @Tags Inbounds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Inbound Shipment ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.InboundShipmentResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/inbounds/{id}/cancel [put]
+func (ibc *InboundController) CancelInboundShipment(c fiber.Ctx) error {
+	log.Println("CancelInboundShipment called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CancelInboundShipment - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	result := ibc.DB.Model(&models.InboundShipment{}).Where("id = ? AND status IN ?", id, []string{"registered", "receiving"}).Updates(map[string]interface{}{
+		"status":      "canceled",
+		"canceled_by": uint(userID),
+		"canceled_at": time.Now(),
+	})
+	if result.Error != nil {
+		log.Println("CancelInboundShipment - Failed to cancel inbound shipment:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to cancel inbound shipment",
+		})
+	}
+	if result.RowsAffected == 0 {
+		log.Println("CancelInboundShipment - Inbound shipment not found or not open")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Inbound shipment with id " + id + " not found or already completed.",
+		})
+	}
+
+	canceled, err := ibc.loadInboundShipment(id)
+	if err != nil {
+		log.Println("CancelInboundShipment - Failed to reload inbound shipment:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload inbound shipment",
+		})
+	}
+
+	log.Println("CancelInboundShipment completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Inbound shipment canceled successfully",
+		Data:    canceled.ToResponse(),
+	})
+}
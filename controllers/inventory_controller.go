@@ -0,0 +1,330 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type InventoryController struct {
+	DB *gorm.DB
+}
+
+func NewInventoryController(db *gorm.DB) *InventoryController {
+	return &InventoryController{DB: db}
+}
+
+// Request structs
+type ReceiveInventoryRequest struct {
+	SKU        string `json:"sku" validate:"required"`
+	LotNumber  string `json:"lotNumber" validate:"required"`
+	ExpiryDate string `json:"expiryDate"` // YYYY-MM-DD, optional for SKUs without expiry
+	Quantity   int    `json:"quantity" validate:"required"`
+	Note       string `json:"note"`
+}
+
+// GetInventories retrieves system stock per SKU with pagination and search
+// @Summary Get Inventories
+// @Description Retrieve system stock per SKU with pagination and search
+// @Tags Inventories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of inventories per page" default(10)
+// @Param search query string false "Search term for SKU"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.InventoryResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/inventories [get]
+func (ic *InventoryController) GetInventories(c fiber.Ctx) error {
+	log.Println("GetInventories called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var inventories []models.Inventory
+
+	// Build base query
+	query := ic.DB.Model(&models.Inventory{}).Order("sku ASC")
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("sku ILIKE ?", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&inventories).Error; err != nil {
+		log.Println("GetInventories - Failed to retrieve inventories:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve inventories",
+		})
+	}
+
+	// Manually load product data for each inventory
+	skus := make([]string, len(inventories))
+	for i, inventory := range inventories {
+		skus[i] = inventory.SKU
+	}
+	var products []models.Product
+	if len(skus) > 0 {
+		ic.DB.Where("sku IN ?", skus).Find(&products)
+	}
+	productMap := make(map[string]*models.Product, len(products))
+	for i := range products {
+		productMap[products[i].SKU] = &products[i]
+	}
+
+	inventoryList := make([]models.InventoryResponse, len(inventories))
+	for i := range inventories {
+		inventories[i].Product = productMap[inventories[i].SKU]
+		inventoryList[i] = *inventories[i].ToResponse()
+	}
+
+	// Build success message
+	message := "Inventories retrieved successfully"
+	if search != "" {
+		message += fmt.Sprintf(" (filtered by search: %s)", search)
+	}
+
+	log.Println("GetInventories completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    inventoryList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetInventoryMovements retrieves the stock movement ledger of a SKU
+// @Summary Get Inventory Movements
+// @Description Retrieve the audited stock movement ledger of a SKU with pagination
+// @Tags Inventories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sku path string true "Product SKU"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of movements per page" default(10)
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.InventoryMovementResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/inventories/{sku}/movements [get]
+func (ic *InventoryController) GetInventoryMovements(c fiber.Ctx) error {
+	log.Println("GetInventoryMovements called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	sku := c.Params("sku")
+
+	var movements []models.InventoryMovement
+	query := ic.DB.Model(&models.InventoryMovement{}).Where("sku = ?", sku)
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Preload("CreateUser").Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&movements).Error; err != nil {
+		log.Println("GetInventoryMovements - Failed to retrieve movements:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve inventory movements",
+		})
+	}
+
+	movementList := make([]models.InventoryMovementResponse, len(movements))
+	for i, movement := range movements {
+		movementList[i] = *movement.ToResponse()
+	}
+
+	log.Println("GetInventoryMovements completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: "Inventory movements for SKU " + sku + " retrieved successfully",
+		Data:    movementList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetInventoryBatches retrieves the lots of a SKU that still have stock, first expiry first
+// @Summary Get Inventory Batches
+// @Description Retrieve the lots of a SKU with remaining stock, ordered first expiry first out
+// @Tags Inventories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sku path string true "Product SKU"
+// @Param all query bool false "Include lots without remaining stock" default(false)
+// @Success 200 {object} utils.SuccessResponse{data=[]models.InventoryBatchResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/inventories/{sku}/batches [get]
+func (ic *InventoryController) GetInventoryBatches(c fiber.Ctx) error {
+	log.Println("GetInventoryBatches called")
+	sku := c.Params("sku")
+
+	query := ic.DB.Model(&models.InventoryBatch{}).Preload("ReceiveUser").Where("sku = ?", sku)
+	if c.Query("all", "false") != "true" {
+		query = query.Where("quantity > 0")
+	}
+
+	var batches []models.InventoryBatch
+	if err := query.Order("expiry_date ASC NULLS LAST, received_at ASC").Find(&batches).Error; err != nil {
+		log.Println("GetInventoryBatches - Failed to retrieve batches:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve inventory batches",
+		})
+	}
+
+	batchList := make([]models.InventoryBatchResponse, len(batches))
+	for i, batch := range batches {
+		batchList[i] = *batch.ToResponse()
+	}
+
+	log.Println("GetInventoryBatches completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Inventory batches for SKU " + sku + " retrieved successfully",
+		Data:    batchList,
+	})
+}
+
+// ReceiveInventory records stock received into a lot
+// @Summary Receive Inventory
+// @Description Record received stock with its lot number and expiry date. Receiving an existing lot adds to it. Orders awaiting stock of the SKU are fulfilled first, oldest first.
+// @Tags Inventories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param receipt body ReceiveInventoryRequest true "Received lot"
+// @Success 201 {object} utils.SuccessResponse{data=models.InventoryBatchResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/inventories/receipts [post]
+func (ic *InventoryController) ReceiveInventory(c fiber.Ctx) error {
+	log.Println("ReceiveInventory called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ReceiveInventory - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req ReceiveInventoryRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ReceiveInventory - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	req.LotNumber = strings.TrimSpace(req.LotNumber)
+	if req.SKU == "" || req.LotNumber == "" || req.Quantity <= 0 {
+		log.Println("ReceiveInventory - Missing required fields")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU, lot number and a quantity greater than 0 are required",
+		})
+	}
+
+	var expiryDate *time.Time
+	if req.ExpiryDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.ExpiryDate)
+		if err != nil {
+			log.Println("ReceiveInventory - Invalid expiry date:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid expiry date format. Use YYYY-MM-DD",
+			})
+		}
+		expiryDate = &parsed
+	}
+
+	var product models.Product
+	if err := ic.DB.Where("sku = ?", req.SKU).First(&product).Error; err != nil {
+		log.Println("ReceiveInventory - Product not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product with SKU " + req.SKU + " not found",
+		})
+	}
+
+	receivedBy := uint(userID)
+	var batch *models.InventoryBatch
+	var fulfillments []utils.BackorderFulfillment
+	if err := ic.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		batch, err = utils.ReceiveInventoryBatch(tx, utils.BatchReceipt{
+			SKU:        req.SKU,
+			LotNumber:  req.LotNumber,
+			ExpiryDate: expiryDate,
+			Quantity:   req.Quantity,
+			Note:       strings.TrimSpace(req.Note),
+			ReceivedBy: &receivedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Orders waiting for the SKU get the stock first
+		fulfillments, err = utils.FulfillBackorders(tx, utils.BackorderRestock{
+			SKU:           req.SKU,
+			Quantity:      req.Quantity,
+			ReferenceType: "inventory_batch",
+			ReferenceID:   batch.ID,
+			ReceivedBy:    &receivedBy,
+		})
+		return err
+	}); err != nil {
+		log.Println("ReceiveInventory - Failed to receive inventory:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to receive inventory: " + err.Error(),
+		})
+	}
+
+	ic.DB.Preload("ReceiveUser").First(batch, batch.ID)
+	batch.Product = &product
+
+	message := fmt.Sprintf("Received %d of SKU %s into lot %s", req.Quantity, req.SKU, req.LotNumber)
+	if len(fulfillments) > 0 {
+		message += fmt.Sprintf(", %d backorders fulfilled", len(fulfillments))
+	}
+
+	log.Println("ReceiveInventory completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    batch.ToResponse(),
+	})
+}
@@ -0,0 +1,604 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type InvitationController struct {
+	DB     *gorm.DB
+	Config *config.Config
+}
+
+func NewInvitationController(cfg *config.Config, db *gorm.DB) *InvitationController {
+	return &InvitationController{Config: cfg, DB: db}
+}
+
+// Request structs
+type InviteUserRequest struct {
+	Email    string `json:"email" validate:"required,email" example:"john@example.com"`
+	FullName string `json:"fullName" validate:"required,min=3,max=100" example:"John Doe"`
+	RoleName string `json:"roleName" validate:"required" example:"picker"`
+}
+
+// sendInvitationEmail emails the invitation link containing the plain token
+func (ic *InvitationController) sendInvitationEmail(invitation *models.UserInvitation, token string) error {
+	link := fmt.Sprintf("%s/invitations/accept?token=%s", strings.TrimRight(ic.Config.FrontendUrl, "/"), token)
+	body := fmt.Sprintf("Hello %s,\n\nYou have been invited to join %s. Open the link below to choose your username and password and enroll your face for attendance:\n\n%s\n\nThis link expires on %s.\n",
+		invitation.FullName, ic.Config.AppName, link, invitation.ExpiresAt.Format("02-01-2006 15:04"))
+	return utils.SendEmail(ic.Config, invitation.Email, "Your "+ic.Config.AppName+" invitation", body)
+}
+
+// findPendingInvitation looks up a pending, unexpired invitation by its plain token
+func (ic *InvitationController) findPendingInvitation(token string) (*models.UserInvitation, error) {
+	var invitation models.UserInvitation
+	if err := ic.DB.Where("token_hash = ?", utils.HashSecureToken(token)).First(&invitation).Error; err != nil {
+		return nil, errors.New("invitation not found")
+	}
+	if status := invitation.EffectiveStatus(time.Now()); status != "pending" {
+		return nil, errors.New("invitation is " + status)
+	}
+	return &invitation, nil
+}
+
+// InviteUser invites a new user by email
+// @Summary Invite User
+// @Description Send an onboarding invitation by email. The invitee sets their own username, password and face enrollment through the emailed link.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body InviteUserRequest true "Invitation details"
+// @Success 201 {object} utils.SuccessResponse{data=models.UserInvitationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 502 {object} utils.ErrorResponse
+// @Router /api/users/invite [post]
+func (ic *InvitationController) InviteUser(c fiber.Ctx) error {
+	log.Println("InviteUser called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("InviteUser - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req InviteUserRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("InviteUser - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Email = utils.NormalizeEmail(req.Email)
+	req.FullName = strings.TrimSpace(req.FullName)
+	if req.Email == "" || !strings.Contains(req.Email, "@") || req.FullName == "" || req.RoleName == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Email, full name and role name are required",
+		})
+	}
+
+	// Check for existing user or open invitation with the same email
+	var existingUser models.User
+	if err := ic.DB.Where("email = ?", req.Email).First(&existingUser).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "A user with this email already exists",
+		})
+	}
+
+	var openInvitation models.UserInvitation
+	if err := ic.DB.Where("email = ? AND status = ? AND expires_at > ?", req.Email, "pending", time.Now()).First(&openInvitation).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "A pending invitation already exists for this email, resend it instead",
+		})
+	}
+
+	var role models.Role
+	if err := ic.DB.Where("role_name = ?", req.RoleName).First(&role).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid role name",
+		})
+	}
+
+	// Check permission hierarchy - current user must have higher or equal privilege
+	currUserRoles := c.Locals("userRoles").([]string)
+	currUserMinHierarchy := 999
+	for _, currUserRoleName := range currUserRoles {
+		var currRole models.Role
+		if err := ic.DB.Where("role_name = ?", currUserRoleName).First(&currRole).Error; err == nil {
+			if currRole.Hierarchy < currUserMinHierarchy {
+				currUserMinHierarchy = currRole.Hierarchy
+			}
+		}
+	}
+
+	// Current user must have equal or higher privilege (lower or equal hierarchy number)
+	if role.Hierarchy < currUserMinHierarchy {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Insufficient permissions",
+		})
+	}
+
+	token, tokenHash, err := utils.GenerateSecureToken()
+	if err != nil {
+		log.Println("InviteUser - Failed to generate token:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate invitation token",
+		})
+	}
+
+	invitation := models.UserInvitation{
+		TokenHash: tokenHash,
+		Email:     req.Email,
+		FullName:  req.FullName,
+		RoleName:  role.RoleName,
+		Status:    "pending",
+		ExpiresAt: time.Now().Add(time.Duration(ic.Config.InvitationTTLHours) * time.Hour),
+		InvitedBy: uint(userID),
+	}
+
+	// The invitation is only kept when the email could be sent
+	if err := ic.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+		return ic.sendInvitationEmail(&invitation, token)
+	}); err != nil {
+		log.Println("InviteUser - Failed to send invitation:", err)
+		return c.Status(fiber.StatusBadGateway).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to send invitation: " + err.Error(),
+		})
+	}
+
+	ic.DB.Preload("InviteUser").First(&invitation, invitation.ID)
+
+	log.Println("InviteUser completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Invitation sent to " + invitation.Email,
+		Data:    invitation.ToResponse(),
+	})
+}
+
+// GetInvitations retrieves a paginated list of user invitations
+// @Summary Get Invitations
+// @Description Retrieve user invitations with pagination, status filter and search
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of invitations per page" default(10)
+// @Param status query string false "Filter by status (pending, expired, accepted, revoked)"
+// @Param search query string false "Search term for email or full name"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.UserInvitationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/users/invitations [get]
+func (ic *InvitationController) GetInvitations(c fiber.Ctx) error {
+	log.Println("GetInvitations called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var invitations []models.UserInvitation
+
+	// Build base query
+	query := ic.DB.Model(&models.UserInvitation{}).Preload("InviteUser").Preload("AcceptedUser").Preload("RevokeUser").Order("created_at DESC")
+
+	// Status filter if provided, expired is a pending invitation past its expiry
+	now := time.Now()
+	status := strings.TrimSpace(c.Query("status", ""))
+	switch status {
+	case "":
+	case "pending":
+		query = query.Where("status = ? AND expires_at > ?", "pending", now)
+	case "expired":
+		query = query.Where("status = ? AND expires_at <= ?", "pending", now)
+	case "accepted", "revoked":
+		query = query.Where("status = ?", status)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid status. Use pending, expired, accepted or revoked.",
+		})
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("email ILIKE ? OR full_name ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&invitations).Error; err != nil {
+		log.Println("GetInvitations - Failed to retrieve invitations:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve invitations",
+		})
+	}
+
+	invitationList := make([]models.UserInvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		invitationList[i] = *invitation.ToResponse()
+	}
+
+	// Build success message
+	message := "Invitations retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetInvitations completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    invitationList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// ResendInvitation issues a new link for a pending or expired invitation
+// @Summary Resend Invitation
+// @Description Issue a new invitation link and expiry for a pending or expired invitation. The previous link stops working.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.UserInvitationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 502 {object} utils.ErrorResponse
+// @Router /api/users/invitations/{id}/resend [post]
+func (ic *InvitationController) ResendInvitation(c fiber.Ctx) error {
+	log.Println("ResendInvitation called")
+	// Parse id parameter
+	id := c.Params("id")
+	var invitation models.UserInvitation
+	if err := ic.DB.Where("id = ?", id).First(&invitation).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invitation with id " + id + " not found.",
+		})
+	}
+
+	if invitation.Status != "pending" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invitation is already " + invitation.Status,
+		})
+	}
+
+	token, tokenHash, err := utils.GenerateSecureToken()
+	if err != nil {
+		log.Println("ResendInvitation - Failed to generate token:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate invitation token",
+		})
+	}
+
+	if err := ic.DB.Transaction(func(tx *gorm.DB) error {
+		invitation.TokenHash = tokenHash
+		invitation.ExpiresAt = time.Now().Add(time.Duration(ic.Config.InvitationTTLHours) * time.Hour)
+		if err := tx.Model(&invitation).Updates(map[string]interface{}{
+			"token_hash": invitation.TokenHash,
+			"expires_at": invitation.ExpiresAt,
+		}).Error; err != nil {
+			return err
+		}
+		return ic.sendInvitationEmail(&invitation, token)
+	}); err != nil {
+		log.Println("ResendInvitation - Failed to resend invitation:", err)
+		return c.Status(fiber.StatusBadGateway).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to resend invitation: " + err.Error(),
+		})
+	}
+
+	ic.DB.Preload("InviteUser").First(&invitation, invitation.ID)
+
+	log.Println("ResendInvitation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Invitation resent to " + invitation.Email,
+		Data:    invitation.ToResponse(),
+	})
+}
+
+// RevokeInvitation revokes a pending invitation
+// @Summary Revoke Invitation
+// @Description Revoke a pending invitation so its link can no longer be used
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.UserInvitationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/users/invitations/{id} [delete]
+func (ic *InvitationController) RevokeInvitation(c fiber.Ctx) error {
+	log.Println("RevokeInvitation called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("RevokeInvitation - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	result := ic.DB.Model(&models.UserInvitation{}).Where("id = ? AND status = ?", id, "pending").Updates(map[string]interface{}{
+		"status":     "revoked",
+		"revoked_by": uint(userID),
+		"revoked_at": time.Now(),
+	})
+	if result.Error != nil {
+		log.Println("RevokeInvitation - Failed to revoke invitation:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to revoke invitation",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invitation with id " + id + " not found or no longer pending.",
+		})
+	}
+
+	var invitation models.UserInvitation
+	ic.DB.Preload("InviteUser").Preload("RevokeUser").Where("id = ?", id).First(&invitation)
+
+	log.Println("RevokeInvitation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Invitation revoked successfully",
+		Data:    invitation.ToResponse(),
+	})
+}
+
+// GetInvitationByToken shows the invitation behind an emailed link
+// @Summary Get Invitation By Token
+// @Description Check an invitation link and return the invited email, name and role
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param token path string true "Invitation token"
+// @Success 200 {object} utils.SuccessResponse{data=models.UserInvitationResponse}
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/invitations/{token} [get]
+func (ic *InvitationController) GetInvitationByToken(c fiber.Ctx) error {
+	log.Println("GetInvitationByToken called")
+	invitation, err := ic.findPendingInvitation(c.Params("token"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid invitation link: " + err.Error(),
+		})
+	}
+
+	ic.DB.Preload("InviteUser").First(invitation, invitation.ID)
+
+	log.Println("GetInvitationByToken completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Invitation is valid",
+		Data:    invitation.ToResponse(),
+	})
+}
+
+// AcceptInvitation creates the invited user with their own credentials and face enrollment
+// @Summary Accept Invitation
+// @Description Accept an invitation by choosing a username and password and uploading a face image for attendance enrollment
+// @Tags Authentication
+// @Accept multipart/form-data
+// @Produce json
+// @Param token path string true "Invitation token"
+// @Param username formData string true "Username (3-50 characters)"
+// @Param password formData string true "Password (min 8 characters)"
+// @Param image formData file true "Face image to enroll"
+// @Success 201 {object} utils.SuccessResponse{data=models.UserResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/invitations/{token}/accept [post]
+func (ic *InvitationController) AcceptInvitation(c fiber.Ctx) error {
+	log.Println("AcceptInvitation called")
+	invitation, err := ic.findPendingInvitation(c.Params("token"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid invitation link: " + err.Error(),
+		})
+	}
+
+	username := utils.NormalizeUsername(c.FormValue("username"))
+	password := c.FormValue("password")
+	if len(username) < 3 || len(username) > 50 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Username must be between 3 and 50 characters",
+		})
+	}
+	if len(password) < 8 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Password must be at least 8 characters",
+		})
+	}
+
+	// Check for existing username or email
+	var existingUser models.User
+	if err := ic.DB.Where("username = ?", username).Or("email = ?", invitation.Email).First(&existingUser).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Username or email already exists",
+		})
+	}
+
+	// Get uploaded face image
+	file, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Face image file is required",
+		})
+	}
+
+	tmpPath, err := utils.CreateTempFile("invite_*.jpg")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save image file",
+		})
+	}
+	defer os.Remove(tmpPath)
+
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save image file",
+		})
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to hash password",
+		})
+	}
+
+	var newUser models.User
+	if err := ic.DB.Transaction(func(tx *gorm.DB) error {
+		// Claim the invitation first so the link cannot be used twice
+		now := time.Now()
+		result := tx.Model(&models.UserInvitation{}).Where("id = ? AND status = ?", invitation.ID, "pending").Updates(map[string]interface{}{
+			"status":      "accepted",
+			"accepted_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("invitation has already been used")
+		}
+
+		newUser = models.User{
+			Username: username,
+			Password: hashedPassword,
+			FullName: invitation.FullName,
+			Email:    invitation.Email,
+			IsActive: true,
+		}
+		if err := tx.Create(&newUser).Error; err != nil {
+			return err
+		}
+
+		if invitation.RoleName != "" {
+			var role models.Role
+			if err := tx.Where("role_name = ?", invitation.RoleName).First(&role).Error; err != nil {
+				return errors.New("invited role no longer exists")
+			}
+			if err := tx.Create(&models.UserRole{UserID: newUser.ID, RoleID: role.ID}).Error; err != nil {
+				return err
+			}
+			if err := utils.RecordRoleChange(tx, role, models.RoleAuditActionGrant, utils.RoleChangeSourceInvitation, &invitation.InvitedBy, newUser.ID); err != nil {
+				return err
+			}
+		}
+
+		// Enroll face with the deepface service
+		if err := utils.SendToDeepFaceRegister(c.Context(), newUser.ID, tmpPath); err != nil {
+			return fmt.Errorf("failed to register face with deepface service: %w", err)
+		}
+		if err := tx.Create(&models.UserFace{UserID: newUser.ID, IsActive: true}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.UserInvitation{}).Where("id = ?", invitation.ID).Update("accepted_user_id", newUser.ID).Error
+	}); err != nil {
+		log.Println("AcceptInvitation - Failed to accept invitation:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to accept invitation: " + err.Error(),
+		})
+	}
+
+	// Reload the data
+	if err := ic.DB.Preload("Roles").Where("id = ?", newUser.ID).First(&newUser).Error; err != nil {
+		log.Println("AcceptInvitation - Failed to load user:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load user",
+		})
+	}
+
+	log.Println("AcceptInvitation completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Invitation accepted, you can now log in",
+		Data:    newUser.ToResponse(),
+	})
+}
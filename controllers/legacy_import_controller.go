@@ -0,0 +1,636 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type LegacyImportController struct {
+	Config *config.Config
+	DB     *gorm.DB
+}
+
+func NewLegacyImportController(cfg *config.Config, db *gorm.DB) *LegacyImportController {
+	return &LegacyImportController{Config: cfg, DB: db}
+}
+
+// Request structs
+type LegacyImportTemplateRequest struct {
+	Name       string            `json:"name" validate:"required,min=3,max=100" example:"Orders sheet 2024"`
+	Entity     string            `json:"entity" validate:"required,oneof=orders complains attendances" example:"orders"`
+	Mapping    map[string]string `json:"mapping"`                         // import field -> spreadsheet header, unmapped fields use their own name
+	DateLayout string            `json:"dateLayout" example:"02/01/2006"` // Go time layout of the date columns, empty tries the common layouts
+}
+
+// Unique response structs
+// LegacyImportFieldsResponse lists the import fields of a legacy entity
+type LegacyImportFieldsResponse struct {
+	Entity string                    `json:"entity"`
+	Fields []utils.LegacyImportField `json:"fields"`
+}
+
+// validateLegacyImportTemplate trims the template fields and checks them, returning the error message when invalid
+func validateLegacyImportTemplate(req *LegacyImportTemplateRequest) string {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Entity = strings.ToLower(strings.TrimSpace(req.Entity))
+	req.DateLayout = strings.TrimSpace(req.DateLayout)
+	if req.Mapping == nil {
+		req.Mapping = map[string]string{}
+	}
+	if len(req.Name) < 3 || len(req.Name) > 100 {
+		return "Template name must be between 3 and 100 characters"
+	}
+	if !models.IsValidLegacyEntity(req.Entity) {
+		return "Invalid entity. Use " + strings.Join(models.LegacyEntities(), ", ") + "."
+	}
+	if err := utils.ValidateLegacyImportMapping(req.Entity, req.Mapping); err != nil {
+		return "Invalid mapping: " + err.Error()
+	}
+	if len(req.DateLayout) > 50 {
+		return "Date layout must be at most 50 characters"
+	}
+	return ""
+}
+
+// GetLegacyImportFields retrieves the import fields of the legacy entities
+// @Summary Get Legacy Import Fields
+// @Description Retrieve the fields each legacy entity is imported with and which of them are required. Templates map these fields to the headers of the legacy spreadsheets, fields without a mapping are read from the column with their own name
+// @Tags Legacy Imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]LegacyImportFieldsResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/legacy-imports/fields [get]
+func (lc *LegacyImportController) GetLegacyImportFields(c fiber.Ctx) error {
+	log.Println("GetLegacyImportFields called")
+
+	entities := models.LegacyEntities()
+	fields := make([]LegacyImportFieldsResponse, len(entities))
+	for i, entity := range entities {
+		fields[i] = LegacyImportFieldsResponse{Entity: entity, Fields: utils.LegacyImportFields(entity)}
+	}
+
+	log.Println("GetLegacyImportFields completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Legacy import fields retrieved successfully",
+		Data:    fields,
+	})
+}
+
+// GetLegacyImportTemplates retrieves the legacy import templates
+// @Summary Get Legacy Import Templates
+// @Description Retrieve the column mapping templates of legacy spreadsheets, optionally of one entity
+// @Tags Legacy Imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param entity query string false "Filter by entity (orders, complains, attendances)"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.LegacyImportTemplateResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/legacy-imports/templates [get]
+func (lc *LegacyImportController) GetLegacyImportTemplates(c fiber.Ctx) error {
+	log.Println("GetLegacyImportTemplates called")
+
+	var templates []models.LegacyImportTemplate
+	query := lc.DB.Preload("UpdateUser").Order("entity ASC, name ASC")
+
+	entity := strings.ToLower(strings.TrimSpace(c.Query("entity", "")))
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+
+	if err := query.Find(&templates).Error; err != nil {
+		log.Println("GetLegacyImportTemplates - Failed to retrieve templates:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve legacy import templates",
+		})
+	}
+
+	// Format response
+	templateList := make([]models.LegacyImportTemplateResponse, len(templates))
+	for i := range templates {
+		templateList[i] = *templates[i].ToResponse()
+	}
+
+	// Build success message
+	message := "Legacy import templates retrieved successfully"
+	if entity != "" {
+		message += fmt.Sprintf(" (filtered by entity: %s)", entity)
+	}
+
+	log.Println("GetLegacyImportTemplates completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    templateList,
+	})
+}
+
+// CreateLegacyImportTemplate creates a legacy import template
+// @Summary Create Legacy Import Template
+// @Description Create a template mapping the headers of a legacy spreadsheet to the import fields of an entity, see GET /api/legacy-imports/fields
+// @Tags Legacy Imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body LegacyImportTemplateRequest true "Template data"
+// @Success 201 {object} utils.SuccessResponse{data=models.LegacyImportTemplateResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/legacy-imports/templates [post]
+func (lc *LegacyImportController) CreateLegacyImportTemplate(c fiber.Ctx) error {
+	log.Println("CreateLegacyImportTemplate called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req LegacyImportTemplateRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateLegacyImportTemplate - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if message := validateLegacyImportTemplate(&req); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Check for existing template with the same name
+	var existing models.LegacyImportTemplate
+	if err := lc.DB.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Legacy import template " + req.Name + " already exists",
+		})
+	}
+
+	mapping, _ := json.Marshal(req.Mapping)
+	updatedBy := uint(userID)
+	template := models.LegacyImportTemplate{
+		Name:       req.Name,
+		Entity:     req.Entity,
+		Mapping:    string(mapping),
+		DateLayout: req.DateLayout,
+		UpdatedBy:  &updatedBy,
+	}
+	if err := lc.DB.Create(&template).Error; err != nil {
+		log.Println("CreateLegacyImportTemplate - Failed to create template:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create legacy import template",
+		})
+	}
+	lc.DB.Preload("UpdateUser").First(&template, template.ID)
+
+	log.Println("CreateLegacyImportTemplate completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Legacy import template created successfully",
+		Data:    template.ToResponse(),
+	})
+}
+
+// UpdateLegacyImportTemplate updates a legacy import template
+// @Summary Update Legacy Import Template
+// @Description Replace the name, entity, column mapping and date layout of a legacy import template
+// @Tags Legacy Imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Param request body LegacyImportTemplateRequest true "Template data"
+// @Success 200 {object} utils.SuccessResponse{data=models.LegacyImportTemplateResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/legacy-imports/templates/{id} [put]
+func (lc *LegacyImportController) UpdateLegacyImportTemplate(c fiber.Ctx) error {
+	log.Println("UpdateLegacyImportTemplate called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	id := c.Params("id")
+	var template models.LegacyImportTemplate
+	if err := lc.DB.First(&template, id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Legacy import template not found",
+		})
+	}
+
+	// Binding request body
+	var req LegacyImportTemplateRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateLegacyImportTemplate - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if message := validateLegacyImportTemplate(&req); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Check for another template with the same name
+	var existing models.LegacyImportTemplate
+	if err := lc.DB.Where("name = ? AND id <> ?", req.Name, template.ID).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Legacy import template " + req.Name + " already exists",
+		})
+	}
+
+	mapping, _ := json.Marshal(req.Mapping)
+	updatedBy := uint(userID)
+	template.Name = req.Name
+	template.Entity = req.Entity
+	template.Mapping = string(mapping)
+	template.DateLayout = req.DateLayout
+	template.UpdatedBy = &updatedBy
+	if err := lc.DB.Save(&template).Error; err != nil {
+		log.Println("UpdateLegacyImportTemplate - Failed to update template:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update legacy import template",
+		})
+	}
+	lc.DB.Preload("UpdateUser").First(&template, template.ID)
+
+	log.Println("UpdateLegacyImportTemplate completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Legacy import template updated successfully",
+		Data:    template.ToResponse(),
+	})
+}
+
+// DeleteLegacyImportTemplate deletes a legacy import template
+// @Summary Delete Legacy Import Template
+// @Description Delete a legacy import template, the runs made with it are kept without their template
+// @Tags Legacy Imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Template ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/legacy-imports/templates/{id} [delete]
+func (lc *LegacyImportController) DeleteLegacyImportTemplate(c fiber.Ctx) error {
+	log.Println("DeleteLegacyImportTemplate called")
+	id := c.Params("id")
+	var template models.LegacyImportTemplate
+	if err := lc.DB.First(&template, id).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Legacy import template not found",
+		})
+	}
+
+	if err := lc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.LegacyImportRun{}).Where("template_id = ?", template.ID).Update("template_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&template).Error
+	}); err != nil {
+		log.Println("DeleteLegacyImportTemplate - Failed to delete template:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete legacy import template",
+		})
+	}
+
+	log.Println("DeleteLegacyImportTemplate completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Legacy import template " + template.Name + " deleted successfully",
+	})
+}
+
+// ValidateLegacyImport validates a legacy spreadsheet without importing it
+// @Summary Validate Legacy Import
+// @Description Dry run of a legacy import: maps the columns, checks every row against the references and the data already in the system and reports the rows that would be rejected. Nothing but the run and its report is saved
+// @Tags Legacy Imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX legacy spreadsheet"
+// @Param entity formData string true "Entity of the rows (orders, complains, attendances)"
+// @Param templateId formData int false "Template mapping the spreadsheet headers, the headers must be the field names without one"
+// @Success 200 {object} utils.SuccessResponse{data=models.LegacyImportRunResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/legacy-imports/validate [post]
+func (lc *LegacyImportController) ValidateLegacyImport(c fiber.Ctx) error {
+	log.Println("ValidateLegacyImport called")
+	return lc.runLegacyImport(c, "ValidateLegacyImport", true)
+}
+
+// RunLegacyImport imports a legacy spreadsheet
+// @Summary Run Legacy Import
+// @Description Import the orders, complains or attendances of a legacy spreadsheet dated before the go-live date. Valid records are created one by one and recorded in the import ledger, invalid rows are listed in the report. Running the same file again skips the records already imported, so a fixed file can be re-imported as a whole
+// @Tags Legacy Imports
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX legacy spreadsheet"
+// @Param entity formData string true "Entity of the rows (orders, complains, attendances)"
+// @Param templateId formData int false "Template mapping the spreadsheet headers, the headers must be the field names without one"
+// @Success 201 {object} utils.SuccessResponse{data=models.LegacyImportRunResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/legacy-imports [post]
+func (lc *LegacyImportController) RunLegacyImport(c fiber.Ctx) error {
+	log.Println("RunLegacyImport called")
+	return lc.runLegacyImport(c, "RunLegacyImport", false)
+}
+
+// runLegacyImport reads the uploaded spreadsheet and runs the import, or its dry run
+func (lc *LegacyImportController) runLegacyImport(c fiber.Ctx, handler string, dryRun bool) error {
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	startedBy := uint(userID)
+
+	entity := strings.ToLower(strings.TrimSpace(c.FormValue("entity")))
+	if !models.IsValidLegacyEntity(entity) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid entity. Use " + strings.Join(models.LegacyEntities(), ", ") + ".",
+		})
+	}
+
+	var template *models.LegacyImportTemplate
+	if templateID := strings.TrimSpace(c.FormValue("templateId")); templateID != "" {
+		template = &models.LegacyImportTemplate{}
+		if err := lc.DB.First(template, templateID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Legacy import template not found",
+			})
+		}
+		if template.Entity != entity {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Template " + template.Name + " maps " + template.Entity + ", not " + entity,
+			})
+		}
+	}
+
+	cutoff, err := utils.LegacyImportCutoff(lc.Config)
+	if err != nil {
+		log.Println(handler+" - Invalid go-live date:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Legacy imports are misconfigured",
+		})
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		log.Println(handler+" - Import file required:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Import file is required",
+		})
+	}
+	src, err := file.Open()
+	if err != nil {
+		log.Println(handler+" - Failed to open import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to open import file",
+		})
+	}
+	defer src.Close()
+
+	records, fileHash, err := utils.ReadLegacyImportFile(file.Filename, src)
+	if err != nil {
+		log.Println(handler+" - Invalid import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid import file: " + err.Error(),
+		})
+	}
+	rows, err := utils.ParseLegacyImportRows(entity, template, records)
+	if err != nil {
+		log.Println(handler+" - Invalid import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid import file: " + err.Error(),
+		})
+	}
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No rows to import",
+		})
+	}
+
+	run, err := utils.RunLegacyImport(lc.DB, rows, utils.LegacyImportOptions{
+		Entity:          entity,
+		Template:        template,
+		FileName:        file.Filename,
+		FileHash:        fileHash,
+		DryRun:          dryRun,
+		Source:          models.LegacyImportSourceAPI,
+		StartedBy:       &startedBy,
+		DefaultCurrency: lc.Config.DefaultCurrency,
+		Cutoff:          cutoff,
+	})
+	if run == nil {
+		log.Println(handler+" - Failed to run legacy import:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to run legacy import",
+		})
+	}
+	if err != nil {
+		// The run is saved as failed with the records imported before the error
+		log.Println(handler+" - Legacy import failed:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Legacy import run %d failed: %s", run.ID, err.Error()),
+		})
+	}
+
+	// Reload run with template and user for response
+	if err := lc.DB.Preload("Template").Preload("StartUser").First(run, run.ID).Error; err != nil {
+		log.Println(handler+" - Failed to load legacy import run:", err)
+	}
+
+	status := fiber.StatusCreated
+	message := fmt.Sprintf("Legacy import completed: %d imported, %d skipped, %d invalid", run.Imported, run.Skipped, run.Invalid)
+	if dryRun {
+		status = fiber.StatusOK
+		message = fmt.Sprintf("Legacy import validated: %d to import, %d skipped, %d invalid", run.Imported, run.Skipped, run.Invalid)
+	}
+
+	log.Println(handler + " completed successfully")
+	return c.Status(status).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    run.ToResponse(true),
+	})
+}
+
+// GetLegacyImportRuns retrieves the legacy import runs
+// @Summary Get Legacy Import Runs
+// @Description Retrieve the audit log of legacy import runs and dry runs with pagination, without their reports
+// @Tags Legacy Imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of runs per page" default(10)
+// @Param entity query string false "Filter by entity (orders, complains, attendances)"
+// @Param status query string false "Filter by status (validated, completed, failed)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.LegacyImportRunResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/legacy-imports [get]
+func (lc *LegacyImportController) GetLegacyImportRuns(c fiber.Ctx) error {
+	log.Println("GetLegacyImportRuns called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var runs []models.LegacyImportRun
+
+	// Build base query, reports are only returned by the run detail
+	query := lc.DB.Model(&models.LegacyImportRun{}).Omit("report").Preload("Template").Preload("StartUser").Order("created_at DESC")
+
+	var filters []string
+	if entity := strings.ToLower(strings.TrimSpace(c.Query("entity", ""))); entity != "" {
+		query = query.Where("entity = ?", entity)
+		filters = append(filters, "entity: "+entity)
+	}
+	if status := strings.ToLower(strings.TrimSpace(c.Query("status", ""))); status != "" {
+		query = query.Where("status = ?", status)
+		filters = append(filters, "status: "+status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		log.Println("GetLegacyImportRuns - Failed to retrieve runs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve legacy import runs",
+		})
+	}
+
+	// Format response
+	runList := make([]models.LegacyImportRunResponse, len(runs))
+	for i := range runs {
+		runList[i] = *runs[i].ToResponse(false)
+	}
+
+	// Build success message
+	message := "Legacy import runs retrieved successfully"
+	if len(filters) > 0 {
+		message += " (filtered by " + strings.Join(filters, " | ") + ")"
+	}
+
+	log.Println("GetLegacyImportRuns completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    runList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetLegacyImportRun retrieves a legacy import run with its validation report
+// @Summary Get Legacy Import Run
+// @Description Retrieve a legacy import run with the rows it rejected and why
+// @Tags Legacy Imports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Run ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.LegacyImportRunResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/legacy-imports/{id} [get]
+func (lc *LegacyImportController) GetLegacyImportRun(c fiber.Ctx) error {
+	log.Println("GetLegacyImportRun called")
+	id := c.Params("id")
+
+	var run models.LegacyImportRun
+	if err := lc.DB.Preload("Template").Preload("StartUser").First(&run, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Legacy import run not found",
+			})
+		}
+		log.Println("GetLegacyImportRun - Failed to retrieve run:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve legacy import run",
+		})
+	}
+
+	log.Println("GetLegacyImportRun completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Legacy import run retrieved successfully",
+		Data:    run.ToResponse(true),
+	})
+}
@@ -1,11 +1,13 @@
 package controllers
 
 import (
+	"encoding/json"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -21,25 +23,61 @@ func NewLocationController(db *gorm.DB) *LocationController {
 
 // Request structs
 type CreateLocationRequest struct {
-	Name      string  `json:"name" validate:"required,min=3,max=100"`
-	Latitude  float64 `json:"latitude" validate:"required"`
-	Longitude float64 `json:"longitude" validate:"required"`
+	Name              string       `json:"name" validate:"required,min=3,max=100"`
+	Latitude          float64      `json:"latitude" validate:"required"`
+	Longitude         float64      `json:"longitude" validate:"required"`
+	GeofenceRadius    float64      `json:"geofenceRadius" validate:"omitempty,gt=0"`           // meters, defaults to 10
+	GeofencePolygon   [][2]float64 `json:"geofencePolygon" validate:"omitempty"`               // [latitude, longitude] points, replaces the radius when set
+	MinFaceConfidence *float64     `json:"minFaceConfidence" validate:"omitempty,min=0,max=1"` // empty uses the global minimum
 }
 
 type UpdateLocationRequest struct {
-	Latitude  float64 `json:"latitude" validate:"required"`
-	Longitude float64 `json:"longitude" validate:"required"`
+	Name              string       `json:"name" validate:"omitempty,min=3,max=100"` // empty keeps the current name
+	Latitude          float64      `json:"latitude" validate:"required"`
+	Longitude         float64      `json:"longitude" validate:"required"`
+	GeofenceRadius    float64      `json:"geofenceRadius" validate:"omitempty,gt=0"`           // meters, defaults to 10
+	GeofencePolygon   [][2]float64 `json:"geofencePolygon" validate:"omitempty"`               // [latitude, longitude] points, empty uses the radius
+	MinFaceConfidence *float64     `json:"minFaceConfidence" validate:"omitempty,min=0,max=1"` // empty uses the global minimum
+	IsActive          *bool        `json:"isActive"`                                           // empty keeps the current state
+}
+
+// validMinFaceConfidence reports whether a location's minimum face match confidence is empty or between 0 and 1
+func validMinFaceConfidence(minFaceConfidence *float64) bool {
+	return minFaceConfidence == nil || (*minFaceConfidence >= 0 && *minFaceConfidence <= 1)
+}
+
+// locationGeofence validates the coordinates and geofence of a location request, returning the radius with its default applied
+// and the polygon encoded for storage
+func locationGeofence(latitude, longitude, radius float64, polygon [][2]float64) (float64, string, error) {
+	if err := utils.ValidateCoordinates(latitude, longitude); err != nil {
+		return 0, "", err
+	}
+	if radius == 0 {
+		radius = models.DefaultGeofenceRadius
+	}
+	if err := utils.ValidateGeofence(latitude, longitude, radius, polygon); err != nil {
+		return 0, "", err
+	}
+	if len(polygon) == 0 {
+		return radius, "", nil
+	}
+	encoded, err := json.Marshal(polygon)
+	if err != nil {
+		return 0, "", err
+	}
+	return radius, string(encoded), nil
 }
 
 // GetLocations retrieves a list of locations with pagination and search
 // @Summary Get Locations
-// @Description Retrieve a list of locations with pagination and search
+// @Description Retrieve a list of locations with pagination and search, deleted locations are left out
 // @Tags Locations
 // @Accept json
 // @Produce json
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Number of locations per page" default(10)
 // @Param search query string false "Search term for location name"
+// @Param isActive query bool false "Filter by active state"
 // @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.LocationResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -54,7 +92,7 @@ func (lc *LocationController) GetLocations(c fiber.Ctx) error {
 	var locations []models.Location
 
 	// Build base query
-	query := lc.DB.Model(&models.Location{}).Order("created_at DESC")
+	query := lc.DB.Model(&models.Location{}).Where("deleted_at IS NULL").Order("created_at DESC")
 
 	// Search condition if provided
 	search := strings.TrimSpace(c.Query("search", ""))
@@ -62,6 +100,19 @@ func (lc *LocationController) GetLocations(c fiber.Ctx) error {
 		query = query.Where("name ILIKE ?", "%"+search+"%")
 	}
 
+	// Active state filter if provided
+	isActive := strings.TrimSpace(c.Query("isActive", ""))
+	if isActive != "" {
+		active, err := strconv.ParseBool(isActive)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid isActive value. Use true or false.",
+			})
+		}
+		query = query.Where("is_active = ?", active)
+	}
+
 	// Get total count for pagination
 	var total int64
 	query.Count(&total)
@@ -88,6 +139,9 @@ func (lc *LocationController) GetLocations(c fiber.Ctx) error {
 	if search != "" {
 		filters = append(filters, "search: "+search)
 	}
+	if isActive != "" {
+		filters = append(filters, "isActive: "+isActive)
+	}
 
 	if len(filters) > 0 {
 		message += " with filters (" + strings.Join(filters, ", ") + ")"
@@ -124,7 +178,7 @@ func (lc *LocationController) GetLocation(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var location models.Location
-	if err := lc.DB.Where("id = ?", id).First(&location).Error; err != nil {
+	if err := lc.DB.Where("id = ? AND deleted_at IS NULL", id).First(&location).Error; err != nil {
 		log.Println("Location not found")
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -163,9 +217,26 @@ func (lc *LocationController) CreateLocation(c fiber.Ctx) error {
 		})
 	}
 
+	if !validMinFaceConfidence(req.MinFaceConfidence) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "minFaceConfidence must be between 0 and 1",
+		})
+	}
+
+	// Coordinates must be plausible and the geofence must surround them
+	radius, polygon, err := locationGeofence(req.Latitude, req.Longitude, req.GeofenceRadius, req.GeofencePolygon)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid location: " + err.Error(),
+		})
+	}
+
 	// Check for existing location with the same name
+	req.Name = strings.TrimSpace(req.Name)
 	var existing models.Location
-	if err := lc.DB.Where("name = ?", req.Name).First(&existing).Error; err == nil {
+	if err := lc.DB.Where("name = ? AND deleted_at IS NULL", req.Name).First(&existing).Error; err == nil {
 		log.Println("Location with the same name already exists")
 		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
 			Success: false,
@@ -175,9 +246,13 @@ func (lc *LocationController) CreateLocation(c fiber.Ctx) error {
 
 	// Create new location
 	location := models.Location{
-		Name:      req.Name,
-		Latitude:  req.Latitude,
-		Longitude: req.Longitude,
+		Name:              req.Name,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		GeofenceRadius:    radius,
+		GeofencePolygon:   polygon,
+		MinFaceConfidence: req.MinFaceConfidence,
+		IsActive:          true,
 	}
 
 	if err := lc.DB.Create(&location).Error; err != nil {
@@ -214,7 +289,7 @@ func (lc *LocationController) UpdateLocation(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var location models.Location
-	if err := lc.DB.Where("id = ?", id).First(&location).Error; err != nil {
+	if err := lc.DB.Where("id = ? AND deleted_at IS NULL", id).First(&location).Error; err != nil {
 		log.Println("Location not found")
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -232,9 +307,53 @@ func (lc *LocationController) UpdateLocation(c fiber.Ctx) error {
 		})
 	}
 
+	if !validMinFaceConfidence(req.MinFaceConfidence) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "minFaceConfidence must be between 0 and 1",
+		})
+	}
+
+	// Coordinates must be plausible and the geofence must surround them
+	radius, polygon, err := locationGeofence(req.Latitude, req.Longitude, req.GeofenceRadius, req.GeofencePolygon)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid location: " + err.Error(),
+		})
+	}
+
+	// Check for another location with the same name
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name != "" && req.Name != location.Name {
+		var existing models.Location
+		if err := lc.DB.Where("name = ? AND id != ? AND deleted_at IS NULL", req.Name, location.ID).First(&existing).Error; err == nil {
+			log.Println("Location with the same name already exists")
+			return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Location with the same name already exists",
+			})
+		}
+		location.Name = req.Name
+	}
+
+	// The kiosk records every face check-in at its location, it cannot be switched off
+	if req.IsActive != nil && !*req.IsActive && location.ID == kioskLocationID {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "The kiosk location cannot be deactivated",
+		})
+	}
+
 	// Update location fields
 	location.Latitude = req.Latitude
 	location.Longitude = req.Longitude
+	location.GeofenceRadius = radius
+	location.GeofencePolygon = polygon
+	location.MinFaceConfidence = req.MinFaceConfidence
+	if req.IsActive != nil {
+		location.IsActive = *req.IsActive
+	}
 
 	if err := lc.DB.Save(&location).Error; err != nil {
 		log.Println("Failed to update location:", err)
@@ -254,7 +373,7 @@ func (lc *LocationController) UpdateLocation(c fiber.Ctx) error {
 
 // DeleteLocation deletes a location by ID
 // @Summary Delete Location
-// @Description Delete a location by its ID
+// @Description Soft delete a location by its ID. The location is hidden and rejects check-ins, past attendances keep referring to it.
 // @Tags Locations
 // @Accept json
 // @Produce json
@@ -269,7 +388,7 @@ func (lc *LocationController) DeleteLocation(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var location models.Location
-	if err := lc.DB.Where("id = ?", id).First(&location).Error; err != nil {
+	if err := lc.DB.Where("id = ? AND deleted_at IS NULL", id).First(&location).Error; err != nil {
 		log.Println("Location not found")
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -277,7 +396,17 @@ func (lc *LocationController) DeleteLocation(c fiber.Ctx) error {
 		})
 	}
 
-	if err := lc.DB.Delete(&location).Error; err != nil {
+	// The kiosk records every face check-in at its location
+	if location.ID == kioskLocationID {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "The kiosk location cannot be deleted",
+		})
+	}
+
+	// Soft delete so past attendances still resolve their location
+	now := time.Now()
+	if err := lc.DB.Model(&location).Updates(map[string]interface{}{"deleted_at": now, "is_active": false}).Error; err != nil {
 		log.Println("Failed to delete location:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
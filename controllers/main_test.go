@@ -0,0 +1,13 @@
+package controllers
+
+import (
+	"livo-fiber-backend/testutil"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// testDB is the migrated and seeded test database, nil when none is available
+var testDB *gorm.DB
+
+func TestMain(m *testing.M) { testutil.RunMain(m, &testDB) }
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"livo-fiber-backend/utils"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type MaintenanceController struct{}
+
+func NewMaintenanceController() *MaintenanceController {
+	return &MaintenanceController{}
+}
+
+// Request structs
+type SetMaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message"`
+}
+
+// GetMaintenanceStatus retrieves the current maintenance mode state
+// @Summary Get Maintenance Status
+// @Description Retrieve whether the API is in maintenance (read-only) mode
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse{data=utils.MaintenanceState}
+// @Router /api/maintenance [get]
+func (mc *MaintenanceController) GetMaintenanceStatus(c fiber.Ctx) error {
+	log.Println("GetMaintenanceStatus called")
+
+	state := utils.GetMaintenance()
+
+	message := "System is operational"
+	if state.Enabled {
+		message = "System is in maintenance mode"
+	}
+
+	log.Println("GetMaintenanceStatus completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    state,
+	})
+}
+
+// SetMaintenanceMode switches maintenance (read-only) mode on or off
+// @Summary Set Maintenance Mode
+// @Description Enable or disable maintenance mode. While enabled, mutating requests return 503 and GET requests keep working.
+// @Tags Maintenance
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SetMaintenanceRequest true "Maintenance mode switch"
+// @Success 200 {object} utils.SuccessResponse{data=utils.MaintenanceState}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/maintenance [put]
+func (mc *MaintenanceController) SetMaintenanceMode(c fiber.Ctx) error {
+	log.Println("SetMaintenanceMode called")
+
+	// Parse request body
+	var req SetMaintenanceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SetMaintenanceMode - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	username, _ := c.Locals("username").(string)
+	state := utils.SetMaintenance(req.Enabled, strings.TrimSpace(req.Message), username)
+
+	message := "Maintenance mode disabled"
+	if state.Enabled {
+		message = "Maintenance mode enabled"
+	}
+
+	log.Printf("SetMaintenanceMode completed successfully (enabled=%t, by=%s)\n", state.Enabled, username)
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    state,
+	})
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type MetaController struct {
+	CORS               *utils.CORSPolicy // nil when the CORS configuration is invalid, the server refuses to start then
+	RateLimitPerMinute int
+	AuthRateLimit      int // per endpoint limit on login and other credential checks
+}
+
+func NewMetaController(cfg *config.Config) *MetaController {
+	corsPolicy, _ := utils.CORSPolicyFromConfig(cfg)
+	return &MetaController{CORS: corsPolicy, RateLimitPerMinute: cfg.RateLimitPerMinute, AuthRateLimit: cfg.AuthRateLimitPerMinute}
+}
+
+// Unique response structs
+// StatusMetaItem is a single status with its label in the requested language
+type StatusMetaItem struct {
+	models.StatusDefinition
+	Label string `json:"label"`
+}
+
+// StatusMetaResponse lists the order statuses clients should use instead of hardcoded strings
+type StatusMetaResponse struct {
+	Language           string           `json:"language"`
+	Languages          []string         `json:"languages"`
+	ProcessingStatuses []StatusMetaItem `json:"processingStatuses"`
+	EventStatuses      []StatusMetaItem `json:"eventStatuses"`
+	QCStatuses         []StatusMetaItem `json:"qcStatuses"`
+}
+
+// FormMeta is the validation constraints of a form, for clients to validate input the way the backend does
+type FormMeta struct {
+	Key         string            `json:"key"`
+	Description string            `json:"description"`
+	Method      string            `json:"method"`
+	Path        string            `json:"path"`
+	Fields      []utils.FormField `json:"fields"`
+}
+
+// metaForm is a form whose constraints are published, described from its request struct
+type metaForm struct {
+	Key         string
+	Description string
+	Method      string
+	Path        string
+	Request     interface{}
+}
+
+// metaForms are the forms published by GetForms, keep in sync with the routes when a request struct moves
+var metaForms = []metaForm{
+	{Key: "order_create", Description: "Create order", Method: fiber.MethodPost, Path: "/api/orders", Request: CreateOrderRequest{}},
+	{Key: "order_manual_create", Description: "Create manual order for offline and walk-in sales", Method: fiber.MethodPost, Path: "/api/orders/manual", Request: CreateManualOrderRequest{}},
+	{Key: "complain_create", Description: "Create complain", Method: fiber.MethodPost, Path: "/api/complains", Request: CreateComplainRequest{}},
+	{Key: "user_create", Description: "Create user", Method: fiber.MethodPost, Path: "/api/users", Request: CreateUserRequest{}},
+}
+
+// CORSCheckResult tells how CORS treats a request from an origin to a path
+type CORSCheckResult struct {
+	Origin  string `json:"origin"`
+	Path    string `json:"path"`
+	Group   string `json:"group"`
+	Allowed bool   `json:"allowed"`
+}
+
+// CORSConfigResponse is the effective CORS configuration per route group
+type CORSConfigResponse struct {
+	utils.CORSPolicy
+	Check *CORSCheckResult `json:"check,omitempty"` // set when an origin is given
+}
+
+// RateLimitUsageResponse is the calling client's usage of the global rate limit
+type RateLimitUsageResponse struct {
+	Client string `json:"client"` // the client IP the limit is counted on
+	utils.ClientRateLimit
+	AuthLimitPerMinute int `json:"authLimitPerMinute"` // separate per endpoint limit on login and credential checks
+}
+
+// GetStatuses retrieves the canonical order statuses with labels, color hints and allowed transitions
+// @Summary Get Status Metadata
+// @Description Retrieve the canonical order processing, order event and QC statuses with display labels, color hints and allowed transitions. The label is localized by the lang query parameter or the Accept-Language header; all labels are included as well
+// @Tags Meta
+// @Accept json
+// @Produce json
+// @Param lang query string false "Label language (en or id), defaults to the Accept-Language header or en"
+// @Success 200 {object} utils.SuccessResponse{data=StatusMetaResponse}
+// @Router /api/meta/statuses [get]
+func (mc *MetaController) GetStatuses(c fiber.Ctx) error {
+	log.Println("GetStatuses called")
+	language := statusLanguage(c.Query("lang", ""), c.Get(fiber.HeaderAcceptLanguage))
+
+	localize := func(definitions []models.StatusDefinition) []StatusMetaItem {
+		items := make([]StatusMetaItem, len(definitions))
+		for i, definition := range definitions {
+			items[i] = StatusMetaItem{StatusDefinition: definition, Label: definition.Labels[language]}
+		}
+		return items
+	}
+
+	log.Println("GetStatuses completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Statuses retrieved successfully",
+		Data: StatusMetaResponse{
+			Language:           language,
+			Languages:          []string{models.StatusLanguageEnglish, models.StatusLanguageIndonesian},
+			ProcessingStatuses: localize(models.ProcessingStatusDefinitions()),
+			EventStatuses:      localize(models.EventStatusDefinitions()),
+			QCStatuses:         localize(models.QCStatusDefinitions()),
+		},
+	})
+}
+
+// GetForms retrieves the validation constraints of the key forms
+// @Summary Get Form Metadata
+// @Description Retrieve the validation constraints (required fields, lengths, value ranges, allowed values and datetime formats) of the order, complain and user forms, derived from the backend request definitions so clients do not duplicate them. Pass form to retrieve a single form
+// @Tags Meta
+// @Accept json
+// @Produce json
+// @Param form query string false "Form key (order_create, order_manual_create, complain_create, user_create)"
+// @Success 200 {object} utils.SuccessResponse{data=[]FormMeta}
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/meta/forms [get]
+func (mc *MetaController) GetForms(c fiber.Ctx) error {
+	log.Println("GetForms called")
+	key := strings.TrimSpace(c.Query("form", ""))
+
+	forms := []FormMeta{}
+	for _, form := range metaForms {
+		if key != "" && form.Key != key {
+			continue
+		}
+		forms = append(forms, FormMeta{
+			Key:         form.Key,
+			Description: form.Description,
+			Method:      form.Method,
+			Path:        form.Path,
+			Fields:      utils.DescribeForm(form.Request),
+		})
+	}
+
+	if key != "" && len(forms) == 0 {
+		log.Println("GetForms - Form not found:", key)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Form " + key + " not found.",
+		})
+	}
+
+	// Build success message
+	message := "Forms retrieved successfully"
+	if key != "" {
+		message += " (filtered by form: " + key + ")"
+	}
+
+	log.Println("GetForms completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    forms,
+	})
+}
+
+// GetCORSConfig retrieves the effective CORS configuration
+// @Summary Get Effective CORS Configuration
+// @Description Retrieve the CORS profile and the effective origins and credential rules of each route group. Pass origin (and optionally path) to check whether requests from that origin are allowed.
+// @Tags Meta
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param origin query string false "Origin to check, e.g. http://localhost:3000"
+// @Param path query string false "Request path to check the origin against" default(/api/orders)
+// @Success 200 {object} utils.SuccessResponse{data=CORSConfigResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/meta/cors [get]
+func (mc *MetaController) GetCORSConfig(c fiber.Ctx) error {
+	log.Println("GetCORSConfig called")
+	if mc.CORS == nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "CORS configuration is invalid",
+		})
+	}
+
+	response := CORSConfigResponse{CORSPolicy: *mc.CORS}
+	if origin := strings.TrimSpace(c.Query("origin", "")); origin != "" {
+		path := c.Query("path", "/api/orders")
+		group := mc.CORS.GroupFor(path)
+		response.Check = &CORSCheckResult{
+			Origin:  origin,
+			Path:    path,
+			Group:   group.Name,
+			Allowed: group.AllowsOrigin(origin),
+		}
+	}
+
+	log.Println("GetCORSConfig completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "CORS configuration retrieved successfully",
+		Data:    response,
+	})
+}
+
+// statusLanguage picks the label language from the lang parameter, then the Accept-Language header, defaulting to English
+func statusLanguage(lang, acceptLanguage string) string {
+	candidates := []string{lang}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		candidates = append(candidates, tag)
+	}
+
+	for _, candidate := range candidates {
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(candidate)), "-")
+		if base == models.StatusLanguageEnglish || base == models.StatusLanguageIndonesian {
+			return base
+		}
+	}
+	return models.StatusLanguageEnglish
+}
+
+// GetRateLimitUsage retrieves the calling client's usage of the global rate limit
+// @Summary Get Rate Limit Usage
+// @Description Retrieve how many requests the calling client (by IP) made in the current rate limit window, how many remain and when the window resets, so clients can back off before being rejected. The same values are sent in the RateLimit-* headers of every response; this request itself is counted.
+// @Tags Meta
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse{data=RateLimitUsageResponse}
+// @Failure 429 {object} utils.ErrorResponse
+// @Router /api/meta/rate-limit [get]
+func (mc *MetaController) GetRateLimitUsage(c fiber.Ctx) error {
+	usage := utils.GetClientRateLimit(c.IP(), mc.RateLimitPerMinute, utils.RateLimitWindow, time.Now())
+
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Rate limit usage retrieved successfully",
+		Data: RateLimitUsageResponse{
+			Client:             c.IP(),
+			ClientRateLimit:    usage,
+			AuthLimitPerMinute: mc.AuthRateLimit,
+		},
+	})
+}
@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"livo-fiber-backend/database"
+	"livo-fiber-backend/utils"
+	"log"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type MetricsController struct {
+	DB *gorm.DB
+}
+
+func NewMetricsController(db *gorm.DB) *MetricsController {
+	return &MetricsController{DB: db}
+}
+
+// Unique response structs
+// DBPoolMetrics represents the database connection pool statistics
+type DBPoolMetrics struct {
+	MaxOpenConnections int   `json:"maxOpenConnections"`
+	OpenConnections    int   `json:"openConnections"`
+	InUse              int   `json:"inUse"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"waitCount"`
+	WaitDurationMs     int64 `json:"waitDurationMs"`
+	MaxIdleClosed      int64 `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64 `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64 `json:"maxLifetimeClosed"`
+}
+
+// MetricsResponse represents the runtime metrics of the API
+type MetricsResponse struct {
+	DBPool      DBPoolMetrics           `json:"dbPool"`
+	SlowQueries database.SlowQueryStats `json:"slowQueries"`
+	AuthGuard   utils.LoginGuardStats   `json:"authGuard"`
+}
+
+// GetMetrics retrieves database pool statistics and slow query counts
+// @Summary Get Metrics
+// @Description Retrieve database connection pool statistics, slow query counts per route and brute-force protection counters
+// @Tags Metrics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=MetricsResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/metrics [get]
+func (mc *MetricsController) GetMetrics(c fiber.Ctx) error {
+	log.Println("GetMetrics called")
+
+	sqlDB, err := mc.DB.DB()
+	if err != nil {
+		log.Println("GetMetrics - Failed to get database instance:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to get database instance",
+		})
+	}
+
+	stats := sqlDB.Stats()
+
+	log.Println("GetMetrics completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Metrics retrieved successfully",
+		Data: MetricsResponse{
+			DBPool: DBPoolMetrics{
+				MaxOpenConnections: stats.MaxOpenConnections,
+				OpenConnections:    stats.OpenConnections,
+				InUse:              stats.InUse,
+				Idle:               stats.Idle,
+				WaitCount:          stats.WaitCount,
+				WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+				MaxIdleClosed:      stats.MaxIdleClosed,
+				MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+				MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+			},
+			SlowQueries: database.GetSlowQueryStats(),
+			AuthGuard:   utils.GetLoginGuardStats(),
+		},
+	})
+}
@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"livo-fiber-backend/config"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
@@ -11,15 +14,27 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
 type MobileAttendanceController struct {
 	DB *gorm.DB
+	// Validates the device clock reported with a check
+	ClockSkew utils.ClockSkewPolicy
+	// Minimum confidence of the face matches accepted per location
+	FaceThreshold utils.FaceThresholdPolicy
+	// Ends the break still in progress at checkout
+	Breaks utils.BreakPolicy
 }
 
-func NewMobileAttendanceController(db *gorm.DB) *MobileAttendanceController {
-	return &MobileAttendanceController{DB: db}
+func NewMobileAttendanceController(cfg *config.Config, db *gorm.DB) *MobileAttendanceController {
+	return &MobileAttendanceController{
+		DB:            db,
+		ClockSkew:     utils.ClockSkewPolicyFromConfig(cfg),
+		FaceThreshold: utils.FaceThresholdPolicyFromConfig(cfg),
+		Breaks:        utils.BreakPolicyFromConfig(cfg),
+	}
 }
 
 // Unique response structs
@@ -33,6 +48,11 @@ type MobileCheckInResponse struct {
 	Late       int                  `json:"late" example:"2"`
 }
 
+type NearestLocationResponse struct {
+	Location *models.LocationResponse `json:"location"`
+	Distance float64                  `json:"distance"` // meters from the location
+}
+
 type MobileCheckOutResponse struct {
 	Matched    bool                 `json:"matched" example:"true"`
 	UserID     string               `json:"userId" example:"1"`
@@ -64,7 +84,7 @@ func (mac *MobileAttendanceController) VerifyUserFace(c fiber.Ctx) error {
 
 	// Get user from database
 	var user models.User
-	if err := mac.DB.Where("id = ?", currUserID).First(&user).Error; err != nil {
+	if err := mac.DB.WithContext(c.Context()).Where("id = ?", currUserID).First(&user).Error; err != nil {
 		log.Println("VerifyUserFace - User not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -81,26 +101,33 @@ func (mac *MobileAttendanceController) VerifyUserFace(c fiber.Ctx) error {
 		})
 	}
 
-	// Validate mime type
-	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
-		log.Println("VerifyUserFace - Invalid image file type")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	tmpPath, err := utils.CreateTempFile("verify_*.jpg")
+	if err != nil {
+		log.Println("VerifyUserFace - Failed to save image file:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid image file type",
+			Error:   "Failed to save image file",
 		})
 	}
+	defer os.Remove(tmpPath)
 
-	tmpPath := fmt.Sprintf("tmp/verify_%d.jpg", user.ID)
-	if err := c.SaveFile(file, tmpPath); err != nil {
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			log.Println("VerifyUserFace - Invalid image file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
 		log.Println("VerifyUserFace - Failed to save image file:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to save image file",
 		})
 	}
-	defer os.Remove(tmpPath)
 
-	result, err := utils.SendToDeepFaceVerify(user.ID, tmpPath)
+	result, err := utils.SendToDeepFaceVerify(c.Context(), user.ID, tmpPath)
 	if err != nil {
 		log.Println("VerifyUserFace - Face verification failed:", err)
 		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
@@ -137,10 +164,15 @@ func (mac *MobileAttendanceController) VerifyUserFace(c fiber.Ctx) error {
 // @Produce json
 // @Security BearerAuth
 // @Param image formData file true "Face image for verification"
-// @Param location_id formData int true "Location ID for GPS verification"
+// @Param location_id formData int false "Location ID for GPS verification, the nearest location whose geofence contains the coordinates when empty"
 // @Param latitude formData float64 true "Latitude for GPS verification"
 // @Param longitude formData float64 true "Longitude for GPS verification"
 // @Param accuracy formData float64 true "GPS accuracy in meters"
+// @Param is_mock_location formData bool false "Device reported mock location flag"
+// @Param device_id formData string false "Device identifier"
+// @Param os_build formData string false "Device OS build"
+// @Param client_timestamp formData string false "Device time of the capture (RFC3339)"
+// @Param time_token formData string false "Last token from /api/time/token, for captures made offline"
 // @Success 200 {object} utils.SuccessResponse{data=MobileCheckInResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -154,7 +186,7 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 
 	// Get user from database
 	var user models.User
-	if err := mac.DB.Where("id = ?", currUserID).First(&user).Error; err != nil {
+	if err := mac.DB.WithContext(c.Context()).Where("id = ?", currUserID).First(&user).Error; err != nil {
 		log.Println("MobileCheckInUserByFace - User not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -171,26 +203,33 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 		})
 	}
 
-	// Validate mime type
-	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
-		log.Println("MobileCheckInUserByFace - Invalid image file type")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	tmpPath, err := utils.CreateTempFile("search_face_*.jpg")
+	if err != nil {
+		log.Println("MobileCheckInUserByFace - Failed to save image:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid image file type",
+			Error:   "Failed to save image file",
 		})
 	}
+	defer os.Remove(tmpPath)
 
-	tmpPath := "tmp/search_face.jpg"
-	if err := c.SaveFile(file, tmpPath); err != nil {
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			log.Println("MobileCheckInUserByFace - Invalid image file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
 		log.Println("MobileCheckInUserByFace - Failed to save image:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to save image file",
 		})
 	}
-	defer os.Remove(tmpPath)
 
-	result, err := utils.SendToDeepFaceVerify(user.ID, tmpPath)
+	result, err := utils.SendToDeepFaceVerify(c.Context(), user.ID, tmpPath)
 	if err != nil {
 		log.Println("MobileCheckInUserByFace - Face verification failed:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
@@ -207,22 +246,6 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 	}
 	log.Println("MobileCheckInUserByFace - Face verified successfully")
 
-	locationIDStr := c.FormValue("location_id")
-	if locationIDStr == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Location ID is required",
-		})
-	}
-
-	locationID, err := strconv.Atoi(locationIDStr)
-	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Invalid Location ID",
-		})
-	}
-
 	// Get user's current GPS coordinates
 	latitudeStr := c.FormValue("latitude")
 	longitudeStr := c.FormValue("longitude")
@@ -259,20 +282,26 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 		})
 	}
 
-	// Verify location exists
-	var location models.Location
-	if err := mac.DB.Where("id = ?", locationID).First(&location).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+	// Verify location exists and accepts check-ins, inferred from the coordinates when no location ID is sent
+	location, err := mac.resolveAttendanceLocation(c.Context(), c.FormValue("location_id"), latitude, longitude)
+	if err != nil {
+		log.Println("MobileCheckInUserByFace - Failed to resolve location:", err)
+		return attendanceLocationErrorResponse(c, err)
+	}
+
+	// Reject face matches below the minimum confidence of the location
+	faceThreshold, err := mac.FaceThreshold.Check(result.Confidence, location)
+	if err != nil {
+		log.Printf("MobileCheckInUserByFace - Face match rejected (userID=%d): %v\n", user.ID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Location not found",
+			Error:   "Face not recognized with enough confidence, please try again",
 		})
 	}
 
-	// Calculate distance between user's GPS and registered location
-	distance := utils.CalculateDistance(latitude, longitude, location.Latitude, location.Longitude)
-
-	// Check if user is within 10 meters
-	if distance > 10.0 {
+	// Check the user's GPS is inside the geofence of the registered location
+	inside, distance := utils.WithinGeofence(location, latitude, longitude)
+	if !inside {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   fmt.Sprintf("You are too far from the check-in location. Distance: %.2f meters", distance),
@@ -280,74 +309,18 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 	}
 
 	// Fake GPS Detection
-	// Check user's recent attendance records for suspicious patterns
-	var recentAttendances []models.Attendance
-	mac.DB.Where("user_id = ?", user.ID).
-		Order("checked_in DESC").
-		Limit(5).
-		Find(&recentAttendances)
-
-	// 1. Check for sudden accuracy jumps
-	if len(recentAttendances) > 0 {
-		lastAccuracy := recentAttendances[0].Accuracy
-		accuracyDiff := accuracy - lastAccuracy
-		if accuracyDiff < 0 {
-			accuracyDiff = -accuracyDiff
-		}
-
-		// If accuracy suddenly jumps more than 50 meters, it's suspicious
-		if accuracyDiff > 50 {
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   fmt.Sprintf("Suspicious GPS behavior detected: Accuracy suddenly changed from %.1f to %.1f meters", lastAccuracy, accuracy),
-			})
-		}
-	}
-
-	// 2. Check for fixed accuracy (always the same value)
-	if len(recentAttendances) >= 3 {
-		allSame := true
-		for _, att := range recentAttendances[:3] {
-			if att.Accuracy != accuracy {
-				allSame = false
-				break
-			}
-		}
-
-		if allSame && accuracy > 0 {
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Suspicious GPS behavior detected: Accuracy values are suspiciously consistent",
-			})
-		}
-	}
-
-	// 3. Check for impossible speed
-	if len(recentAttendances) > 0 {
-		lastAttendance := recentAttendances[0]
-		timeDiff := time.Since(lastAttendance.CheckedIn).Seconds()
-
-		// Only check if last check-in was within the last hour
-		if timeDiff < 3600 && timeDiff > 60 {
-			distanceTraveled := utils.CalculateDistance(
-				latitude, longitude,
-				lastAttendance.Latitude, lastAttendance.Longitude,
-			)
-
-			// Calculate speed in meters per second
-			speed := distanceTraveled / timeDiff
-
-			// If speed is more than 50 m/s (180 km/h), it's suspicious
-			if speed > 50 {
-				return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-					Success: false,
-					Error:   fmt.Sprintf("Suspicious GPS behavior detected: Impossible travel speed (%.2f km/h)", speed*3.6),
-				})
-			}
-		}
+	// Combine device signals with recent attendance heuristics into a fraud score
+	signals := mac.parseDeviceSignals(c, user.ID)
+	assessment := mac.assessFraud(c.Context(), user.ID, latitude, longitude, accuracy, signals)
+	if assessment.Rejected() {
+		log.Printf("MobileCheckInUserByFace - Check-in rejected (userID=%d, fraudScore=%d)\n", user.ID, assessment.Score)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Suspicious GPS behavior detected: " + strings.Join(assessment.Reasons, "; "),
+		})
 	}
 
-	// 4. Check if accuracy is too poor (more than 30 meters)
+	// Check if accuracy is too poor (more than 30 meters)
 	if accuracy > 30 {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -361,7 +334,7 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
 
-	if err := mac.DB.Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err == nil {
+	if err := mac.DB.WithContext(c.Context()).Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err == nil {
 		log.Println("MobileCheckInUserByFace - User already checked in today")
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -429,19 +402,30 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 
 	// Create attendance record
 	newAttendance := models.Attendance{
-		UserID:     user.ID,
-		CheckedIn:  checkedInTime,
-		Checked:    true,
-		Status:     status,
-		Late:       lateMinutes,
-		LocationID: uint(locationID),
-		Latitude:   latitude,
-		Longitude:  longitude,
-		Accuracy:   accuracy,
-	}
-	log.Printf("MobileCheckInUserByFace - Creating attendance (status=%s, late=%d min)\n", status, lateMinutes)
-
-	if err := mac.DB.Create(&newAttendance).Error; err != nil {
+		UserID:      user.ID,
+		CheckedIn:   checkedInTime,
+		Checked:     true,
+		Status:      status,
+		Late:        lateMinutes,
+		LocationID:  location.ID,
+		Latitude:    latitude,
+		Longitude:   longitude,
+		Accuracy:    accuracy,
+		EntryMethod: models.EntryMethodMobile,
+
+		FaceConfidence: &result.Confidence,
+		FaceThreshold:  &faceThreshold,
+
+		IsMockLocation: signals.IsMockLocation,
+		DeviceID:       signals.DeviceID,
+		OSBuild:        signals.OSBuild,
+		FraudScore:     assessment.Score,
+		FraudReasons:   strings.Join(assessment.Reasons, "; "),
+		Suspicious:     assessment.Suspicious(),
+	}
+	log.Printf("MobileCheckInUserByFace - Creating attendance (status=%s, late=%d min, fraudScore=%d)\n", status, lateMinutes, assessment.Score)
+
+	if err := mac.DB.WithContext(c.Context()).Create(&newAttendance).Error; err != nil {
 		log.Println("MobileCheckInUserByFace - Failed to create attendance:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -450,7 +434,7 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 	}
 
 	// Reload with associations
-	mac.DB.Preload("User").Preload("Location").First(&newAttendance, newAttendance.ID)
+	mac.DB.WithContext(c.Context()).Preload("User").Preload("Location").First(&newAttendance, newAttendance.ID)
 
 	log.Println("MobileCheckInUserByFace completed successfully")
 	return c.JSON(utils.SuccessResponse{
@@ -476,10 +460,15 @@ func (mac *MobileAttendanceController) MobileCheckInUserByFace(c fiber.Ctx) erro
 // @Produce json
 // @Security BearerAuth
 // @Param image formData file true "Face image for verification"
-// @Param location_id formData int true "Location ID for GPS verification"
+// @Param location_id formData int false "Location ID for GPS verification, the nearest location whose geofence contains the coordinates when empty"
 // @Param latitude formData float64 true "Latitude for GPS verification"
 // @Param longitude formData float64 true "Longitude for GPS verification"
 // @Param accuracy formData float64 true "GPS accuracy in meters"
+// @Param is_mock_location formData bool false "Device reported mock location flag"
+// @Param device_id formData string false "Device identifier"
+// @Param os_build formData string false "Device OS build"
+// @Param client_timestamp formData string false "Device time of the capture (RFC3339)"
+// @Param time_token formData string false "Last token from /api/time/token, for captures made offline"
 // @Success 200 {object} utils.SuccessResponse{data=MobileCheckOutResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -492,7 +481,7 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 
 	// Get user from database
 	var user models.User
-	if err := mac.DB.Where("id = ?", currUserID).First(&user).Error; err != nil {
+	if err := mac.DB.WithContext(c.Context()).Where("id = ?", currUserID).First(&user).Error; err != nil {
 		log.Println("User not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -509,26 +498,33 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 		})
 	}
 
-	// Validate mime type
-	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
-		log.Println("Invalid image file type")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	tmpPath, err := utils.CreateTempFile("search_face_*.jpg")
+	if err != nil {
+		log.Println("Failed to save image:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid image file type",
+			Error:   "Failed to save image file",
 		})
 	}
+	defer os.Remove(tmpPath)
 
-	tmpPath := "tmp/search_face.jpg"
-	if err := c.SaveFile(file, tmpPath); err != nil {
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			log.Println("Invalid image file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
 		log.Println("Failed to save image:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to save image file",
 		})
 	}
-	defer os.Remove(tmpPath)
 
-	result, err := utils.SendToDeepFaceVerify(user.ID, tmpPath)
+	result, err := utils.SendToDeepFaceVerify(c.Context(), user.ID, tmpPath)
 	if err != nil {
 		log.Println("Face verification failed:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
@@ -545,24 +541,6 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 		})
 	}
 
-	locationIDStr := c.FormValue("location_id")
-	if locationIDStr == "" {
-		log.Println("Location ID is required")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Location ID is required",
-		})
-	}
-
-	locationID, err := strconv.Atoi(locationIDStr)
-	if err != nil {
-		log.Println("Invalid Location ID")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Invalid Location ID",
-		})
-	}
-
 	// Get user's current GPS coordinates
 	latitudeStr := c.FormValue("latitude")
 	longitudeStr := c.FormValue("longitude")
@@ -603,21 +581,26 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 		})
 	}
 
-	// Verify location exists
-	var location models.Location
-	if err := mac.DB.Where("id = ?", locationID).First(&location).Error; err != nil {
-		log.Println("Location not found")
-		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+	// Verify location exists and accepts check-ins, inferred from the coordinates when no location ID is sent
+	location, err := mac.resolveAttendanceLocation(c.Context(), c.FormValue("location_id"), latitude, longitude)
+	if err != nil {
+		log.Println("Failed to resolve location:", err)
+		return attendanceLocationErrorResponse(c, err)
+	}
+
+	// Reject face matches below the minimum confidence of the location
+	faceThreshold, err := mac.FaceThreshold.Check(result.Confidence, location)
+	if err != nil {
+		log.Printf("MobileCheckOutUserByFace - Face match rejected (userID=%d): %v\n", user.ID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Location not found",
+			Error:   "Face not recognized with enough confidence, please try again",
 		})
 	}
 
-	// Calculate distance between user's GPS and registered location
-	distance := utils.CalculateDistance(latitude, longitude, location.Latitude, location.Longitude)
-
-	// Check if user is within 10 meters
-	if distance > 10.0 {
+	// Check the user's GPS is inside the geofence of the registered location
+	inside, distance := utils.WithinGeofence(location, latitude, longitude)
+	if !inside {
 		log.Println("User is too far from the check-in location")
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -626,77 +609,18 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 	}
 
 	// Fake GPS Detection
-	// Check user's recent attendance records for suspicious patterns
-	var recentAttendances []models.Attendance
-	mac.DB.Where("user_id = ?", user.ID).
-		Order("checked_in DESC").
-		Limit(5).
-		Find(&recentAttendances)
-
-	// 1. Check for sudden accuracy jumps
-	if len(recentAttendances) > 0 {
-		lastAccuracy := recentAttendances[0].Accuracy
-		accuracyDiff := accuracy - lastAccuracy
-		if accuracyDiff < 0 {
-			accuracyDiff = -accuracyDiff
-		}
-
-		// If accuracy suddenly jumps more than 50 meters, it's suspicious
-		if accuracyDiff > 50 {
-			log.Println("Suspicious GPS behavior detected")
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   fmt.Sprintf("Suspicious GPS behavior detected: Accuracy suddenly changed from %.1f to %.1f meters", lastAccuracy, accuracy),
-			})
-		}
-	}
-
-	// 2. Check for fixed accuracy (always the same value)
-	if len(recentAttendances) >= 3 {
-		allSame := true
-		for _, att := range recentAttendances[:3] {
-			if att.Accuracy != accuracy {
-				allSame = false
-				break
-			}
-		}
-
-		if allSame && accuracy > 0 {
-			log.Println("Suspicious GPS behavior detected: Accuracy values are suspiciously consistent")
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Suspicious GPS behavior detected: Accuracy values are suspiciously consistent",
-			})
-		}
-	}
-
-	// 3. Check for impossible speed
-	if len(recentAttendances) > 0 {
-		lastAttendance := recentAttendances[0]
-		timeDiff := time.Since(lastAttendance.CheckedIn).Seconds()
-
-		// Only check if last check-in was within the last hour
-		if timeDiff < 3600 && timeDiff > 60 {
-			distanceTraveled := utils.CalculateDistance(
-				latitude, longitude,
-				lastAttendance.Latitude, lastAttendance.Longitude,
-			)
-
-			// Calculate speed in meters per second
-			speed := distanceTraveled / timeDiff
-
-			// If speed is more than 50 m/s (180 km/h), it's suspicious
-			if speed > 50 {
-				log.Println("Suspicious GPS behavior detected: Impossible travel speed")
-				return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-					Success: false,
-					Error:   fmt.Sprintf("Suspicious GPS behavior detected: Impossible travel speed (%.2f km/h)", speed*3.6),
-				})
-			}
-		}
+	// Combine device signals with recent attendance heuristics into a fraud score
+	signals := mac.parseDeviceSignals(c, user.ID)
+	assessment := mac.assessFraud(c.Context(), user.ID, latitude, longitude, accuracy, signals)
+	if assessment.Rejected() {
+		log.Println("Suspicious GPS behavior detected")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Suspicious GPS behavior detected: " + strings.Join(assessment.Reasons, "; "),
+		})
 	}
 
-	// 4. Check if accuracy is too poor (more than 30 meters)
+	// Check if accuracy is too poor (more than 30 meters)
 	if accuracy > 30 {
 		log.Println("GPS accuracy is too poor")
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
@@ -710,13 +634,15 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 	now := time.Now()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := startOfDay.Add(24 * time.Hour)
-	if err := mac.DB.Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err != nil {
+	if err := mac.DB.WithContext(c.Context()).Where("user_id = ? AND checked_in >= ? AND checked_in < ? AND checked = ?", user.ID, startOfDay, endOfDay, true).First(&attendance).Error; err != nil {
 		log.Println("User has not checked in today")
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "User has not checked in today",
 		})
 	}
+	attendance.CheckOutFaceConfidence = &result.Confidence
+	attendance.CheckOutFaceThreshold = &faceThreshold
 
 	// Automatically determine checkout behavior based on time
 	checkedOutTime := time.Now()
@@ -736,7 +662,7 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 		attendance.Status = "halfday"
 		attendance.CheckedOut = &checkedOutTime
 		attendance.Checked = false
-		attendance.Overtime = 0
+		attendance.SetOvertime(0)
 	} else if checkedOutTime.After(regularCheckOutStart) {
 		// Checking out around 17:00 or later
 		switch attendance.Status {
@@ -744,7 +670,7 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 			// Halfday status: just update checkout time, no overtime
 			attendance.CheckedOut = &checkedOutTime
 			attendance.Checked = false
-			attendance.Overtime = 0
+			attendance.SetOvertime(0)
 		case "fullday":
 			// Fullday status: update checkout and calculate overtime if after 17:00
 			attendance.CheckedOut = &checkedOutTime
@@ -753,7 +679,7 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 			if checkedOutTime.After(regularCheckOut) {
 				overtime = int(checkedOutTime.Sub(regularCheckOut).Minutes())
 			}
-			attendance.Overtime = overtime
+			attendance.SetOvertime(overtime)
 		}
 	} else {
 		// Not within valid checkout windows
@@ -768,8 +694,23 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 		})
 	}
 
-	// Update attendance record
-	if err := mac.DB.Save(&attendance).Error; err != nil {
+	// Keep the highest fraud score between check-in and check-out for HR review
+	if signals.IsMockLocation {
+		attendance.IsMockLocation = true
+	}
+	if assessment.Score > attendance.FraudScore {
+		attendance.FraudScore = assessment.Score
+		attendance.FraudReasons = strings.Join(assessment.Reasons, "; ")
+		attendance.Suspicious = assessment.Suspicious()
+	}
+
+	// Update attendance record, ending the break still in progress at the checkout
+	if err := mac.DB.WithContext(c.Context()).Transaction(func(tx *gorm.DB) error {
+		if err := mac.Breaks.CloseOpenBreak(tx, &attendance, checkedOutTime); err != nil {
+			return err
+		}
+		return tx.Save(&attendance).Error
+	}); err != nil {
 		log.Println("Failed to update attendance record:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -792,3 +733,176 @@ func (mac *MobileAttendanceController) MobileCheckOutUserByFace(c fiber.Ctx) err
 		},
 	})
 }
+
+// GetNearestLocation suggests the registered location of the coordinates
+// @Summary Get Nearest Location
+// @Description Retrieve the nearest active location whose geofence contains the coordinates, the location check-ins and checkouts are recorded at when sent without a location ID
+// @Tags Mobile Attendances
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param latitude query number true "Latitude of the device"
+// @Param longitude query number true "Longitude of the device"
+// @Success 200 {object} utils.SuccessResponse{data=NearestLocationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-attendances/locations/nearest [get]
+func (mac *MobileAttendanceController) GetNearestLocation(c fiber.Ctx) error {
+	log.Println("GetNearestLocation called")
+
+	latitude, err := strconv.ParseFloat(c.Query("latitude"), 64)
+	if err != nil {
+		log.Println("GetNearestLocation - Invalid latitude:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid latitude format",
+		})
+	}
+	longitude, err := strconv.ParseFloat(c.Query("longitude"), 64)
+	if err != nil {
+		log.Println("GetNearestLocation - Invalid longitude:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid longitude format",
+		})
+	}
+	if err := utils.ValidateCoordinates(latitude, longitude); err != nil {
+		log.Println("GetNearestLocation - Invalid coordinates:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	location, err := mac.resolveAttendanceLocation(c.Context(), "", latitude, longitude)
+	if err != nil {
+		log.Println("GetNearestLocation - Failed to resolve location:", err)
+		return attendanceLocationErrorResponse(c, err)
+	}
+	_, distance := utils.WithinGeofence(location, latitude, longitude)
+
+	log.Println("GetNearestLocation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Nearest location is " + location.Name,
+		Data: NearestLocationResponse{
+			Location: location.ToResponse(),
+			Distance: distance,
+		},
+	})
+}
+
+// parseDeviceSignals reads the anti-fraud signals reported by the mobile device
+func (mac *MobileAttendanceController) parseDeviceSignals(c fiber.Ctx, userID uint) utils.DeviceSignals {
+	isMockLocation, _ := strconv.ParseBool(c.FormValue("is_mock_location"))
+	return utils.DeviceSignals{
+		IsMockLocation:  isMockLocation,
+		DeviceID:        strings.TrimSpace(c.FormValue("device_id")),
+		OSBuild:         strings.TrimSpace(c.FormValue("os_build")),
+		ClockSkewReason: mac.clockSkewReason(c, userID),
+	}
+}
+
+// clockSkewReason checks the optional device timestamp of a check against the server time
+// The check itself is always recorded at server time, a skewed device clock only adds to the fraud score
+func (mac *MobileAttendanceController) clockSkewReason(c fiber.Ctx, userID uint) string {
+	clientTimestamp := strings.TrimSpace(c.FormValue("client_timestamp"))
+	if clientTimestamp == "" {
+		return ""
+	}
+	clientTime, err := time.Parse(time.RFC3339, clientTimestamp)
+	if err != nil {
+		return "Client timestamp is not RFC3339"
+	}
+
+	var syncedAt *time.Time
+	if timeToken := strings.TrimSpace(c.FormValue("time_token")); timeToken != "" {
+		issuedAt, err := mac.ClockSkew.ParseTimeToken(timeToken, strconv.FormatUint(uint64(userID), 10))
+		if err != nil {
+			return "Invalid time token: " + err.Error()
+		}
+		syncedAt = &issuedAt
+	}
+	return mac.ClockSkew.CheckClientTimestamp(clientTime, time.Now(), syncedAt)
+}
+
+// assessFraud calculates the fraud score of a check against the user's recent attendance records
+func (mac *MobileAttendanceController) assessFraud(ctx context.Context, userID uint, latitude, longitude, accuracy float64, signals utils.DeviceSignals) utils.FraudAssessment {
+	ctx, span := utils.StartSpan(ctx, "attendance.assess_fraud", attribute.Int("user.id", int(userID)))
+	defer span.End()
+
+	var recentAttendances []models.Attendance
+	mac.DB.WithContext(ctx).Where("user_id = ?", userID).
+		Order("checked_in DESC").
+		Limit(5).
+		Find(&recentAttendances)
+
+	previous := make([]utils.PreviousCheck, len(recentAttendances))
+	for i, att := range recentAttendances {
+		previous[i] = utils.PreviousCheck{
+			Latitude:  att.Latitude,
+			Longitude: att.Longitude,
+			Accuracy:  att.Accuracy,
+			DeviceID:  att.DeviceID,
+			CheckedAt: att.CheckedIn,
+		}
+	}
+
+	assessment := utils.CalculateFraudScore(latitude, longitude, accuracy, signals, previous)
+	span.SetAttributes(attribute.Int("attendance.fraud_score", assessment.Score))
+	return assessment
+}
+
+// attendanceLocationError is a location a check cannot be recorded at, with the status it is answered with
+type attendanceLocationError struct {
+	Status  int
+	Message string
+}
+
+func (e *attendanceLocationError) Error() string {
+	return e.Message
+}
+
+// attendanceLocationErrorResponse answers a failed location lookup with the status of the error
+func attendanceLocationErrorResponse(c fiber.Ctx, err error) error {
+	status, message := fiber.StatusInternalServerError, "Failed to retrieve locations"
+	var locationErr *attendanceLocationError
+	if errors.As(err, &locationErr) {
+		status, message = locationErr.Status, locationErr.Message
+	}
+	return c.Status(status).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// resolveAttendanceLocation loads the active location of a check. Without a location ID the location is inferred
+// as the nearest active location whose geofence contains the coordinates.
+func (mac *MobileAttendanceController) resolveAttendanceLocation(ctx context.Context, locationIDStr string, latitude, longitude float64) (*models.Location, error) {
+	locationIDStr = strings.TrimSpace(locationIDStr)
+	if locationIDStr == "" {
+		var locations []models.Location
+		if err := mac.DB.WithContext(ctx).Where("is_active = ? AND deleted_at IS NULL", true).Find(&locations).Error; err != nil {
+			return nil, err
+		}
+		location, _ := utils.NearestGeofenceLocation(locations, latitude, longitude)
+		if location == nil {
+			return nil, &attendanceLocationError{fiber.StatusBadRequest, "No registered location found within range of your position"}
+		}
+		return location, nil
+	}
+
+	locationID, err := strconv.Atoi(locationIDStr)
+	if err != nil {
+		return nil, &attendanceLocationError{fiber.StatusBadRequest, "Invalid Location ID"}
+	}
+	var location models.Location
+	if err := mac.DB.WithContext(ctx).Where("id = ? AND deleted_at IS NULL", locationID).First(&location).Error; err != nil {
+		return nil, &attendanceLocationError{fiber.StatusNotFound, "Location not found"}
+	}
+	if !location.IsActive {
+		return nil, &attendanceLocationError{fiber.StatusBadRequest, "Location " + location.Name + " is inactive"}
+	}
+	return &location, nil
+}
@@ -2,34 +2,73 @@ package controllers
 
 import (
 	"fmt"
+	"livo-fiber-backend/config"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
+	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type MobileOrderController struct {
 	DB *gorm.DB
+	// Only assign pickers checked in at the order's warehouse location
+	RequirePickerAttendance bool
+	// Orders a picker may have in picking progress before assignments need an override, 0 disables the quota
+	PickerMaxOpenOrders int
+	// Validates client timestamps of offline actions
+	ClockSkew utils.ClockSkewPolicy
+	// Flags anomalously fast or slow item picks of completed orders
+	PickAnomaly utils.PickAnomalyPolicy
 }
 
-func NewMobileOrderController(db *gorm.DB) *MobileOrderController {
-	return &MobileOrderController{DB: db}
+func NewMobileOrderController(cfg *config.Config, db *gorm.DB) *MobileOrderController {
+	return &MobileOrderController{DB: db, RequirePickerAttendance: cfg.PickerAttendanceRequired, PickerMaxOpenOrders: cfg.PickerMaxOpenOrders, ClockSkew: utils.ClockSkewPolicyFromConfig(cfg), PickAnomaly: utils.PickAnomalyPolicyFromConfig(cfg)}
 }
 
 // Request structs
 type MobileBulkAssignPickerRequest struct {
 	PickerID        uint     `json:"pickerId" validate:"required"`
 	TrackingNumbers []string `json:"trackingNumbers" validate:"required"`
+	OverrideQuota   bool     `json:"overrideQuota"` // assign past the maximum of orders in picking progress of the picker
 }
 
 type PendingPickRequest struct {
-	Username string `json:"username" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	ApprovalCode string `json:"approvalCode"` // one-time code from the coordinator, replaces username and password
+}
+
+type PickOrderItemRequest struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity int    `json:"quantity"` // defaults to 1 when omitted
+}
+
+type DeclareShortageRequest struct {
+	SKU    string `json:"sku" validate:"required"`
+	Reason string `json:"reason" validate:"required"`
+}
+
+type OfflineSyncRequest struct {
+	Actions []OfflineSyncActionRequest `json:"actions" validate:"required"`
+}
+
+type OfflineSyncActionRequest struct {
+	ClientActionID  string `json:"clientActionId" validate:"required"` // UUID generated by the mobile app
+	Action          string `json:"action" validate:"required"`         // complete_pick or pending_pick
+	OrderID         uint   `json:"orderId" validate:"required"`
+	ClientTimestamp string `json:"clientTimestamp" validate:"required"` // RFC3339
+	Username        string `json:"username,omitempty"`                  // coordinator credentials, pending_pick only
+	Password        string `json:"password,omitempty"`
+	ApprovalCode    string `json:"approvalCode,omitempty"` // one-time approval code, replaces the credentials
+	TimeToken       string `json:"timeToken,omitempty"`    // last token from /api/time/token before the action was captured
 }
 
 // Unique response structs
@@ -41,10 +80,11 @@ type MobileBulkAssignPickerResponse struct {
 }
 
 type BulkAssignSummary struct {
-	Total    int `json:"total"`
-	Assigned int `json:"assigned"`
-	Skipped  int `json:"skipped"`
-	Failed   int `json:"failed"`
+	Total             int  `json:"total"`
+	Assigned          int  `json:"assigned"`
+	Skipped           int  `json:"skipped"`
+	Failed            int  `json:"failed"`
+	RemainingCapacity *int `json:"remainingCapacity"` // orders the picker can still be assigned under the quota, null without a quota
 }
 
 type SkippedAssignment struct {
@@ -59,6 +99,56 @@ type FailedAssignment struct {
 	Error          string `json:"error"`
 }
 
+type OfflineSyncResponse struct {
+	Summary OfflineSyncSummary  `json:"summary"`
+	Results []OfflineSyncResult `json:"results"`
+}
+
+type OfflineSyncSummary struct {
+	Total     int `json:"total"`
+	Applied   int `json:"applied"`
+	Duplicate int `json:"duplicate"`
+	Conflict  int `json:"conflict"`
+	Rejected  int `json:"rejected"`
+	Flagged   int `json:"flagged"` // actions with an implausible client timestamp
+}
+
+type OfflineSyncResult struct {
+	ClientActionID  string `json:"clientActionId"`
+	Action          string `json:"action"`
+	OrderID         uint   `json:"orderId"`
+	Status          string `json:"status"` // applied, duplicate, conflict or rejected
+	Message         string `json:"message"`
+	Duplicate       bool   `json:"duplicate"`
+	ClockSkewFlag   bool   `json:"clockSkewFlag"`
+	ClockSkewReason string `json:"clockSkewReason,omitempty"`
+}
+
+// PickerStatsResponse represents the picking stats of the authenticated picker
+type PickerStatsResponse struct {
+	TodayOrders           int64                    `json:"todayOrders"`
+	TodayItems            int64                    `json:"todayItems"`
+	WeekOrders            int64                    `json:"weekOrders"`
+	WeekItems             int64                    `json:"weekItems"`
+	AveragePickSeconds    float64                  `json:"averagePickSeconds"` // assignment to completion over the last 7 days
+	CurrentStreakDays     int                      `json:"currentStreakDays"`  // consecutive days with at least one picked order
+	LongestStreakDays     int                      `json:"longestStreakDays"`  // within the last 90 days
+	Rank                  int                      `json:"rank"`               // today's rank among checked in pickers, 0 when not ranked
+	CheckedInPickers      int                      `json:"checkedInPickers"`
+	Leaderboard           []PickerLeaderboardEntry `json:"leaderboard"`
+	WeekStartsAt          string                   `json:"weekStartsAt"`
+	StreakWindowStartedAt string                   `json:"streakWindowStartedAt"`
+}
+
+// PickerLeaderboardEntry represents a checked in picker on today's leaderboard
+type PickerLeaderboardEntry struct {
+	Rank     int    `json:"rank"`
+	UserID   uint   `json:"userId"`
+	FullName string `json:"fullName"`
+	Orders   int64  `json:"orders"`
+	IsMe     bool   `json:"isMe"`
+}
+
 // GetMyPickingOrders retrieves all orders assigned to a picker
 // @Summary Get My Picking Orders
 // @Description Retrieve all orders assigned to a picker
@@ -85,23 +175,9 @@ func (moc *MobileOrderController) GetMyPickingOrders(c fiber.Ctx) error {
 		})
 	}
 
-	// Base query to get orders assigned to the picker
-	query := moc.DB.Model(&models.Order{}).Preload("OrderDetails").Preload("PickUser").Preload("AssignUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").
-		Where("picked_by = ? AND processing_status = ?", userID, "picking_progress").Order("created_at DESC").Find(&orders)
-
-	// Get total count
-	var total int64
-	query.Count(&total)
-
-	// Load product details in order responses
-	for i := range orders {
-		for j := range orders[i].OrderDetails {
-			var product models.Product
-			if err := moc.DB.Where("sku = ?", orders[i].OrderDetails[j].SKU).First(&product).Error; err == nil {
-				orders[i].OrderDetails[j].Product = &product
-			}
-		}
-	}
+	// Orders assigned to the picker with their product details
+	orders = moc.loadMyPickingOrders(uint(userID), utils.RequestLanguage(c))
+	total := int64(len(orders))
 
 	// Include product details in order responses
 	orderResponses := make([]models.OrderResponse, len(orders))
@@ -119,6 +195,109 @@ func (moc *MobileOrderController) GetMyPickingOrders(c fiber.Ctx) error {
 	})
 }
 
+// PrintMyPickingOrders renders the picker's queue as a printable sheet
+// @Summary Print My Picking Orders
+// @Description Render the orders assigned to the picker as a compact printable picking sheet, from the same queue as /api/mobile-orders/my-picking-orders. Every order is printed with a Code 128 barcode of its tracking number followed by the items still to pick with their rack location, as a fallback when the picker's device is unavailable
+// @Tags Mobile Orders
+// @Produce application/pdf
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-picking-orders/print [get]
+func (moc *MobileOrderController) PrintMyPickingOrders(c fiber.Ctx) error {
+	log.Println("PrintMyPickingOrders called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("PrintMyPickingOrders - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var picker models.User
+	moc.DB.Select("id", "username", "full_name").Where("id = ?", userID).First(&picker)
+	orders := moc.loadMyPickingOrders(uint(userID), utils.RequestLanguage(c))
+
+	buffer, err := utils.BuildTextPDF([]utils.PDFDocument{pickingSheetDocument(&picker, orders, time.Now())})
+	if err != nil {
+		log.Println("PrintMyPickingOrders - Failed to render PDF:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to render picking sheet",
+		})
+	}
+
+	log.Println("PrintMyPickingOrders completed successfully")
+	c.Set(fiber.HeaderContentType, utils.PDFContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"picking-sheet-%s-%s.pdf\"", picker.Username, time.Now().Format("20060102-150405")))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// loadMyPickingOrders returns the orders the picker is picking, latest first, with their products, lot suggestions
+// and curated product names
+func (moc *MobileOrderController) loadMyPickingOrders(userID uint, language string) []models.Order {
+	var orders []models.Order
+	moc.DB.Model(&models.Order{}).Preload("OrderDetails").Preload("PickUser").Preload("AssignUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").
+		Where("picked_by = ? AND processing_status = ?", userID, "picking_progress").Order(models.OrderQueueOrder).Find(&orders)
+
+	// Load product details in order responses
+	for i := range orders {
+		for j := range orders[i].OrderDetails {
+			var product models.Product
+			if err := moc.DB.Where("sku = ?", orders[i].OrderDetails[j].SKU).First(&product).Error; err == nil {
+				orders[i].OrderDetails[j].Product = &product
+			}
+		}
+		moc.loadBatchPicks(orders[i].OrderDetails)
+		utils.ApplyProductNames(moc.DB, language, orders[i].OrderDetails)
+	}
+	return orders
+}
+
+// pickingSheetDocument lays out the printed picking sheet of a picker, one block per order with the barcode of its
+// tracking number and the items left to pick
+func pickingSheetDocument(picker *models.User, orders []models.Order, printedAt time.Time) utils.PDFDocument {
+	document := utils.PDFDocument{Lines: []string{
+		"PICKING SHEET " + picker.FullName,
+		fmt.Sprintf("Orders: %d   Printed: %s", len(orders), printedAt.Format("02-01-2006 15:04:05")),
+		"",
+	}}
+	if len(orders) == 0 {
+		document.Lines = append(document.Lines, "No orders to pick.")
+		return document
+	}
+
+	for i, order := range orders {
+		// Header lines stay short so they do not run under the barcode
+		lines := []string{
+			fmt.Sprintf("%.55s", fmt.Sprintf("%d. %s", i+1, order.TrackingNumber)),
+			fmt.Sprintf("%.55s", fmt.Sprintf("   Order: %s   %s", order.OrderGineeID, order.Store)),
+			fmt.Sprintf("%.55s", fmt.Sprintf("   %s   Send before: %s", order.Courier, order.SentBefore.Format("02-01 15:04"))),
+			fmt.Sprintf("   %-12s %-24s %5s  %s", "LOCATION", "SKU", "QTY", "PRODUCT"),
+		}
+		for _, detail := range order.OrderDetails {
+			remaining := detail.Quantity - detail.PickedQuantity
+			if detail.IsPicked || detail.IsShortage || remaining <= 0 {
+				continue
+			}
+			location := ""
+			if detail.Product != nil {
+				location = detail.Product.Location
+			}
+			lines = append(lines, fmt.Sprintf("   %-12s %-24s %5d  %s", pickLocationLabel(location), detail.SKU, remaining, pickProductLabel(detail.DisplayName, detail.DisplayVariant)))
+			for _, batchPick := range detail.BatchPicks {
+				lines = append(lines, fmt.Sprintf("   %-12s   lot %-20s %5d", "", batchPick.Batch.LotNumber, batchPick.Quantity))
+			}
+		}
+		lines = append(lines, "")
+		document.AppendBlock(lines, order.TrackingNumber)
+	}
+	return document
+}
+
 // GetMyPickingOrder retrieves a specific order assigned to a picker
 // @Summary Get My Picking Order
 // @Description Retrieve a specific order assigned to a picker
@@ -165,6 +344,8 @@ func (moc *MobileOrderController) GetMyPickingOrder(c fiber.Ctx) error {
 			order.OrderDetails[i].Product = &product
 		}
 	}
+	moc.loadBatchPicks(order.OrderDetails)
+	utils.ApplyProductNames(moc.DB, utils.RequestLanguage(c), order.OrderDetails)
 
 	log.Println("GetMyPickingOrder completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
@@ -211,6 +392,32 @@ func (moc *MobileOrderController) CompletePickingOrder(c fiber.Ctx) error {
 		})
 	}
 
+	// Every item must be confirmed on the checklist or declared as shortage
+	var details []models.OrderDetail
+	if err := moc.DB.Where("order_id = ?", order.ID).Find(&details).Error; err != nil {
+		log.Println("CompletePickingOrder - Failed to load order details:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load order details: " + err.Error(),
+		})
+	}
+	if unconfirmed := unconfirmedPickItems(details); len(unconfirmed) > 0 {
+		log.Println("CompletePickingOrder - Items not confirmed:", unconfirmed)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Items not yet confirmed: " + strings.Join(unconfirmed, ", "),
+		})
+	}
+
+	// Orders on a pick cart proceed to QC through the sortation step
+	if batchCode := activePickBatchCode(moc.DB, order.ID); batchCode != "" {
+		log.Println("CompletePickingOrder - Order is on pick batch:", batchCode)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order is on pick batch " + batchCode + ", confirm its cart slot sortation instead",
+		})
+	}
+
 	// Start transaction
 	tx := moc.DB.Begin()
 	defer func() {
@@ -259,43 +466,381 @@ func (moc *MobileOrderController) CompletePickingOrder(c fiber.Ctx) error {
 		})
 	}
 
+	// Flag anomalously fast or slow items for the coordinators, analytics only so it never fails the pick
+	if _, err := utils.RecordPickAnomalies(moc.DB, moc.PickAnomaly, order.ID); err != nil {
+		log.Println("CompletePickingOrder - Failed to record pick anomalies:", err)
+	}
+
 	// Reload order with updated data
 	if err := moc.DB.Preload("OrderDetails").Preload("PickUser").Preload("AssignUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").
 		Where("id = ?", order.ID).First(&order).Where("picked_by = ?", userID).Error; err != nil {
 		log.Println("CompletePickingOrder - Failed to reload order data:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to reload order data: " + err.Error(),
+			Error:   "Failed to reload order data: " + err.Error(),
+		})
+	}
+
+	// load product details in order response
+	for i := range order.OrderDetails {
+		var product models.Product
+		if err := moc.DB.Where("sku = ?", order.OrderDetails[i].SKU).First(&product).Error; err == nil {
+			order.OrderDetails[i].Product = &product
+		}
+	}
+
+	log.Println("CompletePickingOrder completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order marked as picked successfully",
+		Data:    order.ToOrderResponse(),
+	})
+}
+
+// PickOrderItem confirms a scanned item on the picking checklist of an order
+// @Summary Pick Order Item
+// @Description Confirm a scanned SKU and quantity on the picking checklist of an order assigned to the picker
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body PickOrderItemRequest true "Scanned SKU and quantity"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-picking-order/{id}/items/pick [put]
+func (moc *MobileOrderController) PickOrderItem(c fiber.Ctx) error {
+	log.Println("PickOrderItem called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("PickOrderItem - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req PickOrderItemRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("PickOrderItem - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	if req.SKU == "" {
+		log.Println("PickOrderItem - SKU is required")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU is required",
+		})
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		log.Println("PickOrderItem - Invalid quantity:", req.Quantity)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Quantity must be greater than 0",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := moc.DB.Preload("OrderDetails").Where("id = ?", id).Where("picked_by = ?", userID).First(&order).Error; err != nil {
+		log.Println("PickOrderItem - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	if order.ProcessingStatus != "picking_progress" {
+		log.Println("PickOrderItem - Order not in picking progress status")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order not in picking progress status",
+		})
+	}
+
+	// Find the first checklist item for this SKU that still needs picking
+	var detail *models.OrderDetail
+	skuFound := false
+	for i := range order.OrderDetails {
+		if !strings.EqualFold(order.OrderDetails[i].SKU, req.SKU) {
+			continue
+		}
+		skuFound = true
+		if !order.OrderDetails[i].IsPicked {
+			detail = &order.OrderDetails[i]
+			break
+		}
+	}
+
+	if !skuFound {
+		log.Println("PickOrderItem - SKU not in order:", req.SKU)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU " + req.SKU + " is not part of this order",
+		})
+	}
+	if detail == nil {
+		log.Println("PickOrderItem - SKU already fully picked:", req.SKU)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU " + req.SKU + " is already fully picked",
+		})
+	}
+
+	remaining := detail.Quantity - detail.PickedQuantity
+	if req.Quantity > remaining {
+		log.Println("PickOrderItem - Quantity exceeds remaining:", req.Quantity, remaining)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Quantity exceeds remaining quantity for SKU %s (%d remaining)", req.SKU, remaining),
+		})
+	}
+
+	// Update checklist item
+	now := time.Now()
+	detail.PickedQuantity += req.Quantity
+	detail.ItemPickedAt = &now
+	detail.IsPicked = detail.PickedQuantity >= detail.Quantity
+
+	if err := moc.DB.Model(&models.OrderDetail{}).Where("id = ?", detail.ID).Updates(map[string]interface{}{
+		"picked_quantity": detail.PickedQuantity,
+		"is_picked":       detail.IsPicked,
+		"item_picked_at":  detail.ItemPickedAt,
+	}).Error; err != nil {
+		log.Println("PickOrderItem - Failed to update order detail:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update order detail: " + err.Error(),
+		})
+	}
+
+	// Record the zone the pick was made in, for warehouse analytics only so it never fails the pick
+	if err := utils.RecordPickZoneVisits(moc.DB, uint(userID), nil, []utils.ZonePick{{OrderID: order.ID, SKU: detail.SKU, Quantity: req.Quantity}}); err != nil {
+		log.Println("PickOrderItem - Failed to record pick zone visit:", err)
+	}
+
+	// Reload order with updated data
+	if err := moc.DB.Preload("OrderDetails").Preload("PickUser").Preload("AssignUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").
+		Where("id = ?", order.ID).First(&order).Error; err != nil {
+		log.Println("PickOrderItem - Failed to reload order data:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload order data: " + err.Error(),
+		})
+	}
+
+	// load product details in order response
+	for i := range order.OrderDetails {
+		var product models.Product
+		if err := moc.DB.Where("sku = ?", order.OrderDetails[i].SKU).First(&product).Error; err == nil {
+			order.OrderDetails[i].Product = &product
+		}
+	}
+	utils.ApplyProductNames(moc.DB, utils.RequestLanguage(c), order.OrderDetails)
+
+	message := fmt.Sprintf("Picked %d of %d for SKU %s", detail.PickedQuantity, detail.Quantity, detail.SKU)
+	if len(unconfirmedPickItems(order.OrderDetails)) == 0 {
+		message += ", all items confirmed"
+	}
+
+	log.Println("PickOrderItem completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    order.ToOrderResponse(),
+	})
+}
+
+// DeclareShortage declares an item of an order as out of stock during picking
+// @Summary Declare Shortage
+// @Description Declare an item unavailable while picking. The order moves to awaiting_stock and coordinators are notified.
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body DeclareShortageRequest true "Short SKU and reason"
+// @Success 201 {object} utils.SuccessResponse{data=models.PickShortageResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-picking-order/{id}/shortage [post]
+func (moc *MobileOrderController) DeclareShortage(c fiber.Ctx) error {
+	log.Println("DeclareShortage called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("DeclareShortage - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req DeclareShortageRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("DeclareShortage - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.SKU == "" || req.Reason == "" {
+		log.Println("DeclareShortage - SKU and reason are required")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU and reason are required",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := moc.DB.Preload("OrderDetails").Where("id = ?", id).Where("picked_by = ?", userID).First(&order).Error; err != nil {
+		log.Println("DeclareShortage - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	if order.ProcessingStatus != "picking_progress" {
+		log.Println("DeclareShortage - Order not in picking progress status")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order not in picking progress status",
+		})
+	}
+
+	// Find the checklist item that cannot be fulfilled
+	var detail *models.OrderDetail
+	for i := range order.OrderDetails {
+		if strings.EqualFold(order.OrderDetails[i].SKU, req.SKU) && !order.OrderDetails[i].IsPicked && !order.OrderDetails[i].IsShortage {
+			detail = &order.OrderDetails[i]
+			break
+		}
+	}
+
+	if detail == nil {
+		log.Println("DeclareShortage - No open item for SKU:", req.SKU)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU " + req.SKU + " has no unpicked item in this order",
+		})
+	}
+
+	shortage := models.PickShortage{
+		OrderID:          order.ID,
+		OrderDetailID:    detail.ID,
+		TrackingNumber:   order.TrackingNumber,
+		SKU:              detail.SKU,
+		RequiredQuantity: detail.Quantity,
+		PickedQuantity:   detail.PickedQuantity,
+		Reason:           req.Reason,
+		Status:           "open",
+		DeclaredBy:       uint(userID),
+	}
+
+	if err := moc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.OrderDetail{}).Where("id = ?", detail.ID).Updates(map[string]interface{}{
+			"is_shortage":     true,
+			"shortage_reason": req.Reason,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Update("processing_status", "awaiting_stock").Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&shortage).Error; err != nil {
+			return err
+		}
+
+		return utils.NotifyRoles(tx, utils.CoordinatorApprovalRoles, "pick_shortage",
+			"Stock shortage on "+order.TrackingNumber,
+			fmt.Sprintf("SKU %s is short by %d for order %s: %s", shortage.SKU, shortage.RequiredQuantity-shortage.PickedQuantity, order.TrackingNumber, req.Reason),
+			"pick_shortage", shortage.ID)
+	}); err != nil {
+		log.Println("DeclareShortage - Failed to declare shortage:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to declare shortage: " + err.Error(),
 		})
 	}
 
-	// load product details in order response
-	for i := range order.OrderDetails {
-		var product models.Product
-		if err := moc.DB.Where("sku = ?", order.OrderDetails[i].SKU).First(&product).Error; err == nil {
-			order.OrderDetails[i].Product = &product
-		}
+	// Reload shortage with relations
+	if err := moc.DB.Preload("DeclareUser").Where("id = ?", shortage.ID).First(&shortage).Error; err != nil {
+		log.Println("DeclareShortage - Failed to reload shortage:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload shortage: " + err.Error(),
+		})
 	}
 
-	log.Println("CompletePickingOrder completed successfully")
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+	log.Println("DeclareShortage completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
 		Success: true,
-		Message: "Order marked as picked successfully",
-		Data:    order.ToOrderResponse(),
+		Message: "Shortage declared, order is awaiting stock",
+		Data:    shortage.ToResponse(),
 	})
 }
 
+// loadBatchPicks attaches FEFO lot suggestions for the quantity still to pick of each detail
+func (moc *MobileOrderController) loadBatchPicks(details []models.OrderDetail) {
+	for i := range details {
+		remaining := details[i].Quantity - details[i].PickedQuantity
+		if details[i].IsPicked || details[i].IsShortage || remaining <= 0 {
+			continue
+		}
+		details[i].BatchPicks = utils.SuggestBatchesFEFO(moc.DB, details[i].SKU, remaining)
+	}
+}
+
+// unconfirmedPickItems returns the SKUs on the picking checklist that are neither picked nor declared as shortage
+func unconfirmedPickItems(details []models.OrderDetail) []string {
+	var unconfirmed []string
+	for _, detail := range details {
+		if !detail.IsPicked && !detail.IsShortage {
+			unconfirmed = append(unconfirmed, detail.SKU)
+		}
+	}
+	return unconfirmed
+}
+
 // PendingPickOrder marks an order as pending pick by the picker
 // @Summary Pending Pick Order
-// @Description Mark an order as pending pick by the picker
+// @Description Mark an order as pending pick by the picker, approved with a one-time approval code, coordinator credentials or by a picker holding an active approval delegation
 // @Tags Mobile Orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Order ID"
-// @Param request body PendingPickRequest true "Pending pick request with coordinator credentials"
+// @Param request body PendingPickRequest true "Pending pick request with an approval code, or coordinator or delegate credentials"
 // @Success 200 {object} utils.SuccessResponse{data=models.OrderResponse}
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/mobile-orders/my-picking-order/{id}/pending [put]
@@ -331,52 +876,55 @@ func (moc *MobileOrderController) PendingPickOrder(c fiber.Ctx) error {
 		})
 	}
 
-	// Authenticate coordinator credentials
-	var coordinator models.User
-	if err := moc.DB.Preload("Roles").Where("username = ?", req.Username).First(&coordinator).Error; err != nil {
-		log.Println("PendingPickOrder - Invalid coordinator credentials:", err)
-		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Invalid coordinator credentials",
-		})
-	}
-
-	// Check password
-	if !utils.CheckPasswordHash(req.Password, coordinator.Password) {
-		log.Println("PendingPickOrder - Invalid coordinator password")
+	// Get current logged in picker from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("PendingPickOrder - Invalid user ID:", err)
 		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid coordinator credentials",
+			Error:   "Invalid user ID",
 		})
 	}
 
-	// Check if user has developer, coordinator or superadmin role
-	hasCoordinatorRole := false
-	for _, role := range coordinator.Roles {
-		if role.RoleName == "developer" || role.RoleName == "coordinator" || role.RoleName == "superadmin" {
-			hasCoordinatorRole = true
-			break
+	// Approve with a one-time code, coordinator credentials, or a delegate acting for an absent coordinator.
+	// The approval is checked in the same transaction so a code is not used up when the update fails.
+	now := time.Now()
+	err = moc.DB.Transaction(func(tx *gorm.DB) error {
+		grant, err := utils.AuthorizeApproval(tx, req.Username, req.Password, strings.TrimSpace(req.ApprovalCode), "pending_pick", uint(userID), utils.CoordinatorApprovalRoles)
+		if err != nil {
+			return err
 		}
-	}
 
-	if !hasCoordinatorRole {
+		// Update order status to pending pick, the approver is recorded with the delegator or code they approved through
+		order.PendingBy = &grant.Approver.ID
+		order.PendingAt = &now
+		order.ProcessingStatus = "picking_pending"
+		if err := tx.Save(&order).Error; err != nil {
+			return err
+		}
+		return utils.RecordApproval(tx, "pending_pick", "order", order.ID, uint(userID), grant)
+	})
+	switch {
+	case err == utils.ErrApproverNotPermitted:
 		log.Println("PendingPickOrder - User does not have required permissions")
 		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "User does not have required permissions",
 		})
-	}
-
-	// Get current developer, coordinator, or superadmin user ID that credentials belong to
-	coordinatorID := coordinator.ID
-
-	// Update order status to pending pick
-	now := time.Now()
-	order.PendingBy = &coordinatorID
-	order.PendingAt = &now
-	order.ProcessingStatus = "picking_pending"
-
-	if err := moc.DB.Save(&order).Error; err != nil {
+	case err == utils.ErrInvalidApproverCredentials:
+		log.Println("PendingPickOrder - Invalid coordinator credentials")
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid coordinator credentials",
+		})
+	case err == utils.ErrInvalidApprovalCode:
+		log.Println("PendingPickOrder - Invalid approval code")
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid or expired approval code",
+		})
+	case err != nil:
 		log.Println("PendingPickOrder - Failed to update order status:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -421,6 +969,7 @@ func (moc *MobileOrderController) PendingPickOrder(c fiber.Ctx) error {
 // @Success 200 {object} utils.SuccessResponse{data=MobileBulkAssignPickerResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/mobile-orders/bulk-assign-picker [put]
 func (moc *MobileOrderController) BulkAssignPicker(c fiber.Ctx) error {
@@ -456,15 +1005,48 @@ func (moc *MobileOrderController) BulkAssignPicker(c fiber.Ctx) error {
 		})
 	}
 
+	// Coordinators with a team only assign pickers of their teams
+	userRoles, _ := c.Locals("userRoles").([]string)
+	canManage, err := utils.CanManageUser(moc.DB, uint(assignerID), userRoles, picker.ID)
+	if err != nil {
+		log.Println("BulkAssignPicker - Failed to resolve team scope:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to assign picker",
+		})
+	}
+	if !canManage {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Picker " + picker.Username + " is not a member of your team",
+		})
+	}
+
 	var assignedOrders []models.OrderResponse
 	var skippedOrders []SkippedAssignment
 	var failedOrders []FailedAssignment
 
+	// Orders the picker already works, counted up as orders are assigned
+	openOrders, err := utils.PickerOpenOrders(moc.DB, []uint{picker.ID})
+	if err != nil {
+		log.Println("BulkAssignPicker - Failed to count picker orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to assign picker",
+		})
+	}
+	pickerOrders := openOrders[picker.ID]
+	if moc.PickerMaxOpenOrders > 0 && req.OverrideQuota && pickerOrders+len(req.TrackingNumbers) > moc.PickerMaxOpenOrders {
+		log.Printf("BulkAssignPicker - Quota of picker %s overridden by user %d (%d open orders)\n", picker.Username, assignerID, pickerOrders)
+	}
+
 	assignerIDUint := uint(assignerID)
 	now := time.Now()
+	presence := make(map[string]bool) // picker attendance per warehouse location, checked once per location
 
 	// Process each tracking number
 	for i, trackingNumber := range req.TrackingNumbers {
+		trackingNumber = models.NormalizeIdentifier(trackingNumber)
 		var order models.Order
 		// Find order by tracking number
 		if err := moc.DB.Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
@@ -493,6 +1075,14 @@ func (moc *MobileOrderController) BulkAssignPicker(c fiber.Ctx) error {
 			})
 			continue
 		}
+		if order.EventStatus == "held" {
+			skippedOrders = append(skippedOrders, SkippedAssignment{
+				Index:          i,
+				TrackingNumber: trackingNumber,
+				Reason:         "Order is on hold",
+			})
+			continue
+		}
 
 		// Only allow assignment if order is "ready to pick" or "pending picking"
 		if order.ProcessingStatus != "ready_to_pick" && order.ProcessingStatus != "picking_pending" {
@@ -504,6 +1094,65 @@ func (moc *MobileOrderController) BulkAssignPicker(c fiber.Ctx) error {
 			continue
 		}
 
+		// Pickers must be checked in at the warehouse the order is picked at
+		if moc.RequirePickerAttendance {
+			locationID := utils.OrderLocationID(moc.DB, &order)
+			key := "any"
+			if locationID != nil {
+				key = strconv.FormatUint(uint64(*locationID), 10)
+			}
+			present, checked := presence[key]
+			if !checked {
+				var err error
+				if present, err = utils.IsUserPresent(moc.DB, picker.ID, locationID); err != nil {
+					failedOrders = append(failedOrders, FailedAssignment{
+						Index:          i,
+						TrackingNumber: trackingNumber,
+						Error:          "Failed to check picker attendance",
+					})
+					continue
+				}
+				presence[key] = present
+			}
+			if !present {
+				skippedOrders = append(skippedOrders, SkippedAssignment{
+					Index:          i,
+					TrackingNumber: trackingNumber,
+					Reason:         "Picker is not checked in at the warehouse of this order",
+				})
+				continue
+			}
+		}
+
+		// Products such as fragile goods may only be picked by pickers trained for them
+		missingSkills, err := utils.MissingOrderSkills(moc.DB, picker.ID, &order)
+		if err != nil {
+			failedOrders = append(failedOrders, FailedAssignment{
+				Index:          i,
+				TrackingNumber: trackingNumber,
+				Error:          "Failed to check picker skills",
+			})
+			continue
+		}
+		if len(missingSkills) > 0 {
+			skippedOrders = append(skippedOrders, SkippedAssignment{
+				Index:          i,
+				TrackingNumber: trackingNumber,
+				Reason:         "Picker lacks the skills this order requires: " + utils.SkillNames(missingSkills),
+			})
+			continue
+		}
+
+		// Soft quota on the orders a picker works at once, coordinators may knowingly exceed it
+		if moc.PickerMaxOpenOrders > 0 && !req.OverrideQuota && pickerOrders >= moc.PickerMaxOpenOrders {
+			skippedOrders = append(skippedOrders, SkippedAssignment{
+				Index:          i,
+				TrackingNumber: trackingNumber,
+				Reason:         fmt.Sprintf("Picker reached the maximum of %d orders in picking progress", moc.PickerMaxOpenOrders),
+			})
+			continue
+		}
+
 		// Update order with picker assignment
 		order.PickedBy = &req.PickerID
 		order.AssignedAt = &now
@@ -518,6 +1167,7 @@ func (moc *MobileOrderController) BulkAssignPicker(c fiber.Ctx) error {
 			})
 			continue
 		}
+		pickerOrders++
 
 		// Load order details for response
 		if err := moc.DB.Preload("OrderDetails").Preload("PickUser").Preload("AssignUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").
@@ -544,10 +1194,11 @@ func (moc *MobileOrderController) BulkAssignPicker(c fiber.Ctx) error {
 	// Prepare summary
 	response := MobileBulkAssignPickerResponse{
 		Summary: BulkAssignSummary{
-			Total:    len(req.TrackingNumbers),
-			Assigned: len(assignedOrders),
-			Skipped:  len(skippedOrders),
-			Failed:   len(failedOrders),
+			Total:             len(req.TrackingNumbers),
+			Assigned:          len(assignedOrders),
+			Skipped:           len(skippedOrders),
+			Failed:            len(failedOrders),
+			RemainingCapacity: utils.PickerRemainingCapacity(pickerOrders, moc.PickerMaxOpenOrders),
 		},
 		AssignedOrders: assignedOrders,
 		SkippedOrders:  skippedOrders,
@@ -626,8 +1277,8 @@ func (moc *MobileOrderController) GetMobilePickedOrders(c fiber.Ctx) error {
 	var total int64
 	query.Count(&total)
 
-	// Retrieve paginated results
-	query = query.Order("created_at DESC")
+	// Retrieve paginated results, by priority and then by SentBefore
+	query = query.Order(models.OrderQueueOrder)
 	if err := query.Offset(offset).Limit(limit).Find(&pickedOrders).Error; err != nil {
 		log.Println("GetMobilePickedOrders - Failed to retrieve picked orders:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
@@ -687,6 +1338,7 @@ func (moc *MobileOrderController) GetMobilePickedOrders(c fiber.Ctx) error {
 // @Param id path int true "Picked Order ID"
 // @Success 200 {object} utils.SuccessResponse{data=models.OrderResponse}
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/mobile-orders/{id} [get]
@@ -703,6 +1355,26 @@ func (moc *MobileOrderController) GetMobilePickedOrder(c fiber.Ctx) error {
 		})
 	}
 
+	// Coordinators with a team only see the orders picked by their team
+	if pickedOrder.PickedBy != nil {
+		currentUserID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+		userRoles, _ := c.Locals("userRoles").([]string)
+		canManage, err := utils.CanManageUser(moc.DB, uint(currentUserID), userRoles, *pickedOrder.PickedBy)
+		if err != nil {
+			log.Println("GetMobilePickedOrder - Failed to resolve team scope:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve picked order",
+			})
+		}
+		if !canManage {
+			return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Picked order with id " + id + " is picked by a user outside your team.",
+			})
+		}
+	}
+
 	// Load product details in order response
 	for i := range pickedOrder.OrderDetails {
 		var product models.Product
@@ -718,3 +1390,444 @@ func (moc *MobileOrderController) GetMobilePickedOrder(c fiber.Ctx) error {
 		Data:    pickedOrder.ToOrderResponse(),
 	})
 }
+
+// pickerStatsLeaderboardSize is the number of pickers returned on the leaderboard
+const pickerStatsLeaderboardSize = 10
+
+// pickerStatsStreakDays is how far back streaks are computed
+const pickerStatsStreakDays = 90
+
+// GetMyStats retrieves the picking stats and leaderboard rank of the authenticated picker
+// @Summary Get My Picking Stats
+// @Description Retrieve the authenticated picker's daily and weekly picked orders and items, average pick time, picking streaks, and rank on today's leaderboard of checked in pickers
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=PickerStatsResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-stats [get]
+func (moc *MobileOrderController) GetMyStats(c fiber.Ctx) error {
+	log.Println("GetMyStats called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("GetMyStats - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	userIDUint := uint(userID)
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+	// Weeks start on Monday
+	startOfWeek := startOfDay.AddDate(0, 0, -((int(startOfDay.Weekday()) + 6) % 7))
+	streakStart := startOfDay.AddDate(0, 0, -pickerStatsStreakDays+1)
+
+	response := PickerStatsResponse{
+		Leaderboard:           []PickerLeaderboardEntry{},
+		WeekStartsAt:          startOfWeek.Format("02-01-2006"),
+		StreakWindowStartedAt: streakStart.Format("02-01-2006"),
+	}
+
+	// Picked orders and items today and this week
+	type pickedCount struct {
+		Orders int64
+		Items  int64
+	}
+	countPicked := func(from time.Time) (pickedCount, error) {
+		var count pickedCount
+		err := moc.DB.Table("picked_orders").
+			Select("COUNT(DISTINCT picked_orders.order_id) AS orders, COALESCE(SUM(order_details.quantity), 0) AS items").
+			Joins("LEFT JOIN order_details ON order_details.order_id = picked_orders.order_id").
+			Where("picked_orders.picked_by = ? AND picked_orders.created_at >= ? AND picked_orders.created_at < ?", userIDUint, from, endOfDay).
+			Scan(&count).Error
+		return count, err
+	}
+	today, err := countPicked(startOfDay)
+	if err != nil {
+		log.Println("GetMyStats - Failed to count today's picks:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picking stats",
+		})
+	}
+	week, err := countPicked(startOfWeek)
+	if err != nil {
+		log.Println("GetMyStats - Failed to count this week's picks:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picking stats",
+		})
+	}
+	response.TodayOrders, response.TodayItems = today.Orders, today.Items
+	response.WeekOrders, response.WeekItems = week.Orders, week.Items
+
+	// Average time from assignment to completed pick over the last 7 days
+	var averagePick struct {
+		Seconds *float64
+	}
+	if err := moc.DB.Model(&models.Order{}).
+		Select("AVG(EXTRACT(EPOCH FROM (picked_at - assigned_at))) AS seconds").
+		Where("picked_by = ? AND assigned_at IS NOT NULL AND picked_at >= ? AND picked_at > assigned_at", userIDUint, startOfDay.AddDate(0, 0, -6)).
+		Scan(&averagePick).Error; err != nil {
+		log.Println("GetMyStats - Failed to compute average pick time:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picking stats",
+		})
+	}
+	if averagePick.Seconds != nil {
+		response.AveragePickSeconds = math.Round(*averagePick.Seconds*10) / 10
+	}
+
+	// Streaks from the days with at least one picked order
+	var pickedDays []time.Time
+	if err := moc.DB.Table("picked_orders").
+		Select("DISTINCT DATE(created_at) AS day").
+		Where("picked_by = ? AND created_at >= ?", userIDUint, streakStart).
+		Order("day DESC").
+		Pluck("day", &pickedDays).Error; err != nil {
+		log.Println("GetMyStats - Failed to load picking days:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picking stats",
+		})
+	}
+	response.CurrentStreakDays, response.LongestStreakDays = pickingStreaks(pickedDays, startOfDay)
+
+	// Today's leaderboard among pickers checked in today
+	var checkedInPickerIDs []uint
+	if err := moc.DB.Model(&models.Attendance{}).
+		Joins("JOIN user_roles ON user_roles.user_id = attendances.user_id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.role_name = ? AND attendances.checked_in >= ? AND attendances.checked_in < ? AND attendances.checked = ?", "picker", startOfDay, endOfDay, true).
+		Distinct().Pluck("attendances.user_id", &checkedInPickerIDs).Error; err != nil {
+		log.Println("GetMyStats - Failed to load checked in pickers:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picking stats",
+		})
+	}
+	response.CheckedInPickers = len(checkedInPickerIDs)
+
+	if len(checkedInPickerIDs) > 0 {
+		var rows []struct {
+			UserID   uint
+			FullName string
+			Orders   int64
+		}
+		if err := moc.DB.Table("users").
+			Select("users.id AS user_id, users.full_name, COUNT(picked_orders.id) AS orders").
+			Joins("LEFT JOIN picked_orders ON picked_orders.picked_by = users.id AND picked_orders.created_at >= ? AND picked_orders.created_at < ?", startOfDay, endOfDay).
+			Where("users.id IN ?", checkedInPickerIDs).
+			Group("users.id, users.full_name").
+			Order("orders DESC, users.full_name ASC").
+			Scan(&rows).Error; err != nil {
+			log.Println("GetMyStats - Failed to build leaderboard:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve picking stats",
+			})
+		}
+
+		// Pickers with the same count share a rank
+		rank := 0
+		var previousOrders int64 = -1
+		for i, row := range rows {
+			if row.Orders != previousOrders {
+				rank = i + 1
+				previousOrders = row.Orders
+			}
+			if row.UserID == userIDUint {
+				response.Rank = rank
+			}
+			if i < pickerStatsLeaderboardSize || row.UserID == userIDUint {
+				response.Leaderboard = append(response.Leaderboard, PickerLeaderboardEntry{
+					Rank:     rank,
+					UserID:   row.UserID,
+					FullName: row.FullName,
+					Orders:   row.Orders,
+					IsMe:     row.UserID == userIDUint,
+				})
+			}
+		}
+	}
+
+	log.Println("GetMyStats completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Picking stats retrieved successfully",
+		Data:    response,
+	})
+}
+
+// pickingStreaks returns the current and longest runs of consecutive days from days sorted latest first.
+// The current streak stays alive until the end of today, so a picker who picked yesterday keeps it.
+func pickingStreaks(days []time.Time, today time.Time) (int, int) {
+	current, longest, run := 0, 0, 0
+	var previous time.Time
+	for i, day := range days {
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, today.Location())
+		if i > 0 && previous.AddDate(0, 0, -1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > longest {
+			longest = run
+		}
+		// The current streak is the first run, if it reaches today or yesterday
+		if i == 0 && !day.Before(today.AddDate(0, 0, -1)) {
+			current = 1
+		} else if current == i && current > 0 && run == i+1 {
+			current = run
+		}
+		previous = day
+	}
+	return current, longest
+}
+
+// SyncOfflineActions applies a batch of picker actions performed while the mobile app was offline
+// @Summary Sync Offline Actions
+// @Description Apply a batch of offline picker actions (complete_pick, pending_pick). Actions are applied in client timestamp order and are idempotent by clientActionId; re-submitted actions return their original result. Actions whose client timestamp is implausible against the server time (and the optional timeToken) are still applied but flagged and recorded at sync time.
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body OfflineSyncRequest true "Offline actions"
+// @Success 200 {object} utils.SuccessResponse{data=OfflineSyncResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/sync [post]
+func (moc *MobileOrderController) SyncOfflineActions(c fiber.Ctx) error {
+	log.Println("SyncOfflineActions called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("SyncOfflineActions - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req OfflineSyncRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SyncOfflineActions - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if len(req.Actions) == 0 {
+		log.Println("SyncOfflineActions - No actions provided")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one action is required",
+		})
+	}
+
+	// Validate actions and parse client timestamps before applying anything
+	receivedAt := time.Now()
+	timestamps := make([]time.Time, len(req.Actions))
+	skewReasons := make([]string, len(req.Actions))
+	for i, action := range req.Actions {
+		if _, err := uuid.Parse(action.ClientActionID); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Action %d: clientActionId must be a valid UUID", i),
+			})
+		}
+		if action.Action != "complete_pick" && action.Action != "pending_pick" {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Action %d: action must be complete_pick or pending_pick", i),
+			})
+		}
+		ts, err := time.Parse(time.RFC3339, action.ClientTimestamp)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Action %d: clientTimestamp must be RFC3339", i),
+			})
+		}
+		timestamps[i] = ts
+		skewReasons[i] = moc.clockSkewReason(userIDStr, action, ts, receivedAt)
+	}
+
+	// Apply actions in the order they happened on the device
+	sequence := make([]int, len(req.Actions))
+	for i := range sequence {
+		sequence[i] = i
+	}
+	sort.SliceStable(sequence, func(a, b int) bool {
+		return timestamps[sequence[a]].Before(timestamps[sequence[b]])
+	})
+
+	response := OfflineSyncResponse{Results: make([]OfflineSyncResult, 0, len(req.Actions))}
+	for _, i := range sequence {
+		result := moc.applyOfflineSyncAction(uint(userID), req.Actions[i], timestamps[i], skewReasons[i])
+		if result.ClockSkewFlag {
+			response.Summary.Flagged++
+		}
+		switch {
+		case result.Duplicate:
+			response.Summary.Duplicate++
+		case result.Status == "applied":
+			response.Summary.Applied++
+		case result.Status == "conflict":
+			response.Summary.Conflict++
+		default:
+			response.Summary.Rejected++
+		}
+		response.Results = append(response.Results, result)
+	}
+	response.Summary.Total = len(req.Actions)
+
+	log.Println("SyncOfflineActions completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Synced %d actions: %d applied, %d duplicate, %d conflict, %d rejected, %d flagged", response.Summary.Total, response.Summary.Applied, response.Summary.Duplicate, response.Summary.Conflict, response.Summary.Rejected, response.Summary.Flagged),
+		Data:    response,
+	})
+}
+
+// clockSkewReason explains why the client timestamp of an offline action is implausible, empty when it is not
+func (moc *MobileOrderController) clockSkewReason(userID string, action OfflineSyncActionRequest, clientTimestamp, receivedAt time.Time) string {
+	var syncedAt *time.Time
+	if action.TimeToken != "" {
+		issuedAt, err := moc.ClockSkew.ParseTimeToken(action.TimeToken, userID)
+		if err != nil {
+			return "Invalid time token: " + err.Error()
+		}
+		syncedAt = &issuedAt
+	}
+	return moc.ClockSkew.CheckClientTimestamp(clientTimestamp, receivedAt, syncedAt)
+}
+
+// applyOfflineSyncAction applies a single offline action and records its outcome
+// Actions already recorded under the same client action ID return the stored outcome
+func (moc *MobileOrderController) applyOfflineSyncAction(userID uint, action OfflineSyncActionRequest, clientTimestamp time.Time, skewReason string) OfflineSyncResult {
+	result := OfflineSyncResult{
+		ClientActionID:  action.ClientActionID,
+		Action:          action.Action,
+		OrderID:         action.OrderID,
+		ClockSkewFlag:   skewReason != "",
+		ClockSkewReason: skewReason,
+	}
+
+	var existing models.OfflineSyncAction
+	if err := moc.DB.Where("client_action_id = ?", action.ClientActionID).First(&existing).Error; err == nil {
+		result.Action = existing.ActionType
+		result.OrderID = existing.OrderID
+		result.Status = existing.Status
+		result.Message = existing.Message
+		result.Duplicate = true
+		result.ClockSkewFlag = existing.ClockSkewFlag
+		result.ClockSkewReason = existing.ClockSkewReason
+		return result
+	}
+
+	// Client clocks may drift ahead of the server, never record a future time
+	// An implausible client time is not trusted at all, the action is recorded at sync time
+	actionAt := clientTimestamp
+	if now := time.Now(); actionAt.After(now) || skewReason != "" {
+		actionAt = now
+	}
+
+	err := moc.DB.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Where("id = ?", action.OrderID).First(&order).Error; err != nil {
+			result.Status = "rejected"
+			result.Message = fmt.Sprintf("Order with id %d not found", action.OrderID)
+		} else if order.PickedBy == nil || *order.PickedBy != userID {
+			result.Status = "conflict"
+			result.Message = "Order is no longer assigned to this picker"
+		} else if order.ProcessingStatus != "picking_progress" {
+			result.Status = "conflict"
+			result.Message = fmt.Sprintf("Order is already %s", order.ProcessingStatus)
+		} else if order.EventStatus == "canceled" {
+			result.Status = "conflict"
+			result.Message = "Order has been canceled"
+		} else {
+			switch action.Action {
+			case "complete_pick":
+				var details []models.OrderDetail
+				if err := tx.Where("order_id = ?", order.ID).Find(&details).Error; err != nil {
+					return err
+				}
+				if unconfirmed := unconfirmedPickItems(details); len(unconfirmed) > 0 {
+					result.Status = "rejected"
+					result.Message = "Items not yet confirmed: " + strings.Join(unconfirmed, ", ")
+					break
+				}
+				if batchCode := activePickBatchCode(tx, order.ID); batchCode != "" {
+					result.Status = "rejected"
+					result.Message = "Order is on pick batch " + batchCode + ", confirm its cart slot sortation instead"
+					break
+				}
+				order.PickedAt = &actionAt
+				order.ProcessingStatus = "picking_completed"
+				if err := tx.Save(&order).Error; err != nil {
+					return err
+				}
+				if err := tx.Create(&models.PickedOrder{OrderID: order.ID, PickedBy: userID}).Error; err != nil {
+					return err
+				}
+				result.Status = "applied"
+				result.Message = "Order marked as picked"
+			case "pending_pick":
+				grant, err := utils.AuthorizeApproval(tx, action.Username, action.Password, strings.TrimSpace(action.ApprovalCode), "pending_pick", userID, utils.CoordinatorApprovalRoles)
+				if err != nil {
+					result.Status = "rejected"
+					result.Message = err.Error()
+					break
+				}
+				order.PendingBy = &grant.Approver.ID
+				order.PendingAt = &actionAt
+				order.ProcessingStatus = "picking_pending"
+				if err := tx.Save(&order).Error; err != nil {
+					return err
+				}
+				if err := utils.RecordApproval(tx, "pending_pick", "order", order.ID, userID, grant); err != nil {
+					return err
+				}
+				result.Status = "applied"
+				result.Message = "Order marked as pending pick"
+			}
+		}
+
+		return tx.Create(&models.OfflineSyncAction{
+			ClientActionID:  action.ClientActionID,
+			UserID:          userID,
+			ActionType:      action.Action,
+			OrderID:         action.OrderID,
+			ClientTimestamp: clientTimestamp,
+			Status:          result.Status,
+			Message:         result.Message,
+			ClockSkewFlag:   result.ClockSkewFlag,
+			ClockSkewReason: result.ClockSkewReason,
+		}).Error
+	})
+	if err != nil {
+		log.Println("SyncOfflineActions - Failed to apply action", action.ClientActionID, ":", err)
+		// Not recorded, the client may safely retry this action
+		result.Status = "rejected"
+		result.Message = "Failed to apply action: " + err.Error()
+	} else if action.Action == "complete_pick" && result.Status == "applied" {
+		if _, err := utils.RecordPickAnomalies(moc.DB, moc.PickAnomaly, action.OrderID); err != nil {
+			log.Println("SyncOfflineActions - Failed to record pick anomalies for order", action.OrderID, ":", err)
+		}
+	}
+
+	return result
+}
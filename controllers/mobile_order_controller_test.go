@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"encoding/json"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/testutil"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Bulk assignment skips orders with products the picker holds no skill for, like a single assignment does
+func TestBulkAssignPickerSkills(t *testing.T) {
+	tx := testutil.Begin(t, testDB)
+	f := testutil.NewFactory(t, tx)
+	coordinator := f.User("superadmin")
+
+	skill := models.Skill{Code: "TEST-FRAGILE", Name: "Fragile goods"}
+	if err := tx.Create(&skill).Error; err != nil {
+		t.Fatalf("failed to create skill: %v", err)
+	}
+	product := models.Product{SKU: "TEST-FRAGILE-VASE", Name: "Glass Vase", RequiredSkillID: &skill.ID}
+	if err := tx.Create(&product).Error; err != nil {
+		t.Fatalf("failed to create product: %v", err)
+	}
+
+	untrained := f.User("picker")
+	trained := f.User("picker")
+	if err := tx.Create(&models.UserSkill{UserID: trained.ID, SkillID: skill.ID, AssignedBy: coordinator.ID}).Error; err != nil {
+		t.Fatalf("failed to assign skill: %v", err)
+	}
+
+	cfg := lifecycleConfig()
+	mobileOrderController := NewMobileOrderController(cfg, tx)
+
+	tests := []struct {
+		name         string
+		picker       models.User
+		wantAssigned int
+		wantSkipped  bool // whether the fragile order is skipped
+	}{
+		{"picker without the skill gets only the plain order", untrained, 1, true},
+		{"picker with the skill gets both orders", trained, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fragile := f.Order(testutil.WithDetails(models.OrderDetail{SKU: product.SKU, ProductName: product.Name, Quantity: 1, Price: 150000}))
+			plain := f.Order()
+
+			resp, result := callHandler(t, mobileOrderController.BulkAssignPicker, testRequest{
+				Method: fiber.MethodPut, Route: "/api/mobile-orders/bulk-assign-picker", Path: "/api/mobile-orders/bulk-assign-picker",
+				User: coordinator, Roles: []string{"superadmin"},
+				Body: MobileBulkAssignPickerRequest{PickerID: tt.picker.ID, TrackingNumbers: []string{fragile.TrackingNumber, plain.TrackingNumber}},
+			})
+			expectStatus(t, "bulk assign picker", resp, result, fiber.StatusOK)
+
+			var data MobileBulkAssignPickerResponse
+			if err := json.Unmarshal(result.Data, &data); err != nil {
+				t.Fatalf("invalid response data: %v", err)
+			}
+			if data.Summary.Assigned != tt.wantAssigned {
+				t.Errorf("assigned = %d, want %d", data.Summary.Assigned, tt.wantAssigned)
+			}
+			skipped := len(data.SkippedOrders) == 1 && data.SkippedOrders[0].TrackingNumber == fragile.TrackingNumber
+			if skipped != tt.wantSkipped {
+				t.Errorf("skipped orders = %+v, want fragile order skipped %v", data.SkippedOrders, tt.wantSkipped)
+			}
+		})
+	}
+}
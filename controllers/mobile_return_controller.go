@@ -162,6 +162,8 @@ func (mrc *MobileReturnController) CreateMobileReturn(c fiber.Ctx) error {
 		})
 	}
 
+	req.NewTrackingNumber = models.NormalizeIdentifier(req.NewTrackingNumber)
+
 	// Check existing return with same new tracking number
 	var existingReturn models.Return
 	if err := mrc.DB.Where("new_tracking_number = ?", req.NewTrackingNumber).First(&existingReturn).Error; err == nil {
@@ -0,0 +1,277 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type NotificationController struct {
+	DB *gorm.DB
+}
+
+func NewNotificationController(db *gorm.DB) *NotificationController {
+	return &NotificationController{DB: db}
+}
+
+// Unique Response structs
+type NotificationUnreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+// GetNotifications retrieves the notifications of the logged in user
+// @Summary Get Notifications
+// @Description Retrieve the in-app notifications of the logged in user, newest first, with pagination and read and type filters
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of notifications per page" default(10)
+// @Param isRead query bool false "Filter by read state"
+// @Param type query string false "Filter by notification type (e.g. order_assigned, complain_attributed)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.NotificationResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/notifications [get]
+func (nc *NotificationController) GetNotifications(c fiber.Ctx) error {
+	log.Println("GetNotifications called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("GetNotifications - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var notifications []models.Notification
+
+	// Build base query
+	query := nc.DB.Model(&models.Notification{}).Where("user_id = ?", uint(userID)).Order("created_at DESC, id DESC")
+
+	// Read filter if provided
+	isRead := strings.TrimSpace(c.Query("isRead", ""))
+	if isRead != "" {
+		read, err := strconv.ParseBool(isRead)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid isRead value. Use true or false.",
+			})
+		}
+		query = query.Where("is_read = ?", read)
+	}
+
+	// Type filter if provided
+	notificationType := strings.TrimSpace(c.Query("type", ""))
+	if notificationType != "" {
+		query = query.Where("type = ?", notificationType)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&notifications).Error; err != nil {
+		log.Println("GetNotifications - Failed to retrieve notifications:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve notifications",
+		})
+	}
+
+	// Format response
+	notificationList := make([]models.NotificationResponse, len(notifications))
+	for i, notification := range notifications {
+		notificationList[i] = *notification.ToResponse()
+	}
+
+	// Build success message
+	message := "Notifications retrieved successfully"
+	var filters []string
+
+	if isRead != "" {
+		filters = append(filters, "isRead: "+isRead)
+	}
+
+	if notificationType != "" {
+		filters = append(filters, "type: "+notificationType)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetNotifications completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    notificationList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetUnreadNotificationCount counts the unread notifications of the logged in user
+// @Summary Get Unread Notification Count
+// @Description Count the unread notifications of the logged in user, for the notification bell badge
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=NotificationUnreadCountResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/notifications/unread-count [get]
+func (nc *NotificationController) GetUnreadNotificationCount(c fiber.Ctx) error {
+	log.Println("GetUnreadNotificationCount called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("GetUnreadNotificationCount - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var unread int64
+	if err := nc.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", uint(userID), false).Count(&unread).Error; err != nil {
+		log.Println("GetUnreadNotificationCount - Failed to count notifications:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to count unread notifications",
+		})
+	}
+
+	log.Println("GetUnreadNotificationCount completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Unread notification count retrieved successfully",
+		Data:    NotificationUnreadCountResponse{Unread: unread},
+	})
+}
+
+// MarkNotificationRead marks a notification of the logged in user as read
+// @Summary Mark Notification Read
+// @Description Mark a notification of the logged in user as read
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.NotificationResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/notifications/{id}/read [put]
+func (nc *NotificationController) MarkNotificationRead(c fiber.Ctx) error {
+	log.Println("MarkNotificationRead called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("MarkNotificationRead - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter, users only see their own notifications
+	id := c.Params("id")
+	var notification models.Notification
+	if err := nc.DB.Where("id = ? AND user_id = ?", id, uint(userID)).First(&notification).Error; err != nil {
+		log.Println("MarkNotificationRead - Notification not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Notification with id " + id + " not found.",
+		})
+	}
+
+	if !notification.IsRead {
+		now := time.Now()
+		notification.IsRead = true
+		notification.ReadAt = &now
+		if err := nc.DB.Model(&notification).Updates(map[string]interface{}{
+			"is_read": true,
+			"read_at": now,
+		}).Error; err != nil {
+			log.Println("MarkNotificationRead - Failed to update notification:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to mark notification as read",
+			})
+		}
+	}
+
+	log.Println("MarkNotificationRead completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Notification marked as read",
+		Data:    notification.ToResponse(),
+	})
+}
+
+// MarkAllNotificationsRead marks all notifications of the logged in user as read
+// @Summary Mark All Notifications Read
+// @Description Mark all unread notifications of the logged in user as read
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/notifications/read-all [put]
+func (nc *NotificationController) MarkAllNotificationsRead(c fiber.Ctx) error {
+	log.Println("MarkAllNotificationsRead called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("MarkAllNotificationsRead - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	result := nc.DB.Model(&models.Notification{}).Where("user_id = ? AND is_read = ?", uint(userID), false).Updates(map[string]interface{}{
+		"is_read": true,
+		"read_at": time.Now(),
+	})
+	if result.Error != nil {
+		log.Println("MarkAllNotificationsRead - Failed to update notifications:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to mark notifications as read",
+		})
+	}
+
+	log.Println("MarkAllNotificationsRead completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d notifications marked as read", result.RowsAffected),
+	})
+}
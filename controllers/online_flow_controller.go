@@ -126,6 +126,8 @@ func (ofc *OnlineFlowController) BuildOnlineFlow(trackingNumber string) OnlineFl
 			orderInfo.ProcessingStatus = "Picking in Progress"
 		case "picking_pending":
 			orderInfo.ProcessingStatus = "Picking is Pending"
+		case "awaiting_stock":
+			orderInfo.ProcessingStatus = "Awaiting Stock"
 		case "picking_completed":
 			orderInfo.ProcessingStatus = "Picking Completed"
 		case "qc_progress":
@@ -142,8 +144,8 @@ func (ofc *OnlineFlowController) BuildOnlineFlow(trackingNumber string) OnlineFl
 			orderInfo.EventStatus = "In Progress"
 		case "completed":
 			orderInfo.EventStatus = "Completed"
-		case "cancelled":
-			orderInfo.EventStatus = "Cancelled"
+		case "canceled":
+			orderInfo.EventStatus = "Canceled"
 		case "pending":
 			orderInfo.EventStatus = "Pending"
 		case "duplicated":
@@ -361,7 +363,7 @@ func (ofc *OnlineFlowController) GetOnlineFlows(c fiber.Ctx) error {
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/onlines/flows/{trackingNumber} [get]
 func (ofc *OnlineFlowController) GetOnlineFlow(c fiber.Ctx) error {
-	trackingNumber := c.Params("trackingNumber")
+	trackingNumber := models.NormalizeIdentifier(c.Params("trackingNumber"))
 
 	if trackingNumber == "" {
 		log.Println("Tracking number is required")
@@ -1,29 +1,48 @@
 package controllers
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"livo-fiber-backend/config"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderController struct {
-	DB *gorm.DB
+	DB              *gorm.DB
+	Config          *config.Config
+	AddressProvider utils.AddressProvider // nil when address normalization is disabled
+	DefaultCurrency string                // currency of orders created without one
+	AgingThresholds map[string]int        // minutes per processing status before an order is overdue
+	// Only assign pickers checked in at the order's warehouse location
+	RequirePickerAttendance bool
+	// Orders a picker may have in picking progress before assignments need an override, 0 disables the quota
+	PickerMaxOpenOrders int
+	// Prefix of order numbers generated for orders without a marketplace order id, unless the store or channel sets one
+	OrderNumberPrefix string
+	// What happens to orders naming a channel or store that is neither set up nor aliased
+	ReferencePolicy utils.OrderReferencePolicy
 }
 
-func NewOrderController(db *gorm.DB) *OrderController {
-	return &OrderController{DB: db}
+func NewOrderController(cfg *config.Config, db *gorm.DB) *OrderController {
+	return &OrderController{DB: db, Config: cfg, AddressProvider: utils.NewAddressProvider(cfg), DefaultCurrency: cfg.DefaultCurrency, OrderNumberPrefix: cfg.OrderNumberPrefix, AgingThresholds: cfg.OrderAgingThresholds, RequirePickerAttendance: cfg.PickerAttendanceRequired, PickerMaxOpenOrders: cfg.PickerMaxOpenOrders, ReferencePolicy: utils.OrderReferencePolicyFromConfig(cfg)}
 }
 
 // Request structs
 type CreateOrderRequest struct {
-	OrderGineeID   string                     `json:"orderGineeId" validate:"required,min=3,max=100"`
+	OrderGineeID   string                     `json:"orderGineeId" validate:"omitempty,min=3,max=100"` // generated as PREFIX-YYYY-000123 when absent, for manual orders
 	Channel        string                     `json:"channel" validate:"required,min=3,max=100"`
 	Store          string                     `json:"store" validate:"required,min=3,max=100"`
 	Buyer          string                     `json:"buyer" validate:"required,min=3,max=100"`
@@ -31,6 +50,28 @@ type CreateOrderRequest struct {
 	Courier        string                     `json:"courier" validate:"omitempty,min=3,max=100"`
 	TrackingNumber string                     `json:"trackingNumber" validate:"omitempty,min=3,max=100"`
 	SentBefore     string                     `json:"sentBefore" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Currency       string                     `json:"currency" validate:"omitempty,len=3" example:"IDR"`                               // defaults to DEFAULT_CURRENCY
+	OrderSource    string                     `json:"orderSource" validate:"omitempty,oneof=marketplace offline replacement internal"` // defaults to the source of the channel
+	Priority       string                     `json:"priority" validate:"omitempty,oneof=normal urgent same_day" example:"urgent"`     // defaults to normal
+	GiftMessage    string                     `json:"giftMessage" validate:"omitempty,max=500"`
+	HandlingNote   string                     `json:"handlingNote" validate:"omitempty,max=500" example:"Fragile, keep upright"`
+	Details        []CreateOrderDetailRequest `json:"details" validate:"required,dive,required"`
+}
+
+// CreateManualOrderRequest is a simplified order for offline and walk-in sales, always numbered internally
+type CreateManualOrderRequest struct {
+	OrderSource    string                     `json:"orderSource" validate:"omitempty,oneof=offline replacement internal" example:"offline"` // defaults to offline
+	Channel        string                     `json:"channel" validate:"omitempty,min=3,max=100"`                                            // defaults to the first channel of the order source
+	Store          string                     `json:"store" validate:"required,min=3,max=100"`
+	Buyer          string                     `json:"buyer" validate:"required,min=3,max=100"`
+	Address        string                     `json:"address" validate:"omitempty,max=255"` // empty for walk-in sales handed over at the counter
+	Courier        string                     `json:"courier" validate:"omitempty,min=3,max=100"`
+	TrackingNumber string                     `json:"trackingNumber" validate:"omitempty,min=3,max=100"`
+	SentBefore     string                     `json:"sentBefore" example:"2026-01-31 17:00:00"` // defaults to the end of today
+	Currency       string                     `json:"currency" validate:"omitempty,len=3" example:"IDR"`
+	Priority       string                     `json:"priority" validate:"omitempty,oneof=normal urgent same_day" example:"urgent"` // defaults to normal
+	GiftMessage    string                     `json:"giftMessage" validate:"omitempty,max=500"`
+	HandlingNote   string                     `json:"handlingNote" validate:"omitempty,max=500" example:"Fragile, keep upright"`
 	Details        []CreateOrderDetailRequest `json:"details" validate:"required,dive,required"`
 }
 
@@ -40,6 +81,7 @@ type CreateOrderDetailRequest struct {
 	Variant     string `json:"variant" validate:"omitempty,min=1,max=100"`
 	Quantity    int    `json:"quantity" validate:"required,gt=0"`
 	Price       int    `json:"price" validate:"required,gt=0"`
+	Note        string `json:"note" validate:"omitempty,max=500"`
 }
 
 type BulkCreateOrdersRequest struct {
@@ -56,6 +98,25 @@ type UpdateOrderDetailRequest struct {
 	Variant     string `json:"variant" validate:"omitempty,min=1,max=100"`
 	Quantity    int    `json:"quantity" validate:"required,gt=0"`
 	Price       int    `json:"price" validate:"required,gt=0"`
+	Note        string `json:"note" validate:"omitempty,max=500"`
+}
+
+type OverrideOrderEditRequest struct {
+	Justification string                     `json:"justification" validate:"required" example:"Buyer reduced quantity by chat before shipping"`
+	Details       []UpdateOrderDetailRequest `json:"details" validate:"required,dive,required"`
+}
+
+type UpdateOrderAddressRequest struct {
+	Address string `json:"address" validate:"required,min=3,max=255" example:"Jl. Merdeka No. 10, Bandung 40115"`
+	Reason  string `json:"reason" validate:"required" example:"Buyer moved to their office address by chat"`
+}
+
+// UpdateOrderAddressResponse is the order with its new address, the recorded change, the courier check and the regenerated label
+type UpdateOrderAddressResponse struct {
+	Order          models.OrderResponse              `json:"order"`
+	Change         models.OrderAddressChangeResponse `json:"change"`
+	Serviceability utils.CourierServiceability       `json:"serviceability"`
+	Label          utils.SignedDownload              `json:"label"`
 }
 
 type UpdateProcessingStatusRequest struct {
@@ -66,17 +127,88 @@ type UpdateEventStatusRequest struct {
 	EventStatus string `json:"eventStatus" validate:"required,min=3,max=50"`
 }
 
+type HoldOrderRequest struct {
+	Reason string `json:"reason" validate:"required" example:"Confirming delivery address with buyer"`
+}
+
+type UnholdOrderRequest struct {
+	Note string `json:"note" example:"Address confirmed"`
+}
+
+type UpdateOrderPriorityRequest struct {
+	Priority string `json:"priority" validate:"required,oneof=normal urgent same_day" example:"same_day"`
+}
+
+type SplitOrderRequest struct {
+	TrackingNumber string                    `json:"trackingNumber" validate:"required,min=3,max=100" example:"JX1234567890"`
+	Details        []SplitOrderDetailRequest `json:"details" validate:"required,dive,required"`
+}
+
+type SplitOrderDetailRequest struct {
+	DetailID uint `json:"detailId" validate:"required" example:"12"`
+	Quantity int  `json:"quantity" validate:"omitempty,gt=0" example:"2"` // defaults to the whole detail quantity
+}
+
+type MergeOrdersRequest struct {
+	OrderIDs       []uint `json:"orderIds" validate:"required,min=2" example:"101,102"`
+	PrimaryOrderID uint   `json:"primaryOrderId" example:"101"` // order that keeps its tracking number, defaults to the first order id
+}
+
+type BulkSyncStatusRequest struct {
+	Rows []BulkSyncStatusRow `json:"rows" validate:"required,dive,required"`
+}
+
+type BulkSyncStatusRow struct {
+	OrderGineeID   string `json:"orderGineeId" validate:"required"`
+	ExternalStatus string `json:"externalStatus" validate:"required"`
+}
+
+// GineeStatusWebhookRequest is a status change pushed by Ginee, a single order or a batch of rows
+type GineeStatusWebhookRequest struct {
+	OrderGineeID   string              `json:"orderGineeId" example:"2508150001"`
+	ExternalStatus string              `json:"externalStatus" example:"CANCELLED"`
+	Rows           []BulkSyncStatusRow `json:"rows"`
+}
+
+type RequeueBulkOperationRequest struct {
+	// Corrected rows replacing the stored failed rows of the same index, the other failed rows are re-submitted as stored
+	Rows []RequeueBulkRow `json:"rows"`
+}
+
+type RequeueBulkRow struct {
+	Index int             `json:"index" example:"3"`
+	Row   json.RawMessage `json:"row" swaggertype:"object"` // CreateOrderRequest or BulkSyncStatusRow, depending on the operation
+}
+
 type AssignPickerRequest struct {
 	PickerID       uint   `json:"pickerId" validate:"required"`
 	TrackingNumber string `json:"trackingNumber" validate:"required,min=3,max=100"`
+	OverrideQuota  bool   `json:"overrideQuota"` // assign even when the picker reached the maximum of orders in picking progress
+}
+
+// PickerCapacityResponse is a picker with the orders they work and how many more they can be assigned
+type PickerCapacityResponse struct {
+	UserID            uint   `json:"userId"`
+	Username          string `json:"username"`
+	FullName          string `json:"fullName"`
+	CheckedIn         bool   `json:"checkedIn"`
+	OpenOrders        int    `json:"openOrders"`        // orders in picking progress
+	MaxOpenOrders     int    `json:"maxOpenOrders"`     // 0 without a quota
+	RemainingCapacity *int   `json:"remainingCapacity"` // null without a quota
+}
+
+type DuplicateOrderRequest struct {
+	// Tracking number of the duplicated order, required by channels with the manual duplication policy only
+	TrackingNumber string `json:"trackingNumber" validate:"omitempty,min=3,max=100" example:"JX9876543210"`
 }
 
 // Unique Response structs
 type BulkCreateOrdersReponse struct {
-	Summary       BulkCreateSummary      `json:"summary"`
-	CreatedOrders []models.OrderResponse `json:"createdOrders"`
-	SkippedOrders []SkippedOrder         `json:"skippedOrders"`
-	FailedOrders  []FailedOrder          `json:"failedOrders"`
+	BulkOperationID uint                   `json:"bulkOperationId,omitempty"` // stored result, its failed rows can be re-queued
+	Summary         BulkCreateSummary      `json:"summary"`
+	CreatedOrders   []models.OrderResponse `json:"createdOrders"`
+	SkippedOrders   []SkippedOrder         `json:"skippedOrders"`
+	FailedOrders    []FailedOrder          `json:"failedOrders"`
 }
 
 type BulkCreateSummary struct {
@@ -100,14 +232,126 @@ type FailedOrder struct {
 	Error          string `json:"error"`
 }
 
+type BulkSyncStatusResponse struct {
+	BulkOperationID uint                   `json:"bulkOperationId,omitempty"` // stored result, its failed rows can be re-queued
+	Summary         BulkSyncStatusSummary  `json:"summary"`
+	Results         []BulkSyncStatusResult `json:"results"`
+}
+
+type BulkSyncStatusSummary struct {
+	Total     uint `json:"total"`
+	Updated   uint `json:"updated"`
+	Unchanged uint `json:"unchanged"`
+	Skipped   uint `json:"skipped"`
+	Failed    uint `json:"failed"`
+}
+
+type BulkSyncStatusResult struct {
+	Index          int    `json:"index"`
+	OrderGineeID   string `json:"orderGineeId"`
+	ExternalStatus string `json:"externalStatus"`
+	PreviousStatus string `json:"previousStatus,omitempty"`
+	EventStatus    string `json:"eventStatus,omitempty"`
+	Result         string `json:"result"` // updated, unchanged, skipped or failed
+	Reason         string `json:"reason,omitempty"`
+}
+
+// OrderImportValidationResponse represents the row level validation result of an order import file
+type OrderImportValidationResponse struct {
+	Valid   bool                         `json:"valid"`
+	Summary OrderImportValidationSummary `json:"summary"`
+	Errors  []OrderImportRowError        `json:"errors"`
+}
+
+type OrderImportValidationSummary struct {
+	TotalRows   uint `json:"totalRows"`
+	ValidRows   uint `json:"validRows"`
+	InvalidRows uint `json:"invalidRows"`
+	Orders      uint `json:"orders"` // distinct order ginee ids in the file
+}
+
+type OrderImportRowError struct {
+	Row    int    `json:"row"` // spreadsheet row number, the header is row 1
+	Column string `json:"column,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Error  string `json:"error"`
+}
+
+// OrderShipmentsResponse represents an order with the shipments split from it
+type OrderShipmentsResponse struct {
+	ParentOrder      models.OrderResponse   `json:"parentOrder"`
+	Shipments        []models.OrderResponse `json:"shipments"` // parent first, then split shipments in creation order
+	TotalShipments   int                    `json:"totalShipments"`
+	ShippedShipments int                    `json:"shippedShipments"`
+	FullyShipped     bool                   `json:"fullyShipped"`
+}
+
+// MergeOrdersResponse represents the order picked and packed for a merge and the orders merged into it
+type MergeOrdersResponse struct {
+	PrimaryOrder models.OrderResponse   `json:"primaryOrder"`
+	MergedOrders []models.OrderResponse `json:"mergedOrders"`
+}
+
+// OrderStatusCount represents the number of orders in a status
+type OrderStatusCount struct {
+	Status string `json:"status"`
+	Count  int64  `json:"count"`
+}
+
+// OrderPriorityCount represents the open orders of a priority and how many of them are waiting to be picked or checked
+type OrderPriorityCount struct {
+	Priority    string `json:"priority"`
+	Count       int64  `json:"count"`
+	ReadyToPick int64  `json:"readyToPick"` // ready to pick or picking pending
+	AwaitingQC  int64  `json:"awaitingQc"`  // picking completed or QC in progress
+	PastDue     int64  `json:"pastDue"`     // past their SentBefore deadline
+}
+
+// OrderSummaryResponse represents order counts per status and the orders on hold for the dashboard
+type OrderSummaryResponse struct {
+	ProcessingStatuses []OrderStatusCount     `json:"processingStatuses"`
+	EventStatuses      []OrderStatusCount     `json:"eventStatuses"`
+	Priorities         []OrderPriorityCount   `json:"priorities"` // every priority, same day first
+	OnHold             int                    `json:"onHold"`
+	HeldOrders         []models.OrderResponse `json:"heldOrders"`
+}
+
+// OrderAgingStatus represents the open orders of a processing status split by how long they have been in it
+type OrderAgingStatus struct {
+	Status           string `json:"status"`
+	ThresholdMinutes int    `json:"thresholdMinutes"`
+	Total            int64  `json:"total"`
+	WithinSLA        int64  `json:"withinSla"` // younger than the threshold
+	Overdue          int64  `json:"overdue"`   // between the threshold and twice the threshold
+	Critical         int64  `json:"critical"`  // twice the threshold or older
+	OldestMinutes    int64  `json:"oldestMinutes"`
+}
+
+// OrderAgingResponse represents the SLA aging buckets of open orders for the dashboard
+type OrderAgingResponse struct {
+	Statuses      []OrderAgingStatus `json:"statuses"`
+	TotalOverdue  int64              `json:"totalOverdue"` // overdue and critical orders over all statuses
+	TotalCritical int64              `json:"totalCritical"`
+	GeneratedAt   string             `json:"generatedAt"`
+}
+
 type DuplicatedOrderResponse struct {
 	OriginalOrder   models.OrderResponse `json:"originalOrder"`
 	DuplicatedOrder models.OrderResponse `json:"duplicatedOrder"`
 }
 
+// orderQueueStatuses are the processing statuses listed as work queues, by priority and SentBefore
+var orderQueueStatuses = map[string]bool{
+	models.ProcessingStatusReadyToPick:      true,
+	models.ProcessingStatusPickingPending:   true,
+	models.ProcessingStatusPickingProgress:  true,
+	models.ProcessingStatusPickingCompleted: true,
+	models.ProcessingStatusQCProgress:       true,
+}
+
 // GetOrders retrieves a list of orders with pagination and search
 // @Summary Get Orders
-// @Description Retrieve a list of orders with pagination and search
+// @Description Retrieve a list of orders with pagination and search. Newest first, except for the picking and QC queue statuses (ready_to_pick, picking_pending, picking_progress, picking_completed, qc_progress), which are listed by priority and then by SentBefore
 // @Tags Orders
 // @Accept json
 // @Produce json
@@ -117,6 +361,13 @@ type DuplicatedOrderResponse struct {
 // @Param startDate query string false "Start date (YYYY-MM-DD format)"
 // @Param endDate query string false "End date (YYYY-MM-DD format)"
 // @Param search query string false "Search term for order ginee id or tracking number"
+// @Param processingStatus query string false "Filter by processing status (e.g. ready_to_pick, picking_progress, qc_progress)"
+// @Param eventStatus query string false "Filter by event status (e.g. in_progress, held, completed, canceled)"
+// @Param province query string false "Filter by normalized province"
+// @Param city query string false "Filter by normalized city or regency"
+// @Param addressStatus query string false "Filter by address normalization status (normalized, partial, unresolved)"
+// @Param orderSource query string false "Filter by order source (marketplace, offline, replacement, internal)"
+// @Param priority query string false "Filter by priority (normal, urgent, same_day)"
 // @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.Order}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -132,7 +383,7 @@ func (oc *OrderController) GetOrders(c fiber.Ctx) error {
 	var orders []models.Order
 
 	// Build base query
-	query := oc.DB.Model(&models.Order{}).Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Order("created_at DESC")
+	query := oc.DB.Model(&models.Order{}).Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Preload("HoldUser")
 
 	// Date range filter if provided
 	startDate := c.Query("startDate", "")
@@ -168,6 +419,62 @@ func (oc *OrderController) GetOrders(c fiber.Ctx) error {
 		query = query.Where("order_ginee_id ILIKE ? OR tracking_number ILIKE ?", "%"+search+"%", "%"+search+"%")
 	}
 
+	// Status filters if provided
+	processingStatus := c.Query("processingStatus", "")
+	if processingStatus != "" {
+		query = query.Where("processing_status = ?", processingStatus)
+	}
+
+	// Queues are worked by priority, then by the SentBefore deadline
+	if orderQueueStatuses[processingStatus] {
+		query = query.Order(models.OrderQueueOrder)
+	} else {
+		query = query.Order("created_at DESC")
+	}
+
+	eventStatus := c.Query("eventStatus", "")
+	if eventStatus != "" {
+		query = query.Where("event_status = ?", eventStatus)
+	}
+
+	// Region filters if provided
+	province := c.Query("province", "")
+	if province != "" {
+		query = query.Where("province ILIKE ?", province)
+	}
+	city := c.Query("city", "")
+	if city != "" {
+		query = query.Where("city ILIKE ?", city)
+	}
+	addressStatus := c.Query("addressStatus", "")
+	if addressStatus != "" {
+		query = query.Where("address_status = ?", addressStatus)
+	}
+
+	// Order source filter if provided
+	orderSource := c.Query("orderSource", "")
+	if orderSource != "" {
+		if !models.IsValidOrderSource(orderSource) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid orderSource. Use marketplace, offline, replacement or internal.",
+			})
+		}
+		query = query.Where("order_source = ?", orderSource)
+	}
+
+	// Priority filter if provided
+	priority := c.Query("priority", "")
+	if priority != "" {
+		if !models.IsValidOrderPriority(priority) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid priority. Use normal, urgent or same_day.",
+			})
+		}
+		query = query.Where("priority = ?", priority)
+	}
+
 	// Get total count for pagination
 	var total int64
 	query.Count(&total)
@@ -215,6 +522,34 @@ func (oc *OrderController) GetOrders(c fiber.Ctx) error {
 		filters = append(filters, "search: "+search)
 	}
 
+	if processingStatus != "" {
+		filters = append(filters, "processing status: "+processingStatus)
+	}
+
+	if eventStatus != "" {
+		filters = append(filters, "event status: "+eventStatus)
+	}
+
+	if province != "" {
+		filters = append(filters, "province: "+province)
+	}
+
+	if city != "" {
+		filters = append(filters, "city: "+city)
+	}
+
+	if addressStatus != "" {
+		filters = append(filters, "address status: "+addressStatus)
+	}
+
+	if orderSource != "" {
+		filters = append(filters, "order source: "+orderSource)
+	}
+
+	if priority != "" {
+		filters = append(filters, "priority: "+priority)
+	}
+
 	if len(filters) > 0 {
 		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
 	}
@@ -251,7 +586,7 @@ func (oc *OrderController) GetOrder(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var order models.Order
-	if err := oc.DB.Where("id = ?", id).Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").First(&order).Error; err != nil {
+	if err := oc.DB.Where("id = ?", id).Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Preload("HoldUser").Preload("SplitUser").First(&order).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Order with id " + id + " not found.",
@@ -266,6 +601,10 @@ func (oc *OrderController) GetOrder(c fiber.Ctx) error {
 		}
 	}
 
+	// Include who is currently editing the order and the serial numbers captured at QC
+	order.EditLock = utils.ActiveOrderEditLock(oc.DB, order.ID)
+	utils.LoadOrderSerials(oc.DB, &order)
+
 	log.Println("GetOrder completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
 		Success: true,
@@ -274,9 +613,35 @@ func (oc *OrderController) GetOrder(c fiber.Ctx) error {
 	})
 }
 
+// resolveOrderCurrency returns the uppercased currency code of an order request, or the default currency when empty
+func (oc *OrderController) resolveOrderCurrency(currency string) (string, bool) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return oc.DefaultCurrency, true
+	}
+	if len(currency) != 3 {
+		return "", false
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return "", false
+		}
+	}
+	return currency, true
+}
+
+// orderPriority returns the lowercased priority of an order request, normal when empty
+func orderPriority(priority string) string {
+	priority = strings.ToLower(strings.TrimSpace(priority))
+	if priority == "" {
+		return models.OrderPriorityNormal
+	}
+	return priority
+}
+
 // CreateOrder creates a new order
 // @Summary Create Order
-// @Description Create a new order
+// @Description Create a new order. Manual orders without an orderGineeId get an internally generated order number (PREFIX-YYYY-000123) using the prefix of the store, the channel or ORDER_NUMBER_PREFIX
 // @Tags Orders
 // @Accept json
 // @Produce json
@@ -300,15 +665,56 @@ func (oc *OrderController) CreateOrder(c fiber.Ctx) error {
 		})
 	}
 
+	return oc.createOrder(c, req)
+}
+
+// createOrder validates and creates a single order with its details, writing the response
+func (oc *OrderController) createOrder(c fiber.Ctx, req CreateOrderRequest) error {
 	// Convert Order Ginee ID to uppercase and trim spaces
-	req.OrderGineeID = strings.ToUpper(strings.TrimSpace(req.OrderGineeID))
+	req.OrderGineeID = models.NormalizeIdentifier(req.OrderGineeID)
 
 	// Convert Tracking Number to uppercase and trim spaces
-	req.TrackingNumber = strings.ToUpper(strings.TrimSpace(req.TrackingNumber))
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
+
+	// Catch mistyped tracking numbers before they reach the courier
+	if err := utils.ValidateTrackingNumber(oc.DB, req.TrackingNumber); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// Resolve currency, falling back to the configured default
+	currency, ok := oc.resolveOrderCurrency(req.Currency)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid currency. Use a 3 letter ISO 4217 code.",
+		})
+	}
 
-	// Check for existing order with same Order Ginee ID or Tracking Number
+	// Map the channel and store to the set up records, unknown names are handled by the reference policy
+	references := models.Order{Channel: req.Channel, Store: req.Store}
+	if err := utils.ResolveOrderReferences(oc.DB, &references, oc.ReferencePolicy); err != nil {
+		var unknown *utils.UnknownReferenceError
+		if errors.As(err, &unknown) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   unknown.Error(),
+			})
+		}
+		log.Println("CreateOrder - Failed to resolve channel and store:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to resolve channel and store",
+		})
+	}
+	req.Channel, req.Store = references.Channel, references.Store
+
+	// Check for existing order with same Order Ginee ID or Tracking Number, manual orders may have neither
 	var existingOrder models.Order
-	if err := oc.DB.Where("order_ginee_id = ? OR tracking_number = ?", req.OrderGineeID, req.TrackingNumber).First(&existingOrder).Error; err == nil {
+	if (req.OrderGineeID != "" || req.TrackingNumber != "") &&
+		oc.DB.Where("(order_ginee_id = ? AND order_ginee_id <> '') OR (tracking_number = ? AND tracking_number <> '')", req.OrderGineeID, req.TrackingNumber).First(&existingOrder).Error == nil {
 		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Order with Order Ginee ID " + req.OrderGineeID + " or Tracking Number " + req.TrackingNumber + " already exists.",
@@ -353,6 +759,36 @@ func (oc *OrderController) CreateOrder(c fiber.Ctx) error {
 		Courier:          req.Courier,
 		TrackingNumber:   req.TrackingNumber,
 		SentBefore:       sentBefore,
+		Currency:         currency,
+		Priority:         orderPriority(req.Priority),
+		GiftMessage:      req.GiftMessage,
+		HandlingNote:     req.HandlingNote,
+	}
+
+	// Tag the order source, inferred from the channel when not given
+	orderSource, ok := utils.ResolveOrderSource(oc.DB, &newOrder, strings.ToLower(strings.TrimSpace(req.OrderSource)))
+	if !ok {
+		tx.Rollback()
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid order source. Use one of: " + strings.Join(models.OrderSources(), ", "),
+		})
+	}
+	newOrder.OrderSource = orderSource
+	utils.NormalizeOrderAddress(c.Context(), oc.AddressProvider, &newOrder)
+
+	// Generate the order number of manual orders within the transaction, so a failed order does not use up a number
+	if newOrder.OrderGineeID == "" {
+		orderNumber, err := utils.NextOrderNumber(tx, utils.OrderNumberPrefix(oc.DB, &newOrder, oc.OrderNumberPrefix), time.Now())
+		if err != nil {
+			tx.Rollback()
+			log.Println("CreateOrder - Failed to generate order number:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate order number",
+			})
+		}
+		newOrder.OrderGineeID = orderNumber
 	}
 
 	if err := tx.Create(&newOrder).Error; err != nil {
@@ -371,9 +807,11 @@ func (oc *OrderController) CreateOrder(c fiber.Ctx) error {
 			Variant:     detail.Variant,
 			Quantity:    detail.Quantity,
 			Price:       detail.Price,
+			Note:        detail.Note,
 		}
 		newOrder.OrderDetails = append(newOrder.OrderDetails, orderDetail)
 	}
+	newOrder.CalculateTotals()
 
 	// Save order details within transaction
 	if err := tx.Save(&newOrder).Error; err != nil {
@@ -407,9 +845,90 @@ func (oc *OrderController) CreateOrder(c fiber.Ctx) error {
 	})
 }
 
+// CreateManualOrder creates an order for an offline or walk-in sale
+// @Summary Create Manual Order
+// @Description Create an offline, replacement or internal order without a marketplace order id. The order number is always generated (PREFIX-YYYY-000123), the channel defaults to the first channel of the order source, the address and tracking number may be left empty for counter sales and sentBefore defaults to the end of today.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param order body CreateManualOrderRequest true "Manual order details"
+// @Success 201 {object} utils.SuccessResponse{data=models.OrderResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/manual [post]
+func (oc *OrderController) CreateManualOrder(c fiber.Ctx) error {
+	log.Println("CreateManualOrder called")
+	// Binding request body
+	var req CreateManualOrderRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateManualOrder - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Marketplace orders carry their marketplace order id and go through the regular order creation
+	orderSource := strings.ToLower(strings.TrimSpace(req.OrderSource))
+	if orderSource == "" {
+		orderSource = models.OrderSourceOffline
+	}
+	if orderSource == models.OrderSourceMarketplace || !models.IsValidOrderSource(orderSource) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid order source. Use offline, replacement or internal.",
+		})
+	}
+
+	if strings.TrimSpace(req.Store) == "" || strings.TrimSpace(req.Buyer) == "" || len(req.Details) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store, buyer and at least one detail are required",
+		})
+	}
+
+	// Default to the first channel set up for the order source
+	channel := strings.TrimSpace(req.Channel)
+	if channel == "" {
+		var sourceChannel models.Channel
+		if err := oc.DB.Where("order_source = ?", orderSource).Order("id ASC").First(&sourceChannel).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "No channel is set up for " + orderSource + " orders, channel is required",
+			})
+		}
+		channel = sourceChannel.ChannelName
+	}
+
+	sentBefore := strings.TrimSpace(req.SentBefore)
+	if sentBefore == "" {
+		now := time.Now()
+		sentBefore = time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 0, 0, now.Location()).Format("2006-01-02 15:04:00")
+	}
+
+	return oc.createOrder(c, CreateOrderRequest{
+		Channel:        channel,
+		Store:          req.Store,
+		Buyer:          req.Buyer,
+		Address:        req.Address,
+		Courier:        req.Courier,
+		TrackingNumber: req.TrackingNumber,
+		SentBefore:     sentBefore,
+		Currency:       req.Currency,
+		OrderSource:    orderSource,
+		Priority:       req.Priority,
+		GiftMessage:    req.GiftMessage,
+		HandlingNote:   req.HandlingNote,
+		Details:        req.Details,
+	})
+}
+
 // BulkCreateOrders creates multiple orders in a single request
 // @Summary Bulk Create Orders
-// @Description Create multiple orders in a single request
+// @Description Create multiple orders in a single request. The result is stored as a bulk operation, its failed rows can be re-submitted with /api/orders/bulk-operations/{id}/requeue
 // @Tags Orders
 // @Accept json
 // @Produce json
@@ -432,16 +951,83 @@ func (oc *OrderController) BulkCreateOrders(c fiber.Ctx) error {
 		})
 	}
 
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	response := oc.runBulkCreateOrders(c, req.Orders, nil)
+	response.BulkOperationID = oc.saveBulkCreateOperation(req.Orders, nil, response, nil, uint(userID))
+	statusCode, message := bulkCreateOrdersOutcome(response)
+
+	// Return response
+	log.Printf("BulkCreateOrders completed (created=%d, skipped=%d, failed=%d)\n", response.Summary.Created, response.Summary.Skipped, response.Summary.Failed)
+	return c.Status(statusCode).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    response,
+	})
+}
+
+// runBulkCreateOrders creates the orders of a bulk request, indices are the indices of the orders in the original
+// request when failed rows are re-queued, nil uses their position
+func (oc *OrderController) runBulkCreateOrders(c fiber.Ctx, orders []CreateOrderRequest, indices []int) BulkCreateOrdersReponse {
 	var createdOrders []models.Order
 	var skippedOrders []SkippedOrder
 	var failedOrders []FailedOrder
 
-	for i, orderReq := range req.Orders {
+	// Load the tracking formats once for the whole batch
+	trackingFormats, err := utils.LoadTrackingFormats(oc.DB)
+	if err != nil {
+		log.Println("runBulkCreateOrders - Failed to load tracking formats:", err)
+	}
+
+	for position, orderReq := range orders {
+		// Index of the row in the original request, kept when failed rows are re-queued
+		i := bulkRowIndex(indices, position)
+
 		// Convert Order Ginee ID to uppercase and trim spaces
-		orderReq.OrderGineeID = strings.ToUpper(strings.TrimSpace(orderReq.OrderGineeID))
+		orderReq.OrderGineeID = models.NormalizeIdentifier(orderReq.OrderGineeID)
 
 		// Convert Tracking Number to uppercase and trim spaces
-		orderReq.TrackingNumber = strings.ToUpper(strings.TrimSpace(orderReq.TrackingNumber))
+		orderReq.TrackingNumber = models.NormalizeIdentifier(orderReq.TrackingNumber)
+
+		if err := trackingFormats.Validate(orderReq.TrackingNumber); err != nil {
+			failedOrders = append(failedOrders, FailedOrder{
+				Index:        i,
+				OrderGineeID: orderReq.OrderGineeID,
+				Error:        err.Error(),
+			})
+			continue
+		}
+
+		currency, ok := oc.resolveOrderCurrency(orderReq.Currency)
+		if !ok {
+			failedOrders = append(failedOrders, FailedOrder{
+				Index:        i,
+				OrderGineeID: orderReq.OrderGineeID,
+				Error:        "Invalid currency: " + orderReq.Currency,
+			})
+			continue
+		}
+
+		// Map the channel and store to the set up records, unknown names are handled by the reference policy
+		references := models.Order{Channel: orderReq.Channel, Store: orderReq.Store}
+		if err := utils.ResolveOrderReferences(oc.DB, &references, oc.ReferencePolicy); err != nil {
+			failedOrders = append(failedOrders, FailedOrder{
+				Index:          i,
+				OrderGineeID:   orderReq.OrderGineeID,
+				TrackingNumber: orderReq.TrackingNumber,
+				Error:          err.Error(),
+			})
+			continue
+		}
+		orderReq.Channel, orderReq.Store = references.Channel, references.Store
 
 		// Check if order with same OrderGineeID or tracking number already exists
 		var existingOrder models.Order
@@ -467,7 +1053,30 @@ func (oc *OrderController) BulkCreateOrders(c fiber.Ctx) error {
 			Address:          orderReq.Address,
 			Courier:          orderReq.Courier,
 			TrackingNumber:   orderReq.TrackingNumber,
+			Currency:         currency,
+			Priority:         orderPriority(orderReq.Priority),
+			GiftMessage:      orderReq.GiftMessage,
+			HandlingNote:     orderReq.HandlingNote,
+		}
+		orderSource, ok := utils.ResolveOrderSource(oc.DB, &order, strings.ToLower(strings.TrimSpace(orderReq.OrderSource)))
+		if !ok {
+			failedOrders = append(failedOrders, FailedOrder{
+				Index:        i,
+				OrderGineeID: orderReq.OrderGineeID,
+				Error:        "Invalid order source: " + orderReq.OrderSource,
+			})
+			continue
+		}
+		order.OrderSource = orderSource
+		if !models.IsValidOrderPriority(order.Priority) {
+			failedOrders = append(failedOrders, FailedOrder{
+				Index:        i,
+				OrderGineeID: orderReq.OrderGineeID,
+				Error:        "Invalid priority: " + orderReq.Priority,
+			})
+			continue
 		}
+		utils.NormalizeOrderAddress(c.Context(), oc.AddressProvider, &order)
 
 		if orderReq.SentBefore != "" {
 			if parsedTime, err := time.Parse("2006-01-02 15:04:00", orderReq.SentBefore); err == nil {
@@ -491,12 +1100,26 @@ func (oc *OrderController) BulkCreateOrders(c fiber.Ctx) error {
 				Variant:     detailReq.Variant,
 				Quantity:    detailReq.Quantity,
 				Price:       detailReq.Price,
+				Note:        detailReq.Note,
 			}
 			order.OrderDetails = append(order.OrderDetails, orderDetail)
 		}
+		order.CalculateTotals()
 
 		// Try to create the order using transaction
 		tx := oc.DB.Begin()
+		if order.OrderGineeID == "" {
+			orderNumber, err := utils.NextOrderNumber(tx, utils.OrderNumberPrefix(oc.DB, &order, oc.OrderNumberPrefix), time.Now())
+			if err != nil {
+				tx.Rollback()
+				failedOrders = append(failedOrders, FailedOrder{
+					Index: i,
+					Error: "Failed to generate order number: " + err.Error(),
+				})
+				continue
+			}
+			order.OrderGineeID = orderNumber
+		}
 		if err := tx.Create(&order).Error; err != nil {
 			tx.Rollback()
 			// Failed to create order
@@ -522,7 +1145,7 @@ func (oc *OrderController) BulkCreateOrders(c fiber.Ctx) error {
 
 	response := BulkCreateOrdersReponse{
 		Summary: BulkCreateSummary{
-			Total:   uint(len(req.Orders)),
+			Total:   uint(len(orders)),
 			Created: uint(len(createdOrders)),
 			Skipped: uint(len(skippedOrders)),
 			Failed:  uint(len(failedOrders)),
@@ -532,34 +1155,33 @@ func (oc *OrderController) BulkCreateOrders(c fiber.Ctx) error {
 		FailedOrders:  failedOrders,
 	}
 
+	return response
+}
+
+// bulkCreateOrdersOutcome returns the status code and message of a bulk order creation
+func bulkCreateOrdersOutcome(response BulkCreateOrdersReponse) (int, string) {
 	// Build success message
 	statusCode := fiber.StatusCreated
 	message := "Bulk order creation completed"
 
-	if len(createdOrders) == 0 {
-		if len(skippedOrders) > 0 {
+	if response.Summary.Created == 0 {
+		if response.Summary.Skipped > 0 {
 			statusCode = fiber.StatusOK
 			message = "All orders were skipped (already exist)"
 		} else {
 			statusCode = fiber.StatusBadRequest
 			message = "No orders could be created"
 		}
-	} else if len(failedOrders) > 0 || len(skippedOrders) > 0 {
+	} else if response.Summary.Failed > 0 || response.Summary.Skipped > 0 {
 		message = "Bulk order creation completed with some issues"
 	}
 
-	// Return response
-	log.Printf("BulkCreateOrders completed (created=%d, skipped=%d, failed=%d)\n", len(createdOrders), len(skippedOrders), len(failedOrders))
-	return c.Status(statusCode).JSON(utils.SuccessResponse{
-		Success: true,
-		Message: message,
-		Data:    response,
-	})
+	return statusCode, message
 }
 
 // UpdateOrder updates an existing order
 // @Summary Update Order
-// @Description Update an existing order
+// @Description Update an existing order. Orders already picked or QC completed require the coordinator edit override
 // @Tags Orders
 // @Accept json
 // @Produce json
@@ -570,6 +1192,7 @@ func (oc *OrderController) BulkCreateOrders(c fiber.Ctx) error {
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/orders/{id} [put]
 func (oc *OrderController) UpdateOrder(c fiber.Ctx) error {
@@ -610,6 +1233,20 @@ func (oc *OrderController) UpdateOrder(c fiber.Ctx) error {
 		})
 	}
 
+	// Picked and QC'd orders can only be changed through the coordinator override
+	if models.IsEditLockedProcessingStatus(order.ProcessingStatus) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order in " + order.ProcessingStatus + " status requires a coordinator override to be modified.",
+		})
+	}
+	if order.ProcessingStatus == "outbound_completed" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order has already been shipped and cannot be modified.",
+		})
+	}
+
 	// Check if order is canceled
 	if order.EventStatus == "canceled" {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
@@ -642,8 +1279,8 @@ func (oc *OrderController) UpdateOrder(c fiber.Ctx) error {
 
 	// Update order details if provided - replace all details
 	if req.Details != nil {
-		// Delete all existing order details
-		if err := tx.Where("order_id = ?", order.ID).Delete(&models.OrderDetail{}).Error; err != nil {
+		newDetails, err := replaceOrderDetails(tx, order.ID, req.Details)
+		if err != nil {
 			tx.Rollback()
 			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 				Success: false,
@@ -651,31 +1288,17 @@ func (oc *OrderController) UpdateOrder(c fiber.Ctx) error {
 			})
 		}
 
-		// Create new order details
-		newDetails := make([]models.OrderDetail, 0, len(req.Details))
-		for _, detailReq := range req.Details {
-			detail := models.OrderDetail{
-				SKU:         detailReq.SKU,
-				ProductName: detailReq.ProductName,
-				Variant:     detailReq.Variant,
-				Quantity:    detailReq.Quantity,
-				Price:       detailReq.Price,
-			}
-			newDetails = append(newDetails, detail)
-		}
-
-		if len(newDetails) > 0 {
-			if err := tx.Create(&newDetails).Error; err != nil {
-				tx.Rollback()
-				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-					Success: false,
-					Error:   "Failed to update order details",
-				})
-			}
-		}
-
 		// Update order's OrderDetails field
 		order.OrderDetails = newDetails
+
+		// Recalculate the order totals from the new details
+		if err := models.RecalculateOrderTotals(tx.Where("id = ?", order.ID)); err != nil {
+			tx.Rollback()
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to update order totals",
+			})
+		}
 	}
 
 	// Coommit transaction
@@ -702,14 +1325,282 @@ func (oc *OrderController) UpdateOrder(c fiber.Ctx) error {
 	})
 }
 
+// replaceOrderDetails deletes all details of the order and creates the requested ones
+func replaceOrderDetails(tx *gorm.DB, orderID uint, details []UpdateOrderDetailRequest) ([]models.OrderDetail, error) {
+	if err := tx.Where("order_id = ?", orderID).Delete(&models.OrderDetail{}).Error; err != nil {
+		return nil, err
+	}
+
+	newDetails := make([]models.OrderDetail, 0, len(details))
+	for _, detailReq := range details {
+		newDetails = append(newDetails, models.OrderDetail{
+			OrderID:     orderID,
+			SKU:         detailReq.SKU,
+			ProductName: detailReq.ProductName,
+			Variant:     detailReq.Variant,
+			Quantity:    detailReq.Quantity,
+			Price:       detailReq.Price,
+			Note:        detailReq.Note,
+		})
+	}
+
+	if len(newDetails) > 0 {
+		if err := tx.Create(&newDetails).Error; err != nil {
+			return nil, err
+		}
+	}
+	return newDetails, nil
+}
+
+// orderDetailLine formats an order detail as "SKU x quantity @ price" for the edit override audit
+func orderDetailLine(sku string, quantity, price int) string {
+	return fmt.Sprintf("%s x %d @ %d", sku, quantity, price)
+}
+
+// orderItemsChanged reports whether the requested details change the SKUs or quantities of the order.
+// Details are compared per SKU, so reordering lines or changing only prices is not an item change.
+func orderItemsChanged(details []models.OrderDetail, requested []UpdateOrderDetailRequest) bool {
+	quantities := make(map[string]int)
+	for _, detail := range details {
+		quantities[detail.SKU] += detail.Quantity
+	}
+	for _, detailReq := range requested {
+		quantities[detailReq.SKU] -= detailReq.Quantity
+	}
+	for _, quantity := range quantities {
+		if quantity != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// OverrideOrderEdit changes the items or prices of a picked order with a coordinator justification
+// @Summary Override Order Edit
+// @Description Change the details of an order that was already picked or QC completed. The justification and the details before and after are recorded. When SKUs or quantities change on a QC completed order its QC is voided and the order goes back to picking_completed to be QC'd again
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body OverrideOrderEditRequest true "Justification and new order details"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderEditOverrideResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/edit-override [put]
+func (oc *OrderController) OverrideOrderEdit(c fiber.Ctx) error {
+	log.Println("OverrideOrderEdit called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Preload("OrderDetails", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("OverrideOrderEdit - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	// Getting current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req OverrideOrderEditRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("OverrideOrderEdit - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Justification = strings.TrimSpace(req.Justification)
+	if req.Justification == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Override justification is required",
+		})
+	}
+	if len(req.Details) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one order detail is required",
+		})
+	}
+
+	// Only picked orders that have not been shipped need an override, earlier statuses use UpdateOrder
+	if !models.IsEditLockedProcessingStatus(order.ProcessingStatus) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order in " + order.ProcessingStatus + " status cannot be modified through an override.",
+		})
+	}
+	if order.EventStatus == "canceled" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Canceled order cannot be modified.",
+		})
+	}
+
+	// Snapshot the details before and after for the audit
+	detailsBefore := make([]string, len(order.OrderDetails))
+	for i, detail := range order.OrderDetails {
+		detailsBefore[i] = orderDetailLine(detail.SKU, detail.Quantity, detail.Price)
+	}
+	detailsAfter := make([]string, len(req.Details))
+	for i, detailReq := range req.Details {
+		detailsAfter[i] = orderDetailLine(detailReq.SKU, detailReq.Quantity, detailReq.Price)
+	}
+
+	itemsChanged := orderItemsChanged(order.OrderDetails, req.Details)
+	override := models.OrderEditOverride{
+		OrderID:        order.ID,
+		TrackingNumber: order.TrackingNumber,
+		PreviousStatus: order.ProcessingStatus,
+		Justification:  req.Justification,
+		DetailsBefore:  strings.Join(detailsBefore, "\n"),
+		DetailsAfter:   strings.Join(detailsAfter, "\n"),
+		ItemsChanged:   itemsChanged,
+		QCReset:        itemsChanged && order.ProcessingStatus == "qc_completed",
+		OverriddenBy:   uint(userID),
+	}
+
+	now := time.Now()
+	userIDUint := uint(userID)
+	err = oc.DB.Transaction(func(tx *gorm.DB) error {
+		// Guard against the order moving on while the override was prepared
+		result := tx.Model(&models.Order{}).Where("id = ? AND processing_status = ?", order.ID, order.ProcessingStatus).Updates(map[string]interface{}{
+			"changed_by": userIDUint,
+			"changed_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderEditOverrideConflict
+		}
+
+		if _, err := replaceOrderDetails(tx, order.ID, req.Details); err != nil {
+			return err
+		}
+		if err := models.RecalculateOrderTotals(tx.Where("id = ?", order.ID)); err != nil {
+			return err
+		}
+
+		// Changed items have to be checked again, QC'd orders go back through QC
+		if override.QCReset {
+			if err := voidOrderQC(tx, order.TrackingNumber, "Order edit override: "+req.Justification, userIDUint); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&override).Error
+	})
+	if errors.Is(err, errOrderEditOverrideConflict) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order status changed, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("OverrideOrderEdit - Failed to override order edit:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update order",
+		})
+	}
+
+	// Reload the override log with its user for response
+	if err := oc.DB.Preload("OverrideUser").First(&override, override.ID).Error; err != nil {
+		log.Println("OverrideOrderEdit - Failed to load order edit override:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load order edit override",
+		})
+	}
+
+	message := "Order updated with coordinator override successfully"
+	if override.QCReset {
+		message += ", QC was voided and the order must be QC'd again"
+	}
+
+	log.Println("OverrideOrderEdit completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    override.ToResponse(),
+	})
+}
+
+// GetOrderEditOverrides retrieves the edit override history of an order
+// @Summary Get Order Edit Overrides
+// @Description Retrieve the coordinator edit overrides of an order, latest first
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.OrderEditOverrideResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/edit-overrides [get]
+func (oc *OrderController) GetOrderEditOverrides(c fiber.Ctx) error {
+	log.Println("GetOrderEditOverrides called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("GetOrderEditOverrides - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	var overrides []models.OrderEditOverride
+	if err := oc.DB.Preload("OverrideUser").Where("order_id = ?", order.ID).Order("created_at DESC, id DESC").Find(&overrides).Error; err != nil {
+		log.Println("GetOrderEditOverrides - Failed to retrieve order edit overrides:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order edit overrides",
+		})
+	}
+
+	overrideList := make([]models.OrderEditOverrideResponse, len(overrides))
+	for i, override := range overrides {
+		overrideList[i] = *override.ToResponse()
+	}
+
+	log.Println("GetOrderEditOverrides completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order edit overrides retrieved successfully",
+		Data:    overrideList,
+	})
+}
+
 // DuplicateOrder duplicates an existing order
 // @Summary Duplicate Order
-// @Description Duplicate an existing order
+// @Description Duplicate an existing order. The tracking numbers follow the duplication policy of the order's channel: swap (default) gives the duplicate the original tracking number and the original the same number with an X- prefix, internal keeps the original's tracking number and gives the duplicate an internal one, manual keeps the original's tracking number and requires the duplicate's in the request body
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Order ID"
+// @Param request body DuplicateOrderRequest false "Tracking number of the duplicated order (manual duplication policy only)"
 // @Success 201 {object} utils.SuccessResponse{data=DuplicatedOrderResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -761,6 +1652,50 @@ func (oc *OrderController) DuplicateOrder(c fiber.Ctx) error {
 		})
 	}
 
+	// Parse the optional request body
+	var req DuplicateOrderRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid request body",
+			})
+		}
+	}
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
+
+	// Tracking numbers are assigned by the duplication policy of the order's channel, unknown channels swap them
+	policy := models.DuplicationPolicySwap
+	if channel := utils.FindOrderChannel(oc.DB, &order); channel != nil && channel.DuplicationPolicy != "" {
+		policy = channel.DuplicationPolicy
+	}
+	if policy == models.DuplicationPolicyManual {
+		if req.TrackingNumber == "" {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Channel " + order.Channel + " requires the tracking number of the duplicated order.",
+			})
+		}
+		if err := utils.ValidateTrackingNumber(oc.DB, req.TrackingNumber); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+		var existingOrder models.Order
+		if err := oc.DB.Where("tracking_number = ?", req.TrackingNumber).First(&existingOrder).Error; err == nil {
+			return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Order with tracking number " + req.TrackingNumber + " already exists.",
+			})
+		}
+	} else if req.TrackingNumber != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Channel " + order.Channel + " assigns the tracking number of duplicated orders (" + policy + " policy), it cannot be entered.",
+		})
+	}
+
 	// Start transaction
 	tx := oc.DB.Begin()
 	defer func() {
@@ -771,15 +1706,32 @@ func (oc *OrderController) DuplicateOrder(c fiber.Ctx) error {
 
 	// Store original tracking number before duplication
 	originalTrackingNumber := order.TrackingNumber
-	newTrackingNumber := "X-" + originalTrackingNumber
+	duplicatedTrackingNumber := originalTrackingNumber
+	switch policy {
+	case models.DuplicationPolicyInternal:
+		internalTrackingNumber, err := utils.NextInternalTrackingNumber(tx, time.Now())
+		if err != nil {
+			tx.Rollback()
+			log.Println("DuplicateOrder - Failed to generate internal tracking number:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to generate internal tracking number",
+			})
+		}
+		duplicatedTrackingNumber = internalTrackingNumber
+	case models.DuplicationPolicyManual:
+		duplicatedTrackingNumber = req.TrackingNumber
+	default:
+		// The duplicate takes over the tracking number, the original keeps it with an "X-" prefix
+		order.TrackingNumber = "X-" + originalTrackingNumber
+	}
 
-	// Update original order's order ginee id by adding "-X2" suffix and tracking number with "X-" prefix
+	// Update original order's order ginee id by adding "-X2" suffix
 	now := time.Now()
 	userIDUint := uint(userID)
 	eventStatusDuplicated := "duplicated"
 	order.EventStatus = eventStatusDuplicated
 	order.OrderGineeID = order.OrderGineeID + "-X2"
-	order.TrackingNumber = newTrackingNumber
 	order.DuplicatedBy = &userIDUint
 	order.DuplicatedAt = &now
 
@@ -792,9 +1744,11 @@ func (oc *OrderController) DuplicateOrder(c fiber.Ctx) error {
 	}
 
 	// Update tracking number in qc ribbon, qc online, and outbound if exists (ignore errors if table doesn't exist)
-	tx.Model(&models.QCRibbon{}).Where("tracking_number = ?", originalTrackingNumber).Update("tracking_number", newTrackingNumber)
-	tx.Model(&models.QCOnline{}).Where("tracking_number = ?", originalTrackingNumber).Update("tracking_number", newTrackingNumber)
-	tx.Model(&models.Outbound{}).Where("tracking_number = ?", originalTrackingNumber).Update("tracking_number", newTrackingNumber)
+	if order.TrackingNumber != originalTrackingNumber {
+		tx.Model(&models.QCRibbon{}).Where("tracking_number = ?", originalTrackingNumber).Update("tracking_number", order.TrackingNumber)
+		tx.Model(&models.QCOnline{}).Where("tracking_number = ?", originalTrackingNumber).Update("tracking_number", order.TrackingNumber)
+		tx.Model(&models.Outbound{}).Where("tracking_number = ?", originalTrackingNumber).Update("tracking_number", order.TrackingNumber)
+	}
 
 	// Create duplicated order
 	duplicatedEventStatus := "duplicated"
@@ -805,9 +1759,19 @@ func (oc *OrderController) DuplicateOrder(c fiber.Ctx) error {
 		Store:            order.Store,
 		Buyer:            order.Buyer,
 		Address:          order.Address,
+		Province:         order.Province,
+		City:             order.City,
+		District:         order.District,
+		PostalCode:       order.PostalCode,
+		AddressStatus:    order.AddressStatus,
 		Courier:          order.Courier,
-		TrackingNumber:   originalTrackingNumber,
+		TrackingNumber:   duplicatedTrackingNumber,
 		SentBefore:       order.SentBefore,
+		Currency:         order.Currency,
+		OrderSource:      order.OrderSource,
+		Priority:         order.Priority,
+		GiftMessage:      order.GiftMessage,
+		HandlingNote:     order.HandlingNote,
 		EventStatus:      duplicatedEventStatus,
 		DuplicatedBy:     &userIDUint,
 		DuplicatedAt:     &now,
@@ -821,9 +1785,11 @@ func (oc *OrderController) DuplicateOrder(c fiber.Ctx) error {
 			Variant:     detail.Variant,
 			Quantity:    detail.Quantity,
 			Price:       detail.Price,
+			Note:        detail.Note,
 		}
 		duplicatedOrder.OrderDetails = append(duplicatedOrder.OrderDetails, duplicatedDetail)
 	}
+	duplicatedOrder.CalculateTotals()
 
 	// Create duplicated order in database
 	if err := tx.Create(&duplicatedOrder).Error; err != nil {
@@ -911,11 +1877,11 @@ func (oc *OrderController) CancelOrder(c fiber.Ctx) error {
 		})
 	}
 
-	// Check if order is already cancelled
-	if order.EventStatus == "cancelled" {
+	// Check if order is already canceled
+	if order.EventStatus == models.EventStatusCanceled {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Order is already cancelled",
+			Error:   "Order is already canceled",
 		})
 	}
 
@@ -927,11 +1893,10 @@ func (oc *OrderController) CancelOrder(c fiber.Ctx) error {
 		}
 	}()
 
-	// Update order status to cancelled
+	// Update order status to canceled
 	now := time.Now()
 	userIDUint := uint(userID)
-	eventStatusCanceled := "canceled"
-	order.EventStatus = eventStatusCanceled
+	order.EventStatus = models.EventStatusCanceled
 	order.CanceledBy = &userIDUint
 	order.CanceledAt = &now
 
@@ -952,6 +1917,24 @@ func (oc *OrderController) CancelOrder(c fiber.Ctx) error {
 		})
 	}
 
+	// Close the hold of a held order
+	if err := releaseOrderHolds(tx, order.ID, userIDUint, now, "Order canceled"); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to release order hold",
+		})
+	}
+
+	// Close QC records and flag outbound records of the canceled order
+	if err := propagateOrderCancel(tx, order.TrackingNumber); err != nil {
+		tx.Rollback()
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update QC and outbound records",
+		})
+	}
+
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
@@ -971,14 +1954,14 @@ func (oc *OrderController) CancelOrder(c fiber.Ctx) error {
 
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
 		Success: true,
-		Message: "Order cancelled successfully",
+		Message: "Order canceled successfully",
 		Data:    reloadedOrder.ToOrderResponse(),
 	})
 }
 
 // AssignPicker assigns a picker to an order
 // @Summary Assign Picker
-// @Description Assign a picker to an order
+// @Description Assign a picker to an order. Pickers at the maximum of orders in picking progress are refused with 409 unless overrideQuota is set
 // @Tags Orders
 // @Accept json
 // @Produce json
@@ -987,7 +1970,9 @@ func (oc *OrderController) CancelOrder(c fiber.Ctx) error {
 // @Success 200 {object} utils.SuccessResponse{data=models.Order}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/orders/assign-picker [post]
 func (oc *OrderController) AssignPicker(c fiber.Ctx) error {
@@ -1013,6 +1998,7 @@ func (oc *OrderController) AssignPicker(c fiber.Ctx) error {
 	}
 
 	// Get target order by tracking number
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
 	var order models.Order
 	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("tracking_number = ?", req.TrackingNumber).First(&order).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
@@ -1030,6 +2016,23 @@ func (oc *OrderController) AssignPicker(c fiber.Ctx) error {
 		})
 	}
 
+	// Coordinators with a team only assign pickers of their teams
+	userRoles, _ := c.Locals("userRoles").([]string)
+	canManage, err := utils.CanManageUser(oc.DB, uint(userID), userRoles, picker.ID)
+	if err != nil {
+		log.Println("AssignPicker - Failed to resolve team scope:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to assign picker",
+		})
+	}
+	if !canManage {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Picker " + picker.Username + " is not a member of your team.",
+		})
+	}
+
 	// Check if order processing status allows assignment
 	if order.ProcessingStatus != "ready_to_pick" && order.ProcessingStatus != "picking_pending" {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
@@ -1046,21 +2049,106 @@ func (oc *OrderController) AssignPicker(c fiber.Ctx) error {
 		})
 	}
 
-	// Update order with assignment details
-	now := time.Now()
-	userIDUint := uint(userID)
-	order.AssignedBy = &userIDUint
-	order.AssignedAt = &now
-	order.PickedBy = &req.PickerID
-	order.ProcessingStatus = "picking_progress"
+	// Check if order is on hold
+	if order.EventStatus == "held" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order is on hold and cannot be assigned a picker: " + order.HoldReason,
+		})
+	}
+
+	// Pickers must be checked in at the warehouse the order is picked at
+	if oc.RequirePickerAttendance {
+		present, err := utils.IsUserPresent(oc.DB, picker.ID, utils.OrderLocationID(oc.DB, &order))
+		if err != nil {
+			log.Println("AssignPicker - Failed to check picker attendance:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to assign picker",
+			})
+		}
+		if !present {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Picker " + picker.Username + " is not checked in at the warehouse of this order.",
+			})
+		}
+	}
 
-	if err := oc.DB.Save(&order).Error; err != nil {
+	// Products such as fragile goods may only be picked by pickers trained for them
+	missingSkills, err := utils.MissingOrderSkills(oc.DB, picker.ID, &order)
+	if err != nil {
+		log.Println("AssignPicker - Failed to check picker skills:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to assign picker",
 		})
 	}
-
+	if len(missingSkills) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Picker " + picker.Username + " lacks the skills this order requires: " + utils.SkillNames(missingSkills) + ".",
+		})
+	}
+
+	// Soft quota on the orders a picker works at once, coordinators may knowingly exceed it
+	if oc.PickerMaxOpenOrders > 0 {
+		openOrders, err := utils.PickerOpenOrders(oc.DB, []uint{picker.ID})
+		if err != nil {
+			log.Println("AssignPicker - Failed to count picker orders:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to assign picker",
+			})
+		}
+		if openOrders[picker.ID] >= oc.PickerMaxOpenOrders {
+			if !req.OverrideQuota {
+				return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   fmt.Sprintf("Picker %s already has %d orders in picking progress, the maximum is %d. Set overrideQuota to assign anyway.", picker.Username, openOrders[picker.ID], oc.PickerMaxOpenOrders),
+				})
+			}
+			log.Printf("AssignPicker - Quota of picker %s overridden by user %d (%d open orders)\n", picker.Username, userID, openOrders[picker.ID])
+		}
+	}
+
+	// Update order with assignment details, only if nobody changed its status in the meantime
+	now := time.Now()
+	userIDUint := uint(userID)
+	err = oc.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND processing_status = ? AND event_status NOT IN ?", order.ID, order.ProcessingStatus, []string{"canceled", "held"}).
+			Updates(map[string]interface{}{
+				"assigned_by":       userIDUint,
+				"assigned_at":       now,
+				"picked_by":         req.PickerID,
+				"processing_status": "picking_progress",
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderStatusConflict
+		}
+
+		return utils.NotifyUsers(tx, []uint{picker.ID}, "order_assigned", "Order assigned to you",
+			fmt.Sprintf("Order %s (%s) is assigned to you for picking", order.TrackingNumber, order.OrderGineeID), "order", order.ID)
+	})
+	if errors.Is(err, errOrderStatusConflict) {
+		log.Println("AssignPicker - Order status changed during assignment:", order.TrackingNumber)
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order status changed, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("AssignPicker - Failed to assign picker:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to assign picker",
+		})
+	}
+
 	// Reload the data with fresh query
 	var reloadedOrder models.Order
 	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").First(&reloadedOrder, order.ID).Error; err != nil {
@@ -1077,6 +2165,124 @@ func (oc *OrderController) AssignPicker(c fiber.Ctx) error {
 	})
 }
 
+// GetPickerCapacity retrieves the pickers with their orders in picking progress and remaining capacity
+// @Summary Get Picker Capacity
+// @Description Retrieve the active pickers that can be assigned orders, with whether they are checked in, the orders they have in picking progress and how many more they can be assigned before the quota needs a coordinator override. Coordinators with a team only see its members. Pickers with the most remaining capacity come first.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Search term for username or full name"
+// @Success 200 {object} utils.SuccessResponse{data=[]PickerCapacityResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/picker-capacity [get]
+func (oc *OrderController) GetPickerCapacity(c fiber.Ctx) error {
+	log.Println("GetPickerCapacity called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Build base query of active pickers
+	query := oc.DB.Model(&models.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.role_name = ? AND users.is_active = ?", "picker", true)
+
+	// Coordinators with a team only see its members
+	userRoles, _ := c.Locals("userRoles").([]string)
+	teamIDs, err := utils.CoordinatorTeamScope(oc.DB, uint(userID), userRoles)
+	if err != nil {
+		log.Println("GetPickerCapacity - Failed to resolve team scope:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picker capacity",
+		})
+	}
+	if teamIDs != nil {
+		query = query.Where("users.id IN (?)", utils.TeamMembersQuery(oc.DB, teamIDs...))
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("users.username ILIKE ? OR users.full_name ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	var pickers []models.User
+	if err := query.Distinct("users.*").Order("users.full_name ASC").Find(&pickers).Error; err != nil {
+		log.Println("GetPickerCapacity - Failed to retrieve pickers:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picker capacity",
+		})
+	}
+
+	pickerIDs := make([]uint, len(pickers))
+	for i, picker := range pickers {
+		pickerIDs[i] = picker.ID
+	}
+	openOrders, err := utils.PickerOpenOrders(oc.DB, pickerIDs)
+	if err != nil {
+		log.Println("GetPickerCapacity - Failed to count picker orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picker capacity",
+		})
+	}
+	checkedIn := make(map[uint]bool)
+	if len(pickerIDs) > 0 {
+		var presentIDs []uint
+		if err := oc.DB.Table("(?) AS present", utils.PresentUsersQuery(oc.DB, nil)).
+			Where("user_id IN ?", pickerIDs).Pluck("user_id", &presentIDs).Error; err != nil {
+			log.Println("GetPickerCapacity - Failed to check picker attendance:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve picker capacity",
+			})
+		}
+		for _, id := range presentIDs {
+			checkedIn[id] = true
+		}
+	}
+
+	// Format response
+	capacities := make([]PickerCapacityResponse, len(pickers))
+	for i, picker := range pickers {
+		capacities[i] = PickerCapacityResponse{
+			UserID:            picker.ID,
+			Username:          picker.Username,
+			FullName:          picker.FullName,
+			CheckedIn:         checkedIn[picker.ID],
+			OpenOrders:        openOrders[picker.ID],
+			MaxOpenOrders:     max(oc.PickerMaxOpenOrders, 0),
+			RemainingCapacity: utils.PickerRemainingCapacity(openOrders[picker.ID], oc.PickerMaxOpenOrders),
+		}
+	}
+	sort.SliceStable(capacities, func(i, j int) bool {
+		return capacities[i].OpenOrders < capacities[j].OpenOrders
+	})
+
+	// Build success message
+	message := "Picker capacity retrieved successfully"
+	if search != "" {
+		message += fmt.Sprintf(" (filtered by search: %s)", search)
+	}
+
+	log.Println("GetPickerCapacity completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    capacities,
+	})
+}
+
 // PendingPickingOrders marks an order as pending picking
 // @Summary Pending Picking Order
 // @Description Mark an order as pending picking
@@ -1088,6 +2294,7 @@ func (oc *OrderController) AssignPicker(c fiber.Ctx) error {
 // @Success 200 {object} utils.SuccessResponse{data=models.Order}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/orders/{id}/pending-picking [put]
@@ -1120,6 +2327,25 @@ func (oc *OrderController) PendingPickingOrders(c fiber.Ctx) error {
 		})
 	}
 
+	// Coordinators with a team only manage the orders picked by their team
+	if order.PickedBy != nil {
+		userRoles, _ := c.Locals("userRoles").([]string)
+		canManage, err := utils.CanManageUser(oc.DB, uint(userID), userRoles, *order.PickedBy)
+		if err != nil {
+			log.Println("PendingPickingOrders - Failed to resolve team scope:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to mark order as pending",
+			})
+		}
+		if !canManage {
+			return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Order is picked by a user outside your team.",
+			})
+		}
+	}
+
 	// Update order to pending picking
 	now := time.Now()
 	userIDUint := uint(userID)
@@ -1153,266 +2379,3146 @@ func (oc *OrderController) PendingPickingOrders(c fiber.Ctx) error {
 	})
 }
 
-// GetAssignedOrders retrieves orders assigned to a all picker
-// @Summary Get Assigned Orders
-// @Description Retrieve orders assigned to a all picker with pagination, date range filtering, and search
-// @Tags Orders
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param page query int false "Page number" default(1)
-// @Param limit query int false "Number of items per page" default(10)
-// @Param start_date query string false "Start date for filtering (YYYY-MM-DD)"
-// @Param end_date query string false "End date for filtering (YYYY-MM-DD)"
-// @Param search query string false "Search term for filtering"
-// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.Order}
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
-// @Router /api/orders/assigned [get]
-func (oc *OrderController) GetAssignedOrders(c fiber.Ctx) error {
-	log.Println("GetAssignedOrders called")
-	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	offset := (page - 1) * limit
+// errOrderStatusConflict is returned when the order status changed between reading and updating it
+var errOrderStatusConflict = errors.New("order status changed concurrently")
 
-	var orders []models.Order
+// errOrderHoldConflict is returned when the order status changed while putting it on or off hold
+var errOrderHoldConflict = errors.New("order status changed")
 
-	// Build base query
-	query := oc.DB.Model(&models.Order{}).Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Order("created_at DESC").Where("processing_status = ?", "picking_progress")
+// errOrderEditOverrideConflict is returned when the order moved to another status during an edit override
+var errOrderEditOverrideConflict = errors.New("order status changed during edit override")
 
-	// Date range filter if provided
-	startDate := c.Query("start_date", "")
-	endDate := c.Query("end_date", "")
-	if startDate != "" {
-		// Parse start date and set time to beginning of the day
-		parsedStartDate, err := time.Parse("2006-01-02", startDate)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Invalid start_date format. Use YYYY-MM-DD.",
-			})
-		}
-		startOfDay := time.Date(parsedStartDate.Year(), parsedStartDate.Month(), parsedStartDate.Day(), 0, 0, 0, 0, parsedStartDate.Location())
-		query = query.Where("created_at >= ?", startOfDay)
-	}
-	if endDate != "" {
-		// Parse end date and set time to end of the day
-		parsedEndDate, err := time.Parse("2006-01-02", endDate)
-		if err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Invalid end_date format. Use YYYY-MM-DD.",
-			})
-		}
-		endOfDay := time.Date(parsedEndDate.Year(), parsedEndDate.Month(), parsedEndDate.Day(), 23, 59, 59, 0, parsedEndDate.Location())
-		query = query.Where("created_at <= ?", endOfDay)
-	}
+// errOrderSplitConflict is returned when the order changed while splitting it
+var errOrderSplitConflict = errors.New("order changed during split")
 
-	// Search condition if provided
-	search := c.Query("search", "")
-	if search != "" {
-		query = query.Where("order_ginee_id ILIKE ? OR tracking_number ILIKE ?", "%"+search+"%", "%"+search+"%")
-	}
+// splittableProcessingStatuses are the processing statuses an order can be split in, before it reaches QC
+var splittableProcessingStatuses = []string{"ready_to_pick", "picking_pending", "awaiting_stock", "picking_completed"}
 
-	// Get total count for pagination
-	var total int64
-	query.Count(&total)
+// errOrderMergeConflict is returned when one of the orders changed while merging them
+var errOrderMergeConflict = errors.New("order changed during merge")
 
-	// Retrieve paginated results
-	if err := query.Offset(offset).Limit(limit).Find(&orders).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Failed to retrieve orders",
-		})
+// propagateOrderCancel closes the open QC records of a canceled order, flags its QC and outbound records and
+// releases the serial numbers captured for it
+func propagateOrderCancel(tx *gorm.DB, trackingNumber string) error {
+	if trackingNumber == "" {
+		return nil
 	}
 
-	// Format response
-	orderList := make([]models.OrderResponse, len(orders))
-	for i, order := range orders {
-		orderList[i] = *order.ToOrderResponse()
+	if err := utils.ReleaseOrderSerials(tx, trackingNumber, models.SerialReleaseOrderCanceled, time.Now()); err != nil {
+		return err
 	}
 
-	// Build success message
-	message := "Assigned orders retrieved successfully"
-	var filters []string
-
-	if startDate != "" || endDate != "" {
-		var dateRange []string
-		if startDate != "" {
-			dateRange = append(dateRange, "from: "+startDate)
+	for _, model := range []interface{}{&models.QCRibbon{}, &models.QCOnline{}} {
+		if err := tx.Model(model).Where("tracking_number = ? AND status IN ?", trackingNumber, models.QCStatusesTransitioningTo(models.QCStatusCanceled)).Update("status", models.QCStatusCanceled).Error; err != nil {
+			return err
 		}
-		if endDate != "" {
-			dateRange = append(dateRange, "to: "+endDate)
+		if err := tx.Model(model).Where("tracking_number = ? AND order_canceled = ?", trackingNumber, false).Update("order_canceled", true).Error; err != nil {
+			return err
 		}
-		filters = append(filters, "date: "+strings.Join(dateRange, ", "))
-	}
-
-	if search != "" {
-		filters = append(filters, "search: "+search)
-	}
-
-	if len(filters) > 0 {
-		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
 	}
+	return tx.Model(&models.Outbound{}).Where("tracking_number = ? AND order_canceled = ?", trackingNumber, false).Update("order_canceled", true).Error
+}
 
-	// Return response
-	log.Println("GetAssignedOrders completed successfully")
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
-		Success: true,
-		Message: message,
-		Data:    orderList,
-		Pagination: utils.Pagination{
-			Page:  page,
-			Limit: limit,
-			Total: total,
-		},
-	})
+// releaseOrderHolds closes the open hold records of an order
+func releaseOrderHolds(tx *gorm.DB, orderID, userID uint, now time.Time, note string) error {
+	return tx.Model(&models.OrderHold{}).Where("order_id = ? AND released_at IS NULL", orderID).Updates(map[string]interface{}{
+		"released_by":  userID,
+		"released_at":  now,
+		"release_note": note,
+	}).Error
 }
 
-// QcProcessStatusUpdate updates the QC process status of an order
-// @Summary Update QC Process Status
-// @Description Update the QC process status of an order
+// HoldOrder puts an order on hold so it cannot be assigned to a picker or start QC
+// @Summary Hold Order
+// @Description Put an in progress order on hold with a reason, e.g. while confirming the address with the buyer. Held orders cannot be assigned to a picker or start QC until released.
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Order ID"
-// @Success 200 {object} utils.SuccessResponse{data=models.Order}
+// @Param request body HoldOrderRequest true "Hold reason"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
-// @Router /api/orders/{id}/status/qc-process [put]
-func (oc *OrderController) QCProcessStatusUpdate(c fiber.Ctx) error {
+// @Router /api/orders/{id}/hold [put]
+func (oc *OrderController) HoldOrder(c fiber.Ctx) error {
+	log.Println("HoldOrder called")
 	// Parse id parameter
 	id := c.Params("id")
 	var order models.Order
-	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("id = ?", id).First(&order).Error; err != nil {
-		log.Println("QCProcessStatusUpdate - Order not found:", err)
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("HoldOrder - Order not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Order with id " + id + " not found.",
 		})
 	}
 
-	// Check if order processing status allows modification
-	if order.ProcessingStatus == "qc_progress" {
-		log.Println("QCProcessStatusUpdate - Order is already in qc process status.")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	// Getting current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Order is already in qc process status.",
+			Error:   "Invalid user ID",
 		})
 	}
 
-	// Check if order is canceled
-	if order.EventStatus == "canceled" {
-		log.Println("QCProcessStatusUpdate - Canceled order cannot be updated to qc process status.")
+	// Binding request body
+	var req HoldOrderRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("HoldOrder - Invalid request body:", err)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Canceled order cannot be updated to qc process status.",
+			Error:   "Invalid request body",
 		})
 	}
 
-	// Update order processing status to "qc process"
-	order.ProcessingStatus = "qc_progress"
-
-	if err := oc.DB.Save(&order).Error; err != nil {
-		log.Println("QCProcessStatusUpdate - Failed to update order processing status:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to update order processing status",
+			Error:   "Hold reason is required",
 		})
 	}
 
-	// Reload the data with fresh query
-	var reloadedOrder models.Order
-	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").First(&reloadedOrder, order.ID).Error; err != nil {
-		log.Println("QCProcessStatusUpdate - Failed to load order:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	// Only in progress orders that have not been shipped can be held
+	if order.EventStatus == "held" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to load order",
+			Error:   "Order is already on hold",
+		})
+	}
+	if order.EventStatus != "in_progress" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order in " + order.EventStatus + " status cannot be put on hold.",
+		})
+	}
+	if order.ProcessingStatus == "outbound_completed" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order has already been shipped and cannot be put on hold.",
 		})
 	}
 
-	log.Println("QCProcessStatusUpdate completed successfully")
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
-		Success: true,
-		Message: "Order processing status updated to qc process successfully",
+	now := time.Now()
+	userIDUint := uint(userID)
+	err = oc.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).Where("id = ? AND event_status = ?", order.ID, "in_progress").Updates(map[string]interface{}{
+			"event_status": "held",
+			"held_by":      userIDUint,
+			"held_at":      now,
+			"hold_reason":  req.Reason,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderHoldConflict
+		}
+
+		return tx.Create(&models.OrderHold{
+			OrderID: order.ID,
+			Reason:  req.Reason,
+			HeldBy:  userIDUint,
+			HeldAt:  now,
+		}).Error
+	})
+	if errors.Is(err, errOrderHoldConflict) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order status changed, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("HoldOrder - Failed to hold order:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to put order on hold",
+		})
+	}
+
+	// Reload the data with fresh query
+	var reloadedOrder models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Preload("HoldUser").First(&reloadedOrder, order.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load order",
+		})
+	}
+
+	log.Println("HoldOrder completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order put on hold successfully",
 		Data:    reloadedOrder.ToOrderResponse(),
 	})
 }
 
-// PickingCompletedStatusUpdate updates the picking completed status of an order
-// @Summary Update Picking Completed Status
-// @Description Update the picking completed status of an order
+// UnholdOrder releases a held order back to in progress
+// @Summary Unhold Order
+// @Description Release a held order so it can be assigned to a picker and processed again
 // @Tags Orders
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Order ID"
-// @Success 200 {object} utils.SuccessResponse{data=models.Order}
+// @Param request body UnholdOrderRequest false "Release note"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
-// @Router /api/orders/{id}/status/picking-completed [put]
-func (oc *OrderController) PickingCompletedStatusUpdate(c fiber.Ctx) error {
-	log.Println("PickingCompletedStatusUpdate called")
+// @Router /api/orders/{id}/unhold [put]
+func (oc *OrderController) UnholdOrder(c fiber.Ctx) error {
+	log.Println("UnholdOrder called")
 	// Parse id parameter
 	id := c.Params("id")
 	var order models.Order
-	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("id = ?", id).First(&order).Error; err != nil {
-		log.Println("PickingCompletedStatusUpdate - Order not found:", err)
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("UnholdOrder - Order not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Order with id " + id + " not found.",
 		})
 	}
 
-	// Check if order processing status allows modification
-	if order.ProcessingStatus == "picking_completed" {
-		log.Println("PickingCompletedStatusUpdate - Order is already in picking completed status.")
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	// Getting current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Order is already in picking completed status.",
+			Error:   "Invalid user ID",
 		})
 	}
 
-	// Check if order is canceled
-	if order.EventStatus == "canceled" {
-		log.Println("PickingCompletedStatusUpdate - Canceled order cannot be updated to picking completed status.")
+	// Binding request body, the release note is optional
+	var req UnholdOrderRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			log.Println("UnholdOrder - Invalid request body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid request body",
+			})
+		}
+	}
+
+	if order.EventStatus != "held" {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Canceled order cannot be updated to picking completed status.",
+			Error:   "Order is not on hold",
 		})
 	}
 
-	// Update order processing status to "picking_completed"
-	order.ProcessingStatus = "picking_completed"
-	if err := oc.DB.Save(&order).Error; err != nil {
-		log.Println("PickingCompletedStatusUpdate - Failed to update order processing status:", err)
+	now := time.Now()
+	userIDUint := uint(userID)
+	err = oc.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).Where("id = ? AND event_status = ?", order.ID, "held").Updates(map[string]interface{}{
+			"event_status": "in_progress",
+			"held_by":      nil,
+			"held_at":      nil,
+			"hold_reason":  "",
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderHoldConflict
+		}
+
+		return releaseOrderHolds(tx, order.ID, userIDUint, now, strings.TrimSpace(req.Note))
+	})
+	if errors.Is(err, errOrderHoldConflict) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order status changed, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("UnholdOrder - Failed to release order:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to update order processing status",
+			Error:   "Failed to release order from hold",
 		})
 	}
 
 	// Reload the data with fresh query
 	var reloadedOrder models.Order
 	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").First(&reloadedOrder, order.ID).Error; err != nil {
-		log.Println("PickingCompletedStatusUpdate - Failed to load order:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to load order",
 		})
 	}
 
-	log.Println("PickingCompletedStatusUpdate completed successfully")
+	log.Println("UnholdOrder completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
 		Success: true,
-		Message: "Order processing status updated to picking completed successfully",
+		Message: "Order released from hold successfully",
+		Data:    reloadedOrder.ToOrderResponse(),
+	})
+}
+
+// GetOrderHolds retrieves the hold history of an order
+// @Summary Get Order Holds
+// @Description Retrieve the hold history of an order, latest first
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.OrderHoldResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/holds [get]
+func (oc *OrderController) GetOrderHolds(c fiber.Ctx) error {
+	log.Println("GetOrderHolds called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("GetOrderHolds - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	var holds []models.OrderHold
+	if err := oc.DB.Preload("HoldUser").Preload("ReleaseUser").Where("order_id = ?", order.ID).Order("held_at DESC").Find(&holds).Error; err != nil {
+		log.Println("GetOrderHolds - Failed to retrieve order holds:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order holds",
+		})
+	}
+
+	holdList := make([]models.OrderHoldResponse, len(holds))
+	for i, hold := range holds {
+		holdList[i] = *hold.ToResponse()
+	}
+
+	log.Println("GetOrderHolds completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order holds retrieved successfully",
+		Data:    holdList,
+	})
+}
+
+// UpdateOrderPriority changes the priority of an open order
+// @Summary Update Order Priority
+// @Description Change the priority of an order that has not been shipped yet. Urgent and same day orders are listed before normal ones in the picking and QC queues
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body UpdateOrderPriorityRequest true "Priority"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/priority [put]
+func (oc *OrderController) UpdateOrderPriority(c fiber.Ctx) error {
+	log.Println("UpdateOrderPriority called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("UpdateOrderPriority - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateOrderPriorityRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateOrderPriority - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Shipped, canceled and merged orders are out of the queues
+	if order.EventStatus != "in_progress" && order.EventStatus != "held" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Priority of an order in " + order.EventStatus + " status cannot be changed.",
+		})
+	}
+	if order.ProcessingStatus == "outbound_completed" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order has already been shipped, its priority cannot be changed.",
+		})
+	}
+
+	if err := oc.DB.Model(&order).Update("priority", req.Priority).Error; err != nil {
+		log.Println("UpdateOrderPriority - Failed to update priority:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update order priority",
+		})
+	}
+
+	// Reload the data with fresh query
+	var reloadedOrder models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Preload("HoldUser").First(&reloadedOrder, order.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load order",
+		})
+	}
+
+	log.Println("UpdateOrderPriority completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order priority updated to " + req.Priority + " successfully",
 		Data:    reloadedOrder.ToOrderResponse(),
 	})
 }
+
+// NormalizeOrderAddress re-runs address normalization on an order
+// @Summary Normalize Order Address
+// @Description Re-run address normalization with the configured provider and store the parsed province, city, district and postal code
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/address/normalize [put]
+func (oc *OrderController) NormalizeOrderAddress(c fiber.Ctx) error {
+	log.Println("NormalizeOrderAddress called")
+	if oc.AddressProvider == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Address normalization is not configured",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("NormalizeOrderAddress - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	utils.NormalizeOrderAddress(c.Context(), oc.AddressProvider, &order)
+	if err := oc.DB.Model(&order).Updates(map[string]interface{}{
+		"province":       order.Province,
+		"city":           order.City,
+		"district":       order.District,
+		"postal_code":    order.PostalCode,
+		"address_status": order.AddressStatus,
+	}).Error; err != nil {
+		log.Println("NormalizeOrderAddress - Failed to update order address:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update order address",
+		})
+	}
+
+	log.Println("NormalizeOrderAddress completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order address normalized successfully",
+		Data:    order.ToOrderResponse(),
+	})
+}
+
+// UpdateOrderAddress changes the shipping address of an order before outbound
+// @Summary Update Order Address
+// @Description Change the shipping address of an order that has not gone through outbound yet. The address is normalized again, the courier is checked to still deliver to the new province, the shipping label is regenerated and the previous address is kept in the address change history for disputes
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body UpdateOrderAddressRequest true "New address and reason"
+// @Success 200 {object} utils.SuccessResponse{data=UpdateOrderAddressResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/address [patch]
+func (oc *OrderController) UpdateOrderAddress(c fiber.Ctx) error {
+	log.Println("UpdateOrderAddress called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("UpdateOrderAddress - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	// Getting current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req UpdateOrderAddressRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateOrderAddress - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Address = strings.TrimSpace(req.Address)
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Address == "" || req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Address and reason are required",
+		})
+	}
+
+	// The address can only change while the parcel is still in the warehouse
+	if order.ProcessingStatus == "outbound_completed" {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order has already been shipped and its address cannot be changed.",
+		})
+	}
+	if order.EventStatus == "canceled" || order.EventStatus == "merged" || order.EventStatus == "duplicated" || order.EventStatus == "completed" {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order in " + order.EventStatus + " status cannot have its address changed.",
+		})
+	}
+	if req.Address == order.Address {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "New address is the same as the current address",
+		})
+	}
+
+	change := models.OrderAddressChange{
+		OrderID:            order.ID,
+		TrackingNumber:     order.TrackingNumber,
+		PreviousAddress:    order.Address,
+		PreviousProvince:   order.Province,
+		PreviousCity:       order.City,
+		PreviousDistrict:   order.District,
+		PreviousPostalCode: order.PostalCode,
+		Reason:             req.Reason,
+		ChangedBy:          uint(userID),
+	}
+
+	// Normalize the new address, without a provider only the postal code typed in it is kept
+	order.Address = req.Address
+	if oc.AddressProvider != nil {
+		utils.NormalizeOrderAddress(c.Context(), oc.AddressProvider, &order)
+	} else {
+		order.Province = ""
+		order.City = ""
+		order.District = ""
+		order.PostalCode = utils.ExtractPostalCode(order.Address)
+		order.AddressStatus = ""
+	}
+
+	// Check the courier still delivers to the new address
+	serviceability, err := utils.CheckCourierServiceability(oc.DB, &order)
+	if err != nil {
+		log.Println("UpdateOrderAddress - Failed to check courier serviceability:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check courier serviceability",
+		})
+	}
+	if !serviceability.Serviceable {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Courier cannot deliver to the new address: " + serviceability.Reason,
+		})
+	}
+
+	// Regenerate the shipping label for the new address
+	language := utils.OrderLabelLanguage(utils.FindOrderChannel(oc.DB, &order))
+	store := utils.FindOrderStore(oc.DB, &order)
+	label := utils.BuildShippingLabelDocument(&order, store, utils.LoadPrintBranding(c.Context(), oc.DB, store, models.PrintDocumentLabel), language)
+	buffer, err := utils.BuildTextPDF([]utils.PDFDocument{label})
+	if err != nil {
+		log.Println("UpdateOrderAddress - Failed to render label:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to render shipping label",
+		})
+	}
+	now := time.Now()
+	change.LabelKey = fmt.Sprintf("labels/%d/%d.pdf", order.ID, now.UnixNano())
+	if err := utils.GetStorage().Put(c.Context(), change.LabelKey, buffer.Bytes(), utils.PDFContentType); err != nil {
+		log.Println("UpdateOrderAddress - Failed to store label:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to store shipping label",
+		})
+	}
+
+	change.NewAddress = order.Address
+	change.NewProvince = order.Province
+	change.NewCity = order.City
+	change.NewDistrict = order.District
+	change.NewPostalCode = order.PostalCode
+
+	// Update the order and record the change together
+	userIDUint := uint(userID)
+	order.ChangedBy = &userIDUint
+	order.ChangedAt = &now
+	if err := oc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&order).Updates(map[string]interface{}{
+			"address":        order.Address,
+			"province":       order.Province,
+			"city":           order.City,
+			"district":       order.District,
+			"postal_code":    order.PostalCode,
+			"address_status": order.AddressStatus,
+			"changed_by":     order.ChangedBy,
+			"changed_at":     order.ChangedAt,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&change).Error
+	}); err != nil {
+		log.Println("UpdateOrderAddress - Failed to update order address:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update order address",
+		})
+	}
+
+	// Reload the data with fresh query
+	var reloadedOrder models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").First(&reloadedOrder, order.ID).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load order",
+		})
+	}
+	oc.DB.Preload("ChangeUser").First(&change, change.ID)
+
+	log.Println("UpdateOrderAddress completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order address updated successfully",
+		Data: UpdateOrderAddressResponse{
+			Order:          *reloadedOrder.ToOrderResponse(),
+			Change:         *change.ToResponse(),
+			Serviceability: serviceability,
+			Label:          utils.SignDownloadURL(oc.Config, change.LabelKey, fmt.Sprintf("label-%s.pdf", order.TrackingNumber), now),
+		},
+	})
+}
+
+// GetOrderAddressChanges retrieves the address change history of an order
+// @Summary Get Order Address Changes
+// @Description Retrieve the shipping address changes of an order with the previous and new address, latest first
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.OrderAddressChangeResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/address-changes [get]
+func (oc *OrderController) GetOrderAddressChanges(c fiber.Ctx) error {
+	log.Println("GetOrderAddressChanges called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("GetOrderAddressChanges - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	var changes []models.OrderAddressChange
+	if err := oc.DB.Preload("ChangeUser").Where("order_id = ?", order.ID).Order("created_at DESC, id DESC").Find(&changes).Error; err != nil {
+		log.Println("GetOrderAddressChanges - Failed to retrieve order address changes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order address changes",
+		})
+	}
+
+	changeList := make([]models.OrderAddressChangeResponse, len(changes))
+	for i, change := range changes {
+		changeList[i] = *change.ToResponse()
+	}
+
+	log.Println("GetOrderAddressChanges completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order address changes retrieved successfully",
+		Data:    changeList,
+	})
+}
+
+// SplitOrder moves selected order details into a new shipment with its own tracking number
+// @Summary Split Order
+// @Description Move selected details, or part of their unpicked quantity, into a child order with its own tracking number so it can go through QC and outbound separately
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body SplitOrderRequest true "Tracking number and details to move"
+// @Success 201 {object} utils.SuccessResponse{data=OrderShipmentsResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/split [post]
+func (oc *OrderController) SplitOrder(c fiber.Ctx) error {
+	log.Println("SplitOrder called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Preload("OrderDetails").Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("SplitOrder - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	// Getting current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req SplitOrderRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SplitOrder - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
+	if req.TrackingNumber == "" || len(req.Details) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Tracking number and at least one detail are required",
+		})
+	}
+	if err := utils.ValidateTrackingNumber(oc.DB, req.TrackingNumber); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// Only in progress orders that have not reached QC can be split
+	if order.EventStatus != "in_progress" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order in " + order.EventStatus + " status cannot be split.",
+		})
+	}
+	splittable := false
+	for _, status := range splittableProcessingStatuses {
+		if order.ProcessingStatus == status {
+			splittable = true
+			break
+		}
+	}
+	if !splittable {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order cannot be split in " + order.ProcessingStatus + " status.",
+		})
+	}
+
+	// Check the new tracking number is not used by another order
+	var existingOrder models.Order
+	if err := oc.DB.Where("tracking_number = ?", req.TrackingNumber).First(&existingOrder).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with tracking number " + req.TrackingNumber + " already exists.",
+		})
+	}
+
+	// Validate the details to move, partial moves only take the unpicked quantity
+	detailsByID := make(map[uint]models.OrderDetail, len(order.OrderDetails))
+	remainingQuantity := 0
+	for _, detail := range order.OrderDetails {
+		detailsByID[detail.ID] = detail
+		remainingQuantity += detail.Quantity
+	}
+	moveQuantities := make(map[uint]int, len(req.Details))
+	for _, detailReq := range req.Details {
+		detail, ok := detailsByID[detailReq.DetailID]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Detail %d does not belong to this order", detailReq.DetailID),
+			})
+		}
+		if _, duplicate := moveQuantities[detail.ID]; duplicate {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Detail %d is listed more than once", detail.ID),
+			})
+		}
+
+		quantity := detailReq.Quantity
+		if quantity == 0 {
+			quantity = detail.Quantity
+		}
+		if quantity > detail.Quantity {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Cannot move %d of %s, the order only has %d", quantity, detail.SKU, detail.Quantity),
+			})
+		}
+		if quantity < detail.Quantity && quantity > detail.Quantity-detail.PickedQuantity {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Only the %d unpicked units of %s can be moved without moving the whole detail", detail.Quantity-detail.PickedQuantity, detail.SKU),
+			})
+		}
+		moveQuantities[detail.ID] = quantity
+		remainingQuantity -= quantity
+	}
+	if remainingQuantity <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one item must stay on the original order",
+		})
+	}
+
+	// Shipments always hang off the root order so the combined view has a single parent
+	rootOrderID := order.ID
+	rootOrderGineeID := order.OrderGineeID
+	if order.ParentOrderID != nil {
+		var rootOrder models.Order
+		if err := oc.DB.Where("id = ?", *order.ParentOrderID).First(&rootOrder).Error; err != nil {
+			log.Println("SplitOrder - Failed to load parent order:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to load parent order",
+			})
+		}
+		rootOrderID = rootOrder.ID
+		rootOrderGineeID = rootOrder.OrderGineeID
+	}
+
+	now := time.Now()
+	userIDUint := uint(userID)
+	var shipment models.Order
+	err = oc.DB.Transaction(func(tx *gorm.DB) error {
+		// Touch the order only if nothing changed since it was loaded
+		result := tx.Model(&models.Order{}).Where("id = ? AND event_status = ? AND processing_status = ?", order.ID, "in_progress", order.ProcessingStatus).Updates(map[string]interface{}{
+			"changed_by": userIDUint,
+			"changed_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderSplitConflict
+		}
+
+		// Shipments are numbered after the parent, which is shipment 1
+		var shipmentCount int64
+		if err := tx.Model(&models.Order{}).Where("parent_order_id = ?", rootOrderID).Count(&shipmentCount).Error; err != nil {
+			return err
+		}
+
+		// A shipment made only of picked items keeps the picking result, anything else goes back to picking
+		allPicked := order.ProcessingStatus == "picking_completed"
+		for detailID, quantity := range moveQuantities {
+			detail := detailsByID[detailID]
+			if quantity < detail.Quantity || !detail.IsPicked {
+				allPicked = false
+			}
+		}
+
+		shipment = models.Order{
+			OrderGineeID:     fmt.Sprintf("%s-S%d", rootOrderGineeID, shipmentCount+2),
+			ProcessingStatus: "ready_to_pick",
+			EventStatus:      "in_progress",
+			Channel:          order.Channel,
+			Store:            order.Store,
+			Buyer:            order.Buyer,
+			Address:          order.Address,
+			Province:         order.Province,
+			City:             order.City,
+			District:         order.District,
+			PostalCode:       order.PostalCode,
+			AddressStatus:    order.AddressStatus,
+			Courier:          order.Courier,
+			TrackingNumber:   req.TrackingNumber,
+			SentBefore:       order.SentBefore,
+			Currency:         order.Currency,
+			OrderSource:      order.OrderSource,
+			Priority:         order.Priority,
+			GiftMessage:      order.GiftMessage,
+			HandlingNote:     order.HandlingNote,
+			ParentOrderID:    &rootOrderID,
+			SplitBy:          &userIDUint,
+			SplitAt:          &now,
+		}
+		if allPicked {
+			shipment.ProcessingStatus = "picking_completed"
+			shipment.AssignedBy = order.AssignedBy
+			shipment.AssignedAt = order.AssignedAt
+			shipment.PickedBy = order.PickedBy
+			shipment.PickedAt = order.PickedAt
+		}
+		if err := tx.Create(&shipment).Error; err != nil {
+			return err
+		}
+
+		for detailID, quantity := range moveQuantities {
+			detail := detailsByID[detailID]
+
+			// Whole details move with their picking checklist and shortages
+			if quantity == detail.Quantity {
+				if err := tx.Model(&models.OrderDetail{}).Where("id = ?", detail.ID).Update("order_id", shipment.ID).Error; err != nil {
+					return err
+				}
+				if err := tx.Model(&models.PickShortage{}).Where("order_detail_id = ?", detail.ID).Updates(map[string]interface{}{
+					"order_id":        shipment.ID,
+					"tracking_number": shipment.TrackingNumber,
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Partial moves take unpicked units, the picked units stay on the original detail
+			remaining := detail.Quantity - quantity
+			if err := tx.Model(&models.OrderDetail{}).Where("id = ?", detail.ID).Updates(map[string]interface{}{
+				"quantity":  remaining,
+				"is_picked": detail.PickedQuantity >= remaining,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.OrderDetail{
+				OrderID:     shipment.ID,
+				SKU:         detail.SKU,
+				ProductName: detail.ProductName,
+				Variant:     detail.Variant,
+				Quantity:    quantity,
+				Price:       detail.Price,
+				Note:        detail.Note,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return models.RecalculateOrderTotals(tx.Where("id IN ?", []uint{order.ID, shipment.ID}))
+	})
+	if errors.Is(err, errOrderSplitConflict) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order status changed, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("SplitOrder - Failed to split order:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to split order",
+		})
+	}
+
+	shipments, err := oc.loadOrderShipments(rootOrderID)
+	if err != nil {
+		log.Println("SplitOrder - Failed to load shipments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load shipments",
+		})
+	}
+
+	log.Println("SplitOrder completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order split successfully, shipment " + shipment.OrderGineeID + " created",
+		Data:    shipments,
+	})
+}
+
+// GetOrderShipments retrieves the combined shipment view of a split order
+// @Summary Get Order Shipments
+// @Description Retrieve the parent order and every shipment split from it, with how many have been shipped. Works with the id of the parent or of any shipment.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=OrderShipmentsResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/shipments [get]
+func (oc *OrderController) GetOrderShipments(c fiber.Ctx) error {
+	log.Println("GetOrderShipments called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("GetOrderShipments - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	rootOrderID := order.ID
+	if order.ParentOrderID != nil {
+		rootOrderID = *order.ParentOrderID
+	}
+
+	shipments, err := oc.loadOrderShipments(rootOrderID)
+	if err != nil {
+		log.Println("GetOrderShipments - Failed to load shipments:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load shipments",
+		})
+	}
+
+	log.Println("GetOrderShipments completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order shipments retrieved successfully",
+		Data:    shipments,
+	})
+}
+
+// GetOrderInvoice renders the invoice of an order as a PDF
+// @Summary Get Order Invoice
+// @Description Render the invoice of an order with its items and prices, branded with the order's store and printed in the label language of its channel. Available for every order so invoices can be reprinted, regardless of the channel invoice toggle.
+// @Tags Orders
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {file} file
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/invoice [get]
+func (oc *OrderController) GetOrderInvoice(c fiber.Ctx) error {
+	log.Println("GetOrderInvoice called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Preload("OrderDetails").Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("GetOrderInvoice - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	utils.LoadOrderSerials(oc.DB, &order)
+	language := utils.OrderLabelLanguage(utils.FindOrderChannel(oc.DB, &order))
+	store := utils.FindOrderStore(oc.DB, &order)
+	invoice := utils.BuildInvoiceDocument(&order, store, utils.LoadPrintBranding(c.Context(), oc.DB, store, models.PrintDocumentInvoice), language)
+
+	buffer, err := utils.BuildTextPDF([]utils.PDFDocument{invoice})
+	if err != nil {
+		log.Println("GetOrderInvoice - Failed to render PDF:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to render invoice",
+		})
+	}
+
+	log.Println("GetOrderInvoice completed successfully")
+	c.Set(fiber.HeaderContentType, utils.PDFContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"invoice-%s.pdf\"", order.OrderGineeID))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// loadOrderShipments builds the combined view of a parent order and its split shipments
+func (oc *OrderController) loadOrderShipments(rootOrderID uint) (*OrderShipmentsResponse, error) {
+	var orders []models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Preload("HoldUser").Preload("SplitUser").
+		Where("id = ? OR parent_order_id = ?", rootOrderID, rootOrderID).Order("id ASC").Find(&orders).Error; err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 || orders[0].ID != rootOrderID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	response := &OrderShipmentsResponse{
+		ParentOrder:    *orders[0].ToOrderResponse(),
+		Shipments:      make([]models.OrderResponse, len(orders)),
+		TotalShipments: len(orders),
+	}
+	for i, order := range orders {
+		response.Shipments[i] = *order.ToOrderResponse()
+		if order.ProcessingStatus == "outbound_completed" {
+			response.ShippedShipments++
+		}
+	}
+	response.FullyShipped = response.ShippedShipments == response.TotalShipments
+
+	return response, nil
+}
+
+// MergeOrders combines orders of the same buyer into a single pick and pack task
+// @Summary Merge Orders
+// @Description Move the details of several ready to pick orders for the same buyer and address into one primary order. The other orders keep their marketplace references, are marked as merged and are completed when the primary order is shipped.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MergeOrdersRequest true "Orders to merge"
+// @Success 200 {object} utils.SuccessResponse{data=MergeOrdersResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/merge [post]
+func (oc *OrderController) MergeOrders(c fiber.Ctx) error {
+	log.Println("MergeOrders called")
+	// Getting current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req MergeOrdersRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("MergeOrders - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Deduplicate order ids, keeping the request order
+	var orderIDs []uint
+	seen := make(map[uint]bool)
+	for _, orderID := range req.OrderIDs {
+		if !seen[orderID] {
+			seen[orderID] = true
+			orderIDs = append(orderIDs, orderID)
+		}
+	}
+	if len(orderIDs) < 2 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least two different orders are required to merge",
+		})
+	}
+	primaryOrderID := req.PrimaryOrderID
+	if primaryOrderID == 0 {
+		primaryOrderID = orderIDs[0]
+	}
+	if !seen[primaryOrderID] {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Primary order must be one of the orders to merge",
+		})
+	}
+
+	var orders []models.Order
+	if err := oc.DB.Where("id IN ?", orderIDs).Find(&orders).Error; err != nil {
+		log.Println("MergeOrders - Failed to retrieve orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve orders",
+		})
+	}
+	if len(orders) != len(orderIDs) {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "One or more orders not found",
+		})
+	}
+
+	// All orders must be ready to pick and go to the same buyer and address
+	var primaryOrder models.Order
+	for _, order := range orders {
+		if order.ID == primaryOrderID {
+			primaryOrder = order
+		}
+	}
+	for _, order := range orders {
+		if order.EventStatus != "in_progress" || order.ProcessingStatus != "ready_to_pick" {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Order " + order.OrderGineeID + " is not ready to pick and cannot be merged.",
+			})
+		}
+		if !strings.EqualFold(strings.TrimSpace(order.Buyer), strings.TrimSpace(primaryOrder.Buyer)) ||
+			!strings.EqualFold(strings.Join(strings.Fields(order.Address), " "), strings.Join(strings.Fields(primaryOrder.Address), " ")) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Order " + order.OrderGineeID + " has a different buyer or address than " + primaryOrder.OrderGineeID + ".",
+			})
+		}
+		if order.Currency != primaryOrder.Currency {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Order " + order.OrderGineeID + " has a different currency than " + primaryOrder.OrderGineeID + ".",
+			})
+		}
+	}
+
+	now := time.Now()
+	userIDUint := uint(userID)
+	err = oc.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).Where("id = ? AND event_status = ? AND processing_status = ?", primaryOrderID, "in_progress", "ready_to_pick").Updates(map[string]interface{}{
+			"changed_by": userIDUint,
+			"changed_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderMergeConflict
+		}
+
+		for _, order := range orders {
+			if order.ID == primaryOrderID {
+				continue
+			}
+
+			result := tx.Model(&models.Order{}).Where("id = ? AND event_status = ? AND processing_status = ?", order.ID, "in_progress", "ready_to_pick").Updates(map[string]interface{}{
+				"event_status":   "merged",
+				"merged_into_id": primaryOrderID,
+				"merged_by":      userIDUint,
+				"merged_at":      now,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return errOrderMergeConflict
+			}
+
+			// Details keep a reference to the marketplace order they came from for reconciliation
+			if err := tx.Model(&models.OrderDetail{}).Where("order_id = ? AND source_order_id IS NULL", order.ID).Update("source_order_id", order.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.OrderDetail{}).Where("order_id = ?", order.ID).Update("order_id", primaryOrderID).Error; err != nil {
+				return err
+			}
+		}
+
+		// The primary order now carries the value of the merged orders
+		return models.RecalculateOrderTotals(tx.Where("id IN ?", orderIDs))
+	})
+	if errors.Is(err, errOrderMergeConflict) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order status changed, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("MergeOrders - Failed to merge orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to merge orders",
+		})
+	}
+
+	// Reload the data with fresh query
+	var reloadedOrders []models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Preload("MergeUser").
+		Where("id IN ?", orderIDs).Order("id ASC").Find(&reloadedOrders).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load orders",
+		})
+	}
+
+	response := MergeOrdersResponse{MergedOrders: []models.OrderResponse{}}
+	for _, order := range reloadedOrders {
+		if order.ID == primaryOrderID {
+			response.PrimaryOrder = *order.ToOrderResponse()
+			continue
+		}
+		response.MergedOrders = append(response.MergedOrders, *order.ToOrderResponse())
+	}
+
+	log.Println("MergeOrders completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d orders merged into %s successfully", len(response.MergedOrders), primaryOrder.OrderGineeID),
+		Data:    response,
+	})
+}
+
+// CleanupCanceledOrders applies cancellation to QC and outbound records left behind by canceled orders
+// @Summary Cleanup Canceled Orders
+// @Description Close open QC records, flag QC and outbound records and zero detail quantities of every canceled order that is still inconsistent. See /api/reports/cancel-inconsistencies for the records this touches.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=map[string]int}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/cancel-cleanup [post]
+func (oc *OrderController) CleanupCanceledOrders(c fiber.Ctx) error {
+	log.Println("CleanupCanceledOrders called")
+	// Canceled orders with open QC records, unflagged QC or outbound records, or remaining quantities
+	var orders []models.Order
+	if err := oc.DB.Select("id, tracking_number").Where("event_status = ?", models.EventStatusCanceled).
+		Where("tracking_number IN (?) OR tracking_number IN (?) OR tracking_number IN (?) OR id IN (?)",
+			oc.DB.Model(&models.QCRibbon{}).Select("tracking_number").Where("order_canceled = ? OR status IN ?", false, []string{"in_progress", "pending"}),
+			oc.DB.Model(&models.QCOnline{}).Select("tracking_number").Where("order_canceled = ? OR status IN ?", false, []string{"in_progress", "pending"}),
+			oc.DB.Model(&models.Outbound{}).Select("tracking_number").Where("order_canceled = ?", false),
+			oc.DB.Model(&models.OrderDetail{}).Select("order_id").Where("quantity > ?", 0)).
+		Find(&orders).Error; err != nil {
+		log.Println("CleanupCanceledOrders - Failed to retrieve inconsistent orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve inconsistent orders",
+		})
+	}
+
+	for _, order := range orders {
+		err := oc.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.OrderDetail{}).Where("order_id = ? AND quantity > 0", order.ID).Update("quantity", 0).Error; err != nil {
+				return err
+			}
+			return propagateOrderCancel(tx, order.TrackingNumber)
+		})
+		if err != nil {
+			log.Println("CleanupCanceledOrders - Failed to clean up order", order.ID, ":", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to clean up canceled orders",
+			})
+		}
+	}
+
+	log.Println("CleanupCanceledOrders completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d canceled orders cleaned up successfully", len(orders)),
+		Data:    map[string]int{"cleanedOrders": len(orders)},
+	})
+}
+
+// GetOrderSummary retrieves order counts per status for the operations dashboard
+// @Summary Get Order Summary
+// @Description Retrieve the number of open orders per processing status, event status and priority, and the orders currently on hold, oldest hold first
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=OrderSummaryResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/summary [get]
+func (oc *OrderController) GetOrderSummary(c fiber.Ctx) error {
+	log.Println("GetOrderSummary called")
+	var response OrderSummaryResponse
+
+	// Count open orders per processing status, shipped and canceled orders are excluded
+	if err := oc.DB.Model(&models.Order{}).Select("processing_status as status, COUNT(*) as count").
+		Where("event_status IN ?", []string{"in_progress", "held"}).
+		Group("processing_status").Order("processing_status").Scan(&response.ProcessingStatuses).Error; err != nil {
+		log.Println("GetOrderSummary - Failed to count processing statuses:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order summary",
+		})
+	}
+
+	if err := oc.DB.Model(&models.Order{}).Select("event_status as status, COUNT(*) as count").
+		Group("event_status").Order("event_status").Scan(&response.EventStatuses).Error; err != nil {
+		log.Println("GetOrderSummary - Failed to count event statuses:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order summary",
+		})
+	}
+
+	// Count open orders per priority, so urgent orders are not lost behind the backlog
+	var priorityCounts []OrderPriorityCount
+	if err := oc.DB.Model(&models.Order{}).Select(`priority, COUNT(*) as count,
+		COUNT(*) FILTER (WHERE processing_status IN ?) as ready_to_pick,
+		COUNT(*) FILTER (WHERE processing_status IN ?) as awaiting_qc,
+		COUNT(*) FILTER (WHERE sent_before < ?) as past_due`,
+		[]string{models.ProcessingStatusReadyToPick, models.ProcessingStatusPickingPending},
+		[]string{models.ProcessingStatusPickingCompleted, models.ProcessingStatusQCProgress},
+		time.Now()).
+		Where("event_status IN ?", []string{"in_progress", "held"}).
+		Group("priority").Scan(&priorityCounts).Error; err != nil {
+		log.Println("GetOrderSummary - Failed to count priorities:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order summary",
+		})
+	}
+	countsByPriority := make(map[string]OrderPriorityCount, len(priorityCounts))
+	for _, count := range priorityCounts {
+		countsByPriority[count.Priority] = count
+	}
+	for _, priority := range []string{models.OrderPrioritySameDay, models.OrderPriorityUrgent, models.OrderPriorityNormal} {
+		count := countsByPriority[priority]
+		count.Priority = priority
+		response.Priorities = append(response.Priorities, count)
+	}
+
+	// Orders currently on hold, oldest first
+	var heldOrders []models.Order
+	if err := oc.DB.Preload("HoldUser").Where("event_status = ?", "held").Order("held_at ASC").Find(&heldOrders).Error; err != nil {
+		log.Println("GetOrderSummary - Failed to retrieve held orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order summary",
+		})
+	}
+
+	response.OnHold = len(heldOrders)
+	response.HeldOrders = make([]models.OrderResponse, len(heldOrders))
+	for i, order := range heldOrders {
+		response.HeldOrders[i] = *order.ToOrderResponse()
+	}
+
+	log.Println("GetOrderSummary completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order summary retrieved successfully",
+		Data:    response,
+	})
+}
+
+// orderAgingStatuses are the processing statuses shown on the aging dashboard, in flow order
+var orderAgingStatuses = []string{
+	models.ProcessingStatusReadyToPick, models.ProcessingStatusPickingProgress, models.ProcessingStatusPickingPending, models.ProcessingStatusAwaitingStock,
+	models.ProcessingStatusPickingCompleted, models.ProcessingStatusQCProgress, models.ProcessingStatusQCCompleted,
+}
+
+// orderStatusEnteredAt is the SQL expression of when an order entered its current processing status
+const orderStatusEnteredAt = `CASE orders.processing_status
+	WHEN 'picking_progress' THEN COALESCE(orders.assigned_at, orders.created_at)
+	WHEN 'picking_pending' THEN COALESCE(orders.pending_at, orders.created_at)
+	WHEN 'awaiting_stock' THEN COALESCE((SELECT MAX(pick_shortages.created_at) FROM pick_shortages WHERE pick_shortages.order_id = orders.id), orders.updated_at)
+	WHEN 'picking_completed' THEN COALESCE(orders.picked_at, orders.created_at)
+	WHEN 'qc_progress' THEN COALESCE(
+		(SELECT MAX(qc_ribbons.created_at) FROM qc_ribbons WHERE qc_ribbons.tracking_number = orders.tracking_number),
+		(SELECT MAX(qc_onlines.created_at) FROM qc_onlines WHERE qc_onlines.tracking_number = orders.tracking_number),
+		orders.picked_at, orders.created_at)
+	WHEN 'qc_completed' THEN COALESCE(
+		(SELECT MAX(qc_ribbons.updated_at) FROM qc_ribbons WHERE qc_ribbons.tracking_number = orders.tracking_number),
+		(SELECT MAX(qc_onlines.updated_at) FROM qc_onlines WHERE qc_onlines.tracking_number = orders.tracking_number),
+		orders.picked_at, orders.created_at)
+	ELSE orders.created_at
+END`
+
+// GetOrderAging retrieves the SLA aging buckets of open orders per processing status
+// @Summary Get Order Aging
+// @Description Retrieve the number of open orders per processing status split by how long they have been in that status, counted in warehouse business hours when configured: within the configured threshold, overdue, and critical at twice the threshold. Shipped, canceled and merged orders are excluded.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=OrderAgingResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/aging [get]
+func (oc *OrderController) GetOrderAging(c fiber.Ctx) error {
+	log.Println("GetOrderAging called")
+	type orderEntered struct {
+		Status    string
+		EnteredAt time.Time
+	}
+
+	var rows []orderEntered
+	if err := oc.DB.WithContext(c.Context()).Model(&models.Order{}).
+		Select("orders.processing_status as status, "+orderStatusEnteredAt+" as entered_at").
+		Where("orders.event_status IN ? AND orders.processing_status IN ?", []string{"in_progress", "held"}, orderAgingStatuses).
+		Scan(&rows).Error; err != nil {
+		log.Println("GetOrderAging - Failed to retrieve open orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order aging",
+		})
+	}
+
+	now := time.Now()
+	statuses := make(map[string]*OrderAgingStatus, len(orderAgingStatuses))
+	response := OrderAgingResponse{
+		Statuses:    make([]OrderAgingStatus, len(orderAgingStatuses)),
+		GeneratedAt: now.Format("02-01-2006 15:04:05"),
+	}
+	for i, status := range orderAgingStatuses {
+		response.Statuses[i] = OrderAgingStatus{Status: status, ThresholdMinutes: oc.AgingThresholds[status]}
+		statuses[status] = &response.Statuses[i]
+	}
+
+	calendar := utils.GetBusinessCalendar()
+	for _, row := range rows {
+		bucket := statuses[row.Status]
+		ageMinutes := int64(calendar.Duration(row.EnteredAt, now).Minutes())
+		threshold := int64(bucket.ThresholdMinutes)
+
+		bucket.Total++
+		switch {
+		case threshold <= 0 || ageMinutes < threshold:
+			bucket.WithinSLA++
+		case ageMinutes < 2*threshold:
+			bucket.Overdue++
+			response.TotalOverdue++
+		default:
+			bucket.Critical++
+			response.TotalOverdue++
+			response.TotalCritical++
+		}
+		if ageMinutes > bucket.OldestMinutes {
+			bucket.OldestMinutes = ageMinutes
+		}
+	}
+
+	log.Println("GetOrderAging completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Order aging retrieved successfully (%d overdue, %d critical)", response.TotalOverdue, response.TotalCritical),
+		Data:    response,
+	})
+}
+
+// GetAssignedOrders retrieves orders assigned to a all picker
+// @Summary Get Assigned Orders
+// @Description Retrieve orders assigned to a all picker with pagination, date range filtering, and search, by priority and then by SentBefore.
+// @Description Coordinators who belong to a team only see the orders picked by their team.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of items per page" default(10)
+// @Param start_date query string false "Start date for filtering (YYYY-MM-DD)"
+// @Param end_date query string false "End date for filtering (YYYY-MM-DD)"
+// @Param search query string false "Search term for filtering"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.Order}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/assigned [get]
+func (oc *OrderController) GetAssignedOrders(c fiber.Ctx) error {
+	log.Println("GetAssignedOrders called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var orders []models.Order
+
+	// Build base query
+	query := oc.DB.Model(&models.Order{}).Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Order(models.OrderQueueOrder).Where("processing_status = ?", "picking_progress")
+
+	// Date range filter if provided
+	startDate := c.Query("start_date", "")
+	endDate := c.Query("end_date", "")
+	if startDate != "" {
+		// Parse start date and set time to beginning of the day
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid start_date format. Use YYYY-MM-DD.",
+			})
+		}
+		startOfDay := time.Date(parsedStartDate.Year(), parsedStartDate.Month(), parsedStartDate.Day(), 0, 0, 0, 0, parsedStartDate.Location())
+		query = query.Where("created_at >= ?", startOfDay)
+	}
+	if endDate != "" {
+		// Parse end date and set time to end of the day
+		parsedEndDate, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid end_date format. Use YYYY-MM-DD.",
+			})
+		}
+		endOfDay := time.Date(parsedEndDate.Year(), parsedEndDate.Month(), parsedEndDate.Day(), 23, 59, 59, 0, parsedEndDate.Location())
+		query = query.Where("created_at <= ?", endOfDay)
+	}
+
+	// Search condition if provided
+	search := c.Query("search", "")
+	if search != "" {
+		query = query.Where("order_ginee_id ILIKE ? OR tracking_number ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Coordinators with a team only see the orders picked by their team
+	currentUserID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	userRoles, _ := c.Locals("userRoles").([]string)
+	scopeTeamIDs, err := utils.CoordinatorTeamScope(oc.DB, uint(currentUserID), userRoles)
+	if err != nil {
+		log.Println("GetAssignedOrders - Failed to resolve team scope:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve orders",
+		})
+	}
+	if scopeTeamIDs != nil {
+		query = query.Where("picked_by IN (?)", utils.TeamMembersQuery(oc.DB, scopeTeamIDs...))
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Offset(offset).Limit(limit).Find(&orders).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve orders",
+		})
+	}
+
+	// Format response
+	orderList := make([]models.OrderResponse, len(orders))
+	for i, order := range orders {
+		orderList[i] = *order.ToOrderResponse()
+	}
+
+	// Build success message
+	message := "Assigned orders retrieved successfully"
+	var filters []string
+
+	if startDate != "" || endDate != "" {
+		var dateRange []string
+		if startDate != "" {
+			dateRange = append(dateRange, "from: "+startDate)
+		}
+		if endDate != "" {
+			dateRange = append(dateRange, "to: "+endDate)
+		}
+		filters = append(filters, "date: "+strings.Join(dateRange, ", "))
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	// Return response
+	log.Println("GetAssignedOrders completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    orderList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// QcProcessStatusUpdate updates the QC process status of an order
+// @Summary Update QC Process Status
+// @Description Update the QC process status of an order
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.Order}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/status/qc-process [put]
+func (oc *OrderController) QCProcessStatusUpdate(c fiber.Ctx) error {
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("QCProcessStatusUpdate - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	// Check if order processing status allows modification
+	if order.ProcessingStatus == "qc_progress" {
+		log.Println("QCProcessStatusUpdate - Order is already in qc process status.")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order is already in qc process status.",
+		})
+	}
+
+	// Check if order is canceled
+	if order.EventStatus == "canceled" {
+		log.Println("QCProcessStatusUpdate - Canceled order cannot be updated to qc process status.")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Canceled order cannot be updated to qc process status.",
+		})
+	}
+
+	// Update order processing status to "qc process", only if nobody changed it in the meantime
+	err := oc.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND processing_status = ? AND event_status <> ?", order.ID, order.ProcessingStatus, "canceled").
+			Update("processing_status", "qc_progress")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderStatusConflict
+		}
+		return nil
+	})
+	if errors.Is(err, errOrderStatusConflict) {
+		log.Println("QCProcessStatusUpdate - Order status changed during update:", order.TrackingNumber)
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order status changed, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("QCProcessStatusUpdate - Failed to update order processing status:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update order processing status",
+		})
+	}
+
+	// Reload the data with fresh query
+	var reloadedOrder models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").First(&reloadedOrder, order.ID).Error; err != nil {
+		log.Println("QCProcessStatusUpdate - Failed to load order:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load order",
+		})
+	}
+
+	log.Println("QCProcessStatusUpdate completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order processing status updated to qc process successfully",
+		Data:    reloadedOrder.ToOrderResponse(),
+	})
+}
+
+// PickingCompletedStatusUpdate updates the picking completed status of an order
+// @Summary Update Picking Completed Status
+// @Description Update the picking completed status of an order
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.Order}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/status/picking-completed [put]
+func (oc *OrderController) PickingCompletedStatusUpdate(c fiber.Ctx) error {
+	log.Println("PickingCompletedStatusUpdate called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("PickingCompletedStatusUpdate - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	// Check if order processing status allows modification
+	if order.ProcessingStatus == "picking_completed" {
+		log.Println("PickingCompletedStatusUpdate - Order is already in picking completed status.")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order is already in picking completed status.",
+		})
+	}
+
+	// Check if order is canceled
+	if order.EventStatus == "canceled" {
+		log.Println("PickingCompletedStatusUpdate - Canceled order cannot be updated to picking completed status.")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Canceled order cannot be updated to picking completed status.",
+		})
+	}
+
+	// Update order processing status to "picking_completed", only if nobody changed it in the meantime
+	err := oc.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND processing_status = ? AND event_status <> ?", order.ID, order.ProcessingStatus, "canceled").
+			Update("processing_status", "picking_completed")
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOrderStatusConflict
+		}
+		return nil
+	})
+	if errors.Is(err, errOrderStatusConflict) {
+		log.Println("PickingCompletedStatusUpdate - Order status changed during update:", order.TrackingNumber)
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order status changed, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("PickingCompletedStatusUpdate - Failed to update order processing status:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update order processing status",
+		})
+	}
+
+	// Reload the data with fresh query
+	var reloadedOrder models.Order
+	if err := oc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").First(&reloadedOrder, order.ID).Error; err != nil {
+		log.Println("PickingCompletedStatusUpdate - Failed to load order:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load order",
+		})
+	}
+
+	log.Println("PickingCompletedStatusUpdate completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order processing status updated to picking completed successfully",
+		Data:    reloadedOrder.ToOrderResponse(),
+	})
+}
+
+// parseBulkSyncStatusCSV parses CSV rows of order ginee id and external status
+// A header row (orderGineeId, externalStatus) is detected and skipped, otherwise the first two columns are used
+func parseBulkSyncStatusCSV(reader io.Reader) ([]BulkSyncStatusRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	normalize := func(value string) string {
+		return strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(value)))
+	}
+
+	idCol, statusCol := 0, 1
+	if len(records) > 0 {
+		header := records[0]
+		headerIdCol, headerStatusCol := -1, -1
+		for i, column := range header {
+			switch normalize(column) {
+			case "ordergineeid", "orderid":
+				headerIdCol = i
+			case "externalstatus", "status", "orderstatus":
+				headerStatusCol = i
+			}
+		}
+		if headerIdCol >= 0 && headerStatusCol >= 0 {
+			idCol, statusCol = headerIdCol, headerStatusCol
+			records = records[1:]
+		}
+	}
+
+	rows := make([]BulkSyncStatusRow, 0, len(records))
+	for _, record := range records {
+		if len(record) <= idCol || len(record) <= statusCol {
+			return nil, errors.New("each CSV row must contain order ginee id and external status")
+		}
+		rows = append(rows, BulkSyncStatusRow{
+			OrderGineeID:   record[idCol],
+			ExternalStatus: record[statusCol],
+		})
+	}
+
+	return rows, nil
+}
+
+// syncOrderEventStatus applies a mapped external event status to a single order
+// Returns the row result (updated, unchanged or skipped) and the reason when the row was not updated
+func (oc *OrderController) syncOrderEventStatus(order *models.Order, eventStatus string, userID uint) (string, string, error) {
+	if order.EventStatus == eventStatus {
+		return "unchanged", "", nil
+	}
+
+	// Held orders are released by customer service, only a marketplace cancellation overrides a hold
+	if order.EventStatus == models.EventStatusHeld && eventStatus != models.EventStatusCanceled {
+		return "skipped", "Order is on hold, release it first", nil
+	}
+
+	switch eventStatus {
+	case models.EventStatusCanceled:
+		// Orders physically in process or already shipped must be handled manually
+		if order.ProcessingStatus == "picking_progress" || order.ProcessingStatus == "qc_progress" {
+			return "skipped", "Order is in " + order.ProcessingStatus + ", cancel it manually", nil
+		}
+		if order.ProcessingStatus == "outbound_completed" {
+			return "skipped", "Order has already been shipped", nil
+		}
+
+		// Mirror CancelOrder: mark canceled and set all order details quantity to zero
+		now := time.Now()
+		err := oc.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(order).Updates(map[string]interface{}{
+				"event_status": models.EventStatusCanceled,
+				"canceled_by":  userID,
+				"canceled_at":  now,
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.OrderDetail{}).Where("order_id = ?", order.ID).Update("quantity", 0).Error; err != nil {
+				return err
+			}
+			if err := releaseOrderHolds(tx, order.ID, userID, now, "Order canceled"); err != nil {
+				return err
+			}
+			return propagateOrderCancel(tx, order.TrackingNumber)
+		})
+		if err != nil {
+			return "", "", err
+		}
+		return "updated", "", nil
+
+	case models.EventStatusCompleted:
+		if order.EventStatus == models.EventStatusCanceled {
+			return "skipped", "Canceled orders cannot be completed", nil
+		}
+		if order.ProcessingStatus != "outbound_completed" {
+			return "skipped", "Order has not been shipped by the warehouse yet", nil
+		}
+
+	case models.EventStatusInProgress:
+		return "skipped", "Order is already " + order.EventStatus + " and cannot be reopened", nil
+	}
+
+	if err := oc.DB.Model(order).Update("event_status", eventStatus).Error; err != nil {
+		return "", "", err
+	}
+	return "updated", "", nil
+}
+
+// BulkSyncOrderStatus mirrors marketplace order statuses from a Ginee status export
+// @Summary Bulk Sync Order Status
+// @Description Sync order event statuses from a Ginee status export. Accepts JSON rows, a CSV file upload (file) or a raw text/csv body with orderGineeId and externalStatus columns, and returns a per-row result summary
+// @Tags Orders
+// @Accept json
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param rows body BulkSyncStatusRequest false "Rows of order ginee id and external status"
+// @Param file formData file false "CSV file with orderGineeId and externalStatus columns"
+// @Success 200 {object} utils.SuccessResponse{data=BulkSyncStatusResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/bulk-sync-status [post]
+func (oc *OrderController) BulkSyncOrderStatus(c fiber.Ctx) error {
+	log.Println("BulkSyncOrderStatus called")
+	// Parse rows from CSV upload, raw CSV body or JSON body
+	var rows []BulkSyncStatusRow
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEMultipartForm):
+		file, err := c.FormFile("file")
+		if err != nil {
+			log.Println("BulkSyncOrderStatus - CSV file required:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "CSV file is required",
+			})
+		}
+		src, err := file.Open()
+		if err != nil {
+			log.Println("BulkSyncOrderStatus - Failed to open CSV file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to open CSV file",
+			})
+		}
+		defer src.Close()
+
+		if rows, err = parseBulkSyncStatusCSV(src); err != nil {
+			log.Println("BulkSyncOrderStatus - Invalid CSV file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid CSV file: " + err.Error(),
+			})
+		}
+
+	case strings.HasPrefix(contentType, "text/csv"):
+		var err error
+		if rows, err = parseBulkSyncStatusCSV(strings.NewReader(string(c.Body()))); err != nil {
+			log.Println("BulkSyncOrderStatus - Invalid CSV body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid CSV body: " + err.Error(),
+			})
+		}
+
+	default:
+		var req BulkSyncStatusRequest
+		if err := c.Bind().JSON(&req); err != nil {
+			log.Println("BulkSyncOrderStatus - Invalid request body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid request body",
+			})
+		}
+		rows = req.Rows
+	}
+
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No rows to sync",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	response := oc.runBulkSyncOrderStatus(rows, nil, uint(userID))
+	response.BulkOperationID = oc.saveBulkSyncStatusOperation(rows, nil, response, nil, uint(userID))
+
+	log.Printf("BulkSyncOrderStatus completed (updated=%d, unchanged=%d, skipped=%d, failed=%d)\n", response.Summary.Updated, response.Summary.Unchanged, response.Summary.Skipped, response.Summary.Failed)
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Bulk order status sync completed",
+		Data:    response,
+	})
+}
+
+// ReceiveGineeStatusWebhook mirrors order status changes pushed by Ginee
+// @Summary Receive Ginee Status Webhook
+// @Description Sync order event statuses pushed by Ginee, one order or a batch of rows. Sent with an integration API key scoped to orders:write and signed with X-Webhook-Signature when a Ginee webhook secret is configured. Answers 422 when any row failed, so the webhook log shows it for reprocessing.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param X-API-Key header string true "Integration API key"
+// @Param X-Webhook-Signature header string false "Hex HMAC-SHA256 of timestamp.body"
+// @Param X-Webhook-Timestamp header string false "Unix seconds the webhook was sent at"
+// @Param X-Webhook-Nonce header string false "Unique webhook ID"
+// @Param request body GineeStatusWebhookRequest true "Order status change"
+// @Success 200 {object} utils.SuccessResponse{data=BulkSyncStatusResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 422 {object} utils.SuccessResponse{data=BulkSyncStatusResponse}
+// @Router /api/orders/webhooks/ginee [post]
+func (oc *OrderController) ReceiveGineeStatusWebhook(c fiber.Ctx) error {
+	log.Println("ReceiveGineeStatusWebhook called")
+	// Status changes come from the Ginee integration, not from logged in users
+	if _, ok := c.Locals("apiKeyId").(uint); !ok {
+		log.Println("ReceiveGineeStatusWebhook - Request not made with an API key")
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Ginee status webhooks must be sent with an integration API key",
+		})
+	}
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("ReceiveGineeStatusWebhook - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req GineeStatusWebhookRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ReceiveGineeStatusWebhook - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	rows := req.Rows
+	if req.OrderGineeID != "" || req.ExternalStatus != "" {
+		rows = append(rows, BulkSyncStatusRow{OrderGineeID: req.OrderGineeID, ExternalStatus: req.ExternalStatus})
+	}
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order status to sync",
+		})
+	}
+
+	response := oc.runBulkSyncOrderStatus(rows, nil, uint(userID))
+
+	if response.Summary.Failed > 0 {
+		log.Printf("ReceiveGineeStatusWebhook - %d of %d rows failed\n", response.Summary.Failed, response.Summary.Total)
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(utils.SuccessResponse{
+			Success: false,
+			Message: fmt.Sprintf("%d of %d order statuses failed to sync", response.Summary.Failed, response.Summary.Total),
+			Data:    response,
+		})
+	}
+
+	log.Printf("ReceiveGineeStatusWebhook completed (updated=%d, unchanged=%d, skipped=%d)\n", response.Summary.Updated, response.Summary.Unchanged, response.Summary.Skipped)
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Ginee order status synced",
+		Data:    response,
+	})
+}
+
+// runBulkSyncOrderStatus syncs the external statuses of a bulk request, indices are the indices of the rows in the
+// original request when failed rows are re-queued, nil uses their position
+func (oc *OrderController) runBulkSyncOrderStatus(rows []BulkSyncStatusRow, indices []int, userID uint) BulkSyncStatusResponse {
+	response := BulkSyncStatusResponse{
+		Summary: BulkSyncStatusSummary{Total: uint(len(rows))},
+		Results: make([]BulkSyncStatusResult, 0, len(rows)),
+	}
+
+	for position, row := range rows {
+		// Index of the row in the original request, kept when failed rows are re-queued
+		i := bulkRowIndex(indices, position)
+
+		// Convert Order Ginee ID to uppercase and trim spaces
+		result := BulkSyncStatusResult{
+			Index:          i,
+			OrderGineeID:   models.NormalizeIdentifier(row.OrderGineeID),
+			ExternalStatus: strings.TrimSpace(row.ExternalStatus),
+		}
+
+		eventStatus, ok := utils.MapGineeStatus(result.ExternalStatus)
+		var order models.Order
+		switch {
+		case result.OrderGineeID == "":
+			result.Result = "failed"
+			result.Reason = "Order ginee id is required"
+		case !ok:
+			result.Result = "failed"
+			result.Reason = "Unknown external status " + result.ExternalStatus
+		case oc.DB.Where("order_ginee_id = ?", result.OrderGineeID).First(&order).Error != nil:
+			result.Result = "failed"
+			result.Reason = "Order not found"
+		default:
+			result.PreviousStatus = order.EventStatus
+			result.EventStatus = eventStatus
+			outcome, reason, err := oc.syncOrderEventStatus(&order, eventStatus, userID)
+			if err != nil {
+				log.Println("runBulkSyncOrderStatus - Failed to update order:", result.OrderGineeID, err)
+				result.Result = "failed"
+				result.Reason = "Failed to update order status"
+			} else {
+				result.Result = outcome
+				result.Reason = reason
+			}
+		}
+
+		switch result.Result {
+		case "updated":
+			response.Summary.Updated++
+		case "unchanged":
+			response.Summary.Unchanged++
+		case "skipped":
+			response.Summary.Skipped++
+		default:
+			response.Summary.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response
+}
+
+// bulkFailedRow pairs a failed row with its request row, keyed by the index in the original request
+func bulkFailedRow(index int, reason string, row interface{}) models.BulkFailedRow {
+	encoded, err := json.Marshal(row)
+	if err != nil {
+		encoded = []byte("null")
+	}
+	return models.BulkFailedRow{Index: index, Error: reason, Row: encoded}
+}
+
+// bulkRowIndex returns the index in the original request of the row at position
+func bulkRowIndex(indices []int, position int) int {
+	if indices != nil {
+		return indices[position]
+	}
+	return position
+}
+
+// saveBulkOperation stores the result of a bulk request with its failed rows and returns its ID.
+// The rows are already processed, so a failure is only logged and leaves the ID at 0.
+func (oc *OrderController) saveBulkOperation(operation *models.BulkOperation, failedRows []models.BulkFailedRow) uint {
+	encoded, err := json.Marshal(failedRows)
+	if err != nil {
+		log.Println("saveBulkOperation - Failed to encode failed rows:", err)
+		return 0
+	}
+	operation.Failed = len(failedRows)
+	operation.FailedRows = string(encoded)
+	if err := oc.DB.Create(operation).Error; err != nil {
+		log.Println("saveBulkOperation - Failed to store bulk operation:", err)
+		return 0
+	}
+	return operation.ID
+}
+
+// saveBulkCreateOperation stores the result of a bulk order creation
+func (oc *OrderController) saveBulkCreateOperation(orders []CreateOrderRequest, indices []int, response BulkCreateOrdersReponse, sourceID *uint, userID uint) uint {
+	rows := make(map[int]CreateOrderRequest, len(orders))
+	for position, order := range orders {
+		rows[bulkRowIndex(indices, position)] = order
+	}
+	failedRows := make([]models.BulkFailedRow, 0, len(response.FailedOrders))
+	for _, failed := range response.FailedOrders {
+		failedRows = append(failedRows, bulkFailedRow(failed.Index, failed.Error, rows[failed.Index]))
+	}
+
+	return oc.saveBulkOperation(&models.BulkOperation{
+		Operation:   models.BulkOperationOrderCreate,
+		SourceID:    sourceID,
+		Total:       int(response.Summary.Total),
+		Succeeded:   int(response.Summary.Created),
+		Skipped:     int(response.Summary.Skipped),
+		RequestedBy: userID,
+	}, failedRows)
+}
+
+// saveBulkSyncStatusOperation stores the result of a bulk order status sync
+func (oc *OrderController) saveBulkSyncStatusOperation(rows []BulkSyncStatusRow, indices []int, response BulkSyncStatusResponse, sourceID *uint, userID uint) uint {
+	byIndex := make(map[int]BulkSyncStatusRow, len(rows))
+	for position, row := range rows {
+		byIndex[bulkRowIndex(indices, position)] = row
+	}
+	failedRows := make([]models.BulkFailedRow, 0, response.Summary.Failed)
+	for _, result := range response.Results {
+		if result.Result == "failed" {
+			failedRows = append(failedRows, bulkFailedRow(result.Index, result.Reason, byIndex[result.Index]))
+		}
+	}
+
+	return oc.saveBulkOperation(&models.BulkOperation{
+		Operation:   models.BulkOperationOrderSyncStatus,
+		SourceID:    sourceID,
+		Total:       int(response.Summary.Total),
+		Succeeded:   int(response.Summary.Updated + response.Summary.Unchanged),
+		Skipped:     int(response.Summary.Skipped),
+		RequestedBy: userID,
+	}, failedRows)
+}
+
+// findBulkOperation loads a bulk operation of the current user, developers and superadmins can access every bulk operation
+func (oc *OrderController) findBulkOperation(c fiber.Ctx, id string, userID uint) (*models.BulkOperation, error) {
+	query := oc.DB.WithContext(c.Context()).Preload("RequestUser").Where("id = ?", id)
+	if !utils.HasPermission(c, []string{"developer", "superadmin"}) {
+		query = query.Where("requested_by = ?", userID)
+	}
+
+	var operation models.BulkOperation
+	if err := query.First(&operation).Error; err != nil {
+		return nil, err
+	}
+	return &operation, nil
+}
+
+// GetBulkOperation retrieves the stored result of a bulk request
+// @Summary Get Bulk Operation
+// @Description Retrieve the stored result of a bulk order creation or bulk status sync with its failed rows as submitted, indexed by their position in the original request. Users see their own bulk operations, developers and superadmins see every bulk operation
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bulk operation ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.BulkOperationResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/orders/bulk-operations/{id} [get]
+func (oc *OrderController) GetBulkOperation(c fiber.Ctx) error {
+	log.Println("GetBulkOperation called")
+	id := c.Params("id")
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	operation, err := oc.findBulkOperation(c, id, uint(userID))
+	if err != nil {
+		log.Println("GetBulkOperation - Bulk operation not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Bulk operation with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetBulkOperation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Bulk operation retrieved successfully",
+		Data:    operation.ToResponse(),
+	})
+}
+
+// RequeueBulkOperation re-submits the failed rows of a bulk request
+// @Summary Requeue Failed Bulk Rows
+// @Description Re-submit only the failed rows of a previous bulk order creation or bulk status sync, after the data they failed on was fixed. Rows can be corrected in the body by their original index, the other failed rows are re-submitted as stored. The result keeps the original row indices and is stored as a new bulk operation referencing the previous one, so its failed rows can be re-queued again
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Bulk operation ID"
+// @Param request body RequeueBulkOperationRequest false "Corrected rows"
+// @Success 200 {object} utils.SuccessResponse{data=BulkCreateOrdersReponse} "Bulk order creation, the status sync returns BulkSyncStatusResponse"
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Router /api/orders/bulk-operations/{id}/requeue [post]
+func (oc *OrderController) RequeueBulkOperation(c fiber.Ctx) error {
+	log.Println("RequeueBulkOperation called")
+	id := c.Params("id")
+
+	// Binding request body, the corrections are optional
+	var req RequeueBulkOperationRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			log.Println("RequeueBulkOperation - Invalid request body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid request body",
+			})
+		}
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	operation, err := oc.findBulkOperation(c, id, uint(userID))
+	if err != nil {
+		log.Println("RequeueBulkOperation - Bulk operation not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Bulk operation with id " + id + " not found.",
+		})
+	}
+
+	failedRows := operation.FailedRowList()
+	if len(failedRows) == 0 {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Bulk operation has no failed rows to re-queue",
+		})
+	}
+
+	// Apply the corrections, only failed rows can be corrected
+	positions := make(map[int]int, len(failedRows))
+	for position, row := range failedRows {
+		positions[row.Index] = position
+	}
+	for _, correction := range req.Rows {
+		position, ok := positions[correction.Index]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Row %d did not fail in bulk operation %d", correction.Index, operation.ID),
+			})
+		}
+		failedRows[position].Row = correction.Row
+	}
+
+	indices := make([]int, len(failedRows))
+	for position, row := range failedRows {
+		indices[position] = row.Index
+	}
+
+	switch operation.Operation {
+	case models.BulkOperationOrderCreate:
+		orders := make([]CreateOrderRequest, len(failedRows))
+		for position, row := range failedRows {
+			if err := json.Unmarshal(row.Row, &orders[position]); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   fmt.Sprintf("Invalid order in row %d: %s", row.Index, err.Error()),
+				})
+			}
+		}
+
+		response := oc.runBulkCreateOrders(c, orders, indices)
+		response.BulkOperationID = oc.saveBulkCreateOperation(orders, indices, response, &operation.ID, uint(userID))
+		statusCode, message := bulkCreateOrdersOutcome(response)
+
+		log.Printf("RequeueBulkOperation completed (created=%d, skipped=%d, failed=%d)\n", response.Summary.Created, response.Summary.Skipped, response.Summary.Failed)
+		return c.Status(statusCode).JSON(utils.SuccessResponse{
+			Success: true,
+			Message: message + fmt.Sprintf(" (re-queued from bulk operation %d)", operation.ID),
+			Data:    response,
+		})
+
+	case models.BulkOperationOrderSyncStatus:
+		rows := make([]BulkSyncStatusRow, len(failedRows))
+		for position, row := range failedRows {
+			if err := json.Unmarshal(row.Row, &rows[position]); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   fmt.Sprintf("Invalid status row %d: %s", row.Index, err.Error()),
+				})
+			}
+		}
+
+		response := oc.runBulkSyncOrderStatus(rows, indices, uint(userID))
+		response.BulkOperationID = oc.saveBulkSyncStatusOperation(rows, indices, response, &operation.ID, uint(userID))
+
+		log.Printf("RequeueBulkOperation completed (updated=%d, unchanged=%d, skipped=%d, failed=%d)\n", response.Summary.Updated, response.Summary.Unchanged, response.Summary.Skipped, response.Summary.Failed)
+		return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+			Success: true,
+			Message: fmt.Sprintf("Bulk order status sync completed (re-queued from bulk operation %d)", operation.ID),
+			Data:    response,
+		})
+
+	default:
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Bulk operation " + operation.Operation + " cannot be re-queued",
+		})
+	}
+}
+
+// orderImportColumns are the columns of the order import template, one row per order detail.
+// Order columns are repeated on every detail row of the same order.
+var orderImportColumns = []string{"orderGineeId", "channel", "store", "buyer", "address", "courier", "trackingNumber", "sentBefore", "currency", "giftMessage", "handlingNote", "sku", "productName", "variant", "quantity", "price", "note"}
+
+// orderImportOrderColumns must be equal on all rows of the same order
+var orderImportOrderColumns = []string{"channel", "store", "buyer", "address", "courier", "trackingNumber", "sentBefore", "currency", "giftMessage", "handlingNote"}
+
+var orderImportRequiredColumns = []string{"orderGineeId", "channel", "store", "buyer", "address", "sku", "productName", "quantity", "price"}
+
+// orderImportRow is a data row of an order import file keyed by template column
+type orderImportRow struct {
+	Row    int
+	Values map[string]string
+}
+
+// parseOrderImportRows maps spreadsheet rows to template columns using the header row
+func parseOrderImportRows(records [][]string) ([]orderImportRow, error) {
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	normalize := func(value string) string {
+		return strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(value)))
+	}
+
+	columnIndex := make(map[string]int)
+	for i, header := range records[0] {
+		for _, column := range orderImportColumns {
+			if normalize(header) == strings.ToLower(column) {
+				columnIndex[column] = i
+			}
+		}
+	}
+	var missing []string
+	for _, column := range orderImportRequiredColumns {
+		if _, ok := columnIndex[column]; !ok {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	rows := make([]orderImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		row := orderImportRow{Row: i + 2, Values: make(map[string]string, len(columnIndex))}
+		empty := true
+		for column, index := range columnIndex {
+			if index < len(record) {
+				row.Values[column] = strings.TrimSpace(record[index])
+				if row.Values[column] != "" {
+					empty = false
+				}
+			}
+		}
+		// Skip blank lines left at the end of spreadsheets
+		if empty {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ValidateOrderImport validates an order import file without creating any order
+// @Summary Validate Order Import
+// @Description Validate an order import file (CSV or XLSX, one row per order detail) before importing it. Checks required columns, date and number formats, currencies, unknown channels, stores and SKUs, orders already in the system, duplicates within the file and order columns that differ between rows of the same order. Nothing is persisted.
+// @Tags Orders
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "CSV or XLSX file with orderGineeId, channel, store, buyer, address, courier, trackingNumber, sentBefore (YYYY-MM-DD HH:MM:00), currency, sku, productName, variant, quantity and price columns"
+// @Success 200 {object} utils.SuccessResponse{data=OrderImportValidationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/import/validate [post]
+func (oc *OrderController) ValidateOrderImport(c fiber.Ctx) error {
+	log.Println("ValidateOrderImport called")
+	file, err := c.FormFile("file")
+	if err != nil {
+		log.Println("ValidateOrderImport - Import file required:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Import file is required",
+		})
+	}
+	src, err := file.Open()
+	if err != nil {
+		log.Println("ValidateOrderImport - Failed to open import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to open import file",
+		})
+	}
+	defer src.Close()
+
+	records, err := utils.ReadSpreadsheetRows(file.Filename, src)
+	if err != nil {
+		log.Println("ValidateOrderImport - Invalid import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid import file: " + err.Error(),
+		})
+	}
+	rows, err := parseOrderImportRows(records)
+	if err != nil {
+		log.Println("ValidateOrderImport - Invalid import file:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid import file: " + err.Error(),
+		})
+	}
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No rows to validate",
+		})
+	}
+
+	// Normalize identifiers the same way order creation does and collect them for lookups
+	orderIDSet, trackingSet, skuSet := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, row := range rows {
+		row.Values["orderGineeId"] = models.NormalizeIdentifier(row.Values["orderGineeId"])
+		row.Values["trackingNumber"] = models.NormalizeIdentifier(row.Values["trackingNumber"])
+		if row.Values["orderGineeId"] != "" {
+			orderIDSet[row.Values["orderGineeId"]] = true
+		}
+		if row.Values["trackingNumber"] != "" {
+			trackingSet[row.Values["trackingNumber"]] = true
+		}
+		if row.Values["sku"] != "" {
+			skuSet[row.Values["sku"]] = true
+		}
+	}
+	setKeys := func(set map[string]bool) []string {
+		keys := make([]string, 0, len(set))
+		for key := range set {
+			keys = append(keys, key)
+		}
+		return keys
+	}
+
+	// Look up existing orders and products in chunks to stay below the query parameter limit
+	const lookupChunk = 1000
+	existingOrderIDs, existingTracking, knownSKUs := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	lookups := []struct {
+		values []string
+		query  func(chunk []string) error
+	}{
+		{setKeys(orderIDSet), func(chunk []string) error {
+			var ids []string
+			if err := oc.DB.Model(&models.Order{}).Where("order_ginee_id IN ?", chunk).Pluck("order_ginee_id", &ids).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				existingOrderIDs[id] = true
+			}
+			return nil
+		}},
+		{setKeys(trackingSet), func(chunk []string) error {
+			var numbers []string
+			if err := oc.DB.Model(&models.Order{}).Where("tracking_number IN ?", chunk).Pluck("tracking_number", &numbers).Error; err != nil {
+				return err
+			}
+			for _, number := range numbers {
+				existingTracking[number] = true
+			}
+			return nil
+		}},
+		{setKeys(skuSet), func(chunk []string) error {
+			var skus []string
+			if err := oc.DB.Model(&models.Product{}).Where("sku IN ?", chunk).Pluck("sku", &skus).Error; err != nil {
+				return err
+			}
+			for _, sku := range skus {
+				knownSKUs[sku] = true
+			}
+			return nil
+		}},
+	}
+	for _, lookup := range lookups {
+		for start := 0; start < len(lookup.values); start += lookupChunk {
+			end := start + lookupChunk
+			if end > len(lookup.values) {
+				end = len(lookup.values)
+			}
+			if err := lookup.query(lookup.values[start:end]); err != nil {
+				log.Println("ValidateOrderImport - Failed to look up import references:", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Failed to validate import file",
+				})
+			}
+		}
+	}
+
+	// Channels and stores are matched by code or name
+	var channels []models.Channel
+	var stores []models.Store
+	if err := oc.DB.Find(&channels).Error; err != nil {
+		log.Println("ValidateOrderImport - Failed to retrieve channels:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to validate import file",
+		})
+	}
+	if err := oc.DB.Find(&stores).Error; err != nil {
+		log.Println("ValidateOrderImport - Failed to retrieve stores:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to validate import file",
+		})
+	}
+	knownChannels, knownStores := map[string]bool{}, map[string]bool{}
+	for _, channel := range channels {
+		knownChannels[strings.ToLower(channel.ChannelCode)] = true
+		knownChannels[strings.ToLower(channel.ChannelName)] = true
+	}
+	for _, store := range stores {
+		knownStores[strings.ToLower(store.StoreCode)] = true
+		knownStores[strings.ToLower(store.StoreName)] = true
+	}
+
+	trackingFormats, err := utils.LoadTrackingFormats(oc.DB)
+	if err != nil {
+		log.Println("ValidateOrderImport - Failed to load tracking formats:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to validate import file",
+		})
+	}
+
+	response := OrderImportValidationResponse{
+		Summary: OrderImportValidationSummary{TotalRows: uint(len(rows)), Orders: uint(len(orderIDSet))},
+		Errors:  []OrderImportRowError{},
+	}
+
+	firstOrderRow := make(map[string]orderImportRow) // order ginee id -> first row of the order
+	trackingOrder := make(map[string]string)         // tracking number -> order ginee id using it in the file
+	orderSKURow := make(map[string]int)              // order ginee id and sku -> row
+	for _, row := range rows {
+		var rowErrors []OrderImportRowError
+		addError := func(column, message string) {
+			rowErrors = append(rowErrors, OrderImportRowError{Row: row.Row, Column: column, Value: row.Values[column], Error: message})
+		}
+
+		for _, column := range orderImportRequiredColumns {
+			if row.Values[column] == "" {
+				addError(column, column+" is required")
+			}
+		}
+
+		orderID := row.Values["orderGineeId"]
+		if orderID != "" {
+			if first, ok := firstOrderRow[orderID]; ok {
+				// Later rows of an order only add details
+				for _, column := range orderImportOrderColumns {
+					if row.Values[column] != "" && !strings.EqualFold(row.Values[column], first.Values[column]) {
+						addError(column, fmt.Sprintf("Differs from row %d of the same order", first.Row))
+					}
+				}
+			} else {
+				firstOrderRow[orderID] = row
+				if existingOrderIDs[orderID] {
+					addError("orderGineeId", "Order already exists")
+				}
+			}
+		}
+
+		if tracking := row.Values["trackingNumber"]; tracking != "" {
+			if err := trackingFormats.Validate(tracking); err != nil {
+				addError("trackingNumber", err.Error())
+			}
+			if other, ok := trackingOrder[tracking]; ok && other != orderID {
+				addError("trackingNumber", "Tracking number is used by order "+other+" in this file")
+			} else if !ok {
+				trackingOrder[tracking] = orderID
+				if existingTracking[tracking] {
+					addError("trackingNumber", "Order with this tracking number already exists")
+				}
+			}
+		}
+
+		if channel := row.Values["channel"]; channel != "" && !knownChannels[strings.ToLower(channel)] {
+			addError("channel", "Unknown channel")
+		}
+		if store := row.Values["store"]; store != "" && !knownStores[strings.ToLower(store)] {
+			addError("store", "Unknown store")
+		}
+
+		if sentBefore := row.Values["sentBefore"]; sentBefore != "" {
+			if _, err := time.Parse("2006-01-02 15:04:00", sentBefore); err != nil {
+				addError("sentBefore", "Invalid date, use YYYY-MM-DD HH:MM:00")
+			}
+		}
+		if _, ok := oc.resolveOrderCurrency(row.Values["currency"]); !ok {
+			addError("currency", "Invalid currency")
+		}
+
+		if sku := row.Values["sku"]; sku != "" {
+			if !knownSKUs[sku] {
+				addError("sku", "Unknown SKU")
+			}
+			key := orderID + "\x00" + sku
+			if previous, ok := orderSKURow[key]; ok && orderID != "" {
+				addError("sku", fmt.Sprintf("SKU already listed for this order on row %d", previous))
+			} else {
+				orderSKURow[key] = row.Row
+			}
+		}
+
+		for _, column := range []string{"quantity", "price"} {
+			if value := row.Values[column]; value != "" {
+				if number, err := strconv.Atoi(value); err != nil || number <= 0 {
+					addError(column, column+" must be a whole number greater than zero")
+				}
+			}
+		}
+
+		for _, column := range []string{"giftMessage", "handlingNote", "note"} {
+			if len([]rune(row.Values[column])) > 500 {
+				addError(column, column+" must be at most 500 characters")
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			response.Summary.InvalidRows++
+			response.Errors = append(response.Errors, rowErrors...)
+		} else {
+			response.Summary.ValidRows++
+		}
+	}
+	response.Valid = response.Summary.InvalidRows == 0
+
+	message := "Import file is valid"
+	if !response.Valid {
+		message = fmt.Sprintf("Import file has errors on %d of %d rows", response.Summary.InvalidRows, response.Summary.TotalRows)
+	}
+
+	log.Printf("ValidateOrderImport completed (rows=%d, invalid=%d)\n", response.Summary.TotalRows, response.Summary.InvalidRows)
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    response,
+	})
+}
+
+// courierBookingPendingTimeout is how long a pending booking blocks another booking of the same order, longer than
+// the courier API timeout so a booking still waiting for its answer is never sent twice
+const courierBookingPendingTimeout = 2 * time.Minute
+
+type BookOrderCourierRequest struct {
+	ExpeditionSlug string `json:"expeditionSlug" example:"jne"` // courier to book with, the order's courier when empty
+	WeightGrams    int    `json:"weightGrams" example:"1200"`   // weight to book with, the chargeable weight of the order's products when 0
+}
+
+// errCourierBookingConflict is returned when the order cannot take the tracking number of a booking
+var errCourierBookingConflict = errors.New("courier booking conflict")
+
+// BookOrderCourier books the shipment of an order without a tracking number through the courier API
+// @Summary Book Order Courier
+// @Description Book the shipment of an order created without a tracking number through the API of its courier, with the chargeable weight rolled up from the product weights and dimensions, and write back the official tracking number the courier issued. A weight can be given for orders with products missing measurements.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Param request body BookOrderCourierRequest false "Courier and weight overrides"
+// @Success 201 {object} utils.SuccessResponse{data=models.CourierBookingResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Failure 502 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/courier-booking [post]
+func (oc *OrderController) BookOrderCourier(c fiber.Ctx) error {
+	log.Println("BookOrderCourier called")
+	// Get user ID from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse the optional request body
+	var req BookOrderCourierRequest
+	if len(c.Body()) > 0 {
+		if err := c.Bind().JSON(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid request body",
+			})
+		}
+	}
+	req.ExpeditionSlug = strings.TrimSpace(req.ExpeditionSlug)
+	if req.WeightGrams < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Weight must be greater than zero",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Preload("OrderDetails").Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("BookOrderCourier - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	if order.TrackingNumber != "" {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order already has tracking number " + order.TrackingNumber + ".",
+		})
+	}
+	if order.EventStatus == "canceled" || order.EventStatus == "merged" || order.EventStatus == "duplicated" || order.ProcessingStatus == "outbound_completed" {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order in " + order.EventStatus + " status cannot be booked with a courier.",
+		})
+	}
+
+	// Resolve the courier, the one given or the one the order was placed with
+	var expedition *models.Expedition
+	if req.ExpeditionSlug != "" {
+		var found models.Expedition
+		if err := oc.DB.Where("expedition_slug = ?", req.ExpeditionSlug).First(&found).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Expedition " + req.ExpeditionSlug + " not found.",
+			})
+		}
+		expedition = &found
+	} else {
+		var expeditions []models.Expedition
+		if err := oc.DB.Find(&expeditions).Error; err != nil {
+			log.Println("BookOrderCourier - Failed to load expeditions:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to load expeditions",
+			})
+		}
+		expedition = utils.MatchOrderExpedition(expeditions, &order)
+		if expedition == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Courier " + order.Courier + " of the order is not a known expedition, choose the expedition to book with.",
+			})
+		}
+	}
+	if !utils.IsCourierBookingConfigured(oc.Config, expedition.ExpeditionSlug) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Courier booking is not configured for " + expedition.ExpeditionName + ".",
+		})
+	}
+
+	// Roll up the parcel from the product master data
+	for i := range order.OrderDetails {
+		var product models.Product
+		if err := oc.DB.Where("sku = ?", order.OrderDetails[i].SKU).First(&product).Error; err == nil {
+			order.OrderDetails[i].Product = &product
+		}
+	}
+	parcel := order.ParcelEstimate()
+	weightGrams := req.WeightGrams
+	volumeCm3 := 0.0
+	if parcel != nil {
+		volumeCm3 = parcel.TotalVolumeCm3
+		if weightGrams == 0 && parcel.Complete {
+			weightGrams = parcel.ChargeableWeightGrams
+		}
+	}
+	if weightGrams == 0 {
+		missing := "the order has no products"
+		if parcel != nil && len(parcel.MissingSKUs) > 0 {
+			missing = "SKUs without weight or dimensions: " + strings.Join(parcel.MissingSKUs, ", ")
+		}
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Parcel weight cannot be rolled up (" + missing + "), enter the weight to book with.",
+		})
+	}
+
+	// Claim the booking, so the same order is never booked twice at once
+	booking := models.CourierBooking{
+		OrderID:        order.ID,
+		ExpeditionID:   expedition.ID,
+		ExpeditionSlug: expedition.ExpeditionSlug,
+		Status:         models.CourierBookingStatusPending,
+		WeightGrams:    weightGrams,
+		VolumeCm3:      volumeCm3,
+		BookedBy:       uint(userID),
+	}
+	if err := oc.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", order.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		if locked.TrackingNumber != "" {
+			return errCourierBookingConflict
+		}
+		var pending int64
+		if err := tx.Model(&models.CourierBooking{}).Where("order_id = ? AND status = ? AND created_at > ?", order.ID, models.CourierBookingStatusPending, time.Now().Add(-courierBookingPendingTimeout)).Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return errCourierBookingConflict
+		}
+		return tx.Create(&booking).Error
+	}); err != nil {
+		if errors.Is(err, errCourierBookingConflict) {
+			return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Order is already being booked or has a tracking number.",
+			})
+		}
+		log.Println("BookOrderCourier - Failed to create booking:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create courier booking",
+		})
+	}
+
+	// Book with the courier outside of any transaction, the API may take up to its timeout
+	shipment := utils.NewCourierShipment(&order, utils.FindOrderStore(oc.DB, &order), expedition, utils.CourierParcel{WeightGrams: weightGrams, VolumeCm3: volumeCm3})
+	result, err := utils.BookCourierShipment(c.Context(), oc.Config, expedition.ExpeditionSlug, shipment)
+	if err != nil {
+		log.Println("BookOrderCourier - Courier booking failed:", err)
+		oc.DB.Model(&booking).Updates(map[string]interface{}{"status": models.CourierBookingStatusFailed, "error": err.Error()})
+		return c.Status(fiber.StatusBadGateway).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to book with " + expedition.ExpeditionName + ": " + err.Error(),
+		})
+	}
+
+	// Write back the official tracking number, unless the order got one in the meantime
+	now := time.Now()
+	userIDUint := uint(userID)
+	writeBackErr := oc.DB.Transaction(func(tx *gorm.DB) error {
+		var locked models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", order.ID).First(&locked).Error; err != nil {
+			return err
+		}
+		if locked.TrackingNumber != "" {
+			return fmt.Errorf("%w: order got tracking number %s in the meantime", errCourierBookingConflict, locked.TrackingNumber)
+		}
+		var existing int64
+		if err := tx.Model(&models.Order{}).Where("tracking_number = ?", result.TrackingNumber).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return fmt.Errorf("%w: tracking number %s is already used by another order", errCourierBookingConflict, result.TrackingNumber)
+		}
+
+		updates := map[string]interface{}{
+			"tracking_number": result.TrackingNumber,
+			"changed_by":      userIDUint,
+			"changed_at":      now,
+		}
+		if locked.Courier == "" {
+			updates["courier"] = expedition.ExpeditionName
+		}
+		if err := tx.Model(&locked).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Model(&booking).Updates(map[string]interface{}{
+			"status":            models.CourierBookingStatusBooked,
+			"tracking_number":   result.TrackingNumber,
+			"booking_reference": result.BookingReference,
+		}).Error
+	})
+	if writeBackErr != nil {
+		log.Println("BookOrderCourier - Failed to write back tracking number:", writeBackErr)
+		// The shipment is booked with the courier, keep its tracking number so it can be canceled there
+		oc.DB.Model(&booking).Updates(map[string]interface{}{
+			"status":            models.CourierBookingStatusFailed,
+			"tracking_number":   result.TrackingNumber,
+			"booking_reference": result.BookingReference,
+			"error":             writeBackErr.Error(),
+		})
+		if errors.Is(writeBackErr, errCourierBookingConflict) {
+			return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Booked with " + expedition.ExpeditionName + " as " + result.TrackingNumber + " but it was not written back: " + writeBackErr.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to write back tracking number " + result.TrackingNumber,
+		})
+	}
+
+	oc.DB.Preload("BookUser").First(&booking, booking.ID)
+
+	log.Println("BookOrderCourier completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order booked with " + expedition.ExpeditionName + ", tracking number " + result.TrackingNumber,
+		Data:    booking.ToResponse(),
+	})
+}
+
+// GetOrderCourierBookings retrieves the courier bookings of an order
+// @Summary Get Order Courier Bookings
+// @Description Retrieve every shipment booking made for an order through a courier API, newest first, including failed ones
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.CourierBookingResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/courier-bookings [get]
+func (oc *OrderController) GetOrderCourierBookings(c fiber.Ctx) error {
+	log.Println("GetOrderCourierBookings called")
+	// Parse id parameter
+	id := c.Params("id")
+	var order models.Order
+	if err := oc.DB.Where("id = ?", id).First(&order).Error; err != nil {
+		log.Println("GetOrderCourierBookings - Order not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+
+	var bookings []models.CourierBooking
+	if err := oc.DB.Preload("BookUser").Where("order_id = ?", order.ID).Order("created_at DESC, id DESC").Find(&bookings).Error; err != nil {
+		log.Println("GetOrderCourierBookings - Failed to retrieve bookings:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve courier bookings",
+		})
+	}
+
+	// Format response
+	bookingList := make([]models.CourierBookingResponse, len(bookings))
+	for i := range bookings {
+		bookingList[i] = *bookings[i].ToResponse()
+	}
+
+	log.Println("GetOrderCourierBookings completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order courier bookings retrieved successfully",
+		Data:    bookingList,
+	})
+}
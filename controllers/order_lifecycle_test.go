@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/testutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// testResponse is the body of a handler response, success or error
+type testResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Error   string          `json:"error"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// testRequest is a request to a single handler, made as the user with the roles
+type testRequest struct {
+	Method string
+	Route  string // route of the handler, e.g. /qc-onlines/:id/scan
+	Path   string
+	User   models.User
+	Roles  []string
+	Body   interface{}
+}
+
+// callHandler runs the request against the handler with the locals the auth middleware sets
+func callHandler(t *testing.T, handler fiber.Handler, r testRequest) (*http.Response, testResponse) {
+	t.Helper()
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		c.Locals("userId", strconv.FormatUint(uint64(r.User.ID), 10))
+		c.Locals("userRoles", r.Roles)
+		return c.Next()
+	})
+	app.Add([]string{r.Method}, r.Route, handler)
+
+	body, err := json.Marshal(r.Body)
+	if err != nil {
+		t.Fatalf("failed to encode request body: %v", err)
+	}
+	req := httptest.NewRequest(r.Method, r.Path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", r.Method, r.Path, err)
+	}
+	defer resp.Body.Close()
+
+	var result testResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("%s %s returned an invalid body: %v", r.Method, r.Path, err)
+	}
+	return resp, result
+}
+
+// expectStatus fails the test when the handler did not answer with the status
+func expectStatus(t *testing.T, step string, resp *http.Response, result testResponse, status int) {
+	t.Helper()
+	if resp.StatusCode != status {
+		t.Fatalf("%s: status = %d, want %d (error: %q)", step, resp.StatusCode, status, result.Error)
+	}
+}
+
+// loadOrder reloads the order with its details and checks its processing status
+func loadOrder(t *testing.T, trackingNumber, processingStatus string) models.Order {
+	t.Helper()
+
+	var order models.Order
+	if err := testDB.Preload("OrderDetails").Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		t.Fatalf("order %s not found: %v", trackingNumber, err)
+	}
+	if order.ProcessingStatus != processingStatus {
+		t.Fatalf("order %s processing status = %s, want %s", trackingNumber, order.ProcessingStatus, processingStatus)
+	}
+	return order
+}
+
+// lifecycleConfig is the test configuration without the attendance, quota and shadow QC checks
+// the happy path does not exercise
+func lifecycleConfig() *config.Config {
+	cfg := testutil.Config()
+	cfg.PickerAttendanceRequired = false
+	cfg.PickerMaxOpenOrders = 0
+	cfg.QCShadowPercent = 0
+	return cfg
+}
+
+// lifecycleTrackingNumber returns a unique tracking number with the prefix of a seeded expedition,
+// outbound scans need one to detect the courier
+func lifecycleTrackingNumber() string {
+	return fmt.Sprintf("TG%d", time.Now().UnixNano())
+}
+
+// Creating, picking, checking and handing over an order drives it through every processing status.
+// The handlers open transactions with DB.Begin, so the test runs against the database itself.
+func TestOrderLifecycle(t *testing.T) {
+	testutil.RequireDatabase(t, testDB)
+
+	cfg := lifecycleConfig()
+	f := testutil.NewFactory(t, testDB)
+	admin := f.User("superadmin")
+	picker := f.User("picker")
+	qcUser := f.User("qc-online")
+	outboundUser := f.User("outbound")
+	box := firstBox(t)
+
+	trackingNumber := lifecycleTrackingNumber()
+	sku := "LC-" + trackingNumber
+
+	orderController := NewOrderController(cfg, testDB)
+	resp, result := callHandler(t, orderController.CreateOrder, testRequest{
+		Method: fiber.MethodPost, Route: "/api/orders", Path: "/api/orders",
+		User: admin, Roles: []string{"superadmin"},
+		Body: map[string]interface{}{
+			"channel":        "Shopee",
+			"store":          "Axon",
+			"buyer":          "Lifecycle Buyer",
+			"address":        "Jl. Soekarno Hatta No. 12, Lowokwaru, Kota Malang, Jawa Timur 65141",
+			"courier":        "JNE",
+			"trackingNumber": trackingNumber,
+			"sentBefore":     time.Now().Add(24 * time.Hour).Format("2006-01-02 15:04:00"),
+			"details": []map[string]interface{}{
+				{"sku": sku, "productName": "Lifecycle Product", "quantity": 2, "price": 50000},
+			},
+		},
+	})
+	expectStatus(t, "create order", resp, result, fiber.StatusCreated)
+	order := loadOrder(t, trackingNumber, models.ProcessingStatusReadyToPick)
+
+	resp, result = callHandler(t, orderController.AssignPicker, testRequest{
+		Method: fiber.MethodPost, Route: "/api/orders/assign-picker", Path: "/api/orders/assign-picker",
+		User: admin, Roles: []string{"superadmin"},
+		Body: AssignPickerRequest{PickerID: picker.ID, TrackingNumber: trackingNumber},
+	})
+	expectStatus(t, "assign picker", resp, result, fiber.StatusOK)
+	order = loadOrder(t, trackingNumber, models.ProcessingStatusPickingProgress)
+	if order.PickedBy == nil || *order.PickedBy != picker.ID {
+		t.Fatalf("order picked by %v, want picker %d", order.PickedBy, picker.ID)
+	}
+
+	mobileOrderController := NewMobileOrderController(cfg, testDB)
+	orderPath := "/api/mobile-orders/my-picking-order/" + strconv.FormatUint(uint64(order.ID), 10)
+	resp, result = callHandler(t, mobileOrderController.PickOrderItem, testRequest{
+		Method: fiber.MethodPut, Route: "/api/mobile-orders/my-picking-order/:id/items/pick", Path: orderPath + "/items/pick",
+		User: picker, Roles: []string{"picker"},
+		Body: PickOrderItemRequest{SKU: sku, Quantity: 2},
+	})
+	expectStatus(t, "pick item", resp, result, fiber.StatusOK)
+
+	resp, result = callHandler(t, mobileOrderController.CompletePickingOrder, testRequest{
+		Method: fiber.MethodPut, Route: "/api/mobile-orders/my-picking-order/:id/complete", Path: orderPath + "/complete",
+		User: picker, Roles: []string{"picker"},
+	})
+	expectStatus(t, "complete picking", resp, result, fiber.StatusOK)
+	loadOrder(t, trackingNumber, models.ProcessingStatusPickingCompleted)
+	var pickedOrders int64
+	testDB.Model(&models.PickedOrder{}).Where("order_id = ?", order.ID).Count(&pickedOrders)
+	if pickedOrders != 1 {
+		t.Fatalf("picked orders = %d, want 1", pickedOrders)
+	}
+
+	qcController := NewQCController(cfg, testDB)
+	resp, result = callHandler(t, qcController.QCStart, testRequest{
+		Method: fiber.MethodPost, Route: "/api/qc/start", Path: "/api/qc/start",
+		User: qcUser, Roles: []string{"qc-online"},
+		Body: QCStartRequest{TrackingNumber: trackingNumber},
+	})
+	expectStatus(t, "start QC", resp, result, fiber.StatusOK)
+	if lane := resp.Header.Get("X-QC-Lane"); lane != "online" {
+		t.Fatalf("QC lane = %q, want online", lane)
+	}
+	loadOrder(t, trackingNumber, models.ProcessingStatusQCProgress)
+	var qcOnline models.QCOnline
+	if err := testDB.Where("tracking_number = ?", trackingNumber).First(&qcOnline).Error; err != nil {
+		t.Fatalf("QC online not created: %v", err)
+	}
+
+	qcOnlineController := NewQCOnlineController(testDB)
+	qcPath := "/api/onlines/qc-onlines/" + strconv.FormatUint(uint64(qcOnline.ID), 10)
+	scan := testRequest{
+		Method: fiber.MethodPut, Route: "/api/onlines/qc-onlines/:id/scan", Path: qcPath + "/scan",
+		User: qcUser, Roles: []string{"qc-online"},
+		Body: QCScanRequest{SKU: sku},
+	}
+	for i := 1; i <= 2; i++ {
+		resp, result = callHandler(t, qcOnlineController.ScanQCOnlineProduct, scan)
+		expectStatus(t, fmt.Sprintf("scan %d", i), resp, result, fiber.StatusOK)
+	}
+	if resp, _ = callHandler(t, qcOnlineController.ScanQCOnlineProduct, scan); resp.StatusCode == fiber.StatusOK {
+		t.Fatal("scan past the ordered quantity was accepted")
+	}
+	order = loadOrder(t, trackingNumber, models.ProcessingStatusQCProgress)
+	if detail := order.OrderDetails[0]; !detail.IsValid || detail.ScannedQuantity != 2 {
+		t.Fatalf("detail scanned %d valid %v, want 2 valid", detail.ScannedQuantity, detail.IsValid)
+	}
+
+	resp, result = callHandler(t, qcOnlineController.CompleteQcOnline, testRequest{
+		Method: fiber.MethodPut, Route: "/api/onlines/qc-onlines/:id/complete", Path: qcPath + "/complete",
+		User: qcUser, Roles: []string{"qc-online"},
+		Body: CreateQCOnlineDetailRequest{Details: []CreateQCRibbonDetail{{BoxID: box.ID, Quantity: 1}}},
+	})
+	expectStatus(t, "complete QC", resp, result, fiber.StatusOK)
+	loadOrder(t, trackingNumber, models.ProcessingStatusQCCompleted)
+
+	outboundController := NewOutboundController(testDB)
+	resp, result = callHandler(t, outboundController.CreateOutbound, testRequest{
+		Method: fiber.MethodPost, Route: "/api/outbounds", Path: "/api/outbounds",
+		User: outboundUser, Roles: []string{"outbound"},
+		Body: CreateOutboundRequest{TrackingNumber: trackingNumber},
+	})
+	expectStatus(t, "create outbound", resp, result, fiber.StatusCreated)
+	order = loadOrder(t, trackingNumber, models.ProcessingStatusOutboundCompleted)
+	if order.EventStatus != models.EventStatusCompleted {
+		t.Fatalf("event status = %s, want %s", order.EventStatus, models.EventStatusCompleted)
+	}
+}
+
+// Every step of the lifecycle accepts an order in the stage before it and refuses orders in other stages
+func TestOrderLifecycleStages(t *testing.T) {
+	testutil.RequireDatabase(t, testDB)
+
+	cfg := lifecycleConfig()
+	f := testutil.NewFactory(t, testDB)
+	admin := f.User("superadmin")
+	picker := f.User("picker")
+	qcUser := f.User("qc-online")
+	outboundUser := f.User("outbound")
+
+	orderController := NewOrderController(cfg, testDB)
+	qcController := NewQCController(cfg, testDB)
+	outboundController := NewOutboundController(testDB)
+
+	assignPicker := func(t *testing.T, order models.Order) (*http.Response, testResponse) {
+		return callHandler(t, orderController.AssignPicker, testRequest{
+			Method: fiber.MethodPost, Route: "/api/orders/assign-picker", Path: "/api/orders/assign-picker",
+			User: admin, Roles: []string{"superadmin"},
+			Body: AssignPickerRequest{PickerID: picker.ID, TrackingNumber: order.TrackingNumber},
+		})
+	}
+	startQC := func(t *testing.T, order models.Order) (*http.Response, testResponse) {
+		return callHandler(t, qcController.QCStart, testRequest{
+			Method: fiber.MethodPost, Route: "/api/qc/start", Path: "/api/qc/start",
+			User: qcUser, Roles: []string{"qc-online"},
+			Body: QCStartRequest{TrackingNumber: order.TrackingNumber},
+		})
+	}
+	createOutbound := func(t *testing.T, order models.Order) (*http.Response, testResponse) {
+		return callHandler(t, outboundController.CreateOutbound, testRequest{
+			Method: fiber.MethodPost, Route: "/api/outbounds", Path: "/api/outbounds",
+			User: outboundUser, Roles: []string{"outbound"},
+			Body: CreateOutboundRequest{TrackingNumber: order.TrackingNumber},
+		})
+	}
+
+	tests := []struct {
+		name           string
+		stage          string
+		call           func(*testing.T, models.Order) (*http.Response, testResponse)
+		wantStatus     int
+		wantProcessing string // processing status of the order after the call
+	}{
+		{"assign picker to ready to pick order", models.ProcessingStatusReadyToPick, assignPicker, fiber.StatusOK, models.ProcessingStatusPickingProgress},
+		{"assign picker refuses order in picking", models.ProcessingStatusPickingProgress, assignPicker, fiber.StatusBadRequest, models.ProcessingStatusPickingProgress},
+		{"start QC of picked order", models.ProcessingStatusPickingCompleted, startQC, fiber.StatusOK, models.ProcessingStatusQCProgress},
+		{"start QC refuses order still in picking", models.ProcessingStatusPickingProgress, startQC, fiber.StatusNotFound, models.ProcessingStatusPickingProgress},
+		{"start QC refuses order already in QC", models.ProcessingStatusQCProgress, startQC, fiber.StatusBadRequest, models.ProcessingStatusQCProgress},
+		{"outbound of QC completed order", models.ProcessingStatusQCCompleted, createOutbound, fiber.StatusCreated, models.ProcessingStatusOutboundCompleted},
+		{"outbound refuses order in QC", models.ProcessingStatusQCProgress, createOutbound, fiber.StatusBadRequest, models.ProcessingStatusQCProgress},
+		{"outbound refuses order shipped already", models.ProcessingStatusOutboundCompleted, createOutbound, fiber.StatusBadRequest, models.ProcessingStatusOutboundCompleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed := f.OrderAtStage(tt.stage, testutil.WithChannel("Shopee"), func(o *models.Order) {
+				o.TrackingNumber = lifecycleTrackingNumber()
+			})
+
+			resp, result := tt.call(t, seed.Order)
+			expectStatus(t, tt.name, resp, result, tt.wantStatus)
+			loadOrder(t, seed.Order.TrackingNumber, tt.wantProcessing)
+		})
+	}
+}
+
+// firstBox returns the first seeded box
+func firstBox(t *testing.T) models.Box {
+	t.Helper()
+
+	var box models.Box
+	if err := testDB.Order("id").First(&box).Error; err != nil {
+		t.Fatalf("no box found: %v", err)
+	}
+	return box
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type OrderLockController struct {
+	DB *gorm.DB
+	// How long an edit lock stays held without a heartbeat
+	LockTTL time.Duration
+}
+
+func NewOrderLockController(cfg *config.Config, db *gorm.DB) *OrderLockController {
+	return &OrderLockController{DB: db, LockTTL: time.Duration(cfg.OrderEditLockTTLSeconds) * time.Second}
+}
+
+// findLockOrder loads the order of the lock endpoints, writing a not found response when it does not exist
+func (olc *OrderLockController) findLockOrder(c fiber.Ctx) (*models.Order, error) {
+	id := c.Params("id")
+	var order models.Order
+	if err := olc.DB.Select("id").Where("id = ?", id).First(&order).Error; err != nil {
+		return nil, c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with id " + id + " not found.",
+		})
+	}
+	return &order, nil
+}
+
+// lockedByOtherResponse writes the conflict response telling who is editing the order
+func (olc *OrderLockController) lockedByOtherResponse(c fiber.Ctx, orderID uint) error {
+	message := "Order is currently being edited by another user"
+	if lock := utils.ActiveOrderEditLock(olc.DB, orderID); lock != nil && lock.LockUser != nil {
+		message = "Order is currently being edited by " + lock.LockUser.FullName + " until " + lock.ExpiresAt.Format("15:04:05")
+	}
+	return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   message,
+	})
+}
+
+// GetOrderEditLock retrieves the active edit lock of an order
+// @Summary Get Order Edit Lock
+// @Description Retrieve who is currently editing an order, data is null when nobody is
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderEditLockResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/lock [get]
+func (olc *OrderLockController) GetOrderEditLock(c fiber.Ctx) error {
+	log.Println("GetOrderEditLock called")
+	order, err := olc.findLockOrder(c)
+	if order == nil {
+		return err
+	}
+
+	var data *models.OrderEditLockResponse
+	message := "Order is not being edited"
+	if lock := utils.ActiveOrderEditLock(olc.DB, order.ID); lock != nil {
+		data = lock.ToResponse()
+		message = "Order is being edited by " + data.LockedBy
+	}
+
+	log.Println("GetOrderEditLock completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
+// AcquireOrderEditLock marks an order as being edited by the logged in user
+// @Summary Acquire Order Edit Lock
+// @Description Take the edit lock of an order, or renew it when already held. The lock expires unless kept alive with heartbeats. Locks are advisory and only warn other editors.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderEditLockResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/lock [put]
+func (olc *OrderLockController) AcquireOrderEditLock(c fiber.Ctx) error {
+	log.Println("AcquireOrderEditLock called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	order, err := olc.findLockOrder(c)
+	if order == nil {
+		return err
+	}
+
+	acquired, err := utils.AcquireOrderEditLock(olc.DB, order.ID, uint(userID), olc.LockTTL)
+	if err != nil {
+		log.Println("AcquireOrderEditLock - Failed to acquire edit lock:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to acquire edit lock",
+		})
+	}
+	if !acquired {
+		log.Println("AcquireOrderEditLock - Order is locked by another user:", order.ID)
+		return olc.lockedByOtherResponse(c, order.ID)
+	}
+
+	var data *models.OrderEditLockResponse
+	if lock := utils.ActiveOrderEditLock(olc.DB, order.ID); lock != nil {
+		data = lock.ToResponse()
+	}
+
+	log.Println("AcquireOrderEditLock completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Edit lock acquired successfully",
+		Data:    data,
+	})
+}
+
+// HeartbeatOrderEditLock keeps the edit lock of the logged in user alive
+// @Summary Heartbeat Order Edit Lock
+// @Description Extend the edit lock held by the logged in user while the order is still open for editing
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderEditLockResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/lock/heartbeat [put]
+func (olc *OrderLockController) HeartbeatOrderEditLock(c fiber.Ctx) error {
+	log.Println("HeartbeatOrderEditLock called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	order, err := olc.findLockOrder(c)
+	if order == nil {
+		return err
+	}
+
+	extended, err := utils.HeartbeatOrderEditLock(olc.DB, order.ID, uint(userID), olc.LockTTL)
+	if err != nil {
+		log.Println("HeartbeatOrderEditLock - Failed to extend edit lock:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to extend edit lock",
+		})
+	}
+	if !extended {
+		// The lock expired, take it again unless someone else did in the meantime
+		acquired, err := utils.AcquireOrderEditLock(olc.DB, order.ID, uint(userID), olc.LockTTL)
+		if err != nil {
+			log.Println("HeartbeatOrderEditLock - Failed to reacquire edit lock:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to extend edit lock",
+			})
+		}
+		if !acquired {
+			log.Println("HeartbeatOrderEditLock - Edit lock was taken over:", order.ID)
+			return olc.lockedByOtherResponse(c, order.ID)
+		}
+	}
+
+	var data *models.OrderEditLockResponse
+	if lock := utils.ActiveOrderEditLock(olc.DB, order.ID); lock != nil {
+		data = lock.ToResponse()
+	}
+
+	log.Println("HeartbeatOrderEditLock completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Edit lock extended successfully",
+		Data:    data,
+	})
+}
+
+// ReleaseOrderEditLock releases the edit lock of the logged in user
+// @Summary Release Order Edit Lock
+// @Description Release the edit lock held by the logged in user when the order is closed or saved
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Order ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/orders/{id}/lock [delete]
+func (olc *OrderLockController) ReleaseOrderEditLock(c fiber.Ctx) error {
+	log.Println("ReleaseOrderEditLock called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	order, err := olc.findLockOrder(c)
+	if order == nil {
+		return err
+	}
+
+	released, err := utils.ReleaseOrderEditLock(olc.DB, order.ID, uint(userID))
+	if err != nil {
+		log.Println("ReleaseOrderEditLock - Failed to release edit lock:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to release edit lock",
+		})
+	}
+	if !released {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You do not hold the edit lock of this order",
+		})
+	}
+
+	log.Println("ReleaseOrderEditLock completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Edit lock released successfully",
+	})
+}
@@ -0,0 +1,518 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type OrderReferenceController struct {
+	DB *gorm.DB
+}
+
+func NewOrderReferenceController(db *gorm.DB) *OrderReferenceController {
+	return &OrderReferenceController{DB: db}
+}
+
+// Request structs
+type CreateReferenceAliasRequest struct {
+	Kind        string `json:"kind" validate:"required,oneof=channel store" example:"store"`
+	Alias       string `json:"alias" validate:"required,min=1,max=100" example:"Livo Official Shop"`
+	ReferenceID uint   `json:"referenceId" validate:"required" example:"3"`
+	// Rewrite the channel or store of existing orders stored with the alias to the mapped name
+	RemapOrders bool `json:"remapOrders" example:"true"`
+}
+
+type MergePendingReferenceRequest struct {
+	TargetID uint `json:"targetId" validate:"required" example:"3"`
+}
+
+// Unique response structs
+// CreateReferenceAliasResponse represents a new alias and the existing orders rewritten to its mapped name
+type CreateReferenceAliasResponse struct {
+	Alias          models.ReferenceAliasResponse `json:"alias"`
+	RemappedOrders int64                         `json:"remappedOrders"`
+}
+
+// PendingReferencesResponse represents the channels and stores created from orders and waiting for review
+type PendingReferencesResponse struct {
+	Channels []models.ChannelResponse `json:"channels"`
+	Stores   []models.StoreResponse   `json:"stores"`
+}
+
+// MergePendingReferenceResponse represents a pending channel or store merged into an existing one
+type MergePendingReferenceResponse struct {
+	Alias          models.ReferenceAliasResponse `json:"alias"`
+	RemappedOrders int64                         `json:"remappedOrders"`
+}
+
+// GetReferenceAliases retrieves the channel and store aliases
+// @Summary Get Reference Aliases
+// @Description Retrieve the aliases mapping channel and store names arriving on orders to existing channels and stores
+// @Tags Order References
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of aliases per page" default(10)
+// @Param kind query string false "Filter by kind (channel, store)"
+// @Param search query string false "Search term for the alias"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ReferenceAliasResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-references/aliases [get]
+func (orc *OrderReferenceController) GetReferenceAliases(c fiber.Ctx) error {
+	log.Println("GetReferenceAliases called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Parse filter parameters
+	kind := strings.TrimSpace(c.Query("kind", ""))
+	if kind != "" && !models.IsValidReferenceKind(kind) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid kind. Use channel or store.",
+		})
+	}
+	search := strings.TrimSpace(c.Query("search", ""))
+
+	query := orc.DB.WithContext(c.Context()).Model(&models.ReferenceAlias{})
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	if search != "" {
+		query = query.Where("alias ILIKE ?", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var aliases []models.ReferenceAlias
+	if err := query.Preload("CreateUser").Order("kind ASC, alias ASC").Offset(offset).Limit(limit).Find(&aliases).Error; err != nil {
+		log.Println("GetReferenceAliases - Failed to retrieve aliases:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve aliases",
+		})
+	}
+
+	aliasList := make([]models.ReferenceAliasResponse, len(aliases))
+	for i, alias := range aliases {
+		referenceName, _ := utils.ReferenceName(orc.DB.WithContext(c.Context()), alias.Kind, alias.ReferenceID)
+		aliasList[i] = *alias.ToResponse(referenceName)
+	}
+
+	// Build success message
+	message := "Aliases retrieved successfully"
+	var filters []string
+
+	if kind != "" {
+		filters = append(filters, "kind: "+kind)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetReferenceAliases completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    aliasList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateReferenceAlias maps a channel or store name to an existing channel or store
+// @Summary Create Reference Alias
+// @Description Map a channel or store name arriving on orders to an existing channel or store. New orders naming the alias are stored with the mapped name, existing orders can be rewritten with remapOrders. Names matching a channel or store name or code directly need no alias
+// @Tags Order References
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateReferenceAliasRequest true "Alias"
+// @Success 201 {object} utils.SuccessResponse{data=CreateReferenceAliasResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-references/aliases [post]
+func (orc *OrderReferenceController) CreateReferenceAlias(c fiber.Ctx) error {
+	log.Println("CreateReferenceAlias called")
+	// Binding request body
+	var req CreateReferenceAliasRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateReferenceAlias - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	referenceName, err := utils.ReferenceName(orc.DB, req.Kind, req.ReferenceID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("%s with id %d not found.", strings.ToUpper(req.Kind[:1])+req.Kind[1:], req.ReferenceID),
+		})
+	}
+
+	// An alias may not shadow a name or code, orders naming it already match
+	existingName, found, err := utils.FindReferenceName(orc.DB, req.Kind, req.Alias)
+	if err != nil {
+		log.Println("CreateReferenceAlias - Failed to check alias:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create alias",
+		})
+	}
+	if found {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("%q already maps to the %s %s.", req.Alias, req.Kind, existingName),
+		})
+	}
+
+	createdBy := uint(userID)
+	alias := models.ReferenceAlias{
+		Kind:        req.Kind,
+		Alias:       models.NormalizeReferenceAlias(req.Alias),
+		ReferenceID: req.ReferenceID,
+		CreatedBy:   &createdBy,
+	}
+	var remapped int64
+	err = orc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&alias).Error; err != nil {
+			return err
+		}
+		if req.RemapOrders {
+			var err error
+			remapped, err = utils.RemapOrderReferences(tx, req.Kind, req.Alias, referenceName)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("CreateReferenceAlias - Failed to create alias:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create alias",
+		})
+	}
+
+	orc.DB.Preload("CreateUser").First(&alias, alias.ID)
+
+	log.Println("CreateReferenceAlias completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Alias created successfully (%d orders remapped)", remapped),
+		Data: CreateReferenceAliasResponse{
+			Alias:          *alias.ToResponse(referenceName),
+			RemappedOrders: remapped,
+		},
+	})
+}
+
+// DeleteReferenceAlias removes a channel or store alias
+// @Summary Delete Reference Alias
+// @Description Remove a channel or store alias, orders already stored with the mapped name keep it
+// @Tags Order References
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Alias ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-references/aliases/{id} [delete]
+func (orc *OrderReferenceController) DeleteReferenceAlias(c fiber.Ctx) error {
+	log.Println("DeleteReferenceAlias called")
+	id := c.Params("id")
+
+	var alias models.ReferenceAlias
+	if err := orc.DB.Where("id = ?", id).First(&alias).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Alias with id " + id + " not found.",
+		})
+	}
+
+	if err := orc.DB.Delete(&alias).Error; err != nil {
+		log.Println("DeleteReferenceAlias - Failed to delete alias:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete alias",
+		})
+	}
+
+	log.Println("DeleteReferenceAlias completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Alias deleted successfully",
+	})
+}
+
+// GetPendingReferences retrieves the channels and stores waiting for review
+// @Summary Get Pending References
+// @Description Retrieve the channels and stores created automatically from orders naming an unknown channel or store (ORDER_UNKNOWN_CHANNEL_POLICY / ORDER_UNKNOWN_STORE_POLICY create), oldest first. Approve them as new records or merge them into existing ones
+// @Tags Order References
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=PendingReferencesResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-references/pending [get]
+func (orc *OrderReferenceController) GetPendingReferences(c fiber.Ctx) error {
+	log.Println("GetPendingReferences called")
+	var channels []models.Channel
+	if err := orc.DB.WithContext(c.Context()).Where("pending_review = ?", true).Order("created_at ASC").Find(&channels).Error; err != nil {
+		log.Println("GetPendingReferences - Failed to retrieve channels:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pending channels and stores",
+		})
+	}
+	var stores []models.Store
+	if err := orc.DB.WithContext(c.Context()).Where("pending_review = ?", true).Order("created_at ASC").Find(&stores).Error; err != nil {
+		log.Println("GetPendingReferences - Failed to retrieve stores:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pending channels and stores",
+		})
+	}
+
+	response := PendingReferencesResponse{
+		Channels: make([]models.ChannelResponse, len(channels)),
+		Stores:   make([]models.StoreResponse, len(stores)),
+	}
+	for i, channel := range channels {
+		response.Channels[i] = *channel.ToResponse()
+	}
+	for i, store := range stores {
+		response.Stores[i] = *store.ToResponse()
+	}
+
+	log.Println("GetPendingReferences completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Pending references retrieved successfully (%d channels, %d stores)", len(channels), len(stores)),
+		Data:    response,
+	})
+}
+
+// findPendingReference checks the kind of a pending reference route and loads the name of the pending channel or store
+func (orc *OrderReferenceController) findPendingReference(kind, id string) (string, error) {
+	if !models.IsValidReferenceKind(kind) {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid kind. Use channel or store.")
+	}
+
+	var names []string
+	var err error
+	if kind == models.ReferenceKindStore {
+		err = orc.DB.Model(&models.Store{}).Where("id = ? AND pending_review = ?", id, true).Pluck("store_name", &names).Error
+	} else {
+		err = orc.DB.Model(&models.Channel{}).Where("id = ? AND pending_review = ?", id, true).Pluck("channel_name", &names).Error
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fiber.NewError(fiber.StatusNotFound, "Pending "+kind+" with id "+id+" not found.")
+	}
+	return names[0], nil
+}
+
+// pendingReferenceErrorResponse writes the response of a pending reference lookup error
+func pendingReferenceErrorResponse(c fiber.Ctx, err error) error {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return c.Status(fiberErr.Code).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fiberErr.Message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Failed to retrieve pending reference",
+	})
+}
+
+// ApprovePendingReference accepts a channel or store created from an order as a new record
+// @Summary Approve Pending Reference
+// @Description Accept a channel or store created automatically from an order as a new channel or store. Edit its code and settings through the channel or store endpoints
+// @Tags Order References
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "Kind (channel, store)"
+// @Param id path int true "Channel or store ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-references/pending/{kind}/{id}/approve [put]
+func (orc *OrderReferenceController) ApprovePendingReference(c fiber.Ctx) error {
+	log.Println("ApprovePendingReference called")
+	kind, id := c.Params("kind"), c.Params("id")
+
+	name, err := orc.findPendingReference(kind, id)
+	if err != nil {
+		log.Println("ApprovePendingReference - Pending reference not found:", err)
+		return pendingReferenceErrorResponse(c, err)
+	}
+
+	var model interface{} = &models.Channel{}
+	if kind == models.ReferenceKindStore {
+		model = &models.Store{}
+	}
+	if err := orc.DB.Model(model).Where("id = ?", id).Update("pending_review", false).Error; err != nil {
+		log.Println("ApprovePendingReference - Failed to approve:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to approve " + kind,
+		})
+	}
+
+	log.Println("ApprovePendingReference completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: strings.ToUpper(kind[:1]) + kind[1:] + " " + name + " approved successfully",
+	})
+}
+
+// MergePendingReference merges a channel or store created from an order into an existing one
+// @Summary Merge Pending Reference
+// @Description Merge a channel or store created automatically from an order into an existing channel or store. Its name becomes an alias of the target, the orders stored with it are rewritten to the target name and the pending record is removed
+// @Tags Order References
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param kind path string true "Kind (channel, store)"
+// @Param id path int true "Pending channel or store ID"
+// @Param request body MergePendingReferenceRequest true "Target channel or store"
+// @Success 200 {object} utils.SuccessResponse{data=MergePendingReferenceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-references/pending/{kind}/{id}/merge [post]
+func (orc *OrderReferenceController) MergePendingReference(c fiber.Ctx) error {
+	log.Println("MergePendingReference called")
+	kind, id := c.Params("kind"), c.Params("id")
+
+	// Binding request body
+	var req MergePendingReferenceRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("MergePendingReference - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	name, err := orc.findPendingReference(kind, id)
+	if err != nil {
+		log.Println("MergePendingReference - Pending reference not found:", err)
+		return pendingReferenceErrorResponse(c, err)
+	}
+	if strconv.FormatUint(uint64(req.TargetID), 10) == id {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "A " + kind + " cannot be merged into itself",
+		})
+	}
+	targetName, err := utils.ReferenceName(orc.DB, kind, req.TargetID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Target %s with id %d not found.", kind, req.TargetID),
+		})
+	}
+
+	createdBy := uint(userID)
+	alias := models.ReferenceAlias{
+		Kind:        kind,
+		Alias:       models.NormalizeReferenceAlias(name),
+		ReferenceID: req.TargetID,
+		CreatedBy:   &createdBy,
+	}
+	var remapped int64
+	err = orc.DB.Transaction(func(tx *gorm.DB) error {
+		// Drop the pending record first, so its name no longer matches directly
+		var model interface{} = &models.Channel{}
+		if kind == models.ReferenceKindStore {
+			model = &models.Store{}
+		}
+		if err := tx.Where("id = ?", id).Delete(model).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("kind = ? AND alias = ?", kind, alias.Alias).Delete(&models.ReferenceAlias{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&alias).Error; err != nil {
+			return err
+		}
+		var err error
+		remapped, err = utils.RemapOrderReferences(tx, kind, name, targetName)
+		return err
+	})
+	if err != nil {
+		log.Println("MergePendingReference - Failed to merge:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to merge " + kind,
+		})
+	}
+
+	orc.DB.Preload("CreateUser").First(&alias, alias.ID)
+
+	log.Println("MergePendingReference completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("%s %s merged into %s successfully (%d orders remapped)", strings.ToUpper(kind[:1])+kind[1:], name, targetName, remapped),
+		Data: MergePendingReferenceResponse{
+			Alias:          *alias.ToResponse(targetName),
+			RemappedOrders: remapped,
+		},
+	})
+}
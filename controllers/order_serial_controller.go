@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type OrderSerialController struct {
+	DB *gorm.DB
+}
+
+func NewOrderSerialController(db *gorm.DB) *OrderSerialController {
+	return &OrderSerialController{DB: db}
+}
+
+// Unique response structs
+// SerialHistoryResponse represents the orders a serial number was shipped with and the complains and returns of them
+type SerialHistoryResponse struct {
+	SerialNumber string                           `json:"serialNumber"`
+	Captures     []models.OrderItemSerialResponse `json:"captures"`  // newest first, released captures included
+	Complains    []models.ComplainResponse        `json:"complains"` // complains naming the serial or filed on a parcel it was shipped in
+	Returns      []models.ReturnResponse          `json:"returns"`   // returns of the parcels it was shipped in
+}
+
+// GetSerialHistory retrieves the orders, complains and returns of a serial number
+// @Summary Get Serial History
+// @Description Retrieve the orders a serial number (IMEI or SN) was captured on at QC, newest first, with the complains and returns of those parcels. Used to check the unit a buyer sent back against the unit that was shipped
+// @Tags Order Serials
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param serialNumber path string true "Serial number"
+// @Success 200 {object} utils.SuccessResponse{data=SerialHistoryResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-serials/{serialNumber} [get]
+func (osc *OrderSerialController) GetSerialHistory(c fiber.Ctx) error {
+	log.Println("GetSerialHistory called")
+	serialNumber := models.NormalizeSerialNumber(c.Params("serialNumber"))
+
+	var captures []models.OrderItemSerial
+	if err := osc.DB.WithContext(c.Context()).Preload("CaptureUser").Where("serial_number = ?", serialNumber).Order("created_at DESC, id DESC").Find(&captures).Error; err != nil {
+		log.Println("GetSerialHistory - Failed to retrieve captures:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve serial history",
+		})
+	}
+
+	var complains []models.Complain
+	trackingNumbers := make([]string, len(captures))
+	for i, capture := range captures {
+		trackingNumbers[i] = capture.TrackingNumber
+	}
+	complainQuery := osc.DB.WithContext(c.Context()).Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").
+		Where("serial_number = ?", serialNumber)
+	if len(trackingNumbers) > 0 {
+		complainQuery = complainQuery.Or("tracking_number IN ?", trackingNumbers)
+	}
+	if err := complainQuery.Order("created_at DESC").Find(&complains).Error; err != nil {
+		log.Println("GetSerialHistory - Failed to retrieve complains:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve serial history",
+		})
+	}
+
+	if len(captures) == 0 && len(complains) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Serial number " + serialNumber + " not found.",
+		})
+	}
+
+	var returns []models.Return
+	if len(trackingNumbers) > 0 {
+		if err := osc.DB.WithContext(c.Context()).Preload("ReturnDetails").Preload("CreateUser").Preload("UpdateUser").
+			Where("tracking_number IN ?", trackingNumbers).Order("created_at DESC").Find(&returns).Error; err != nil {
+			log.Println("GetSerialHistory - Failed to retrieve returns:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve serial history",
+			})
+		}
+	}
+
+	response := SerialHistoryResponse{
+		SerialNumber: serialNumber,
+		Captures:     make([]models.OrderItemSerialResponse, len(captures)),
+		Complains:    make([]models.ComplainResponse, len(complains)),
+		Returns:      make([]models.ReturnResponse, len(returns)),
+	}
+	for i, capture := range captures {
+		response.Captures[i] = *capture.ToResponse()
+	}
+	for i, complain := range complains {
+		response.Complains[i] = *complain.ToComplainResponse()
+	}
+	for i, ret := range returns {
+		response.Returns[i] = ret.ToResponse()
+	}
+
+	log.Println("GetSerialHistory completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Serial history retrieved successfully",
+		Data:    response,
+	})
+}
@@ -0,0 +1,378 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type OrderVolumeController struct {
+	DB     *gorm.DB
+	Policy utils.OrderVolumePolicy
+}
+
+func NewOrderVolumeController(cfg *config.Config, db *gorm.DB) *OrderVolumeController {
+	return &OrderVolumeController{DB: db, Policy: utils.OrderVolumePolicyFromConfig(cfg)}
+}
+
+// Request structs
+type SaveOrderVolumeThresholdRequest struct {
+	Enabled      *bool `json:"enabled"`      // defaults to true
+	SpikePercent int   `json:"spikePercent"` // 0 disables spike alerts
+	DropPercent  int   `json:"dropPercent"`  // 0 disables drop alerts
+	MinBaseline  int   `json:"minBaseline"`
+}
+
+// Unique response structs
+// OrderVolumeThresholdsResponse represents the default order volume thresholds and the thresholds set per store or channel
+type OrderVolumeThresholdsResponse struct {
+	Defaults   utils.OrderVolumePolicy               `json:"defaults"`
+	Thresholds []models.OrderVolumeThresholdResponse `json:"thresholds"`
+}
+
+// findOrderVolumeReference returns the name of the store or channel a threshold is set for
+func (ovc *OrderVolumeController) findOrderVolumeReference(c fiber.Ctx, scope, referenceID string) (string, error) {
+	switch scope {
+	case models.OrderVolumeScopeStore:
+		var store models.Store
+		if err := ovc.DB.WithContext(c.Context()).Where("id = ?", referenceID).First(&store).Error; err != nil {
+			return "", fiber.NewError(fiber.StatusNotFound, "Store with id "+referenceID+" not found.")
+		}
+		return store.StoreName, nil
+	case models.OrderVolumeScopeChannel:
+		var channel models.Channel
+		if err := ovc.DB.WithContext(c.Context()).Where("id = ?", referenceID).First(&channel).Error; err != nil {
+			return "", fiber.NewError(fiber.StatusNotFound, "Channel with id "+referenceID+" not found.")
+		}
+		return channel.ChannelName, nil
+	}
+	return "", fiber.NewError(fiber.StatusBadRequest, "Invalid scope. Use one of: "+strings.Join(models.OrderVolumeScopes(), ", "))
+}
+
+// orderVolumeErrorResponse writes the status carried by a lookup error
+func orderVolumeErrorResponse(c fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fiberErr.Message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Failed to retrieve order volume threshold",
+	})
+}
+
+// GetOrderVolumeThresholds retrieves the order volume alert thresholds
+// @Summary Get Order Volume Thresholds
+// @Description Retrieve the default order volume alert thresholds and the thresholds set per store or channel
+// @Tags Order Volume
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param scope query string false "Filter by scope (store, channel)"
+// @Success 200 {object} utils.SuccessResponse{data=OrderVolumeThresholdsResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-volume/thresholds [get]
+func (ovc *OrderVolumeController) GetOrderVolumeThresholds(c fiber.Ctx) error {
+	log.Println("GetOrderVolumeThresholds called")
+	scope := strings.TrimSpace(c.Query("scope", ""))
+	if scope != "" && !models.IsValidOrderVolumeScope(scope) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid scope. Use one of: " + strings.Join(models.OrderVolumeScopes(), ", "),
+		})
+	}
+
+	query := ovc.DB.WithContext(c.Context()).Preload("UpdateUser").Order("scope ASC, reference_id ASC")
+	if scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+	var thresholds []models.OrderVolumeThreshold
+	if err := query.Find(&thresholds).Error; err != nil {
+		log.Println("GetOrderVolumeThresholds - Failed to retrieve thresholds:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order volume thresholds",
+		})
+	}
+
+	thresholdList := make([]models.OrderVolumeThresholdResponse, len(thresholds))
+	for i, threshold := range thresholds {
+		thresholdList[i] = *threshold.ToResponse()
+	}
+
+	message := "Order volume thresholds retrieved successfully"
+	if scope != "" {
+		message += fmt.Sprintf(" (filtered by scope: %s)", scope)
+	}
+
+	log.Println("GetOrderVolumeThresholds completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: OrderVolumeThresholdsResponse{
+			Defaults:   ovc.Policy,
+			Thresholds: thresholdList,
+		},
+	})
+}
+
+// SaveOrderVolumeThreshold creates or updates the order volume alert threshold of a store or channel
+// @Summary Save Order Volume Threshold
+// @Description Override the default order volume alert thresholds of a store or channel. An alert is raised when the orders of today deviate from the baseline by more than the spike or drop percent, once the baseline reaches the minimum. Disabling silences the store or channel
+// @Tags Order Volume
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param scope path string true "Scope (store, channel)"
+// @Param referenceId path int true "Store or channel ID"
+// @Param request body SaveOrderVolumeThresholdRequest true "Thresholds"
+// @Success 200 {object} utils.SuccessResponse{data=models.OrderVolumeThresholdResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-volume/thresholds/{scope}/{referenceId} [put]
+func (ovc *OrderVolumeController) SaveOrderVolumeThreshold(c fiber.Ctx) error {
+	log.Println("SaveOrderVolumeThreshold called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("SaveOrderVolumeThreshold - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	scope := c.Params("scope")
+	referenceID := c.Params("referenceId")
+	name, err := ovc.findOrderVolumeReference(c, scope, referenceID)
+	if err != nil {
+		return orderVolumeErrorResponse(c, err)
+	}
+
+	// Parse request body
+	var req SaveOrderVolumeThresholdRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SaveOrderVolumeThreshold - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if req.SpikePercent < 0 || req.DropPercent < 0 || req.DropPercent > 100 || req.MinBaseline < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Spike percent and minimum baseline must not be negative, drop percent must be between 0 and 100",
+		})
+	}
+
+	var threshold models.OrderVolumeThreshold
+	err = ovc.DB.WithContext(c.Context()).Where("scope = ? AND reference_id = ?", scope, referenceID).First(&threshold).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("SaveOrderVolumeThreshold - Failed to retrieve threshold:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order volume threshold",
+		})
+	}
+
+	parsedReferenceID, _ := strconv.ParseUint(referenceID, 10, 32)
+	threshold.Scope = scope
+	threshold.ReferenceID = uint(parsedReferenceID)
+	threshold.Enabled = req.Enabled == nil || *req.Enabled
+	threshold.SpikePercent = req.SpikePercent
+	threshold.DropPercent = req.DropPercent
+	threshold.MinBaseline = req.MinBaseline
+	threshold.UpdatedBy = uint(userID)
+	if err := ovc.DB.WithContext(c.Context()).Save(&threshold).Error; err != nil {
+		log.Println("SaveOrderVolumeThreshold - Failed to save threshold:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save order volume threshold",
+		})
+	}
+
+	// Reload threshold with its user for response
+	ovc.DB.WithContext(c.Context()).Preload("UpdateUser").Where("id = ?", threshold.ID).First(&threshold)
+
+	log.Println("SaveOrderVolumeThreshold completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order volume threshold of " + scope + " " + name + " saved successfully",
+		Data:    threshold.ToResponse(),
+	})
+}
+
+// DeleteOrderVolumeThreshold removes the order volume alert threshold of a store or channel
+// @Summary Delete Order Volume Threshold
+// @Description Remove the thresholds of a store or channel, it is alerted with the default thresholds again
+// @Tags Order Volume
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param scope path string true "Scope (store, channel)"
+// @Param referenceId path int true "Store or channel ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-volume/thresholds/{scope}/{referenceId} [delete]
+func (ovc *OrderVolumeController) DeleteOrderVolumeThreshold(c fiber.Ctx) error {
+	log.Println("DeleteOrderVolumeThreshold called")
+	scope := c.Params("scope")
+	referenceID := c.Params("referenceId")
+	if !models.IsValidOrderVolumeScope(scope) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid scope. Use one of: " + strings.Join(models.OrderVolumeScopes(), ", "),
+		})
+	}
+
+	result := ovc.DB.WithContext(c.Context()).Where("scope = ? AND reference_id = ?", scope, referenceID).Delete(&models.OrderVolumeThreshold{})
+	if result.Error != nil {
+		log.Println("DeleteOrderVolumeThreshold - Failed to delete threshold:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete order volume threshold",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order volume threshold set for " + scope + " with id " + referenceID + ".",
+		})
+	}
+
+	log.Println("DeleteOrderVolumeThreshold completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order volume threshold deleted successfully",
+	})
+}
+
+// GetOrderVolumeAlerts retrieves the order volume alerts
+// @Summary Get Order Volume Alerts
+// @Description Retrieve the stores and channels whose daily orders deviated from their baseline, latest first, with pagination and filters. Alerts are detected by a background job
+// @Tags Order Volume
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of alerts per page" default(10)
+// @Param scope query string false "Filter by scope (store, channel)"
+// @Param direction query string false "Filter by direction (spike, drop)"
+// @Param startDate query string false "Filter by date from (YYYY-MM-DD format)"
+// @Param endDate query string false "Filter by date to (YYYY-MM-DD format)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.OrderVolumeAlertResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/order-volume/alerts [get]
+func (ovc *OrderVolumeController) GetOrderVolumeAlerts(c fiber.Ctx) error {
+	log.Println("GetOrderVolumeAlerts called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Parse filter parameters
+	scope := strings.TrimSpace(c.Query("scope", ""))
+	direction := strings.TrimSpace(c.Query("direction", ""))
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+
+	query := ovc.DB.WithContext(c.Context()).Model(&models.OrderVolumeAlert{})
+	var filters []string
+	if scope != "" {
+		if !models.IsValidOrderVolumeScope(scope) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid scope. Use one of: " + strings.Join(models.OrderVolumeScopes(), ", "),
+			})
+		}
+		query = query.Where("scope = ?", scope)
+		filters = append(filters, "scope: "+scope)
+	}
+	if direction != "" {
+		if direction != models.OrderVolumeDirectionSpike && direction != models.OrderVolumeDirectionDrop {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid direction. Use spike or drop.",
+			})
+		}
+		query = query.Where("direction = ?", direction)
+		filters = append(filters, "direction: "+direction)
+	}
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("date >= ?", parsedStartDate)
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("date <= ?", parsedEndDate)
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var alerts []models.OrderVolumeAlert
+	if err := query.Order("detected_at DESC, id DESC").Offset(offset).Limit(limit).Find(&alerts).Error; err != nil {
+		log.Println("GetOrderVolumeAlerts - Failed to retrieve alerts:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order volume alerts",
+		})
+	}
+
+	alertList := make([]models.OrderVolumeAlertResponse, len(alerts))
+	for i, alert := range alerts {
+		alertList[i] = *alert.ToResponse()
+	}
+
+	message := "Order volume alerts retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetOrderVolumeAlerts completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    alertList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
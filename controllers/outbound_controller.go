@@ -1,10 +1,14 @@
 package controllers
 
 import (
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -27,6 +31,23 @@ type CreateOutboundRequest struct {
 	Expedition      string `json:"expedition"`
 	ExpeditionSlug  string `json:"expeditionSlug"`
 	ExpeditionColor string `json:"expeditionColor"`
+
+	// Optional parcel measurements sent by the scanning client from a connected scale
+	WeightGrams *int     `json:"weightGrams" validate:"omitempty,min=1" example:"1250"`
+	LengthCm    *float64 `json:"lengthCm" validate:"omitempty,gt=0" example:"30"`
+	WidthCm     *float64 `json:"widthCm" validate:"omitempty,gt=0" example:"20"`
+	HeightCm    *float64 `json:"heightCm" validate:"omitempty,gt=0" example:"10"`
+}
+
+type ImportCourierInvoiceRequest struct {
+	InvoiceRef string                     `json:"invoiceRef" validate:"required" example:"JNE-INV-2024-10"`
+	Rows       []CourierInvoiceRowRequest `json:"rows" validate:"required,dive"`
+}
+
+type CourierInvoiceRowRequest struct {
+	TrackingNumber   string  `json:"trackingNumber" validate:"required" example:"JNE1234567890"`
+	InvoicedWeightKg float64 `json:"invoicedWeightKg" validate:"required,gt=0" example:"2"`
+	InvoicedAmount   *int    `json:"invoicedAmount" validate:"omitempty,min=0" example:"18000"`
 }
 
 type UpdateOutboundRequest struct {
@@ -216,7 +237,25 @@ func (oc *OutboundController) CreateOutbound(c fiber.Ctx) error {
 	}
 
 	// Convert tracking number to uppercase and trim spaces
-	req.TrackingNumber = strings.ToUpper(strings.TrimSpace(req.TrackingNumber))
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
+
+	// Reject scans that do not match the courier format, mistyped numbers are caught here with a suggestion
+	if err := utils.ValidateTrackingNumber(oc.DB, req.TrackingNumber); err != nil {
+		log.Println("CreateOutbound - Tracking number format mismatch:", req.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// Measurements are optional, but must be positive when sent
+	if (req.WeightGrams != nil && *req.WeightGrams <= 0) || (req.LengthCm != nil && *req.LengthCm <= 0) ||
+		(req.WidthCm != nil && *req.WidthCm <= 0) || (req.HeightCm != nil && *req.HeightCm <= 0) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Weight and dimensions must be greater than 0",
+		})
+	}
 
 	// Get current logged in user from context
 	userIDStr := c.Locals("userId").(string)
@@ -228,6 +267,16 @@ func (oc *OutboundController) CreateOutbound(c fiber.Ctx) error {
 		})
 	}
 
+	// Canceled orders must not be handed over to the courier
+	var canceledOrder models.Order
+	if err := oc.DB.Where("tracking_number = ? AND event_status = ?", req.TrackingNumber, models.EventStatusCanceled).First(&canceledOrder).Error; err == nil {
+		log.Println("CreateOutbound - Order is canceled:", req.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with tracking number " + req.TrackingNumber + " has been canceled.",
+		})
+	}
+
 	// Check if tracking number exists in orders and processing status is "qc completed"
 	var order models.Order
 	if err := oc.DB.Where("tracking_number = ? AND processing_status = ?", req.TrackingNumber, "qc_completed").First(&order).Error; err != nil {
@@ -305,6 +354,10 @@ func (oc *OutboundController) CreateOutbound(c fiber.Ctx) error {
 		Expedition:      expedition,
 		ExpeditionSlug:  expeditionSlug,
 		ExpeditionColor: expeditionColor,
+		WeightGrams:     req.WeightGrams,
+		LengthCm:        req.LengthCm,
+		WidthCm:         req.WidthCm,
+		HeightCm:        req.HeightCm,
 	}
 
 	if err := oc.DB.Create(&outbound).Error; err != nil {
@@ -322,6 +375,24 @@ func (oc *OutboundController) CreateOutbound(c fiber.Ctx) error {
 		})
 	}
 
+	// Orders merged into this one ship in the same package
+	if err := oc.DB.Model(&models.Order{}).Where("merged_into_id = ? AND event_status = ?", order.ID, "merged").Updates(map[string]interface{}{
+		"processing_status": "outbound_completed",
+		"event_status":      "completed",
+	}).Error; err != nil {
+		log.Println("CreateOutbound - Failed to complete merged orders:", err)
+	}
+
+	// The parcel leaves its QC outbound lane, a courier bin keeps it until handover
+	if err := utils.ReleaseStagingPlacement(oc.DB, req.TrackingNumber, models.StagingRemovalOutbound, uint(userID), models.StagingAreaQCOutboundLane); err != nil {
+		log.Println("CreateOutbound - Failed to release staging placement:", err)
+	}
+
+	// Queue the completed order for the accounting system of its store, merged orders are booked with it
+	if err := utils.EnqueueAccountingSync(oc.DB, &order); err != nil {
+		log.Println("CreateOutbound - Failed to queue accounting sync:", err)
+	}
+
 	// reload created outbound with outbound user
 	if err := oc.DB.Preload("OutboundUser").Where("id = ?", outbound.ID).First(&outbound).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
@@ -430,6 +501,7 @@ func (oc *OutboundController) UpdateOutbound(c fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param live query bool false "Count the outbound records instead of the daily aggregates" default(false)
 // @Success 200 {object} utils.SuccessResponse{data=OutboundsDailyCountResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -448,10 +520,19 @@ func (oc *OutboundController) GetChartOutbounds(c fiber.Ctx) error {
 	// First day of next month at 00:00:00 (to use as upper bound)
 	startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
 
+	// Read the daily aggregates unless live counts are requested
+	live := c.Query("live", "false") == "true"
+	dailyQuery := oc.DB.Model(&models.Outbound{}).Select("DATE(created_at) as date, COUNT(*) as count").Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Group("DATE(created_at)")
+	totalQuery := oc.DB.Model(&models.Outbound{}).Select("COUNT(*)").Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth)
+	if !live {
+		dailyQuery = utils.DailyAggregateQuery(oc.DB, models.DailyAggregateOutboundByExpedition, "", startOfMonth, startOfNextMonth).Select("date, SUM(count) as count").Group("date")
+		totalQuery = utils.DailyAggregateQuery(oc.DB, models.DailyAggregateOutboundByExpedition, "", startOfMonth, startOfNextMonth).Select("COALESCE(SUM(count), 0)")
+	}
+
 	// Query to get daily counts for current month
 	var dailyCounts []OutboundsDailyCount
 
-	if err := oc.DB.Model(&models.Outbound{}).Select("DATE(created_at) as date, COUNT(*) as count").Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Group("DATE(created_at)").Order("date ASC").Scan(&dailyCounts).Error; err != nil {
+	if err := dailyQuery.Order("date ASC").Scan(&dailyCounts).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to retrieve Outbound data",
@@ -460,7 +541,7 @@ func (oc *OutboundController) GetChartOutbounds(c fiber.Ctx) error {
 
 	// Get total count for the current month
 	var totalCount int64
-	if err := oc.DB.Model(&models.Outbound{}).Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Count(&totalCount).Error; err != nil {
+	if err := totalQuery.Scan(&totalCount).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to retrieve total Outbound count",
@@ -484,3 +565,201 @@ func (oc *OutboundController) GetChartOutbounds(c fiber.Ctx) error {
 		Data:    response,
 	})
 }
+
+// CourierInvoiceImportResponse represents the result of a courier invoice import
+type CourierInvoiceImportResponse struct {
+	InvoiceRef string                       `json:"invoiceRef"`
+	Total      int                          `json:"total"`
+	Matched    int                          `json:"matched"`
+	Failed     int                          `json:"failed"`
+	Results    []CourierInvoiceImportResult `json:"results"`
+}
+
+type CourierInvoiceImportResult struct {
+	Index          int    `json:"index"`
+	TrackingNumber string `json:"trackingNumber"`
+	Result         string `json:"result"` // matched or failed
+	Reason         string `json:"reason,omitempty"`
+}
+
+// parseCourierInvoiceCSV reads courier invoice rows from a CSV with a header naming the tracking number and weight in kg columns,
+// and optionally the invoiced amount
+func parseCourierInvoiceCSV(reader io.Reader) ([]CourierInvoiceRowRequest, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("CSV file is empty")
+	}
+
+	normalize := func(value string) string {
+		return strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(value)))
+	}
+
+	trackingCol, weightCol, amountCol := -1, -1, -1
+	for i, column := range records[0] {
+		switch normalize(column) {
+		case "trackingnumber", "awb", "resi", "noresi":
+			trackingCol = i
+		case "invoicedweightkg", "weightkg", "weight", "berat":
+			weightCol = i
+		case "invoicedamount", "amount", "ongkir", "cost":
+			amountCol = i
+		}
+	}
+	if trackingCol < 0 || weightCol < 0 {
+		return nil, errors.New("header must contain trackingNumber and invoicedWeightKg columns")
+	}
+
+	rows := make([]CourierInvoiceRowRequest, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if len(record) <= trackingCol || len(record) <= weightCol {
+			return nil, fmt.Errorf("row %d must contain tracking number and weight", i+2)
+		}
+		weight, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(record[weightCol]), ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d has an invalid weight %q", i+2, record[weightCol])
+		}
+		row := CourierInvoiceRowRequest{TrackingNumber: record[trackingCol], InvoicedWeightKg: weight}
+		if amountCol >= 0 && len(record) > amountCol && strings.TrimSpace(record[amountCol]) != "" {
+			amount, err := strconv.Atoi(strings.TrimSpace(record[amountCol]))
+			if err != nil {
+				return nil, fmt.Errorf("row %d has an invalid amount %q", i+2, record[amountCol])
+			}
+			row.InvoicedAmount = &amount
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ImportCourierInvoice records the weights a courier invoiced per parcel for the courier weight audit
+// @Summary Import Courier Invoice
+// @Description Record the invoiced weight and amount of each parcel of a courier invoice on its outbound, for comparison with the weight measured at the outbound scan. Accepts JSON rows or a CSV file upload (file) with trackingNumber, invoicedWeightKg and optional invoicedAmount columns plus an invoiceRef form field. Importing a parcel again overwrites its invoice line
+// @Tags Outbounds
+// @Accept json
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param request body ImportCourierInvoiceRequest false "Invoice reference and rows"
+// @Param file formData file false "CSV file with trackingNumber, invoicedWeightKg and invoicedAmount columns"
+// @Param invoiceRef formData string false "Invoice reference, required with a CSV file"
+// @Success 200 {object} utils.SuccessResponse{data=CourierInvoiceImportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/outbounds/courier-invoices [post]
+func (oc *OutboundController) ImportCourierInvoice(c fiber.Ctx) error {
+	log.Println("ImportCourierInvoice called")
+	// Parse rows from CSV upload or JSON body
+	var req ImportCourierInvoiceRequest
+	if strings.HasPrefix(strings.ToLower(c.Get(fiber.HeaderContentType)), fiber.MIMEMultipartForm) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			log.Println("ImportCourierInvoice - CSV file required:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "CSV file is required",
+			})
+		}
+		src, err := file.Open()
+		if err != nil {
+			log.Println("ImportCourierInvoice - Failed to open CSV file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to open CSV file",
+			})
+		}
+		defer src.Close()
+
+		if req.Rows, err = parseCourierInvoiceCSV(src); err != nil {
+			log.Println("ImportCourierInvoice - Invalid CSV file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid CSV file: " + err.Error(),
+			})
+		}
+		req.InvoiceRef = c.FormValue("invoiceRef")
+	} else if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ImportCourierInvoice - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.InvoiceRef = strings.TrimSpace(req.InvoiceRef)
+	if req.InvoiceRef == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invoice reference is required",
+		})
+	}
+	if len(req.Rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No rows to import",
+		})
+	}
+
+	now := time.Now()
+	response := CourierInvoiceImportResponse{
+		InvoiceRef: req.InvoiceRef,
+		Total:      len(req.Rows),
+		Results:    make([]CourierInvoiceImportResult, 0, len(req.Rows)),
+	}
+	for i, row := range req.Rows {
+		// Convert tracking number to uppercase and trim spaces
+		result := CourierInvoiceImportResult{
+			Index:          i,
+			TrackingNumber: models.NormalizeIdentifier(row.TrackingNumber),
+			Result:         "failed",
+		}
+
+		switch {
+		case result.TrackingNumber == "":
+			result.Reason = "Tracking number is required"
+		case row.InvoicedWeightKg <= 0:
+			result.Reason = "Invoiced weight must be greater than 0"
+		case row.InvoicedAmount != nil && *row.InvoicedAmount < 0:
+			result.Reason = "Invoiced amount cannot be negative"
+		default:
+			weightGrams := int(math.Round(row.InvoicedWeightKg * 1000))
+			update := oc.DB.Model(&models.Outbound{}).Where("tracking_number = ?", result.TrackingNumber).Updates(map[string]interface{}{
+				"invoiced_weight_grams": weightGrams,
+				"invoiced_amount":       row.InvoicedAmount,
+				"invoice_ref":           req.InvoiceRef,
+				"invoice_imported_at":   now,
+			})
+			switch {
+			case update.Error != nil:
+				log.Println("ImportCourierInvoice - Failed to record invoice line:", result.TrackingNumber, update.Error)
+				result.Reason = "Failed to record invoice line"
+			case update.RowsAffected == 0:
+				result.Reason = "Outbound not found"
+			default:
+				result.Result = "matched"
+			}
+		}
+
+		if result.Result == "matched" {
+			response.Matched++
+		} else {
+			response.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	log.Printf("ImportCourierInvoice completed (matched=%d, failed=%d)\n", response.Matched, response.Failed)
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Courier invoice " + req.InvoiceRef + " imported",
+		Data:    response,
+	})
+}
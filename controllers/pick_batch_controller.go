@@ -0,0 +1,968 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type PickBatchController struct {
+	DB *gorm.DB
+}
+
+func NewPickBatchController(db *gorm.DB) *PickBatchController {
+	return &PickBatchController{DB: db}
+}
+
+// Request structs
+type CreatePickBatchRequest struct {
+	OrderIDs []uint `json:"orderIds" validate:"required,min=2" example:"12,15,18"` // cart slots follow this order
+}
+
+type PickBatchItemRequest struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity int    `json:"quantity"` // defaults to 1
+}
+
+type ConfirmBatchSortationRequest struct {
+	CartSlot int `json:"cartSlot" validate:"required" example:"1"`
+}
+
+type PrintPickBatchesRequest struct {
+	BatchIDs []uint `json:"batchIds" validate:"required,min=1" example:"3,4,5"` // printed in this order
+}
+
+// Unique response structs
+// PickListOrder is the share of one order in an aggregated pick list line
+type PickListOrder struct {
+	OrderID        uint   `json:"orderId"`
+	TrackingNumber string `json:"trackingNumber"`
+	CartSlot       int    `json:"cartSlot"`
+	Quantity       int    `json:"quantity"`
+	PickedQuantity int    `json:"pickedQuantity"`
+	GiftMessage    string `json:"giftMessage,omitempty"`
+	HandlingNote   string `json:"handlingNote,omitempty"`
+	Note           string `json:"note,omitempty"` // item note of the order detail
+}
+
+// PickListItem is one SKU of a pick batch with its quantity summed over all orders on the cart
+type PickListItem struct {
+	SKU            string          `json:"sku"`
+	ProductName    string          `json:"productName"`
+	Variant        string          `json:"variant"`
+	DisplayName    string          `json:"displayName"`
+	DisplayVariant string          `json:"displayVariant"`
+	Location       string          `json:"location"`
+	TotalQuantity  int             `json:"totalQuantity"`
+	PickedQuantity int             `json:"pickedQuantity"`
+	Remaining      int             `json:"remaining"`
+	HasNotes       bool            `json:"hasNotes"` // an order of the line carries a gift message, handling note or item note
+	Orders         []PickListOrder `json:"orders"`
+}
+
+// PickBatchAllocation tells the picker how many units of a picked SKU go into which cart slot
+type PickBatchAllocation struct {
+	CartSlot       int    `json:"cartSlot"`
+	OrderID        uint   `json:"orderId"`
+	TrackingNumber string `json:"trackingNumber"`
+	Quantity       int    `json:"quantity"`
+}
+
+// PickBatchDetailResponse is a pick batch with its aggregated pick list
+type PickBatchDetailResponse struct {
+	Batch       *models.PickBatchResponse `json:"batch"`
+	PickList    []PickListItem            `json:"pickList"`
+	Allocations []PickBatchAllocation     `json:"allocations,omitempty"`
+}
+
+// loadPickBatch loads a pick batch of the picker with its orders in cart slot order
+func (pbc *PickBatchController) loadPickBatch(id interface{}, pickerID uint) (*models.PickBatch, error) {
+	var batch models.PickBatch
+	if err := pbc.DB.Preload("Orders", func(db *gorm.DB) *gorm.DB {
+		return db.Order("cart_slot ASC")
+	}).Preload("Orders.Order.OrderDetails").Preload("Picker").Where("id = ? AND picker_id = ?", id, pickerID).First(&batch).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// buildPickList sums the quantity of every SKU still on the cart with a breakdown per order, in walking order by product location
+func (pbc *PickBatchController) buildPickList(batch *models.PickBatch, language string) []PickListItem {
+	pickList := []PickListItem{}
+	itemIndex := make(map[string]int)
+	for _, batchOrder := range batch.Orders {
+		order := batchOrder.Order
+		if order == nil || batchOrder.SortedAt != nil || order.ProcessingStatus != models.ProcessingStatusPickingProgress {
+			continue
+		}
+		for _, detail := range order.OrderDetails {
+			if detail.IsShortage {
+				continue
+			}
+			key := strings.ToUpper(detail.SKU)
+			idx, ok := itemIndex[key]
+			if !ok {
+				idx = len(pickList)
+				itemIndex[key] = idx
+				pickList = append(pickList, PickListItem{
+					SKU:         detail.SKU,
+					ProductName: detail.ProductName,
+					Variant:     detail.Variant,
+				})
+			}
+			pickList[idx].TotalQuantity += detail.Quantity
+			pickList[idx].PickedQuantity += detail.PickedQuantity
+			pickList[idx].Remaining += detail.Quantity - detail.PickedQuantity
+			pickList[idx].Orders = append(pickList[idx].Orders, PickListOrder{
+				OrderID:        order.ID,
+				TrackingNumber: order.TrackingNumber,
+				CartSlot:       batchOrder.CartSlot,
+				Quantity:       detail.Quantity,
+				PickedQuantity: detail.PickedQuantity,
+				GiftMessage:    order.GiftMessage,
+				HandlingNote:   order.HandlingNote,
+				Note:           detail.Note,
+			})
+			if order.GiftMessage != "" || order.HandlingNote != "" || detail.Note != "" {
+				pickList[idx].HasNotes = true
+			}
+		}
+	}
+
+	// Attach product locations so the picker can walk the aisles in order
+	skus := make([]string, len(pickList))
+	for i := range pickList {
+		skus[i] = pickList[i].SKU
+		var product models.Product
+		if err := pbc.DB.Where("sku = ?", pickList[i].SKU).First(&product).Error; err == nil {
+			pickList[i].Location = product.Location
+		}
+	}
+
+	// Show the curated product names instead of the marketplace ones
+	names := utils.LoadProductNames(pbc.DB, language, skus)
+	for i := range pickList {
+		pickList[i].DisplayName, pickList[i].DisplayVariant = names.Display(pickList[i].SKU, pickList[i].ProductName, pickList[i].Variant)
+	}
+	sort.SliceStable(pickList, func(i, j int) bool {
+		if pickList[i].Location != pickList[j].Location {
+			return pickList[i].Location < pickList[j].Location
+		}
+		return pickList[i].SKU < pickList[j].SKU
+	})
+
+	return pickList
+}
+
+// activePickBatchCode returns the code of the active pick batch the order is waiting for sortation in, or an empty string
+func activePickBatchCode(db *gorm.DB, orderID uint) string {
+	var batch models.PickBatch
+	if err := db.Joins("JOIN pick_batch_orders ON pick_batch_orders.pick_batch_id = pick_batches.id").
+		Where("pick_batch_orders.order_id = ? AND pick_batch_orders.sorted_at IS NULL AND pick_batches.status = ?", orderID, models.PickBatchStatusPicking).
+		First(&batch).Error; err != nil {
+		return ""
+	}
+	return batch.BatchCode
+}
+
+// GetMyPickBatches retrieves the active pick batches of the picker
+// @Summary Get My Pick Batches
+// @Description Retrieve the pick carts the logged in picker is currently working
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessTotaledResponse{data=[]models.PickBatchResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-pick-batches [get]
+func (pbc *PickBatchController) GetMyPickBatches(c fiber.Ctx) error {
+	log.Println("GetMyPickBatches called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("GetMyPickBatches - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var batches []models.PickBatch
+	if err := pbc.DB.Preload("Orders", func(db *gorm.DB) *gorm.DB {
+		return db.Order("cart_slot ASC")
+	}).Preload("Orders.Order").Preload("Picker").
+		Where("picker_id = ? AND status = ?", userID, models.PickBatchStatusPicking).Order("created_at DESC").Find(&batches).Error; err != nil {
+		log.Println("GetMyPickBatches - Failed to retrieve pick batches:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pick batches",
+		})
+	}
+
+	batchList := make([]models.PickBatchResponse, len(batches))
+	for i, batch := range batches {
+		batchList[i] = *batch.ToResponse()
+	}
+
+	log.Println("GetMyPickBatches completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: "Pick batches retrieved successfully",
+		Data:    batchList,
+		Total:   int64(len(batchList)),
+	})
+}
+
+// GetMyPickBatch retrieves a pick batch with its aggregated pick list
+// @Summary Get My Pick Batch
+// @Description Retrieve a pick cart of the logged in picker with the quantity to pick per SKU and its breakdown per order
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Pick Batch ID"
+// @Success 200 {object} utils.SuccessResponse{data=PickBatchDetailResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-pick-batches/{id} [get]
+func (pbc *PickBatchController) GetMyPickBatch(c fiber.Ctx) error {
+	log.Println("GetMyPickBatch called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("GetMyPickBatch - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	batch, err := pbc.loadPickBatch(id, uint(userID))
+	if err != nil {
+		log.Println("GetMyPickBatch - Pick batch not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick batch with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetMyPickBatch completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Pick batch retrieved successfully",
+		Data: PickBatchDetailResponse{
+			Batch:    batch.ToResponse(),
+			PickList: pbc.buildPickList(batch, utils.RequestLanguage(c)),
+		},
+	})
+}
+
+// CreatePickBatch puts several of the picker's assigned orders on one cart
+// @Summary Create Pick Batch
+// @Description Group orders assigned to the logged in picker into a pick cart, each order gets a cart slot in the given order
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreatePickBatchRequest true "Orders to put on the cart"
+// @Success 201 {object} utils.SuccessResponse{data=PickBatchDetailResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-pick-batches [post]
+func (pbc *PickBatchController) CreatePickBatch(c fiber.Ctx) error {
+	log.Println("CreatePickBatch called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CreatePickBatch - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req CreatePickBatchRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreatePickBatch - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Drop duplicate order ids, keeping the first position
+	orderIDs := []uint{}
+	seen := make(map[uint]bool)
+	for _, orderID := range req.OrderIDs {
+		if !seen[orderID] {
+			seen[orderID] = true
+			orderIDs = append(orderIDs, orderID)
+		}
+	}
+	if len(orderIDs) < 2 || len(orderIDs) > models.MaxPickBatchOrders {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("A pick batch needs between 2 and %d orders", models.MaxPickBatchOrders),
+		})
+	}
+
+	// Every order must be assigned to the picker and still being picked
+	var orders []models.Order
+	if err := pbc.DB.Where("id IN ?", orderIDs).Find(&orders).Error; err != nil {
+		log.Println("CreatePickBatch - Failed to retrieve orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve orders",
+		})
+	}
+	ordersByID := make(map[uint]models.Order, len(orders))
+	for _, order := range orders {
+		ordersByID[order.ID] = order
+	}
+	for _, orderID := range orderIDs {
+		order, ok := ordersByID[orderID]
+		if !ok || order.PickedBy == nil || *order.PickedBy != uint(userID) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Order with id %d is not assigned to you", orderID),
+			})
+		}
+		if order.ProcessingStatus != models.ProcessingStatusPickingProgress {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Order " + order.TrackingNumber + " not in picking progress status",
+			})
+		}
+		if batchCode := activePickBatchCode(pbc.DB, order.ID); batchCode != "" {
+			return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Order " + order.TrackingNumber + " is already on pick batch " + batchCode,
+			})
+		}
+	}
+
+	batch := models.PickBatch{
+		BatchCode: utils.GeneratePickBatchCode(pbc.DB),
+		PickerID:  uint(userID),
+		Status:    models.PickBatchStatusPicking,
+	}
+	for i, orderID := range orderIDs {
+		batch.Orders = append(batch.Orders, models.PickBatchOrder{OrderID: orderID, CartSlot: i + 1})
+	}
+	if err := pbc.DB.Create(&batch).Error; err != nil {
+		log.Println("CreatePickBatch - Failed to create pick batch:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create pick batch",
+		})
+	}
+
+	created, err := pbc.loadPickBatch(batch.ID, uint(userID))
+	if err != nil {
+		log.Println("CreatePickBatch - Failed to load pick batch:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load pick batch",
+		})
+	}
+
+	log.Println("CreatePickBatch completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Pick batch %s created with %d orders", created.BatchCode, len(created.Orders)),
+		Data: PickBatchDetailResponse{
+			Batch:    created.ToResponse(),
+			PickList: pbc.buildPickList(created, utils.RequestLanguage(c)),
+		},
+	})
+}
+
+// PickBatchItem confirms picked units of an SKU for the whole cart and allocates them to the orders by cart slot
+// @Summary Pick Batch Item
+// @Description Confirm a scanned SKU and quantity for a pick cart. The units are allocated to the orders still needing the SKU in cart slot order and the response tells which slot each unit goes into.
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Pick Batch ID"
+// @Param request body PickBatchItemRequest true "Scanned SKU and quantity"
+// @Success 200 {object} utils.SuccessResponse{data=PickBatchDetailResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-pick-batches/{id}/items/pick [put]
+func (pbc *PickBatchController) PickBatchItem(c fiber.Ctx) error {
+	log.Println("PickBatchItem called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("PickBatchItem - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req PickBatchItemRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("PickBatchItem - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	if req.SKU == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU is required",
+		})
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Quantity must be greater than 0",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	batch, err := pbc.loadPickBatch(id, uint(userID))
+	if err != nil {
+		log.Println("PickBatchItem - Pick batch not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick batch with id " + id + " not found.",
+		})
+	}
+	if batch.Status != models.PickBatchStatusPicking {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick batch " + batch.BatchCode + " is already " + batch.Status,
+		})
+	}
+
+	// Allocate the picked units to the checklist items still needing the SKU, in cart slot order
+	remaining := req.Quantity
+	allocations := []PickBatchAllocation{}
+	pickedDetails := make(map[uint]int)
+	for _, batchOrder := range batch.Orders {
+		order := batchOrder.Order
+		if order == nil || batchOrder.SortedAt != nil || order.ProcessingStatus != models.ProcessingStatusPickingProgress {
+			continue
+		}
+		for _, detail := range order.OrderDetails {
+			open := detail.Quantity - detail.PickedQuantity
+			if remaining == 0 || !strings.EqualFold(detail.SKU, req.SKU) || detail.IsPicked || detail.IsShortage || open <= 0 {
+				continue
+			}
+			quantity := min(open, remaining)
+			remaining -= quantity
+			pickedDetails[detail.ID] = detail.PickedQuantity + quantity
+			allocations = append(allocations, PickBatchAllocation{
+				CartSlot:       batchOrder.CartSlot,
+				OrderID:        order.ID,
+				TrackingNumber: order.TrackingNumber,
+				Quantity:       quantity,
+			})
+		}
+	}
+
+	if len(allocations) == 0 {
+		log.Println("PickBatchItem - SKU has nothing left to pick:", req.SKU)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU " + req.SKU + " has no unpicked quantity on this cart",
+		})
+	}
+	if remaining > 0 {
+		log.Println("PickBatchItem - Quantity exceeds remaining:", req.Quantity, req.Quantity-remaining)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Quantity exceeds remaining quantity for SKU %s on this cart (%d remaining)", req.SKU, req.Quantity-remaining),
+		})
+	}
+
+	now := time.Now()
+	err = pbc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, batchOrder := range batch.Orders {
+			if batchOrder.Order == nil {
+				continue
+			}
+			for _, detail := range batchOrder.Order.OrderDetails {
+				pickedQuantity, ok := pickedDetails[detail.ID]
+				if !ok {
+					continue
+				}
+				if err := tx.Model(&models.OrderDetail{}).Where("id = ?", detail.ID).Updates(map[string]interface{}{
+					"picked_quantity": pickedQuantity,
+					"is_picked":       pickedQuantity >= detail.Quantity,
+					"item_picked_at":  now,
+				}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("PickBatchItem - Failed to update order details:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update order details: " + err.Error(),
+		})
+	}
+
+	// Record the zone the pick was made in, for warehouse analytics only so it never fails the pick
+	zonePicks := make([]utils.ZonePick, len(allocations))
+	for i, allocation := range allocations {
+		zonePicks[i] = utils.ZonePick{OrderID: allocation.OrderID, SKU: req.SKU, Quantity: allocation.Quantity}
+	}
+	if err := utils.RecordPickZoneVisits(pbc.DB, uint(userID), &batch.ID, zonePicks); err != nil {
+		log.Println("PickBatchItem - Failed to record pick zone visits:", err)
+	}
+
+	updated, err := pbc.loadPickBatch(batch.ID, uint(userID))
+	if err != nil {
+		log.Println("PickBatchItem - Failed to load pick batch:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load pick batch",
+		})
+	}
+
+	slots := make([]string, len(allocations))
+	for i, allocation := range allocations {
+		slots[i] = fmt.Sprintf("%d to slot %d", allocation.Quantity, allocation.CartSlot)
+	}
+
+	log.Println("PickBatchItem completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Picked %d of SKU %s: %s", req.Quantity, req.SKU, strings.Join(slots, ", ")),
+		Data: PickBatchDetailResponse{
+			Batch:       updated.ToResponse(),
+			PickList:    pbc.buildPickList(updated, utils.RequestLanguage(c)),
+			Allocations: allocations,
+		},
+	})
+}
+
+// ConfirmBatchSortation confirms a cart slot holds all items of its order and sends the order on to QC
+// @Summary Confirm Batch Sortation
+// @Description Confirm the items of an order were sorted into its cart slot. The order is marked as picked and proceeds to QC, the batch completes once every order is sorted or has left the cart.
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Pick Batch ID"
+// @Param request body ConfirmBatchSortationRequest true "Cart slot"
+// @Success 200 {object} utils.SuccessResponse{data=PickBatchDetailResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-pick-batches/{id}/sort [put]
+func (pbc *PickBatchController) ConfirmBatchSortation(c fiber.Ctx) error {
+	log.Println("ConfirmBatchSortation called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ConfirmBatchSortation - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req ConfirmBatchSortationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ConfirmBatchSortation - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	batch, err := pbc.loadPickBatch(id, uint(userID))
+	if err != nil {
+		log.Println("ConfirmBatchSortation - Pick batch not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick batch with id " + id + " not found.",
+		})
+	}
+	if batch.Status != models.PickBatchStatusPicking {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick batch " + batch.BatchCode + " is already " + batch.Status,
+		})
+	}
+
+	var batchOrder *models.PickBatchOrder
+	for i := range batch.Orders {
+		if batch.Orders[i].CartSlot == req.CartSlot {
+			batchOrder = &batch.Orders[i]
+			break
+		}
+	}
+	if batchOrder == nil || batchOrder.Order == nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Cart slot %d not found in pick batch %s", req.CartSlot, batch.BatchCode),
+		})
+	}
+	order := batchOrder.Order
+	if batchOrder.SortedAt != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Cart slot %d is already sorted", req.CartSlot),
+		})
+	}
+	if order.ProcessingStatus != models.ProcessingStatusPickingProgress {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order " + order.TrackingNumber + " not in picking progress status",
+		})
+	}
+
+	// Every item must be confirmed on the checklist or declared as shortage
+	if unconfirmed := unconfirmedPickItems(order.OrderDetails); len(unconfirmed) > 0 {
+		log.Println("ConfirmBatchSortation - Items not confirmed:", unconfirmed)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Items not yet confirmed: " + strings.Join(unconfirmed, ", "),
+		})
+	}
+
+	now := time.Now()
+	userIDUint := uint(userID)
+	err = pbc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PickBatchOrder{}).Where("id = ?", batchOrder.ID).Update("sorted_at", now).Error; err != nil {
+			return err
+		}
+
+		// The sorted order proceeds to QC like an individually picked order
+		if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+			"picked_by":         userIDUint,
+			"picked_at":         now,
+			"processing_status": models.ProcessingStatusPickingCompleted,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.PickedOrder{OrderID: order.ID, PickedBy: userIDUint}).Error; err != nil {
+			return err
+		}
+
+		// Complete the batch once every order is sorted or has left the cart, e.g. after a shortage
+		var open int64
+		if err := tx.Model(&models.PickBatchOrder{}).
+			Joins("JOIN orders ON orders.id = pick_batch_orders.order_id").
+			Where("pick_batch_orders.pick_batch_id = ? AND pick_batch_orders.sorted_at IS NULL AND orders.processing_status = ?", batch.ID, models.ProcessingStatusPickingProgress).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open == 0 {
+			return tx.Model(&models.PickBatch{}).Where("id = ?", batch.ID).Updates(map[string]interface{}{
+				"status":       models.PickBatchStatusCompleted,
+				"completed_at": now,
+			}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		log.Println("ConfirmBatchSortation - Failed to confirm sortation:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to confirm sortation",
+		})
+	}
+
+	updated, err := pbc.loadPickBatch(batch.ID, uint(userID))
+	if err != nil {
+		log.Println("ConfirmBatchSortation - Failed to load pick batch:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load pick batch",
+		})
+	}
+
+	message := fmt.Sprintf("Cart slot %d sorted, order %s is ready for QC", req.CartSlot, order.TrackingNumber)
+	if updated.Status == models.PickBatchStatusCompleted {
+		message += ", pick batch completed"
+	}
+
+	log.Println("ConfirmBatchSortation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: PickBatchDetailResponse{
+			Batch:    updated.ToResponse(),
+			PickList: pbc.buildPickList(updated, utils.RequestLanguage(c)),
+		},
+	})
+}
+
+// CancelPickBatch takes the orders off the cart, they stay assigned to the picker to be picked individually
+// @Summary Cancel Pick Batch
+// @Description Cancel a pick cart, unsorted orders stay assigned to the picker and keep their picking progress
+// @Tags Mobile Orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Pick Batch ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.PickBatchResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/mobile-orders/my-pick-batches/{id}/cancel [put]
+func (pbc *PickBatchController) CancelPickBatch(c fiber.Ctx) error {
+	log.Println("CancelPickBatch called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CancelPickBatch - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	batch, err := pbc.loadPickBatch(id, uint(userID))
+	if err != nil {
+		log.Println("CancelPickBatch - Pick batch not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick batch with id " + id + " not found.",
+		})
+	}
+
+	result := pbc.DB.Model(&models.PickBatch{}).Where("id = ? AND status = ?", batch.ID, models.PickBatchStatusPicking).
+		Update("status", models.PickBatchStatusCanceled)
+	if result.Error != nil {
+		log.Println("CancelPickBatch - Failed to cancel pick batch:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to cancel pick batch",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick batch " + batch.BatchCode + " is already " + batch.Status,
+		})
+	}
+	batch.Status = models.PickBatchStatusCanceled
+
+	log.Println("CancelPickBatch completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Pick batch " + batch.BatchCode + " canceled, its orders can be picked individually",
+		Data:    batch.ToResponse(),
+	})
+}
+
+// PrintPickBatches renders the picking lists of several pick batches into one PDF
+// @Summary Print Pick Batches
+// @Description Render the picking lists of the given pick batches into a single PDF for printing. Each batch starts with its aggregated pick list in pick path order, followed by the picking list of every order on the cart, ordered by where its first item is picked.
+// @Tags Pick Batches
+// @Accept json
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param request body PrintPickBatchesRequest true "Pick batches to print"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/pick-batches/print [post]
+func (pbc *PickBatchController) PrintPickBatches(c fiber.Ctx) error {
+	log.Println("PrintPickBatches called")
+	// Binding request body
+	var req PrintPickBatchesRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("PrintPickBatches - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if len(req.BatchIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one pick batch is required",
+		})
+	}
+
+	var batches []models.PickBatch
+	if err := pbc.DB.Preload("Orders", func(db *gorm.DB) *gorm.DB {
+		return db.Order("cart_slot ASC")
+	}).Preload("Orders.Order.OrderDetails").Preload("Picker").Where("id IN ?", req.BatchIDs).Find(&batches).Error; err != nil {
+		log.Println("PrintPickBatches - Failed to retrieve pick batches:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pick batches",
+		})
+	}
+	batchByID := make(map[uint]*models.PickBatch, len(batches))
+	for i := range batches {
+		batchByID[batches[i].ID] = &batches[i]
+	}
+
+	printedAt := time.Now().Format("02-01-2006 15:04:05")
+	brandings := utils.NewPrintBrandingCache(c.Context(), pbc.DB, models.PrintDocumentPickingList)
+	var documents []utils.PDFDocument
+	printed := make(map[uint]bool)
+	for _, batchID := range req.BatchIDs {
+		batch, ok := batchByID[batchID]
+		if !ok {
+			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Pick batch with id %d not found.", batchID),
+			})
+		}
+		if printed[batchID] {
+			continue
+		}
+		printed[batchID] = true
+		documents = append(documents, pbc.pickBatchDocuments(batch, printedAt, utils.RequestLanguage(c), brandings)...)
+	}
+
+	buffer, err := utils.BuildTextPDF(documents)
+	if err != nil {
+		log.Println("PrintPickBatches - Failed to render PDF:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to render picking lists",
+		})
+	}
+
+	log.Println("PrintPickBatches completed successfully")
+	c.Set(fiber.HeaderContentType, utils.PDFContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"picking-lists-%s.pdf\"", time.Now().Format("20060102-150405")))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// pickBatchDocuments lays out the printed picking lists of a pick batch: the aggregated pick list of the cart
+// followed by one picking list per order, orders ordered by the pick path position of their first item and branded
+// with the picking list template of their store
+func (pbc *PickBatchController) pickBatchDocuments(batch *models.PickBatch, printedAt, language string, brandings *utils.PrintBrandingCache) []utils.PDFDocument {
+	var picker string
+	if batch.Picker != nil {
+		picker = batch.Picker.FullName
+	}
+	pickList := pbc.buildPickList(batch, language)
+
+	totalQuantity := 0
+	summary := []string{
+		"PICK BATCH " + batch.BatchCode,
+		fmt.Sprintf("Picker: %s   Orders: %d   Status: %s   Printed: %s", picker, len(batch.Orders), batch.Status, printedAt),
+		"",
+		fmt.Sprintf("%-12s %-24s %5s  %s", "LOCATION", "SKU", "QTY", "PRODUCT"),
+	}
+	for _, item := range pickList {
+		totalQuantity += item.Remaining
+		summary = append(summary, fmt.Sprintf("%-12s %-24s %5d  %s", pickLocationLabel(item.Location), item.SKU, item.Remaining, pickProductLabel(item.DisplayName, item.DisplayVariant)))
+		for _, order := range item.Orders {
+			if remaining := order.Quantity - order.PickedQuantity; remaining > 0 {
+				summary = append(summary, fmt.Sprintf("%-12s   slot %-3d %-26s %3d", "", order.CartSlot, order.TrackingNumber, remaining))
+			}
+		}
+	}
+	summary = append(summary, "", fmt.Sprintf("Total to pick: %d", totalQuantity))
+	documents := []utils.PDFDocument{{Lines: summary}}
+
+	// Split the pick path back into one picking list per order, keeping the walking order
+	type orderList struct {
+		batchOrder models.PickBatchOrder
+		position   int
+		lines      []string
+	}
+	lists := make(map[uint]*orderList)
+	var sequence []*orderList
+	for position, item := range pickList {
+		for _, order := range item.Orders {
+			list, ok := lists[order.OrderID]
+			if !ok {
+				list = &orderList{position: position}
+				for _, batchOrder := range batch.Orders {
+					if batchOrder.OrderID == order.OrderID {
+						list.batchOrder = batchOrder
+						break
+					}
+				}
+				lists[order.OrderID] = list
+				sequence = append(sequence, list)
+			}
+			list.lines = append(list.lines, fmt.Sprintf("%-12s %-24s %5d  %s", pickLocationLabel(item.Location), item.SKU, order.Quantity-order.PickedQuantity, pickProductLabel(item.DisplayName, item.DisplayVariant)))
+		}
+	}
+	sort.SliceStable(sequence, func(i, j int) bool { return sequence[i].position < sequence[j].position })
+
+	for _, list := range sequence {
+		order := list.batchOrder.Order
+		if order == nil {
+			continue
+		}
+		lines := []string{
+			"PICKING LIST " + order.TrackingNumber,
+			fmt.Sprintf("Batch: %s   Cart slot: %d   Picker: %s", batch.BatchCode, list.batchOrder.CartSlot, picker),
+			fmt.Sprintf("Order: %s   Channel: %s   Store: %s", order.OrderGineeID, order.Channel, order.Store),
+			fmt.Sprintf("Courier: %s   Send before: %s", order.Courier, order.SentBefore.Format("02-01-2006 15:04")),
+			"",
+			fmt.Sprintf("%-12s %-24s %5s  %s", "LOCATION", "SKU", "QTY", "PRODUCT"),
+		}
+		lines = append(lines, list.lines...)
+		documents = append(documents, brandings.ForOrder(order).Apply(utils.PDFDocument{Lines: lines}))
+	}
+
+	return documents
+}
+
+// pickLocationLabel prints products without a rack location as unknown
+func pickLocationLabel(location string) string {
+	if location == "" {
+		return "-"
+	}
+	return location
+}
+
+// pickProductLabel joins the product name and its variant
+func pickProductLabel(name, variant string) string {
+	if variant == "" {
+		return name
+	}
+	return name + " (" + variant + ")"
+}
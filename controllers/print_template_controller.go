@@ -0,0 +1,423 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type PrintTemplateController struct {
+	DB *gorm.DB
+}
+
+func NewPrintTemplateController(db *gorm.DB) *PrintTemplateController {
+	return &PrintTemplateController{DB: db}
+}
+
+// Unique request structs
+type SavePrintTemplateRequest struct {
+	HeaderText string `json:"headerText" example:"Official store of Livo\nFree returns within 7 days"` // lines printed below the logo
+	FooterText string `json:"footerText" example:"Follow us @livo.id"`                                 // lines printed at the bottom, replacing the store's invoice footer
+	ShowLogo   *bool  `json:"showLogo" example:"true"`                                                 // defaults to true
+}
+
+// Unique response structs
+// StorePrintTemplatesResponse lists the print templates of a store per document type, null for document types printed with the generic layout
+type StorePrintTemplatesResponse struct {
+	StoreID   uint                                     `json:"storeId"`
+	HasLogo   bool                                     `json:"hasLogo"`
+	Templates map[string]*models.PrintTemplateResponse `json:"templates"`
+}
+
+// findStore loads the store of the id parameter
+func (ptc *PrintTemplateController) findStore(c fiber.Ctx) (*models.Store, error) {
+	var store models.Store
+	if err := ptc.DB.Where("id = ?", c.Params("id")).First(&store).Error; err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+// GetPrintTemplates lists the print templates of a store
+// @Summary Get Print Templates
+// @Description List the label, invoice and picking list templates of a store, document types without a template are printed with the generic layout
+// @Tags Print Templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Store ID"
+// @Success 200 {object} utils.SuccessResponse{data=StorePrintTemplatesResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stores/{id}/print-templates [get]
+func (ptc *PrintTemplateController) GetPrintTemplates(c fiber.Ctx) error {
+	log.Println("GetPrintTemplates called")
+	store, err := ptc.findStore(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store with id " + c.Params("id") + " not found.",
+		})
+	}
+
+	var templates []models.PrintTemplate
+	if err := ptc.DB.Preload("UpdatedUser").Where("store_id = ?", store.ID).Find(&templates).Error; err != nil {
+		log.Println("GetPrintTemplates - Failed to retrieve print templates:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve print templates",
+		})
+	}
+
+	// Format response, every document type is listed
+	response := StorePrintTemplatesResponse{
+		StoreID:   store.ID,
+		HasLogo:   store.LogoKey != "",
+		Templates: make(map[string]*models.PrintTemplateResponse),
+	}
+	for _, documentType := range models.PrintDocumentTypes() {
+		response.Templates[documentType] = nil
+	}
+	for i := range templates {
+		response.Templates[templates[i].DocumentType] = templates[i].ToResponse()
+	}
+
+	log.Println("GetPrintTemplates completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Print templates retrieved successfully",
+		Data:    response,
+	})
+}
+
+// SavePrintTemplate creates or replaces a print template of a store
+// @Summary Save Print Template
+// @Description Create or replace the template of a store's label, invoice or picking list. The header text is printed at the top below the logo and the footer text at the bottom, replacing the store's invoice footer on invoices. Both are limited to 10 lines
+// @Tags Print Templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Store ID"
+// @Param documentType path string true "Document type (label, invoice or picking_list)"
+// @Param request body SavePrintTemplateRequest true "Template text blocks"
+// @Success 200 {object} utils.SuccessResponse{data=models.PrintTemplateResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stores/{id}/print-templates/{documentType} [put]
+func (ptc *PrintTemplateController) SavePrintTemplate(c fiber.Ctx) error {
+	log.Println("SavePrintTemplate called")
+	store, err := ptc.findStore(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store with id " + c.Params("id") + " not found.",
+		})
+	}
+
+	documentType := c.Params("documentType")
+	if !models.IsValidPrintDocumentType(documentType) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid document type " + documentType + ", use " + strings.Join(models.PrintDocumentTypes(), ", "),
+		})
+	}
+
+	// Binding request body
+	var req SavePrintTemplateRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SavePrintTemplate - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if len(utils.PrintTemplateLines(req.HeaderText)) > utils.MaxPrintTemplateLines || len(utils.PrintTemplateLines(req.FooterText)) > utils.MaxPrintTemplateLines {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Header and footer text must not exceed %d lines", utils.MaxPrintTemplateLines),
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	updatedBy := uint(userID)
+
+	var template models.PrintTemplate
+	if err := ptc.DB.Where("store_id = ? AND document_type = ?", store.ID, documentType).First(&template).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Println("SavePrintTemplate - Failed to retrieve print template:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve print template",
+		})
+	}
+	template.StoreID = store.ID
+	template.DocumentType = documentType
+	template.HeaderText = strings.Join(utils.PrintTemplateLines(req.HeaderText), "\n")
+	template.FooterText = strings.Join(utils.PrintTemplateLines(req.FooterText), "\n")
+	template.ShowLogo = req.ShowLogo == nil || *req.ShowLogo
+	template.UpdatedBy = &updatedBy
+
+	if err := ptc.DB.Save(&template).Error; err != nil {
+		log.Println("SavePrintTemplate - Failed to save print template:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to save print template",
+		})
+	}
+
+	// Reload print template with updating user for response
+	if err := ptc.DB.Preload("UpdatedUser").First(&template, template.ID).Error; err != nil {
+		log.Println("SavePrintTemplate - Failed to load print template:", err)
+	}
+
+	log.Println("SavePrintTemplate completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Print template " + documentType + " of store " + store.StoreCode + " saved successfully",
+		Data:    template.ToResponse(),
+	})
+}
+
+// DeletePrintTemplate removes a print template of a store
+// @Summary Delete Print Template
+// @Description Remove the template of a store's label, invoice or picking list, the document is printed with the generic layout again
+// @Tags Print Templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Store ID"
+// @Param documentType path string true "Document type (label, invoice or picking_list)"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stores/{id}/print-templates/{documentType} [delete]
+func (ptc *PrintTemplateController) DeletePrintTemplate(c fiber.Ctx) error {
+	log.Println("DeletePrintTemplate called")
+	store, err := ptc.findStore(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store with id " + c.Params("id") + " not found.",
+		})
+	}
+
+	documentType := c.Params("documentType")
+	result := ptc.DB.Where("store_id = ? AND document_type = ?", store.ID, documentType).Delete(&models.PrintTemplate{})
+	if result.Error != nil {
+		log.Println("DeletePrintTemplate - Failed to delete print template:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete print template",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store " + store.StoreCode + " has no " + documentType + " print template.",
+		})
+	}
+
+	log.Println("DeletePrintTemplate completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Print template " + documentType + " of store " + store.StoreCode + " deleted successfully",
+	})
+}
+
+// UploadStoreLogo uploads the logo printed on a store's documents
+// @Summary Upload Store Logo
+// @Description Upload the logo printed at the top of a store's labels, invoices and picking lists, replacing the current one. JPEG and PNG images are accepted, transparent areas are printed white and logos larger than 600 pixels are scaled down
+// @Tags Print Templates
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Store ID"
+// @Param image formData file true "Logo image (JPEG or PNG)"
+// @Success 200 {object} utils.SuccessResponse{data=models.StoreResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stores/{id}/logo [put]
+func (ptc *PrintTemplateController) UploadStoreLogo(c fiber.Ctx) error {
+	log.Println("UploadStoreLogo called")
+	store, err := ptc.findStore(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store with id " + c.Params("id") + " not found.",
+		})
+	}
+
+	// Get uploaded logo image
+	file, err := c.FormFile("image")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Logo image file is required",
+		})
+	}
+
+	// Validate image content, strip metadata and scale down
+	content, err := utils.SanitizeLogoImage(file)
+	if err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+		log.Println("UploadStoreLogo - Failed to process logo:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to process logo image",
+		})
+	}
+
+	// A new key per upload, the previous logo is removed once the store points to the new one
+	previousKey := store.LogoKey
+	logoKey := fmt.Sprintf("stores/%d/logo-%d.jpg", store.ID, time.Now().UnixNano())
+	if err := utils.GetStorage().Put(c.Context(), logoKey, content, "image/jpeg"); err != nil {
+		log.Println("UploadStoreLogo - Failed to store logo:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to store logo image",
+		})
+	}
+	if err := ptc.DB.Model(store).Update("logo_key", logoKey).Error; err != nil {
+		log.Println("UploadStoreLogo - Failed to update store:", err)
+		utils.GetStorage().Delete(c.Context(), logoKey)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update store logo",
+		})
+	}
+	if previousKey != "" {
+		if err := utils.GetStorage().Delete(c.Context(), previousKey); err != nil {
+			log.Println("UploadStoreLogo - Failed to delete previous logo:", err)
+		}
+	}
+
+	log.Println("UploadStoreLogo completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Logo of store " + store.StoreCode + " uploaded successfully",
+		Data:    store.ToResponse(),
+	})
+}
+
+// GetStoreLogo downloads the logo printed on a store's documents
+// @Summary Get Store Logo
+// @Description Download the logo printed on a store's documents as a JPEG image
+// @Tags Print Templates
+// @Produce jpeg
+// @Security BearerAuth
+// @Param id path int true "Store ID"
+// @Success 200 {file} file
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stores/{id}/logo [get]
+func (ptc *PrintTemplateController) GetStoreLogo(c fiber.Ctx) error {
+	log.Println("GetStoreLogo called")
+	store, err := ptc.findStore(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store with id " + c.Params("id") + " not found.",
+		})
+	}
+	if store.LogoKey == "" {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store " + store.StoreCode + " has no logo.",
+		})
+	}
+
+	content, err := utils.GetStorage().Get(c.Context(), store.LogoKey)
+	if err != nil {
+		log.Println("GetStoreLogo - Failed to read logo:", err)
+		if errors.Is(err, utils.ErrStorageObjectNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Logo file of store " + store.StoreCode + " not found.",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to read logo image",
+		})
+	}
+
+	log.Println("GetStoreLogo completed successfully")
+	c.Set(fiber.HeaderContentType, "image/jpeg")
+	return c.Status(fiber.StatusOK).Send(content)
+}
+
+// DeleteStoreLogo removes the logo printed on a store's documents
+// @Summary Delete Store Logo
+// @Description Remove the logo of a store, its documents are printed without a logo
+// @Tags Print Templates
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Store ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.StoreResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stores/{id}/logo [delete]
+func (ptc *PrintTemplateController) DeleteStoreLogo(c fiber.Ctx) error {
+	log.Println("DeleteStoreLogo called")
+	store, err := ptc.findStore(c)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store with id " + c.Params("id") + " not found.",
+		})
+	}
+	if store.LogoKey == "" {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Store " + store.StoreCode + " has no logo.",
+		})
+	}
+
+	logoKey := store.LogoKey
+	if err := ptc.DB.Model(store).Update("logo_key", "").Error; err != nil {
+		log.Println("DeleteStoreLogo - Failed to update store:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete store logo",
+		})
+	}
+	if err := utils.GetStorage().Delete(c.Context(), logoKey); err != nil {
+		log.Println("DeleteStoreLogo - Failed to delete logo file:", err)
+	}
+
+	log.Println("DeleteStoreLogo completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Logo of store " + store.StoreCode + " deleted successfully",
+		Data:    store.ToResponse(),
+	})
+}
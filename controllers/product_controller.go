@@ -1,7 +1,12 @@
 package controllers
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
@@ -22,19 +27,93 @@ func NewProductController(db *gorm.DB) *ProductController {
 
 // Request structs
 type CreateProductRequest struct {
-	SKU      string `json:"sku" validate:"required,min=3,max=50"`
-	Name     string `json:"name" validate:"required,min=3,max=100"`
-	Image    string `json:"image" validate:"omitempty"`
-	Variant  string `json:"variant" validate:"omitempty,min=1,max=100"`
-	Location string `json:"location" validate:"omitempty,min=1,max=100"`
+	SKU            string `json:"sku" validate:"required,min=3,max=50"`
+	Name           string `json:"name" validate:"required,min=3,max=100"`
+	Image          string `json:"image" validate:"omitempty"`
+	Variant        string `json:"variant" validate:"omitempty,min=1,max=100"`
+	Location       string `json:"location" validate:"omitempty,min=1,max=100"`
+	Barcode        string `json:"barcode" validate:"omitempty,max=100"`
+	SerialRequired bool   `json:"serialRequired"` // capture the serial number (IMEI or SN) of every unit at QC
+	// Packed unit weight and dimensions used for courier bookings
+	WeightGrams *int     `json:"weightGrams" validate:"omitempty,gt=0" example:"250"`
+	LengthCm    *float64 `json:"lengthCm" validate:"omitempty,gt=0" example:"20"`
+	WidthCm     *float64 `json:"widthCm" validate:"omitempty,gt=0" example:"15"`
+	HeightCm    *float64 `json:"heightCm" validate:"omitempty,gt=0" example:"5"`
+	// Skill pickers need to be assigned orders with the product, e.g. fragile goods
+	RequiredSkillID *uint `json:"requiredSkillId" example:"2"`
 }
 
 type UpdateProductRequest struct {
-	SKU      string `json:"sku" validate:"required,min=3,max=50"`
-	Name     string `json:"name" validate:"required,min=3,max=100"`
-	Image    string `json:"image" validate:"omitempty"`
-	Variant  string `json:"variant" validate:"omitempty,min=1,max=100"`
-	Location string `json:"location" validate:"omitempty,min=1,max=100"`
+	SKU            string `json:"sku" validate:"required,min=3,max=50"`
+	Name           string `json:"name" validate:"required,min=3,max=100"`
+	Image          string `json:"image" validate:"omitempty"`
+	Variant        string `json:"variant" validate:"omitempty,min=1,max=100"`
+	Location       string `json:"location" validate:"omitempty,min=1,max=100"`
+	Barcode        string `json:"barcode" validate:"omitempty,max=100"`
+	SerialRequired bool   `json:"serialRequired"` // capture the serial number (IMEI or SN) of every unit at QC
+	// Packed unit weight and dimensions used for courier bookings
+	WeightGrams *int     `json:"weightGrams" validate:"omitempty,gt=0" example:"250"`
+	LengthCm    *float64 `json:"lengthCm" validate:"omitempty,gt=0" example:"20"`
+	WidthCm     *float64 `json:"widthCm" validate:"omitempty,gt=0" example:"15"`
+	HeightCm    *float64 `json:"heightCm" validate:"omitempty,gt=0" example:"5"`
+	// Skill pickers need to be assigned orders with the product, e.g. fragile goods
+	RequiredSkillID *uint `json:"requiredSkillId" example:"2"`
+}
+
+// maxBulkProductUpsert caps the rows of one bulk product upsert request
+const maxBulkProductUpsert = 1000
+
+// BulkUpsertProductRequest is a list of products keyed by SKU, a bare JSON array of rows is accepted as well
+type BulkUpsertProductRequest struct {
+	Products []BulkUpsertProductRow `json:"products"`
+}
+
+// BulkUpsertProductRow creates the product with the SKU or updates it, fields left out keep their current value
+type BulkUpsertProductRow struct {
+	SKU      string  `json:"sku" example:"SKU-001"`
+	Name     *string `json:"name" example:"Phone Case"` // required when the product is created
+	Variant  *string `json:"variant" example:"Black"`
+	Location *string `json:"location" example:"A-01-03"` // rack location
+	Barcode  *string `json:"barcode" example:"8991234567890"`
+	// Packed unit weight and dimensions used for courier bookings
+	WeightGrams *int     `json:"weightGrams" example:"250"`
+	LengthCm    *float64 `json:"lengthCm" example:"20"`
+	WidthCm     *float64 `json:"widthCm" example:"15"`
+	HeightCm    *float64 `json:"heightCm" example:"5"`
+}
+
+type BulkUpsertProductResponse struct {
+	Summary BulkUpsertProductSummary  `json:"summary"`
+	Results []BulkUpsertProductResult `json:"results"`
+}
+
+type BulkUpsertProductSummary struct {
+	Total     uint `json:"total"`
+	Created   uint `json:"created"`
+	Updated   uint `json:"updated"`
+	Unchanged uint `json:"unchanged"`
+	Failed    uint `json:"failed"`
+}
+
+type BulkUpsertProductResult struct {
+	Index     int    `json:"index"`
+	SKU       string `json:"sku"`
+	ProductID uint   `json:"productId,omitempty"`
+	Result    string `json:"result"` // created, updated, unchanged or failed
+	Reason    string `json:"reason,omitempty"`
+}
+
+// validateProductMeasurements checks that the weight and dimensions given for a product are positive
+func validateProductMeasurements(weightGrams *int, dimensions ...*float64) error {
+	if weightGrams != nil && *weightGrams <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Weight must be greater than zero")
+	}
+	for _, dimension := range dimensions {
+		if dimension != nil && *dimension <= 0 {
+			return fiber.NewError(fiber.StatusBadRequest, "Dimensions must be greater than zero")
+		}
+	}
+	return nil
 }
 
 // GetProducts retrieves a list of products with pagination and search
@@ -180,6 +259,22 @@ func (pc *ProductController) CreateProduct(c fiber.Ctx) error {
 	// Convert sku to uppercase and trim spaces
 	req.SKU = strings.ToUpper(strings.TrimSpace(req.SKU))
 
+	if err := validateProductMeasurements(req.WeightGrams, req.LengthCm, req.WidthCm, req.HeightCm); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.(*fiber.Error).Message,
+		})
+	}
+	if req.RequiredSkillID != nil {
+		var skill models.Skill
+		if err := pc.DB.First(&skill, *req.RequiredSkillID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Skill with id " + strconv.FormatUint(uint64(*req.RequiredSkillID), 10) + " not found.",
+			})
+		}
+	}
+
 	// Check for existing product with same code
 	var existingProduct models.Product
 	if err := pc.DB.Where("sku = ?", req.SKU).First(&existingProduct).Error; err == nil {
@@ -191,11 +286,18 @@ func (pc *ProductController) CreateProduct(c fiber.Ctx) error {
 
 	// Create new product
 	newProduct := models.Product{
-		SKU:      req.SKU,
-		Name:     req.Name,
-		Image:    req.Image,
-		Variant:  req.Variant,
-		Location: req.Location,
+		SKU:             req.SKU,
+		Name:            req.Name,
+		Image:           req.Image,
+		Variant:         req.Variant,
+		Location:        req.Location,
+		Barcode:         strings.TrimSpace(req.Barcode),
+		SerialRequired:  req.SerialRequired,
+		WeightGrams:     req.WeightGrams,
+		LengthCm:        req.LengthCm,
+		WidthCm:         req.WidthCm,
+		HeightCm:        req.HeightCm,
+		RequiredSkillID: req.RequiredSkillID,
 	}
 
 	if err := pc.DB.Create(&newProduct).Error; err != nil {
@@ -254,6 +356,22 @@ func (pc *ProductController) UpdateProduct(c fiber.Ctx) error {
 	// Convert SKU to uppercase and trim spaces
 	req.SKU = strings.ToUpper(strings.TrimSpace(req.SKU))
 
+	if err := validateProductMeasurements(req.WeightGrams, req.LengthCm, req.WidthCm, req.HeightCm); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.(*fiber.Error).Message,
+		})
+	}
+	if req.RequiredSkillID != nil {
+		var skill models.Skill
+		if err := pc.DB.First(&skill, *req.RequiredSkillID).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Skill with id " + strconv.FormatUint(uint64(*req.RequiredSkillID), 10) + " not found.",
+			})
+		}
+	}
+
 	// Check for existing product with same SKU (excluding current product)
 	var existingProduct models.Product
 	if err := pc.DB.Where("sku = ? AND id != ?", req.SKU, id).First(&existingProduct).Error; err == nil {
@@ -269,6 +387,13 @@ func (pc *ProductController) UpdateProduct(c fiber.Ctx) error {
 	product.Image = req.Image
 	product.Variant = req.Variant
 	product.Location = req.Location
+	product.Barcode = strings.TrimSpace(req.Barcode)
+	product.SerialRequired = req.SerialRequired
+	product.WeightGrams = req.WeightGrams
+	product.LengthCm = req.LengthCm
+	product.WidthCm = req.WidthCm
+	product.HeightCm = req.HeightCm
+	product.RequiredSkillID = req.RequiredSkillID
 
 	if err := pc.DB.Save(&product).Error; err != nil {
 		log.Println("UpdateProduct - Failed to update product:", err)
@@ -328,3 +453,352 @@ func (pc *ProductController) DeleteProduct(c fiber.Ctx) error {
 		Message: "Product deleted successfully",
 	})
 }
+
+// BulkUpsertProducts creates or updates products keyed by SKU
+// @Summary Bulk Upsert Products
+// @Description Create or update products keyed by SKU with name, variant, rack location, weight, dimensions and barcode. Accepts a JSON array of rows, a JSON object with products, a CSV file upload (file) or a raw text/csv body with a header row, and returns a per-row result summary. Fields left out or empty CSV cells keep the current value. Saved products are emitted in the products change feed.
+// @Tags Products
+// @Accept json
+// @Accept multipart/form-data
+// @Accept text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkUpsertProductRequest false "Products keyed by SKU"
+// @Param file formData file false "CSV file with sku, name, variant, location, barcode, weightGrams, lengthCm, widthCm and heightCm columns"
+// @Success 200 {object} utils.SuccessResponse{data=BulkUpsertProductResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/products/bulk-upsert [post]
+func (pc *ProductController) BulkUpsertProducts(c fiber.Ctx) error {
+	log.Println("BulkUpsertProducts called")
+	// Parse rows from CSV upload, raw CSV body or JSON body
+	var rows []BulkUpsertProductRow
+	contentType := strings.ToLower(c.Get(fiber.HeaderContentType))
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEMultipartForm):
+		file, err := c.FormFile("file")
+		if err != nil {
+			log.Println("BulkUpsertProducts - CSV file required:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "CSV file is required",
+			})
+		}
+		src, err := file.Open()
+		if err != nil {
+			log.Println("BulkUpsertProducts - Failed to open CSV file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to open CSV file",
+			})
+		}
+		defer src.Close()
+
+		if rows, err = parseBulkUpsertProductCSV(src); err != nil {
+			log.Println("BulkUpsertProducts - Invalid CSV file:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid CSV file: " + err.Error(),
+			})
+		}
+
+	case strings.HasPrefix(contentType, "text/csv"):
+		var err error
+		if rows, err = parseBulkUpsertProductCSV(bytes.NewReader(c.Body())); err != nil {
+			log.Println("BulkUpsertProducts - Invalid CSV body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid CSV body: " + err.Error(),
+			})
+		}
+
+	default:
+		// A bare array of rows or an object with the rows in products
+		body := bytes.TrimSpace(c.Body())
+		var err error
+		if bytes.HasPrefix(body, []byte("[")) {
+			err = json.Unmarshal(body, &rows)
+		} else {
+			var req BulkUpsertProductRequest
+			err = json.Unmarshal(body, &req)
+			rows = req.Products
+		}
+		if err != nil {
+			log.Println("BulkUpsertProducts - Invalid request body:", err)
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid request body",
+			})
+		}
+	}
+
+	if len(rows) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No products to upsert",
+		})
+	}
+	if len(rows) > maxBulkProductUpsert {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("At most %d products can be upserted at once", maxBulkProductUpsert),
+		})
+	}
+
+	response := pc.runBulkUpsertProducts(rows)
+
+	log.Printf("BulkUpsertProducts completed (created=%d, updated=%d, unchanged=%d, failed=%d)\n", response.Summary.Created, response.Summary.Updated, response.Summary.Unchanged, response.Summary.Failed)
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Bulk product upsert completed",
+		Data:    response,
+	})
+}
+
+// runBulkUpsertProducts saves the rows one by one so a failed row does not hold back the others.
+// The products table is part of the change feed, every created or updated product emits a change event.
+func (pc *ProductController) runBulkUpsertProducts(rows []BulkUpsertProductRow) BulkUpsertProductResponse {
+	response := BulkUpsertProductResponse{
+		Summary: BulkUpsertProductSummary{Total: uint(len(rows))},
+		Results: make([]BulkUpsertProductResult, 0, len(rows)),
+	}
+
+	seen := make(map[string]int)
+	for i, row := range rows {
+		// Convert sku to uppercase and trim spaces
+		result := BulkUpsertProductResult{Index: i, SKU: strings.ToUpper(strings.TrimSpace(row.SKU))}
+
+		first, duplicate := seen[result.SKU]
+		reason := validateBulkUpsertProductRow(result.SKU, row)
+		if reason == "" && !duplicate {
+			seen[result.SKU] = i
+		}
+		if reason != "" {
+			result.Result = "failed"
+			result.Reason = reason
+		} else if duplicate {
+			result.Result = "failed"
+			result.Reason = fmt.Sprintf("SKU is already upserted by row %d", first)
+		} else {
+			productID, outcome, reason, err := pc.upsertProduct(result.SKU, row)
+			if err != nil {
+				log.Println("runBulkUpsertProducts - Failed to save product:", result.SKU, err)
+				result.Result = "failed"
+				result.Reason = "Failed to save product"
+			} else {
+				result.ProductID = productID
+				result.Result = outcome
+				result.Reason = reason
+			}
+		}
+
+		switch result.Result {
+		case "created":
+			response.Summary.Created++
+		case "updated":
+			response.Summary.Updated++
+		case "unchanged":
+			response.Summary.Unchanged++
+		default:
+			response.Summary.Failed++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	return response
+}
+
+// validateBulkUpsertProductRow applies the create and update product rules to the fields given in a row,
+// returns the reason the row is rejected or an empty string
+func validateBulkUpsertProductRow(sku string, row BulkUpsertProductRow) string {
+	if length := len([]rune(sku)); length < 3 || length > 50 {
+		return "SKU must be between 3 and 50 characters"
+	}
+	if row.Name != nil {
+		if length := len([]rune(strings.TrimSpace(*row.Name))); length < 3 || length > 100 {
+			return "Name must be between 3 and 100 characters"
+		}
+	}
+	for _, field := range []struct {
+		label string
+		value *string
+	}{{"Variant", row.Variant}, {"Location", row.Location}, {"Barcode", row.Barcode}} {
+		if field.value != nil && len([]rune(strings.TrimSpace(*field.value))) > 100 {
+			return field.label + " must be at most 100 characters"
+		}
+	}
+	if err := validateProductMeasurements(row.WeightGrams, row.LengthCm, row.WidthCm, row.HeightCm); err != nil {
+		return err.(*fiber.Error).Message
+	}
+	return ""
+}
+
+// upsertProduct creates the product with the SKU or updates the fields given in the row.
+// Returns the product id, the row result (created, updated, unchanged or failed) and the reason when it failed.
+func (pc *ProductController) upsertProduct(sku string, row BulkUpsertProductRow) (uint, string, string, error) {
+	trimmed := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		text := strings.TrimSpace(*value)
+		return &text
+	}
+	name, variant, location, barcode := trimmed(row.Name), trimmed(row.Variant), trimmed(row.Location), trimmed(row.Barcode)
+
+	var product models.Product
+	err := pc.DB.Where("sku = ?", sku).First(&product).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if name == nil {
+			return 0, "failed", "Name is required for a new product", nil
+		}
+		product = models.Product{
+			SKU:         sku,
+			Name:        *name,
+			WeightGrams: row.WeightGrams,
+			LengthCm:    row.LengthCm,
+			WidthCm:     row.WidthCm,
+			HeightCm:    row.HeightCm,
+		}
+		if variant != nil {
+			product.Variant = *variant
+		}
+		if location != nil {
+			product.Location = *location
+		}
+		if barcode != nil {
+			product.Barcode = *barcode
+		}
+		if err := pc.DB.Create(&product).Error; err != nil {
+			return 0, "", "", err
+		}
+		return product.ID, "created", "", nil
+	}
+	if err != nil {
+		return 0, "", "", err
+	}
+
+	// Only the fields that differ are written, an unchanged row leaves the product and the change feed untouched
+	updates := make(map[string]interface{})
+	for column, value := range map[string]struct {
+		given   *string
+		current string
+	}{
+		"name":     {name, product.Name},
+		"variant":  {variant, product.Variant},
+		"location": {location, product.Location},
+		"barcode":  {barcode, product.Barcode},
+	} {
+		if value.given != nil && *value.given != value.current {
+			updates[column] = *value.given
+		}
+	}
+	if row.WeightGrams != nil && (product.WeightGrams == nil || *product.WeightGrams != *row.WeightGrams) {
+		updates["weight_grams"] = *row.WeightGrams
+	}
+	for column, value := range map[string]struct{ given, current *float64 }{
+		"length_cm": {row.LengthCm, product.LengthCm},
+		"width_cm":  {row.WidthCm, product.WidthCm},
+		"height_cm": {row.HeightCm, product.HeightCm},
+	} {
+		if value.given != nil && (value.current == nil || *value.current != *value.given) {
+			updates[column] = *value.given
+		}
+	}
+	if len(updates) == 0 {
+		return product.ID, "unchanged", "", nil
+	}
+
+	if err := pc.DB.Model(&product).Updates(updates).Error; err != nil {
+		return 0, "", "", err
+	}
+	return product.ID, "updated", "", nil
+}
+
+// parseBulkUpsertProductCSV parses CSV rows of products keyed by SKU, the header row names the columns.
+// Columns other than sku are optional, empty cells keep the current value of the product.
+func parseBulkUpsertProductCSV(reader io.Reader) ([]BulkUpsertProductRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	normalize := func(value string) string {
+		return strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(value)))
+	}
+
+	// Column index per field, -1 when the column is missing
+	columns := map[string]int{"sku": -1, "name": -1, "variant": -1, "location": -1, "barcode": -1, "weight": -1, "length": -1, "width": -1, "height": -1}
+	for i, column := range records[0] {
+		switch normalize(column) {
+		case "sku":
+			columns["sku"] = i
+		case "name", "productname":
+			columns["name"] = i
+		case "variant":
+			columns["variant"] = i
+		case "location", "racklocation", "rack":
+			columns["location"] = i
+		case "barcode":
+			columns["barcode"] = i
+		case "weight", "weightgrams":
+			columns["weight"] = i
+		case "length", "lengthcm":
+			columns["length"] = i
+		case "width", "widthcm":
+			columns["width"] = i
+		case "height", "heightcm":
+			columns["height"] = i
+		}
+	}
+	if columns["sku"] < 0 {
+		return nil, errors.New("header row with a sku column is required")
+	}
+
+	rows := make([]BulkUpsertProductRow, 0, len(records)-1)
+	for line, record := range records[1:] {
+		cell := func(field string) *string {
+			if i := columns[field]; i >= 0 && i < len(record) && strings.TrimSpace(record[i]) != "" {
+				value := strings.TrimSpace(record[i])
+				return &value
+			}
+			return nil
+		}
+
+		row := BulkUpsertProductRow{
+			Name:     cell("name"),
+			Variant:  cell("variant"),
+			Location: cell("location"),
+			Barcode:  cell("barcode"),
+		}
+		if sku := cell("sku"); sku != nil {
+			row.SKU = *sku
+		}
+		if weight := cell("weight"); weight != nil {
+			grams, err := strconv.Atoi(*weight)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid weight %q", line+2, *weight)
+			}
+			row.WeightGrams = &grams
+		}
+		for field, target := range map[string]**float64{"length": &row.LengthCm, "width": &row.WidthCm, "height": &row.HeightCm} {
+			if value := cell(field); value != nil {
+				dimension, err := strconv.ParseFloat(*value, 64)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s %q", line+2, field, *value)
+				}
+				*target = &dimension
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBulkUpsertProductCSV(t *testing.T) {
+	text := func(s string) *string { return &s }
+	grams := func(g int) *int { return &g }
+	cm := func(c float64) *float64 { return &c }
+
+	tests := []struct {
+		name    string
+		csv     string
+		want    []BulkUpsertProductRow
+		wantErr string // substring of the expected error, empty when parsing succeeds
+	}{
+		{
+			name: "all columns",
+			csv:  "sku,name,variant,location,barcode,weight,length,width,height\nCASE-BLK,Phone Case,Black,A-01-03,8991234567890,250,20,15,5.5\n",
+			want: []BulkUpsertProductRow{{
+				SKU: "CASE-BLK", Name: text("Phone Case"), Variant: text("Black"), Location: text("A-01-03"), Barcode: text("8991234567890"),
+				WeightGrams: grams(250), LengthCm: cm(20), WidthCm: cm(15), HeightCm: cm(5.5),
+			}},
+		},
+		{
+			name: "header aliases in any case and order",
+			csv:  "Product Name,Rack Location,SKU,Weight_Grams,length-cm\nPhone Case,A-01-03,CASE-BLK,250,20\n",
+			want: []BulkUpsertProductRow{{SKU: "CASE-BLK", Name: text("Phone Case"), Location: text("A-01-03"), WeightGrams: grams(250), LengthCm: cm(20)}},
+		},
+		{
+			name: "empty cells and short rows keep the current values",
+			csv:  "sku,name,location,weight\nCASE-BLK, ,,\nCABLE,USB Cable\n",
+			want: []BulkUpsertProductRow{{SKU: "CASE-BLK"}, {SKU: "CABLE", Name: text("USB Cable")}},
+		},
+		{
+			name: "cells are trimmed and unknown columns ignored",
+			csv:  "sku,stock,name\n  CASE-BLK  ,12,  Phone Case  \n",
+			want: []BulkUpsertProductRow{{SKU: "CASE-BLK", Name: text("Phone Case")}},
+		},
+		{
+			name: "rows without sku are kept for the upsert to reject",
+			csv:  "sku,name\n,Phone Case\n",
+			want: []BulkUpsertProductRow{{Name: text("Phone Case")}},
+		},
+		{name: "header only", csv: "sku,name\n", want: []BulkUpsertProductRow{}},
+		{name: "empty file", csv: "", want: nil},
+		{name: "missing sku column", csv: "name,location\nPhone Case,A-01-03\n", wantErr: "sku column is required"},
+		{name: "invalid weight names the line", csv: "sku,weight\nCASE-BLK,250\nCABLE,0.25kg\n", wantErr: `line 3: invalid weight "0.25kg"`},
+		{name: "invalid dimension", csv: "sku,height\nCASE-BLK,five\n", wantErr: `line 2: invalid height "five"`},
+		{name: "malformed csv", csv: "sku,name\n\"CASE-BLK,Phone Case\n", wantErr: "quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := parseBulkUpsertProductCSV(strings.NewReader(tt.csv))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("rows = %+v, want %+v", rows, tt.want)
+			}
+		})
+	}
+}
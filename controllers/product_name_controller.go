@@ -0,0 +1,668 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ProductNameController struct {
+	DB *gorm.DB
+}
+
+func NewProductNameController(db *gorm.DB) *ProductNameController {
+	return &ProductNameController{DB: db}
+}
+
+// Request structs
+type CreateProductDisplayNameRequest struct {
+	SKU      string `json:"sku" validate:"required" example:"SKU-A"`
+	Language string `json:"language" validate:"required" example:"id"`
+	Name     string `json:"name" validate:"required" example:"Kaos Polos Katun"`
+}
+
+type UpdateProductDisplayNameRequest struct {
+	Name string `json:"name" validate:"required" example:"Kaos Polos Katun"`
+}
+
+type CreateProductVariantMappingRequest struct {
+	SKU        string `json:"sku" example:"SKU-A"`
+	RawVariant string `json:"rawVariant" validate:"required" example:"merah,xl"`
+	Variant    string `json:"variant" validate:"required" example:"Red / XL"`
+}
+
+type UpdateProductVariantMappingRequest struct {
+	Variant string `json:"variant" validate:"required" example:"Red / XL"`
+}
+
+// Unique response structs
+type ProductNamePreviewResponse struct {
+	SKU            string `json:"sku" example:"SKU-A"`
+	Language       string `json:"language" example:"id"`
+	ProductName    string `json:"productName" example:"[READY] Kaos  Polos"`
+	Variant        string `json:"variant" example:"merah/xl"`
+	DisplayName    string `json:"displayName" example:"Kaos Polos Katun"`
+	DisplayVariant string `json:"displayVariant" example:"Red / XL"`
+}
+
+// GetProductDisplayNames retrieves the curated product display names
+// @Summary Get Product Display Names
+// @Description Retrieve the curated display names of SKUs per language with pagination and filters
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of names per page" default(10)
+// @Param sku query string false "Filter by SKU"
+// @Param language query string false "Filter by language (id, en)"
+// @Param search query string false "Search by SKU or name"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ProductDisplayNameResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/product-names [get]
+func (pnc *ProductNameController) GetProductDisplayNames(c fiber.Ctx) error {
+	log.Println("GetProductDisplayNames called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var displayNames []models.ProductDisplayName
+
+	// Build base query
+	query := pnc.DB.Model(&models.ProductDisplayName{}).Preload("CreateUser").Order("sku ASC, language ASC")
+
+	// SKU filter if provided
+	sku := strings.TrimSpace(c.Query("sku", ""))
+	if sku != "" {
+		query = query.Where("sku = ?", sku)
+	}
+
+	// Language filter if provided
+	language := strings.TrimSpace(c.Query("language", ""))
+	if language != "" {
+		query = query.Where("language = ?", language)
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("sku ILIKE ? OR name ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&displayNames).Error; err != nil {
+		log.Println("GetProductDisplayNames - Failed to retrieve product display names:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve product display names",
+		})
+	}
+
+	displayNameList := make([]models.ProductDisplayNameResponse, len(displayNames))
+	for i, displayName := range displayNames {
+		displayNameList[i] = *displayName.ToResponse()
+	}
+
+	// Build success message
+	message := "Product display names retrieved successfully"
+	var filters []string
+
+	if sku != "" {
+		filters = append(filters, "sku: "+sku)
+	}
+
+	if language != "" {
+		filters = append(filters, "language: "+language)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetProductDisplayNames completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    displayNameList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateProductDisplayName sets the name an SKU is displayed with in a language
+// @Summary Create Product Display Name
+// @Description Set the curated name an SKU is displayed with on picking lists and QC screens in a language
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateProductDisplayNameRequest true "Display name"
+// @Success 201 {object} utils.SuccessResponse{data=models.ProductDisplayNameResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/product-names [post]
+func (pnc *ProductNameController) CreateProductDisplayName(c fiber.Ctx) error {
+	log.Println("CreateProductDisplayName called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req CreateProductDisplayNameRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateProductDisplayName - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	req.Language = strings.ToLower(strings.TrimSpace(req.Language))
+	req.Name = strings.Join(strings.Fields(req.Name), " ")
+	if req.SKU == "" || req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU and name are required",
+		})
+	}
+	if !utils.IsLabelLanguage(req.Language) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Invalid language. Use %s or %s.", utils.LabelLanguageIndonesian, utils.LabelLanguageEnglish),
+		})
+	}
+
+	// The SKU must be a known product
+	var product models.Product
+	if err := pnc.DB.Where("sku = ?", req.SKU).First(&product).Error; err != nil {
+		log.Println("CreateProductDisplayName - Product not found:", req.SKU)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product with SKU " + req.SKU + " not found",
+		})
+	}
+
+	// Check for an existing name in the same language
+	var existing models.ProductDisplayName
+	if err := pnc.DB.Where("sku = ? AND language = ?", req.SKU, req.Language).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU " + req.SKU + " already has a display name in language " + req.Language,
+		})
+	}
+
+	displayName := models.ProductDisplayName{
+		SKU:       req.SKU,
+		Language:  req.Language,
+		Name:      req.Name,
+		CreatedBy: uint(userID),
+	}
+	if err := pnc.DB.Create(&displayName).Error; err != nil {
+		log.Println("CreateProductDisplayName - Failed to create product display name:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create product display name",
+		})
+	}
+	pnc.DB.Preload("CreateUser").First(&displayName, displayName.ID)
+
+	log.Println("CreateProductDisplayName completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product display name created successfully",
+		Data:    displayName.ToResponse(),
+	})
+}
+
+// UpdateProductDisplayName renames a curated product display name
+// @Summary Update Product Display Name
+// @Description Change the curated name an SKU is displayed with
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product Display Name ID"
+// @Param request body UpdateProductDisplayNameRequest true "Display name"
+// @Success 200 {object} utils.SuccessResponse{data=models.ProductDisplayNameResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/product-names/{id} [put]
+func (pnc *ProductNameController) UpdateProductDisplayName(c fiber.Ctx) error {
+	log.Println("UpdateProductDisplayName called")
+	// Parse id parameter
+	id := c.Params("id")
+	var displayName models.ProductDisplayName
+	if err := pnc.DB.Where("id = ?", id).First(&displayName).Error; err != nil {
+		log.Println("UpdateProductDisplayName - Product display name not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product display name with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateProductDisplayNameRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateProductDisplayName - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Name = strings.Join(strings.Fields(req.Name), " ")
+	if req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Name is required",
+		})
+	}
+
+	displayName.Name = req.Name
+	if err := pnc.DB.Save(&displayName).Error; err != nil {
+		log.Println("UpdateProductDisplayName - Failed to update product display name:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update product display name",
+		})
+	}
+	pnc.DB.Preload("CreateUser").First(&displayName, displayName.ID)
+
+	log.Println("UpdateProductDisplayName completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product display name updated successfully",
+		Data:    displayName.ToResponse(),
+	})
+}
+
+// DeleteProductDisplayName deletes a curated product display name
+// @Summary Delete Product Display Name
+// @Description Delete a curated name, the SKU is displayed with its normalized marketplace name again
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product Display Name ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/product-names/{id} [delete]
+func (pnc *ProductNameController) DeleteProductDisplayName(c fiber.Ctx) error {
+	log.Println("DeleteProductDisplayName called")
+	// Parse id parameter
+	id := c.Params("id")
+	result := pnc.DB.Where("id = ?", id).Delete(&models.ProductDisplayName{})
+	if result.Error != nil {
+		log.Println("DeleteProductDisplayName - Failed to delete product display name:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete product display name",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product display name with id " + id + " not found.",
+		})
+	}
+
+	log.Println("DeleteProductDisplayName completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product display name deleted successfully",
+	})
+}
+
+// GetProductVariantMappings retrieves the variant mappings
+// @Summary Get Product Variant Mappings
+// @Description Retrieve the mappings of marketplace variants to canonical variants with pagination and filters
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of mappings per page" default(10)
+// @Param sku query string false "Filter by SKU, mappings for every SKU are included"
+// @Param search query string false "Search by raw or canonical variant"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ProductVariantMappingResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/product-names/variants [get]
+func (pnc *ProductNameController) GetProductVariantMappings(c fiber.Ctx) error {
+	log.Println("GetProductVariantMappings called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var mappings []models.ProductVariantMapping
+
+	// Build base query
+	query := pnc.DB.Model(&models.ProductVariantMapping{}).Preload("CreateUser").Order("sku ASC, raw_variant ASC")
+
+	// SKU filter if provided
+	sku := strings.TrimSpace(c.Query("sku", ""))
+	if sku != "" {
+		query = query.Where("sku = ? OR sku = ?", sku, "")
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("raw_variant ILIKE ? OR variant ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&mappings).Error; err != nil {
+		log.Println("GetProductVariantMappings - Failed to retrieve product variant mappings:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve product variant mappings",
+		})
+	}
+
+	mappingList := make([]models.ProductVariantMappingResponse, len(mappings))
+	for i, mapping := range mappings {
+		mappingList[i] = *mapping.ToResponse()
+	}
+
+	// Build success message
+	message := "Product variant mappings retrieved successfully"
+	var filters []string
+
+	if sku != "" {
+		filters = append(filters, "sku: "+sku)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetProductVariantMappings completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    mappingList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateProductVariantMapping maps a marketplace variant to its canonical variant
+// @Summary Create Product Variant Mapping
+// @Description Map a marketplace variant to the variant displayed on picking lists and QC screens, for one SKU or for every SKU when sku is empty. Variants differing only in case and separators are mapped alike.
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateProductVariantMappingRequest true "Variant mapping"
+// @Success 201 {object} utils.SuccessResponse{data=models.ProductVariantMappingResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/product-names/variants [post]
+func (pnc *ProductNameController) CreateProductVariantMapping(c fiber.Ctx) error {
+	log.Println("CreateProductVariantMapping called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req CreateProductVariantMappingRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateProductVariantMapping - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	rawVariant := utils.VariantKey(req.RawVariant)
+	req.Variant = strings.Join(strings.Fields(req.Variant), " ")
+	if rawVariant == "" || req.Variant == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Raw variant and variant are required",
+		})
+	}
+
+	// The SKU must be a known product when the mapping is not for every SKU
+	if req.SKU != "" {
+		var product models.Product
+		if err := pnc.DB.Where("sku = ?", req.SKU).First(&product).Error; err != nil {
+			log.Println("CreateProductVariantMapping - Product not found:", req.SKU)
+			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Product with SKU " + req.SKU + " not found",
+			})
+		}
+	}
+
+	// Check for an existing mapping of the same variant
+	var existing models.ProductVariantMapping
+	if err := pnc.DB.Where("sku = ? AND raw_variant = ?", req.SKU, rawVariant).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Variant " + rawVariant + " is already mapped to " + existing.Variant,
+		})
+	}
+
+	mapping := models.ProductVariantMapping{
+		SKU:        req.SKU,
+		RawVariant: rawVariant,
+		Variant:    req.Variant,
+		CreatedBy:  uint(userID),
+	}
+	if err := pnc.DB.Create(&mapping).Error; err != nil {
+		log.Println("CreateProductVariantMapping - Failed to create product variant mapping:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create product variant mapping",
+		})
+	}
+	pnc.DB.Preload("CreateUser").First(&mapping, mapping.ID)
+
+	log.Println("CreateProductVariantMapping completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product variant mapping created successfully",
+		Data:    mapping.ToResponse(),
+	})
+}
+
+// UpdateProductVariantMapping changes the canonical variant of a mapping
+// @Summary Update Product Variant Mapping
+// @Description Change the variant a marketplace variant is displayed as
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product Variant Mapping ID"
+// @Param request body UpdateProductVariantMappingRequest true "Canonical variant"
+// @Success 200 {object} utils.SuccessResponse{data=models.ProductVariantMappingResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/product-names/variants/{id} [put]
+func (pnc *ProductNameController) UpdateProductVariantMapping(c fiber.Ctx) error {
+	log.Println("UpdateProductVariantMapping called")
+	// Parse id parameter
+	id := c.Params("id")
+	var mapping models.ProductVariantMapping
+	if err := pnc.DB.Where("id = ?", id).First(&mapping).Error; err != nil {
+		log.Println("UpdateProductVariantMapping - Product variant mapping not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product variant mapping with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateProductVariantMappingRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateProductVariantMapping - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Variant = strings.Join(strings.Fields(req.Variant), " ")
+	if req.Variant == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Variant is required",
+		})
+	}
+
+	mapping.Variant = req.Variant
+	if err := pnc.DB.Save(&mapping).Error; err != nil {
+		log.Println("UpdateProductVariantMapping - Failed to update product variant mapping:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update product variant mapping",
+		})
+	}
+	pnc.DB.Preload("CreateUser").First(&mapping, mapping.ID)
+
+	log.Println("UpdateProductVariantMapping completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product variant mapping updated successfully",
+		Data:    mapping.ToResponse(),
+	})
+}
+
+// DeleteProductVariantMapping deletes a variant mapping
+// @Summary Delete Product Variant Mapping
+// @Description Delete a variant mapping, the variant is displayed normalized again
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product Variant Mapping ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/product-names/variants/{id} [delete]
+func (pnc *ProductNameController) DeleteProductVariantMapping(c fiber.Ctx) error {
+	log.Println("DeleteProductVariantMapping called")
+	// Parse id parameter
+	id := c.Params("id")
+	result := pnc.DB.Where("id = ?", id).Delete(&models.ProductVariantMapping{})
+	if result.Error != nil {
+		log.Println("DeleteProductVariantMapping - Failed to delete product variant mapping:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete product variant mapping",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product variant mapping with id " + id + " not found.",
+		})
+	}
+
+	log.Println("DeleteProductVariantMapping completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product variant mapping deleted successfully",
+	})
+}
+
+// PreviewProductName shows how a marketplace product name and variant are displayed
+// @Summary Preview Product Name
+// @Description Normalize a marketplace product name and variant of an SKU with the curated names and variant mappings, to check mappings before QC
+// @Tags Product Names
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sku query string true "SKU"
+// @Param name query string false "Marketplace product name"
+// @Param variant query string false "Marketplace variant"
+// @Param lang query string false "Display language (id, en)"
+// @Success 200 {object} utils.SuccessResponse{data=ProductNamePreviewResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/product-names/preview [get]
+func (pnc *ProductNameController) PreviewProductName(c fiber.Ctx) error {
+	log.Println("PreviewProductName called")
+	sku := strings.TrimSpace(c.Query("sku", ""))
+	if sku == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU is required",
+		})
+	}
+	name := c.Query("name", "")
+	variant := c.Query("variant", "")
+
+	language := utils.RequestLanguage(c)
+	displayName, displayVariant := utils.LoadProductNames(pnc.DB, language, []string{sku}).Display(sku, name, variant)
+
+	log.Println("PreviewProductName completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product name previewed successfully",
+		Data: ProductNamePreviewResponse{
+			SKU:            sku,
+			Language:       language,
+			ProductName:    name,
+			Variant:        variant,
+			DisplayName:    displayName,
+			DisplayVariant: displayVariant,
+		},
+	})
+}
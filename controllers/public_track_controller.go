@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// Coarse statuses shown to buyers
+const (
+	PublicTrackStatusProcessing = "processing"
+	PublicTrackStatusPacked     = "packed"
+	PublicTrackStatusShipped    = "shipped"
+)
+
+// publicTrackingNumberPattern rejects lookups that cannot be a tracking number before touching the database
+var publicTrackingNumberPattern = regexp.MustCompile(`^[A-Za-z0-9-]{6,100}$`)
+
+type PublicTrackController struct {
+	DB *gorm.DB
+}
+
+func NewPublicTrackController(db *gorm.DB) *PublicTrackController {
+	return &PublicTrackController{DB: db}
+}
+
+// Unique response structs
+// PublicTrackResponse represents the status of a parcel shown to its buyer, without any personal data
+type PublicTrackResponse struct {
+	TrackingNumber string  `json:"trackingNumber"`
+	Status         string  `json:"status"` // processing, packed or shipped
+	ProcessingAt   string  `json:"processingAt"`
+	PackedAt       *string `json:"packedAt,omitempty"`
+	ShippedAt      *string `json:"shippedAt,omitempty"`
+}
+
+// TrackOrder shows the status of a parcel to its buyer
+// @Summary Track Parcel
+// @Description Public status lookup for buyers. Returns only a coarse status (processing, packed, shipped) with its timestamps, no buyer, address or item data. Rate limited per IP, repeated lookups of unknown tracking numbers ban the IP temporarily. Canceled orders are not found.
+// @Tags Public Tracking
+// @Accept json
+// @Produce json
+// @Param trackingNumber path string true "Tracking Number"
+// @Success 200 {object} utils.SuccessResponse{data=PublicTrackResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 429 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/public/track/{trackingNumber} [get]
+func (ptc *PublicTrackController) TrackOrder(c fiber.Ctx) error {
+	log.Println("TrackOrder called")
+	// Buyers may share the link, keep the status out of shared caches
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	trackingNumber := strings.TrimSpace(c.Params("trackingNumber"))
+	if !publicTrackingNumberPattern.MatchString(trackingNumber) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid tracking number",
+		})
+	}
+
+	// Only the columns needed for the status, so no personal data is ever loaded
+	var order models.Order
+	err := ptc.DB.WithContext(c.Context()).Model(&models.Order{}).
+		Select("id, tracking_number, event_status, created_at").
+		Where("tracking_number = ? AND parent_order_id IS NULL", trackingNumber).
+		Where("event_status NOT IN ?", []string{models.EventStatusCanceled, models.EventStatusMerged, models.EventStatusDuplicated}).
+		Order("id DESC").First(&order).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Println("TrackOrder - Failed to retrieve order:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve parcel status",
+			})
+		}
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Parcel not found",
+		})
+	}
+
+	response := PublicTrackResponse{
+		TrackingNumber: order.TrackingNumber,
+		Status:         PublicTrackStatusProcessing,
+		ProcessingAt:   order.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+	formatTime := func(t time.Time) *string {
+		formatted := t.Format("02-01-2006 15:04:05")
+		return &formatted
+	}
+
+	// Packed once QC is completed on either lane
+	var packedAt []time.Time
+	if err := ptc.DB.WithContext(c.Context()).Raw(`SELECT updated_at FROM qc_ribbons WHERE tracking_number = ? AND status = 'completed'
+		UNION ALL SELECT updated_at FROM qc_onlines WHERE tracking_number = ? AND status = 'completed'
+		ORDER BY updated_at ASC LIMIT 1`, trackingNumber, trackingNumber).Scan(&packedAt).Error; err != nil {
+		log.Println("TrackOrder - Failed to retrieve QC:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve parcel status",
+		})
+	}
+	if len(packedAt) > 0 {
+		response.Status = PublicTrackStatusPacked
+		response.PackedAt = formatTime(packedAt[0])
+	}
+
+	// Shipped once the parcel is scanned out
+	var outbound models.Outbound
+	if err := ptc.DB.WithContext(c.Context()).Select("id, created_at").Where("tracking_number = ?", trackingNumber).First(&outbound).Error; err == nil {
+		response.Status = PublicTrackStatusShipped
+		response.ShippedAt = formatTime(outbound.CreatedAt)
+	} else if err != gorm.ErrRecordNotFound {
+		log.Println("TrackOrder - Failed to retrieve outbound:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve parcel status",
+		})
+	}
+
+	log.Println("TrackOrder completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Parcel status retrieved successfully",
+		Data:    response,
+	})
+}
@@ -0,0 +1,1106 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type QCController struct {
+	DB                 *gorm.DB
+	ShadowPercent      int // share of lane scan and validate requests checked against the unified QC service
+	qcRibbonController *QCRibbonController
+	qcOnlineController *QCOnlineController
+}
+
+func NewQCController(cfg *config.Config, db *gorm.DB) *QCController {
+	return &QCController{
+		DB:                 db,
+		ShadowPercent:      cfg.QCShadowPercent,
+		qcRibbonController: NewQCRibbonController(db),
+		qcOnlineController: NewQCOnlineController(db),
+	}
+}
+
+// Request structs
+type QCStartRequest struct {
+	TrackingNumber string `json:"trackingNumber" validate:"required"`
+	StationCode    string `json:"stationCode"` // claims the station, defaults to the station already claimed by the user
+}
+
+// ResolveQCLane returns the QC lane (ribbon or online) configured for the given order channel
+// The order channel is matched against channel name or channel code, defaulting to online
+func (qcc *QCController) ResolveQCLane(orderChannel string) string {
+	orderChannel = strings.TrimSpace(orderChannel)
+	if orderChannel == "" {
+		return "online"
+	}
+
+	var channel models.Channel
+	if err := qcc.DB.Where("LOWER(channel_name) = LOWER(?) OR LOWER(channel_code) = LOWER(?)", orderChannel, orderChannel).First(&channel).Error; err != nil {
+		return "online"
+	}
+	return channelQCLane(&channel)
+}
+
+// channelQCLane returns the QC lane of a channel, orders of unknown channels go to the online lane
+func channelQCLane(channel *models.Channel) string {
+	if channel != nil && channel.QCLane == "ribbon" {
+		return "ribbon"
+	}
+	return "online"
+}
+
+// QCStart starts QC processing on the lane configured for the order's channel
+// @Summary Start QC Processing
+// @Description Start QC processing for an order, automatically selecting QC Ribbon or QC Online based on the order's channel configuration
+// @Tags QC
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param trackingNumber body QCStartRequest true "Tracking Number"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc/start [post]
+func (qcc *QCController) QCStart(c fiber.Ctx) error {
+	log.Println("QCStart called")
+
+	// Binding request body
+	var req QCStartRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("QCStart - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Convert tracking number to uppercase and trim spaces
+	trackingNumber := models.NormalizeIdentifier(req.TrackingNumber)
+
+	// Find the order to determine its channel
+	var order models.Order
+	if err := qcc.DB.Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		log.Println("QCStart - No order found with tracking number:", trackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + trackingNumber + ".",
+		})
+	}
+
+	// Delegate to the lane specific start handler
+	lane := qcc.ResolveQCLane(order.Channel)
+	log.Printf("QCStart - Tracking number %s routed to %s lane (channel=%s)\n", trackingNumber, lane, order.Channel)
+	c.Set("X-QC-Lane", lane)
+
+	if lane == "ribbon" {
+		return qcc.qcRibbonController.QCRibbonStart(c)
+	}
+	return qcc.qcOnlineController.QCOnlineStart(c)
+}
+
+// QCScanRequest represents a single item scan during QC validation
+// PrintQCDocuments renders the documents packed with an order at QC as a single PDF print job
+// @Summary Print QC Documents
+// @Description Render the packing slip of an order, followed by its invoice when the order's channel requires one. Serial numbers captured at QC are listed under their items. Both are printed in the label language of the channel. The X-Invoice-Included header tells whether the invoice was bundled.
+// @Tags QC
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param trackingNumber path string true "Tracking Number"
+// @Success 200 {file} file
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc/print/{trackingNumber} [get]
+func (qcc *QCController) PrintQCDocuments(c fiber.Ctx) error {
+	log.Println("PrintQCDocuments called")
+
+	// Convert tracking number to uppercase and trim spaces
+	trackingNumber := models.NormalizeIdentifier(c.Params("trackingNumber"))
+
+	var order models.Order
+	if err := qcc.DB.Preload("OrderDetails").Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		log.Println("PrintQCDocuments - No order found with tracking number:", trackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + trackingNumber + ".",
+		})
+	}
+
+	// Packing slip first, then the invoice when the channel ships one inside the package
+	utils.LoadOrderSerials(qcc.DB, &order)
+	channel := utils.FindOrderChannel(qcc.DB, &order)
+	language := utils.OrderLabelLanguage(channel)
+	documents := []utils.PDFDocument{utils.BuildPackingSlipDocument(&order, language)}
+	invoiceIncluded := channel != nil && channel.InvoiceRequired
+	if invoiceIncluded {
+		store := utils.FindOrderStore(qcc.DB, &order)
+		documents = append(documents, utils.BuildInvoiceDocument(&order, store, utils.LoadPrintBranding(c.Context(), qcc.DB, store, models.PrintDocumentInvoice), language))
+	}
+
+	buffer, err := utils.BuildTextPDF(documents)
+	if err != nil {
+		log.Println("PrintQCDocuments - Failed to render PDF:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to render QC documents",
+		})
+	}
+
+	log.Println("PrintQCDocuments completed successfully")
+	c.Set("X-Invoice-Included", strconv.FormatBool(invoiceIncluded))
+	c.Set(fiber.HeaderContentType, utils.PDFContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"qc-%s.pdf\"", trackingNumber))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// GetQCContext retrieves everything the QC scanning screen shows for an order in one payload
+// @Summary Get QC Context
+// @Description Retrieve the read model of the QC scanning screen for an order: the order with its packing notes, the scanned vs expected quantity of each order line, the box catalog with the boxes already added to the parcel and the state of the QC record on the order's lane (null until QC is started). Loaded with a fixed set of queries whatever the number of order lines.
+// @Tags QC
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param trackingNumber path string true "Tracking Number"
+// @Success 200 {object} utils.SuccessResponse{data=QCContextResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc/context/{trackingNumber} [get]
+func (qcc *QCController) GetQCContext(c fiber.Ctx) error {
+	log.Println("GetQCContext called")
+
+	// Convert tracking number to uppercase and trim spaces
+	trackingNumber := models.NormalizeIdentifier(c.Params("trackingNumber"))
+
+	var order models.Order
+	if err := qcc.DB.Preload("OrderDetails").Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		log.Println("GetQCContext - No order found with tracking number:", trackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + trackingNumber + ".",
+		})
+	}
+
+	channel := utils.FindOrderChannel(qcc.DB, &order)
+	progress, err := orderQCProgress(qcc.DB, &order)
+	if err != nil {
+		log.Println("GetQCContext - Failed to build scan progress:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve QC scan progress",
+		})
+	}
+
+	response := QCContextResponse{
+		Lane: channelQCLane(channel),
+		Order: QCContextOrder{
+			ID:               order.ID,
+			TrackingNumber:   order.TrackingNumber,
+			OrderGineeID:     order.OrderGineeID,
+			ProcessingStatus: order.ProcessingStatus,
+			Channel:          order.Channel,
+			Store:            order.Store,
+			Buyer:            order.Buyer,
+			Courier:          order.Courier,
+			SentBefore:       order.SentBefore.Format("02-01-2006 15:04:05"),
+			InvoiceRequired:  channel != nil && channel.InvoiceRequired,
+			PackingNotes:     order.PackingNotes(),
+		},
+		Progress: progress,
+	}
+
+	// QC record of the order's lane with the boxes already added to the parcel
+	boxQuantities := make(map[uint]int)
+	var qcErr error
+	if response.Lane == "ribbon" {
+		var qcRibbon models.QCRibbon
+		if qcErr = qcc.DB.Preload("QCRibbonDetails").Preload("QCUser").Preload("QCStation").Where("tracking_number = ?", trackingNumber).First(&qcRibbon).Error; qcErr == nil {
+			response.QC = newQCContextRecord(qcRibbon.ID, qcRibbon.Status, qcRibbon.QCUser, qcRibbon.QCStation, qcRibbon.Complained, qcRibbon.CreatedAt, qcRibbon.UpdatedAt)
+			for _, detail := range qcRibbon.QCRibbonDetails {
+				boxQuantities[detail.BoxID] += detail.Quantity
+			}
+		}
+	} else {
+		var qcOnline models.QCOnline
+		if qcErr = qcc.DB.Preload("QCOnlineDetails").Preload("QCUser").Preload("QCStation").Where("tracking_number = ?", trackingNumber).First(&qcOnline).Error; qcErr == nil {
+			response.QC = newQCContextRecord(qcOnline.ID, qcOnline.Status, qcOnline.QCUser, qcOnline.QCStation, qcOnline.Complained, qcOnline.CreatedAt, qcOnline.UpdatedAt)
+			for _, detail := range qcOnline.QCOnlineDetails {
+				boxQuantities[detail.BoxID] += detail.Quantity
+			}
+		}
+	}
+	if qcErr != nil && !errors.Is(qcErr, gorm.ErrRecordNotFound) {
+		log.Println("GetQCContext - Failed to retrieve QC record:", qcErr)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve QC record",
+		})
+	}
+
+	var boxes []models.Box
+	if err := qcc.DB.Order("box_code ASC").Find(&boxes).Error; err != nil {
+		log.Println("GetQCContext - Failed to retrieve boxes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve boxes",
+		})
+	}
+	response.Boxes = make([]QCContextBox, len(boxes))
+	for i, box := range boxes {
+		response.Boxes[i] = QCContextBox{
+			ID:       box.ID,
+			BoxCode:  box.BoxCode,
+			BoxName:  box.BoxName,
+			Quantity: boxQuantities[box.ID],
+		}
+	}
+
+	log.Println("GetQCContext completed successfully")
+	c.Set("X-QC-Lane", response.Lane)
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC context retrieved successfully",
+		Data:    response,
+	})
+}
+
+// newQCContextRecord describes the QC record of either lane for the QC scanning screen
+func newQCContextRecord(id uint, status models.QCStatus, qcUser *models.User, station *models.QCStation, complained bool, createdAt, updatedAt time.Time) *QCContextRecord {
+	record := &QCContextRecord{
+		ID:         id,
+		Status:     string(status),
+		Complained: complained,
+		StartedAt:  createdAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:  updatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if qcUser != nil {
+		record.QCBy = qcUser.FullName
+	}
+	if station != nil {
+		record.Station = station.StationCode
+	}
+	return record
+}
+
+type QCScanRequest struct {
+	SKU          string `json:"sku" validate:"required"`
+	SerialNumber string `json:"serialNumber" example:"356938035643809"` // IMEI or SN of the unit, required for serialized products
+}
+
+// Unique response structs
+// QCProgressItem represents the scan progress of a single order detail
+type QCProgressItem struct {
+	SKU         string `json:"sku"`
+	ProductName string `json:"productName"`
+	Variant     string `json:"variant"`
+	OriginalSKU string `json:"originalSku,omitempty"` // ordered SKU when the item was substituted during picking
+	Expected    int    `json:"expected"`
+	Scanned     int    `json:"scanned"`
+	Remaining   int    `json:"remaining"`
+	IsValid     bool   `json:"isValid"`
+	// Serialized products need the serial number of every unit
+	SerialRequired bool     `json:"serialRequired"`
+	SerialNumbers  []string `json:"serialNumbers,omitempty"`
+}
+
+// QCProgressResponse represents the live scan progress of an order during QC
+type QCProgressResponse struct {
+	TrackingNumber string           `json:"trackingNumber"`
+	TotalExpected  int              `json:"totalExpected"`
+	TotalScanned   int              `json:"totalScanned"`
+	Completed      bool             `json:"completed"`
+	Items          []QCProgressItem `json:"items"`
+}
+
+// QCContextResponse is the read model of the QC scanning screen
+type QCContextResponse struct {
+	Lane     string              `json:"lane"` // ribbon or online
+	Order    QCContextOrder      `json:"order"`
+	Progress *QCProgressResponse `json:"progress"`
+	Boxes    []QCContextBox      `json:"boxes"`
+	QC       *QCContextRecord    `json:"qc"` // null until QC is started
+}
+
+// QCContextOrder represents the order fields shown on the QC scanning screen
+type QCContextOrder struct {
+	ID               uint     `json:"id"`
+	TrackingNumber   string   `json:"trackingNumber"`
+	OrderGineeID     string   `json:"orderGineeId"`
+	ProcessingStatus string   `json:"processingStatus"`
+	Channel          string   `json:"channel"`
+	Store            string   `json:"store"`
+	Buyer            string   `json:"buyer"`
+	Courier          string   `json:"courier"`
+	SentBefore       string   `json:"sentBefore"`
+	InvoiceRequired  bool     `json:"invoiceRequired"`        // an invoice is packed with the parcel
+	PackingNotes     []string `json:"packingNotes,omitempty"` // gift message, handling note and item notes of the order
+}
+
+// QCContextBox represents a box of the catalog with the quantity already added to the parcel
+type QCContextBox struct {
+	ID       uint   `json:"id"`
+	BoxCode  string `json:"boxCode"`
+	BoxName  string `json:"boxName"`
+	Quantity int    `json:"quantity"`
+}
+
+// QCContextRecord represents the state of the QC record of the order
+type QCContextRecord struct {
+	ID         uint   `json:"id"`
+	Status     string `json:"status"`
+	QCBy       string `json:"qcBy"`
+	Station    string `json:"station,omitempty"`
+	Complained bool   `json:"complained"`
+	StartedAt  string `json:"startedAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+var (
+	errQCSKUNotInOrder  = errors.New("sku not found in order details")
+	errQCSKUFullScanned = errors.New("sku already fully scanned")
+)
+
+// qcSubstitutedSKUError is returned when the ordered SKU is scanned for an item that was fulfilled by a substitute
+type qcSubstitutedSKUError struct {
+	SubstituteSKU string
+}
+
+func (e *qcSubstitutedSKUError) Error() string {
+	return "sku was substituted by " + e.SubstituteSKU
+}
+
+// qcScanDetail returns the order detail a scan of the SKU counts towards, the first line of the SKU that is not fully
+// scanned yet so orders with the SKU on several lines fill them in turn. When all of them are full the first line is
+// returned, nil when the SKU is not in the order.
+func qcScanDetail(details []models.OrderDetail, sku string) *models.OrderDetail {
+	var matched *models.OrderDetail
+	for i := range details {
+		if details[i].SKU != sku {
+			continue
+		}
+		if details[i].ScannedQuantity < details[i].Quantity {
+			return &details[i]
+		}
+		if matched == nil {
+			matched = &details[i]
+		}
+	}
+	return matched
+}
+
+// scanQCItem adds one scanned item to the order detail matching the SKU, with the serial number of the unit for
+// serialized products. The increment is done atomically so concurrent scans cannot exceed the expected quantity
+func scanQCItem(db *gorm.DB, trackingNumber, sku, serialNumber, lane string, userID uint) error {
+	var order models.Order
+	if err := db.Preload("OrderDetails").Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		return err
+	}
+
+	matchedDetail := qcScanDetail(order.OrderDetails, sku)
+	if matchedDetail == nil {
+		// Substituted items must be validated against the substitute, not the ordered SKU
+		for _, detail := range order.OrderDetails {
+			if detail.OriginalSKU != "" && detail.OriginalSKU == sku {
+				return &qcSubstitutedSKUError{SubstituteSKU: detail.SKU}
+			}
+		}
+		return errQCSKUNotInOrder
+	}
+
+	serialRequired, err := utils.SerialRequiredSKUs(db, []string{sku})
+	if err != nil {
+		return err
+	}
+	if serialRequired[sku] && models.NormalizeSerialNumber(serialNumber) == "" {
+		return utils.ErrSerialNumberRequired
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.OrderDetail{}).
+			Where("id = ? AND scanned_quantity < quantity", matchedDetail.ID).
+			Updates(map[string]interface{}{
+				"scanned_quantity": gorm.Expr("scanned_quantity + 1"),
+				"is_valid":         gorm.Expr("scanned_quantity + 1 >= quantity"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errQCSKUFullScanned
+		}
+
+		if !serialRequired[sku] {
+			return nil
+		}
+		return utils.CaptureOrderSerials(tx, &order, matchedDetail, []string{serialNumber}, lane, userID)
+	})
+}
+
+// checkQCSerialsComplete checks that every unit of the serialized products of an order has its serial number
+func checkQCSerialsComplete(db *gorm.DB, order *models.Order) error {
+	skus := make([]string, len(order.OrderDetails))
+	for i, detail := range order.OrderDetails {
+		skus[i] = detail.SKU
+	}
+	serialRequired, err := utils.SerialRequiredSKUs(db, skus)
+	if err != nil {
+		return err
+	}
+	if len(serialRequired) == 0 {
+		return nil
+	}
+
+	utils.LoadOrderSerials(db, order)
+	for _, detail := range order.OrderDetails {
+		if serialRequired[detail.SKU] && len(detail.SerialNumbers) != detail.Quantity {
+			return &qcSerialCountError{SKU: detail.SKU, Expected: detail.Quantity, Got: len(detail.SerialNumbers)}
+		}
+	}
+	return nil
+}
+
+// qcSerialCountError is returned when a serialized product does not have a serial number for every unit
+type qcSerialCountError struct {
+	SKU      string
+	Expected int
+	Got      int
+}
+
+func (e *qcSerialCountError) Error() string {
+	return fmt.Sprintf("Serial numbers missing for SKU %s. Expected: %d, Got: %d", e.SKU, e.Expected, e.Got)
+}
+
+// qcSerialErrorResponse maps serial number errors to the matching HTTP response, other errors fail with the fallback message
+func qcSerialErrorResponse(c fiber.Ctx, err error, fallback string) error {
+	var countErr *qcSerialCountError
+	var conflict *utils.SerialConflictError
+	switch {
+	case errors.Is(err, utils.ErrSerialNumberRequired):
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Serial number is required for this product, scan the IMEI or SN of the unit",
+		})
+	case errors.As(err, &countErr):
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   countErr.Error(),
+		})
+	case errors.As(err, &conflict):
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   conflict.Error(),
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fallback,
+		})
+	}
+}
+
+// buildQCProgress builds the scan progress of the order with the given tracking number
+func buildQCProgress(db *gorm.DB, trackingNumber string) (*QCProgressResponse, error) {
+	var order models.Order
+	if err := db.Preload("OrderDetails").Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		return nil, err
+	}
+	return orderQCProgress(db, &order)
+}
+
+// orderQCProgress builds the scan progress of an order loaded with its details
+func orderQCProgress(db *gorm.DB, order *models.Order) (*QCProgressResponse, error) {
+	skus := make([]string, len(order.OrderDetails))
+	for i, detail := range order.OrderDetails {
+		skus[i] = detail.SKU
+	}
+	serialRequired, err := utils.SerialRequiredSKUs(db, skus)
+	if err != nil {
+		return nil, err
+	}
+	utils.LoadOrderSerials(db, order)
+
+	progress := &QCProgressResponse{
+		TrackingNumber: order.TrackingNumber,
+		Completed:      true,
+		Items:          make([]QCProgressItem, len(order.OrderDetails)),
+	}
+	for i, detail := range order.OrderDetails {
+		progress.Items[i] = QCProgressItem{
+			SKU:         detail.SKU,
+			ProductName: detail.ProductName,
+			Variant:     detail.Variant,
+			OriginalSKU: detail.OriginalSKU,
+			Expected:    detail.Quantity,
+			Scanned:     detail.ScannedQuantity,
+			Remaining:   detail.Quantity - detail.ScannedQuantity,
+			IsValid:     detail.IsValid,
+
+			SerialRequired: serialRequired[detail.SKU],
+			SerialNumbers:  detail.SerialNumbers,
+		}
+		progress.TotalExpected += detail.Quantity
+		progress.TotalScanned += detail.ScannedQuantity
+		if detail.ScannedQuantity != detail.Quantity {
+			progress.Completed = false
+		}
+	}
+
+	return progress, nil
+}
+
+// parseChartMonth parses the month, year and compare query parameters of the QC chart endpoints
+// Month and year default to the current month
+func parseChartMonth(c fiber.Ctx) (int, time.Month, string, error) {
+	now := time.Now()
+
+	month, err := strconv.Atoi(c.Query("month", strconv.Itoa(int(now.Month()))))
+	if err != nil || month < 1 || month > 12 {
+		return 0, 0, "", errors.New("Invalid month. Use a number between 1 and 12.")
+	}
+
+	year, err := strconv.Atoi(c.Query("year", strconv.Itoa(now.Year())))
+	if err != nil || year < 2000 || year > 9999 {
+		return 0, 0, "", errors.New("Invalid year.")
+	}
+
+	compare := c.Query("compare", "")
+	if compare != "" && compare != "previous_month" && compare != "previous_year" {
+		return 0, 0, "", errors.New("Invalid compare. Use previous_month or previous_year.")
+	}
+
+	return year, time.Month(month), compare, nil
+}
+
+// chartComparisonMonth returns the month a chart is compared with
+func chartComparisonMonth(year int, month time.Month, compare string) (int, time.Month) {
+	if compare == "previous_year" {
+		return year - 1, month
+	}
+	previous := time.Date(year, month, 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+	return previous.Year(), previous.Month()
+}
+
+// chartChangePercent returns the change from the previous to the current count in percent, nil when there is nothing to compare with
+func chartChangePercent(current, previous int) *float64 {
+	if previous == 0 {
+		return nil
+	}
+	change := math.Round(float64(current-previous)/float64(previous)*10000) / 100
+	return &change
+}
+
+// recordQCMismatch stores a mispick caught at QC for the error hotspot report.
+// Recording is best effort, a failure is only logged and never blocks the QC flow.
+func recordQCMismatch(db *gorm.DB, c fiber.Ctx, lane, trackingNumber string, qcStationID *uint, sku, mismatchType string, scannedQuantity int) {
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return
+	}
+
+	mismatch := models.QCMismatch{
+		Lane:            lane,
+		TrackingNumber:  trackingNumber,
+		SKU:             sku,
+		Type:            mismatchType,
+		ScannedQuantity: scannedQuantity,
+		RecordedBy:      uint(userID),
+		QCStationID:     qcStationID,
+	}
+
+	// Attach the order picker and the expected quantity when the order is known
+	var order models.Order
+	if err := db.Preload("OrderDetails").Where("tracking_number = ?", trackingNumber).First(&order).Error; err == nil {
+		mismatch.OrderID = &order.ID
+		mismatch.PickedBy = order.PickedBy
+		for _, detail := range order.OrderDetails {
+			if detail.SKU == sku {
+				mismatch.ExpectedQuantity = detail.Quantity
+				break
+			}
+		}
+	}
+
+	if err := db.Create(&mismatch).Error; err != nil {
+		log.Println("recordQCMismatch - Failed to record QC mismatch:", err)
+	}
+}
+
+// qcScanMismatchType returns the mismatch type of a scan error, or an empty string when the error is not a mispick
+func qcScanMismatchType(err error) string {
+	var substituted *qcSubstitutedSKUError
+	switch {
+	case errors.Is(err, errQCSKUNotInOrder), errors.As(err, &substituted):
+		return models.QCMismatchWrongSKU
+	case errors.Is(err, errQCSKUFullScanned):
+		return models.QCMismatchOverScan
+	default:
+		return ""
+	}
+}
+
+// qcScanErrorResponse maps scan errors to the matching HTTP response
+func qcScanErrorResponse(c fiber.Ctx, err error, trackingNumber, sku string) error {
+	var substituted *qcSubstitutedSKUError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + trackingNumber,
+		})
+	case errors.Is(err, errQCSKUNotInOrder):
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product with SKU " + sku + " not found in order details",
+		})
+	case errors.Is(err, errQCSKUFullScanned):
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "All items with SKU " + sku + " have already been scanned",
+		})
+	case errors.As(err, &substituted):
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU " + sku + " was substituted by " + substituted.SubstituteSKU + ", scan the substitute instead",
+		})
+	default:
+		return qcSerialErrorResponse(c, err, "Failed to scan product with SKU "+sku)
+	}
+}
+
+// VoidQCRequest represents the coordinator approval required to void a QC
+type VoidQCRequest struct {
+	Username string `json:"username" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Reason   string `json:"reason" validate:"required"`
+}
+
+// resetOrderForQCVoid reverts the order back to picking_completed and clears its QC validation progress,
+// releasing the serial numbers captured so they are scanned again
+func resetOrderForQCVoid(tx *gorm.DB, trackingNumber string) error {
+	var order models.Order
+	if err := tx.Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		return err
+	}
+
+	if err := utils.ReleaseOrderSerials(tx, trackingNumber, models.SerialReleaseQCVoided, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.OrderDetail{}).Where("order_id = ?", order.ID).Updates(map[string]interface{}{
+		"is_valid":         false,
+		"scanned_quantity": 0,
+	}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&order).Update("processing_status", "picking_completed").Error
+}
+
+// voidOrderQC removes the QC Ribbon and QC Online records of an order, logging each as voided by the user,
+// and reverts the order to picking_completed so it goes through QC again
+func voidOrderQC(tx *gorm.DB, trackingNumber, reason string, userID uint) error {
+	var qcRibbons []models.QCRibbon
+	if err := tx.Where("tracking_number = ?", trackingNumber).Find(&qcRibbons).Error; err != nil {
+		return err
+	}
+	for _, qcRibbon := range qcRibbons {
+		if err := tx.Where("qc_ribbon_id = ?", qcRibbon.ID).Delete(&models.QCRibbonDetail{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("lane = ? AND qc_id = ?", "ribbon", qcRibbon.ID).Delete(&models.QCParcelPhoto{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&qcRibbon).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.QCVoid{
+			Lane:           "ribbon",
+			QCID:           qcRibbon.ID,
+			TrackingNumber: qcRibbon.TrackingNumber,
+			PreviousStatus: string(qcRibbon.Status),
+			QCBy:           qcRibbon.QCBy,
+			QCStationID:    qcRibbon.QCStationID,
+			Reason:         reason,
+			RequestedBy:    userID,
+			ApprovedBy:     userID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	var qcOnlines []models.QCOnline
+	if err := tx.Where("tracking_number = ?", trackingNumber).Find(&qcOnlines).Error; err != nil {
+		return err
+	}
+	for _, qcOnline := range qcOnlines {
+		if err := tx.Where("qc_online_id = ?", qcOnline.ID).Delete(&models.QCOnlineDetail{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("lane = ? AND qc_id = ?", "online", qcOnline.ID).Delete(&models.QCParcelPhoto{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&qcOnline).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.QCVoid{
+			Lane:           "online",
+			QCID:           qcOnline.ID,
+			TrackingNumber: qcOnline.TrackingNumber,
+			PreviousStatus: string(qcOnline.Status),
+			QCBy:           qcOnline.QCBy,
+			QCStationID:    qcOnline.QCStationID,
+			Reason:         reason,
+			RequestedBy:    userID,
+			ApprovedBy:     userID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return resetOrderForQCVoid(tx, trackingNumber)
+}
+
+// approverErrorResponse maps coordinator approval errors to the matching HTTP response
+func approverErrorResponse(c fiber.Ctx, err error) error {
+	if errors.Is(err, utils.ErrApproverNotPermitted) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User does not have required permissions",
+		})
+	}
+	return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Invalid coordinator credentials",
+	})
+}
+
+// newQCParcelPhoto validates the parcel photo sent when completing a QC and builds its record, flagged when
+// the order had packing notes so reviewers can check them against the photo.
+// Returns nil without error when no photo was captured.
+func newQCParcelPhoto(lane string, qcID uint, order *models.Order, photo string, capturedBy uint) (*models.QCParcelPhoto, error) {
+	if photo == "" {
+		return nil, nil
+	}
+	if _, _, err := utils.DecodeImageDataURL(photo); err != nil {
+		return nil, err
+	}
+	return &models.QCParcelPhoto{
+		Lane:           lane,
+		QCID:           qcID,
+		TrackingNumber: order.TrackingNumber,
+		Photo:          photo,
+		CapturedBy:     capturedBy,
+		PackingNotes:   order.HasPackingNotes(),
+	}, nil
+}
+
+var (
+	errQCStationNotFound  = errors.New("qc station not found")
+	errQCStationInactive  = errors.New("qc station is inactive")
+	errQCStationWrongLane = errors.New("qc station works another lane")
+	errQCStationClaimed   = errors.New("qc station is claimed by another user")
+	errQCStationSkill     = errors.New("user lacks the skills of the qc station lane")
+)
+
+// claimQCStation assigns the station to the user, releasing the station the user claimed before
+func claimQCStation(db *gorm.DB, station *models.QCStation, userID uint) error {
+	if !station.IsActive {
+		return errQCStationInactive
+	}
+	if station.ClaimedBy != nil {
+		if *station.ClaimedBy != userID {
+			return errQCStationClaimed
+		}
+		return nil
+	}
+
+	// Only users trained for the lane may work its stations
+	missingSkills, err := utils.MissingQCLaneSkills(db, userID, station.Lane)
+	if err != nil {
+		return err
+	}
+	if len(missingSkills) > 0 {
+		return fmt.Errorf("%w: %s", errQCStationSkill, utils.SkillNames(missingSkills))
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.QCStation{}).Where("claimed_by = ?", userID).Updates(map[string]interface{}{
+			"claimed_by": nil,
+			"claimed_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Claim only while still free, another user may have claimed it meanwhile
+		now := time.Now()
+		result := tx.Model(&models.QCStation{}).Where("id = ? AND claimed_by IS NULL", station.ID).Updates(map[string]interface{}{
+			"claimed_by": userID,
+			"claimed_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errQCStationClaimed
+		}
+		station.ClaimedBy = &userID
+		station.ClaimedAt = &now
+		return nil
+	})
+}
+
+// resolveQCStation returns the station a QC on the lane is performed at. A given station code is claimed
+// for the user, otherwise the station the user already claimed is used. Returns nil without error when
+// the user works without a station, or when their claimed station works another lane.
+func resolveQCStation(db *gorm.DB, stationCode, lane string, userID uint) (*models.QCStation, error) {
+	stationCode = strings.ToUpper(strings.TrimSpace(stationCode))
+	var station models.QCStation
+	if stationCode == "" {
+		if err := db.Where("claimed_by = ?", userID).First(&station).Error; err != nil || station.Lane != lane {
+			return nil, nil
+		}
+		return &station, nil
+	}
+
+	if err := db.Where("station_code = ?", stationCode).First(&station).Error; err != nil {
+		return nil, errQCStationNotFound
+	}
+	if station.Lane != lane {
+		return nil, errQCStationWrongLane
+	}
+	if err := claimQCStation(db, &station, userID); err != nil {
+		return nil, err
+	}
+	return &station, nil
+}
+
+// qcStationID returns the ID of the station, nil when the QC is performed without a station
+func qcStationID(station *models.QCStation) *uint {
+	if station == nil {
+		return nil
+	}
+	return &station.ID
+}
+
+// qcStationErrorResponse maps QC station errors to the matching HTTP response
+func qcStationErrorResponse(c fiber.Ctx, err error, stationCode string) error {
+	stationCode = strings.ToUpper(strings.TrimSpace(stationCode))
+	switch {
+	case errors.Is(err, errQCStationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station " + stationCode + " not found",
+		})
+	case errors.Is(err, errQCStationInactive):
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station " + stationCode + " is inactive",
+		})
+	case errors.Is(err, errQCStationWrongLane):
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station " + stationCode + " works another QC lane",
+		})
+	case errors.Is(err, errQCStationSkill):
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station " + stationCode + " requires skills you lack (" + strings.TrimPrefix(err.Error(), errQCStationSkill.Error()+": ") + ")",
+		})
+	case errors.Is(err, errQCStationClaimed):
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station " + stationCode + " is claimed by another user",
+		})
+	default:
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to claim QC station " + stationCode,
+		})
+	}
+}
+
+// qcListSupervisorRoles may review the QC records of other users and past days
+var qcListSupervisorRoles = []string{"developer", "superadmin", "coordinator"}
+
+var qcListStatuses = []string{"in_progress", "completed", "canceled", "pending"}
+
+// scopeQCList applies the userId, startDate, endDate and status filters of the QC list endpoints to the query.
+// QC staff only see their own records of today, supervisors see every user of their scope and any date range, today by default.
+// Coordinators with a team are limited to its members. Returns the filter descriptions, or a nil query after writing the error response.
+func scopeQCList(c fiber.Ctx, db *gorm.DB, query *gorm.DB) (*gorm.DB, []string, error) {
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		return nil, nil, c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var filters []string
+	filterUserID := strings.TrimSpace(c.Query("userId", ""))
+	startDate := strings.TrimSpace(c.Query("startDate", ""))
+	endDate := strings.TrimSpace(c.Query("endDate", ""))
+	supervisor := utils.HasPermission(c, qcListSupervisorRoles)
+	if !supervisor && (startDate != "" || endDate != "" || (filterUserID != "" && filterUserID != strconv.FormatUint(userID, 10))) {
+		return nil, nil, c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Only coordinators can view the QC records of other users or past days",
+		})
+	}
+
+	// Date range, today unless a supervisor asks for another range
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 0, 1)
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return nil, nil, c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		start = parsedStartDate
+		if endDate == "" {
+			end = start.AddDate(0, 0, 1)
+		}
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return nil, nil, c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		end = parsedEndDate.AddDate(0, 0, 1)
+		if startDate == "" {
+			start = parsedEndDate
+		}
+		filters = append(filters, "endDate: "+endDate)
+	}
+	if !end.After(start) {
+		return nil, nil, c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	query = query.Where("created_at >= ? AND created_at < ?", start, end)
+
+	// Users, only the own records for QC staff
+	switch {
+	case !supervisor:
+		query = query.Where("qc_by = ?", uint(userID))
+	case filterUserID != "":
+		targetUserID, err := strconv.ParseUint(filterUserID, 10, 32)
+		if err != nil {
+			return nil, nil, c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid userId",
+			})
+		}
+		query = query.Where("qc_by = ?", uint(targetUserID))
+		filters = append(filters, "userId: "+filterUserID)
+	}
+	if supervisor {
+		userRoles, _ := c.Locals("userRoles").([]string)
+		scopeTeamIDs, err := utils.CoordinatorTeamScope(db, uint(userID), userRoles)
+		if err != nil {
+			log.Println("scopeQCList - Failed to resolve team scope:", err)
+			utils.CaptureError(c, err)
+			return nil, nil, c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to resolve team scope",
+			})
+		}
+		if scopeTeamIDs != nil {
+			query = query.Where("qc_by IN (?)", utils.TeamMembersQuery(db, scopeTeamIDs...))
+		}
+	}
+
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		valid := false
+		for _, qcStatus := range qcListStatuses {
+			if status == qcStatus {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, nil, c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status. Use one of: " + strings.Join(qcListStatuses, ", "),
+			})
+		}
+		query = query.Where("status = ?", status)
+		filters = append(filters, "status: "+status)
+	}
+
+	return query, filters, nil
+}
+
+// AddQCBoxRequest records a box scanned while packing a QC parcel
+type AddQCBoxRequest struct {
+	BoxCode  string `json:"boxCode" example:"BOX-S"` // scanned box barcode
+	BoxID    uint   `json:"boxId"`                   // used when no box code is given
+	Quantity int    `json:"quantity" example:"1"`    // defaults to 1
+}
+
+// resolveQCBox finds a box by its scanned code, or by its ID when no code is given
+func resolveQCBox(db *gorm.DB, boxID uint, boxCode string) (*models.Box, error) {
+	boxCode = strings.TrimSpace(boxCode)
+	var box models.Box
+	switch {
+	case boxCode != "":
+		if err := db.Where("UPPER(box_code) = UPPER(?)", boxCode).First(&box).Error; err != nil {
+			return nil, errors.New("box with code " + boxCode + " not found")
+		}
+	case boxID != 0:
+		if err := db.Where("id = ?", boxID).First(&box).Error; err != nil {
+			return nil, fmt.Errorf("box with id %d not found", boxID)
+		}
+	default:
+		return nil, errors.New("boxCode or boxId is required")
+	}
+	return &box, nil
+}
+
+// resolveQCBoxDetails resolves the boxes of a QC completion payload into the box IDs in request order with their quantities.
+// A box listed twice, by code or ID, is rejected.
+func resolveQCBoxDetails(db *gorm.DB, details []CreateQCRibbonDetail) ([]uint, map[uint]int, error) {
+	boxIDs := make([]uint, 0, len(details))
+	quantities := make(map[uint]int, len(details))
+	for _, detail := range details {
+		box, err := resolveQCBox(db, detail.BoxID, detail.BoxCode)
+		if err != nil {
+			return nil, nil, err
+		}
+		if detail.Quantity < 1 {
+			return nil, nil, errors.New("quantity of box " + box.BoxCode + " must be at least 1")
+		}
+		if _, ok := quantities[box.ID]; ok {
+			return nil, nil, errors.New("duplicate box " + box.BoxCode + " in the request")
+		}
+		boxIDs = append(boxIDs, box.ID)
+		quantities[box.ID] = detail.Quantity
+	}
+	return boxIDs, quantities, nil
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/testutil"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestQCScanDetail(t *testing.T) {
+	details := []models.OrderDetail{
+		{ID: 1, SKU: "CASE-BLK", Quantity: 2, ScannedQuantity: 2},
+		{ID: 2, SKU: "CABLE", Quantity: 1, ScannedQuantity: 0},
+		{ID: 3, SKU: "CASE-BLK", Quantity: 3, ScannedQuantity: 1},
+		{ID: 4, SKU: "CASE-BLK", Quantity: 1, ScannedQuantity: 0},
+		{ID: 5, SKU: "CHARGER", Quantity: 1, ScannedQuantity: 1},
+		{ID: 6, SKU: "CHARGER", Quantity: 2, ScannedQuantity: 2},
+	}
+
+	tests := []struct {
+		name   string
+		sku    string
+		wantID uint // 0 when no detail is expected
+	}{
+		{"single line not scanned yet", "CABLE", 2},
+		{"first line full, scan counts towards the next line of the SKU", "CASE-BLK", 3},
+		{"all lines full returns the first line", "CHARGER", 5},
+		{"sku not in order", "UNKNOWN", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail := qcScanDetail(details, tt.sku)
+			if tt.wantID == 0 {
+				if detail != nil {
+					t.Fatalf("qcScanDetail(%q) = detail %d, want nil", tt.sku, detail.ID)
+				}
+				return
+			}
+			if detail == nil {
+				t.Fatalf("qcScanDetail(%q) = nil, want detail %d", tt.sku, tt.wantID)
+			}
+			if detail.ID != tt.wantID {
+				t.Errorf("qcScanDetail(%q) = detail %d, want %d", tt.sku, detail.ID, tt.wantID)
+			}
+		})
+	}
+
+	t.Run("lines fill in turn", func(t *testing.T) {
+		lines := []models.OrderDetail{
+			{ID: 1, SKU: "CASE-BLK", Quantity: 1},
+			{ID: 2, SKU: "CASE-BLK", Quantity: 2},
+		}
+		var scanned []uint
+		for i := 0; i < 3; i++ {
+			detail := qcScanDetail(lines, "CASE-BLK")
+			scanned = append(scanned, detail.ID)
+			detail.ScannedQuantity++
+		}
+		if scanned[0] != 1 || scanned[1] != 2 || scanned[2] != 2 {
+			t.Errorf("scans went to details %v, want [1 2 2]", scanned)
+		}
+	})
+}
+
+// Validating counts a single unit like a scan, it cannot mark a whole line as scanned at once
+func TestValidateQCProductCountsOneUnit(t *testing.T) {
+	tx := testutil.Begin(t, testDB)
+	f := testutil.NewFactory(t, tx)
+	qcUser := f.User("qc-ribbon")
+
+	ribbonController := NewQCRibbonController(tx)
+	onlineController := NewQCOnlineController(tx)
+
+	tests := []struct {
+		name     string
+		handler  func(order models.Order) (fiber.Handler, string)
+		quantity int
+		want     []int // status of each validate call in turn
+	}{
+		{"ribbon validates one unit at a time", func(order models.Order) (fiber.Handler, string) {
+			qc := f.QCRibbon(order, qcUser, models.QCStatusInProgress)
+			return ribbonController.ValidateQCRibbonProduct, "/api/ribbons/qc-ribbons/" + strconv.FormatUint(uint64(qc.ID), 10)
+		}, 1, []int{fiber.StatusOK, fiber.StatusOK, fiber.StatusBadRequest}},
+		{"ribbon refuses the full quantity", func(order models.Order) (fiber.Handler, string) {
+			qc := f.QCRibbon(order, qcUser, models.QCStatusInProgress)
+			return ribbonController.ValidateQCRibbonProduct, "/api/ribbons/qc-ribbons/" + strconv.FormatUint(uint64(qc.ID), 10)
+		}, 2, []int{fiber.StatusBadRequest}},
+		{"online validates one unit at a time", func(order models.Order) (fiber.Handler, string) {
+			qc := f.QCOnline(order, qcUser, models.QCStatusInProgress)
+			return onlineController.ValidateQCOnlineProduct, "/api/onlines/qc-onlines/" + strconv.FormatUint(uint64(qc.ID), 10)
+		}, 1, []int{fiber.StatusOK, fiber.StatusOK, fiber.StatusBadRequest}},
+		{"online refuses the full quantity", func(order models.Order) (fiber.Handler, string) {
+			qc := f.QCOnline(order, qcUser, models.QCStatusInProgress)
+			return onlineController.ValidateQCOnlineProduct, "/api/onlines/qc-onlines/" + strconv.FormatUint(uint64(qc.ID), 10)
+		}, 2, []int{fiber.StatusBadRequest}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := f.Order(
+				testutil.WithStatus(models.ProcessingStatusQCProgress, models.EventStatusInProgress),
+				testutil.WithDetails(models.OrderDetail{SKU: "TEST-VALIDATE", ProductName: "Test Product", Quantity: 2, Price: 50000}),
+			)
+			handler, qcPath := tt.handler(order)
+
+			for i, status := range tt.want {
+				resp, result := callHandler(t, handler, testRequest{
+					Method: fiber.MethodPut, Route: "/api/:lane/:qc/:id/validate", Path: qcPath + "/validate",
+					User: qcUser, Roles: []string{"qc-ribbon"},
+					Body: ValidateQCRibbonProductRequest{SKU: "TEST-VALIDATE", Quantity: tt.quantity},
+				})
+				expectStatus(t, "validate "+strconv.Itoa(i+1), resp, result, status)
+			}
+
+			var detail models.OrderDetail
+			if err := tx.Where("order_id = ?", order.ID).First(&detail).Error; err != nil {
+				t.Fatalf("failed to load order detail: %v", err)
+			}
+			wantScanned := 0
+			for _, status := range tt.want {
+				if status == fiber.StatusOK {
+					wantScanned++
+				}
+			}
+			if detail.ScannedQuantity != wantScanned || detail.IsValid != (wantScanned == detail.Quantity) {
+				t.Errorf("detail scanned %d valid %v, want %d scanned of %d", detail.ScannedQuantity, detail.IsValid, wantScanned, detail.Quantity)
+			}
+		})
+	}
+}
@@ -24,20 +24,24 @@ func NewQCOnlineController(db *gorm.DB) *QCOnlineController {
 // Request structs
 type QCOnlineStartRequest struct {
 	TrackingNumber string `json:"trackingNumber" validate:"required"`
+	StationCode    string `json:"stationCode"` // claims the station, defaults to the station already claimed by the user
 }
 
 type ValidateQCOnlineProductRequest struct {
-	SKU      string `json:"sku" validate:"required"`
-	Quantity int    `json:"quantity" validate:"required,min=1"`
+	SKU           string   `json:"sku" validate:"required"`
+	Quantity      int      `json:"quantity" validate:"required,min=1,max=1"` // always 1, units are validated one at a time
+	SerialNumbers []string `json:"serialNumbers"`                            // IMEI or SN of the unit, required for serialized products
 }
 
 type CreateQCOnlineDetail struct {
-	BoxID    uint `json:"boxId" validate:"required"`
-	Quantity int  `json:"quantity" validate:"required,min=1"`
+	BoxCode  string `json:"boxCode" example:"BOX-S"` // scanned box barcode
+	BoxID    uint   `json:"boxId"`                   // used when no box code is given
+	Quantity int    `json:"quantity" validate:"required,min=1"`
 }
 
 type CreateQCOnlineDetailRequest struct {
-	Details []CreateQCRibbonDetail `json:"details" validate:"required,dive,required"`
+	Details     []CreateQCRibbonDetail `json:"details" validate:"required,dive,required"`
+	ParcelPhoto string                 `json:"parcelPhoto" validate:"omitempty"` // optional base64 image data URL of the packed parcel with its label visible
 }
 
 // Unique response structs
@@ -53,11 +57,15 @@ type QcOnlinesDailyCountResponse struct {
 	Year        int                  `json:"year"`
 	DailyCounts []QcOnlineDailyCount `json:"dailyCounts"`
 	TotalCount  int                  `json:"totalCount"`
+
+	// Filled in compare mode with the counts of the compared month
+	Comparison    *QcOnlinesDailyCountResponse `json:"comparison,omitempty"`
+	ChangePercent *float64                     `json:"changePercent,omitempty"`
 }
 
 // GetQCOnlines retrieves a list of qc onlines with pagination and search
 // @Summary Get QC Onlines
-// @Description Retrieve a list of QC Onlines with pagination and search
+// @Description Retrieve a list of QC Onlines with pagination and search. QC staff see their own records of today, coordinators can review every user and past dates.
 // @Tags Onlines
 // @Accept json
 // @Produce json
@@ -65,9 +73,14 @@ type QcOnlinesDailyCountResponse struct {
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Number of QC Onlines per page" default(10)
 // @Param search query string false "Search term for tracking number"
+// @Param userId query int false "Filter by QC user ID (coordinators only, defaults to all users)"
+// @Param startDate query string false "Start date (YYYY-MM-DD, coordinators only, defaults to today)"
+// @Param endDate query string false "End date (YYYY-MM-DD, coordinators only, defaults to today)"
+// @Param status query string false "Filter by status (in_progress, completed, canceled, pending)"
 // @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.QCOnlineResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/onlines/qc-onlines [get]
 func (qcoc *QCOnlineController) GetQCOnlines(c fiber.Ctx) error {
@@ -79,23 +92,14 @@ func (qcoc *QCOnlineController) GetQCOnlines(c fiber.Ctx) error {
 
 	var qcOnlines []models.QCOnline
 
-	// Get current logged in user from context
-	userIDStr := c.Locals("userId").(string)
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Invalid user ID",
-		})
-	}
-
-	// Get start of current day (midnight)
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
 	// Build base query
-	query := qcoc.DB.Model(&models.QCOnline{}).Preload("QCOnlineDetails.Box").Preload("QCUser").Order("created_at DESC").Where("qc_by = ?", uint(userID)).Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay)
+	query := qcoc.DB.Model(&models.QCOnline{}).Preload("QCOnlineDetails.Box").Preload("QCUser").Preload("QCStation").Order("created_at DESC")
+
+	// QC staff see their own records of today, supervisors may filter users and dates
+	query, filters, err := scopeQCList(c, qcoc.DB, query)
+	if query == nil {
+		return err
+	}
 
 	// Search condition if provided
 	search := strings.TrimSpace(c.Query("search", ""))
@@ -114,9 +118,11 @@ func (qcoc *QCOnlineController) GetQCOnlines(c fiber.Ctx) error {
 	}
 
 	// Load orders for each QC Online by tracking number
+	language := utils.RequestLanguage(c)
 	for i := range qcOnlines {
 		var order models.Order
 		if err := qcoc.DB.Preload("OrderDetails").Where("tracking_number = ?", qcOnlines[i].TrackingNumber).First(&order).Error; err == nil {
+			utils.ApplyProductNames(qcoc.DB, language, order.OrderDetails)
 			qcOnlines[i].Order = &order
 		}
 	}
@@ -129,8 +135,6 @@ func (qcoc *QCOnlineController) GetQCOnlines(c fiber.Ctx) error {
 
 	// Build success message
 	message := "QC Onlines retrieved successfully"
-	var filters []string
-
 	if search != "" {
 		filters = append(filters, "search: "+search)
 	}
@@ -172,7 +176,7 @@ func (qcoc *QCOnlineController) GetQCOnline(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var qcOnline models.QCOnline
-	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Where("id = ?", id).First(&qcOnline).Error; err != nil {
+	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Preload("QCStation").Where("id = ?", id).First(&qcOnline).Error; err != nil {
 		log.Println("GetQCOnline - QC Online not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -183,6 +187,7 @@ func (qcoc *QCOnlineController) GetQCOnline(c fiber.Ctx) error {
 	// Load order by tracking number
 	var order models.Order
 	if err := qcoc.DB.Preload("OrderDetails").Where("tracking_number = ?", qcOnline.TrackingNumber).First(&order).Error; err == nil {
+		utils.ApplyProductNames(qcoc.DB, utils.RequestLanguage(c), order.OrderDetails)
 		qcOnline.Order = &order
 	}
 
@@ -194,13 +199,64 @@ func (qcoc *QCOnlineController) GetQCOnline(c fiber.Ctx) error {
 	})
 }
 
+// monthlyQCOnlineCounts counts QC Onlines per day of the given month, from the daily aggregates unless live
+func (qcoc *QCOnlineController) monthlyQCOnlineCounts(year int, month time.Month, live bool) (*QcOnlinesDailyCountResponse, error) {
+	// Start of the month and first day of next month at 00:00:00 (to use as upper bound)
+	startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
+
+	if !live {
+		aggregates := utils.DailyAggregateQuery(qcoc.DB, models.DailyAggregateQCByUser, models.DailyAggregateLaneOnline, startOfMonth, startOfNextMonth)
+
+		var dailyCounts []QcOnlineDailyCount
+		if err := aggregates.Session(&gorm.Session{}).Select("date, SUM(count) as count").Group("date").Order("date ASC").Scan(&dailyCounts).Error; err != nil {
+			return nil, err
+		}
+
+		var totalCount int64
+		if err := aggregates.Select("COALESCE(SUM(count), 0)").Scan(&totalCount).Error; err != nil {
+			return nil, err
+		}
+
+		return &QcOnlinesDailyCountResponse{
+			Month:       month.String(),
+			Year:        year,
+			DailyCounts: dailyCounts,
+			TotalCount:  int(totalCount),
+		}, nil
+	}
+
+	// Query to get daily counts for the month
+	var dailyCounts []QcOnlineDailyCount
+	if err := qcoc.DB.Model(&models.QCOnline{}).Select("DATE(created_at) as date, COUNT(*) as count").Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Group("DATE(created_at)").Order("date ASC").Scan(&dailyCounts).Error; err != nil {
+		return nil, err
+	}
+
+	// Get total count for the month
+	var totalCount int64
+	if err := qcoc.DB.Model(&models.QCOnline{}).Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Count(&totalCount).Error; err != nil {
+		return nil, err
+	}
+
+	return &QcOnlinesDailyCountResponse{
+		Month:       month.String(),
+		Year:        year,
+		DailyCounts: dailyCounts,
+		TotalCount:  int(totalCount),
+	}, nil
+}
+
 // GetChartQcOnlines retrieves QC Online data for charting
 // @Summary Get Chart QC Onlines
-// @Description Retrieve QC Online data for charting
+// @Description Retrieve daily QC Online counts of a month (current month by default), optionally compared with the previous month or the same month of the previous year
 // @Tags Onlines
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param month query int false "Month (1-12), defaults to the current month"
+// @Param year query int false "Year, defaults to the current year"
+// @Param compare query string false "Compare with previous_month or previous_year"
+// @Param live query bool false "Count the QC records instead of the daily aggregates" default(false)
 // @Success 200 {object} utils.SuccessResponse{data=QcOnlinesDailyCountResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -208,45 +264,44 @@ func (qcoc *QCOnlineController) GetQCOnline(c fiber.Ctx) error {
 // @Router /api/onlines/qc-onlines/chart [get]
 func (qcoc *QCOnlineController) GetChartQCOnlines(c fiber.Ctx) error {
 	log.Println("GetChartQCOnlines called")
-	// Get current month start and end dates
-	now := time.Now()
-	currentYear, currentMonth, _ := now.Date()
-	currentLocation := now.Location()
-
-	// Start of the month
-	startOfMonth := time.Date(currentYear, currentMonth, 1, 0, 0, 0, 0, currentLocation)
-
-	// First day of next month at 00:00:00 (to use as upper bound)
-	startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
-
-	// Query to get daily counts for current month
-	var dailyCounts []QcOnlineDailyCount
-
-	if err := qcoc.DB.Model(&models.QCOnline{}).Select("DATE(created_at) as date, COUNT(*) as count").Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Group("DATE(created_at)").Order("date ASC").Scan(&dailyCounts).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	// Parse month, year and compare parameters
+	year, month, compare, err := parseChartMonth(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to retrieve QC Online data",
+			Error:   err.Error(),
 		})
 	}
 
-	// Get total count for the current month
-	var totalCount int64
-	if err := qcoc.DB.Model(&models.QCOnline{}).Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Count(&totalCount).Error; err != nil {
+	live := c.Query("live", "false") == "true"
+	response, err := qcoc.monthlyQCOnlineCounts(year, month, live)
+	if err != nil {
+		log.Println("GetChartQCOnlines - Failed to retrieve QC Online data:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to retrieve total QC Online count",
+			Error:   "Failed to retrieve QC Online data",
 		})
 	}
 
-	// Format response
-	response := QcOnlinesDailyCountResponse{
-		Month:       currentMonth.String(),
-		Year:        currentYear,
-		DailyCounts: dailyCounts,
-		TotalCount:  int(totalCount),
-	}
+	message := "QC Online chart data " + month.String() + "  " + strconv.Itoa(year) + " retrieved successfully"
 
-	message := "QC Online chart data " + currentMonth.String() + "  " + strconv.Itoa(currentYear) + " retrieved successfully"
+	// Add the compared month when requested
+	if compare != "" {
+		compareYear, compareMonth := chartComparisonMonth(year, month, compare)
+		comparison, err := qcoc.monthlyQCOnlineCounts(compareYear, compareMonth, live)
+		if err != nil {
+			log.Println("GetChartQCOnlines - Failed to retrieve QC Online comparison data:", err)
+			utils.CaptureError(c, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve QC Online data",
+			})
+		}
+		response.Comparison = comparison
+		response.ChangePercent = chartChangePercent(response.TotalCount, comparison.TotalCount)
+		message += " (compared with " + compareMonth.String() + " " + strconv.Itoa(compareYear) + ")"
+	}
 
 	log.Println("GetChartQCOnlines completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
@@ -258,12 +313,12 @@ func (qcoc *QCOnlineController) GetChartQCOnlines(c fiber.Ctx) error {
 
 // QCOnlineStart Starting QC Online processing for an order
 // @Summary Start QC Online Processing
-// @Description Mark an order as in QC Online processing
+// @Description Mark an order as in QC Online processing. The response carries the order with its packing notes: gift message, handling note and item notes
 // @Tags Onlines
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param trackingNumber body QCRibbonStartRequest true "Tracking Number"
+// @Param trackingNumber body QCOnlineStartRequest true "Tracking Number"
 // @Success 200 {object} utils.SuccessResponse{data=models.QCOnlineResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -294,7 +349,16 @@ func (qcoc *QCOnlineController) QCOnlineStart(c fiber.Ctx) error {
 	}
 
 	// Convert tracking number to uppercase and trim spaces
-	req.TrackingNumber = strings.ToUpper(strings.TrimSpace(req.TrackingNumber))
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
+
+	// Reject scans that do not match the courier format, mistyped numbers are caught here with a suggestion
+	if err := utils.ValidateTrackingNumber(qcoc.DB, req.TrackingNumber); err != nil {
+		log.Println("QCOnlineStart - Tracking number format mismatch:", req.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
 
 	// Duplicate check in QCOnline
 	var existingQCOnline models.QCOnline
@@ -316,6 +380,48 @@ func (qcoc *QCOnlineController) QCOnlineStart(c fiber.Ctx) error {
 		})
 	}
 
+	// Canceled orders must not be packed
+	if order.EventStatus == models.EventStatusCanceled {
+		log.Println("QCOnlineStart - Order is canceled:", req.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with tracking number " + req.TrackingNumber + " has been canceled.",
+		})
+	}
+
+	// Held orders cannot start QC until released
+	if order.EventStatus == "held" {
+		log.Println("QCOnlineStart - Order is on hold:", req.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with tracking number " + req.TrackingNumber + " is on hold: " + order.HoldReason,
+		})
+	}
+
+	// Only users trained for the lane may QC on it
+	missingSkills, err := utils.MissingQCLaneSkills(qcoc.DB, uint(userID), "online")
+	if err != nil {
+		log.Println("QCOnlineStart - Failed to check QC skills:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check QC skills",
+		})
+	}
+	if len(missingSkills) > 0 {
+		log.Println("QCOnlineStart - User lacks QC lane skills:", userID)
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You lack the skills required for online QC: " + utils.SkillNames(missingSkills) + ".",
+		})
+	}
+
+	// Resolve the station the QC is performed at
+	station, err := resolveQCStation(qcoc.DB, req.StationCode, "online", uint(userID))
+	if err != nil {
+		log.Println("QCOnlineStart - Failed to resolve QC station:", err)
+		return qcStationErrorResponse(c, err, req.StationCode)
+	}
+
 	// Start database transaction
 	tx := qcoc.DB.Begin()
 	defer func() {
@@ -328,13 +434,15 @@ func (qcoc *QCOnlineController) QCOnlineStart(c fiber.Ctx) error {
 	qcOnline := models.QCOnline{
 		TrackingNumber: req.TrackingNumber,
 		QCBy:           uint(userID),
-		Status:         "in_progress",
+		QCStationID:    qcStationID(station),
+		Status:         models.QCStatusInProgress,
 		Complained:     false,
 	}
 
 	if err := tx.Create(&qcOnline).Error; err != nil {
 		tx.Rollback()
 		log.Println("QCOnlineStart - Failed to create QC Online record:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to start QC Online processing",
@@ -345,6 +453,7 @@ func (qcoc *QCOnlineController) QCOnlineStart(c fiber.Ctx) error {
 	if err := tx.Save(&order).Error; err != nil {
 		tx.Rollback()
 		log.Println("QCOnlineStart - Failed to update order processing status:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to update order processing status",
@@ -354,12 +463,19 @@ func (qcoc *QCOnlineController) QCOnlineStart(c fiber.Ctx) error {
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		log.Println("QCOnlineStart - Failed to commit transaction:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to start QC Online processing",
 		})
 	}
 
+	// Show the packer the gift message, handling note and item notes of the order
+	if err := qcoc.DB.Where("order_id = ?", order.ID).Find(&order.OrderDetails).Error; err != nil {
+		log.Println("QCOnlineStart - Failed to load order details:", err)
+	}
+	qcOnline.Order = &order
+
 	log.Println("QCOnlineStart completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
 		Success: true,
@@ -370,7 +486,7 @@ func (qcoc *QCOnlineController) QCOnlineStart(c fiber.Ctx) error {
 
 // ValidateQCOnlineProduct validates the QC Online Details items or product by SKU and quantity
 // @Summary Validate QC Online Product
-// @Description Validate the QC Online Details items or product by SKU and quantity
+// @Description Validate one unit of a product by SKU, counted like a scan. Quantities above one are rejected, every unit is scanned on its own. Serialized products need the serial number (IMEI or SN) of the unit
 // @Tags Onlines
 // @Accept json
 // @Produce json
@@ -380,6 +496,7 @@ func (qcoc *QCOnlineController) QCOnlineStart(c fiber.Ctx) error {
 // @Success 200 {object} utils.SuccessResponse{data=models.QCOnlineDetailResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/onlines/qc-onlines/{id}/validate [post]
 func (qcoc *QCOnlineController) ValidateQCOnlineProduct(c fiber.Ctx) error {
@@ -406,7 +523,7 @@ func (qcoc *QCOnlineController) ValidateQCOnlineProduct(c fiber.Ctx) error {
 	}
 
 	// Check if QC Online is in progress or pending
-	if qcOnline.Status != "in_progress" && qcOnline.Status != "pending" {
+	if !models.CanTransitionQCStatus(qcOnline.Status, models.QCStatusCompleted) {
 		log.Println("ValidateQCOnlineProduct - QC Online is not in progress or pending:", qcOnline.Status)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -415,7 +532,7 @@ func (qcoc *QCOnlineController) ValidateQCOnlineProduct(c fiber.Ctx) error {
 	}
 
 	// If QC Online is pending, check if the user is the one who marked it as pending
-	if qcOnline.Status == "pending" {
+	if qcOnline.Status == models.QCStatusPending {
 		userIDStr := c.Locals("userId").(string)
 		userID, err := strconv.ParseUint(userIDStr, 10, 32)
 		if err != nil {
@@ -430,50 +547,27 @@ func (qcoc *QCOnlineController) ValidateQCOnlineProduct(c fiber.Ctx) error {
 		}
 	}
 
-	// Search the target order by tracking number from QC online record
-	var order models.Order
-	if err := qcoc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("tracking_number = ?", qcOnline.TrackingNumber).First(&order).Error; err != nil {
-		log.Println("ValidateQCOnlineProduct - No order found with tracking number:", qcOnline.TrackingNumber)
-		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "No order found with tracking number " + qcOnline.TrackingNumber,
-		})
-	}
-
-	// Find matching order detail by SKU
-	var matchedDetail *models.OrderDetail
-	for i := range order.OrderDetails {
-		if order.OrderDetails[i].SKU == req.SKU {
-			matchedDetail = &order.OrderDetails[i]
-			break
-		}
-	}
-
-	// Check if product SKU exists in order details
-	if matchedDetail == nil {
-		log.Println("ValidatedQCOnlineProduct - Product not found in order details:", req.SKU)
-		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Product with SKU " + req.SKU + " not found in order details.",
-		})
-	}
-
-	// Check if quantity matches
-	if matchedDetail.Quantity != req.Quantity {
-		log.Println("ValidateQCOnlineProduct - Quantity mismatch for product:", req.SKU)
+	// Items are counted one unit at a time, so a quantity above one cannot mark the whole line as scanned
+	if req.Quantity != 1 || len(req.SerialNumbers) > 1 {
+		log.Println("ValidateQCOnlineProduct - More than one unit validated at once:", req.SKU)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   fmt.Sprintf("Quantity mismatch for SKU %s. Expected: %d, Got: %d", req.SKU, matchedDetail.Quantity, req.Quantity),
+			Error:   "Items are validated one unit at a time, scan each unit of SKU " + req.SKU + " separately",
 		})
 	}
+	serialNumber := ""
+	if len(req.SerialNumbers) == 1 {
+		serialNumber = req.SerialNumbers[0]
+	}
 
-	// Update the is_valid flag to true
-	if err := qcoc.DB.Model(&models.OrderDetail{}).Where("id = ?", matchedDetail.ID).Update("is_valid", true).Error; err != nil {
-		log.Println("ValidateQCOnlineProduct - Failed to update order detail:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Failed to update order detail for product with SKU " + req.SKU,
-		})
+	// Add the unit to the matching order detail like a scan
+	validatorID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err := scanQCItem(qcoc.DB, qcOnline.TrackingNumber, req.SKU, serialNumber, "online", uint(validatorID)); err != nil {
+		if mismatchType := qcScanMismatchType(err); mismatchType != "" {
+			recordQCMismatch(qcoc.DB, c, "online", qcOnline.TrackingNumber, qcOnline.QCStationID, req.SKU, mismatchType, 1)
+		}
+		log.Println("ValidateQCOnlineProduct - Failed to validate product:", err)
+		return qcScanErrorResponse(c, err, qcOnline.TrackingNumber, req.SKU)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
@@ -485,7 +579,7 @@ func (qcoc *QCOnlineController) ValidateQCOnlineProduct(c fiber.Ctx) error {
 
 // CompleteQcOnline adding box details and marking QC Online as completed.
 // @Summary Complete QC Online
-// @Description Add box details and mark QC Online as completed
+// @Description Add box details and mark QC Online as completed, optionally with a photo of the packed parcel showing its label. Boxes are given by their scanned box code or their ID; boxes already scanned while packing are kept and take the quantity of the request when listed again
 // @Tags Onlines
 // @Accept json
 // @Produce json
@@ -522,7 +616,7 @@ func (qcoc *QCOnlineController) CompleteQcOnline(c fiber.Ctx) error {
 	}
 
 	// Check if QC Online is in progress or pending
-	if qcOnline.Status != "in_progress" && qcOnline.Status != "pending" {
+	if !models.CanTransitionQCStatus(qcOnline.Status, models.QCStatusCompleted) {
 		log.Println("CompleteQcOnline - QC Online is not in progress or pending:", qcOnline.Status)
 		return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
 			Success: true,
@@ -542,7 +636,7 @@ func (qcoc *QCOnlineController) CompleteQcOnline(c fiber.Ctx) error {
 		})
 	}
 
-	if qcOnline.Status == "pending" && qcOnline.QCBy != uint(userID) {
+	if qcOnline.Status == models.QCStatusPending && qcOnline.QCBy != uint(userID) {
 		log.Println("CompleteQcOnline - User is not the one who marked QC Online as pending:", userID)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -568,19 +662,48 @@ func (qcoc *QCOnlineController) CompleteQcOnline(c fiber.Ctx) error {
 				Error:   "Order details not validated",
 			})
 		}
-	}
 
-	// Validate all boxes exist and no duplicates
-	boxIDSet := make(map[uint]bool)
-	for _, detailReq := range req.Details {
-		// Check for duplicate box IDs in the request
-		if boxIDSet[detailReq.BoxID] {
+		// Scanned counts must match the expected quantity
+		if detail.ScannedQuantity != detail.Quantity {
+			log.Println("CompleteQcOnline - Scanned quantity mismatch:", detail.SKU)
 			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 				Success: false,
-				Error:   "Duplicate box ID in the request",
+				Error:   fmt.Sprintf("Scanned quantity mismatch for SKU %s. Expected: %d, Scanned: %d", detail.SKU, detail.Quantity, detail.ScannedQuantity),
 			})
 		}
-		boxIDSet[detailReq.BoxID] = true
+	}
+
+	// Serialized products need the serial number of every unit
+	if err := checkQCSerialsComplete(qcoc.DB, &order); err != nil {
+		log.Println("CompleteQcOnline - Serial numbers incomplete:", err)
+		return qcSerialErrorResponse(c, err, "Failed to check serial numbers")
+	}
+
+	// Resolve the scanned box codes, or box IDs, and reject duplicates
+	boxIDs, boxQuantities, err := resolveQCBoxDetails(qcoc.DB, req.Details)
+	if err != nil {
+		log.Println("CompleteQcOnline - Invalid box details:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid box details: " + err.Error(),
+		})
+	}
+	if len(boxIDs) == 0 && len(qcOnline.QCOnlineDetails) == 0 {
+		log.Println("CompleteQcOnline - No boxes recorded:", qcOnline.ID)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No boxes recorded, scan the boxes used or list them in details",
+		})
+	}
+
+	// Validate the optional parcel photo
+	parcelPhoto, err := newQCParcelPhoto("online", qcOnline.ID, &order, req.ParcelPhoto, uint(userID))
+	if err != nil {
+		log.Println("CompleteQcOnline - Invalid parcel photo:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid parcel photo: " + err.Error(),
+		})
 	}
 
 	// Start database transaction
@@ -591,39 +714,60 @@ func (qcoc *QCOnlineController) CompleteQcOnline(c fiber.Ctx) error {
 		}
 	}()
 
-	// Create QCOnlineDetails records
-	for _, detailReq := range req.Details {
-		qcOnlineDetail := models.QCOnlineDetail{
-			QCOnlineID: qcOnline.ID,
-			BoxID:      detailReq.BoxID,
-			Quantity:   detailReq.Quantity,
-		}
-		qcOnline.QCOnlineDetails = append(qcOnline.QCOnlineDetails, qcOnlineDetail)
+	// Record the boxes of the request, boxes already scanned while packing take the quantity of the request
+	recordedBoxes := make(map[uint]*models.QCOnlineDetail)
+	for i := range qcOnline.QCOnlineDetails {
+		recordedBoxes[qcOnline.QCOnlineDetails[i].BoxID] = &qcOnline.QCOnlineDetails[i]
 	}
-	if err := tx.Create(&qcOnline.QCOnlineDetails).Error; err != nil {
-		tx.Rollback()
-		log.Println("CompleteQcOnline - Failed to create QC Online details:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Failed to create QC Online details",
-		})
+	for _, boxID := range boxIDs {
+		var err error
+		if recorded, ok := recordedBoxes[boxID]; ok {
+			recorded.Quantity = boxQuantities[boxID]
+			err = tx.Model(&models.QCOnlineDetail{}).Where("id = ?", recorded.ID).Update("quantity", recorded.Quantity).Error
+		} else {
+			err = tx.Create(&models.QCOnlineDetail{QCOnlineID: qcOnline.ID, BoxID: boxID, Quantity: boxQuantities[boxID]}).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			log.Println("CompleteQcOnline - Failed to create QC Online details:", err)
+			utils.CaptureError(c, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to create QC Online details",
+			})
+		}
 	}
 
 	// Update QCOnline status to completed
-	qcOnline.Status = "completed"
+	qcOnline.Status = models.QCStatusCompleted
 	if err := tx.Save(&qcOnline).Error; err != nil {
 		tx.Rollback()
 		log.Println("CompleteQcOnline - Failed to update QC Online status:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to update QC Online status",
 		})
 	}
 
+	// Link the parcel photo to the QC record
+	if parcelPhoto != nil {
+		if err := tx.Create(parcelPhoto).Error; err != nil {
+			tx.Rollback()
+			log.Println("CompleteQcOnline - Failed to save parcel photo:", err)
+			utils.CaptureError(c, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to save parcel photo",
+			})
+		}
+	}
+
 	// Update order processing status to "qc_completed"
 	if err := tx.Model(&models.Order{}).Where("tracking_number = ?", qcOnline.TrackingNumber).Update("processing_status", "qc_completed").Error; err != nil {
 		tx.Rollback()
 		log.Println("CompleteQcOnline - Failed to update order processing status:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to update order processing status",
@@ -633,6 +777,7 @@ func (qcoc *QCOnlineController) CompleteQcOnline(c fiber.Ctx) error {
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		log.Println("CompleteQcOnline - Failed to commit transaction:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to complete QC Online",
@@ -640,8 +785,9 @@ func (qcoc *QCOnlineController) CompleteQcOnline(c fiber.Ctx) error {
 	}
 
 	// Reload the updated record with all relationships for response
-	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Where("id = ?", qcOnline.ID).First(&qcOnline).Error; err != nil {
+	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Preload("QCStation").Where("id = ?", qcOnline.ID).First(&qcOnline).Error; err != nil {
 		log.Println("CompleteQcOnline - Failed to reload QC Online record:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to retrieve completed QC Online",
@@ -650,6 +796,7 @@ func (qcoc *QCOnlineController) CompleteQcOnline(c fiber.Ctx) error {
 
 	// load order by tracking number
 	if err := qcoc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("tracking_number = ?", qcOnline.TrackingNumber).First(&order).Error; err == nil {
+		utils.ApplyProductNames(qcoc.DB, utils.RequestLanguage(c), order.OrderDetails)
 		qcOnline.Order = &order
 	}
 
@@ -661,6 +808,130 @@ func (qcoc *QCOnlineController) CompleteQcOnline(c fiber.Ctx) error {
 	})
 }
 
+// AddQCOnlineBox records a box scanned while packing a QC Online parcel
+// @Summary Add QC Online Box
+// @Description Record a box by its scanned barcode as it is used, before completing the QC Online. Scanning a box already recorded adds to its quantity.
+// @Tags Onlines
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Online ID"
+// @Param box body AddQCBoxRequest true "Scanned box"
+// @Success 200 {object} utils.SuccessResponse{data=models.QCOnlineResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/onlines/qc-onlines/{id}/boxes [post]
+func (qcoc *QCOnlineController) AddQCOnlineBox(c fiber.Ctx) error {
+	log.Println("AddQCOnlineBox called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("AddQCOnlineBox - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var qcOnline models.QCOnline
+	if err := qcoc.DB.Preload("QCOnlineDetails").Where("id = ?", id).First(&qcOnline).Error; err != nil {
+		log.Println("AddQCOnlineBox - QC Online not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Online with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req AddQCBoxRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("AddQCOnlineBox - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Quantity must be greater than 0",
+		})
+	}
+
+	// Boxes can only be added while the QC Online is open
+	if !models.CanTransitionQCStatus(qcOnline.Status, models.QCStatusCompleted) {
+		log.Println("AddQCOnlineBox - QC Online is not in progress or pending:", qcOnline.Status)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Online is not in progress or pending",
+		})
+	}
+	if qcOnline.Status == models.QCStatusPending && qcOnline.QCBy != uint(userID) {
+		log.Println("AddQCOnlineBox - User is not the one who marked QC Online as pending:", userID)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Only the user who marked the QC Online as pending can add boxes",
+		})
+	}
+
+	box, err := resolveQCBox(qcoc.DB, req.BoxID, req.BoxCode)
+	if err != nil {
+		log.Println("AddQCOnlineBox - Box not found:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid box: " + err.Error(),
+		})
+	}
+
+	// Add to the box when already recorded, otherwise record it
+	quantity := req.Quantity
+	var recorded *models.QCOnlineDetail
+	for i := range qcOnline.QCOnlineDetails {
+		if qcOnline.QCOnlineDetails[i].BoxID == box.ID {
+			recorded = &qcOnline.QCOnlineDetails[i]
+			break
+		}
+	}
+	if recorded != nil {
+		quantity += recorded.Quantity
+		err = qcoc.DB.Model(&models.QCOnlineDetail{}).Where("id = ?", recorded.ID).Update("quantity", quantity).Error
+	} else {
+		err = qcoc.DB.Create(&models.QCOnlineDetail{QCOnlineID: qcOnline.ID, BoxID: box.ID, Quantity: quantity}).Error
+	}
+	if err != nil {
+		log.Println("AddQCOnlineBox - Failed to record box:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to record box",
+		})
+	}
+
+	// Reload the updated record with all relationships for response
+	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Preload("QCStation").First(&qcOnline, qcOnline.ID).Error; err != nil {
+		log.Println("AddQCOnlineBox - Failed to load updated QC Online:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load updated QC Online",
+		})
+	}
+
+	log.Println("AddQCOnlineBox completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Box %s recorded (quantity %d)", box.BoxCode, quantity),
+		Data:    qcOnline.ToResponse(),
+	})
+}
+
 // PendingQCOnline marks a QC Online as pending
 // @Summary Pending QC Online
 // @Description Mark a QC Online as pending
@@ -680,7 +951,7 @@ func (qcoc *QCOnlineController) PendingQCOnline(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var qcOnline models.QCOnline
-	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Where("id = ?", id).First(&qcOnline).Error; err != nil {
+	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Preload("QCStation").Where("id = ?", id).First(&qcOnline).Error; err != nil {
 		log.Println("PendingQCOnline - QC Online not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -689,7 +960,7 @@ func (qcoc *QCOnlineController) PendingQCOnline(c fiber.Ctx) error {
 	}
 
 	// Check if QC Online is in progress
-	if qcOnline.Status != "in_progress" {
+	if !models.CanTransitionQCStatus(qcOnline.Status, models.QCStatusPending) {
 		log.Println("PendingQCOnline - QC Online is not in progress:", qcOnline.Status)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -698,9 +969,10 @@ func (qcoc *QCOnlineController) PendingQCOnline(c fiber.Ctx) error {
 	}
 
 	// Update QCOnline status to pending
-	qcOnline.Status = "pending"
+	qcOnline.Status = models.QCStatusPending
 	if err := qcoc.DB.Save(&qcOnline).Error; err != nil {
 		log.Println("PendingQCOnline - Failed to update QC Online status:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to mark QC Online as pending",
@@ -708,8 +980,9 @@ func (qcoc *QCOnlineController) PendingQCOnline(c fiber.Ctx) error {
 	}
 
 	// Reload the updated record with all relationships for response
-	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Where("id = ?", qcOnline.ID).First(&qcOnline).Error; err != nil {
+	if err := qcoc.DB.Preload("QCOnlineDetails.Box").Preload("QCUser").Preload("QCStation").Where("id = ?", qcOnline.ID).First(&qcOnline).Error; err != nil {
 		log.Println("PendingQCOnline - Failed to reload QC Online record:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to retrieve pending QC Online",
@@ -719,6 +992,7 @@ func (qcoc *QCOnlineController) PendingQCOnline(c fiber.Ctx) error {
 	// Load order by tracking number
 	var order models.Order
 	if err := qcoc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("tracking_number = ?", qcOnline.TrackingNumber).First(&order).Error; err == nil {
+		utils.ApplyProductNames(qcoc.DB, utils.RequestLanguage(c), order.OrderDetails)
 		qcOnline.Order = &order
 	}
 
@@ -729,3 +1003,306 @@ func (qcoc *QCOnlineController) PendingQCOnline(c fiber.Ctx) error {
 		Data:    qcOnline.ToResponse(),
 	})
 }
+
+// ScanQCOnlineProduct adds a single scanned item to the QC Online validation progress
+// @Summary Scan QC Online Product
+// @Description Scan one item by SKU, the server accumulates scanned vs expected quantity per order detail. Serialized products need the serial number (IMEI or SN) of the unit, which must not be held by another order
+// @Tags Onlines
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Online ID"
+// @Param scan body QCScanRequest true "Scanned SKU"
+// @Success 200 {object} utils.SuccessResponse{data=QCProgressResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/onlines/qc-onlines/{id}/scan [put]
+func (qcoc *QCOnlineController) ScanQCOnlineProduct(c fiber.Ctx) error {
+	log.Println("ScanQCOnlineProduct called")
+	// Parse id parameter
+	id := c.Params("id")
+	var qcOnline models.QCOnline
+	if err := qcoc.DB.Where("id = ?", id).First(&qcOnline).Error; err != nil {
+		log.Println("ScanQCOnlineProduct - QC Online not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Online with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req QCScanRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ScanQCOnlineProduct - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	req.SKU = strings.TrimSpace(req.SKU)
+
+	// Check if QC Online is in progress or pending
+	if !models.CanTransitionQCStatus(qcOnline.Status, models.QCStatusCompleted) {
+		log.Println("ScanQCOnlineProduct - QC Online is not in progress or pending:", qcOnline.Status)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Online is not in progress or pending",
+		})
+	}
+
+	// Add one scanned item to the matching order detail
+	userID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err := scanQCItem(qcoc.DB, qcOnline.TrackingNumber, req.SKU, req.SerialNumber, "online", uint(userID)); err != nil {
+		if mismatchType := qcScanMismatchType(err); mismatchType != "" {
+			recordQCMismatch(qcoc.DB, c, "online", qcOnline.TrackingNumber, qcOnline.QCStationID, req.SKU, mismatchType, 1)
+		}
+		log.Println("ScanQCOnlineProduct - Failed to scan product:", err)
+		return qcScanErrorResponse(c, err, qcOnline.TrackingNumber, req.SKU)
+	}
+
+	progress, err := buildQCProgress(qcoc.DB, qcOnline.TrackingNumber)
+	if err != nil {
+		log.Println("ScanQCOnlineProduct - Failed to build scan progress:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve QC Online scan progress",
+		})
+	}
+
+	log.Println("ScanQCOnlineProduct completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product with SKU " + req.SKU + " scanned successfully",
+		Data:    progress,
+	})
+}
+
+// GetQCOnlineProgress retrieves the live scan progress of a QC Online
+// @Summary Get QC Online Progress
+// @Description Retrieve scanned vs expected quantity for each order detail of a QC Online
+// @Tags Onlines
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Online ID"
+// @Success 200 {object} utils.SuccessResponse{data=QCProgressResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/onlines/qc-onlines/{id}/progress [get]
+func (qcoc *QCOnlineController) GetQCOnlineProgress(c fiber.Ctx) error {
+	log.Println("GetQCOnlineProgress called")
+	// Parse id parameter
+	id := c.Params("id")
+	var qcOnline models.QCOnline
+	if err := qcoc.DB.Where("id = ?", id).First(&qcOnline).Error; err != nil {
+		log.Println("GetQCOnlineProgress - QC Online not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Online with id " + id + " not found.",
+		})
+	}
+
+	progress, err := buildQCProgress(qcoc.DB, qcOnline.TrackingNumber)
+	if err != nil {
+		log.Println("GetQCOnlineProgress - No order found with tracking number:", qcOnline.TrackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + qcOnline.TrackingNumber,
+		})
+	}
+
+	log.Println("GetQCOnlineProgress completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC Online scan progress retrieved successfully",
+		Data:    progress,
+	})
+}
+
+// VoidQCOnline voids a QC Online with coordinator approval
+// @Summary Void QC Online
+// @Description Void a mistaken QC Online validation or completion with coordinator approval. The order is reverted to picking_completed, box details are removed and validation progress is reset
+// @Tags Onlines
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Online ID"
+// @Param void body VoidQCRequest true "Coordinator credentials and void reason"
+// @Success 200 {object} utils.SuccessResponse{data=models.QCVoidResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/onlines/qc-onlines/{id}/void [put]
+func (qcoc *QCOnlineController) VoidQCOnline(c fiber.Ctx) error {
+	log.Println("VoidQCOnline called")
+
+	// Parse id parameter
+	id := c.Params("id")
+	var qcOnline models.QCOnline
+	if err := qcoc.DB.Where("id = ?", id).First(&qcOnline).Error; err != nil {
+		log.Println("VoidQCOnline - QC Online not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Online with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req VoidQCRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("VoidQCOnline - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		log.Println("VoidQCOnline - Void reason is required")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Void reason is required",
+		})
+	}
+
+	// Verify coordinator credentials
+	approver, err := utils.VerifyApprover(qcoc.DB, req.Username, req.Password, utils.CoordinatorApprovalRoles)
+	if err != nil {
+		log.Println("VoidQCOnline - Coordinator approval failed:", err)
+		return approverErrorResponse(c, err)
+	}
+
+	// Get current user ID from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("VoidQCOnline - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Orders already handed to outbound can no longer be voided
+	var order models.Order
+	if err := qcoc.DB.Where("tracking_number = ?", qcOnline.TrackingNumber).First(&order).Error; err != nil {
+		log.Println("VoidQCOnline - No order found with tracking number:", qcOnline.TrackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + qcOnline.TrackingNumber,
+		})
+	}
+	if order.ProcessingStatus == "outbound_completed" {
+		log.Println("VoidQCOnline - Order already outbound completed:", qcOnline.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order " + qcOnline.TrackingNumber + " has already been processed by outbound",
+		})
+	}
+
+	// Start database transaction
+	tx := qcoc.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Remove box detail rows and the QC Online record so the order can be QC'd again
+	if err := tx.Where("qc_online_id = ?", qcOnline.ID).Delete(&models.QCOnlineDetail{}).Error; err != nil {
+		tx.Rollback()
+		log.Println("VoidQCOnline - Failed to delete QC Online details:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete QC Online details",
+		})
+	}
+
+	if err := tx.Where("lane = ? AND qc_id = ?", "online", qcOnline.ID).Delete(&models.QCParcelPhoto{}).Error; err != nil {
+		tx.Rollback()
+		log.Println("VoidQCOnline - Failed to delete parcel photo:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete parcel photo",
+		})
+	}
+
+	if err := tx.Delete(&qcOnline).Error; err != nil {
+		tx.Rollback()
+		log.Println("VoidQCOnline - Failed to delete QC Online:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to void QC Online",
+		})
+	}
+
+	// Revert order to picking_completed and reset validation flags
+	if err := resetOrderForQCVoid(tx, qcOnline.TrackingNumber); err != nil {
+		tx.Rollback()
+		log.Println("VoidQCOnline - Failed to reset order:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reset order processing status",
+		})
+	}
+
+	// Log the void
+	qcVoid := models.QCVoid{
+		Lane:           "online",
+		QCID:           qcOnline.ID,
+		TrackingNumber: qcOnline.TrackingNumber,
+		PreviousStatus: string(qcOnline.Status),
+		QCBy:           qcOnline.QCBy,
+		QCStationID:    qcOnline.QCStationID,
+		Reason:         req.Reason,
+		RequestedBy:    uint(userID),
+		ApprovedBy:     approver.ID,
+	}
+	if err := tx.Create(&qcVoid).Error; err != nil {
+		tx.Rollback()
+		log.Println("VoidQCOnline - Failed to create QC void log:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to log QC void",
+		})
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		log.Println("VoidQCOnline - Failed to commit transaction:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to commit transaction",
+		})
+	}
+
+	// Reload the void log with all relationships for response
+	if err := qcoc.DB.Preload("QCUser").Preload("RequestUser").Preload("ApproveUser").First(&qcVoid, qcVoid.ID).Error; err != nil {
+		log.Println("VoidQCOnline - Failed to load QC void log:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load QC void log",
+		})
+	}
+
+	log.Println("VoidQCOnline completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC Online voided successfully",
+		Data:    qcVoid.ToResponse(),
+	})
+}
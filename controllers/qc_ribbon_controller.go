@@ -24,20 +24,24 @@ func NewQCRibbonController(db *gorm.DB) *QCRibbonController {
 // Request structs
 type QCRibbonStartRequest struct {
 	TrackingNumber string `json:"trackingNumber" validate:"required"`
+	StationCode    string `json:"stationCode"` // claims the station, defaults to the station already claimed by the user
 }
 
 type ValidateQCRibbonProductRequest struct {
-	SKU      string `json:"sku" validate:"required"`
-	Quantity int    `json:"quantity" validate:"required,min=1"`
+	SKU           string   `json:"sku" validate:"required"`
+	Quantity      int      `json:"quantity" validate:"required,min=1,max=1"` // always 1, units are validated one at a time
+	SerialNumbers []string `json:"serialNumbers"`                            // IMEI or SN of the unit, required for serialized products
 }
 
 type CreateQCRibbonDetail struct {
-	BoxID    uint `json:"boxId" validate:"required"`
-	Quantity int  `json:"quantity" validate:"required,min=1"`
+	BoxCode  string `json:"boxCode" example:"BOX-S"` // scanned box barcode
+	BoxID    uint   `json:"boxId"`                   // used when no box code is given
+	Quantity int    `json:"quantity" validate:"required,min=1"`
 }
 
 type CreateQCRibbonDetailRequest struct {
-	Details []CreateQCRibbonDetail `json:"details" validate:"required,dive,required"`
+	Details     []CreateQCRibbonDetail `json:"details" validate:"required,dive,required"`
+	ParcelPhoto string                 `json:"parcelPhoto" validate:"omitempty"` // optional base64 image data URL of the packed parcel with its label visible
 }
 
 // Unique response structs
@@ -53,11 +57,15 @@ type QcRibbonsDailyCountResponse struct {
 	Year        int                  `json:"year"`
 	DailyCounts []QcRibbonDailyCount `json:"dailyCounts"`
 	TotalCount  int                  `json:"totalCount"`
+
+	// Filled in compare mode with the counts of the compared month
+	Comparison    *QcRibbonsDailyCountResponse `json:"comparison,omitempty"`
+	ChangePercent *float64                     `json:"changePercent,omitempty"`
 }
 
 // GetQCRibbons retrieves a list of qc ribbons with pagination and search
 // @Summary Get QC Ribbons
-// @Description Retrieve a list of QC Ribbons with pagination and search
+// @Description Retrieve a list of QC Ribbons with pagination and search. QC staff see their own records of today, coordinators can review every user and past dates.
 // @Tags Ribbons
 // @Accept json
 // @Produce json
@@ -65,9 +73,14 @@ type QcRibbonsDailyCountResponse struct {
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Number of QC Ribbons per page" default(10)
 // @Param search query string false "Search term for tracking number"
+// @Param userId query int false "Filter by QC user ID (coordinators only, defaults to all users)"
+// @Param startDate query string false "Start date (YYYY-MM-DD, coordinators only, defaults to today)"
+// @Param endDate query string false "End date (YYYY-MM-DD, coordinators only, defaults to today)"
+// @Param status query string false "Filter by status (in_progress, completed, canceled, pending)"
 // @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.QCRibbonResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/ribbons/qc-ribbons [get]
 func (qcrc *QCRibbonController) GetQCRibbons(c fiber.Ctx) error {
@@ -79,23 +92,14 @@ func (qcrc *QCRibbonController) GetQCRibbons(c fiber.Ctx) error {
 
 	var qcRibbons []models.QCRibbon
 
-	// Get current logged in user from context
-	userIDStr := c.Locals("userId").(string)
-	userID, err := strconv.ParseUint(userIDStr, 10, 32)
-	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Invalid user ID",
-		})
-	}
-
-	// Get start of current day (midnight)
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
-
 	// Build base query
-	query := qcrc.DB.Model(&models.QCRibbon{}).Preload("QCRibbonDetails.Box").Preload("QCUser").Order("created_at DESC").Where("qc_by = ?", uint(userID)).Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay)
+	query := qcrc.DB.Model(&models.QCRibbon{}).Preload("QCRibbonDetails.Box").Preload("QCUser").Preload("QCStation").Order("created_at DESC")
+
+	// QC staff see their own records of today, supervisors may filter users and dates
+	query, filters, err := scopeQCList(c, qcrc.DB, query)
+	if query == nil {
+		return err
+	}
 
 	// Search condition if provided
 	search := strings.TrimSpace(c.Query("search", ""))
@@ -114,9 +118,11 @@ func (qcrc *QCRibbonController) GetQCRibbons(c fiber.Ctx) error {
 	}
 
 	// Load orders for each QC Ribbon by tracking number
+	language := utils.RequestLanguage(c)
 	for i := range qcRibbons {
 		var order models.Order
 		if err := qcrc.DB.Preload("OrderDetails").Where("tracking_number = ?", qcRibbons[i].TrackingNumber).First(&order).Error; err == nil {
+			utils.ApplyProductNames(qcrc.DB, language, order.OrderDetails)
 			qcRibbons[i].Order = &order
 		}
 	}
@@ -129,8 +135,6 @@ func (qcrc *QCRibbonController) GetQCRibbons(c fiber.Ctx) error {
 
 	// Build success message
 	message := "QC Ribbons retrieved successfully"
-	var filters []string
-
 	if search != "" {
 		filters = append(filters, "search: "+search)
 	}
@@ -172,7 +176,7 @@ func (qcrc *QCRibbonController) GetQCRibbon(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var qcRibbon models.QCRibbon
-	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
+	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Preload("QCStation").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
 		log.Println("GetQCRibbon - QC Ribbon not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -183,6 +187,7 @@ func (qcrc *QCRibbonController) GetQCRibbon(c fiber.Ctx) error {
 	// Load order by tracking number
 	var order models.Order
 	if err := qcrc.DB.Preload("OrderDetails").Where("tracking_number = ?", qcRibbon.TrackingNumber).First(&order).Error; err == nil {
+		utils.ApplyProductNames(qcrc.DB, utils.RequestLanguage(c), order.OrderDetails)
 		qcRibbon.Order = &order
 	}
 
@@ -194,13 +199,64 @@ func (qcrc *QCRibbonController) GetQCRibbon(c fiber.Ctx) error {
 	})
 }
 
+// monthlyQCRibbonCounts counts QC Ribbons per day of the given month, from the daily aggregates unless live
+func (qcrc *QCRibbonController) monthlyQCRibbonCounts(year int, month time.Month, live bool) (*QcRibbonsDailyCountResponse, error) {
+	// Start of the month and first day of next month at 00:00:00 (to use as upper bound)
+	startOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
+
+	if !live {
+		aggregates := utils.DailyAggregateQuery(qcrc.DB, models.DailyAggregateQCByUser, models.DailyAggregateLaneRibbon, startOfMonth, startOfNextMonth)
+
+		var dailyCounts []QcRibbonDailyCount
+		if err := aggregates.Session(&gorm.Session{}).Select("date, SUM(count) as count").Group("date").Order("date ASC").Scan(&dailyCounts).Error; err != nil {
+			return nil, err
+		}
+
+		var totalCount int64
+		if err := aggregates.Select("COALESCE(SUM(count), 0)").Scan(&totalCount).Error; err != nil {
+			return nil, err
+		}
+
+		return &QcRibbonsDailyCountResponse{
+			Month:       month.String(),
+			Year:        year,
+			DailyCounts: dailyCounts,
+			TotalCount:  int(totalCount),
+		}, nil
+	}
+
+	// Query to get daily counts for the month
+	var dailyCounts []QcRibbonDailyCount
+	if err := qcrc.DB.Model(&models.QCRibbon{}).Select("DATE(created_at) as date, COUNT(*) as count").Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Group("DATE(created_at)").Order("date ASC").Scan(&dailyCounts).Error; err != nil {
+		return nil, err
+	}
+
+	// Get total count for the month
+	var totalCount int64
+	if err := qcrc.DB.Model(&models.QCRibbon{}).Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Count(&totalCount).Error; err != nil {
+		return nil, err
+	}
+
+	return &QcRibbonsDailyCountResponse{
+		Month:       month.String(),
+		Year:        year,
+		DailyCounts: dailyCounts,
+		TotalCount:  int(totalCount),
+	}, nil
+}
+
 // GetChartQcRibbons retrieves QC Ribbon data for charting
 // @Summary Get Chart QC Ribbons
-// @Description Retrieve QC Ribbon data for charting
+// @Description Retrieve daily QC Ribbon counts of a month (current month by default), optionally compared with the previous month or the same month of the previous year
 // @Tags Ribbons
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param month query int false "Month (1-12), defaults to the current month"
+// @Param year query int false "Year, defaults to the current year"
+// @Param compare query string false "Compare with previous_month or previous_year"
+// @Param live query bool false "Count the QC records instead of the daily aggregates" default(false)
 // @Success 200 {object} utils.SuccessResponse{data=QcRibbonsDailyCountResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -208,45 +264,44 @@ func (qcrc *QCRibbonController) GetQCRibbon(c fiber.Ctx) error {
 // @Router /api/ribbons/qc-ribbons/chart [get]
 func (qcrc *QCRibbonController) GetChartQCRibbons(c fiber.Ctx) error {
 	log.Println("GetChartQCRibbons called")
-	// Get current month start and end dates
-	now := time.Now()
-	currentYear, currentMonth, _ := now.Date()
-	currentLocation := now.Location()
-
-	// Start of the month
-	startOfMonth := time.Date(currentYear, currentMonth, 1, 0, 0, 0, 0, currentLocation)
-
-	// First day of next month at 00:00:00 (to use as upper bound)
-	startOfNextMonth := startOfMonth.AddDate(0, 1, 0)
-
-	// Query to get daily counts for current month
-	var dailyCounts []QcRibbonDailyCount
-
-	if err := qcrc.DB.Model(&models.QCRibbon{}).Select("DATE(created_at) as date, COUNT(*) as count").Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Group("DATE(created_at)").Order("date ASC").Scan(&dailyCounts).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+	// Parse month, year and compare parameters
+	year, month, compare, err := parseChartMonth(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to retrieve QC Ribbon data",
+			Error:   err.Error(),
 		})
 	}
 
-	// Get total count for the current month
-	var totalCount int64
-	if err := qcrc.DB.Model(&models.QCRibbon{}).Where("created_at >= ? AND created_at < ?", startOfMonth, startOfNextMonth).Count(&totalCount).Error; err != nil {
+	live := c.Query("live", "false") == "true"
+	response, err := qcrc.monthlyQCRibbonCounts(year, month, live)
+	if err != nil {
+		log.Println("GetChartQCRibbons - Failed to retrieve QC Ribbon data:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to retrieve total QC Ribbon count",
+			Error:   "Failed to retrieve QC Ribbon data",
 		})
 	}
 
-	// Format response
-	response := QcRibbonsDailyCountResponse{
-		Month:       currentMonth.String(),
-		Year:        currentYear,
-		DailyCounts: dailyCounts,
-		TotalCount:  int(totalCount),
-	}
+	message := "QC Ribbon chart data " + month.String() + "  " + strconv.Itoa(year) + " retrieved successfully"
 
-	message := "QC Ribbon chart data " + currentMonth.String() + "  " + strconv.Itoa(currentYear) + " retrieved successfully"
+	// Add the compared month when requested
+	if compare != "" {
+		compareYear, compareMonth := chartComparisonMonth(year, month, compare)
+		comparison, err := qcrc.monthlyQCRibbonCounts(compareYear, compareMonth, live)
+		if err != nil {
+			log.Println("GetChartQCRibbons - Failed to retrieve QC Ribbon comparison data:", err)
+			utils.CaptureError(c, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve QC Ribbon data",
+			})
+		}
+		response.Comparison = comparison
+		response.ChangePercent = chartChangePercent(response.TotalCount, comparison.TotalCount)
+		message += " (compared with " + compareMonth.String() + " " + strconv.Itoa(compareYear) + ")"
+	}
 
 	log.Println("GetChartQCRibbons completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
@@ -258,7 +313,7 @@ func (qcrc *QCRibbonController) GetChartQCRibbons(c fiber.Ctx) error {
 
 // QCRibbonStart Starting QC Ribbon processing for an order
 // @Summary Start QC Ribbon Processing
-// @Description Mark an order as in QC Ribbon processing
+// @Description Mark an order as in QC Ribbon processing. The response carries the order with its packing notes: gift message, handling note and item notes
 // @Tags Ribbons
 // @Accept json
 // @Produce json
@@ -294,7 +349,16 @@ func (qcrc *QCRibbonController) QCRibbonStart(c fiber.Ctx) error {
 	}
 
 	// Convert tracking number to uppercase and trim spaces
-	req.TrackingNumber = strings.ToUpper(strings.TrimSpace(req.TrackingNumber))
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
+
+	// Reject scans that do not match the courier format, mistyped numbers are caught here with a suggestion
+	if err := utils.ValidateTrackingNumber(qcrc.DB, req.TrackingNumber); err != nil {
+		log.Println("QCRibbonStart - Tracking number format mismatch:", req.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
 
 	// Duplicate check in QCRibbon
 	var existingQCRibbon models.QCRibbon
@@ -316,6 +380,48 @@ func (qcrc *QCRibbonController) QCRibbonStart(c fiber.Ctx) error {
 		})
 	}
 
+	// Canceled orders must not be packed
+	if order.EventStatus == models.EventStatusCanceled {
+		log.Println("QCRibbonStart - Order is canceled:", req.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with tracking number " + req.TrackingNumber + " has been canceled.",
+		})
+	}
+
+	// Held orders cannot start QC until released
+	if order.EventStatus == "held" {
+		log.Println("QCRibbonStart - Order is on hold:", req.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with tracking number " + req.TrackingNumber + " is on hold: " + order.HoldReason,
+		})
+	}
+
+	// Only users trained for the lane may QC on it
+	missingSkills, err := utils.MissingQCLaneSkills(qcrc.DB, uint(userID), "ribbon")
+	if err != nil {
+		log.Println("QCRibbonStart - Failed to check QC skills:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check QC skills",
+		})
+	}
+	if len(missingSkills) > 0 {
+		log.Println("QCRibbonStart - User lacks QC lane skills:", userID)
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You lack the skills required for ribbon QC: " + utils.SkillNames(missingSkills) + ".",
+		})
+	}
+
+	// Resolve the station the QC is performed at
+	station, err := resolveQCStation(qcrc.DB, req.StationCode, "ribbon", uint(userID))
+	if err != nil {
+		log.Println("QCRibbonStart - Failed to resolve QC station:", err)
+		return qcStationErrorResponse(c, err, req.StationCode)
+	}
+
 	// Start database transaction
 	tx := qcrc.DB.Begin()
 	defer func() {
@@ -328,13 +434,15 @@ func (qcrc *QCRibbonController) QCRibbonStart(c fiber.Ctx) error {
 	qcRibbon := models.QCRibbon{
 		TrackingNumber: req.TrackingNumber,
 		QCBy:           uint(userID),
-		Status:         "in_progress",
+		QCStationID:    qcStationID(station),
+		Status:         models.QCStatusInProgress,
 		Complained:     false,
 	}
 
 	if err := tx.Create(&qcRibbon).Error; err != nil {
 		tx.Rollback()
 		log.Println("QCRibbonStart - Failed to create QC Ribbon record:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to start QC Ribbon processing",
@@ -345,6 +453,7 @@ func (qcrc *QCRibbonController) QCRibbonStart(c fiber.Ctx) error {
 	if err := tx.Save(&order).Error; err != nil {
 		tx.Rollback()
 		log.Println("QCRibbonStart - Failed to update order processing status:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to start QC Ribbon processing",
@@ -354,12 +463,19 @@ func (qcrc *QCRibbonController) QCRibbonStart(c fiber.Ctx) error {
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		log.Println("QCRibbonStart - Failed to commit transaction:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to start QC Ribbon processing",
 		})
 	}
 
+	// Show the packer the gift message, handling note and item notes of the order
+	if err := qcrc.DB.Where("order_id = ?", order.ID).Find(&order.OrderDetails).Error; err != nil {
+		log.Println("QCRibbonStart - Failed to load order details:", err)
+	}
+	qcRibbon.Order = &order
+
 	log.Println("QCRibbonStart completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
 		Success: true,
@@ -370,7 +486,7 @@ func (qcrc *QCRibbonController) QCRibbonStart(c fiber.Ctx) error {
 
 // ValidateQCRibbonProduct validates the QC Ribbon Details items or product by SKU and quantity
 // @Summary Validate QC Ribbon Product
-// @Description Validate the QC Ribbon Details items or product by SKU and quantity
+// @Description Validate one unit of a product by SKU, counted like a scan. Quantities above one are rejected, every unit is scanned on its own. Serialized products need the serial number (IMEI or SN) of the unit
 // @Tags Ribbons
 // @Accept json
 // @Produce json
@@ -380,6 +496,7 @@ func (qcrc *QCRibbonController) QCRibbonStart(c fiber.Ctx) error {
 // @Success 200 {object} utils.SuccessResponse(data=models.QCRibbonResponse)
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/ribbons/qc-ribbons/{id}/validate [put]
 func (qcrc *QCRibbonController) ValidateQCRibbonProduct(c fiber.Ctx) error {
@@ -387,7 +504,7 @@ func (qcrc *QCRibbonController) ValidateQCRibbonProduct(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var qcRibbon models.QCRibbon
-	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
+	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Preload("QCStation").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
 		log.Println("ValidateQCRibbonProduct - QC Ribbon not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -406,7 +523,7 @@ func (qcrc *QCRibbonController) ValidateQCRibbonProduct(c fiber.Ctx) error {
 	}
 
 	// Check if QC Ribbon is in progress or pending
-	if qcRibbon.Status != "in_progress" && qcRibbon.Status != "pending" {
+	if !models.CanTransitionQCStatus(qcRibbon.Status, models.QCStatusCompleted) {
 		log.Println("ValidateQCRibbonProduct - QC Ribbon is not in progress or pending:", qcRibbon.Status)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -415,7 +532,7 @@ func (qcrc *QCRibbonController) ValidateQCRibbonProduct(c fiber.Ctx) error {
 	}
 
 	// If QC Ribbon is pending, check if the user is the one who marked it as pending
-	if qcRibbon.Status == "pending" {
+	if qcRibbon.Status == models.QCStatusPending {
 		userIDStr := c.Locals("userId").(string)
 		userID, err := strconv.ParseUint(userIDStr, 10, 32)
 		if err != nil {
@@ -431,50 +548,27 @@ func (qcrc *QCRibbonController) ValidateQCRibbonProduct(c fiber.Ctx) error {
 		}
 	}
 
-	// Search the target order by tracking number from QC ribbon record
-	var order models.Order
-	if err := qcrc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("tracking_number = ?", qcRibbon.TrackingNumber).First(&order).Error; err != nil {
-		log.Println("ValidateQCRibbonProduct - No order found with tracking number:", qcRibbon.TrackingNumber)
-		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "No order found with tracking number " + qcRibbon.TrackingNumber,
-		})
-	}
-
-	// Find matching order detail by SKU
-	var matchedDetail *models.OrderDetail
-	for i := range order.OrderDetails {
-		if order.OrderDetails[i].SKU == req.SKU {
-			matchedDetail = &order.OrderDetails[i]
-			break
-		}
-	}
-
-	// Check if product SKU exists in order details
-	if matchedDetail == nil {
-		log.Println("ValidateQCRibbonProduct - Product not found in order details:", req.SKU)
+	// Items are counted one unit at a time, so a quantity above one cannot mark the whole line as scanned
+	if req.Quantity != 1 || len(req.SerialNumbers) > 1 {
+		log.Println("ValidateQCRibbonProduct - More than one unit validated at once:", req.SKU)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Product with SKU " + req.SKU + " not found in order details",
+			Error:   "Items are validated one unit at a time, scan each unit of SKU " + req.SKU + " separately",
 		})
 	}
-
-	// Check if quantity matches
-	if matchedDetail.Quantity != req.Quantity {
-		log.Println("ValidateQCRibbonProduct - Quantity mismatch for product:", req.SKU)
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   fmt.Sprintf("Quantity mismatch for SKU %s. Expected: %d, Got: %d", req.SKU, matchedDetail.Quantity, req.Quantity),
-		})
+	serialNumber := ""
+	if len(req.SerialNumbers) == 1 {
+		serialNumber = req.SerialNumbers[0]
 	}
 
-	// Update the is_valid flag to true
-	if err := qcrc.DB.Model(&models.OrderDetail{}).Where("id = ?", matchedDetail.ID).Update("is_valid", true).Error; err != nil {
-		log.Println("ValidateQCRibbonProduct - Failed to update order detail:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Failed to update order detail for product with SKU " + req.SKU,
-		})
+	// Add the unit to the matching order detail like a scan
+	validatorID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err := scanQCItem(qcrc.DB, qcRibbon.TrackingNumber, req.SKU, serialNumber, "ribbon", uint(validatorID)); err != nil {
+		if mismatchType := qcScanMismatchType(err); mismatchType != "" {
+			recordQCMismatch(qcrc.DB, c, "ribbon", qcRibbon.TrackingNumber, qcRibbon.QCStationID, req.SKU, mismatchType, 1)
+		}
+		log.Println("ValidateQCRibbonProduct - Failed to validate product:", err)
+		return qcScanErrorResponse(c, err, qcRibbon.TrackingNumber, req.SKU)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
@@ -486,7 +580,7 @@ func (qcrc *QCRibbonController) ValidateQCRibbonProduct(c fiber.Ctx) error {
 
 // CompleteQcRibbon adding box details and marking QC Ribbon as completed
 // @Summary Complete QC Ribbon
-// @Description Add box details and mark QC Ribbon as completed
+// @Description Add box details and mark QC Ribbon as completed, optionally with a photo of the packed parcel showing its label. Boxes are given by their scanned box code or their ID; boxes already scanned while packing are kept and take the quantity of the request when listed again
 // @Tags Ribbons
 // @Accept json
 // @Produce json
@@ -504,7 +598,7 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var qcRibbon models.QCRibbon
-	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
+	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Preload("QCStation").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
 		log.Println("CompleteQcRibbon - QC Ribbon not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -523,7 +617,7 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 	}
 
 	// Check if QC Ribbon is in progress or pending
-	if qcRibbon.Status != "in_progress" && qcRibbon.Status != "pending" {
+	if !models.CanTransitionQCStatus(qcRibbon.Status, models.QCStatusCompleted) {
 		log.Println("CompleteQcRibbon - QC Ribbon is not in progress or pending:", qcRibbon.Status)
 		return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
 			Success: true,
@@ -543,7 +637,7 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 		})
 	}
 
-	if qcRibbon.Status == "pending" && qcRibbon.QCBy != uint(userID) {
+	if qcRibbon.Status == models.QCStatusPending && qcRibbon.QCBy != uint(userID) {
 		log.Println("CompleteQcRibbon - User is not the one who marked QC Ribbon as pending:", userID)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -569,19 +663,48 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 				Error:   "Order details not validated",
 			})
 		}
-	}
 
-	// Validate all boxes exist and no duplicates
-	boxIDSet := make(map[uint]bool)
-	for _, detailReq := range req.Details {
-		// Check for duplicate box IDs in the request
-		if boxIDSet[detailReq.BoxID] {
+		// Scanned counts must match the expected quantity
+		if detail.ScannedQuantity != detail.Quantity {
+			log.Println("CompleteQcRibbon - Scanned quantity mismatch:", detail.SKU)
 			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 				Success: false,
-				Error:   "Duplicate box ID in the request",
+				Error:   fmt.Sprintf("Scanned quantity mismatch for SKU %s. Expected: %d, Scanned: %d", detail.SKU, detail.Quantity, detail.ScannedQuantity),
 			})
 		}
-		boxIDSet[detailReq.BoxID] = true
+	}
+
+	// Serialized products need the serial number of every unit
+	if err := checkQCSerialsComplete(qcrc.DB, &order); err != nil {
+		log.Println("CompleteQcRibbon - Serial numbers incomplete:", err)
+		return qcSerialErrorResponse(c, err, "Failed to check serial numbers")
+	}
+
+	// Resolve the scanned box codes, or box IDs, and reject duplicates
+	boxIDs, boxQuantities, err := resolveQCBoxDetails(qcrc.DB, req.Details)
+	if err != nil {
+		log.Println("CompleteQcRibbon - Invalid box details:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid box details: " + err.Error(),
+		})
+	}
+	if len(boxIDs) == 0 && len(qcRibbon.QCRibbonDetails) == 0 {
+		log.Println("CompleteQcRibbon - No boxes recorded:", qcRibbon.ID)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No boxes recorded, scan the boxes used or list them in details",
+		})
+	}
+
+	// Validate the optional parcel photo
+	parcelPhoto, err := newQCParcelPhoto("ribbon", qcRibbon.ID, &order, req.ParcelPhoto, uint(userID))
+	if err != nil {
+		log.Println("CompleteQcRibbon - Invalid parcel photo:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid parcel photo: " + err.Error(),
+		})
 	}
 
 	// Start database transaction
@@ -592,39 +715,60 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 		}
 	}()
 
-	// Create QCRibbonDetails records
-	for _, detailReq := range req.Details {
-		qcRibbonDetail := models.QCRibbonDetail{
-			QCRibbonID: qcRibbon.ID,
-			BoxID:      detailReq.BoxID,
-			Quantity:   detailReq.Quantity,
-		}
-		qcRibbon.QCRibbonDetails = append(qcRibbon.QCRibbonDetails, qcRibbonDetail)
+	// Record the boxes of the request, boxes already scanned while packing take the quantity of the request
+	recordedBoxes := make(map[uint]*models.QCRibbonDetail)
+	for i := range qcRibbon.QCRibbonDetails {
+		recordedBoxes[qcRibbon.QCRibbonDetails[i].BoxID] = &qcRibbon.QCRibbonDetails[i]
 	}
-	if err := tx.Create(&qcRibbon.QCRibbonDetails).Error; err != nil {
-		tx.Rollback()
-		log.Println("CompleteQcRibbon - Failed to create QC Ribbon details:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Failed to create QC Ribbon details",
-		})
+	for _, boxID := range boxIDs {
+		var err error
+		if recorded, ok := recordedBoxes[boxID]; ok {
+			recorded.Quantity = boxQuantities[boxID]
+			err = tx.Model(&models.QCRibbonDetail{}).Where("id = ?", recorded.ID).Update("quantity", recorded.Quantity).Error
+		} else {
+			err = tx.Create(&models.QCRibbonDetail{QCRibbonID: qcRibbon.ID, BoxID: boxID, Quantity: boxQuantities[boxID]}).Error
+		}
+		if err != nil {
+			tx.Rollback()
+			log.Println("CompleteQcRibbon - Failed to create QC Ribbon details:", err)
+			utils.CaptureError(c, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to create QC Ribbon details",
+			})
+		}
 	}
 
 	// Update QC Ribbon status to completed
-	qcRibbon.Status = "completed"
+	qcRibbon.Status = models.QCStatusCompleted
 	if err := tx.Save(&qcRibbon).Error; err != nil {
 		tx.Rollback()
 		log.Println("CompleteQcRibbon - Failed to update QC Ribbon status:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to complete QC Ribbon",
 		})
 	}
 
+	// Link the parcel photo to the QC record
+	if parcelPhoto != nil {
+		if err := tx.Create(parcelPhoto).Error; err != nil {
+			tx.Rollback()
+			log.Println("CompleteQcRibbon - Failed to save parcel photo:", err)
+			utils.CaptureError(c, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to save parcel photo",
+			})
+		}
+	}
+
 	// Update order processing status to "qc_completed"
 	if err := tx.Model(&models.Order{}).Where("tracking_number = ?", qcRibbon.TrackingNumber).Update("processing_status", "qc_completed").Error; err != nil {
 		tx.Rollback()
 		log.Println("CompleteQcRibbon - Failed to update order processing status:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to update order processing status",
@@ -634,6 +778,7 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 	// Commit transaction
 	if err := tx.Commit().Error; err != nil {
 		log.Println("CompleteQcRibbon - Failed to commit transaction:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to commit transaction",
@@ -641,8 +786,9 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 	}
 
 	// Reload the updated record with all relationships for response
-	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").First(&qcRibbon, qcRibbon.ID).Error; err != nil {
+	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Preload("QCStation").First(&qcRibbon, qcRibbon.ID).Error; err != nil {
 		log.Println("CompleteQcRibbon - Failed to load updated QC Ribbon:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to load updated QC Ribbon",
@@ -651,6 +797,7 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 
 	// load order by tracking number
 	if err := qcrc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("tracking_number = ?", qcRibbon.TrackingNumber).First(&order).Error; err == nil {
+		utils.ApplyProductNames(qcrc.DB, utils.RequestLanguage(c), order.OrderDetails)
 		qcRibbon.Order = &order
 	}
 
@@ -662,6 +809,130 @@ func (qcrc *QCRibbonController) CompleteQcRibbon(c fiber.Ctx) error {
 	})
 }
 
+// AddQCRibbonBox records a box scanned while packing a QC Ribbon parcel
+// @Summary Add QC Ribbon Box
+// @Description Record a box by its scanned barcode as it is used, before completing the QC Ribbon. Scanning a box already recorded adds to its quantity.
+// @Tags Ribbons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Ribbon ID"
+// @Param box body AddQCBoxRequest true "Scanned box"
+// @Success 200 {object} utils.SuccessResponse{data=models.QCRibbonResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/ribbons/qc-ribbons/{id}/boxes [post]
+func (qcrc *QCRibbonController) AddQCRibbonBox(c fiber.Ctx) error {
+	log.Println("AddQCRibbonBox called")
+	// Get current logged in user from context
+	userID, err := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err != nil {
+		log.Println("AddQCRibbonBox - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var qcRibbon models.QCRibbon
+	if err := qcrc.DB.Preload("QCRibbonDetails").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
+		log.Println("AddQCRibbonBox - QC Ribbon not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Ribbon with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req AddQCBoxRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("AddQCRibbonBox - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Quantity must be greater than 0",
+		})
+	}
+
+	// Boxes can only be added while the QC Ribbon is open
+	if !models.CanTransitionQCStatus(qcRibbon.Status, models.QCStatusCompleted) {
+		log.Println("AddQCRibbonBox - QC Ribbon is not in progress or pending:", qcRibbon.Status)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Ribbon is not in progress or pending",
+		})
+	}
+	if qcRibbon.Status == models.QCStatusPending && qcRibbon.QCBy != uint(userID) {
+		log.Println("AddQCRibbonBox - User is not the one who marked QC Ribbon as pending:", userID)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Only the user who marked the QC Ribbon as pending can add boxes",
+		})
+	}
+
+	box, err := resolveQCBox(qcrc.DB, req.BoxID, req.BoxCode)
+	if err != nil {
+		log.Println("AddQCRibbonBox - Box not found:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid box: " + err.Error(),
+		})
+	}
+
+	// Add to the box when already recorded, otherwise record it
+	quantity := req.Quantity
+	var recorded *models.QCRibbonDetail
+	for i := range qcRibbon.QCRibbonDetails {
+		if qcRibbon.QCRibbonDetails[i].BoxID == box.ID {
+			recorded = &qcRibbon.QCRibbonDetails[i]
+			break
+		}
+	}
+	if recorded != nil {
+		quantity += recorded.Quantity
+		err = qcrc.DB.Model(&models.QCRibbonDetail{}).Where("id = ?", recorded.ID).Update("quantity", quantity).Error
+	} else {
+		err = qcrc.DB.Create(&models.QCRibbonDetail{QCRibbonID: qcRibbon.ID, BoxID: box.ID, Quantity: quantity}).Error
+	}
+	if err != nil {
+		log.Println("AddQCRibbonBox - Failed to record box:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to record box",
+		})
+	}
+
+	// Reload the updated record with all relationships for response
+	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Preload("QCStation").First(&qcRibbon, qcRibbon.ID).Error; err != nil {
+		log.Println("AddQCRibbonBox - Failed to load updated QC Ribbon:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load updated QC Ribbon",
+		})
+	}
+
+	log.Println("AddQCRibbonBox completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Box %s recorded (quantity %d)", box.BoxCode, quantity),
+		Data:    qcRibbon.ToResponse(),
+	})
+}
+
 // PendingQCRibbon marks a QC Ribbon as pending
 // @Summary Pending QC Ribbon
 // @Description Mark a QC Ribbon as pending
@@ -681,7 +952,7 @@ func (qcrc *QCRibbonController) PendingQCRibbon(c fiber.Ctx) error {
 	// Parse id parameter
 	id := c.Params("id")
 	var qcRibbon models.QCRibbon
-	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
+	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Preload("QCStation").Where("id = ?", id).First(&qcRibbon).Error; err != nil {
 		log.Println("PendingQCRibbon - QC Ribbon not found:", err)
 		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
 			Success: false,
@@ -690,7 +961,7 @@ func (qcrc *QCRibbonController) PendingQCRibbon(c fiber.Ctx) error {
 	}
 
 	// Check if QC Ribbon is in progress
-	if qcRibbon.Status != "in_progress" {
+	if !models.CanTransitionQCStatus(qcRibbon.Status, models.QCStatusPending) {
 		log.Println("PendingQCRibbon - QC Ribbon is not in progress:", qcRibbon.Status)
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -699,9 +970,10 @@ func (qcrc *QCRibbonController) PendingQCRibbon(c fiber.Ctx) error {
 	}
 
 	// Update QC Ribbon status to pending
-	qcRibbon.Status = "pending"
+	qcRibbon.Status = models.QCStatusPending
 	if err := qcrc.DB.Save(&qcRibbon).Error; err != nil {
 		log.Println("PendingQCRibbon - Failed to update QC Ribbon status:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to mark QC Ribbon as pending",
@@ -709,8 +981,9 @@ func (qcrc *QCRibbonController) PendingQCRibbon(c fiber.Ctx) error {
 	}
 
 	// Reload the updated record with all relationships for response
-	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").First(&qcRibbon, qcRibbon.ID).Error; err != nil {
+	if err := qcrc.DB.Preload("QCRibbonDetails.Box").Preload("QCUser").Preload("QCStation").First(&qcRibbon, qcRibbon.ID).Error; err != nil {
 		log.Println("PendingQCRibbon - Failed to load updated QC Ribbon:", err)
+		utils.CaptureError(c, err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to load updated QC Ribbon",
@@ -720,6 +993,7 @@ func (qcrc *QCRibbonController) PendingQCRibbon(c fiber.Ctx) error {
 	// Load order by tracking number
 	var order models.Order
 	if err := qcrc.DB.Preload("OrderDetails").Preload("AssignUser").Preload("PickUser").Preload("PendingUser").Preload("ChangeUser").Preload("DuplicateUser").Preload("CancelUser").Where("tracking_number = ?", qcRibbon.TrackingNumber).First(&order).Error; err == nil {
+		utils.ApplyProductNames(qcrc.DB, utils.RequestLanguage(c), order.OrderDetails)
 		qcRibbon.Order = &order
 	}
 
@@ -730,3 +1004,306 @@ func (qcrc *QCRibbonController) PendingQCRibbon(c fiber.Ctx) error {
 		Data:    qcRibbon.ToResponse(),
 	})
 }
+
+// ScanQCRibbonProduct adds a single scanned item to the QC Ribbon validation progress
+// @Summary Scan QC Ribbon Product
+// @Description Scan one item by SKU, the server accumulates scanned vs expected quantity per order detail. Serialized products need the serial number (IMEI or SN) of the unit, which must not be held by another order
+// @Tags Ribbons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Ribbon ID"
+// @Param scan body QCScanRequest true "Scanned SKU"
+// @Success 200 {object} utils.SuccessResponse{data=QCProgressResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/ribbons/qc-ribbons/{id}/scan [put]
+func (qcrc *QCRibbonController) ScanQCRibbonProduct(c fiber.Ctx) error {
+	log.Println("ScanQCRibbonProduct called")
+	// Parse id parameter
+	id := c.Params("id")
+	var qcRibbon models.QCRibbon
+	if err := qcrc.DB.Where("id = ?", id).First(&qcRibbon).Error; err != nil {
+		log.Println("ScanQCRibbonProduct - QC Ribbon not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Ribbon with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req QCScanRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ScanQCRibbonProduct - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	req.SKU = strings.TrimSpace(req.SKU)
+
+	// Check if QC Ribbon is in progress or pending
+	if !models.CanTransitionQCStatus(qcRibbon.Status, models.QCStatusCompleted) {
+		log.Println("ScanQCRibbonProduct - QC Ribbon is not in progress or pending:", qcRibbon.Status)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Ribbon is not in progress or pending",
+		})
+	}
+
+	// Add one scanned item to the matching order detail
+	userID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	if err := scanQCItem(qcrc.DB, qcRibbon.TrackingNumber, req.SKU, req.SerialNumber, "ribbon", uint(userID)); err != nil {
+		if mismatchType := qcScanMismatchType(err); mismatchType != "" {
+			recordQCMismatch(qcrc.DB, c, "ribbon", qcRibbon.TrackingNumber, qcRibbon.QCStationID, req.SKU, mismatchType, 1)
+		}
+		log.Println("ScanQCRibbonProduct - Failed to scan product:", err)
+		return qcScanErrorResponse(c, err, qcRibbon.TrackingNumber, req.SKU)
+	}
+
+	progress, err := buildQCProgress(qcrc.DB, qcRibbon.TrackingNumber)
+	if err != nil {
+		log.Println("ScanQCRibbonProduct - Failed to build scan progress:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve QC Ribbon scan progress",
+		})
+	}
+
+	log.Println("ScanQCRibbonProduct completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product with SKU " + req.SKU + " scanned successfully",
+		Data:    progress,
+	})
+}
+
+// GetQCRibbonProgress retrieves the live scan progress of a QC Ribbon
+// @Summary Get QC Ribbon Progress
+// @Description Retrieve scanned vs expected quantity for each order detail of a QC Ribbon
+// @Tags Ribbons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Ribbon ID"
+// @Success 200 {object} utils.SuccessResponse{data=QCProgressResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/ribbons/qc-ribbons/{id}/progress [get]
+func (qcrc *QCRibbonController) GetQCRibbonProgress(c fiber.Ctx) error {
+	log.Println("GetQCRibbonProgress called")
+	// Parse id parameter
+	id := c.Params("id")
+	var qcRibbon models.QCRibbon
+	if err := qcrc.DB.Where("id = ?", id).First(&qcRibbon).Error; err != nil {
+		log.Println("GetQCRibbonProgress - QC Ribbon not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Ribbon with id " + id + " not found.",
+		})
+	}
+
+	progress, err := buildQCProgress(qcrc.DB, qcRibbon.TrackingNumber)
+	if err != nil {
+		log.Println("GetQCRibbonProgress - No order found with tracking number:", qcRibbon.TrackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + qcRibbon.TrackingNumber,
+		})
+	}
+
+	log.Println("GetQCRibbonProgress completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC Ribbon scan progress retrieved successfully",
+		Data:    progress,
+	})
+}
+
+// VoidQCRibbon voids a QC Ribbon with coordinator approval
+// @Summary Void QC Ribbon
+// @Description Void a mistaken QC Ribbon validation or completion with coordinator approval. The order is reverted to picking_completed, box details are removed and validation progress is reset
+// @Tags Ribbons
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Ribbon ID"
+// @Param void body VoidQCRequest true "Coordinator credentials and void reason"
+// @Success 200 {object} utils.SuccessResponse{data=models.QCVoidResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/ribbons/qc-ribbons/{id}/void [put]
+func (qcrc *QCRibbonController) VoidQCRibbon(c fiber.Ctx) error {
+	log.Println("VoidQCRibbon called")
+
+	// Parse id parameter
+	id := c.Params("id")
+	var qcRibbon models.QCRibbon
+	if err := qcrc.DB.Where("id = ?", id).First(&qcRibbon).Error; err != nil {
+		log.Println("VoidQCRibbon - QC Ribbon not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC Ribbon with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req VoidQCRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("VoidQCRibbon - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		log.Println("VoidQCRibbon - Void reason is required")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Void reason is required",
+		})
+	}
+
+	// Verify coordinator credentials
+	approver, err := utils.VerifyApprover(qcrc.DB, req.Username, req.Password, utils.CoordinatorApprovalRoles)
+	if err != nil {
+		log.Println("VoidQCRibbon - Coordinator approval failed:", err)
+		return approverErrorResponse(c, err)
+	}
+
+	// Get current user ID from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("VoidQCRibbon - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Orders already handed to outbound can no longer be voided
+	var order models.Order
+	if err := qcrc.DB.Where("tracking_number = ?", qcRibbon.TrackingNumber).First(&order).Error; err != nil {
+		log.Println("VoidQCRibbon - No order found with tracking number:", qcRibbon.TrackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + qcRibbon.TrackingNumber,
+		})
+	}
+	if order.ProcessingStatus == "outbound_completed" {
+		log.Println("VoidQCRibbon - Order already outbound completed:", qcRibbon.TrackingNumber)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order " + qcRibbon.TrackingNumber + " has already been processed by outbound",
+		})
+	}
+
+	// Start database transaction
+	tx := qcrc.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Remove box detail rows and the QC Ribbon record so the order can be QC'd again
+	if err := tx.Where("qc_ribbon_id = ?", qcRibbon.ID).Delete(&models.QCRibbonDetail{}).Error; err != nil {
+		tx.Rollback()
+		log.Println("VoidQCRibbon - Failed to delete QC Ribbon details:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete QC Ribbon details",
+		})
+	}
+
+	if err := tx.Where("lane = ? AND qc_id = ?", "ribbon", qcRibbon.ID).Delete(&models.QCParcelPhoto{}).Error; err != nil {
+		tx.Rollback()
+		log.Println("VoidQCRibbon - Failed to delete parcel photo:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete parcel photo",
+		})
+	}
+
+	if err := tx.Delete(&qcRibbon).Error; err != nil {
+		tx.Rollback()
+		log.Println("VoidQCRibbon - Failed to delete QC Ribbon:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to void QC Ribbon",
+		})
+	}
+
+	// Revert order to picking_completed and reset validation flags
+	if err := resetOrderForQCVoid(tx, qcRibbon.TrackingNumber); err != nil {
+		tx.Rollback()
+		log.Println("VoidQCRibbon - Failed to reset order:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reset order processing status",
+		})
+	}
+
+	// Log the void
+	qcVoid := models.QCVoid{
+		Lane:           "ribbon",
+		QCID:           qcRibbon.ID,
+		TrackingNumber: qcRibbon.TrackingNumber,
+		PreviousStatus: string(qcRibbon.Status),
+		QCBy:           qcRibbon.QCBy,
+		QCStationID:    qcRibbon.QCStationID,
+		Reason:         req.Reason,
+		RequestedBy:    uint(userID),
+		ApprovedBy:     approver.ID,
+	}
+	if err := tx.Create(&qcVoid).Error; err != nil {
+		tx.Rollback()
+		log.Println("VoidQCRibbon - Failed to create QC void log:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to log QC void",
+		})
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		log.Println("VoidQCRibbon - Failed to commit transaction:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to commit transaction",
+		})
+	}
+
+	// Reload the void log with all relationships for response
+	if err := qcrc.DB.Preload("QCUser").Preload("RequestUser").Preload("ApproveUser").First(&qcVoid, qcVoid.ID).Error; err != nil {
+		log.Println("VoidQCRibbon - Failed to load QC void log:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load QC void log",
+		})
+	}
+
+	log.Println("VoidQCRibbon completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC Ribbon voided successfully",
+		Data:    qcVoid.ToResponse(),
+	})
+}
@@ -0,0 +1,230 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// QC operations checked in shadow mode
+const (
+	qcShadowScan     = "scan"
+	qcShadowValidate = "validate"
+)
+
+// qcShadowCheck decides a QC request of the lane the way the unified QC service does, without writing anything.
+// It returns the tracking number the request acts on and the result the service would answer with.
+type qcShadowCheck func(db *gorm.DB, lane, id string, body []byte) (string, utils.ShadowResult, error)
+
+var qcShadowChecks = map[string]qcShadowCheck{
+	qcShadowScan:     decideQCScan,
+	qcShadowValidate: decideQCValidate,
+}
+
+// Shadow serves the request with the lane handler. On the sampled share of requests the unified QC service first
+// decides the same request read-only, on the state the handler starts from, and divergences between the two are
+// logged. The shadow never changes the response, its errors and panics are only counted.
+func (qcc *QCController) Shadow(lane, operation string, primary fiber.Handler) fiber.Handler {
+	check := qcShadowChecks[operation]
+	name := lane + "_" + operation
+
+	return func(c fiber.Ctx) error {
+		if check == nil || !utils.ShadowSampled(qcc.ShadowPercent) {
+			return primary(c)
+		}
+
+		// Request strings point into buffers reused after the response, the divergence is stored later
+		id := strings.Clone(c.Params("id"))
+		body := string(c.Body())
+		subject, shadow, ok := runQCShadowCheck(qcc.DB, check, name, lane, id, []byte(body))
+
+		err := primary(c)
+		if !ok {
+			return err
+		}
+
+		primaryStatus, primaryResponse := utils.ShadowPrimaryResponse(c, err)
+		divergence := models.ShadowDivergence{
+			Experiment:      models.ShadowExperimentUnifiedQC,
+			Operation:       name,
+			Subject:         subject,
+			Request:         body,
+			PrimaryStatus:   primaryStatus,
+			PrimaryResponse: primaryResponse,
+			ShadowStatus:    shadow.Status,
+			ShadowOutcome:   shadow.Outcome,
+			ShadowDetail:    shadow.Detail,
+		}
+		if userID, isString := c.Locals("userId").(string); isString {
+			if parsed, parseErr := strconv.ParseUint(userID, 10, 32); parseErr == nil {
+				id := uint(parsed)
+				divergence.UserID = &id
+			}
+		}
+		utils.RecordShadowComparison(qcc.DB, divergence)
+
+		return err
+	}
+}
+
+// runQCShadowCheck runs a shadow check, reporting false when it failed so the request is not compared
+func runQCShadowCheck(db *gorm.DB, check qcShadowCheck, name, lane, id string, body []byte) (subject string, result utils.ShadowResult, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.RecordShadowFailure(models.ShadowExperimentUnifiedQC, name, r)
+			ok = false
+		}
+	}()
+
+	subject, result, err := check(db, lane, id, body)
+	if err != nil {
+		utils.RecordShadowFailure(models.ShadowExperimentUnifiedQC, name, err)
+		return subject, result, false
+	}
+	return subject, result, true
+}
+
+// qcShadowRecord is the QC record of either lane, as the unified QC service reads it
+type qcShadowRecord struct {
+	TrackingNumber string
+	Status         models.QCStatus
+}
+
+// loadQCShadowRecord loads the QC record of the lane, found is false when it does not exist
+func loadQCShadowRecord(db *gorm.DB, lane, id string) (*qcShadowRecord, bool, error) {
+	table := "qc_onlines"
+	if lane == "ribbon" {
+		table = "qc_ribbons"
+	}
+
+	var record qcShadowRecord
+	err := db.Table(table).Select("tracking_number, status").Where("id = ?", id).Take(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &record, true, nil
+}
+
+// loadQCShadowOrder loads the order of a QC record with its details, found is false when it does not exist
+func loadQCShadowOrder(db *gorm.DB, trackingNumber string) (*models.Order, bool, error) {
+	var order models.Order
+	err := db.Preload("OrderDetails").Where("tracking_number = ?", trackingNumber).First(&order).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &order, true, nil
+}
+
+func qcShadowReject(status int, outcome, detail string) utils.ShadowResult {
+	return utils.ShadowResult{Status: status, Outcome: outcome, Detail: detail}
+}
+
+// decideQCScan decides a single item scan: the SKU must be in the order, not fully scanned yet, and come with the
+// serial number of the unit for serialized products
+func decideQCScan(db *gorm.DB, lane, id string, body []byte) (string, utils.ShadowResult, error) {
+	record, found, err := loadQCShadowRecord(db, lane, id)
+	if err != nil {
+		return "", utils.ShadowResult{}, err
+	}
+	if !found {
+		return "", qcShadowReject(fiber.StatusNotFound, "qc_not_found", "QC "+id+" not found"), nil
+	}
+
+	var req QCScanRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, "invalid_request", err.Error()), nil
+	}
+	req.SKU = strings.TrimSpace(req.SKU)
+
+	if !models.CanTransitionQCStatus(record.Status, models.QCStatusCompleted) {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, "not_in_progress", "QC is "+string(record.Status)), nil
+	}
+
+	order, found, err := loadQCShadowOrder(db, record.TrackingNumber)
+	if err != nil {
+		return record.TrackingNumber, utils.ShadowResult{}, err
+	}
+	if !found {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusNotFound, "order_not_found", ""), nil
+	}
+
+	// Substituted items are scanned by the substitute, their ordered SKU is a wrong SKU
+	detail := qcScanDetail(order.OrderDetails, req.SKU)
+	if detail == nil {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, models.QCMismatchWrongSKU, "SKU "+req.SKU), nil
+	}
+
+	serialRequired, err := utils.SerialRequiredSKUs(db, []string{req.SKU})
+	if err != nil {
+		return record.TrackingNumber, utils.ShadowResult{}, err
+	}
+	if serialRequired[req.SKU] && models.NormalizeSerialNumber(req.SerialNumber) == "" {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, "serial_required", "SKU "+req.SKU), nil
+	}
+
+	if detail.ScannedQuantity >= detail.Quantity {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, models.QCMismatchOverScan, fmt.Sprintf("SKU %s scanned %d of %d", req.SKU, detail.ScannedQuantity, detail.Quantity)), nil
+	}
+
+	return record.TrackingNumber, utils.ShadowResult{Status: fiber.StatusOK, Outcome: "scanned"}, nil
+}
+
+// decideQCValidate decides the validation of the full quantity of an order detail at once
+func decideQCValidate(db *gorm.DB, lane, id string, body []byte) (string, utils.ShadowResult, error) {
+	record, found, err := loadQCShadowRecord(db, lane, id)
+	if err != nil {
+		return "", utils.ShadowResult{}, err
+	}
+	if !found {
+		return "", qcShadowReject(fiber.StatusNotFound, "qc_not_found", "QC "+id+" not found"), nil
+	}
+
+	// Both lanes share the same validate request
+	var req ValidateQCRibbonProductRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, "invalid_request", err.Error()), nil
+	}
+
+	if !models.CanTransitionQCStatus(record.Status, models.QCStatusCompleted) {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, "not_in_progress", "QC is "+string(record.Status)), nil
+	}
+
+	order, found, err := loadQCShadowOrder(db, record.TrackingNumber)
+	if err != nil {
+		return record.TrackingNumber, utils.ShadowResult{}, err
+	}
+	if !found {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusNotFound, "order_not_found", ""), nil
+	}
+
+	detail := qcScanDetail(order.OrderDetails, req.SKU)
+	if detail == nil {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, models.QCMismatchWrongSKU, "SKU "+req.SKU), nil
+	}
+	if detail.Quantity != req.Quantity {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, models.QCMismatchQuantityMismatch, fmt.Sprintf("SKU %s expected %d, got %d", req.SKU, detail.Quantity, req.Quantity)), nil
+	}
+
+	serialRequired, err := utils.SerialRequiredSKUs(db, []string{req.SKU})
+	if err != nil {
+		return record.TrackingNumber, utils.ShadowResult{}, err
+	}
+	if serialRequired[req.SKU] && len(req.SerialNumbers) != detail.Quantity {
+		return record.TrackingNumber, qcShadowReject(fiber.StatusBadRequest, "serial_count", fmt.Sprintf("SKU %s expected %d serial numbers, got %d", req.SKU, detail.Quantity, len(req.SerialNumbers))), nil
+	}
+
+	return record.TrackingNumber, utils.ShadowResult{Status: fiber.StatusOK, Outcome: "validated"}, nil
+}
@@ -0,0 +1,478 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type QCStationController struct {
+	DB *gorm.DB
+}
+
+func NewQCStationController(db *gorm.DB) *QCStationController {
+	return &QCStationController{DB: db}
+}
+
+// Request structs
+type CreateQCStationRequest struct {
+	StationCode string `json:"stationCode" validate:"required,min=2,max=50"`
+	Lane        string `json:"lane" validate:"required,oneof=ribbon online"`
+	Printer     string `json:"printer" validate:"omitempty,max=100"`
+}
+
+type UpdateQCStationRequest struct {
+	StationCode string `json:"stationCode" validate:"required,min=2,max=50"`
+	Lane        string `json:"lane" validate:"required,oneof=ribbon online"`
+	Printer     string `json:"printer" validate:"omitempty,max=100"`
+	IsActive    bool   `json:"isActive"`
+}
+
+// GetQCStations retrieves a list of QC stations with pagination and search
+// @Summary Get QC Stations
+// @Description Retrieve a list of QC stations with pagination, search and lane filter, including who currently claims each station
+// @Tags QC Stations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of QC stations per page" default(10)
+// @Param search query string false "Search term for station code or printer"
+// @Param lane query string false "Filter by lane (ribbon, online)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.QCStationResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc-stations [get]
+func (qsc *QCStationController) GetQCStations(c fiber.Ctx) error {
+	log.Println("GetQCStations called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var stations []models.QCStation
+
+	// Build base query
+	query := qsc.DB.Model(&models.QCStation{}).Preload("ClaimUser").Order("station_code ASC")
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("station_code ILIKE ? OR printer ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Lane filter if provided
+	lane := strings.ToLower(strings.TrimSpace(c.Query("lane", "")))
+	if lane != "" {
+		query = query.Where("lane = ?", lane)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&stations).Error; err != nil {
+		log.Println("GetQCStations - Failed to retrieve QC stations:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve QC stations",
+		})
+	}
+
+	// Format response
+	stationList := make([]models.QCStationResponse, len(stations))
+	for i, station := range stations {
+		stationList[i] = *station.ToResponse()
+	}
+
+	// Build success message
+	message := "QC stations retrieved successfully"
+	var filters []string
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if lane != "" {
+		filters = append(filters, "lane: "+lane)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetQCStations completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    stationList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetQCStation retrieves a single QC station by ID
+// @Summary Get QC Station
+// @Description Retrieve a single QC station by ID
+// @Tags QC Stations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Station ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.QCStationResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/qc-stations/{id} [get]
+func (qsc *QCStationController) GetQCStation(c fiber.Ctx) error {
+	log.Println("GetQCStation called")
+	// Parse id parameter
+	id := c.Params("id")
+	var station models.QCStation
+	if err := qsc.DB.Preload("ClaimUser").Where("id = ?", id).First(&station).Error; err != nil {
+		log.Println("GetQCStation - QC station not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetQCStation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC station retrieved successfully",
+		Data:    station.ToResponse(),
+	})
+}
+
+// CreateQCStation creates a new QC station
+// @Summary Create QC Station
+// @Description Create a new QC station working the ribbon or online lane
+// @Tags QC Stations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param station body CreateQCStationRequest true "QC station details"
+// @Success 201 {object} utils.SuccessResponse{data=models.QCStationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc-stations [post]
+func (qsc *QCStationController) CreateQCStation(c fiber.Ctx) error {
+	log.Println("CreateQCStation called")
+	// Binding request body
+	var req CreateQCStationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateQCStation - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Convert station code to uppercase and trim spaces
+	req.StationCode = strings.ToUpper(strings.TrimSpace(req.StationCode))
+	req.Lane = strings.ToLower(strings.TrimSpace(req.Lane))
+	if req.StationCode == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Station code is required",
+		})
+	}
+	if req.Lane != "ribbon" && req.Lane != "online" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid lane. Use ribbon or online.",
+		})
+	}
+
+	// Check for existing station with same code
+	var existingStation models.QCStation
+	if err := qsc.DB.Where("station_code = ?", req.StationCode).First(&existingStation).Error; err == nil {
+		log.Println("CreateQCStation - QC station already exists:", req.StationCode)
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station with code " + req.StationCode + " already exists.",
+		})
+	}
+
+	station := models.QCStation{
+		StationCode: req.StationCode,
+		Lane:        req.Lane,
+		Printer:     strings.TrimSpace(req.Printer),
+		IsActive:    true,
+	}
+	if err := qsc.DB.Create(&station).Error; err != nil {
+		log.Println("CreateQCStation - Failed to create QC station:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create QC station",
+		})
+	}
+
+	log.Println("CreateQCStation completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC station created successfully",
+		Data:    station.ToResponse(),
+	})
+}
+
+// UpdateQCStation updates an existing QC station by ID
+// @Summary Update QC Station
+// @Description Update an existing QC station by ID. Deactivating a station releases its claim.
+// @Tags QC Stations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Station ID"
+// @Param request body UpdateQCStationRequest true "Updated QC station details"
+// @Success 200 {object} utils.SuccessResponse{data=models.QCStationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc-stations/{id} [put]
+func (qsc *QCStationController) UpdateQCStation(c fiber.Ctx) error {
+	log.Println("UpdateQCStation called")
+	// Parse id parameter
+	id := c.Params("id")
+	var station models.QCStation
+	if err := qsc.DB.Where("id = ?", id).First(&station).Error; err != nil {
+		log.Println("UpdateQCStation - QC station not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateQCStationRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateQCStation - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.StationCode = strings.ToUpper(strings.TrimSpace(req.StationCode))
+	req.Lane = strings.ToLower(strings.TrimSpace(req.Lane))
+	if req.StationCode == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Station code is required",
+		})
+	}
+	if req.Lane != "ribbon" && req.Lane != "online" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid lane. Use ribbon or online.",
+		})
+	}
+
+	// Check for existing station with same code (excluding current station)
+	var existingStation models.QCStation
+	if err := qsc.DB.Where("station_code = ? AND id != ?", req.StationCode, id).First(&existingStation).Error; err == nil {
+		log.Println("UpdateQCStation - QC station already exists:", req.StationCode)
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station with code " + req.StationCode + " already exists.",
+		})
+	}
+
+	station.StationCode = req.StationCode
+	station.Lane = req.Lane
+	station.Printer = strings.TrimSpace(req.Printer)
+	station.IsActive = req.IsActive
+	if !station.IsActive {
+		station.ClaimedBy = nil
+		station.ClaimedAt = nil
+	}
+	if err := qsc.DB.Save(&station).Error; err != nil {
+		log.Println("UpdateQCStation - Failed to update QC station:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update QC station",
+		})
+	}
+
+	qsc.DB.Preload("ClaimUser").First(&station, station.ID)
+
+	log.Println("UpdateQCStation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC station updated successfully",
+		Data:    station.ToResponse(),
+	})
+}
+
+// DeleteQCStation deletes a QC station by ID
+// @Summary Delete QC Station
+// @Description Delete a QC station by ID. QC records keep no station once it is deleted.
+// @Tags QC Stations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Station ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc-stations/{id} [delete]
+func (qsc *QCStationController) DeleteQCStation(c fiber.Ctx) error {
+	log.Println("DeleteQCStation called")
+	// Parse id parameter
+	id := c.Params("id")
+	var station models.QCStation
+	if err := qsc.DB.Where("id = ?", id).First(&station).Error; err != nil {
+		log.Println("DeleteQCStation - QC station not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station with id " + id + " not found.",
+		})
+	}
+
+	err := qsc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.QCRibbon{}, &models.QCOnline{}, &models.QCMismatch{}, &models.QCVoid{}} {
+			if err := tx.Model(model).Where("qc_station_id = ?", station.ID).Update("qc_station_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&station).Error
+	})
+	if err != nil {
+		log.Println("DeleteQCStation - Failed to delete QC station:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete QC station",
+		})
+	}
+
+	log.Println("DeleteQCStation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC station deleted successfully",
+	})
+}
+
+// ClaimQCStation claims a QC station for the logged in user
+// @Summary Claim QC Station
+// @Description Claim a QC station for the logged in user, typically right after login. The station the user claimed before is released. QC started afterwards is recorded on the claimed station.
+// @Tags QC Stations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "QC Station ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.QCStationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc-stations/{id}/claim [post]
+func (qsc *QCStationController) ClaimQCStation(c fiber.Ctx) error {
+	log.Println("ClaimQCStation called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ClaimQCStation - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var station models.QCStation
+	if err := qsc.DB.Where("id = ?", id).First(&station).Error; err != nil {
+		log.Println("ClaimQCStation - QC station not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "QC station with id " + id + " not found.",
+		})
+	}
+
+	if err := claimQCStation(qsc.DB, &station, uint(userID)); err != nil {
+		log.Println("ClaimQCStation - Failed to claim QC station:", err)
+		return qcStationErrorResponse(c, err, station.StationCode)
+	}
+
+	qsc.DB.Preload("ClaimUser").First(&station, station.ID)
+
+	log.Println("ClaimQCStation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC station " + station.StationCode + " claimed successfully",
+		Data:    station.ToResponse(),
+	})
+}
+
+// ReleaseQCStation releases the QC station claimed by the logged in user
+// @Summary Release QC Station
+// @Description Release the QC station claimed by the logged in user, typically at logout or shift end
+// @Tags QC Stations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/qc-stations/release [post]
+func (qsc *QCStationController) ReleaseQCStation(c fiber.Ctx) error {
+	log.Println("ReleaseQCStation called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ReleaseQCStation - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var station models.QCStation
+	if err := qsc.DB.Where("claimed_by = ?", uint(userID)).First(&station).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You have not claimed a QC station",
+		})
+	}
+
+	if err := qsc.DB.Model(&station).Updates(map[string]interface{}{
+		"claimed_by": nil,
+		"claimed_at": nil,
+	}).Error; err != nil {
+		log.Println("ReleaseQCStation - Failed to release QC station:", err)
+		utils.CaptureError(c, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to release QC station",
+		})
+	}
+
+	log.Println("ReleaseQCStation completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "QC station " + station.StationCode + " released successfully",
+	})
+}
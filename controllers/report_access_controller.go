@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ReportAccessController struct {
+	DB *gorm.DB
+}
+
+func NewReportAccessController(db *gorm.DB) *ReportAccessController {
+	return &ReportAccessController{DB: db}
+}
+
+// Unique response structs
+// ReportAccessPolicyResponse is a row of the report permission matrix
+type ReportAccessPolicyResponse struct {
+	Report string   `json:"report"`
+	Roles  []string `json:"roles"`
+}
+
+// GetReportAccessPolicies retrieves the report permission matrix
+// @Summary Get Report Access Policies
+// @Description Retrieve the roles allowed to pull each report, sorted by report key
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]ReportAccessPolicyResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /api/reports/access-policies [get]
+func (rac *ReportAccessController) GetReportAccessPolicies(c fiber.Ctx) error {
+	log.Println("GetReportAccessPolicies called")
+	policies := make([]ReportAccessPolicyResponse, 0, len(utils.ReportAccessPolicies))
+	for report, roles := range utils.ReportAccessPolicies {
+		policies = append(policies, ReportAccessPolicyResponse{Report: report, Roles: roles})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Report < policies[j].Report })
+
+	log.Println("GetReportAccessPolicies completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Report access policies retrieved successfully",
+		Data:    policies,
+	})
+}
+
+// GetReportAccessDenials retrieves the audit of denied report access attempts
+// @Summary Get Report Access Denials
+// @Description Retrieve the attempts of users to pull reports their roles are not allowed to access, with pagination, newest first
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of denials per page" default(10)
+// @Param report query string false "Filter by report key"
+// @Param userId query int false "Filter by user ID"
+// @Param startDate query string false "Filter from date (YYYY-MM-DD)"
+// @Param endDate query string false "Filter until date (YYYY-MM-DD)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ReportAccessDenialResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/access-denials [get]
+func (rac *ReportAccessController) GetReportAccessDenials(c fiber.Ctx) error {
+	log.Println("GetReportAccessDenials called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var denials []models.ReportAccessDenial
+
+	// Build base query
+	query := rac.DB.Model(&models.ReportAccessDenial{}).Order("created_at DESC, id DESC").Preload("User")
+
+	var filters []string
+
+	// Filter by report if provided
+	report := strings.TrimSpace(c.Query("report", ""))
+	if report != "" {
+		if _, ok := utils.ReportAccessPolicies[report]; !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid report " + report,
+			})
+		}
+		query = query.Where("report = ?", report)
+		filters = append(filters, "report: "+report)
+	}
+
+	// Filter by user if provided
+	userID, _ := strconv.ParseUint(c.Query("userId", "0"), 10, 32)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+		filters = append(filters, fmt.Sprintf("user: %d", userID))
+	}
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at >= ?", parsedStartDate)
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at < ?", parsedEndDate.AddDate(0, 0, 1))
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&denials).Error; err != nil {
+		log.Println("GetReportAccessDenials - Failed to retrieve report access denials:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve report access denials",
+		})
+	}
+
+	// Format response
+	denialList := make([]models.ReportAccessDenialResponse, len(denials))
+	for i, denial := range denials {
+		denialList[i] = *denial.ToResponse()
+	}
+
+	// Build success message
+	message := "Report access denials retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetReportAccessDenials completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    denialList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
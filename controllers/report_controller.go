@@ -1,10 +1,19 @@
 package controllers
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"livo-fiber-backend/config"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
+	"math"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -14,11 +23,13 @@ import (
 )
 
 type ReportController struct {
-	DB *gorm.DB
+	DB          *gorm.DB
+	Masking     utils.MaskingPolicy
+	OrderVolume utils.OrderVolumePolicy
 }
 
-func NewReportController(db *gorm.DB) *ReportController {
-	return &ReportController{DB: db}
+func NewReportController(cfg *config.Config, db *gorm.DB) *ReportController {
+	return &ReportController{DB: db, Masking: utils.MaskingPolicyFromConfig(cfg), OrderVolume: utils.OrderVolumePolicyFromConfig(cfg)}
 }
 
 // Unique response structs
@@ -39,7 +50,18 @@ type BoxCountReport struct {
 	TotalCount  int              `json:"totalCount"`
 	RibbonCount int              `json:"ribbonCount"`
 	OnlineCount int              `json:"onlineCount"`
-	Details     []BoxUsageDetail `json:"details" gorm:"-"`
+	Details     []BoxUsageDetail `json:"details,omitempty" gorm:"-"`
+}
+
+// BoxReportStreamLine is a single line of the NDJSON box usage report stream.
+// Every box line is followed by the detail lines of that box, the stream ends with an end or error line.
+type BoxReportStreamLine struct {
+	Type   string          `json:"type"` // box, detail, end or error
+	BoxID  uint            `json:"boxId,omitempty"`
+	Box    *BoxCountReport `json:"box,omitempty"`
+	Detail *BoxUsageDetail `json:"detail,omitempty"`
+	Total  int             `json:"total,omitempty"` // number of boxes, on the end line
+	Error  string          `json:"error,omitempty"`
 }
 
 type BoxCountReportsListResponse struct {
@@ -54,8 +76,47 @@ type ReturnReportsListResponse struct {
 	Returns []models.ReturnResponse `json:"returns"`
 }
 
+// ReturnValuationRow is the value and disposition of the items returned through a single channel
+type ReturnValuationRow struct {
+	Channel        string  `json:"channel"`
+	Returns        int64   `json:"returns"`
+	ReturnedItems  int64   `json:"returnedItems"`
+	ReturnedValue  int64   `json:"returnedValue"`
+	RestockedItems int64   `json:"restockedItems"`
+	RestockedValue int64   `json:"restockedValue"`
+	DamagedItems   int64   `json:"damagedItems"`
+	DamagedValue   int64   `json:"damagedValue"`
+	DisposedItems  int64   `json:"disposedItems"`
+	DisposedValue  int64   `json:"disposedValue"`
+	PendingItems   int64   `json:"pendingItems"` // returned items without disposition yet
+	PendingValue   int64   `json:"pendingValue"`
+	OutboundOrders int64   `json:"outboundOrders"`
+	ReturnRate     float64 `json:"returnRate"` // returns per 100 outbound orders
+}
+
+// ReturnValuationResponse represents the returns valuation and disposition report of a period
+type ReturnValuationResponse struct {
+	StartDate string               `json:"startDate"`
+	EndDate   string               `json:"endDate"`
+	Reports   []ReturnValuationRow `json:"reports"`
+	Totals    ReturnValuationRow   `json:"totals"`
+}
+
 type ComplaintReportsListResponse struct {
 	Complaints []models.ComplainResponse `json:"complains"`
+	Agents     []ComplainAgentMetrics    `json:"agents"` // resolution metrics per CS agent over the reported complains
+}
+
+// ComplainAgentMetrics are the complains a CS agent holds and resolved, complains are counted for their current agent
+type ComplainAgentMetrics struct {
+	UserID             uint    `json:"userId"`
+	Agent              string  `json:"agent"`
+	Assigned           int     `json:"assigned"`
+	Open               int     `json:"open"`
+	Resolved           int     `json:"resolved"`
+	ResolutionRate     float64 `json:"resolutionRate"`     // percentage of assigned complains resolved
+	AvgResolutionHours float64 `json:"avgResolutionHours"` // from filing to resolution
+	ReassignedAway     int64   `json:"reassignedAway"`     // complains reassigned from the agent to another
 }
 
 type ComplainDetailInReport struct {
@@ -65,6 +126,8 @@ type ComplainDetailInReport struct {
 	OrderGineeID      string `json:"orderGineeId"`
 	FeeCharge         uint   `json:"feeCharge"`
 	ComplainUpdatedAt string `json:"complainUpdatedAt"`
+	DisputeStatus     string `json:"disputeStatus,omitempty"`    // status of the latest fee dispute, empty when the fee was not disputed
+	SettlementPeriod  string `json:"settlementPeriod,omitempty"` // period of the settlement freezing the fee, empty while unsettled
 }
 
 type UserFeeReportWithDetails struct {
@@ -74,6 +137,8 @@ type UserFeeReportWithDetails struct {
 	Email           string                   `json:"email"`
 	TotalComplaints int                      `json:"totalComplaints"`
 	TotalFeeCharge  uint                     `json:"totalFeeCharge"`
+	UnsettledFee    uint                     `json:"unsettledFee"` // part of the total fee not deducted by a settlement yet
+	OpenFeeDisputes int                      `json:"openFeeDisputes"`
 	ComplainDetails []ComplainDetailInReport `json:"complainDetails"`
 }
 
@@ -81,111 +146,483 @@ type UserFeeReportsWithDetailsListResponse struct {
 	Reports []UserFeeReportWithDetails `json:"reports"`
 }
 
-// BuildBoxUsageDetails retrieves detailed usage for a specific box
-func (rc *ReportController) BuildBoxUsageDetails(boxID uint, startDate, endDate string) []BoxUsageDetail {
-	log.Println("BuildBoxUsageDetails called")
-	var details []BoxUsageDetail
+// BillingReportRow represents the billing summary of a single channel/store and currency for a month
+type BillingReportRow struct {
+	Channel             string `json:"channel"`
+	Store               string `json:"store"`
+	Currency            string `json:"currency"`
+	ShippedOrders       int64  `json:"shippedOrders"`
+	TotalItems          int64  `json:"totalItems"`
+	TotalValue          int64  `json:"totalValue"`
+	Complaints          int64  `json:"complaints"`
+	ComplaintDeductions int64  `json:"complaintDeductions"`
+	NetValue            int64  `json:"netValue"`
+}
 
-	// Query from QCRibbonDetail with joins
-	type RibbonResult struct {
-		TrackingNumber string
-		OrderGineeID   string
-		BoxName        string
-		Quantity       int
-		FullName       string
-		CreatedAt      time.Time
-	}
+// BillingReportResponse represents the monthly billing report per channel/store
+type BillingReportResponse struct {
+	Month   string             `json:"month"`
+	Reports []BillingReportRow `json:"reports"`
+	Totals  BillingReportRow   `json:"totals"`
+}
 
-	var ribbonResults []RibbonResult
-	ribbonQuery := rc.DB.Table("qc_ribbon_details").
-		Select("qc_ribbons.tracking_number, orders.order_ginee_id, boxes.box_name, qc_ribbon_details.quantity, users.full_name, qc_ribbons.created_at").
-		Joins("LEFT JOIN qc_ribbons ON qc_ribbons.id = qc_ribbon_details.qc_ribbon_id").
-		Joins("LEFT JOIN boxes ON boxes.id = qc_ribbon_details.box_id").
-		Joins("LEFT JOIN users ON users.id = qc_ribbons.qc_by").
-		Joins("LEFT JOIN orders ON orders.tracking_number = qc_ribbons.tracking_number").
-		Where("qc_ribbon_details.box_id = ?", boxID)
+// OrderReconciliationRow reconciles the value of the orders received in a month for a single channel/store and currency
+type OrderReconciliationRow struct {
+	Channel        string `json:"channel"`
+	Store          string `json:"store"`
+	Currency       string `json:"currency"`
+	Orders         int64  `json:"orders"`
+	OrderedValue   int64  `json:"orderedValue"`
+	ShippedOrders  int64  `json:"shippedOrders"`
+	ShippedValue   int64  `json:"shippedValue"`
+	CanceledOrders int64  `json:"canceledOrders"`
+	CanceledValue  int64  `json:"canceledValue"`
+	OpenOrders     int64  `json:"openOrders"`
+	OpenValue      int64  `json:"openValue"`
+}
 
-	// Apply date filters for ribbon
-	if startDate != "" {
-		ribbonQuery = ribbonQuery.Where("qc_ribbons.created_at >= ?", startDate+" 00:00:00")
-	}
-	if endDate != "" {
-		ribbonQuery = ribbonQuery.Where("qc_ribbons.created_at <= ?", endDate+" 23:59:59")
-	}
+// OrderReconciliationResponse represents the monthly order value reconciliation per channel/store
+type OrderReconciliationResponse struct {
+	Month   string                   `json:"month"`
+	Reports []OrderReconciliationRow `json:"reports"`
+	Totals  OrderReconciliationRow   `json:"totals"`
+}
 
-	ribbonQuery.Scan(&ribbonResults)
+// ShortageAgingBucket counts shortages by how long they have been open
+type ShortageAgingBucket struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
 
-	// Add ribbon results to details
-	for _, r := range ribbonResults {
-		details = append(details, BoxUsageDetail{
-			TrackingNumber: r.TrackingNumber,
-			OrderGineeID:   r.OrderGineeID,
-			BoxName:        r.BoxName,
-			Quantity:       r.Quantity,
-			QcBy:           r.FullName,
-			CreatedAt:      r.CreatedAt.Format("02-01-2006 15:04:05"),
-			Source:         "ribbon",
-		})
-	}
+// ShortageReportResponse represents pick shortages with their aging summary
+type ShortageReportResponse struct {
+	Aging     []ShortageAgingBucket         `json:"aging"`
+	Shortages []models.PickShortageResponse `json:"shortages"`
+}
 
-	// Query from QCOnlineDetail with joins
-	type OnlineResult struct {
-		TrackingNumber string
-		OrderGineeID   string
-		BoxName        string
-		Quantity       int
-		FullName       string
-		CreatedAt      time.Time
+// BackorderAgingRow represents the backorders of a SKU with the supply that can fulfill them
+type BackorderAgingRow struct {
+	SKU              string  `json:"sku"`
+	ProductName      string  `json:"productName"`
+	WaitingOrders    int     `json:"waitingOrders"`
+	ShortQuantity    int     `json:"shortQuantity"`
+	StockOnHand      int     `json:"stockOnHand"`
+	IncomingQuantity int     `json:"incomingQuantity"` // expected on open inbound shipments
+	OldestAgingHours float64 `json:"oldestAgingHours"`
+	OldestDeclaredAt string  `json:"oldestDeclaredAt"`
+	Restocked        int     `json:"restocked"`       // backorders fulfilled by received stock in the period
+	AvgRestockHours  float64 `json:"avgRestockHours"` // average wait of the restocked backorders
+}
+
+// BackorderReportResponse represents the backorders per SKU with their aging summary
+type BackorderReportResponse struct {
+	StartDate string                `json:"startDate"`
+	EndDate   string                `json:"endDate"`
+	Aging     []ShortageAgingBucket `json:"aging"`
+	SKUs      []BackorderAgingRow   `json:"skus"`
+}
+
+// PickerPerformanceRow represents the picking output, attributed complaints and retraining flag of a picker
+type PickerPerformanceRow struct {
+	UserID            uint    `json:"userId"`
+	Username          string  `json:"username"`
+	FullName          string  `json:"fullName"`
+	PickedOrders      int64   `json:"pickedOrders"`
+	Shortages         int64   `json:"shortages"`  // shortages declared while picking
+	Complaints        int64   `json:"complaints"` // complaints attributed to the picker
+	ComplaintRate     float64 `json:"complaintRate"`
+	RetrainingFlagged bool    `json:"retrainingFlagged"`
+	TrainingTaskID    *uint   `json:"trainingTaskId,omitempty"` // open training task
+	FlaggedAt         *string `json:"flaggedAt,omitempty"`
+}
+
+// PickerPerformanceReportResponse represents the performance of every picker over a date range
+type PickerPerformanceReportResponse struct {
+	StartDate string                 `json:"startDate"`
+	EndDate   string                 `json:"endDate"`
+	Pickers   []PickerPerformanceRow `json:"pickers"`
+}
+
+// DataQualityOrderRow represents an order with suspicious data and its issues
+type DataQualityOrderRow struct {
+	OrderID        uint                     `json:"orderId"`
+	OrderGineeID   string                   `json:"orderGineeId"`
+	TrackingNumber string                   `json:"trackingNumber"`
+	Channel        string                   `json:"channel"`
+	Store          string                   `json:"store"`
+	Courier        string                   `json:"courier"`
+	Buyer          string                   `json:"buyer"`
+	OrderSource    string                   `json:"orderSource"`
+	CreatedAt      string                   `json:"createdAt"`
+	Issues         []utils.DataQualityIssue `json:"issues"`
+}
+
+// DataQualityIssueCount counts the orders with a data quality issue
+type DataQualityIssueCount struct {
+	Type   string `json:"type"`
+	Orders int64  `json:"orders"`
+}
+
+// DataQualityReportResponse represents the orders with suspicious data of a date range with the count per issue
+type DataQualityReportResponse struct {
+	StartDate string                  `json:"startDate"`
+	EndDate   string                  `json:"endDate"`
+	Checked   int64                   `json:"checked"` // orders checked in the date range
+	ByIssue   []DataQualityIssueCount `json:"byIssue"`
+	Orders    []DataQualityOrderRow   `json:"orders"`
+}
+
+// PickAnomalyPickerCount counts the pick anomalies of a picker
+type PickAnomalyPickerCount struct {
+	UserID   uint   `json:"userId"`
+	FullName string `json:"fullName"`
+	Fast     int64  `json:"fast"`
+	Slow     int64  `json:"slow"`
+	Total    int64  `json:"total"`
+}
+
+// PickAnomalyReportResponse represents the pick anomalies of a date range with their count per picker
+type PickAnomalyReportResponse struct {
+	StartDate string                       `json:"startDate"`
+	EndDate   string                       `json:"endDate"`
+	ByPicker  []PickAnomalyPickerCount     `json:"byPicker"`
+	Anomalies []models.PickAnomalyResponse `json:"anomalies"`
+}
+
+// CourierWeightRow represents the weight measured at the outbound scan of a parcel against the weight the courier invoiced
+type CourierWeightRow struct {
+	OutboundID            uint     `json:"outboundId"`
+	TrackingNumber        string   `json:"trackingNumber"`
+	Expedition            string   `json:"expedition"`
+	OutboundAt            string   `json:"outboundAt"`
+	WeightGrams           *int     `json:"weightGrams,omitempty"`
+	LengthCm              *float64 `json:"lengthCm,omitempty"`
+	WidthCm               *float64 `json:"widthCm,omitempty"`
+	HeightCm              *float64 `json:"heightCm,omitempty"`
+	VolumetricWeightGrams *int     `json:"volumetricWeightGrams,omitempty"`
+	ChargeableWeightGrams *int     `json:"chargeableWeightGrams,omitempty"` // higher of the measured and volumetric weight
+	InvoicedWeightGrams   int      `json:"invoicedWeightGrams"`
+	InvoicedAmount        *int     `json:"invoicedAmount,omitempty"`
+	InvoiceRef            string   `json:"invoiceRef"`
+	DifferenceWeightGrams *int     `json:"differenceWeightGrams,omitempty"` // invoiced minus chargeable weight
+	Status                string   `json:"status"`                          // overbilled, ok or unmeasured
+}
+
+// CourierWeightSummary represents the totals of the courier weight audit
+type CourierWeightSummary struct {
+	InvoicedParcels   int64 `json:"invoicedParcels"`
+	MeasuredParcels   int64 `json:"measuredParcels"`
+	OverbilledParcels int64 `json:"overbilledParcels"`
+	ExcessWeightGrams int64 `json:"excessWeightGrams"` // invoiced weight above the chargeable weight of overbilled parcels
+	OverbilledAmount  int64 `json:"overbilledAmount"`  // invoiced amount of overbilled parcels
+}
+
+// CourierWeightReportResponse represents the courier weight audit
+type CourierWeightReportResponse struct {
+	ToleranceGrams int                  `json:"toleranceGrams"`
+	Summary        CourierWeightSummary `json:"summary"`
+	Parcels        []CourierWeightRow   `json:"parcels"`
+}
+
+// SLABreachStageCount represents the number of breaches of orders stuck in a stage
+type SLABreachStageCount struct {
+	Stage  string `json:"stage"`
+	Reason string `json:"reason"`
+	Count  int64  `json:"count"`
+}
+
+// SLABreachReportResponse represents SentBefore breaches with their count per stage
+type SLABreachReportResponse struct {
+	ByStage  []SLABreachStageCount      `json:"byStage"`
+	Breaches []models.SLABreachResponse `json:"breaches"`
+}
+
+// NearExpiryReportResponse represents lots expiring within the report window
+type NearExpiryReportResponse struct {
+	Days            int                             `json:"days"`
+	ExpiredQuantity int                             `json:"expiredQuantity"`
+	NearQuantity    int                             `json:"nearQuantity"`
+	Batches         []models.InventoryBatchResponse `json:"batches"`
+}
+
+// ErrorHotspotTrendPoint counts error events of a hotspot in a single period
+type ErrorHotspotTrendPoint struct {
+	Period        string `json:"period"`
+	Complaints    int64  `json:"complaints"`
+	QCMismatches  int64  `json:"qcMismatches"`
+	PickAnomalies int64  `json:"pickAnomalies"`
+}
+
+// ErrorHotspotRow aggregates complaint, QC mismatch and pick anomaly events of a SKU, variant or rack location
+type ErrorHotspotRow struct {
+	Key                string                   `json:"key"`
+	ProductName        string                   `json:"productName,omitempty"`
+	Variant            string                   `json:"variant,omitempty"`
+	Location           string                   `json:"location,omitempty"`
+	SKUs               []string                 `json:"skus"`
+	Complaints         int64                    `json:"complaints"`
+	ComplaintQuantity  int64                    `json:"complaintQuantity"`
+	WrongSKUs          int64                    `json:"wrongSkus"`
+	QuantityMismatches int64                    `json:"quantityMismatches"`
+	OverScans          int64                    `json:"overScans"`
+	FastPicks          int64                    `json:"fastPicks"` // items picked too fast to have been verified
+	SlowPicks          int64                    `json:"slowPicks"` // items that took unusually long to locate
+	Total              int64                    `json:"total"`
+	Trend              []ErrorHotspotTrendPoint `json:"trend"`
+}
+
+// ErrorHotspotReportResponse represents the ranked error hotspots of a date range
+type ErrorHotspotReportResponse struct {
+	StartDate   string            `json:"startDate"`
+	EndDate     string            `json:"endDate"`
+	GroupBy     string            `json:"groupBy"`
+	Granularity string            `json:"granularity"`
+	Hotspots    []ErrorHotspotRow `json:"hotspots"`
+}
+
+// QCStationReportRow represents the throughput and errors of a single QC station
+type QCStationReportRow struct {
+	StationID    *uint   `json:"stationId"` // nil for QC performed without a station
+	StationCode  string  `json:"stationCode"`
+	Lane         string  `json:"lane"`
+	Printer      string  `json:"printer"`
+	TotalQCs     int64   `json:"totalQcs"`
+	CompletedQCs int64   `json:"completedQcs"`
+	QCUsers      int     `json:"qcUsers"`
+	ActiveHours  int     `json:"activeHours"`  // hours with at least one QC started
+	PerHour      float64 `json:"perHour"`      // QCs started per active hour
+	Mismatches   int64   `json:"mismatches"`   // mispicks caught at the station
+	Voids        int64   `json:"voids"`        // QCs voided by a coordinator
+	Complaints   int64   `json:"complaints"`   // QCs of parcels complained about later
+	ErrorRate    float64 `json:"errorRate"`    // voids and complaints per 100 QCs
+	MismatchRate float64 `json:"mismatchRate"` // mismatches per 100 QCs
+}
+
+// QCStationReportResponse represents the per-station QC metrics of a date range
+type QCStationReportResponse struct {
+	StartDate string               `json:"startDate"`
+	EndDate   string               `json:"endDate"`
+	Stations  []QCStationReportRow `json:"stations"`
+}
+
+// OutboundForecastStages represents how many pipeline packages of an expedition are in each stage
+type OutboundForecastStages struct {
+	WaitingPick int `json:"waitingPick"` // ready to pick, not assigned yet
+	Picking     int `json:"picking"`     // picking in progress or pending
+	Picked      int `json:"picked"`      // picking completed, waiting for QC
+	InQC        int `json:"inQc"`
+	Ready       int `json:"ready"` // QC completed, waiting for outbound
+}
+
+// OutboundForecastRow represents the expected pickup volume of a single expedition
+type OutboundForecastRow struct {
+	Expedition       string                 `json:"expedition"`
+	ExpeditionSlug   string                 `json:"expeditionSlug"`
+	ExpeditionColor  string                 `json:"expeditionColor"`
+	CutOffTime       string                 `json:"cutOffTime"` // HH:MM, end of day when not configured
+	MinutesToCutOff  int                    `json:"minutesToCutOff"`
+	ShippedToday     int64                  `json:"shippedToday"`
+	InPipeline       int                    `json:"inPipeline"`
+	ExpectedByCutOff int                    `json:"expectedByCutOff"` // ready now plus packages forecast to finish QC before the cut-off
+	AtRisk           int                    `json:"atRisk"`           // in the pipeline but not expected before the cut-off
+	Stages           OutboundForecastStages `json:"stages"`
+}
+
+// OutboundForecastResponse represents today's expected pickup volumes per expedition
+type OutboundForecastResponse struct {
+	GeneratedAt        string                `json:"generatedAt"`
+	AverageWaitMinutes float64               `json:"averageWaitMinutes"` // order creation to picker assignment
+	AveragePickMinutes float64               `json:"averagePickMinutes"` // assignment to completed pick
+	AverageQCMinutes   float64               `json:"averageQcMinutes"`   // QC start to QC completed
+	Expeditions        []OutboundForecastRow `json:"expeditions"`
+	TotalExpected      int                   `json:"totalExpected"`
+	TotalAtRisk        int                   `json:"totalAtRisk"`
+}
+
+// CancelInconsistencyRow represents a record left behind by a canceled order
+type CancelInconsistencyRow struct {
+	Type           string `json:"type"` // qc_ribbon, qc_online, outbound or order_detail
+	RecordID       uint   `json:"recordId"`
+	OrderID        uint   `json:"orderId"`
+	OrderGineeID   string `json:"orderGineeId"`
+	TrackingNumber string `json:"trackingNumber"`
+	Status         string `json:"status,omitempty"`
+	Issue          string `json:"issue"`
+	CanceledAt     string `json:"canceledAt,omitempty"`
+}
+
+// CancelInconsistencyReportResponse represents the records of canceled orders that still look active
+type CancelInconsistencyReportResponse struct {
+	Summary map[string]int           `json:"summary"`
+	Records []CancelInconsistencyRow `json:"records"`
+}
+
+// ComplainRootCauseTrendPoint counts the complains of a root cause filed in a week
+type ComplainRootCauseTrendPoint struct {
+	Week       string `json:"week"` // Monday the week starts on (YYYY-MM-DD)
+	Complaints int64  `json:"complaints"`
+}
+
+// ComplainRootCauseTrendRow is the weekly complains of a single root cause
+type ComplainRootCauseTrendRow struct {
+	RootCauseID *uint                         `json:"rootCauseId"`
+	Code        string                        `json:"code"`
+	Name        string                        `json:"name"`
+	Complaints  int64                         `json:"complaints"`
+	Share       float64                       `json:"share"` // percentage of all complains in the range
+	Trend       []ComplainRootCauseTrendPoint `json:"trend"`
+}
+
+// ComplainRootCauseTrendResponse represents the complains per root cause per week of a date range
+type ComplainRootCauseTrendResponse struct {
+	StartDate  string                      `json:"startDate"`
+	EndDate    string                      `json:"endDate"`
+	Weeks      []string                    `json:"weeks"`
+	RootCauses []ComplainRootCauseTrendRow `json:"rootCauses"`
+}
+
+// ComplainRepeatBuyerRow is a buyer complaining on several orders
+type ComplainRepeatBuyerRow struct {
+	Buyer           string   `json:"buyer"`
+	Complaints      int64    `json:"complaints"`
+	Orders          int64    `json:"orders"`
+	Reopens         int64    `json:"reopens"`
+	TrackingNumbers []string `json:"trackingNumbers"`
+	RootCauses      []string `json:"rootCauses"`
+	FirstComplaint  string   `json:"firstComplaint"`
+	LastComplaint   string   `json:"lastComplaint"`
+}
+
+// ComplainRepeatTrackingRow is a tracking number complained about again after its complain was resolved
+type ComplainRepeatTrackingRow struct {
+	TrackingNumber string `json:"trackingNumber"`
+	ComplainCode   string `json:"complainCode"`
+	Buyer          string `json:"buyer"`
+	RootCause      string `json:"rootCause"`
+	Complaints     int    `json:"complaints"` // the complain and its reopens
+	Reopens        int    `json:"reopens"`
+	CreatedAt      string `json:"createdAt"`
+	LastReopenedAt string `json:"lastReopenedAt,omitempty"`
+}
+
+// ComplainRepeatOffenderResponse represents the buyers and tracking numbers with repeated complaints of a date range
+type ComplainRepeatOffenderResponse struct {
+	StartDate       string                      `json:"startDate"`
+	EndDate         string                      `json:"endDate"`
+	MinComplaints   int                         `json:"minComplaints"`
+	Buyers          []ComplainRepeatBuyerRow    `json:"buyers"`
+	TrackingNumbers []ComplainRepeatTrackingRow `json:"trackingNumbers"`
+}
+
+// unclassifiedRootCauseCode labels the trend of complains filed without a root cause
+const unclassifiedRootCauseCode = "unclassified"
+
+// boxUsageDetailBatchSize is the number of usage detail rows read per query when iterating a box's usage
+const boxUsageDetailBatchSize = 1000
+
+// boxUsageSources are the QC detail tables a box usage is recorded in
+var boxUsageSources = []struct {
+	Source      string
+	DetailTable string
+	QCTable     string
+	QCKey       string
+}{
+	{Source: "ribbon", DetailTable: "qc_ribbon_details", QCTable: "qc_ribbons", QCKey: "qc_ribbon_id"},
+	{Source: "online", DetailTable: "qc_online_details", QCTable: "qc_onlines", QCKey: "qc_online_id"},
+}
+
+// boxUsageDetailRow is a single box usage read from one of the QC detail tables
+type boxUsageDetailRow struct {
+	DetailID       uint
+	TrackingNumber string
+	OrderGineeID   string
+	BoxName        string
+	Quantity       int
+	FullName       string
+	CreatedAt      time.Time
+	Source         string
+}
+
+// toDetail converts the row to the box usage detail returned in reports
+func (r boxUsageDetailRow) toDetail() BoxUsageDetail {
+	return BoxUsageDetail{
+		TrackingNumber: r.TrackingNumber,
+		OrderGineeID:   r.OrderGineeID,
+		BoxName:        r.BoxName,
+		Quantity:       r.Quantity,
+		QcBy:           r.FullName,
+		CreatedAt:      r.CreatedAt.Format("02-01-2006 15:04:05"),
+		Source:         r.Source,
 	}
+}
 
-	var onlineResults []OnlineResult
-	onlineQuery := rc.DB.Table("qc_online_details").
-		Select("qc_onlines.tracking_number, orders.order_ginee_id, boxes.box_name, qc_online_details.quantity, users.full_name, qc_onlines.created_at").
-		Joins("LEFT JOIN qc_onlines ON qc_onlines.id = qc_online_details.qc_online_id").
-		Joins("LEFT JOIN boxes ON boxes.id = qc_online_details.box_id").
-		Joins("LEFT JOIN users ON users.id = qc_onlines.qc_by").
-		Joins("LEFT JOIN orders ON orders.tracking_number = qc_onlines.tracking_number").
-		Where("qc_online_details.box_id = ?", boxID)
+// boxUsageDetailQuery builds the query of the usages of a box recorded in one QC detail table within the date range
+func (rc *ReportController) boxUsageDetailQuery(ctx context.Context, sourceIndex int, boxID uint, startDate, endDate string) *gorm.DB {
+	source := boxUsageSources[sourceIndex]
+	query := rc.DB.WithContext(ctx).Table(source.DetailTable).
+		Select(fmt.Sprintf("%[1]s.id as detail_id, %[2]s.tracking_number, orders.order_ginee_id, boxes.box_name, %[1]s.quantity, users.full_name, %[2]s.created_at, '%[3]s' as source", source.DetailTable, source.QCTable, source.Source)).
+		Joins(fmt.Sprintf("LEFT JOIN %[2]s ON %[2]s.id = %[1]s.%[3]s", source.DetailTable, source.QCTable, source.QCKey)).
+		Joins(fmt.Sprintf("LEFT JOIN boxes ON boxes.id = %s.box_id", source.DetailTable)).
+		Joins(fmt.Sprintf("LEFT JOIN users ON users.id = %s.qc_by", source.QCTable)).
+		Joins(fmt.Sprintf("LEFT JOIN orders ON orders.tracking_number = %s.tracking_number", source.QCTable)).
+		Where(source.DetailTable+".box_id = ?", boxID)
 
-	// Apply date filters for online
+	// Apply date filters
 	if startDate != "" {
-		onlineQuery = onlineQuery.Where("qc_onlines.created_at >= ?", startDate+" 00:00:00")
+		query = query.Where(source.QCTable+".created_at >= ?", startDate+" 00:00:00")
 	}
 	if endDate != "" {
-		onlineQuery = onlineQuery.Where("qc_onlines.created_at <= ?", endDate+" 23:59:59")
+		query = query.Where(source.QCTable+".created_at <= ?", endDate+" 23:59:59")
 	}
+	return query
+}
 
-	onlineQuery.Scan(&onlineResults)
+// EachBoxUsageDetail calls fn for every usage of a specific box, reading the rows in keyset batches
+// so long date ranges never hold the whole usage of a box in memory
+func (rc *ReportController) EachBoxUsageDetail(ctx context.Context, boxID uint, startDate, endDate string, fn func(BoxUsageDetail) error) error {
+	for i, source := range boxUsageSources {
+		var lastID uint
+		for {
+			var results []boxUsageDetailRow
+			query := rc.boxUsageDetailQuery(ctx, i, boxID, startDate, endDate).
+				Where(source.DetailTable+".id > ?", lastID).
+				Order(source.DetailTable + ".id ASC").
+				Limit(boxUsageDetailBatchSize)
+			if err := query.Scan(&results).Error; err != nil {
+				return err
+			}
 
-	// Add online results to details
-	for _, r := range onlineResults {
-		details = append(details, BoxUsageDetail{
-			TrackingNumber: r.TrackingNumber,
-			OrderGineeID:   r.OrderGineeID,
-			BoxName:        r.BoxName,
-			Quantity:       r.Quantity,
-			QcBy:           r.FullName,
-			CreatedAt:      r.CreatedAt.Format("02-01-2006 15:04:05"),
-			Source:         "online",
-		})
-	}
+			for _, r := range results {
+				if err := fn(r.toDetail()); err != nil {
+					return err
+				}
+			}
 
-	return details
+			if len(results) < boxUsageDetailBatchSize {
+				break
+			}
+			lastID = results[len(results)-1].DetailID
+		}
+	}
+	return nil
 }
 
 // GetBoxReports generates box usage reports
 // @Summary Get Box Usage Reports
-// @Description Generate box usage reports with optional filters
+// @Description Generate the box usage summary with optional filters. The usage details of a box are retrieved with the box details endpoint, or streamed together with the summary in ndjson format
 // @Tags Reports
 // @Accept json
 // @Produce json
+// @Produce application/x-ndjson
 // @Security BearerAuth
 // @Param startDate query string false "Filter by start date (YYYY-MM-DD format)"
 // @Param endDate query string false "Filter by end date (YYYY-MM-DD format)"
 // @Param boxName query string false "Filter term for box name"
+// @Param format query string false "Response format (json or ndjson). ndjson streams one line per box and per usage detail" default(json)
 // @Success 200 {object} utils.SuccessTotaledResponse{data=[]BoxCountReportsListResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/reports/boxes [get]
 func (rc *ReportController) GetBoxReports(c fiber.Ctx) error {
@@ -194,9 +631,17 @@ func (rc *ReportController) GetBoxReports(c fiber.Ctx) error {
 	startDate := c.Query("startDate", "")
 	endDate := c.Query("endDate", "")
 	boxName := c.Query("boxName", "")
+	format := strings.ToLower(c.Query("format", "json"))
+
+	if format != "json" && format != "ndjson" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or ndjson.",
+		})
+	}
 
 	// Build subquery for ribbon counts
-	ribbonCountSubquery := rc.DB.Table("qc_ribbon_details").
+	ribbonCountSubquery := rc.DB.WithContext(c.Context()).Table("qc_ribbon_details").
 		Select("qc_ribbon_details.box_id, COALESCE(SUM(qc_ribbon_details.quantity), 0) as ribbon_count").
 		Joins("LEFT JOIN qc_ribbons ON qc_ribbons.id = qc_ribbon_details.qc_ribbon_id")
 
@@ -211,7 +656,7 @@ func (rc *ReportController) GetBoxReports(c fiber.Ctx) error {
 	ribbonCountSubquery = ribbonCountSubquery.Group("qc_ribbon_details.box_id")
 
 	// Build subquery for online counts
-	onlineCountSubquery := rc.DB.Table("qc_online_details").
+	onlineCountSubquery := rc.DB.WithContext(c.Context()).Table("qc_online_details").
 		Select("qc_online_details.box_id, COALESCE(SUM(qc_online_details.quantity), 0) as online_count").
 		Joins("LEFT JOIN qc_onlines ON qc_onlines.id = qc_online_details.qc_online_id")
 
@@ -236,7 +681,7 @@ func (rc *ReportController) GetBoxReports(c fiber.Ctx) error {
 	}
 
 	var results []BoxCountResult
-	query := rc.DB.Table("boxes").
+	query := rc.DB.WithContext(c.Context()).Table("boxes").
 		Select("boxes.id as box_id, boxes.box_code, boxes.box_name, COALESCE(ribbon.ribbon_count, 0) as ribbon_count, COALESCE(online.online_count, 0) as online_count, (COALESCE(ribbon.ribbon_count, 0) + COALESCE(online.online_count, 0)) as total_count").
 		Joins("LEFT JOIN (?) as ribbon ON ribbon.box_id = boxes.id", ribbonCountSubquery).
 		Joins("LEFT JOIN (?) as online ON online.box_id = boxes.id", onlineCountSubquery)
@@ -264,22 +709,60 @@ func (rc *ReportController) GetBoxReports(c fiber.Ctx) error {
 		})
 	}
 
-	// Build response with details
+	// Stream the boxes and their usage details line by line if requested
+	if format == "ndjson" {
+		log.Println("GetBoxReports streaming", len(results), "boxes")
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+		return c.Status(fiber.StatusOK).SendStreamWriter(func(w *bufio.Writer) {
+			// The request context ends when the handler returns, the stream outlives it
+			ctx := context.Background()
+			encoder := json.NewEncoder(w)
+			for _, result := range results {
+				report := BoxCountReport{
+					BoxID:       result.BoxID,
+					BoxCode:     result.BoxCode,
+					BoxName:     result.BoxName,
+					TotalCount:  result.TotalCount,
+					RibbonCount: result.RibbonCount,
+					OnlineCount: result.OnlineCount,
+				}
+				if err := encoder.Encode(BoxReportStreamLine{Type: "box", BoxID: report.BoxID, Box: &report}); err != nil {
+					log.Println("GetBoxReports - Failed to write stream:", err)
+					return
+				}
+
+				err := rc.EachBoxUsageDetail(ctx, result.BoxID, startDate, endDate, func(detail BoxUsageDetail) error {
+					return encoder.Encode(BoxReportStreamLine{Type: "detail", BoxID: result.BoxID, Detail: &detail})
+				})
+				if err == nil {
+					// Flush after every box so clients receive the report progressively
+					err = w.Flush()
+				}
+				if err != nil {
+					log.Println("GetBoxReports - Failed to stream box usage details:", err)
+					_ = encoder.Encode(BoxReportStreamLine{Type: "error", Error: "Failed to retrieve box usage details"})
+					_ = w.Flush()
+					return
+				}
+			}
+
+			_ = encoder.Encode(BoxReportStreamLine{Type: "end", Total: len(results)})
+			_ = w.Flush()
+			log.Println("GetBoxReports completed successfully")
+		})
+	}
+
+	// Build summary response, details are paginated per box by GetBoxUsageDetails
 	var reports []BoxCountReport
 	for _, result := range results {
-		report := BoxCountReport{
+		reports = append(reports, BoxCountReport{
 			BoxID:       result.BoxID,
 			BoxCode:     result.BoxCode,
 			BoxName:     result.BoxName,
 			TotalCount:  result.TotalCount,
 			RibbonCount: result.RibbonCount,
 			OnlineCount: result.OnlineCount,
-		}
-
-		// Get detailed usage for this box
-		report.Details = rc.BuildBoxUsageDetails(result.BoxID, startDate, endDate)
-
-		reports = append(reports, report)
+		})
 	}
 
 	response := BoxCountReportsListResponse{
@@ -318,6 +801,146 @@ func (rc *ReportController) GetBoxReports(c fiber.Ctx) error {
 	})
 }
 
+// GetBoxUsageDetails retrieves the usage details of a single box
+// @Summary Get Box Usage Details
+// @Description Retrieve the paginated ribbon and online QC usages of a box, newest first
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param boxId path int true "Box ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of usages per page" default(10)
+// @Param startDate query string false "Filter by start date (YYYY-MM-DD format)"
+// @Param endDate query string false "Filter by end date (YYYY-MM-DD format)"
+// @Param source query string false "Filter by QC source (ribbon or online)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]BoxUsageDetail}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/boxes/{boxId}/details [get]
+func (rc *ReportController) GetBoxUsageDetails(c fiber.Ctx) error {
+	log.Println("GetBoxUsageDetails called")
+	// Parse boxId parameter
+	boxIDStr := c.Params("boxId")
+	boxID, err := strconv.ParseUint(boxIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid box ID",
+		})
+	}
+
+	var box models.Box
+	if err := rc.DB.WithContext(c.Context()).First(&box, boxID).Error; err != nil {
+		log.Println("GetBoxUsageDetails - Box not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Box with id " + boxIDStr + " not found.",
+		})
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Parse and validate query parameters
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	source := c.Query("source", "")
+	for _, date := range []string{startDate, endDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid date format. Use YYYY-MM-DD.",
+			})
+		}
+	}
+
+	// Combine the usages of the requested sources
+	var sourceQueries []interface{}
+	for i := range boxUsageSources {
+		if source == "" || source == boxUsageSources[i].Source {
+			sourceQueries = append(sourceQueries, rc.boxUsageDetailQuery(c.Context(), i, uint(boxID), startDate, endDate))
+		}
+	}
+	if len(sourceQueries) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid source. Use ribbon or online.",
+		})
+	}
+	usages := rc.DB.WithContext(c.Context()).Raw(strings.TrimSuffix(strings.Repeat("(?) UNION ALL ", len(sourceQueries)), " UNION ALL "), sourceQueries...)
+	query := rc.DB.WithContext(c.Context()).Table("(?) as usages", usages)
+
+	// Get total count for pagination
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		log.Println("GetBoxUsageDetails - Failed to count box usages:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve box usage details",
+		})
+	}
+
+	// Retrieve paginated results
+	var results []boxUsageDetailRow
+	if err := query.Order("created_at DESC, source ASC, detail_id DESC").Limit(limit).Offset(offset).Scan(&results).Error; err != nil {
+		log.Println("GetBoxUsageDetails - Failed to retrieve box usages:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve box usage details",
+		})
+	}
+
+	// Format response
+	details := make([]BoxUsageDetail, len(results))
+	for i, result := range results {
+		details[i] = result.toDetail()
+	}
+
+	// Build success message
+	message := "Usage details of box " + box.BoxName + " retrieved successfully"
+	var filters []string
+
+	if startDate != "" || endDate != "" {
+		var dateRange []string
+		if startDate != "" {
+			dateRange = append(dateRange, "from: "+startDate)
+		}
+		if endDate != "" {
+			dateRange = append(dateRange, "to: "+endDate)
+		}
+		filters = append(filters, "date: "+strings.Join(dateRange, ", "))
+	}
+
+	if source != "" {
+		filters = append(filters, "source: "+source)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetBoxUsageDetails completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    details,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
 // GetOutboundReports generates outbound reports
 // @Summary Get Outbound Reports
 // @Description Generate outbound reports with optional filters
@@ -330,6 +953,7 @@ func (rc *ReportController) GetBoxReports(c fiber.Ctx) error {
 // @Success 200 {object} utils.SuccessTotaledResponse{data=OutboundReportsListResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/reports/outbounds [get]
 func (rc *ReportController) GetOutboundReports(c fiber.Ctx) error {
@@ -340,7 +964,7 @@ func (rc *ReportController) GetOutboundReports(c fiber.Ctx) error {
 
 	// Build base query
 	var outbounds []models.Outbound
-	query := rc.DB.Model(&models.Outbound{}).Order("created_at DESC")
+	query := rc.DB.WithContext(c.Context()).Model(&models.Outbound{}).Order("created_at DESC")
 
 	// Apply date filters
 	if date != "" {
@@ -424,6 +1048,7 @@ func (rc *ReportController) GetOutboundReports(c fiber.Ctx) error {
 // @Success 200 {object} utils.SuccessTotaledResponse{data=[]models.ReturnResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/reports/returns [get]
 func (rc *ReportController) GetReturnReports(c fiber.Ctx) error {
@@ -435,7 +1060,7 @@ func (rc *ReportController) GetReturnReports(c fiber.Ctx) error {
 
 	// Build base query
 	var returns []models.Return
-	query := rc.DB.Model(&models.Return{}).Order("created_at DESC")
+	query := rc.DB.WithContext(c.Context()).Model(&models.Return{}).Order("created_at DESC")
 
 	// Apply date filters
 	if date != "" {
@@ -515,281 +1140,3615 @@ func (rc *ReportController) GetReturnReports(c fiber.Ctx) error {
 	})
 }
 
-// GetComplaintReports generates complaint reports
-// @Summary Get Complaint Reports
-// @Description Generate complaint reports with optional filters
-// @Tags Reports
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param date query string false "Filter by date (YYYY-MM-DD format)"
-// @Success 200 {object} utils.SuccessTotaledResponse{data=ComplaintReportsListResponse}
-// @Failure 400 {object} utils.ErrorResponse
-// @Failure 401 {object} utils.ErrorResponse
-// @Failure 500 {object} utils.ErrorResponse
-// @Router /api/reports/complains [get]
-func (rc *ReportController) GetComplainReports(c fiber.Ctx) error {
-	log.Println("GetComplainReports called")
-	var complaints []models.Complain
+// BuildReturnValuationReport aggregates the value and disposition of the items returned between start and end (exclusive)
+// per channel with their totals, limited to a single channel when given
+func (rc *ReportController) BuildReturnValuationReport(ctx context.Context, start, end time.Time, channel *models.Channel) ([]ReturnValuationRow, ReturnValuationRow, error) {
+	// Aggregate returned items per channel with their value and disposition
+	returnQuery := rc.DB.WithContext(ctx).Table("returns").
+		Select(`channels.channel_name AS channel,
+			COUNT(DISTINCT returns.id) AS returns,
+			COALESCE(SUM(return_details.quantity), 0) AS returned_items,
+			COALESCE(SUM(return_details.quantity * return_details.price), 0) AS returned_value,
+			COALESCE(SUM(CASE WHEN return_details.disposition = ? THEN return_details.quantity END), 0) AS restocked_items,
+			COALESCE(SUM(CASE WHEN return_details.disposition = ? THEN return_details.quantity * return_details.price END), 0) AS restocked_value,
+			COALESCE(SUM(CASE WHEN return_details.disposition = ? THEN return_details.quantity END), 0) AS damaged_items,
+			COALESCE(SUM(CASE WHEN return_details.disposition = ? THEN return_details.quantity * return_details.price END), 0) AS damaged_value,
+			COALESCE(SUM(CASE WHEN return_details.disposition = ? THEN return_details.quantity END), 0) AS disposed_items,
+			COALESCE(SUM(CASE WHEN return_details.disposition = ? THEN return_details.quantity * return_details.price END), 0) AS disposed_value,
+			COALESCE(SUM(CASE WHEN return_details.id IS NOT NULL AND return_details.disposition IS NULL THEN return_details.quantity END), 0) AS pending_items,
+			COALESCE(SUM(CASE WHEN return_details.id IS NOT NULL AND return_details.disposition IS NULL THEN return_details.quantity * return_details.price END), 0) AS pending_value`,
+			models.ReturnDispositionRestocked, models.ReturnDispositionRestocked,
+			models.ReturnDispositionDamaged, models.ReturnDispositionDamaged,
+			models.ReturnDispositionDisposed, models.ReturnDispositionDisposed).
+		Joins("JOIN channels ON channels.id = returns.channel_id").
+		Joins("LEFT JOIN return_details ON return_details.return_id = returns.id").
+		Where("returns.created_at >= ? AND returns.created_at < ?", start, end)
 
-	// Build base query
-	query := rc.DB.Model(&models.Complain{}).Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Order("created_at DESC")
+	// Outbound volume per order channel over the same period
+	type channelOutbound struct {
+		Channel        string
+		OutboundOrders int64
+	}
+	outboundQuery := rc.DB.WithContext(ctx).Table("outbounds").
+		Select("orders.channel, COUNT(DISTINCT outbounds.id) AS outbound_orders").
+		Joins("JOIN orders ON orders.tracking_number = outbounds.tracking_number").
+		Where("outbounds.created_at >= ? AND outbounds.created_at < ?", start, end)
 
-	// Apply date filters if provided
-	date := c.Query("date")
-	if date != "" {
-		// Parse date dan validate format
-		if parsedDate, err := time.Parse("2006-01-02", date); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Invalid date format. Use YYYY-MM-DD.",
-			})
-		} else {
-			// Filter for the entire day (from 00:00:00 to 23:59:59)
-			startOfDay := parsedDate.Format("2006-01-02 00:00:00")
-			endOfDay := parsedDate.AddDate(0, 0, 1).Format("2006-01-02 00:00:00")
-			query = query.Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay)
-		}
+	// Apply channel filter
+	if channel != nil {
+		returnQuery = returnQuery.Where("returns.channel_id = ?", channel.ID)
+		outboundQuery = outboundQuery.Where("LOWER(orders.channel) = LOWER(?)", channel.ChannelName)
 	}
 
-	// Get total count
-	var total int64
-	query.Count(&total)
+	var reports []ReturnValuationRow
+	if err := returnQuery.Group("channels.channel_name").Order("channels.channel_name").Scan(&reports).Error; err != nil {
+		return nil, ReturnValuationRow{}, fmt.Errorf("failed to aggregate returns: %w", err)
+	}
 
-	// Execute query
-	if err := query.Find(&complaints).Error; err != nil {
-		log.Println("GetComplainReports - Failed to retrieve complaint reports:", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-			Success: false,
-			Error:   "Failed to retrieve complaint reports",
-		})
+	var outbounds []channelOutbound
+	if err := outboundQuery.Group("orders.channel").Scan(&outbounds).Error; err != nil {
+		return nil, ReturnValuationRow{}, fmt.Errorf("failed to aggregate outbound volume: %w", err)
 	}
 
-	// Format response
-	complaintList := make([]models.ComplainResponse, len(complaints))
-	for i, complaint := range complaints {
-		complaintList[i] = *complaint.ToComplainResponse()
+	// Order channels are free text, match them to the channel names case-insensitively
+	outboundByChannel := make(map[string]int64)
+	for _, outbound := range outbounds {
+		outboundByChannel[strings.ToLower(strings.TrimSpace(outbound.Channel))] += outbound.OutboundOrders
 	}
 
-	response := ComplaintReportsListResponse{
-		Complaints: complaintList,
+	returnRate := func(returns, outboundOrders int64) float64 {
+		if outboundOrders == 0 {
+			return 0
+		}
+		return math.Round(float64(returns)/float64(outboundOrders)*10000) / 100
 	}
 
-	// Build success message
-	message := "Complaint reports retrieved successfully"
+	if reports == nil {
+		reports = []ReturnValuationRow{}
+	}
+	totals := ReturnValuationRow{Channel: "TOTAL"}
+	for i := range reports {
+		reports[i].OutboundOrders = outboundByChannel[strings.ToLower(strings.TrimSpace(reports[i].Channel))]
+		reports[i].ReturnRate = returnRate(reports[i].Returns, reports[i].OutboundOrders)
+		totals.Returns += reports[i].Returns
+		totals.ReturnedItems += reports[i].ReturnedItems
+		totals.ReturnedValue += reports[i].ReturnedValue
+		totals.RestockedItems += reports[i].RestockedItems
+		totals.RestockedValue += reports[i].RestockedValue
+		totals.DamagedItems += reports[i].DamagedItems
+		totals.DamagedValue += reports[i].DamagedValue
+		totals.DisposedItems += reports[i].DisposedItems
+		totals.DisposedValue += reports[i].DisposedValue
+		totals.PendingItems += reports[i].PendingItems
+		totals.PendingValue += reports[i].PendingValue
+	}
+	// The total return rate is measured against the whole outbound volume, including channels without returns
+	for _, outboundOrders := range outboundByChannel {
+		totals.OutboundOrders += outboundOrders
+	}
+	totals.ReturnRate = returnRate(totals.Returns, totals.OutboundOrders)
+
+	return reports, totals, nil
+}
+
+// BuildReturnValuationReportXLSX exports the return valuation rows of a period to an XLSX workbook
+func BuildReturnValuationReportXLSX(reports []ReturnValuationRow, totals ReturnValuationRow) (*bytes.Buffer, error) {
+	headers := []string{"Channel", "Returns", "Returned Items", "Returned Value", "Restocked Items", "Restocked Value", "Damaged Items", "Damaged Value", "Disposed Items", "Disposed Value", "Pending Items", "Pending Value", "Outbound Orders", "Return Rate (%)"}
+	rows := make([][]interface{}, 0, len(reports)+1)
+	for _, row := range append(reports, totals) {
+		rows = append(rows, []interface{}{row.Channel, row.Returns, row.ReturnedItems, row.ReturnedValue, row.RestockedItems, row.RestockedValue, row.DamagedItems, row.DamagedValue, row.DisposedItems, row.DisposedValue, row.PendingItems, row.PendingValue, row.OutboundOrders, row.ReturnRate})
+	}
+	return utils.BuildXLSX("Return Valuation", headers, rows)
+}
+
+// GetReturnValuationReports generates the returns valuation and disposition report per channel
+// @Summary Get Return Valuation Reports
+// @Description Generate the value of returned items per channel with the split by disposition (restocked, damaged, disposed, pending) and the return rate against outbound volume over the period, optionally exported to XLSX
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD format, default first day of the current month)"
+// @Param endDate query string false "End date (YYYY-MM-DD format, default today)"
+// @Param channelId query string false "Filter by channel ID"
+// @Param format query string false "Response format (json or xlsx)" default(json)
+// @Success 200 {object} utils.SuccessResponse{data=ReturnValuationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/return-valuation [get]
+func (rc *ReportController) GetReturnValuationReports(c fiber.Ctx) error {
+	log.Println("GetReturnValuationReports called")
+	// Parse query parameters
+	now := time.Now()
+	startDate := c.Query("startDate", now.Format("2006-01")+"-01")
+	endDate := c.Query("endDate", now.Format("2006-01-02"))
+	channelId := c.Query("channelId", "")
+	format := strings.ToLower(c.Query("format", "json"))
+
+	// Parse dates and validate format
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	if format != "json" && format != "xlsx" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or xlsx.",
+		})
+	}
+
+	// Apply channel filter
+	var channel *models.Channel
+	if channelId != "" {
+		channel = &models.Channel{}
+		if err := rc.DB.WithContext(c.Context()).Where("id = ?", channelId).First(channel).Error; err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Channel with id " + channelId + " not found",
+			})
+		}
+	}
+
+	reports, totals, err := rc.BuildReturnValuationReport(c.Context(), start, end, channel)
+	if err != nil {
+		log.Println("GetReturnValuationReports - Failed to build return valuation reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve return valuation reports",
+		})
+	}
+
+	// Export to XLSX if requested
+	if format == "xlsx" {
+		buffer, err := BuildReturnValuationReportXLSX(reports, totals)
+		if err != nil {
+			log.Println("GetReturnValuationReports - Failed to build XLSX:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to export return valuation reports",
+			})
+		}
+
+		log.Println("GetReturnValuationReports completed successfully")
+		c.Set(fiber.HeaderContentType, utils.XLSXContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"return-valuation-%s-%s.xlsx\"", startDate, endDate))
+		return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+	}
+
+	// Build success message
+	message := "Return valuation reports retrieved successfully"
+	filters := []string{"from: " + startDate, "to: " + endDate}
+
+	if channelId != "" {
+		filters = append(filters, "channelId: "+channelId)
+	}
+
+	message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+
+	log.Println("GetReturnValuationReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: ReturnValuationResponse{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Reports:   reports,
+			Totals:    totals,
+		},
+	})
+}
+
+// GetComplaintReports generates complaint reports
+// @Summary Get Complaint Reports
+// @Description Generate complaint reports with optional filters, with the assigned, open and resolved complains, resolution rate and average resolution time per CS agent
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param date query string false "Filter by date (YYYY-MM-DD format)"
+// @Success 200 {object} utils.SuccessTotaledResponse{data=ComplaintReportsListResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/complains [get]
+func (rc *ReportController) GetComplainReports(c fiber.Ctx) error {
+	log.Println("GetComplainReports called")
+	var complaints []models.Complain
+
+	// Build base query
+	query := rc.DB.WithContext(c.Context()).Model(&models.Complain{}).Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").Order("created_at DESC")
+
+	// Apply date filters if provided
+	date := c.Query("date")
+	if date != "" {
+		// Parse date dan validate format
+		if parsedDate, err := time.Parse("2006-01-02", date); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid date format. Use YYYY-MM-DD.",
+			})
+		} else {
+			// Filter for the entire day (from 00:00:00 to 23:59:59)
+			startOfDay := parsedDate.Format("2006-01-02 00:00:00")
+			endOfDay := parsedDate.AddDate(0, 0, 1).Format("2006-01-02 00:00:00")
+			query = query.Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay)
+		}
+	}
+
+	// Get total count
+	var total int64
+	query.Count(&total)
+
+	// Execute query
+	if err := query.Find(&complaints).Error; err != nil {
+		log.Println("GetComplainReports - Failed to retrieve complaint reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complaint reports",
+		})
+	}
+
+	// Format response
+	complaintList := make([]models.ComplainResponse, len(complaints))
+	for i, complaint := range complaints {
+		complaintList[i] = *complaint.ToComplainResponse()
+	}
+
+	agents, err := rc.buildComplainAgentMetrics(c.Context(), complaints)
+	if err != nil {
+		log.Println("GetComplainReports - Failed to build agent metrics:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complaint reports",
+		})
+	}
+
+	response := ComplaintReportsListResponse{
+		Complaints: complaintList,
+		Agents:     agents,
+	}
+
+	// Build success message
+	message := "Complaint reports retrieved successfully"
+	var filters []string
+
+	if date != "" {
+		filters = append(filters, "date: "+date)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetComplainReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: message,
+		Data:    response,
+		Total:   total,
+	})
+}
+
+// buildComplainAgentMetrics computes the resolution metrics per CS agent of the reported complains, most assigned first
+func (rc *ReportController) buildComplainAgentMetrics(ctx context.Context, complaints []models.Complain) ([]ComplainAgentMetrics, error) {
+	metricsByAgent := make(map[uint]*ComplainAgentMetrics)
+	resolutionHours := make(map[uint]float64)
+	complainIDs := make([]uint, len(complaints))
+	for i, complaint := range complaints {
+		complainIDs[i] = complaint.ID
+		if complaint.AssignedTo == nil {
+			continue
+		}
+		metrics, ok := metricsByAgent[*complaint.AssignedTo]
+		if !ok {
+			metrics = &ComplainAgentMetrics{UserID: *complaint.AssignedTo}
+			if complaint.AssignUser != nil {
+				metrics.Agent = complaint.AssignUser.FullName
+			}
+			metricsByAgent[*complaint.AssignedTo] = metrics
+		}
+		metrics.Assigned++
+		if complaint.ResolvedAt != nil {
+			metrics.Resolved++
+			resolutionHours[metrics.UserID] += complaint.ResolvedAt.Sub(complaint.CreatedAt).Hours()
+		} else {
+			metrics.Open++
+		}
+	}
+
+	// Reassignments away from each agent
+	if len(complainIDs) > 0 {
+		var reassigned []struct {
+			FromUserID uint
+			Count      int64
+		}
+		if err := rc.DB.WithContext(ctx).Model(&models.ComplainAssignment{}).
+			Select("from_user_id, COUNT(*) as count").
+			Where("complain_id IN ? AND from_user_id IS NOT NULL", complainIDs).
+			Group("from_user_id").
+			Scan(&reassigned).Error; err != nil {
+			return nil, err
+		}
+		for _, row := range reassigned {
+			if metrics, ok := metricsByAgent[row.FromUserID]; ok {
+				metrics.ReassignedAway = row.Count
+			}
+		}
+	}
+
+	agents := make([]ComplainAgentMetrics, 0, len(metricsByAgent))
+	for userID, metrics := range metricsByAgent {
+		metrics.ResolutionRate = math.Round(float64(metrics.Resolved)/float64(metrics.Assigned)*10000) / 100
+		if metrics.Resolved > 0 {
+			metrics.AvgResolutionHours = math.Round(resolutionHours[userID]/float64(metrics.Resolved)*100) / 100
+		}
+		agents = append(agents, *metrics)
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		if agents[i].Assigned != agents[j].Assigned {
+			return agents[i].Assigned > agents[j].Assigned
+		}
+		return agents[i].UserID < agents[j].UserID
+	})
+	return agents, nil
+}
+
+// GetComplainRootCauseReports groups complains by root cause per week
+// @Summary Get Complain Root Cause Reports
+// @Description Count complains per root cause per week (weeks start on Monday), most frequent root cause first, to target process fixes. Complains filed before the root cause taxonomy are reported as "unclassified"
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to 12 weeks ago"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param channelId query int false "Filter by channel ID"
+// @Param storeId query int false "Filter by store ID"
+// @Success 200 {object} utils.SuccessTotaledResponse{data=ComplainRootCauseTrendResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/complains/root-causes [get]
+func (rc *ReportController) GetComplainRootCauseReports(c fiber.Ctx) error {
+	log.Println("GetComplainRootCauseReports called")
+	// Parse query parameters
+	now := time.Now()
+	startDate := c.Query("startDate", now.AddDate(0, 0, -7*12).Format("2006-01-02"))
+	endDate := c.Query("endDate", now.Format("2006-01-02"))
+	channelID := c.Query("channelId", "")
+	storeID := c.Query("storeId", "")
+
+	// Validate date formats and range
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	// Aggregate complains per root cause and week
+	query := rc.DB.WithContext(c.Context()).Model(&models.Complain{}).
+		Select("root_cause_id, date_trunc('week', created_at) as week, COUNT(*) as count").
+		Where("created_at >= ? AND created_at < ?", start, end)
+	if channelID != "" {
+		if _, err := strconv.ParseUint(channelID, 10, 32); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid channelId",
+			})
+		}
+		query = query.Where("channel_id = ?", channelID)
+	}
+	if storeID != "" {
+		if _, err := strconv.ParseUint(storeID, 10, 32); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid storeId",
+			})
+		}
+		query = query.Where("store_id = ?", storeID)
+	}
+
+	var counts []struct {
+		RootCauseID *uint
+		Week        time.Time
+		Count       int64
+	}
+	if err := query.Group("root_cause_id, week").Scan(&counts).Error; err != nil {
+		log.Println("GetComplainRootCauseReports - Failed to aggregate complains:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complain root cause reports",
+		})
+	}
+
+	var rootCauses []models.ComplainRootCause
+	if err := rc.DB.WithContext(c.Context()).Find(&rootCauses).Error; err != nil {
+		log.Println("GetComplainRootCauseReports - Failed to load root causes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complain root cause reports",
+		})
+	}
+
+	// Every week of the range, starting on the Monday of the start date
+	weekStart := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+	var weeks []string
+	for week := weekStart; week.Before(end); week = week.AddDate(0, 0, 7) {
+		weeks = append(weeks, week.Format("2006-01-02"))
+	}
+
+	// Every active root cause is reported, inactive ones only when complains still use them
+	rows := make(map[uint]*ComplainRootCauseTrendRow)
+	weekCounts := make(map[*ComplainRootCauseTrendRow]map[string]int64)
+	for _, rootCause := range rootCauses {
+		rootCauseID := rootCause.ID
+		rows[rootCause.ID] = &ComplainRootCauseTrendRow{RootCauseID: &rootCauseID, Code: rootCause.Code, Name: rootCause.Name}
+	}
+	unclassified := &ComplainRootCauseTrendRow{Code: unclassifiedRootCauseCode, Name: "Unclassified"}
+
+	var total int64
+	for _, count := range counts {
+		row := unclassified
+		if count.RootCauseID != nil {
+			if known, ok := rows[*count.RootCauseID]; ok {
+				row = known
+			}
+		}
+		if weekCounts[row] == nil {
+			weekCounts[row] = make(map[string]int64)
+		}
+		weekCounts[row][count.Week.In(time.Local).Format("2006-01-02")] += count.Count
+		row.Complaints += count.Count
+		total += count.Count
+	}
+
+	reports := make([]ComplainRootCauseTrendRow, 0, len(rows)+1)
+	addRow := func(row *ComplainRootCauseTrendRow) {
+		row.Trend = make([]ComplainRootCauseTrendPoint, len(weeks))
+		for i, week := range weeks {
+			row.Trend[i] = ComplainRootCauseTrendPoint{Week: week, Complaints: weekCounts[row][week]}
+		}
+		if total > 0 {
+			row.Share = math.Round(float64(row.Complaints)*10000/float64(total)) / 100
+		}
+		reports = append(reports, *row)
+	}
+	for _, rootCause := range rootCauses {
+		if row := rows[rootCause.ID]; rootCause.IsActive || row.Complaints > 0 {
+			addRow(row)
+		}
+	}
+	if unclassified.Complaints > 0 {
+		addRow(unclassified)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		if reports[i].Complaints != reports[j].Complaints {
+			return reports[i].Complaints > reports[j].Complaints
+		}
+		return reports[i].Name < reports[j].Name
+	})
+
+	// Build success message
+	message := "Complain root cause reports retrieved successfully"
+	var filters []string
+
+	filters = append(filters, "date: "+startDate+" to "+endDate)
+
+	if channelID != "" {
+		filters = append(filters, "channelId: "+channelID)
+	}
+
+	if storeID != "" {
+		filters = append(filters, "storeId: "+storeID)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetComplainRootCauseReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: message,
+		Data: ComplainRootCauseTrendResponse{
+			StartDate:  startDate,
+			EndDate:    endDate,
+			Weeks:      weeks,
+			RootCauses: reports,
+		},
+		Total: total,
+	})
+}
+
+// GetComplainRepeatOffenderReports lists the buyers and tracking numbers with repeated complaints
+// @Summary Get Complain Repeat Offender Reports
+// @Description List the buyers complaining on several orders and the tracking numbers complained about again after being resolved (reopened), most complaints first, to identify fraud-prone buyers and systemic packing issues. Buyers are matched through the order of the complained tracking number
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to 90 days ago"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param minComplaints query int false "Minimum complaints to be reported" default(2)
+// @Param masking query string false "Buyer data masking (full, partial, anonymized), defaults to the least masked profile allowed for your role"
+// @Success 200 {object} utils.SuccessTotaledResponse{data=ComplainRepeatOffenderResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/complains/repeat-offenders [get]
+func (rc *ReportController) GetComplainRepeatOffenderReports(c fiber.Ctx) error {
+	log.Println("GetComplainRepeatOffenderReports called")
+	// Parse query parameters
+	now := time.Now()
+	startDate := c.Query("startDate", now.AddDate(0, 0, -90).Format("2006-01-02"))
+	endDate := c.Query("endDate", now.Format("2006-01-02"))
+	minComplaints, err := strconv.Atoi(c.Query("minComplaints", "2"))
+	if err != nil || minComplaints < 2 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid minComplaints, must be at least 2",
+		})
+	}
+
+	// Validate date formats and range
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	masking, err := resolveMasking(c, rc.Masking)
+	if err != nil {
+		return maskingErrorResponse(c, rc.Masking, err)
+	}
+
+	// Complains of the range with the buyer of their order
+	var complains []struct {
+		Code           string
+		TrackingNumber string
+		ReopenCount    int
+		CreatedAt      time.Time
+		LastReopenedAt *time.Time
+		RootCause      string
+		Buyer          string
+	}
+	if err := rc.DB.WithContext(c.Context()).Table("complains").
+		Select("complains.code, complains.tracking_number, complains.reopen_count, complains.created_at, complains.last_reopened_at, "+
+			"COALESCE(complain_root_causes.name, '') as root_cause, "+
+			"COALESCE((SELECT orders.buyer FROM orders WHERE orders.tracking_number = complains.tracking_number ORDER BY orders.id LIMIT 1), '') as buyer").
+		Joins("LEFT JOIN complain_root_causes ON complain_root_causes.id = complains.root_cause_id").
+		Where("complains.created_at >= ? AND complains.created_at < ?", start, end).
+		Order("complains.created_at ASC").
+		Scan(&complains).Error; err != nil {
+		log.Println("GetComplainRepeatOffenderReports - Failed to retrieve complains:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve complain repeat offender reports",
+		})
+	}
+
+	// Every complain is a different order, a reopened complain counts once more per reopen
+	buyerRows := make(map[string]*ComplainRepeatBuyerRow)
+	var buyerKeys []string
+	trackingNumbers := []ComplainRepeatTrackingRow{}
+	for _, complain := range complains {
+		if complaints := 1 + complain.ReopenCount; complaints >= minComplaints {
+			row := ComplainRepeatTrackingRow{
+				TrackingNumber: complain.TrackingNumber,
+				ComplainCode:   complain.Code,
+				Buyer:          utils.MaskName(masking, complain.Buyer),
+				RootCause:      complain.RootCause,
+				Complaints:     complaints,
+				Reopens:        complain.ReopenCount,
+				CreatedAt:      complain.CreatedAt.Format("02-01-2006 15:04:05"),
+			}
+			if complain.LastReopenedAt != nil {
+				row.LastReopenedAt = complain.LastReopenedAt.Format("02-01-2006 15:04:05")
+			}
+			trackingNumbers = append(trackingNumbers, row)
+		}
+
+		// Buyer names are grouped regardless of case and spacing
+		key := strings.ToLower(strings.Join(strings.Fields(complain.Buyer), " "))
+		if key == "" {
+			continue
+		}
+		row, ok := buyerRows[key]
+		if !ok {
+			row = &ComplainRepeatBuyerRow{
+				Buyer:           utils.MaskName(masking, complain.Buyer),
+				TrackingNumbers: []string{},
+				RootCauses:      []string{},
+				FirstComplaint:  complain.CreatedAt.Format("02-01-2006 15:04:05"),
+			}
+			buyerRows[key] = row
+			buyerKeys = append(buyerKeys, key)
+		}
+		row.Orders++
+		row.Complaints += int64(1 + complain.ReopenCount)
+		row.Reopens += int64(complain.ReopenCount)
+		row.TrackingNumbers = append(row.TrackingNumbers, complain.TrackingNumber)
+		if complain.RootCause != "" && !slices.Contains(row.RootCauses, complain.RootCause) {
+			row.RootCauses = append(row.RootCauses, complain.RootCause)
+		}
+		row.LastComplaint = complain.CreatedAt.Format("02-01-2006 15:04:05")
+	}
+
+	// Buyers complaining on at least the minimum number of orders
+	buyers := []ComplainRepeatBuyerRow{}
+	for _, key := range buyerKeys {
+		if row := buyerRows[key]; row.Orders >= int64(minComplaints) {
+			buyers = append(buyers, *row)
+		}
+	}
+	sort.SliceStable(buyers, func(i, j int) bool { return buyers[i].Complaints > buyers[j].Complaints })
+	sort.SliceStable(trackingNumbers, func(i, j int) bool { return trackingNumbers[i].Complaints > trackingNumbers[j].Complaints })
+
+	// Build success message
+	message := "Complain repeat offender reports retrieved successfully"
+	filters := []string{"date: " + startDate + " to " + endDate, fmt.Sprintf("minComplaints: %d", minComplaints)}
+	message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+
+	log.Println("GetComplainRepeatOffenderReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: message,
+		Data: ComplainRepeatOffenderResponse{
+			StartDate:       startDate,
+			EndDate:         endDate,
+			MinComplaints:   minComplaints,
+			Buyers:          buyers,
+			TrackingNumbers: trackingNumbers,
+		},
+		Total: int64(len(buyers) + len(trackingNumbers)),
+	})
+}
+
+// GetUserFeeReports generates user fee reports
+// @Summary Get User Fee Reports
+// @Description Generate user fee reports with optional filters, including the fee dispute status and settlement period of each complain
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number for pagination (default is 1)"
+// @Param limit query int false "Number of items per page for pagination (default is 10)"
+// @Param startDate query string false "Filter by start date (YYYY-MM-DD format)"
+// @Param endDate query string false "Filter by end date (YYYY-MM-DD format)"
+// @Param userId query string false "Filter term for user ID"
+// @Param teamId query int false "Filter by team ID"
+// @Param settled query bool false "Filter by whether the complain fees are frozen in a settlement"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=UserFeeReportsWithDetailsListResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/user-fees [get]
+func (rc *ReportController) GetUserFeeReports(c fiber.Ctx) error {
+	log.Println("GetUserFeeReports called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Parse filter parameters
+	userId := c.Query("userId", "")
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	settled := c.Query("settled", "")
+	if settled != "" && settled != "true" && settled != "false" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid settled filter. Use true or false.",
+		})
+	}
+
+	// Validate date formats
+	if startDate != "" {
+		if _, err := time.Parse("2006-01-02", startDate); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+	}
+	if endDate != "" {
+		if _, err := time.Parse("2006-01-02", endDate); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+	}
+
+	// Build query to get user summaries
+	type UserSummary struct {
+		UserID          uint
+		Username        string
+		FullName        string
+		Email           string
+		TotalComplaints int
+		TotalFeeCharge  uint
+		UnsettledFee    uint
+	}
+
+	summaryQuery := rc.DB.WithContext(c.Context()).Table("complain_user_details").
+		Select("users.id as user_id, users.username, users.full_name, users.email, COUNT(DISTINCT complain_user_details.complain_id) as total_complaints, COALESCE(SUM(complain_user_details.fee_charge), 0) as total_fee_charge, COALESCE(SUM(CASE WHEN complains.settlement_id IS NULL THEN complain_user_details.fee_charge ELSE 0 END), 0) as unsettled_fee").
+		Joins("LEFT JOIN users ON users.id = complain_user_details.user_id").
+		Joins("LEFT JOIN complains ON complains.id = complain_user_details.complain_id")
+
+	// Apply date filters on complains table
+	if startDate != "" {
+		summaryQuery = summaryQuery.Where("complains.updated_at >= ?", startDate+" 00:00:00")
+	}
+	if endDate != "" {
+		summaryQuery = summaryQuery.Where("complains.updated_at <= ?", endDate+" 23:59:59")
+	}
+
+	// Apply settlement filter
+	if settled == "true" {
+		summaryQuery = summaryQuery.Where("complains.settlement_id IS NOT NULL")
+	} else if settled == "false" {
+		summaryQuery = summaryQuery.Where("complains.settlement_id IS NULL")
+	}
+
+	// Apply user filter
+	if userId != "" {
+		summaryQuery = summaryQuery.Where("complain_user_details.user_id = ?", userId)
+	}
+
+	// Apply team filter
+	if teamID > 0 {
+		summaryQuery = summaryQuery.Where("complain_user_details.user_id IN (?)", utils.TeamMembersQuery(rc.DB, uint(teamID)))
+	}
+
+	summaryQuery = summaryQuery.Group("users.id, users.username, users.full_name, users.email").
+		Order("total_fee_charge DESC")
+
+	// Get total count
+	var totalCount int64
+	countQuery := rc.DB.WithContext(c.Context()).Table("(?) as summaries", summaryQuery)
+	if err := countQuery.Count(&totalCount).Error; err != nil {
+		log.Println("GetUserFeeReports - Failed to count user fee reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to count user fee reports",
+		})
+	}
+
+	// Apply pagination
+	var summaries []UserSummary
+	if err := summaryQuery.Limit(limit).Offset(offset).Scan(&summaries).Error; err != nil {
+		log.Println("GetUserFeeReports - Failed to retrieve user fee reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve user fee reports",
+		})
+	}
+
+	// Build detailed reports for each user
+	var reports []UserFeeReportWithDetails
+	for _, summary := range summaries {
+		// Get detailed complain information for this user
+		detailQuery := rc.DB.WithContext(c.Context()).Table("complain_user_details").
+			Select("complain_user_details.complain_id, complains.code as complain_code, complains.tracking_number as tracking, complains.order_ginee_id, complain_user_details.fee_charge, complains.updated_at as complain_updated_at, complain_settlements.period as settlement_period").
+			Joins("LEFT JOIN complains ON complains.id = complain_user_details.complain_id").
+			Joins("LEFT JOIN complain_settlements ON complain_settlements.id = complains.settlement_id").
+			Where("complain_user_details.user_id = ?", summary.UserID)
+
+		// Apply same date filters
+		if startDate != "" {
+			detailQuery = detailQuery.Where("complains.updated_at >= ?", startDate+" 00:00:00")
+		}
+		if endDate != "" {
+			detailQuery = detailQuery.Where("complains.updated_at <= ?", endDate+" 23:59:59")
+		}
+		if settled == "true" {
+			detailQuery = detailQuery.Where("complains.settlement_id IS NOT NULL")
+		} else if settled == "false" {
+			detailQuery = detailQuery.Where("complains.settlement_id IS NULL")
+		}
+
+		detailQuery = detailQuery.Order("complains.updated_at DESC")
+
+		// Scan into temporary struct with time.Time
+		type ComplainDetailRaw struct {
+			ComplainID        uint
+			ComplainCode      string
+			Tracking          string
+			OrderGineeID      string
+			FeeCharge         uint
+			ComplainUpdatedAt time.Time
+			SettlementPeriod  *string
+		}
+
+		var rawDetails []ComplainDetailRaw
+		if err := detailQuery.Scan(&rawDetails).Error; err != nil {
+			log.Println("GetUserFeeReports - Failed to retrieve complain details:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve complain details",
+			})
+		}
+
+		// Latest fee dispute status per complain of this user
+		complainIDs := make([]uint, len(rawDetails))
+		for i, raw := range rawDetails {
+			complainIDs[i] = raw.ComplainID
+		}
+		var disputes []models.ComplainFeeDispute
+		if len(complainIDs) > 0 {
+			if err := rc.DB.WithContext(c.Context()).Where("user_id = ? AND complain_id IN ?", summary.UserID, complainIDs).Order("created_at ASC, id ASC").Find(&disputes).Error; err != nil {
+				log.Println("GetUserFeeReports - Failed to retrieve fee disputes:", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Failed to retrieve fee disputes",
+				})
+			}
+		}
+		disputeStatuses := make(map[uint]string)
+		for _, dispute := range disputes {
+			disputeStatuses[dispute.ComplainID] = dispute.Status
+		}
+
+		// Format the dates
+		var details []ComplainDetailInReport
+		openFeeDisputes := 0
+		for _, raw := range rawDetails {
+			disputeStatus := disputeStatuses[raw.ComplainID]
+			if disputeStatus == models.FeeDisputeStatusOpen {
+				openFeeDisputes++
+			}
+			var settlementPeriod string
+			if raw.SettlementPeriod != nil {
+				settlementPeriod = *raw.SettlementPeriod
+			}
+			details = append(details, ComplainDetailInReport{
+				ComplainID:        raw.ComplainID,
+				ComplainCode:      raw.ComplainCode,
+				Tracking:          raw.Tracking,
+				OrderGineeID:      raw.OrderGineeID,
+				FeeCharge:         raw.FeeCharge,
+				ComplainUpdatedAt: raw.ComplainUpdatedAt.Format("02-01-2006 15:04:05"),
+				DisputeStatus:     disputeStatus,
+				SettlementPeriod:  settlementPeriod,
+			})
+		}
+
+		report := UserFeeReportWithDetails{
+			UserID:          summary.UserID,
+			Username:        summary.Username,
+			FullName:        summary.FullName,
+			Email:           summary.Email,
+			TotalComplaints: summary.TotalComplaints,
+			TotalFeeCharge:  summary.TotalFeeCharge,
+			UnsettledFee:    summary.UnsettledFee,
+			OpenFeeDisputes: openFeeDisputes,
+			ComplainDetails: details,
+		}
+
+		reports = append(reports, report)
+	}
+
+	response := UserFeeReportsWithDetailsListResponse{
+		Reports: reports,
+	}
+
+	// Build success message
+	message := "User fee reports retrieved successfully"
+	var filters []string
+
+	if startDate != "" {
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		filters = append(filters, "endDate: "+endDate)
+	}
+	if userId != "" {
+		filters = append(filters, "userId: "+userId)
+	}
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("teamId: %d", teamID))
+	}
+	if settled != "" {
+		filters = append(filters, "settled: "+settled)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetUserFeeReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    response,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: totalCount,
+		},
+	})
+}
+
+// BuildBillingReport aggregates the billing rows of completed outbound orders of a month per channel, store and currency with their totals
+func (rc *ReportController) BuildBillingReport(ctx context.Context, startOfMonth time.Time, channel, store, orderSource string) ([]BillingReportRow, BillingReportRow, error) {
+	endOfMonth := startOfMonth.AddDate(0, 1, 0)
+
+	// Aggregate completed outbound orders with their stored totals. Orders are billed once, in the month of their
+	// first outbound scan, duplicate outbound rows of a tracking number are not billed again
+	type billingShipment struct {
+		Channel       string
+		Store         string
+		Currency      string
+		ShippedOrders int64
+		TotalItems    int64
+		TotalValue    int64
+	}
+
+	shipmentQuery := rc.DB.WithContext(ctx).Table("orders").
+		Select("orders.channel, orders.store, orders.currency, COUNT(*) as shipped_orders, COALESCE(SUM(orders.total_quantity), 0) as total_items, COALESCE(SUM(orders.total_value), 0) as total_value").
+		Where("orders.tracking_number IN (?)", rc.DB.Table("outbounds").Select("tracking_number").Group("tracking_number").Having("MIN(created_at) >= ? AND MIN(created_at) < ?", startOfMonth, endOfMonth)).
+		Where("orders.processing_status = ?", models.ProcessingStatusOutboundCompleted)
+
+	// Aggregate complaint deductions of the month per order channel/store and currency
+	type billingDeduction struct {
+		Channel             string
+		Store               string
+		Currency            string
+		Complaints          int64
+		ComplaintDeductions int64
+	}
+
+	deductionQuery := rc.DB.WithContext(ctx).Table("complains").
+		Select("orders.channel, orders.store, orders.currency, COUNT(DISTINCT complains.id) as complaints, COALESCE(SUM(complains.total_fee), 0) as complaint_deductions").
+		Joins("JOIN orders ON orders.tracking_number = complains.tracking_number").
+		Where("complains.created_at >= ? AND complains.created_at < ?", startOfMonth, endOfMonth)
+
+	// Apply channel, store and order source filters
+	if channel != "" {
+		shipmentQuery = shipmentQuery.Where("LOWER(orders.channel) = LOWER(?)", channel)
+		deductionQuery = deductionQuery.Where("LOWER(orders.channel) = LOWER(?)", channel)
+	}
+	if store != "" {
+		shipmentQuery = shipmentQuery.Where("LOWER(orders.store) = LOWER(?)", store)
+		deductionQuery = deductionQuery.Where("LOWER(orders.store) = LOWER(?)", store)
+	}
+	if orderSource != "" {
+		shipmentQuery = shipmentQuery.Where("orders.order_source = ?", orderSource)
+		deductionQuery = deductionQuery.Where("orders.order_source = ?", orderSource)
+	}
+
+	var shipments []billingShipment
+	if err := shipmentQuery.Group("orders.channel, orders.store, orders.currency").Scan(&shipments).Error; err != nil {
+		return nil, BillingReportRow{}, fmt.Errorf("failed to aggregate shipped orders: %w", err)
+	}
+
+	var deductions []billingDeduction
+	if err := deductionQuery.Group("orders.channel, orders.store, orders.currency").Scan(&deductions).Error; err != nil {
+		return nil, BillingReportRow{}, fmt.Errorf("failed to aggregate complaint deductions: %w", err)
+	}
+
+	// Merge shipments and deductions by channel/store and currency
+	reports := []BillingReportRow{}
+	rowIndex := make(map[string]int)
+	rowFor := func(channel, store, currency string) *BillingReportRow {
+		key := channel + "\x00" + store + "\x00" + currency
+		if idx, ok := rowIndex[key]; ok {
+			return &reports[idx]
+		}
+		rowIndex[key] = len(reports)
+		reports = append(reports, BillingReportRow{Channel: channel, Store: store, Currency: currency})
+		return &reports[len(reports)-1]
+	}
+
+	for _, shipment := range shipments {
+		row := rowFor(shipment.Channel, shipment.Store, shipment.Currency)
+		row.ShippedOrders = shipment.ShippedOrders
+		row.TotalItems = shipment.TotalItems
+		row.TotalValue = shipment.TotalValue
+	}
+	for _, deduction := range deductions {
+		row := rowFor(deduction.Channel, deduction.Store, deduction.Currency)
+		row.Complaints = deduction.Complaints
+		row.ComplaintDeductions = deduction.ComplaintDeductions
+	}
+
+	// Totals only carry a currency when every row shares it
+	totals := BillingReportRow{Channel: "TOTAL"}
+	if len(reports) > 0 {
+		totals.Currency = reports[0].Currency
+	}
+	for i := range reports {
+		if reports[i].Currency != totals.Currency {
+			totals.Currency = "MIXED"
+		}
+		reports[i].NetValue = reports[i].TotalValue - reports[i].ComplaintDeductions
+		totals.ShippedOrders += reports[i].ShippedOrders
+		totals.TotalItems += reports[i].TotalItems
+		totals.TotalValue += reports[i].TotalValue
+		totals.Complaints += reports[i].Complaints
+		totals.ComplaintDeductions += reports[i].ComplaintDeductions
+		totals.NetValue += reports[i].NetValue
+	}
+
+	return reports, totals, nil
+}
+
+// BuildBillingReportXLSX exports the billing rows of a month to an XLSX workbook
+func BuildBillingReportXLSX(month string, reports []BillingReportRow, totals BillingReportRow) (*bytes.Buffer, error) {
+	headers := []string{"Channel", "Store", "Currency", "Shipped Orders", "Total Items", "Total Value", "Complaints", "Complaint Deductions", "Net Value"}
+	rows := make([][]interface{}, 0, len(reports)+1)
+	for _, row := range append(reports, totals) {
+		rows = append(rows, []interface{}{row.Channel, row.Store, row.Currency, row.ShippedOrders, row.TotalItems, row.TotalValue, row.Complaints, row.ComplaintDeductions, row.NetValue})
+	}
+	return utils.BuildXLSX("Billing "+month, headers, rows)
+}
+
+// GetBillingReports generates the monthly billing report per channel and store
+// @Summary Get Billing Reports
+// @Description Generate the monthly billing report of completed outbound orders per channel, store and currency with total item value and complaint deductions, optionally exported to XLSX. Orders are billed once, in the month of their first outbound scan
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param month query string false "Billing month (YYYY-MM format, default current month)"
+// @Param channel query string false "Filter by order channel"
+// @Param store query string false "Filter by order store"
+// @Param orderSource query string false "Filter by order source (marketplace, offline, replacement, internal)"
+// @Param format query string false "Response format (json or xlsx)" default(json)
+// @Success 200 {object} utils.SuccessResponse{data=BillingReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/billing [get]
+func (rc *ReportController) GetBillingReports(c fiber.Ctx) error {
+	log.Println("GetBillingReports called")
+	// Parse query parameters
+	month := c.Query("month", time.Now().Format("2006-01"))
+	channel := strings.TrimSpace(c.Query("channel", ""))
+	store := strings.TrimSpace(c.Query("store", ""))
+	orderSource := strings.TrimSpace(c.Query("orderSource", ""))
+	format := strings.ToLower(c.Query("format", "json"))
+
+	// Parse month and validate format
+	startOfMonth, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid month format. Use YYYY-MM.",
+		})
+	}
+	if orderSource != "" && !models.IsValidOrderSource(orderSource) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid orderSource. Use marketplace, offline, replacement or internal.",
+		})
+	}
+	if format != "json" && format != "xlsx" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or xlsx.",
+		})
+	}
+
+	reports, totals, err := rc.BuildBillingReport(c.Context(), startOfMonth, channel, store, orderSource)
+	if err != nil {
+		log.Println("GetBillingReports - Failed to build billing reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve billing reports",
+		})
+	}
+
+	// Export to XLSX if requested
+	if format == "xlsx" {
+		buffer, err := BuildBillingReportXLSX(month, reports, totals)
+		if err != nil {
+			log.Println("GetBillingReports - Failed to build XLSX:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to export billing reports",
+			})
+		}
+
+		log.Println("GetBillingReports completed successfully")
+		c.Set(fiber.HeaderContentType, utils.XLSXContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"billing-report-%s.xlsx\"", month))
+		return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+	}
+
+	// Build success message
+	message := "Billing reports retrieved successfully"
+	filters := []string{"month: " + month}
+
+	if channel != "" {
+		filters = append(filters, "channel: "+channel)
+	}
+
+	if store != "" {
+		filters = append(filters, "store: "+store)
+	}
+
+	if orderSource != "" {
+		filters = append(filters, "order source: "+orderSource)
+	}
+
+	message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+
+	log.Println("GetBillingReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: BillingReportResponse{
+			Month:   month,
+			Reports: reports,
+			Totals:  totals,
+		},
+	})
+}
+
+// BuildOrderReconciliationReport reconciles the orders received in a month per channel, store and currency with their totals
+func (rc *ReportController) BuildOrderReconciliationReport(ctx context.Context, startOfMonth time.Time, channel, store, orderSource string) ([]OrderReconciliationRow, OrderReconciliationRow, error) {
+	endOfMonth := startOfMonth.AddDate(0, 1, 0)
+
+	// Split shipments belong to the month of their original order
+	query := rc.DB.WithContext(ctx).Table("orders").
+		Select(`orders.channel, orders.store, orders.currency,
+			COUNT(*) as orders, COALESCE(SUM(orders.total_value), 0) as ordered_value,
+			SUM(CASE WHEN orders.processing_status = ? AND orders.event_status <> ? THEN 1 ELSE 0 END) as shipped_orders,
+			COALESCE(SUM(CASE WHEN orders.processing_status = ? AND orders.event_status <> ? THEN orders.total_value ELSE 0 END), 0) as shipped_value,
+			SUM(CASE WHEN orders.event_status = ? THEN 1 ELSE 0 END) as canceled_orders,
+			COALESCE(SUM(CASE WHEN orders.event_status = ? THEN orders.total_value ELSE 0 END), 0) as canceled_value`,
+			models.ProcessingStatusOutboundCompleted, models.EventStatusCanceled,
+			models.ProcessingStatusOutboundCompleted, models.EventStatusCanceled,
+			models.EventStatusCanceled, models.EventStatusCanceled).
+		Joins("LEFT JOIN orders parent_orders ON parent_orders.id = orders.parent_order_id").
+		Where("COALESCE(parent_orders.created_at, orders.created_at) >= ? AND COALESCE(parent_orders.created_at, orders.created_at) < ?", startOfMonth, endOfMonth).
+		Where("orders.event_status <> ?", models.EventStatusMerged)
+
+	// Apply channel, store and order source filters
+	if channel != "" {
+		query = query.Where("LOWER(orders.channel) = LOWER(?)", channel)
+	}
+	if store != "" {
+		query = query.Where("LOWER(orders.store) = LOWER(?)", store)
+	}
+	if orderSource != "" {
+		query = query.Where("orders.order_source = ?", orderSource)
+	}
+
+	reports := []OrderReconciliationRow{}
+	if err := query.Group("orders.channel, orders.store, orders.currency").Order("orders.channel, orders.store, orders.currency").Scan(&reports).Error; err != nil {
+		return nil, OrderReconciliationRow{}, fmt.Errorf("failed to aggregate orders: %w", err)
+	}
+
+	// Totals only carry a currency when every row shares it
+	totals := OrderReconciliationRow{Channel: "TOTAL"}
+	if len(reports) > 0 {
+		totals.Currency = reports[0].Currency
+	}
+	for i := range reports {
+		if reports[i].Currency != totals.Currency {
+			totals.Currency = "MIXED"
+		}
+		reports[i].OpenOrders = reports[i].Orders - reports[i].ShippedOrders - reports[i].CanceledOrders
+		reports[i].OpenValue = reports[i].OrderedValue - reports[i].ShippedValue - reports[i].CanceledValue
+		totals.Orders += reports[i].Orders
+		totals.OrderedValue += reports[i].OrderedValue
+		totals.ShippedOrders += reports[i].ShippedOrders
+		totals.ShippedValue += reports[i].ShippedValue
+		totals.CanceledOrders += reports[i].CanceledOrders
+		totals.CanceledValue += reports[i].CanceledValue
+		totals.OpenOrders += reports[i].OpenOrders
+		totals.OpenValue += reports[i].OpenValue
+	}
+
+	return reports, totals, nil
+}
+
+// BuildOrderReconciliationReportXLSX exports the order reconciliation rows of a month to an XLSX workbook
+func BuildOrderReconciliationReportXLSX(month string, reports []OrderReconciliationRow, totals OrderReconciliationRow) (*bytes.Buffer, error) {
+	headers := []string{"Channel", "Store", "Currency", "Orders", "Ordered Value", "Shipped Orders", "Shipped Value", "Canceled Orders", "Canceled Value", "Open Orders", "Open Value"}
+	rows := make([][]interface{}, 0, len(reports)+1)
+	for _, row := range append(reports, totals) {
+		rows = append(rows, []interface{}{row.Channel, row.Store, row.Currency, row.Orders, row.OrderedValue, row.ShippedOrders, row.ShippedValue, row.CanceledOrders, row.CanceledValue, row.OpenOrders, row.OpenValue})
+	}
+	return utils.BuildXLSX("Reconciliation "+month, headers, rows)
+}
+
+// GetOrderReconciliationReports reconciles the value of the orders received in a month
+// @Summary Get Order Reconciliation Reports
+// @Description Reconcile the stored order totals of the orders received in a month per channel, store and currency into shipped, canceled and still open value, optionally exported to XLSX. Split shipments count in the month of their original order and merged orders are counted in the order they were merged into.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param month query string false "Order month (YYYY-MM format, default current month)"
+// @Param channel query string false "Filter by order channel"
+// @Param store query string false "Filter by order store"
+// @Param orderSource query string false "Filter by order source (marketplace, offline, replacement, internal)"
+// @Param format query string false "Response format (json or xlsx)" default(json)
+// @Success 200 {object} utils.SuccessResponse{data=OrderReconciliationResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/order-reconciliation [get]
+func (rc *ReportController) GetOrderReconciliationReports(c fiber.Ctx) error {
+	log.Println("GetOrderReconciliationReports called")
+	// Parse query parameters
+	month := c.Query("month", time.Now().Format("2006-01"))
+	channel := strings.TrimSpace(c.Query("channel", ""))
+	store := strings.TrimSpace(c.Query("store", ""))
+	orderSource := strings.TrimSpace(c.Query("orderSource", ""))
+	format := strings.ToLower(c.Query("format", "json"))
+
+	// Parse month and validate format
+	startOfMonth, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid month format. Use YYYY-MM.",
+		})
+	}
+	if orderSource != "" && !models.IsValidOrderSource(orderSource) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid orderSource. Use marketplace, offline, replacement or internal.",
+		})
+	}
+	if format != "json" && format != "xlsx" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or xlsx.",
+		})
+	}
+
+	reports, totals, err := rc.BuildOrderReconciliationReport(c.Context(), startOfMonth, channel, store, orderSource)
+	if err != nil {
+		log.Println("GetOrderReconciliationReports - Failed to build order reconciliation reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order reconciliation reports",
+		})
+	}
+
+	// Export to XLSX if requested
+	if format == "xlsx" {
+		buffer, err := BuildOrderReconciliationReportXLSX(month, reports, totals)
+		if err != nil {
+			log.Println("GetOrderReconciliationReports - Failed to build XLSX:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to export order reconciliation reports",
+			})
+		}
+
+		log.Println("GetOrderReconciliationReports completed successfully")
+		c.Set(fiber.HeaderContentType, utils.XLSXContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"order-reconciliation-%s.xlsx\"", month))
+		return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+	}
+
+	// Build success message
+	message := "Order reconciliation reports retrieved successfully"
+	filters := []string{"month: " + month}
+
+	if channel != "" {
+		filters = append(filters, "channel: "+channel)
+	}
+
+	if store != "" {
+		filters = append(filters, "store: "+store)
+	}
+
+	if orderSource != "" {
+		filters = append(filters, "order source: "+orderSource)
+	}
+
+	message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+
+	log.Println("GetOrderReconciliationReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: OrderReconciliationResponse{
+			Month:   month,
+			Reports: reports,
+			Totals:  totals,
+		},
+	})
+}
+
+// resolveMasking returns the masking profile requested with the masking query parameter for the current user's roles
+func resolveMasking(c fiber.Ctx, policy utils.MaskingPolicy) (string, error) {
+	userRoles, _ := c.Locals("userRoles").([]string)
+	return policy.Resolve(userRoles, strings.ToLower(strings.TrimSpace(c.Query("masking", ""))))
+}
+
+// maskingErrorResponse maps masking profile errors to the matching HTTP response
+func maskingErrorResponse(c fiber.Ctx, policy utils.MaskingPolicy, err error) error {
+	if errors.Is(err, utils.ErrMaskingProfileNotAllowed) {
+		userRoles, _ := c.Locals("userRoles").([]string)
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Masking profile not allowed for your role. Use " + policy.ProfileForRoles(userRoles) + " or a more masked profile.",
+		})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Invalid masking. Use full, partial or anonymized.",
+	})
+}
+
+// BuildDataQualityReport checks the orders created in a date range for suspicious data, newest first.
+// orderSource all checks every order, issue only keeps the orders with that issue while the counts cover every issue.
+// Buyer names and addresses are masked with the masking profile, also within the issue details.
+// Returns the flagged orders, the count per issue and the number of orders checked.
+func (rc *ReportController) BuildDataQualityReport(ctx context.Context, start, end time.Time, issue, orderSource, masking string) ([]DataQualityOrderRow, []DataQualityIssueCount, int64, error) {
+	checker, err := utils.LoadDataQualityChecker(rc.DB.WithContext(ctx))
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to load reference data: %w", err)
+	}
+
+	query := rc.DB.WithContext(ctx).Model(&models.Order{}).Preload("OrderDetails").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Where("event_status <> ?", models.EventStatusMerged).
+		Order("created_at DESC, id DESC")
+	if orderSource != "all" {
+		query = query.Where("order_source = ?", orderSource)
+	}
+
+	counts := make(map[string]int64)
+	rows := []DataQualityOrderRow{}
+	var checked int64
+	var orders []models.Order
+	result := query.FindInBatches(&orders, 500, func(tx *gorm.DB, batch int) error {
+		for i := range orders {
+			checked++
+			issues := checker.Check(&orders[i])
+			if len(issues) == 0 {
+				continue
+			}
+
+			// Count each issue type once per order
+			seen := make(map[string]bool)
+			keep := issue == ""
+			for _, found := range issues {
+				if !seen[found.Type] {
+					seen[found.Type] = true
+					counts[found.Type]++
+				}
+				if found.Type == issue {
+					keep = true
+				}
+			}
+			if !keep {
+				continue
+			}
+			for j := range issues {
+				issues[j].Detail = utils.MaskText(masking, issues[j].Detail, orders[i].Buyer, orders[i].Address)
+			}
+
+			rows = append(rows, DataQualityOrderRow{
+				OrderID:        orders[i].ID,
+				OrderGineeID:   orders[i].OrderGineeID,
+				TrackingNumber: orders[i].TrackingNumber,
+				Channel:        orders[i].Channel,
+				Store:          orders[i].Store,
+				Courier:        orders[i].Courier,
+				Buyer:          utils.MaskName(masking, orders[i].Buyer),
+				OrderSource:    orders[i].OrderSource,
+				CreatedAt:      orders[i].CreatedAt.Format("02-01-2006 15:04:05"),
+				Issues:         issues,
+			})
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return nil, nil, 0, fmt.Errorf("failed to check orders: %w", result.Error)
+	}
+
+	byIssue := make([]DataQualityIssueCount, len(utils.DataQualityIssueTypes))
+	for i, issueType := range utils.DataQualityIssueTypes {
+		byIssue[i] = DataQualityIssueCount{Type: issueType, Orders: counts[issueType]}
+	}
+
+	return rows, byIssue, checked, nil
+}
+
+// BuildDataQualityReportXLSX exports the flagged orders to an XLSX workbook with one row per issue
+func BuildDataQualityReportXLSX(rows []DataQualityOrderRow) (*bytes.Buffer, error) {
+	headers := []string{"Order ID", "Order Ginee ID", "Tracking Number", "Channel", "Store", "Courier", "Buyer", "Order Source", "Created At", "Issue", "Detail"}
+	sheetRows := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		for _, issue := range row.Issues {
+			sheetRows = append(sheetRows, []interface{}{row.OrderID, row.OrderGineeID, row.TrackingNumber, row.Channel, row.Store, row.Courier, row.Buyer, row.OrderSource, row.CreatedAt, issue.Type, issue.Detail})
+		}
+	}
+	return utils.BuildXLSX("Data Quality", headers, sheetRows)
+}
+
+// GetDataQualityReports lists orders with suspicious data
+// @Summary Get Data Quality Reports
+// @Description List the orders of a date range with suspicious data to clean up upstream in Ginee: missing courier or tracking number, tracking numbers not matching the courier format, zero priced details, missing or implausible buyer and address, SKUs repeated within an order and channels not set up in the channel table. Marketplace orders only unless orderSource is given, newest first, optionally exported to XLSX
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of orders per page" default(10)
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to 30 days ago"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param issue query string false "Filter by issue (missing_courier, missing_tracking_number, malformed_tracking_number, zero_price, buyer_anomaly, address_anomaly, duplicate_sku, unknown_channel)"
+// @Param orderSource query string false "Order source (marketplace, offline, replacement, internal, all)" default(marketplace)
+// @Param masking query string false "Buyer data masking (full, partial, anonymized), defaults to the least masked profile allowed for your role"
+// @Param format query string false "Response format (json or xlsx)" default(json)
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=DataQualityReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/data-quality [get]
+func (rc *ReportController) GetDataQualityReports(c fiber.Ctx) error {
+	log.Println("GetDataQualityReports called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+
+	// Parse query parameters
+	now := time.Now()
+	startDate := c.Query("startDate", now.AddDate(0, 0, -30).Format("2006-01-02"))
+	endDate := c.Query("endDate", now.Format("2006-01-02"))
+	issue := strings.ToLower(strings.TrimSpace(c.Query("issue", "")))
+	orderSource := strings.ToLower(strings.TrimSpace(c.Query("orderSource", models.OrderSourceMarketplace)))
+	format := strings.ToLower(c.Query("format", "json"))
+
+	// Validate date formats and range
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	if issue != "" && !utils.IsDataQualityIssueType(issue) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid issue. Use " + strings.Join(utils.DataQualityIssueTypes, ", ") + ".",
+		})
+	}
+	if orderSource != "all" && !models.IsValidOrderSource(orderSource) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid orderSource. Use marketplace, offline, replacement, internal or all.",
+		})
+	}
+	if format != "json" && format != "xlsx" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid format. Use json or xlsx.",
+		})
+	}
+
+	masking, err := resolveMasking(c, rc.Masking)
+	if err != nil {
+		return maskingErrorResponse(c, rc.Masking, err)
+	}
+
+	rows, byIssue, checked, err := rc.BuildDataQualityReport(c.Context(), start, end, issue, orderSource, masking)
+	if err != nil {
+		log.Println("GetDataQualityReports - Failed to build data quality reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve data quality reports",
+		})
+	}
+
+	// Export to XLSX if requested
+	if format == "xlsx" {
+		buffer, err := BuildDataQualityReportXLSX(rows)
+		if err != nil {
+			log.Println("GetDataQualityReports - Failed to build XLSX:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to export data quality reports",
+			})
+		}
+
+		log.Println("GetDataQualityReports completed successfully")
+		c.Set(fiber.HeaderContentType, utils.XLSXContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"data-quality-%s-%s.xlsx\"", startDate, endDate))
+		return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+	}
+
+	// Paginate the flagged orders
+	total := int64(len(rows))
+	offset := min((page-1)*limit, len(rows))
+	pageRows := rows[offset:min(offset+limit, len(rows))]
+
+	// Build success message
+	message := "Data quality reports retrieved successfully"
+	filters := []string{"date: " + startDate + " to " + endDate, "orderSource: " + orderSource, "masking: " + masking}
+
+	if issue != "" {
+		filters = append(filters, "issue: "+issue)
+	}
+
+	message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+
+	log.Println("GetDataQualityReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data: DataQualityReportResponse{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Checked:   checked,
+			ByIssue:   byIssue,
+			Orders:    pageRows,
+		},
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetShortageReports generates the pick shortage report with aging
+// @Summary Get Shortage Reports
+// @Description List stock shortages declared during picking, oldest first, with aging buckets
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (open, resolved, all)" default(open)
+// @Param sku query string false "Filter by SKU"
+// @Success 200 {object} utils.SuccessTotaledResponse{data=ShortageReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/shortages [get]
+func (rc *ReportController) GetShortageReports(c fiber.Ctx) error {
+	log.Println("GetShortageReports called")
+	// Parse query parameters
+	status := c.Query("status", "open")
+	sku := strings.TrimSpace(c.Query("sku", ""))
+
+	if status != "open" && status != "resolved" && status != "all" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid status. Use open, resolved or all.",
+		})
+	}
+
+	// Build base query, oldest shortages first
+	var shortages []models.PickShortage
+	query := rc.DB.WithContext(c.Context()).Model(&models.PickShortage{}).Preload("DeclareUser").Preload("ResolveUser").Order("created_at ASC")
+
+	if status != "all" {
+		query = query.Where("status = ?", status)
+	}
+
+	if sku != "" {
+		query = query.Where("sku ILIKE ?", "%"+sku+"%")
+	}
+
+	// Get total count
+	var total int64
+	query.Count(&total)
+
+	// retrieve results
+	if err := query.Find(&shortages).Error; err != nil {
+		log.Println("GetShortageReports - Failed to retrieve shortage reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve shortage reports",
+		})
+	}
+
+	// Format response and bucket by aging
+	aging := []ShortageAgingBucket{
+		{Label: "< 24h"},
+		{Label: "24-48h"},
+		{Label: "48-72h"},
+		{Label: "> 72h"},
+	}
+	now := time.Now()
+	shortageList := make([]models.PickShortageResponse, len(shortages))
+	for i, shortage := range shortages {
+		shortageList[i] = *shortage.ToResponse()
+
+		hours := utils.PickShortageAging(&shortage, now).Hours()
+		shortageList[i].AgingHours = float64(int(hours*10)) / 10
+		switch {
+		case hours < 24:
+			aging[0].Count++
+		case hours < 48:
+			aging[1].Count++
+		case hours < 72:
+			aging[2].Count++
+		default:
+			aging[3].Count++
+		}
+	}
+
+	// Build success message
+	message := "Shortage reports retrieved successfully"
+	var filters []string
+
+	filters = append(filters, "status: "+status)
+
+	if sku != "" {
+		filters = append(filters, "sku: "+sku)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetShortageReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: message,
+		Data: ShortageReportResponse{
+			Aging:     aging,
+			Shortages: shortageList,
+		},
+		Total: total,
+	})
+}
+
+// GetPickerPerformanceReports generates the picker performance report with retraining flags
+// @Summary Get Picker Performance Reports
+// @Description Per picker over a date range: orders picked, shortages declared, complaints attributed, complaints per 100 picked orders and whether the picker is flagged for retraining. Defaults to the last 30 days, most complaints first
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD format)"
+// @Param endDate query string false "End date (YYYY-MM-DD format)"
+// @Param teamId query int false "Filter by team ID"
+// @Param flagged query bool false "Only pickers flagged for retraining" default(false)
+// @Success 200 {object} utils.SuccessTotaledResponse{data=PickerPerformanceReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/picker-performance [get]
+func (rc *ReportController) GetPickerPerformanceReports(c fiber.Ctx) error {
+	log.Println("GetPickerPerformanceReports called")
+	// Parse date range, defaults to the last 30 days
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	startDate := c.Query("startDate", today.AddDate(0, 0, -29).Format("2006-01-02"))
+	endDate := c.Query("endDate", today.Format("2006-01-02"))
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	flagged := c.Query("flagged", "false") == "true"
+
+	// Active pickers
+	db := rc.DB.WithContext(c.Context())
+	var pickers []models.User
+	pickerQuery := db.Model(&models.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.role_name = ? AND users.is_active = ?", "picker", true).
+		Order("users.full_name ASC")
+	if teamID > 0 {
+		pickerQuery = pickerQuery.Where("users.id IN (?)", utils.TeamMembersQuery(rc.DB, uint(teamID)))
+	}
+	if err := pickerQuery.Find(&pickers).Error; err != nil {
+		log.Println("GetPickerPerformanceReports - Failed to retrieve pickers:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picker performance reports",
+		})
+	}
+
+	// Counts per picker over the date range
+	failed := func(err error) error {
+		log.Println("GetPickerPerformanceReports - Failed to retrieve picker performance:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve picker performance reports",
+		})
+	}
+	picked, err := pickerCounts(db.Model(&models.PickedOrder{}).
+		Select("picked_by as user_id, COUNT(*) as count").
+		Where("created_at >= ? AND created_at < ?", start, end).Group("picked_by"))
+	if err != nil {
+		return failed(err)
+	}
+	shortages, err := pickerCounts(db.Model(&models.PickShortage{}).
+		Select("declared_by as user_id, COUNT(*) as count").
+		Where("created_at >= ? AND created_at < ?", start, end).Group("declared_by"))
+	if err != nil {
+		return failed(err)
+	}
+	complaints, err := pickerCounts(db.Table("complain_user_details").
+		Select("complain_user_details.user_id as user_id, COUNT(DISTINCT complain_user_details.complain_id) as count").
+		Joins("JOIN complains ON complains.id = complain_user_details.complain_id").
+		Where("complains.created_at >= ? AND complains.created_at < ?", start, end).Group("complain_user_details.user_id"))
+	if err != nil {
+		return failed(err)
+	}
+
+	// Open retraining flags
+	var openTasks []models.TrainingTask
+	if err := db.Where("status = ?", models.TrainingTaskStatusOpen).Find(&openTasks).Error; err != nil {
+		return failed(err)
+	}
+	tasksByUser := make(map[uint]models.TrainingTask, len(openTasks))
+	for _, task := range openTasks {
+		tasksByUser[task.UserID] = task
+	}
+
+	rows := []PickerPerformanceRow{}
+	for _, picker := range pickers {
+		row := PickerPerformanceRow{
+			UserID:       picker.ID,
+			Username:     picker.Username,
+			FullName:     picker.FullName,
+			PickedOrders: picked[picker.ID],
+			Shortages:    shortages[picker.ID],
+			Complaints:   complaints[picker.ID],
+		}
+		if row.PickedOrders > 0 {
+			row.ComplaintRate = math.Round(float64(row.Complaints)/float64(row.PickedOrders)*10000) / 100
+		}
+		if task, ok := tasksByUser[picker.ID]; ok {
+			taskID := task.ID
+			flaggedAt := task.CreatedAt.Format("02-01-2006 15:04:05")
+			row.RetrainingFlagged = true
+			row.TrainingTaskID = &taskID
+			row.FlaggedAt = &flaggedAt
+		}
+		if flagged && !row.RetrainingFlagged {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Complaints > rows[j].Complaints
+	})
+
+	// Build success message
+	message := "Picker performance reports retrieved successfully"
+	var filters []string
+
+	filters = append(filters, "date: "+startDate+" to "+endDate)
+
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("teamId: %d", teamID))
+	}
+
+	if flagged {
+		filters = append(filters, "flagged: true")
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetPickerPerformanceReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: message,
+		Data: PickerPerformanceReportResponse{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Pickers:   rows,
+		},
+		Total: int64(len(rows)),
+	})
+}
+
+// pickerCounts scans a query grouped by user into counts per user ID, the query selects user_id and count
+func pickerCounts(query *gorm.DB) (map[uint]int64, error) {
+	var rows []struct {
+		UserID uint
+		Count  int64
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetPickAnomalyReports lists items picked anomalously fast or slow
+// @Summary Get Pick Anomaly Reports
+// @Description List items of individually picked orders confirmed faster than they can be located and scanned (possible skipped verification) or taking unusually long, measured between consecutive item confirmations of an order, with the count per picker. Defaults to the last 7 days, latest first
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of anomalies per page" default(10)
+// @Param startDate query string false "Start date (YYYY-MM-DD format)"
+// @Param endDate query string false "End date (YYYY-MM-DD format)"
+// @Param type query string false "Filter by anomaly type (fast, slow)"
+// @Param pickerId query int false "Filter by picker ID"
+// @Param teamId query int false "Filter by team ID"
+// @Param sku query string false "Filter by SKU"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=PickAnomalyReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/pick-anomalies [get]
+func (rc *ReportController) GetPickAnomalyReports(c fiber.Ctx) error {
+	log.Println("GetPickAnomalyReports called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Parse date range, defaults to the last 7 days
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	startDate := c.Query("startDate", today.AddDate(0, 0, -6).Format("2006-01-02"))
+	endDate := c.Query("endDate", today.Format("2006-01-02"))
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	anomalyType := strings.ToLower(c.Query("type", ""))
+	if anomalyType != "" && anomalyType != models.PickAnomalyFast && anomalyType != models.PickAnomalySlow {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid type. Use fast or slow.",
+		})
+	}
+	pickerID, _ := strconv.ParseUint(c.Query("pickerId", "0"), 10, 32)
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	sku := strings.TrimSpace(c.Query("sku", ""))
+
+	// Build base query
+	db := rc.DB.WithContext(c.Context())
+	query := db.Model(&models.PickAnomaly{}).Where("pick_anomalies.item_picked_at >= ? AND pick_anomalies.item_picked_at < ?", start, end)
+	if anomalyType != "" {
+		query = query.Where("pick_anomalies.type = ?", anomalyType)
+	}
+	if pickerID > 0 {
+		query = query.Where("pick_anomalies.picked_by = ?", pickerID)
+	}
+	if teamID > 0 {
+		query = query.Where("pick_anomalies.picked_by IN (?)", utils.TeamMembersQuery(rc.DB, uint(teamID)))
+	}
+	if sku != "" {
+		query = query.Where("pick_anomalies.sku ILIKE ?", "%"+sku+"%")
+	}
+
+	// Count per picker over all matching anomalies
+	var pickerCountRows []struct {
+		UserID   uint
+		FullName string
+		Type     string
+		Count    int64
+	}
+	if err := query.Session(&gorm.Session{}).
+		Select("pick_anomalies.picked_by as user_id, users.full_name, pick_anomalies.type, COUNT(*) as count").
+		Joins("LEFT JOIN users ON users.id = pick_anomalies.picked_by").
+		Group("pick_anomalies.picked_by, users.full_name, pick_anomalies.type").
+		Scan(&pickerCountRows).Error; err != nil {
+		log.Println("GetPickAnomalyReports - Failed to count anomalies per picker:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pick anomaly reports",
+		})
+	}
+	byPicker := []PickAnomalyPickerCount{}
+	pickerIndex := make(map[uint]int)
+	for _, row := range pickerCountRows {
+		index, ok := pickerIndex[row.UserID]
+		if !ok {
+			index = len(byPicker)
+			pickerIndex[row.UserID] = index
+			byPicker = append(byPicker, PickAnomalyPickerCount{UserID: row.UserID, FullName: row.FullName})
+		}
+		switch row.Type {
+		case models.PickAnomalyFast:
+			byPicker[index].Fast += row.Count
+		case models.PickAnomalySlow:
+			byPicker[index].Slow += row.Count
+		}
+		byPicker[index].Total += row.Count
+	}
+	sort.SliceStable(byPicker, func(i, j int) bool {
+		if byPicker[i].Total != byPicker[j].Total {
+			return byPicker[i].Total > byPicker[j].Total
+		}
+		return byPicker[i].FullName < byPicker[j].FullName
+	})
+
+	// Get total count for pagination
+	var total int64
+	query.Session(&gorm.Session{}).Count(&total)
+
+	// Retrieve paginated results, latest first
+	var anomalies []models.PickAnomaly
+	if err := query.Preload("PickUser").Order("pick_anomalies.item_picked_at DESC").Offset(offset).Limit(limit).Find(&anomalies).Error; err != nil {
+		log.Println("GetPickAnomalyReports - Failed to retrieve anomalies:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pick anomaly reports",
+		})
+	}
+
+	anomalyList := make([]models.PickAnomalyResponse, len(anomalies))
+	for i := range anomalies {
+		anomalyList[i] = *anomalies[i].ToResponse()
+	}
+
+	// Build success message
+	message := "Pick anomaly reports retrieved successfully"
+	var filters []string
+
+	filters = append(filters, "date: "+startDate+" to "+endDate)
+
+	if anomalyType != "" {
+		filters = append(filters, "type: "+anomalyType)
+	}
+
+	if pickerID > 0 {
+		filters = append(filters, fmt.Sprintf("pickerId: %d", pickerID))
+	}
+
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("teamId: %d", teamID))
+	}
+
+	if sku != "" {
+		filters = append(filters, "sku: "+sku)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetPickAnomalyReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data: PickAnomalyReportResponse{
+			StartDate: startDate,
+			EndDate:   endDate,
+			ByPicker:  byPicker,
+			Anomalies: anomalyList,
+		},
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// courierChargeableWeightSQL computes the chargeable weight in grams of a measured outbound, 0 when nothing was measured
+var courierChargeableWeightSQL = fmt.Sprintf("GREATEST(COALESCE(weight_grams, 0), COALESCE(CEIL(length_cm * width_cm * height_cm * 1000 / %d), 0))", models.VolumetricWeightDivisor)
+
+// GetCourierWeightReports compares the weights measured at the outbound scan with the weights couriers invoiced
+// @Summary Get Courier Weight Reports
+// @Description Compare the chargeable weight measured at the outbound scan, the higher of the actual and volumetric weight, with the weight the courier invoiced, to contest overbilling. Only parcels with an imported invoice line are listed, largest overbilling first
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of parcels per page" default(10)
+// @Param startDate query string false "Filter by outbound date from (YYYY-MM-DD format)"
+// @Param endDate query string false "Filter by outbound date to (YYYY-MM-DD format)"
+// @Param expeditionSlug query string false "Filter by expedition slug"
+// @Param invoiceRef query string false "Filter by courier invoice reference"
+// @Param toleranceGrams query int false "Invoiced weight above the chargeable weight tolerated before a parcel counts as overbilled" default(100)
+// @Param overbilled query bool false "Only overbilled parcels" default(false)
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=CourierWeightReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/courier-weights [get]
+func (rc *ReportController) GetCourierWeightReports(c fiber.Ctx) error {
+	log.Println("GetCourierWeightReports called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Parse filter parameters
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	expeditionSlug := strings.TrimSpace(c.Query("expeditionSlug", ""))
+	invoiceRef := strings.TrimSpace(c.Query("invoiceRef", ""))
+	overbilled := c.Query("overbilled", "false") == "true"
+	tolerance, err := strconv.Atoi(c.Query("toleranceGrams", "100"))
+	if err != nil || tolerance < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid toleranceGrams. Use a number of grams of 0 or more.",
+		})
+	}
+
+	// Build base query of the parcels with an invoice line
+	query := rc.DB.WithContext(c.Context()).Model(&models.Outbound{}).Where("invoiced_weight_grams IS NOT NULL")
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("created_at >= ?", parsedStartDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("created_at < ?", parsedEndDate.AddDate(0, 0, 1))
+	}
+	if expeditionSlug != "" {
+		query = query.Where("expedition_slug = ?", expeditionSlug)
+	}
+	if invoiceRef != "" {
+		query = query.Where("invoice_ref = ?", invoiceRef)
+	}
+
+	// Summary over every matching parcel
+	overbilledCondition := fmt.Sprintf("%s > 0 AND invoiced_weight_grams - %s > %d", courierChargeableWeightSQL, courierChargeableWeightSQL, tolerance)
+	var summary CourierWeightSummary
+	if err := query.Session(&gorm.Session{}).Select(fmt.Sprintf(`COUNT(*) AS invoiced_parcels,
+		COUNT(*) FILTER (WHERE %s > 0) AS measured_parcels,
+		COUNT(*) FILTER (WHERE %s) AS overbilled_parcels,
+		COALESCE(SUM(invoiced_weight_grams - %s) FILTER (WHERE %s), 0) AS excess_weight_grams,
+		COALESCE(SUM(invoiced_amount) FILTER (WHERE %s), 0) AS overbilled_amount`,
+		courierChargeableWeightSQL, overbilledCondition, courierChargeableWeightSQL, overbilledCondition, overbilledCondition)).
+		Scan(&summary).Error; err != nil {
+		log.Println("GetCourierWeightReports - Failed to summarize courier weights:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve courier weight reports",
+		})
+	}
+
+	if overbilled {
+		query = query.Where(overbilledCondition)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Session(&gorm.Session{}).Count(&total)
+
+	// Retrieve paginated results, largest overbilling first and unmeasured parcels last
+	var outbounds []models.Outbound
+	if err := query.Order(fmt.Sprintf("CASE WHEN %s > 0 THEN invoiced_weight_grams - %s END DESC NULLS LAST, created_at DESC", courierChargeableWeightSQL, courierChargeableWeightSQL)).
+		Limit(limit).Offset(offset).Find(&outbounds).Error; err != nil {
+		log.Println("GetCourierWeightReports - Failed to retrieve courier weights:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve courier weight reports",
+		})
+	}
+
+	// Format response
+	parcels := make([]CourierWeightRow, len(outbounds))
+	for i, outbound := range outbounds {
+		row := CourierWeightRow{
+			OutboundID:            outbound.ID,
+			TrackingNumber:        outbound.TrackingNumber,
+			Expedition:            outbound.Expedition,
+			OutboundAt:            outbound.CreatedAt.Format("02-01-2006 15:04:05"),
+			WeightGrams:           outbound.WeightGrams,
+			LengthCm:              outbound.LengthCm,
+			WidthCm:               outbound.WidthCm,
+			HeightCm:              outbound.HeightCm,
+			VolumetricWeightGrams: outbound.VolumetricWeightGrams(),
+			ChargeableWeightGrams: outbound.ChargeableWeightGrams(),
+			InvoicedWeightGrams:   *outbound.InvoicedWeightGrams,
+			InvoicedAmount:        outbound.InvoicedAmount,
+			InvoiceRef:            outbound.InvoiceRef,
+			Status:                "unmeasured",
+		}
+		if row.ChargeableWeightGrams != nil && *row.ChargeableWeightGrams > 0 {
+			difference := row.InvoicedWeightGrams - *row.ChargeableWeightGrams
+			row.DifferenceWeightGrams = &difference
+			row.Status = "ok"
+			if difference > tolerance {
+				row.Status = "overbilled"
+			}
+		}
+		parcels[i] = row
+	}
+
+	// Build success message
+	message := "Courier weight reports retrieved successfully"
+	var filters []string
+
+	if startDate != "" {
+		filters = append(filters, "from: "+startDate)
+	}
+
+	if endDate != "" {
+		filters = append(filters, "to: "+endDate)
+	}
+
+	if expeditionSlug != "" {
+		filters = append(filters, "expeditionSlug: "+expeditionSlug)
+	}
+
+	if invoiceRef != "" {
+		filters = append(filters, "invoiceRef: "+invoiceRef)
+	}
+
+	if overbilled {
+		filters = append(filters, "overbilled: true")
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetCourierWeightReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data: CourierWeightReportResponse{
+			ToleranceGrams: tolerance,
+			Summary:        summary,
+			Parcels:        parcels,
+		},
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetSLABreachReports lists orders that missed their SentBefore deadline without an outbound scan
+// @Summary Get SLA Breach Reports
+// @Description List orders that missed their send deadline, with the stage they were stuck in and the derived reason, and the breach count per stage. Breaches are detected by a background job.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of breaches per page" default(10)
+// @Param startDate query string false "Filter by send deadline from (YYYY-MM-DD format)"
+// @Param endDate query string false "Filter by send deadline to (YYYY-MM-DD format)"
+// @Param status query string false "Filter by status (open, resolved, all)" default(all)
+// @Param stage query string false "Filter by the processing status the order was stuck in"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=SLABreachReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/sla-breaches [get]
+func (rc *ReportController) GetSLABreachReports(c fiber.Ctx) error {
+	log.Println("GetSLABreachReports called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Parse filter parameters
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	status := c.Query("status", "all")
+	stage := strings.TrimSpace(c.Query("stage", ""))
+
+	if status != "open" && status != "resolved" && status != "all" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid status. Use open, resolved or all.",
+		})
+	}
+	if stage != "" && !models.IsValidProcessingStatus(stage) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid stage " + stage,
+		})
+	}
+
+	// Build filtered query, latest deadlines first
+	query := rc.DB.WithContext(c.Context()).Model(&models.SLABreach{})
+	if startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("sent_before >= ?", parsedStartDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		query = query.Where("sent_before < ?", parsedEndDate.AddDate(0, 0, 1))
+	}
+	switch status {
+	case "open":
+		query = query.Where("resolved_at IS NULL")
+	case "resolved":
+		query = query.Where("resolved_at IS NOT NULL")
+	}
+	if stage != "" {
+		query = query.Where("stage = ?", stage)
+	}
+
+	// Count per stage over the whole filtered set
+	var byStage []SLABreachStageCount
+	if err := query.Session(&gorm.Session{}).Select("stage, COUNT(*) as count").Group("stage").Order("count DESC").Scan(&byStage).Error; err != nil {
+		log.Println("GetSLABreachReports - Failed to count breaches per stage:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve SLA breach reports",
+		})
+	}
+	var total int64
+	for i := range byStage {
+		byStage[i].Reason = utils.SLABreachReason(&models.Order{ProcessingStatus: byStage[i].Stage})
+		total += byStage[i].Count
+	}
+
+	// Retrieve paginated results
+	var breaches []models.SLABreach
+	if err := query.Session(&gorm.Session{}).Order("sent_before DESC").Offset(offset).Limit(limit).Find(&breaches).Error; err != nil {
+		log.Println("GetSLABreachReports - Failed to retrieve breaches:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve SLA breach reports",
+		})
+	}
+
+	breachList := make([]models.SLABreachResponse, len(breaches))
+	for i, breach := range breaches {
+		breachList[i] = *breach.ToResponse()
+	}
+
+	// Build success message
+	message := "SLA breach reports retrieved successfully"
+	var filters []string
+
+	filters = append(filters, "status: "+status)
+
+	if startDate != "" {
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		filters = append(filters, "endDate: "+endDate)
+	}
+	if stage != "" {
+		filters = append(filters, "stage: "+stage)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetSLABreachReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data: SLABreachReportResponse{
+			ByStage:  byStage,
+			Breaches: breachList,
+		},
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetNearExpiryReports lists lots with stock that are expired or expire within the given window
+// @Summary Get Near Expiry Reports
+// @Description List lots with remaining stock that expire within the given number of days, earliest expiry first. Expired lots are included unless excluded.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Expiry window in days" default(30)
+// @Param sku query string false "Filter by SKU"
+// @Param include_expired query bool false "Include already expired lots" default(true)
+// @Success 200 {object} utils.SuccessTotaledResponse{data=NearExpiryReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/near-expiry [get]
+func (rc *ReportController) GetNearExpiryReports(c fiber.Ctx) error {
+	log.Println("GetNearExpiryReports called")
+	// Parse query parameters
+	days, err := strconv.Atoi(c.Query("days", strconv.Itoa(models.DefaultNearExpiryDays)))
+	if err != nil || days < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid days. Use a non-negative number of days.",
+		})
+	}
+	sku := strings.TrimSpace(c.Query("sku", ""))
+	includeExpired := c.Query("include_expired", "true") != "false"
+
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	until := now.AddDate(0, 0, days).Format("2006-01-02")
+
+	// Build base query, earliest expiry first
+	var batches []models.InventoryBatch
+	query := rc.DB.WithContext(c.Context()).Model(&models.InventoryBatch{}).Preload("ReceiveUser").
+		Where("quantity > 0 AND expiry_date IS NOT NULL AND expiry_date <= ?", until).Order("expiry_date ASC, sku ASC")
+
+	if !includeExpired {
+		query = query.Where("expiry_date >= ?", today)
+	}
+
+	if sku != "" {
+		query = query.Where("sku ILIKE ?", "%"+sku+"%")
+	}
+
+	// Get total count
+	var total int64
+	query.Count(&total)
+
+	// retrieve results
+	if err := query.Find(&batches).Error; err != nil {
+		log.Println("GetNearExpiryReports - Failed to retrieve near expiry reports:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve near expiry reports",
+		})
+	}
+
+	// Manually load product data for each batch
+	products := make(map[string]*models.Product)
+	report := NearExpiryReportResponse{Days: days, Batches: make([]models.InventoryBatchResponse, len(batches))}
+	for i := range batches {
+		product, ok := products[batches[i].SKU]
+		if !ok {
+			var p models.Product
+			if err := rc.DB.WithContext(c.Context()).Where("sku = ?", batches[i].SKU).First(&p).Error; err == nil {
+				product = &p
+			}
+			products[batches[i].SKU] = product
+		}
+		batches[i].Product = product
+
+		report.Batches[i] = *batches[i].ToResponse()
+		if batches[i].ExpiryStatus(now, days) == "expired" {
+			report.ExpiredQuantity += batches[i].Quantity
+		} else {
+			report.NearQuantity += batches[i].Quantity
+		}
+	}
+
+	// Build success message
+	message := "Near expiry reports retrieved successfully"
+	var filters []string
+
+	filters = append(filters, fmt.Sprintf("days: %d", days))
+
+	if sku != "" {
+		filters = append(filters, "sku: "+sku)
+	}
+
+	if !includeExpired {
+		filters = append(filters, "excluding expired")
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetNearExpiryReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: message,
+		Data:    report,
+		Total:   total,
+	})
+}
+
+// GetErrorHotspotReports ranks the SKUs, variants or rack locations generating the most picking errors
+// @Summary Get Error Hotspot Reports
+// @Description Aggregate complaint products, QC mismatches (wrong SKU, quantity mismatch, over scan) and pick anomalies (fast or slow picks) by SKU, variant or rack location, most errors first, with a trend per period
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to 30 days ago"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param groupBy query string false "Group by sku, variant or location" default(sku)
+// @Param source query string false "Error source (all, complaint, qc, pick)" default(all)
+// @Param granularity query string false "Trend period (day, week)" default(day)
+// @Param limit query int false "Number of hotspots to return" default(20)
+// @Success 200 {object} utils.SuccessTotaledResponse{data=ErrorHotspotReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/error-hotspots [get]
+func (rc *ReportController) GetErrorHotspotReports(c fiber.Ctx) error {
+	log.Println("GetErrorHotspotReports called")
+	// Parse query parameters
+	now := time.Now()
+	startDate := c.Query("startDate", now.AddDate(0, 0, -30).Format("2006-01-02"))
+	endDate := c.Query("endDate", now.Format("2006-01-02"))
+	groupBy := strings.ToLower(c.Query("groupBy", "sku"))
+	source := strings.ToLower(c.Query("source", "all"))
+	granularity := strings.ToLower(c.Query("granularity", "day"))
+	limit, err := strconv.Atoi(c.Query("limit", "20"))
+	if err != nil || limit < 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid limit. Use a positive number.",
+		})
+	}
+
+	// Validate date formats and range
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	if groupBy != "sku" && groupBy != "variant" && groupBy != "location" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid groupBy. Use sku, variant or location.",
+		})
+	}
+	if source != "all" && source != "complaint" && source != "qc" && source != "pick" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid source. Use all, complaint, qc or pick.",
+		})
+	}
+	if granularity != "day" && granularity != "week" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid granularity. Use day or week.",
+		})
+	}
+
+	// Aggregate error events per SKU and period, granularity is validated above
+	type skuEvents struct {
+		SKU      string
+		Type     string
+		Period   time.Time
+		Count    int64
+		Quantity int64
+	}
+
+	var complaintEvents []skuEvents
+	if source == "all" || source == "complaint" {
+		if err := rc.DB.WithContext(c.Context()).Table("complain_product_details").
+			Select(fmt.Sprintf("complain_product_details.product_sku as sku, date_trunc('%s', complains.created_at) as period, COUNT(DISTINCT complains.id) as count, COALESCE(SUM(complain_product_details.quantity), 0) as quantity", granularity)).
+			Joins("JOIN complains ON complains.id = complain_product_details.complain_id").
+			Where("complains.created_at >= ? AND complains.created_at < ?", start, end).
+			Group("sku, period").Scan(&complaintEvents).Error; err != nil {
+			log.Println("GetErrorHotspotReports - Failed to aggregate complaints:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve error hotspot reports",
+			})
+		}
+	}
+
+	var mismatchEvents []skuEvents
+	if source == "all" || source == "qc" {
+		if err := rc.DB.WithContext(c.Context()).Model(&models.QCMismatch{}).
+			Select(fmt.Sprintf("sku, type, date_trunc('%s', created_at) as period, COUNT(*) as count", granularity)).
+			Where("created_at >= ? AND created_at < ?", start, end).
+			Group("sku, type, period").Scan(&mismatchEvents).Error; err != nil {
+			log.Println("GetErrorHotspotReports - Failed to aggregate QC mismatches:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve error hotspot reports",
+			})
+		}
+	}
+
+	var anomalyEvents []skuEvents
+	if source == "all" || source == "pick" {
+		if err := rc.DB.WithContext(c.Context()).Model(&models.PickAnomaly{}).
+			Select(fmt.Sprintf("sku, type, date_trunc('%s', item_picked_at) as period, COUNT(*) as count", granularity)).
+			Where("item_picked_at >= ? AND item_picked_at < ?", start, end).
+			Group("sku, type, period").Scan(&anomalyEvents).Error; err != nil {
+			log.Println("GetErrorHotspotReports - Failed to aggregate pick anomalies:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve error hotspot reports",
+			})
+		}
+	}
+
+	// Load products of all SKUs involved to resolve variant and rack location
+	skuSet := make(map[string]bool)
+	for _, event := range complaintEvents {
+		skuSet[event.SKU] = true
+	}
+	for _, event := range mismatchEvents {
+		skuSet[event.SKU] = true
+	}
+	for _, event := range anomalyEvents {
+		skuSet[event.SKU] = true
+	}
+	skus := make([]string, 0, len(skuSet))
+	for sku := range skuSet {
+		skus = append(skus, sku)
+	}
+
+	products := make(map[string]models.Product)
+	if len(skus) > 0 {
+		var productList []models.Product
+		if err := rc.DB.WithContext(c.Context()).Where("sku IN ?", skus).Find(&productList).Error; err != nil {
+			log.Println("GetErrorHotspotReports - Failed to load products:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve error hotspot reports",
+			})
+		}
+		for _, product := range productList {
+			products[product.SKU] = product
+		}
+	}
+
+	// Group the events by the requested key
+	rows := make(map[string]*ErrorHotspotRow)
+	trends := make(map[string]map[string]*ErrorHotspotTrendPoint)
+	hotspotFor := func(sku string, period time.Time) (*ErrorHotspotRow, *ErrorHotspotTrendPoint) {
+		product, known := products[sku]
+		key := sku
+		switch groupBy {
+		case "variant":
+			key = product.Variant
+		case "location":
+			key = product.Location
+		}
+		if key == "" {
+			key = "unknown"
+		}
+
+		row, ok := rows[key]
+		if !ok {
+			row = &ErrorHotspotRow{Key: key}
+			switch groupBy {
+			case "sku":
+				if known {
+					row.ProductName = product.Name
+					row.Variant = product.Variant
+					row.Location = product.Location
+				}
+			case "variant":
+				row.Variant = product.Variant
+			case "location":
+				row.Location = product.Location
+			}
+			rows[key] = row
+			trends[key] = make(map[string]*ErrorHotspotTrendPoint)
+		}
+		found := false
+		for _, existing := range row.SKUs {
+			if existing == sku {
+				found = true
+				break
+			}
+		}
+		if !found {
+			row.SKUs = append(row.SKUs, sku)
+		}
+
+		label := period.Format("2006-01-02")
+		point, ok := trends[key][label]
+		if !ok {
+			point = &ErrorHotspotTrendPoint{Period: label}
+			trends[key][label] = point
+		}
+		return row, point
+	}
+
+	for _, event := range complaintEvents {
+		row, point := hotspotFor(event.SKU, event.Period)
+		row.Complaints += event.Count
+		row.ComplaintQuantity += event.Quantity
+		row.Total += event.Count
+		point.Complaints += event.Count
+	}
+	for _, event := range mismatchEvents {
+		row, point := hotspotFor(event.SKU, event.Period)
+		switch event.Type {
+		case models.QCMismatchWrongSKU:
+			row.WrongSKUs += event.Count
+		case models.QCMismatchQuantityMismatch:
+			row.QuantityMismatches += event.Count
+		case models.QCMismatchOverScan:
+			row.OverScans += event.Count
+		}
+		row.Total += event.Count
+		point.QCMismatches += event.Count
+	}
+	for _, event := range anomalyEvents {
+		row, point := hotspotFor(event.SKU, event.Period)
+		switch event.Type {
+		case models.PickAnomalyFast:
+			row.FastPicks += event.Count
+		case models.PickAnomalySlow:
+			row.SlowPicks += event.Count
+		}
+		row.Total += event.Count
+		point.PickAnomalies += event.Count
+	}
+
+	// Rank hotspots by total errors and keep the top ones
+	hotspots := make([]ErrorHotspotRow, 0, len(rows))
+	for key, row := range rows {
+		for _, point := range trends[key] {
+			row.Trend = append(row.Trend, *point)
+		}
+		sort.Slice(row.Trend, func(i, j int) bool { return row.Trend[i].Period < row.Trend[j].Period })
+		sort.Strings(row.SKUs)
+		hotspots = append(hotspots, *row)
+	}
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Total != hotspots[j].Total {
+			return hotspots[i].Total > hotspots[j].Total
+		}
+		return hotspots[i].Key < hotspots[j].Key
+	})
+	total := int64(len(hotspots))
+	if len(hotspots) > limit {
+		hotspots = hotspots[:limit]
+	}
+
+	// Build success message
+	message := "Error hotspot reports retrieved successfully"
+	var filters []string
+
+	filters = append(filters, "date: "+startDate+" to "+endDate)
+	filters = append(filters, "groupBy: "+groupBy)
+
+	if source != "all" {
+		filters = append(filters, "source: "+source)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetErrorHotspotReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: message,
+		Data: ErrorHotspotReportResponse{
+			StartDate:   startDate,
+			EndDate:     endDate,
+			GroupBy:     groupBy,
+			Granularity: granularity,
+			Hotspots:    hotspots,
+		},
+		Total: total,
+	})
+}
+
+// GetQCStationReports compares QC throughput and errors per QC station
+// @Summary Get QC Station Reports
+// @Description Per QC station throughput (QCs, active hours, QCs per active hour, users) and errors (mismatches, voids, complaints) for layout optimization. QC performed without a station is reported as "unassigned".
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD format), defaults to 7 days ago"
+// @Param endDate query string false "End date (YYYY-MM-DD format), defaults to today"
+// @Param lane query string false "Filter by lane (ribbon, online)"
+// @Success 200 {object} utils.SuccessTotaledResponse{data=QCStationReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/qc-stations [get]
+func (rc *ReportController) GetQCStationReports(c fiber.Ctx) error {
+	log.Println("GetQCStationReports called")
+	// Parse query parameters
+	now := time.Now()
+	startDate := c.Query("startDate", now.AddDate(0, 0, -7).Format("2006-01-02"))
+	endDate := c.Query("endDate", now.Format("2006-01-02"))
+	lane := strings.ToLower(strings.TrimSpace(c.Query("lane", "")))
+
+	// Validate date formats and range
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+
+	if lane != "" && lane != "ribbon" && lane != "online" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid lane. Use ribbon or online.",
+		})
+	}
+
+	// Every station is listed, also the ones without QC in the range
+	var stations []models.QCStation
+	stationQuery := rc.DB.WithContext(c.Context()).Order("station_code ASC")
+	if lane != "" {
+		stationQuery = stationQuery.Where("lane = ?", lane)
+	}
+	if err := stationQuery.Find(&stations).Error; err != nil {
+		log.Println("GetQCStationReports - Failed to load QC stations:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve QC station reports",
+		})
+	}
+
+	rows := make(map[uint]*QCStationReportRow)
+	for _, station := range stations {
+		stationID := station.ID
+		rows[station.ID] = &QCStationReportRow{
+			StationID:   &stationID,
+			StationCode: station.StationCode,
+			Lane:        station.Lane,
+			Printer:     station.Printer,
+		}
+	}
+	unassigned := make(map[string]*QCStationReportRow) // per lane
+	rowFor := func(stationID *uint, qcLane string) *QCStationReportRow {
+		if stationID != nil {
+			if row, ok := rows[*stationID]; ok {
+				return row
+			}
+		}
+		row, ok := unassigned[qcLane]
+		if !ok {
+			row = &QCStationReportRow{StationCode: "unassigned", Lane: qcLane}
+			unassigned[qcLane] = row
+		}
+		return row
+	}
+
+	// Aggregate QC records per station, hour and user so active hours and users can be counted across lanes
+	type qcBucket struct {
+		StationID  *uint
+		Hour       time.Time
+		QCBy       uint
+		Total      int64
+		Completed  int64
+		Complaints int64
+	}
+	hours := make(map[*QCStationReportRow]map[time.Time]bool)
+	users := make(map[*QCStationReportRow]map[uint]bool)
+	for _, source := range []struct {
+		lane  string
+		model interface{}
+	}{{"ribbon", &models.QCRibbon{}}, {"online", &models.QCOnline{}}} {
+		if lane != "" && lane != source.lane {
+			continue
+		}
+		var buckets []qcBucket
+		if err := rc.DB.WithContext(c.Context()).Model(source.model).
+			Select("qc_station_id as station_id, date_trunc('hour', created_at) as hour, qc_by, COUNT(*) as total, COUNT(*) FILTER (WHERE status = 'completed') as completed, COUNT(*) FILTER (WHERE complained) as complaints").
+			Where("created_at >= ? AND created_at < ?", start, end).
+			Group("qc_station_id, hour, qc_by").Scan(&buckets).Error; err != nil {
+			log.Println("GetQCStationReports - Failed to aggregate QC records:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve QC station reports",
+			})
+		}
+		for _, bucket := range buckets {
+			row := rowFor(bucket.StationID, source.lane)
+			row.TotalQCs += bucket.Total
+			row.CompletedQCs += bucket.Completed
+			row.Complaints += bucket.Complaints
+			if hours[row] == nil {
+				hours[row] = make(map[time.Time]bool)
+				users[row] = make(map[uint]bool)
+			}
+			hours[row][bucket.Hour] = true
+			users[row][bucket.QCBy] = true
+		}
+	}
+
+	// Count mismatches and voids recorded at each station
+	type errorBucket struct {
+		StationID *uint
+		Lane      string
+		Count     int64
+	}
+	for _, source := range []struct {
+		model interface{}
+		add   func(row *QCStationReportRow, count int64)
+	}{
+		{&models.QCMismatch{}, func(row *QCStationReportRow, count int64) { row.Mismatches += count }},
+		{&models.QCVoid{}, func(row *QCStationReportRow, count int64) { row.Voids += count }},
+	} {
+		query := rc.DB.WithContext(c.Context()).Model(source.model).
+			Select("qc_station_id as station_id, lane, COUNT(*) as count").
+			Where("created_at >= ? AND created_at < ?", start, end)
+		if lane != "" {
+			query = query.Where("lane = ?", lane)
+		}
+		var buckets []errorBucket
+		if err := query.Group("qc_station_id, lane").Scan(&buckets).Error; err != nil {
+			log.Println("GetQCStationReports - Failed to aggregate QC errors:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve QC station reports",
+			})
+		}
+		for _, bucket := range buckets {
+			source.add(rowFor(bucket.StationID, bucket.Lane), bucket.Count)
+		}
+	}
+
+	for row, rowHours := range hours {
+		row.ActiveHours = len(rowHours)
+		row.QCUsers = len(users[row])
+	}
+
+	// Derive rates, stations first by code, unassigned QC last
+	result := make([]QCStationReportRow, 0, len(rows)+len(unassigned))
+	for _, station := range stations {
+		result = append(result, *rows[station.ID])
+	}
+	for _, qcLane := range []string{"ribbon", "online"} {
+		if row, ok := unassigned[qcLane]; ok {
+			result = append(result, *row)
+		}
+	}
+	for i := range result {
+		row := &result[i]
+		if row.ActiveHours > 0 {
+			row.PerHour = math.Round(float64(row.TotalQCs)/float64(row.ActiveHours)*100) / 100
+		}
+		if row.TotalQCs > 0 {
+			row.ErrorRate = math.Round(float64(row.Voids+row.Complaints)/float64(row.TotalQCs)*10000) / 100
+			row.MismatchRate = math.Round(float64(row.Mismatches)/float64(row.TotalQCs)*10000) / 100
+		}
+	}
+
+	// Build success message
+	message := "QC station reports retrieved successfully"
 	var filters []string
 
-	if date != "" {
-		filters = append(filters, "date: "+date)
+	filters = append(filters, "date: "+startDate+" to "+endDate)
+
+	if lane != "" {
+		filters = append(filters, "lane: "+lane)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetQCStationReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
+		Success: true,
+		Message: message,
+		Data: QCStationReportResponse{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Stations:  result,
+		},
+		Total: int64(len(result)),
+	})
+}
+
+// outboundForecastLookbackDays is the history used to estimate stage durations
+const outboundForecastLookbackDays = 7
+
+// averageStageMinutes returns the average minutes between two timestamp columns over the lookback window
+func (rc *ReportController) averageStageMinutes(ctx context.Context, table, fromColumn, toColumn string, since time.Time, extraWhere string, args ...interface{}) (float64, error) {
+	var result struct {
+		Minutes *float64
+	}
+	query := rc.DB.WithContext(ctx).Table(table).
+		Select(fmt.Sprintf("AVG(EXTRACT(EPOCH FROM (%s - %s)) / 60) AS minutes", toColumn, fromColumn)).
+		Where(fmt.Sprintf("%s IS NOT NULL AND %s >= ? AND %s > %s", fromColumn, toColumn, toColumn, fromColumn), since)
+	if extraWhere != "" {
+		query = query.Where(extraWhere, args...)
+	}
+	if err := query.Scan(&result).Error; err != nil {
+		return 0, err
+	}
+	if result.Minutes == nil {
+		return 0, nil
+	}
+	return math.Round(*result.Minutes*10) / 10, nil
+}
+
+// GetOutboundForecastReports forecasts how many packages each expedition can pick up by its cut-off today
+// @Summary Get Outbound Pickup Forecast
+// @Description Forecast per expedition how many packages will be ready by the expedition cut-off time today. Packages are in progress orders with a tracking number, matched to expeditions by tracking number prefix. Remaining time per stage is estimated from the average wait, pick and QC durations of the last 7 days.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=OutboundForecastResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/outbound-forecast [get]
+func (rc *ReportController) GetOutboundForecastReports(c fiber.Ctx) error {
+	log.Println("GetOutboundForecastReports called")
+	ctx := c.Context()
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := startOfDay.Add(24 * time.Hour)
+	since := startOfDay.AddDate(0, 0, -outboundForecastLookbackDays)
+
+	// Estimate stage durations from recent history
+	response := OutboundForecastResponse{
+		GeneratedAt: now.Format("02-01-2006 15:04:05"),
+		Expeditions: []OutboundForecastRow{},
+	}
+	var ribbonMinutes, onlineMinutes float64
+	for _, estimate := range []struct {
+		target                      *float64
+		table, fromColumn, toColumn string
+		extraWhere                  string
+		args                        []interface{}
+	}{
+		{&response.AverageWaitMinutes, "orders", "created_at", "assigned_at", "", nil},
+		{&response.AveragePickMinutes, "orders", "assigned_at", "picked_at", "", nil},
+		{&ribbonMinutes, "qc_ribbons", "created_at", "updated_at", "status = ?", []interface{}{"completed"}},
+		{&onlineMinutes, "qc_onlines", "created_at", "updated_at", "status = ?", []interface{}{"completed"}},
+	} {
+		minutes, err := rc.averageStageMinutes(ctx, estimate.table, estimate.fromColumn, estimate.toColumn, since, estimate.extraWhere, estimate.args...)
+		if err != nil {
+			log.Println("GetOutboundForecastReports - Failed to estimate stage durations:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to estimate stage durations",
+			})
+		}
+		*estimate.target = minutes
+	}
+	// Ribbon and online QC run in parallel lanes, plan with the slower one
+	response.AverageQCMinutes = math.Max(ribbonMinutes, onlineMinutes)
+
+	var expeditions []models.Expedition
+	if err := rc.DB.WithContext(ctx).Order("expedition_name ASC").Find(&expeditions).Error; err != nil {
+		log.Println("GetOutboundForecastReports - Failed to retrieve expeditions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve expeditions",
+		})
 	}
 
-	if len(filters) > 0 {
-		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	// Packages already handed over today per expedition
+	var shipped []struct {
+		ExpeditionSlug string
+		Count          int64
+	}
+	if err := rc.DB.WithContext(ctx).Model(&models.Outbound{}).Select("expedition_slug, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", startOfDay, endOfDay).Group("expedition_slug").Scan(&shipped).Error; err != nil {
+		log.Println("GetOutboundForecastReports - Failed to count outbounds:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to count outbounds",
+		})
+	}
+	shippedBySlug := make(map[string]int64, len(shipped))
+	for _, row := range shipped {
+		shippedBySlug[row.ExpeditionSlug] = row.Count
 	}
 
-	log.Println("GetComplainReports completed successfully")
+	// Pipeline packages with a courier tracking number
+	var orders []models.Order
+	if err := rc.DB.WithContext(ctx).Select("id, tracking_number, processing_status").
+		Where("event_status = ? AND tracking_number <> ? AND processing_status IN ?", "in_progress", "",
+			[]string{"ready_to_pick", "picking_progress", "picking_pending", "picking_completed", "qc_progress", "qc_completed"}).
+		Find(&orders).Error; err != nil {
+		log.Println("GetOutboundForecastReports - Failed to retrieve pipeline orders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve pipeline orders",
+		})
+	}
+
+	// Rows per expedition, longer codes are matched first so "JX" does not take "JXE" packages
+	rows := make([]OutboundForecastRow, len(expeditions))
+	cutOffs := make([]time.Time, len(expeditions))
+	for i, expedition := range expeditions {
+		cutOff, ok := expedition.CutOffOn(now)
+		if !ok {
+			cutOff = endOfDay
+		}
+		cutOffs[i] = cutOff
+		rows[i] = OutboundForecastRow{
+			Expedition:      expedition.ExpeditionName,
+			ExpeditionSlug:  expedition.ExpeditionSlug,
+			ExpeditionColor: expedition.ExpeditionColor,
+			CutOffTime:      cutOff.Format("15:04"),
+			MinutesToCutOff: int(math.Max(0, cutOff.Sub(now).Minutes())),
+			ShippedToday:    shippedBySlug[expedition.ExpeditionSlug],
+		}
+	}
+	matchOrder := make([]int, len(expeditions))
+	for i := range matchOrder {
+		matchOrder[i] = i
+	}
+	sort.SliceStable(matchOrder, func(a, b int) bool {
+		return len(expeditions[matchOrder[a]].ExpeditionCode) > len(expeditions[matchOrder[b]].ExpeditionCode)
+	})
+
+	for _, order := range orders {
+		index := -1
+		for _, i := range matchOrder {
+			if strings.HasPrefix(order.TrackingNumber, expeditions[i].ExpeditionCode) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			continue
+		}
+		row := &rows[index]
+		row.InPipeline++
+
+		// Remaining minutes until the package is ready for pickup
+		var remaining float64
+		switch order.ProcessingStatus {
+		case "ready_to_pick":
+			row.Stages.WaitingPick++
+			remaining = response.AverageWaitMinutes + response.AveragePickMinutes + response.AverageQCMinutes
+		case "picking_progress", "picking_pending":
+			row.Stages.Picking++
+			remaining = response.AveragePickMinutes + response.AverageQCMinutes
+		case "picking_completed":
+			row.Stages.Picked++
+			remaining = response.AverageQCMinutes
+		case "qc_progress":
+			row.Stages.InQC++
+			remaining = response.AverageQCMinutes / 2
+		case "qc_completed":
+			row.Stages.Ready++
+		}
+
+		if !now.Add(time.Duration(remaining * float64(time.Minute))).After(cutOffs[index]) {
+			row.ExpectedByCutOff++
+		} else {
+			row.AtRisk++
+		}
+	}
+
+	// Only expeditions with activity today are listed, earliest cut-off first
+	for _, row := range rows {
+		if row.InPipeline == 0 && row.ShippedToday == 0 {
+			continue
+		}
+		response.Expeditions = append(response.Expeditions, row)
+		response.TotalExpected += row.ExpectedByCutOff
+		response.TotalAtRisk += row.AtRisk
+	}
+	sort.SliceStable(response.Expeditions, func(a, b int) bool {
+		return response.Expeditions[a].CutOffTime < response.Expeditions[b].CutOffTime
+	})
+
+	log.Println("GetOutboundForecastReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Outbound pickup forecast retrieved successfully",
+		Data:    response,
+	})
+}
+
+// GetCancelInconsistencyReports lists QC, outbound and detail records still active for canceled orders
+// @Summary Get Cancel Inconsistency Report
+// @Description List QC Ribbon, QC Online and outbound records of canceled orders that are still open or not flagged, and canceled orders with remaining detail quantities. Use POST /api/orders/cancel-cleanup to fix them.
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessTotaledResponse{data=CancelInconsistencyReportResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/cancel-inconsistencies [get]
+func (rc *ReportController) GetCancelInconsistencyReports(c fiber.Ctx) error {
+	log.Println("GetCancelInconsistencyReports called")
+	ctx := c.Context()
+
+	type inconsistencyScan struct {
+		RecordID       uint
+		OrderID        uint
+		OrderGineeID   string
+		TrackingNumber string
+		Status         string
+		CanceledAt     *time.Time
+	}
+
+	response := CancelInconsistencyReportResponse{
+		Summary: map[string]int{"qc_ribbon": 0, "qc_online": 0, "outbound": 0, "order_detail": 0},
+		Records: []CancelInconsistencyRow{},
+	}
+	appendRows := func(recordType, issue string, rows []inconsistencyScan) {
+		for _, row := range rows {
+			record := CancelInconsistencyRow{
+				Type:           recordType,
+				RecordID:       row.RecordID,
+				OrderID:        row.OrderID,
+				OrderGineeID:   row.OrderGineeID,
+				TrackingNumber: row.TrackingNumber,
+				Status:         row.Status,
+				Issue:          issue,
+			}
+			if row.CanceledAt != nil {
+				record.CanceledAt = row.CanceledAt.Format("02-01-2006 15:04:05")
+			}
+			response.Records = append(response.Records, record)
+			response.Summary[recordType]++
+		}
+	}
+
+	// QC records that are still open or not flagged
+	for _, lane := range []struct{ Type, Table string }{{"qc_ribbon", "qc_ribbons"}, {"qc_online", "qc_onlines"}} {
+		var rows []inconsistencyScan
+		if err := rc.DB.WithContext(ctx).Table(lane.Table).
+			Select(lane.Table+".id AS record_id, orders.id AS order_id, orders.order_ginee_id, "+lane.Table+".tracking_number, "+lane.Table+".status, orders.canceled_at").
+			Joins("JOIN orders ON orders.tracking_number = "+lane.Table+".tracking_number").
+			Where("orders.event_status = ?", models.EventStatusCanceled).
+			Where(lane.Table+".order_canceled = ? OR "+lane.Table+".status IN ?", false, []string{"in_progress", "pending"}).
+			Order(lane.Table + ".id ASC").Scan(&rows).Error; err != nil {
+			log.Println("GetCancelInconsistencyReports - Failed to retrieve", lane.Table, ":", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve QC records",
+			})
+		}
+		appendRows(lane.Type, "QC record of a canceled order is still open or not flagged", rows)
+	}
+
+	// Outbounds of canceled orders that are not flagged
+	var outboundRows []inconsistencyScan
+	if err := rc.DB.WithContext(ctx).Table("outbounds").
+		Select("outbounds.id AS record_id, orders.id AS order_id, orders.order_ginee_id, outbounds.tracking_number, orders.processing_status AS status, orders.canceled_at").
+		Joins("JOIN orders ON orders.tracking_number = outbounds.tracking_number").
+		Where("orders.event_status = ? AND outbounds.order_canceled = ?", models.EventStatusCanceled, false).
+		Order("outbounds.id ASC").Scan(&outboundRows).Error; err != nil {
+		log.Println("GetCancelInconsistencyReports - Failed to retrieve outbounds:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve outbound records",
+		})
+	}
+	appendRows("outbound", "Outbound of a canceled order is not flagged", outboundRows)
+
+	// Canceled orders that still have quantities to pick
+	var detailRows []inconsistencyScan
+	if err := rc.DB.WithContext(ctx).Table("order_details").
+		Select("order_details.id AS record_id, orders.id AS order_id, orders.order_ginee_id, orders.tracking_number, orders.processing_status AS status, orders.canceled_at").
+		Joins("JOIN orders ON orders.id = order_details.order_id").
+		Where("orders.event_status = ? AND order_details.quantity > 0", models.EventStatusCanceled).
+		Order("order_details.id ASC").Scan(&detailRows).Error; err != nil {
+		log.Println("GetCancelInconsistencyReports - Failed to retrieve order details:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve order details",
+		})
+	}
+	appendRows("order_detail", "Canceled order detail still has a quantity", detailRows)
+
+	log.Println("GetCancelInconsistencyReports completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
 		Success: true,
-		Message: message,
+		Message: "Cancel inconsistency report retrieved successfully",
 		Data:    response,
-		Total:   total,
+		Total:   int64(len(response.Records)),
 	})
 }
 
-// GetUserFeeReports generates user fee reports
-// @Summary Get User Fee Reports
-// @Description Generate user fee reports with optional filters
+// OrderVolumeReportResponse compares the orders of every store or channel on a day with its baseline
+type OrderVolumeReportResponse struct {
+	Scope        string                      `json:"scope"`
+	Date         string                      `json:"date"`
+	CheckedAt    string                      `json:"checkedAt"` // orders are counted up to this time on the date and on the baseline days
+	BaselineDays int                         `json:"baselineDays"`
+	Spikes       int                         `json:"spikes"`
+	Drops        int                         `json:"drops"`
+	Variances    []utils.OrderVolumeVariance `json:"variances"`
+}
+
+// GetOrderVolumeReports compares the orders of every store or channel with its baseline
+// @Summary Get Order Volume Variance Report
+// @Description Compare the orders of every store or channel on a day with the average of the previous days up to the same time of day. Today is compared up to now, past days as whole days. Stores and channels beyond their spike or drop threshold are alerted by a background job
 // @Tags Reports
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "Page number for pagination (default is 1)"
-// @Param limit query int false "Number of items per page for pagination (default is 10)"
-// @Param startDate query string false "Filter by start date (YYYY-MM-DD format)"
-// @Param endDate query string false "Filter by end date (YYYY-MM-DD format)"
-// @Param userId query string false "Filter term for user ID"
-// @Success 200 {object} utils.SuccessPaginatedResponse{data=UserFeeReportsWithDetailsListResponse}
+// @Param scope query string false "Scope (store, channel)" default(store)
+// @Param date query string false "Date (YYYY-MM-DD format), defaults to today"
+// @Success 200 {object} utils.SuccessResponse{data=OrderVolumeReportResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
-// @Router /api/reports/user-fees [get]
-func (rc *ReportController) GetUserFeeReports(c fiber.Ctx) error {
-	log.Println("GetUserFeeReports called")
-	// Parse pagination parameters
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "10"))
-	offset := (page - 1) * limit
-
-	// Parse filter parameters
-	userId := c.Query("userId", "")
-	startDate := c.Query("startDate", "")
-	endDate := c.Query("endDate", "")
+// @Router /api/reports/order-volume [get]
+func (rc *ReportController) GetOrderVolumeReports(c fiber.Ctx) error {
+	log.Println("GetOrderVolumeReports called")
+	scope := strings.TrimSpace(c.Query("scope", models.OrderVolumeScopeStore))
+	if !models.IsValidOrderVolumeScope(scope) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid scope. Use one of: " + strings.Join(models.OrderVolumeScopes(), ", "),
+		})
+	}
 
-	// Validate date formats
-	if startDate != "" {
-		if _, err := time.Parse("2006-01-02", startDate); err != nil {
+	now := time.Now()
+	at := now
+	if date := c.Query("date", ""); date != "" {
+		parsedDate, err := time.ParseInLocation("2006-01-02", date, time.Local)
+		if err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 				Success: false,
-				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+				Error:   "Invalid date format. Use YYYY-MM-DD.",
 			})
 		}
-	}
-	if endDate != "" {
-		if _, err := time.Parse("2006-01-02", endDate); err != nil {
+		if parsedDate.After(now) {
 			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 				Success: false,
-				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+				Error:   "Date must not be in the future.",
 			})
 		}
+		// Past days are compared as whole days, up to the last instant of the day
+		if endOfDay := parsedDate.AddDate(0, 0, 1).Add(-time.Nanosecond); endOfDay.Before(now) {
+			at = endOfDay
+		}
 	}
 
-	// Build query to get user summaries
-	type UserSummary struct {
-		UserID          uint
-		Username        string
-		FullName        string
-		Email           string
-		TotalComplaints int
-		TotalFeeCharge  uint
+	variances, err := rc.OrderVolume.ComputeVariance(rc.DB.WithContext(c.Context()), scope, at)
+	if err != nil {
+		log.Println("GetOrderVolumeReports - Failed to compute order volume:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to compute order volume",
+		})
 	}
 
-	summaryQuery := rc.DB.Table("complain_user_details").
-		Select("users.id as user_id, users.username, users.full_name, users.email, COUNT(DISTINCT complain_user_details.complain_id) as total_complaints, COALESCE(SUM(complain_user_details.fee_charge), 0) as total_fee_charge").
-		Joins("LEFT JOIN users ON users.id = complain_user_details.user_id").
-		Joins("LEFT JOIN complains ON complains.id = complain_user_details.complain_id")
-
-	// Apply date filters on complains table
-	if startDate != "" {
-		summaryQuery = summaryQuery.Where("complains.updated_at >= ?", startDate+" 00:00:00")
+	response := OrderVolumeReportResponse{
+		Scope:        scope,
+		Date:         at.Format("2006-01-02"),
+		CheckedAt:    at.Format("02-01-2006 15:04:05"),
+		BaselineDays: rc.OrderVolume.BaselineDays,
+		Variances:    variances,
 	}
-	if endDate != "" {
-		summaryQuery = summaryQuery.Where("complains.updated_at <= ?", endDate+" 23:59:59")
+	for _, variance := range variances {
+		switch variance.Status {
+		case utils.OrderVolumeStatusSpike:
+			response.Spikes++
+		case utils.OrderVolumeStatusDrop:
+			response.Drops++
+		}
 	}
 
-	// Apply user filter
-	if userId != "" {
-		summaryQuery = summaryQuery.Where("complain_user_details.user_id = ?", userId)
+	log.Println("GetOrderVolumeReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Order volume report retrieved successfully",
+		Data:    response,
+	})
+}
+
+// GetBackorderReports generates the backorder aging report
+// @Summary Get Backorder Reports
+// @Description Per SKU: the orders still awaiting stock with their aging, the stock on hand and expected on open inbound shipments, and the backorders restocked in the date range with their average wait. Defaults to the last 30 days, oldest backorder first
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Restocked from (YYYY-MM-DD format)"
+// @Param endDate query string false "Restocked to (YYYY-MM-DD format)"
+// @Param sku query string false "Filter by SKU"
+// @Success 200 {object} utils.SuccessTotaledResponse{data=BackorderReportResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/backorders [get]
+func (rc *ReportController) GetBackorderReports(c fiber.Ctx) error {
+	log.Println("GetBackorderReports called")
+	// Parse date range, defaults to the last 30 days
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	startDate := c.Query("startDate", today.AddDate(0, 0, -29).Format("2006-01-02"))
+	endDate := c.Query("endDate", today.Format("2006-01-02"))
+	start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+		})
+	}
+	end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+		})
 	}
+	sku := strings.TrimSpace(c.Query("sku", ""))
 
-	summaryQuery = summaryQuery.Group("users.id, users.username, users.full_name, users.email").
-		Order("total_fee_charge DESC")
+	ctx := c.Context()
+	openQuery := rc.DB.WithContext(ctx).Model(&models.PickShortage{}).Where("status = ?", "open")
+	restockedQuery := rc.DB.WithContext(ctx).Model(&models.PickShortage{}).
+		Where("status = ? AND resolution = ? AND resolved_at >= ? AND resolved_at < ?", "resolved", utils.PickShortageResolutionRestocked, start, end.AddDate(0, 0, 1))
+	if sku != "" {
+		openQuery = openQuery.Where("sku ILIKE ?", "%"+sku+"%")
+		restockedQuery = restockedQuery.Where("sku ILIKE ?", "%"+sku+"%")
+	}
 
-	// Get total count
-	var totalCount int64
-	countQuery := rc.DB.Table("(?) as summaries", summaryQuery)
-	if err := countQuery.Count(&totalCount).Error; err != nil {
-		log.Println("GetUserFeeReports - Failed to count user fee reports:", err)
+	var open []models.PickShortage
+	if err := openQuery.Order("created_at ASC, id ASC").Find(&open).Error; err != nil {
+		log.Println("GetBackorderReports - Failed to retrieve open backorders:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to count user fee reports",
+			Error:   "Failed to retrieve backorders",
 		})
 	}
 
-	// Apply pagination
-	var summaries []UserSummary
-	if err := summaryQuery.Limit(limit).Offset(offset).Scan(&summaries).Error; err != nil {
-		log.Println("GetUserFeeReports - Failed to retrieve user fee reports:", err)
+	var restocked []struct {
+		SKU       string
+		Count     int
+		WaitHours float64
+	}
+	if err := restockedQuery.Select("sku, COUNT(*) AS count, AVG(EXTRACT(EPOCH FROM resolved_at - created_at) / 3600) AS wait_hours").
+		Group("sku").Scan(&restocked).Error; err != nil {
+		log.Println("GetBackorderReports - Failed to retrieve restocked backorders:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Failed to retrieve user fee reports",
+			Error:   "Failed to retrieve restocked backorders",
 		})
 	}
 
-	// Build detailed reports for each user
-	var reports []UserFeeReportWithDetails
-	for _, summary := range summaries {
-		// Get detailed complain information for this user
-		detailQuery := rc.DB.Table("complain_user_details").
-			Select("complain_user_details.complain_id, complains.code as complain_code, complains.tracking_number as tracking, complains.order_ginee_id, complain_user_details.fee_charge, complains.updated_at as complain_updated_at").
-			Joins("LEFT JOIN complains ON complains.id = complain_user_details.complain_id").
-			Where("complain_user_details.user_id = ?", summary.UserID)
-
-		// Apply same date filters
-		if startDate != "" {
-			detailQuery = detailQuery.Where("complains.updated_at >= ?", startDate+" 00:00:00")
-		}
-		if endDate != "" {
-			detailQuery = detailQuery.Where("complains.updated_at <= ?", endDate+" 23:59:59")
-		}
-
-		detailQuery = detailQuery.Order("complains.updated_at DESC")
-
-		// Scan into temporary struct with time.Time
-		type ComplainDetailRaw struct {
-			ComplainID        uint
-			ComplainCode      string
-			Tracking          string
-			OrderGineeID      string
-			FeeCharge         uint
-			ComplainUpdatedAt time.Time
-		}
-
-		var rawDetails []ComplainDetailRaw
-		if err := detailQuery.Scan(&rawDetails).Error; err != nil {
-			log.Println("GetUserFeeReports - Failed to retrieve complain details:", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Failed to retrieve complain details",
-			})
+	// Group the open backorders per SKU, oldest first, and bucket them by aging
+	aging := []ShortageAgingBucket{
+		{Label: "< 24h"},
+		{Label: "24-48h"},
+		{Label: "48-72h"},
+		{Label: "> 72h"},
+	}
+	rows := make([]BackorderAgingRow, 0)
+	rowIndex := make(map[string]int)
+	for _, shortage := range open {
+		hours := utils.PickShortageAging(&shortage, now).Hours()
+		switch {
+		case hours < 24:
+			aging[0].Count++
+		case hours < 48:
+			aging[1].Count++
+		case hours < 72:
+			aging[2].Count++
+		default:
+			aging[3].Count++
 		}
 
-		// Format the dates
-		var details []ComplainDetailInReport
-		for _, raw := range rawDetails {
-			details = append(details, ComplainDetailInReport{
-				ComplainID:        raw.ComplainID,
-				ComplainCode:      raw.ComplainCode,
-				Tracking:          raw.Tracking,
-				OrderGineeID:      raw.OrderGineeID,
-				FeeCharge:         raw.FeeCharge,
-				ComplainUpdatedAt: raw.ComplainUpdatedAt.Format("02-01-2006 15:04:05"),
+		index, ok := rowIndex[shortage.SKU]
+		if !ok {
+			index = len(rows)
+			rowIndex[shortage.SKU] = index
+			rows = append(rows, BackorderAgingRow{
+				SKU:              shortage.SKU,
+				OldestAgingHours: float64(int(hours*10)) / 10,
+				OldestDeclaredAt: shortage.CreatedAt.Format("02-01-2006 15:04:05"),
 			})
 		}
-
-		report := UserFeeReportWithDetails{
-			UserID:          summary.UserID,
-			Username:        summary.Username,
-			FullName:        summary.FullName,
-			Email:           summary.Email,
-			TotalComplaints: summary.TotalComplaints,
-			TotalFeeCharge:  summary.TotalFeeCharge,
-			ComplainDetails: details,
+		rows[index].WaitingOrders++
+		rows[index].ShortQuantity += shortage.RequiredQuantity - shortage.PickedQuantity
+	}
+	for _, row := range restocked {
+		index, ok := rowIndex[row.SKU]
+		if !ok {
+			index = len(rows)
+			rowIndex[row.SKU] = index
+			rows = append(rows, BackorderAgingRow{SKU: row.SKU})
 		}
-
-		reports = append(reports, report)
+		rows[index].Restocked = row.Count
+		rows[index].AvgRestockHours = math.Round(row.WaitHours*10) / 10
 	}
 
-	response := UserFeeReportsWithDetailsListResponse{
-		Reports: reports,
+	skus := make([]string, len(rows))
+	for i, row := range rows {
+		skus[i] = row.SKU
 	}
-
-	// Build success message
-	message := "User fee reports retrieved successfully"
-	var filters []string
-
-	if startDate != "" {
-		filters = append(filters, "startDate: "+startDate)
+	supply, err := utils.GetBackorderSupply(rc.DB.WithContext(ctx), skus)
+	if err != nil {
+		log.Println("GetBackorderReports - Failed to retrieve stock:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve stock of the backorders",
+		})
 	}
-	if endDate != "" {
-		filters = append(filters, "endDate: "+endDate)
+	var products []models.Product
+	if len(skus) > 0 {
+		rc.DB.WithContext(ctx).Select("sku, name").Where("sku IN ?", skus).Find(&products)
 	}
-	if userId != "" {
-		filters = append(filters, "userId: "+userId)
+	productNames := make(map[string]string, len(products))
+	for _, product := range products {
+		productNames[product.SKU] = product.Name
+	}
+	for i := range rows {
+		rows[i].ProductName = productNames[rows[i].SKU]
+		rows[i].StockOnHand = supply[rows[i].SKU].OnHand
+		rows[i].IncomingQuantity = supply[rows[i].SKU].Incoming
 	}
 
-	if len(filters) > 0 {
-		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	// Build success message
+	message := "Backorder reports retrieved successfully"
+	filters := []string{"date: " + startDate + " to " + endDate}
+	if sku != "" {
+		filters = append(filters, "sku: "+sku)
 	}
+	message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
 
-	log.Println("GetUserFeeReports completed successfully")
-	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+	log.Println("GetBackorderReports completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessTotaledResponse{
 		Success: true,
 		Message: message,
-		Data:    response,
-		Pagination: utils.Pagination{
-			Page:  page,
-			Limit: limit,
-			Total: totalCount,
+		Data: BackorderReportResponse{
+			StartDate: startDate,
+			EndDate:   endDate,
+			Aging:     aging,
+			SKUs:      rows,
 		},
+		Total: int64(len(open)),
 	})
 }
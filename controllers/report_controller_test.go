@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"encoding/json"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/testutil"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Orders are billed once, in the month of their first outbound scan, even when the outbound was scanned twice.
+// The report runs on months long past so other orders of the database are not billed in them.
+func TestGetBillingReportsDuplicateOutbounds(t *testing.T) {
+	tx := testutil.Begin(t, testDB)
+	f := testutil.NewFactory(t, tx)
+	admin := f.User("superadmin")
+	outboundUser := f.User("outbound")
+	reportController := NewReportController(testutil.Config(), tx)
+
+	shipped := testutil.WithStatus(models.ProcessingStatusOutboundCompleted, models.EventStatusCompleted)
+	outboundAt := func(order models.Order, at time.Time) {
+		t.Helper()
+		outbound := f.Outbound(order, outboundUser)
+		if err := tx.Model(&outbound).Update("created_at", at).Error; err != nil {
+			t.Fatalf("failed to backdate outbound: %v", err)
+		}
+	}
+	at := func(month time.Month, day int) time.Time {
+		return time.Date(2001, month, day, 10, 0, 0, 0, time.Local)
+	}
+
+	// Scanned twice in March
+	scannedTwice := f.Order(shipped)
+	outboundAt(scannedTwice, at(time.March, 5))
+	outboundAt(scannedTwice, at(time.March, 6))
+
+	// Shipped in February, scanned again in March
+	shippedBefore := f.Order(shipped)
+	outboundAt(shippedBefore, at(time.February, 27))
+	outboundAt(shippedBefore, at(time.March, 2))
+
+	tests := []struct {
+		month string
+		want  models.Order
+	}{
+		{"2001-02", shippedBefore},
+		{"2001-03", scannedTwice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.month, func(t *testing.T) {
+			resp, result := callHandler(t, reportController.GetBillingReports, testRequest{
+				Method: fiber.MethodGet, Route: "/api/reports/billing", Path: "/api/reports/billing?month=" + tt.month,
+				User: admin, Roles: []string{"superadmin"},
+			})
+			expectStatus(t, "billing report "+tt.month, resp, result, fiber.StatusOK)
+
+			var report BillingReportResponse
+			if err := json.Unmarshal(result.Data, &report); err != nil {
+				t.Fatalf("failed to decode billing report: %v", err)
+			}
+			totals := report.Totals
+			if totals.ShippedOrders != 1 || totals.TotalItems != int64(tt.want.TotalQuantity) || totals.TotalValue != tt.want.TotalValue {
+				t.Errorf("billed %d orders with %d items worth %d, want 1 order with %d items worth %d",
+					totals.ShippedOrders, totals.TotalItems, totals.TotalValue, tt.want.TotalQuantity, tt.want.TotalValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,946 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ReportScheduleController struct {
+	DB      *gorm.DB
+	Config  *config.Config
+	Reports *ReportController
+}
+
+func NewReportScheduleController(cfg *config.Config, db *gorm.DB) *ReportScheduleController {
+	return &ReportScheduleController{Config: cfg, DB: db, Reports: NewReportController(cfg, db)}
+}
+
+// Unique request structs
+type ReportScheduleRequest struct {
+	Name       string            `json:"name" example:"Monthly billing"`
+	ReportType string            `json:"reportType" example:"billing"`
+	Filters    map[string]string `json:"filters"`
+	Frequency  string            `json:"frequency" example:"monthly"` // none, daily, weekly or monthly
+	RunHour    *int              `json:"runHour" example:"7"`
+	Recipients []string          `json:"recipients" example:"finance@example.com"`
+	Active     *bool             `json:"active" example:"true"`
+}
+
+// Unique response structs
+// ReportScheduleTypeResponse describes a report that can be saved and scheduled
+type ReportScheduleTypeResponse struct {
+	ReportType string   `json:"reportType"`
+	Label      string   `json:"label"`
+	Filters    []string `json:"filters"`
+	Roles      []string `json:"roles"`
+}
+
+// reportScheduleType is a report that can be rendered to XLSX outside of a request
+type reportScheduleType struct {
+	Label   string
+	Roles   []string // roles allowed to save and receive the report, the access policy of its report route
+	Filters []string // accepted filter keys, the query parameters of its report route
+	Monthly bool     // the report covers a month, otherwise a startDate/endDate range
+	Render  func(rc *ReportController, ctx context.Context, filters map[string]string) (*bytes.Buffer, string, error)
+}
+
+// reportScheduleTypes are the reports that can be saved as favorites and emailed on a schedule
+var reportScheduleTypes = map[string]reportScheduleType{
+	"billing": {
+		Label:   "Billing",
+		Roles:   utils.ReportAccessRoles("billing"),
+		Filters: []string{"month", "channel", "store", "orderSource"},
+		Monthly: true,
+		Render: func(rc *ReportController, ctx context.Context, filters map[string]string) (*bytes.Buffer, string, error) {
+			month := reportFilter(filters, "month", time.Now().Format("2006-01"))
+			startOfMonth, err := time.ParseInLocation("2006-01", month, time.Local)
+			if err != nil {
+				return nil, "", err
+			}
+			reports, totals, err := rc.BuildBillingReport(ctx, startOfMonth, filters["channel"], filters["store"], filters["orderSource"])
+			if err != nil {
+				return nil, "", err
+			}
+			buffer, err := BuildBillingReportXLSX(month, reports, totals)
+			return buffer, fmt.Sprintf("billing-report-%s.xlsx", month), err
+		},
+	},
+	"order_reconciliation": {
+		Label:   "Order reconciliation",
+		Roles:   utils.ReportAccessRoles("order_reconciliation"),
+		Filters: []string{"month", "channel", "store", "orderSource"},
+		Monthly: true,
+		Render: func(rc *ReportController, ctx context.Context, filters map[string]string) (*bytes.Buffer, string, error) {
+			month := reportFilter(filters, "month", time.Now().Format("2006-01"))
+			startOfMonth, err := time.ParseInLocation("2006-01", month, time.Local)
+			if err != nil {
+				return nil, "", err
+			}
+			reports, totals, err := rc.BuildOrderReconciliationReport(ctx, startOfMonth, filters["channel"], filters["store"], filters["orderSource"])
+			if err != nil {
+				return nil, "", err
+			}
+			buffer, err := BuildOrderReconciliationReportXLSX(month, reports, totals)
+			return buffer, fmt.Sprintf("order-reconciliation-%s.xlsx", month), err
+		},
+	},
+	"return_valuation": {
+		Label:   "Return valuation",
+		Roles:   utils.ReportAccessRoles("return_valuation"),
+		Filters: []string{"startDate", "endDate", "channelId"},
+		Render: func(rc *ReportController, ctx context.Context, filters map[string]string) (*bytes.Buffer, string, error) {
+			now := time.Now()
+			startDate := reportFilter(filters, "startDate", now.Format("2006-01")+"-01")
+			endDate := reportFilter(filters, "endDate", now.Format("2006-01-02"))
+			start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+			if err != nil {
+				return nil, "", err
+			}
+			end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+			if err != nil {
+				return nil, "", err
+			}
+
+			var channel *models.Channel
+			if channelId := filters["channelId"]; channelId != "" {
+				channel = &models.Channel{}
+				if err := rc.DB.WithContext(ctx).Where("id = ?", channelId).First(channel).Error; err != nil {
+					return nil, "", fmt.Errorf("channel with id %s not found", channelId)
+				}
+			}
+
+			reports, totals, err := rc.BuildReturnValuationReport(ctx, start, end.AddDate(0, 0, 1), channel)
+			if err != nil {
+				return nil, "", err
+			}
+			buffer, err := BuildReturnValuationReportXLSX(reports, totals)
+			return buffer, fmt.Sprintf("return-valuation-%s-%s.xlsx", startDate, endDate), err
+		},
+	},
+	"data_quality": {
+		Label:   "Data quality",
+		Roles:   utils.ReportAccessRoles("data_quality"),
+		Filters: []string{"startDate", "endDate", "issue", "orderSource", "masking"},
+		Render: func(rc *ReportController, ctx context.Context, filters map[string]string) (*bytes.Buffer, string, error) {
+			now := time.Now()
+			startDate := reportFilter(filters, "startDate", now.AddDate(0, 0, -30).Format("2006-01-02"))
+			endDate := reportFilter(filters, "endDate", now.Format("2006-01-02"))
+			start, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+			if err != nil {
+				return nil, "", err
+			}
+			end, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+			if err != nil {
+				return nil, "", err
+			}
+
+			rows, _, _, err := rc.BuildDataQualityReport(ctx, start, end.AddDate(0, 0, 1), filters["issue"], reportFilter(filters, "orderSource", models.OrderSourceMarketplace), filters["masking"])
+			if err != nil {
+				return nil, "", err
+			}
+			buffer, err := BuildDataQualityReportXLSX(rows)
+			return buffer, fmt.Sprintf("data-quality-%s-%s.xlsx", startDate, endDate), err
+		},
+	},
+}
+
+// reportFilterFormats are the date layouts report filters must follow
+var reportFilterFormats = map[string]string{
+	"month":     "2006-01",
+	"startDate": "2006-01-02",
+	"endDate":   "2006-01-02",
+}
+
+// reportFilter returns the trimmed filter value or the fallback when it is empty
+func reportFilter(filters map[string]string, key, fallback string) string {
+	if value := strings.TrimSpace(filters[key]); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// scheduledReportFilters replaces the period filters of a scheduled report with the period that ended at runAt:
+// the previous day, the previous 7 days or the previous month. Monthly reports cover the month of the period's last day.
+func scheduledReportFilters(schedule *models.ReportSchedule, reportType reportScheduleType, runAt time.Time) map[string]string {
+	filters := schedule.FilterMap()
+	if schedule.Frequency == models.ReportFrequencyNone {
+		return filters
+	}
+
+	end := time.Date(runAt.Year(), runAt.Month(), runAt.Day(), 0, 0, 0, 0, runAt.Location())
+	start := end.AddDate(0, 0, -1)
+	switch schedule.Frequency {
+	case models.ReportFrequencyWeekly:
+		start = end.AddDate(0, 0, -7)
+	case models.ReportFrequencyMonthly:
+		end = time.Date(runAt.Year(), runAt.Month(), 1, 0, 0, 0, 0, runAt.Location())
+		start = end.AddDate(0, -1, 0)
+	}
+	lastDay := end.AddDate(0, 0, -1)
+
+	if reportType.Monthly {
+		filters["month"] = lastDay.Format("2006-01")
+	} else {
+		filters["startDate"] = start.Format("2006-01-02")
+		filters["endDate"] = lastDay.Format("2006-01-02")
+	}
+	return filters
+}
+
+// canManageReportSchedule reports whether the user owns the schedule or manages every schedule
+func canManageReportSchedule(c fiber.Ctx, schedule *models.ReportSchedule, userID uint) bool {
+	return schedule.CreatedBy == userID || utils.HasPermission(c, []string{"developer", "superadmin"})
+}
+
+// validateReportScheduleRequest normalizes the request and returns a client error message when it is invalid
+func validateReportScheduleRequest(c fiber.Ctx, req *ReportScheduleRequest, masking utils.MaskingPolicy) string {
+	req.Name = strings.TrimSpace(req.Name)
+	req.ReportType = strings.TrimSpace(req.ReportType)
+	req.Frequency = strings.ToLower(strings.TrimSpace(req.Frequency))
+	if req.Frequency == "" {
+		req.Frequency = models.ReportFrequencyNone
+	}
+
+	if req.Name == "" {
+		return "Name is required"
+	}
+	reportType, ok := reportScheduleTypes[req.ReportType]
+	if !ok {
+		return "Invalid report type " + req.ReportType
+	}
+	if !utils.HasPermission(c, reportType.Roles) {
+		return "You do not have access to the " + reportType.Label + " report"
+	}
+
+	// Only the query parameters of the report are accepted as filters
+	filters := make(map[string]string, len(req.Filters))
+	for key, value := range req.Filters {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		allowed := false
+		for _, filter := range reportType.Filters {
+			if filter == key {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "Invalid filter " + key + " for the " + reportType.Label + " report"
+		}
+		if layout, ok := reportFilterFormats[key]; ok {
+			if _, err := time.Parse(layout, value); err != nil {
+				return "Invalid " + key + " format. Use " + strings.NewReplacer("2006", "YYYY", "01", "MM", "02", "DD").Replace(layout) + "."
+			}
+		}
+		if key == "channelId" {
+			if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+				return "Invalid channelId"
+			}
+		}
+		if key == "orderSource" && !models.IsValidOrderSource(value) && !(value == "all" && req.ReportType == "data_quality") {
+			return "Invalid orderSource. Use marketplace, offline, replacement or internal."
+		}
+		if key == "issue" && !utils.IsDataQualityIssueType(value) {
+			return "Invalid issue. Use " + strings.Join(utils.DataQualityIssueTypes, ", ") + "."
+		}
+		if key == "masking" {
+			userRoles, _ := c.Locals("userRoles").([]string)
+			if _, err := masking.Resolve(userRoles, value); err != nil {
+				return "Invalid masking. Use " + masking.ProfileForRoles(userRoles) + " or a more masked profile of full, partial or anonymized."
+			}
+		}
+		filters[key] = value
+	}
+	req.Filters = filters
+
+	switch req.Frequency {
+	case models.ReportFrequencyNone, models.ReportFrequencyDaily, models.ReportFrequencyWeekly, models.ReportFrequencyMonthly:
+	default:
+		return "Invalid frequency. Use none, daily, weekly or monthly."
+	}
+	if req.RunHour == nil {
+		runHour := 7
+		req.RunHour = &runHour
+	}
+	if *req.RunHour < 0 || *req.RunHour > 23 {
+		return "runHour must be between 0 and 23"
+	}
+
+	// Recipients are required to email the report
+	recipients := make([]string, 0, len(req.Recipients))
+	for _, recipient := range req.Recipients {
+		recipient = strings.ToLower(strings.TrimSpace(recipient))
+		if recipient == "" {
+			continue
+		}
+		address, err := mail.ParseAddress(recipient)
+		if err != nil || address.Address != recipient {
+			return "Invalid recipient email " + recipient
+		}
+		recipients = append(recipients, recipient)
+	}
+	req.Recipients = recipients
+	if req.Frequency != models.ReportFrequencyNone && len(recipients) == 0 {
+		return "At least one recipient is required for a scheduled report"
+	}
+
+	if req.Active == nil {
+		active := true
+		req.Active = &active
+	}
+	return ""
+}
+
+// applyReportScheduleRequest copies a validated request onto the schedule and plans its next run
+func applyReportScheduleRequest(schedule *models.ReportSchedule, req *ReportScheduleRequest) {
+	filters, _ := json.Marshal(req.Filters)
+	schedule.Name = req.Name
+	schedule.ReportType = req.ReportType
+	schedule.Filters = string(filters)
+	schedule.Frequency = req.Frequency
+	schedule.RunHour = *req.RunHour
+	schedule.Recipients = strings.Join(req.Recipients, ",")
+	schedule.Active = *req.Active
+	schedule.NextRunAt = nil
+	if schedule.Active {
+		schedule.NextRunAt = models.NextReportRun(schedule.Frequency, schedule.RunHour, time.Now())
+	}
+}
+
+// GetReportScheduleTypes lists the reports that can be saved and scheduled
+// @Summary Get Report Schedule Types
+// @Description List the reports that can be saved as favorites and emailed as XLSX on a schedule, with their accepted filters and the roles allowed to use them
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]ReportScheduleTypeResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/reports/schedules/types [get]
+func (rsc *ReportScheduleController) GetReportScheduleTypes(c fiber.Ctx) error {
+	log.Println("GetReportScheduleTypes called")
+
+	types := make([]ReportScheduleTypeResponse, 0, len(reportScheduleTypes))
+	for key, reportType := range reportScheduleTypes {
+		types = append(types, ReportScheduleTypeResponse{
+			ReportType: key,
+			Label:      reportType.Label,
+			Filters:    reportType.Filters,
+			Roles:      reportType.Roles,
+		})
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i].ReportType < types[j].ReportType })
+
+	log.Println("GetReportScheduleTypes completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Report schedule types retrieved successfully",
+		Data:    types,
+	})
+}
+
+// GetReportSchedules retrieves the saved reports of the current user
+// @Summary Get Report Schedules
+// @Description Retrieve the saved report configurations and schedules of the current user. Developers and superadmins see every user's schedules
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of schedules per page" default(10)
+// @Param reportType query string false "Filter by report type"
+// @Param frequency query string false "Filter by frequency (none, daily, weekly, monthly)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ReportScheduleResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/schedules [get]
+func (rsc *ReportScheduleController) GetReportSchedules(c fiber.Ctx) error {
+	log.Println("GetReportSchedules called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var schedules []models.ReportSchedule
+
+	// Build base query
+	query := rsc.DB.WithContext(c.Context()).Model(&models.ReportSchedule{}).Preload("CreateUser").Order("name ASC, id ASC")
+	if !utils.HasPermission(c, []string{"developer", "superadmin"}) {
+		query = query.Where("created_by = ?", userID)
+	}
+
+	// Report type and frequency filters if provided
+	reportType := c.Query("reportType", "")
+	if reportType != "" {
+		query = query.Where("report_type = ?", reportType)
+	}
+	frequency := c.Query("frequency", "")
+	if frequency != "" {
+		query = query.Where("frequency = ?", frequency)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&schedules).Error; err != nil {
+		log.Println("GetReportSchedules - Failed to retrieve report schedules:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve report schedules",
+		})
+	}
+
+	// Format response
+	scheduleList := make([]models.ReportScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		scheduleList[i] = *schedule.ToResponse()
+	}
+
+	// Build success message
+	message := "Report schedules retrieved successfully"
+	var filters []string
+
+	if reportType != "" {
+		filters = append(filters, "reportType: "+reportType)
+	}
+
+	if frequency != "" {
+		filters = append(filters, "frequency: "+frequency)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetReportSchedules completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    scheduleList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateReportSchedule saves a report configuration, optionally scheduled for email delivery
+// @Summary Create Report Schedule
+// @Description Save a report type with its filters as a favorite. With a daily, weekly (Monday) or monthly (1st) frequency the report is emailed as XLSX to the recipients at the run hour, covering the previous day, 7 days or month
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ReportScheduleRequest true "Report configuration and schedule"
+// @Success 201 {object} utils.SuccessResponse{data=models.ReportScheduleResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/schedules [post]
+func (rsc *ReportScheduleController) CreateReportSchedule(c fiber.Ctx) error {
+	log.Println("CreateReportSchedule called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req ReportScheduleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateReportSchedule - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if message := validateReportScheduleRequest(c, &req, rsc.Reports.Masking); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	schedule := models.ReportSchedule{CreatedBy: uint(userID)}
+	applyReportScheduleRequest(&schedule, &req)
+	if err := rsc.DB.WithContext(c.Context()).Create(&schedule).Error; err != nil {
+		log.Println("CreateReportSchedule - Failed to create report schedule:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create report schedule",
+		})
+	}
+
+	// Reload the schedule with its creator for response
+	if err := rsc.DB.WithContext(c.Context()).Preload("CreateUser").First(&schedule, schedule.ID).Error; err != nil {
+		log.Println("CreateReportSchedule - Failed to load report schedule:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load report schedule",
+		})
+	}
+
+	log.Println("CreateReportSchedule completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Report schedule created successfully",
+		Data:    schedule.ToResponse(),
+	})
+}
+
+// UpdateReportSchedule replaces the configuration and schedule of a saved report
+// @Summary Update Report Schedule
+// @Description Replace the report type, filters, frequency, recipients and active state of a saved report. The next run is planned again from now
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report schedule ID"
+// @Param request body ReportScheduleRequest true "Report configuration and schedule"
+// @Success 200 {object} utils.SuccessResponse{data=models.ReportScheduleResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/schedules/{id} [put]
+func (rsc *ReportScheduleController) UpdateReportSchedule(c fiber.Ctx) error {
+	log.Println("UpdateReportSchedule called")
+	// Parse id parameter
+	id := c.Params("id")
+	var schedule models.ReportSchedule
+	if err := rsc.DB.WithContext(c.Context()).Where("id = ?", id).First(&schedule).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Report schedule with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	if !canManageReportSchedule(c, &schedule, uint(userID)) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You can only update your own report schedules",
+		})
+	}
+
+	// Binding request body
+	var req ReportScheduleRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateReportSchedule - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if message := validateReportScheduleRequest(c, &req, rsc.Reports.Masking); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	applyReportScheduleRequest(&schedule, &req)
+	if err := rsc.DB.WithContext(c.Context()).Save(&schedule).Error; err != nil {
+		log.Println("UpdateReportSchedule - Failed to update report schedule:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update report schedule",
+		})
+	}
+
+	// Reload the schedule with its creator for response
+	if err := rsc.DB.WithContext(c.Context()).Preload("CreateUser").First(&schedule, schedule.ID).Error; err != nil {
+		log.Println("UpdateReportSchedule - Failed to load report schedule:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load report schedule",
+		})
+	}
+
+	log.Println("UpdateReportSchedule completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Report schedule updated successfully",
+		Data:    schedule.ToResponse(),
+	})
+}
+
+// DeleteReportSchedule deletes a saved report
+// @Summary Delete Report Schedule
+// @Description Delete a saved report configuration and stop its email delivery
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report schedule ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/schedules/{id} [delete]
+func (rsc *ReportScheduleController) DeleteReportSchedule(c fiber.Ctx) error {
+	log.Println("DeleteReportSchedule called")
+	// Parse id parameter
+	id := c.Params("id")
+	var schedule models.ReportSchedule
+	if err := rsc.DB.WithContext(c.Context()).Where("id = ?", id).First(&schedule).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Report schedule with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	if !canManageReportSchedule(c, &schedule, uint(userID)) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You can only delete your own report schedules",
+		})
+	}
+
+	if err := rsc.DB.WithContext(c.Context()).Delete(&schedule).Error; err != nil {
+		log.Println("DeleteReportSchedule - Failed to delete report schedule:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete report schedule",
+		})
+	}
+
+	log.Println("DeleteReportSchedule completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Report schedule deleted successfully",
+	})
+}
+
+// DownloadReportSchedule renders a saved report to XLSX
+// @Summary Download Report Schedule
+// @Description Render a saved report with its filters to XLSX. Scheduled reports cover the period of their latest run
+// @Tags Reports
+// @Accept json
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Param id path int true "Report schedule ID"
+// @Success 200 {file} file
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/schedules/{id}/download [get]
+func (rsc *ReportScheduleController) DownloadReportSchedule(c fiber.Ctx) error {
+	log.Println("DownloadReportSchedule called")
+	// Parse id parameter
+	id := c.Params("id")
+	var schedule models.ReportSchedule
+	if err := rsc.DB.WithContext(c.Context()).Where("id = ?", id).First(&schedule).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Report schedule with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	reportType, ok := reportScheduleTypes[schedule.ReportType]
+	if !canManageReportSchedule(c, &schedule, uint(userID)) || !ok || !utils.HasPermission(c, reportType.Roles) {
+		if ok && canManageReportSchedule(c, &schedule, uint(userID)) {
+			// Roles changed since the report was saved
+			utils.RecordReportAccessDenial(rsc.DB, c, schedule.ReportType)
+		}
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You do not have access to this report",
+		})
+	}
+
+	buffer, filename, err := reportType.Render(rsc.Reports, c.Context(), scheduledReportFilters(&schedule, reportType, time.Now()))
+	if err != nil {
+		log.Println("DownloadReportSchedule - Failed to render report:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to export report",
+		})
+	}
+
+	log.Println("DownloadReportSchedule completed successfully")
+	c.Set(fiber.HeaderContentType, utils.XLSXContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// SendReportSchedule emails a saved report to its recipients now
+// @Summary Send Report Schedule
+// @Description Email a saved report as XLSX to its recipients immediately, without changing its next scheduled run
+// @Tags Reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Report schedule ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.ReportScheduleResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/reports/schedules/{id}/send [post]
+func (rsc *ReportScheduleController) SendReportSchedule(c fiber.Ctx) error {
+	log.Println("SendReportSchedule called")
+	// Parse id parameter
+	id := c.Params("id")
+	var schedule models.ReportSchedule
+	if err := rsc.DB.WithContext(c.Context()).Where("id = ?", id).First(&schedule).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Report schedule with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	if !canManageReportSchedule(c, &schedule, uint(userID)) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You can only send your own report schedules",
+		})
+	}
+	if len(schedule.RecipientList()) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Report schedule has no recipients",
+		})
+	}
+
+	if err := deliverReportSchedule(c.Context(), rsc.Config, rsc.DB, rsc.Reports, &schedule, time.Now()); err != nil {
+		log.Println("SendReportSchedule - Failed to send report:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to send report: " + err.Error(),
+		})
+	}
+
+	// Reload the schedule with its creator for response
+	if err := rsc.DB.WithContext(c.Context()).Preload("CreateUser").First(&schedule, schedule.ID).Error; err != nil {
+		log.Println("SendReportSchedule - Failed to load report schedule:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load report schedule",
+		})
+	}
+
+	log.Println("SendReportSchedule completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Report sent to " + strings.Join(schedule.RecipientList(), ", "),
+		Data:    schedule.ToResponse(),
+	})
+}
+
+// deliverReportSchedule renders the report for the period ending at runAt and emails it to the recipients.
+// The report is only rendered while its creator is active and still holds a role allowed to see it.
+// The outcome is recorded on the schedule.
+func deliverReportSchedule(ctx context.Context, cfg *config.Config, db *gorm.DB, reports *ReportController, schedule *models.ReportSchedule, runAt time.Time) error {
+	err := func() error {
+		reportType, ok := reportScheduleTypes[schedule.ReportType]
+		if !ok {
+			return fmt.Errorf("unknown report type %s", schedule.ReportType)
+		}
+
+		var creator models.User
+		if err := db.WithContext(ctx).Preload("Roles").First(&creator, schedule.CreatedBy).Error; err != nil {
+			return fmt.Errorf("failed to load report owner: %w", err)
+		}
+		allowed := false
+		roleNames := make([]string, len(creator.Roles))
+		for i, role := range creator.Roles {
+			roleNames[i] = role.RoleName
+			for _, allowedRole := range reportType.Roles {
+				if role.RoleName == allowedRole {
+					allowed = true
+				}
+			}
+		}
+		if !creator.IsActive || !allowed {
+			return errors.New("the report owner no longer has access to this report")
+		}
+
+		filters := scheduledReportFilters(schedule, reportType, runAt)
+		for _, filter := range reportType.Filters {
+			if filter != "masking" {
+				continue
+			}
+			// Buyer data is masked for the owner's current roles, a profile they may no longer use masks more
+			masking, err := reports.Masking.Resolve(roleNames, filters["masking"])
+			if err != nil {
+				masking = reports.Masking.ProfileForRoles(roleNames)
+			}
+			filters["masking"] = masking
+		}
+		buffer, filename, err := reportType.Render(reports, ctx, filters)
+		if err != nil {
+			return fmt.Errorf("failed to render report: %w", err)
+		}
+
+		// Describe the filters the report was rendered with
+		keys := make([]string, 0, len(filters))
+		for key := range filters {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		lines := []string{
+			"Attached is the " + reportType.Label + " report \"" + schedule.Name + "\".",
+			"",
+		}
+		for _, key := range keys {
+			lines = append(lines, key+": "+filters[key])
+		}
+		lines = append(lines, "", "This report was scheduled by "+creator.FullName+" in "+cfg.AppName+".")
+
+		return utils.SendEmailWithAttachment(cfg, schedule.RecipientList(), cfg.AppName+" report: "+schedule.Name, strings.Join(lines, "\n"), utils.EmailAttachment{
+			Filename:    filename,
+			ContentType: utils.XLSXContentType,
+			Data:        buffer.Bytes(),
+		})
+	}()
+
+	// Record the outcome of the delivery
+	updates := map[string]interface{}{
+		"last_run_at": time.Now(),
+		"last_status": models.ReportDeliverySent,
+		"last_error":  "",
+	}
+	if err != nil {
+		updates["last_status"] = models.ReportDeliveryFailed
+		updates["last_error"] = err.Error()
+	}
+	if updateErr := db.WithContext(ctx).Model(&models.ReportSchedule{}).Where("id = ?", schedule.ID).Updates(updates).Error; updateErr != nil {
+		log.Println("deliverReportSchedule - Failed to record delivery of report schedule", schedule.ID, ":", updateErr)
+	}
+	return err
+}
+
+// RunDueReportSchedules emails every active scheduled report whose next run is due.
+// Each schedule is claimed by moving its next run forward first, so a report is sent once even with several instances.
+// Returns the number of reports sent.
+func RunDueReportSchedules(cfg *config.Config, db *gorm.DB) (int, error) {
+	now := time.Now()
+	reports := NewReportController(cfg, db)
+
+	var schedules []models.ReportSchedule
+	if err := db.Where("active = ? AND frequency <> ? AND next_run_at <= ?", true, models.ReportFrequencyNone, now).
+		Order("next_run_at ASC").Find(&schedules).Error; err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for i := range schedules {
+		schedule := &schedules[i]
+		runAt := *schedule.NextRunAt
+
+		result := db.Model(&models.ReportSchedule{}).Where("id = ? AND next_run_at = ?", schedule.ID, runAt).
+			Update("next_run_at", models.NextReportRun(schedule.Frequency, schedule.RunHour, now))
+		if result.Error != nil {
+			log.Println("RunDueReportSchedules - Failed to claim report schedule", schedule.ID, ":", result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		if err := deliverReportSchedule(context.Background(), cfg, db, reports, schedule, runAt); err != nil {
+			log.Println("RunDueReportSchedules - Failed to deliver report schedule", schedule.ID, ":", err)
+			continue
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// StartReportScheduler emails the scheduled reports that are due periodically in the background
+func StartReportScheduler(cfg *config.Config, db *gorm.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if utils.GetMaintenance().Enabled {
+				log.Println("StartReportScheduler - Skipping scheduled reports during maintenance mode")
+				continue
+			}
+
+			sent, err := RunDueReportSchedules(cfg, db)
+			if err != nil {
+				log.Println("StartReportScheduler - Scheduled reports failed:", err)
+				continue
+			}
+			if sent > 0 {
+				log.Printf("StartReportScheduler - %d scheduled reports sent\n", sent)
+			}
+		}
+	}()
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type RetentionController struct {
+	DB     *gorm.DB
+	Config *config.Config
+}
+
+func NewRetentionController(cfg *config.Config, db *gorm.DB) *RetentionController {
+	return &RetentionController{Config: cfg, DB: db}
+}
+
+// Unique response structs
+// RetentionPolicyResponse represents the configured data retention policy
+type RetentionPolicyResponse struct {
+	Policy               utils.RetentionPolicy `json:"policy"`
+	PurgeIntervalHours   int                   `json:"purgeIntervalHours"`
+	ScheduledPurgeActive bool                  `json:"scheduledPurgeActive"`
+}
+
+// GetRetentionPolicy retrieves the configured data retention policy
+// @Summary Get Retention Policy
+// @Description Retrieve the configured retention period per data category and the scheduled purge interval
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=RetentionPolicyResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/retention/policy [get]
+func (rc *RetentionController) GetRetentionPolicy(c fiber.Ctx) error {
+	log.Println("GetRetentionPolicy called")
+
+	log.Println("GetRetentionPolicy completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Retention policy retrieved successfully",
+		Data: RetentionPolicyResponse{
+			Policy:               utils.RetentionPolicyFromConfig(rc.Config),
+			PurgeIntervalHours:   rc.Config.RetentionPurgeIntervalHours,
+			ScheduledPurgeActive: rc.Config.RetentionPurgeIntervalHours > 0,
+		},
+	})
+}
+
+// RunRetentionPurge purges or anonymizes data older than the retention policy
+// @Summary Run Retention Purge
+// @Description Purge or anonymize old buyer PII, face images, attendance GPS data and QC/handover photos. Runs as a dry-run by default, reporting affected rows without modifying data. Every run is recorded in the purge audit log
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param dryRun query bool false "Only report affected rows without modifying data" default(true)
+// @Success 200 {object} utils.SuccessResponse{data=models.PurgeRunResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/retention/purge [post]
+func (rc *RetentionController) RunRetentionPurge(c fiber.Ctx) error {
+	log.Println("RunRetentionPurge called")
+	// Parse dry run parameter, defaults to true to avoid accidental purges
+	dryRun, err := strconv.ParseBool(c.Query("dryRun", "true"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid dryRun value. Use true or false.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	triggeredBy := uint(userID)
+
+	run, err := utils.RunRetentionPurge(rc.DB, utils.RetentionPolicyFromConfig(rc.Config), dryRun, utils.RetentionTriggerManual, &triggeredBy)
+	if err != nil {
+		log.Println("RunRetentionPurge - Failed to run retention purge:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to run retention purge",
+		})
+	}
+
+	// Reload purge run with trigger user for response
+	if err := rc.DB.Preload("Details").Preload("TriggerUser").First(run, run.ID).Error; err != nil {
+		log.Println("RunRetentionPurge - Failed to load purge run:", err)
+	}
+
+	message := "Retention purge completed successfully"
+	if dryRun {
+		message = "Retention purge dry-run completed successfully"
+	}
+
+	log.Println("RunRetentionPurge completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    run.ToResponse(),
+	})
+}
+
+// GetPurgeRuns retrieves the audit log of retention purge runs
+// @Summary Get Purge Runs
+// @Description Retrieve the audit log of manual and scheduled retention purge runs with pagination
+// @Tags Retention
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of purge runs per page" default(10)
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.PurgeRunResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/retention/runs [get]
+func (rc *RetentionController) GetPurgeRuns(c fiber.Ctx) error {
+	log.Println("GetPurgeRuns called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var runs []models.PurgeRun
+
+	// Build base query
+	query := rc.DB.Model(&models.PurgeRun{}).Preload("Details").Preload("TriggerUser").Order("created_at DESC")
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&runs).Error; err != nil {
+		log.Println("GetPurgeRuns - Failed to retrieve purge runs:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve purge runs",
+		})
+	}
+
+	// Format response
+	runList := make([]models.PurgeRunResponse, len(runs))
+	for i, run := range runs {
+		runList[i] = *run.ToResponse()
+	}
+
+	log.Println("GetPurgeRuns completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: "Purge runs retrieved successfully",
+		Data:    runList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
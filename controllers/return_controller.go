@@ -41,6 +41,36 @@ type UpdateReturnRequest struct {
 	ScrapNumber    *string `json:"scrapNumber,omitempty"`
 }
 
+type ReturnDispositionDetailRequest struct {
+	ProductSKU  string `json:"productSku" validate:"required"`
+	Disposition string `json:"disposition" validate:"required,oneof=restocked damaged disposed"`
+}
+
+type SetReturnDispositionRequest struct {
+	Details []ReturnDispositionDetailRequest `json:"details" validate:"required,dive"`
+}
+
+type ScanReturnRequest struct {
+	TrackingNumber string `json:"trackingNumber" validate:"required"`
+}
+
+// Unique response structs
+// ReturnScanResponse represents the pre-filled return intake data of a scanned parcel
+type ReturnScanResponse struct {
+	TrackingNumber string                        `json:"trackingNumber"`
+	Matched        bool                          `json:"matched"`
+	OrderGineeID   *string                       `json:"orderGineeId,omitempty"`
+	ChannelID      *uint                         `json:"channelId,omitempty"`
+	Channel        string                        `json:"channel,omitempty"`
+	StoreID        *uint                         `json:"storeId,omitempty"`
+	Store          string                        `json:"store,omitempty"`
+	Details        []models.ReturnDetailResponse `json:"details,omitempty"`
+	Order          *models.OrderResponse         `json:"order,omitempty"`
+	Complain       *models.ComplainResponse      `json:"complain,omitempty"`
+	ExistingReturn *models.ReturnResponse        `json:"existingReturn,omitempty"`
+	UnknownReturn  *models.UnknownReturnResponse `json:"unknownReturn,omitempty"`
+}
+
 // GetReturns retrieves a list of returns with pagination and search
 // @Summary Get Returns
 // @Description Retrieve a list of returns with pagination and search
@@ -275,7 +305,7 @@ func (rc *ReturnController) CreateReturn(c fiber.Ctx) error {
 	}
 
 	// Convert new tracking number to uppercase and trim spaces
-	req.NewTrackingNumber = strings.ToUpper(strings.TrimSpace(req.NewTrackingNumber))
+	req.NewTrackingNumber = models.NormalizeIdentifier(req.NewTrackingNumber)
 
 	// Check for duplicate NewTrackingNumber
 	var existingReturn models.Return
@@ -289,7 +319,7 @@ func (rc *ReturnController) CreateReturn(c fiber.Ctx) error {
 	// If TrackingNumber is provided, convert to uppercase and trim spaces
 	var order *models.Order
 	if req.TrackingNumber != nil && *req.TrackingNumber != "" {
-		tracking := strings.ToUpper(strings.TrimSpace(*req.TrackingNumber))
+		tracking := models.NormalizeIdentifier(*req.TrackingNumber)
 		req.TrackingNumber = &tracking
 
 		// Check if it exists in Order
@@ -408,7 +438,7 @@ func (rc *ReturnController) CreateReturn(c fiber.Ctx) error {
 
 // UpdateReturn handles updating an existing return and if details still empty, populate from order details
 // @Summary Update Return
-// @Description Update an existing return
+// @Description Partially update an existing return, omitted fields are kept and fields sent as null are cleared
 // @Tags Returns
 // @Accept json
 // @Produce json
@@ -421,6 +451,7 @@ func (rc *ReturnController) CreateReturn(c fiber.Ctx) error {
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/returns/{id} [put]
+// @Router /api/returns/{id} [patch]
 func (rc *ReturnController) UpdateReturn(c fiber.Ctx) error {
 	log.Println("UpdateReturn called")
 	// Parse id parameters
@@ -436,6 +467,13 @@ func (rc *ReturnController) UpdateReturn(c fiber.Ctx) error {
 
 	// Binding request body
 	var req UpdateReturnRequest
+	fields, err := utils.PresentFields(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
 	if err := c.Bind().JSON(&req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
@@ -455,7 +493,7 @@ func (rc *ReturnController) UpdateReturn(c fiber.Ctx) error {
 
 	// Check if TrackingNumber is provided, convert to uppercase and trim spaces and check if it's exists in Order
 	if req.TrackingNumber != nil {
-		tracking := strings.ToUpper(strings.TrimSpace(*req.TrackingNumber))
+		tracking := models.NormalizeIdentifier(*req.TrackingNumber)
 		req.TrackingNumber = &tracking
 	}
 
@@ -495,11 +533,22 @@ func (rc *ReturnController) UpdateReturn(c fiber.Ctx) error {
 	// Update return fields
 	updatedBy := uint(userID)
 
-	ret.TrackingNumber = req.TrackingNumber
-	ret.ReturnType = req.ReturnType
-	ret.ReturnReason = req.ReturnReason
-	ret.ReturnNumber = req.ReturnNumber
-	ret.ScrapNumber = req.ScrapNumber
+	// Omitted fields are kept, fields sent as null are cleared
+	if fields["trackingNumber"] {
+		ret.TrackingNumber = req.TrackingNumber
+	}
+	if fields["returnType"] {
+		ret.ReturnType = req.ReturnType
+	}
+	if fields["returnReason"] {
+		ret.ReturnReason = req.ReturnReason
+	}
+	if fields["returnNumber"] {
+		ret.ReturnNumber = req.ReturnNumber
+	}
+	if fields["scrapNumber"] {
+		ret.ScrapNumber = req.ScrapNumber
+	}
 	ret.UpdatedBy = &updatedBy
 
 	// Update OrderGineeID if tracking number is provided and order exists
@@ -584,3 +633,422 @@ func (rc *ReturnController) UpdateReturn(c fiber.Ctx) error {
 		Data:    ret.ToResponse(),
 	})
 }
+
+// SetReturnDisposition records the disposition of inspected return items
+// @Summary Set Return Disposition
+// @Description Record per SKU whether returned items were restocked, damaged or disposed. Restocked quantities are added back to inventory. A disposition cannot be changed once recorded
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Return ID"
+// @Param request body SetReturnDispositionRequest true "Disposition per returned SKU"
+// @Success 200 {object} utils.SuccessResponse{data=models.ReturnResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/returns/{id}/disposition [put]
+func (rc *ReturnController) SetReturnDisposition(c fiber.Ctx) error {
+	log.Println("SetReturnDisposition called")
+	// Parse id parameter
+	id := c.Params("id")
+	var ret models.Return
+	if err := rc.DB.Preload("ReturnDetails").First(&ret, id).Error; err != nil {
+		log.Println("SetReturnDisposition - Return not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Return with id " + id + " not found",
+		})
+	}
+
+	// Binding request body
+	var req SetReturnDispositionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SetReturnDisposition - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if len(req.Details) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one disposition detail is required",
+		})
+	}
+
+	// Get current logged in user
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("SetReturnDisposition - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+	userIDUint := uint(userID)
+
+	// Match every requested SKU to a return detail without disposition
+	details := make(map[string]*models.ReturnDetail)
+	for i := range *ret.ReturnDetails {
+		detail := &(*ret.ReturnDetails)[i]
+		if detail.ProductSKU != nil {
+			details[strings.ToUpper(*detail.ProductSKU)] = detail
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, detailReq := range req.Details {
+		sku := strings.ToUpper(strings.TrimSpace(detailReq.ProductSKU))
+		switch detailReq.Disposition {
+		case models.ReturnDispositionRestocked, models.ReturnDispositionDamaged, models.ReturnDispositionDisposed:
+		default:
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid disposition " + detailReq.Disposition + " for SKU " + sku + ". Use restocked, damaged or disposed.",
+			})
+		}
+		if seen[sku] {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Duplicate SKU " + sku + " in the request",
+			})
+		}
+		seen[sku] = true
+
+		detail, ok := details[sku]
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "SKU " + sku + " is not part of this return",
+			})
+		}
+		if detail.Disposition != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "SKU " + sku + " is already " + *detail.Disposition,
+			})
+		}
+	}
+
+	// Start database transaction
+	tx := rc.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	now := time.Now()
+	for _, detailReq := range req.Details {
+		detail := details[strings.ToUpper(strings.TrimSpace(detailReq.ProductSKU))]
+		disposition := detailReq.Disposition
+		if err := tx.Model(detail).Updates(map[string]interface{}{
+			"disposition":      disposition,
+			"dispositioned_by": userIDUint,
+			"dispositioned_at": now,
+		}).Error; err != nil {
+			tx.Rollback()
+			log.Println("SetReturnDisposition - Failed to update return detail:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to update return disposition",
+			})
+		}
+
+		// Restocked items go back to the sellable stock
+		if disposition == models.ReturnDispositionRestocked && detail.Quantity != nil && *detail.Quantity > 0 {
+			if _, err := utils.AdjustInventory(tx, utils.InventoryAdjustment{
+				SKU:           *detail.ProductSKU,
+				Quantity:      *detail.Quantity,
+				Type:          utils.InventoryMovementReturn,
+				ReferenceType: "return",
+				ReferenceID:   ret.ID,
+				Note:          "Restocked from return " + ret.NewTrackingNumber,
+				CreatedBy:     &userIDUint,
+			}); err != nil {
+				tx.Rollback()
+				log.Println("SetReturnDisposition - Failed to restock inventory:", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Failed to restock inventory for SKU " + *detail.ProductSKU,
+				})
+			}
+		}
+	}
+
+	if err := tx.Model(&ret).Update("updated_by", userIDUint).Error; err != nil {
+		tx.Rollback()
+		log.Println("SetReturnDisposition - Failed to update return:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update return",
+		})
+	}
+
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		log.Println("SetReturnDisposition - Failed to commit transaction:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to commit transaction",
+		})
+	}
+
+	// Reload return with details
+	if err := rc.DB.Preload("ReturnDetails").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("UpdateUser").First(&ret, ret.ID).Error; err != nil {
+		log.Println("SetReturnDisposition - Failed to retrieve updated return:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve updated return",
+		})
+	}
+
+	log.Println("SetReturnDisposition completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Return disposition recorded successfully",
+		Data:    ret.ToResponse(),
+	})
+}
+
+// ScanReturn scans an incoming return parcel and matches it to the original order and any open complain
+// @Summary Scan Return
+// @Description Scan a return parcel tracking number, auto-match it to the original order and open complain and pre-fill channel, store and product details. Unmatched parcels are flagged into the unknown returns queue
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ScanReturnRequest true "Return parcel tracking number"
+// @Success 200 {object} utils.SuccessResponse{data=ReturnScanResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/returns/scan [post]
+func (rc *ReturnController) ScanReturn(c fiber.Ctx) error {
+	log.Println("ScanReturn called")
+	// Binding request body
+	var req ScanReturnRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ScanReturn - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Convert tracking number to uppercase and trim spaces
+	trackingNumber := models.NormalizeIdentifier(req.TrackingNumber)
+	if trackingNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Tracking number is required",
+		})
+	}
+
+	// Get current user logged in user
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	response := ReturnScanResponse{TrackingNumber: trackingNumber}
+
+	// Check if the parcel has already been registered as a return
+	var existingReturn models.Return
+	if err := rc.DB.Preload("Channel").Preload("Store").Preload("CreateUser").Preload("UpdateUser").
+		Where("new_tracking_number = ? OR tracking_number = ?", trackingNumber, trackingNumber).
+		First(&existingReturn).Error; err == nil {
+		existingResponse := existingReturn.ToResponse()
+		response.ExistingReturn = &existingResponse
+	}
+
+	// Match the original order by tracking number
+	var order models.Order
+	if err := rc.DB.Preload("OrderDetails").Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		// Flag unmatched parcel into the unknown returns queue
+		var unknownReturn models.UnknownReturn
+		if err := rc.DB.Where("tracking_number = ?", trackingNumber).First(&unknownReturn).Error; err == nil {
+			if err := rc.DB.Model(&unknownReturn).Update("scan_count", gorm.Expr("scan_count + 1")).Error; err != nil {
+				log.Println("ScanReturn - Failed to update unknown return:", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Failed to update unknown return",
+				})
+			}
+		} else {
+			unknownReturn = models.UnknownReturn{
+				TrackingNumber: trackingNumber,
+				Status:         "open",
+				ScanCount:      1,
+				ScannedBy:      uint(userID),
+			}
+			if err := rc.DB.Create(&unknownReturn).Error; err != nil {
+				log.Println("ScanReturn - Failed to create unknown return:", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Failed to flag unknown return",
+				})
+			}
+		}
+
+		if err := rc.DB.Preload("ScanUser").First(&unknownReturn, unknownReturn.ID).Error; err == nil {
+			unknownResponse := unknownReturn.ToResponse()
+			response.UnknownReturn = &unknownResponse
+		}
+
+		log.Println("ScanReturn - No order found, flagged as unknown return:", trackingNumber)
+		return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+			Success: true,
+			Message: "No order found with tracking number " + trackingNumber + ", flagged as unknown return",
+			Data:    response,
+		})
+	}
+
+	response.Matched = true
+	response.OrderGineeID = &order.OrderGineeID
+	response.Order = order.ToOrderResponse()
+
+	// Pre-fill channel and store from the order
+	var channel models.Channel
+	if err := rc.DB.Where("LOWER(channel_name) = LOWER(?) OR LOWER(channel_code) = LOWER(?)", order.Channel, order.Channel).First(&channel).Error; err == nil {
+		response.ChannelID = &channel.ID
+		response.Channel = channel.ChannelName
+	}
+
+	var store models.Store
+	if err := rc.DB.Where("LOWER(store_name) = LOWER(?) OR LOWER(store_code) = LOWER(?)", order.Store, order.Store).First(&store).Error; err == nil {
+		response.StoreID = &store.ID
+		response.Store = store.StoreName
+	}
+
+	// Pre-fill product details from order details
+	response.Details = make([]models.ReturnDetailResponse, len(order.OrderDetails))
+	for i := range order.OrderDetails {
+		detail := &order.OrderDetails[i]
+		detailResp := models.ReturnDetailResponse{
+			ProductSKU: &detail.SKU,
+			Quantity:   &detail.Quantity,
+			Price:      &detail.Price,
+		}
+		var product models.Product
+		if err := rc.DB.Where("sku = ?", detail.SKU).First(&product).Error; err == nil {
+			detailResp.Product = product.ToResponse()
+		}
+		response.Details[i] = detailResp
+	}
+
+	// Match any open complain for the order
+	var complain models.Complain
+	if err := rc.DB.Preload("ComplainProductDetails").Preload("ComplainUserDetails.User").Preload("Channel").Preload("Store").Preload("CreateUser").Preload("RootCause").Preload("AssignUser").
+		Where("tracking_number = ? AND checked = ?", trackingNumber, false).
+		First(&complain).Error; err == nil {
+		response.Complain = complain.ToComplainResponse()
+	}
+
+	// Resolve a previously flagged unknown return now that the order is known
+	if err := rc.DB.Model(&models.UnknownReturn{}).Where("tracking_number = ? AND status = ?", trackingNumber, "open").Update("status", "matched").Error; err != nil {
+		log.Println("ScanReturn - Failed to resolve unknown return:", err)
+	}
+
+	log.Println("ScanReturn completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Return parcel matched to order " + order.OrderGineeID,
+		Data:    response,
+	})
+}
+
+// GetUnknownReturns retrieves the queue of scanned return parcels that could not be matched to any order
+// @Summary Get Unknown Returns
+// @Description Retrieve the unknown returns queue with pagination, status filter and search
+// @Tags Returns
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of unknown returns per page" default(10)
+// @Param status query string false "Filter by status (open, matched)" default(open)
+// @Param search query string false "Search term for tracking number"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.UnknownReturnResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/returns/unknown [get]
+func (rc *ReturnController) GetUnknownReturns(c fiber.Ctx) error {
+	log.Println("GetUnknownReturns called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var unknownReturns []models.UnknownReturn
+
+	// Build base query
+	query := rc.DB.Model(&models.UnknownReturn{}).Preload("ScanUser").Order("created_at DESC")
+
+	// Status filter, defaults to open
+	status := strings.TrimSpace(c.Query("status", "open"))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("tracking_number ILIKE ?", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&unknownReturns).Error; err != nil {
+		log.Println("GetUnknownReturns - Failed to retrieve unknown returns:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve unknown returns",
+		})
+	}
+
+	// Format response
+	unknownReturnList := make([]models.UnknownReturnResponse, len(unknownReturns))
+	for i, unknownReturn := range unknownReturns {
+		unknownReturnList[i] = unknownReturn.ToResponse()
+	}
+
+	// Build success message
+	message := "Unknown returns retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	// Return success response
+	log.Println("GetUnknownReturns completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    unknownReturnList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/testutil"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Partial return updates keep omitted fields and clear the fields sent as null.
+// UpdateReturn opens its transaction with DB.Begin, so the test runs against the database itself.
+func TestUpdateReturnPartial(t *testing.T) {
+	testutil.RequireDatabase(t, testDB)
+
+	f := testutil.NewFactory(t, testDB)
+	admin := f.User("superadmin")
+	var channel models.Channel
+	var store models.Store
+	if err := testDB.Order("id").First(&channel).Error; err != nil {
+		t.Fatalf("no channel found: %v", err)
+	}
+	if err := testDB.Order("id").First(&store).Error; err != nil {
+		t.Fatalf("no store found: %v", err)
+	}
+	returnController := NewReturnController(testDB)
+
+	text := func(s string) *string { return &s }
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantType   *string
+		wantReason *string
+	}{
+		{"omitted fields are kept", `{"returnType":"exchange"}`, fiber.StatusOK, text("exchange"), text("Wrong size")},
+		{"field sent as null is cleared", `{"returnReason":null}`, fiber.StatusOK, text("refund"), nil},
+		{"empty body changes nothing", `{}`, fiber.StatusOK, text("refund"), text("Wrong size")},
+		{"unknown tracking number is rejected", `{"trackingNumber":"NOPE-0000","returnType":"exchange"}`, fiber.StatusBadRequest, text("refund"), text("Wrong size")},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ret := models.Return{
+				NewTrackingNumber: fmt.Sprintf("RET%d%02d", time.Now().UnixNano(), i),
+				ChannelID:         channel.ID,
+				StoreID:           store.ID,
+				CreatedBy:         admin.ID,
+				ReturnType:        text("refund"),
+				ReturnReason:      text("Wrong size"),
+			}
+			if err := testDB.Create(&ret).Error; err != nil {
+				t.Fatalf("failed to create return: %v", err)
+			}
+
+			resp, result := callHandler(t, returnController.UpdateReturn, testRequest{
+				Method: fiber.MethodPatch, Route: "/api/returns/:id", Path: "/api/returns/" + strconv.FormatUint(uint64(ret.ID), 10),
+				User: admin, Roles: []string{"superadmin"},
+				Body: json.RawMessage(tt.body),
+			})
+			expectStatus(t, tt.name, resp, result, tt.wantStatus)
+
+			var after models.Return
+			if err := testDB.First(&after, ret.ID).Error; err != nil {
+				t.Fatalf("failed to reload return: %v", err)
+			}
+			if !equalText(after.ReturnType, tt.wantType) {
+				t.Errorf("return type = %v, want %v", textValue(after.ReturnType), textValue(tt.wantType))
+			}
+			if !equalText(after.ReturnReason, tt.wantReason) {
+				t.Errorf("return reason = %v, want %v", textValue(after.ReturnReason), textValue(tt.wantReason))
+			}
+		})
+	}
+}
+
+// equalText compares optional strings, nil only equals nil
+func equalText(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// textValue formats an optional string for messages
+func textValue(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return strconv.Quote(*s)
+}
@@ -125,6 +125,8 @@ func (rfc *RibbonFlowController) BuildRibbonFlow(trackingNumber string) RibbonFl
 			orderInfo.ProcessingStatus = "Picking in Progress"
 		case "picking_pending":
 			orderInfo.ProcessingStatus = "Picking is Pending"
+		case "awaiting_stock":
+			orderInfo.ProcessingStatus = "Awaiting Stock"
 		case "picking_completed":
 			orderInfo.ProcessingStatus = "Picking Completed"
 		case "qc_progress":
@@ -141,8 +143,8 @@ func (rfc *RibbonFlowController) BuildRibbonFlow(trackingNumber string) RibbonFl
 			orderInfo.EventStatus = "In Progress"
 		case "completed":
 			orderInfo.EventStatus = "Completed"
-		case "cancelled":
-			orderInfo.EventStatus = "Cancelled"
+		case "canceled":
+			orderInfo.EventStatus = "Canceled"
 		case "pending":
 			orderInfo.EventStatus = "Pending"
 		case "duplicated":
@@ -360,7 +362,7 @@ func (rfc *RibbonFlowController) GetRibbonFlows(c fiber.Ctx) error {
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/ribbons/flows/{trackingNumber} [get]
 func (rfc *RibbonFlowController) GetRibbonFlow(c fiber.Ctx) error {
-	trackingNumber := c.Params("trackingNumber")
+	trackingNumber := models.NormalizeIdentifier(c.Params("trackingNumber"))
 
 	if trackingNumber == "" {
 		log.Println("Tracking number is required")
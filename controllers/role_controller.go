@@ -7,6 +7,7 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -31,6 +32,26 @@ type UpdateRoleRequest struct {
 	Hierarchy int    `json:"hierarchy" validate:"required,min=1"`
 }
 
+type AssignRoleUsersRequest struct {
+	UserIDs []uint `json:"userIds" validate:"required,min=1,max=200"`
+}
+
+// maxBulkRoleAssign caps the users assigned in one bulk request
+const maxBulkRoleAssign = 200
+
+// SkippedRoleUser is a user a bulk role assignment left unchanged, with the reason
+type SkippedRoleUser struct {
+	UserID uint   `json:"userId"`
+	Reason string `json:"reason"`
+}
+
+// AssignRoleUsersResponse is the outcome of a bulk role assignment
+type AssignRoleUsersResponse struct {
+	RoleName string            `json:"roleName"`
+	Assigned []uint            `json:"assigned"`
+	Skipped  []SkippedRoleUser `json:"skipped"`
+}
+
 // GetRoles retrieves a list of roles with pagination and search
 // @Summary Get Roles
 // @Description Retrieve a list of roles with pagination and search
@@ -335,8 +356,23 @@ func (rc *RoleController) DeleteRole(c fiber.Ctx) error {
 		})
 	}
 
-	// Delete role (also deletes user_roles due to foreign key constraint with ON DELETE CASCADE)
-	if err := rc.DB.Delete(&role).Error; err != nil {
+	// Delete role (also deletes user_roles due to foreign key constraint with ON DELETE CASCADE),
+	// auditing the revocation from every user holding it
+	currUserID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	changedBy := uint(currUserID)
+	if err := rc.DB.Transaction(func(tx *gorm.DB) error {
+		var holderIDs []uint
+		if err := tx.Model(&models.UserRole{}).Where("role_id = ?", role.ID).Pluck("user_id", &holderIDs).Error; err != nil {
+			return err
+		}
+		if err := utils.RecordRoleChange(tx, role, models.RoleAuditActionRevoke, utils.RoleChangeSourceRoleDelete, &changedBy, holderIDs...); err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.UserRole{}, "role_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	}); err != nil {
 		log.Println("DeleteRole - Failed to delete role:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -350,3 +386,343 @@ func (rc *RoleController) DeleteRole(c fiber.Ctx) error {
 		Message: "Role deleted successfully",
 	})
 }
+
+// AssignRoleUsers assigns a role to several users at once
+// @Summary Bulk Assign Role
+// @Description Assign a role to a list of users, e.g. when onboarding a team. The role must not outrank the current user, and users who outrank the current user, already have the role or do not exist are skipped with a reason. Every grant is recorded in the role audit
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param roleName path string true "Role name"
+// @Param request body AssignRoleUsersRequest true "Users to assign the role to"
+// @Success 200 {object} utils.SuccessResponse{data=AssignRoleUsersResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/roles/{roleName}/assign [post]
+func (rc *RoleController) AssignRoleUsers(c fiber.Ctx) error {
+	log.Println("AssignRoleUsers called")
+	// Parse roleName parameter
+	roleName := c.Params("roleName")
+	var role models.Role
+	if err := rc.DB.Where("role_name = ?", roleName).First(&role).Error; err != nil {
+		log.Println("AssignRoleUsers - Role not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Role with name " + roleName + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req AssignRoleUsersRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("AssignRoleUsers - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if len(req.UserIDs) == 0 || len(req.UserIDs) > maxBulkRoleAssign {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("userIds must contain between 1 and %d users", maxBulkRoleAssign),
+		})
+	}
+
+	// Check permission hierarchy - current user must have higher or equal privilege than the role
+	currUserMinHierarchy := utils.MinRoleHierarchy(rc.DB, c.Locals("userRoles").([]string))
+	if role.Hierarchy < currUserMinHierarchy {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Insufficient permissions",
+		})
+	}
+
+	// Load the requested users with their roles
+	var users []models.User
+	if err := rc.DB.Preload("Roles").Where("id IN ?", req.UserIDs).Find(&users).Error; err != nil {
+		log.Println("AssignRoleUsers - Failed to load users:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load users",
+		})
+	}
+	usersByID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	// Decide per user, keeping the request order and ignoring repeated ids
+	response := AssignRoleUsersResponse{RoleName: role.RoleName, Assigned: []uint{}, Skipped: []SkippedRoleUser{}}
+	seen := make(map[uint]bool, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		user, ok := usersByID[userID]
+		if !ok {
+			response.Skipped = append(response.Skipped, SkippedRoleUser{UserID: userID, Reason: "user not found"})
+			continue
+		}
+		userMinHierarchy := 999
+		hasRole := false
+		for _, userRole := range user.Roles {
+			if userRole.ID == role.ID {
+				hasRole = true
+			}
+			if userRole.Hierarchy < userMinHierarchy {
+				userMinHierarchy = userRole.Hierarchy
+			}
+		}
+		switch {
+		case hasRole:
+			response.Skipped = append(response.Skipped, SkippedRoleUser{UserID: userID, Reason: "user already has this role"})
+		case userMinHierarchy < currUserMinHierarchy:
+			response.Skipped = append(response.Skipped, SkippedRoleUser{UserID: userID, Reason: "user has higher privilege than you"})
+		default:
+			response.Assigned = append(response.Assigned, userID)
+		}
+	}
+
+	// Assign the role to every eligible user together with the audit records
+	if len(response.Assigned) > 0 {
+		currUserID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+		changedBy := uint(currUserID)
+		if err := rc.DB.Transaction(func(tx *gorm.DB) error {
+			userRoles := make([]models.UserRole, len(response.Assigned))
+			for i, userID := range response.Assigned {
+				userRoles[i] = models.UserRole{UserID: userID, RoleID: role.ID}
+			}
+			if err := tx.Create(&userRoles).Error; err != nil {
+				return err
+			}
+			return utils.RecordRoleChange(tx, role, models.RoleAuditActionGrant, utils.RoleChangeSourceBulk, &changedBy, response.Assigned...)
+		}); err != nil {
+			log.Println("AssignRoleUsers - Failed to assign role to users:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to assign role to users",
+			})
+		}
+	}
+
+	log.Println("AssignRoleUsers completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Role %s assigned to %d users, %d skipped", role.RoleName, len(response.Assigned), len(response.Skipped)),
+		Data:    response,
+	})
+}
+
+// GetRoleUsers retrieves the users holding a role
+// @Summary Get Role Users
+// @Description Retrieve the users holding a role with pagination and search
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param roleName path string true "Role name"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of users per page" default(10)
+// @Param search query string false "Search term for username or full name"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.UserResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/roles/{roleName}/users [get]
+func (rc *RoleController) GetRoleUsers(c fiber.Ctx) error {
+	log.Println("GetRoleUsers called")
+	// Parse roleName parameter
+	roleName := c.Params("roleName")
+	var role models.Role
+	if err := rc.DB.Where("role_name = ?", roleName).First(&role).Error; err != nil {
+		log.Println("GetRoleUsers - Role not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Role with name " + roleName + " not found.",
+		})
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var users []models.User
+
+	// Build base query
+	query := rc.DB.Model(&models.User{}).Order("users.username ASC").Preload("Roles").
+		Where("users.id IN (?)", rc.DB.Model(&models.UserRole{}).Select("user_id").Where("role_id = ?", role.ID))
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("username ILIKE ? OR full_name ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&users).Error; err != nil {
+		log.Println("GetRoleUsers - Failed to retrieve users:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve users",
+		})
+	}
+
+	// Format response
+	userList := make([]models.UserResponse, len(users))
+	for i, user := range users {
+		userList[i] = *user.ToResponse()
+	}
+
+	// Build success message
+	message := "Users with role " + role.RoleName + " retrieved successfully"
+	if search != "" {
+		message += fmt.Sprintf(" (filtered by search: %s)", search)
+	}
+
+	log.Println("GetRoleUsers completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    userList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetRoleAudits retrieves the audit trail of role grants and revocations
+// @Summary Get Role Audit
+// @Description Retrieve the audit trail of roles granted to and revoked from users with pagination, newest first
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of audit records per page" default(10)
+// @Param roleName query string false "Filter by role name"
+// @Param userId query int false "Filter by user ID"
+// @Param action query string false "Filter by action (grant, revoke)"
+// @Param startDate query string false "Filter from date (YYYY-MM-DD)"
+// @Param endDate query string false "Filter until date (YYYY-MM-DD)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.RoleAuditResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/roles/audit [get]
+func (rc *RoleController) GetRoleAudits(c fiber.Ctx) error {
+	log.Println("GetRoleAudits called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var audits []models.RoleAudit
+
+	// Build base query
+	query := rc.DB.Model(&models.RoleAudit{}).Order("created_at DESC, id DESC").Preload("User").Preload("ChangeUser")
+
+	var filters []string
+
+	// Filter by role name if provided
+	roleName := strings.TrimSpace(c.Query("roleName", ""))
+	if roleName != "" {
+		query = query.Where("role_name = ?", roleName)
+		filters = append(filters, "role: "+roleName)
+	}
+
+	// Filter by user if provided
+	userID, _ := strconv.ParseUint(c.Query("userId", "0"), 10, 32)
+	if userID > 0 {
+		query = query.Where("user_id = ?", userID)
+		filters = append(filters, fmt.Sprintf("user: %d", userID))
+	}
+
+	// Filter by action if provided
+	action := strings.TrimSpace(c.Query("action", ""))
+	if action != "" {
+		if action != models.RoleAuditActionGrant && action != models.RoleAuditActionRevoke {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid action, must be grant or revoke",
+			})
+		}
+		query = query.Where("action = ?", action)
+		filters = append(filters, "action: "+action)
+	}
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at >= ?", parsedStartDate)
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at < ?", parsedEndDate.AddDate(0, 0, 1))
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&audits).Error; err != nil {
+		log.Println("GetRoleAudits - Failed to retrieve role audit:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve role audit",
+		})
+	}
+
+	// Format response
+	auditList := make([]models.RoleAuditResponse, len(audits))
+	for i, audit := range audits {
+		auditList[i] = *audit.ToResponse()
+	}
+
+	// Build success message
+	message := "Role audit retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetRoleAudits completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    auditList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
@@ -0,0 +1,177 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type SecurityEventController struct {
+	DB *gorm.DB
+}
+
+func NewSecurityEventController(db *gorm.DB) *SecurityEventController {
+	return &SecurityEventController{DB: db}
+}
+
+// GetSecurityEvents retrieves the security event log
+// @Summary Get Security Events
+// @Description Retrieve failed logins, permission-denied attempts, impersonations, role changes and the alerts raised from them, with pagination, newest first
+// @Tags Security Events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of events per page" default(10)
+// @Param eventType query string false "Filter by event type (failed_login, permission_denied, impersonation, role_change, alert)"
+// @Param userId query int false "Filter by acting or target user ID"
+// @Param username query string false "Filter by username"
+// @Param ipAddress query string false "Filter by IP address"
+// @Param startDate query string false "Filter from date (YYYY-MM-DD)"
+// @Param endDate query string false "Filter until date (YYYY-MM-DD)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.SecurityEventResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/security-events [get]
+func (sec *SecurityEventController) GetSecurityEvents(c fiber.Ctx) error {
+	log.Println("GetSecurityEvents called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var events []models.SecurityEvent
+
+	// Build base query
+	query := sec.DB.Model(&models.SecurityEvent{}).Order("created_at DESC, id DESC").Preload("User").Preload("TargetUser")
+
+	var filters []string
+
+	// Filter by event type if provided
+	eventType := strings.TrimSpace(c.Query("eventType", ""))
+	if eventType != "" {
+		if !slices.Contains(models.SecurityEventTypes(), eventType) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid eventType " + eventType,
+			})
+		}
+		query = query.Where("event_type = ?", eventType)
+		filters = append(filters, "eventType: "+eventType)
+	}
+
+	// Filter by acting or target user if provided
+	userID, _ := strconv.ParseUint(c.Query("userId", "0"), 10, 32)
+	if userID > 0 {
+		query = query.Where("user_id = ? OR target_user_id = ?", userID, userID)
+		filters = append(filters, fmt.Sprintf("user: %d", userID))
+	}
+
+	// Filter by username if provided
+	username := utils.NormalizeUsername(c.Query("username", ""))
+	if username != "" {
+		query = query.Where("username = ?", username)
+		filters = append(filters, "username: "+username)
+	}
+
+	// Filter by IP address if provided
+	ipAddress := strings.TrimSpace(c.Query("ipAddress", ""))
+	if ipAddress != "" {
+		query = query.Where("ip_address = ?", ipAddress)
+		filters = append(filters, "ipAddress: "+ipAddress)
+	}
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at >= ?", parsedStartDate)
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at < ?", parsedEndDate.AddDate(0, 0, 1))
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		log.Println("GetSecurityEvents - Failed to retrieve security events:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve security events",
+		})
+	}
+
+	// Format response
+	eventList := make([]models.SecurityEventResponse, len(events))
+	for i, event := range events {
+		eventList[i] = *event.ToResponse()
+	}
+
+	// Build success message
+	message := "Security events retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetSecurityEvents completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    eventList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetSecurityAlertRules retrieves the enabled security alert rules
+// @Summary Get Security Alert Rules
+// @Description Retrieve the enabled alert rules: the number of events of a type from one account, user or IP within the window that notifies developers and superadmins
+// @Tags Security Events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]utils.SecurityAlertRule}
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/security-events/alert-rules [get]
+func (sec *SecurityEventController) GetSecurityAlertRules(c fiber.Ctx) error {
+	log.Println("GetSecurityAlertRules called")
+	rules := utils.GetSecurityAlertRules()
+
+	log.Println("GetSecurityAlertRules completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Security alert rules retrieved successfully",
+		Data:    rules,
+	})
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ShadowDivergenceController struct {
+	DB *gorm.DB
+}
+
+func NewShadowDivergenceController(db *gorm.DB) *ShadowDivergenceController {
+	return &ShadowDivergenceController{DB: db}
+}
+
+// GetShadowDivergences retrieves the requests where a shadow implementation disagreed with the primary one
+// @Summary Get Shadow Divergences
+// @Description Retrieve requests where the shadow implementation of an experiment, e.g. the unified QC service, answered differently than the handler serving the response, with pagination, newest first
+// @Tags Shadow Divergences
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of divergences per page" default(10)
+// @Param experiment query string false "Filter by experiment (unified_qc)"
+// @Param operation query string false "Filter by operation (ribbon_scan, ribbon_validate, online_scan, online_validate)"
+// @Param subject query string false "Filter by tracking number"
+// @Param startDate query string false "Filter from date (YYYY-MM-DD)"
+// @Param endDate query string false "Filter until date (YYYY-MM-DD)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ShadowDivergenceResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/shadow-divergences [get]
+func (sdc *ShadowDivergenceController) GetShadowDivergences(c fiber.Ctx) error {
+	log.Println("GetShadowDivergences called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var divergences []models.ShadowDivergence
+
+	// Build base query
+	query := sdc.DB.Model(&models.ShadowDivergence{}).Order("created_at DESC, id DESC").Preload("User")
+
+	var filters []string
+
+	// Filter by experiment if provided
+	experiment := strings.TrimSpace(c.Query("experiment", ""))
+	if experiment != "" {
+		query = query.Where("experiment = ?", experiment)
+		filters = append(filters, "experiment: "+experiment)
+	}
+
+	// Filter by operation if provided
+	operation := strings.TrimSpace(c.Query("operation", ""))
+	if operation != "" {
+		query = query.Where("operation = ?", operation)
+		filters = append(filters, "operation: "+operation)
+	}
+
+	// Filter by subject if provided
+	subject := models.NormalizeIdentifier(c.Query("subject", ""))
+	if subject != "" {
+		query = query.Where("subject = ?", subject)
+		filters = append(filters, "subject: "+subject)
+	}
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at >= ?", parsedStartDate)
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at < ?", parsedEndDate.AddDate(0, 0, 1))
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&divergences).Error; err != nil {
+		log.Println("GetShadowDivergences - Failed to retrieve shadow divergences:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve shadow divergences",
+		})
+	}
+
+	// Format response
+	divergenceList := make([]models.ShadowDivergenceResponse, len(divergences))
+	for i := range divergences {
+		divergenceList[i] = *divergences[i].ToResponse()
+	}
+
+	// Build success message
+	message := "Shadow divergences retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetShadowDivergences completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    divergenceList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetShadowStats retrieves how often shadow implementations agreed with the primary ones
+// @Summary Get Shadow Stats
+// @Description Retrieve the compared, diverged and failed shadow runs of every experiment operation since the server started, with the match rate, to judge whether a refactor is ready for cutover
+// @Tags Shadow Divergences
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]utils.ShadowStat}
+// @Failure 401 {object} utils.ErrorResponse
+// @Router /api/shadow-divergences/stats [get]
+func (sdc *ShadowDivergenceController) GetShadowStats(c fiber.Ctx) error {
+	log.Println("GetShadowStats called")
+	stats := utils.GetShadowStats()
+
+	log.Println("GetShadowStats completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Shadow stats retrieved successfully",
+		Data:    stats,
+	})
+}
@@ -0,0 +1,810 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// maxSkillCoverageDays bounds the date range of the skill coverage report
+const maxSkillCoverageDays = 31
+
+// Attendance statuses of the kiosk shifts covered by the skill coverage report
+var skillCoverageShifts = []string{"fullday", "halfday"}
+
+var skillCodePattern = regexp.MustCompile(`^[a-z0-9_]{2,50}$`)
+
+type SkillController struct {
+	DB *gorm.DB
+}
+
+func NewSkillController(db *gorm.DB) *SkillController {
+	return &SkillController{DB: db}
+}
+
+// Request structs
+type CreateSkillRequest struct {
+	Code             string `json:"code" validate:"required" example:"fragile_goods"`
+	Name             string `json:"name" validate:"required,min=2,max=100" example:"Fragile goods handling"`
+	Description      string `json:"description" example:"Trained to pick and pack glassware and ceramics"`
+	QCLane           string `json:"qcLane" validate:"omitempty,oneof=ribbon online" example:""` // QC lane only holders may work
+	RequiredPerShift bool   `json:"requiredPerShift" example:"true"`
+}
+
+type UpdateSkillRequest struct {
+	Name             string `json:"name" validate:"required,min=2,max=100" example:"Fragile goods handling"`
+	Description      string `json:"description" example:"Trained to pick and pack glassware and ceramics"`
+	QCLane           string `json:"qcLane" validate:"omitempty,oneof=ribbon online" example:""`
+	RequiredPerShift bool   `json:"requiredPerShift" example:"true"`
+}
+
+type AssignSkillRequest struct {
+	UserIDs   []uint `json:"userIds" validate:"required,min=1" example:"4,7"`
+	ExpiresAt string `json:"expiresAt" example:"2027-06-30"` // YYYY-MM-DD, the certification does not expire when empty
+}
+
+// SkillCoverageShift is a shift at a location with the required skills none of its checked in staff holds
+type SkillCoverageShift struct {
+	Date          string   `json:"date"` // YYYY-MM-DD
+	LocationID    uint     `json:"locationId"`
+	Location      string   `json:"location"`
+	Shift         string   `json:"shift"` // fullday or halfday
+	StaffCount    int      `json:"staffCount"`
+	MissingSkills []string `json:"missingSkills"`
+}
+
+// SkillCoverageResponse is the skill coverage report of a date range
+type SkillCoverageResponse struct {
+	RequiredSkills []string             `json:"requiredSkills"`
+	TotalShifts    int                  `json:"totalShifts"`
+	LackingShifts  int                  `json:"lackingShifts"`
+	Shifts         []SkillCoverageShift `json:"shifts"`
+}
+
+// validateSkillFields trims the skill fields and checks them, returning the error message when invalid
+func validateSkillFields(name, description, qcLane *string) string {
+	*name = strings.TrimSpace(*name)
+	*description = strings.TrimSpace(*description)
+	*qcLane = strings.ToLower(strings.TrimSpace(*qcLane))
+	if len(*name) < 2 || len(*name) > 100 {
+		return "Skill name must be between 2 and 100 characters"
+	}
+	if *qcLane != "" && *qcLane != "ribbon" && *qcLane != "online" {
+		return "Invalid qcLane. Use ribbon or online."
+	}
+	return ""
+}
+
+// GetSkills retrieves a list of skills with how many users hold them
+// @Summary Get Skills
+// @Description Retrieve a list of skills with the number of users holding a valid assignment, with pagination and search
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of skills per page" default(10)
+// @Param search query string false "Search term for skill code or name"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.SkillResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills [get]
+func (sc *SkillController) GetSkills(c fiber.Ctx) error {
+	log.Println("GetSkills called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var skills []models.Skill
+
+	// Build base query
+	query := sc.DB.Model(&models.Skill{}).Order("code ASC")
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("code ILIKE ? OR name ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&skills).Error; err != nil {
+		log.Println("GetSkills - Failed to retrieve skills:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve skills",
+		})
+	}
+
+	// Count the valid holders of the listed skills
+	holderCounts := make(map[uint]int64)
+	if len(skills) > 0 {
+		skillIDs := make([]uint, len(skills))
+		for i, skill := range skills {
+			skillIDs[i] = skill.ID
+		}
+		var rows []struct {
+			SkillID uint
+			Holders int64
+		}
+		if err := sc.DB.Model(&models.UserSkill{}).Select("skill_id, COUNT(*) AS holders").
+			Where("skill_id IN ? AND (expires_at IS NULL OR expires_at > ?)", skillIDs, time.Now()).
+			Group("skill_id").Scan(&rows).Error; err != nil {
+			log.Println("GetSkills - Failed to count skill holders:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to retrieve skills",
+			})
+		}
+		for _, row := range rows {
+			holderCounts[row.SkillID] = row.Holders
+		}
+	}
+
+	// Format response
+	skillList := make([]models.SkillResponse, len(skills))
+	for i := range skills {
+		skillList[i] = *skills[i].ToResponse()
+		skillList[i].HolderCount = holderCounts[skills[i].ID]
+	}
+
+	// Build success message
+	message := "Skills retrieved successfully"
+	if search != "" {
+		message += fmt.Sprintf(" (filtered by search: %s)", search)
+	}
+
+	log.Println("GetSkills completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    skillList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateSkill creates a new skill
+// @Summary Create Skill
+// @Description Create a skill staff can be assigned, e.g. ribbon QC or fragile goods. A skill with a QC lane gates QC on that lane, products can require a skill from their pickers.
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateSkillRequest true "Skill data"
+// @Success 201 {object} utils.SuccessResponse{data=models.SkillResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills [post]
+func (sc *SkillController) CreateSkill(c fiber.Ctx) error {
+	log.Println("CreateSkill called")
+	// Binding request body
+	var req CreateSkillRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateSkill - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Code = strings.ToLower(strings.TrimSpace(req.Code))
+	if !skillCodePattern.MatchString(req.Code) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Skill code must be 2 to 50 lowercase letters, digits or underscores",
+		})
+	}
+	if message := validateSkillFields(&req.Name, &req.Description, &req.QCLane); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Check for existing skill with the same code
+	var existing models.Skill
+	if err := sc.DB.Where("code = ?", req.Code).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Skill " + req.Code + " already exists",
+		})
+	}
+
+	skill := models.Skill{
+		Code:             req.Code,
+		Name:             req.Name,
+		Description:      req.Description,
+		QCLane:           req.QCLane,
+		RequiredPerShift: req.RequiredPerShift,
+	}
+	if err := sc.DB.Create(&skill).Error; err != nil {
+		log.Println("CreateSkill - Failed to create skill:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create skill",
+		})
+	}
+
+	log.Println("CreateSkill completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Skill created successfully",
+		Data:    skill.ToResponse(),
+	})
+}
+
+// UpdateSkill updates a skill
+// @Summary Update Skill
+// @Description Update the name, description, gated QC lane and shift requirement of a skill, its code cannot change
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Skill ID"
+// @Param request body UpdateSkillRequest true "Skill data"
+// @Success 200 {object} utils.SuccessResponse{data=models.SkillResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills/{id} [put]
+func (sc *SkillController) UpdateSkill(c fiber.Ctx) error {
+	log.Println("UpdateSkill called")
+	// Parse id parameter
+	id := c.Params("id")
+	var skill models.Skill
+	if err := sc.DB.Where("id = ?", id).First(&skill).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Skill with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateSkillRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateSkill - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if message := validateSkillFields(&req.Name, &req.Description, &req.QCLane); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	skill.Name = req.Name
+	skill.Description = req.Description
+	skill.QCLane = req.QCLane
+	skill.RequiredPerShift = req.RequiredPerShift
+	if err := sc.DB.Save(&skill).Error; err != nil {
+		log.Println("UpdateSkill - Failed to update skill:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update skill",
+		})
+	}
+
+	log.Println("UpdateSkill completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Skill updated successfully",
+		Data:    skill.ToResponse(),
+	})
+}
+
+// DeleteSkill deletes a skill
+// @Summary Delete Skill
+// @Description Delete a skill with its user assignments, products requiring it no longer require a skill
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Skill ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills/{id} [delete]
+func (sc *SkillController) DeleteSkill(c fiber.Ctx) error {
+	log.Println("DeleteSkill called")
+	// Parse id parameter
+	id := c.Params("id")
+	var skill models.Skill
+	if err := sc.DB.Where("id = ?", id).First(&skill).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Skill with id " + id + " not found.",
+		})
+	}
+
+	if err := sc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Product{}).Where("required_skill_id = ?", skill.ID).Update("required_skill_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("skill_id = ?", skill.ID).Delete(&models.UserSkill{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&skill).Error
+	}); err != nil {
+		log.Println("DeleteSkill - Failed to delete skill:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete skill",
+		})
+	}
+
+	log.Println("DeleteSkill completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Skill " + skill.Code + " deleted successfully",
+	})
+}
+
+// GetSkillHolders retrieves the users a skill is assigned to
+// @Summary Get Skill Holders
+// @Description Retrieve the users a skill is assigned to, including expired assignments
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Skill ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.UserSkillResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills/{id}/users [get]
+func (sc *SkillController) GetSkillHolders(c fiber.Ctx) error {
+	log.Println("GetSkillHolders called")
+	// Parse id parameter
+	id := c.Params("id")
+	var skill models.Skill
+	if err := sc.DB.Where("id = ?", id).First(&skill).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Skill with id " + id + " not found.",
+		})
+	}
+
+	var assignments []models.UserSkill
+	if err := sc.DB.Preload("User").Preload("Skill").Preload("AssignUser").Where("skill_id = ?", skill.ID).Order("created_at ASC").Find(&assignments).Error; err != nil {
+		log.Println("GetSkillHolders - Failed to retrieve skill holders:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve skill holders",
+		})
+	}
+
+	// Format response
+	assignmentList := make([]models.UserSkillResponse, len(assignments))
+	for i := range assignments {
+		assignmentList[i] = *assignments[i].ToResponse()
+	}
+
+	log.Println("GetSkillHolders completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Skill holders retrieved successfully",
+		Data:    assignmentList,
+	})
+}
+
+// AssignSkill assigns a skill to users
+// @Summary Assign Skill
+// @Description Assign a skill to users once they are trained or certified, optionally until the certification expires. Users already holding the skill get the new expiry.
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Skill ID"
+// @Param request body AssignSkillRequest true "Users and expiry"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.UserSkillResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills/{id}/users [post]
+func (sc *SkillController) AssignSkill(c fiber.Ctx) error {
+	log.Println("AssignSkill called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var skill models.Skill
+	if err := sc.DB.Where("id = ?", id).First(&skill).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Skill with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req AssignSkillRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("AssignSkill - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if len(req.UserIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one user is required",
+		})
+	}
+
+	// The certification is valid through the whole expiry day
+	var expiresAt *time.Time
+	if strings.TrimSpace(req.ExpiresAt) != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(req.ExpiresAt), time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid expiresAt format. Use YYYY-MM-DD.",
+			})
+		}
+		parsed = parsed.AddDate(0, 0, 1)
+		if !parsed.After(time.Now()) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "expiresAt must not be in the past",
+			})
+		}
+		expiresAt = &parsed
+	}
+
+	// Every user must exist
+	var users []models.User
+	if err := sc.DB.Where("id IN ?", req.UserIDs).Find(&users).Error; err != nil {
+		log.Println("AssignSkill - Failed to load users:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to assign skill",
+		})
+	}
+	found := make(map[uint]bool, len(users))
+	for _, user := range users {
+		found[user.ID] = true
+	}
+	for _, requested := range req.UserIDs {
+		if !found[requested] {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "User with id " + strconv.FormatUint(uint64(requested), 10) + " does not exist.",
+			})
+		}
+	}
+
+	if err := sc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, user := range users {
+			var assignment models.UserSkill
+			err := tx.Where("user_id = ? AND skill_id = ?", user.ID, skill.ID).First(&assignment).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				assignment = models.UserSkill{UserID: user.ID, SkillID: skill.ID}
+			} else if err != nil {
+				return err
+			}
+			assignment.ExpiresAt = expiresAt
+			assignment.AssignedBy = uint(userID)
+			if err := tx.Save(&assignment).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Println("AssignSkill - Failed to assign skill:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to assign skill",
+		})
+	}
+
+	var assignments []models.UserSkill
+	sc.DB.Preload("User").Preload("Skill").Preload("AssignUser").Where("skill_id = ? AND user_id IN ?", skill.ID, req.UserIDs).Find(&assignments)
+	assignmentList := make([]models.UserSkillResponse, len(assignments))
+	for i := range assignments {
+		assignmentList[i] = *assignments[i].ToResponse()
+	}
+
+	log.Println("AssignSkill completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Skill %s assigned to %d users", skill.Code, len(users)),
+		Data:    assignmentList,
+	})
+}
+
+// RevokeSkill removes a skill from a user
+// @Summary Revoke Skill
+// @Description Remove a skill from a user, they can no longer be assigned tasks requiring it
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Skill ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills/{id}/users/{userId} [delete]
+func (sc *SkillController) RevokeSkill(c fiber.Ctx) error {
+	log.Println("RevokeSkill called")
+	// Parse id parameters
+	id := c.Params("id")
+	targetUserID := c.Params("userId")
+
+	result := sc.DB.Where("skill_id = ? AND user_id = ?", id, targetUserID).Delete(&models.UserSkill{})
+	if result.Error != nil {
+		log.Println("RevokeSkill - Failed to revoke skill:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to revoke skill",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User " + targetUserID + " does not hold skill " + id + ".",
+		})
+	}
+
+	log.Println("RevokeSkill completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Skill revoked successfully",
+	})
+}
+
+// GetUserSkills retrieves the skills assigned to a user
+// @Summary Get User Skills
+// @Description Retrieve the skills assigned to a user, including expired assignments
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId path int true "User ID"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.UserSkillResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills/users/{userId} [get]
+func (sc *SkillController) GetUserSkills(c fiber.Ctx) error {
+	log.Println("GetUserSkills called")
+	// Parse userId parameter
+	targetUserID := c.Params("userId")
+	var user models.User
+	if err := sc.DB.Where("id = ?", targetUserID).First(&user).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User with id " + targetUserID + " not found.",
+		})
+	}
+
+	var assignments []models.UserSkill
+	if err := sc.DB.Preload("User").Preload("Skill").Preload("AssignUser").Where("user_id = ?", user.ID).Order("created_at ASC").Find(&assignments).Error; err != nil {
+		log.Println("GetUserSkills - Failed to retrieve user skills:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve user skills",
+		})
+	}
+
+	// Format response
+	assignmentList := make([]models.UserSkillResponse, len(assignments))
+	for i := range assignments {
+		assignmentList[i] = *assignments[i].ToResponse()
+	}
+
+	log.Println("GetUserSkills completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "User skills retrieved successfully",
+		Data:    assignmentList,
+	})
+}
+
+// GetSkillCoverage reports the shifts lacking required skills
+// @Summary Get Skill Coverage
+// @Description Report the kiosk shifts (fullday, halfday) per day and location where none of the checked in staff holds a required skill, i.e. a skill required per shift or gating a QC lane. Certifications count when valid on the shift day. Covered shifts are only listed with includeCovered.
+// @Tags Skills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD), defaults to today"
+// @Param endDate query string false "End date (YYYY-MM-DD), defaults to the start date"
+// @Param locationId query int false "Filter by location ID"
+// @Param includeCovered query bool false "Also list shifts covering every required skill" default(false)
+// @Success 200 {object} utils.SuccessResponse{data=SkillCoverageResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/skills/coverage [get]
+func (sc *SkillController) GetSkillCoverage(c fiber.Ctx) error {
+	log.Println("GetSkillCoverage called")
+	// Parse date range, today by default
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if startDate := c.Query("startDate", ""); startDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		start = parsed
+	}
+	end := start
+	if endDate := c.Query("endDate", ""); endDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		end = parsed
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "endDate must not be before startDate",
+		})
+	}
+	end = end.AddDate(0, 0, 1)
+	if end.Sub(start) > maxSkillCoverageDays*24*time.Hour {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Date range must not exceed %d days", maxSkillCoverageDays),
+		})
+	}
+	includeCovered := c.Query("includeCovered", "false") == "true"
+
+	// Skills every shift needs, either explicitly or to run a QC lane
+	var required []models.Skill
+	if err := sc.DB.Where("required_per_shift = ? OR qc_lane <> ''", true).Order("code ASC").Find(&required).Error; err != nil {
+		log.Println("GetSkillCoverage - Failed to load required skills:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to build skill coverage",
+		})
+	}
+	response := SkillCoverageResponse{RequiredSkills: make([]string, len(required)), Shifts: []SkillCoverageShift{}}
+	for i, skill := range required {
+		response.RequiredSkills[i] = skill.Name
+	}
+
+	// Checked in staff of the shifts in the range
+	query := sc.DB.Preload("Location").Where("checked_in >= ? AND checked_in < ? AND checked = ? AND status IN ?", start, end, true, skillCoverageShifts)
+	if locationID := c.Query("locationId", ""); locationID != "" {
+		query = query.Where("location_id = ?", locationID)
+	}
+	var attendances []models.Attendance
+	if err := query.Find(&attendances).Error; err != nil {
+		log.Println("GetSkillCoverage - Failed to load attendances:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to build skill coverage",
+		})
+	}
+
+	// Skill assignments of the checked in staff
+	userIDs := make([]uint, 0, len(attendances))
+	for _, attendance := range attendances {
+		userIDs = append(userIDs, attendance.UserID)
+	}
+	holdings := make(map[uint][]models.UserSkill)
+	if len(userIDs) > 0 && len(required) > 0 {
+		var assignments []models.UserSkill
+		if err := sc.DB.Where("user_id IN ?", userIDs).Find(&assignments).Error; err != nil {
+			log.Println("GetSkillCoverage - Failed to load skill assignments:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to build skill coverage",
+			})
+		}
+		for _, assignment := range assignments {
+			holdings[assignment.UserID] = append(holdings[assignment.UserID], assignment)
+		}
+	}
+
+	// Group the staff per day, location and shift, collecting the skills they hold on that day
+	type shiftKey struct {
+		Date       string
+		LocationID uint
+		Shift      string
+	}
+	type shiftStaff struct {
+		Location string
+		Staff    int
+		Covered  map[uint]bool
+	}
+	shifts := make(map[shiftKey]*shiftStaff)
+	for _, attendance := range attendances {
+		key := shiftKey{Date: attendance.CheckedIn.In(time.Local).Format("2006-01-02"), LocationID: attendance.LocationID, Shift: attendance.Status}
+		shift, ok := shifts[key]
+		if !ok {
+			shift = &shiftStaff{Location: attendance.Location.Name, Covered: make(map[uint]bool)}
+			shifts[key] = shift
+		}
+		shift.Staff++
+		for _, assignment := range holdings[attendance.UserID] {
+			if assignment.ValidAt(attendance.CheckedIn) {
+				shift.Covered[assignment.SkillID] = true
+			}
+		}
+	}
+
+	for key, shift := range shifts {
+		response.TotalShifts++
+		missing := []string{}
+		for _, skill := range required {
+			if !shift.Covered[skill.ID] {
+				missing = append(missing, skill.Name)
+			}
+		}
+		if len(missing) > 0 {
+			response.LackingShifts++
+		} else if !includeCovered {
+			continue
+		}
+		response.Shifts = append(response.Shifts, SkillCoverageShift{
+			Date:          key.Date,
+			LocationID:    key.LocationID,
+			Location:      shift.Location,
+			Shift:         key.Shift,
+			StaffCount:    shift.Staff,
+			MissingSkills: missing,
+		})
+	}
+	sort.Slice(response.Shifts, func(i, j int) bool {
+		a, b := response.Shifts[i], response.Shifts[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		return a.Shift < b.Shift
+	})
+
+	log.Println("GetSkillCoverage completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Skill coverage retrieved successfully, %d of %d shifts lack required skills", response.LackingShifts, response.TotalShifts),
+		Data:    response,
+	})
+}
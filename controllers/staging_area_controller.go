@@ -0,0 +1,908 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StagingAreaController struct {
+	DB *gorm.DB
+}
+
+func NewStagingAreaController(db *gorm.DB) *StagingAreaController {
+	return &StagingAreaController{DB: db}
+}
+
+// Request structs
+type CreateStagingAreaRequest struct {
+	Code         string `json:"code" validate:"required,min=1,max=20" example:"LANE-01"`
+	Name         string `json:"name" validate:"required,max=100" example:"QC outbound lane 1"`
+	AreaType     string `json:"areaType" validate:"required,oneof=qc_outbound_lane courier_staging" example:"qc_outbound_lane"`
+	ExpeditionID *uint  `json:"expeditionId" example:"1"`
+	Capacity     int    `json:"capacity" validate:"required,min=1" example:"50"`
+}
+
+type UpdateStagingAreaRequest struct {
+	Code         string `json:"code" validate:"required,min=1,max=20" example:"LANE-01"`
+	Name         string `json:"name" validate:"required,max=100" example:"QC outbound lane 1"`
+	AreaType     string `json:"areaType" validate:"required,oneof=qc_outbound_lane courier_staging" example:"qc_outbound_lane"`
+	ExpeditionID *uint  `json:"expeditionId" example:"1"`
+	Capacity     int    `json:"capacity" validate:"required,min=1" example:"50"`
+	IsActive     bool   `json:"isActive" example:"true"`
+}
+
+type PlaceStagingParcelRequest struct {
+	TrackingNumber string `json:"trackingNumber" validate:"required,min=4,max=100" example:"JX1234567890"`
+}
+
+type RemoveStagingParcelRequest struct {
+	TrackingNumber string `json:"trackingNumber" validate:"required,min=4,max=100" example:"JX1234567890"`
+}
+
+// StagingAreaDetailResponse is a staging area with the parcels currently in it, oldest first
+type StagingAreaDetailResponse struct {
+	models.StagingAreaResponse
+	Parcels []models.StagingPlacementResponse `json:"parcels"`
+}
+
+// StagingLookupResponse is where a parcel is staged, with every area it passed through
+type StagingLookupResponse struct {
+	TrackingNumber   string                            `json:"trackingNumber"`
+	ProcessingStatus string                            `json:"processingStatus"`
+	EventStatus      string                            `json:"eventStatus"`
+	Current          *models.StagingPlacementResponse  `json:"current"` // nil when the parcel is not in any staging area
+	History          []models.StagingPlacementResponse `json:"history"`
+}
+
+// StagingAreaOccupancy is the fill level of a staging area on the occupancy dashboard
+type StagingAreaOccupancy struct {
+	models.StagingAreaResponse
+	Available          int     `json:"available"`
+	UtilizationPercent float64 `json:"utilizationPercent"`
+	OldestPlacedAt     *string `json:"oldestPlacedAt"`
+	OldestAgeMinutes   int     `json:"oldestAgeMinutes"`
+}
+
+// StagingOccupancyResponse is the occupancy dashboard of every active staging area
+type StagingOccupancyResponse struct {
+	Areas          []StagingAreaOccupancy `json:"areas"`
+	TotalCapacity  int                    `json:"totalCapacity"`
+	TotalOccupied  int                    `json:"totalOccupied"`
+	UnstagedPacked int                    `json:"unstagedPacked"` // QC completed parcels not in any staging area
+	CanceledStaged []string               `json:"canceledStaged"` // tracking numbers of canceled orders still in a staging area, to be pulled
+}
+
+// validateStagingAreaRequest normalizes the staging area fields and checks the code is not taken
+func (sac *StagingAreaController) validateStagingAreaRequest(code, name, areaType *string, expeditionID *uint, capacity int, excludeID uint) error {
+	*code = strings.ToUpper(strings.TrimSpace(*code))
+	*name = strings.TrimSpace(*name)
+	*areaType = strings.TrimSpace(*areaType)
+	if *code == "" || len(*code) > 20 {
+		return fiber.NewError(fiber.StatusBadRequest, "Code is required and must be at most 20 characters")
+	}
+	if *name == "" || len(*name) > 100 {
+		return fiber.NewError(fiber.StatusBadRequest, "Name is required and must be at most 100 characters")
+	}
+	if !slices.Contains(models.StagingAreaTypes, *areaType) {
+		return fiber.NewError(fiber.StatusBadRequest, "Area type must be one of: "+strings.Join(models.StagingAreaTypes, ", "))
+	}
+	if capacity < 1 {
+		return fiber.NewError(fiber.StatusBadRequest, "Capacity must be at least 1")
+	}
+
+	// Only courier bins are dedicated to an expedition, QC outbound lanes take every parcel
+	if expeditionID != nil {
+		if *areaType != models.StagingAreaCourierStaging {
+			return fiber.NewError(fiber.StatusBadRequest, "Only courier staging bins can be dedicated to an expedition")
+		}
+		var expedition models.Expedition
+		if err := sac.DB.Where("id = ?", *expeditionID).First(&expedition).Error; err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Expedition with id %d not found.", *expeditionID))
+		}
+	}
+
+	var count int64
+	if err := sac.DB.Model(&models.StagingArea{}).Where("code = ? AND id != ?", *code, excludeID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fiber.NewError(fiber.StatusConflict, "Staging area with code "+*code+" already exists.")
+	}
+	return nil
+}
+
+// stagingErrorResponse writes the response of a staging area error
+func stagingErrorResponse(c fiber.Ctx, err error, fallback string) error {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return c.Status(fiberErr.Code).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fiberErr.Message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   fallback,
+	})
+}
+
+// loadStagingParcels returns the parcels currently in a staging area, oldest first
+func (sac *StagingAreaController) loadStagingParcels(areaID uint) ([]models.StagingPlacementResponse, error) {
+	var placements []models.StagingPlacement
+	if err := sac.DB.Preload("StagingArea").Preload("PlaceUser").
+		Where("staging_area_id = ? AND removed_at IS NULL", areaID).
+		Order("created_at ASC").
+		Find(&placements).Error; err != nil {
+		return nil, err
+	}
+
+	parcels := make([]models.StagingPlacementResponse, len(placements))
+	for i, placement := range placements {
+		parcels[i] = *placement.ToResponse()
+	}
+	return parcels, nil
+}
+
+// GetStagingAreas retrieves the staging areas
+// @Summary Get Staging Areas
+// @Description Retrieve the QC outbound lanes and courier staging bins with the number of parcels in each
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param areaType query string false "Filter by area type (qc_outbound_lane, courier_staging)"
+// @Param search query string false "Search term for code or name"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.StagingAreaResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas [get]
+func (sac *StagingAreaController) GetStagingAreas(c fiber.Ctx) error {
+	log.Println("GetStagingAreas called")
+	var areas []models.StagingArea
+
+	// Build base query
+	query := sac.DB.Model(&models.StagingArea{}).Preload("Expedition").Order("area_type ASC, code ASC")
+
+	// Area type filter if provided
+	areaType := strings.TrimSpace(c.Query("areaType", ""))
+	if areaType != "" {
+		query = query.Where("area_type = ?", areaType)
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("code ILIKE ? OR name ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Find(&areas).Error; err != nil {
+		log.Println("GetStagingAreas - Failed to retrieve staging areas:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve staging areas",
+		})
+	}
+
+	occupancy, err := utils.CountStagingOccupancy(sac.DB)
+	if err != nil {
+		log.Println("GetStagingAreas - Failed to count staging occupancy:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve staging areas",
+		})
+	}
+
+	// Format response
+	areaList := make([]models.StagingAreaResponse, len(areas))
+	for i, area := range areas {
+		areaList[i] = *area.ToResponse(occupancy[area.ID])
+	}
+
+	// Build success message
+	message := "Staging areas retrieved successfully"
+	var filters []string
+	if areaType != "" {
+		filters = append(filters, "areaType: "+areaType)
+	}
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetStagingAreas completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    areaList,
+	})
+}
+
+// GetStagingArea retrieves a single staging area by ID with the parcels in it
+// @Summary Get Staging Area
+// @Description Retrieve a single staging area by ID with the parcels currently in it, oldest first
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Staging Area ID"
+// @Success 200 {object} utils.SuccessResponse{data=StagingAreaDetailResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas/{id} [get]
+func (sac *StagingAreaController) GetStagingArea(c fiber.Ctx) error {
+	log.Println("GetStagingArea called")
+	// Parse id parameter
+	id := c.Params("id")
+	var area models.StagingArea
+	if err := sac.DB.Preload("Expedition").Where("id = ?", id).First(&area).Error; err != nil {
+		log.Println("GetStagingArea - Staging area not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Staging area with id " + id + " not found.",
+		})
+	}
+
+	parcels, err := sac.loadStagingParcels(area.ID)
+	if err != nil {
+		log.Println("GetStagingArea - Failed to retrieve staged parcels:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve staged parcels",
+		})
+	}
+
+	log.Println("GetStagingArea completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Staging area retrieved successfully",
+		Data: StagingAreaDetailResponse{
+			StagingAreaResponse: *area.ToResponse(len(parcels)),
+			Parcels:             parcels,
+		},
+	})
+}
+
+// CreateStagingArea creates a new staging area
+// @Summary Create Staging Area
+// @Description Create a new QC outbound lane or courier staging bin holding at most capacity parcels. A courier bin can be dedicated to one expedition
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateStagingAreaRequest true "Staging area details"
+// @Success 201 {object} utils.SuccessResponse{data=models.StagingAreaResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas [post]
+func (sac *StagingAreaController) CreateStagingArea(c fiber.Ctx) error {
+	log.Println("CreateStagingArea called")
+	// Binding request body
+	var req CreateStagingAreaRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateStagingArea - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := sac.validateStagingAreaRequest(&req.Code, &req.Name, &req.AreaType, req.ExpeditionID, req.Capacity, 0); err != nil {
+		log.Println("CreateStagingArea - Invalid staging area:", err)
+		return stagingErrorResponse(c, err, "Failed to validate staging area")
+	}
+
+	area := models.StagingArea{
+		Code:         req.Code,
+		Name:         req.Name,
+		AreaType:     req.AreaType,
+		ExpeditionID: req.ExpeditionID,
+		Capacity:     req.Capacity,
+		IsActive:     true,
+	}
+	if err := sac.DB.Create(&area).Error; err != nil {
+		log.Println("CreateStagingArea - Failed to create staging area:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create staging area",
+		})
+	}
+	sac.DB.Preload("Expedition").Where("id = ?", area.ID).First(&area)
+
+	log.Println("CreateStagingArea completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Staging area created successfully",
+		Data:    area.ToResponse(0),
+	})
+}
+
+// UpdateStagingArea updates an existing staging area by ID
+// @Summary Update Staging Area
+// @Description Update an existing staging area by ID. The capacity cannot drop below the parcels in it, and an area holding parcels keeps its type
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Staging Area ID"
+// @Param request body UpdateStagingAreaRequest true "Updated staging area details"
+// @Success 200 {object} utils.SuccessResponse{data=models.StagingAreaResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas/{id} [put]
+func (sac *StagingAreaController) UpdateStagingArea(c fiber.Ctx) error {
+	log.Println("UpdateStagingArea called")
+	// Parse id parameter
+	id := c.Params("id")
+	var area models.StagingArea
+	if err := sac.DB.Where("id = ?", id).First(&area).Error; err != nil {
+		log.Println("UpdateStagingArea - Staging area not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Staging area with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateStagingAreaRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateStagingArea - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := sac.validateStagingAreaRequest(&req.Code, &req.Name, &req.AreaType, req.ExpeditionID, req.Capacity, area.ID); err != nil {
+		log.Println("UpdateStagingArea - Invalid staging area:", err)
+		return stagingErrorResponse(c, err, "Failed to validate staging area")
+	}
+
+	var occupied int64
+	if err := sac.DB.Model(&models.StagingPlacement{}).Where("staging_area_id = ? AND removed_at IS NULL", area.ID).Count(&occupied).Error; err != nil {
+		log.Println("UpdateStagingArea - Failed to count staged parcels:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update staging area",
+		})
+	}
+	if int64(req.Capacity) < occupied {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Staging area %s holds %d parcels, the capacity cannot be lower", area.Code, occupied),
+		})
+	}
+	if occupied > 0 && req.AreaType != area.AreaType {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Staging area " + area.Code + " still holds parcels, empty it before changing its type",
+		})
+	}
+
+	area.Code = req.Code
+	area.Name = req.Name
+	area.AreaType = req.AreaType
+	area.ExpeditionID = req.ExpeditionID
+	area.Capacity = req.Capacity
+	area.IsActive = req.IsActive
+	area.Expedition = nil
+	if err := sac.DB.Save(&area).Error; err != nil {
+		log.Println("UpdateStagingArea - Failed to update staging area:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update staging area",
+		})
+	}
+	sac.DB.Preload("Expedition").Where("id = ?", area.ID).First(&area)
+
+	log.Println("UpdateStagingArea completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Staging area updated successfully",
+		Data:    area.ToResponse(int(occupied)),
+	})
+}
+
+// DeleteStagingArea deletes an empty staging area by ID
+// @Summary Delete Staging Area
+// @Description Delete a staging area by ID. Areas still holding parcels cannot be deleted, deactivate them instead
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Staging Area ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas/{id} [delete]
+func (sac *StagingAreaController) DeleteStagingArea(c fiber.Ctx) error {
+	log.Println("DeleteStagingArea called")
+	// Parse id parameter
+	id := c.Params("id")
+	var area models.StagingArea
+	if err := sac.DB.Where("id = ?", id).First(&area).Error; err != nil {
+		log.Println("DeleteStagingArea - Staging area not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Staging area with id " + id + " not found.",
+		})
+	}
+
+	var occupied int64
+	if err := sac.DB.Model(&models.StagingPlacement{}).Where("staging_area_id = ? AND removed_at IS NULL", area.ID).Count(&occupied).Error; err != nil {
+		log.Println("DeleteStagingArea - Failed to count staged parcels:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete staging area",
+		})
+	}
+	if occupied > 0 {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Staging area %s still holds %d parcels", area.Code, occupied),
+		})
+	}
+
+	// Past placements are removed with the area, they no longer point to a physical location
+	err := sac.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("staging_area_id = ?", area.ID).Delete(&models.StagingPlacement{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&area).Error
+	})
+	if err != nil {
+		log.Println("DeleteStagingArea - Failed to delete staging area:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete staging area",
+		})
+	}
+
+	log.Println("DeleteStagingArea completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Staging area deleted successfully",
+	})
+}
+
+// PlaceStagingParcel moves a packed parcel into a staging area
+// @Summary Place Parcel In Staging Area
+// @Description Move a packed parcel into a staging area, taking it out of the area it was in. QC outbound lanes take QC completed parcels, courier staging bins also take outbound scanned parcels not yet handed over, of the bin's expedition when it has one
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Staging Area ID"
+// @Param request body PlaceStagingParcelRequest true "Parcel to place"
+// @Success 200 {object} utils.SuccessResponse{data=models.StagingPlacementResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas/{id}/place [post]
+func (sac *StagingAreaController) PlaceStagingParcel(c fiber.Ctx) error {
+	log.Println("PlaceStagingParcel called")
+	// Parse id parameter
+	id := c.Params("id")
+	var area models.StagingArea
+	if err := sac.DB.Preload("Expedition").Where("id = ?", id).First(&area).Error; err != nil {
+		log.Println("PlaceStagingParcel - Staging area not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Staging area with id " + id + " not found.",
+		})
+	}
+	if !area.IsActive {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Staging area " + area.Code + " is inactive",
+		})
+	}
+
+	// Binding request body
+	var req PlaceStagingParcelRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("PlaceStagingParcel - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Convert tracking number to uppercase and trim spaces
+	trackingNumber := models.NormalizeIdentifier(req.TrackingNumber)
+	if trackingNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Tracking number is required",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var order models.Order
+	if err := sac.DB.Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		log.Println("PlaceStagingParcel - Order not found:", trackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + trackingNumber,
+		})
+	}
+	if order.EventStatus == models.EventStatusCanceled {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order with tracking number " + trackingNumber + " has been canceled.",
+		})
+	}
+
+	// Only packed parcels are staged, outbound scanned ones only in courier bins until they are handed over
+	var outbound models.Outbound
+	switch {
+	case order.ProcessingStatus == models.ProcessingStatusQCCompleted:
+	case order.ProcessingStatus == models.ProcessingStatusOutboundCompleted && area.AreaType == models.StagingAreaCourierStaging:
+		if err := sac.DB.Where("tracking_number = ?", trackingNumber).First(&outbound).Error; err == nil {
+			var handedOver int64
+			sac.DB.Model(&models.HandoverSessionItem{}).Where("outbound_id = ?", outbound.ID).Count(&handedOver)
+			if handedOver > 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Parcel " + trackingNumber + " has already been handed over to the courier",
+				})
+			}
+		}
+	default:
+		log.Println("PlaceStagingParcel - Parcel not packed:", trackingNumber, order.ProcessingStatus)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Parcel %s cannot be placed in a %s area with processing status %s", trackingNumber, area.AreaType, order.ProcessingStatus),
+		})
+	}
+
+	// A dedicated courier bin only takes parcels of its expedition
+	if area.ExpeditionID != nil && area.Expedition != nil {
+		matches := false
+		if outbound.ID != 0 && outbound.ExpeditionSlug != "" {
+			matches = outbound.ExpeditionSlug == area.Expedition.ExpeditionSlug
+		} else if expedition, err := utils.FindOrderExpedition(sac.DB, &order); err != nil {
+			log.Println("PlaceStagingParcel - Failed to resolve order expedition:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to resolve the expedition of the parcel",
+			})
+		} else if expedition != nil {
+			matches = expedition.ID == area.Expedition.ID
+		}
+		if !matches {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Parcel %s does not belong to %s, staging bin %s only takes %s parcels", trackingNumber, area.Expedition.ExpeditionName, area.Code, area.Expedition.ExpeditionName),
+			})
+		}
+	}
+
+	var placement models.StagingPlacement
+	err = sac.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock the area so concurrent placements cannot overfill it
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", area.ID).First(&models.StagingArea{}).Error; err != nil {
+			return err
+		}
+
+		var current models.StagingPlacement
+		if err := tx.Where("tracking_number = ? AND removed_at IS NULL", trackingNumber).First(&current).Error; err == nil {
+			if current.StagingAreaID == area.ID {
+				return fiber.NewError(fiber.StatusConflict, "Parcel "+trackingNumber+" is already in staging area "+area.Code)
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var occupied int64
+		if err := tx.Model(&models.StagingPlacement{}).Where("staging_area_id = ? AND removed_at IS NULL", area.ID).Count(&occupied).Error; err != nil {
+			return err
+		}
+		if occupied >= int64(area.Capacity) {
+			return fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Staging area %s is full (%d/%d)", area.Code, occupied, area.Capacity))
+		}
+
+		if err := utils.ReleaseStagingPlacement(tx, trackingNumber, models.StagingRemovalMoved, uint(userID)); err != nil {
+			return err
+		}
+		placement = models.StagingPlacement{
+			StagingAreaID:  area.ID,
+			OrderID:        order.ID,
+			TrackingNumber: trackingNumber,
+			PlacedBy:       uint(userID),
+		}
+		return tx.Create(&placement).Error
+	})
+	if err != nil {
+		log.Println("PlaceStagingParcel - Failed to place parcel:", err)
+		return stagingErrorResponse(c, err, "Failed to place parcel in staging area")
+	}
+
+	sac.DB.Preload("StagingArea").Preload("PlaceUser").Where("id = ?", placement.ID).First(&placement)
+
+	log.Println("PlaceStagingParcel completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Parcel " + trackingNumber + " placed in staging area " + area.Code + " successfully",
+		Data:    placement.ToResponse(),
+	})
+}
+
+// RemoveStagingParcel takes a parcel out of its staging area by hand
+// @Summary Remove Parcel From Staging Area
+// @Description Take a parcel out of the staging area it is in, e.g. a canceled order pulled from the lane. Outbound and handover scans take parcels out on their own
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RemoveStagingParcelRequest true "Parcel to remove"
+// @Success 200 {object} utils.SuccessResponse{data=models.StagingPlacementResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas/remove [post]
+func (sac *StagingAreaController) RemoveStagingParcel(c fiber.Ctx) error {
+	log.Println("RemoveStagingParcel called")
+	// Binding request body
+	var req RemoveStagingParcelRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("RemoveStagingParcel - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Convert tracking number to uppercase and trim spaces
+	trackingNumber := models.NormalizeIdentifier(req.TrackingNumber)
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var placement models.StagingPlacement
+	if err := sac.DB.Where("tracking_number = ? AND removed_at IS NULL", trackingNumber).First(&placement).Error; err != nil {
+		log.Println("RemoveStagingParcel - Parcel not staged:", trackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Parcel " + trackingNumber + " is not in any staging area",
+		})
+	}
+
+	if err := utils.ReleaseStagingPlacement(sac.DB, trackingNumber, models.StagingRemovalManual, uint(userID)); err != nil {
+		log.Println("RemoveStagingParcel - Failed to remove parcel:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to remove parcel from staging area",
+		})
+	}
+
+	sac.DB.Preload("StagingArea").Preload("PlaceUser").Preload("RemoveUser").Where("id = ?", placement.ID).First(&placement)
+
+	log.Println("RemoveStagingParcel completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Parcel " + trackingNumber + " removed from staging area successfully",
+		Data:    placement.ToResponse(),
+	})
+}
+
+// LookupStagingParcel finds the staging area a parcel is in
+// @Summary Lookup Staged Parcel
+// @Description Find the staging area a parcel is in by tracking number, with every staging area it passed through
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param trackingNumber path string true "Tracking Number"
+// @Success 200 {object} utils.SuccessResponse{data=StagingLookupResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas/lookup/{trackingNumber} [get]
+func (sac *StagingAreaController) LookupStagingParcel(c fiber.Ctx) error {
+	log.Println("LookupStagingParcel called")
+	trackingNumber := models.NormalizeIdentifier(c.Params("trackingNumber"))
+
+	var order models.Order
+	if err := sac.DB.Where("tracking_number = ?", trackingNumber).First(&order).Error; err != nil {
+		log.Println("LookupStagingParcel - Order not found:", trackingNumber)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No order found with tracking number " + trackingNumber,
+		})
+	}
+
+	var placements []models.StagingPlacement
+	if err := sac.DB.Preload("StagingArea").Preload("PlaceUser").Preload("RemoveUser").
+		Where("tracking_number = ?", trackingNumber).
+		Order("created_at DESC").
+		Find(&placements).Error; err != nil {
+		log.Println("LookupStagingParcel - Failed to retrieve staging placements:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve staging placements",
+		})
+	}
+
+	response := StagingLookupResponse{
+		TrackingNumber:   trackingNumber,
+		ProcessingStatus: order.ProcessingStatus,
+		EventStatus:      order.EventStatus,
+		History:          make([]models.StagingPlacementResponse, len(placements)),
+	}
+	for i, placement := range placements {
+		response.History[i] = *placement.ToResponse()
+		if placement.RemovedAt == nil {
+			response.Current = &response.History[i]
+		}
+	}
+
+	message := "Parcel " + trackingNumber + " is not in any staging area"
+	if response.Current != nil {
+		message = "Parcel " + trackingNumber + " is in staging area " + response.Current.StagingAreaCode
+	}
+
+	log.Println("LookupStagingParcel completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    response,
+	})
+}
+
+// GetStagingOccupancy retrieves the occupancy dashboard of the staging areas
+// @Summary Get Staging Occupancy
+// @Description Retrieve the fill level of every active staging area with the age of its oldest parcel, the QC completed parcels not staged anywhere and the canceled parcels still staged
+// @Tags Staging Areas
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param areaType query string false "Filter by area type (qc_outbound_lane, courier_staging)"
+// @Success 200 {object} utils.SuccessResponse{data=StagingOccupancyResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/staging-areas/occupancy [get]
+func (sac *StagingAreaController) GetStagingOccupancy(c fiber.Ctx) error {
+	log.Println("GetStagingOccupancy called")
+	var areas []models.StagingArea
+
+	// Build base query
+	query := sac.DB.Model(&models.StagingArea{}).Preload("Expedition").Where("is_active = ?", true).Order("area_type ASC, code ASC")
+
+	// Area type filter if provided
+	areaType := strings.TrimSpace(c.Query("areaType", ""))
+	if areaType != "" {
+		query = query.Where("area_type = ?", areaType)
+	}
+
+	if err := query.Find(&areas).Error; err != nil {
+		log.Println("GetStagingOccupancy - Failed to retrieve staging areas:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve staging occupancy",
+		})
+	}
+
+	// Parcels in each area with the placement time of the oldest one
+	var rows []struct {
+		StagingAreaID uint
+		Occupied      int
+		OldestAt      *time.Time
+	}
+	if err := sac.DB.Model(&models.StagingPlacement{}).
+		Select("staging_area_id, COUNT(*) AS occupied, MIN(created_at) AS oldest_at").
+		Where("removed_at IS NULL").
+		Group("staging_area_id").
+		Scan(&rows).Error; err != nil {
+		log.Println("GetStagingOccupancy - Failed to count staging occupancy:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve staging occupancy",
+		})
+	}
+	occupancy := make(map[uint]int, len(rows))
+	oldest := make(map[uint]time.Time, len(rows))
+	for _, row := range rows {
+		occupancy[row.StagingAreaID] = row.Occupied
+		if row.OldestAt != nil {
+			oldest[row.StagingAreaID] = *row.OldestAt
+		}
+	}
+
+	now := time.Now()
+	response := StagingOccupancyResponse{
+		Areas:          make([]StagingAreaOccupancy, len(areas)),
+		CanceledStaged: []string{},
+	}
+	for i, area := range areas {
+		occupied := occupancy[area.ID]
+		entry := StagingAreaOccupancy{
+			StagingAreaResponse: *area.ToResponse(occupied),
+			Available:           max(area.Capacity-occupied, 0),
+		}
+		if area.Capacity > 0 {
+			entry.UtilizationPercent = math.Round(float64(occupied)/float64(area.Capacity)*10000) / 100
+		}
+		if oldestAt, ok := oldest[area.ID]; ok {
+			formatted := oldestAt.Format("02-01-2006 15:04:05")
+			entry.OldestPlacedAt = &formatted
+			entry.OldestAgeMinutes = int(now.Sub(oldestAt).Minutes())
+		}
+		response.Areas[i] = entry
+		response.TotalCapacity += area.Capacity
+		response.TotalOccupied += occupied
+	}
+
+	// Packed parcels not staged anywhere are the ones at risk of getting lost
+	var unstaged int64
+	if err := sac.DB.Model(&models.Order{}).
+		Where("processing_status = ? AND event_status != ?", models.ProcessingStatusQCCompleted, models.EventStatusCanceled).
+		Where("NOT EXISTS (SELECT 1 FROM staging_placements sp WHERE sp.tracking_number = orders.tracking_number AND sp.removed_at IS NULL)").
+		Count(&unstaged).Error; err != nil {
+		log.Println("GetStagingOccupancy - Failed to count unstaged parcels:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve staging occupancy",
+		})
+	}
+	response.UnstagedPacked = int(unstaged)
+
+	// Canceled orders still staged must be pulled before they are shipped by mistake
+	if err := sac.DB.Model(&models.StagingPlacement{}).
+		Joins("JOIN orders ON orders.id = staging_placements.order_id").
+		Where("staging_placements.removed_at IS NULL AND orders.event_status = ?", models.EventStatusCanceled).
+		Order("staging_placements.created_at ASC").
+		Pluck("staging_placements.tracking_number", &response.CanceledStaged).Error; err != nil {
+		log.Println("GetStagingOccupancy - Failed to retrieve canceled staged parcels:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve staging occupancy",
+		})
+	}
+
+	log.Println("GetStagingOccupancy completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Staging occupancy retrieved successfully",
+		Data:    response,
+	})
+}
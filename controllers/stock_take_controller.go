@@ -0,0 +1,622 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type StockTakeController struct {
+	DB *gorm.DB
+}
+
+func NewStockTakeController(db *gorm.DB) *StockTakeController {
+	return &StockTakeController{DB: db}
+}
+
+// Request structs
+type CreateStockTakeRequest struct {
+	Scope     string   `json:"scope" validate:"required"` // rack or sku
+	Locations []string `json:"locations"`                 // racks, required for rack scope
+	SKUs      []string `json:"skus"`                      // SKUs, required for sku scope
+	Notes     string   `json:"notes"`
+}
+
+type CountStockTakeItemRequest struct {
+	SKU      string `json:"sku" validate:"required"`
+	Quantity int    `json:"quantity"` // defaults to 1 when omitted
+	Mode     string `json:"mode"`     // add (default, one scan adds to the count) or set (overwrite the count)
+}
+
+// loadStockTake loads a stock take session with all relationships needed for the response
+func (stc *StockTakeController) loadStockTake(id interface{}) (*models.StockTake, error) {
+	var stockTake models.StockTake
+	if err := stc.DB.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("location ASC, sku ASC")
+	}).Preload("Items.CountUser").Preload("CreateUser").Preload("ApproveUser").Preload("CancelUser").Where("id = ?", id).First(&stockTake).Error; err != nil {
+		return nil, err
+	}
+	return &stockTake, nil
+}
+
+// GetStockTakes retrieves a list of stock take sessions with pagination and filters
+// @Summary Get Stock Takes
+// @Description Retrieve a list of stock take (cycle counting) sessions with pagination, status filter and search
+// @Tags Stock Takes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of stock takes per page" default(10)
+// @Param status query string false "Filter by status (counting, approved, canceled)"
+// @Param search query string false "Search term for code or scope value"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.StockTakeResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stock-takes [get]
+func (stc *StockTakeController) GetStockTakes(c fiber.Ctx) error {
+	log.Println("GetStockTakes called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var stockTakes []models.StockTake
+
+	// Build base query
+	query := stc.DB.Model(&models.StockTake{}).Preload("Items").Preload("CreateUser").Preload("ApproveUser").Preload("CancelUser").Order("created_at DESC")
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("code ILIKE ? OR scope_value ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&stockTakes).Error; err != nil {
+		log.Println("GetStockTakes - Failed to retrieve stock takes:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve stock takes",
+		})
+	}
+
+	// Format response without items, only the summary
+	stockTakeList := make([]models.StockTakeResponse, len(stockTakes))
+	for i, stockTake := range stockTakes {
+		stockTakeList[i] = *stockTake.ToResponse()
+		stockTakeList[i].Items = nil
+	}
+
+	// Build success message
+	message := "Stock takes retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetStockTakes completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    stockTakeList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetStockTake retrieves a single stock take session by ID
+// @Summary Get Stock Take
+// @Description Retrieve a stock take session with its items and variances against system stock
+// @Tags Stock Takes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock Take ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.StockTakeResponse}
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/stock-takes/{id} [get]
+func (stc *StockTakeController) GetStockTake(c fiber.Ctx) error {
+	log.Println("GetStockTake called")
+	// Parse id parameter
+	id := c.Params("id")
+	stockTake, err := stc.loadStockTake(id)
+	if err != nil {
+		log.Println("GetStockTake - Stock take not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Stock take with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetStockTake completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Stock take retrieved successfully",
+		Data:    stockTake.ToResponse(),
+	})
+}
+
+// CreateStockTake creates a new counting session and snapshots the system stock of every SKU in scope
+// @Summary Create Stock Take
+// @Description Create a stock take session scoped to racks (product locations) or SKUs
+// @Tags Stock Takes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param stockTake body CreateStockTakeRequest true "Stock take scope"
+// @Success 201 {object} utils.SuccessResponse{data=models.StockTakeResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stock-takes [post]
+func (stc *StockTakeController) CreateStockTake(c fiber.Ctx) error {
+	log.Println("CreateStockTake called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CreateStockTake - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req CreateStockTakeRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateStockTake - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Resolve the products in scope
+	var scopeValues []string
+	query := stc.DB.Model(&models.Product{}).Order("location ASC, sku ASC")
+	switch req.Scope {
+	case "rack":
+		scopeValues = normalizeScopeValues(req.Locations)
+		query = query.Where("location IN ?", scopeValues)
+	case "sku":
+		scopeValues = normalizeScopeValues(req.SKUs)
+		query = query.Where("sku IN ?", scopeValues)
+	default:
+		log.Println("CreateStockTake - Invalid scope:", req.Scope)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Scope must be rack or sku",
+		})
+	}
+
+	if len(scopeValues) == 0 {
+		log.Println("CreateStockTake - Empty scope")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "At least one location or SKU is required for the selected scope",
+		})
+	}
+
+	var products []models.Product
+	if err := query.Find(&products).Error; err != nil {
+		log.Println("CreateStockTake - Failed to load products:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load products",
+		})
+	}
+
+	if len(products) == 0 {
+		log.Println("CreateStockTake - No products in scope")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "No products found for the selected scope",
+		})
+	}
+
+	stockTake := models.StockTake{
+		Scope:      req.Scope,
+		ScopeValue: strings.Join(scopeValues, ","),
+		Status:     "counting",
+		Notes:      strings.TrimSpace(req.Notes),
+		CreatedBy:  uint(userID),
+	}
+
+	if err := stc.DB.Transaction(func(tx *gorm.DB) error {
+		stockTake.Code = utils.GenerateStockTakeCode(tx)
+		if err := tx.Create(&stockTake).Error; err != nil {
+			return err
+		}
+
+		// Snapshot system stock of every product in scope
+		items := make([]models.StockTakeItem, len(products))
+		for i, product := range products {
+			items[i] = models.StockTakeItem{
+				StockTakeID:    stockTake.ID,
+				SKU:            product.SKU,
+				ProductName:    product.Name,
+				Location:       product.Location,
+				SystemQuantity: utils.GetInventoryQuantity(tx, product.SKU),
+			}
+		}
+		return tx.Create(&items).Error
+	}); err != nil {
+		log.Println("CreateStockTake - Failed to create stock take:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create stock take: " + err.Error(),
+		})
+	}
+
+	created, err := stc.loadStockTake(stockTake.ID)
+	if err != nil {
+		log.Println("CreateStockTake - Failed to reload stock take:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload stock take",
+		})
+	}
+
+	log.Println("CreateStockTake completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Stock take %s created with %d items", created.Code, len(created.Items)),
+		Data:    created.ToResponse(),
+	})
+}
+
+// CountStockTakeItem records a counted quantity for a SKU in a stock take session
+// @Summary Count Stock Take Item
+// @Description Submit a counted quantity for a SKU. Each scan adds to the count by default; mode "set" overwrites it. SKUs outside the session scope are added as unexpected items.
+// @Tags Stock Takes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock Take ID"
+// @Param count body CountStockTakeItemRequest true "Counted SKU and quantity"
+// @Success 200 {object} utils.SuccessResponse{data=models.StockTakeItemResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stock-takes/{id}/count [post]
+func (stc *StockTakeController) CountStockTakeItem(c fiber.Ctx) error {
+	log.Println("CountStockTakeItem called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CountStockTakeItem - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse request body
+	var req CountStockTakeItemRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CountStockTakeItem - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	if req.SKU == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU is required",
+		})
+	}
+	if req.Mode == "" {
+		req.Mode = "add"
+	}
+	if req.Mode != "add" && req.Mode != "set" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Mode must be add or set",
+		})
+	}
+	if req.Mode == "add" && req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Quantity cannot be negative",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var stockTake models.StockTake
+	if err := stc.DB.Where("id = ?", id).First(&stockTake).Error; err != nil {
+		log.Println("CountStockTakeItem - Stock take not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Stock take with id " + id + " not found.",
+		})
+	}
+
+	if stockTake.Status != "counting" {
+		log.Println("CountStockTakeItem - Stock take is not counting")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Stock take is already " + stockTake.Status,
+		})
+	}
+
+	var item models.StockTakeItem
+	if err := stc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("stock_take_id = ? AND sku = ?", stockTake.ID, req.SKU).First(&item).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				return err
+			}
+
+			// SKU found outside the session scope
+			var product models.Product
+			if err := tx.Where("sku = ?", req.SKU).First(&product).Error; err != nil {
+				return fmt.Errorf("product with SKU %s not found", req.SKU)
+			}
+			item = models.StockTakeItem{
+				StockTakeID:    stockTake.ID,
+				SKU:            product.SKU,
+				ProductName:    product.Name,
+				Location:       product.Location,
+				SystemQuantity: utils.GetInventoryQuantity(tx, product.SKU),
+				Unexpected:     true,
+			}
+			if err := tx.Create(&item).Error; err != nil {
+				return err
+			}
+		}
+
+		counted := req.Quantity
+		if req.Mode == "add" && item.CountedQuantity != nil {
+			counted += *item.CountedQuantity
+		}
+		now := time.Now()
+		countedBy := uint(userID)
+		item.CountedQuantity = &counted
+		item.CountedBy = &countedBy
+		item.CountedAt = &now
+
+		return tx.Model(&item).Updates(map[string]interface{}{
+			"counted_quantity": counted,
+			"counted_by":       countedBy,
+			"counted_at":       now,
+		}).Error
+	}); err != nil {
+		log.Println("CountStockTakeItem - Failed to record count:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to record count: " + err.Error(),
+		})
+	}
+
+	stc.DB.Preload("CountUser").First(&item, item.ID)
+
+	log.Println("CountStockTakeItem completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("SKU %s counted: %d (variance %d)", item.SKU, *item.CountedQuantity, item.Variance()),
+		Data:    item.ToResponse(),
+	})
+}
+
+// ApproveStockTake approves a stock take and posts its variances as inventory adjustments
+// @Summary Approve Stock Take
+// @Description Approve a stock take session. Every counted item with a variance is posted to inventory as an audited adjustment; uncounted items are left unchanged.
+// @Tags Stock Takes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock Take ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.StockTakeResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/stock-takes/{id}/approve [put]
+func (stc *StockTakeController) ApproveStockTake(c fiber.Ctx) error {
+	log.Println("ApproveStockTake called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ApproveStockTake - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	stockTake, err := stc.loadStockTake(id)
+	if err != nil {
+		log.Println("ApproveStockTake - Stock take not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Stock take with id " + id + " not found.",
+		})
+	}
+
+	if stockTake.Status != "counting" {
+		log.Println("ApproveStockTake - Stock take is not counting")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Stock take is already " + stockTake.Status,
+		})
+	}
+
+	approverID := uint(userID)
+	adjusted := 0
+	if err := stc.DB.Transaction(func(tx *gorm.DB) error {
+		// Guard against concurrent approvals
+		result := tx.Model(&models.StockTake{}).Where("id = ? AND status = ?", stockTake.ID, "counting").Updates(map[string]interface{}{
+			"status":      "approved",
+			"approved_by": approverID,
+			"approved_at": time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("stock take is no longer counting")
+		}
+
+		for _, item := range stockTake.Items {
+			variance := item.Variance()
+			if variance == 0 {
+				continue
+			}
+			if _, err := utils.AdjustInventory(tx, utils.InventoryAdjustment{
+				SKU:           item.SKU,
+				Quantity:      variance,
+				Type:          utils.InventoryMovementStockTake,
+				ReferenceType: "stock_take",
+				ReferenceID:   stockTake.ID,
+				Note:          fmt.Sprintf("Stock take %s: system %d, counted %d", stockTake.Code, item.SystemQuantity, *item.CountedQuantity),
+				CreatedBy:     &approverID,
+			}); err != nil {
+				return err
+			}
+			adjusted++
+		}
+		return nil
+	}); err != nil {
+		log.Println("ApproveStockTake - Failed to approve stock take:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to approve stock take: " + err.Error(),
+		})
+	}
+
+	approved, err := stc.loadStockTake(stockTake.ID)
+	if err != nil {
+		log.Println("ApproveStockTake - Failed to reload stock take:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload stock take",
+		})
+	}
+
+	log.Println("ApproveStockTake completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Stock take %s approved, %d inventory adjustments posted", approved.Code, adjusted),
+		Data:    approved.ToResponse(),
+	})
+}
+
+// CancelStockTake cancels a stock take session without touching inventory
+// @Summary Cancel Stock Take
+// @Description Cancel a stock take session that is still counting
+// @Tags Stock Takes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock Take ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.StockTakeResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/stock-takes/{id}/cancel [put]
+func (stc *StockTakeController) CancelStockTake(c fiber.Ctx) error {
+	log.Println("CancelStockTake called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("CancelStockTake - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	result := stc.DB.Model(&models.StockTake{}).Where("id = ? AND status = ?", id, "counting").Updates(map[string]interface{}{
+		"status":      "canceled",
+		"canceled_by": uint(userID),
+		"canceled_at": time.Now(),
+	})
+	if result.Error != nil {
+		log.Println("CancelStockTake - Failed to cancel stock take:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to cancel stock take",
+		})
+	}
+	if result.RowsAffected == 0 {
+		log.Println("CancelStockTake - Stock take not found or not counting")
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Stock take with id " + id + " not found or no longer counting.",
+		})
+	}
+
+	canceled, err := stc.loadStockTake(id)
+	if err != nil {
+		log.Println("CancelStockTake - Failed to reload stock take:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload stock take",
+		})
+	}
+
+	log.Println("CancelStockTake completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Stock take canceled successfully",
+		Data:    canceled.ToResponse(),
+	})
+}
+
+// normalizeScopeValues trims values and removes blanks and duplicates
+func normalizeScopeValues(values []string) []string {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+	return normalized
+}
@@ -22,13 +22,36 @@ func NewStoreController(db *gorm.DB) *StoreController {
 
 // Request structs
 type CreateStoreRequest struct {
-	StoreCode string `json:"storeCode" validate:"required,min=3,max=50"`
-	StoreName string `json:"storeName" validate:"required,min=3,max=100"`
+	StoreCode  string `json:"storeCode" validate:"required,min=3,max=50"`
+	StoreName  string `json:"storeName" validate:"required,min=3,max=100"`
+	LocationID *uint  `json:"locationId"` // warehouse location the store's orders are picked at
+	// Branding printed on the store's invoices
+	InvoiceName    string `json:"invoiceName" validate:"max=150"`
+	InvoiceAddress string `json:"invoiceAddress"`
+	InvoicePhone   string `json:"invoicePhone" validate:"max=30"`
+	InvoiceFooter  string `json:"invoiceFooter"`
+	// Prefix of order numbers generated for orders without a marketplace order id, empty uses the channel's prefix
+	OrderNumberPrefix string `json:"orderNumberPrefix" validate:"max=20" example:"LIVO"`
 }
 
 type UpdateStoreRequest struct {
-	StoreCode string `json:"storeCode" validate:"required,min=3,max=50"`
-	StoreName string `json:"storeName" validate:"required,min=3,max=100"`
+	StoreCode  string `json:"storeCode" validate:"required,min=3,max=50"`
+	StoreName  string `json:"storeName" validate:"required,min=3,max=100"`
+	LocationID *uint  `json:"locationId"` // warehouse location the store's orders are picked at
+	// Branding printed on the store's invoices
+	InvoiceName    string `json:"invoiceName" validate:"max=150"`
+	InvoiceAddress string `json:"invoiceAddress"`
+	InvoicePhone   string `json:"invoicePhone" validate:"max=30"`
+	InvoiceFooter  string `json:"invoiceFooter"`
+	// Prefix of order numbers generated for orders without a marketplace order id, empty uses the channel's prefix
+	OrderNumberPrefix string `json:"orderNumberPrefix" validate:"max=20" example:"LIVO"`
+}
+
+// locationExists reports whether the warehouse location exists
+func (bc *StoreController) locationExists(locationID uint) bool {
+	var count int64
+	bc.DB.Model(&models.Location{}).Where("id = ? AND deleted_at IS NULL", locationID).Count(&count)
+	return count > 0
 }
 
 // GetStores retrieves a list of stores with pagination and search
@@ -182,10 +205,31 @@ func (bc *StoreController) CreateStore(c fiber.Ctx) error {
 		})
 	}
 
+	if req.LocationID != nil && !bc.locationExists(*req.LocationID) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Location with id " + strconv.FormatUint(uint64(*req.LocationID), 10) + " not found.",
+		})
+	}
+
+	orderNumberPrefix, ok := utils.NormalizeOrderNumberPrefix(req.OrderNumberPrefix)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid order number prefix. Use up to 20 letters, digits and dashes.",
+		})
+	}
+
 	// Create new store
 	newStore := models.Store{
-		StoreCode: req.StoreCode,
-		StoreName: req.StoreName,
+		StoreCode:         req.StoreCode,
+		StoreName:         req.StoreName,
+		LocationID:        req.LocationID,
+		InvoiceName:       strings.TrimSpace(req.InvoiceName),
+		InvoiceAddress:    strings.TrimSpace(req.InvoiceAddress),
+		InvoicePhone:      strings.TrimSpace(req.InvoicePhone),
+		InvoiceFooter:     strings.TrimSpace(req.InvoiceFooter),
+		OrderNumberPrefix: orderNumberPrefix,
 	}
 
 	if err := bc.DB.Create(&newStore).Error; err != nil {
@@ -253,9 +297,30 @@ func (bc *StoreController) UpdateStore(c fiber.Ctx) error {
 		})
 	}
 
+	if req.LocationID != nil && !bc.locationExists(*req.LocationID) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Location with id " + strconv.FormatUint(uint64(*req.LocationID), 10) + " not found.",
+		})
+	}
+
+	orderNumberPrefix, ok := utils.NormalizeOrderNumberPrefix(req.OrderNumberPrefix)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid order number prefix. Use up to 20 letters, digits and dashes.",
+		})
+	}
+
 	// Update store fields
 	store.StoreCode = req.StoreCode
 	store.StoreName = req.StoreName
+	store.LocationID = req.LocationID
+	store.InvoiceName = strings.TrimSpace(req.InvoiceName)
+	store.InvoiceAddress = strings.TrimSpace(req.InvoiceAddress)
+	store.InvoicePhone = strings.TrimSpace(req.InvoicePhone)
+	store.InvoiceFooter = strings.TrimSpace(req.InvoiceFooter)
+	store.OrderNumberPrefix = orderNumberPrefix
 
 	if err := bc.DB.Save(&store).Error; err != nil {
 		log.Println("UpdateStore - Failed to update store:", err)
@@ -308,6 +373,11 @@ func (bc *StoreController) DeleteStore(c fiber.Ctx) error {
 			Error:   "Failed to delete store",
 		})
 	}
+	if store.LogoKey != "" {
+		if err := utils.GetStorage().Delete(c.Context(), store.LogoKey); err != nil {
+			log.Println("DeleteStore - Failed to delete logo file:", err)
+		}
+	}
 
 	log.Println("DeleteStore completed successfully")
 	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
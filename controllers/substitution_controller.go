@@ -0,0 +1,506 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type SubstitutionController struct {
+	DB *gorm.DB
+}
+
+func NewSubstitutionController(db *gorm.DB) *SubstitutionController {
+	return &SubstitutionController{DB: db}
+}
+
+// errPickShortageResolved is returned when a shortage was resolved by someone else in the meantime
+var errPickShortageResolved = errors.New("pick shortage already resolved")
+
+// Request structs
+type CreateProductSubstitutionRequest struct {
+	SKU           string `json:"sku" validate:"required" example:"SKU-A"`
+	SubstituteSKU string `json:"substituteSku" validate:"required" example:"SKU-B"`
+	Notes         string `json:"notes" example:"Same product, newer packaging"`
+}
+
+type UpdateProductSubstitutionRequest struct {
+	IsActive *bool   `json:"isActive"`
+	Notes    *string `json:"notes"`
+}
+
+type SubstituteShortageRequest struct {
+	SubstituteSKU string `json:"substituteSku" validate:"required" example:"SKU-B"`
+}
+
+// GetProductSubstitutions retrieves the configured product substitutions
+// @Summary Get Product Substitutions
+// @Description Retrieve the SKU substitution rules with pagination and filters
+// @Tags Substitutions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of substitutions per page" default(10)
+// @Param sku query string false "Filter by the ordered SKU"
+// @Param active query bool false "Filter by active status"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.ProductSubstitutionResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/substitutions [get]
+func (sc *SubstitutionController) GetProductSubstitutions(c fiber.Ctx) error {
+	log.Println("GetProductSubstitutions called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var substitutions []models.ProductSubstitution
+
+	// Build base query
+	query := sc.DB.Model(&models.ProductSubstitution{}).Preload("CreateUser").Order("sku ASC, substitute_sku ASC")
+
+	// SKU filter if provided
+	sku := strings.TrimSpace(c.Query("sku", ""))
+	if sku != "" {
+		query = query.Where("sku = ?", sku)
+	}
+
+	// Active filter if provided
+	active := strings.TrimSpace(c.Query("active", ""))
+	if active != "" {
+		query = query.Where("is_active = ?", active == "true")
+	}
+
+	var total int64
+	query.Count(&total)
+
+	if err := query.Limit(limit).Offset(offset).Find(&substitutions).Error; err != nil {
+		log.Println("GetProductSubstitutions - Failed to retrieve product substitutions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve product substitutions",
+		})
+	}
+
+	substitutionList := make([]models.ProductSubstitutionResponse, len(substitutions))
+	for i, substitution := range substitutions {
+		substitutionList[i] = *substitution.ToResponse()
+	}
+
+	// Build success message
+	message := "Product substitutions retrieved successfully"
+	var filters []string
+
+	if sku != "" {
+		filters = append(filters, "sku: "+sku)
+	}
+
+	if active != "" {
+		filters = append(filters, "active: "+active)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetProductSubstitutions completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    substitutionList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateProductSubstitution allows an SKU to be fulfilled by another SKU during a shortage
+// @Summary Create Product Substitution
+// @Description Allow an SKU to be fulfilled by a substitute SKU when it is short during picking
+// @Tags Substitutions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateProductSubstitutionRequest true "Substitution rule"
+// @Success 201 {object} utils.SuccessResponse{data=models.ProductSubstitutionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/substitutions [post]
+func (sc *SubstitutionController) CreateProductSubstitution(c fiber.Ctx) error {
+	log.Println("CreateProductSubstitution called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req CreateProductSubstitutionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateProductSubstitution - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.SKU = strings.TrimSpace(req.SKU)
+	req.SubstituteSKU = strings.TrimSpace(req.SubstituteSKU)
+	if req.SKU == "" || req.SubstituteSKU == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU and substitute SKU are required",
+		})
+	}
+	if strings.EqualFold(req.SKU, req.SubstituteSKU) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "An SKU cannot substitute itself",
+		})
+	}
+
+	// Both SKUs must be known products
+	for _, sku := range []string{req.SKU, req.SubstituteSKU} {
+		var product models.Product
+		if err := sc.DB.Where("sku = ?", sku).First(&product).Error; err != nil {
+			log.Println("CreateProductSubstitution - Product not found:", sku)
+			return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Product with SKU " + sku + " not found",
+			})
+		}
+	}
+
+	// Check for an existing rule for the same pair
+	var existing models.ProductSubstitution
+	if err := sc.DB.Where("sku = ? AND substitute_sku = ?", req.SKU, req.SubstituteSKU).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU " + req.SKU + " can already be substituted by " + req.SubstituteSKU,
+		})
+	}
+
+	substitution := models.ProductSubstitution{
+		SKU:           req.SKU,
+		SubstituteSKU: req.SubstituteSKU,
+		IsActive:      true,
+		Notes:         strings.TrimSpace(req.Notes),
+		CreatedBy:     uint(userID),
+	}
+	if err := sc.DB.Create(&substitution).Error; err != nil {
+		log.Println("CreateProductSubstitution - Failed to create product substitution:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create product substitution",
+		})
+	}
+	sc.DB.Preload("CreateUser").First(&substitution, substitution.ID)
+
+	log.Println("CreateProductSubstitution completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product substitution created successfully",
+		Data:    substitution.ToResponse(),
+	})
+}
+
+// UpdateProductSubstitution activates, deactivates or annotates a substitution rule
+// @Summary Update Product Substitution
+// @Description Activate or deactivate a substitution rule or update its notes
+// @Tags Substitutions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product Substitution ID"
+// @Param request body UpdateProductSubstitutionRequest true "Fields to update"
+// @Success 200 {object} utils.SuccessResponse{data=models.ProductSubstitutionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/substitutions/{id} [put]
+func (sc *SubstitutionController) UpdateProductSubstitution(c fiber.Ctx) error {
+	log.Println("UpdateProductSubstitution called")
+	// Parse id parameter
+	id := c.Params("id")
+	var substitution models.ProductSubstitution
+	if err := sc.DB.Where("id = ?", id).First(&substitution).Error; err != nil {
+		log.Println("UpdateProductSubstitution - Product substitution not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product substitution with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateProductSubstitutionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateProductSubstitution - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if req.IsActive != nil {
+		substitution.IsActive = *req.IsActive
+	}
+	if req.Notes != nil {
+		substitution.Notes = strings.TrimSpace(*req.Notes)
+	}
+	if err := sc.DB.Save(&substitution).Error; err != nil {
+		log.Println("UpdateProductSubstitution - Failed to update product substitution:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update product substitution",
+		})
+	}
+	sc.DB.Preload("CreateUser").First(&substitution, substitution.ID)
+
+	log.Println("UpdateProductSubstitution completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product substitution updated successfully",
+		Data:    substitution.ToResponse(),
+	})
+}
+
+// DeleteProductSubstitution deletes a substitution rule
+// @Summary Delete Product Substitution
+// @Description Delete a substitution rule, items already substituted keep their substitute
+// @Tags Substitutions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product Substitution ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/substitutions/{id} [delete]
+func (sc *SubstitutionController) DeleteProductSubstitution(c fiber.Ctx) error {
+	log.Println("DeleteProductSubstitution called")
+	// Parse id parameter
+	id := c.Params("id")
+	result := sc.DB.Where("id = ?", id).Delete(&models.ProductSubstitution{})
+	if result.Error != nil {
+		log.Println("DeleteProductSubstitution - Failed to delete product substitution:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete product substitution",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product substitution with id " + id + " not found.",
+		})
+	}
+
+	log.Println("DeleteProductSubstitution completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Product substitution deleted successfully",
+	})
+}
+
+// SubstituteShortage resolves an open pick shortage by fulfilling the item with an approved substitute SKU
+// @Summary Substitute Shortage
+// @Description Approve fulfilling a short item with a substitute SKU allowed by an active substitution rule. The order detail keeps the original SKU, the substitute goes back on the picking checklist and QC validates the substitute.
+// @Tags Substitutions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Pick Shortage ID"
+// @Param request body SubstituteShortageRequest true "Substitute SKU"
+// @Success 200 {object} utils.SuccessResponse{data=models.PickShortageResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/shortages/{id}/substitute [put]
+func (sc *SubstitutionController) SubstituteShortage(c fiber.Ctx) error {
+	log.Println("SubstituteShortage called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var approver models.User
+	if err := sc.DB.Where("id = ?", userID).First(&approver).Error; err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Parse id parameter
+	id := c.Params("id")
+	var shortage models.PickShortage
+	if err := sc.DB.Preload("Order").Where("id = ?", id).First(&shortage).Error; err != nil {
+		log.Println("SubstituteShortage - Pick shortage not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick shortage with id " + id + " not found.",
+		})
+	}
+
+	if shortage.Status != "open" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick shortage is already resolved",
+		})
+	}
+
+	// Binding request body
+	var req SubstituteShortageRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("SubstituteShortage - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	req.SubstituteSKU = strings.TrimSpace(req.SubstituteSKU)
+
+	// The substitute must be allowed by an active rule for the short SKU
+	var substitution models.ProductSubstitution
+	if err := sc.DB.Where("sku = ? AND substitute_sku = ? AND is_active = ?", shortage.SKU, req.SubstituteSKU, true).First(&substitution).Error; err != nil {
+		log.Println("SubstituteShortage - No active substitution rule:", shortage.SKU, "->", req.SubstituteSKU)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "SKU " + shortage.SKU + " cannot be substituted by " + req.SubstituteSKU,
+		})
+	}
+
+	var product models.Product
+	if err := sc.DB.Where("sku = ?", req.SubstituteSKU).First(&product).Error; err != nil {
+		log.Println("SubstituteShortage - Substitute product not found:", req.SubstituteSKU)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Product with SKU " + req.SubstituteSKU + " not found",
+		})
+	}
+
+	var detail models.OrderDetail
+	if err := sc.DB.Where("id = ?", shortage.OrderDetailID).First(&detail).Error; err != nil {
+		log.Println("SubstituteShortage - Order detail not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Order item of the shortage not found",
+		})
+	}
+
+	now := time.Now()
+	approverID := uint(userID)
+	err = sc.DB.Transaction(func(tx *gorm.DB) error {
+		// Only resolve the shortage if it is still open to avoid concurrent resolutions
+		result := tx.Model(&models.PickShortage{}).Where("id = ? AND status = ?", shortage.ID, "open").Updates(map[string]interface{}{
+			"status":         "resolved",
+			"resolution":     "substituted",
+			"substitute_sku": product.SKU,
+			"resolved_by":    approverID,
+			"resolved_at":    now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPickShortageResolved
+		}
+
+		// Keep the SKU that was ordered if the item was substituted before
+		originalSKU := detail.OriginalSKU
+		if originalSKU == "" {
+			originalSKU = detail.SKU
+		}
+
+		// The substitute goes back on the picking checklist and QC validates it instead of the original
+		if err := tx.Model(&models.OrderDetail{}).Where("id = ?", detail.ID).Updates(map[string]interface{}{
+			"sku":              product.SKU,
+			"product_name":     product.Name,
+			"variant":          product.Variant,
+			"original_sku":     originalSKU,
+			"substituted_by":   approverID,
+			"substituted_at":   now,
+			"picked_quantity":  0,
+			"is_picked":        false,
+			"item_picked_at":   nil,
+			"is_shortage":      false,
+			"shortage_reason":  "",
+			"is_valid":         false,
+			"scanned_quantity": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		// Resume picking once the order has no other open shortages
+		var openShortages int64
+		if err := tx.Model(&models.PickShortage{}).Where("order_id = ? AND status = ?", shortage.OrderID, "open").Count(&openShortages).Error; err != nil {
+			return err
+		}
+		if openShortages == 0 && shortage.Order != nil && shortage.Order.ProcessingStatus == models.ProcessingStatusAwaitingStock {
+			nextStatus := models.ProcessingStatusReadyToPick
+			if shortage.Order.PickedBy != nil {
+				nextStatus = models.ProcessingStatusPickingProgress
+			}
+			if err := tx.Model(&models.Order{}).Where("id = ?", shortage.OrderID).Update("processing_status", nextStatus).Error; err != nil {
+				return err
+			}
+		}
+
+		return utils.RecordApproval(tx, "substitution", "pick_shortage", shortage.ID, shortage.DeclaredBy, &utils.ApprovalGrant{Approver: &approver})
+	})
+	if errors.Is(err, errPickShortageResolved) {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pick shortage was already resolved, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("SubstituteShortage - Failed to substitute shortage:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to substitute shortage",
+		})
+	}
+
+	if err := sc.DB.Preload("DeclareUser").Preload("ResolveUser").Where("id = ?", shortage.ID).First(&shortage).Error; err != nil {
+		log.Println("SubstituteShortage - Failed to reload shortage:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to reload shortage",
+		})
+	}
+
+	log.Println("SubstituteShortage completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("SKU %s substituted by %s, the order can continue picking", shortage.SKU, shortage.SubstituteSKU),
+		Data:    shortage.ToResponse(),
+	})
+}
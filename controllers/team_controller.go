@@ -0,0 +1,520 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type TeamController struct {
+	DB *gorm.DB
+}
+
+func NewTeamController(db *gorm.DB) *TeamController {
+	return &TeamController{DB: db}
+}
+
+// Request structs
+type CreateTeamRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=100" example:"Picking Team A"`
+	Description string `json:"description" example:"Morning shift pickers"`
+}
+
+type UpdateTeamRequest struct {
+	Name        string `json:"name" validate:"required,min=2,max=100" example:"Picking Team A"`
+	Description string `json:"description" example:"Morning shift pickers"`
+}
+
+type AddTeamMembersRequest struct {
+	UserIDs []uint `json:"userIds" validate:"required,min=1" example:"4,7"`
+}
+
+// loadTeam loads a team with its members and their roles
+func (tc *TeamController) loadTeam(id interface{}) (*models.Team, error) {
+	var team models.Team
+	if err := tc.DB.Preload("Members", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Members.User.Roles").Where("id = ?", id).First(&team).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+// GetTeams retrieves a list of teams with their member counts
+// @Summary Get Teams
+// @Description Retrieve a list of teams with pagination and search
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of teams per page" default(10)
+// @Param search query string false "Search term for team name"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.TeamResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/teams [get]
+func (tc *TeamController) GetTeams(c fiber.Ctx) error {
+	log.Println("GetTeams called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var teams []models.Team
+
+	// Build base query
+	query := tc.DB.Model(&models.Team{}).Preload("Members").Order("name ASC")
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("name ILIKE ?", "%"+search+"%")
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Offset(offset).Limit(limit).Find(&teams).Error; err != nil {
+		log.Println("GetTeams - Failed to retrieve teams:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve teams",
+		})
+	}
+
+	// Format response without member lists, only counts
+	teamList := make([]models.TeamResponse, len(teams))
+	for i, team := range teams {
+		teamList[i] = *team.ToResponse()
+		teamList[i].Members = nil
+	}
+
+	// Build success message
+	message := "Teams retrieved successfully"
+	var filters []string
+
+	if search != "" {
+		filters = append(filters, "search: "+search)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetTeams completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    teamList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetTeam retrieves a single team with its members
+// @Summary Get Team
+// @Description Retrieve a single team with its members
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.TeamResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/teams/{id} [get]
+func (tc *TeamController) GetTeam(c fiber.Ctx) error {
+	log.Println("GetTeam called")
+	// Parse id parameter
+	id := c.Params("id")
+	team, err := tc.loadTeam(id)
+	if err != nil {
+		log.Println("GetTeam - Team not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Team with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetTeam completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Team retrieved successfully",
+		Data:    team.ToResponse(),
+	})
+}
+
+// CreateTeam creates a new team
+// @Summary Create Team
+// @Description Create a new team such as a picking team, the QC ribbon team or customer service
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateTeamRequest true "Team data"
+// @Success 201 {object} utils.SuccessResponse{data=models.TeamResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/teams [post]
+func (tc *TeamController) CreateTeam(c fiber.Ctx) error {
+	log.Println("CreateTeam called")
+	// Binding request body
+	var req CreateTeamRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateTeam - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) < 2 || len(req.Name) > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Team name must be between 2 and 100 characters",
+		})
+	}
+
+	// Check for existing team with the same name
+	var existing models.Team
+	if err := tc.DB.Where("LOWER(name) = LOWER(?)", req.Name).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Team " + req.Name + " already exists",
+		})
+	}
+
+	team := models.Team{
+		Name:        req.Name,
+		Description: strings.TrimSpace(req.Description),
+	}
+	if err := tc.DB.Create(&team).Error; err != nil {
+		log.Println("CreateTeam - Failed to create team:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create team",
+		})
+	}
+
+	log.Println("CreateTeam completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Team created successfully",
+		Data:    team.ToResponse(),
+	})
+}
+
+// UpdateTeam updates the name and description of a team
+// @Summary Update Team
+// @Description Update the name and description of a team
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param request body UpdateTeamRequest true "Team data"
+// @Success 200 {object} utils.SuccessResponse{data=models.TeamResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/teams/{id} [put]
+func (tc *TeamController) UpdateTeam(c fiber.Ctx) error {
+	log.Println("UpdateTeam called")
+	// Parse id parameter
+	id := c.Params("id")
+	var team models.Team
+	if err := tc.DB.Where("id = ?", id).First(&team).Error; err != nil {
+		log.Println("UpdateTeam - Team not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Team with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateTeamRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateTeam - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) < 2 || len(req.Name) > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Team name must be between 2 and 100 characters",
+		})
+	}
+
+	// Check for another team with the same name
+	var existing models.Team
+	if err := tc.DB.Where("LOWER(name) = LOWER(?) AND id <> ?", req.Name, team.ID).First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Team " + req.Name + " already exists",
+		})
+	}
+
+	team.Name = req.Name
+	team.Description = strings.TrimSpace(req.Description)
+	if err := tc.DB.Save(&team).Error; err != nil {
+		log.Println("UpdateTeam - Failed to update team:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update team",
+		})
+	}
+
+	reloadedTeam, err := tc.loadTeam(team.ID)
+	if err != nil {
+		log.Println("UpdateTeam - Failed to load team:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load team",
+		})
+	}
+
+	log.Println("UpdateTeam completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Team updated successfully",
+		Data:    reloadedTeam.ToResponse(),
+	})
+}
+
+// DeleteTeam deletes a team and its memberships
+// @Summary Delete Team
+// @Description Delete a team and its memberships, the users themselves are kept
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/teams/{id} [delete]
+func (tc *TeamController) DeleteTeam(c fiber.Ctx) error {
+	log.Println("DeleteTeam called")
+	// Parse id parameter
+	id := c.Params("id")
+	var team models.Team
+	if err := tc.DB.Where("id = ?", id).First(&team).Error; err != nil {
+		log.Println("DeleteTeam - Team not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Team with id " + id + " not found.",
+		})
+	}
+
+	err := tc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("team_id = ?", team.ID).Delete(&models.TeamMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&team).Error
+	})
+	if err != nil {
+		log.Println("DeleteTeam - Failed to delete team:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete team",
+		})
+	}
+
+	log.Println("DeleteTeam completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Team deleted successfully",
+	})
+}
+
+// AddTeamMembers adds users to a team
+// @Summary Add Team Members
+// @Description Add users to a team, users already in the team are skipped
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param request body AddTeamMembersRequest true "Users to add"
+// @Success 200 {object} utils.SuccessResponse{data=models.TeamResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/teams/{id}/members [post]
+func (tc *TeamController) AddTeamMembers(c fiber.Ctx) error {
+	log.Println("AddTeamMembers called")
+	// Parse id parameter
+	id := c.Params("id")
+	var team models.Team
+	if err := tc.DB.Where("id = ?", id).First(&team).Error; err != nil {
+		log.Println("AddTeamMembers - Team not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Team with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req AddTeamMembersRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("AddTeamMembers - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if len(req.UserIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "userIds is required",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// All users must exist
+	var userCount int64
+	if err := tc.DB.Model(&models.User{}).Where("id IN ?", req.UserIDs).Count(&userCount).Error; err != nil {
+		log.Println("AddTeamMembers - Failed to check users:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to add team members",
+		})
+	}
+	uniqueUserIDs := make(map[uint]bool)
+	for _, memberID := range req.UserIDs {
+		uniqueUserIDs[memberID] = true
+	}
+	if int(userCount) != len(uniqueUserIDs) {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "One or more users not found",
+		})
+	}
+
+	// Skip users already in the team
+	var existingIDs []uint
+	if err := tc.DB.Model(&models.TeamMember{}).Where("team_id = ? AND user_id IN ?", team.ID, req.UserIDs).Pluck("user_id", &existingIDs).Error; err != nil {
+		log.Println("AddTeamMembers - Failed to check memberships:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to add team members",
+		})
+	}
+	for _, existingID := range existingIDs {
+		delete(uniqueUserIDs, existingID)
+	}
+
+	members := make([]models.TeamMember, 0, len(uniqueUserIDs))
+	for memberID := range uniqueUserIDs {
+		members = append(members, models.TeamMember{TeamID: team.ID, UserID: memberID, AddedBy: uint(userID)})
+	}
+	if len(members) > 0 {
+		if err := tc.DB.Create(&members).Error; err != nil {
+			log.Println("AddTeamMembers - Failed to add team members:", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Failed to add team members",
+			})
+		}
+	}
+
+	reloadedTeam, err := tc.loadTeam(team.ID)
+	if err != nil {
+		log.Println("AddTeamMembers - Failed to load team:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load team",
+		})
+	}
+
+	log.Println("AddTeamMembers completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("%d members added to team %s", len(members), team.Name),
+		Data:    reloadedTeam.ToResponse(),
+	})
+}
+
+// RemoveTeamMember removes a user from a team
+// @Summary Remove Team Member
+// @Description Remove a user from a team
+// @Tags Teams
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Team ID"
+// @Param userId path int true "User ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.TeamResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/teams/{id}/members/{userId} [delete]
+func (tc *TeamController) RemoveTeamMember(c fiber.Ctx) error {
+	log.Println("RemoveTeamMember called")
+	// Parse id parameters
+	id := c.Params("id")
+	memberID := c.Params("userId")
+
+	result := tc.DB.Where("team_id = ? AND user_id = ?", id, memberID).Delete(&models.TeamMember{})
+	if result.Error != nil {
+		log.Println("RemoveTeamMember - Failed to remove team member:", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to remove team member",
+		})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User " + memberID + " is not a member of team " + id,
+		})
+	}
+
+	reloadedTeam, err := tc.loadTeam(id)
+	if err != nil {
+		log.Println("RemoveTeamMember - Failed to load team:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load team",
+		})
+	}
+
+	log.Println("RemoveTeamMember completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Team member removed successfully",
+		Data:    reloadedTeam.ToResponse(),
+	})
+}
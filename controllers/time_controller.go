@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/utils"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type TimeController struct {
+	Config *config.Config
+}
+
+func NewTimeController(cfg *config.Config) *TimeController {
+	return &TimeController{Config: cfg}
+}
+
+// Unique response structs
+// ServerTimeResponse is the canonical server time devices align their clocks to
+type ServerTimeResponse struct {
+	ServerTime       string `json:"serverTime"` // RFC3339
+	UnixMillis       int64  `json:"unixMillis"`
+	Timezone         string `json:"timezone"`
+	SkewToleranceSec int    `json:"skewToleranceSec"`
+}
+
+// TimeTokenResponse is the server time signed for offline capture
+type TimeTokenResponse struct {
+	ServerTimeResponse
+	TimeToken          string `json:"timeToken"`
+	OfflineWindowHours int    `json:"offlineWindowHours"`
+}
+
+// GetServerTime retrieves the canonical server time
+// @Summary Get Server Time
+// @Description Retrieve the canonical server time so devices can detect and correct clock skew
+// @Tags Time
+// @Accept json
+// @Produce json
+// @Success 200 {object} utils.SuccessResponse{data=ServerTimeResponse}
+// @Router /api/time [get]
+func (tc *TimeController) GetServerTime(c fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Server time retrieved successfully",
+		Data:    tc.serverTime(time.Now()),
+	})
+}
+
+// GetTimeToken issues a signed server time token for offline capture
+// @Summary Get Time Token
+// @Description Issue a signed token of the current server time. Devices send it with actions captured offline so the server can verify the capture time against the last clock sync; timestamps outside the skew tolerance or the offline window are flagged
+// @Tags Time
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=TimeTokenResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/time/token [get]
+func (tc *TimeController) GetTimeToken(c fiber.Ctx) error {
+	log.Println("GetTimeToken called")
+	userID, _ := c.Locals("userId").(string)
+	if userID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	token, issuedAt, err := utils.ClockSkewPolicyFromConfig(tc.Config).GenerateTimeToken(userID)
+	if err != nil {
+		log.Println("GetTimeToken - Failed to generate time token:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to generate time token",
+		})
+	}
+
+	log.Println("GetTimeToken completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Time token generated successfully",
+		Data: TimeTokenResponse{
+			ServerTimeResponse: tc.serverTime(issuedAt),
+			TimeToken:          token,
+			OfflineWindowHours: tc.Config.OfflineCaptureWindowHours,
+		},
+	})
+}
+
+func (tc *TimeController) serverTime(now time.Time) ServerTimeResponse {
+	if location, err := time.LoadLocation(tc.Config.DbTz); err == nil {
+		now = now.In(location)
+	}
+	return ServerTimeResponse{
+		ServerTime:       now.Format(time.RFC3339),
+		UnixMillis:       now.UnixMilli(),
+		Timezone:         now.Location().String(),
+		SkewToleranceSec: tc.Config.ClockSkewToleranceSeconds,
+	}
+}
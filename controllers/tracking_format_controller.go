@@ -0,0 +1,405 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type TrackingFormatController struct {
+	DB *gorm.DB
+}
+
+func NewTrackingFormatController(db *gorm.DB) *TrackingFormatController {
+	return &TrackingFormatController{DB: db}
+}
+
+// Request structs
+type CreateTrackingFormatRequest struct {
+	ExpeditionID uint   `json:"expeditionId" validate:"required" example:"1"`
+	Pattern      string `json:"pattern" validate:"required,max=255" example:"JP[0-9]{10}"`
+	Description  string `json:"description" validate:"omitempty,max=255" example:"JP followed by 10 digits"`
+	Example      string `json:"example" validate:"omitempty,max=100" example:"JP1234567890"`
+}
+
+type UpdateTrackingFormatRequest struct {
+	Pattern     string `json:"pattern" validate:"required,max=255" example:"JP[0-9]{10}"`
+	Description string `json:"description" validate:"omitempty,max=255" example:"JP followed by 10 digits"`
+	Example     string `json:"example" validate:"omitempty,max=100" example:"JP1234567890"`
+	IsActive    bool   `json:"isActive" example:"true"`
+}
+
+type CheckTrackingNumberRequest struct {
+	TrackingNumber string `json:"trackingNumber" validate:"required" example:"JP12345678O0"`
+}
+
+// CheckTrackingNumberResponse is the result of checking a tracking number against the expedition formats
+type CheckTrackingNumberResponse struct {
+	TrackingNumber string `json:"trackingNumber"`
+	Valid          bool   `json:"valid"`
+	Error          string `json:"error,omitempty"`
+}
+
+// trackingFormatPatternError checks the pattern compiles and the example matches it, empty when both are fine.
+// Formats only apply to tracking numbers starting with the expedition code, so the example has to as well.
+func trackingFormatPatternError(pattern, example, expeditionCode string) string {
+	compiled, err := utils.CompileTrackingFormat(pattern)
+	if err != nil {
+		return "Invalid pattern: " + err.Error()
+	}
+	if example != "" && !strings.HasPrefix(example, expeditionCode) {
+		return "Example " + example + " does not start with the expedition code " + expeditionCode
+	}
+	if example != "" && !compiled.MatchString(example) {
+		return "Example " + example + " does not match the pattern"
+	}
+	return ""
+}
+
+// GetTrackingFormats retrieves a list of tracking formats with pagination and filters
+// @Summary Get Tracking Formats
+// @Description Retrieve a list of expedition tracking number formats with pagination and filters
+// @Tags Tracking Formats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of tracking formats per page" default(10)
+// @Param expeditionId query int false "Filter by expedition ID"
+// @Param isActive query bool false "Filter by active state"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.TrackingFormatResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/tracking-formats [get]
+func (tfc *TrackingFormatController) GetTrackingFormats(c fiber.Ctx) error {
+	log.Println("GetTrackingFormats called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var formats []models.TrackingFormat
+
+	// Build base query
+	query := tfc.DB.Model(&models.TrackingFormat{}).Preload("Expedition").Order("expedition_id ASC, id ASC")
+
+	var filters []string
+
+	expeditionID := strings.TrimSpace(c.Query("expeditionId", ""))
+	if expeditionID != "" {
+		query = query.Where("expedition_id = ?", expeditionID)
+		filters = append(filters, "expeditionId: "+expeditionID)
+	}
+
+	isActive := strings.TrimSpace(c.Query("isActive", ""))
+	if isActive != "" {
+		active, err := strconv.ParseBool(isActive)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid isActive value. Use true or false.",
+			})
+		}
+		query = query.Where("is_active = ?", active)
+		filters = append(filters, "isActive: "+isActive)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&formats).Error; err != nil {
+		log.Println("GetTrackingFormats - Failed to retrieve tracking formats:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve tracking formats",
+		})
+	}
+
+	// Format response
+	formatList := make([]models.TrackingFormatResponse, len(formats))
+	for i, format := range formats {
+		formatList[i] = *format.ToResponse()
+	}
+
+	// Build success message
+	message := "Tracking formats retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetTrackingFormats completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    formatList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// CreateTrackingFormat creates a new tracking format for an expedition
+// @Summary Create Tracking Format
+// @Description Create a tracking number format for an expedition. The pattern is a regular expression that must match the whole tracking number. Tracking numbers starting with the expedition code must match one of its active formats.
+// @Tags Tracking Formats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateTrackingFormatRequest true "Tracking format details"
+// @Success 201 {object} utils.SuccessResponse{data=models.TrackingFormatResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/tracking-formats [post]
+func (tfc *TrackingFormatController) CreateTrackingFormat(c fiber.Ctx) error {
+	log.Println("CreateTrackingFormat called")
+	// Binding request body
+	var req CreateTrackingFormatRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateTrackingFormat - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Pattern = strings.TrimSpace(req.Pattern)
+	req.Example = strings.ToUpper(strings.TrimSpace(req.Example))
+	if req.Pattern == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pattern is required",
+		})
+	}
+
+	var expedition models.Expedition
+	if err := tfc.DB.Where("id = ?", req.ExpeditionID).First(&expedition).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fmt.Sprintf("Expedition with id %d not found.", req.ExpeditionID),
+		})
+	}
+	if message := trackingFormatPatternError(req.Pattern, req.Example, expedition.ExpeditionCode); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	format := models.TrackingFormat{
+		ExpeditionID: expedition.ID,
+		Pattern:      req.Pattern,
+		Description:  strings.TrimSpace(req.Description),
+		Example:      req.Example,
+		IsActive:     true,
+	}
+	if err := tfc.DB.Create(&format).Error; err != nil {
+		log.Println("CreateTrackingFormat - Failed to create tracking format:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create tracking format",
+		})
+	}
+	format.Expedition = &expedition
+
+	log.Println("CreateTrackingFormat completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Tracking format created successfully",
+		Data:    format.ToResponse(),
+	})
+}
+
+// UpdateTrackingFormat updates an existing tracking format by ID
+// @Summary Update Tracking Format
+// @Description Update the pattern, description, example or active state of a tracking format
+// @Tags Tracking Formats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tracking Format ID"
+// @Param request body UpdateTrackingFormatRequest true "Updated tracking format details"
+// @Success 200 {object} utils.SuccessResponse{data=models.TrackingFormatResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/tracking-formats/{id} [put]
+func (tfc *TrackingFormatController) UpdateTrackingFormat(c fiber.Ctx) error {
+	log.Println("UpdateTrackingFormat called")
+	// Parse id parameter
+	id := c.Params("id")
+	var format models.TrackingFormat
+	if err := tfc.DB.Preload("Expedition").Where("id = ?", id).First(&format).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Tracking format with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateTrackingFormatRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateTrackingFormat - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Pattern = strings.TrimSpace(req.Pattern)
+	req.Example = strings.ToUpper(strings.TrimSpace(req.Example))
+	if req.Pattern == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Pattern is required",
+		})
+	}
+	var expeditionCode string
+	if format.Expedition != nil {
+		expeditionCode = format.Expedition.ExpeditionCode
+	}
+	if message := trackingFormatPatternError(req.Pattern, req.Example, expeditionCode); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// Updated with a map so deactivating is not skipped as a zero value
+	if err := tfc.DB.Model(&format).Updates(map[string]interface{}{
+		"pattern":     req.Pattern,
+		"description": strings.TrimSpace(req.Description),
+		"example":     req.Example,
+		"is_active":   req.IsActive,
+	}).Error; err != nil {
+		log.Println("UpdateTrackingFormat - Failed to update tracking format:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update tracking format",
+		})
+	}
+
+	format.Pattern = req.Pattern
+	format.Description = strings.TrimSpace(req.Description)
+	format.Example = req.Example
+	format.IsActive = req.IsActive
+
+	log.Println("UpdateTrackingFormat completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Tracking format updated successfully",
+		Data:    format.ToResponse(),
+	})
+}
+
+// DeleteTrackingFormat deletes a tracking format by ID
+// @Summary Delete Tracking Format
+// @Description Delete a tracking format by ID
+// @Tags Tracking Formats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tracking Format ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/tracking-formats/{id} [delete]
+func (tfc *TrackingFormatController) DeleteTrackingFormat(c fiber.Ctx) error {
+	log.Println("DeleteTrackingFormat called")
+	// Parse id parameter
+	id := c.Params("id")
+	var format models.TrackingFormat
+	if err := tfc.DB.Where("id = ?", id).First(&format).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Tracking format with id " + id + " not found.",
+		})
+	}
+
+	if err := tfc.DB.Delete(&format).Error; err != nil {
+		log.Println("DeleteTrackingFormat - Failed to delete tracking format:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete tracking format",
+		})
+	}
+
+	log.Println("DeleteTrackingFormat completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Tracking format deleted successfully",
+	})
+}
+
+// CheckTrackingNumber checks a tracking number against the expedition formats without saving anything
+// @Summary Check Tracking Number
+// @Description Check a tracking number against the active format of the expedition whose code it starts with, suggesting a correction for lookalike characters such as O and 0
+// @Tags Tracking Formats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CheckTrackingNumberRequest true "Tracking number to check"
+// @Success 200 {object} utils.SuccessResponse{data=CheckTrackingNumberResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/tracking-formats/check [post]
+func (tfc *TrackingFormatController) CheckTrackingNumber(c fiber.Ctx) error {
+	log.Println("CheckTrackingNumber called")
+	// Binding request body
+	var req CheckTrackingNumberRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CheckTrackingNumber - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	// Convert tracking number to uppercase and trim spaces
+	req.TrackingNumber = models.NormalizeIdentifier(req.TrackingNumber)
+	if req.TrackingNumber == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Tracking number is required",
+		})
+	}
+
+	formats, err := utils.LoadTrackingFormats(tfc.DB)
+	if err != nil {
+		log.Println("CheckTrackingNumber - Failed to load tracking formats:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to check tracking number",
+		})
+	}
+
+	response := CheckTrackingNumberResponse{TrackingNumber: req.TrackingNumber, Valid: true}
+	message := "Tracking number matches the expedition format"
+	if err := formats.Validate(req.TrackingNumber); err != nil {
+		response.Valid = false
+		response.Error = err.Error()
+		message = "Tracking number does not match the expedition format"
+	}
+
+	log.Println("CheckTrackingNumber completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    response,
+	})
+}
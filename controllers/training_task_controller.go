@@ -0,0 +1,248 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type TrainingTaskController struct {
+	DB *gorm.DB
+}
+
+func NewTrainingTaskController(db *gorm.DB) *TrainingTaskController {
+	return &TrainingTaskController{DB: db}
+}
+
+// Request structs
+type ClearTrainingTaskRequest struct {
+	Note string `json:"note" validate:"required" example:"Repeated the packing SOP training with the team lead"`
+}
+
+// GetTrainingTasks retrieves the retraining flags
+// @Summary Get Training Tasks
+// @Description Retrieve the training tasks of users flagged for retraining by attributed complaints, open first, with pagination and status and user filters
+// @Tags Training Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of tasks per page" default(10)
+// @Param status query string false "Filter by status (open, cleared)"
+// @Param userId query int false "Filter by user ID"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.TrainingTaskResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/training-tasks [get]
+func (ttc *TrainingTaskController) GetTrainingTasks(c fiber.Ctx) error {
+	log.Println("GetTrainingTasks called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var tasks []models.TrainingTask
+
+	// Build base query, open tasks first so the follow-up queue is on top
+	query := ttc.DB.Model(&models.TrainingTask{}).Preload("User").Preload("ClearUser").
+		Order("CASE WHEN status = 'open' THEN 0 ELSE 1 END, created_at ASC")
+
+	// Status filter if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		if status != models.TrainingTaskStatusOpen && status != models.TrainingTaskStatusCleared {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status. Use open or cleared.",
+			})
+		}
+		query = query.Where("status = ?", status)
+	}
+
+	// User filter if provided
+	userIDFilter := strings.TrimSpace(c.Query("userId", ""))
+	if userIDFilter != "" {
+		query = query.Where("user_id = ?", userIDFilter)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&tasks).Error; err != nil {
+		log.Println("GetTrainingTasks - Failed to retrieve training tasks:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve training tasks",
+		})
+	}
+
+	// Format response
+	taskList := make([]models.TrainingTaskResponse, len(tasks))
+	for i, task := range tasks {
+		taskList[i] = *task.ToResponse()
+	}
+
+	// Build success message
+	message := "Training tasks retrieved successfully"
+	var filters []string
+
+	if status != "" {
+		filters = append(filters, "status: "+status)
+	}
+
+	if userIDFilter != "" {
+		filters = append(filters, "userId: "+userIDFilter)
+	}
+
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetTrainingTasks completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    taskList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// ClearTrainingTask clears a retraining flag once the user was retrained
+// @Summary Clear Training Task
+// @Description Clear an open training task with a note once the user completed the retraining. Complaints attributed before the clearance do not flag the user again. The user is notified
+// @Tags Training Tasks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Training Task ID"
+// @Param request body ClearTrainingTaskRequest true "Clearance note"
+// @Success 200 {object} utils.SuccessResponse{data=models.TrainingTaskResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/training-tasks/{id}/clear [put]
+func (ttc *TrainingTaskController) ClearTrainingTask(c fiber.Ctx) error {
+	log.Println("ClearTrainingTask called")
+	// Parse id parameter
+	id := c.Params("id")
+	var task models.TrainingTask
+	if err := ttc.DB.Where("id = ?", id).First(&task).Error; err != nil {
+		log.Println("ClearTrainingTask - Training task not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Training task with id " + id + " not found.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		log.Println("ClearTrainingTask - Invalid user ID:", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req ClearTrainingTaskRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("ClearTrainingTask - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	req.Note = strings.TrimSpace(req.Note)
+	if req.Note == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Clearance note is required",
+		})
+	}
+
+	if task.Status != models.TrainingTaskStatusOpen {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Training task has already been cleared",
+		})
+	}
+
+	// Users cannot clear their own retraining flag
+	if task.UserID == uint(userID) {
+		return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "You cannot clear your own training task",
+		})
+	}
+
+	now := time.Now()
+	clearerID := uint(userID)
+	conflict := false
+	err = ttc.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.TrainingTask{}).Where("id = ? AND status = ?", task.ID, models.TrainingTaskStatusOpen).Updates(map[string]interface{}{
+			"status":         models.TrainingTaskStatusCleared,
+			"clearance_note": req.Note,
+			"cleared_by":     clearerID,
+			"cleared_at":     now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			conflict = true
+			return nil
+		}
+
+		return utils.NotifyUsers(tx, []uint{task.UserID}, "retraining", "Retraining cleared",
+			"Your retraining flag was cleared: "+req.Note, "training_task", task.ID)
+	})
+	if conflict {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Training task was cleared in the meantime, please reload and try again.",
+		})
+	}
+	if err != nil {
+		log.Println("ClearTrainingTask - Failed to clear training task:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to clear training task",
+		})
+	}
+
+	// Reload the task with all relationships for response
+	if err := ttc.DB.Preload("User").Preload("ClearUser").First(&task, task.ID).Error; err != nil {
+		log.Println("ClearTrainingTask - Failed to load training task:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load training task",
+		})
+	}
+
+	log.Println("ClearTrainingTask completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Training task cleared successfully",
+		Data:    task.ToResponse(),
+	})
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
@@ -8,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v3"
 	"gorm.io/gorm"
@@ -22,10 +24,12 @@ func NewUserController(db *gorm.DB) *UserController {
 }
 
 // Request structs
+// Partial updates, omitted fields are left unchanged
 type UpdateUserRequest struct {
-	FullName string `json:"fullName" validate:"omitempty,min=3,max=100" example:"John Doe"`
-	Email    string `json:"email" validate:"omitempty,email" example:"john@example.com"`
-	IsActive *bool  `json:"isActive" validate:"omitempty" example:"true"`
+	FullName *string `json:"fullName,omitempty" validate:"omitempty,min=3,max=100" example:"John Doe"`
+	Email    *string `json:"email,omitempty" validate:"omitempty,email" example:"john@example.com"`
+	Phone    *string `json:"phone,omitempty" example:"6281234567890"` // null or empty clears the phone
+	IsActive *bool   `json:"isActive,omitempty" example:"true"`
 }
 
 type UpdatePasswordRequest struct {
@@ -33,6 +37,19 @@ type UpdatePasswordRequest struct {
 	ConfirmNewPassword string `json:"confirmNewPassword" validate:"required,eqfield=NewPassword" example:"SecurePass123"`
 }
 
+type UpdateMeRequest struct {
+	FullName *string `json:"fullName,omitempty" validate:"omitempty,min=3,max=100" example:"John Doe"`
+	Email    *string `json:"email,omitempty" validate:"omitempty,email" example:"john@example.com"`
+	Phone    *string `json:"phone,omitempty" example:"6281234567890"` // null or empty clears the phone
+}
+
+type UpdateMyPasswordRequest struct {
+	CurrentPassword    string `json:"currentPassword" validate:"required" example:"OldPass123"`
+	NewPassword        string `json:"newPassword" validate:"required,min=8" example:"SecurePass123"`
+	ConfirmNewPassword string `json:"confirmNewPassword" validate:"required,eqfield=NewPassword" example:"SecurePass123"`
+	RefreshToken       string `json:"refreshToken,omitempty"` // mobile clients pass theirs to stay logged in on this device
+}
+
 type CreateUserRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50" example:"john_doe"`
 	Password string `json:"password" validate:"required,min=8" example:"SecurePass123"`
@@ -51,7 +68,8 @@ type RemoveRoleRequest struct {
 
 // GetUsers retrieves a paginated list of users with optional search and role filtering
 // @Summary Get Users
-// @Description Retrieve a paginated list of users with optional search and role filtering
+// @Description Retrieve a paginated list of users with optional search, role and team filtering.
+// @Description Coordinators who belong to a team only see the members of their teams.
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -60,6 +78,9 @@ type RemoveRoleRequest struct {
 // @Param limit query int false "Number of users per page" default(10)
 // @Param search query string false "Search term for username or full name"
 // @Param role query string false "Filter users by role name"
+// @Param teamId query int false "Filter users by team ID"
+// @Param present query bool false "Only users checked in today and not yet checked out"
+// @Param locationId query int false "With present, only users checked in at this location"
 // @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.UserResponse}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 401 {object} utils.ErrorResponse
@@ -86,6 +107,38 @@ func (uc *UserController) GetUsers(c fiber.Ctx) error {
 			Where("roles.role_name = ?", roleName)
 	}
 
+	// Filter by team if provided
+	teamID, _ := strconv.ParseUint(c.Query("teamId", "0"), 10, 32)
+	if teamID > 0 {
+		query = query.Where("users.id IN (?)", utils.TeamMembersQuery(uc.DB, uint(teamID)))
+	}
+
+	// Filter by presence if provided, e.g. to list the pickers currently in the warehouse
+	present := c.Query("present", "") == "true"
+	var locationID *uint
+	if present {
+		if parsed, err := strconv.ParseUint(c.Query("locationId", "0"), 10, 32); err == nil && parsed > 0 {
+			id := uint(parsed)
+			locationID = &id
+		}
+		query = query.Where("users.id IN (?)", utils.PresentUsersQuery(uc.DB, locationID))
+	}
+
+	// Coordinators with a team only see the members of their teams
+	currentUserID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	userRoles, _ := c.Locals("userRoles").([]string)
+	scopeTeamIDs, err := utils.CoordinatorTeamScope(uc.DB, uint(currentUserID), userRoles)
+	if err != nil {
+		log.Println("GetUsers - Failed to resolve team scope:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve users",
+		})
+	}
+	if scopeTeamIDs != nil {
+		query = query.Where("users.id IN (?)", utils.TeamMembersQuery(uc.DB, scopeTeamIDs...))
+	}
+
 	// Search condition if provided
 	search := strings.TrimSpace(c.Query("search", ""))
 	if search != "" {
@@ -119,6 +172,17 @@ func (uc *UserController) GetUsers(c fiber.Ctx) error {
 		filters = append(filters, "search: "+search)
 	}
 
+	if teamID > 0 {
+		filters = append(filters, fmt.Sprintf("team: %d", teamID))
+	}
+
+	if present {
+		filters = append(filters, "present: true")
+		if locationID != nil {
+			filters = append(filters, fmt.Sprintf("location: %d", *locationID))
+		}
+	}
+
 	if len(filters) > 0 {
 		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
 	}
@@ -174,7 +238,7 @@ func (uc *UserController) GetUser(c fiber.Ctx) error {
 
 // CreateUser creates a new user with optional role assignment
 // @Summary Create User
-// @Description Create a new user with optional role assignment
+// @Description Create a new user with an admin-chosen password and optional role assignment (developer only, use /api/users/invite to onboard users)
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -198,6 +262,9 @@ func (uc *UserController) CreateUser(c fiber.Ctx) error {
 		})
 	}
 
+	req.Username = utils.NormalizeUsername(req.Username)
+	req.Email = utils.NormalizeEmail(req.Email)
+
 	// Check for existing username or email
 	var existingUser models.User
 	if err := uc.DB.Preload("Roles").Where("username = ?", req.Username).Or("email = ?", req.Email).First(&existingUser).Error; err == nil {
@@ -316,7 +383,7 @@ func (uc *UserController) CreateUser(c fiber.Ctx) error {
 
 // UpdateUser updates user details
 // @Summary Update User
-// @Description Update user details
+// @Description Partially update user details, omitted fields are left unchanged (developer, superadmin and hrd only, users update their own profile through /api/me)
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -329,6 +396,7 @@ func (uc *UserController) CreateUser(c fiber.Ctx) error {
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/users/{id} [put]
+// @Router /api/users/{id} [patch]
 func (uc *UserController) UpdateUser(c fiber.Ctx) error {
 	log.Println("UpdateUser called")
 	// Parse id parameter
@@ -344,49 +412,36 @@ func (uc *UserController) UpdateUser(c fiber.Ctx) error {
 
 	// Binding request body
 	var req UpdateUserRequest
-	if err := c.Bind().JSON(&req); err != nil {
+	fields, err := utils.PresentFields(c.Body())
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Invalid request body",
 		})
 	}
-
-	// Users can only update their own profile unless they have developer/superadmin/hrd role
-	currUserID := c.Locals("userId").(string)
-	if id != currUserID {
-		if !utils.HasPermission(c, []string{"developer", "superadmin", "hrd"}) {
-			return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Insufficient permissions to update other user's profile",
-			})
-		}
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
 	}
 
-	// Update fields if provided
-	user.FullName = req.FullName
-	// If updating email, check for uniqueness
-	if req.Email != "" && req.Email != user.Email {
-		var existingUser models.User
-		if err := uc.DB.Where("email = ? AND id != ?", req.Email, id).First(&existingUser).Error; err == nil {
-			return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Email already in use",
-			})
-		}
-		user.Email = req.Email
+	// Only the fields sent are written so concurrent partial updates do not overwrite each other
+	updates, err := uc.profileUpdates(&user, fields, req.FullName, req.Email, req.Phone)
+	if err != nil {
+		return userUpdateErrorResponse(c, err)
 	}
-	// Only developer/superadmin/hrd can update IsActive
-	if req.IsActive != nil {
-		if !utils.HasPermission(c, []string{"developer", "superadmin", "hrd"}) {
-			return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+	if fields["isActive"] {
+		if req.IsActive == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
 				Success: false,
-				Error:   "Insufficient permissions to update user status",
+				Error:   "isActive cannot be null",
 			})
 		}
-		user.IsActive = *req.IsActive
+		updates["is_active"] = *req.IsActive
 	}
 
-	if err := uc.DB.Save(&user).Error; err != nil {
+	if err := uc.saveUserUpdates(user.ID, updates); err != nil {
 		log.Println("UpdateUser - Failed to update user:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -412,9 +467,71 @@ func (uc *UserController) UpdateUser(c fiber.Ctx) error {
 	})
 }
 
+// profileUpdates validates the profile fields present in a partial update and returns the columns to write.
+// Validation failures are returned as a *fiber.Error carrying the response status.
+func (uc *UserController) profileUpdates(user *models.User, fields map[string]bool, fullName, email, phone *string) (map[string]interface{}, error) {
+	updates := make(map[string]interface{})
+
+	if fields["fullName"] {
+		if fullName == nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "fullName cannot be null")
+		}
+		trimmed := strings.TrimSpace(*fullName)
+		if len(trimmed) < 3 || len(trimmed) > 100 {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Full name must be between 3 and 100 characters")
+		}
+		updates["full_name"] = trimmed
+	}
+
+	// If updating email, check for uniqueness
+	if fields["email"] {
+		if email == nil || strings.TrimSpace(*email) == "" {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Email cannot be empty")
+		}
+		normalized := utils.NormalizeEmail(*email)
+		if normalized != user.Email {
+			var existingUser models.User
+			if err := uc.DB.Where("email = ? AND id != ?", normalized, user.ID).First(&existingUser).Error; err == nil {
+				return nil, fiber.NewError(fiber.StatusConflict, "Email already in use")
+			}
+			updates["email"] = normalized
+		}
+	}
+
+	if fields["phone"] {
+		var trimmed string
+		if phone != nil {
+			trimmed = strings.TrimSpace(*phone)
+		}
+		updates["phone"] = trimmed
+	}
+
+	return updates, nil
+}
+
+// saveUserUpdates writes only the given columns of a user
+func (uc *UserController) saveUserUpdates(userID uint, updates map[string]interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	return uc.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error
+}
+
+// userUpdateErrorResponse writes the response of a failed profile validation
+func userUpdateErrorResponse(c fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		fiberErr = fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Status(fiberErr.Code).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   fiberErr.Message,
+	})
+}
+
 // UpdatePassword updates a user's password
 // @Summary Update Password
-// @Description Update a user's password
+// @Description Update a user's password (developer, superadmin and hrd only, users change their own password through /api/me/password)
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -449,17 +566,6 @@ func (uc *UserController) UpdatePassword(c fiber.Ctx) error {
 		})
 	}
 
-	// Users can only update their own password unless they have developer/superadmin/hrd role
-	currUserID := c.Locals("userId").(string)
-	if id != currUserID {
-		if !utils.HasPermission(c, []string{"developer", "superadmin", "hrd"}) {
-			return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
-				Success: false,
-				Error:   "Insufficient permissions to update other user's password",
-			})
-		}
-	}
-
 	// Check if new password and confirm password match
 	if req.NewPassword != req.ConfirmNewPassword {
 		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
@@ -617,13 +723,15 @@ func (uc *UserController) AssignRole(c fiber.Ctx) error {
 		})
 	}
 
-	// Assign role to user
-	newUserRole := models.UserRole{
-		UserID: user.ID,
-		RoleID: role.ID,
-	}
-
-	if err := uc.DB.Create(&newUserRole).Error; err != nil {
+	// Assign role to user together with its audit record
+	currUserID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	changedBy := uint(currUserID)
+	if err := uc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&models.UserRole{UserID: user.ID, RoleID: role.ID}).Error; err != nil {
+			return err
+		}
+		return utils.RecordRoleChange(tx, role, models.RoleAuditActionGrant, utils.RoleChangeSourceUser, &changedBy, user.ID)
+	}); err != nil {
 		log.Println("AssignRole - Failed to assign role to user:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -724,8 +832,15 @@ func (uc *UserController) RemoveRole(c fiber.Ctx) error {
 		})
 	}
 
-	// Remove role from user
-	if err := uc.DB.Delete(&models.UserRole{}, "user_id = ? AND role_id = ?", user.ID, role.ID).Error; err != nil {
+	// Remove role from user together with its audit record
+	currUserID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+	changedBy := uint(currUserID)
+	if err := uc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.UserRole{}, "user_id = ? AND role_id = ?", user.ID, role.ID).Error; err != nil {
+			return err
+		}
+		return utils.RecordRoleChange(tx, role, models.RoleAuditActionRevoke, utils.RoleChangeSourceUser, &changedBy, user.ID)
+	}); err != nil {
 		log.Println("RemoveRole - Failed to remove role from user:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -789,8 +904,8 @@ func (uc *UserController) GetSessions(c fiber.Ctx) error {
 		}
 	}
 
-	var sessions []models.Session
-	if err := uc.DB.Where("user_id = ?", user.ID).Find(&sessions).Error; err != nil {
+	sessionList, err := uc.userSessions(user.ID)
+	if err != nil {
 		log.Println("GetSessions - Failed to retrieve user sessions:", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
@@ -798,16 +913,256 @@ func (uc *UserController) GetSessions(c fiber.Ctx) error {
 		})
 	}
 
-	// Format response
+	log.Println("GetSessions completed successfully")
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "User sessions retrieved successfully",
+		Data:    sessionList,
+	})
+}
+
+// userSessions lists the active sessions of a user
+func (uc *UserController) userSessions(userID uint) ([]models.SessionResponse, error) {
+	var sessions []models.Session
+	if err := uc.DB.Where("user_id = ?", userID).Order("created_at DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
 	sessionList := make([]models.SessionResponse, len(sessions))
 	for i, session := range sessions {
 		sessionList[i] = *session.ToResponse()
 	}
+	return sessionList, nil
+}
 
-	log.Println("GetSessions completed successfully")
+// currentUser loads the user the access token was issued to
+func (uc *UserController) currentUser(c fiber.Ctx) (*models.User, error) {
+	var user models.User
+	if err := uc.DB.Preload("Roles").Where("id = ?", c.Locals("userId").(string)).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetMe retrieves the profile of the logged in user
+// @Summary Get My Profile
+// @Description Retrieve the profile of the logged in user, resolved from the access token
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=models.UserResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/me [get]
+func (uc *UserController) GetMe(c fiber.Ctx) error {
+	log.Println("GetMe called")
+	user, err := uc.currentUser(c)
+	if err != nil {
+		log.Println("GetMe - User not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+	}
+
+	log.Println("GetMe completed successfully")
 	return c.JSON(utils.SuccessResponse{
 		Success: true,
-		Message: "User sessions retrieved successfully",
+		Message: "Profile retrieved successfully",
+		Data:    user.ToResponse(),
+	})
+}
+
+// UpdateMe updates the profile of the logged in user
+// @Summary Update My Profile
+// @Description Partially update the full name, email or phone of the logged in user, omitted fields are left unchanged
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateMeRequest true "Updated profile details"
+// @Success 200 {object} utils.SuccessResponse{data=models.UserResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/me [put]
+// @Router /api/me [patch]
+func (uc *UserController) UpdateMe(c fiber.Ctx) error {
+	log.Println("UpdateMe called")
+	user, err := uc.currentUser(c)
+	if err != nil {
+		log.Println("UpdateMe - User not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+	}
+
+	// Binding request body
+	var req UpdateMeRequest
+	fields, err := utils.PresentFields(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	updates, err := uc.profileUpdates(user, fields, req.FullName, req.Email, req.Phone)
+	if err != nil {
+		return userUpdateErrorResponse(c, err)
+	}
+	if err := uc.saveUserUpdates(user.ID, updates); err != nil {
+		log.Println("UpdateMe - Failed to update profile:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update profile",
+		})
+	}
+
+	// Reload the data with fresh query
+	if user, err = uc.currentUser(c); err != nil {
+		log.Println("UpdateMe - Failed to load user:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load user",
+		})
+	}
+
+	log.Println("UpdateMe completed successfully")
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Profile updated successfully",
+		Data:    user.ToResponse(),
+	})
+}
+
+// UpdateMyPassword changes the password of the logged in user
+// @Summary Change My Password
+// @Description Change the password of the logged in user after confirming the current one.
+// @Description Every other session is logged out, the session of the refresh token cookie or the refreshToken field is kept.
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateMyPasswordRequest true "Current and new password"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/me/password [put]
+func (uc *UserController) UpdateMyPassword(c fiber.Ctx) error {
+	log.Println("UpdateMyPassword called")
+	user, err := uc.currentUser(c)
+	if err != nil {
+		log.Println("UpdateMyPassword - User not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User not found",
+		})
+	}
+
+	// Binding request body
+	var req UpdateMyPasswordRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if !utils.CheckPasswordHash(req.CurrentPassword, user.Password) {
+		log.Println("UpdateMyPassword - Wrong current password for userID:", user.ID)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Current password is incorrect",
+		})
+	}
+
+	if len(req.NewPassword) < 8 || req.NewPassword != req.ConfirmNewPassword {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "New password must be at least 8 characters and match the confirmation",
+		})
+	}
+
+	// Hash new password
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to hash password",
+		})
+	}
+
+	// Keep the session of this device, log out every other one
+	keepRefreshToken := c.Cookies("refresh_token")
+	if keepRefreshToken == "" {
+		keepRefreshToken = req.RefreshToken
+	}
+
+	if err := uc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("password", hashedPassword).Error; err != nil {
+			return err
+		}
+		sessions := tx.Where("user_id = ?", user.ID)
+		if keepRefreshToken != "" {
+			sessions = sessions.Where("refresh_token <> ?", keepRefreshToken)
+		}
+		return sessions.Delete(&models.Session{}).Error
+	}); err != nil {
+		log.Println("UpdateMyPassword - Failed to update password:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update password",
+		})
+	}
+
+	log.Println("UpdateMyPassword completed successfully")
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Password updated successfully, other sessions have been logged out",
+	})
+}
+
+// GetMySessions retrieves the active sessions of the logged in user
+// @Summary Get My Sessions
+// @Description Retrieve the active sessions of the logged in user, newest first
+// @Tags Me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} utils.SuccessResponse{data=[]models.SessionResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/me/sessions [get]
+func (uc *UserController) GetMySessions(c fiber.Ctx) error {
+	log.Println("GetMySessions called")
+	userID, _ := strconv.ParseUint(c.Locals("userId").(string), 10, 32)
+
+	sessionList, err := uc.userSessions(uint(userID))
+	if err != nil {
+		log.Println("GetMySessions - Failed to retrieve sessions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve sessions",
+		})
+	}
+
+	log.Println("GetMySessions completed successfully")
+	return c.JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Sessions retrieved successfully",
 		Data:    sessionList,
 	})
 }
@@ -858,26 +1213,32 @@ func (uc *UserController) RegisterUserFace(c fiber.Ctx) error {
 		})
 	}
 
-	// Validate mime type
-	if !strings.HasPrefix(file.Header.Get("Content-Type"), "image/") {
-		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+	// Save temp file
+	tmpPath, err := utils.CreateTempFile("face_*.jpg")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
-			Error:   "Invalid image file type",
+			Error:   "Failed to save image file",
 		})
 	}
+	defer os.Remove(tmpPath)
 
-	// Save temp file
-	tmpPath := fmt.Sprintf("tmp/face_%d.jpg", userID)
-	if err := c.SaveFile(file, tmpPath); err != nil {
+	// Validate image content and strip metadata
+	if err := utils.SaveSanitizedImage(file, tmpPath); err != nil {
+		if errors.Is(err, utils.ErrInvalidImage) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   "Failed to save image file",
 		})
 	}
-	defer os.Remove(tmpPath)
 
 	// Call deepface service to register face
-	if err := utils.SendToDeepFaceRegister(uint(userID), tmpPath); err != nil {
+	if err := utils.SendToDeepFaceRegister(c.Context(), uint(userID), tmpPath); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
 			Success: false,
 			Error:   fmt.Sprintf("Failed to register face with deepface service: %v", err),
@@ -916,3 +1277,129 @@ func (uc *UserController) RegisterUserFace(c fiber.Ctx) error {
 		Data:    userFace,
 	})
 }
+
+// GetUserActivity retrieves the recent actions of a user across modules
+// @Summary Get User Activity
+// @Description Retrieve the actions of a user across modules, latest first: orders changed, picked, canceled and held, shortages declared, QC done, outbounds, attendance check-ins and check-outs and complains attributed. Descriptions are in Indonesian or English (lang query or Accept-Language header). Users can view their own activity, developer, superadmin, hrd and coordinator can view anyone's.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of activities per page" default(20)
+// @Param type query string false "Filter by activity types, comma separated (e.g. qc_ribbon,outbound)"
+// @Param startDate query string false "Filter by date from (YYYY-MM-DD format)"
+// @Param endDate query string false "Filter by date to (YYYY-MM-DD format)"
+// @Param lang query string false "Description language (id, en)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]utils.UserActivity}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/users/{id}/activity [get]
+func (uc *UserController) GetUserActivity(c fiber.Ctx) error {
+	log.Println("GetUserActivity called")
+	// Parse id parameter
+	id := c.Params("id")
+	var user models.User
+	if err := uc.DB.Where("id = ?", id).First(&user).Error; err != nil {
+		log.Println("GetUserActivity - User not found:", err)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "User with id " + id + " not found.",
+		})
+	}
+
+	// Users can only view their own activity unless they review performance
+	currUserID := c.Locals("userId").(string)
+	if id != currUserID {
+		if !utils.HasPermission(c, []string{"developer", "superadmin", "hrd", "coordinator"}) {
+			return c.Status(fiber.StatusForbidden).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Insufficient permissions to view other user's activity",
+			})
+		}
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	// Parse filter parameters
+	filter := utils.UserActivityFilter{
+		UserID: user.ID,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+	}
+	var filters []string
+	if types := strings.TrimSpace(c.Query("type", "")); types != "" {
+		for _, activityType := range strings.Split(types, ",") {
+			activityType = strings.TrimSpace(activityType)
+			if !utils.IsUserActivityType(activityType) {
+				return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+					Success: false,
+					Error:   "Invalid activity type " + activityType + ". Use one of: " + strings.Join(utils.UserActivityTypes(), ", "),
+				})
+			}
+			filter.Types = append(filter.Types, activityType)
+		}
+		filters = append(filters, "type: "+strings.Join(filter.Types, ","))
+	}
+	if startDate := c.Query("startDate", ""); startDate != "" {
+		parsedStartDate, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format. Use YYYY-MM-DD.",
+			})
+		}
+		filter.Start = &parsedStartDate
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate := c.Query("endDate", ""); endDate != "" {
+		parsedEndDate, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format. Use YYYY-MM-DD.",
+			})
+		}
+		endOfDay := parsedEndDate.AddDate(0, 0, 1)
+		filter.End = &endOfDay
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	activities, total, err := utils.ListUserActivity(uc.DB.WithContext(c.Context()), filter, utils.RequestLanguage(c))
+	if err != nil {
+		log.Println("GetUserActivity - Failed to retrieve user activity:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve user activity",
+		})
+	}
+
+	message := "Activity of " + user.Username + " retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetUserActivity completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    activities,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
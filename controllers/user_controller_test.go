@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"encoding/json"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/testutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Partial user updates write the fields sent, keep omitted ones and clear the phone sent as null
+func TestUpdateUserPartial(t *testing.T) {
+	tx := testutil.Begin(t, testDB)
+	f := testutil.NewFactory(t, tx)
+	admin := f.User("superadmin")
+	taken := f.User("picker")
+	userController := NewUserController(tx)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantError  string
+		check      func(t *testing.T, before, after models.User)
+	}{
+		{
+			name: "omitted fields are kept", body: `{"fullName":"  Jane Doe  "}`, wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, before, after models.User) {
+				if after.FullName != "Jane Doe" {
+					t.Errorf("full name = %q, want Jane Doe", after.FullName)
+				}
+				if after.Email != before.Email || after.Phone != before.Phone || after.IsActive != before.IsActive {
+					t.Errorf("omitted fields changed: %+v, was %+v", after, before)
+				}
+			},
+		},
+		{
+			name: "phone sent as null is cleared", body: `{"phone":null}`, wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, before, after models.User) {
+				if after.Phone != "" {
+					t.Errorf("phone = %q, want cleared", after.Phone)
+				}
+				if after.FullName != before.FullName {
+					t.Errorf("full name = %q, want %q", after.FullName, before.FullName)
+				}
+			},
+		},
+		{
+			name: "deactivate without touching the profile", body: `{"isActive":false}`, wantStatus: fiber.StatusOK,
+			check: func(t *testing.T, before, after models.User) {
+				if after.IsActive {
+					t.Error("user still active")
+				}
+				if after.FullName != before.FullName || after.Phone != before.Phone {
+					t.Errorf("profile changed: %+v, was %+v", after, before)
+				}
+			},
+		},
+		{name: "full name cannot be null", body: `{"fullName":null}`, wantStatus: fiber.StatusBadRequest, wantError: "fullName cannot be null"},
+		{name: "active flag cannot be null", body: `{"isActive":null}`, wantStatus: fiber.StatusBadRequest, wantError: "isActive cannot be null"},
+		{name: "email cannot be emptied", body: `{"email":""}`, wantStatus: fiber.StatusBadRequest, wantError: "Email cannot be empty"},
+		{name: "email of another user", body: `{"email":"` + strings.ToUpper(taken.Email) + `"}`, wantStatus: fiber.StatusConflict, wantError: "Email already in use"},
+		{name: "full name too short", body: `{"fullName":"Jo"}`, wantStatus: fiber.StatusBadRequest, wantError: "Full name must be between 3 and 100 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := f.User("picker")
+			if err := tx.Model(&before).Update("phone", "6281234567890").Error; err != nil {
+				t.Fatalf("failed to set phone: %v", err)
+			}
+			before.Phone = "6281234567890"
+
+			path := "/api/users/" + strconv.FormatUint(uint64(before.ID), 10)
+			resp, result := callHandler(t, userController.UpdateUser, testRequest{
+				Method: fiber.MethodPatch, Route: "/api/users/:id", Path: path,
+				User: admin, Roles: []string{"superadmin"},
+				Body: json.RawMessage(tt.body),
+			})
+			expectStatus(t, tt.name, resp, result, tt.wantStatus)
+			if result.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", result.Error, tt.wantError)
+			}
+
+			var after models.User
+			if err := tx.First(&after, before.ID).Error; err != nil {
+				t.Fatalf("failed to reload user: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, before, after)
+			} else if after.FullName != before.FullName || after.Email != before.Email || after.Phone != before.Phone || after.IsActive != before.IsActive {
+				t.Errorf("rejected update changed the user: %+v, was %+v", after, before)
+			}
+		})
+	}
+}
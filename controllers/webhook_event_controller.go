@@ -0,0 +1,332 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type WebhookEventController struct {
+	DB      *gorm.DB
+	Replays map[string]fiber.Handler // handler of each webhook endpoint, used to reprocess its events
+}
+
+func NewWebhookEventController(db *gorm.DB) *WebhookEventController {
+	return &WebhookEventController{DB: db, Replays: map[string]fiber.Handler{}}
+}
+
+// RegisterReplay sets the handler events logged on the webhook endpoint are reprocessed with
+func (wec *WebhookEventController) RegisterReplay(endpoint string, handler fiber.Handler) {
+	wec.Replays[endpoint] = handler
+}
+
+// GetWebhookEvents retrieves the inbound webhook log
+// @Summary Get Webhook Events
+// @Description Retrieve webhooks received from Ginee, marketplaces and 3PLs with their signature check and handler outcome, with pagination, newest first
+// @Tags Webhook Events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of events per page" default(10)
+// @Param source query string false "Filter by source (ginee, shopee, tokopedia, 3pl)"
+// @Param endpoint query string false "Filter by endpoint (ginee_status, complain_dispute, handover_ack)"
+// @Param status query string false "Filter by status (received, processed, failed, rejected)"
+// @Param startDate query string false "Filter from date (YYYY-MM-DD)"
+// @Param endDate query string false "Filter until date (YYYY-MM-DD)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.InboundWebhookEventResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/webhook-events [get]
+func (wec *WebhookEventController) GetWebhookEvents(c fiber.Ctx) error {
+	log.Println("GetWebhookEvents called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var events []models.InboundWebhookEvent
+
+	// Build base query
+	query := wec.DB.Model(&models.InboundWebhookEvent{}).Order("created_at DESC, id DESC").Preload("ReprocessUser")
+
+	var filters []string
+
+	// Filter by source if provided
+	source := strings.ToLower(strings.TrimSpace(c.Query("source", "")))
+	if source != "" {
+		query = query.Where("source = ?", source)
+		filters = append(filters, "source: "+source)
+	}
+
+	// Filter by endpoint if provided
+	endpoint := strings.TrimSpace(c.Query("endpoint", ""))
+	if endpoint != "" {
+		query = query.Where("endpoint = ?", endpoint)
+		filters = append(filters, "endpoint: "+endpoint)
+	}
+
+	// Filter by status if provided
+	status := strings.TrimSpace(c.Query("status", ""))
+	if status != "" {
+		statuses := []string{models.WebhookEventReceived, models.WebhookEventProcessed, models.WebhookEventFailed, models.WebhookEventRejected}
+		if !slices.Contains(statuses, status) {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid status " + status,
+			})
+		}
+		query = query.Where("status = ?", status)
+		filters = append(filters, "status: "+status)
+	}
+
+	// Date range filter if provided
+	startDate := c.Query("startDate", "")
+	endDate := c.Query("endDate", "")
+	if startDate != "" {
+		parsedStartDate, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid startDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at >= ?", parsedStartDate)
+		filters = append(filters, "startDate: "+startDate)
+	}
+	if endDate != "" {
+		parsedEndDate, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Invalid endDate format, expected YYYY-MM-DD",
+			})
+		}
+		query = query.Where("created_at < ?", parsedEndDate.AddDate(0, 0, 1))
+		filters = append(filters, "endDate: "+endDate)
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&events).Error; err != nil {
+		log.Println("GetWebhookEvents - Failed to retrieve webhook events:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve webhook events",
+		})
+	}
+
+	// Format response
+	eventList := make([]models.InboundWebhookEventResponse, len(events))
+	for i := range events {
+		eventList[i] = *events[i].ToResponse(false)
+	}
+
+	// Build success message
+	message := "Webhook events retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetWebhookEvents completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    eventList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetWebhookEvent retrieves a logged webhook with its payload
+// @Summary Get Webhook Event
+// @Description Retrieve a logged webhook with its headers, raw payload and the response its handler gave
+// @Tags Webhook Events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook event ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.InboundWebhookEventResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/webhook-events/{id} [get]
+func (wec *WebhookEventController) GetWebhookEvent(c fiber.Ctx) error {
+	log.Println("GetWebhookEvent called")
+	id := c.Params("id")
+
+	var event models.InboundWebhookEvent
+	if err := wec.DB.Preload("ReprocessUser").Where("id = ?", id).First(&event).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook event with id " + id + " not found",
+		})
+	}
+
+	log.Println("GetWebhookEvent completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Webhook event retrieved successfully",
+		Data:    event.ToResponse(true),
+	})
+}
+
+// ReprocessWebhookEvent runs a logged webhook through its handler again
+// @Summary Reprocess Webhook Event
+// @Description Run the stored payload of a logged webhook through its endpoint handler again, as the integration user that sent it, e.g. after fixing a payload mapping issue. Signature and replay checks are not repeated, rejected webhooks cannot be reprocessed. Webhooks already processed are only run again with force, their handler would apply them twice.
+// @Tags Webhook Events
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook event ID"
+// @Param force query bool false "Reprocess a webhook that was already processed" default(false)
+// @Success 200 {object} utils.SuccessResponse{data=models.InboundWebhookEventResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/webhook-events/{id}/reprocess [post]
+func (wec *WebhookEventController) ReprocessWebhookEvent(c fiber.Ctx) error {
+	log.Println("ReprocessWebhookEvent called")
+	id := c.Params("id")
+
+	// Parse force parameter, processed webhooks are not run twice by accident
+	force, err := strconv.ParseBool(c.Query("force", "false"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid force value. Use true or false.",
+		})
+	}
+
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	var event models.InboundWebhookEvent
+	if err := wec.DB.Where("id = ?", id).First(&event).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook event with id " + id + " not found",
+		})
+	}
+
+	// Rejected webhooks failed authentication, their payload is not trusted
+	if event.Status == models.WebhookEventRejected {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Rejected webhooks cannot be reprocessed",
+		})
+	}
+
+	// Processed webhooks already took effect, running them again would apply them twice
+	if event.Status == models.WebhookEventProcessed && !force {
+		return c.Status(fiber.StatusConflict).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook event was already processed, reprocess it with force to run it again",
+		})
+	}
+
+	handler, ok := wec.Replays[event.Endpoint]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook endpoint " + event.Endpoint + " cannot be reprocessed",
+		})
+	}
+
+	// Handlers act on behalf of the owner of the API key the webhook was sent with
+	if event.UserID == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook event has no integration user to reprocess it as",
+		})
+	}
+	var sender models.User
+	if err := wec.DB.Preload("Roles").Where("id = ?", *event.UserID).First(&sender).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Integration user of the webhook event not found",
+		})
+	}
+	senderRoles := make([]string, len(sender.Roles))
+	for i, role := range sender.Roles {
+		senderRoles[i] = role.RoleName
+	}
+
+	// Replay the stored request as the integration, restoring the caller afterwards
+	callerLocals := map[string]interface{}{}
+	for _, key := range []string{"userId", "username", "userRoles", "apiKeyId"} {
+		callerLocals[key] = c.Locals(key)
+	}
+
+	c.Request().SetBody([]byte(event.Payload))
+	c.Request().Header.SetContentType(event.ContentType)
+	utils.SetWebhookReplayParams(c, event.ParamMap())
+	c.Locals("userId", strconv.FormatUint(uint64(sender.ID), 10))
+	c.Locals("username", sender.Username)
+	c.Locals("userRoles", senderRoles)
+	if event.APIKeyID != nil {
+		c.Locals("apiKeyId", *event.APIKeyID)
+	}
+
+	handlerErr := handler(c)
+	responseStatus, responseBody, eventStatus := utils.WebhookOutcome(c, handlerErr)
+
+	for key, value := range callerLocals {
+		c.Locals(key, value)
+	}
+	c.Response().ResetBody()
+
+	now := time.Now()
+	reprocessedBy := uint(userID)
+	if err := wec.DB.Model(&event).Updates(map[string]interface{}{
+		"status":              eventStatus,
+		"response_status":     responseStatus,
+		"response_body":       responseBody,
+		"reprocess_count":     gorm.Expr("reprocess_count + 1"),
+		"last_reprocessed_at": now,
+		"last_reprocessed_by": reprocessedBy,
+	}).Error; err != nil {
+		log.Println("ReprocessWebhookEvent - Failed to record outcome:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to record webhook event outcome",
+		})
+	}
+
+	wec.DB.Preload("ReprocessUser").Where("id = ?", event.ID).First(&event)
+
+	message := "Webhook event reprocessed successfully"
+	if eventStatus == models.WebhookEventFailed {
+		message = fmt.Sprintf("Webhook event reprocessed, handler answered with status %d", responseStatus)
+	}
+
+	log.Println("ReprocessWebhookEvent completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    event.ToResponse(true),
+	})
+}
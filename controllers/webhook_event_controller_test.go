@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/testutil"
+	"strconv"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Processed webhooks already took effect, they are only run through their handler again when forced
+func TestReprocessWebhookEvent(t *testing.T) {
+	tx := testutil.Begin(t, testDB)
+	f := testutil.NewFactory(t, tx)
+	developer := f.User("developer")
+	integration := f.User("superadmin")
+
+	handled := 0
+	webhookEventController := NewWebhookEventController(tx)
+	webhookEventController.RegisterReplay("ginee_status", func(c fiber.Ctx) error {
+		handled++
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true})
+	})
+
+	tests := []struct {
+		name        string
+		status      string
+		query       string
+		wantStatus  int
+		wantHandled bool
+	}{
+		{"failed webhook is reprocessed", models.WebhookEventFailed, "", fiber.StatusOK, true},
+		{"processed webhook is refused", models.WebhookEventProcessed, "", fiber.StatusConflict, false},
+		{"processed webhook is reprocessed with force", models.WebhookEventProcessed, "?force=true", fiber.StatusOK, true},
+		{"rejected webhook is refused even with force", models.WebhookEventRejected, "?force=true", fiber.StatusBadRequest, false},
+		{"invalid force value", models.WebhookEventProcessed, "?force=maybe", fiber.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := models.InboundWebhookEvent{
+				Source:          "ginee",
+				Endpoint:        "ginee_status",
+				Method:          fiber.MethodPost,
+				ContentType:     fiber.MIMEApplicationJSON,
+				Payload:         `{"orderId":"TEST"}`,
+				SignatureStatus: models.WebhookSignatureVerified,
+				Status:          tt.status,
+				UserID:          &integration.ID,
+			}
+			if err := tx.Create(&event).Error; err != nil {
+				t.Fatalf("failed to create webhook event: %v", err)
+			}
+
+			handledBefore := handled
+			resp, result := callHandler(t, webhookEventController.ReprocessWebhookEvent, testRequest{
+				Method: fiber.MethodPost, Route: "/api/webhook-events/:id/reprocess",
+				Path: "/api/webhook-events/" + strconv.FormatUint(uint64(event.ID), 10) + "/reprocess" + tt.query,
+				User: developer, Roles: []string{"developer"},
+			})
+			expectStatus(t, tt.name, resp, result, tt.wantStatus)
+
+			if got := handled > handledBefore; got != tt.wantHandled {
+				t.Errorf("handler ran = %v, want %v", got, tt.wantHandled)
+			}
+			var after models.InboundWebhookEvent
+			if err := tx.First(&after, event.ID).Error; err != nil {
+				t.Fatalf("failed to reload webhook event: %v", err)
+			}
+			wantCount := 0
+			if tt.wantHandled {
+				wantCount = 1
+			}
+			if after.ReprocessCount != wantCount {
+				t.Errorf("reprocess count = %d, want %d", after.ReprocessCount, wantCount)
+			}
+		})
+	}
+}
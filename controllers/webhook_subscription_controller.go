@@ -0,0 +1,449 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type WebhookSubscriptionController struct {
+	DB *gorm.DB
+}
+
+func NewWebhookSubscriptionController(db *gorm.DB) *WebhookSubscriptionController {
+	return &WebhookSubscriptionController{DB: db}
+}
+
+// Unique request structs
+type WebhookSubscriptionRequest struct {
+	Name       string   `json:"name" example:"ERP QC events"`
+	URL        string   `json:"url" example:"https://erp.example.com/webhooks/livo"`
+	EventTypes []string `json:"eventTypes" example:"qc_completed"` // empty subscribes to every event type
+	Channels   []string `json:"channels" example:"Shopee"`         // channel names as on the orders, empty matches every channel
+	Stores     []string `json:"stores" example:"Livo Official"`    // store names as on the orders, empty matches every store
+	Statuses   []string `json:"statuses" example:"qc_completed"`   // processing statuses, empty matches every status
+	Active     *bool    `json:"active" example:"true"`             // empty keeps subscriptions active
+}
+
+// Unique response structs
+// WebhookSubscriptionSecretResponse represents a webhook subscription together with its signing secret, only
+// returned on creation
+type WebhookSubscriptionSecretResponse struct {
+	Secret       string                              `json:"secret"`
+	Subscription *models.WebhookSubscriptionResponse `json:"subscription"`
+}
+
+// normalizeSubscriptionList trims and deduplicates the values of a subscription filter, validating them when a
+// check is given
+func normalizeSubscriptionList(values []string, valid func(string) bool) (string, string) {
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[strings.ToLower(value)] {
+			continue
+		}
+		if valid != nil && !valid(value) {
+			return "", value
+		}
+		seen[strings.ToLower(value)] = true
+		normalized = append(normalized, value)
+	}
+	return strings.Join(normalized, ","), ""
+}
+
+// validateWebhookSubscriptionRequest normalizes the request onto the subscription and returns a client error
+// message when it is invalid
+func validateWebhookSubscriptionRequest(req *WebhookSubscriptionRequest, subscription *models.WebhookSubscription) string {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return "Name is required"
+	}
+	if len(name) > 100 {
+		return "Name must be at most 100 characters"
+	}
+	target, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return "Invalid url, use an absolute http or https URL"
+	}
+
+	eventTypes, invalid := normalizeSubscriptionList(req.EventTypes, models.IsValidOrderEventType)
+	if invalid != "" {
+		return "Invalid event type " + invalid + ". Use order_created, order_updated, order_deleted or a processing status."
+	}
+	statuses, invalid := normalizeSubscriptionList(req.Statuses, models.IsValidProcessingStatus)
+	if invalid != "" {
+		return "Invalid status " + invalid
+	}
+	channels, _ := normalizeSubscriptionList(req.Channels, nil)
+	stores, _ := normalizeSubscriptionList(req.Stores, nil)
+
+	subscription.Name = name
+	subscription.URL = target.String()
+	subscription.EventTypes = eventTypes
+	subscription.Channels = channels
+	subscription.Stores = stores
+	subscription.Statuses = statuses
+	subscription.Active = req.Active == nil || *req.Active
+	return ""
+}
+
+// GetWebhookSubscriptions retrieves the webhook subscriptions
+// @Summary Get Webhook Subscriptions
+// @Description Retrieve the subscriptions receiving order events by webhook, with their filters and delivery state
+// @Tags Webhook Subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of subscriptions per page" default(10)
+// @Param active query bool false "Filter by active state"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.WebhookSubscriptionResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/webhook-subscriptions [get]
+func (wsc *WebhookSubscriptionController) GetWebhookSubscriptions(c fiber.Ctx) error {
+	log.Println("GetWebhookSubscriptions called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	var subscriptions []models.WebhookSubscription
+
+	// Build base query
+	query := wsc.DB.WithContext(c.Context()).Model(&models.WebhookSubscription{}).Preload("CreateUser").Order("name ASC, id ASC")
+
+	// Active filter if provided
+	active := c.Query("active", "")
+	if active != "" {
+		query = query.Where("active = ?", active == "true")
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&subscriptions).Error; err != nil {
+		log.Println("GetWebhookSubscriptions - Failed to retrieve webhook subscriptions:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve webhook subscriptions",
+		})
+	}
+
+	// Format response
+	subscriptionList := make([]models.WebhookSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		subscriptionList[i] = *subscription.ToResponse()
+	}
+
+	// Build success message
+	message := "Webhook subscriptions retrieved successfully"
+	if active != "" {
+		message += fmt.Sprintf(" (filtered by active: %s)", active)
+	}
+
+	log.Println("GetWebhookSubscriptions completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    subscriptionList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
+
+// GetWebhookSubscription retrieves a webhook subscription
+// @Summary Get Webhook Subscription
+// @Description Retrieve a webhook subscription with its filters and delivery state
+// @Tags Webhook Subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook subscription ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.WebhookSubscriptionResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/webhook-subscriptions/{id} [get]
+func (wsc *WebhookSubscriptionController) GetWebhookSubscription(c fiber.Ctx) error {
+	log.Println("GetWebhookSubscription called")
+	// Parse id parameter
+	id := c.Params("id")
+	var subscription models.WebhookSubscription
+	if err := wsc.DB.WithContext(c.Context()).Preload("CreateUser").Where("id = ?", id).First(&subscription).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook subscription with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetWebhookSubscription completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Webhook subscription retrieved successfully",
+		Data:    subscription.ToResponse(),
+	})
+}
+
+// CreateWebhookSubscription subscribes a URL to order events
+// @Summary Create Webhook Subscription
+// @Description Subscribe a URL to the order events matching the filters, e.g. only qc_completed events of two stores. Event types are order_created, order_updated, order_deleted and the processing status an order moved to; channels, stores and statuses filter on the order, empty filters match everything. Events are posted as JSON in order, from the time of creation, signed with the returned secret as an HMAC-SHA256 of "<X-Webhook-Timestamp>.<raw body>" in X-Webhook-Signature. Failed deliveries are retried with backoff, deliveries are at least once so subscribers dedupe by cursor. The secret is only returned once
+// @Tags Webhook Subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body WebhookSubscriptionRequest true "Webhook subscription"
+// @Success 201 {object} utils.SuccessResponse{data=WebhookSubscriptionSecretResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/webhook-subscriptions [post]
+func (wsc *WebhookSubscriptionController) CreateWebhookSubscription(c fiber.Ctx) error {
+	log.Println("CreateWebhookSubscription called")
+	// Get current logged in user from context
+	userIDStr := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid user ID",
+		})
+	}
+
+	// Binding request body
+	var req WebhookSubscriptionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateWebhookSubscription - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	subscription := models.WebhookSubscription{CreatedBy: uint(userID)}
+	if message := validateWebhookSubscriptionRequest(&req, &subscription); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	secret, _, err := utils.GenerateSecureToken()
+	if err != nil {
+		log.Println("CreateWebhookSubscription - Failed to generate secret:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create webhook subscription",
+		})
+	}
+	subscription.Secret = secret
+
+	// Deliver the events recorded from now on
+	if err := wsc.DB.WithContext(c.Context()).Model(&models.ChangeEvent{}).Select("COALESCE(MAX(id), 0)").Scan(&subscription.Cursor).Error; err != nil {
+		log.Println("CreateWebhookSubscription - Failed to read change feed cursor:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create webhook subscription",
+		})
+	}
+
+	if err := wsc.DB.WithContext(c.Context()).Create(&subscription).Error; err != nil {
+		log.Println("CreateWebhookSubscription - Failed to create webhook subscription:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create webhook subscription",
+		})
+	}
+
+	// Reload the subscription with its creator for response
+	if err := wsc.DB.WithContext(c.Context()).Preload("CreateUser").First(&subscription, subscription.ID).Error; err != nil {
+		log.Println("CreateWebhookSubscription - Failed to load webhook subscription:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load webhook subscription",
+		})
+	}
+
+	log.Println("CreateWebhookSubscription completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Webhook subscription created successfully. Store the secret now, it will not be shown again.",
+		Data: WebhookSubscriptionSecretResponse{
+			Secret:       secret,
+			Subscription: subscription.ToResponse(),
+		},
+	})
+}
+
+// UpdateWebhookSubscription replaces the URL and filters of a webhook subscription
+// @Summary Update Webhook Subscription
+// @Description Replace the name, URL, filters and active state of a webhook subscription. The secret and cursor are kept, a paused subscription resumes from the events it has not handled yet
+// @Tags Webhook Subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook subscription ID"
+// @Param request body WebhookSubscriptionRequest true "Webhook subscription"
+// @Success 200 {object} utils.SuccessResponse{data=models.WebhookSubscriptionResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/webhook-subscriptions/{id} [put]
+func (wsc *WebhookSubscriptionController) UpdateWebhookSubscription(c fiber.Ctx) error {
+	log.Println("UpdateWebhookSubscription called")
+	// Parse id parameter
+	id := c.Params("id")
+	var subscription models.WebhookSubscription
+	if err := wsc.DB.WithContext(c.Context()).Where("id = ?", id).First(&subscription).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook subscription with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req WebhookSubscriptionRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateWebhookSubscription - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+	if message := validateWebhookSubscriptionRequest(&req, &subscription); message != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   message,
+		})
+	}
+
+	// The new settings are tried at once instead of waiting for the retry of a failed delivery
+	updates := map[string]interface{}{
+		"name":            subscription.Name,
+		"url":             subscription.URL,
+		"event_types":     subscription.EventTypes,
+		"channels":        subscription.Channels,
+		"stores":          subscription.Stores,
+		"statuses":        subscription.Statuses,
+		"active":          subscription.Active,
+		"next_attempt_at": nil,
+	}
+	if err := wsc.DB.WithContext(c.Context()).Model(&subscription).Updates(updates).Error; err != nil {
+		log.Println("UpdateWebhookSubscription - Failed to update webhook subscription:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update webhook subscription",
+		})
+	}
+
+	// Reload the subscription with its creator for response
+	if err := wsc.DB.WithContext(c.Context()).Preload("CreateUser").First(&subscription, subscription.ID).Error; err != nil {
+		log.Println("UpdateWebhookSubscription - Failed to load webhook subscription:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to load webhook subscription",
+		})
+	}
+
+	log.Println("UpdateWebhookSubscription completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Webhook subscription updated successfully",
+		Data:    subscription.ToResponse(),
+	})
+}
+
+// DeleteWebhookSubscription deletes a webhook subscription
+// @Summary Delete Webhook Subscription
+// @Description Delete a webhook subscription and stop its deliveries
+// @Tags Webhook Subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook subscription ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/webhook-subscriptions/{id} [delete]
+func (wsc *WebhookSubscriptionController) DeleteWebhookSubscription(c fiber.Ctx) error {
+	log.Println("DeleteWebhookSubscription called")
+	// Parse id parameter
+	id := c.Params("id")
+	var subscription models.WebhookSubscription
+	if err := wsc.DB.WithContext(c.Context()).Where("id = ?", id).First(&subscription).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook subscription with id " + id + " not found.",
+		})
+	}
+
+	if err := wsc.DB.WithContext(c.Context()).Delete(&subscription).Error; err != nil {
+		log.Println("DeleteWebhookSubscription - Failed to delete webhook subscription:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete webhook subscription",
+		})
+	}
+
+	log.Println("DeleteWebhookSubscription completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Webhook subscription deleted successfully",
+	})
+}
+
+// TestWebhookSubscription sends a sample order event to a webhook subscription
+// @Summary Test Webhook Subscription
+// @Description Post a sample order event to the URL of the subscription, signed with its secret like real deliveries. The sample carries test true, the first subscribed event type and the first channel, store and status of the filters. The cursor and delivery state of the subscription are left unchanged
+// @Tags Webhook Subscriptions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Webhook subscription ID"
+// @Success 200 {object} utils.SuccessResponse{data=utils.WebhookDeliveryResult}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 502 {object} utils.ErrorResponse
+// @Router /api/webhook-subscriptions/{id}/test [post]
+func (wsc *WebhookSubscriptionController) TestWebhookSubscription(c fiber.Ctx) error {
+	log.Println("TestWebhookSubscription called")
+	// Parse id parameter
+	id := c.Params("id")
+	var subscription models.WebhookSubscription
+	if err := wsc.DB.WithContext(c.Context()).Where("id = ?", id).First(&subscription).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Webhook subscription with id " + id + " not found.",
+		})
+	}
+
+	event := utils.SampleOrderEvent(&subscription)
+	result, err := utils.DeliverOrderEvent(c.Context(), &subscription, event)
+	if err != nil {
+		log.Println("TestWebhookSubscription - Test delivery failed:", err)
+		return c.Status(fiber.StatusBadGateway).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Test delivery failed: " + err.Error(),
+		})
+	}
+
+	log.Println("TestWebhookSubscription completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: fmt.Sprintf("Sample %s event delivered to %s", event.Event, subscription.URL),
+		Data:    result,
+	})
+}
@@ -0,0 +1,578 @@
+package controllers
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+type ZoneController struct {
+	DB *gorm.DB
+}
+
+func NewZoneController(db *gorm.DB) *ZoneController {
+	return &ZoneController{DB: db}
+}
+
+// Request structs
+type CreateZoneRequest struct {
+	ZoneCode         string   `json:"zoneCode" validate:"required,min=1,max=20" example:"A"`
+	Name             string   `json:"name" validate:"required,max=100" example:"Fast movers"`
+	LocationPrefixes []string `json:"locationPrefixes" example:"A-,B-01"`
+	SortOrder        int      `json:"sortOrder" example:"1"`
+}
+
+type UpdateZoneRequest struct {
+	ZoneCode         string   `json:"zoneCode" validate:"required,min=1,max=20" example:"A"`
+	Name             string   `json:"name" validate:"required,max=100" example:"Fast movers"`
+	LocationPrefixes []string `json:"locationPrefixes" example:"A-,B-01"`
+	SortOrder        int      `json:"sortOrder" example:"1"`
+}
+
+// ZoneHeatmapRow is the picks made in a zone per hour of the day, index 0 is 00:00-00:59
+type ZoneHeatmapRow struct {
+	ZoneID     *uint   `json:"zoneId"`
+	ZoneCode   string  `json:"zoneCode"`
+	ZoneName   string  `json:"zoneName"`
+	Picks      [24]int `json:"picks"`
+	Units      [24]int `json:"units"`
+	TotalPicks int     `json:"totalPicks"`
+	TotalUnits int     `json:"totalUnits"`
+}
+
+// ZoneHeatmapResponse is the picks per zone per hour of the day over a date range
+type ZoneHeatmapResponse struct {
+	StartDate string           `json:"startDate"`
+	EndDate   string           `json:"endDate"`
+	Zones     []ZoneHeatmapRow `json:"zones"`
+}
+
+// unzonedCode labels the heatmap row of picks from rack locations outside every zone
+const unzonedCode = "UNZONED"
+
+// validateZoneRequest normalizes the zone fields and checks no prefix is already claimed by another zone
+func (zc *ZoneController) validateZoneRequest(zoneCode, name *string, prefixes []string, excludeID uint) (string, error) {
+	*zoneCode = strings.ToUpper(strings.TrimSpace(*zoneCode))
+	*name = strings.TrimSpace(*name)
+	if *zoneCode == "" || len(*zoneCode) > 20 {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Zone code is required and must be at most 20 characters")
+	}
+	if *zoneCode == unzonedCode {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Zone code "+unzonedCode+" is reserved")
+	}
+	if *name == "" || len(*name) > 100 {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Name is required and must be at most 100 characters")
+	}
+
+	// Normalize prefixes to uppercase, dropping blanks and repeats
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, prefix := range prefixes {
+		prefix = strings.ToUpper(strings.TrimSpace(prefix))
+		if prefix == "" || seen[prefix] {
+			continue
+		}
+		if strings.Contains(prefix, ",") {
+			return "", fiber.NewError(fiber.StatusBadRequest, "Location prefixes cannot contain commas")
+		}
+		seen[prefix] = true
+		normalized = append(normalized, prefix)
+	}
+
+	// A prefix belongs to a single zone, otherwise its rack locations would be ambiguous
+	var zones []models.Zone
+	if err := zc.DB.Where("id != ?", excludeID).Find(&zones).Error; err != nil {
+		return "", err
+	}
+	for _, zone := range zones {
+		if zone.ZoneCode == *zoneCode {
+			return "", fiber.NewError(fiber.StatusConflict, "Zone with code "+*zoneCode+" already exists.")
+		}
+		for _, prefix := range zone.PrefixList() {
+			if seen[strings.ToUpper(prefix)] {
+				return "", fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Location prefix %s already belongs to zone %s", prefix, zone.ZoneCode))
+			}
+		}
+	}
+
+	return strings.Join(normalized, ","), nil
+}
+
+// zoneErrorResponse writes the response of a zone validation error
+func zoneErrorResponse(c fiber.Ctx, err error) error {
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		return c.Status(fiberErr.Code).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   fiberErr.Message,
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Failed to validate zone",
+	})
+}
+
+// GetZones retrieves the warehouse zones
+// @Summary Get Zones
+// @Description Retrieve the warehouse zones in map order with their rack location prefixes
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Search term for zone code or name"
+// @Success 200 {object} utils.SuccessResponse{data=[]models.ZoneResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/zones [get]
+func (zc *ZoneController) GetZones(c fiber.Ctx) error {
+	log.Println("GetZones called")
+	var zones []models.Zone
+
+	// Build base query
+	query := zc.DB.Model(&models.Zone{}).Order("sort_order ASC, zone_code ASC")
+
+	// Search condition if provided
+	search := strings.TrimSpace(c.Query("search", ""))
+	if search != "" {
+		query = query.Where("zone_code ILIKE ? OR name ILIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	if err := query.Find(&zones).Error; err != nil {
+		log.Println("GetZones - Failed to retrieve zones:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve zones",
+		})
+	}
+
+	// Format response
+	zoneList := make([]models.ZoneResponse, len(zones))
+	for i, zone := range zones {
+		zoneList[i] = *zone.ToResponse()
+	}
+
+	// Build success message
+	message := "Zones retrieved successfully"
+	if search != "" {
+		message += fmt.Sprintf(" (filtered by search: %s)", search)
+	}
+
+	log.Println("GetZones completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    zoneList,
+	})
+}
+
+// GetZone retrieves a single zone by ID
+// @Summary Get Zone
+// @Description Retrieve a single warehouse zone by ID
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Zone ID"
+// @Success 200 {object} utils.SuccessResponse{data=models.ZoneResponse}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/zones/{id} [get]
+func (zc *ZoneController) GetZone(c fiber.Ctx) error {
+	log.Println("GetZone called")
+	// Parse id parameter
+	id := c.Params("id")
+	var zone models.Zone
+	if err := zc.DB.Where("id = ?", id).First(&zone).Error; err != nil {
+		log.Println("GetZone - Zone not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Zone with id " + id + " not found.",
+		})
+	}
+
+	log.Println("GetZone completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Zone retrieved successfully",
+		Data:    zone.ToResponse(),
+	})
+}
+
+// CreateZone creates a new warehouse zone
+// @Summary Create Zone
+// @Description Create a new warehouse zone owning the rack locations starting with its location prefixes. A prefix can only belong to one zone
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateZoneRequest true "Zone details"
+// @Success 201 {object} utils.SuccessResponse{data=models.ZoneResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/zones [post]
+func (zc *ZoneController) CreateZone(c fiber.Ctx) error {
+	log.Println("CreateZone called")
+	// Binding request body
+	var req CreateZoneRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("CreateZone - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	prefixes, err := zc.validateZoneRequest(&req.ZoneCode, &req.Name, req.LocationPrefixes, 0)
+	if err != nil {
+		log.Println("CreateZone - Invalid zone:", err)
+		return zoneErrorResponse(c, err)
+	}
+
+	zone := models.Zone{
+		ZoneCode:         req.ZoneCode,
+		Name:             req.Name,
+		LocationPrefixes: prefixes,
+		SortOrder:        req.SortOrder,
+	}
+	if err := zc.DB.Create(&zone).Error; err != nil {
+		log.Println("CreateZone - Failed to create zone:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to create zone",
+		})
+	}
+
+	log.Println("CreateZone completed successfully")
+	return c.Status(fiber.StatusCreated).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Zone created successfully",
+		Data:    zone.ToResponse(),
+	})
+}
+
+// UpdateZone updates an existing warehouse zone by ID
+// @Summary Update Zone
+// @Description Update an existing warehouse zone by ID. Pick visits already recorded keep the zone they were resolved to
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Zone ID"
+// @Param request body UpdateZoneRequest true "Updated zone details"
+// @Success 200 {object} utils.SuccessResponse{data=models.ZoneResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 409 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/zones/{id} [put]
+func (zc *ZoneController) UpdateZone(c fiber.Ctx) error {
+	log.Println("UpdateZone called")
+	// Parse id parameter
+	id := c.Params("id")
+	var zone models.Zone
+	if err := zc.DB.Where("id = ?", id).First(&zone).Error; err != nil {
+		log.Println("UpdateZone - Zone not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Zone with id " + id + " not found.",
+		})
+	}
+
+	// Binding request body
+	var req UpdateZoneRequest
+	if err := c.Bind().JSON(&req); err != nil {
+		log.Println("UpdateZone - Invalid request body:", err)
+		return c.Status(fiber.StatusBadRequest).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	prefixes, err := zc.validateZoneRequest(&req.ZoneCode, &req.Name, req.LocationPrefixes, zone.ID)
+	if err != nil {
+		log.Println("UpdateZone - Invalid zone:", err)
+		return zoneErrorResponse(c, err)
+	}
+
+	zone.ZoneCode = req.ZoneCode
+	zone.Name = req.Name
+	zone.LocationPrefixes = prefixes
+	zone.SortOrder = req.SortOrder
+	if err := zc.DB.Save(&zone).Error; err != nil {
+		log.Println("UpdateZone - Failed to update zone:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to update zone",
+		})
+	}
+
+	log.Println("UpdateZone completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Zone updated successfully",
+		Data:    zone.ToResponse(),
+	})
+}
+
+// DeleteZone deletes a warehouse zone by ID
+// @Summary Delete Zone
+// @Description Delete a warehouse zone by ID. Pick visits recorded in the zone become unzoned
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Zone ID"
+// @Success 200 {object} utils.SuccessResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/zones/{id} [delete]
+func (zc *ZoneController) DeleteZone(c fiber.Ctx) error {
+	log.Println("DeleteZone called")
+	// Parse id parameter
+	id := c.Params("id")
+	var zone models.Zone
+	if err := zc.DB.Where("id = ?", id).First(&zone).Error; err != nil {
+		log.Println("DeleteZone - Zone not found:", id)
+		return c.Status(fiber.StatusNotFound).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Zone with id " + id + " not found.",
+		})
+	}
+
+	err := zc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PickZoneVisit{}).Where("zone_id = ?", zone.ID).Update("zone_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&zone).Error
+	})
+	if err != nil {
+		log.Println("DeleteZone - Failed to delete zone:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to delete zone",
+		})
+	}
+
+	log.Println("DeleteZone completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Zone deleted successfully",
+	})
+}
+
+// parseZoneDateRange parses the startDate and endDate filters, defaulting to the last 7 days including today
+func parseZoneDateRange(c fiber.Ctx) (time.Time, time.Time, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -7)
+	if startDate := c.Query("startDate", ""); startDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", startDate, now.Location())
+		if err != nil {
+			return start, end, fiber.NewError(fiber.StatusBadRequest, "Invalid startDate format, expected YYYY-MM-DD")
+		}
+		start = parsed
+	}
+	if endDate := c.Query("endDate", ""); endDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", endDate, now.Location())
+		if err != nil {
+			return start, end, fiber.NewError(fiber.StatusBadRequest, "Invalid endDate format, expected YYYY-MM-DD")
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+	if !start.Before(end) {
+		return start, end, fiber.NewError(fiber.StatusBadRequest, "startDate must not be after endDate")
+	}
+	return start, end, nil
+}
+
+// GetZoneHeatmap retrieves the picks per zone per hour of the day
+// @Summary Get Zone Heatmap
+// @Description Retrieve the number of pick scans and picked units per zone per hour of the day over a date range (default the last 7 days), to inform slotting decisions. Picks from rack locations outside every zone are reported as UNZONED
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} utils.SuccessResponse{data=ZoneHeatmapResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/zones/heatmap [get]
+func (zc *ZoneController) GetZoneHeatmap(c fiber.Ctx) error {
+	log.Println("GetZoneHeatmap called")
+	start, end, err := parseZoneDateRange(c)
+	if err != nil {
+		return zoneErrorResponse(c, err)
+	}
+
+	var zones []models.Zone
+	if err := zc.DB.Order("sort_order ASC, zone_code ASC").Find(&zones).Error; err != nil {
+		log.Println("GetZoneHeatmap - Failed to retrieve zones:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve zones",
+		})
+	}
+
+	// Aggregate the visits per zone and local hour of the day
+	var cells []struct {
+		ZoneID *uint
+		Hour   int
+		Picks  int
+		Units  int
+	}
+	if err := zc.DB.Model(&models.PickZoneVisit{}).
+		Select("zone_id, EXTRACT(HOUR FROM created_at)::int AS hour, COUNT(*) AS picks, COALESCE(SUM(quantity), 0) AS units").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Group("zone_id, hour").
+		Scan(&cells).Error; err != nil {
+		log.Println("GetZoneHeatmap - Failed to aggregate pick zone visits:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve zone heatmap",
+		})
+	}
+
+	// Every zone gets a row, even without picks, so the map shows cold zones
+	rows := make([]ZoneHeatmapRow, len(zones))
+	rowIndex := make(map[uint]int, len(zones))
+	for i := range zones {
+		rows[i] = ZoneHeatmapRow{ZoneID: &zones[i].ID, ZoneCode: zones[i].ZoneCode, ZoneName: zones[i].Name}
+		rowIndex[zones[i].ID] = i
+	}
+	unzoned := ZoneHeatmapRow{ZoneCode: unzonedCode, ZoneName: "Outside every zone"}
+	for _, cell := range cells {
+		if cell.Hour < 0 || cell.Hour > 23 {
+			continue
+		}
+		row := &unzoned
+		if cell.ZoneID != nil {
+			if i, ok := rowIndex[*cell.ZoneID]; ok {
+				row = &rows[i]
+			}
+		}
+		row.Picks[cell.Hour] += cell.Picks
+		row.Units[cell.Hour] += cell.Units
+		row.TotalPicks += cell.Picks
+		row.TotalUnits += cell.Units
+	}
+	if unzoned.TotalPicks > 0 {
+		rows = append(rows, unzoned)
+	}
+
+	log.Println("GetZoneHeatmap completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessResponse{
+		Success: true,
+		Message: "Zone heatmap retrieved successfully",
+		Data: ZoneHeatmapResponse{
+			StartDate: start.Format("2006-01-02"),
+			EndDate:   end.AddDate(0, 0, -1).Format("2006-01-02"),
+			Zones:     rows,
+		},
+	})
+}
+
+// GetZoneVisits retrieves the recorded pick zone visits
+// @Summary Get Zone Visits
+// @Description Retrieve the zones each pick visited with pagination, newest first
+// @Tags Zones
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Number of visits per page" default(10)
+// @Param zoneId query int false "Filter by zone ID"
+// @Param orderId query int false "Filter by order ID"
+// @Param pickBatchId query int false "Filter by pick batch ID"
+// @Param pickedBy query int false "Filter by picker user ID"
+// @Param startDate query string false "Start date (YYYY-MM-DD)"
+// @Param endDate query string false "End date (YYYY-MM-DD)"
+// @Success 200 {object} utils.SuccessPaginatedResponse{data=[]models.PickZoneVisitResponse}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/zones/visits [get]
+func (zc *ZoneController) GetZoneVisits(c fiber.Ctx) error {
+	log.Println("GetZoneVisits called")
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "10"))
+	offset := (page - 1) * limit
+
+	start, end, err := parseZoneDateRange(c)
+	if err != nil {
+		return zoneErrorResponse(c, err)
+	}
+
+	var visits []models.PickZoneVisit
+
+	// Build base query
+	query := zc.DB.Model(&models.PickZoneVisit{}).Preload("Zone").Preload("Order").Preload("PickUser").
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Order("created_at DESC, id DESC")
+
+	var filters []string
+	for _, filter := range []struct {
+		param  string
+		column string
+	}{
+		{"zoneId", "zone_id"},
+		{"orderId", "order_id"},
+		{"pickBatchId", "pick_batch_id"},
+		{"pickedBy", "picked_by"},
+	} {
+		if value, _ := strconv.ParseUint(c.Query(filter.param, "0"), 10, 32); value > 0 {
+			query = query.Where(filter.column+" = ?", value)
+			filters = append(filters, fmt.Sprintf("%s: %d", filter.param, value))
+		}
+	}
+
+	// Get total count for pagination
+	var total int64
+	query.Count(&total)
+
+	// Retrieve paginated results
+	if err := query.Limit(limit).Offset(offset).Find(&visits).Error; err != nil {
+		log.Println("GetZoneVisits - Failed to retrieve pick zone visits:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+			Success: false,
+			Error:   "Failed to retrieve zone visits",
+		})
+	}
+
+	// Format response
+	visitList := make([]models.PickZoneVisitResponse, len(visits))
+	for i, visit := range visits {
+		visitList[i] = *visit.ToResponse()
+	}
+
+	// Build success message
+	message := "Zone visits retrieved successfully"
+	if len(filters) > 0 {
+		message += fmt.Sprintf(" (filtered by %s)", strings.Join(filters, " | "))
+	}
+
+	log.Println("GetZoneVisits completed successfully")
+	return c.Status(fiber.StatusOK).JSON(utils.SuccessPaginatedResponse{
+		Success: true,
+		Message: message,
+		Data:    visitList,
+		Pagination: utils.Pagination{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		},
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"livo-fiber-backend/models"
 	"livo-fiber-backend/utils"
 	"log"
+	"strings"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -34,6 +35,9 @@ func ConnectDatabase(cfg *config.Config) error {
 		gormLogger = logger.Default.LogMode(logger.Info)
 	}
 
+	// Record queries slower than the configured threshold
+	slowQueryLogger = NewSlowQueryLogger(gormLogger, time.Duration(cfg.DbSlowQueryThreshold)*time.Millisecond)
+
 	maxRetries := 5
 	retryInterval := time.Second * 10
 
@@ -42,7 +46,7 @@ func ConnectDatabase(cfg *config.Config) error {
 
 		var err error
 		DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-			Logger: gormLogger,
+			Logger: slowQueryLogger,
 
 			NowFunc: func() time.Time {
 				location, _ := time.LoadLocation(cfg.DbTz)
@@ -57,9 +61,15 @@ func ConnectDatabase(cfg *config.Config) error {
 				err = sqlDB.Ping()
 				if err == nil {
 					// Set connection pool settings
-					sqlDB.SetMaxIdleConns(10)
-					sqlDB.SetMaxOpenConns(100)
-					sqlDB.SetConnMaxLifetime(time.Hour)
+					sqlDB.SetMaxIdleConns(cfg.DbMaxIdleConns)
+					sqlDB.SetMaxOpenConns(cfg.DbMaxOpenConns)
+					sqlDB.SetConnMaxLifetime(time.Duration(cfg.DbConnMaxLifetime) * time.Minute)
+					sqlDB.SetConnMaxIdleTime(time.Duration(cfg.DbConnMaxIdleTime) * time.Minute)
+
+					// Trace queries executed with a traced request context
+					if err := DB.Use(&TracingPlugin{}); err != nil {
+						log.Printf("x Failed to register tracing plugin: %v", err)
+					}
 
 					log.Println("Database connection established.")
 					return nil
@@ -83,9 +93,17 @@ func ConnectDatabase(cfg *config.Config) error {
 }
 
 // MigrateDatabase performs automatic migration of database schemas
-func MigrateDatabase() error {
+func MigrateDatabase(cfg *config.Config) error {
 	log.Println("🔄 Starting database migration...")
 
+	// Out-of-vocabulary QC statuses have to be repaired before the status CHECK constraints are added
+	if err := repairQCStatuses(); err != nil {
+		return fmt.Errorf("failed to repair QC statuses: %w", err)
+	}
+
+	// Scanned quantities are only backfilled by the migration adding the column, later runs keep the counted scans
+	scannedQuantityAdded := DB.Migrator().HasTable(&models.OrderDetail{}) && !DB.Migrator().HasColumn(&models.OrderDetail{}, "ScannedQuantity")
+
 	err := DB.AutoMigrate(
 		&models.Role{},
 		&models.User{},
@@ -95,36 +113,431 @@ func MigrateDatabase() error {
 		&models.Expedition{},
 		&models.Store{},
 		&models.Product{},
+		&models.Inventory{},
+		&models.InventoryMovement{},
+		&models.InventoryBatch{},
+		&models.InboundShipment{},
+		&models.InboundDetail{},
+		&models.StockTake{},
+		&models.StockTakeItem{},
 		&models.Order{},
 		&models.OrderDetail{},
+		&models.QCStation{},
 		&models.QCRibbon{},
 		&models.QCRibbonDetail{},
 		&models.QCOnline{},
 		&models.QCOnlineDetail{},
+		&models.QCVoid{},
+		&models.QCParcelPhoto{},
+		&models.QCMismatch{},
 		&models.Outbound{},
+		&models.HandoverSession{},
+		&models.HandoverSessionItem{},
+		&models.HandoverAck{},
+		&models.HandoverAckItem{},
 		&models.LostFound{},
 		&models.Return{},
 		&models.ReturnDetail{},
 		&models.PickedOrder{},
+		&models.PickBatch{},
+		&models.PickBatchOrder{},
+		&models.OfflineSyncAction{},
+		&models.PickShortage{},
+		&models.ProductSubstitution{},
+		&models.OrderHold{},
+		&models.OrderEditOverride{},
+		&models.OrderAddressChange{},
+		&models.FeatureFlag{},
+		&models.FeatureFlagAudit{},
+		&models.SLABreach{},
+		&models.ApprovalDelegation{},
+		&models.Approval{},
+		&models.ApprovalCode{},
 		&models.Return{},
 		&models.ReturnDetail{},
+		&models.UnknownReturn{},
 		&models.LostFound{},
 		&models.Complain{},
 		&models.ComplainUserDetail{},
 		&models.ComplainProductDetail{},
+		&models.ComplainFeeDispute{},
+		&models.ComplainFeeChange{},
+		&models.ComplainSettlement{},
+		&models.ComplainSettlementLine{},
+		&models.ComplainReopen{},
+		&models.ComplainAssignment{},
 		&models.UserFace{},
-		&models.Location{}, // Must be before Attendance
+		&models.Team{},
+		&models.TeamMember{},
+		&models.Location{},           // Must be before Attendance
+		&models.AttendanceBackfill{}, // Must be before Attendance
 		&models.Attendance{},
+		&models.AttendanceBreak{},
+		&models.PurgeRun{},
+		&models.PurgeRunDetail{},
+		&models.DataRepairRun{},
+		&models.DataRepairRunDetail{},
+		&models.DataRepairChange{},
+		&models.Notification{},
+		&models.UserInvitation{},
+		&models.PasswordReset{},
+		&models.APIKey{},
+		&models.APIKeyUsage{},
+		&models.InboundWebhookEvent{},
+		&models.WebhookSubscription{},
+		&models.PrintTemplate{},
+		&models.ShadowDivergence{},
+		&models.CourierBooking{},
+		&models.Skill{},
+		&models.UserSkill{},
+		&models.LegacyImportTemplate{},
+		&models.LegacyImportRun{},
+		&models.LegacyImportRecord{},
+		&models.ReportSchedule{},
+		&models.AccessLog{},
+		&models.RoleAudit{},
+		&models.ReportAccessDenial{},
+		&models.SecurityEvent{},
+		&models.AccountingMapping{},
+		&models.AccountingSync{},
+		&models.OrderVolumeThreshold{},
+		&models.OrderVolumeAlert{},
+		&models.BulkOperation{},
+		&models.ReferenceAlias{},
+		&models.OrderItemSerial{},
+		&models.Zone{},
+		&models.PickZoneVisit{},
+		&models.StagingArea{},
+		&models.StagingPlacement{},
+		&models.OrderNumberSequence{},
+		&models.ComplainRootCause{},
+		&models.ExportJob{},
+		&models.ProductDisplayName{},
+		&models.ProductVariantMapping{},
+		&models.DailyAggregate{},
+		&models.OrderEditLock{},
+		&models.TrainingTask{},
+		&models.TrackingFormat{},
+		&models.PickAnomaly{},
+		&models.ChangeEvent{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if err := normalizeStatuses(); err != nil {
+		return fmt.Errorf("failed to normalize statuses: %w", err)
+	}
+
+	if err := normalizeIdentifiers(); err != nil {
+		return fmt.Errorf("failed to normalize identifiers: %w", err)
+	}
+
+	if err := backfillOrderTotals(cfg.DefaultCurrency); err != nil {
+		return fmt.Errorf("failed to backfill order totals: %w", err)
+	}
+
+	if err := backfillOvertimeStatus(); err != nil {
+		return fmt.Errorf("failed to backfill overtime status: %w", err)
+	}
+
+	if scannedQuantityAdded {
+		if err := backfillScannedQuantity(); err != nil {
+			return fmt.Errorf("failed to backfill scanned quantities: %w", err)
+		}
+	}
+
+	if err := normalizeUserIdentities(); err != nil {
+		return fmt.Errorf("failed to normalize user identities: %w", err)
+	}
+
+	if err := utils.InstallChangeFeedTriggers(DB); err != nil {
+		return fmt.Errorf("failed to install change feed triggers: %w", err)
+	}
+
 	log.Println("✅ Database migrations completed successfully")
 	return nil
 }
 
+// normalizeStatuses rewrites legacy status spellings to the values defined in models/status.go
+func normalizeStatuses() error {
+	legacyStatuses := []struct {
+		Table  string
+		Column string
+		From   string
+		To     string
+	}{
+		{"orders", "event_status", "cancelled", models.EventStatusCanceled},
+	}
+
+	for _, legacy := range legacyStatuses {
+		result := DB.Table(legacy.Table).Where(legacy.Column+" = ?", legacy.From).Update(legacy.Column, legacy.To)
+		if result.Error != nil {
+			return fmt.Errorf("%s.%s: %w", legacy.Table, legacy.Column, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Normalized %d %s.%s values from %q to %q", result.RowsAffected, legacy.Table, legacy.Column, legacy.From, legacy.To)
+		}
+	}
+	return nil
+}
+
+// repairQCStatuses rewrites QC Ribbon and QC Online statuses outside the QC state machine (see models.QCStatus).
+// Spelling variants such as "Completed", "in progress" or "cancelled" are mapped to their status. Anything else is
+// derived from the order: completed when the order got past QC, pending otherwise so the QC can be finished, and
+// logged for review.
+func repairQCStatuses() error {
+	var known []string
+	for _, definition := range models.QCStatusDefinitions() {
+		known = append(known, definition.Value)
+	}
+
+	for _, table := range []string{"qc_ribbons", "qc_onlines"} {
+		if !DB.Migrator().HasTable(table) {
+			continue
+		}
+		invalid := func() *gorm.DB {
+			return DB.Table(table).Where("status IS NULL OR status NOT IN ?", known)
+		}
+
+		// Spelling variants
+		normalized := "REPLACE(REPLACE(LOWER(TRIM(status)), ' ', '_'), '-', '_')"
+		variants := map[string]models.QCStatus{"cancelled": models.QCStatusCanceled, "done": models.QCStatusCompleted, "progress": models.QCStatusInProgress}
+		for _, status := range known {
+			variants[status] = models.QCStatus(status)
+		}
+		for variant, status := range variants {
+			result := invalid().Where(normalized+" = ?", variant).Update("status", status)
+			if result.Error != nil {
+				return fmt.Errorf("%s.status: %w", table, result.Error)
+			}
+			if result.RowsAffected > 0 {
+				log.Printf("Repaired %d %s.status values spelled as %q to %q", result.RowsAffected, table, variant, status)
+			}
+		}
+
+		// Unknown values, derived from the order
+		var unknown []struct {
+			ID     uint
+			Status *string
+		}
+		if err := invalid().Select("id, status").Find(&unknown).Error; err != nil {
+			return fmt.Errorf("%s.status: %w", table, err)
+		}
+		if len(unknown) == 0 {
+			continue
+		}
+		pastQC := fmt.Sprintf("EXISTS (SELECT 1 FROM orders WHERE orders.tracking_number = %s.tracking_number AND orders.processing_status IN ?)", table)
+		if err := invalid().Where(pastQC, []string{models.ProcessingStatusQCCompleted, models.ProcessingStatusOutboundCompleted}).Update("status", models.QCStatusCompleted).Error; err != nil {
+			return fmt.Errorf("%s.status: %w", table, err)
+		}
+		if err := invalid().Update("status", models.QCStatusPending).Error; err != nil {
+			return fmt.Errorf("%s.status: %w", table, err)
+		}
+		for _, row := range unknown {
+			previous := "NULL"
+			if row.Status != nil {
+				previous = fmt.Sprintf("%q", *row.Status)
+			}
+			log.Printf("Repaired %s %d with unknown status %s, review its QC", table, row.ID, previous)
+		}
+	}
+	return nil
+}
+
+// normalizeIdentifiers rewrites tracking numbers and Ginee order IDs stored before they were normalized on write
+// to their canonical form (see models.NormalizeIdentifier). On unique columns a row is only rewritten when no other
+// row holds or normalizes to the same value, conflicting rows are logged and left as they are for manual review.
+func normalizeIdentifiers() error {
+	for _, identifier := range utils.IdentifierColumns {
+		normalized := fmt.Sprintf("UPPER(TRIM(%s.%s))", identifier.Table, identifier.Column)
+		query := DB.Table(identifier.Table).Where(identifier.Table + "." + identifier.Column + " <> " + normalized)
+		if identifier.Unique {
+			// Only the first row of a normalized value takes it, and only when it is not held yet
+			query = query.
+				Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %[1]s AS other WHERE other.%[2]s = %[3]s)", identifier.Table, identifier.Column, normalized)).
+				Where(fmt.Sprintf("%[1]s.id = (SELECT MIN(other.id) FROM %[1]s AS other WHERE UPPER(TRIM(other.%[2]s)) = %[3]s)", identifier.Table, identifier.Column, normalized))
+		}
+		result := query.Update(identifier.Column, gorm.Expr(normalized))
+		if result.Error != nil {
+			return fmt.Errorf("%s.%s: %w", identifier.Table, identifier.Column, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("Normalized %d %s.%s values", result.RowsAffected, identifier.Table, identifier.Column)
+		}
+
+		if identifier.Unique {
+			var conflicts int64
+			if err := DB.Table(identifier.Table).Where(identifier.Table + "." + identifier.Column + " <> " + normalized).Count(&conflicts).Error; err != nil {
+				return fmt.Errorf("%s.%s: %w", identifier.Table, identifier.Column, err)
+			}
+			if conflicts > 0 {
+				log.Printf("Left %d %s.%s values unnormalized, their normalized value is already used by another row", conflicts, identifier.Table, identifier.Column)
+			}
+		}
+	}
+	return nil
+}
+
+// backfillOrderTotals fills the totals and currency of orders created before they were stored.
+// Orders without a currency have never had their totals calculated.
+func backfillOrderTotals(defaultCurrency string) error {
+	pending := "currency IS NULL OR currency = ''"
+	var count int64
+	if err := DB.Model(&models.Order{}).Where(pending).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	if err := models.RecalculateOrderTotals(DB.Where(pending)); err != nil {
+		return err
+	}
+	if err := DB.Model(&models.Order{}).Where(pending).Update("currency", defaultCurrency).Error; err != nil {
+		return err
+	}
+	log.Printf("Backfilled totals and currency of %d orders", count)
+	return nil
+}
+
+// backfillOvertimeStatus puts overtime recorded before the approval workflow up for approval
+func backfillOvertimeStatus() error {
+	result := DB.Model(&models.Attendance{}).
+		Where("overtime > 0 AND (overtime_status IS NULL OR overtime_status = ?)", models.OvertimeStatusNone).
+		Update("overtime_status", models.OvertimeStatusPending)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled %d attendances with overtime pending approval", result.RowsAffected)
+	}
+	return nil
+}
+
+// backfillScannedQuantity marks order details validated before scanned quantities were counted as fully scanned,
+// otherwise QC started before the upgrade could not be completed. It runs once, right after the column is added.
+func backfillScannedQuantity() error {
+	result := DB.Model(&models.OrderDetail{}).
+		Where("is_valid = ? AND scanned_quantity <> quantity", true).
+		Update("scanned_quantity", gorm.Expr("quantity"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled scanned quantity of %d validated order details", result.RowsAffected)
+	}
+	return nil
+}
+
+// normalizeUserIdentities rewrites usernames and emails to their normalized form (see utils.NormalizeUsername).
+// Accounts that only differ by case or spacing are deduplicated: the most recently logged in account keeps the
+// normalized value and the others are renamed with their id, so every account stays reachable.
+// Afterwards unique indexes on the lowercased columns keep new case-variant duplicates out.
+func normalizeUserIdentities() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var users []models.User
+		if err := tx.Order("last_login DESC NULLS LAST, id ASC").Find(&users).Error; err != nil {
+			return err
+		}
+
+		usernames := make(map[uint]string, len(users))
+		emails := make(map[uint]string, len(users))
+		takenUsernames := make(map[string]bool, len(users))
+		takenEmails := make(map[string]bool, len(users))
+
+		// The first account of every normalized value keeps it, users are ordered by preference
+		for _, user := range users {
+			username := utils.NormalizeUsername(user.Username)
+			if !takenUsernames[username] {
+				takenUsernames[username] = true
+				usernames[user.ID] = username
+			}
+			// Accounts without an email have nothing to deduplicate
+			email := utils.NormalizeEmail(user.Email)
+			if email == "" {
+				emails[user.ID] = email
+			} else if !takenEmails[email] {
+				takenEmails[email] = true
+				emails[user.ID] = email
+			}
+		}
+
+		// Duplicates get a value derived from their id
+		for _, user := range users {
+			if _, ok := usernames[user.ID]; !ok {
+				usernames[user.ID] = uniqueIdentity(takenUsernames, func(attempt int) string {
+					suffix := fmt.Sprintf("_%d", user.ID)
+					if attempt > 0 {
+						suffix = fmt.Sprintf("_%d_%d", user.ID, attempt)
+					}
+					base := []rune(utils.NormalizeUsername(user.Username))
+					if len(base)+len(suffix) > 50 {
+						base = base[:50-len(suffix)]
+					}
+					return string(base) + suffix
+				})
+				log.Printf("Renamed duplicate username %q of user %d to %q", user.Username, user.ID, usernames[user.ID])
+			}
+			if _, ok := emails[user.ID]; !ok {
+				emails[user.ID] = uniqueIdentity(takenEmails, func(attempt int) string {
+					tag := fmt.Sprintf("+duplicate-%d", user.ID)
+					if attempt > 0 {
+						tag = fmt.Sprintf("+duplicate-%d-%d", user.ID, attempt)
+					}
+					email := utils.NormalizeEmail(user.Email)
+					if at := strings.LastIndex(email, "@"); at >= 0 {
+						return email[:at] + tag + email[at:]
+					}
+					return email + tag
+				})
+				log.Printf("Renamed duplicate email %q of user %d to %q", user.Email, user.ID, emails[user.ID])
+			}
+		}
+
+		// Park changed rows on temporary values first so swapping values never trips the existing unique indexes
+		var changed []models.User
+		for _, user := range users {
+			if user.Username != usernames[user.ID] || user.Email != emails[user.ID] {
+				changed = append(changed, user)
+			}
+		}
+		for _, user := range changed {
+			placeholder := fmt.Sprintf("~normalizing-%d", user.ID)
+			if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+				Updates(map[string]interface{}{"username": placeholder, "email": placeholder}).Error; err != nil {
+				return err
+			}
+		}
+		for _, user := range changed {
+			if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
+				Updates(map[string]interface{}{"username": usernames[user.ID], "email": emails[user.ID]}).Error; err != nil {
+				return err
+			}
+		}
+		if len(changed) > 0 {
+			log.Printf("Normalized usernames and emails of %d users", len(changed))
+		}
+
+		if err := tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_username_lower ON users (LOWER(username))").Error; err != nil {
+			return err
+		}
+		return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (LOWER(email)) WHERE email <> ''").Error
+	})
+}
+
+// uniqueIdentity returns the first candidate not taken yet and marks it taken
+func uniqueIdentity(taken map[string]bool, candidate func(attempt int) string) string {
+	for attempt := 0; ; attempt++ {
+		value := candidate(attempt)
+		if !taken[value] {
+			taken[value] = true
+			return value
+		}
+	}
+}
+
 // Seeds initial data into the database
 func SeedInitialRole() error {
 	log.Println("🌱 Seeding initial role data into the database...")
@@ -476,6 +889,36 @@ func SeedInitialLocation() error {
 	return nil
 }
 
+func SeedInitialComplainRootCause() error {
+	log.Println("🌱 Seeding initial complain root cause data into the database...")
+
+	// Create initial complain root causes
+	rootCauses := []models.ComplainRootCause{
+		{Code: models.ComplainRootCauseWrongItem, Name: "Wrong item", Description: "A different product or variant was packed"},
+		{Code: models.ComplainRootCauseMissingItem, Name: "Missing item", Description: "One or more items were missing from the parcel"},
+		{Code: models.ComplainRootCauseDamaged, Name: "Damaged", Description: "The item arrived damaged or poorly packed"},
+		{Code: models.ComplainRootCauseCourierIssue, Name: "Courier issue", Description: "The parcel was lost, delayed or mishandled by the courier"},
+		{Code: models.ComplainRootCauseProductQuality, Name: "Product quality", Description: "The item was defective or not as described by the supplier"},
+		{Code: models.ComplainRootCauseBuyerRelated, Name: "Buyer related", Description: "The buyer changed their mind or the item did not meet their expectation"},
+		{Code: models.ComplainRootCauseOther, Name: "Other", Description: "Any other cause"},
+	}
+
+	for _, rootCauseData := range rootCauses {
+		var existingRootCause models.ComplainRootCause
+		result := DB.Where("code = ?", rootCauseData.Code).First(&existingRootCause)
+
+		if result.Error == gorm.ErrRecordNotFound {
+			rootCauseData.IsActive = true
+			if err := DB.Create(&rootCauseData).Error; err != nil {
+				return fmt.Errorf("failed to create complain root cause %s: %w", rootCauseData.Code, err)
+			}
+		}
+	}
+
+	log.Println("✅ Complain root causes seeding completed successfully")
+	return nil
+}
+
 // GetDB returns the database instance
 func GetDB() *gorm.DB {
 	return DB
@@ -0,0 +1,467 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SandboxPassword is the password of every sandbox user
+const SandboxPassword = "12345678"
+
+// sandboxUsers are the warehouse staff the sandbox data is attributed to
+var sandboxUsers = []struct {
+	Username string
+	FullName string
+	RoleName string
+}{
+	{"sandbox.admin", "Sandbox Admin", "admin"},
+	{"sandbox.coordinator", "Sandbox Coordinator", "coordinator"},
+	{"sandbox.picker1", "Sandbox Picker One", "picker"},
+	{"sandbox.picker2", "Sandbox Picker Two", "picker"},
+	{"sandbox.picker3", "Sandbox Picker Three", "picker"},
+	{"sandbox.qcribbon", "Sandbox QC Ribbon", "qc-ribbon"},
+	{"sandbox.qconline", "Sandbox QC Online", "qc-online"},
+	{"sandbox.outbound", "Sandbox Outbound", "outbound"},
+}
+
+// sandboxProducts are the products sandbox orders are made of
+var sandboxProducts = []models.Product{
+	{SKU: "SBX-TSH-001", Name: "Sandbox Cotton T-Shirt", Variant: "Black, M", Location: "A-01-01"},
+	{SKU: "SBX-TSH-002", Name: "Sandbox Cotton T-Shirt", Variant: "White, L", Location: "A-01-02"},
+	{SKU: "SBX-HOD-001", Name: "Sandbox Zip Hoodie", Variant: "Navy, XL", Location: "A-02-01"},
+	{SKU: "SBX-CAP-001", Name: "Sandbox Baseball Cap", Location: "A-03-04"},
+	{SKU: "SBX-BTL-001", Name: "Sandbox Water Bottle 750ml", Variant: "Blue", Location: "B-01-01"},
+	{SKU: "SBX-BTL-002", Name: "Sandbox Water Bottle 1L", Variant: "Green", Location: "B-01-02"},
+	{SKU: "SBX-MUG-001", Name: "Sandbox Ceramic Mug", Location: "B-02-03", NeedCheck: true},
+	{SKU: "SBX-BAG-001", Name: "Sandbox Tote Bag", Variant: "Canvas", Location: "C-01-01"},
+	{SKU: "SBX-SCK-001", Name: "Sandbox Sport Socks", Variant: "3 Pairs", Location: "C-02-02"},
+	{SKU: "SBX-RBN-001", Name: "Sandbox Gift Ribbon", Variant: "Red", Location: "D-01-01"},
+	{SKU: "SBX-RBN-002", Name: "Sandbox Gift Ribbon", Variant: "Gold", Location: "D-01-02"},
+	{SKU: "SBX-PHC-001", Name: "Sandbox Phone Case", Variant: "Clear", Location: "D-03-01", NeedCheck: true},
+}
+
+// sandboxScenario is an order state the sandbox generates orders in
+type sandboxScenario struct {
+	ProcessingStatus string
+	EventStatus      string
+	Weight           int // relative frequency
+}
+
+var sandboxScenarios = []sandboxScenario{
+	{models.ProcessingStatusReadyToPick, models.EventStatusInProgress, 12},
+	{models.ProcessingStatusPickingProgress, models.EventStatusInProgress, 6},
+	{models.ProcessingStatusPickingPending, models.EventStatusPending, 2},
+	{models.ProcessingStatusAwaitingStock, models.EventStatusInProgress, 2},
+	{models.ProcessingStatusPickingCompleted, models.EventStatusInProgress, 6},
+	{models.ProcessingStatusQCProgress, models.EventStatusInProgress, 4},
+	{models.ProcessingStatusQCCompleted, models.EventStatusInProgress, 6},
+	{models.ProcessingStatusOutboundCompleted, models.EventStatusCompleted, 45},
+	{models.ProcessingStatusReadyToPick, models.EventStatusHeld, 3},
+	{models.ProcessingStatusReadyToPick, models.EventStatusCanceled, 4},
+	{models.ProcessingStatusReadyToPick, models.EventStatusDuplicated, 1},
+}
+
+var sandboxBuyers = []string{"Budi Santoso", "Siti Rahma", "Andi Wijaya", "Dewi Lestari", "Rizky Pratama", "Putri Ayu", "Agus Salim", "Nur Hidayah", "Fajar Nugroho", "Maya Sari"}
+
+var sandboxAddresses = []string{
+	"Jl. Soekarno Hatta No. 12, Lowokwaru, Kota Malang, Jawa Timur 65141",
+	"Jl. Raya Darmo No. 88, Wonokromo, Kota Surabaya, Jawa Timur 60241",
+	"Jl. Malioboro No. 5, Gedong Tengen, Kota Yogyakarta, DI Yogyakarta 55271",
+	"Jl. Asia Afrika No. 21, Sumur Bandung, Kota Bandung, Jawa Barat 40111",
+	"Jl. Sudirman Kav. 45, Setiabudi, Jakarta Selatan, DKI Jakarta 12930",
+	"Jl. Pemuda No. 150, Semarang Tengah, Kota Semarang, Jawa Tengah 50132",
+}
+
+var sandboxComplainReasons = []struct{ Reason, RootCause string }{
+	{"Item damaged", models.ComplainRootCauseDamaged},
+	{"Wrong item sent", models.ComplainRootCauseWrongItem},
+	{"Item missing from parcel", models.ComplainRootCauseMissingItem},
+	{"Wrong variant sent", models.ComplainRootCauseWrongItem},
+}
+
+// SandboxSummary counts the records created by SeedSandbox
+type SandboxSummary struct {
+	Users       int
+	Products    int
+	Orders      int
+	QC          int
+	Outbounds   int
+	Attendances int
+	Complains   int
+}
+
+// SeedSandbox fills the database with realistic fake data for staging and frontend development:
+// sandbox users and products, orders across all statuses with their picking, QC and outbound records,
+// attendance history and complains, spread over the last days. Requires the initial seeds.
+// Every run adds new orders, users and products are only created once.
+func SeedSandbox(orderCount, days int) (*SandboxSummary, error) {
+	log.Println("🌱 Seeding sandbox data into the database...")
+
+	if orderCount <= 0 || days <= 0 {
+		return nil, errors.New("order count and days must be greater than zero")
+	}
+
+	var channels []models.Channel
+	var stores []models.Store
+	var boxes []models.Box
+	var expeditions []models.Expedition
+	var location models.Location
+	DB.Find(&channels)
+	DB.Find(&stores)
+	DB.Find(&boxes)
+	DB.Find(&expeditions)
+	if len(channels) == 0 || len(stores) == 0 || len(boxes) == 0 || len(expeditions) == 0 {
+		return nil, errors.New("channels, stores, boxes and expeditions are missing, run the initial seeds first")
+	}
+	if err := DB.Order("id").First(&location).Error; err != nil {
+		return nil, errors.New("no location found, run the initial seeds first")
+	}
+	var rootCauses []models.ComplainRootCause
+	DB.Find(&rootCauses)
+	rootCauseIDs := make(map[string]*uint)
+	for i := range rootCauses {
+		rootCauseIDs[rootCauses[i].Code] = &rootCauses[i].ID
+	}
+
+	summary := &SandboxSummary{}
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	now := time.Now()
+	runPrefix := "SBX" + now.Format("060102150405")
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		// Users by role and username
+		usersByRole := make(map[string][]models.User)
+		usersByName := make(map[string]models.User)
+		hashedPassword, err := utils.HashPassword(SandboxPassword)
+		if err != nil {
+			return fmt.Errorf("failed to hash sandbox password: %w", err)
+		}
+		for _, userData := range sandboxUsers {
+			var user models.User
+			result := tx.Where("username = ?", userData.Username).First(&user)
+			if result.Error == gorm.ErrRecordNotFound {
+				var role models.Role
+				if err := tx.Where("role_name = ?", userData.RoleName).First(&role).Error; err != nil {
+					return fmt.Errorf("%s role not found. Please seed roles first: %w", userData.RoleName, err)
+				}
+				user = models.User{
+					Username: userData.Username,
+					Password: hashedPassword,
+					FullName: userData.FullName,
+					Email:    userData.Username + "@sandbox.local",
+					IsActive: true,
+				}
+				if err := tx.Create(&user).Error; err != nil {
+					return fmt.Errorf("failed to create user %s: %w", userData.Username, err)
+				}
+				if err := tx.Exec("INSERT INTO user_roles (user_id, role_id) VALUES (?, ?)", user.ID, role.ID).Error; err != nil {
+					return fmt.Errorf("failed to assign role to user %s: %w", userData.Username, err)
+				}
+				summary.Users++
+			} else if result.Error != nil {
+				return result.Error
+			}
+			usersByRole[userData.RoleName] = append(usersByRole[userData.RoleName], user)
+			usersByName[user.Username] = user
+		}
+		pickUser := func() models.User {
+			pickers := usersByRole["picker"]
+			return pickers[random.Intn(len(pickers))]
+		}
+
+		// Products
+		for _, productData := range sandboxProducts {
+			product := productData
+			result := tx.Where("sku = ?", product.SKU).FirstOrCreate(&product)
+			if result.Error != nil {
+				return fmt.Errorf("failed to create product %s: %w", product.SKU, result.Error)
+			}
+			summary.Products += int(result.RowsAffected)
+		}
+
+		// Orders with their picking, QC and outbound records
+		totalWeight := 0
+		for _, scenario := range sandboxScenarios {
+			totalWeight += scenario.Weight
+		}
+		var shipped []models.Order
+		for i := 0; i < orderCount; i++ {
+			// Walk every scenario once before picking by weight so each status is present
+			scenario := sandboxScenarios[i%len(sandboxScenarios)]
+			if i >= len(sandboxScenarios) {
+				pick := random.Intn(totalWeight)
+				for _, candidate := range sandboxScenarios {
+					if pick < candidate.Weight {
+						scenario = candidate
+						break
+					}
+					pick -= candidate.Weight
+				}
+			}
+
+			// Open orders are recent, finished ones spread over the whole period
+			age := time.Duration(random.Int63n(int64(days) * int64(24*time.Hour)))
+			if scenario.EventStatus != models.EventStatusCompleted && scenario.ProcessingStatus != models.ProcessingStatusOutboundCompleted {
+				age = time.Duration(random.Int63n(int64(8 * time.Hour)))
+			}
+			createdAt := now.Add(-age)
+			channel := channels[random.Intn(len(channels))]
+			expedition := expeditions[random.Intn(len(expeditions))]
+
+			order := models.Order{
+				OrderGineeID:     fmt.Sprintf("%s%05d", runPrefix, i+1),
+				ProcessingStatus: scenario.ProcessingStatus,
+				EventStatus:      scenario.EventStatus,
+				Channel:          channel.ChannelName,
+				Store:            stores[random.Intn(len(stores))].StoreName,
+				Buyer:            sandboxBuyers[random.Intn(len(sandboxBuyers))],
+				Address:          sandboxAddresses[random.Intn(len(sandboxAddresses))],
+				Courier:          expedition.ExpeditionName,
+				TrackingNumber:   fmt.Sprintf("%sTN%05d", runPrefix, i+1),
+				SentBefore:       createdAt.Add(24 * time.Hour),
+				Currency:         "IDR",
+				CreatedAt:        createdAt,
+				UpdatedAt:        createdAt,
+			}
+
+			used := make(map[int]bool)
+			for lines := 1 + random.Intn(3); len(order.OrderDetails) < lines; {
+				index := random.Intn(len(sandboxProducts))
+				if used[index] {
+					continue
+				}
+				used[index] = true
+				product := sandboxProducts[index]
+				order.OrderDetails = append(order.OrderDetails, models.OrderDetail{
+					SKU:         product.SKU,
+					ProductName: product.Name,
+					Variant:     product.Variant,
+					Quantity:    1 + random.Intn(3),
+					Price:       (50 + random.Intn(550)) * 500,
+				})
+			}
+
+			// Fill the audit trail of the stages the order went through
+			stage := scenario.ProcessingStatus
+			picked := stage == models.ProcessingStatusPickingCompleted || stage == models.ProcessingStatusQCProgress ||
+				stage == models.ProcessingStatusQCCompleted || stage == models.ProcessingStatusOutboundCompleted
+			picker := pickUser()
+			assignedAt := createdAt.Add(time.Duration(10+random.Intn(50)) * time.Minute)
+			pickedAt := assignedAt.Add(time.Duration(5+random.Intn(25)) * time.Minute)
+			qcAt := pickedAt.Add(time.Duration(5+random.Intn(40)) * time.Minute)
+			outboundAt := qcAt.Add(time.Duration(10+random.Intn(120)) * time.Minute)
+			if stage != models.ProcessingStatusReadyToPick {
+				coordinator := usersByRole["coordinator"][0]
+				order.AssignedBy, order.AssignedAt = &coordinator.ID, &assignedAt
+				order.PickedBy = &picker.ID
+			}
+			if picked {
+				order.PickedAt = &pickedAt
+				for d := range order.OrderDetails {
+					order.OrderDetails[d].PickedQuantity = order.OrderDetails[d].Quantity
+					order.OrderDetails[d].IsPicked = true
+					order.OrderDetails[d].ItemPickedAt = &pickedAt
+				}
+			}
+			if stage == models.ProcessingStatusPickingPending {
+				order.PendingBy, order.PendingAt = &picker.ID, &pickedAt
+			}
+			if stage == models.ProcessingStatusAwaitingStock {
+				order.OrderDetails[0].IsShortage = true
+				order.OrderDetails[0].ShortageReason = "Bin empty"
+			}
+			admin := usersByRole["admin"][0]
+			switch scenario.EventStatus {
+			case models.EventStatusHeld:
+				order.HeldBy, order.HeldAt = &admin.ID, &assignedAt
+				order.HoldReason = "Confirming delivery address with buyer"
+			case models.EventStatusCanceled:
+				order.CanceledBy, order.CanceledAt = &admin.ID, &assignedAt
+				for d := range order.OrderDetails {
+					order.OrderDetails[d].Quantity = 0
+				}
+			case models.EventStatusDuplicated:
+				order.DuplicatedBy, order.DuplicatedAt = &admin.ID, &assignedAt
+			}
+			order.CalculateTotals()
+
+			if err := tx.Create(&order).Error; err != nil {
+				return fmt.Errorf("failed to create order %s: %w", order.OrderGineeID, err)
+			}
+			summary.Orders++
+
+			if picked {
+				pickedOrder := models.PickedOrder{OrderID: order.ID, PickedBy: picker.ID, CreatedAt: pickedAt, UpdatedAt: pickedAt}
+				if err := tx.Create(&pickedOrder).Error; err != nil {
+					return fmt.Errorf("failed to create picked order %s: %w", order.OrderGineeID, err)
+				}
+			}
+
+			// QC on the channel's lane
+			if stage == models.ProcessingStatusQCProgress || stage == models.ProcessingStatusQCCompleted || stage == models.ProcessingStatusOutboundCompleted {
+				status := models.QCStatusCompleted
+				if stage == models.ProcessingStatusQCProgress {
+					status = models.QCStatusInProgress
+				}
+				box := boxes[random.Intn(len(boxes))]
+				if channel.QCLane == "ribbon" {
+					qc := models.QCRibbon{
+						TrackingNumber:  order.TrackingNumber,
+						QCBy:            usersByRole["qc-ribbon"][0].ID,
+						Status:          status,
+						CreatedAt:       qcAt,
+						UpdatedAt:       qcAt,
+						QCRibbonDetails: []models.QCRibbonDetail{{BoxID: box.ID, Quantity: 1}},
+					}
+					if err := tx.Create(&qc).Error; err != nil {
+						return fmt.Errorf("failed to create QC ribbon %s: %w", order.TrackingNumber, err)
+					}
+				} else {
+					qc := models.QCOnline{
+						TrackingNumber:  order.TrackingNumber,
+						QCBy:            usersByRole["qc-online"][0].ID,
+						Status:          status,
+						CreatedAt:       qcAt,
+						UpdatedAt:       qcAt,
+						QCOnlineDetails: []models.QCOnlineDetail{{BoxID: box.ID, Quantity: 1}},
+					}
+					if err := tx.Create(&qc).Error; err != nil {
+						return fmt.Errorf("failed to create QC online %s: %w", order.TrackingNumber, err)
+					}
+				}
+				summary.QC++
+			}
+
+			if stage == models.ProcessingStatusOutboundCompleted {
+				outbound := models.Outbound{
+					TrackingNumber:  order.TrackingNumber,
+					OutboundBy:      usersByRole["outbound"][0].ID,
+					Expedition:      expedition.ExpeditionName,
+					ExpeditionSlug:  expedition.ExpeditionSlug,
+					ExpeditionColor: expedition.ExpeditionColor,
+					CreatedAt:       outboundAt,
+					UpdatedAt:       outboundAt,
+				}
+				if err := tx.Create(&outbound).Error; err != nil {
+					return fmt.Errorf("failed to create outbound %s: %w", order.TrackingNumber, err)
+				}
+				summary.Outbounds++
+				shipped = append(shipped, order)
+			}
+		}
+
+		// Complains on a few shipped orders, charged to the picker
+		complainCount := len(shipped) / 20
+		if complainCount == 0 && len(shipped) > 0 {
+			complainCount = 1
+		}
+		for _, index := range random.Perm(len(shipped))[:complainCount] {
+			order := shipped[index]
+			var channelID, storeID uint
+			for _, channel := range channels {
+				if channel.ChannelName == order.Channel {
+					channelID = channel.ID
+				}
+			}
+			for _, store := range stores {
+				if store.StoreName == order.Store {
+					storeID = store.ID
+				}
+			}
+			detail := order.OrderDetails[0]
+			reason := sandboxComplainReasons[random.Intn(len(sandboxComplainReasons))]
+			complain := models.Complain{
+				Code:           utils.GenerateComplainCode(tx, "sandbox", ""),
+				TrackingNumber: order.TrackingNumber,
+				OrderGineeID:   order.OrderGineeID,
+				ChannelID:      channelID,
+				StoreID:        storeID,
+				CreatedBy:      usersByRole["admin"][0].ID,
+				Reason:         reason.Reason,
+				RootCauseID:    rootCauseIDs[reason.RootCause],
+				ComplainProductDetails: []models.ComplainProductDetail{
+					{ProductSKU: detail.SKU, Quantity: 1, Price: detail.Price},
+				},
+				ComplainUserDetails: []models.ComplainUserDetail{
+					{UserID: *order.PickedBy, FeeCharge: detail.Price / 2},
+				},
+			}
+			if err := tx.Create(&complain).Error; err != nil {
+				return fmt.Errorf("failed to create complain %s: %w", order.TrackingNumber, err)
+			}
+			if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Update("complained", true).Error; err != nil {
+				return err
+			}
+			for _, model := range []interface{}{&models.QCRibbon{}, &models.QCOnline{}, &models.Outbound{}} {
+				if err := tx.Model(model).Where("tracking_number = ?", order.TrackingNumber).Update("complained", true).Error; err != nil {
+					return err
+				}
+			}
+			summary.Complains++
+		}
+
+		// Attendance history on working days, skipped for days a user already has one
+		for _, userData := range sandboxUsers {
+			user := usersByName[userData.Username]
+			for day := days; day >= 1; day-- {
+				date := now.AddDate(0, 0, -day)
+				if date.Weekday() == time.Sunday || random.Intn(20) == 0 {
+					continue
+				}
+				startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+				var existing int64
+				tx.Model(&models.Attendance{}).Where("user_id = ? AND checked_in >= ? AND checked_in < ?", user.ID, startOfDay, startOfDay.AddDate(0, 0, 1)).Count(&existing)
+				if existing > 0 {
+					continue
+				}
+
+				workStart := startOfDay.Add(8 * time.Hour)
+				checkedIn := workStart.Add(time.Duration(random.Intn(35)-20) * time.Minute)
+				checkedOut := startOfDay.Add(17*time.Hour + time.Duration(random.Intn(60))*time.Minute)
+				late := 0
+				if checkedIn.After(workStart) {
+					late = int(checkedIn.Sub(workStart).Minutes())
+				}
+				attendance := models.Attendance{
+					UserID:      user.ID,
+					Status:      "fullday",
+					Late:        late,
+					LocationID:  location.ID,
+					Latitude:    location.Latitude,
+					Longitude:   location.Longitude,
+					Accuracy:    float64(3 + random.Intn(15)),
+					CheckedIn:   checkedIn,
+					CheckedOut:  &checkedOut,
+					Checked:     true,
+					DeviceID:    "sandbox-" + user.Username,
+					EntryMethod: models.EntryMethodMobile,
+				}
+				attendance.SetOvertime(int(checkedOut.Sub(startOfDay.Add(17 * time.Hour)).Minutes()))
+				// Overtime older than a week has been approved already
+				if attendance.OvertimeStatus == models.OvertimeStatusPending && day > 7 {
+					attendance.OvertimeStatus = models.OvertimeStatusApproved
+					attendance.ApprovedOvertime = attendance.Overtime
+				}
+				if err := tx.Create(&attendance).Error; err != nil {
+					return fmt.Errorf("failed to create attendance of %s: %w", user.Username, err)
+				}
+				summary.Attendances++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("✅ Sandbox seeding completed successfully")
+	return summary, nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"livo-fiber-backend/utils"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+// maxRecentSlowQueries is the number of recent slow queries kept in memory
+const maxRecentSlowQueries = 50
+
+// SlowQueryEntry represents a single query slower than the threshold
+type SlowQueryEntry struct {
+	Route      string  `json:"route"`
+	SQL        string  `json:"sql"`
+	Rows       int64   `json:"rows"`
+	DurationMs float64 `json:"durationMs"`
+	OccurredAt string  `json:"occurredAt"`
+}
+
+// SlowQueryRouteCount represents the number of slow queries triggered by a route
+type SlowQueryRouteCount struct {
+	Route string `json:"route"`
+	Count int64  `json:"count"`
+}
+
+// SlowQueryStats represents the slow query counters exposed on the metrics endpoint
+type SlowQueryStats struct {
+	ThresholdMs int64                 `json:"thresholdMs"`
+	Total       int64                 `json:"total"`
+	ByRoute     []SlowQueryRouteCount `json:"byRoute"`
+	Recent      []SlowQueryEntry      `json:"recent"`
+}
+
+// SlowQueryLogger wraps a GORM logger and records queries slower than the threshold with their route
+type SlowQueryLogger struct {
+	logger.Interface
+	threshold time.Duration
+	counters  *slowQueryCounters
+}
+
+// slowQueryCounters holds the slow query counters shared by all copies of a SlowQueryLogger
+type slowQueryCounters struct {
+	mu      sync.Mutex
+	total   int64
+	byRoute map[string]int64
+	recent  []SlowQueryEntry
+}
+
+// slowQueryLogger is the active slow query logger, set on connect
+var slowQueryLogger *SlowQueryLogger
+
+// NewSlowQueryLogger creates a slow query logger wrapping the given GORM logger
+func NewSlowQueryLogger(base logger.Interface, threshold time.Duration) *SlowQueryLogger {
+	return &SlowQueryLogger{
+		Interface: base,
+		threshold: threshold,
+		counters:  &slowQueryCounters{byRoute: make(map[string]int64)},
+	}
+}
+
+// LogMode returns a copy of the slow query logger with the wrapped logger set to the given level
+func (l *SlowQueryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return &SlowQueryLogger{
+		Interface: l.Interface.LogMode(level),
+		threshold: l.threshold,
+		counters:  l.counters,
+	}
+}
+
+// Trace records the query as slow if it exceeds the threshold and delegates to the wrapped logger
+func (l *SlowQueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	if l.threshold > 0 && elapsed >= l.threshold {
+		sql, rows := fc()
+		route := utils.RouteFromContext(ctx)
+		if route == "" {
+			route = "background"
+		}
+
+		counters := l.counters
+		counters.mu.Lock()
+		counters.total++
+		counters.byRoute[route]++
+		counters.recent = append(counters.recent, SlowQueryEntry{
+			Route:      route,
+			SQL:        sql,
+			Rows:       rows,
+			DurationMs: float64(elapsed.Microseconds()) / 1000,
+			OccurredAt: begin.Format("02-01-2006 15:04:05"),
+		})
+		if len(counters.recent) > maxRecentSlowQueries {
+			counters.recent = counters.recent[len(counters.recent)-maxRecentSlowQueries:]
+		}
+		counters.mu.Unlock()
+
+		log.Printf("Slow query (%s) on %s [rows:%d]: %s\n", elapsed, route, rows, sql)
+	}
+
+	l.Interface.Trace(ctx, begin, fc, err)
+}
+
+// Stats returns a snapshot of the slow query counters
+func (l *SlowQueryLogger) Stats() SlowQueryStats {
+	counters := l.counters
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+
+	stats := SlowQueryStats{
+		ThresholdMs: l.threshold.Milliseconds(),
+		Total:       counters.total,
+		ByRoute:     make([]SlowQueryRouteCount, 0, len(counters.byRoute)),
+		Recent:      make([]SlowQueryEntry, len(counters.recent)),
+	}
+	for route, count := range counters.byRoute {
+		stats.ByRoute = append(stats.ByRoute, SlowQueryRouteCount{Route: route, Count: count})
+	}
+	sort.Slice(stats.ByRoute, func(i, j int) bool {
+		return stats.ByRoute[i].Count > stats.ByRoute[j].Count
+	})
+
+	// Most recent slow queries first
+	for i, entry := range counters.recent {
+		stats.Recent[len(counters.recent)-1-i] = entry
+	}
+
+	return stats
+}
+
+// GetSlowQueryStats returns the slow query counters of the active database connection
+func GetSlowQueryStats() SlowQueryStats {
+	if slowQueryLogger == nil {
+		return SlowQueryStats{ByRoute: []SlowQueryRouteCount{}, Recent: []SlowQueryEntry{}}
+	}
+	return slowQueryLogger.Stats()
+}
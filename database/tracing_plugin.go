@@ -0,0 +1,96 @@
+package database
+
+import (
+	"errors"
+	"livo-fiber-backend/utils"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "otel:span"
+
+// TracingPlugin creates a span for every query executed with a context that carries a trace.
+// Queries without a traced context (background jobs, code not using WithContext) are not traced.
+type TracingPlugin struct{}
+
+func (p *TracingPlugin) Name() string {
+	return "otel-tracing"
+}
+
+func (p *TracingPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("otel:before_create", p.before("gorm.create")); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("otel:after_create", p.after); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("otel:before_query", p.before("gorm.query")); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register("otel:after_query", p.after); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("otel:before_update", p.before("gorm.update")); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("otel:after_update", p.after); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("otel:before_delete", p.before("gorm.delete")); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register("otel:after_delete", p.after); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("otel:before_row", p.before("gorm.row")); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().After("gorm:row").Register("otel:after_row", p.after); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("otel:before_raw", p.before("gorm.raw")); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register("otel:after_raw", p.after)
+}
+
+func (p *TracingPlugin) before(spanName string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil || !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+			return
+		}
+
+		ctx, span := otel.Tracer(utils.TracerName).Start(ctx, spanName, trace.WithSpanKind(trace.SpanKindClient))
+		db.Statement.Context = ctx
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func (p *TracingPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.String("db.sql.table", db.Statement.Table),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,12 @@
+package public
+
+import _ "embed"
+
+// SwaggerJSON and SwaggerYAML are the public spec generated by swag, served at /docs/public.
+// Regenerate them with: swag init -o docs/public --instanceName public --tags "Authentication,Public Tracking"
+//
+//go:embed public_swagger.json
+var SwaggerJSON []byte
+
+//go:embed public_swagger.yaml
+var SwaggerYAML []byte
@@ -1,22 +1,24 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"os"
-	"strings"
 
 	"time"
 
+	"livo-fiber-backend/cli"
 	"livo-fiber-backend/config"
+	"livo-fiber-backend/controllers"
 	"livo-fiber-backend/database"
 	_ "livo-fiber-backend/docs" // Import generated docs
+	"livo-fiber-backend/middleware"
 	"livo-fiber-backend/routes"
+	"livo-fiber-backend/utils"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/gofiber/fiber/v3/middleware/cors"
 	"github.com/gofiber/fiber/v3/middleware/helmet"
-	"github.com/gofiber/fiber/v3/middleware/limiter"
 	"github.com/gofiber/fiber/v3/middleware/logger"
 	"github.com/gofiber/fiber/v3/middleware/recover"
 	"github.com/joho/godotenv"
@@ -37,24 +39,6 @@ import (
 // @BasePath /
 // @schemes http https
 
-// matchOriginPattern checks if an origin matches a pattern with wildcards
-func matchOriginPattern(pattern, origin string) bool {
-	// Convert pattern to regex-like matching
-	// Replace * with a regex pattern that matches any characters except colon and slash
-	if !strings.Contains(pattern, "*") {
-		return false
-	}
-
-	// Split by wildcard
-	parts := strings.Split(pattern, "*")
-	if len(parts) != 2 {
-		return false
-	}
-
-	// Check if origin starts with the part before * and ends with the part after *
-	return strings.HasPrefix(origin, parts[0]) && strings.HasSuffix(origin, parts[1])
-}
-
 func main() {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
@@ -64,9 +48,21 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Run an admin subcommand instead of the server when one is given
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(cfg, os.Args[1:]))
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := utils.InitTracing(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Initialize database
 	database.ConnectDatabase(cfg)
-	database.MigrateDatabase()
+	database.MigrateDatabase(cfg)
 	database.SeedInitialRole()
 	database.SeedInitialBox()
 	database.SeedInitialChannel()
@@ -74,10 +70,107 @@ func main() {
 	database.SeedInitialStore()
 	database.SeedInitialUser()
 	database.SeedInitialLocation()
+	database.SeedInitialComplainRootCause()
 
 	// Get database instance
 	database.GetDB()
 
+	// Set up file storage and the temp directory of uploads
+	if err := utils.InitStorage(cfg); err != nil {
+		log.Fatalf("Failed to initialize file storage: %v", err)
+	}
+
+	// Load the warehouse operating hours SLA and aging durations are counted in
+	if err := utils.InitBusinessCalendar(cfg); err != nil {
+		log.Fatalf("Invalid business calendar: %v", err)
+	}
+
+	// Load the token keys before any token is issued or validated
+	if err := utils.InitTokenKeys(cfg); err != nil {
+		log.Fatalf("Failed to load token keys: %v", err)
+	}
+
+	// Load the thresholds security events raise alerts at
+	utils.InitSecurityAlertRules(cfg)
+
+	// Report panics and server errors when a DSN is configured
+	if err := utils.InitErrorReporting(cfg); err != nil {
+		log.Fatalf("Invalid error reporting configuration: %v", err)
+	}
+
+	// Evaluate feature flags against the running environment
+	utils.InitFeatureFlags(cfg)
+
+	// Load the staging fault injection rules
+	if err := utils.InitFaultInjection(cfg); err != nil {
+		log.Fatalf("Invalid fault injection configuration: %v", err)
+	}
+
+	// Start in maintenance mode when configured
+	if cfg.MaintenanceMode {
+		utils.SetMaintenance(true, cfg.MaintenanceMessage, "config")
+	}
+
+	// Start scheduled data retention purge
+	if cfg.RetentionPurgeIntervalHours > 0 {
+		utils.StartRetentionScheduler(database.DB, utils.RetentionPolicyFromConfig(cfg), time.Duration(cfg.RetentionPurgeIntervalHours)*time.Hour)
+	}
+
+	// Start scheduled SentBefore breach detection
+	if cfg.SLABreachCheckMinutes > 0 {
+		utils.StartSLABreachScheduler(database.DB, time.Duration(cfg.SLABreachCheckMinutes)*time.Minute)
+	}
+
+	// Start scheduled refresh of the daily dashboard aggregates
+	if cfg.DailyAggregateIntervalMinutes > 0 {
+		utils.StartDailyAggregateScheduler(database.DB, time.Duration(cfg.DailyAggregateIntervalMinutes)*time.Minute, cfg.DailyAggregateLookbackDays)
+	}
+
+	// Start the nightly missing attendance checkout check
+	if cfg.MissingCheckoutHour >= 0 && cfg.MissingCheckoutHour < 24 {
+		utils.StartMissingCheckoutScheduler(database.DB, cfg.MissingCheckoutHour, cfg.AttendanceAutoClose, utils.BreakPolicyFromConfig(cfg))
+	}
+
+	// Start scheduled report email delivery
+	if cfg.ReportScheduleCheckMinutes > 0 {
+		controllers.StartReportScheduler(cfg, database.DB, time.Duration(cfg.ReportScheduleCheckMinutes)*time.Minute)
+	}
+
+	// Start persisting access logs
+	if cfg.AccessLogEnabled {
+		utils.StartAccessLogWriter(database.DB, cfg.AccessLogRetentionDays)
+	}
+
+	// Start purging change feed events past their retention
+	if cfg.ChangeFeedRetentionDays > 0 {
+		utils.StartChangeFeedPurgeScheduler(database.DB, cfg.ChangeFeedRetentionDays)
+	}
+
+	// Start purging logged inbound webhooks past their retention
+	if cfg.WebhookEventRetentionDays > 0 {
+		utils.StartWebhookEventPurgeScheduler(database.DB, cfg.WebhookEventRetentionDays)
+	}
+
+	// Start delivering order events to the webhook subscriptions
+	if cfg.WebhookDeliveryIntervalSeconds > 0 {
+		utils.StartWebhookDeliveryWorker(cfg, database.DB, time.Duration(cfg.WebhookDeliveryIntervalSeconds)*time.Second)
+	}
+
+	// Start pushing outbound scanned orders to the accounting systems
+	if cfg.AccountingSyncIntervalSeconds > 0 {
+		utils.StartAccountingSyncWorker(cfg, database.DB, time.Duration(cfg.AccountingSyncIntervalSeconds)*time.Second)
+	}
+
+	// Start alerting on order volume spikes and drops per store and channel
+	if cfg.OrderVolumeCheckMinutes > 0 {
+		utils.StartOrderVolumeScheduler(database.DB, utils.OrderVolumePolicyFromConfig(cfg), time.Duration(cfg.OrderVolumeCheckMinutes)*time.Minute)
+	}
+
+	// Start removing temp files orphaned by interrupted requests
+	if cfg.TempCleanupIntervalMinutes > 0 {
+		utils.StartTempCleanupWorker(time.Duration(cfg.TempCleanupIntervalMinutes)*time.Minute, time.Duration(cfg.TempFileMaxAgeMinutes)*time.Minute)
+	}
+
 	// Create or open log file
 	logFile, err := os.OpenFile("./log.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
@@ -101,51 +194,34 @@ func main() {
 				"error": err.Error(),
 			})
 		},
+		BodyLimit:    cfg.MaxBodySizeMB * 1024 * 1024,
 		AppName:      "Livotech Warehouse Management System API Documentation",
 		ServerHeader: "Fiber",
 	})
 
 	// Global middleware
-	app.Use(recover.New())
+	app.Use(recover.New(recover.Config{EnableStackTrace: true, StackTraceHandler: utils.CapturePanic}))
 	app.Use(logger.New())
 	app.Use(helmet.New())
-
-	// Configure CORS based on origins
-	corsConfig := cors.Config{
-		AllowHeaders:  []string{"Origin", "Content-Type", "Accept", "Authorization", "X-CSRF-Token", "X-Requested-With"},
-		AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-		ExposeHeaders: []string{"Content-Length", "Content-Type"},
-		MaxAge:        86400, // 24 hours
-	}
-
-	// If origins contain wildcard, don't use credentials
-	if len(cfg.CorsOrigins) == 1 && cfg.CorsOrigins[0] == "*" {
-		corsConfig.AllowOrigins = []string{"*"}
-		corsConfig.AllowCredentials = false
-	} else {
-		// Use custom origin validator to support wildcard patterns
-		corsConfig.AllowOriginsFunc = func(origin string) bool {
-			// Check each configured origin
-			for _, allowedOrigin := range cfg.CorsOrigins {
-				// Exact match
-				if origin == allowedOrigin {
-					return true
-				}
-				// Pattern match (e.g., http://192.168.41.*:8081)
-				if matchOriginPattern(allowedOrigin, origin) {
-					return true
-				}
-			}
-			return false
-		}
-		corsConfig.AllowCredentials = true
+	app.Use(middleware.TracingMiddleware())
+	app.Use(middleware.ErrorReportingMiddleware())
+	app.Use(middleware.RouteContextMiddleware())
+	if cfg.AccessLogEnabled {
+		app.Use(middleware.AccessLogMiddleware())
 	}
+	app.Use(middleware.TimeoutMiddleware(cfg))
+	app.Use(middleware.FaultInjectionMiddleware())
+	app.Use(middleware.MaintenanceMiddleware())
 
-	app.Use(cors.New(corsConfig))
-	app.Use(limiter.New(limiter.Config{
-		Max:        100,
-		Expiration: 60 * time.Second,
-	}))
+	// Configure CORS per route group from the CORS profile
+	corsPolicy, err := utils.CORSPolicyFromConfig(cfg)
+	if err != nil {
+		log.Fatalf("Invalid CORS configuration: %v", err)
+	}
+	for _, corsHandler := range middleware.CORSMiddleware(corsPolicy) {
+		app.Use(corsHandler)
+	}
+	app.Use(middleware.RateLimitMiddleware(cfg))
 
 	// Setup routes
 	routes.SetupRoutes(app, cfg, database.DB)
@@ -154,7 +230,7 @@ func main() {
 	log.Println("════════════════════════════════════════════════════════════")
 	log.Printf("✓ Server ready on port %s", cfg.Port)
 	log.Printf("📊 Health check: %s/api/health", cfg.AppUrl)
-	log.Printf("📚 API documentation: %s/rapidoc", cfg.AppUrl)
+	log.Printf("📚 API documentation: %s/rapidoc (internal), %s/docs/public (public)", cfg.AppUrl, cfg.AppUrl)
 	log.Println("════════════════════════════════════════════════════════════")
 
 	// port := fmt.Sprintf(":%s", cfg.Port)
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// AccessLogMiddleware persists every API request with its route pattern, status, duration, user and IP.
+// Documentation pages, health checks and CORS preflight requests are not recorded.
+func AccessLogMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		path := c.Path()
+		if c.Method() == fiber.MethodOptions || !strings.HasPrefix(path, "/api/") || path == "/api/health" {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+		duration := time.Since(start)
+
+		status := c.Response().StatusCode()
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			status = fiberErr.Code
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+
+		// Requests stopped by a middleware report the path the middleware is mounted on
+		route := c.Route().Path
+		if !strings.HasPrefix(route, "/api/") {
+			route = models.AccessLogUnroutedRoute
+		}
+
+		// Request strings point into buffers reused after the response, the entry is persisted later
+		entry := models.AccessLog{
+			Method:     strings.Clone(c.Method()),
+			Route:      strings.Clone(route),
+			Path:       strings.Clone(path),
+			Status:     status,
+			DurationMs: float64(duration.Microseconds()) / 1000,
+			IPAddress:  strings.Clone(c.IP()),
+			CreatedAt:  start,
+		}
+		if userID, ok := c.Locals("userId").(string); ok {
+			if parsed, parseErr := strconv.ParseUint(userID, 10, 32); parseErr == nil {
+				id := uint(parsed)
+				entry.UserID = &id
+			}
+		}
+		utils.RecordAccessLog(entry)
+
+		return err
+	}
+}
@@ -10,6 +10,11 @@ import (
 
 func AuthMiddleware(cfg *config.Config) fiber.Handler {
 	return func(c fiber.Ctx) error {
+		// External systems authenticate with an API key instead of a JWT
+		if apiKey := c.Get(APIKeyHeader); apiKey != "" {
+			return authenticateAPIKey(c, apiKey)
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
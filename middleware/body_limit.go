@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// BodyLimitMiddleware rejects requests whose body exceeds the given size in megabytes.
+// It narrows the global body limit for routes that accept uploads.
+func BodyLimitMiddleware(maxMB int) fiber.Handler {
+	maxBytes := maxMB * 1024 * 1024
+	return func(c fiber.Ctx) error {
+		if maxBytes > 0 && (c.Request().Header.ContentLength() > maxBytes || len(c.Body()) > maxBytes) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+				"error": fmt.Sprintf("Request body exceeds the %d MB limit", maxMB),
+			})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/limiter"
+)
+
+// AuthRateLimitMiddleware is a stricter per-IP rate limiter for a credential-checking endpoint,
+// independent from the global limiter
+func AuthRateLimitMiddleware(cfg *config.Config, scope string) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        cfg.AuthRateLimitPerMinute,
+		Expiration: time.Minute,
+		KeyGenerator: func(c fiber.Ctx) string {
+			return scope + "|" + c.IP()
+		},
+		LimitReached: func(c fiber.Ctx) error {
+			utils.RecordRateLimited(scope)
+			return c.Status(fiber.StatusTooManyRequests).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Too many attempts, please slow down",
+			})
+		},
+	})
+}
+
+// PublicTrackRateLimitMiddleware is a per-IP rate limiter for the unauthenticated buyer tracking lookup,
+// independent from the global limiter
+func PublicTrackRateLimitMiddleware(cfg *config.Config) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        cfg.PublicTrackRateLimitPerMinute,
+		Expiration: time.Minute,
+		KeyGenerator: func(c fiber.Ctx) string {
+			return "public_track|" + c.IP()
+		},
+		LimitReached: func(c fiber.Ctx) error {
+			utils.RecordRateLimited("public_track")
+			return c.Status(fiber.StatusTooManyRequests).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Too many lookups, please slow down",
+			})
+		},
+	})
+}
+
+// DownloadRateLimitMiddleware is a per-IP rate limiter for signed file downloads, which need no bearer token
+func DownloadRateLimitMiddleware(cfg *config.Config) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        cfg.DownloadRateLimitPerMinute,
+		Expiration: time.Minute,
+		KeyGenerator: func(c fiber.Ctx) string {
+			return "download|" + c.IP()
+		},
+		LimitReached: func(c fiber.Ctx) error {
+			utils.RecordRateLimited("download")
+			return c.Status(fiber.StatusTooManyRequests).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Too many downloads, please slow down",
+			})
+		},
+	})
+}
+
+// PublicTrackGuardMiddleware temporarily bans an IP from the buyer tracking lookup after repeated lookups of
+// unknown or malformed tracking numbers, so tracking numbers cannot be enumerated.
+// A 400 or 404 response from the handler counts as a miss.
+func PublicTrackGuardMiddleware(cfg *config.Config) fiber.Handler {
+	window := time.Duration(cfg.AuthFailureWindowMinutes) * time.Minute
+	ban := time.Duration(cfg.AuthBanMinutes) * time.Minute
+
+	return func(c fiber.Ctx) error {
+		ip := c.IP()
+		if until := utils.LoginBannedUntil("public_track", "", ip); !until.IsZero() {
+			retryAfter := int(time.Until(until).Seconds()) + 1
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return c.Status(fiber.StatusTooManyRequests).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Too many unknown tracking numbers, try again in %d minutes", (retryAfter+59)/60),
+			})
+		}
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if status == fiber.StatusBadRequest || status == fiber.StatusNotFound {
+			utils.RecordLoginFailure("public_track", "", ip, cfg.PublicTrackMaxMisses, window, ban)
+		}
+		return err
+	}
+}
+
+// BruteForceMiddleware temporarily bans a username + IP on a credential-checking endpoint after repeated failed attempts.
+// A 401 or 404 response from the handler counts as a failed attempt, a 2xx response clears the count.
+// Requests approved with a one-time approval code instead of credentials are keyed on the authenticated user.
+func BruteForceMiddleware(cfg *config.Config, scope string) fiber.Handler {
+	window := time.Duration(cfg.AuthFailureWindowMinutes) * time.Minute
+	ban := time.Duration(cfg.AuthBanMinutes) * time.Minute
+
+	return func(c fiber.Ctx) error {
+		// Credentials are always sent as JSON with a username field
+		var body struct {
+			Username     string `json:"username"`
+			ApprovalCode string `json:"approvalCode"`
+		}
+		_ = json.Unmarshal(c.Body(), &body)
+		username := strings.ToLower(strings.TrimSpace(body.Username))
+		if username == "" && strings.TrimSpace(body.ApprovalCode) != "" {
+			if userID, ok := c.Locals("userId").(string); ok && userID != "" {
+				username = "approval-code:" + userID
+			}
+		}
+		if username == "" {
+			return c.Next()
+		}
+
+		ip := c.IP()
+		if until := utils.LoginBannedUntil(scope, username, ip); !until.IsZero() {
+			return bannedResponse(c, until)
+		}
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		switch {
+		case status == fiber.StatusUnauthorized || status == fiber.StatusNotFound:
+			utils.RecordLoginFailure(scope, username, ip, cfg.AuthMaxFailures, window, ban)
+		case status >= 200 && status < 300:
+			utils.RecordLoginSuccess(scope, username, ip)
+		}
+		return err
+	}
+}
+
+// PasswordResetGuardMiddleware temporarily bans an identifier + IP from the password reset endpoints after repeated
+// wrong OTPs. Mounted on both the OTP request and the reset, so a banned identifier cannot request new OTPs either.
+// A 400 response to a reset with an OTP counts as a failed attempt, only a successful reset clears the count.
+func PasswordResetGuardMiddleware(cfg *config.Config) fiber.Handler {
+	const scope = "password_reset"
+	window := time.Duration(cfg.AuthFailureWindowMinutes) * time.Minute
+	ban := time.Duration(cfg.AuthBanMinutes) * time.Minute
+
+	return func(c fiber.Ctx) error {
+		var body struct {
+			Identifier string `json:"identifier"`
+			OTP        string `json:"otp"`
+		}
+		_ = json.Unmarshal(c.Body(), &body)
+		identifier := strings.ToLower(strings.TrimSpace(body.Identifier))
+		if identifier == "" {
+			return c.Next()
+		}
+
+		ip := c.IP()
+		if until := utils.LoginBannedUntil(scope, identifier, ip); !until.IsZero() {
+			return bannedResponse(c, until)
+		}
+
+		err := c.Next()
+
+		// Requesting an OTP neither fails nor clears the count
+		if strings.TrimSpace(body.OTP) == "" {
+			return err
+		}
+		status := c.Response().StatusCode()
+		switch {
+		case status == fiber.StatusBadRequest:
+			utils.RecordLoginFailure(scope, identifier, ip, cfg.AuthMaxFailures, window, ban)
+		case status >= 200 && status < 300:
+			utils.RecordLoginSuccess(scope, identifier, ip)
+		}
+		return err
+	}
+}
+
+// bannedResponse rejects a request of a temporarily banned username + IP
+func bannedResponse(c fiber.Ctx, until time.Time) error {
+	retryAfter := int(time.Until(until).Seconds()) + 1
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return c.Status(fiber.StatusTooManyRequests).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   fmt.Sprintf("Too many failed attempts, try again in %d minutes", (retryAfter+59)/60),
+	})
+}
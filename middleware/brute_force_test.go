@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"livo-fiber-backend/config"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestPasswordResetGuardMiddleware(t *testing.T) {
+	cfg := &config.Config{AuthMaxFailures: 3, AuthFailureWindowMinutes: 15, AuthBanMinutes: 15}
+	guard := PasswordResetGuardMiddleware(cfg)
+
+	app := fiber.New()
+	app.Post("/forgot-password", guard, func(c fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Post("/reset-password", guard, func(c fiber.Ctx) error {
+		if strings.Contains(string(c.Body()), `"otp":"000000"`) {
+			return c.SendStatus(fiber.StatusBadRequest)
+		}
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	post := func(path, body string) int {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("POST %s failed: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	request := `{"identifier":"Guarded.User","channel":"whatsapp"}`
+	wrongOTP := `{"identifier":"guarded.user","otp":"000000","newPassword":"NewPass123","confirmNewPassword":"NewPass123"}`
+
+	// Requesting a new OTP between wrong guesses does not clear the count
+	for i := 0; i < cfg.AuthMaxFailures; i++ {
+		if status := post("/forgot-password", request); status != fiber.StatusOK {
+			t.Fatalf("OTP request %d: status = %d, want 200", i+1, status)
+		}
+		if status := post("/reset-password", wrongOTP); status != fiber.StatusBadRequest {
+			t.Fatalf("wrong OTP %d: status = %d, want 400", i+1, status)
+		}
+	}
+
+	if status := post("/reset-password", wrongOTP); status != fiber.StatusTooManyRequests {
+		t.Errorf("reset after %d wrong OTPs: status = %d, want 429", cfg.AuthMaxFailures, status)
+	}
+	if status := post("/forgot-password", request); status != fiber.StatusTooManyRequests {
+		t.Errorf("OTP request of a banned identifier: status = %d, want 429", status)
+	}
+	if status := post("/forgot-password", `{"identifier":"other.user","channel":"whatsapp"}`); status != fiber.StatusOK {
+		t.Errorf("OTP request of another identifier: status = %d, want 200", status)
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"livo-fiber-backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/cors"
+)
+
+// CORSMiddleware applies the CORS configuration of the route group each request belongs to.
+// Every group gets its own CORS handler that skips requests of the other groups.
+func CORSMiddleware(policy *utils.CORSPolicy) []fiber.Handler {
+	handlers := make([]fiber.Handler, 0, len(policy.Groups))
+	for i := range policy.Groups {
+		group := &policy.Groups[i]
+
+		corsConfig := cors.Config{
+			Next: func(c fiber.Ctx) bool {
+				return policy.GroupFor(c.Path()) != group
+			},
+			AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-CSRF-Token", "X-Requested-With", KioskKeyHeader},
+			AllowMethods: []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
+			ExposeHeaders: []string{"Content-Length", "Content-Type", "Content-Disposition",
+				HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset, HeaderRateLimitPolicy, fiber.HeaderRetryAfter},
+			AllowCredentials: group.AllowCredentials,
+			MaxAge:           86400, // 24 hours
+		}
+		if group.AllowsAnyOrigin() {
+			corsConfig.AllowOrigins = []string{"*"}
+		} else {
+			// Use custom origin validator to support wildcard patterns
+			corsConfig.AllowOriginsFunc = group.AllowsOrigin
+		}
+
+		handlers = append(handlers, cors.New(corsConfig))
+	}
+	return handlers
+}
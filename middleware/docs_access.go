@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// DocsAccessMiddleware protects the internal API documentation.
+// Access is granted with the configured docs API key (X-Docs-Key header or key query)
+// or with an access token of a user holding one of the allowed roles.
+func DocsAccessMiddleware(cfg *config.Config, allowedRoles []string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		// Docs API key
+		key := c.Get("X-Docs-Key")
+		if key == "" {
+			key = c.Query("key")
+		}
+		if cfg.DocsAPIKey != "" && key != "" && subtle.ConstantTimeCompare([]byte(key), []byte(cfg.DocsAPIKey)) == 1 {
+			return c.Next()
+		}
+
+		// Bearer access token with an allowed role
+		parts := strings.Split(c.Get("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			token, err := utils.ValidateToken(parts[1], cfg)
+			if err == nil {
+				tokenType, err := token.GetString("type")
+				if err == nil && tokenType == "access" {
+					var roles []string
+					if err := token.Get("roles", &roles); err == nil && hasRequiredRole(roles, allowedRoles) {
+						return c.Next()
+					}
+				}
+			}
+		}
+
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "API documentation requires an authorized token or docs API key",
+		})
+	}
+}
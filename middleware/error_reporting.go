@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"encoding/json"
+	"livo-fiber-backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ErrorReportingMiddleware reports requests ending in a server error that their handler did not report itself,
+// while the request span is still active so the report carries its trace
+func ErrorReportingMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		message := ""
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			}
+			message = err.Error()
+		}
+		if status < fiber.StatusInternalServerError {
+			return err
+		}
+
+		// Handlers answer with {"error": "..."}, report the message the client saw
+		if message == "" {
+			var body struct {
+				Error string `json:"error"`
+			}
+			json.Unmarshal(c.Response().Body(), &body)
+			message = body.Error
+		}
+		utils.CaptureResponseError(c, status, message)
+		return err
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"errors"
+	"livo-fiber-backend/utils"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// faultInjectedHeader tells the client which dependency a fault was injected into
+const faultInjectedHeader = "X-Fault-Injected"
+
+// faultInjectionExemptPrefixes never get faults so testers can always log in and switch fault injection off
+var faultInjectionExemptPrefixes = []string{
+	"/api/auth",
+	"/api/fault-injection",
+}
+
+// FaultInjectionMiddleware injects the faults of the staging fault rules matching the request. Database faults
+// fail the request as a database error would, before the handler runs or, for afterHandler rules, after its
+// writes are committed. DeepFace faults are carried in the request context to the DeepFace client.
+func FaultInjectionMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		for _, prefix := range faultInjectionExemptPrefixes {
+			if strings.HasPrefix(c.Path(), prefix) {
+				return c.Next()
+			}
+		}
+
+		rules := utils.MatchFaultRules(c.Method(), c.Path())
+		if len(rules) == 0 {
+			return c.Next()
+		}
+
+		parent := c.Context()
+		c.SetContext(utils.WithFaultRules(parent, rules))
+		defer c.SetContext(parent)
+
+		if err := utils.InjectFault(c.Context(), utils.FaultTargetDB); err != nil {
+			if !errors.Is(err, utils.ErrInjectedFault) {
+				return err
+			}
+			log.Println("FaultInjection - Failing request before handler:", c.Method(), c.Path())
+			return injectedDBFaultResponse(c)
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		if c.Response().StatusCode() < fiber.StatusBadRequest {
+			for _, rule := range rules {
+				if rule.Target == utils.FaultTargetDB && rule.AfterHandler && rule.Fail() {
+					log.Println("FaultInjection - Failing request after handler:", c.Method(), c.Path())
+					c.Response().ResetBody()
+					return injectedDBFaultResponse(c)
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// injectedDBFaultResponse answers like a handler whose database call failed
+func injectedDBFaultResponse(c fiber.Ctx) error {
+	c.Set(faultInjectedHeader, utils.FaultTargetDB)
+	return c.Status(fiber.StatusInternalServerError).JSON(utils.ErrorResponse{
+		Success: false,
+		Error:   "Injected database fault",
+	})
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"livo-fiber-backend/database"
+	"livo-fiber-backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// FeatureFlagMiddleware hides a route while its feature flag is off for the user. Routes whose flag is not set up
+// yet stay available when enabledByDefault is true.
+func FeatureFlagMiddleware(key string, enabledByDefault bool) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if utils.FeatureEnabled(database.DB, key, utils.FeatureSubjectFromContext(c), enabledByDefault) {
+			return c.Next()
+		}
+
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "This feature is not available",
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"livo-fiber-backend/config"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// KioskKeyHeader is the header the attendance kiosk sends its key in
+const KioskKeyHeader = "X-Kiosk-Key"
+
+// KioskKeyMiddleware protects kiosk endpoints with the configured kiosk API key.
+// Kiosk endpoints are unavailable while no key is configured.
+func KioskKeyMiddleware(cfg *config.Config) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if cfg.KioskAPIKey == "" {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Attendance kiosk is not configured",
+			})
+		}
+
+		key := c.Get(KioskKeyHeader)
+		if key == "" || subtle.ConstantTimeCompare([]byte(key), []byte(cfg.KioskAPIKey)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid or missing kiosk key",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"livo-fiber-backend/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// maintenanceExemptPrefixes stay writable in maintenance mode so admins can still log in and switch it off
+var maintenanceExemptPrefixes = []string{
+	"/api/auth",
+	"/api/maintenance",
+}
+
+// MaintenanceMiddleware rejects mutating requests with 503 while maintenance mode is enabled.
+// Read-only requests (GET, HEAD, OPTIONS) are always allowed.
+func MaintenanceMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		state := utils.GetMaintenance()
+		if !state.Enabled {
+			return c.Next()
+		}
+
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		for _, prefix := range maintenanceExemptPrefixes {
+			if strings.HasPrefix(c.Path(), prefix) {
+				return c.Next()
+			}
+		}
+
+		c.Set(fiber.HeaderRetryAfter, "300")
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"success":     false,
+			"error":       state.Message,
+			"maintenance": state,
+		})
+	}
+}
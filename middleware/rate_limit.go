@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/utils"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Rate limit headers, RateLimit-* follows the IETF draft and X-RateLimit-* is kept for existing clients
+const (
+	HeaderRateLimitLimit      = "RateLimit-Limit"
+	HeaderRateLimitRemaining  = "RateLimit-Remaining"
+	HeaderRateLimitReset      = "RateLimit-Reset"
+	HeaderRateLimitPolicy     = "RateLimit-Policy"
+	headerXRateLimitLimit     = "X-RateLimit-Limit"
+	headerXRateLimitRemaining = "X-RateLimit-Remaining"
+	headerXRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimitMiddleware is the global per-IP rate limiter. Every response carries the rate limit headers so clients
+// can slow down before they are rejected, rejected requests also get a Retry-After header.
+func RateLimitMiddleware(cfg *config.Config) fiber.Handler {
+	policy := fmt.Sprintf("%d;w=%d", cfg.RateLimitPerMinute, int(utils.RateLimitWindow/time.Second))
+
+	return func(c fiber.Ctx) error {
+		allowed, state := utils.AllowClientRequest(c.IP(), cfg.RateLimitPerMinute, utils.RateLimitWindow, time.Now())
+
+		c.Set(HeaderRateLimitPolicy, policy)
+		for _, header := range [][2]string{
+			{HeaderRateLimitLimit, strconv.Itoa(state.Limit)},
+			{HeaderRateLimitRemaining, strconv.Itoa(state.Remaining)},
+			{HeaderRateLimitReset, strconv.Itoa(state.ResetInSeconds)},
+			{headerXRateLimitLimit, strconv.Itoa(state.Limit)},
+			{headerXRateLimitRemaining, strconv.Itoa(state.Remaining)},
+			{headerXRateLimitReset, strconv.Itoa(state.ResetInSeconds)},
+		} {
+			c.Set(header[0], header[1])
+		}
+
+		if !allowed {
+			utils.RecordRateLimited("global")
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(state.ResetInSeconds))
+			return c.Status(fiber.StatusTooManyRequests).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   fmt.Sprintf("Too many requests, please retry in %d seconds", state.ResetInSeconds),
+			})
+		}
+		return c.Next()
+	}
+}
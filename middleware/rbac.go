@@ -3,38 +3,50 @@ package middleware
 import (
 	"livo-fiber-backend/database"
 	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"strings"
 
 	"github.com/gofiber/fiber/v3"
 )
 
-// RoleMiddleware checks if user has required role hierarchy
+// RoleMiddleware checks if user has required role hierarchy, denied attempts are recorded in the security events
 func RoleMiddleware(allowedRoles []string) fiber.Handler {
 	return func(c fiber.Ctx) error {
 		userRoles := c.Locals("userRoles").([]string)
 
-		// Get minimum hierarchy level from allowed roles
-		minHierarchy := 999
-		for _, allowedRole := range allowedRoles {
-			var role models.Role
-			if err := database.DB.Where("role_name = ?", allowedRole).First(&role).Error; err == nil {
-				if role.Hierarchy < minHierarchy {
-					minHierarchy = role.Hierarchy
-				}
-			}
-		}
-
-		// Check if user has any role with equal or higher privilege
-		for _, userRole := range userRoles {
-			var role models.Role
-			if err := database.DB.Where("role_name = ?", userRole).First(&role).Error; err == nil {
-				if role.Hierarchy <= minHierarchy {
-					return c.Next()
-				}
-			}
+		if hasRequiredRole(userRoles, allowedRoles) {
+			return c.Next()
 		}
 
+		utils.RecordPermissionDenied(database.DB, c, "requires "+strings.Join(allowedRoles, ", ")+", has "+strings.Join(userRoles, ", "))
 		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
 			"error": "Insufficient permissions",
 		})
 	}
 }
+
+// hasRequiredRole checks if any of the user roles has equal or higher privilege than the allowed roles
+func hasRequiredRole(userRoles, allowedRoles []string) bool {
+	// Get minimum hierarchy level from allowed roles
+	minHierarchy := 999
+	for _, allowedRole := range allowedRoles {
+		var role models.Role
+		if err := database.DB.Where("role_name = ?", allowedRole).First(&role).Error; err == nil {
+			if role.Hierarchy < minHierarchy {
+				minHierarchy = role.Hierarchy
+			}
+		}
+	}
+
+	// Check if user has any role with equal or higher privilege
+	for _, userRole := range userRoles {
+		var role models.Role
+		if err := database.DB.Where("role_name = ?", userRole).First(&role).Error; err == nil {
+			if role.Hierarchy <= minHierarchy {
+				return true
+			}
+		}
+	}
+
+	return false
+}
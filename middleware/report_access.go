@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"livo-fiber-backend/database"
+	"livo-fiber-backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// ReportAccessMiddleware restricts a report route to the roles of its access policy (see utils.ReportAccessPolicies).
+// Denied attempts are recorded in the report access audit.
+func ReportAccessMiddleware(report string) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if utils.CanAccessReport(c, report) {
+			return c.Next()
+		}
+
+		utils.RecordReportAccessDenial(database.DB, c, report)
+		utils.RecordPermissionDenied(database.DB, c, "report "+report)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You do not have access to this report",
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"livo-fiber-backend/utils"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// RouteContextMiddleware stores the request method and path in the request context
+// so database queries run with c.Context() can be traced back to the route
+func RouteContextMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		c.SetContext(utils.WithRoute(c.Context(), c.Method()+" "+c.Path()))
+		return c.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// TimeoutMiddleware puts a deadline on the request context so database queries and
+// outbound calls made with c.Context() are canceled once the route's time budget is spent.
+// Reports get a longer budget, kiosk/attendance endpoints a shorter one.
+// Requests that exceed their deadline get a 504 response.
+func TimeoutMiddleware(cfg *config.Config) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		timeout := routeTimeout(cfg, c.Path())
+		if timeout <= 0 {
+			return c.Next()
+		}
+
+		parent := c.Context()
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		c.SetContext(ctx)
+		defer func() {
+			cancel()
+			c.SetContext(parent)
+		}()
+
+		err := c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded) {
+			return c.Status(fiber.StatusGatewayTimeout).JSON(utils.ErrorResponse{
+				Success: false,
+				Error:   "Request timed out after " + timeout.String(),
+			})
+		}
+
+		return err
+	}
+}
+
+// routeTimeout returns the time budget for the given request path
+func routeTimeout(cfg *config.Config, path string) time.Duration {
+	switch {
+	case strings.HasPrefix(path, "/api/reports"):
+		return time.Duration(cfg.ReportTimeoutSeconds) * time.Second
+	case path == "/api/attendances/checkin/face/batch":
+		// A batch searches many faces, it gets the default budget instead of the kiosk one
+		return time.Duration(cfg.RequestTimeoutSeconds) * time.Second
+	case strings.HasPrefix(path, "/api/attendances"), strings.HasPrefix(path, "/api/mobile-attendances"):
+		return time.Duration(cfg.KioskTimeoutSeconds) * time.Second
+	default:
+		return time.Duration(cfg.RequestTimeoutSeconds) * time.Second
+	}
+}
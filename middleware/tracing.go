@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"fmt"
+	"livo-fiber-backend/utils"
+	"net/http"
+
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware starts a server span for every request, continuing any trace context
+// sent by the caller. The span is stored in the request context so queries and outbound
+// calls made with c.Context() become child spans. The trace ID is returned in X-Trace-Id.
+func TracingMiddleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		headers := http.Header(c.GetReqHeaders())
+		ctx := otel.GetTextMapPropagator().Extract(c.Context(), propagation.HeaderCarrier(headers))
+
+		ctx, span := otel.Tracer(utils.TracerName).Start(ctx, c.Method()+" "+c.Path(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Method()),
+				attribute.String("url.path", c.Path()),
+				attribute.String("client.address", c.IP()),
+			),
+		)
+		defer span.End()
+
+		parent := c.Context()
+		c.SetContext(ctx)
+		defer c.SetContext(parent)
+
+		if span.SpanContext().HasTraceID() {
+			c.Set("X-Trace-Id", span.SpanContext().TraceID().String())
+		}
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			span.RecordError(err)
+			if fiberErr, ok := err.(*fiber.Error); ok {
+				status = fiberErr.Code
+			}
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"encoding/json"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/database"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Headers integrations sign their webhooks with
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature" // hex HMAC-SHA256 of "<timestamp>.<raw body>"
+	WebhookTimestampHeader = "X-Webhook-Timestamp" // unix seconds the webhook was sent at
+	WebhookNonceHeader     = "X-Webhook-Nonce"     // unique per webhook, checked for replays in addition to the signature
+)
+
+// webhookHiddenHeaders are credentials never stored with a logged webhook
+var webhookHiddenHeaders = map[string]bool{
+	"authorization": true,
+	"cookie":        true,
+	"x-api-key":     true,
+}
+
+// WebhookMiddleware verifies the signature, timestamp and nonce of inbound webhooks and logs every webhook with
+// its payload and the handler outcome, so it can be reprocessed. The endpoint names the handler for reprocessing.
+// A source starting with ":" is read from that route parameter, e.g. ":marketplace".
+func WebhookMiddleware(cfg *config.Config, endpoint, source string) fiber.Handler {
+	policy := utils.WebhookPolicyFromConfig(cfg)
+	// Nonces are remembered for as long as their timestamp is accepted, on both sides of the server clock
+	nonceTTL := 2 * policy.Tolerance
+
+	return func(c fiber.Ctx) error {
+		now := time.Now()
+
+		eventSource := source
+		if strings.HasPrefix(source, ":") {
+			eventSource = c.Params(strings.TrimPrefix(source, ":"))
+		}
+		eventSource = strings.ToLower(eventSource)
+
+		// Request strings point into buffers reused after the response, the event is persisted after the handler
+		event := models.InboundWebhookEvent{
+			Source:      strings.Clone(eventSource),
+			Endpoint:    endpoint,
+			Method:      strings.Clone(c.Method()),
+			Path:        strings.Clone(c.Path()),
+			ContentType: strings.Clone(c.Get(fiber.HeaderContentType)),
+			Payload:     string(c.Body()),
+			IPAddress:   strings.Clone(c.IP()),
+			Status:      models.WebhookEventReceived,
+		}
+
+		params := map[string]string{}
+		for _, name := range c.Route().Params {
+			params[name] = strings.Clone(c.Params(name))
+		}
+		if encoded, err := json.Marshal(params); err == nil {
+			event.Params = string(encoded)
+		}
+
+		headers := map[string]string{}
+		for name, values := range c.GetReqHeaders() {
+			if webhookHiddenHeaders[strings.ToLower(name)] {
+				continue
+			}
+			headers[name] = strings.Join(values, ", ")
+		}
+		if encoded, err := json.Marshal(headers); err == nil {
+			event.Headers = string(encoded)
+		}
+
+		if apiKeyID, ok := c.Locals("apiKeyId").(uint); ok {
+			event.APIKeyID = &apiKeyID
+		}
+		if userID, ok := c.Locals("userId").(string); ok {
+			if parsed, err := strconv.ParseUint(userID, 10, 32); err == nil {
+				id := uint(parsed)
+				event.UserID = &id
+			}
+		}
+
+		// Check the signature and timestamp against the secret of the source
+		signature := strings.Clone(c.Get(WebhookSignatureHeader))
+		signatureStatus, err := policy.VerifyWebhook(eventSource, signature, c.Get(WebhookTimestampHeader), c.Body(), now)
+		event.SignatureStatus = signatureStatus
+		if err != nil {
+			log.Println("Webhook - Rejected", endpoint, "from", eventSource+":", err)
+			return rejectWebhook(c, &event, fiber.StatusUnauthorized, err)
+		}
+
+		// Reject replays by the signature, which cannot be changed without the secret since it covers the timestamp
+		// and body, and by the nonce header, which senders keep when they retry a webhook with a new timestamp.
+		// The nonce is not signed, so it only adds to the signature and never replaces it.
+		if signatureStatus == models.WebhookSignatureVerified {
+			event.Signature = utils.NormalizeWebhookSignature(signature)
+		}
+		event.Nonce = strings.Clone(c.Get(WebhookNonceHeader))
+		var replayKeys []string
+		replayQuery := database.DB.Model(&models.InboundWebhookEvent{}).
+			Where("source = ? AND status <> ? AND created_at > ?", eventSource, models.WebhookEventRejected, now.Add(-nonceTTL))
+		switch {
+		case event.Signature != "" && event.Nonce != "":
+			replayKeys = []string{"signature:" + event.Signature, "nonce:" + event.Nonce}
+			replayQuery = replayQuery.Where("(signature = ? OR nonce = ?)", event.Signature, event.Nonce)
+		case event.Signature != "":
+			replayKeys = []string{"signature:" + event.Signature}
+			replayQuery = replayQuery.Where("signature = ?", event.Signature)
+		case event.Nonce != "":
+			replayKeys = []string{"nonce:" + event.Nonce}
+			replayQuery = replayQuery.Where("nonce = ?", event.Nonce)
+		}
+		if len(replayKeys) > 0 {
+			var seen int64
+			replayQuery.Count(&seen)
+			if seen > 0 || !utils.ClaimWebhookReplayKeys(eventSource, replayKeys, nonceTTL, now) {
+				log.Println("Webhook - Replayed", endpoint, "from", eventSource+", nonce", event.Nonce)
+				return rejectWebhook(c, &event, fiber.StatusConflict, utils.ErrWebhookReplayed)
+			}
+		}
+
+		if err := database.DB.Create(&event).Error; err != nil {
+			log.Println("Webhook - Failed to log event:", err)
+		}
+
+		handlerErr := c.Next()
+
+		// Record the outcome so failed webhooks can be found and reprocessed
+		if event.ID != 0 {
+			status, body, eventStatus := utils.WebhookOutcome(c, handlerErr)
+			updates := map[string]interface{}{
+				"status":          eventStatus,
+				"response_status": status,
+				"response_body":   body,
+			}
+			if err := database.DB.Model(&event).Updates(updates).Error; err != nil {
+				log.Println("Webhook - Failed to record event outcome:", err)
+			}
+		}
+
+		return handlerErr
+	}
+}
+
+// rejectWebhook logs a webhook stopped before its handler and answers with the reason
+func rejectWebhook(c fiber.Ctx, event *models.InboundWebhookEvent, status int, reason error) error {
+	event.Status = models.WebhookEventRejected
+	event.ResponseStatus = status
+	event.Error = reason.Error()
+	if err := database.DB.Create(event).Error; err != nil {
+		log.Println("Webhook - Failed to log rejected event:", err)
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"error": reason.Error(),
+	})
+}
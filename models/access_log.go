@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// AccessLogUnroutedRoute is the route recorded for requests answered before reaching a route handler:
+// unknown paths and requests rejected by a middleware (rate limit, maintenance, authentication)
+const AccessLogUnroutedRoute = "(unrouted)"
+
+// AccessLog is a persisted API request, grouped by route pattern for per-endpoint latency and error analytics
+type AccessLog struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Method     string    `gorm:"not null;type:varchar(10)" json:"method"`
+	Route      string    `gorm:"not null;type:varchar(255);index:idx_access_logs_route_created,priority:1" json:"route"` // route pattern, e.g. /api/orders/:id
+	Path       string    `gorm:"not null;type:text" json:"path"`
+	Status     int       `gorm:"not null;index" json:"status"`
+	DurationMs float64   `gorm:"not null" json:"duration_ms"`
+	UserID     *uint     `gorm:"default:null;index" json:"user_id"`
+	IPAddress  string    `gorm:"type:varchar(50)" json:"ip_address"`
+	CreatedAt  time.Time `gorm:"index;index:idx_access_logs_route_created,priority:2" json:"created_at"`
+}
+
+// AccessLogResponse represents the access log data returned in API responses
+type AccessLogResponse struct {
+	ID         uint    `json:"id"`
+	Method     string  `json:"method"`
+	Route      string  `json:"route"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	DurationMs float64 `json:"durationMs"`
+	UserID     *uint   `json:"userId"`
+	IPAddress  string  `json:"ipAddress"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+// ToResponse converts an AccessLog model to an AccessLogResponse
+func (al *AccessLog) ToResponse() *AccessLogResponse {
+	return &AccessLogResponse{
+		ID:         al.ID,
+		Method:     al.Method,
+		Route:      al.Route,
+		Path:       al.Path,
+		Status:     al.Status,
+		DurationMs: al.DurationMs,
+		UserID:     al.UserID,
+		IPAddress:  al.IPAddress,
+		CreatedAt:  al.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
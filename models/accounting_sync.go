@@ -0,0 +1,165 @@
+package models
+
+import "time"
+
+// Accounting systems completed orders are pushed to as sales transactions
+const (
+	AccountingProviderAccurate = "accurate" // Accurate Online
+	AccountingProviderJurnal   = "jurnal"   // Jurnal by Mekari
+)
+
+var accountingProviders = []string{AccountingProviderAccurate, AccountingProviderJurnal}
+
+// AccountingProviders returns the supported accounting systems
+func AccountingProviders() []string {
+	return accountingProviders
+}
+
+// IsValidAccountingProvider reports whether provider is a supported accounting system
+func IsValidAccountingProvider(provider string) bool {
+	for _, known := range accountingProviders {
+		if known == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// Accounting sync statuses
+const (
+	AccountingSyncStatusPending = "pending" // waiting for its first attempt or a retry
+	AccountingSyncStatusSynced  = "synced"  // booked in the accounting system
+	AccountingSyncStatusFailed  = "failed"  // every attempt failed, retried by hand
+)
+
+// AccountingMapping configures how the completed orders of a store are booked in the accounting system.
+// Order lines are booked under their SKU, which must exist as an item of the accounting system.
+type AccountingMapping struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	StoreID       uint      `gorm:"not null;uniqueIndex" json:"store_id"`
+	Provider      string    `gorm:"not null;type:varchar(20)" json:"provider"`
+	Enabled       bool      `gorm:"not null" json:"enabled"`                       // disabled mappings queue nothing
+	CustomerNo    string    `gorm:"not null;type:varchar(100)" json:"customer_no"` // customer number (Accurate) or contact name (Jurnal) the sales are booked to
+	WarehouseName string    `gorm:"type:varchar(100)" json:"warehouse_name"`       // warehouse the stock is taken from, the default warehouse when empty
+	BranchName    string    `gorm:"type:varchar(100)" json:"branch_name"`          // Accurate branch, ignored by Jurnal
+	TaxIncluded   bool      `gorm:"not null;default:false" json:"tax_included"`    // order prices include tax, Accurate only
+	UpdatedBy     uint      `gorm:"not null" json:"updated_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	Store      *Store `gorm:"foreignKey:StoreID" json:"store,omitempty"`
+	UpdateUser *User  `gorm:"foreignKey:UpdatedBy" json:"update_user,omitempty"`
+}
+
+// AccountingMappingResponse represents the accounting mapping data returned in API responses
+type AccountingMappingResponse struct {
+	ID            uint   `json:"id"`
+	StoreID       uint   `json:"storeId"`
+	StoreCode     string `json:"storeCode"`
+	StoreName     string `json:"storeName"`
+	Provider      string `json:"provider"`
+	Enabled       bool   `json:"enabled"`
+	CustomerNo    string `json:"customerNo"`
+	WarehouseName string `json:"warehouseName"`
+	BranchName    string `json:"branchName"`
+	TaxIncluded   bool   `json:"taxIncluded"`
+	UpdatedBy     string `json:"updatedBy"`
+	UpdatedAt     string `json:"updatedAt"`
+}
+
+// ToResponse converts an AccountingMapping model to an AccountingMappingResponse
+func (am *AccountingMapping) ToResponse() *AccountingMappingResponse {
+	response := &AccountingMappingResponse{
+		ID:            am.ID,
+		StoreID:       am.StoreID,
+		Provider:      am.Provider,
+		Enabled:       am.Enabled,
+		CustomerNo:    am.CustomerNo,
+		WarehouseName: am.WarehouseName,
+		BranchName:    am.BranchName,
+		TaxIncluded:   am.TaxIncluded,
+		UpdatedAt:     am.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if am.Store != nil {
+		response.StoreCode = am.Store.StoreCode
+		response.StoreName = am.Store.StoreName
+	}
+	if am.UpdateUser != nil {
+		response.UpdatedBy = am.UpdateUser.Username
+	}
+	return response
+}
+
+// AccountingSync is the queue entry pushing a completed order to the accounting system of its store.
+// Failed attempts are retried with a growing delay until the attempts run out.
+type AccountingSync struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	OrderID       uint       `gorm:"not null;uniqueIndex" json:"order_id"`
+	StoreID       uint       `gorm:"not null;index" json:"store_id"`
+	Provider      string     `gorm:"not null;type:varchar(20);index" json:"provider"`
+	Status        string     `gorm:"not null;default:'pending';type:varchar(20);index" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt *time.Time `gorm:"default:null;index" json:"next_attempt_at"` // nil once synced or failed
+	LastAttemptAt *time.Time `gorm:"default:null" json:"last_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error"`
+	ExternalID    string     `gorm:"type:varchar(100)" json:"external_id"` // transaction number in the accounting system
+	SyncedAt      *time.Time `gorm:"default:null" json:"synced_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Order *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	Store *Store `gorm:"foreignKey:StoreID" json:"store,omitempty"`
+}
+
+// AccountingSyncResponse represents the accounting sync data returned in API responses
+type AccountingSyncResponse struct {
+	ID             uint    `json:"id"`
+	OrderID        uint    `json:"orderId"`
+	OrderGineeID   string  `json:"orderGineeId"`
+	TrackingNumber string  `json:"trackingNumber"`
+	StoreID        uint    `json:"storeId"`
+	StoreName      string  `json:"storeName"`
+	Provider       string  `json:"provider"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	NextAttemptAt  *string `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *string `json:"lastAttemptAt,omitempty"`
+	LastError      string  `json:"lastError,omitempty"`
+	ExternalID     string  `json:"externalId,omitempty"`
+	SyncedAt       *string `json:"syncedAt,omitempty"`
+	CreatedAt      string  `json:"createdAt"`
+}
+
+// ToResponse converts an AccountingSync model to an AccountingSyncResponse
+func (as *AccountingSync) ToResponse() *AccountingSyncResponse {
+	formatTime := func(t *time.Time) *string {
+		if t == nil {
+			return nil
+		}
+		formatted := t.Format("02-01-2006 15:04:05")
+		return &formatted
+	}
+
+	response := &AccountingSyncResponse{
+		ID:            as.ID,
+		OrderID:       as.OrderID,
+		StoreID:       as.StoreID,
+		Provider:      as.Provider,
+		Status:        as.Status,
+		Attempts:      as.Attempts,
+		NextAttemptAt: formatTime(as.NextAttemptAt),
+		LastAttemptAt: formatTime(as.LastAttemptAt),
+		LastError:     as.LastError,
+		ExternalID:    as.ExternalID,
+		SyncedAt:      formatTime(as.SyncedAt),
+		CreatedAt:     as.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if as.Order != nil {
+		response.OrderGineeID = as.Order.OrderGineeID
+		response.TrackingNumber = as.Order.TrackingNumber
+	}
+	if as.Store != nil {
+		response.StoreName = as.Store.StoreName
+	}
+	return response
+}
@@ -0,0 +1,185 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix is prepended to every generated API key so keys are recognizable in logs and secret scanners
+const APIKeyPrefix = "lvk_"
+
+// DefaultAPIKeyRateLimit is the number of requests per minute allowed when none is given
+const DefaultAPIKeyRateLimit = 60
+
+// APIKeyScopeResources lists the API resources an API key can be granted read or write access to
+var APIKeyScopeResources = []string{
+	"orders",
+	"outbounds",
+	"handovers",
+	"inbounds",
+	"inventories",
+	"products",
+	"returns",
+	"complains",
+	"channels",
+	"stores",
+	"expeditions",
+	"boxes",
+	"reports",
+	"charts",
+	"changes",
+}
+
+// APIKey grants an external system machine access on behalf of the user who created it, limited to its scopes
+type APIKey struct {
+	ID                 uint       `gorm:"primaryKey" json:"id"`
+	Name               string     `gorm:"not null;type:varchar(100)" json:"name"`
+	Description        string     `gorm:"type:text" json:"description"`
+	KeyPrefix          string     `gorm:"not null;type:varchar(20)" json:"key_prefix"` // first characters of the key, for identification
+	KeyHash            string     `gorm:"uniqueIndex;not null;type:varchar(64)" json:"-"`
+	Scopes             string     `gorm:"not null;type:text" json:"scopes"` // comma separated, e.g. orders:read,outbounds:write
+	RateLimitPerMinute int        `gorm:"not null;default:60" json:"rate_limit_per_minute"`
+	Status             string     `gorm:"not null;type:varchar(20);default:active" json:"status"` // active or revoked
+	RequestCount       int64      `gorm:"not null;default:0" json:"request_count"`
+	LastUsedAt         *time.Time `gorm:"default:null" json:"last_used_at"`
+	LastUsedIP         string     `gorm:"type:varchar(50)" json:"last_used_ip"`
+	ExpiresAt          *time.Time `gorm:"default:null" json:"expires_at"`
+	CreatedBy          uint       `gorm:"not null" json:"created_by"`
+	RotatedAt          *time.Time `gorm:"default:null" json:"rotated_at"`
+	RevokedBy          *uint      `gorm:"default:null" json:"revoked_by"`
+	RevokedAt          *time.Time `gorm:"default:null" json:"revoked_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	CreateUser *User `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+	RevokeUser *User `gorm:"foreignKey:RevokedBy" json:"revoke_user,omitempty"`
+}
+
+// APIKeyUsage counts the requests made with an API key per day
+type APIKeyUsage struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	APIKeyID    uint      `gorm:"not null;uniqueIndex:idx_api_key_usage_date" json:"api_key_id"`
+	Date        time.Time `gorm:"not null;type:date;uniqueIndex:idx_api_key_usage_date" json:"date"`
+	Requests    int64     `gorm:"not null;default:0" json:"requests"`
+	RateLimited int64     `gorm:"not null;default:0" json:"rate_limited"`
+	Forbidden   int64     `gorm:"not null;default:0" json:"forbidden"` // requests outside the key scopes
+}
+
+// ScopeList returns the scopes of the key
+func (k *APIKey) ScopeList() []string {
+	var scopes []string
+	for _, scope := range strings.Split(k.Scopes, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// HasScope reports whether the key may perform the action (read or write) on the resource, write access implies read access
+func (k *APIKey) HasScope(resource, action string) bool {
+	for _, scope := range k.ScopeList() {
+		if scope == resource+":"+action || (action == "read" && scope == resource+":write") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUsable reports whether the key is active and not expired
+func (k *APIKey) IsUsable(now time.Time) bool {
+	return k.Status == "active" && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type APIKeyResponse struct {
+	ID                 uint     `json:"id"`
+	Name               string   `json:"name"`
+	Description        string   `json:"description"`
+	KeyPrefix          string   `json:"keyPrefix"`
+	Scopes             []string `json:"scopes"`
+	RateLimitPerMinute int      `json:"rateLimitPerMinute"`
+	Status             string   `json:"status"`
+	RequestCount       int64    `json:"requestCount"`
+	LastUsedAt         *string  `json:"lastUsedAt,omitempty"`
+	LastUsedIP         string   `json:"lastUsedIp,omitempty"`
+	ExpiresAt          *string  `json:"expiresAt,omitempty"`
+	CreatedBy          string   `json:"createdBy"`
+	RotatedAt          *string  `json:"rotatedAt,omitempty"`
+	RevokedBy          *string  `json:"revokedBy,omitempty"`
+	RevokedAt          *string  `json:"revokedAt,omitempty"`
+	CreatedAt          string   `json:"createdAt"`
+	UpdatedAt          string   `json:"updatedAt"`
+}
+
+type APIKeyUsageResponse struct {
+	Date        string `json:"date"`
+	Requests    int64  `json:"requests"`
+	RateLimited int64  `json:"rateLimited"`
+	Forbidden   int64  `json:"forbidden"`
+}
+
+// ToResponse converts an APIKeyUsage model to an APIKeyUsageResponse
+func (u *APIKeyUsage) ToResponse() APIKeyUsageResponse {
+	return APIKeyUsageResponse{
+		Date:        u.Date.Format("02-01-2006"),
+		Requests:    u.Requests,
+		RateLimited: u.RateLimited,
+		Forbidden:   u.Forbidden,
+	}
+}
+
+// ToResponse converts an APIKey model to an APIKeyResponse
+func (k *APIKey) ToResponse() *APIKeyResponse {
+	// User visual handlers
+	var createdBy string
+	if k.CreateUser != nil {
+		createdBy = k.CreateUser.FullName
+	}
+	var revokedBy *string
+	if k.RevokeUser != nil {
+		revokedBy = &k.RevokeUser.FullName
+	}
+
+	var lastUsedAt, expiresAt, rotatedAt, revokedAt *string
+	if k.LastUsedAt != nil {
+		formatted := k.LastUsedAt.Format("02-01-2006 15:04:05")
+		lastUsedAt = &formatted
+	}
+	if k.ExpiresAt != nil {
+		formatted := k.ExpiresAt.Format("02-01-2006 15:04:05")
+		expiresAt = &formatted
+	}
+	if k.RotatedAt != nil {
+		formatted := k.RotatedAt.Format("02-01-2006 15:04:05")
+		rotatedAt = &formatted
+	}
+	if k.RevokedAt != nil {
+		formatted := k.RevokedAt.Format("02-01-2006 15:04:05")
+		revokedAt = &formatted
+	}
+
+	scopes := k.ScopeList()
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &APIKeyResponse{
+		ID:                 k.ID,
+		Name:               k.Name,
+		Description:        k.Description,
+		KeyPrefix:          k.KeyPrefix,
+		Scopes:             scopes,
+		RateLimitPerMinute: k.RateLimitPerMinute,
+		Status:             k.Status,
+		RequestCount:       k.RequestCount,
+		LastUsedAt:         lastUsedAt,
+		LastUsedIP:         k.LastUsedIP,
+		ExpiresAt:          expiresAt,
+		CreatedBy:          createdBy,
+		RotatedAt:          rotatedAt,
+		RevokedBy:          revokedBy,
+		RevokedAt:          revokedAt,
+		CreatedAt:          k.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:          k.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,215 @@
+package models
+
+import "time"
+
+// Approval methods
+const (
+	ApprovalMethodCredentials = "credentials" // coordinator typed their own username and password
+	ApprovalMethodDelegation  = "delegation"  // a delegate approved on behalf of an absent coordinator
+	ApprovalMethodCode        = "code"        // a worker entered a one-time code generated by the coordinator
+)
+
+// ApprovalDelegation grants a picker the right to approve on behalf of a coordinator until it expires or is revoked
+type ApprovalDelegation struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	DelegatorID uint       `gorm:"not null;index" json:"delegator_id"`
+	DelegateID  uint       `gorm:"not null;index" json:"delegate_id"`
+	Reason      string     `gorm:"type:text" json:"reason"`
+	ExpiresAt   time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedBy   *uint      `gorm:"default:null" json:"revoked_by"`
+	RevokedAt   *time.Time `gorm:"default:null" json:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	Delegator  *User `gorm:"foreignKey:DelegatorID" json:"delegator,omitempty"`
+	Delegate   *User `gorm:"foreignKey:DelegateID" json:"delegate,omitempty"`
+	RevokeUser *User `gorm:"foreignKey:RevokedBy" json:"revoke_user,omitempty"`
+}
+
+// IsActive reports whether the delegation can still be used at the given time
+func (ad *ApprovalDelegation) IsActive(at time.Time) bool {
+	return ad.RevokedAt == nil && at.Before(ad.ExpiresAt)
+}
+
+type ApprovalDelegationResponse struct {
+	ID          uint    `json:"id"`
+	DelegatorID uint    `json:"delegatorId"`
+	Delegator   string  `json:"delegator"`
+	DelegateID  uint    `json:"delegateId"`
+	Delegate    string  `json:"delegate"`
+	Reason      string  `json:"reason,omitempty"`
+	Status      string  `json:"status"`
+	ExpiresAt   string  `json:"expiresAt"`
+	RevokedBy   *string `json:"revokedBy,omitempty"`
+	RevokedAt   *string `json:"revokedAt,omitempty"`
+	CreatedAt   string  `json:"createdAt"`
+}
+
+// ToResponse converts an ApprovalDelegation model to an ApprovalDelegationResponse
+func (ad *ApprovalDelegation) ToResponse() *ApprovalDelegationResponse {
+	// User visual handlers
+	var delegator, delegate string
+	if ad.Delegator != nil {
+		delegator = ad.Delegator.FullName
+	}
+	if ad.Delegate != nil {
+		delegate = ad.Delegate.FullName
+	}
+	var revokedBy *string
+	if ad.RevokeUser != nil {
+		revokedBy = &ad.RevokeUser.FullName
+	}
+
+	// Status visual handlers
+	status := "Active"
+	var revokedAt *string
+	if ad.RevokedAt != nil {
+		status = "Revoked"
+		formatted := ad.RevokedAt.Format("02-01-2006 15:04:05")
+		revokedAt = &formatted
+	} else if !ad.IsActive(time.Now()) {
+		status = "Expired"
+	}
+
+	return &ApprovalDelegationResponse{
+		ID:          ad.ID,
+		DelegatorID: ad.DelegatorID,
+		Delegator:   delegator,
+		DelegateID:  ad.DelegateID,
+		Delegate:    delegate,
+		Reason:      ad.Reason,
+		Status:      status,
+		ExpiresAt:   ad.ExpiresAt.Format("02-01-2006 15:04:05"),
+		RevokedBy:   revokedBy,
+		RevokedAt:   revokedAt,
+		CreatedAt:   ad.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// Approval is the audit record of a sensitive operation approved on behalf of a worker
+type Approval struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	Action        string    `gorm:"not null;type:varchar(50);index" json:"action"` // e.g. pending_pick
+	ReferenceType string    `gorm:"type:varchar(50)" json:"reference_type"`
+	ReferenceID   uint      `gorm:"default:0;index" json:"reference_id"`
+	Method        string    `gorm:"not null;type:varchar(20)" json:"method"`
+	RequestedBy   uint      `gorm:"not null;index" json:"requested_by"`
+	ApprovedBy    uint      `gorm:"not null;index" json:"approved_by"`
+	DelegatorID   *uint     `gorm:"default:null;index" json:"delegator_id"` // coordinator the approver acted for, delegation only
+	DelegationID  *uint     `gorm:"default:null" json:"delegation_id"`
+	CodeID        *uint     `gorm:"default:null" json:"code_id"` // one-time approval code, code method only
+	CreatedAt     time.Time `json:"created_at"`
+
+	RequestUser *User `gorm:"foreignKey:RequestedBy" json:"request_user,omitempty"`
+	ApproveUser *User `gorm:"foreignKey:ApprovedBy" json:"approve_user,omitempty"`
+	Delegator   *User `gorm:"foreignKey:DelegatorID" json:"delegator,omitempty"`
+}
+
+type ApprovalResponse struct {
+	ID            uint    `json:"id"`
+	Action        string  `json:"action"`
+	ReferenceType string  `json:"referenceType,omitempty"`
+	ReferenceID   uint    `json:"referenceId,omitempty"`
+	Method        string  `json:"method"`
+	RequestedBy   string  `json:"requestedBy"`
+	ApprovedBy    string  `json:"approvedBy"`
+	OnBehalfOf    *string `json:"onBehalfOf,omitempty"`
+	DelegationID  *uint   `json:"delegationId,omitempty"`
+	CodeID        *uint   `json:"codeId,omitempty"`
+	CreatedAt     string  `json:"createdAt"`
+}
+
+// ToResponse converts an Approval model to an ApprovalResponse
+func (a *Approval) ToResponse() *ApprovalResponse {
+	// User visual handlers
+	var requestedBy, approvedBy string
+	if a.RequestUser != nil {
+		requestedBy = a.RequestUser.FullName
+	}
+	if a.ApproveUser != nil {
+		approvedBy = a.ApproveUser.FullName
+	}
+	var onBehalfOf *string
+	if a.Delegator != nil {
+		onBehalfOf = &a.Delegator.FullName
+	}
+
+	return &ApprovalResponse{
+		ID:            a.ID,
+		Action:        a.Action,
+		ReferenceType: a.ReferenceType,
+		ReferenceID:   a.ReferenceID,
+		Method:        a.Method,
+		RequestedBy:   requestedBy,
+		ApprovedBy:    approvedBy,
+		OnBehalfOf:    onBehalfOf,
+		DelegationID:  a.DelegationID,
+		CodeID:        a.CodeID,
+		CreatedAt:     a.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// ApprovalCode is a short-lived single-use numeric code a coordinator generates so workers never type coordinator passwords
+type ApprovalCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	IssuedBy  uint       `gorm:"not null;index" json:"issued_by"`
+	CodeHash  string     `gorm:"not null;type:varchar(64);index" json:"-"`
+	Action    string     `gorm:"type:varchar(50)" json:"action"` // empty allows any approval action
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	UsedBy    *uint      `gorm:"default:null" json:"used_by"`
+	UsedAt    *time.Time `gorm:"default:null" json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	IssueUser *User `gorm:"foreignKey:IssuedBy" json:"issue_user,omitempty"`
+	UseUser   *User `gorm:"foreignKey:UsedBy" json:"use_user,omitempty"`
+}
+
+// IsUsable reports whether the code has not been used or expired
+func (ac *ApprovalCode) IsUsable(now time.Time) bool {
+	return ac.UsedAt == nil && now.Before(ac.ExpiresAt)
+}
+
+type ApprovalCodeResponse struct {
+	ID        uint    `json:"id"`
+	IssuedBy  string  `json:"issuedBy"`
+	Action    string  `json:"action,omitempty"`
+	Status    string  `json:"status"`
+	ExpiresAt string  `json:"expiresAt"`
+	UsedBy    *string `json:"usedBy,omitempty"`
+	UsedAt    *string `json:"usedAt,omitempty"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// ToResponse converts an ApprovalCode model to an ApprovalCodeResponse, the code itself is never returned
+func (ac *ApprovalCode) ToResponse() *ApprovalCodeResponse {
+	// User visual handlers
+	var issuedBy string
+	if ac.IssueUser != nil {
+		issuedBy = ac.IssueUser.FullName
+	}
+	var usedBy *string
+	if ac.UseUser != nil {
+		usedBy = &ac.UseUser.FullName
+	}
+
+	// Status visual handlers
+	status := "Active"
+	var usedAt *string
+	if ac.UsedAt != nil {
+		status = "Used"
+		formatted := ac.UsedAt.Format("02-01-2006 15:04:05")
+		usedAt = &formatted
+	} else if !ac.IsUsable(time.Now()) {
+		status = "Expired"
+	}
+
+	return &ApprovalCodeResponse{
+		ID:        ac.ID,
+		IssuedBy:  issuedBy,
+		Action:    ac.Action,
+		Status:    status,
+		ExpiresAt: ac.ExpiresAt.Format("02-01-2006 15:04:05"),
+		UsedBy:    usedBy,
+		UsedAt:    usedAt,
+		CreatedAt: ac.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
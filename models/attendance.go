@@ -1,19 +1,63 @@
 package models
 
 import (
+	"encoding/json"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// DefaultGeofenceRadius is the check-in radius in meters of locations without a custom geofence
+const DefaultGeofenceRadius = 10.0
+
 type Location struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
-	Latitude  float64   `json:"latitude"`
-	Longitude float64   `json:"longitude"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	Name              string     `gorm:"type:varchar(100);not null" json:"name"`
+	Latitude          float64    `json:"latitude"`
+	Longitude         float64    `json:"longitude"`
+	GeofenceRadius    float64    `gorm:"not null;default:10" json:"geofence_radius"` // meters around the coordinates, used when no polygon is set
+	GeofencePolygon   string     `gorm:"type:text" json:"geofence_polygon"`          // JSON array of [latitude, longitude] points, replaces the radius when set
+	MinFaceConfidence *float64   `gorm:"default:null" json:"min_face_confidence"`    // overrides the global minimum face match confidence when set
+	IsActive          bool       `gorm:"default:true" json:"is_active"`              // inactive locations reject check-ins
+	DeletedAt         *time.Time `gorm:"default:null;index" json:"deleted_at"`       // deleted locations are hidden but still resolve on past attendances
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// PolygonPoints returns the geofence polygon as [latitude, longitude] points, empty when the location uses a radius
+func (l *Location) PolygonPoints() [][2]float64 {
+	var points [][2]float64
+	if l.GeofencePolygon != "" {
+		_ = json.Unmarshal([]byte(l.GeofencePolygon), &points)
+	}
+	return points
 }
 
+// Attendance overtime approval statuses
+const (
+	OvertimeStatusNone     = "none" // no overtime recorded at checkout
+	OvertimeStatusPending  = "pending"
+	OvertimeStatusApproved = "approved"
+	OvertimeStatusAdjusted = "adjusted" // approved with a different number of minutes
+	OvertimeStatusRejected = "rejected"
+)
+
+// Attendance entry methods
+const (
+	EntryMethodFace     = "face"
+	EntryMethodManual   = "manual"
+	EntryMethodMobile   = "mobile"
+	EntryMethodBackfill = "backfill" // entered by HR for an outage, see AttendanceBackfill
+	EntryMethodLegacy   = "legacy"   // imported from the spreadsheets of the system used before go-live
+)
+
+// Attendance fallback statuses, set on manual entries made while face recognition was unavailable
+const (
+	FallbackStatusPending  = "pending" // waiting for HR verification
+	FallbackStatusVerified = "verified"
+	FallbackStatusRejected = "rejected"
+)
+
 type Attendance struct {
 	ID         uint       `gorm:"primaryKey" json:"id"`
 	UserID     uint       `json:"user_id"`
@@ -28,29 +72,113 @@ type Attendance struct {
 	CheckedOut *time.Time `gorm:"default:null" json:"checked_out"`
 	Checked    bool       `gorm:"default:true" json:"checked"`
 
-	Location Location `gorm:"foreignKey:LocationID" json:"location"`
-	User     User     `gorm:"foreignKey:UserID" json:"user"`
+	// Anti-fraud signals reported by mobile devices
+	IsMockLocation bool   `gorm:"default:false" json:"is_mock_location"`
+	DeviceID       string `gorm:"type:varchar(255)" json:"device_id"`
+	OSBuild        string `gorm:"type:varchar(255)" json:"os_build"`
+	FraudScore     int    `gorm:"type:int;default:0" json:"fraud_score"`
+	FraudReasons   string `gorm:"type:text" json:"fraud_reasons"`
+	Suspicious     bool   `gorm:"default:false;index" json:"suspicious"`
+
+	// Overtime approval, only approved minutes count towards payroll
+	OvertimeStatus     string     `gorm:"type:varchar(20);default:'none';index" json:"overtime_status"`
+	ApprovedOvertime   int        `gorm:"type:int;default:0" json:"approved_overtime"` // in minutes
+	OvertimeReviewedBy *uint      `gorm:"default:null" json:"overtime_reviewed_by"`
+	OvertimeReviewedAt *time.Time `gorm:"default:null" json:"overtime_reviewed_at"`
+	OvertimeReviewNote string     `gorm:"type:text" json:"overtime_review_note"`
+
+	// Entry method and degraded mode, manual entries made while face recognition was down are verified by HR
+	EntryMethod        string     `gorm:"type:varchar(20)" json:"entry_method"`
+	FallbackStatus     string     `gorm:"type:varchar(20);index" json:"fallback_status"` // empty when not a fallback entry
+	FallbackReason     string     `gorm:"type:text" json:"fallback_reason"`
+	FallbackReviewedBy *uint      `gorm:"default:null" json:"fallback_reviewed_by"`
+	FallbackReviewedAt *time.Time `gorm:"default:null" json:"fallback_reviewed_at"`
+	FallbackReviewNote string     `gorm:"type:text" json:"fallback_review_note"`
+
+	// Face match confidence reported by the provider and the minimum confidence it was accepted against, nil without a face match
+	FaceConfidence         *float64 `gorm:"default:null;index" json:"face_confidence"`
+	FaceThreshold          *float64 `gorm:"default:null" json:"face_threshold"`
+	CheckOutFaceConfidence *float64 `gorm:"default:null;index" json:"check_out_face_confidence"`
+	CheckOutFaceThreshold  *float64 `gorm:"default:null" json:"check_out_face_threshold"`
+
+	// Missing checkout, flagged by the nightly job when the shift ended without a checkout, AutoClosedAt is set when the job also checked it out
+	MissingCheckout bool       `gorm:"default:false;index" json:"missing_checkout"`
+	AutoClosedAt    *time.Time `gorm:"default:null" json:"auto_closed_at"`
+
+	// Breaks, the minutes of the ended breaks are deducted from the worked time and flagged when over the allowed maximum
+	BreakMinutes   int  `gorm:"type:int;default:0" json:"break_minutes"`
+	BreakViolation bool `gorm:"default:false;index" json:"break_violation"`
+
+	// Backfill, set on attendances entered or completed by HR for an incident the kiosk could not record
+	BackfillID *uint `gorm:"default:null;index" json:"backfill_id"`
+
+	Location           Location            `gorm:"foreignKey:LocationID" json:"location"`
+	User               User                `gorm:"foreignKey:UserID" json:"user"`
+	OvertimeReviewUser *User               `gorm:"foreignKey:OvertimeReviewedBy" json:"overtime_review_user,omitempty"`
+	FallbackReviewUser *User               `gorm:"foreignKey:FallbackReviewedBy" json:"fallback_review_user,omitempty"`
+	Breaks             []AttendanceBreak   `gorm:"foreignKey:AttendanceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"breaks,omitempty"`
+	Backfill           *AttendanceBackfill `gorm:"foreignKey:BackfillID" json:"backfill,omitempty"`
+}
+
+// MarkFallback tags the attendance as a manual entry made while face recognition was unavailable.
+// A check-out fallback after the check-in was verified puts the attendance up for verification again.
+func (a *Attendance) MarkFallback(reason string) {
+	if a.FallbackReason == "" {
+		a.FallbackReason = reason
+	} else {
+		a.FallbackReason += "; " + reason
+	}
+	if a.FallbackStatus != FallbackStatusRejected {
+		a.FallbackStatus = FallbackStatusPending
+		a.FallbackReviewedBy = nil
+		a.FallbackReviewedAt = nil
+		a.FallbackReviewNote = ""
+	}
+}
+
+// SetOvertime records the overtime measured at checkout and puts it up for approval
+func (a *Attendance) SetOvertime(minutes int) {
+	a.Overtime = minutes
+	a.ApprovedOvertime = 0
+	a.OvertimeReviewedBy = nil
+	a.OvertimeReviewedAt = nil
+	a.OvertimeReviewNote = ""
+	if minutes > 0 {
+		a.OvertimeStatus = OvertimeStatusPending
+	} else {
+		a.OvertimeStatus = OvertimeStatusNone
+	}
 }
 
 // LocationResponse represents the location data returned in API responses
 type LocationResponse struct {
-	ID        uint    `json:"id"`
-	Name      string  `json:"name"`
-	Latitude  float64 `json:"latitude"`
-	Longitude float64 `json:"longitude"`
-	CreatedAt string  `json:"createdAt"`
-	UpdatedAt string  `json:"updatedAt"`
+	ID                uint         `json:"id"`
+	Name              string       `json:"name"`
+	Latitude          float64      `json:"latitude"`
+	Longitude         float64      `json:"longitude"`
+	GeofenceRadius    float64      `json:"geofenceRadius"`
+	GeofencePolygon   [][2]float64 `json:"geofencePolygon,omitempty"`
+	MinFaceConfidence *float64     `json:"minFaceConfidence,omitempty"`
+	IsActive          bool         `json:"isActive"`
+	Deleted           bool         `json:"deleted"`
+	CreatedAt         string       `json:"createdAt"`
+	UpdatedAt         string       `json:"updatedAt"`
 }
 
 // ToResponse converts a Location model to a LocationResponse
 func (l *Location) ToResponse() *LocationResponse {
 	return &LocationResponse{
-		ID:        l.ID,
-		Name:      l.Name,
-		Latitude:  l.Latitude,
-		Longitude: l.Longitude,
-		CreatedAt: l.CreatedAt.Format("02-01-2006 15:04:05"),
-		UpdatedAt: l.UpdatedAt.Format("02-01-2006 15:04:05"),
+		ID:                l.ID,
+		Name:              l.Name,
+		Latitude:          l.Latitude,
+		Longitude:         l.Longitude,
+		GeofenceRadius:    l.GeofenceRadius,
+		GeofencePolygon:   l.PolygonPoints(),
+		MinFaceConfidence: l.MinFaceConfidence,
+		IsActive:          l.IsActive,
+		Deleted:           l.DeletedAt != nil,
+		CreatedAt:         l.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:         l.UpdatedAt.Format("02-01-2006 15:04:05"),
 	}
 }
 
@@ -68,6 +196,41 @@ type AttendanceResponse struct {
 	CheckedIn  string `json:"checkedIn"`
 	CheckedOut string `json:"checkedOut"`
 	Checked    bool   `json:"checked"`
+
+	IsMockLocation bool     `json:"isMockLocation"`
+	DeviceID       string   `json:"deviceId,omitempty"`
+	OSBuild        string   `json:"osBuild,omitempty"`
+	FraudScore     int      `json:"fraudScore"`
+	FraudReasons   []string `json:"fraudReasons,omitempty"`
+	Suspicious     bool     `json:"suspicious"`
+
+	OvertimeStatus     string  `json:"overtimeStatus"`
+	ApprovedOvertime   int     `json:"approvedOvertime"`
+	OvertimeReviewedBy *string `json:"overtimeReviewedBy,omitempty"`
+	OvertimeReviewedAt *string `json:"overtimeReviewedAt,omitempty"`
+	OvertimeReviewNote string  `json:"overtimeReviewNote,omitempty"`
+
+	EntryMethod        string  `json:"entryMethod,omitempty"`
+	FallbackStatus     string  `json:"fallbackStatus,omitempty"`
+	FallbackReason     string  `json:"fallbackReason,omitempty"`
+	FallbackReviewedBy *string `json:"fallbackReviewedBy,omitempty"`
+	FallbackReviewedAt *string `json:"fallbackReviewedAt,omitempty"`
+	FallbackReviewNote string  `json:"fallbackReviewNote,omitempty"`
+
+	FaceConfidence         *float64 `json:"faceConfidence,omitempty"`
+	FaceThreshold          *float64 `json:"faceThreshold,omitempty"`
+	CheckOutFaceConfidence *float64 `json:"checkOutFaceConfidence,omitempty"`
+	CheckOutFaceThreshold  *float64 `json:"checkOutFaceThreshold,omitempty"`
+
+	MissingCheckout bool    `json:"missingCheckout"`
+	AutoClosedAt    *string `json:"autoClosedAt,omitempty"`
+
+	BreakMinutes   int                       `json:"breakMinutes"`
+	BreakViolation bool                      `json:"breakViolation"`
+	Breaks         []AttendanceBreakResponse `json:"breaks,omitempty"` // only when the breaks are loaded
+
+	BackfillID        *uint  `json:"backfillId,omitempty"`
+	IncidentReference string `json:"incidentReference,omitempty"` // only when the backfill is loaded
 }
 
 // ToResponse converts an Attendance model to an AttendanceResponse
@@ -101,6 +264,51 @@ func (a *Attendance) ToResponse() *AttendanceResponse {
 		checkedOutStr = "Not Checked Out Yet"
 	}
 
+	// Fraud reasons are stored as a semicolon separated list
+	var fraudReasons []string
+	if a.FraudReasons != "" {
+		fraudReasons = strings.Split(a.FraudReasons, "; ")
+	}
+
+	// Overtime review handlers
+	var overtimeReviewedBy, overtimeReviewedAt *string
+	if a.OvertimeReviewUser != nil {
+		overtimeReviewedBy = &a.OvertimeReviewUser.FullName
+	}
+	if a.OvertimeReviewedAt != nil {
+		formatted := a.OvertimeReviewedAt.Format("02-01-2006 15:04:05")
+		overtimeReviewedAt = &formatted
+	}
+
+	// Fallback review handlers
+	var fallbackReviewedBy, fallbackReviewedAt *string
+	if a.FallbackReviewUser != nil {
+		fallbackReviewedBy = &a.FallbackReviewUser.FullName
+	}
+	if a.FallbackReviewedAt != nil {
+		formatted := a.FallbackReviewedAt.Format("02-01-2006 15:04:05")
+		fallbackReviewedAt = &formatted
+	}
+
+	// Auto-close handler
+	var autoClosedAt *string
+	if a.AutoClosedAt != nil {
+		formatted := a.AutoClosedAt.Format("02-01-2006 15:04:05")
+		autoClosedAt = &formatted
+	}
+
+	// Break handlers
+	var breaks []AttendanceBreakResponse
+	for i := range a.Breaks {
+		breaks = append(breaks, a.Breaks[i].ToResponse())
+	}
+
+	// Backfill handler
+	var incidentReference string
+	if a.Backfill != nil {
+		incidentReference = a.Backfill.IncidentReference
+	}
+
 	return &AttendanceResponse{
 		ID:         a.ID,
 		User:       userName,
@@ -114,5 +322,40 @@ func (a *Attendance) ToResponse() *AttendanceResponse {
 		CheckedIn:  a.CheckedIn.Format("02-01-2006 15:04:05"),
 		CheckedOut: checkedOutStr,
 		Checked:    a.Checked,
+
+		IsMockLocation: a.IsMockLocation,
+		DeviceID:       a.DeviceID,
+		OSBuild:        a.OSBuild,
+		FraudScore:     a.FraudScore,
+		FraudReasons:   fraudReasons,
+		Suspicious:     a.Suspicious,
+
+		OvertimeStatus:     a.OvertimeStatus,
+		ApprovedOvertime:   a.ApprovedOvertime,
+		OvertimeReviewedBy: overtimeReviewedBy,
+		OvertimeReviewedAt: overtimeReviewedAt,
+		OvertimeReviewNote: a.OvertimeReviewNote,
+
+		EntryMethod:        a.EntryMethod,
+		FallbackStatus:     a.FallbackStatus,
+		FallbackReason:     a.FallbackReason,
+		FallbackReviewedBy: fallbackReviewedBy,
+		FallbackReviewedAt: fallbackReviewedAt,
+		FallbackReviewNote: a.FallbackReviewNote,
+
+		FaceConfidence:         a.FaceConfidence,
+		FaceThreshold:          a.FaceThreshold,
+		CheckOutFaceConfidence: a.CheckOutFaceConfidence,
+		CheckOutFaceThreshold:  a.CheckOutFaceThreshold,
+
+		MissingCheckout: a.MissingCheckout,
+		AutoClosedAt:    autoClosedAt,
+
+		BreakMinutes:   a.BreakMinutes,
+		BreakViolation: a.BreakViolation,
+		Breaks:         breaks,
+
+		BackfillID:        a.BackfillID,
+		IncidentReference: incidentReference,
 	}
 }
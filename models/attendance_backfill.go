@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// AttendanceBackfill is a bulk attendance entry made by HR for users the kiosk could not record during an incident,
+// e.g. face recognition being down for an hour. Every attendance it created or completed points back to it.
+type AttendanceBackfill struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	IncidentReference string     `gorm:"not null;type:varchar(100);index" json:"incident_reference"`
+	Date              time.Time  `gorm:"type:date;not null;index" json:"date"`
+	CheckIn           time.Time  `gorm:"not null" json:"check_in"`
+	CheckOut          *time.Time `gorm:"default:null" json:"check_out"`
+	Note              string     `gorm:"type:text" json:"note"`
+	UserIDs           string     `gorm:"type:text" json:"user_ids"` // comma separated user IDs requested, including skipped ones
+	Created           int        `gorm:"type:int;default:0" json:"created"`
+	Completed         int        `gorm:"type:int;default:0" json:"completed"` // open attendances given the checkout
+	Skipped           int        `gorm:"type:int;default:0" json:"skipped"`
+	CreatedBy         uint       `gorm:"not null;index" json:"created_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+
+	CreateUser  *User        `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+	Attendances []Attendance `gorm:"foreignKey:BackfillID" json:"attendances,omitempty"`
+}
+
+// AttendanceBackfillResponse represents the attendance backfill data returned in API responses
+type AttendanceBackfillResponse struct {
+	ID                uint                 `json:"id"`
+	IncidentReference string               `json:"incidentReference"`
+	Date              string               `json:"date"`
+	CheckIn           string               `json:"checkIn"`
+	CheckOut          *string              `json:"checkOut"`
+	Note              string               `json:"note,omitempty"`
+	Created           int                  `json:"created"`
+	Completed         int                  `json:"completed"`
+	Skipped           int                  `json:"skipped"`
+	CreatedBy         string               `json:"createdBy"`
+	CreatedAt         string               `json:"createdAt"`
+	Attendances       []AttendanceResponse `json:"attendances,omitempty"` // only when the attendances are loaded
+}
+
+// ToResponse converts an AttendanceBackfill model to an AttendanceBackfillResponse
+func (ab *AttendanceBackfill) ToResponse() *AttendanceBackfillResponse {
+	// User visual handler
+	var createdBy string
+	if ab.CreateUser != nil {
+		createdBy = ab.CreateUser.FullName
+	}
+
+	var checkOut *string
+	if ab.CheckOut != nil {
+		formatted := ab.CheckOut.Format("15:04")
+		checkOut = &formatted
+	}
+
+	var attendances []AttendanceResponse
+	for i := range ab.Attendances {
+		attendances = append(attendances, *ab.Attendances[i].ToResponse())
+	}
+
+	return &AttendanceBackfillResponse{
+		ID:                ab.ID,
+		IncidentReference: ab.IncidentReference,
+		Date:              ab.Date.Format("02-01-2006"),
+		CheckIn:           ab.CheckIn.Format("15:04"),
+		CheckOut:          checkOut,
+		Note:              ab.Note,
+		Created:           ab.Created,
+		Completed:         ab.Completed,
+		Skipped:           ab.Skipped,
+		CreatedBy:         createdBy,
+		CreatedAt:         ab.CreatedAt.Format("02-01-2006 15:04:05"),
+		Attendances:       attendances,
+	}
+}
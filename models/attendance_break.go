@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// AttendanceBreak is a break taken during an attendance, started and ended at the kiosk.
+// An attendance has at most one break in progress, ended breaks are deducted from the worked time.
+type AttendanceBreak struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	AttendanceID uint       `gorm:"not null;index;uniqueIndex:idx_attendance_breaks_open,where:ended_at IS NULL" json:"attendance_id"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	StartedAt    time.Time  `gorm:"not null" json:"started_at"`
+	EndedAt      *time.Time `gorm:"default:null" json:"ended_at"`
+	Minutes      int        `gorm:"type:int;default:0" json:"minutes"`    // set when the break ends
+	StartMethod  string     `gorm:"type:varchar(20)" json:"start_method"` // face or manual
+	EndMethod    string     `gorm:"type:varchar(20)" json:"end_method"`   // face, manual, or empty when ended by the checkout
+	AutoEnded    bool       `gorm:"default:false" json:"auto_ended"`      // ended by the checkout instead of the user
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// AttendanceBreakResponse represents the attendance break data returned in API responses
+type AttendanceBreakResponse struct {
+	ID          uint    `json:"id"`
+	StartedAt   string  `json:"startedAt"`
+	EndedAt     *string `json:"endedAt,omitempty"`
+	Minutes     int     `json:"minutes"`
+	StartMethod string  `json:"startMethod"`
+	EndMethod   string  `json:"endMethod,omitempty"`
+	AutoEnded   bool    `json:"autoEnded"`
+}
+
+// ToResponse converts an AttendanceBreak model to an AttendanceBreakResponse
+func (ab *AttendanceBreak) ToResponse() AttendanceBreakResponse {
+	var endedAt *string
+	if ab.EndedAt != nil {
+		formatted := ab.EndedAt.Format("02-01-2006 15:04:05")
+		endedAt = &formatted
+	}
+
+	return AttendanceBreakResponse{
+		ID:          ab.ID,
+		StartedAt:   ab.StartedAt.Format("02-01-2006 15:04:05"),
+		EndedAt:     endedAt,
+		Minutes:     ab.Minutes,
+		StartMethod: ab.StartMethod,
+		EndMethod:   ab.EndMethod,
+		AutoEnded:   ab.AutoEnded,
+	}
+}
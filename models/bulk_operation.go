@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Bulk operation types
+const (
+	BulkOperationOrderCreate     = "order_create"
+	BulkOperationOrderSyncStatus = "order_sync_status"
+)
+
+// BulkOperation is the stored result of a bulk request, keeping the failed rows so they can be re-submitted
+type BulkOperation struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Operation   string    `gorm:"not null;type:varchar(50);index" json:"operation"`
+	SourceID    *uint     `gorm:"index" json:"source_id"` // bulk operation whose failed rows were re-submitted
+	Total       int       `gorm:"not null;default:0" json:"total"`
+	Succeeded   int       `gorm:"not null;default:0" json:"succeeded"`
+	Skipped     int       `gorm:"not null;default:0" json:"skipped"`
+	Failed      int       `gorm:"not null;default:0" json:"failed"`
+	FailedRows  string    `gorm:"not null;type:jsonb;default:'[]'" json:"failed_rows"` // JSON array of BulkFailedRow
+	RequestedBy uint      `gorm:"not null;index" json:"requested_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	RequestUser *User `gorm:"foreignKey:RequestedBy" json:"request_user,omitempty"`
+}
+
+// BulkFailedRow is a failed row of a bulk operation, with its index in the original request
+type BulkFailedRow struct {
+	Index int             `json:"index"`
+	Error string          `json:"error"`
+	Row   json.RawMessage `json:"row"` // request row as submitted
+}
+
+// FailedRowList decodes the failed rows
+func (bo *BulkOperation) FailedRowList() []BulkFailedRow {
+	rows := []BulkFailedRow{}
+	if bo.FailedRows != "" {
+		_ = json.Unmarshal([]byte(bo.FailedRows), &rows)
+	}
+	return rows
+}
+
+// BulkOperationResponse represents the bulk operation data returned in API responses
+type BulkOperationResponse struct {
+	ID          uint            `json:"id"`
+	Operation   string          `json:"operation"`
+	SourceID    *uint           `json:"sourceId,omitempty"`
+	Total       int             `json:"total"`
+	Succeeded   int             `json:"succeeded"`
+	Skipped     int             `json:"skipped"`
+	Failed      int             `json:"failed"`
+	FailedRows  []BulkFailedRow `json:"failedRows"`
+	RequestedBy string          `json:"requestedBy"`
+	CreatedAt   string          `json:"createdAt"`
+}
+
+// ToResponse converts a BulkOperation model to a BulkOperationResponse
+func (bo *BulkOperation) ToResponse() *BulkOperationResponse {
+	// User visual handler
+	var requestedBy string
+	if bo.RequestUser != nil {
+		requestedBy = bo.RequestUser.FullName
+	}
+
+	return &BulkOperationResponse{
+		ID:          bo.ID,
+		Operation:   bo.Operation,
+		SourceID:    bo.SourceID,
+		Total:       bo.Total,
+		Succeeded:   bo.Succeeded,
+		Skipped:     bo.Skipped,
+		Failed:      bo.Failed,
+		FailedRows:  bo.FailedRowList(),
+		RequestedBy: requestedBy,
+		CreatedAt:   bo.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
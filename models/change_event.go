@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operations of a change event
+const (
+	ChangeEventUpsert   = "upsert"   // row inserted or updated
+	ChangeEventDelete   = "delete"   // row deleted, the payload only holds the id
+	ChangeEventSnapshot = "snapshot" // current row queued again by a replay
+)
+
+// ChangeEvent is an outbox entry written by database triggers whenever a row of a change feed entity changes,
+// read in id order by the BI warehouse loader
+type ChangeEvent struct {
+	ID            uint64    `gorm:"primaryKey;index:idx_change_events_entity_cursor,priority:2" json:"id"` // cursor of the change feed
+	Entity        string    `gorm:"not null;type:varchar(30);index:idx_change_events_entity_cursor,priority:1" json:"entity"`
+	EntityID      uint      `gorm:"not null;index" json:"entity_id"`
+	Operation     string    `gorm:"not null;type:varchar(10)" json:"operation"`
+	SchemaVersion int       `gorm:"not null" json:"schema_version"`
+	Payload       string    `gorm:"not null;type:jsonb" json:"payload"` // row columns listed in the entity schema
+	ChangedAt     time.Time `gorm:"not null;index" json:"changed_at"`
+}
+
+// ChangeEventResponse is a line of the NDJSON change feed
+type ChangeEventResponse struct {
+	Cursor        uint64          `json:"cursor"`
+	Entity        string          `json:"entity"`
+	EntityID      uint            `json:"entityId"`
+	Operation     string          `json:"operation"`
+	SchemaVersion int             `json:"schemaVersion"`
+	ChangedAt     string          `json:"changedAt"` // RFC 3339 with the server offset
+	Data          json.RawMessage `json:"data"`
+}
+
+// ToResponse converts a ChangeEvent model to a ChangeEventResponse
+func (ce *ChangeEvent) ToResponse() *ChangeEventResponse {
+	return &ChangeEventResponse{
+		Cursor:        ce.ID,
+		Entity:        ce.Entity,
+		EntityID:      ce.EntityID,
+		Operation:     ce.Operation,
+		SchemaVersion: ce.SchemaVersion,
+		ChangedAt:     ce.ChangedAt.Format(time.RFC3339Nano),
+		Data:          json.RawMessage(ce.Payload),
+	}
+}
@@ -2,30 +2,80 @@ package models
 
 import "time"
 
+// Order duplication policies, how DuplicateOrder assigns the tracking numbers of a channel's orders
+const (
+	DuplicationPolicySwap     = "swap"     // the duplicate takes over the tracking number, the original keeps it with an X- prefix
+	DuplicationPolicyInternal = "internal" // the original keeps its tracking number, the duplicate gets an internal tracking number
+	DuplicationPolicyManual   = "manual"   // the original keeps its tracking number, the duplicate's tracking number is entered by hand
+)
+
+var duplicationPolicies = []string{DuplicationPolicySwap, DuplicationPolicyInternal, DuplicationPolicyManual}
+
+// DuplicationPolicies returns the known order duplication policies
+func DuplicationPolicies() []string {
+	return duplicationPolicies
+}
+
+// IsValidDuplicationPolicy reports whether policy is a known order duplication policy
+func IsValidDuplicationPolicy(policy string) bool {
+	for _, known := range duplicationPolicies {
+		if known == policy {
+			return true
+		}
+	}
+	return false
+}
+
 type Channel struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	ChannelCode string    `gorm:"uniqueIndex;not null;type:varchar(50)" json:"channel_code"`
-	ChannelName string    `gorm:"not null;type:varchar(100)" json:"channel_name"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ChannelCode string `gorm:"uniqueIndex;not null;type:varchar(50)" json:"channel_code"`
+	ChannelName string `gorm:"not null;type:varchar(100)" json:"channel_name"`
+	QCLane      string `gorm:"not null;type:varchar(20);default:online" json:"qc_lane"` // ribbon or online
+	// Printed order documents: whether the parcel carries an invoice and the language of the packing slip and invoice
+	InvoiceRequired bool   `gorm:"default:false" json:"invoice_required"`
+	LabelLanguage   string `gorm:"not null;type:varchar(5);default:id" json:"label_language"` // id or en
+	// Prefix of order numbers generated for the channel's orders without a marketplace order id, overridden by the store's prefix
+	OrderNumberPrefix string `gorm:"type:varchar(20)" json:"order_number_prefix"`
+	// Source of the channel's orders when an order does not name one, e.g. offline for a shop counter channel
+	OrderSource string `gorm:"not null;type:varchar(20);default:marketplace" json:"order_source"`
+	// How duplicating an order of the channel assigns tracking numbers, some channels forbid reusing the original one
+	DuplicationPolicy string `gorm:"not null;type:varchar(20);default:swap" json:"duplication_policy"`
+	// Created automatically from an order naming an unknown channel, waiting for an admin to approve or merge it
+	PendingReview bool      `gorm:"not null;default:false;index" json:"pending_review"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // ChannelResponse represents the channel data returned in API responses
 type ChannelResponse struct {
-	ID          uint   `json:"id"`
-	ChannelCode string `json:"channelCode"`
-	ChannelName string `json:"channelName"`
-	CreatedAt   string `json:"createdAt"`
-	UpdatedAt   string `json:"updatedAt"`
+	ID                uint   `json:"id"`
+	ChannelCode       string `json:"channelCode"`
+	ChannelName       string `json:"channelName"`
+	QCLane            string `json:"qcLane"`
+	InvoiceRequired   bool   `json:"invoiceRequired"`
+	LabelLanguage     string `json:"labelLanguage"`
+	OrderNumberPrefix string `json:"orderNumberPrefix"`
+	OrderSource       string `json:"orderSource"`
+	DuplicationPolicy string `json:"duplicationPolicy"`
+	PendingReview     bool   `json:"pendingReview"`
+	CreatedAt         string `json:"createdAt"`
+	UpdatedAt         string `json:"updatedAt"`
 }
 
 // ToResponse converts a Channel model to a ChannelResponse
 func (ch *Channel) ToResponse() *ChannelResponse {
 	return &ChannelResponse{
-		ID:          ch.ID,
-		ChannelCode: ch.ChannelCode,
-		ChannelName: ch.ChannelName,
-		CreatedAt:   ch.CreatedAt.Format("02-01-2006 15:04:05"),
-		UpdatedAt:   ch.UpdatedAt.Format("02-01-2006 15:04:05"),
+		ID:                ch.ID,
+		ChannelCode:       ch.ChannelCode,
+		ChannelName:       ch.ChannelName,
+		QCLane:            ch.QCLane,
+		InvoiceRequired:   ch.InvoiceRequired,
+		LabelLanguage:     ch.LabelLanguage,
+		OrderNumberPrefix: ch.OrderNumberPrefix,
+		OrderSource:       ch.OrderSource,
+		DuplicationPolicy: ch.DuplicationPolicy,
+		PendingReview:     ch.PendingReview,
+		CreatedAt:         ch.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:         ch.UpdatedAt.Format("02-01-2006 15:04:05"),
 	}
 }
@@ -3,25 +3,38 @@ package models
 import "time"
 
 type Complain struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	Code           string    `gorm:"not null;uniqueIndex;type:varchar(50)" json:"code"`
-	TrackingNumber string    `gorm:"not null;uniqueIndex;type:varchar(100)" json:"tracking_number"`
-	OrderGineeID   string    `gorm:"not null;index;type:varchar(100)" json:"order_ginee_id"`
-	ChannelID      uint      `gorm:"not null" json:"channel_id"`
-	StoreID        uint      `gorm:"not null" json:"store_id"`
-	CreatedBy      uint      `gorm:"not null" json:"created_by"`
-	Reason         string    `gorm:"not null;type:text" json:"reason"`
-	Solution       *string   `gorm:"default:null;type:text" json:"solution"`
-	TotalFee       *int      `gorm:"default:null" json:"total_fee"`
-	Checked        bool      `gorm:"default:false" json:"checked"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Code           string     `gorm:"not null;uniqueIndex;type:varchar(50)" json:"code"`
+	TrackingNumber string     `gorm:"not null;uniqueIndex;type:varchar(100)" json:"tracking_number"`
+	OrderGineeID   string     `gorm:"not null;index;type:varchar(100)" json:"order_ginee_id"`
+	ChannelID      uint       `gorm:"not null;uniqueIndex:idx_complain_external_case" json:"channel_id"`
+	StoreID        uint       `gorm:"not null" json:"store_id"`
+	CreatedBy      uint       `gorm:"not null" json:"created_by"`
+	Reason         string     `gorm:"not null;type:text" json:"reason"`
+	Solution       *string    `gorm:"default:null;type:text" json:"solution"`
+	TotalFee       *int       `gorm:"default:null" json:"total_fee"`
+	Checked        bool       `gorm:"default:false" json:"checked"`
+	ExternalCaseID *string    `gorm:"default:null;type:varchar(100);uniqueIndex:idx_complain_external_case" json:"external_case_id"` // marketplace dispute case the complain was created from
+	RootCauseID    *uint      `gorm:"default:null;index" json:"root_cause_id"`                                                       // nil on complains filed before the root cause taxonomy
+	SerialNumber   *string    `gorm:"default:null;type:varchar(100);index" json:"serial_number"`                                     // serial of the returned unit, checked against the serials shipped at QC
+	SettlementID   *uint      `gorm:"default:null;index" json:"settlement_id"`                                                       // set once the fees are frozen in a settlement
+	ReopenCount    int        `gorm:"not null;default:0;index" json:"reopen_count"`                                                  // times the complain was reopened after being resolved
+	LastReopenedAt *time.Time `gorm:"default:null" json:"last_reopened_at"`
+	AssignedTo     *uint      `gorm:"default:null;index" json:"assigned_to"` // CS agent owning the complain, nil while unassigned
+	AssignedAt     *time.Time `gorm:"default:null" json:"assigned_at"`
+	ResolvedAt     *time.Time `gorm:"default:null;index" json:"resolved_at"` // when the current solution was first given, cleared on reopen
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
 
 	ComplainProductDetails []ComplainProductDetail `gorm:"foreignKey:ComplainID" json:"complain_product_details,omitempty"`
 	ComplainUserDetails    []ComplainUserDetail    `gorm:"foreignKey:ComplainID" json:"complain_user_details,omitempty"`
 	Channel                *Channel                `gorm:"foreignKey:ChannelID" json:"channel,omitempty"`
 	Store                  *Store                  `gorm:"foreignKey:StoreID" json:"store,omitempty"`
 	CreateUser             *User                   `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+	RootCause              *ComplainRootCause      `gorm:"foreignKey:RootCauseID" json:"root_cause,omitempty"`
+	Reopens                []ComplainReopen        `gorm:"foreignKey:ComplainID" json:"reopens,omitempty"`
+	AssignUser             *User                   `gorm:"foreignKey:AssignedTo" json:"assign_user,omitempty"`
+	Assignments            []ComplainAssignment    `gorm:"foreignKey:ComplainID" json:"assignments,omitempty"`
 	Order                  *Order                  `gorm:"-" json:"order,omitempty"`
 	Return                 *Return                 `gorm:"-" json:"return,omitempty"`
 }
@@ -55,10 +68,24 @@ type ComplainResponse struct {
 	Channel        string                          `json:"channel"`
 	Store          string                          `json:"store"`
 	Reason         string                          `json:"reason"`
+	RootCauseID    *uint                           `json:"rootCauseId,omitempty"`
+	RootCauseCode  string                          `json:"rootCauseCode,omitempty"`
+	RootCause      string                          `json:"rootCause,omitempty"`
 	CreatedBy      string                          `json:"createdBy"`
 	Solution       *string                         `json:"solution,omitempty"`
 	TotalFee       *int                            `json:"totalFee,omitempty"`
 	Checked        bool                            `json:"checked"`
+	ExternalCaseID *string                         `json:"externalCaseId,omitempty"`
+	SerialNumber   *string                         `json:"serialNumber,omitempty"`
+	SettlementID   *uint                           `json:"settlementId,omitempty"` // fees can no longer be edited once settled
+	ReopenCount    int                             `json:"reopenCount"`
+	LastReopenedAt *string                         `json:"lastReopenedAt,omitempty"`
+	Reopens        []ComplainReopenResponse        `json:"reopens,omitempty"`
+	AssignedToID   *uint                           `json:"assignedToId,omitempty"`
+	AssignedTo     *string                         `json:"assignedTo,omitempty"`
+	AssignedAt     *string                         `json:"assignedAt,omitempty"`
+	ResolvedAt     *string                         `json:"resolvedAt,omitempty"`
+	Assignments    []ComplainAssignmentResponse    `json:"assignments,omitempty"`
 	CreatedAt      string                          `json:"createdAt"`
 	UpdatedAt      string                          `json:"updatedAt"`
 	ProductDetails []ComplainProductDetailResponse `json:"details,omitempty"`
@@ -129,6 +156,42 @@ func (c *Complain) ToComplainResponse() *ComplainResponse {
 		createuser = c.CreateUser.FullName
 	}
 
+	// Root cause visual handler
+	var rootCauseCode, rootCause string
+	if c.RootCause != nil {
+		rootCauseCode = c.RootCause.Code
+		rootCause = c.RootCause.Name
+	}
+
+	// Reopen history, when loaded
+	var lastReopenedAt *string
+	if c.LastReopenedAt != nil {
+		formatted := c.LastReopenedAt.Format("02-01-2006 15:04:05")
+		lastReopenedAt = &formatted
+	}
+	var reopens []ComplainReopenResponse
+	for _, reopen := range c.Reopens {
+		reopens = append(reopens, reopen.ToResponse())
+	}
+
+	// Assignment visual handler and history, when loaded
+	var assignedTo, assignedAt, resolvedAt *string
+	if c.AssignUser != nil {
+		assignedTo = &c.AssignUser.FullName
+	}
+	if c.AssignedAt != nil {
+		formatted := c.AssignedAt.Format("02-01-2006 15:04:05")
+		assignedAt = &formatted
+	}
+	if c.ResolvedAt != nil {
+		formatted := c.ResolvedAt.Format("02-01-2006 15:04:05")
+		resolvedAt = &formatted
+	}
+	var assignments []ComplainAssignmentResponse
+	for _, assignment := range c.Assignments {
+		assignments = append(assignments, assignment.ToResponse())
+	}
+
 	return &ComplainResponse{
 		ID:             c.ID,
 		Code:           c.Code,
@@ -136,10 +199,24 @@ func (c *Complain) ToComplainResponse() *ComplainResponse {
 		Channel:        channelName,
 		Store:          storeName,
 		Reason:         c.Reason,
+		RootCauseID:    c.RootCauseID,
+		RootCauseCode:  rootCauseCode,
+		RootCause:      rootCause,
 		CreatedBy:      createuser,
 		Solution:       c.Solution,
 		TotalFee:       c.TotalFee,
 		Checked:        c.Checked,
+		ExternalCaseID: c.ExternalCaseID,
+		SerialNumber:   c.SerialNumber,
+		SettlementID:   c.SettlementID,
+		ReopenCount:    c.ReopenCount,
+		LastReopenedAt: lastReopenedAt,
+		Reopens:        reopens,
+		AssignedToID:   c.AssignedTo,
+		AssignedTo:     assignedTo,
+		AssignedAt:     assignedAt,
+		ResolvedAt:     resolvedAt,
+		Assignments:    assignments,
 		CreatedAt:      c.CreatedAt.Format("02-01-2006 15:04:05"),
 		UpdatedAt:      c.UpdatedAt.Format("02-01-2006 15:04:05"),
 		ProductDetails: productDetailsResponse,
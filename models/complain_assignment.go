@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Complain assignment methods
+const (
+	ComplainAssignmentManual = "manual" // picked by a supervisor
+	ComplainAssignmentAuto   = "auto"   // round-robin over the CS agents
+)
+
+// ComplainAssignment is a complain handed to a CS agent, the history keeps every reassignment
+type ComplainAssignment struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ComplainID uint      `gorm:"not null;index" json:"complain_id"`
+	FromUserID *uint     `gorm:"default:null" json:"from_user_id"` // previous agent, nil on the first assignment
+	ToUserID   uint      `gorm:"not null;index" json:"to_user_id"`
+	Method     string    `gorm:"not null;type:varchar(20)" json:"method"`
+	Reason     *string   `gorm:"default:null;type:text" json:"reason"`
+	AssignedBy *uint     `gorm:"default:null" json:"assigned_by"` // nil when assigned automatically on creation
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+
+	FromUser   *User `gorm:"foreignKey:FromUserID" json:"from_user,omitempty"`
+	ToUser     *User `gorm:"foreignKey:ToUserID" json:"to_user,omitempty"`
+	AssignUser *User `gorm:"foreignKey:AssignedBy" json:"assign_user,omitempty"`
+}
+
+// ComplainAssignmentResponse represents the complain assignment data returned in API responses
+type ComplainAssignmentResponse struct {
+	ID         uint    `json:"id"`
+	FromUserID *uint   `json:"fromUserId,omitempty"`
+	FromUser   *string `json:"fromUser,omitempty"`
+	ToUserID   uint    `json:"toUserId"`
+	ToUser     string  `json:"toUser"`
+	Method     string  `json:"method"`
+	Reason     *string `json:"reason,omitempty"`
+	AssignedBy *string `json:"assignedBy,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+// ToResponse converts a ComplainAssignment model to a ComplainAssignmentResponse
+func (ca *ComplainAssignment) ToResponse() ComplainAssignmentResponse {
+	// User visual handlers
+	var fromUser, assignedBy *string
+	if ca.FromUser != nil {
+		fromUser = &ca.FromUser.FullName
+	}
+	var toUser string
+	if ca.ToUser != nil {
+		toUser = ca.ToUser.FullName
+	}
+	if ca.AssignUser != nil {
+		assignedBy = &ca.AssignUser.FullName
+	}
+
+	return ComplainAssignmentResponse{
+		ID:         ca.ID,
+		FromUserID: ca.FromUserID,
+		FromUser:   fromUser,
+		ToUserID:   ca.ToUserID,
+		ToUser:     toUser,
+		Method:     ca.Method,
+		Reason:     ca.Reason,
+		AssignedBy: assignedBy,
+		CreatedAt:  ca.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
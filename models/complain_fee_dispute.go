@@ -0,0 +1,162 @@
+package models
+
+import "time"
+
+// Complain fee dispute statuses
+const (
+	FeeDisputeStatusOpen     = "open"
+	FeeDisputeStatusAdjusted = "adjusted"
+	FeeDisputeStatusWaived   = "waived"
+	FeeDisputeStatusRejected = "rejected"
+)
+
+// Complain fee change actions
+const (
+	FeeChangeActionCharge  = "charge"  // fee set or changed while updating the complain
+	FeeChangeActionDispute = "dispute" // the charged user disputed the fee
+	FeeChangeActionAdjust  = "adjust"
+	FeeChangeActionWaive   = "waive"
+	FeeChangeActionReject  = "reject"
+)
+
+// ComplainFeeDispute is a charged user contesting the fee a complain charges them, reviewed by a coordinator or HRD.
+// It is keyed by complain and user so it survives the user details being replaced when the complain is updated.
+type ComplainFeeDispute struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ComplainID  uint       `gorm:"not null;index" json:"complain_id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	DisputedFee int        `gorm:"not null" json:"disputed_fee"`
+	Note        string     `gorm:"not null;type:text" json:"note"`
+	Status      string     `gorm:"not null;default:'open';type:varchar(20);index" json:"status"`
+	ResolvedFee *int       `gorm:"default:null" json:"resolved_fee"`
+	ReviewNote  string     `gorm:"type:text" json:"review_note"`
+	ReviewedBy  *uint      `gorm:"default:null" json:"reviewed_by"`
+	ReviewedAt  *time.Time `gorm:"default:null" json:"reviewed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Complain   *Complain `gorm:"foreignKey:ComplainID" json:"complain,omitempty"`
+	User       *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ReviewUser *User     `gorm:"foreignKey:ReviewedBy" json:"review_user,omitempty"`
+}
+
+// ComplainFeeChange is the audit trail of the fee charged to a user by a complain
+type ComplainFeeChange struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ComplainID  uint      `gorm:"not null;index" json:"complain_id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	DisputeID   *uint     `gorm:"default:null;index" json:"dispute_id"`
+	Action      string    `gorm:"not null;type:varchar(20)" json:"action"`
+	PreviousFee int       `gorm:"not null" json:"previous_fee"`
+	NewFee      int       `gorm:"not null" json:"new_fee"`
+	Note        string    `gorm:"type:text" json:"note"`
+	ChangedBy   uint      `gorm:"not null" json:"changed_by"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	User       *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ChangeUser *User `gorm:"foreignKey:ChangedBy" json:"change_user,omitempty"`
+}
+
+// ComplainFeeDisputeResponse represents the complain fee dispute data returned in API responses
+type ComplainFeeDisputeResponse struct {
+	ID             uint    `json:"id"`
+	ComplainID     uint    `json:"complainId"`
+	ComplainCode   string  `json:"complainCode"`
+	TrackingNumber string  `json:"trackingNumber"`
+	UserID         uint    `json:"userId"`
+	User           string  `json:"user"`
+	DisputedFee    int     `json:"disputedFee"`
+	Note           string  `json:"note"`
+	Status         string  `json:"status"`
+	ResolvedFee    *int    `json:"resolvedFee,omitempty"`
+	ReviewNote     string  `json:"reviewNote,omitempty"`
+	ReviewedBy     *string `json:"reviewedBy,omitempty"`
+	ReviewedAt     *string `json:"reviewedAt,omitempty"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
+// ComplainFeeChangeResponse represents the complain fee change data returned in API responses
+type ComplainFeeChangeResponse struct {
+	ID          uint   `json:"id"`
+	ComplainID  uint   `json:"complainId"`
+	UserID      uint   `json:"userId"`
+	User        string `json:"user"`
+	DisputeID   *uint  `json:"disputeId,omitempty"`
+	Action      string `json:"action"`
+	PreviousFee int    `json:"previousFee"`
+	NewFee      int    `json:"newFee"`
+	Note        string `json:"note,omitempty"`
+	ChangedBy   string `json:"changedBy"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+// ToResponse converts a ComplainFeeDispute model to a ComplainFeeDisputeResponse
+func (cfd *ComplainFeeDispute) ToResponse() *ComplainFeeDisputeResponse {
+	// Complain visual handlers
+	var complainCode, trackingNumber string
+	if cfd.Complain != nil {
+		complainCode = cfd.Complain.Code
+		trackingNumber = cfd.Complain.TrackingNumber
+	}
+
+	// User visual handlers
+	var user string
+	if cfd.User != nil {
+		user = cfd.User.FullName
+	}
+	var reviewedBy *string
+	if cfd.ReviewUser != nil {
+		reviewedBy = &cfd.ReviewUser.FullName
+	}
+
+	var reviewedAt *string
+	if cfd.ReviewedAt != nil {
+		formatted := cfd.ReviewedAt.Format("02-01-2006 15:04:05")
+		reviewedAt = &formatted
+	}
+
+	return &ComplainFeeDisputeResponse{
+		ID:             cfd.ID,
+		ComplainID:     cfd.ComplainID,
+		ComplainCode:   complainCode,
+		TrackingNumber: trackingNumber,
+		UserID:         cfd.UserID,
+		User:           user,
+		DisputedFee:    cfd.DisputedFee,
+		Note:           cfd.Note,
+		Status:         cfd.Status,
+		ResolvedFee:    cfd.ResolvedFee,
+		ReviewNote:     cfd.ReviewNote,
+		ReviewedBy:     reviewedBy,
+		ReviewedAt:     reviewedAt,
+		CreatedAt:      cfd.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:      cfd.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// ToResponse converts a ComplainFeeChange model to a ComplainFeeChangeResponse
+func (cfc *ComplainFeeChange) ToResponse() *ComplainFeeChangeResponse {
+	// User visual handlers
+	var user, changedBy string
+	if cfc.User != nil {
+		user = cfc.User.FullName
+	}
+	if cfc.ChangeUser != nil {
+		changedBy = cfc.ChangeUser.FullName
+	}
+
+	return &ComplainFeeChangeResponse{
+		ID:          cfc.ID,
+		ComplainID:  cfc.ComplainID,
+		UserID:      cfc.UserID,
+		User:        user,
+		DisputeID:   cfc.DisputeID,
+		Action:      cfc.Action,
+		PreviousFee: cfc.PreviousFee,
+		NewFee:      cfc.NewFee,
+		Note:        cfc.Note,
+		ChangedBy:   changedBy,
+		CreatedAt:   cfc.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
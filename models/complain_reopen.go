@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// ComplainReopen is a resolved complain put back under investigation, with the resolution it replaced
+type ComplainReopen struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ComplainID       uint      `gorm:"not null;index" json:"complain_id"`
+	Reason           string    `gorm:"not null;type:text" json:"reason"`
+	PreviousSolution *string   `gorm:"default:null;type:text" json:"previous_solution"`
+	PreviousTotalFee *int      `gorm:"default:null" json:"previous_total_fee"`
+	ReopenedBy       uint      `gorm:"not null" json:"reopened_by"`
+	CreatedAt        time.Time `gorm:"index" json:"created_at"`
+
+	ReopenUser *User `gorm:"foreignKey:ReopenedBy" json:"reopen_user,omitempty"`
+}
+
+// ComplainReopenResponse represents the complain reopen data returned in API responses
+type ComplainReopenResponse struct {
+	ID               uint    `json:"id"`
+	Reason           string  `json:"reason"`
+	PreviousSolution *string `json:"previousSolution,omitempty"`
+	PreviousTotalFee *int    `json:"previousTotalFee,omitempty"`
+	ReopenedBy       string  `json:"reopenedBy"`
+	CreatedAt        string  `json:"createdAt"`
+}
+
+// ToResponse converts a ComplainReopen model to a ComplainReopenResponse
+func (cr *ComplainReopen) ToResponse() ComplainReopenResponse {
+	// User visual handler
+	var reopenedBy string
+	if cr.ReopenUser != nil {
+		reopenedBy = cr.ReopenUser.FullName
+	}
+
+	return ComplainReopenResponse{
+		ID:               cr.ID,
+		Reason:           cr.Reason,
+		PreviousSolution: cr.PreviousSolution,
+		PreviousTotalFee: cr.PreviousTotalFee,
+		ReopenedBy:       reopenedBy,
+		CreatedAt:        cr.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
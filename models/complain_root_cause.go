@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Codes of the default complain root causes, marketplace disputes are classified with them
+const (
+	ComplainRootCauseWrongItem      = "wrong_item"
+	ComplainRootCauseMissingItem    = "missing_item"
+	ComplainRootCauseDamaged        = "damaged"
+	ComplainRootCauseCourierIssue   = "courier_issue"
+	ComplainRootCauseProductQuality = "product_quality"
+	ComplainRootCauseBuyerRelated   = "buyer_related"
+	ComplainRootCauseOther          = "other"
+)
+
+// ComplainRootCause is an entry of the managed taxonomy of complain reasons, used to find the process to fix
+type ComplainRootCause struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Code        string    `gorm:"uniqueIndex;not null;type:varchar(50)" json:"code"`
+	Name        string    `gorm:"not null;type:varchar(100)" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	IsActive    bool      `gorm:"not null" json:"is_active"` // inactive root causes stay on past complains but cannot be selected
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ComplainRootCauseResponse represents the complain root cause data returned in API responses
+type ComplainRootCauseResponse struct {
+	ID          uint   `json:"id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	IsActive    bool   `json:"isActive"`
+	CreatedAt   string `json:"createdAt"`
+	UpdatedAt   string `json:"updatedAt"`
+}
+
+// ToResponse converts a ComplainRootCause model to a ComplainRootCauseResponse
+func (crc *ComplainRootCause) ToResponse() *ComplainRootCauseResponse {
+	return &ComplainRootCauseResponse{
+		ID:          crc.ID,
+		Code:        crc.Code,
+		Name:        crc.Name,
+		Description: crc.Description,
+		IsActive:    crc.IsActive,
+		CreatedAt:   crc.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:   crc.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,144 @@
+package models
+
+import "time"
+
+// Complain settlement statuses
+const (
+	ComplainSettlementStatusOpen    = "open"    // fees frozen, deductions not applied to payroll yet
+	ComplainSettlementStatusSettled = "settled" // deductions applied to payroll
+)
+
+// ComplainSettlement freezes the complain fees charged up to the end of a month into per-user deduction statements.
+// Complains included in a settlement keep their fees, they can no longer be edited or disputed.
+type ComplainSettlement struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Period        string     `gorm:"not null;uniqueIndex;type:varchar(7)" json:"period"` // YYYY-MM
+	PeriodEnd     time.Time  `gorm:"not null" json:"period_end"`                         // complains created before this moment are included
+	Status        string     `gorm:"not null;default:'open';type:varchar(20);index" json:"status"`
+	ComplainCount int        `gorm:"not null;default:0" json:"complain_count"`
+	UserCount     int        `gorm:"not null;default:0" json:"user_count"`
+	TotalFee      int64      `gorm:"not null;default:0" json:"total_fee"`
+	GeneratedBy   uint       `gorm:"not null" json:"generated_by"`
+	SettledBy     *uint      `gorm:"default:null" json:"settled_by"`
+	SettledAt     *time.Time `gorm:"default:null" json:"settled_at"`
+	SettleNote    string     `gorm:"type:text" json:"settle_note"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Lines        []ComplainSettlementLine `gorm:"foreignKey:SettlementID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"lines,omitempty"`
+	GenerateUser *User                    `gorm:"foreignKey:GeneratedBy" json:"generate_user,omitempty"`
+	SettleUser   *User                    `gorm:"foreignKey:SettledBy" json:"settle_user,omitempty"`
+}
+
+// ComplainSettlementLine is the frozen fee a settled complain charges a user
+type ComplainSettlementLine struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	SettlementID      uint      `gorm:"not null;index" json:"settlement_id"`
+	ComplainID        uint      `gorm:"not null;index" json:"complain_id"`
+	UserID            uint      `gorm:"not null;index" json:"user_id"`
+	ComplainCode      string    `gorm:"not null;type:varchar(50)" json:"complain_code"`
+	TrackingNumber    string    `gorm:"not null;type:varchar(100)" json:"tracking_number"`
+	FeeCharge         int       `gorm:"not null" json:"fee_charge"`
+	ComplainCreatedAt time.Time `gorm:"not null" json:"complain_created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// ComplainSettlementResponse represents the complain settlement data returned in API responses
+type ComplainSettlementResponse struct {
+	ID            uint                          `json:"id"`
+	Period        string                        `json:"period"`
+	PeriodEnd     string                        `json:"periodEnd"`
+	Status        string                        `json:"status"`
+	ComplainCount int                           `json:"complainCount"`
+	UserCount     int                           `json:"userCount"`
+	TotalFee      int64                         `json:"totalFee"`
+	GeneratedBy   string                        `json:"generatedBy"`
+	SettledBy     *string                       `json:"settledBy,omitempty"`
+	SettledAt     *string                       `json:"settledAt,omitempty"`
+	SettleNote    string                        `json:"settleNote,omitempty"`
+	CreatedAt     string                        `json:"createdAt"`
+	Statements    []ComplainSettlementStatement `json:"statements,omitempty"`
+}
+
+// ComplainSettlementStatement is the deduction statement of a user in a settlement
+type ComplainSettlementStatement struct {
+	UserID        uint                             `json:"userId"`
+	Username      string                           `json:"username"`
+	FullName      string                           `json:"fullName"`
+	ComplainCount int                              `json:"complainCount"`
+	TotalFee      int64                            `json:"totalFee"`
+	Lines         []ComplainSettlementLineResponse `json:"lines"`
+}
+
+type ComplainSettlementLineResponse struct {
+	ComplainID        uint   `json:"complainId"`
+	ComplainCode      string `json:"complainCode"`
+	TrackingNumber    string `json:"trackingNumber"`
+	FeeCharge         int    `json:"feeCharge"`
+	ComplainCreatedAt string `json:"complainCreatedAt"`
+}
+
+// ToResponse converts a ComplainSettlement model to a ComplainSettlementResponse, with the statements of its loaded lines
+func (cs *ComplainSettlement) ToResponse() *ComplainSettlementResponse {
+	// User visual handlers
+	var generatedBy string
+	if cs.GenerateUser != nil {
+		generatedBy = cs.GenerateUser.FullName
+	}
+	var settledBy *string
+	if cs.SettleUser != nil {
+		settledBy = &cs.SettleUser.FullName
+	}
+
+	var settledAt *string
+	if cs.SettledAt != nil {
+		formatted := cs.SettledAt.Format("02-01-2006 15:04:05")
+		settledAt = &formatted
+	}
+
+	return &ComplainSettlementResponse{
+		ID:            cs.ID,
+		Period:        cs.Period,
+		PeriodEnd:     cs.PeriodEnd.Format("02-01-2006 15:04:05"),
+		Status:        cs.Status,
+		ComplainCount: cs.ComplainCount,
+		UserCount:     cs.UserCount,
+		TotalFee:      cs.TotalFee,
+		GeneratedBy:   generatedBy,
+		SettledBy:     settledBy,
+		SettledAt:     settledAt,
+		SettleNote:    cs.SettleNote,
+		CreatedAt:     cs.CreatedAt.Format("02-01-2006 15:04:05"),
+		Statements:    cs.Statements(),
+	}
+}
+
+// Statements groups the loaded lines of the settlement per user, in the order of the lines
+func (cs *ComplainSettlement) Statements() []ComplainSettlementStatement {
+	var statements []ComplainSettlementStatement
+	indexByUser := make(map[uint]int)
+	for _, line := range cs.Lines {
+		index, ok := indexByUser[line.UserID]
+		if !ok {
+			index = len(statements)
+			indexByUser[line.UserID] = index
+			statement := ComplainSettlementStatement{UserID: line.UserID, Lines: []ComplainSettlementLineResponse{}}
+			if line.User != nil {
+				statement.Username = line.User.Username
+				statement.FullName = line.User.FullName
+			}
+			statements = append(statements, statement)
+		}
+		statements[index].ComplainCount++
+		statements[index].TotalFee += int64(line.FeeCharge)
+		statements[index].Lines = append(statements[index].Lines, ComplainSettlementLineResponse{
+			ComplainID:        line.ComplainID,
+			ComplainCode:      line.ComplainCode,
+			TrackingNumber:    line.TrackingNumber,
+			FeeCharge:         line.FeeCharge,
+			ComplainCreatedAt: line.ComplainCreatedAt.Format("02-01-2006 15:04:05"),
+		})
+	}
+	return statements
+}
@@ -0,0 +1,69 @@
+package models
+
+import "time"
+
+// Courier booking statuses
+const (
+	CourierBookingStatusPending = "pending" // sent to the courier API, waiting for its answer
+	CourierBookingStatusBooked  = "booked"  // booked, the tracking number was written back to the order
+	CourierBookingStatusFailed  = "failed"  // rejected by the courier or not written back, booked again by hand
+)
+
+// CourierBooking logs a shipment booked through a courier API for an order created without a tracking number
+type CourierBooking struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	OrderID          uint      `gorm:"not null;index" json:"order_id"`
+	ExpeditionID     uint      `gorm:"not null" json:"expedition_id"`
+	ExpeditionSlug   string    `gorm:"not null;type:varchar(100)" json:"expedition_slug"`
+	Status           string    `gorm:"not null;type:varchar(20);index" json:"status"`
+	BookingReference string    `gorm:"type:varchar(100)" json:"booking_reference"` // booking id of the courier
+	TrackingNumber   string    `gorm:"type:varchar(100)" json:"tracking_number"`   // official tracking number issued by the courier
+	WeightGrams      int       `gorm:"not null" json:"weight_grams"`               // chargeable weight the shipment was booked with
+	VolumeCm3        float64   `gorm:"not null;default:0" json:"volume_cm3"`
+	Error            string    `gorm:"type:text" json:"error"`
+	BookedBy         uint      `gorm:"not null" json:"booked_by"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	BookUser *User `gorm:"foreignKey:BookedBy" json:"book_user,omitempty"`
+}
+
+// CourierBookingResponse represents the courier booking data returned in API responses
+type CourierBookingResponse struct {
+	ID               uint    `json:"id"`
+	OrderID          uint    `json:"orderId"`
+	ExpeditionSlug   string  `json:"expeditionSlug"`
+	Status           string  `json:"status"`
+	BookingReference string  `json:"bookingReference"`
+	TrackingNumber   string  `json:"trackingNumber"`
+	WeightGrams      int     `json:"weightGrams"`
+	VolumeCm3        float64 `json:"volumeCm3"`
+	Error            string  `json:"error,omitempty"`
+	BookedBy         *string `json:"bookedBy"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+}
+
+// ToResponse converts a CourierBooking model to a CourierBookingResponse
+func (cb *CourierBooking) ToResponse() *CourierBookingResponse {
+	// User visual handler
+	var bookedBy *string
+	if cb.BookUser != nil {
+		bookedBy = &cb.BookUser.FullName
+	}
+
+	return &CourierBookingResponse{
+		ID:               cb.ID,
+		OrderID:          cb.OrderID,
+		ExpeditionSlug:   cb.ExpeditionSlug,
+		Status:           cb.Status,
+		BookingReference: cb.BookingReference,
+		TrackingNumber:   cb.TrackingNumber,
+		WeightGrams:      cb.WeightGrams,
+		VolumeCm3:        cb.VolumeCm3,
+		Error:            cb.Error,
+		BookedBy:         bookedBy,
+		CreatedAt:        cb.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:        cb.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Metrics of the daily aggregates
+const (
+	DailyAggregateOrdersByStatus       = "orders_by_status"       // orders created per day, dimension is the processing status
+	DailyAggregateQCByUser             = "qc_by_user"             // QC processed per day and QC user, dimension is the lane
+	DailyAggregateOutboundByExpedition = "outbound_by_expedition" // outbound scans per day, dimension is the expedition slug
+)
+
+// Lanes of the QC daily aggregates
+const (
+	DailyAggregateLaneRibbon = "ribbon"
+	DailyAggregateLaneOnline = "online"
+)
+
+// DailyAggregate is a count precomputed per day by the aggregate job so dashboards do not scan the transactional tables
+type DailyAggregate struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Date        time.Time `gorm:"type:date;not null;uniqueIndex:idx_daily_aggregate_key" json:"date"`
+	Metric      string    `gorm:"not null;type:varchar(50);uniqueIndex:idx_daily_aggregate_key" json:"metric"`
+	Dimension   string    `gorm:"not null;default:'';type:varchar(100);uniqueIndex:idx_daily_aggregate_key" json:"dimension"`
+	UserID      uint      `gorm:"not null;default:0;uniqueIndex:idx_daily_aggregate_key" json:"user_id"` // 0 when the metric is not per user
+	Count       int64     `gorm:"not null;default:0" json:"count"`
+	RefreshedAt time.Time `gorm:"not null" json:"refreshed_at"`
+}
@@ -0,0 +1,162 @@
+package models
+
+import "time"
+
+// Data repair run statuses
+const (
+	DataRepairStatusRunning   = "running"
+	DataRepairStatusCompleted = "completed"
+	DataRepairStatusFailed    = "failed" // stopped by an error, the batches applied before it are kept and can be reverted
+	DataRepairStatusReverted  = "reverted"
+)
+
+// DataRepairRun is the audit entry of a data repair rule run. Dry runs only count the rows the rule would change.
+type DataRepairRun struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Rule         string     `gorm:"not null;type:varchar(50);index" json:"rule"`
+	DryRun       bool       `gorm:"default:false" json:"dry_run"`
+	Status       string     `gorm:"not null;type:varchar(20)" json:"status"`
+	BatchSize    int        `gorm:"not null" json:"batch_size"`
+	MaxRows      int        `gorm:"not null;default:0" json:"max_rows"` // rows changed at most by the run, 0 for every row
+	Batches      int        `gorm:"not null;default:0" json:"batches"`
+	AffectedRows int64      `gorm:"not null;default:0" json:"affected_rows"`
+	RevertedRows int64      `gorm:"not null;default:0" json:"reverted_rows"`
+	Error        string     `gorm:"type:text" json:"error"`
+	TriggeredBy  *uint      `gorm:"default:null" json:"triggered_by"`
+	RevertedBy   *uint      `gorm:"default:null" json:"reverted_by"`
+	RevertedAt   *time.Time `gorm:"default:null" json:"reverted_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Details     []DataRepairRunDetail `gorm:"foreignKey:DataRepairRunID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"details,omitempty"`
+	TriggerUser *User                 `gorm:"foreignKey:TriggeredBy" json:"trigger_user,omitempty"`
+	RevertUser  *User                 `gorm:"foreignKey:RevertedBy" json:"revert_user,omitempty"`
+}
+
+// DataRepairRunDetail records the rows of a single column changed by a data repair run
+type DataRepairRunDetail struct {
+	ID              uint   `gorm:"primaryKey" json:"id"`
+	DataRepairRunID uint   `gorm:"not null;index" json:"data_repair_run_id"`
+	Entity          string `gorm:"not null;type:varchar(50)" json:"entity"` // table of the column
+	Column          string `gorm:"not null;type:varchar(50)" json:"column"`
+	AffectedRows    int64  `gorm:"not null;default:0" json:"affected_rows"`
+	ConflictRows    int64  `gorm:"not null;default:0" json:"conflict_rows"` // left unchanged, the repaired value collides with another row
+
+	DataRepairRun *DataRepairRun `gorm:"foreignKey:DataRepairRunID" json:"-"`
+}
+
+// DataRepairChange is the backup of a value changed by a data repair run, restored when the run is reverted
+type DataRepairChange struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	DataRepairRunID uint       `gorm:"not null;index" json:"data_repair_run_id"`
+	Entity          string     `gorm:"not null;type:varchar(50)" json:"entity"`
+	Column          string     `gorm:"not null;type:varchar(50)" json:"column"`
+	RowID           uint       `gorm:"not null" json:"row_id"`
+	OldValue        *string    `gorm:"type:text" json:"old_value"`
+	NewValue        string     `gorm:"type:text" json:"new_value"`
+	RevertedAt      *time.Time `gorm:"default:null" json:"reverted_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+
+	DataRepairRun *DataRepairRun `gorm:"foreignKey:DataRepairRunID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+}
+
+// DataRepairRunResponse represents the data repair run data returned in API responses
+type DataRepairRunResponse struct {
+	ID           uint                          `json:"id"`
+	Rule         string                        `json:"rule"`
+	DryRun       bool                          `json:"dryRun"`
+	Status       string                        `json:"status"`
+	BatchSize    int                           `json:"batchSize"`
+	MaxRows      int                           `json:"maxRows"`
+	Batches      int                           `json:"batches"`
+	AffectedRows int64                         `json:"affectedRows"`
+	RevertedRows int64                         `json:"revertedRows"`
+	Error        string                        `json:"error,omitempty"`
+	TriggeredBy  *string                       `json:"triggeredBy"`
+	RevertedBy   *string                       `json:"revertedBy,omitempty"`
+	RevertedAt   *string                       `json:"revertedAt,omitempty"`
+	CreatedAt    string                        `json:"createdAt"`
+	Details      []DataRepairRunDetailResponse `json:"details"`
+}
+
+type DataRepairRunDetailResponse struct {
+	Entity       string `json:"entity"`
+	Column       string `json:"column"`
+	AffectedRows int64  `json:"affectedRows"`
+	ConflictRows int64  `json:"conflictRows"`
+}
+
+// ToResponse converts a DataRepairRun model to a DataRepairRunResponse
+func (drr *DataRepairRun) ToResponse() *DataRepairRunResponse {
+	// User visual handlers
+	var triggeredBy, revertedBy *string
+	if drr.TriggerUser != nil {
+		triggeredBy = &drr.TriggerUser.FullName
+	}
+	if drr.RevertUser != nil {
+		revertedBy = &drr.RevertUser.FullName
+	}
+
+	var revertedAt *string
+	if drr.RevertedAt != nil {
+		formatted := drr.RevertedAt.Format("02-01-2006 15:04:05")
+		revertedAt = &formatted
+	}
+
+	// Convert data repair run details
+	details := make([]DataRepairRunDetailResponse, len(drr.Details))
+	for i, detail := range drr.Details {
+		details[i] = DataRepairRunDetailResponse{
+			Entity:       detail.Entity,
+			Column:       detail.Column,
+			AffectedRows: detail.AffectedRows,
+			ConflictRows: detail.ConflictRows,
+		}
+	}
+
+	return &DataRepairRunResponse{
+		ID:           drr.ID,
+		Rule:         drr.Rule,
+		DryRun:       drr.DryRun,
+		Status:       drr.Status,
+		BatchSize:    drr.BatchSize,
+		MaxRows:      drr.MaxRows,
+		Batches:      drr.Batches,
+		AffectedRows: drr.AffectedRows,
+		RevertedRows: drr.RevertedRows,
+		Error:        drr.Error,
+		TriggeredBy:  triggeredBy,
+		RevertedBy:   revertedBy,
+		RevertedAt:   revertedAt,
+		CreatedAt:    drr.CreatedAt.Format("02-01-2006 15:04:05"),
+		Details:      details,
+	}
+}
+
+// DataRepairChangeResponse represents a backed up value of a data repair run returned in API responses
+type DataRepairChangeResponse struct {
+	Entity     string  `json:"entity"`
+	Column     string  `json:"column"`
+	RowID      uint    `json:"rowId"`
+	OldValue   *string `json:"oldValue"`
+	NewValue   string  `json:"newValue"`
+	RevertedAt *string `json:"revertedAt,omitempty"`
+}
+
+// ToResponse converts a DataRepairChange model to a DataRepairChangeResponse
+func (drc *DataRepairChange) ToResponse() *DataRepairChangeResponse {
+	var revertedAt *string
+	if drc.RevertedAt != nil {
+		formatted := drc.RevertedAt.Format("02-01-2006 15:04:05")
+		revertedAt = &formatted
+	}
+
+	return &DataRepairChangeResponse{
+		Entity:     drc.Entity,
+		Column:     drc.Column,
+		RowID:      drc.RowID,
+		OldValue:   drc.OldValue,
+		NewValue:   drc.NewValue,
+		RevertedAt: revertedAt,
+	}
+}
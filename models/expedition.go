@@ -1,37 +1,66 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Expedition struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	ExpeditionCode  string    `gorm:"uniqueIndex;not null;type:varchar(50)" json:"expedition_code"`
-	ExpeditionName  string    `gorm:"not null;type:varchar(100)" json:"expedition_name"`
-	ExpeditionSlug  string    `gorm:"index;not null;type:varchar(100)" json:"expedition_slug"`
-	ExpeditionColor string    `gorm:"not null;type:varchar(20)" json:"expedition_color"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ExpeditionCode   string    `gorm:"uniqueIndex;not null;type:varchar(50)" json:"expedition_code"`
+	ExpeditionName   string    `gorm:"not null;type:varchar(100)" json:"expedition_name"`
+	ExpeditionSlug   string    `gorm:"index;not null;type:varchar(100)" json:"expedition_slug"`
+	ExpeditionColor  string    `gorm:"not null;type:varchar(20)" json:"expedition_color"`
+	CutOffTime       string    `gorm:"type:varchar(5)" json:"cut_off_time"` // daily courier pickup cut-off, HH:MM
+	ServiceProvinces string    `gorm:"type:text" json:"service_provinces"`  // comma separated provinces the courier delivers to, empty delivers everywhere
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 // ExpeditionResponse represents the expedition data returned in API responses
 type ExpeditionResponse struct {
-	ID              uint   `json:"id"`
-	ExpeditionCode  string `json:"expeditionCode"`
-	ExpeditionName  string `json:"expeditionName"`
-	ExpeditionSlug  string `json:"expeditionSlug"`
-	ExpeditionColor string `json:"expeditionColor"`
-	CreatedAt       string `json:"createdAt"`
-	UpdatedAt       string `json:"updatedAt"`
+	ID               uint     `json:"id"`
+	ExpeditionCode   string   `json:"expeditionCode"`
+	ExpeditionName   string   `json:"expeditionName"`
+	ExpeditionSlug   string   `json:"expeditionSlug"`
+	ExpeditionColor  string   `json:"expeditionColor"`
+	CutOffTime       string   `json:"cutOffTime,omitempty"`
+	ServiceProvinces []string `json:"serviceProvinces,omitempty"`
+	CreatedAt        string   `json:"createdAt"`
+	UpdatedAt        string   `json:"updatedAt"`
+}
+
+// CutOffOn returns the cut-off time of the expedition on the given day, or false when no cut-off is set
+func (e *Expedition) CutOffOn(day time.Time) (time.Time, bool) {
+	cutOff, err := time.Parse("15:04", e.CutOffTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), cutOff.Hour(), cutOff.Minute(), 0, 0, day.Location()), true
+}
+
+// ServiceProvinceList returns the provinces the courier delivers to, none when it delivers everywhere
+func (e *Expedition) ServiceProvinceList() []string {
+	var provinces []string
+	for _, province := range strings.Split(e.ServiceProvinces, ",") {
+		if province = strings.TrimSpace(province); province != "" {
+			provinces = append(provinces, province)
+		}
+	}
+	return provinces
 }
 
 // ToResponse converts an Expedition model to an ExpeditionResponse
 func (e *Expedition) ToResponse() *ExpeditionResponse {
 	return &ExpeditionResponse{
-		ID:              e.ID,
-		ExpeditionCode:  e.ExpeditionCode,
-		ExpeditionName:  e.ExpeditionName,
-		ExpeditionSlug:  e.ExpeditionSlug,
-		ExpeditionColor: e.ExpeditionColor,
-		CreatedAt:       e.CreatedAt.Format("02-01-2006 15:04:05"),
-		UpdatedAt:       e.UpdatedAt.Format("02-01-2006 15:04:05"),
+		ID:               e.ID,
+		ExpeditionCode:   e.ExpeditionCode,
+		ExpeditionName:   e.ExpeditionName,
+		ExpeditionSlug:   e.ExpeditionSlug,
+		ExpeditionColor:  e.ExpeditionColor,
+		CutOffTime:       e.CutOffTime,
+		ServiceProvinces: e.ServiceProvinceList(),
+		CreatedAt:        e.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:        e.UpdatedAt.Format("02-01-2006 15:04:05"),
 	}
 }
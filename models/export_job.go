@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Export job statuses
+const (
+	ExportJobStatusPending   = "pending"
+	ExportJobStatusRunning   = "running"
+	ExportJobStatusCompleted = "completed"
+	ExportJobStatusFailed    = "failed"
+)
+
+// ExportJob is an export file generated in the background, downloaded by the requesting user once completed
+type ExportJob struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	ExportType  string     `gorm:"not null;type:varchar(50);index" json:"export_type"`
+	Filters     string     `gorm:"type:text" json:"filters"` // JSON object of the export parameters
+	Status      string     `gorm:"not null;type:varchar(20);index" json:"status"`
+	FileName    string     `gorm:"type:varchar(255)" json:"file_name"`
+	ContentType string     `gorm:"type:varchar(100)" json:"content_type"`
+	File        []byte     `gorm:"type:bytea" json:"-"`                  // generated file of jobs completed before the file storage, empty once StorageKey is set
+	StorageKey  string     `gorm:"type:varchar(255)" json:"storage_key"` // key of the generated file in the file storage
+	RowCount    int        `gorm:"default:0" json:"row_count"`
+	Error       string     `gorm:"type:text" json:"error"`
+	RequestedBy uint       `gorm:"not null;index" json:"requested_by"`
+	StartedAt   *time.Time `gorm:"default:null" json:"started_at"`
+	CompletedAt *time.Time `gorm:"default:null" json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	RequestUser *User `gorm:"foreignKey:RequestedBy" json:"request_user,omitempty"`
+}
+
+// FilterMap returns the export parameters
+func (ej *ExportJob) FilterMap() map[string]string {
+	filters := map[string]string{}
+	if ej.Filters != "" {
+		_ = json.Unmarshal([]byte(ej.Filters), &filters)
+	}
+	return filters
+}
+
+// ExportJobResponse represents the export job data returned in API responses
+type ExportJobResponse struct {
+	ID          uint              `json:"id"`
+	ExportType  string            `json:"exportType"`
+	Filters     map[string]string `json:"filters"`
+	Status      string            `json:"status"`
+	FileName    string            `json:"fileName,omitempty"`
+	RowCount    int               `json:"rowCount"`
+	Error       string            `json:"error,omitempty"`
+	RequestedBy string            `json:"requestedBy"`
+	StartedAt   *string           `json:"startedAt,omitempty"`
+	CompletedAt *string           `json:"completedAt,omitempty"`
+	CreatedAt   string            `json:"createdAt"`
+}
+
+// ToResponse converts an ExportJob model to an ExportJobResponse
+func (ej *ExportJob) ToResponse() *ExportJobResponse {
+	// User visual handler
+	var requestedBy string
+	if ej.RequestUser != nil {
+		requestedBy = ej.RequestUser.FullName
+	}
+
+	var startedAt, completedAt *string
+	if ej.StartedAt != nil {
+		formatted := ej.StartedAt.Format("02-01-2006 15:04:05")
+		startedAt = &formatted
+	}
+	if ej.CompletedAt != nil {
+		formatted := ej.CompletedAt.Format("02-01-2006 15:04:05")
+		completedAt = &formatted
+	}
+
+	return &ExportJobResponse{
+		ID:          ej.ID,
+		ExportType:  ej.ExportType,
+		Filters:     ej.FilterMap(),
+		Status:      ej.Status,
+		FileName:    ej.FileName,
+		RowCount:    ej.RowCount,
+		Error:       ej.Error,
+		RequestedBy: requestedBy,
+		StartedAt:   startedAt,
+		CompletedAt: completedAt,
+		CreatedAt:   ej.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Feature flags guarding subsystems that can be switched off per environment, role or rollout
+const (
+	FeatureFlagComplainAutoAssign         = "complain_auto_assign"         // round-robin assignment of new complains
+	FeatureFlagMarketplaceDisputeWebhooks = "marketplace_dispute_webhooks" // complains received from marketplace webhooks
+)
+
+// Feature flag audit actions
+const (
+	FeatureFlagAuditCreate = "create"
+	FeatureFlagAuditUpdate = "update"
+	FeatureFlagAuditDelete = "delete"
+)
+
+// FeatureFlag switches a subsystem on for some environments, roles or a percentage of users
+type FeatureFlag struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	FlagKey           string    `gorm:"uniqueIndex;not null;type:varchar(100)" json:"flag_key"`
+	Description       string    `gorm:"type:text" json:"description"`
+	Enabled           bool      `gorm:"not null;default:false" json:"enabled"`
+	Environments      string    `gorm:"type:text" json:"environments"`                  // comma separated ENV values the flag is on in, empty for every environment
+	Roles             string    `gorm:"type:text" json:"roles"`                         // comma separated roles the flag is on for, empty for every role
+	RolloutPercentage int       `gorm:"not null;default:100" json:"rollout_percentage"` // share of users the flag is on for, bucketed by user ID
+	UpdatedBy         *uint     `gorm:"default:null" json:"updated_by"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	UpdateUser *User `gorm:"foreignKey:UpdatedBy" json:"update_user,omitempty"`
+}
+
+// FeatureFlagResponse represents the feature flag data returned in API responses
+type FeatureFlagResponse struct {
+	ID                uint     `json:"id"`
+	FlagKey           string   `json:"flagKey"`
+	Description       string   `json:"description"`
+	Enabled           bool     `json:"enabled"`
+	Environments      []string `json:"environments"`
+	Roles             []string `json:"roles"`
+	RolloutPercentage int      `json:"rolloutPercentage"`
+	UpdatedBy         *string  `json:"updatedBy,omitempty"`
+	CreatedAt         string   `json:"createdAt"`
+	UpdatedAt         string   `json:"updatedAt"`
+}
+
+// EnvironmentList returns the environments the flag is on in, none when it is on in every environment
+func (ff *FeatureFlag) EnvironmentList() []string {
+	return splitFeatureFlagList(ff.Environments)
+}
+
+// RoleList returns the roles the flag is on for, none when it is on for every role
+func (ff *FeatureFlag) RoleList() []string {
+	return splitFeatureFlagList(ff.Roles)
+}
+
+func splitFeatureFlagList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Settings describes what the flag is switched on for, as recorded in the audit trail
+func (ff *FeatureFlag) Settings() string {
+	environments, roles := "all", "all"
+	if list := ff.EnvironmentList(); len(list) > 0 {
+		environments = strings.Join(list, ",")
+	}
+	if list := ff.RoleList(); len(list) > 0 {
+		roles = strings.Join(list, ",")
+	}
+	return fmt.Sprintf("enabled=%t environments=%s roles=%s rollout=%d%%", ff.Enabled, environments, roles, ff.RolloutPercentage)
+}
+
+// ToResponse converts a FeatureFlag model to a FeatureFlagResponse
+func (ff *FeatureFlag) ToResponse() *FeatureFlagResponse {
+	// User visual handler
+	var updatedBy *string
+	if ff.UpdateUser != nil {
+		updatedBy = &ff.UpdateUser.FullName
+	}
+
+	return &FeatureFlagResponse{
+		ID:                ff.ID,
+		FlagKey:           ff.FlagKey,
+		Description:       ff.Description,
+		Enabled:           ff.Enabled,
+		Environments:      ff.EnvironmentList(),
+		Roles:             ff.RoleList(),
+		RolloutPercentage: ff.RolloutPercentage,
+		UpdatedBy:         updatedBy,
+		CreatedAt:         ff.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:         ff.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// FeatureFlagAudit is the audit trail of feature flag changes
+type FeatureFlagAudit struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	FlagKey   string    `gorm:"not null;type:varchar(100);index" json:"flag_key"` // kept so the trail survives flag deletion
+	Action    string    `gorm:"not null;type:varchar(20)" json:"action"`
+	Before    string    `gorm:"type:text" json:"before"` // flag settings before the change, empty on create
+	After     string    `gorm:"type:text" json:"after"`  // flag settings after the change, empty on delete
+	ChangedBy uint      `gorm:"not null" json:"changed_by"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	ChangeUser *User `gorm:"foreignKey:ChangedBy" json:"change_user,omitempty"`
+}
+
+// FeatureFlagAuditResponse represents the feature flag audit data returned in API responses
+type FeatureFlagAuditResponse struct {
+	ID        uint   `json:"id"`
+	FlagKey   string `json:"flagKey"`
+	Action    string `json:"action"`
+	Before    string `json:"before,omitempty"`
+	After     string `json:"after,omitempty"`
+	ChangedBy string `json:"changedBy"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ToResponse converts a FeatureFlagAudit model to a FeatureFlagAuditResponse
+func (ffa *FeatureFlagAudit) ToResponse() *FeatureFlagAuditResponse {
+	// User visual handler
+	var changedBy string
+	if ffa.ChangeUser != nil {
+		changedBy = ffa.ChangeUser.FullName
+	}
+
+	return &FeatureFlagAuditResponse{
+		ID:        ffa.ID,
+		FlagKey:   ffa.FlagKey,
+		Action:    ffa.Action,
+		Before:    ffa.Before,
+		After:     ffa.After,
+		ChangedBy: changedBy,
+		CreatedAt: ffa.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,140 @@
+package models
+
+import "time"
+
+// HandoverSession groups outbounds handed over to a courier during a single pickup visit
+type HandoverSession struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	SessionCode     string     `gorm:"uniqueIndex;not null;type:varchar(50)" json:"session_code"`
+	Expedition      string     `gorm:"type:varchar(100)" json:"expedition"`
+	ExpeditionSlug  string     `gorm:"index;type:varchar(100)" json:"expedition_slug"`
+	ExpeditionColor string     `gorm:"type:varchar(50)" json:"expedition_color"`
+	Status          string     `gorm:"default:'open';index;type:varchar(20)" json:"status"` // open or closed
+	OpenedBy        uint       `gorm:"not null" json:"opened_by"`
+	ClosedBy        *uint      `json:"closed_by"`
+	DriverName      string     `gorm:"type:varchar(100)" json:"driver_name"`
+	DriverPhoto     string     `gorm:"type:text" json:"driver_photo"`
+	DriverSignature string     `gorm:"type:text" json:"driver_signature"`
+	Notes           string     `gorm:"type:text" json:"notes"`
+	ClosedAt        *time.Time `json:"closed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	Items     []HandoverSessionItem `gorm:"foreignKey:HandoverSessionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items,omitempty"`
+	OpenUser  *User                 `gorm:"foreignKey:OpenedBy" json:"open_user,omitempty"`
+	CloseUser *User                 `gorm:"foreignKey:ClosedBy" json:"close_user,omitempty"`
+}
+
+// HandoverSessionItem is a single outbound scanned into a handover session
+type HandoverSessionItem struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	HandoverSessionID uint      `gorm:"not null;index" json:"handover_session_id"`
+	OutboundID        uint      `gorm:"uniqueIndex;not null" json:"outbound_id"`
+	TrackingNumber    string    `gorm:"not null;type:varchar(100)" json:"tracking_number"`
+	ScannedBy         uint      `gorm:"not null" json:"scanned_by"`
+	CreatedAt         time.Time `json:"created_at"`
+
+	Outbound        *Outbound        `gorm:"foreignKey:OutboundID" json:"outbound,omitempty"`
+	ScanUser        *User            `gorm:"foreignKey:ScannedBy" json:"scan_user,omitempty"`
+	HandoverSession *HandoverSession `gorm:"foreignKey:HandoverSessionID" json:"-"`
+}
+
+// HandoverSessionResponse represents the handover session data returned in API responses
+type HandoverSessionResponse struct {
+	ID              uint                          `json:"id"`
+	SessionCode     string                        `json:"sessionCode"`
+	Expedition      string                        `json:"expedition"`
+	ExpeditionSlug  string                        `json:"expeditionSlug"`
+	ExpeditionColor string                        `json:"expeditionColor"`
+	Status          string                        `json:"status"`
+	OpenedBy        string                        `json:"openedBy"`
+	ClosedBy        *string                       `json:"closedBy"`
+	DriverName      string                        `json:"driverName"`
+	DriverPhoto     string                        `json:"driverPhoto,omitempty"`
+	DriverSignature string                        `json:"driverSignature,omitempty"`
+	Notes           string                        `json:"notes"`
+	TotalParcels    int                           `json:"totalParcels"`
+	ClosedAt        *string                       `json:"closedAt"`
+	CreatedAt       string                        `json:"createdAt"`
+	UpdatedAt       string                        `json:"updatedAt"`
+	Items           []HandoverSessionItemResponse `json:"items,omitempty"`
+}
+
+// HandoverSessionItemResponse represents a handover session item returned in API responses
+type HandoverSessionItemResponse struct {
+	ID             uint   `json:"id"`
+	OutboundID     uint   `json:"outboundId"`
+	TrackingNumber string `json:"trackingNumber"`
+	Expedition     string `json:"expedition"`
+	ScannedBy      string `json:"scannedBy"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// ToResponse converts a HandoverSession model to a HandoverSessionResponse
+func (hs *HandoverSession) ToResponse() *HandoverSessionResponse {
+	// User visual handlers
+	var openedBy string
+	if hs.OpenUser != nil {
+		openedBy = hs.OpenUser.FullName
+	}
+
+	var closedBy *string
+	if hs.CloseUser != nil {
+		closedBy = &hs.CloseUser.FullName
+	}
+
+	var closedAt *string
+	if hs.ClosedAt != nil {
+		formatted := hs.ClosedAt.Format("02-01-2006 15:04:05")
+		closedAt = &formatted
+	}
+
+	// Convert handover session items
+	items := make([]HandoverSessionItemResponse, len(hs.Items))
+	for i, item := range hs.Items {
+		items[i] = *item.ToResponse()
+	}
+
+	return &HandoverSessionResponse{
+		ID:              hs.ID,
+		SessionCode:     hs.SessionCode,
+		Expedition:      hs.Expedition,
+		ExpeditionSlug:  hs.ExpeditionSlug,
+		ExpeditionColor: hs.ExpeditionColor,
+		Status:          hs.Status,
+		OpenedBy:        openedBy,
+		ClosedBy:        closedBy,
+		DriverName:      hs.DriverName,
+		DriverPhoto:     hs.DriverPhoto,
+		DriverSignature: hs.DriverSignature,
+		Notes:           hs.Notes,
+		TotalParcels:    len(hs.Items),
+		ClosedAt:        closedAt,
+		CreatedAt:       hs.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:       hs.UpdatedAt.Format("02-01-2006 15:04:05"),
+		Items:           items,
+	}
+}
+
+// ToResponse converts a HandoverSessionItem model to a HandoverSessionItemResponse
+func (hsi *HandoverSessionItem) ToResponse() *HandoverSessionItemResponse {
+	// User visual handlers
+	var scannedBy string
+	if hsi.ScanUser != nil {
+		scannedBy = hsi.ScanUser.FullName
+	}
+
+	var expedition string
+	if hsi.Outbound != nil {
+		expedition = hsi.Outbound.Expedition
+	}
+
+	return &HandoverSessionItemResponse{
+		ID:             hsi.ID,
+		OutboundID:     hsi.OutboundID,
+		TrackingNumber: hsi.TrackingNumber,
+		Expedition:     expedition,
+		ScannedBy:      scannedBy,
+		CreatedAt:      hsi.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
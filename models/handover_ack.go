@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// Handover acknowledgment item statuses
+const (
+	HandoverAckItemMatched      = "matched"       // acknowledged and scanned into the acknowledged session
+	HandoverAckItemOtherSession = "other_session" // acknowledged but scanned into a different handover session
+	HandoverAckItemNotScanned   = "not_scanned"   // acknowledged outbound that was never scanned into a handover session
+	HandoverAckItemUnknown      = "unknown"       // acknowledged tracking number without an outbound
+	HandoverAckItemMissing      = "missing"       // scanned into the session but not acknowledged by the 3PL
+)
+
+// HandoverAck records a 3PL's electronic confirmation of the parcels they received in a pickup manifest
+type HandoverAck struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	ManifestReference string    `gorm:"not null;type:varchar(100);uniqueIndex:idx_handover_ack_manifest" json:"manifest_reference"`
+	APIKeyID          uint      `gorm:"not null;uniqueIndex:idx_handover_ack_manifest" json:"api_key_id"` // manifest references are unique per 3PL key
+	HandoverSessionID *uint     `gorm:"default:null;index" json:"handover_session_id"`
+	ReceivedCount     int       `gorm:"default:0" json:"received_count"`
+	MatchedCount      int       `gorm:"default:0" json:"matched_count"`
+	DiscrepancyCount  int       `gorm:"default:0;index" json:"discrepancy_count"`
+	CreatedAt         time.Time `json:"created_at"`
+
+	Items           []HandoverAckItem `gorm:"foreignKey:HandoverAckID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items,omitempty"`
+	APIKey          *APIKey           `gorm:"foreignKey:APIKeyID" json:"api_key,omitempty"`
+	HandoverSession *HandoverSession  `gorm:"foreignKey:HandoverSessionID" json:"handover_session,omitempty"`
+}
+
+// HandoverAckItem is a single tracking number of an acknowledgment, or a session parcel the 3PL did not acknowledge
+type HandoverAckItem struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	HandoverAckID  uint   `gorm:"not null;index" json:"handover_ack_id"`
+	TrackingNumber string `gorm:"not null;type:varchar(100);index" json:"tracking_number"`
+	OutboundID     *uint  `gorm:"default:null" json:"outbound_id"`
+	Status         string `gorm:"not null;type:varchar(20);index" json:"status"`
+}
+
+type HandoverAckResponse struct {
+	ID                uint                      `json:"id"`
+	ManifestReference string                    `json:"manifestReference"`
+	APIKey            string                    `json:"apiKey"`
+	SessionCode       *string                   `json:"sessionCode"`
+	ReceivedCount     int                       `json:"receivedCount"`
+	MatchedCount      int                       `json:"matchedCount"`
+	DiscrepancyCount  int                       `json:"discrepancyCount"`
+	CreatedAt         string                    `json:"createdAt"`
+	Items             []HandoverAckItemResponse `json:"items,omitempty"`
+}
+
+type HandoverAckItemResponse struct {
+	TrackingNumber string `json:"trackingNumber"`
+	OutboundID     *uint  `json:"outboundId,omitempty"`
+	Status         string `json:"status"`
+}
+
+// ToResponse converts a HandoverAck model to a HandoverAckResponse
+func (ha *HandoverAck) ToResponse() *HandoverAckResponse {
+	var apiKey string
+	if ha.APIKey != nil {
+		apiKey = ha.APIKey.Name
+	}
+
+	var sessionCode *string
+	if ha.HandoverSession != nil {
+		sessionCode = &ha.HandoverSession.SessionCode
+	}
+
+	items := make([]HandoverAckItemResponse, len(ha.Items))
+	for i, item := range ha.Items {
+		items[i] = HandoverAckItemResponse{
+			TrackingNumber: item.TrackingNumber,
+			OutboundID:     item.OutboundID,
+			Status:         item.Status,
+		}
+	}
+
+	return &HandoverAckResponse{
+		ID:                ha.ID,
+		ManifestReference: ha.ManifestReference,
+		APIKey:            apiKey,
+		SessionCode:       sessionCode,
+		ReceivedCount:     ha.ReceivedCount,
+		MatchedCount:      ha.MatchedCount,
+		DiscrepancyCount:  ha.DiscrepancyCount,
+		CreatedAt:         ha.CreatedAt.Format("02-01-2006 15:04:05"),
+		Items:             items,
+	}
+}
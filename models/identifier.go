@@ -0,0 +1,145 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// NormalizeIdentifier returns the canonical form tracking numbers and Ginee order IDs are stored and looked up in:
+// trimmed and uppercased, so scans, imports and typed input match whatever case they were entered in
+func NormalizeIdentifier(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
+// NormalizeIdentifiers normalizes every identifier of the list, see NormalizeIdentifier
+func NormalizeIdentifiers(values []string) []string {
+	normalized := make([]string, len(values))
+	for i, value := range values {
+		normalized[i] = NormalizeIdentifier(value)
+	}
+	return normalized
+}
+
+// identifierColumn pairs an identifier column with its model field, nil when the model field is an unset pointer
+type identifierColumn struct {
+	Column string
+	Value  *string
+}
+
+// normalizeIdentifierColumns normalizes identifiers written through a model (Create, Save)
+// or through column updates (Update, Updates with a map)
+func normalizeIdentifierColumns(tx *gorm.DB, columns ...identifierColumn) {
+	updates, _ := tx.Statement.Dest.(map[string]interface{})
+	for _, column := range columns {
+		if column.Value != nil {
+			*column.Value = NormalizeIdentifier(*column.Value)
+		}
+		if updates == nil {
+			continue
+		}
+		switch value := updates[column.Column].(type) {
+		case string:
+			updates[column.Column] = NormalizeIdentifier(value)
+		case *string:
+			if value != nil {
+				normalized := NormalizeIdentifier(*value)
+				updates[column.Column] = &normalized
+			}
+		}
+	}
+}
+
+// BeforeSave normalizes the complain identifiers
+func (c *Complain) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx,
+		identifierColumn{Column: "tracking_number", Value: &c.TrackingNumber},
+		identifierColumn{Column: "order_ginee_id", Value: &c.OrderGineeID},
+	)
+	return nil
+}
+
+// BeforeSave normalizes the settled tracking number
+func (csl *ComplainSettlementLine) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &csl.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the outbound tracking number
+func (o *Outbound) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &o.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the handed over tracking number
+func (hsi *HandoverSessionItem) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &hsi.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the acknowledged tracking number
+func (hai *HandoverAckItem) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &hai.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the overridden tracking number
+func (oeo *OrderEditOverride) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &oeo.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the tracking number of the anomaly
+func (pa *PickAnomaly) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &pa.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the tracking number of the shortage
+func (ps *PickShortage) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &ps.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the tracking number of the mismatch
+func (qcm *QCMismatch) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &qcm.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the photographed tracking number
+func (qpp *QCParcelPhoto) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &qpp.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the voided tracking number
+func (qv *QCVoid) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &qv.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the return identifiers
+func (r *Return) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx,
+		identifierColumn{Column: "new_tracking_number", Value: &r.NewTrackingNumber},
+		identifierColumn{Column: "tracking_number", Value: r.TrackingNumber},
+		identifierColumn{Column: "order_ginee_id", Value: r.OrderGineeID},
+	)
+	return nil
+}
+
+// BeforeSave normalizes the unknown tracking number
+func (ur *UnknownReturn) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &ur.TrackingNumber})
+	return nil
+}
+
+// BeforeSave normalizes the identifiers of the breached order
+func (sb *SLABreach) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx,
+		identifierColumn{Column: "tracking_number", Value: &sb.TrackingNumber},
+		identifierColumn{Column: "order_ginee_id", Value: &sb.OrderGineeID},
+	)
+	return nil
+}
@@ -0,0 +1,210 @@
+package models
+
+import "time"
+
+// InboundShipment is a supplier delivery received against a purchase order or manifest
+type InboundShipment struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	Code            string     `gorm:"uniqueIndex;not null;type:varchar(50)" json:"code"`
+	Supplier        string     `gorm:"not null;type:varchar(255)" json:"supplier"`
+	ReferenceNumber string     `gorm:"type:varchar(100);index" json:"reference_number"`            // PO or manifest number
+	ExpectedAt      *time.Time `gorm:"default:null" json:"expected_at"`                            // expected arrival
+	Status          string     `gorm:"not null;type:varchar(20);default:registered" json:"status"` // registered, receiving, completed, canceled
+	Notes           string     `gorm:"type:text" json:"notes"`
+	CreatedBy       uint       `gorm:"not null" json:"created_by"`
+	CompletedBy     *uint      `gorm:"default:null" json:"completed_by"`
+	CompletedAt     *time.Time `gorm:"default:null" json:"completed_at"`
+	CanceledBy      *uint      `gorm:"default:null" json:"canceled_by"`
+	CanceledAt      *time.Time `gorm:"default:null" json:"canceled_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+
+	Details      []InboundDetail `gorm:"foreignKey:InboundShipmentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"details,omitempty"`
+	CreateUser   *User           `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+	CompleteUser *User           `gorm:"foreignKey:CompletedBy" json:"complete_user,omitempty"`
+	CancelUser   *User           `gorm:"foreignKey:CanceledBy" json:"cancel_user,omitempty"`
+}
+
+// InboundDetail is a single SKU line of an inbound shipment
+type InboundDetail struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	InboundShipmentID uint       `gorm:"not null;uniqueIndex:idx_inbound_detail_sku" json:"inbound_shipment_id"`
+	SKU               string     `gorm:"not null;type:varchar(255);uniqueIndex:idx_inbound_detail_sku" json:"sku"`
+	ProductName       string     `gorm:"type:varchar(255)" json:"product_name"`
+	LotNumber         string     `gorm:"type:varchar(100)" json:"lot_number"`
+	ExpiryDate        *time.Time `gorm:"type:date" json:"expiry_date"`
+	ExpectedQuantity  int        `gorm:"not null;default:0" json:"expected_quantity"`
+	ReceivedQuantity  *int       `gorm:"default:null" json:"received_quantity"`
+	DamagedQuantity   int        `gorm:"not null;default:0" json:"damaged_quantity"`
+	Unexpected        bool       `gorm:"default:false" json:"unexpected"` // delivered but not on the PO/manifest
+	DiscrepancyNote   string     `gorm:"type:text" json:"discrepancy_note"`
+	ReceivedBy        *uint      `gorm:"default:null" json:"received_by"`
+	ReceivedAt        *time.Time `gorm:"default:null" json:"received_at"`
+
+	ReceiveUser *User `gorm:"foreignKey:ReceivedBy" json:"receive_user,omitempty"`
+}
+
+// AcceptedQuantity returns the received quantity in good condition that goes into stock
+func (d *InboundDetail) AcceptedQuantity() int {
+	if d.ReceivedQuantity == nil {
+		return 0
+	}
+	accepted := *d.ReceivedQuantity - d.DamagedQuantity
+	if accepted < 0 {
+		return 0
+	}
+	return accepted
+}
+
+// Discrepancy returns received minus expected quantity, or 0 when the line has not been received
+func (d *InboundDetail) Discrepancy() int {
+	if d.ReceivedQuantity == nil {
+		return 0
+	}
+	return *d.ReceivedQuantity - d.ExpectedQuantity
+}
+
+type InboundShipmentResponse struct {
+	ID              uint                    `json:"id"`
+	Code            string                  `json:"code"`
+	Supplier        string                  `json:"supplier"`
+	ReferenceNumber string                  `json:"referenceNumber"`
+	ExpectedAt      *string                 `json:"expectedAt,omitempty"`
+	Status          string                  `json:"status"`
+	Notes           string                  `json:"notes"`
+	Summary         InboundSummary          `json:"summary"`
+	Details         []InboundDetailResponse `json:"details,omitempty"`
+	CreatedBy       string                  `json:"createdBy"`
+	CompletedBy     *string                 `json:"completedBy,omitempty"`
+	CompletedAt     *string                 `json:"completedAt,omitempty"`
+	CanceledBy      *string                 `json:"canceledBy,omitempty"`
+	CanceledAt      *string                 `json:"canceledAt,omitempty"`
+	CreatedAt       string                  `json:"createdAt"`
+	UpdatedAt       string                  `json:"updatedAt"`
+}
+
+type InboundSummary struct {
+	TotalLines       int `json:"totalLines"`
+	ReceivedLines    int `json:"receivedLines"`
+	DiscrepancyLines int `json:"discrepancyLines"`
+	ExpectedQuantity int `json:"expectedQuantity"`
+	ReceivedQuantity int `json:"receivedQuantity"`
+	DamagedQuantity  int `json:"damagedQuantity"`
+}
+
+type InboundDetailResponse struct {
+	ID               uint    `json:"id"`
+	SKU              string  `json:"sku"`
+	ProductName      string  `json:"productName"`
+	LotNumber        string  `json:"lotNumber,omitempty"`
+	ExpiryDate       *string `json:"expiryDate,omitempty"`
+	ExpectedQuantity int     `json:"expectedQuantity"`
+	ReceivedQuantity *int    `json:"receivedQuantity"`
+	DamagedQuantity  int     `json:"damagedQuantity"`
+	Discrepancy      int     `json:"discrepancy"`
+	Unexpected       bool    `json:"unexpected"`
+	DiscrepancyNote  string  `json:"discrepancyNote,omitempty"`
+	ReceivedBy       *string `json:"receivedBy,omitempty"`
+	ReceivedAt       *string `json:"receivedAt,omitempty"`
+}
+
+// ToResponse converts an InboundDetail model to an InboundDetailResponse
+func (d *InboundDetail) ToResponse() InboundDetailResponse {
+	// User visual handlers
+	var receivedBy *string
+	if d.ReceiveUser != nil {
+		receivedBy = &d.ReceiveUser.FullName
+	}
+
+	var receivedAt, expiryDate *string
+	if d.ReceivedAt != nil {
+		formatted := d.ReceivedAt.Format("02-01-2006 15:04:05")
+		receivedAt = &formatted
+	}
+	if d.ExpiryDate != nil {
+		formatted := d.ExpiryDate.Format("02-01-2006")
+		expiryDate = &formatted
+	}
+
+	return InboundDetailResponse{
+		ID:               d.ID,
+		SKU:              d.SKU,
+		ProductName:      d.ProductName,
+		LotNumber:        d.LotNumber,
+		ExpiryDate:       expiryDate,
+		ExpectedQuantity: d.ExpectedQuantity,
+		ReceivedQuantity: d.ReceivedQuantity,
+		DamagedQuantity:  d.DamagedQuantity,
+		Discrepancy:      d.Discrepancy(),
+		Unexpected:       d.Unexpected,
+		DiscrepancyNote:  d.DiscrepancyNote,
+		ReceivedBy:       receivedBy,
+		ReceivedAt:       receivedAt,
+	}
+}
+
+// ToResponse converts an InboundShipment model to an InboundShipmentResponse
+func (s *InboundShipment) ToResponse() *InboundShipmentResponse {
+	var summary InboundSummary
+	details := make([]InboundDetailResponse, len(s.Details))
+	for i := range s.Details {
+		details[i] = s.Details[i].ToResponse()
+
+		summary.TotalLines++
+		summary.ExpectedQuantity += s.Details[i].ExpectedQuantity
+		summary.DamagedQuantity += s.Details[i].DamagedQuantity
+		if s.Details[i].ReceivedQuantity != nil {
+			summary.ReceivedLines++
+			summary.ReceivedQuantity += *s.Details[i].ReceivedQuantity
+		}
+		if s.Details[i].Discrepancy() != 0 || s.Details[i].DamagedQuantity > 0 {
+			summary.DiscrepancyLines++
+		}
+	}
+
+	// User visual handlers
+	var createdBy string
+	if s.CreateUser != nil {
+		createdBy = s.CreateUser.FullName
+	}
+	var completedBy, canceledBy *string
+	if s.CompleteUser != nil {
+		completedBy = &s.CompleteUser.FullName
+	}
+	if s.CancelUser != nil {
+		canceledBy = &s.CancelUser.FullName
+	}
+
+	var expectedAt, completedAt, canceledAt *string
+	if s.ExpectedAt != nil {
+		formatted := s.ExpectedAt.Format("02-01-2006 15:04:05")
+		expectedAt = &formatted
+	}
+	if s.CompletedAt != nil {
+		formatted := s.CompletedAt.Format("02-01-2006 15:04:05")
+		completedAt = &formatted
+	}
+	if s.CanceledAt != nil {
+		formatted := s.CanceledAt.Format("02-01-2006 15:04:05")
+		canceledAt = &formatted
+	}
+
+	return &InboundShipmentResponse{
+		ID:              s.ID,
+		Code:            s.Code,
+		Supplier:        s.Supplier,
+		ReferenceNumber: s.ReferenceNumber,
+		ExpectedAt:      expectedAt,
+		Status:          s.Status,
+		Notes:           s.Notes,
+		Summary:         summary,
+		Details:         details,
+		CreatedBy:       createdBy,
+		CompletedBy:     completedBy,
+		CompletedAt:     completedAt,
+		CanceledBy:      canceledBy,
+		CanceledAt:      canceledAt,
+		CreatedAt:       s.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:       s.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// Inventory holds the system stock of a SKU
+type Inventory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SKU       string    `gorm:"uniqueIndex;not null;type:varchar(255)" json:"sku"`
+	Quantity  int       `gorm:"not null;default:0" json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Product *Product `gorm:"-" json:"product,omitempty"`
+}
+
+// InventoryMovement is the audit ledger of every change to system stock
+type InventoryMovement struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	SKU           string    `gorm:"not null;type:varchar(255);index" json:"sku"`
+	Quantity      int       `gorm:"not null" json:"quantity"` // signed change
+	BalanceBefore int       `gorm:"not null" json:"balance_before"`
+	BalanceAfter  int       `gorm:"not null" json:"balance_after"`
+	Type          string    `gorm:"not null;type:varchar(50);index" json:"type"` // stocktake_adjustment, receipt, ...
+	LotNumber     string    `gorm:"type:varchar(100)" json:"lot_number"`
+	ReferenceType string    `gorm:"type:varchar(50)" json:"reference_type"`
+	ReferenceID   uint      `gorm:"default:0" json:"reference_id"`
+	Note          string    `gorm:"type:text" json:"note"`
+	CreatedBy     *uint     `gorm:"default:null" json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+
+	CreateUser *User `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+}
+
+// InventoryResponse represents the inventory data returned in API responses
+type InventoryResponse struct {
+	ID          uint   `json:"id"`
+	SKU         string `json:"sku"`
+	ProductName string `json:"productName,omitempty"`
+	Variant     string `json:"variant,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Quantity    int    `json:"quantity"`
+	UpdatedAt   string `json:"updatedAt"`
+}
+
+// ToResponse converts an Inventory model to an InventoryResponse
+func (i *Inventory) ToResponse() *InventoryResponse {
+	resp := &InventoryResponse{
+		ID:        i.ID,
+		SKU:       i.SKU,
+		Quantity:  i.Quantity,
+		UpdatedAt: i.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if i.Product != nil {
+		resp.ProductName = i.Product.Name
+		resp.Variant = i.Product.Variant
+		resp.Location = i.Product.Location
+	}
+	return resp
+}
+
+type InventoryMovementResponse struct {
+	ID            uint   `json:"id"`
+	SKU           string `json:"sku"`
+	Quantity      int    `json:"quantity"`
+	BalanceBefore int    `json:"balanceBefore"`
+	BalanceAfter  int    `json:"balanceAfter"`
+	Type          string `json:"type"`
+	LotNumber     string `json:"lotNumber,omitempty"`
+	ReferenceType string `json:"referenceType,omitempty"`
+	ReferenceID   uint   `json:"referenceId,omitempty"`
+	Note          string `json:"note,omitempty"`
+	CreatedBy     string `json:"createdBy,omitempty"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+// ToResponse converts an InventoryMovement model to an InventoryMovementResponse
+func (m *InventoryMovement) ToResponse() *InventoryMovementResponse {
+	// User visual handlers
+	var createdBy string
+	if m.CreateUser != nil {
+		createdBy = m.CreateUser.FullName
+	}
+
+	return &InventoryMovementResponse{
+		ID:            m.ID,
+		SKU:           m.SKU,
+		Quantity:      m.Quantity,
+		BalanceBefore: m.BalanceBefore,
+		BalanceAfter:  m.BalanceAfter,
+		Type:          m.Type,
+		LotNumber:     m.LotNumber,
+		ReferenceType: m.ReferenceType,
+		ReferenceID:   m.ReferenceID,
+		Note:          m.Note,
+		CreatedBy:     createdBy,
+		CreatedAt:     m.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
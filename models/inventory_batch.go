@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// DefaultNearExpiryDays is the window in which a batch is flagged as near expiry
+const DefaultNearExpiryDays = 30
+
+// InventoryBatch is a received lot of a SKU, tracked for expiry and FEFO picking
+type InventoryBatch struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	SKU              string     `gorm:"not null;type:varchar(255);uniqueIndex:idx_inventory_batch_lot" json:"sku"`
+	LotNumber        string     `gorm:"not null;type:varchar(100);uniqueIndex:idx_inventory_batch_lot" json:"lot_number"`
+	ExpiryDate       *time.Time `gorm:"type:date;index" json:"expiry_date"`
+	ReceivedQuantity int        `gorm:"not null;default:0" json:"received_quantity"`
+	Quantity         int        `gorm:"not null;default:0" json:"quantity"` // remaining on hand
+	ReceivedBy       *uint      `gorm:"default:null" json:"received_by"`
+	ReceivedAt       time.Time  `gorm:"not null" json:"received_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	ReceiveUser *User    `gorm:"foreignKey:ReceivedBy" json:"receive_user,omitempty"`
+	Product     *Product `gorm:"-" json:"product,omitempty"`
+}
+
+// BatchPick is a FEFO suggestion to take a quantity from a batch
+type BatchPick struct {
+	Batch    InventoryBatch
+	Quantity int
+}
+
+// DaysToExpiry returns the whole days left until the batch expires, nil when it has no expiry date
+func (b *InventoryBatch) DaysToExpiry(now time.Time) *int {
+	if b.ExpiryDate == nil {
+		return nil
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	expiry := time.Date(b.ExpiryDate.Year(), b.ExpiryDate.Month(), b.ExpiryDate.Day(), 0, 0, 0, 0, now.Location())
+	days := int(expiry.Sub(today).Hours() / 24)
+	return &days
+}
+
+// ExpiryStatus classifies the batch as expired, near_expiry (within nearDays) or ok
+func (b *InventoryBatch) ExpiryStatus(now time.Time, nearDays int) string {
+	days := b.DaysToExpiry(now)
+	switch {
+	case days == nil:
+		return "ok"
+	case *days < 0:
+		return "expired"
+	case *days <= nearDays:
+		return "near_expiry"
+	default:
+		return "ok"
+	}
+}
+
+// InventoryBatchResponse represents the batch data returned in API responses
+type InventoryBatchResponse struct {
+	ID               uint    `json:"id"`
+	SKU              string  `json:"sku"`
+	ProductName      string  `json:"productName,omitempty"`
+	Location         string  `json:"location,omitempty"`
+	LotNumber        string  `json:"lotNumber"`
+	ExpiryDate       *string `json:"expiryDate,omitempty"`
+	DaysToExpiry     *int    `json:"daysToExpiry,omitempty"`
+	ExpiryStatus     string  `json:"expiryStatus"`
+	ReceivedQuantity int     `json:"receivedQuantity"`
+	Quantity         int     `json:"quantity"`
+	ReceivedBy       string  `json:"receivedBy,omitempty"`
+	ReceivedAt       string  `json:"receivedAt"`
+}
+
+// ToResponse converts an InventoryBatch model to an InventoryBatchResponse
+func (b *InventoryBatch) ToResponse() *InventoryBatchResponse {
+	now := time.Now()
+	resp := &InventoryBatchResponse{
+		ID:               b.ID,
+		SKU:              b.SKU,
+		LotNumber:        b.LotNumber,
+		DaysToExpiry:     b.DaysToExpiry(now),
+		ExpiryStatus:     b.ExpiryStatus(now, DefaultNearExpiryDays),
+		ReceivedQuantity: b.ReceivedQuantity,
+		Quantity:         b.Quantity,
+		ReceivedAt:       b.ReceivedAt.Format("02-01-2006 15:04:05"),
+	}
+	if b.ExpiryDate != nil {
+		formatted := b.ExpiryDate.Format("02-01-2006")
+		resp.ExpiryDate = &formatted
+	}
+
+	// User visual handlers
+	if b.ReceiveUser != nil {
+		resp.ReceivedBy = b.ReceiveUser.FullName
+	}
+
+	if b.Product != nil {
+		resp.ProductName = b.Product.Name
+		resp.Location = b.Product.Location
+	}
+	return resp
+}
+
+// BatchPickResponse represents a FEFO pick suggestion in the picking list
+type BatchPickResponse struct {
+	LotNumber    string  `json:"lotNumber"`
+	ExpiryDate   *string `json:"expiryDate,omitempty"`
+	DaysToExpiry *int    `json:"daysToExpiry,omitempty"`
+	ExpiryStatus string  `json:"expiryStatus"`
+	Quantity     int     `json:"quantity"`
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entities imported from the spreadsheets of the system used before go-live
+const (
+	LegacyEntityOrders      = "orders"
+	LegacyEntityComplains   = "complains"
+	LegacyEntityAttendances = "attendances"
+)
+
+var legacyEntities = []string{LegacyEntityOrders, LegacyEntityComplains, LegacyEntityAttendances}
+
+// LegacyEntities returns the entities that can be imported from legacy spreadsheets
+func LegacyEntities() []string {
+	return legacyEntities
+}
+
+// IsValidLegacyEntity reports whether entity can be imported from legacy spreadsheets
+func IsValidLegacyEntity(entity string) bool {
+	for _, known := range legacyEntities {
+		if known == entity {
+			return true
+		}
+	}
+	return false
+}
+
+// Legacy import run statuses
+const (
+	LegacyImportStatusValidated = "validated" // dry run, nothing was imported
+	LegacyImportStatusCompleted = "completed" // every valid row was imported, invalid rows are in the report
+	LegacyImportStatusFailed    = "failed"    // stopped by an error, rows imported before it stay imported
+)
+
+// Where a legacy import run was started from
+const (
+	LegacyImportSourceAPI = "api"
+	LegacyImportSourceCLI = "cli"
+)
+
+// LegacyImportTemplate maps the columns of a legacy spreadsheet to the import fields of an entity
+type LegacyImportTemplate struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `gorm:"uniqueIndex;not null;type:varchar(100)" json:"name"`
+	Entity     string    `gorm:"not null;type:varchar(20);index" json:"entity"`
+	Mapping    string    `gorm:"not null;type:text" json:"mapping"`   // JSON object of import field -> spreadsheet header, unmapped fields use their own name
+	DateLayout string    `gorm:"type:varchar(50)" json:"date_layout"` // Go time layout of the date columns, the common layouts are tried when empty
+	UpdatedBy  *uint     `gorm:"default:null" json:"updated_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	UpdateUser *User `gorm:"foreignKey:UpdatedBy" json:"update_user,omitempty"`
+}
+
+// MappingColumns returns the spreadsheet header mapped to each import field
+func (t *LegacyImportTemplate) MappingColumns() map[string]string {
+	mapping := map[string]string{}
+	if t.Mapping != "" {
+		_ = json.Unmarshal([]byte(t.Mapping), &mapping)
+	}
+	return mapping
+}
+
+// LegacyImportTemplateResponse represents the legacy import template data returned in API responses
+type LegacyImportTemplateResponse struct {
+	ID         uint              `json:"id"`
+	Name       string            `json:"name"`
+	Entity     string            `json:"entity"`
+	Mapping    map[string]string `json:"mapping"`
+	DateLayout string            `json:"dateLayout"`
+	UpdatedBy  *string           `json:"updatedBy"`
+	CreatedAt  string            `json:"createdAt"`
+	UpdatedAt  string            `json:"updatedAt"`
+}
+
+// ToResponse converts a LegacyImportTemplate model to a LegacyImportTemplateResponse
+func (t *LegacyImportTemplate) ToResponse() *LegacyImportTemplateResponse {
+	// User visual handler
+	var updatedBy *string
+	if t.UpdateUser != nil {
+		updatedBy = &t.UpdateUser.FullName
+	}
+
+	return &LegacyImportTemplateResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Entity:     t.Entity,
+		Mapping:    t.MappingColumns(),
+		DateLayout: t.DateLayout,
+		UpdatedBy:  updatedBy,
+		CreatedAt:  t.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:  t.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// LegacyImportRun is the audit entry and validation report of a legacy spreadsheet import
+type LegacyImportRun struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Entity       string     `gorm:"not null;type:varchar(20);index" json:"entity"`
+	TemplateID   *uint      `gorm:"default:null" json:"template_id"`
+	FileName     string     `gorm:"type:varchar(255)" json:"file_name"`
+	FileHash     string     `gorm:"type:varchar(64);index" json:"file_hash"` // SHA-256 of the file, to spot the same file imported again
+	Source       string     `gorm:"not null;type:varchar(10)" json:"source"`
+	DryRun       bool       `gorm:"not null;default:false" json:"dry_run"`
+	Status       string     `gorm:"not null;type:varchar(20)" json:"status"`
+	TotalRecords int        `gorm:"not null;default:0" json:"total_records"` // orders, complains or attendances in the file
+	Imported     int        `gorm:"not null;default:0" json:"imported"`      // created by this run, or that would be on a dry run
+	Skipped      int        `gorm:"not null;default:0" json:"skipped"`       // imported by an earlier run
+	Invalid      int        `gorm:"not null;default:0" json:"invalid"`
+	Report       string     `gorm:"type:text" json:"report"` // JSON list of row errors
+	Error        string     `gorm:"type:text" json:"error"`
+	StartedBy    *uint      `gorm:"default:null" json:"started_by"` // nil when run from the command line
+	CreatedAt    time.Time  `json:"created_at"`
+	CompletedAt  *time.Time `gorm:"default:null" json:"completed_at"`
+
+	Template  *LegacyImportTemplate `gorm:"foreignKey:TemplateID" json:"template,omitempty"`
+	StartUser *User                 `gorm:"foreignKey:StartedBy" json:"start_user,omitempty"`
+}
+
+// LegacyImportRowError is a validation error of a row of a legacy spreadsheet
+type LegacyImportRowError struct {
+	Row     int    `json:"row"`
+	Key     string `json:"key,omitempty"` // order ID, complain code or user and date the row belongs to
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// LegacyImportRunResponse represents the legacy import run data returned in API responses
+type LegacyImportRunResponse struct {
+	ID           uint                   `json:"id"`
+	Entity       string                 `json:"entity"`
+	Template     string                 `json:"template,omitempty"`
+	FileName     string                 `json:"fileName"`
+	FileHash     string                 `json:"fileHash"`
+	Source       string                 `json:"source"`
+	DryRun       bool                   `json:"dryRun"`
+	Status       string                 `json:"status"`
+	TotalRecords int                    `json:"totalRecords"`
+	Imported     int                    `json:"imported"`
+	Skipped      int                    `json:"skipped"`
+	Invalid      int                    `json:"invalid"`
+	Error        string                 `json:"error,omitempty"`
+	StartedBy    *string                `json:"startedBy"`
+	CreatedAt    string                 `json:"createdAt"`
+	CompletedAt  *string                `json:"completedAt"`
+	Report       []LegacyImportRowError `json:"report,omitempty"`
+}
+
+// ToResponse converts a LegacyImportRun model to a LegacyImportRunResponse, with its report when withReport is set
+func (r *LegacyImportRun) ToResponse(withReport bool) *LegacyImportRunResponse {
+	response := &LegacyImportRunResponse{
+		ID:           r.ID,
+		Entity:       r.Entity,
+		FileName:     r.FileName,
+		FileHash:     r.FileHash,
+		Source:       r.Source,
+		DryRun:       r.DryRun,
+		Status:       r.Status,
+		TotalRecords: r.TotalRecords,
+		Imported:     r.Imported,
+		Skipped:      r.Skipped,
+		Invalid:      r.Invalid,
+		Error:        r.Error,
+		CreatedAt:    r.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if r.Template != nil {
+		response.Template = r.Template.Name
+	}
+	if r.CompletedAt != nil {
+		formatted := r.CompletedAt.Format("02-01-2006 15:04:05")
+		response.CompletedAt = &formatted
+	}
+	if withReport && r.Report != "" {
+		_ = json.Unmarshal([]byte(r.Report), &response.Report)
+	}
+
+	// User visual handler
+	if r.StartUser != nil {
+		response.StartedBy = &r.StartUser.FullName
+	}
+	return response
+}
+
+// LegacyImportRecord ledgers a record created from a legacy spreadsheet by its key in the legacy system,
+// re-running an import skips the keys already in the ledger
+type LegacyImportRecord struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Entity    string    `gorm:"not null;type:varchar(20);uniqueIndex:idx_legacy_import_record" json:"entity"`
+	LegacyKey string    `gorm:"not null;type:varchar(150);uniqueIndex:idx_legacy_import_record" json:"legacy_key"`
+	RecordID  uint      `gorm:"not null" json:"record_id"` // ID of the created order, complain or attendance
+	RunID     uint      `gorm:"not null;index" json:"run_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Notification is an in-app message addressed to a single user
+type Notification struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	UserID        uint       `gorm:"not null;index" json:"user_id"`
+	Type          string     `gorm:"not null;type:varchar(50);index" json:"type"`
+	Title         string     `gorm:"not null;type:varchar(255)" json:"title"`
+	Message       string     `gorm:"type:text" json:"message"`
+	ReferenceType string     `gorm:"type:varchar(50)" json:"reference_type"`
+	ReferenceID   uint       `gorm:"default:0" json:"reference_id"`
+	IsRead        bool       `gorm:"default:false;index" json:"is_read"`
+	ReadAt        *time.Time `gorm:"default:null" json:"read_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+type NotificationResponse struct {
+	ID            uint    `json:"id"`
+	Type          string  `json:"type"`
+	Title         string  `json:"title"`
+	Message       string  `json:"message"`
+	ReferenceType string  `json:"referenceType,omitempty"`
+	ReferenceID   uint    `json:"referenceId,omitempty"`
+	IsRead        bool    `json:"isRead"`
+	ReadAt        *string `json:"readAt,omitempty"`
+	CreatedAt     string  `json:"createdAt"`
+}
+
+// ToResponse converts a Notification model to a NotificationResponse
+func (n *Notification) ToResponse() *NotificationResponse {
+	var readAt *string
+	if n.ReadAt != nil {
+		formatted := n.ReadAt.Format("02-01-2006 15:04:05")
+		readAt = &formatted
+	}
+
+	return &NotificationResponse{
+		ID:            n.ID,
+		Type:          n.Type,
+		Title:         n.Title,
+		Message:       n.Message,
+		ReferenceType: n.ReferenceType,
+		ReferenceID:   n.ReferenceID,
+		IsRead:        n.IsRead,
+		ReadAt:        readAt,
+		CreatedAt:     n.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
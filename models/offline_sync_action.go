@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// OfflineSyncAction records an action performed offline by the mobile app and applied on sync
+// ClientActionID is generated by the client and makes re-submitted actions idempotent
+type OfflineSyncAction struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ClientActionID  string    `gorm:"uniqueIndex;not null;type:varchar(36)" json:"client_action_id"`
+	UserID          uint      `gorm:"not null;index" json:"user_id"`
+	ActionType      string    `gorm:"not null;type:varchar(50)" json:"action_type"` // complete_pick or pending_pick
+	OrderID         uint      `gorm:"not null;index" json:"order_id"`
+	ClientTimestamp time.Time `json:"client_timestamp"`
+	Status          string    `gorm:"not null;type:varchar(20)" json:"status"` // applied, conflict or rejected
+	Message         string    `gorm:"type:text" json:"message"`
+	ClockSkewFlag   bool      `gorm:"default:false;index" json:"clock_skew_flag"` // client timestamp was implausible, the action was recorded at sync time
+	ClockSkewReason string    `gorm:"type:text" json:"clock_skew_reason"`
+	CreatedAt       time.Time `json:"created_at"`
+
+	User  *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Order *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
@@ -1,6 +1,64 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Order sources, where an order comes from
+const (
+	OrderSourceMarketplace = "marketplace" // synced or imported from a marketplace
+	OrderSourceOffline     = "offline"     // walk-in or offline sale entered by hand
+	OrderSourceReplacement = "replacement" // replacement shipment for a complaint or return
+	OrderSourceInternal    = "internal"    // internal use, samples and transfers
+)
+
+var orderSources = []string{OrderSourceMarketplace, OrderSourceOffline, OrderSourceReplacement, OrderSourceInternal}
+
+// OrderSources returns the known order sources
+func OrderSources() []string {
+	return orderSources
+}
+
+// IsValidOrderSource reports whether source is a known order source
+func IsValidOrderSource(source string) bool {
+	for _, known := range orderSources {
+		if known == source {
+			return true
+		}
+	}
+	return false
+}
+
+// Order priorities, urgent and same day orders are picked and checked before normal ones
+const (
+	OrderPriorityNormal  = "normal"
+	OrderPriorityUrgent  = "urgent"   // marketplace express or flagged by customer service
+	OrderPrioritySameDay = "same_day" // must leave the warehouse today
+)
+
+var orderPriorities = []string{OrderPriorityNormal, OrderPriorityUrgent, OrderPrioritySameDay}
+
+// OrderPriorities returns the known order priorities
+func OrderPriorities() []string {
+	return orderPriorities
+}
+
+// IsValidOrderPriority reports whether priority is a known order priority
+func IsValidOrderPriority(priority string) bool {
+	for _, known := range orderPriorities {
+		if known == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// OrderQueueOrder sorts order queues by priority, same day first, then by the SentBefore deadline
+const OrderQueueOrder = "CASE orders.priority WHEN 'same_day' THEN 0 WHEN 'urgent' THEN 1 ELSE 2 END ASC, orders.sent_before ASC, orders.id ASC"
 
 type Order struct {
 	ID               uint       `gorm:"primaryKey" json:"id"`
@@ -11,6 +69,11 @@ type Order struct {
 	Store            string     `gorm:"type:varchar(100)" json:"store"`
 	Buyer            string     `gorm:"type:varchar(150)" json:"buyer"`
 	Address          string     `gorm:"type:text" json:"address"`
+	Province         string     `gorm:"type:varchar(100);index" json:"province"`
+	City             string     `gorm:"type:varchar(100);index" json:"city"`
+	District         string     `gorm:"type:varchar(100)" json:"district"`
+	PostalCode       string     `gorm:"type:varchar(10);index" json:"postal_code"`
+	AddressStatus    string     `gorm:"type:varchar(20)" json:"address_status"`
 	Courier          string     `gorm:"type:varchar(100)" json:"courier"`
 	TrackingNumber   string     `gorm:"type:varchar(100)" json:"tracking_number"`
 	SentBefore       time.Time  `gorm:"type:timestamp;not null" json:"sent_before"`
@@ -26,6 +89,23 @@ type Order struct {
 	DuplicatedAt     *time.Time `gorm:"default:null" json:"duplicated_at"`
 	CanceledBy       *uint      `gorm:"default:null" json:"canceled_by"`
 	CanceledAt       *time.Time `gorm:"default:null" json:"canceled_at"`
+	HeldBy           *uint      `gorm:"default:null" json:"held_by"`
+	HeldAt           *time.Time `gorm:"default:null" json:"held_at"`
+	HoldReason       string     `gorm:"type:text" json:"hold_reason"`
+	ParentOrderID    *uint      `gorm:"default:null;index" json:"parent_order_id"` // set on shipments split from another order
+	SplitBy          *uint      `gorm:"default:null" json:"split_by"`
+	SplitAt          *time.Time `gorm:"default:null" json:"split_at"`
+	MergedIntoID     *uint      `gorm:"default:null;index" json:"merged_into_id"` // set on orders whose details were merged into another order
+	MergedBy         *uint      `gorm:"default:null" json:"merged_by"`
+	MergedAt         *time.Time `gorm:"default:null" json:"merged_at"`
+	TotalItems       int        `gorm:"not null;default:0" json:"total_items"` // number of order detail lines
+	TotalQuantity    int        `gorm:"not null;default:0" json:"total_quantity"`
+	TotalValue       int64      `gorm:"not null;default:0" json:"total_value"` // sum of quantity * price
+	Currency         string     `gorm:"type:varchar(3)" json:"currency"`
+	OrderSource      string     `gorm:"not null;type:varchar(20);default:marketplace;index" json:"order_source"`
+	Priority         string     `gorm:"not null;type:varchar(20);default:normal;index" json:"priority"`
+	GiftMessage      string     `gorm:"type:text" json:"gift_message"`  // buyer's gift note to print or enclose in the parcel
+	HandlingNote     string     `gorm:"type:text" json:"handling_note"` // e.g. fragile, keep upright, no invoice in the parcel
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
 	Complained       bool       `gorm:"default:false" json:"complained"`
@@ -37,6 +117,12 @@ type Order struct {
 	ChangeUser    *User         `gorm:"foreignKey:ChangedBy" json:"change_user,omitempty"`
 	DuplicateUser *User         `gorm:"foreignKey:DuplicatedBy" json:"duplicate_user,omitempty"`
 	CancelUser    *User         `gorm:"foreignKey:CanceledBy" json:"cancel_user,omitempty"`
+	HoldUser      *User         `gorm:"foreignKey:HeldBy" json:"hold_user,omitempty"`
+	SplitUser     *User         `gorm:"foreignKey:SplitBy" json:"split_user,omitempty"`
+	MergeUser     *User         `gorm:"foreignKey:MergedBy" json:"merge_user,omitempty"`
+
+	// Active edit lock, only loaded for the order detail
+	EditLock *OrderEditLock `gorm:"-" json:"-"`
 }
 
 type OrderDetail struct {
@@ -48,49 +134,167 @@ type OrderDetail struct {
 	Quantity    int    `gorm:"not null" json:"quantity"`
 	Price       int    `gorm:"not null" json:"price"`
 	IsValid     bool   `gorm:"default:false" json:"is_valid"`
+	Note        string `gorm:"type:text" json:"note"` // marketplace item note, e.g. gift wrap or a color preference
+
+	ScannedQuantity int `gorm:"not null;default:0" json:"scanned_quantity"`
+
+	// Marketplace order the detail came from when orders were merged, nil for the order's own details
+	SourceOrderID *uint `gorm:"default:null;index" json:"source_order_id"`
+
+	// Picking checklist
+	PickedQuantity int        `gorm:"not null;default:0" json:"picked_quantity"`
+	IsPicked       bool       `gorm:"default:false" json:"is_picked"`
+	ItemPickedAt   *time.Time `gorm:"default:null" json:"item_picked_at"`
+	IsShortage     bool       `gorm:"default:false" json:"is_shortage"`
+	ShortageReason string     `gorm:"type:text" json:"shortage_reason"`
+
+	// Shortage substitution, OriginalSKU is the ordered SKU when the item was fulfilled by a substitute
+	OriginalSKU   string     `gorm:"type:varchar(255)" json:"original_sku"`
+	SubstitutedBy *uint      `gorm:"default:null" json:"substituted_by"`
+	SubstitutedAt *time.Time `gorm:"default:null" json:"substituted_at"`
 
 	Order   *Order   `gorm:"foreignKey:OrderID" json:"-"`
 	Product *Product `gorm:"-" json:"product,omitempty"`
+
+	// FEFO lot suggestions, only loaded for picking lists
+	BatchPicks []BatchPick `gorm:"-" json:"-"`
+
+	// Curated name and variant, only set for picking lists and QC screens
+	DisplayName    string `gorm:"-" json:"-"`
+	DisplayVariant string `gorm:"-" json:"-"`
+
+	// Serial numbers captured at QC, only loaded for QC screens, documents and order details
+	SerialNumbers []string `gorm:"-" json:"-"`
+}
+
+// CalculateTotals sets the order totals from its loaded order details
+func (o *Order) CalculateTotals() {
+	o.TotalItems = len(o.OrderDetails)
+	o.TotalQuantity = 0
+	o.TotalValue = 0
+	for _, detail := range o.OrderDetails {
+		o.TotalQuantity += detail.Quantity
+		o.TotalValue += int64(detail.Quantity) * int64(detail.Price)
+	}
+}
+
+// PackingNotes returns the gift message, handling instructions and item notes the packer must see.
+// Item notes are only included when the order details are loaded.
+func (o *Order) PackingNotes() []string {
+	var notes []string
+	if o.GiftMessage != "" {
+		notes = append(notes, "Gift message: "+o.GiftMessage)
+	}
+	if o.HandlingNote != "" {
+		notes = append(notes, "Handling: "+o.HandlingNote)
+	}
+	for _, detail := range o.OrderDetails {
+		if detail.Note != "" {
+			notes = append(notes, detail.SKU+": "+detail.Note)
+		}
+	}
+	return notes
+}
+
+// HasPackingNotes reports whether the order carries notes or instructions for the packer
+func (o *Order) HasPackingNotes() bool {
+	return len(o.PackingNotes()) > 0
+}
+
+// RecalculateOrderTotals recomputes the persisted totals of the orders matched by tx from their order details.
+// Totals reflect the order as ordered: they follow detail edits, splits and merges, but not cancellation.
+func RecalculateOrderTotals(tx *gorm.DB) error {
+	return tx.Model(&Order{}).Updates(map[string]interface{}{
+		"total_items":    gorm.Expr("(SELECT COUNT(*) FROM order_details WHERE order_details.order_id = orders.id)"),
+		"total_quantity": gorm.Expr("(SELECT COALESCE(SUM(quantity), 0) FROM order_details WHERE order_details.order_id = orders.id)"),
+		"total_value":    gorm.Expr("(SELECT COALESCE(SUM(CAST(quantity AS bigint) * price), 0) FROM order_details WHERE order_details.order_id = orders.id)"),
+	}).Error
 }
 
 // OrderResponse represents the order data returned in API responses
 type OrderResponse struct {
-	ID               uint                  `json:"id"`
-	OrderGineeID     string                `json:"orderGineeId"`
-	ProcessingStatus string                `json:"processingStatus"`
-	EventStatus      string                `json:"eventStatus"`
-	Channel          string                `json:"channel"`
-	Store            string                `json:"store"`
-	Buyer            string                `json:"buyer"`
-	Address          string                `json:"address"`
-	Courier          string                `json:"courier"`
-	TrackingNumber   string                `json:"trackingNumber"`
-	SentBefore       string                `json:"sentBefore"`
-	AssignedBy       *string               `json:"assignedBy,omitempty"`
-	AssignedAt       *string               `json:"assignedAt,omitempty"`
-	PickedBy         *string               `json:"pickedBy,omitempty"`
-	PickedAt         *string               `json:"pickedAt,omitempty"`
-	PendingBy        *string               `json:"pendingBy,omitempty"`
-	PendingAt        *string               `json:"pendingAt,omitempty"`
-	ChangedBy        *string               `json:"changedBy,omitempty"`
-	ChangedAt        *string               `json:"changedAt,omitempty"`
-	DuplicatedBy     *string               `json:"duplicatedBy,omitempty"`
-	DuplicatedAt     *string               `json:"duplicatedAt,omitempty"`
-	CanceledBy       *string               `json:"canceledBy,omitempty"`
-	CanceledAt       *string               `json:"canceledAt,omitempty"`
-	CreatedAt        string                `json:"createdAt"`
-	UpdatedAt        string                `json:"updatedAt"`
-	Complained       bool                  `json:"complained"`
-	Details          []OrderDetailResponse `json:"details,omitempty"`
+	ID               uint                   `json:"id"`
+	OrderGineeID     string                 `json:"orderGineeId"`
+	ProcessingStatus string                 `json:"processingStatus"`
+	EventStatus      string                 `json:"eventStatus"`
+	Channel          string                 `json:"channel"`
+	Store            string                 `json:"store"`
+	Buyer            string                 `json:"buyer"`
+	Address          string                 `json:"address"`
+	Province         string                 `json:"province,omitempty"`
+	City             string                 `json:"city,omitempty"`
+	District         string                 `json:"district,omitempty"`
+	PostalCode       string                 `json:"postalCode,omitempty"`
+	AddressStatus    string                 `json:"addressStatus,omitempty"`
+	Courier          string                 `json:"courier"`
+	TrackingNumber   string                 `json:"trackingNumber"`
+	SentBefore       string                 `json:"sentBefore"`
+	AssignedBy       *string                `json:"assignedBy,omitempty"`
+	AssignedAt       *string                `json:"assignedAt,omitempty"`
+	PickedBy         *string                `json:"pickedBy,omitempty"`
+	PickedAt         *string                `json:"pickedAt,omitempty"`
+	PendingBy        *string                `json:"pendingBy,omitempty"`
+	PendingAt        *string                `json:"pendingAt,omitempty"`
+	ChangedBy        *string                `json:"changedBy,omitempty"`
+	ChangedAt        *string                `json:"changedAt,omitempty"`
+	DuplicatedBy     *string                `json:"duplicatedBy,omitempty"`
+	DuplicatedAt     *string                `json:"duplicatedAt,omitempty"`
+	CanceledBy       *string                `json:"canceledBy,omitempty"`
+	CanceledAt       *string                `json:"canceledAt,omitempty"`
+	HeldBy           *string                `json:"heldBy,omitempty"`
+	HeldAt           *string                `json:"heldAt,omitempty"`
+	HoldReason       string                 `json:"holdReason,omitempty"`
+	ParentOrderID    *uint                  `json:"parentOrderId,omitempty"`
+	SplitBy          *string                `json:"splitBy,omitempty"`
+	SplitAt          *string                `json:"splitAt,omitempty"`
+	MergedIntoID     *uint                  `json:"mergedIntoId,omitempty"`
+	MergedBy         *string                `json:"mergedBy,omitempty"`
+	MergedAt         *string                `json:"mergedAt,omitempty"`
+	TotalItems       int                    `json:"totalItems"`
+	TotalQuantity    int                    `json:"totalQuantity"`
+	TotalValue       int64                  `json:"totalValue"`
+	Currency         string                 `json:"currency"`
+	OrderSource      string                 `json:"orderSource"`
+	Priority         string                 `json:"priority"`
+	GiftMessage      string                 `json:"giftMessage,omitempty"`
+	HandlingNote     string                 `json:"handlingNote,omitempty"`
+	CreatedAt        string                 `json:"createdAt"`
+	UpdatedAt        string                 `json:"updatedAt"`
+	Complained       bool                   `json:"complained"`
+	Details          []OrderDetailResponse  `json:"details,omitempty"`
+	Parcel           *OrderParcelEstimate   `json:"parcel,omitempty"` // only when the products of the details are loaded
+	EditLock         *OrderEditLockResponse `json:"editLock,omitempty"`
 }
 
 type OrderDetailResponse struct {
+	ID          uint   `json:"id"`
 	SKU         string `json:"sku"`
 	ProductName string `json:"productName"`
 	Variant     string `json:"variant"`
 	Quantity    int    `json:"quantity"`
 	Price       int    `json:"price"`
 	IsValid     bool   `json:"isValid"`
+	Note        string `json:"note,omitempty"`
+
+	DisplayName    string `json:"displayName,omitempty"`
+	DisplayVariant string `json:"displayVariant,omitempty"`
+
+	ScannedQuantity int `json:"scannedQuantity"`
+
+	SourceOrderID *uint `json:"sourceOrderId,omitempty"`
+
+	PickedQuantity int     `json:"pickedQuantity"`
+	IsPicked       bool    `json:"isPicked"`
+	ItemPickedAt   *string `json:"itemPickedAt,omitempty"`
+	IsShortage     bool    `json:"isShortage"`
+	ShortageReason string  `json:"shortageReason,omitempty"`
+
+	OriginalSKU string `json:"originalSku,omitempty"`
+
+	SerialNumbers []string `json:"serialNumbers,omitempty"`
+
+	SuggestedBatches []BatchPickResponse `json:"suggestedBatches,omitempty"`
+	ExpiryWarning    string              `json:"expiryWarning,omitempty"`
 
 	Product *ProductResponse `json:"product,omitempty"`
 }
@@ -100,32 +304,82 @@ func (o *Order) ToOrderResponse() *OrderResponse {
 	details := make([]OrderDetailResponse, len(o.OrderDetails))
 	for i, detail := range o.OrderDetails {
 		detailResp := OrderDetailResponse{
+			ID:          detail.ID,
 			SKU:         detail.SKU,
 			ProductName: detail.ProductName,
 			Variant:     detail.Variant,
 			Quantity:    detail.Quantity,
 			Price:       detail.Price,
 			IsValid:     detail.IsValid,
+			Note:        detail.Note,
+
+			DisplayName:    detail.DisplayName,
+			DisplayVariant: detail.DisplayVariant,
+
+			ScannedQuantity: detail.ScannedQuantity,
+
+			SourceOrderID: detail.SourceOrderID,
+
+			PickedQuantity: detail.PickedQuantity,
+			IsPicked:       detail.IsPicked,
+			IsShortage:     detail.IsShortage,
+			ShortageReason: detail.ShortageReason,
+
+			OriginalSKU: detail.OriginalSKU,
+
+			SerialNumbers: detail.SerialNumbers,
+		}
+		if detail.ItemPickedAt != nil {
+			formatted := detail.ItemPickedAt.Format("02-01-2006 15:04:05")
+			detailResp.ItemPickedAt = &formatted
+		}
+
+		// Include FEFO lot suggestions and expiry warnings if loaded
+		if len(detail.BatchPicks) > 0 {
+			now := time.Now()
+			var warnings []string
+			detailResp.SuggestedBatches = make([]BatchPickResponse, len(detail.BatchPicks))
+			for j, pick := range detail.BatchPicks {
+				pickResp := BatchPickResponse{
+					LotNumber:    pick.Batch.LotNumber,
+					DaysToExpiry: pick.Batch.DaysToExpiry(now),
+					ExpiryStatus: pick.Batch.ExpiryStatus(now, DefaultNearExpiryDays),
+					Quantity:     pick.Quantity,
+				}
+				if pick.Batch.ExpiryDate != nil {
+					formatted := pick.Batch.ExpiryDate.Format("02-01-2006")
+					pickResp.ExpiryDate = &formatted
+				}
+				if pickResp.ExpiryStatus == "near_expiry" {
+					warnings = append(warnings, fmt.Sprintf("Lot %s expires in %d days", pickResp.LotNumber, *pickResp.DaysToExpiry))
+				}
+				detailResp.SuggestedBatches[j] = pickResp
+			}
+			detailResp.ExpiryWarning = strings.Join(warnings, "; ")
 		}
 
 		// Include product data if exists
 		if detail.Product != nil {
 			detailResp.Product = &ProductResponse{
-				ID:        detail.Product.ID,
-				SKU:       detail.Product.SKU,
-				Name:      detail.Product.Name,
-				Image:     detail.Product.Image,
-				Variant:   detail.Product.Variant,
-				Location:  detail.Product.Location,
-				CreatedAt: detail.Product.CreatedAt.Format("02-01-2006 15:04:05"),
-				UpdatedAt: detail.Product.UpdatedAt.Format("02-01-2006 15:04:05"),
+				ID:          detail.Product.ID,
+				SKU:         detail.Product.SKU,
+				Name:        detail.Product.Name,
+				Image:       detail.Product.Image,
+				Variant:     detail.Product.Variant,
+				Location:    detail.Product.Location,
+				WeightGrams: detail.Product.WeightGrams,
+				LengthCm:    detail.Product.LengthCm,
+				WidthCm:     detail.Product.WidthCm,
+				HeightCm:    detail.Product.HeightCm,
+				CreatedAt:   detail.Product.CreatedAt.Format("02-01-2006 15:04:05"),
+				UpdatedAt:   detail.Product.UpdatedAt.Format("02-01-2006 15:04:05"),
 			}
 		}
 		details[i] = detailResp
 	}
 
 	// User visual handlers
-	var assignedBy, pickedBy, pendingBy, changedBy, duplicatedBy, canceledBy *string
+	var assignedBy, pickedBy, pendingBy, changedBy, duplicatedBy, canceledBy, heldBy, splitBy, mergedBy *string
 	if o.AssignUser != nil {
 		assignedBy = &o.AssignUser.FullName
 	}
@@ -144,9 +398,18 @@ func (o *Order) ToOrderResponse() *OrderResponse {
 	if o.CancelUser != nil {
 		canceledBy = &o.CancelUser.FullName
 	}
+	if o.HoldUser != nil {
+		heldBy = &o.HoldUser.FullName
+	}
+	if o.SplitUser != nil {
+		splitBy = &o.SplitUser.FullName
+	}
+	if o.MergeUser != nil {
+		mergedBy = &o.MergeUser.FullName
+	}
 
 	// Date visual handlers
-	var assignedAt, pickedAt, pendingAt, changedAt, duplicatedAt, canceledAt *string
+	var assignedAt, pickedAt, pendingAt, changedAt, duplicatedAt, canceledAt, heldAt, splitAt, mergedAt *string
 	if o.AssignedAt != nil {
 		formatted := o.AssignedAt.Format("02-01-2006 15:04:05")
 		assignedAt = &formatted
@@ -171,37 +434,27 @@ func (o *Order) ToOrderResponse() *OrderResponse {
 		formatted := o.CanceledAt.Format("02-01-2006 15:04:05")
 		canceledAt = &formatted
 	}
+	if o.HeldAt != nil {
+		formatted := o.HeldAt.Format("02-01-2006 15:04:05")
+		heldAt = &formatted
+	}
+	if o.SplitAt != nil {
+		formatted := o.SplitAt.Format("02-01-2006 15:04:05")
+		splitAt = &formatted
+	}
+	if o.MergedAt != nil {
+		formatted := o.MergedAt.Format("02-01-2006 15:04:05")
+		mergedAt = &formatted
+	}
+
+	// Status visual handlers, labels come from the status state machine
+	processingStatus := ProcessingStatusLabel(o.ProcessingStatus)
+	eventStatus := EventStatusLabel(o.EventStatus)
 
-	// Processing status visual handler
-	var processingStatus string
-	switch o.ProcessingStatus {
-	case "ready_to_pick":
-		processingStatus = "Ready to Pick"
-	case "picking_progress":
-		processingStatus = "Picking in Progress"
-	case "picking_pending":
-		processingStatus = "Picking is Pending"
-	case "picking_completed":
-		processingStatus = "Picking Completed"
-	case "qc_progress":
-		processingStatus = "QC in Progress"
-	case "qc_completed":
-		processingStatus = "QC Completed"
-	case "outbound_completed":
-		processingStatus = "Outbound Completed"
-	}
-
-	// Event status visual handler
-	var eventStatus string
-	switch o.EventStatus {
-	case "in_progress":
-		eventStatus = "In Progress"
-	case "completed":
-		eventStatus = "Completed"
-	case "pending":
-		eventStatus = "Pending"
-	case "canceled":
-		eventStatus = "Canceled"
+	// Include the edit lock if loaded
+	var editLock *OrderEditLockResponse
+	if o.EditLock != nil {
+		editLock = o.EditLock.ToResponse()
 	}
 
 	return &OrderResponse{
@@ -213,6 +466,11 @@ func (o *Order) ToOrderResponse() *OrderResponse {
 		Store:            o.Store,
 		Buyer:            o.Buyer,
 		Address:          o.Address,
+		Province:         o.Province,
+		City:             o.City,
+		District:         o.District,
+		PostalCode:       o.PostalCode,
+		AddressStatus:    o.AddressStatus,
 		Courier:          o.Courier,
 		TrackingNumber:   o.TrackingNumber,
 		SentBefore:       o.SentBefore.Format("02-01-2006 15:04:05"),
@@ -228,9 +486,28 @@ func (o *Order) ToOrderResponse() *OrderResponse {
 		DuplicatedAt:     duplicatedAt,
 		CanceledBy:       canceledBy,
 		CanceledAt:       canceledAt,
+		HeldBy:           heldBy,
+		HeldAt:           heldAt,
+		HoldReason:       o.HoldReason,
+		ParentOrderID:    o.ParentOrderID,
+		SplitBy:          splitBy,
+		SplitAt:          splitAt,
+		MergedIntoID:     o.MergedIntoID,
+		MergedBy:         mergedBy,
+		MergedAt:         mergedAt,
+		TotalItems:       o.TotalItems,
+		TotalQuantity:    o.TotalQuantity,
+		TotalValue:       o.TotalValue,
+		Currency:         o.Currency,
+		OrderSource:      o.OrderSource,
+		Priority:         o.Priority,
+		GiftMessage:      o.GiftMessage,
+		HandlingNote:     o.HandlingNote,
 		CreatedAt:        o.CreatedAt.Format("02-01-2006 15:04:05"),
 		UpdatedAt:        o.UpdatedAt.Format("02-01-2006 15:04:05"),
 		Complained:       o.Complained,
 		Details:          details,
+		Parcel:           o.ParcelEstimate(),
+		EditLock:         editLock,
 	}
 }
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// OrderAddressChange records a shipping address edit of an order before outbound, keeping the previous address for disputes
+type OrderAddressChange struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	OrderID            uint      `gorm:"not null;index" json:"order_id"`
+	TrackingNumber     string    `gorm:"not null;index;type:varchar(100)" json:"tracking_number"`
+	PreviousAddress    string    `gorm:"type:text" json:"previous_address"`
+	PreviousProvince   string    `gorm:"type:varchar(100)" json:"previous_province"`
+	PreviousCity       string    `gorm:"type:varchar(100)" json:"previous_city"`
+	PreviousDistrict   string    `gorm:"type:varchar(100)" json:"previous_district"`
+	PreviousPostalCode string    `gorm:"type:varchar(10)" json:"previous_postal_code"`
+	NewAddress         string    `gorm:"type:text" json:"new_address"`
+	NewProvince        string    `gorm:"type:varchar(100)" json:"new_province"`
+	NewCity            string    `gorm:"type:varchar(100)" json:"new_city"`
+	NewDistrict        string    `gorm:"type:varchar(100)" json:"new_district"`
+	NewPostalCode      string    `gorm:"type:varchar(10)" json:"new_postal_code"`
+	Reason             string    `gorm:"not null;type:text" json:"reason"`
+	LabelKey           string    `gorm:"type:text" json:"label_key"` // storage key of the shipping label regenerated for the new address
+	ChangedBy          uint      `gorm:"not null" json:"changed_by"`
+	CreatedAt          time.Time `json:"created_at"`
+
+	ChangeUser *User `gorm:"foreignKey:ChangedBy" json:"change_user,omitempty"`
+}
+
+// OrderAddressChangeResponse represents the order address change data returned in API responses
+type OrderAddressChangeResponse struct {
+	ID                 uint   `json:"id"`
+	OrderID            uint   `json:"orderId"`
+	TrackingNumber     string `json:"trackingNumber"`
+	PreviousAddress    string `json:"previousAddress"`
+	PreviousProvince   string `json:"previousProvince,omitempty"`
+	PreviousCity       string `json:"previousCity,omitempty"`
+	PreviousDistrict   string `json:"previousDistrict,omitempty"`
+	PreviousPostalCode string `json:"previousPostalCode,omitempty"`
+	NewAddress         string `json:"newAddress"`
+	NewProvince        string `json:"newProvince,omitempty"`
+	NewCity            string `json:"newCity,omitempty"`
+	NewDistrict        string `json:"newDistrict,omitempty"`
+	NewPostalCode      string `json:"newPostalCode,omitempty"`
+	Reason             string `json:"reason"`
+	ChangedBy          string `json:"changedBy"`
+	CreatedAt          string `json:"createdAt"`
+}
+
+// ToResponse converts an OrderAddressChange model to an OrderAddressChangeResponse
+func (oac *OrderAddressChange) ToResponse() *OrderAddressChangeResponse {
+	// User visual handlers
+	var changedBy string
+	if oac.ChangeUser != nil {
+		changedBy = oac.ChangeUser.FullName
+	}
+
+	return &OrderAddressChangeResponse{
+		ID:                 oac.ID,
+		OrderID:            oac.OrderID,
+		TrackingNumber:     oac.TrackingNumber,
+		PreviousAddress:    oac.PreviousAddress,
+		PreviousProvince:   oac.PreviousProvince,
+		PreviousCity:       oac.PreviousCity,
+		PreviousDistrict:   oac.PreviousDistrict,
+		PreviousPostalCode: oac.PreviousPostalCode,
+		NewAddress:         oac.NewAddress,
+		NewProvince:        oac.NewProvince,
+		NewCity:            oac.NewCity,
+		NewDistrict:        oac.NewDistrict,
+		NewPostalCode:      oac.NewPostalCode,
+		Reason:             oac.Reason,
+		ChangedBy:          changedBy,
+		CreatedAt:          oac.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
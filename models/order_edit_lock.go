@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// OrderEditLock marks an order as being edited by an admin so others are warned, it lapses at ExpiresAt unless kept alive by heartbeats.
+// Locks are advisory, order updates are not blocked by them.
+type OrderEditLock struct {
+	OrderID     uint      `gorm:"primaryKey;autoIncrement:false" json:"order_id"`
+	LockedBy    uint      `gorm:"not null" json:"locked_by"`
+	AcquiredAt  time.Time `gorm:"not null" json:"acquired_at"`
+	HeartbeatAt time.Time `gorm:"not null" json:"heartbeat_at"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+
+	LockUser *User `gorm:"foreignKey:LockedBy" json:"lock_user,omitempty"`
+}
+
+// OrderEditLockResponse represents the order edit lock data returned in API responses
+type OrderEditLockResponse struct {
+	OrderID     uint   `json:"orderId"`
+	LockedByID  uint   `json:"lockedById"`
+	LockedBy    string `json:"lockedBy"`
+	AcquiredAt  string `json:"acquiredAt"`
+	HeartbeatAt string `json:"heartbeatAt"`
+	ExpiresAt   string `json:"expiresAt"`
+}
+
+// ToResponse converts an OrderEditLock model to an OrderEditLockResponse
+func (l *OrderEditLock) ToResponse() *OrderEditLockResponse {
+	// User visual handlers
+	var lockedBy string
+	if l.LockUser != nil {
+		lockedBy = l.LockUser.FullName
+	}
+
+	return &OrderEditLockResponse{
+		OrderID:     l.OrderID,
+		LockedByID:  l.LockedBy,
+		LockedBy:    lockedBy,
+		AcquiredAt:  l.AcquiredAt.Format("02-01-2006 15:04:05"),
+		HeartbeatAt: l.HeartbeatAt.Format("02-01-2006 15:04:05"),
+		ExpiresAt:   l.ExpiresAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// OrderEditOverride records a coordinator override that changed the items or prices of an order after picking
+type OrderEditOverride struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	OrderID        uint      `gorm:"not null;index" json:"order_id"`
+	TrackingNumber string    `gorm:"not null;index;type:varchar(100)" json:"tracking_number"`
+	PreviousStatus string    `gorm:"type:varchar(50)" json:"previous_status"`
+	Justification  string    `gorm:"not null;type:text" json:"justification"`
+	DetailsBefore  string    `gorm:"type:text" json:"details_before"` // one "SKU x quantity @ price" line per order detail
+	DetailsAfter   string    `gorm:"type:text" json:"details_after"`
+	ItemsChanged   bool      `gorm:"default:false" json:"items_changed"` // SKUs or quantities changed, not only prices
+	QCReset        bool      `gorm:"default:false" json:"qc_reset"`      // the QC was voided so the order is QC'd again
+	OverriddenBy   uint      `gorm:"not null" json:"overridden_by"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	OverrideUser *User `gorm:"foreignKey:OverriddenBy" json:"override_user,omitempty"`
+}
+
+// OrderEditOverrideResponse represents the order edit override data returned in API responses
+type OrderEditOverrideResponse struct {
+	ID             uint   `json:"id"`
+	OrderID        uint   `json:"orderId"`
+	TrackingNumber string `json:"trackingNumber"`
+	PreviousStatus string `json:"previousStatus"`
+	Justification  string `json:"justification"`
+	DetailsBefore  string `json:"detailsBefore"`
+	DetailsAfter   string `json:"detailsAfter"`
+	ItemsChanged   bool   `json:"itemsChanged"`
+	QCReset        bool   `json:"qcReset"`
+	OverriddenBy   string `json:"overriddenBy"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// ToResponse converts an OrderEditOverride model to an OrderEditOverrideResponse
+func (oeo *OrderEditOverride) ToResponse() *OrderEditOverrideResponse {
+	// User visual handlers
+	var overriddenBy string
+	if oeo.OverrideUser != nil {
+		overriddenBy = oeo.OverrideUser.FullName
+	}
+
+	return &OrderEditOverrideResponse{
+		ID:             oeo.ID,
+		OrderID:        oeo.OrderID,
+		TrackingNumber: oeo.TrackingNumber,
+		PreviousStatus: oeo.PreviousStatus,
+		Justification:  oeo.Justification,
+		DetailsBefore:  oeo.DetailsBefore,
+		DetailsAfter:   oeo.DetailsAfter,
+		ItemsChanged:   oeo.ItemsChanged,
+		QCReset:        oeo.QCReset,
+		OverriddenBy:   overriddenBy,
+		CreatedAt:      oeo.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// OrderHold records an order being put on hold, e.g. while customer service confirms an address
+type OrderHold struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	OrderID     uint       `gorm:"not null;index" json:"order_id"`
+	Reason      string     `gorm:"not null;type:text" json:"reason"`
+	HeldBy      uint       `gorm:"not null" json:"held_by"`
+	HeldAt      time.Time  `gorm:"not null" json:"held_at"`
+	ReleasedBy  *uint      `gorm:"default:null" json:"released_by"`
+	ReleasedAt  *time.Time `gorm:"default:null" json:"released_at"`
+	ReleaseNote string     `gorm:"type:text" json:"release_note"`
+
+	HoldUser    *User `gorm:"foreignKey:HeldBy" json:"hold_user,omitempty"`
+	ReleaseUser *User `gorm:"foreignKey:ReleasedBy" json:"release_user,omitempty"`
+}
+
+type OrderHoldResponse struct {
+	ID          uint    `json:"id"`
+	OrderID     uint    `json:"orderId"`
+	Reason      string  `json:"reason"`
+	HeldBy      string  `json:"heldBy"`
+	HeldAt      string  `json:"heldAt"`
+	ReleasedBy  *string `json:"releasedBy,omitempty"`
+	ReleasedAt  *string `json:"releasedAt,omitempty"`
+	ReleaseNote string  `json:"releaseNote,omitempty"`
+}
+
+// ToResponse converts an OrderHold model to an OrderHoldResponse
+func (oh *OrderHold) ToResponse() *OrderHoldResponse {
+	// User visual handlers
+	var heldBy string
+	if oh.HoldUser != nil {
+		heldBy = oh.HoldUser.FullName
+	}
+	var releasedBy *string
+	if oh.ReleaseUser != nil {
+		releasedBy = &oh.ReleaseUser.FullName
+	}
+
+	var releasedAt *string
+	if oh.ReleasedAt != nil {
+		formatted := oh.ReleasedAt.Format("02-01-2006 15:04:05")
+		releasedAt = &formatted
+	}
+
+	return &OrderHoldResponse{
+		ID:          oh.ID,
+		OrderID:     oh.OrderID,
+		Reason:      oh.Reason,
+		HeldBy:      heldBy,
+		HeldAt:      oh.HeldAt.Format("02-01-2006 15:04:05"),
+		ReleasedBy:  releasedBy,
+		ReleasedAt:  releasedAt,
+		ReleaseNote: oh.ReleaseNote,
+	}
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Reasons an order item serial was released
+const (
+	SerialReleaseOrderCanceled = "order_canceled"
+	SerialReleaseQCVoided      = "qc_voided"
+)
+
+// OrderItemSerial is the serial number (IMEI or SN) of a unit of a serialized product, captured at QC.
+// A serial is shipped with one order at a time, it can be captured again once released.
+type OrderItemSerial struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	OrderID        uint       `gorm:"not null;index" json:"order_id"`
+	OrderDetailID  uint       `gorm:"not null;index" json:"order_detail_id"`
+	TrackingNumber string     `gorm:"not null;type:varchar(100);index" json:"tracking_number"`
+	SKU            string     `gorm:"not null;type:varchar(255);uniqueIndex:idx_order_item_serials_active,where:released_at IS NULL" json:"sku"`
+	SerialNumber   string     `gorm:"not null;type:varchar(100);index;uniqueIndex:idx_order_item_serials_active,where:released_at IS NULL" json:"serial_number"` // normalized with NormalizeSerialNumber
+	Lane           string     `gorm:"not null;type:varchar(20)" json:"lane"`                                                                                     // ribbon or online
+	CapturedBy     uint       `gorm:"not null" json:"captured_by"`
+	ReleasedAt     *time.Time `gorm:"default:null" json:"released_at"`
+	ReleaseReason  string     `gorm:"type:varchar(30)" json:"release_reason"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	CaptureUser *User `gorm:"foreignKey:CapturedBy" json:"capture_user,omitempty"`
+}
+
+// NormalizeSerialNumber returns the form serial numbers are stored and looked up in: trimmed, uppercased and
+// without inner spaces, as printed labels are often read with spacing
+func NormalizeSerialNumber(serialNumber string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(serialNumber), ""))
+}
+
+// OrderItemSerialResponse represents the order item serial data returned in API responses
+type OrderItemSerialResponse struct {
+	ID             uint    `json:"id"`
+	OrderID        uint    `json:"orderId"`
+	TrackingNumber string  `json:"trackingNumber"`
+	SKU            string  `json:"sku"`
+	SerialNumber   string  `json:"serialNumber"`
+	Lane           string  `json:"lane"`
+	CapturedBy     string  `json:"capturedBy"`
+	CapturedAt     string  `json:"capturedAt"`
+	ReleasedAt     *string `json:"releasedAt,omitempty"`
+	ReleaseReason  string  `json:"releaseReason,omitempty"`
+}
+
+// ToResponse converts an OrderItemSerial model to an OrderItemSerialResponse
+func (s *OrderItemSerial) ToResponse() *OrderItemSerialResponse {
+	// User visual handler
+	var capturedBy string
+	if s.CaptureUser != nil {
+		capturedBy = s.CaptureUser.FullName
+	}
+
+	// Date visual handler
+	var releasedAt *string
+	if s.ReleasedAt != nil {
+		formatted := s.ReleasedAt.Format("02-01-2006 15:04:05")
+		releasedAt = &formatted
+	}
+
+	return &OrderItemSerialResponse{
+		ID:             s.ID,
+		OrderID:        s.OrderID,
+		TrackingNumber: s.TrackingNumber,
+		SKU:            s.SKU,
+		SerialNumber:   s.SerialNumber,
+		Lane:           s.Lane,
+		CapturedBy:     capturedBy,
+		CapturedAt:     s.CreatedAt.Format("02-01-2006 15:04:05"),
+		ReleasedAt:     releasedAt,
+		ReleaseReason:  s.ReleaseReason,
+	}
+}
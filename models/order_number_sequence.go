@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// OrderNumberSequence is the last internal order number issued for a prefix in a year, numbers restart every year.
+// Internal tracking numbers of duplicated orders have their own sequence row.
+type OrderNumberSequence struct {
+	Prefix    string    `gorm:"primaryKey;type:varchar(20)" json:"prefix"`
+	Year      int       `gorm:"primaryKey;autoIncrement:false" json:"year"`
+	LastValue int64     `gorm:"not null" json:"last_value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
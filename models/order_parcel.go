@@ -0,0 +1,60 @@
+package models
+
+import "math"
+
+// OrderParcelEstimate is the parcel of an order rolled up from the product master data of its details, used to
+// book the shipment with the courier before the parcel is weighed at outbound
+type OrderParcelEstimate struct {
+	TotalWeightGrams      int      `json:"totalWeightGrams"`      // unit weights times quantities
+	TotalVolumeCm3        float64  `json:"totalVolumeCm3"`        // unit volumes times quantities
+	VolumetricWeightGrams int      `json:"volumetricWeightGrams"` // total volume at the courier volumetric divisor
+	ChargeableWeightGrams int      `json:"chargeableWeightGrams"` // higher of the total and volumetric weight
+	Complete              bool     `json:"complete"`              // every item has its weight and dimensions
+	MissingSKUs           []string `json:"missingSkus,omitempty"` // items without weight or dimensions, left out of the totals
+}
+
+// ParcelEstimate rolls up the weight and volume of the order details from their products. Returns nil unless the
+// details are loaded with their products, details without a product count as missing.
+func (o *Order) ParcelEstimate() *OrderParcelEstimate {
+	loaded := false
+	for _, detail := range o.OrderDetails {
+		if detail.Product != nil {
+			loaded = true
+			break
+		}
+	}
+	if !loaded {
+		return nil
+	}
+
+	estimate := &OrderParcelEstimate{Complete: true}
+	missing := map[string]bool{}
+	for _, detail := range o.OrderDetails {
+		if detail.Quantity <= 0 {
+			continue
+		}
+
+		var volume *float64
+		if detail.Product != nil {
+			volume = detail.Product.VolumeCm3()
+		}
+		if detail.Product == nil || detail.Product.WeightGrams == nil || volume == nil {
+			estimate.Complete = false
+			if !missing[detail.SKU] {
+				missing[detail.SKU] = true
+				estimate.MissingSKUs = append(estimate.MissingSKUs, detail.SKU)
+			}
+		}
+		if detail.Product != nil && detail.Product.WeightGrams != nil {
+			estimate.TotalWeightGrams += *detail.Product.WeightGrams * detail.Quantity
+		}
+		if volume != nil {
+			estimate.TotalVolumeCm3 += *volume * float64(detail.Quantity)
+		}
+	}
+
+	estimate.TotalVolumeCm3 = math.Round(estimate.TotalVolumeCm3*100) / 100
+	estimate.VolumetricWeightGrams = int(math.Ceil(estimate.TotalVolumeCm3 * 1000 / VolumetricWeightDivisor))
+	estimate.ChargeableWeightGrams = max(estimate.TotalWeightGrams, estimate.VolumetricWeightGrams)
+	return estimate
+}
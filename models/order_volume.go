@@ -0,0 +1,125 @@
+package models
+
+import "time"
+
+// Scopes the order volume is tracked per
+const (
+	OrderVolumeScopeStore   = "store"
+	OrderVolumeScopeChannel = "channel"
+)
+
+var orderVolumeScopes = []string{OrderVolumeScopeStore, OrderVolumeScopeChannel}
+
+// OrderVolumeScopes returns the scopes the order volume is tracked per
+func OrderVolumeScopes() []string {
+	return orderVolumeScopes
+}
+
+// IsValidOrderVolumeScope reports whether scope is a known order volume scope
+func IsValidOrderVolumeScope(scope string) bool {
+	for _, known := range orderVolumeScopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Directions of an order volume deviation
+const (
+	OrderVolumeDirectionSpike = "spike" // more orders than usual, e.g. a flash sale
+	OrderVolumeDirectionDrop  = "drop"  // fewer orders than usual, e.g. a marketplace sync failure
+)
+
+// OrderVolumeThreshold overrides the default order volume alert thresholds of a store or channel
+type OrderVolumeThreshold struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Scope        string    `gorm:"not null;type:varchar(20);uniqueIndex:idx_order_volume_threshold" json:"scope"`
+	ReferenceID  uint      `gorm:"not null;uniqueIndex:idx_order_volume_threshold" json:"reference_id"` // store or channel ID
+	Enabled      bool      `gorm:"not null" json:"enabled"`                                             // disabled thresholds silence the alerts of the store or channel
+	SpikePercent int       `gorm:"not null;default:0" json:"spike_percent"`                             // 0 disables spike alerts
+	DropPercent  int       `gorm:"not null;default:0" json:"drop_percent"`                              // 0 disables drop alerts
+	MinBaseline  int       `gorm:"not null;default:0" json:"min_baseline"`                              // baseline orders needed before deviations are alerted
+	UpdatedBy    uint      `gorm:"not null" json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	UpdateUser *User `gorm:"foreignKey:UpdatedBy" json:"update_user,omitempty"`
+}
+
+// OrderVolumeThresholdResponse represents the order volume threshold data returned in API responses
+type OrderVolumeThresholdResponse struct {
+	ID           uint   `json:"id"`
+	Scope        string `json:"scope"`
+	ReferenceID  uint   `json:"referenceId"`
+	Enabled      bool   `json:"enabled"`
+	SpikePercent int    `json:"spikePercent"`
+	DropPercent  int    `json:"dropPercent"`
+	MinBaseline  int    `json:"minBaseline"`
+	UpdatedBy    string `json:"updatedBy"`
+	UpdatedAt    string `json:"updatedAt"`
+}
+
+// ToResponse converts an OrderVolumeThreshold model to an OrderVolumeThresholdResponse
+func (ovt *OrderVolumeThreshold) ToResponse() *OrderVolumeThresholdResponse {
+	response := &OrderVolumeThresholdResponse{
+		ID:           ovt.ID,
+		Scope:        ovt.Scope,
+		ReferenceID:  ovt.ReferenceID,
+		Enabled:      ovt.Enabled,
+		SpikePercent: ovt.SpikePercent,
+		DropPercent:  ovt.DropPercent,
+		MinBaseline:  ovt.MinBaseline,
+		UpdatedAt:    ovt.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if ovt.UpdateUser != nil {
+		response.UpdatedBy = ovt.UpdateUser.Username
+	}
+	return response
+}
+
+// OrderVolumeAlert records a store or channel whose order count of a day deviated from its baseline.
+// A store or channel is alerted at most once per day and direction.
+type OrderVolumeAlert struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Scope            string    `gorm:"not null;type:varchar(20);uniqueIndex:idx_order_volume_alert" json:"scope"`
+	ReferenceID      uint      `gorm:"not null;uniqueIndex:idx_order_volume_alert" json:"reference_id"`
+	Name             string    `gorm:"type:varchar(100)" json:"name"` // store or channel name when alerted
+	Date             time.Time `gorm:"type:date;not null;uniqueIndex:idx_order_volume_alert;index" json:"date"`
+	Direction        string    `gorm:"not null;type:varchar(10);uniqueIndex:idx_order_volume_alert" json:"direction"`
+	Count            int64     `gorm:"not null" json:"count"`    // orders of the day up to the check
+	Baseline         float64   `gorm:"not null" json:"baseline"` // average orders of the previous days up to the same time of day
+	DeviationPercent float64   `gorm:"not null" json:"deviation_percent"`
+	DetectedAt       time.Time `gorm:"not null" json:"detected_at"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// OrderVolumeAlertResponse represents the order volume alert data returned in API responses
+type OrderVolumeAlertResponse struct {
+	ID               uint    `json:"id"`
+	Scope            string  `json:"scope"`
+	ReferenceID      uint    `json:"referenceId"`
+	Name             string  `json:"name"`
+	Date             string  `json:"date"`
+	Direction        string  `json:"direction"`
+	Count            int64   `json:"count"`
+	Baseline         float64 `json:"baseline"`
+	DeviationPercent float64 `json:"deviationPercent"`
+	DetectedAt       string  `json:"detectedAt"`
+}
+
+// ToResponse converts an OrderVolumeAlert model to an OrderVolumeAlertResponse
+func (ova *OrderVolumeAlert) ToResponse() *OrderVolumeAlertResponse {
+	return &OrderVolumeAlertResponse{
+		ID:               ova.ID,
+		Scope:            ova.Scope,
+		ReferenceID:      ova.ReferenceID,
+		Name:             ova.Name,
+		Date:             ova.Date.Format("2006-01-02"),
+		Direction:        ova.Direction,
+		Count:            ova.Count,
+		Baseline:         ova.Baseline,
+		DeviationPercent: ova.DeviationPercent,
+		DetectedAt:       ova.DetectedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -1,17 +1,37 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
+)
+
+// VolumetricWeightDivisor converts a parcel volume in cm3 to the volumetric weight in kg couriers bill by
+const VolumetricWeightDivisor = 6000
 
 type Outbound struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	TrackingNumber  string    `gorm:"uniqueIndex;not null;type:varchar(100)" json:"tracking_number"`
-	OutboundBy      uint      `gorm:"not null" json:"outbound_by"`
-	Expedition      string    `gorm:"type:varchar(100)" json:"expedition"`
-	ExpeditionSlug  string    `gorm:"type:varchar(100)" json:"expedition_slug"`
-	ExpeditionColor string    `gorm:"type:varchar(50)" json:"expedition_color"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	Complained      bool      `gorm:"default:false" json:"complained"`
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	TrackingNumber  string     `gorm:"uniqueIndex;not null;type:varchar(100)" json:"tracking_number"`
+	OutboundBy      uint       `gorm:"not null" json:"outbound_by"`
+	Expedition      string     `gorm:"type:varchar(100)" json:"expedition"`
+	ExpeditionSlug  string     `gorm:"type:varchar(100)" json:"expedition_slug"`
+	ExpeditionColor string     `gorm:"type:varchar(50)" json:"expedition_color"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Complained      bool       `gorm:"default:false" json:"complained"`
+	OrderCanceled   bool       `gorm:"default:false;index" json:"order_canceled"` // the order was canceled after it was shipped
+	HandedOverAt    *time.Time `gorm:"default:null" json:"handed_over_at"`        // set when the 3PL acknowledges receipt
+
+	// Parcel measured at the outbound scan by the scale connected to the scanning client, nil when not captured
+	WeightGrams *int     `gorm:"default:null" json:"weight_grams"`
+	LengthCm    *float64 `gorm:"default:null" json:"length_cm"`
+	WidthCm     *float64 `gorm:"default:null" json:"width_cm"`
+	HeightCm    *float64 `gorm:"default:null" json:"height_cm"`
+
+	// Courier invoice line of the parcel, imported for billing audits
+	InvoicedWeightGrams *int       `gorm:"default:null" json:"invoiced_weight_grams"`
+	InvoicedAmount      *int       `gorm:"default:null" json:"invoiced_amount"`
+	InvoiceRef          string     `gorm:"type:varchar(100);index" json:"invoice_ref"`
+	InvoiceImportedAt   *time.Time `gorm:"default:null" json:"invoice_imported_at"`
 
 	OutboundUser *User  `gorm:"foreignKey:OutboundBy" json:"outbound_user,omitempty"`
 	Order        *Order `gorm:"-" json:"order,omitempty"`
@@ -19,16 +39,50 @@ type Outbound struct {
 
 // OutboundResponse represents the outbound data returned in API responses
 type OutboundResponse struct {
-	ID              uint           `json:"id"`
-	TrackingNumber  string         `json:"trackingNumber"`
-	OutboundBy      string         `json:"outboundBy"`
-	Expedition      string         `json:"expedition"`
-	ExpeditionSlug  string         `json:"expeditionSlug"`
-	ExpeditionColor string         `json:"expeditionColor"`
-	CreatedAt       string         `json:"createdAt"`
-	UpdatedAt       string         `json:"updatedAt"`
-	Complained      bool           `json:"complained"`
-	Order           *OrderResponse `json:"order,omitempty"`
+	ID              uint    `json:"id"`
+	TrackingNumber  string  `json:"trackingNumber"`
+	OutboundBy      string  `json:"outboundBy"`
+	Expedition      string  `json:"expedition"`
+	ExpeditionSlug  string  `json:"expeditionSlug"`
+	ExpeditionColor string  `json:"expeditionColor"`
+	CreatedAt       string  `json:"createdAt"`
+	UpdatedAt       string  `json:"updatedAt"`
+	Complained      bool    `json:"complained"`
+	OrderCanceled   bool    `json:"orderCanceled"`
+	HandedOverAt    *string `json:"handedOverAt,omitempty"`
+
+	WeightGrams         *int     `json:"weightGrams,omitempty"`
+	LengthCm            *float64 `json:"lengthCm,omitempty"`
+	WidthCm             *float64 `json:"widthCm,omitempty"`
+	HeightCm            *float64 `json:"heightCm,omitempty"`
+	InvoicedWeightGrams *int     `json:"invoicedWeightGrams,omitempty"`
+	InvoicedAmount      *int     `json:"invoicedAmount,omitempty"`
+	InvoiceRef          string   `json:"invoiceRef,omitempty"`
+
+	Order *OrderResponse `json:"order,omitempty"`
+}
+
+// VolumetricWeightGrams returns the volumetric weight of the measured parcel, nil when a dimension was not captured
+func (o *Outbound) VolumetricWeightGrams() *int {
+	if o.LengthCm == nil || o.WidthCm == nil || o.HeightCm == nil {
+		return nil
+	}
+	grams := int(math.Ceil(*o.LengthCm * *o.WidthCm * *o.HeightCm * 1000 / VolumetricWeightDivisor))
+	return &grams
+}
+
+// ChargeableWeightGrams returns the weight a courier should bill for the measured parcel, the higher of the actual and volumetric weight.
+// Nil when nothing was measured.
+func (o *Outbound) ChargeableWeightGrams() *int {
+	volumetric := o.VolumetricWeightGrams()
+	switch {
+	case o.WeightGrams == nil:
+		return volumetric
+	case volumetric == nil || *o.WeightGrams >= *volumetric:
+		return o.WeightGrams
+	default:
+		return volumetric
+	}
 }
 
 // ToResponse converts an Outbound model to an OutboundResponse
@@ -45,6 +99,12 @@ func (o *Outbound) ToResponse() *OutboundResponse {
 		orderResponse = o.Order.ToOrderResponse()
 	}
 
+	var handedOverAt *string
+	if o.HandedOverAt != nil {
+		formatted := o.HandedOverAt.Format("02-01-2006 15:04:05")
+		handedOverAt = &formatted
+	}
+
 	return &OutboundResponse{
 		ID:              o.ID,
 		TrackingNumber:  o.TrackingNumber,
@@ -55,6 +115,17 @@ func (o *Outbound) ToResponse() *OutboundResponse {
 		CreatedAt:       o.CreatedAt.Format("02-01-2006 15:04:05"),
 		UpdatedAt:       o.UpdatedAt.Format("02-01-2006 15:04:05"),
 		Complained:      o.Complained,
-		Order:           orderResponse,
+		OrderCanceled:   o.OrderCanceled,
+		HandedOverAt:    handedOverAt,
+
+		WeightGrams:         o.WeightGrams,
+		LengthCm:            o.LengthCm,
+		WidthCm:             o.WidthCm,
+		HeightCm:            o.HeightCm,
+		InvoicedWeightGrams: o.InvoicedWeightGrams,
+		InvoicedAmount:      o.InvoicedAmount,
+		InvoiceRef:          o.InvoiceRef,
+
+		Order: orderResponse,
 	}
 }
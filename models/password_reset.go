@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// PasswordReset is a self-service password reset request delivered by email link or WhatsApp OTP
+type PasswordReset struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Channel   string     `gorm:"not null;type:varchar(20)" json:"channel"` // email or whatsapp
+	TokenHash string     `gorm:"not null;type:varchar(64);index" json:"-"` // hash of the emailed token or the OTP
+	Attempts  int        `gorm:"not null;default:0" json:"attempts"`       // wrong OTP attempts
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `gorm:"default:null" json:"used_at"`
+	RequestIP string     `gorm:"type:varchar(50)" json:"request_ip"`
+	CreatedAt time.Time  `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// IsUsable reports whether the reset has not been used, expired or locked by wrong attempts
+func (r *PasswordReset) IsUsable(now time.Time, maxAttempts int) bool {
+	return r.UsedAt == nil && now.Before(r.ExpiresAt) && r.Attempts < maxAttempts
+}
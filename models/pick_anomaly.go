@@ -0,0 +1,84 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Types of a pick anomaly
+const (
+	PickAnomalyFast = "fast" // confirmed faster than an item can be located and scanned, verification possibly skipped
+	PickAnomalySlow = "slow" // took unusually long to locate
+)
+
+// PickAnomaly records an item whose pick took anomalously short or long, measured from the previous confirmation
+// of the order (or its assignment for the first item) to the item's confirmation
+type PickAnomaly struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	OrderID          uint      `gorm:"not null;index" json:"order_id"`
+	OrderDetailID    uint      `gorm:"not null;uniqueIndex" json:"order_detail_id"`
+	TrackingNumber   string    `gorm:"type:varchar(100);index" json:"tracking_number"`
+	PickedBy         uint      `gorm:"not null;index" json:"picked_by"`
+	SKU              string    `gorm:"not null;type:varchar(255);index" json:"sku"`
+	Quantity         int       `gorm:"not null" json:"quantity"`
+	Type             string    `gorm:"not null;type:varchar(20);index" json:"type"`
+	Seconds          int       `gorm:"not null" json:"seconds"`
+	ThresholdSeconds int       `gorm:"not null" json:"threshold_seconds"` // minimum for the quantity on fast picks, maximum on slow picks
+	StartedAt        time.Time `gorm:"not null" json:"started_at"`
+	ItemPickedAt     time.Time `gorm:"not null" json:"item_picked_at"`
+	DetectedAt       time.Time `gorm:"not null;index" json:"detected_at"`
+	CreatedAt        time.Time `json:"created_at"`
+
+	Order    *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	PickUser *User  `gorm:"foreignKey:PickedBy" json:"pick_user,omitempty"`
+}
+
+type PickAnomalyResponse struct {
+	ID               uint    `json:"id"`
+	OrderID          uint    `json:"orderId"`
+	OrderDetailID    uint    `json:"orderDetailId"`
+	TrackingNumber   string  `json:"trackingNumber"`
+	PickerID         uint    `json:"pickerId"`
+	Picker           string  `json:"picker"`
+	SKU              string  `json:"sku"`
+	Quantity         int     `json:"quantity"`
+	Type             string  `json:"type"`
+	Seconds          int     `json:"seconds"`
+	SecondsPerUnit   float64 `json:"secondsPerUnit"`
+	ThresholdSeconds int     `json:"thresholdSeconds"`
+	StartedAt        string  `json:"startedAt"`
+	ItemPickedAt     string  `json:"itemPickedAt"`
+	DetectedAt       string  `json:"detectedAt"`
+}
+
+// ToResponse converts a PickAnomaly model to a PickAnomalyResponse
+func (pa *PickAnomaly) ToResponse() *PickAnomalyResponse {
+	// User visual handlers
+	var picker string
+	if pa.PickUser != nil {
+		picker = pa.PickUser.FullName
+	}
+
+	var secondsPerUnit float64
+	if pa.Quantity > 0 {
+		secondsPerUnit = math.Round(float64(pa.Seconds)/float64(pa.Quantity)*10) / 10
+	}
+
+	return &PickAnomalyResponse{
+		ID:               pa.ID,
+		OrderID:          pa.OrderID,
+		OrderDetailID:    pa.OrderDetailID,
+		TrackingNumber:   pa.TrackingNumber,
+		PickerID:         pa.PickedBy,
+		Picker:           picker,
+		SKU:              pa.SKU,
+		Quantity:         pa.Quantity,
+		Type:             pa.Type,
+		Seconds:          pa.Seconds,
+		SecondsPerUnit:   secondsPerUnit,
+		ThresholdSeconds: pa.ThresholdSeconds,
+		StartedAt:        pa.StartedAt.Format("02-01-2006 15:04:05"),
+		ItemPickedAt:     pa.ItemPickedAt.Format("02-01-2006 15:04:05"),
+		DetectedAt:       pa.DetectedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// MaxPickBatchOrders is the largest number of orders a picker can carry on one cart
+const MaxPickBatchOrders = 20
+
+// Pick batch statuses
+const (
+	PickBatchStatusPicking   = "picking"
+	PickBatchStatusCompleted = "completed"
+	PickBatchStatusCanceled  = "canceled"
+)
+
+// PickBatch groups orders assigned to one picker that are picked together onto a cart and sorted into per-order slots
+type PickBatch struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	BatchCode   string     `gorm:"uniqueIndex;not null;type:varchar(50)" json:"batch_code"`
+	PickerID    uint       `gorm:"not null;index" json:"picker_id"`
+	Status      string     `gorm:"not null;type:varchar(20);default:picking;index" json:"status"`
+	CompletedAt *time.Time `gorm:"default:null" json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Orders []PickBatchOrder `gorm:"foreignKey:PickBatchID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"orders,omitempty"`
+	Picker *User            `gorm:"foreignKey:PickerID" json:"picker,omitempty"`
+}
+
+// PickBatchOrder is an order on a pick cart, placed in its own cart slot
+type PickBatchOrder struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	PickBatchID uint       `gorm:"not null;index" json:"pick_batch_id"`
+	OrderID     uint       `gorm:"not null;index" json:"order_id"`
+	CartSlot    int        `gorm:"not null" json:"cart_slot"`
+	SortedAt    *time.Time `gorm:"default:null" json:"sorted_at"` // set once the picker confirms the slot holds the order's items
+
+	Order *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
+
+type PickBatchResponse struct {
+	ID           uint                     `json:"id"`
+	BatchCode    string                   `json:"batchCode"`
+	Picker       string                   `json:"picker"`
+	Status       string                   `json:"status"`
+	TotalOrders  int                      `json:"totalOrders"`
+	SortedOrders int                      `json:"sortedOrders"`
+	CompletedAt  *string                  `json:"completedAt,omitempty"`
+	CreatedAt    string                   `json:"createdAt"`
+	UpdatedAt    string                   `json:"updatedAt"`
+	Orders       []PickBatchOrderResponse `json:"orders"`
+}
+
+type PickBatchOrderResponse struct {
+	OrderID          uint    `json:"orderId"`
+	TrackingNumber   string  `json:"trackingNumber"`
+	ProcessingStatus string  `json:"processingStatus"`
+	CartSlot         int     `json:"cartSlot"`
+	Sorted           bool    `json:"sorted"`
+	SortedAt         *string `json:"sortedAt,omitempty"`
+}
+
+// ToResponse converts a PickBatch model to a PickBatchResponse
+func (pb *PickBatch) ToResponse() *PickBatchResponse {
+	// User visual handlers
+	var picker string
+	if pb.Picker != nil {
+		picker = pb.Picker.FullName
+	}
+
+	var completedAt *string
+	if pb.CompletedAt != nil {
+		formatted := pb.CompletedAt.Format("02-01-2006 15:04:05")
+		completedAt = &formatted
+	}
+
+	sortedOrders := 0
+	orders := make([]PickBatchOrderResponse, len(pb.Orders))
+	for i, batchOrder := range pb.Orders {
+		orders[i] = PickBatchOrderResponse{
+			OrderID:  batchOrder.OrderID,
+			CartSlot: batchOrder.CartSlot,
+			Sorted:   batchOrder.SortedAt != nil,
+		}
+		if batchOrder.Order != nil {
+			orders[i].TrackingNumber = batchOrder.Order.TrackingNumber
+			orders[i].ProcessingStatus = batchOrder.Order.ProcessingStatus
+		}
+		if batchOrder.SortedAt != nil {
+			formatted := batchOrder.SortedAt.Format("02-01-2006 15:04:05")
+			orders[i].SortedAt = &formatted
+			sortedOrders++
+		}
+	}
+
+	return &PickBatchResponse{
+		ID:           pb.ID,
+		BatchCode:    pb.BatchCode,
+		Picker:       picker,
+		Status:       pb.Status,
+		TotalOrders:  len(pb.Orders),
+		SortedOrders: sortedOrders,
+		CompletedAt:  completedAt,
+		CreatedAt:    pb.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:    pb.UpdatedAt.Format("02-01-2006 15:04:05"),
+		Orders:       orders,
+	}
+}
@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// PickShortage records an item a picker could not find in stock while picking an order
+type PickShortage struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	OrderID          uint       `gorm:"not null;index" json:"order_id"`
+	OrderDetailID    uint       `gorm:"not null;index" json:"order_detail_id"`
+	TrackingNumber   string     `gorm:"not null;type:varchar(255);index" json:"tracking_number"`
+	SKU              string     `gorm:"not null;type:varchar(255);index" json:"sku"`
+	RequiredQuantity int        `gorm:"not null" json:"required_quantity"`
+	PickedQuantity   int        `gorm:"not null;default:0" json:"picked_quantity"`
+	Reason           string     `gorm:"not null;type:text" json:"reason"`
+	Status           string     `gorm:"not null;type:varchar(20);default:open;index" json:"status"` // open or resolved
+	Resolution       string     `gorm:"type:varchar(20)" json:"resolution"`                         // how a resolved shortage was resolved, e.g. substituted
+	SubstituteSKU    string     `gorm:"type:varchar(255)" json:"substitute_sku"`                    // SKU picked instead, substitution only
+	RestockRefType   string     `gorm:"type:varchar(50)" json:"restock_ref_type"`                   // stock receipt that fulfilled the backorder, restock only
+	RestockRefID     *uint      `gorm:"default:null" json:"restock_ref_id"`
+	DeclaredBy       uint       `gorm:"not null" json:"declared_by"`
+	ResolvedBy       *uint      `gorm:"default:null" json:"resolved_by"`
+	ResolvedAt       *time.Time `gorm:"default:null" json:"resolved_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	Order       *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	DeclareUser *User  `gorm:"foreignKey:DeclaredBy" json:"declare_user,omitempty"`
+	ResolveUser *User  `gorm:"foreignKey:ResolvedBy" json:"resolve_user,omitempty"`
+}
+
+type PickShortageResponse struct {
+	ID               uint    `json:"id"`
+	OrderID          uint    `json:"orderId"`
+	TrackingNumber   string  `json:"trackingNumber"`
+	SKU              string  `json:"sku"`
+	RequiredQuantity int     `json:"requiredQuantity"`
+	PickedQuantity   int     `json:"pickedQuantity"`
+	ShortQuantity    int     `json:"shortQuantity"`
+	Reason           string  `json:"reason"`
+	Status           string  `json:"status"`
+	Resolution       string  `json:"resolution,omitempty"`
+	SubstituteSKU    string  `json:"substituteSku,omitempty"`
+	AgingHours       float64 `json:"agingHours"`
+	DeclaredBy       string  `json:"declaredBy"`
+	ResolvedBy       *string `json:"resolvedBy,omitempty"`
+	ResolvedAt       *string `json:"resolvedAt,omitempty"`
+	CreatedAt        string  `json:"createdAt"`
+	UpdatedAt        string  `json:"updatedAt"`
+}
+
+// Aging returns how long the shortage has been open, or how long it took to resolve
+func (ps *PickShortage) Aging(now time.Time) time.Duration {
+	if ps.ResolvedAt != nil {
+		return ps.ResolvedAt.Sub(ps.CreatedAt)
+	}
+	return now.Sub(ps.CreatedAt)
+}
+
+// ToResponse converts a PickShortage model to a PickShortageResponse
+func (ps *PickShortage) ToResponse() *PickShortageResponse {
+	// User visual handlers
+	var declaredBy string
+	if ps.DeclareUser != nil {
+		declaredBy = ps.DeclareUser.FullName
+	}
+	var resolvedBy *string
+	if ps.ResolveUser != nil {
+		resolvedBy = &ps.ResolveUser.FullName
+	}
+
+	var resolvedAt *string
+	if ps.ResolvedAt != nil {
+		formatted := ps.ResolvedAt.Format("02-01-2006 15:04:05")
+		resolvedAt = &formatted
+	}
+
+	agingHours := float64(int(ps.Aging(time.Now()).Hours()*10)) / 10
+
+	return &PickShortageResponse{
+		ID:               ps.ID,
+		OrderID:          ps.OrderID,
+		TrackingNumber:   ps.TrackingNumber,
+		SKU:              ps.SKU,
+		RequiredQuantity: ps.RequiredQuantity,
+		PickedQuantity:   ps.PickedQuantity,
+		ShortQuantity:    ps.RequiredQuantity - ps.PickedQuantity,
+		Reason:           ps.Reason,
+		Status:           ps.Status,
+		Resolution:       ps.Resolution,
+		SubstituteSKU:    ps.SubstituteSKU,
+		AgingHours:       agingHours,
+		DeclaredBy:       declaredBy,
+		ResolvedBy:       resolvedBy,
+		ResolvedAt:       resolvedAt,
+		CreatedAt:        ps.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:        ps.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
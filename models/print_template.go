@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// Printed document types a store can customize
+const (
+	PrintDocumentLabel       = "label"        // shipping label
+	PrintDocumentInvoice     = "invoice"      // invoice of the order
+	PrintDocumentPickingList = "picking_list" // picking list of the order in a pick batch
+)
+
+var printDocumentTypes = []string{PrintDocumentLabel, PrintDocumentInvoice, PrintDocumentPickingList}
+
+// PrintDocumentTypes returns the printed document types a store can customize
+func PrintDocumentTypes() []string {
+	return printDocumentTypes
+}
+
+// IsValidPrintDocumentType reports whether documentType is a printed document type a store can customize
+func IsValidPrintDocumentType(documentType string) bool {
+	for _, known := range printDocumentTypes {
+		if known == documentType {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintTemplate customizes a printed document of a store, documents of stores without one use the generic layout
+type PrintTemplate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	StoreID      uint      `gorm:"not null;uniqueIndex:idx_print_template_store_document" json:"store_id"`
+	DocumentType string    `gorm:"not null;type:varchar(20);uniqueIndex:idx_print_template_store_document" json:"document_type"`
+	HeaderText   string    `gorm:"type:text" json:"header_text"` // lines printed at the top of the document, below the logo
+	FooterText   string    `gorm:"type:text" json:"footer_text"` // lines printed at the bottom, replacing the store's invoice footer
+	ShowLogo     bool      `gorm:"not null" json:"show_logo"`
+	UpdatedBy    *uint     `gorm:"default:null" json:"updated_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Store       *Store `gorm:"foreignKey:StoreID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"-"`
+	UpdatedUser *User  `gorm:"foreignKey:UpdatedBy" json:"updated_user,omitempty"`
+}
+
+// PrintTemplateResponse represents the print template data returned in API responses
+type PrintTemplateResponse struct {
+	ID           uint    `json:"id"`
+	StoreID      uint    `json:"storeId"`
+	DocumentType string  `json:"documentType"`
+	HeaderText   string  `json:"headerText"`
+	FooterText   string  `json:"footerText"`
+	ShowLogo     bool    `json:"showLogo"`
+	UpdatedBy    *string `json:"updatedBy"`
+	CreatedAt    string  `json:"createdAt"`
+	UpdatedAt    string  `json:"updatedAt"`
+}
+
+// ToResponse converts a PrintTemplate model to a PrintTemplateResponse
+func (pt *PrintTemplate) ToResponse() *PrintTemplateResponse {
+	// User visual handlers
+	var updatedBy *string
+	if pt.UpdatedUser != nil {
+		updatedBy = &pt.UpdatedUser.FullName
+	}
+
+	return &PrintTemplateResponse{
+		ID:           pt.ID,
+		StoreID:      pt.StoreID,
+		DocumentType: pt.DocumentType,
+		HeaderText:   pt.HeaderText,
+		FooterText:   pt.FooterText,
+		ShowLogo:     pt.ShowLogo,
+		UpdatedBy:    updatedBy,
+		CreatedAt:    pt.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:    pt.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -3,41 +3,74 @@ package models
 import "time"
 
 type Product struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	SKU       string    `gorm:"uniqueIndex;not null;type:varchar(255)" json:"sku"`
-	Name      string    `gorm:"not null;type:varchar(255)" json:"name"`
-	Image     string    `gorm:"type:text" json:"image"`
-	Variant   string    `gorm:"type:varchar(100)" json:"variant"`
-	Location  string    `gorm:"type:varchar(100)" json:"location"`
-	NeedCheck bool      `gorm:"default:false" json:"need_check"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	SKU       string `gorm:"uniqueIndex;not null;type:varchar(255)" json:"sku"`
+	Name      string `gorm:"not null;type:varchar(255)" json:"name"`
+	Image     string `gorm:"type:text" json:"image"`
+	Variant   string `gorm:"type:varchar(100)" json:"variant"`
+	Location  string `gorm:"type:varchar(100)" json:"location"`
+	Barcode   string `gorm:"type:varchar(100);index" json:"barcode"` // barcode printed on the unit when it differs from the SKU
+	NeedCheck bool   `gorm:"default:false" json:"need_check"`
+	// Units carry a serial number (IMEI or SN) that is captured at QC
+	SerialRequired bool `gorm:"not null;default:false" json:"serial_required"`
+	// Packed unit weight and dimensions, rolled up per order for courier bookings, nil when not measured
+	WeightGrams *int     `gorm:"default:null" json:"weight_grams"`
+	LengthCm    *float64 `gorm:"default:null" json:"length_cm"`
+	WidthCm     *float64 `gorm:"default:null" json:"width_cm"`
+	HeightCm    *float64 `gorm:"default:null" json:"height_cm"`
+	// Skill pickers need to be assigned orders containing the product, e.g. fragile goods, nil when none
+	RequiredSkillID *uint     `gorm:"default:null;index" json:"required_skill_id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 // ProductResponse represents the product data returned in API responses
 type ProductResponse struct {
-	ID        uint   `json:"id"`
-	SKU       string `json:"sku"`
-	Name      string `json:"name"`
-	Image     string `json:"image"`
-	Variant   string `json:"variant"`
-	NeedCheck bool   `json:"needCheck"`
-	Location  string `json:"location"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	ID              uint     `json:"id"`
+	SKU             string   `json:"sku"`
+	Name            string   `json:"name"`
+	Image           string   `json:"image"`
+	Variant         string   `json:"variant"`
+	NeedCheck       bool     `json:"needCheck"`
+	SerialRequired  bool     `json:"serialRequired"`
+	Location        string   `json:"location"`
+	Barcode         string   `json:"barcode"`
+	WeightGrams     *int     `json:"weightGrams,omitempty"`
+	LengthCm        *float64 `json:"lengthCm,omitempty"`
+	WidthCm         *float64 `json:"widthCm,omitempty"`
+	HeightCm        *float64 `json:"heightCm,omitempty"`
+	RequiredSkillID *uint    `json:"requiredSkillId"`
+	CreatedAt       string   `json:"createdAt"`
+	UpdatedAt       string   `json:"updatedAt"`
+}
+
+// VolumeCm3 returns the volume of a packed unit, nil when a dimension is not set
+func (p *Product) VolumeCm3() *float64 {
+	if p.LengthCm == nil || p.WidthCm == nil || p.HeightCm == nil {
+		return nil
+	}
+	volume := *p.LengthCm * *p.WidthCm * *p.HeightCm
+	return &volume
 }
 
 // ToResponse converts a Product model to a ProductResponse
 func (p *Product) ToResponse() *ProductResponse {
 	return &ProductResponse{
-		ID:        p.ID,
-		SKU:       p.SKU,
-		Name:      p.Name,
-		Image:     p.Image,
-		Variant:   p.Variant,
-		Location:  p.Location,
-		NeedCheck: p.NeedCheck,
-		CreatedAt: p.CreatedAt.Format("02-01-2006 15:04:05"),
-		UpdatedAt: p.UpdatedAt.Format("02-01-2006 15:04:05"),
+		ID:              p.ID,
+		SKU:             p.SKU,
+		Name:            p.Name,
+		Image:           p.Image,
+		Variant:         p.Variant,
+		Location:        p.Location,
+		Barcode:         p.Barcode,
+		NeedCheck:       p.NeedCheck,
+		SerialRequired:  p.SerialRequired,
+		WeightGrams:     p.WeightGrams,
+		LengthCm:        p.LengthCm,
+		WidthCm:         p.WidthCm,
+		HeightCm:        p.HeightCm,
+		RequiredSkillID: p.RequiredSkillID,
+		CreatedAt:       p.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:       p.UpdatedAt.Format("02-01-2006 15:04:05"),
 	}
 }
@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// ProductDisplayName is the curated name of an SKU shown on picking lists and QC screens in a language, instead of the marketplace product name
+type ProductDisplayName struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SKU       string    `gorm:"not null;type:varchar(255);uniqueIndex:idx_product_display_name_sku_language" json:"sku"`
+	Language  string    `gorm:"not null;type:varchar(5);uniqueIndex:idx_product_display_name_sku_language" json:"language"`
+	Name      string    `gorm:"not null;type:varchar(255)" json:"name"`
+	CreatedBy uint      `gorm:"not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	CreateUser *User `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+}
+
+type ProductDisplayNameResponse struct {
+	ID        uint   `json:"id"`
+	SKU       string `json:"sku"`
+	Language  string `json:"language"`
+	Name      string `json:"name"`
+	CreatedBy string `json:"createdBy"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// ToResponse converts a ProductDisplayName model to a ProductDisplayNameResponse
+func (pdn *ProductDisplayName) ToResponse() *ProductDisplayNameResponse {
+	// User visual handlers
+	var createdBy string
+	if pdn.CreateUser != nil {
+		createdBy = pdn.CreateUser.FullName
+	}
+
+	return &ProductDisplayNameResponse{
+		ID:        pdn.ID,
+		SKU:       pdn.SKU,
+		Language:  pdn.Language,
+		Name:      pdn.Name,
+		CreatedBy: createdBy,
+		CreatedAt: pdn.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt: pdn.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// ProductVariantMapping maps a marketplace variant to its canonical variant, for one SKU or for every SKU when SKU is empty.
+// RawVariant is stored as its normalized key so "Merah, XL" and "merah/xl" map alike.
+type ProductVariantMapping struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	SKU        string    `gorm:"not null;default:'';type:varchar(255);uniqueIndex:idx_product_variant_mapping_raw" json:"sku"`
+	RawVariant string    `gorm:"not null;type:varchar(255);uniqueIndex:idx_product_variant_mapping_raw" json:"raw_variant"`
+	Variant    string    `gorm:"not null;type:varchar(100)" json:"variant"`
+	CreatedBy  uint      `gorm:"not null" json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	CreateUser *User `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+}
+
+type ProductVariantMappingResponse struct {
+	ID         uint   `json:"id"`
+	SKU        string `json:"sku,omitempty"`
+	RawVariant string `json:"rawVariant"`
+	Variant    string `json:"variant"`
+	CreatedBy  string `json:"createdBy"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+// ToResponse converts a ProductVariantMapping model to a ProductVariantMappingResponse
+func (pvm *ProductVariantMapping) ToResponse() *ProductVariantMappingResponse {
+	// User visual handlers
+	var createdBy string
+	if pvm.CreateUser != nil {
+		createdBy = pvm.CreateUser.FullName
+	}
+
+	return &ProductVariantMappingResponse{
+		ID:         pvm.ID,
+		SKU:        pvm.SKU,
+		RawVariant: pvm.RawVariant,
+		Variant:    pvm.Variant,
+		CreatedBy:  createdBy,
+		CreatedAt:  pvm.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:  pvm.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
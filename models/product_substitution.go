@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// ProductSubstitution allows an order item of SKU to be fulfilled by SubstituteSKU when SKU is short, with coordinator approval
+type ProductSubstitution struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	SKU           string    `gorm:"not null;type:varchar(255);uniqueIndex:idx_product_substitution_pair" json:"sku"`
+	SubstituteSKU string    `gorm:"not null;type:varchar(255);uniqueIndex:idx_product_substitution_pair" json:"substitute_sku"`
+	IsActive      bool      `gorm:"default:true;index" json:"is_active"`
+	Notes         string    `gorm:"type:text" json:"notes"`
+	CreatedBy     uint      `gorm:"not null" json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	CreateUser *User `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+}
+
+type ProductSubstitutionResponse struct {
+	ID            uint   `json:"id"`
+	SKU           string `json:"sku"`
+	SubstituteSKU string `json:"substituteSku"`
+	IsActive      bool   `json:"isActive"`
+	Notes         string `json:"notes,omitempty"`
+	CreatedBy     string `json:"createdBy"`
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
+}
+
+// ToResponse converts a ProductSubstitution model to a ProductSubstitutionResponse
+func (ps *ProductSubstitution) ToResponse() *ProductSubstitutionResponse {
+	// User visual handlers
+	var createdBy string
+	if ps.CreateUser != nil {
+		createdBy = ps.CreateUser.FullName
+	}
+
+	return &ProductSubstitutionResponse{
+		ID:            ps.ID,
+		SKU:           ps.SKU,
+		SubstituteSKU: ps.SubstituteSKU,
+		IsActive:      ps.IsActive,
+		Notes:         ps.Notes,
+		CreatedBy:     createdBy,
+		CreatedAt:     ps.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:     ps.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
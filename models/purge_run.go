@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// PurgeRun is the audit entry of a single data retention purge run
+type PurgeRun struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Trigger     string    `gorm:"not null;type:varchar(20)" json:"trigger"` // manual or scheduled
+	TriggeredBy *uint     `gorm:"default:null" json:"triggered_by"`
+	DryRun      bool      `gorm:"default:false" json:"dry_run"`
+	Status      string    `gorm:"not null;type:varchar(20)" json:"status"` // completed or failed
+	Error       string    `gorm:"type:text" json:"error"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Details     []PurgeRunDetail `gorm:"foreignKey:PurgeRunID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"details,omitempty"`
+	TriggerUser *User            `gorm:"foreignKey:TriggeredBy" json:"trigger_user,omitempty"`
+}
+
+// PurgeRunDetail records the rows affected by a purge run for a single data category
+type PurgeRunDetail struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	PurgeRunID    uint      `gorm:"not null;index" json:"purge_run_id"`
+	Category      string    `gorm:"not null;type:varchar(50)" json:"category"`
+	Action        string    `gorm:"not null;type:varchar(20)" json:"action"` // anonymize or delete
+	RetentionDays int       `gorm:"not null" json:"retention_days"`
+	Cutoff        time.Time `json:"cutoff"`
+	AffectedRows  int64     `gorm:"not null;default:0" json:"affected_rows"`
+
+	PurgeRun *PurgeRun `gorm:"foreignKey:PurgeRunID" json:"-"`
+}
+
+// PurgeRunResponse represents the purge run data returned in API responses
+type PurgeRunResponse struct {
+	ID          uint                     `json:"id"`
+	Trigger     string                   `json:"trigger"`
+	TriggeredBy *string                  `json:"triggeredBy"`
+	DryRun      bool                     `json:"dryRun"`
+	Status      string                   `json:"status"`
+	Error       string                   `json:"error,omitempty"`
+	CreatedAt   string                   `json:"createdAt"`
+	Details     []PurgeRunDetailResponse `json:"details"`
+}
+
+type PurgeRunDetailResponse struct {
+	Category      string `json:"category"`
+	Action        string `json:"action"`
+	RetentionDays int    `json:"retentionDays"`
+	Cutoff        string `json:"cutoff"`
+	AffectedRows  int64  `json:"affectedRows"`
+}
+
+// ToResponse converts a PurgeRun model to a PurgeRunResponse
+func (pr *PurgeRun) ToResponse() *PurgeRunResponse {
+	// User visual handlers
+	var triggeredBy *string
+	if pr.TriggerUser != nil {
+		triggeredBy = &pr.TriggerUser.FullName
+	}
+
+	// Convert purge run details
+	details := make([]PurgeRunDetailResponse, len(pr.Details))
+	for i, detail := range pr.Details {
+		details[i] = PurgeRunDetailResponse{
+			Category:      detail.Category,
+			Action:        detail.Action,
+			RetentionDays: detail.RetentionDays,
+			Cutoff:        detail.Cutoff.Format("02-01-2006 15:04:05"),
+			AffectedRows:  detail.AffectedRows,
+		}
+	}
+
+	return &PurgeRunResponse{
+		ID:          pr.ID,
+		Trigger:     pr.Trigger,
+		TriggeredBy: triggeredBy,
+		DryRun:      pr.DryRun,
+		Status:      pr.Status,
+		Error:       pr.Error,
+		CreatedAt:   pr.CreatedAt.Format("02-01-2006 15:04:05"),
+		Details:     details,
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// QC mismatch types
+const (
+	QCMismatchWrongSKU         = "wrong_sku"         // scanned SKU is not part of the order
+	QCMismatchQuantityMismatch = "quantity_mismatch" // validated quantity differs from the order quantity
+	QCMismatchOverScan         = "over_scan"         // SKU scanned more times than ordered
+)
+
+// QCMismatch records a mispick caught at QC, used to find problem SKUs and bins
+type QCMismatch struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	Lane             string    `gorm:"not null;type:varchar(20)" json:"lane"` // ribbon or online
+	OrderID          *uint     `gorm:"default:null;index" json:"order_id"`
+	TrackingNumber   string    `gorm:"not null;type:varchar(255);index" json:"tracking_number"`
+	SKU              string    `gorm:"not null;type:varchar(255);index" json:"sku"`
+	Type             string    `gorm:"not null;type:varchar(30)" json:"type"`
+	ExpectedQuantity int       `gorm:"not null;default:0" json:"expected_quantity"`
+	ScannedQuantity  int       `gorm:"not null;default:0" json:"scanned_quantity"`
+	PickedBy         *uint     `gorm:"default:null" json:"picked_by"`
+	RecordedBy       uint      `gorm:"not null" json:"recorded_by"`
+	QCStationID      *uint     `gorm:"default:null;index" json:"qc_station_id"` // station of the QC the mismatch was caught at
+	CreatedAt        time.Time `gorm:"index" json:"created_at"`
+
+	PickUser   *User `gorm:"foreignKey:PickedBy" json:"pick_user,omitempty"`
+	RecordUser *User `gorm:"foreignKey:RecordedBy" json:"record_user,omitempty"`
+}
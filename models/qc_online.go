@@ -6,13 +6,16 @@ type QCOnline struct {
 	ID             uint      `gorm:"primaryKey" json:"id"`
 	TrackingNumber string    `gorm:"uniqueIndex;not null;type:varchar(100)" json:"tracking_number"`
 	QCBy           uint      `gorm:"not null" json:"qc_by"`
-	Status         string    `gorm:"default:'in_progress';type:varchar(50)" json:"status"`
+	Status         QCStatus  `gorm:"default:'in_progress';type:varchar(50);check:chk_qc_onlines_status,status IN ('in_progress', 'pending', 'completed', 'canceled')" json:"status"` // kept in sync with the QCStatus constants
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 	Complained     bool      `gorm:"default:false" json:"complained"`
+	OrderCanceled  bool      `gorm:"default:false;index" json:"order_canceled"` // the order was canceled after QC started
+	QCStationID    *uint     `gorm:"default:null;index" json:"qc_station_id"`   // station the QC was performed at
 
 	QCOnlineDetails []QCOnlineDetail `gorm:"foreignKey:QCOnlineID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"qc_online_details,omitempty"`
 	QCUser          *User            `gorm:"foreignKey:QCBy" json:"qc_user,omitempty"`
+	QCStation       *QCStation       `gorm:"foreignKey:QCStationID" json:"qc_station,omitempty"`
 	Order           *Order           `gorm:"-" json:"order,omitempty"`
 }
 
@@ -35,6 +38,9 @@ type QCOnlineResponse struct {
 	CreatedAt      string                   `json:"createdAt"`
 	UpdatedAt      string                   `json:"updatedAt"`
 	Complained     bool                     `json:"complained"`
+	OrderCanceled  bool                     `json:"orderCanceled"`
+	Station        string                   `json:"station,omitempty"`
+	PackingNotes   []string                 `json:"packingNotes,omitempty"` // gift message, handling note and item notes of the order
 	Details        []QCOnlineDetailResponse `json:"details,omitempty"`
 	Order          *OrderResponse           `json:"order,omitempty"`
 }
@@ -67,8 +73,8 @@ func (qcr *QCOnline) ToResponse() *QCOnlineResponse {
 		status = "In Progress"
 	case "completed":
 		status = "Completed"
-	case "cancelled":
-		status = "Cancelled"
+	case "canceled":
+		status = "Canceled"
 	case "pending":
 		status = "Pending"
 	}
@@ -79,10 +85,18 @@ func (qcr *QCOnline) ToResponse() *QCOnlineResponse {
 		qcBy = qcr.QCUser.FullName
 	}
 
+	// Station visual handlers
+	var station string
+	if qcr.QCStation != nil {
+		station = qcr.QCStation.StationCode
+	}
+
 	// Include Order response if tracking number exists in Order
 	var orderResponse *OrderResponse
+	var packingNotes []string
 	if qcr.Order != nil {
 		orderResponse = qcr.Order.ToOrderResponse()
+		packingNotes = qcr.Order.PackingNotes()
 	}
 
 	return &QCOnlineResponse{
@@ -93,6 +107,9 @@ func (qcr *QCOnline) ToResponse() *QCOnlineResponse {
 		CreatedAt:      qcr.CreatedAt.Format("02-01-2006 15:04:05"),
 		UpdatedAt:      qcr.UpdatedAt.Format("02-01-2006 15:04:05"),
 		Complained:     qcr.Complained,
+		OrderCanceled:  qcr.OrderCanceled,
+		Station:        station,
+		PackingNotes:   packingNotes,
 		Details:        details,
 		Order:          orderResponse,
 	}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// QCParcelPhoto is the photo of a packed parcel with its label visible, captured when a QC Ribbon or QC Online is completed
+type QCParcelPhoto struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Lane           string    `gorm:"not null;type:varchar(20);uniqueIndex:idx_qc_parcel_photo_qc" json:"lane"` // ribbon or online
+	QCID           uint      `gorm:"not null;uniqueIndex:idx_qc_parcel_photo_qc" json:"qc_id"`
+	TrackingNumber string    `gorm:"not null;index;type:varchar(100)" json:"tracking_number"`
+	Photo          string    `gorm:"not null;type:text" json:"photo"` // base64 image data URL, same storage as the handover driver photo
+	CapturedBy     uint      `gorm:"not null" json:"captured_by"`
+	PackingNotes   bool      `gorm:"not null;default:false" json:"packing_notes"` // the order had a gift message, handling note or item notes to pack by
+	CreatedAt      time.Time `json:"created_at"`
+
+	CaptureUser *User `gorm:"foreignKey:CapturedBy" json:"capture_user,omitempty"`
+}
+
+// QCParcelPhotoResponse represents the QC parcel photo data returned in API responses
+type QCParcelPhotoResponse struct {
+	ID             uint   `json:"id"`
+	Lane           string `json:"lane"`
+	QCID           uint   `json:"qcId"`
+	TrackingNumber string `json:"trackingNumber"`
+	Photo          string `json:"photo"`
+	CapturedBy     string `json:"capturedBy"`
+	PackingNotes   bool   `json:"packingNotes"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// ToResponse converts a QCParcelPhoto model to a QCParcelPhotoResponse
+func (qpp *QCParcelPhoto) ToResponse() *QCParcelPhotoResponse {
+	// User visual handlers
+	var capturedBy string
+	if qpp.CaptureUser != nil {
+		capturedBy = qpp.CaptureUser.FullName
+	}
+
+	return &QCParcelPhotoResponse{
+		ID:             qpp.ID,
+		Lane:           qpp.Lane,
+		QCID:           qpp.QCID,
+		TrackingNumber: qpp.TrackingNumber,
+		Photo:          qpp.Photo,
+		CapturedBy:     capturedBy,
+		PackingNotes:   qpp.PackingNotes,
+		CreatedAt:      qpp.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// QCStation is a physical QC desk working a single lane, claimed by one QC user at a time
+type QCStation struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	StationCode string     `gorm:"uniqueIndex;not null;type:varchar(50)" json:"station_code"`
+	Lane        string     `gorm:"not null;type:varchar(20);index" json:"lane"` // ribbon or online
+	Printer     string     `gorm:"type:varchar(100)" json:"printer"`            // label printer attached to the station
+	IsActive    bool       `gorm:"default:true" json:"is_active"`
+	ClaimedBy   *uint      `gorm:"default:null;uniqueIndex" json:"claimed_by"` // a user works one station at a time
+	ClaimedAt   *time.Time `gorm:"default:null" json:"claimed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	ClaimUser *User `gorm:"foreignKey:ClaimedBy" json:"claim_user,omitempty"`
+}
+
+// QCStationResponse represents the QC station data returned in API responses
+type QCStationResponse struct {
+	ID          uint    `json:"id"`
+	StationCode string  `json:"stationCode"`
+	Lane        string  `json:"lane"`
+	Printer     string  `json:"printer"`
+	IsActive    bool    `json:"isActive"`
+	ClaimedBy   *string `json:"claimedBy"`
+	ClaimedAt   *string `json:"claimedAt"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+// ToResponse converts a QCStation model to a QCStationResponse
+func (qs *QCStation) ToResponse() *QCStationResponse {
+	// User visual handlers
+	var claimedBy *string
+	if qs.ClaimUser != nil {
+		claimedBy = &qs.ClaimUser.FullName
+	}
+
+	var claimedAt *string
+	if qs.ClaimedAt != nil {
+		formatted := qs.ClaimedAt.Format("02-01-2006 15:04:05")
+		claimedAt = &formatted
+	}
+
+	return &QCStationResponse{
+		ID:          qs.ID,
+		StationCode: qs.StationCode,
+		Lane:        qs.Lane,
+		Printer:     qs.Printer,
+		IsActive:    qs.IsActive,
+		ClaimedBy:   claimedBy,
+		ClaimedAt:   claimedAt,
+		CreatedAt:   qs.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:   qs.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// QCVoid records a voided QC Ribbon or QC Online together with the approving coordinator
+type QCVoid struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	Lane           string    `gorm:"not null;type:varchar(20)" json:"lane"` // ribbon or online
+	QCID           uint      `gorm:"not null" json:"qc_id"`
+	TrackingNumber string    `gorm:"not null;index;type:varchar(100)" json:"tracking_number"`
+	PreviousStatus string    `gorm:"type:varchar(50)" json:"previous_status"`
+	QCBy           uint      `gorm:"not null" json:"qc_by"`
+	QCStationID    *uint     `gorm:"default:null;index" json:"qc_station_id"`
+	Reason         string    `gorm:"not null;type:text" json:"reason"`
+	RequestedBy    uint      `gorm:"not null" json:"requested_by"`
+	ApprovedBy     uint      `gorm:"not null" json:"approved_by"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	QCUser      *User `gorm:"foreignKey:QCBy" json:"qc_user,omitempty"`
+	RequestUser *User `gorm:"foreignKey:RequestedBy" json:"request_user,omitempty"`
+	ApproveUser *User `gorm:"foreignKey:ApprovedBy" json:"approve_user,omitempty"`
+}
+
+// QCVoidResponse represents the QC void data returned in API responses
+type QCVoidResponse struct {
+	ID             uint   `json:"id"`
+	Lane           string `json:"lane"`
+	QCID           uint   `json:"qcId"`
+	TrackingNumber string `json:"trackingNumber"`
+	PreviousStatus string `json:"previousStatus"`
+	QCBy           string `json:"qcBy"`
+	Reason         string `json:"reason"`
+	RequestedBy    string `json:"requestedBy"`
+	ApprovedBy     string `json:"approvedBy"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// ToResponse converts a QCVoid model to a QCVoidResponse
+func (qv *QCVoid) ToResponse() *QCVoidResponse {
+	// User visual handlers
+	var qcBy, requestedBy, approvedBy string
+	if qv.QCUser != nil {
+		qcBy = qv.QCUser.FullName
+	}
+	if qv.RequestUser != nil {
+		requestedBy = qv.RequestUser.FullName
+	}
+	if qv.ApproveUser != nil {
+		approvedBy = qv.ApproveUser.FullName
+	}
+
+	return &QCVoidResponse{
+		ID:             qv.ID,
+		Lane:           qv.Lane,
+		QCID:           qv.QCID,
+		TrackingNumber: qv.TrackingNumber,
+		PreviousStatus: qv.PreviousStatus,
+		QCBy:           qcBy,
+		Reason:         qv.Reason,
+		RequestedBy:    requestedBy,
+		ApprovedBy:     approvedBy,
+		CreatedAt:      qv.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Kinds of order references that can be aliased
+const (
+	ReferenceKindChannel = "channel"
+	ReferenceKindStore   = "store"
+)
+
+// IsValidReferenceKind reports whether kind is a known order reference kind
+func IsValidReferenceKind(kind string) bool {
+	return kind == ReferenceKindChannel || kind == ReferenceKindStore
+}
+
+// ReferenceAlias maps a channel or store name arriving on orders to an existing channel or store
+type ReferenceAlias struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Kind        string    `gorm:"not null;type:varchar(20);uniqueIndex:idx_reference_alias" json:"kind"`   // channel or store
+	Alias       string    `gorm:"not null;type:varchar(100);uniqueIndex:idx_reference_alias" json:"alias"` // normalized with NormalizeReferenceAlias
+	ReferenceID uint      `gorm:"not null;index" json:"reference_id"`                                      // channel or store ID
+	CreatedBy   *uint     `gorm:"default:null" json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	CreateUser *User `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+}
+
+// NormalizeReferenceAlias returns the form aliases are stored and matched in, case and spacing are ignored
+func NormalizeReferenceAlias(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// ReferenceAliasResponse represents the reference alias data returned in API responses
+type ReferenceAliasResponse struct {
+	ID            uint   `json:"id"`
+	Kind          string `json:"kind"`
+	Alias         string `json:"alias"`
+	ReferenceID   uint   `json:"referenceId"`
+	ReferenceName string `json:"referenceName"` // channel or store name orders are mapped to
+	CreatedBy     string `json:"createdBy,omitempty"`
+	CreatedAt     string `json:"createdAt"`
+}
+
+// ToResponse converts a ReferenceAlias model to a ReferenceAliasResponse
+func (ra *ReferenceAlias) ToResponse(referenceName string) *ReferenceAliasResponse {
+	// User visual handler
+	var createdBy string
+	if ra.CreateUser != nil {
+		createdBy = ra.CreateUser.FullName
+	}
+
+	return &ReferenceAliasResponse{
+		ID:            ra.ID,
+		Kind:          ra.Kind,
+		Alias:         ra.Alias,
+		ReferenceID:   ra.ReferenceID,
+		ReferenceName: referenceName,
+		CreatedBy:     createdBy,
+		CreatedAt:     ra.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// ReportAccessDenial is the audit record of a user pulling a report their roles are not allowed to access
+type ReportAccessDenial struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Report    string    `gorm:"not null;type:varchar(50);index" json:"report"` // report key of the access policy
+	Method    string    `gorm:"not null;type:varchar(10)" json:"method"`
+	Path      string    `gorm:"not null;type:text" json:"path"`
+	Roles     string    `gorm:"type:varchar(255)" json:"roles"` // comma separated roles of the user at the time
+	IPAddress string    `gorm:"type:varchar(50)" json:"ip_address"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// ReportAccessDenialResponse represents the report access denial data returned in API responses
+type ReportAccessDenialResponse struct {
+	ID        uint   `json:"id"`
+	UserID    uint   `json:"userId"`
+	Username  string `json:"username"`
+	User      string `json:"user"`
+	Report    string `json:"report"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Roles     string `json:"roles"`
+	IPAddress string `json:"ipAddress"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ToResponse converts a ReportAccessDenial model to a ReportAccessDenialResponse
+func (rad *ReportAccessDenial) ToResponse() *ReportAccessDenialResponse {
+	// User visual handlers
+	var username, user string
+	if rad.User != nil {
+		username = rad.User.Username
+		user = rad.User.FullName
+	}
+
+	return &ReportAccessDenialResponse{
+		ID:        rad.ID,
+		UserID:    rad.UserID,
+		Username:  username,
+		User:      user,
+		Report:    rad.Report,
+		Method:    rad.Method,
+		Path:      rad.Path,
+		Roles:     rad.Roles,
+		IPAddress: rad.IPAddress,
+		CreatedAt: rad.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
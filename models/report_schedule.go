@@ -0,0 +1,147 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Report schedule frequencies
+const (
+	ReportFrequencyNone    = "none" // saved as a favorite only, never emailed
+	ReportFrequencyDaily   = "daily"
+	ReportFrequencyWeekly  = "weekly"  // every Monday
+	ReportFrequencyMonthly = "monthly" // on the first day of the month
+)
+
+// Report schedule delivery statuses
+const (
+	ReportDeliverySent   = "sent"
+	ReportDeliveryFailed = "failed"
+)
+
+// ReportSchedule is a saved report configuration (report type and filters) of a user,
+// optionally emailed as XLSX to a list of recipients on a daily, weekly or monthly schedule
+type ReportSchedule struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Name       string     `gorm:"not null;type:varchar(100)" json:"name"`
+	ReportType string     `gorm:"not null;type:varchar(50)" json:"report_type"`
+	Filters    string     `gorm:"type:text" json:"filters"` // JSON object of report query parameters
+	Frequency  string     `gorm:"not null;type:varchar(20)" json:"frequency"`
+	RunHour    int        `gorm:"not null" json:"run_hour"`    // local hour of the day the report is emailed
+	Recipients string     `gorm:"type:text" json:"recipients"` // comma separated email addresses
+	Active     bool       `gorm:"not null" json:"active"`      // paused schedules stay saved as favorites
+	NextRunAt  *time.Time `gorm:"default:null;index" json:"next_run_at"`
+	LastRunAt  *time.Time `gorm:"default:null" json:"last_run_at"`
+	LastStatus string     `gorm:"type:varchar(20)" json:"last_status"` // sent or failed
+	LastError  string     `gorm:"type:text" json:"last_error"`
+	CreatedBy  uint       `gorm:"not null;index" json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	CreateUser *User `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+}
+
+// FilterMap returns the saved report filters
+func (rs *ReportSchedule) FilterMap() map[string]string {
+	filters := map[string]string{}
+	if rs.Filters != "" {
+		_ = json.Unmarshal([]byte(rs.Filters), &filters)
+	}
+	return filters
+}
+
+// RecipientList returns the email addresses the report is sent to
+func (rs *ReportSchedule) RecipientList() []string {
+	var recipients []string
+	for _, recipient := range strings.Split(rs.Recipients, ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	return recipients
+}
+
+// NextReportRun returns the first run of a schedule strictly after the given time, or nil for favorites
+func NextReportRun(frequency string, runHour int, after time.Time) *time.Time {
+	next := time.Date(after.Year(), after.Month(), after.Day(), runHour, 0, 0, 0, after.Location())
+	switch frequency {
+	case ReportFrequencyDaily:
+		if !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+	case ReportFrequencyWeekly:
+		for next.Weekday() != time.Monday || !next.After(after) {
+			next = next.AddDate(0, 0, 1)
+		}
+	case ReportFrequencyMonthly:
+		next = time.Date(after.Year(), after.Month(), 1, runHour, 0, 0, 0, after.Location())
+		if !next.After(after) {
+			next = next.AddDate(0, 1, 0)
+		}
+	default:
+		return nil
+	}
+	return &next
+}
+
+// ReportScheduleResponse represents the report schedule data returned in API responses
+type ReportScheduleResponse struct {
+	ID         uint              `json:"id"`
+	Name       string            `json:"name"`
+	ReportType string            `json:"reportType"`
+	Filters    map[string]string `json:"filters"`
+	Frequency  string            `json:"frequency"`
+	RunHour    int               `json:"runHour"`
+	Recipients []string          `json:"recipients"`
+	Active     bool              `json:"active"`
+	NextRunAt  *string           `json:"nextRunAt,omitempty"`
+	LastRunAt  *string           `json:"lastRunAt,omitempty"`
+	LastStatus string            `json:"lastStatus,omitempty"`
+	LastError  string            `json:"lastError,omitempty"`
+	CreatedBy  string            `json:"createdBy"`
+	CreatedAt  string            `json:"createdAt"`
+	UpdatedAt  string            `json:"updatedAt"`
+}
+
+// ToResponse converts a ReportSchedule model to a ReportScheduleResponse
+func (rs *ReportSchedule) ToResponse() *ReportScheduleResponse {
+	// User visual handlers
+	var createdBy string
+	if rs.CreateUser != nil {
+		createdBy = rs.CreateUser.FullName
+	}
+
+	var nextRunAt, lastRunAt *string
+	if rs.NextRunAt != nil {
+		formatted := rs.NextRunAt.Format("02-01-2006 15:04:05")
+		nextRunAt = &formatted
+	}
+	if rs.LastRunAt != nil {
+		formatted := rs.LastRunAt.Format("02-01-2006 15:04:05")
+		lastRunAt = &formatted
+	}
+
+	recipients := rs.RecipientList()
+	if recipients == nil {
+		recipients = []string{}
+	}
+
+	return &ReportScheduleResponse{
+		ID:         rs.ID,
+		Name:       rs.Name,
+		ReportType: rs.ReportType,
+		Filters:    rs.FilterMap(),
+		Frequency:  rs.Frequency,
+		RunHour:    rs.RunHour,
+		Recipients: recipients,
+		Active:     rs.Active,
+		NextRunAt:  nextRunAt,
+		LastRunAt:  lastRunAt,
+		LastStatus: rs.LastStatus,
+		LastError:  rs.LastError,
+		CreatedBy:  createdBy,
+		CreatedAt:  rs.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:  rs.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
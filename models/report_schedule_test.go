@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextReportRun(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, wib)
+	}
+
+	tests := []struct {
+		name      string
+		frequency string
+		runHour   int
+		after     time.Time
+		want      time.Time // zero when no run is expected
+	}{
+		{"daily before the run hour", ReportFrequencyDaily, 8, at(2026, 10, 14, 6, 30), at(2026, 10, 14, 8, 0)},
+		{"daily at the run hour moves to the next day", ReportFrequencyDaily, 8, at(2026, 10, 14, 8, 0), at(2026, 10, 15, 8, 0)},
+		{"daily after the run hour", ReportFrequencyDaily, 8, at(2026, 10, 14, 9, 0), at(2026, 10, 15, 8, 0)},
+		{"daily across the month end", ReportFrequencyDaily, 8, at(2026, 10, 31, 9, 0), at(2026, 11, 1, 8, 0)},
+		{"daily at midnight", ReportFrequencyDaily, 0, at(2026, 10, 14, 0, 0), at(2026, 10, 15, 0, 0)},
+		{"weekly on monday before the run hour", ReportFrequencyWeekly, 8, at(2026, 10, 12, 7, 0), at(2026, 10, 12, 8, 0)},
+		{"weekly on monday after the run hour", ReportFrequencyWeekly, 8, at(2026, 10, 12, 9, 0), at(2026, 10, 19, 8, 0)},
+		{"weekly midweek", ReportFrequencyWeekly, 8, at(2026, 10, 14, 9, 0), at(2026, 10, 19, 8, 0)},
+		{"weekly on sunday night", ReportFrequencyWeekly, 8, at(2026, 10, 18, 23, 0), at(2026, 10, 19, 8, 0)},
+		{"monthly mid month", ReportFrequencyMonthly, 8, at(2026, 10, 14, 9, 0), at(2026, 11, 1, 8, 0)},
+		{"monthly on the first before the run hour", ReportFrequencyMonthly, 8, at(2026, 10, 1, 7, 0), at(2026, 10, 1, 8, 0)},
+		{"monthly on the first at the run hour", ReportFrequencyMonthly, 8, at(2026, 10, 1, 8, 0), at(2026, 11, 1, 8, 0)},
+		{"monthly across the year end", ReportFrequencyMonthly, 8, at(2026, 12, 31, 9, 0), at(2027, 1, 1, 8, 0)},
+		{"favorites never run", ReportFrequencyNone, 8, at(2026, 10, 14, 9, 0), time.Time{}},
+		{"unknown frequency never runs", "hourly", 8, at(2026, 10, 14, 9, 0), time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextReportRun(tt.frequency, tt.runHour, tt.after)
+			if tt.want.IsZero() {
+				if got != nil {
+					t.Fatalf("NextReportRun() = %s, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("NextReportRun() = nil, want %s", tt.want)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextReportRun() = %s, want %s", got, tt.want)
+			}
+			if got.Location() != tt.after.Location() {
+				t.Errorf("NextReportRun() in %s, want %s", got.Location(), tt.after.Location())
+			}
+		})
+	}
+}
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Return detail dispositions, a detail without disposition is still awaiting inspection
+const (
+	ReturnDispositionRestocked = "restocked"
+	ReturnDispositionDamaged   = "damaged"
+	ReturnDispositionDisposed  = "disposed"
+)
+
 type Return struct {
 	ID                uint   `gorm:"primaryKey" json:"id"`
 	NewTrackingNumber string `gorm:"not null;index;type:varchar(255)" json:"new_tracking_number"`
@@ -34,6 +41,10 @@ type ReturnDetail struct {
 	Quantity   *int    `gorm:"not null" json:"quantity"`
 	Price      *int    `gorm:"not null" json:"price"`
 
+	Disposition     *string    `gorm:"default:null;type:varchar(20);index" json:"disposition"` // restocked, damaged or disposed
+	DispositionedBy *uint      `gorm:"default:null" json:"dispositioned_by"`
+	DispositionedAt *time.Time `gorm:"default:null" json:"dispositioned_at"`
+
 	Return  Return   `gorm:"foreignKey:ReturnID" json:"-"`
 	Product *Product `gorm:"-" json:"product,omitempty"`
 }
@@ -59,10 +70,11 @@ type ReturnResponse struct {
 }
 
 type ReturnDetailResponse struct {
-	ProductSKU *string          `json:"productSKU"`
-	Quantity   *int             `json:"quantity"`
-	Price      *int             `json:"price"`
-	Product    *ProductResponse `json:"product,omitempty"`
+	ProductSKU  *string          `json:"productSKU"`
+	Quantity    *int             `json:"quantity"`
+	Price       *int             `json:"price"`
+	Disposition *string          `json:"disposition,omitempty"`
+	Product     *ProductResponse `json:"product,omitempty"`
 }
 
 type MobileReturnResponse struct {
@@ -83,9 +95,10 @@ func (r *Return) ToResponse() ReturnResponse {
 		details = make([]ReturnDetailResponse, len(*r.ReturnDetails))
 		for i, detail := range *r.ReturnDetails {
 			detailResp := ReturnDetailResponse{
-				ProductSKU: detail.ProductSKU,
-				Quantity:   detail.Quantity,
-				Price:      detail.Price,
+				ProductSKU:  detail.ProductSKU,
+				Quantity:    detail.Quantity,
+				Price:       detail.Price,
+				Disposition: detail.Disposition,
 			}
 			// Only add product response if product exists
 			if detail.Product != nil {
@@ -173,3 +186,46 @@ func (r *Return) ToMobileResponse() MobileReturnResponse {
 		UpdatedAt:         r.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
 }
+
+// UnknownReturn represents a scanned return parcel that could not be matched to any order
+type UnknownReturn struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	TrackingNumber string    `gorm:"uniqueIndex;not null;type:varchar(255)" json:"tracking_number"`
+	Status         string    `gorm:"default:'open';index;type:varchar(20)" json:"status"` // open or matched
+	ScanCount      int       `gorm:"not null;default:1" json:"scan_count"`
+	ScannedBy      uint      `gorm:"not null" json:"scanned_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	ScanUser *User `gorm:"foreignKey:ScannedBy" json:"scan_user,omitempty"`
+}
+
+// UnknownReturnResponse represents the unknown return data returned in API responses
+type UnknownReturnResponse struct {
+	ID             uint   `json:"id"`
+	TrackingNumber string `json:"trackingNumber"`
+	Status         string `json:"status"`
+	ScanCount      int    `json:"scanCount"`
+	ScannedBy      string `json:"scannedBy"`
+	CreatedAt      string `json:"createdAt"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+// ToResponse converts UnknownReturn model to UnknownReturnResponse
+func (ur *UnknownReturn) ToResponse() UnknownReturnResponse {
+	// User visual handlers
+	var scannedBy string
+	if ur.ScanUser != nil {
+		scannedBy = ur.ScanUser.FullName
+	}
+
+	return UnknownReturnResponse{
+		ID:             ur.ID,
+		TrackingNumber: ur.TrackingNumber,
+		Status:         ur.Status,
+		ScanCount:      ur.ScanCount,
+		ScannedBy:      scannedBy,
+		CreatedAt:      ur.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:      ur.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
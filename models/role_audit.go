@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Role audit actions
+const (
+	RoleAuditActionGrant  = "grant"
+	RoleAuditActionRevoke = "revoke"
+)
+
+// RoleAudit is the audit trail of roles granted to and revoked from users
+type RoleAudit struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	RoleID    uint      `gorm:"not null" json:"role_id"`
+	RoleName  string    `gorm:"not null;type:varchar(50);index" json:"role_name"` // kept so the trail survives role deletion
+	Action    string    `gorm:"not null;type:varchar(20)" json:"action"`
+	Source    string    `gorm:"type:varchar(30)" json:"source"` // where the change came from: user, bulk, invitation, registration, role-delete
+	ChangedBy *uint     `gorm:"default:null" json:"changed_by"` // nil when the user registered themselves
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+
+	User       *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ChangeUser *User `gorm:"foreignKey:ChangedBy" json:"change_user,omitempty"`
+}
+
+// RoleAuditResponse represents the role audit data returned in API responses
+type RoleAuditResponse struct {
+	ID        uint    `json:"id"`
+	UserID    uint    `json:"userId"`
+	Username  string  `json:"username"`
+	User      string  `json:"user"`
+	RoleName  string  `json:"roleName"`
+	Action    string  `json:"action"`
+	Source    string  `json:"source"`
+	ChangedBy *string `json:"changedBy,omitempty"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+// ToResponse converts a RoleAudit model to a RoleAuditResponse
+func (ra *RoleAudit) ToResponse() *RoleAuditResponse {
+	// User visual handlers
+	var username, user string
+	if ra.User != nil {
+		username = ra.User.Username
+		user = ra.User.FullName
+	}
+	var changedBy *string
+	if ra.ChangeUser != nil {
+		changedBy = &ra.ChangeUser.FullName
+	}
+
+	return &RoleAuditResponse{
+		ID:        ra.ID,
+		UserID:    ra.UserID,
+		Username:  username,
+		User:      user,
+		RoleName:  ra.RoleName,
+		Action:    ra.Action,
+		Source:    ra.Source,
+		ChangedBy: changedBy,
+		CreatedAt: ra.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
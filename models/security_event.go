@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// Security event types
+const (
+	SecurityEventFailedLogin      = "failed_login"
+	SecurityEventPermissionDenied = "permission_denied"
+	SecurityEventImpersonation    = "impersonation"
+	SecurityEventRoleChange       = "role_change"
+	SecurityEventAlert            = "alert" // an alert rule was triggered, Detail names the rule
+)
+
+// SecurityEventTypes lists the security event types
+func SecurityEventTypes() []string {
+	return []string{SecurityEventFailedLogin, SecurityEventPermissionDenied, SecurityEventImpersonation, SecurityEventRoleChange, SecurityEventAlert}
+}
+
+// SecurityEvent is the security log of failed logins, permission-denied attempts, impersonations, role changes
+// and the alerts raised from them
+type SecurityEvent struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	EventType    string    `gorm:"not null;type:varchar(30);index:idx_security_events_type_created,priority:1" json:"event_type"`
+	UserID       *uint     `gorm:"default:null;index" json:"user_id"`        // acting user, nil for failed logins of unknown accounts
+	Username     string    `gorm:"type:varchar(50);index" json:"username"`   // account the event is about, as typed on failed logins
+	TargetUserID *uint     `gorm:"default:null;index" json:"target_user_id"` // user whose roles changed or who was impersonated
+	Detail       string    `gorm:"type:text" json:"detail"`                  // reason, role or alert rule
+	Method       string    `gorm:"type:varchar(10)" json:"method"`
+	Path         string    `gorm:"type:text" json:"path"`
+	IPAddress    string    `gorm:"type:varchar(50);index" json:"ip_address"`
+	UserAgent    string    `gorm:"type:text" json:"user_agent"`
+	CreatedAt    time.Time `gorm:"index;index:idx_security_events_type_created,priority:2" json:"created_at"`
+
+	User       *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	TargetUser *User `gorm:"foreignKey:TargetUserID" json:"target_user,omitempty"`
+}
+
+// SecurityEventResponse represents the security event data returned in API responses
+type SecurityEventResponse struct {
+	ID           uint    `json:"id"`
+	EventType    string  `json:"eventType"`
+	UserID       *uint   `json:"userId"`
+	User         *string `json:"user,omitempty"`
+	Username     string  `json:"username"`
+	TargetUserID *uint   `json:"targetUserId"`
+	TargetUser   *string `json:"targetUser,omitempty"`
+	Detail       string  `json:"detail"`
+	Method       string  `json:"method"`
+	Path         string  `json:"path"`
+	IPAddress    string  `json:"ipAddress"`
+	UserAgent    string  `json:"userAgent"`
+	CreatedAt    string  `json:"createdAt"`
+}
+
+// ToResponse converts a SecurityEvent model to a SecurityEventResponse
+func (se *SecurityEvent) ToResponse() *SecurityEventResponse {
+	// User visual handlers
+	var user, targetUser *string
+	if se.User != nil {
+		user = &se.User.FullName
+	}
+	if se.TargetUser != nil {
+		targetUser = &se.TargetUser.FullName
+	}
+
+	return &SecurityEventResponse{
+		ID:           se.ID,
+		EventType:    se.EventType,
+		UserID:       se.UserID,
+		User:         user,
+		Username:     se.Username,
+		TargetUserID: se.TargetUserID,
+		TargetUser:   targetUser,
+		Detail:       se.Detail,
+		Method:       se.Method,
+		Path:         se.Path,
+		IPAddress:    se.IPAddress,
+		UserAgent:    se.UserAgent,
+		CreatedAt:    se.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// Shadow experiments, a new implementation checked against the one serving responses
+const (
+	ShadowExperimentUnifiedQC = "unified_qc" // unified QC service against the ribbon and online lane handlers
+)
+
+// ShadowDivergence logs a request where the shadow implementation of an experiment disagreed with the primary one
+type ShadowDivergence struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	Experiment      string    `gorm:"not null;type:varchar(50);index" json:"experiment"`
+	Operation       string    `gorm:"not null;type:varchar(50);index" json:"operation"` // e.g. ribbon_scan, online_validate
+	Subject         string    `gorm:"type:varchar(100);index" json:"subject"`           // tracking number or record the request acted on
+	Request         string    `gorm:"type:text" json:"request"`
+	PrimaryStatus   int       `gorm:"type:int" json:"primary_status"`
+	PrimaryResponse string    `gorm:"type:text" json:"primary_response"`
+	ShadowStatus    int       `gorm:"type:int" json:"shadow_status"`
+	ShadowOutcome   string    `gorm:"type:varchar(50)" json:"shadow_outcome"`
+	ShadowDetail    string    `gorm:"type:text" json:"shadow_detail"`
+	UserID          *uint     `gorm:"default:null" json:"user_id"`
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// ShadowDivergenceResponse represents the shadow divergence data returned in API responses
+type ShadowDivergenceResponse struct {
+	ID              uint    `json:"id"`
+	Experiment      string  `json:"experiment"`
+	Operation       string  `json:"operation"`
+	Subject         string  `json:"subject"`
+	Request         string  `json:"request"`
+	PrimaryStatus   int     `json:"primaryStatus"`
+	PrimaryResponse string  `json:"primaryResponse"`
+	ShadowStatus    int     `json:"shadowStatus"`
+	ShadowOutcome   string  `json:"shadowOutcome"`
+	ShadowDetail    string  `json:"shadowDetail,omitempty"`
+	User            *string `json:"user"`
+	CreatedAt       string  `json:"createdAt"`
+}
+
+// ToResponse converts a ShadowDivergence model to a ShadowDivergenceResponse
+func (sd *ShadowDivergence) ToResponse() *ShadowDivergenceResponse {
+	// User visual handler
+	var user *string
+	if sd.User != nil {
+		user = &sd.User.FullName
+	}
+
+	return &ShadowDivergenceResponse{
+		ID:              sd.ID,
+		Experiment:      sd.Experiment,
+		Operation:       sd.Operation,
+		Subject:         sd.Subject,
+		Request:         sd.Request,
+		PrimaryStatus:   sd.PrimaryStatus,
+		PrimaryResponse: sd.PrimaryResponse,
+		ShadowStatus:    sd.ShadowStatus,
+		ShadowOutcome:   sd.ShadowOutcome,
+		ShadowDetail:    sd.ShadowDetail,
+		User:            user,
+		CreatedAt:       sd.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,108 @@
+package models
+
+import "time"
+
+// Skill is a training or certification staff need for some tasks, e.g. ribbon QC or handling fragile goods
+type Skill struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Code        string `gorm:"uniqueIndex;not null;type:varchar(50)" json:"code"`
+	Name        string `gorm:"not null;type:varchar(100)" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	// QC lane (ribbon or online) only users holding the skill may work, empty when the skill gates no lane
+	QCLane string `gorm:"type:varchar(20);index" json:"qc_lane"`
+	// Every shift needs at least one checked in user holding the skill, reported by the coverage report
+	RequiredPerShift bool      `gorm:"not null;default:false" json:"required_per_shift"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// UserSkill assigns a skill to a user, optionally until the certification expires
+type UserSkill struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;uniqueIndex:idx_user_skill;index" json:"user_id"`
+	SkillID    uint       `gorm:"not null;uniqueIndex:idx_user_skill" json:"skill_id"`
+	ExpiresAt  *time.Time `gorm:"default:null" json:"expires_at"` // nil when the skill does not expire
+	AssignedBy uint       `gorm:"not null" json:"assigned_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	User       *User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Skill      *Skill `gorm:"foreignKey:SkillID" json:"skill,omitempty"`
+	AssignUser *User  `gorm:"foreignKey:AssignedBy" json:"assign_user,omitempty"`
+}
+
+// ValidAt reports whether the skill assignment is not expired at the given time
+func (us *UserSkill) ValidAt(at time.Time) bool {
+	return us.ExpiresAt == nil || us.ExpiresAt.After(at)
+}
+
+// SkillResponse represents the skill data returned in API responses
+type SkillResponse struct {
+	ID               uint   `json:"id"`
+	Code             string `json:"code"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	QCLane           string `json:"qcLane"`
+	RequiredPerShift bool   `json:"requiredPerShift"`
+	HolderCount      int64  `json:"holderCount"`
+	CreatedAt        string `json:"createdAt"`
+	UpdatedAt        string `json:"updatedAt"`
+}
+
+// ToResponse converts a Skill model to a SkillResponse
+func (s *Skill) ToResponse() *SkillResponse {
+	return &SkillResponse{
+		ID:               s.ID,
+		Code:             s.Code,
+		Name:             s.Name,
+		Description:      s.Description,
+		QCLane:           s.QCLane,
+		RequiredPerShift: s.RequiredPerShift,
+		CreatedAt:        s.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:        s.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// UserSkillResponse represents the user skill data returned in API responses
+type UserSkillResponse struct {
+	ID         uint    `json:"id"`
+	UserID     uint    `json:"userId"`
+	User       string  `json:"user"`
+	SkillID    uint    `json:"skillId"`
+	SkillCode  string  `json:"skillCode"`
+	SkillName  string  `json:"skillName"`
+	ExpiresAt  *string `json:"expiresAt"`
+	Expired    bool    `json:"expired"`
+	AssignedBy *string `json:"assignedBy"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+// ToResponse converts a UserSkill model to a UserSkillResponse
+func (us *UserSkill) ToResponse() *UserSkillResponse {
+	response := &UserSkillResponse{
+		ID:        us.ID,
+		UserID:    us.UserID,
+		SkillID:   us.SkillID,
+		Expired:   !us.ValidAt(time.Now()),
+		CreatedAt: us.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt: us.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if us.ExpiresAt != nil {
+		formatted := us.ExpiresAt.Format("02-01-2006")
+		response.ExpiresAt = &formatted
+	}
+
+	// User visual handlers
+	if us.User != nil {
+		response.User = us.User.FullName
+	}
+	if us.Skill != nil {
+		response.SkillCode = us.Skill.Code
+		response.SkillName = us.Skill.Name
+	}
+	if us.AssignUser != nil {
+		response.AssignedBy = &us.AssignUser.FullName
+	}
+	return response
+}
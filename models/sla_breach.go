@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// SLABreach records an order whose SentBefore deadline passed before it was scanned for outbound
+type SLABreach struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	OrderID        uint       `gorm:"not null;uniqueIndex" json:"order_id"`
+	OrderGineeID   string     `gorm:"type:varchar(100)" json:"order_ginee_id"`
+	TrackingNumber string     `gorm:"type:varchar(100);index" json:"tracking_number"`
+	SentBefore     time.Time  `gorm:"not null" json:"sent_before"`
+	Stage          string     `gorm:"not null;type:varchar(50);index" json:"stage"` // processing status the order was stuck in
+	Held           bool       `gorm:"default:false" json:"held"`                    // the order was on hold when the deadline passed
+	Reason         string     `gorm:"type:text" json:"reason"`
+	DetectedAt     time.Time  `gorm:"not null;index" json:"detected_at"`
+	ResolvedAt     *time.Time `gorm:"default:null" json:"resolved_at"` // set once the order ships or is canceled
+	Resolution     string     `gorm:"type:varchar(50)" json:"resolution"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	Order *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+}
+
+type SLABreachResponse struct {
+	ID             uint    `json:"id"`
+	OrderID        uint    `json:"orderId"`
+	OrderGineeID   string  `json:"orderGineeId"`
+	TrackingNumber string  `json:"trackingNumber"`
+	SentBefore     string  `json:"sentBefore"`
+	Stage          string  `json:"stage"`
+	Held           bool    `json:"held"`
+	Reason         string  `json:"reason"`
+	LateMinutes    int64   `json:"lateMinutes"` // until resolved, or until now while open
+	DetectedAt     string  `json:"detectedAt"`
+	ResolvedAt     *string `json:"resolvedAt,omitempty"`
+	Resolution     string  `json:"resolution,omitempty"`
+}
+
+// ToResponse converts an SLABreach model to an SLABreachResponse
+func (b *SLABreach) ToResponse() *SLABreachResponse {
+	end := time.Now()
+	var resolvedAt *string
+	if b.ResolvedAt != nil {
+		end = *b.ResolvedAt
+		formatted := b.ResolvedAt.Format("02-01-2006 15:04:05")
+		resolvedAt = &formatted
+	}
+
+	return &SLABreachResponse{
+		ID:             b.ID,
+		OrderID:        b.OrderID,
+		OrderGineeID:   b.OrderGineeID,
+		TrackingNumber: b.TrackingNumber,
+		SentBefore:     b.SentBefore.Format("02-01-2006 15:04:05"),
+		Stage:          b.Stage,
+		Held:           b.Held,
+		Reason:         b.Reason,
+		LateMinutes:    int64(end.Sub(b.SentBefore).Minutes()),
+		DetectedAt:     b.DetectedAt.Format("02-01-2006 15:04:05"),
+		ResolvedAt:     resolvedAt,
+		Resolution:     b.Resolution,
+	}
+}
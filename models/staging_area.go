@@ -0,0 +1,148 @@
+package models
+
+import "time"
+
+// Staging area types, in the order a packed parcel passes them
+const (
+	StagingAreaQCOutboundLane = "qc_outbound_lane" // lane packed parcels wait in between QC and the outbound scan
+	StagingAreaCourierStaging = "courier_staging"  // courier bin outbound scanned parcels wait in until handover
+)
+
+// StagingAreaTypes lists the valid staging area types
+var StagingAreaTypes = []string{StagingAreaQCOutboundLane, StagingAreaCourierStaging}
+
+// Reasons a parcel left a staging area
+const (
+	StagingRemovalMoved    = "moved"    // placed in another staging area
+	StagingRemovalOutbound = "outbound" // outbound scanned, leaving the QC outbound lane
+	StagingRemovalHandover = "handover" // scanned into a courier handover session
+	StagingRemovalManual   = "manual"   // taken out by hand, e.g. a canceled order pulled from the lane
+)
+
+// StagingArea is a physical lane or bin packed parcels are staged in, holding at most Capacity parcels
+type StagingArea struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Code         string    `gorm:"uniqueIndex;not null;type:varchar(20)" json:"code"`
+	Name         string    `gorm:"not null;type:varchar(100)" json:"name"`
+	AreaType     string    `gorm:"not null;type:varchar(30);index" json:"area_type"`
+	ExpeditionID *uint     `gorm:"default:null;index" json:"expedition_id"` // courier bins only take parcels of this expedition, nil takes any
+	Capacity     int       `gorm:"not null" json:"capacity"`
+	IsActive     bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Expedition *Expedition `gorm:"foreignKey:ExpeditionID" json:"expedition,omitempty"`
+}
+
+// StagingPlacement records a parcel placed in a staging area, open until the parcel leaves it
+type StagingPlacement struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	StagingAreaID  uint       `gorm:"not null;index" json:"staging_area_id"`
+	OrderID        uint       `gorm:"not null;index" json:"order_id"`
+	TrackingNumber string     `gorm:"not null;index;type:varchar(100)" json:"tracking_number"`
+	PlacedBy       uint       `gorm:"not null" json:"placed_by"`
+	RemovedAt      *time.Time `gorm:"default:null;index" json:"removed_at"` // nil while the parcel is in the area
+	RemovedBy      *uint      `gorm:"default:null" json:"removed_by"`
+	RemovalReason  string     `gorm:"type:varchar(20)" json:"removal_reason"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+
+	StagingArea *StagingArea `gorm:"foreignKey:StagingAreaID" json:"staging_area,omitempty"`
+	PlaceUser   *User        `gorm:"foreignKey:PlacedBy" json:"place_user,omitempty"`
+	RemoveUser  *User        `gorm:"foreignKey:RemovedBy" json:"remove_user,omitempty"`
+}
+
+// StagingAreaResponse represents the staging area data returned in API responses
+type StagingAreaResponse struct {
+	ID             uint   `json:"id"`
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+	AreaType       string `json:"areaType"`
+	ExpeditionID   *uint  `json:"expeditionId"`
+	ExpeditionName string `json:"expeditionName,omitempty"`
+	Capacity       int    `json:"capacity"`
+	Occupied       int    `json:"occupied"`
+	IsActive       bool   `json:"isActive"`
+	CreatedAt      string `json:"createdAt"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+// ToResponse converts a StagingArea model to a StagingAreaResponse with the number of parcels in it
+func (sa *StagingArea) ToResponse(occupied int) *StagingAreaResponse {
+	// Expedition visual handler
+	var expeditionName string
+	if sa.Expedition != nil {
+		expeditionName = sa.Expedition.ExpeditionName
+	}
+
+	return &StagingAreaResponse{
+		ID:             sa.ID,
+		Code:           sa.Code,
+		Name:           sa.Name,
+		AreaType:       sa.AreaType,
+		ExpeditionID:   sa.ExpeditionID,
+		ExpeditionName: expeditionName,
+		Capacity:       sa.Capacity,
+		Occupied:       occupied,
+		IsActive:       sa.IsActive,
+		CreatedAt:      sa.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:      sa.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// StagingPlacementResponse represents the staging placement data returned in API responses
+type StagingPlacementResponse struct {
+	ID              uint    `json:"id"`
+	StagingAreaID   uint    `json:"stagingAreaId"`
+	StagingAreaCode string  `json:"stagingAreaCode,omitempty"`
+	StagingAreaName string  `json:"stagingAreaName,omitempty"`
+	AreaType        string  `json:"areaType,omitempty"`
+	OrderID         uint    `json:"orderId"`
+	TrackingNumber  string  `json:"trackingNumber"`
+	PlacedBy        string  `json:"placedBy"`
+	RemovedAt       *string `json:"removedAt"`
+	RemovedBy       *string `json:"removedBy,omitempty"`
+	RemovalReason   string  `json:"removalReason,omitempty"`
+	CreatedAt       string  `json:"createdAt"`
+}
+
+// ToResponse converts a StagingPlacement model to a StagingPlacementResponse
+func (sp *StagingPlacement) ToResponse() *StagingPlacementResponse {
+	// Staging area visual handler
+	var areaCode, areaName, areaType string
+	if sp.StagingArea != nil {
+		areaCode = sp.StagingArea.Code
+		areaName = sp.StagingArea.Name
+		areaType = sp.StagingArea.AreaType
+	}
+
+	// User visual handlers
+	var placedBy string
+	if sp.PlaceUser != nil {
+		placedBy = sp.PlaceUser.FullName
+	}
+	var removedBy *string
+	if sp.RemoveUser != nil {
+		removedBy = &sp.RemoveUser.FullName
+	}
+
+	var removedAt *string
+	if sp.RemovedAt != nil {
+		formatted := sp.RemovedAt.Format("02-01-2006 15:04:05")
+		removedAt = &formatted
+	}
+
+	return &StagingPlacementResponse{
+		ID:              sp.ID,
+		StagingAreaID:   sp.StagingAreaID,
+		StagingAreaCode: areaCode,
+		StagingAreaName: areaName,
+		AreaType:        areaType,
+		OrderID:         sp.OrderID,
+		TrackingNumber:  sp.TrackingNumber,
+		PlacedBy:        placedBy,
+		RemovedAt:       removedAt,
+		RemovedBy:       removedBy,
+		RemovalReason:   sp.RemovalReason,
+		CreatedAt:       sp.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
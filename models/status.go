@@ -0,0 +1,369 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Order processing statuses
+const (
+	ProcessingStatusReadyToPick       = "ready_to_pick"
+	ProcessingStatusPickingProgress   = "picking_progress"
+	ProcessingStatusPickingPending    = "picking_pending"
+	ProcessingStatusAwaitingStock     = "awaiting_stock"
+	ProcessingStatusPickingCompleted  = "picking_completed"
+	ProcessingStatusQCProgress        = "qc_progress"
+	ProcessingStatusQCCompleted       = "qc_completed"
+	ProcessingStatusOutboundCompleted = "outbound_completed"
+)
+
+// Order event statuses
+const (
+	EventStatusInProgress = "in_progress"
+	EventStatusPending    = "pending"
+	EventStatusHeld       = "held"
+	EventStatusMerged     = "merged"
+	EventStatusDuplicated = "duplicated"
+	EventStatusCompleted  = "completed"
+	EventStatusCanceled   = "canceled"
+)
+
+// QCStatus is the status of a QC Ribbon or QC Online record, constrained in the database to the values below
+type QCStatus string
+
+// QC Ribbon and QC Online statuses
+const (
+	QCStatusInProgress QCStatus = "in_progress"
+	QCStatusPending    QCStatus = "pending"
+	QCStatusCompleted  QCStatus = "completed"
+	QCStatusCanceled   QCStatus = "canceled"
+)
+
+// ErrInvalidStatusTransition is returned when a status is moved to a status its state machine does not allow
+var ErrInvalidStatusTransition = errors.New("invalid status transition")
+
+var (
+	processingStatuses = []string{
+		ProcessingStatusReadyToPick, ProcessingStatusPickingProgress, ProcessingStatusPickingPending, ProcessingStatusAwaitingStock,
+		ProcessingStatusPickingCompleted, ProcessingStatusQCProgress, ProcessingStatusQCCompleted, ProcessingStatusOutboundCompleted,
+	}
+	eventStatuses = []string{
+		EventStatusInProgress, EventStatusPending, EventStatusHeld, EventStatusMerged,
+		EventStatusDuplicated, EventStatusCompleted, EventStatusCanceled,
+	}
+	qcStatuses = []string{string(QCStatusInProgress), string(QCStatusPending), string(QCStatusCompleted), string(QCStatusCanceled)}
+)
+
+// editLockedProcessingStatuses are the processing statuses after picking in which the items and prices
+// of an order can only be changed through a coordinator override
+var editLockedProcessingStatuses = []string{ProcessingStatusPickingCompleted, ProcessingStatusQCCompleted}
+
+// IsEditLockedProcessingStatus reports whether order edits require a coordinator override in status
+func IsEditLockedProcessingStatus(status string) bool {
+	return containsStatus(editLockedProcessingStatuses, status)
+}
+
+// Status label languages
+const (
+	StatusLanguageEnglish    = "en"
+	StatusLanguageIndonesian = "id"
+)
+
+// StatusDefinition describes a status for clients: its display labels per language,
+// a color hint and the statuses an order can move to from it
+type StatusDefinition struct {
+	Value       string            `json:"value"`
+	Labels      map[string]string `json:"labels"`
+	Color       string            `json:"color"`
+	Transitions []string          `json:"transitions"`
+}
+
+// processingStatusDefinitions is the order processing state machine, in flow order
+var processingStatusDefinitions = []StatusDefinition{
+	{
+		Value:       ProcessingStatusReadyToPick,
+		Labels:      map[string]string{StatusLanguageEnglish: "Ready to Pick", StatusLanguageIndonesian: "Siap Diambil"},
+		Color:       "#6B7280",
+		Transitions: []string{ProcessingStatusPickingProgress},
+	},
+	{
+		Value:       ProcessingStatusPickingProgress,
+		Labels:      map[string]string{StatusLanguageEnglish: "Picking in Progress", StatusLanguageIndonesian: "Sedang Diambil"},
+		Color:       "#3B82F6",
+		Transitions: []string{ProcessingStatusPickingPending, ProcessingStatusAwaitingStock, ProcessingStatusPickingCompleted},
+	},
+	{
+		Value:       ProcessingStatusPickingPending,
+		Labels:      map[string]string{StatusLanguageEnglish: "Picking is Pending", StatusLanguageIndonesian: "Pengambilan Tertunda"},
+		Color:       "#F59E0B",
+		Transitions: []string{ProcessingStatusPickingProgress, ProcessingStatusPickingCompleted},
+	},
+	{
+		Value:       ProcessingStatusAwaitingStock,
+		Labels:      map[string]string{StatusLanguageEnglish: "Awaiting Stock", StatusLanguageIndonesian: "Menunggu Stok"},
+		Color:       "#EF4444",
+		Transitions: []string{ProcessingStatusReadyToPick, ProcessingStatusPickingProgress},
+	},
+	{
+		Value:       ProcessingStatusPickingCompleted,
+		Labels:      map[string]string{StatusLanguageEnglish: "Picking Completed", StatusLanguageIndonesian: "Pengambilan Selesai"},
+		Color:       "#8B5CF6",
+		Transitions: []string{ProcessingStatusQCProgress},
+	},
+	{
+		Value:       ProcessingStatusQCProgress,
+		Labels:      map[string]string{StatusLanguageEnglish: "QC in Progress", StatusLanguageIndonesian: "Sedang QC"},
+		Color:       "#06B6D4",
+		Transitions: []string{ProcessingStatusQCCompleted, ProcessingStatusPickingCompleted},
+	},
+	{
+		Value:       ProcessingStatusQCCompleted,
+		Labels:      map[string]string{StatusLanguageEnglish: "QC Completed", StatusLanguageIndonesian: "QC Selesai"},
+		Color:       "#14B8A6",
+		Transitions: []string{ProcessingStatusOutboundCompleted, ProcessingStatusPickingCompleted},
+	},
+	{
+		Value:       ProcessingStatusOutboundCompleted,
+		Labels:      map[string]string{StatusLanguageEnglish: "Outbound Completed", StatusLanguageIndonesian: "Outbound Selesai"},
+		Color:       "#22C55E",
+		Transitions: []string{},
+	},
+}
+
+// eventStatusDefinitions is the order event state machine
+var eventStatusDefinitions = []StatusDefinition{
+	{
+		Value:       EventStatusInProgress,
+		Labels:      map[string]string{StatusLanguageEnglish: "In Progress", StatusLanguageIndonesian: "Dalam Proses"},
+		Color:       "#3B82F6",
+		Transitions: []string{EventStatusPending, EventStatusHeld, EventStatusMerged, EventStatusDuplicated, EventStatusCompleted, EventStatusCanceled},
+	},
+	{
+		Value:       EventStatusPending,
+		Labels:      map[string]string{StatusLanguageEnglish: "Pending", StatusLanguageIndonesian: "Tertunda"},
+		Color:       "#F59E0B",
+		Transitions: []string{EventStatusInProgress, EventStatusCanceled},
+	},
+	{
+		Value:       EventStatusHeld,
+		Labels:      map[string]string{StatusLanguageEnglish: "On Hold", StatusLanguageIndonesian: "Ditahan"},
+		Color:       "#F97316",
+		Transitions: []string{EventStatusInProgress, EventStatusCanceled},
+	},
+	{
+		Value:       EventStatusMerged,
+		Labels:      map[string]string{StatusLanguageEnglish: "Merged", StatusLanguageIndonesian: "Digabung"},
+		Color:       "#6B7280",
+		Transitions: []string{},
+	},
+	{
+		Value:       EventStatusDuplicated,
+		Labels:      map[string]string{StatusLanguageEnglish: "Duplicated", StatusLanguageIndonesian: "Duplikat"},
+		Color:       "#6B7280",
+		Transitions: []string{},
+	},
+	{
+		Value:       EventStatusCompleted,
+		Labels:      map[string]string{StatusLanguageEnglish: "Completed", StatusLanguageIndonesian: "Selesai"},
+		Color:       "#22C55E",
+		Transitions: []string{EventStatusCanceled}, // an order can still be canceled after it was shipped
+	},
+	{
+		Value:       EventStatusCanceled,
+		Labels:      map[string]string{StatusLanguageEnglish: "Canceled", StatusLanguageIndonesian: "Dibatalkan"},
+		Color:       "#EF4444",
+		Transitions: []string{},
+	},
+}
+
+// qcStatusDefinitions is the QC Ribbon and QC Online state machine. Voided QC records are deleted rather than
+// moved out of completed.
+var qcStatusDefinitions = []StatusDefinition{
+	{
+		Value:       string(QCStatusInProgress),
+		Labels:      map[string]string{StatusLanguageEnglish: "QC in Progress", StatusLanguageIndonesian: "Sedang QC"},
+		Color:       "#06B6D4",
+		Transitions: []string{string(QCStatusPending), string(QCStatusCompleted), string(QCStatusCanceled)},
+	},
+	{
+		Value:       string(QCStatusPending),
+		Labels:      map[string]string{StatusLanguageEnglish: "QC Pending", StatusLanguageIndonesian: "QC Tertunda"},
+		Color:       "#F59E0B",
+		Transitions: []string{string(QCStatusInProgress), string(QCStatusCompleted), string(QCStatusCanceled)},
+	},
+	{
+		Value:       string(QCStatusCompleted),
+		Labels:      map[string]string{StatusLanguageEnglish: "QC Completed", StatusLanguageIndonesian: "QC Selesai"},
+		Color:       "#22C55E",
+		Transitions: []string{},
+	},
+	{
+		Value:       string(QCStatusCanceled),
+		Labels:      map[string]string{StatusLanguageEnglish: "QC Canceled", StatusLanguageIndonesian: "QC Dibatalkan"},
+		Color:       "#EF4444",
+		Transitions: []string{},
+	},
+}
+
+// ProcessingStatusDefinitions returns the order processing statuses in flow order
+func ProcessingStatusDefinitions() []StatusDefinition {
+	return processingStatusDefinitions
+}
+
+// EventStatusDefinitions returns the order event statuses
+func EventStatusDefinitions() []StatusDefinition {
+	return eventStatusDefinitions
+}
+
+// QCStatusDefinitions returns the QC Ribbon and QC Online statuses
+func QCStatusDefinitions() []StatusDefinition {
+	return qcStatusDefinitions
+}
+
+// ProcessingStatusLabel returns the English display label of an order processing status, empty when unknown
+func ProcessingStatusLabel(status string) string {
+	return statusLabel(processingStatusDefinitions, status)
+}
+
+// EventStatusLabel returns the English display label of an order event status, empty when unknown
+func EventStatusLabel(status string) string {
+	return statusLabel(eventStatusDefinitions, status)
+}
+
+// CanTransitionProcessingStatus reports whether the order processing state machine allows moving from one status to another
+func CanTransitionProcessingStatus(from, to string) bool {
+	return canTransition(processingStatusDefinitions, from, to)
+}
+
+// CanTransitionEventStatus reports whether the order event state machine allows moving from one status to another
+func CanTransitionEventStatus(from, to string) bool {
+	return canTransition(eventStatusDefinitions, from, to)
+}
+
+// CanTransitionQCStatus reports whether the QC state machine allows moving from one status to another
+func CanTransitionQCStatus(from, to QCStatus) bool {
+	return canTransition(qcStatusDefinitions, string(from), string(to))
+}
+
+// ValidateQCStatusTransition returns ErrInvalidStatusTransition when the QC state machine does not allow the move
+func ValidateQCStatusTransition(from, to QCStatus) error {
+	if !CanTransitionQCStatus(from, to) {
+		return fmt.Errorf("%w: QC status cannot move from %s to %s", ErrInvalidStatusTransition, from, to)
+	}
+	return nil
+}
+
+// QCStatusesTransitioningTo returns the QC statuses the state machine allows moving to status from
+func QCStatusesTransitioningTo(status QCStatus) []QCStatus {
+	var from []QCStatus
+	for _, definition := range qcStatusDefinitions {
+		if canTransition(qcStatusDefinitions, definition.Value, string(status)) {
+			from = append(from, QCStatus(definition.Value))
+		}
+	}
+	return from
+}
+
+// canTransition reports whether the state machine lists to among the transitions of from. Staying in the same
+// status is not a transition.
+func canTransition(definitions []StatusDefinition, from, to string) bool {
+	for _, definition := range definitions {
+		if definition.Value == from {
+			return containsStatus(definition.Transitions, to)
+		}
+	}
+	return false
+}
+
+func statusLabel(definitions []StatusDefinition, status string) string {
+	for _, definition := range definitions {
+		if definition.Value == status {
+			return definition.Labels[StatusLanguageEnglish]
+		}
+	}
+	return ""
+}
+
+// IsValidProcessingStatus reports whether status is a known order processing status
+func IsValidProcessingStatus(status string) bool {
+	return containsStatus(processingStatuses, status)
+}
+
+// IsValidEventStatus reports whether status is a known order event status
+func IsValidEventStatus(status string) bool {
+	return containsStatus(eventStatuses, status)
+}
+
+// IsValidQCStatus reports whether status is a known QC Ribbon or QC Online status
+func IsValidQCStatus(status string) bool {
+	return containsStatus(qcStatuses, status)
+}
+
+func containsStatus(statuses []string, status string) bool {
+	for _, known := range statuses {
+		if known == status {
+			return true
+		}
+	}
+	return false
+}
+
+// statusColumn pairs a status column with its current model value and validator
+type statusColumn struct {
+	Column  string
+	Value   string
+	IsValid func(string) bool
+}
+
+// validateStatusColumns rejects unknown status values written through a model (Create, Save)
+// or through column updates (Update, Updates with a map). Empty model values are left to the column default.
+func validateStatusColumns(tx *gorm.DB, columns ...statusColumn) error {
+	updates, _ := tx.Statement.Dest.(map[string]interface{})
+	for _, column := range columns {
+		value := column.Value
+		if updates != nil {
+			updated, ok := updates[column.Column]
+			if !ok {
+				continue
+			}
+			switch updated := updated.(type) {
+			case string:
+				value = updated
+			case QCStatus:
+				value = string(updated)
+			default:
+				return fmt.Errorf("invalid %s value %v", column.Column, updated)
+			}
+		}
+		if value != "" && !column.IsValid(value) {
+			return fmt.Errorf("unknown %s %q", column.Column, value)
+		}
+	}
+	return nil
+}
+
+// BeforeSave normalizes the order identifiers and rejects unknown order statuses
+func (o *Order) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx,
+		identifierColumn{Column: "tracking_number", Value: &o.TrackingNumber},
+		identifierColumn{Column: "order_ginee_id", Value: &o.OrderGineeID},
+	)
+	return validateStatusColumns(tx,
+		statusColumn{Column: "processing_status", Value: o.ProcessingStatus, IsValid: IsValidProcessingStatus},
+		statusColumn{Column: "event_status", Value: o.EventStatus, IsValid: IsValidEventStatus},
+	)
+}
+
+// BeforeSave normalizes the tracking number and rejects unknown QC Ribbon statuses
+func (qcr *QCRibbon) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &qcr.TrackingNumber})
+	return validateStatusColumns(tx, statusColumn{Column: "status", Value: string(qcr.Status), IsValid: IsValidQCStatus})
+}
+
+// BeforeSave normalizes the tracking number and rejects unknown QC Online statuses
+func (qco *QCOnline) BeforeSave(tx *gorm.DB) error {
+	normalizeIdentifierColumns(tx, identifierColumn{Column: "tracking_number", Value: &qco.TrackingNumber})
+	return validateStatusColumns(tx, statusColumn{Column: "status", Value: string(qco.Status), IsValid: IsValidQCStatus})
+}
@@ -0,0 +1,178 @@
+package models
+
+import "time"
+
+// StockTake is a cycle counting session scoped to racks or SKUs
+type StockTake struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	Code       string     `gorm:"uniqueIndex;not null;type:varchar(50)" json:"code"`
+	Scope      string     `gorm:"not null;type:varchar(20)" json:"scope"`                   // rack or sku
+	ScopeValue string     `gorm:"type:text" json:"scope_value"`                             // comma separated racks or SKUs
+	Status     string     `gorm:"not null;type:varchar(20);default:counting" json:"status"` // counting, approved, canceled
+	Notes      string     `gorm:"type:text" json:"notes"`
+	CreatedBy  uint       `gorm:"not null" json:"created_by"`
+	ApprovedBy *uint      `gorm:"default:null" json:"approved_by"`
+	ApprovedAt *time.Time `gorm:"default:null" json:"approved_at"`
+	CanceledBy *uint      `gorm:"default:null" json:"canceled_by"`
+	CanceledAt *time.Time `gorm:"default:null" json:"canceled_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Items       []StockTakeItem `gorm:"foreignKey:StockTakeID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"items,omitempty"`
+	CreateUser  *User           `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+	ApproveUser *User           `gorm:"foreignKey:ApprovedBy" json:"approve_user,omitempty"`
+	CancelUser  *User           `gorm:"foreignKey:CanceledBy" json:"cancel_user,omitempty"`
+}
+
+// StockTakeItem is a single SKU counted in a stock take session
+type StockTakeItem struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	StockTakeID     uint       `gorm:"not null;uniqueIndex:idx_stock_take_item_sku" json:"stock_take_id"`
+	SKU             string     `gorm:"not null;type:varchar(255);uniqueIndex:idx_stock_take_item_sku" json:"sku"`
+	ProductName     string     `gorm:"type:varchar(255)" json:"product_name"`
+	Location        string     `gorm:"type:varchar(100)" json:"location"`
+	SystemQuantity  int        `gorm:"not null;default:0" json:"system_quantity"` // snapshot when the session was created
+	CountedQuantity *int       `gorm:"default:null" json:"counted_quantity"`
+	Unexpected      bool       `gorm:"default:false" json:"unexpected"` // scanned but outside the session scope
+	CountedBy       *uint      `gorm:"default:null" json:"counted_by"`
+	CountedAt       *time.Time `gorm:"default:null" json:"counted_at"`
+
+	CountUser *User `gorm:"foreignKey:CountedBy" json:"count_user,omitempty"`
+}
+
+// Variance returns counted minus system quantity, or 0 when the item has not been counted
+func (i *StockTakeItem) Variance() int {
+	if i.CountedQuantity == nil {
+		return 0
+	}
+	return *i.CountedQuantity - i.SystemQuantity
+}
+
+type StockTakeResponse struct {
+	ID         uint                    `json:"id"`
+	Code       string                  `json:"code"`
+	Scope      string                  `json:"scope"`
+	ScopeValue string                  `json:"scopeValue"`
+	Status     string                  `json:"status"`
+	Notes      string                  `json:"notes"`
+	Summary    StockTakeSummary        `json:"summary"`
+	Items      []StockTakeItemResponse `json:"items,omitempty"`
+	CreatedBy  string                  `json:"createdBy"`
+	ApprovedBy *string                 `json:"approvedBy,omitempty"`
+	ApprovedAt *string                 `json:"approvedAt,omitempty"`
+	CanceledBy *string                 `json:"canceledBy,omitempty"`
+	CanceledAt *string                 `json:"canceledAt,omitempty"`
+	CreatedAt  string                  `json:"createdAt"`
+	UpdatedAt  string                  `json:"updatedAt"`
+}
+
+type StockTakeSummary struct {
+	TotalItems     int `json:"totalItems"`
+	CountedItems   int `json:"countedItems"`
+	VarianceItems  int `json:"varianceItems"`
+	TotalVariance  int `json:"totalVariance"`
+	UnexpectedSKUs int `json:"unexpectedSkus"`
+}
+
+type StockTakeItemResponse struct {
+	ID              uint    `json:"id"`
+	SKU             string  `json:"sku"`
+	ProductName     string  `json:"productName"`
+	Location        string  `json:"location"`
+	SystemQuantity  int     `json:"systemQuantity"`
+	CountedQuantity *int    `json:"countedQuantity"`
+	Variance        int     `json:"variance"`
+	Unexpected      bool    `json:"unexpected"`
+	CountedBy       *string `json:"countedBy,omitempty"`
+	CountedAt       *string `json:"countedAt,omitempty"`
+}
+
+// ToResponse converts a StockTakeItem model to a StockTakeItemResponse
+func (i *StockTakeItem) ToResponse() StockTakeItemResponse {
+	// User visual handlers
+	var countedBy *string
+	if i.CountUser != nil {
+		countedBy = &i.CountUser.FullName
+	}
+
+	var countedAt *string
+	if i.CountedAt != nil {
+		formatted := i.CountedAt.Format("02-01-2006 15:04:05")
+		countedAt = &formatted
+	}
+
+	return StockTakeItemResponse{
+		ID:              i.ID,
+		SKU:             i.SKU,
+		ProductName:     i.ProductName,
+		Location:        i.Location,
+		SystemQuantity:  i.SystemQuantity,
+		CountedQuantity: i.CountedQuantity,
+		Variance:        i.Variance(),
+		Unexpected:      i.Unexpected,
+		CountedBy:       countedBy,
+		CountedAt:       countedAt,
+	}
+}
+
+// ToResponse converts a StockTake model to a StockTakeResponse
+func (st *StockTake) ToResponse() *StockTakeResponse {
+	var summary StockTakeSummary
+	items := make([]StockTakeItemResponse, len(st.Items))
+	for i := range st.Items {
+		items[i] = st.Items[i].ToResponse()
+
+		summary.TotalItems++
+		if st.Items[i].CountedQuantity != nil {
+			summary.CountedItems++
+		}
+		if variance := st.Items[i].Variance(); variance != 0 {
+			summary.VarianceItems++
+			summary.TotalVariance += variance
+		}
+		if st.Items[i].Unexpected {
+			summary.UnexpectedSKUs++
+		}
+	}
+
+	// User visual handlers
+	var createdBy string
+	if st.CreateUser != nil {
+		createdBy = st.CreateUser.FullName
+	}
+	var approvedBy, canceledBy *string
+	if st.ApproveUser != nil {
+		approvedBy = &st.ApproveUser.FullName
+	}
+	if st.CancelUser != nil {
+		canceledBy = &st.CancelUser.FullName
+	}
+
+	var approvedAt, canceledAt *string
+	if st.ApprovedAt != nil {
+		formatted := st.ApprovedAt.Format("02-01-2006 15:04:05")
+		approvedAt = &formatted
+	}
+	if st.CanceledAt != nil {
+		formatted := st.CanceledAt.Format("02-01-2006 15:04:05")
+		canceledAt = &formatted
+	}
+
+	return &StockTakeResponse{
+		ID:         st.ID,
+		Code:       st.Code,
+		Scope:      st.Scope,
+		ScopeValue: st.ScopeValue,
+		Status:     st.Status,
+		Notes:      st.Notes,
+		Summary:    summary,
+		Items:      items,
+		CreatedBy:  createdBy,
+		ApprovedBy: approvedBy,
+		ApprovedAt: approvedAt,
+		CanceledBy: canceledBy,
+		CanceledAt: canceledAt,
+		CreatedAt:  st.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:  st.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
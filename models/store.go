@@ -3,29 +3,58 @@ package models
 import "time"
 
 type Store struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	StoreCode string    `gorm:"uniqueIndex;not null;type:varchar(50)" json:"store_code"`
-	StoreName string    `gorm:"not null;type:varchar(100)" json:"store_name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	StoreCode string `gorm:"uniqueIndex;not null;type:varchar(50)" json:"store_code"`
+	StoreName string `gorm:"not null;type:varchar(100)" json:"store_name"`
+	// Warehouse location the store's orders are picked at, pickers must be checked in there to be assigned
+	LocationID *uint `gorm:"default:null;index" json:"location_id"`
+	// Branding printed on the invoices of the store's orders, the store name is used when no invoice name is set
+	InvoiceName    string `gorm:"type:varchar(150)" json:"invoice_name"`
+	InvoiceAddress string `gorm:"type:text" json:"invoice_address"`
+	InvoicePhone   string `gorm:"type:varchar(30)" json:"invoice_phone"`
+	InvoiceFooter  string `gorm:"type:text" json:"invoice_footer"`
+	// Storage key of the logo printed on the store's documents, a sanitized JPEG
+	LogoKey string `gorm:"type:varchar(255)" json:"logo_key"`
+	// Prefix of order numbers generated for the store's orders without a marketplace order id
+	OrderNumberPrefix string `gorm:"type:varchar(20)" json:"order_number_prefix"`
+	// Created automatically from an order naming an unknown store, waiting for an admin to approve or merge it
+	PendingReview bool      `gorm:"not null;default:false;index" json:"pending_review"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 // StoreResponse represents the store data returned in API responses
 type StoreResponse struct {
-	ID        uint   `json:"id"`
-	StoreCode string `json:"storeCode"`
-	StoreName string `json:"storeName"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	ID                uint   `json:"id"`
+	StoreCode         string `json:"storeCode"`
+	StoreName         string `json:"storeName"`
+	LocationID        *uint  `json:"locationId"`
+	InvoiceName       string `json:"invoiceName"`
+	InvoiceAddress    string `json:"invoiceAddress"`
+	InvoicePhone      string `json:"invoicePhone"`
+	InvoiceFooter     string `json:"invoiceFooter"`
+	HasLogo           bool   `json:"hasLogo"`
+	OrderNumberPrefix string `json:"orderNumberPrefix"`
+	PendingReview     bool   `json:"pendingReview"`
+	CreatedAt         string `json:"createdAt"`
+	UpdatedAt         string `json:"updatedAt"`
 }
 
 // ToResponse converts a Store model to a StoreResponse
 func (s *Store) ToResponse() *StoreResponse {
 	return &StoreResponse{
-		ID:        s.ID,
-		StoreCode: s.StoreCode,
-		StoreName: s.StoreName,
-		CreatedAt: s.CreatedAt.Format("02-01-2006 15:04:05"),
-		UpdatedAt: s.UpdatedAt.Format("02-01-2006 15:04:05"),
+		ID:                s.ID,
+		StoreCode:         s.StoreCode,
+		StoreName:         s.StoreName,
+		LocationID:        s.LocationID,
+		InvoiceName:       s.InvoiceName,
+		InvoiceAddress:    s.InvoiceAddress,
+		InvoicePhone:      s.InvoicePhone,
+		InvoiceFooter:     s.InvoiceFooter,
+		HasLogo:           s.LogoKey != "",
+		OrderNumberPrefix: s.OrderNumberPrefix,
+		PendingReview:     s.PendingReview,
+		CreatedAt:         s.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:         s.UpdatedAt.Format("02-01-2006 15:04:05"),
 	}
 }
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Team groups users into an organizational unit, e.g. picking team A or the customer service team
+type Team struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"uniqueIndex;not null;type:varchar(100)" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Members []TeamMember `gorm:"foreignKey:TeamID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;" json:"members,omitempty"`
+}
+
+// TeamMember is the membership of a user in a team, a user can belong to several teams
+type TeamMember struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TeamID    uint      `gorm:"not null;uniqueIndex:idx_team_member" json:"team_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_team_member;index" json:"user_id"`
+	AddedBy   uint      `gorm:"not null" json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Team *Team `gorm:"foreignKey:TeamID" json:"-"`
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TeamResponse represents the team data returned in API responses
+type TeamResponse struct {
+	ID          uint                 `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	MemberCount int                  `json:"memberCount"`
+	CreatedAt   string               `json:"createdAt"`
+	UpdatedAt   string               `json:"updatedAt"`
+	Members     []TeamMemberResponse `json:"members,omitempty"`
+}
+
+type TeamMemberResponse struct {
+	UserID   uint     `json:"userId"`
+	Username string   `json:"username"`
+	FullName string   `json:"fullName"`
+	Roles    []string `json:"roles"`
+	JoinedAt string   `json:"joinedAt"`
+}
+
+// ToResponse converts a Team model to a TeamResponse
+func (t *Team) ToResponse() *TeamResponse {
+	members := make([]TeamMemberResponse, 0, len(t.Members))
+	for _, member := range t.Members {
+		if member.User == nil {
+			continue
+		}
+		roleNames := make([]string, len(member.User.Roles))
+		for i, role := range member.User.Roles {
+			roleNames[i] = role.RoleName
+		}
+		members = append(members, TeamMemberResponse{
+			UserID:   member.UserID,
+			Username: member.User.Username,
+			FullName: member.User.FullName,
+			Roles:    roleNames,
+			JoinedAt: member.CreatedAt.Format("02-01-2006 15:04:05"),
+		})
+	}
+
+	return &TeamResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		MemberCount: len(t.Members),
+		CreatedAt:   t.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:   t.UpdatedAt.Format("02-01-2006 15:04:05"),
+		Members:     members,
+	}
+}
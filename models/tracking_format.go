@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// TrackingFormat is a tracking number format of an expedition. Tracking numbers starting with the expedition code
+// must match at least one of its active formats.
+type TrackingFormat struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ExpeditionID uint      `gorm:"not null;index" json:"expedition_id"`
+	Pattern      string    `gorm:"not null;type:varchar(255)" json:"pattern"` // regular expression matched against the whole tracking number
+	Description  string    `gorm:"type:varchar(255)" json:"description"`
+	Example      string    `gorm:"type:varchar(100)" json:"example"`
+	IsActive     bool      `gorm:"default:true" json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Expedition *Expedition `gorm:"foreignKey:ExpeditionID" json:"expedition,omitempty"`
+}
+
+// TrackingFormatResponse represents the tracking format data returned in API responses
+type TrackingFormatResponse struct {
+	ID             uint   `json:"id"`
+	ExpeditionID   uint   `json:"expeditionId"`
+	ExpeditionCode string `json:"expeditionCode"`
+	ExpeditionName string `json:"expeditionName"`
+	Pattern        string `json:"pattern"`
+	Description    string `json:"description"`
+	Example        string `json:"example"`
+	IsActive       bool   `json:"isActive"`
+	CreatedAt      string `json:"createdAt"`
+	UpdatedAt      string `json:"updatedAt"`
+}
+
+// ToResponse converts a TrackingFormat model to a TrackingFormatResponse
+func (f *TrackingFormat) ToResponse() *TrackingFormatResponse {
+	// Expedition visual handlers
+	var expeditionCode, expeditionName string
+	if f.Expedition != nil {
+		expeditionCode = f.Expedition.ExpeditionCode
+		expeditionName = f.Expedition.ExpeditionName
+	}
+
+	return &TrackingFormatResponse{
+		ID:             f.ID,
+		ExpeditionID:   f.ExpeditionID,
+		ExpeditionCode: expeditionCode,
+		ExpeditionName: expeditionName,
+		Pattern:        f.Pattern,
+		Description:    f.Description,
+		Example:        f.Example,
+		IsActive:       f.IsActive,
+		CreatedAt:      f.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:      f.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Training task statuses
+const (
+	TrainingTaskStatusOpen    = "open"
+	TrainingTaskStatusCleared = "cleared"
+)
+
+// TrainingTask flags a user for retraining after too many complaints were attributed to them in the rolling window.
+// A user has at most one open task, a coordinator or HRD clears it once the retraining is done.
+type TrainingTask struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"not null;index" json:"user_id"`
+	Reason         string     `gorm:"not null;type:text" json:"reason"`
+	ComplaintCount int        `gorm:"not null" json:"complaint_count"` // attributed complaints in the window when the task was created
+	WindowDays     int        `gorm:"not null" json:"window_days"`
+	Status         string     `gorm:"not null;default:'open';type:varchar(20);index" json:"status"`
+	ClearanceNote  string     `gorm:"type:text" json:"clearance_note"`
+	ClearedBy      *uint      `gorm:"default:null" json:"cleared_by"`
+	ClearedAt      *time.Time `gorm:"default:null" json:"cleared_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	User      *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ClearUser *User `gorm:"foreignKey:ClearedBy" json:"clear_user,omitempty"`
+}
+
+// TrainingTaskResponse represents the training task data returned in API responses
+type TrainingTaskResponse struct {
+	ID             uint    `json:"id"`
+	UserID         uint    `json:"userId"`
+	User           string  `json:"user"`
+	Reason         string  `json:"reason"`
+	ComplaintCount int     `json:"complaintCount"`
+	WindowDays     int     `json:"windowDays"`
+	Status         string  `json:"status"`
+	ClearanceNote  string  `json:"clearanceNote,omitempty"`
+	ClearedBy      *string `json:"clearedBy,omitempty"`
+	ClearedAt      *string `json:"clearedAt,omitempty"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
+
+// ToResponse converts a TrainingTask model to a TrainingTaskResponse
+func (tt *TrainingTask) ToResponse() *TrainingTaskResponse {
+	// User visual handlers
+	var user string
+	if tt.User != nil {
+		user = tt.User.FullName
+	}
+	var clearedBy *string
+	if tt.ClearUser != nil {
+		clearedBy = &tt.ClearUser.FullName
+	}
+
+	var clearedAt *string
+	if tt.ClearedAt != nil {
+		formatted := tt.ClearedAt.Format("02-01-2006 15:04:05")
+		clearedAt = &formatted
+	}
+
+	return &TrainingTaskResponse{
+		ID:             tt.ID,
+		UserID:         tt.UserID,
+		User:           user,
+		Reason:         tt.Reason,
+		ComplaintCount: tt.ComplaintCount,
+		WindowDays:     tt.WindowDays,
+		Status:         tt.Status,
+		ClearanceNote:  tt.ClearanceNote,
+		ClearedBy:      clearedBy,
+		ClearedAt:      clearedAt,
+		CreatedAt:      tt.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:      tt.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
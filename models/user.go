@@ -10,6 +10,7 @@ type User struct {
 	Password  string     `gorm:"not null" json:"-"`
 	FullName  string     `gorm:"not null;type:varchar(100)" json:"full_name"`
 	Email     string     `gorm:"uniqueIndex;not null;type:varchar(100)" json:"email"`
+	Phone     string     `gorm:"type:varchar(20)" json:"phone"` // WhatsApp number in international format, used for OTP
 	IsActive  bool       `gorm:"default:true" json:"is_active"`
 	LastLogin *time.Time `gorm:"default:null" json:"last_login"`
 	CreatedAt time.Time  `json:"created_at"`
@@ -43,6 +44,7 @@ type UserResponse struct {
 	Username  string   `json:"username"`
 	FullName  string   `json:"fullName"`
 	Email     string   `json:"email"`
+	Phone     string   `json:"phone,omitempty"`
 	IsActive  bool     `json:"isActive"`
 	LastLogin *string  `json:"lastLogin,omitempty"`
 	CreatedAt string   `json:"createdAt"`
@@ -76,6 +78,7 @@ func (u *User) ToResponse() *UserResponse {
 		Username:  u.Username,
 		FullName:  u.FullName,
 		Email:     u.Email,
+		Phone:     u.Phone,
 		IsActive:  u.IsActive,
 		LastLogin: lastLoginStr,
 		CreatedAt: u.CreatedAt.Format("02-01-2006 15:04:05"),
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// UserInvitation is an onboarding invitation emailed to a new user
+type UserInvitation struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	TokenHash      string     `gorm:"uniqueIndex;not null;type:varchar(64)" json:"-"`
+	Email          string     `gorm:"not null;type:varchar(100);index" json:"email"`
+	FullName       string     `gorm:"not null;type:varchar(100)" json:"full_name"`
+	RoleName       string     `gorm:"type:varchar(50)" json:"role_name"`
+	Status         string     `gorm:"not null;type:varchar(20);default:pending;index" json:"status"` // pending, accepted, revoked
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	InvitedBy      uint       `gorm:"not null" json:"invited_by"`
+	AcceptedUserID *uint      `gorm:"default:null" json:"accepted_user_id"`
+	AcceptedAt     *time.Time `gorm:"default:null" json:"accepted_at"`
+	RevokedBy      *uint      `gorm:"default:null" json:"revoked_by"`
+	RevokedAt      *time.Time `gorm:"default:null" json:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	InviteUser   *User `gorm:"foreignKey:InvitedBy" json:"invite_user,omitempty"`
+	AcceptedUser *User `gorm:"foreignKey:AcceptedUserID" json:"accepted_user,omitempty"`
+	RevokeUser   *User `gorm:"foreignKey:RevokedBy" json:"revoke_user,omitempty"`
+}
+
+// EffectiveStatus returns the status with pending invitations past their expiry reported as expired
+func (i *UserInvitation) EffectiveStatus(now time.Time) string {
+	if i.Status == "pending" && now.After(i.ExpiresAt) {
+		return "expired"
+	}
+	return i.Status
+}
+
+type UserInvitationResponse struct {
+	ID         uint    `json:"id"`
+	Email      string  `json:"email"`
+	FullName   string  `json:"fullName"`
+	RoleName   string  `json:"roleName,omitempty"`
+	Status     string  `json:"status"`
+	ExpiresAt  string  `json:"expiresAt"`
+	InvitedBy  string  `json:"invitedBy"`
+	AcceptedBy *string `json:"acceptedBy,omitempty"` // username chosen by the invitee
+	AcceptedAt *string `json:"acceptedAt,omitempty"`
+	RevokedBy  *string `json:"revokedBy,omitempty"`
+	RevokedAt  *string `json:"revokedAt,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+// ToResponse converts a UserInvitation model to a UserInvitationResponse
+func (i *UserInvitation) ToResponse() *UserInvitationResponse {
+	// User visual handlers
+	var invitedBy string
+	if i.InviteUser != nil {
+		invitedBy = i.InviteUser.FullName
+	}
+	var acceptedBy, revokedBy *string
+	if i.AcceptedUser != nil {
+		acceptedBy = &i.AcceptedUser.Username
+	}
+	if i.RevokeUser != nil {
+		revokedBy = &i.RevokeUser.FullName
+	}
+
+	var acceptedAt, revokedAt *string
+	if i.AcceptedAt != nil {
+		formatted := i.AcceptedAt.Format("02-01-2006 15:04:05")
+		acceptedAt = &formatted
+	}
+	if i.RevokedAt != nil {
+		formatted := i.RevokedAt.Format("02-01-2006 15:04:05")
+		revokedAt = &formatted
+	}
+
+	return &UserInvitationResponse{
+		ID:         i.ID,
+		Email:      i.Email,
+		FullName:   i.FullName,
+		RoleName:   i.RoleName,
+		Status:     i.EffectiveStatus(time.Now()),
+		ExpiresAt:  i.ExpiresAt.Format("02-01-2006 15:04:05"),
+		InvitedBy:  invitedBy,
+		AcceptedBy: acceptedBy,
+		AcceptedAt: acceptedAt,
+		RevokedBy:  revokedBy,
+		RevokedAt:  revokedAt,
+		CreatedAt:  i.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
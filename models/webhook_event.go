@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Inbound webhook event statuses
+const (
+	WebhookEventReceived  = "received"  // logged, the handler has not answered yet
+	WebhookEventProcessed = "processed" // the handler answered with a 2xx
+	WebhookEventFailed    = "failed"    // the handler answered with an error, the payload can be reprocessed
+	WebhookEventRejected  = "rejected"  // stopped by signature, timestamp or replay checks, never handled
+)
+
+// Inbound webhook signature statuses
+const (
+	WebhookSignatureVerified = "verified"
+	WebhookSignatureUnsigned = "unsigned" // no secret is configured for the source
+	WebhookSignatureInvalid  = "invalid"
+)
+
+// InboundWebhookEvent logs a webhook received from an integration with the payload as sent, so payload mapping
+// issues can be debugged and the event reprocessed once the mapping is fixed
+type InboundWebhookEvent struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	Source            string     `gorm:"not null;type:varchar(50);index;index:idx_webhook_events_source_nonce,priority:1" json:"source"` // ginee, shopee, tokopedia, 3pl
+	Endpoint          string     `gorm:"not null;type:varchar(50);index" json:"endpoint"`                                                // handler the event was received on, replayed on reprocess
+	Method            string     `gorm:"type:varchar(10)" json:"method"`
+	Path              string     `gorm:"type:text" json:"path"`
+	Params            string     `gorm:"type:text" json:"params"`  // JSON route parameters
+	Headers           string     `gorm:"type:text" json:"headers"` // JSON of the headers relevant to the integration
+	ContentType       string     `gorm:"type:varchar(100)" json:"content_type"`
+	Payload           string     `gorm:"type:text" json:"payload"`
+	Nonce             string     `gorm:"type:varchar(255);index:idx_webhook_events_source_nonce,priority:2" json:"nonce"`
+	Signature         string     `gorm:"type:varchar(128);index" json:"signature"` // normalized signature of signed webhooks, replays reuse it
+	SignatureStatus   string     `gorm:"type:varchar(20);not null" json:"signature_status"`
+	Status            string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	ResponseStatus    int        `gorm:"type:int;default:0" json:"response_status"`
+	ResponseBody      string     `gorm:"type:text" json:"response_body"`
+	Error             string     `gorm:"type:text" json:"error"`
+	APIKeyID          *uint      `gorm:"default:null" json:"api_key_id"`
+	UserID            *uint      `gorm:"default:null" json:"user_id"` // owner of the API key the event was sent with
+	IPAddress         string     `gorm:"type:varchar(45)" json:"ip_address"`
+	ReprocessCount    int        `gorm:"type:int;default:0" json:"reprocess_count"`
+	LastReprocessedAt *time.Time `gorm:"default:null" json:"last_reprocessed_at"`
+	LastReprocessedBy *uint      `gorm:"default:null" json:"last_reprocessed_by"`
+	CreatedAt         time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	ReprocessUser *User `gorm:"foreignKey:LastReprocessedBy" json:"reprocess_user,omitempty"`
+}
+
+// ParamMap returns the route parameters the event was received with
+func (e *InboundWebhookEvent) ParamMap() map[string]string {
+	params := map[string]string{}
+	if e.Params != "" {
+		_ = json.Unmarshal([]byte(e.Params), &params)
+	}
+	return params
+}
+
+// HeaderMap returns the logged headers of the event
+func (e *InboundWebhookEvent) HeaderMap() map[string]string {
+	headers := map[string]string{}
+	if e.Headers != "" {
+		_ = json.Unmarshal([]byte(e.Headers), &headers)
+	}
+	return headers
+}
+
+// InboundWebhookEventResponse represents the inbound webhook event data returned in API responses
+type InboundWebhookEventResponse struct {
+	ID                uint              `json:"id"`
+	Source            string            `json:"source"`
+	Endpoint          string            `json:"endpoint"`
+	Method            string            `json:"method"`
+	Path              string            `json:"path"`
+	Params            map[string]string `json:"params,omitempty"`
+	Headers           map[string]string `json:"headers,omitempty"`
+	ContentType       string            `json:"contentType"`
+	Payload           string            `json:"payload,omitempty"` // only on the detail
+	Nonce             string            `json:"nonce,omitempty"`
+	SignatureStatus   string            `json:"signatureStatus"`
+	Status            string            `json:"status"`
+	ResponseStatus    int               `json:"responseStatus"`
+	ResponseBody      string            `json:"responseBody,omitempty"` // only on the detail
+	Error             string            `json:"error,omitempty"`
+	APIKeyID          *uint             `json:"apiKeyId,omitempty"`
+	IPAddress         string            `json:"ipAddress"`
+	ReprocessCount    int               `json:"reprocessCount"`
+	LastReprocessedAt *string           `json:"lastReprocessedAt,omitempty"`
+	LastReprocessedBy *string           `json:"lastReprocessedBy,omitempty"`
+	CreatedAt         string            `json:"createdAt"`
+}
+
+// ToResponse converts an InboundWebhookEvent model to an InboundWebhookEventResponse, with the payload and
+// response body when detailed
+func (e *InboundWebhookEvent) ToResponse(detailed bool) *InboundWebhookEventResponse {
+	// User visual handler
+	var reprocessedBy *string
+	if e.ReprocessUser != nil {
+		reprocessedBy = &e.ReprocessUser.FullName
+	}
+
+	var reprocessedAt *string
+	if e.LastReprocessedAt != nil {
+		formatted := e.LastReprocessedAt.Format("02-01-2006 15:04:05")
+		reprocessedAt = &formatted
+	}
+
+	response := &InboundWebhookEventResponse{
+		ID:                e.ID,
+		Source:            e.Source,
+		Endpoint:          e.Endpoint,
+		Method:            e.Method,
+		Path:              e.Path,
+		Params:            e.ParamMap(),
+		ContentType:       e.ContentType,
+		Nonce:             e.Nonce,
+		SignatureStatus:   e.SignatureStatus,
+		Status:            e.Status,
+		ResponseStatus:    e.ResponseStatus,
+		Error:             e.Error,
+		APIKeyID:          e.APIKeyID,
+		IPAddress:         e.IPAddress,
+		ReprocessCount:    e.ReprocessCount,
+		LastReprocessedAt: reprocessedAt,
+		LastReprocessedBy: reprocessedBy,
+		CreatedAt:         e.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+	if detailed {
+		response.Headers = e.HeaderMap()
+		response.Payload = e.Payload
+		response.ResponseBody = e.ResponseBody
+	}
+	return response
+}
@@ -0,0 +1,164 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Order event types delivered to webhook subscriptions, besides the processing status an order moved to
+const (
+	OrderEventCreated = "order_created"
+	OrderEventUpdated = "order_updated" // changed without moving to another processing status
+	OrderEventDeleted = "order_deleted"
+	OrderEventTest    = "test" // sample payload of a test delivery
+)
+
+// Webhook subscription delivery statuses
+const (
+	WebhookDeliverySent   = "sent"
+	WebhookDeliveryFailed = "failed"
+)
+
+// IsValidOrderEventType reports whether an order event can be subscribed to
+func IsValidOrderEventType(eventType string) bool {
+	switch eventType {
+	case OrderEventCreated, OrderEventUpdated, OrderEventDeleted:
+		return true
+	}
+	return IsValidProcessingStatus(eventType)
+}
+
+// WebhookSubscription delivers the order events of the change feed matching its filters to the URL of an
+// integration. Empty filters match every value. The cursor is the last change event handled, events are
+// delivered in cursor order and retried until the URL accepts them.
+type WebhookSubscription struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	Name           string     `gorm:"not null;type:varchar(100)" json:"name"`
+	URL            string     `gorm:"not null;type:text" json:"url"`
+	Secret         string     `gorm:"not null;type:varchar(64)" json:"-"` // signs the deliveries, only shown on creation
+	EventTypes     string     `gorm:"type:text" json:"event_types"`       // comma separated order event types
+	Channels       string     `gorm:"type:text" json:"channels"`          // comma separated channel names
+	Stores         string     `gorm:"type:text" json:"stores"`            // comma separated store names
+	Statuses       string     `gorm:"type:text" json:"statuses"`          // comma separated processing statuses
+	Active         bool       `gorm:"not null" json:"active"`
+	Cursor         uint64     `gorm:"not null;default:0" json:"cursor"`
+	FailedAttempts int        `gorm:"not null;default:0" json:"failed_attempts"` // consecutive failed deliveries of the event after the cursor
+	NextAttemptAt  *time.Time `gorm:"default:null;index" json:"next_attempt_at"`
+	LastDeliveryAt *time.Time `gorm:"default:null" json:"last_delivery_at"`
+	LastStatus     string     `gorm:"type:varchar(20)" json:"last_status"` // sent or failed
+	LastError      string     `gorm:"type:text" json:"last_error"`
+	CreatedBy      uint       `gorm:"not null;index" json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	CreateUser *User `gorm:"foreignKey:CreatedBy" json:"create_user,omitempty"`
+}
+
+// splitSubscriptionList returns the values of a comma separated filter
+func splitSubscriptionList(list string) []string {
+	values := []string{}
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// EventTypeList returns the subscribed event types, every event type when empty
+func (ws *WebhookSubscription) EventTypeList() []string {
+	return splitSubscriptionList(ws.EventTypes)
+}
+
+// ChannelList returns the channels of the subscribed orders, every channel when empty
+func (ws *WebhookSubscription) ChannelList() []string {
+	return splitSubscriptionList(ws.Channels)
+}
+
+// StoreList returns the stores of the subscribed orders, every store when empty
+func (ws *WebhookSubscription) StoreList() []string {
+	return splitSubscriptionList(ws.Stores)
+}
+
+// StatusList returns the processing statuses of the subscribed orders, every status when empty
+func (ws *WebhookSubscription) StatusList() []string {
+	return splitSubscriptionList(ws.Statuses)
+}
+
+// Matches reports whether an order event passes the filters of the subscription. Deleted orders carry no
+// channel, store or status and only match subscriptions without those filters.
+func (ws *WebhookSubscription) Matches(eventType, channel, store, status string) bool {
+	matches := func(values []string, value string) bool {
+		if len(values) == 0 {
+			return true
+		}
+		for _, allowed := range values {
+			if strings.EqualFold(allowed, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return matches(ws.EventTypeList(), eventType) && matches(ws.ChannelList(), channel) &&
+		matches(ws.StoreList(), store) && matches(ws.StatusList(), status)
+}
+
+// WebhookSubscriptionResponse represents the webhook subscription data returned in API responses
+type WebhookSubscriptionResponse struct {
+	ID             uint     `json:"id"`
+	Name           string   `json:"name"`
+	URL            string   `json:"url"`
+	EventTypes     []string `json:"eventTypes"`
+	Channels       []string `json:"channels"`
+	Stores         []string `json:"stores"`
+	Statuses       []string `json:"statuses"`
+	Active         bool     `json:"active"`
+	Cursor         uint64   `json:"cursor"`
+	FailedAttempts int      `json:"failedAttempts"`
+	NextAttemptAt  *string  `json:"nextAttemptAt,omitempty"`
+	LastDeliveryAt *string  `json:"lastDeliveryAt,omitempty"`
+	LastStatus     string   `json:"lastStatus,omitempty"`
+	LastError      string   `json:"lastError,omitempty"`
+	CreatedBy      string   `json:"createdBy"`
+	CreatedAt      string   `json:"createdAt"`
+	UpdatedAt      string   `json:"updatedAt"`
+}
+
+// ToResponse converts a WebhookSubscription model to a WebhookSubscriptionResponse
+func (ws *WebhookSubscription) ToResponse() *WebhookSubscriptionResponse {
+	// User visual handlers
+	var createdBy string
+	if ws.CreateUser != nil {
+		createdBy = ws.CreateUser.FullName
+	}
+
+	var nextAttemptAt, lastDeliveryAt *string
+	if ws.NextAttemptAt != nil {
+		formatted := ws.NextAttemptAt.Format("02-01-2006 15:04:05")
+		nextAttemptAt = &formatted
+	}
+	if ws.LastDeliveryAt != nil {
+		formatted := ws.LastDeliveryAt.Format("02-01-2006 15:04:05")
+		lastDeliveryAt = &formatted
+	}
+
+	return &WebhookSubscriptionResponse{
+		ID:             ws.ID,
+		Name:           ws.Name,
+		URL:            ws.URL,
+		EventTypes:     ws.EventTypeList(),
+		Channels:       ws.ChannelList(),
+		Stores:         ws.StoreList(),
+		Statuses:       ws.StatusList(),
+		Active:         ws.Active,
+		Cursor:         ws.Cursor,
+		FailedAttempts: ws.FailedAttempts,
+		NextAttemptAt:  nextAttemptAt,
+		LastDeliveryAt: lastDeliveryAt,
+		LastStatus:     ws.LastStatus,
+		LastError:      ws.LastError,
+		CreatedBy:      createdBy,
+		CreatedAt:      ws.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:      ws.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -0,0 +1,125 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Zone is an area of the warehouse map, owning the rack locations that start with one of its location prefixes
+type Zone struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	ZoneCode         string    `gorm:"uniqueIndex;not null;type:varchar(20)" json:"zone_code"`
+	Name             string    `gorm:"not null;type:varchar(100)" json:"name"`
+	LocationPrefixes string    `gorm:"type:text" json:"location_prefixes"` // comma separated rack location prefixes, e.g. "A-,B-01"
+	SortOrder        int       `gorm:"not null" json:"sort_order"`         // position on the warehouse map, in walking order
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// PrefixList returns the rack location prefixes of the zone
+func (z *Zone) PrefixList() []string {
+	var prefixes []string
+	for _, prefix := range strings.Split(z.LocationPrefixes, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
+
+// PickZoneVisit records the zone a pick scan was made in, resolved from the rack location of the picked SKU
+type PickZoneVisit struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ZoneID      *uint     `gorm:"default:null;index" json:"zone_id"` // nil when the rack location is not in any zone
+	OrderID     uint      `gorm:"not null;index" json:"order_id"`
+	PickBatchID *uint     `gorm:"default:null;index" json:"pick_batch_id"`
+	PickedBy    uint      `gorm:"not null;index" json:"picked_by"`
+	SKU         string    `gorm:"not null;type:varchar(255)" json:"sku"`
+	Location    string    `gorm:"type:varchar(100)" json:"location"`
+	Quantity    int       `gorm:"not null" json:"quantity"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+
+	Zone     *Zone  `gorm:"foreignKey:ZoneID" json:"zone,omitempty"`
+	Order    *Order `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	PickUser *User  `gorm:"foreignKey:PickedBy" json:"pick_user,omitempty"`
+}
+
+// ZoneResponse represents the zone data returned in API responses
+type ZoneResponse struct {
+	ID               uint     `json:"id"`
+	ZoneCode         string   `json:"zoneCode"`
+	Name             string   `json:"name"`
+	LocationPrefixes []string `json:"locationPrefixes"`
+	SortOrder        int      `json:"sortOrder"`
+	CreatedAt        string   `json:"createdAt"`
+	UpdatedAt        string   `json:"updatedAt"`
+}
+
+// ToResponse converts a Zone model to a ZoneResponse
+func (z *Zone) ToResponse() *ZoneResponse {
+	prefixes := z.PrefixList()
+	if prefixes == nil {
+		prefixes = []string{}
+	}
+
+	return &ZoneResponse{
+		ID:               z.ID,
+		ZoneCode:         z.ZoneCode,
+		Name:             z.Name,
+		LocationPrefixes: prefixes,
+		SortOrder:        z.SortOrder,
+		CreatedAt:        z.CreatedAt.Format("02-01-2006 15:04:05"),
+		UpdatedAt:        z.UpdatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
+
+// PickZoneVisitResponse represents the pick zone visit data returned in API responses
+type PickZoneVisitResponse struct {
+	ID             uint   `json:"id"`
+	ZoneCode       string `json:"zoneCode,omitempty"`
+	ZoneName       string `json:"zoneName,omitempty"`
+	OrderID        uint   `json:"orderId"`
+	TrackingNumber string `json:"trackingNumber"`
+	PickBatchID    *uint  `json:"pickBatchId,omitempty"`
+	PickedBy       string `json:"pickedBy"`
+	SKU            string `json:"sku"`
+	Location       string `json:"location"`
+	Quantity       int    `json:"quantity"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// ToResponse converts a PickZoneVisit model to a PickZoneVisitResponse
+func (pzv *PickZoneVisit) ToResponse() *PickZoneVisitResponse {
+	// Zone visual handlers
+	var zoneCode, zoneName string
+	if pzv.Zone != nil {
+		zoneCode = pzv.Zone.ZoneCode
+		zoneName = pzv.Zone.Name
+	}
+
+	// Order visual handlers
+	var trackingNumber string
+	if pzv.Order != nil {
+		trackingNumber = pzv.Order.TrackingNumber
+	}
+
+	// User visual handlers
+	var pickedBy string
+	if pzv.PickUser != nil {
+		pickedBy = pzv.PickUser.FullName
+	}
+
+	return &PickZoneVisitResponse{
+		ID:             pzv.ID,
+		ZoneCode:       zoneCode,
+		ZoneName:       zoneName,
+		OrderID:        pzv.OrderID,
+		TrackingNumber: trackingNumber,
+		PickBatchID:    pzv.PickBatchID,
+		PickedBy:       pickedBy,
+		SKU:            pzv.SKU,
+		Location:       pzv.Location,
+		Quantity:       pzv.Quantity,
+		CreatedAt:      pzv.CreatedAt.Format("02-01-2006 15:04:05"),
+	}
+}
@@ -3,7 +3,10 @@ package routes
 import (
 	"livo-fiber-backend/config"
 	"livo-fiber-backend/controllers"
+	publicdocs "livo-fiber-backend/docs/public"
 	"livo-fiber-backend/middleware"
+	"livo-fiber-backend/models"
+	"net/url"
 	"time"
 
 	"github.com/gofiber/fiber/v3"
@@ -14,31 +17,102 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 
 	// Controllers
 	authController := controllers.NewAuthController(cfg, db)
+	invitationController := controllers.NewInvitationController(cfg, db)
 	userController := controllers.NewUserController(db)
 	roleController := controllers.NewRoleController(db)
 	boxController := controllers.NewBoxController(db)
 	channelController := controllers.NewChannelController(db)
 	expeditionController := controllers.NewExpeditionController(db)
+	trackingFormatController := controllers.NewTrackingFormatController(db)
 	storeController := controllers.NewStoreController(db)
+	printTemplateController := controllers.NewPrintTemplateController(db)
 	productController := controllers.NewProductController(db)
-	orderController := controllers.NewOrderController(db)
+	orderController := controllers.NewOrderController(cfg, db)
+	orderLockController := controllers.NewOrderLockController(cfg, db)
 	qcRibbonController := controllers.NewQCRibbonController(db)
 	qcOnlineController := controllers.NewQCOnlineController(db)
+	qcController := controllers.NewQCController(cfg, db)
+	qcStationController := controllers.NewQCStationController(db)
+	zoneController := controllers.NewZoneController(db)
+	stagingAreaController := controllers.NewStagingAreaController(db)
 	outboundController := controllers.NewOutboundController(db)
+	handoverController := controllers.NewHandoverController(db)
 	ribbonFlowController := controllers.NewRibbonFlowController(db)
 	onlineFlowController := controllers.NewOnlineFlowController(db)
-	reportController := controllers.NewReportController(db)
+	reportController := controllers.NewReportController(cfg, db)
+	reportScheduleController := controllers.NewReportScheduleController(cfg, db)
+	reportAccessController := controllers.NewReportAccessController(db)
+	chartController := controllers.NewChartController(db)
+	apiKeyController := controllers.NewAPIKeyController(db)
 	lostFoundController := controllers.NewLostFoundController(db)
 	returnController := controllers.NewReturnController(db)
 	returnPickedOrderController := controllers.NewPickedOrderController(db)
-	complainController := controllers.NewComplainController(db)
+	complainController := controllers.NewComplainController(cfg, db)
+	complainRootCauseController := controllers.NewComplainRootCauseController(db)
+	exportJobController := controllers.NewExportJobController(cfg, db)
+	changeFeedController := controllers.NewChangeFeedController(cfg, db)
+	complainFeeDisputeController := controllers.NewComplainFeeDisputeController(db)
+	complainSettlementController := controllers.NewComplainSettlementController(db)
+	trainingTaskController := controllers.NewTrainingTaskController(db)
 	mobileChannelController := controllers.NewMobileChannelController(db)
 	mobileStoreController := controllers.NewMobileStoreController(db)
 	mobileReturnController := controllers.NewMobileReturnController(db)
-	mobileOrderController := controllers.NewMobileOrderController(db)
-	attendanceController := controllers.NewAttendanceController(db)
-	mobileAttendanceController := controllers.NewMobileAttendanceController(db)
+	mobileOrderController := controllers.NewMobileOrderController(cfg, db)
+	attendanceController := controllers.NewAttendanceController(cfg, db)
+	mobileAttendanceController := controllers.NewMobileAttendanceController(cfg, db)
 	locationController := controllers.NewLocationController(db)
+	teamController := controllers.NewTeamController(db)
+	skillController := controllers.NewSkillController(db)
+	substitutionController := controllers.NewSubstitutionController(db)
+	productNameController := controllers.NewProductNameController(db)
+	pickBatchController := controllers.NewPickBatchController(db)
+	approvalController := controllers.NewApprovalController(cfg, db)
+	retentionController := controllers.NewRetentionController(cfg, db)
+	dataRepairController := controllers.NewDataRepairController(db)
+	legacyImportController := controllers.NewLegacyImportController(cfg, db)
+	accountingController := controllers.NewAccountingController(cfg, db)
+	adminController := controllers.NewAdminController(cfg, db)
+	orderVolumeController := controllers.NewOrderVolumeController(cfg, db)
+	backorderController := controllers.NewBackorderController(db)
+	orderReferenceController := controllers.NewOrderReferenceController(db)
+	orderSerialController := controllers.NewOrderSerialController(db)
+
+	// Upload size limits
+	imageUploadLimit := middleware.BodyLimitMiddleware(cfg.MaxImageUploadMB)
+	faceBatchUploadLimit := middleware.BodyLimitMiddleware(cfg.MaxFaceBatchMB)
+
+	// Brute-force protection on endpoints that check passwords
+	loginRateLimit := middleware.AuthRateLimitMiddleware(cfg, "login")
+	loginGuard := middleware.BruteForceMiddleware(cfg, "login")
+	passwordResetRateLimit := middleware.AuthRateLimitMiddleware(cfg, "password_reset")
+	passwordResetGuard := middleware.PasswordResetGuardMiddleware(cfg)
+	manualAttendanceRateLimit := middleware.AuthRateLimitMiddleware(cfg, "manual_attendance")
+	manualAttendanceGuard := middleware.BruteForceMiddleware(cfg, "manual_attendance")
+	coordinatorRateLimit := middleware.AuthRateLimitMiddleware(cfg, "coordinator_approval")
+	coordinatorGuard := middleware.BruteForceMiddleware(cfg, "coordinator_approval")
+	importUploadLimit := middleware.BodyLimitMiddleware(cfg.MaxImportUploadMB)
+	metricsController := controllers.NewMetricsController(db)
+	accessLogController := controllers.NewAccessLogController(db)
+	securityEventController := controllers.NewSecurityEventController(db)
+	webhookEventController := controllers.NewWebhookEventController(db)
+	webhookSubscriptionController := controllers.NewWebhookSubscriptionController(db)
+	shadowDivergenceController := controllers.NewShadowDivergenceController(db)
+	featureFlagController := controllers.NewFeatureFlagController(db)
+	maintenanceController := controllers.NewMaintenanceController()
+	faultInjectionController := controllers.NewFaultInjectionController()
+	metaController := controllers.NewMetaController(cfg)
+	timeController := controllers.NewTimeController(cfg)
+	inventoryController := controllers.NewInventoryController(db)
+	stockTakeController := controllers.NewStockTakeController(db)
+	inboundController := controllers.NewInboundController(db)
+	notificationController := controllers.NewNotificationController(db)
+	publicTrackController := controllers.NewPublicTrackController(db)
+	fileController := controllers.NewFileController(cfg)
+
+	// Inbound webhooks are signature checked, replay protected and logged for reprocessing
+	webhookEventController.RegisterReplay("ginee_status", orderController.ReceiveGineeStatusWebhook)
+	webhookEventController.RegisterReplay("complain_dispute", complainController.ReceiveMarketplaceDispute)
+	webhookEventController.RegisterReplay("handover_ack", handoverController.AcknowledgeHandover)
 
 	// Public routes
 	api := app.Group("/api")
@@ -54,45 +128,29 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 		})
 	})
 
-	// API Documentation routes - Serve static swagger files
-	app.Get("/docs/swagger.json", func(c fiber.Ctx) error {
+	// API Documentation routes
+	// The public spec only contains endpoints meant for external consumers and is open to everyone.
+	// The internal spec documents the whole API and requires an admin token or the docs API key.
+	setupPublicDocsRoutes(app)
+
+	docsAccess := middleware.DocsAccessMiddleware(cfg, []string{"developer", "superadmin"})
+
+	app.Get("/docs/swagger.json", docsAccess, func(c fiber.Ctx) error {
 		return c.SendFile("./docs/swagger.json")
 	})
 
-	app.Get("/docs/swagger.yaml", func(c fiber.Ctx) error {
+	app.Get("/docs/swagger.yaml", docsAccess, func(c fiber.Ctx) error {
 		return c.SendFile("./docs/swagger.yaml")
 	})
 
 	// Swagger UI HTML page
-	app.Get("/docs", func(c fiber.Ctx) error {
-		html := `<!DOCTYPE html>
-<html lang="en">
-<head>
-  <meta charset="utf-8" />
-  <meta name="viewport" content="width=device-width, initial-scale=1" />
-  <meta name="description" content="SwaggerUI" />
-  <title>Livo API - Swagger UI</title>
-  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui.css" />
-</head>
-<body>
-<div id="swagger-ui"></div>
-<script src="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-bundle.js" crossorigin></script>
-<script>
-  window.onload = () => {
-    window.ui = SwaggerUIBundle({
-      url: '/docs/swagger.json',
-      dom_id: '#swagger-ui',
-    });
-  };
-</script>
-</body>
-</html>`
+	app.Get("/docs", docsAccess, func(c fiber.Ctx) error {
 		c.Set("Content-Type", "text/html")
-		return c.SendString(html)
+		return c.SendString(swaggerUIPage(docsSpecURL(c, "/docs/swagger.json")))
 	})
 
 	// RapiDoc HTML page
-	app.Get("/rapidoc", func(c fiber.Ctx) error {
+	app.Get("/rapidoc", docsAccess, func(c fiber.Ctx) error {
 		html := `<!doctype html>
 <html>
 <head>
@@ -103,7 +161,7 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 </head>
 <body>
   <rapi-doc
-        spec-url="/docs/swagger.yaml"
+        spec-url="` + docsSpecURL(c, "/docs/swagger.yaml") + `"
         theme="dark"
         bg-color="#1a1a1a"
         text-color="#f0f0f0"
@@ -154,16 +212,30 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	// Auth routes (public)
 	auth := api.Group("/auth")
 	auth.Post("/register", authController.Register)
-	auth.Post("/login", authController.Login)
+	auth.Post("/login", loginRateLimit, loginGuard, authController.Login)
 	auth.Post("/refresh", authController.RefreshToken)
+	auth.Post("/forgot-password", passwordResetRateLimit, passwordResetGuard, authController.ForgotPassword)
+	auth.Post("/reset-password", passwordResetRateLimit, passwordResetGuard, authController.ResetPassword)
+
+	// Invitation routes (public, authorized by the emailed token)
+	invitations := api.Group("/invitations")
+	invitations.Get("/:token", invitationController.GetInvitationByToken)
+	invitations.Post("/:token/accept", imageUploadLimit, invitationController.AcceptInvitation)
 
 	// Attendances routes (public)
 	attendances := api.Group("/attendances")
-	attendances.Post("/search/face", attendanceController.SearchUsersByFace)
-	attendances.Post("/checkin/face", attendanceController.CheckInUserByFace)
-	attendances.Put("/checkout/face", attendanceController.CheckOutUserByFace)
-	attendances.Post("/checkin/manual", attendanceController.CheckInUserManual)
-	attendances.Put("/checkout/manual", attendanceController.CheckOutUserManual)
+	attendances.Post("/search/face", imageUploadLimit, attendanceController.SearchUsersByFace)
+	attendances.Post("/checkin/face", imageUploadLimit, attendanceController.CheckInUserByFace)
+	attendances.Post("/checkin/face/batch", faceBatchUploadLimit, middleware.KioskKeyMiddleware(cfg), attendanceController.CheckInUsersByFaceBatch)
+	attendances.Put("/checkout/face", imageUploadLimit, attendanceController.CheckOutUserByFace)
+	attendances.Post("/checkin/manual", manualAttendanceRateLimit, manualAttendanceGuard, attendanceController.CheckInUserManual)
+	attendances.Put("/checkout/manual", manualAttendanceRateLimit, manualAttendanceGuard, attendanceController.CheckOutUserManual)
+	attendances.Post("/break/start/face", imageUploadLimit, attendanceController.StartBreakByFace)
+	attendances.Put("/break/end/face", imageUploadLimit, attendanceController.EndBreakByFace)
+	attendances.Post("/break/start/manual", manualAttendanceRateLimit, manualAttendanceGuard, attendanceController.StartBreakManual)
+	attendances.Put("/break/end/manual", manualAttendanceRateLimit, manualAttendanceGuard, attendanceController.EndBreakManual)
+	attendances.Get("/kiosk-summary", middleware.KioskKeyMiddleware(cfg), attendanceController.GetKioskSummary)
+	attendances.Get("/face-status", attendanceController.GetFaceStatus)
 
 	// Mobile Returns routes (public)
 	mobileReturns := api.Group("/mobile-returns")
@@ -173,6 +245,23 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	mobileReturns.Get("/:id", mobileReturnController.GetMobileReturn)
 	mobileReturns.Post("/", mobileReturnController.CreateMobileReturn)
 
+	// Maintenance status (public so clients can show a banner)
+	api.Get("/maintenance", maintenanceController.GetMaintenanceStatus)
+
+	// Status metadata (public so clients stop hardcoding status strings)
+	api.Get("/meta/statuses", metaController.GetStatuses)
+	api.Get("/meta/forms", metaController.GetForms)
+	api.Get("/meta/rate-limit", metaController.GetRateLimitUsage)
+
+	// Canonical server time (public so devices can check their clock before login)
+	api.Get("/time", timeController.GetServerTime)
+
+	// Buyer parcel tracking (public, coarse status only, per-IP rate limit and enumeration ban)
+	api.Get("/public/track/:trackingNumber", middleware.PublicTrackRateLimitMiddleware(cfg), middleware.PublicTrackGuardMiddleware(cfg), publicTrackController.TrackOrder)
+
+	// Signed file downloads (public, the signed link is the credential, per-IP rate limit)
+	api.Get("/files/download", middleware.DownloadRateLimitMiddleware(cfg), fileController.DownloadSignedFile)
+
 	// CSRF token endpoint for web clients
 	auth.Get("/csrf-token", middleware.CSRFMiddleware(), func(c fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -199,27 +288,66 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 
 	// Mobile Attendance routes
 	mobileAttendance := protected.Group("/mobile-attendances")
-	mobileAttendance.Post("/face-verify", mobileAttendanceController.VerifyUserFace)
-	mobileAttendance.Post("/checkin/face", mobileAttendanceController.MobileCheckInUserByFace)
-	mobileAttendance.Put("/checkout/face", mobileAttendanceController.MobileCheckOutUserByFace)
+	mobileAttendance.Get("/locations/nearest", mobileAttendanceController.GetNearestLocation)
+	mobileAttendance.Post("/face-verify", imageUploadLimit, mobileAttendanceController.VerifyUserFace)
+	mobileAttendance.Post("/checkin/face", imageUploadLimit, mobileAttendanceController.MobileCheckInUserByFace)
+	mobileAttendance.Put("/checkout/face", imageUploadLimit, mobileAttendanceController.MobileCheckOutUserByFace)
+
+	// Effective CORS configuration for debugging origin issues
+	protected.Get("/meta/cors", middleware.RoleMiddleware([]string{"developer", "superadmin"}), metaController.GetCORSConfig)
+
+	// Notification inbox routes, each user only sees their own notifications
+	notificationRoutes := protected.Group("/notifications")
+	notificationRoutes.Get("/", notificationController.GetNotifications)
+	notificationRoutes.Get("/unread-count", notificationController.GetUnreadNotificationCount)
+	notificationRoutes.Put("/read-all", notificationController.MarkAllNotificationsRead)
+	notificationRoutes.Put("/:id/read", notificationController.MarkNotificationRead)
+
+	// Self-service routes, resolved from the access token
+	me := protected.Group("/me")
+	me.Get("/", userController.GetMe)
+	me.Put("/", userController.UpdateMe)
+	me.Patch("/", userController.UpdateMe)
+	me.Put("/password", userController.UpdateMyPassword)
+	me.Get("/sessions", userController.GetMySessions)
 
 	// User routes
 	users := protected.Group("/users")
 	users.Get("/", userController.GetUsers)
+	users.Get("/invitations", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), invitationController.GetInvitations)
+	users.Post("/invite", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), invitationController.InviteUser)
+	users.Post("/invitations/:id/resend", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), invitationController.ResendInvitation)
+	users.Delete("/invitations/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), invitationController.RevokeInvitation)
 	users.Get("/:id", userController.GetUser)
-	users.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), userController.CreateUser)
+	// Direct creation with an admin-chosen password is kept for developers only, onboarding goes through invitations
+	users.Post("/", middleware.RoleMiddleware([]string{"developer"}), userController.CreateUser)
 	users.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), userController.UpdateUser)
+	users.Patch("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), userController.UpdateUser)
 	users.Put("/:id/password", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), userController.UpdatePassword)
 	users.Delete("/:id", middleware.RoleMiddleware([]string{"developer"}), userController.DeleteUser)
 	users.Post("/:id/roles", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), userController.AssignRole)
 	users.Delete("/:id/roles", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), userController.RemoveRole)
-	users.Post("/:id/face-register", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), userController.RegisterUserFace)
+	users.Post("/:id/face-register", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), imageUploadLimit, userController.RegisterUserFace)
 	users.Get("/:id/sessions", userController.GetSessions)
+	users.Get("/:id/activity", userController.GetUserActivity)
+
+	// API key routes for external systems
+	apiKeys := protected.Group("/api-keys")
+	apiKeys.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), apiKeyController.GetAPIKeys)
+	apiKeys.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), apiKeyController.CreateAPIKey)
+	apiKeys.Get("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), apiKeyController.GetAPIKey)
+	apiKeys.Get("/:id/usage", middleware.RoleMiddleware([]string{"developer", "superadmin"}), apiKeyController.GetAPIKeyUsage)
+	apiKeys.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), apiKeyController.UpdateAPIKey)
+	apiKeys.Post("/:id/rotate", middleware.RoleMiddleware([]string{"developer", "superadmin"}), apiKeyController.RotateAPIKey)
+	apiKeys.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), apiKeyController.RevokeAPIKey)
 
 	// Role routes
 	roles := protected.Group("/roles")
 	roles.Get("/", roleController.GetRoles)
+	roles.Get("/audit", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), roleController.GetRoleAudits)
 	roles.Get("/:id", roleController.GetRole)
+	roles.Get("/:roleName/users", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd", "coordinator"}), roleController.GetRoleUsers)
+	roles.Post("/:roleName/assign", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), roleController.AssignRoleUsers)
 	roles.Post("/", middleware.RoleMiddleware([]string{"admin", "developer"}), roleController.CreateRole)
 	roles.Put("/:id", middleware.RoleMiddleware([]string{"admin", "developer"}), roleController.UpdateRole)
 	roles.Delete("/:id", middleware.RoleMiddleware([]string{"admin", "developer"}), roleController.DeleteRole)
@@ -238,6 +366,9 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	channelRoutes.Get("/:id", channelController.GetChannel)
 	channelRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), channelController.CreateChannel)
 	channelRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), channelController.UpdateChannel)
+	channelRoutes.Put("/:id/qc-lane", middleware.RoleMiddleware([]string{"developer", "superadmin"}), channelController.UpdateChannelQCLane)
+	channelRoutes.Put("/:id/invoice", middleware.RoleMiddleware([]string{"developer", "superadmin"}), channelController.UpdateChannelInvoice)
+	channelRoutes.Put("/:id/duplication-policy", middleware.RoleMiddleware([]string{"developer", "superadmin"}), channelController.UpdateChannelDuplicationPolicy)
 	channelRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer"}), channelController.DeleteChannel)
 
 	// Expedition routes
@@ -248,6 +379,14 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	expeditionRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), expeditionController.UpdateExpedition)
 	expeditionRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer"}), expeditionController.DeleteExpedition)
 
+	// Tracking format routes
+	trackingFormatRoutes := protected.Group("/tracking-formats")
+	trackingFormatRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), trackingFormatController.GetTrackingFormats)
+	trackingFormatRoutes.Post("/check", trackingFormatController.CheckTrackingNumber)
+	trackingFormatRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), trackingFormatController.CreateTrackingFormat)
+	trackingFormatRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), trackingFormatController.UpdateTrackingFormat)
+	trackingFormatRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), trackingFormatController.DeleteTrackingFormat)
+
 	// Store routes
 	storeRoutes := protected.Group("/stores")
 	storeRoutes.Get("/", storeController.GetStores)
@@ -255,10 +394,30 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	storeRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), storeController.CreateStore)
 	storeRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), storeController.UpdateStore)
 	storeRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer"}), storeController.DeleteStore)
+	storeRoutes.Get("/:id/print-templates", printTemplateController.GetPrintTemplates)
+	storeRoutes.Put("/:id/print-templates/:documentType", middleware.RoleMiddleware([]string{"developer", "superadmin"}), printTemplateController.SavePrintTemplate)
+	storeRoutes.Delete("/:id/print-templates/:documentType", middleware.RoleMiddleware([]string{"developer", "superadmin"}), printTemplateController.DeletePrintTemplate)
+	storeRoutes.Get("/:id/logo", printTemplateController.GetStoreLogo)
+	storeRoutes.Put("/:id/logo", middleware.RoleMiddleware([]string{"developer", "superadmin"}), imageUploadLimit, printTemplateController.UploadStoreLogo)
+	storeRoutes.Delete("/:id/logo", middleware.RoleMiddleware([]string{"developer", "superadmin"}), printTemplateController.DeleteStoreLogo)
+
+	// Order reference routes (channel and store aliases, channels and stores created from orders for review)
+	orderReferenceRoutes := protected.Group("/order-references")
+	orderReferenceRoutes.Get("/aliases", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderReferenceController.GetReferenceAliases)
+	orderReferenceRoutes.Post("/aliases", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderReferenceController.CreateReferenceAlias)
+	orderReferenceRoutes.Delete("/aliases/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderReferenceController.DeleteReferenceAlias)
+	orderReferenceRoutes.Get("/pending", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderReferenceController.GetPendingReferences)
+	orderReferenceRoutes.Put("/pending/:kind/:id/approve", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderReferenceController.ApprovePendingReference)
+	orderReferenceRoutes.Post("/pending/:kind/:id/merge", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderReferenceController.MergePendingReference)
+
+	// Order serial routes (serial numbers captured at QC)
+	orderSerialRoutes := protected.Group("/order-serials")
+	orderSerialRoutes.Get("/:serialNumber", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderSerialController.GetSerialHistory)
 
 	// Product routes
 	productRoutes := protected.Group("/products")
 	productRoutes.Get("/", productController.GetProducts)
+	productRoutes.Post("/bulk-upsert", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin", "warehouse"}), productController.BulkUpsertProducts)
 	productRoutes.Get("/:id", productController.GetProduct)
 	productRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin", "warehouse"}), productController.CreateProduct)
 	productRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin", "warehouse"}), productController.UpdateProduct)
@@ -267,21 +426,53 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	// Order routes
 	orderRoutes := protected.Group("/orders")
 	orderRoutes.Get("/", orderController.GetOrders)
+	orderRoutes.Get("/summary", orderController.GetOrderSummary)
+	orderRoutes.Get("/aging", orderController.GetOrderAging)
+	orderRoutes.Get("/picker-capacity", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), orderController.GetPickerCapacity)
 	orderRoutes.Get("/:id", orderController.GetOrder)
+	orderRoutes.Get("/:id/holds", orderController.GetOrderHolds)
+	orderRoutes.Get("/:id/edit-overrides", orderController.GetOrderEditOverrides)
+	orderRoutes.Get("/:id/address-changes", orderController.GetOrderAddressChanges)
+	orderRoutes.Get("/:id/lock", orderLockController.GetOrderEditLock)
+	orderRoutes.Get("/:id/shipments", orderController.GetOrderShipments)
+	orderRoutes.Get("/:id/invoice", orderController.GetOrderInvoice)
+	orderRoutes.Get("/:id/courier-bookings", orderController.GetOrderCourierBookings)
 	orderRoutes.Put("/:id/status/qc-process", orderController.QCProcessStatusUpdate)
 	orderRoutes.Put("/:id/status/picking-completed", orderController.PickingCompletedStatusUpdate)
 
 	// Order router for admin
 	orderRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.CreateOrder)
 	orderRoutes.Post("/bulk", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.BulkCreateOrders)
+	orderRoutes.Post("/manual", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.CreateManualOrder)
+	orderRoutes.Post("/import/validate", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), importUploadLimit, orderController.ValidateOrderImport)
+	orderRoutes.Post("/bulk-sync-status", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), importUploadLimit, orderController.BulkSyncOrderStatus)
+	// Ginee status webhook, sent with an API key scoped to orders:write
+	orderRoutes.Post("/webhooks/ginee", middleware.WebhookMiddleware(cfg, "ginee_status", "ginee"), orderController.ReceiveGineeStatusWebhook)
+	orderRoutes.Get("/bulk-operations/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.GetBulkOperation)
+	orderRoutes.Post("/bulk-operations/:id/requeue", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.RequeueBulkOperation)
+	orderRoutes.Post("/merge", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.MergeOrders)
+	orderRoutes.Post("/cancel-cleanup", middleware.RoleMiddleware([]string{"developer", "superadmin"}), orderController.CleanupCanceledOrders)
 	orderRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.UpdateOrder)
 	orderRoutes.Put("/:id/duplicate", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.DuplicateOrder)
 	orderRoutes.Put("/:id/cancel", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.CancelOrder)
+	orderRoutes.Put("/:id/hold", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.HoldOrder)
+	orderRoutes.Put("/:id/unhold", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.UnholdOrder)
+	orderRoutes.Put("/:id/priority", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.UpdateOrderPriority)
+	orderRoutes.Patch("/:id/address", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin", "coordinator"}), orderController.UpdateOrderAddress)
+	orderRoutes.Put("/:id/address/normalize", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.NormalizeOrderAddress)
+	orderRoutes.Post("/:id/split", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.SplitOrder)
+	orderRoutes.Post("/:id/courier-booking", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderController.BookOrderCourier)
 
 	// Order router for coordinator
 	orderRoutes.Post("/assign-picker", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), orderController.AssignPicker)
 	orderRoutes.Put("/:id/pending-picking", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), orderController.PendingPickingOrders)
 	orderRoutes.Get("/assigned", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), orderController.GetAssignedOrders)
+	orderRoutes.Put("/:id/edit-override", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), orderController.OverrideOrderEdit)
+
+	// Order edit lock routes
+	orderRoutes.Put("/:id/lock", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin", "coordinator"}), orderLockController.AcquireOrderEditLock)
+	orderRoutes.Put("/:id/lock/heartbeat", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin", "coordinator"}), orderLockController.HeartbeatOrderEditLock)
+	orderRoutes.Delete("/:id/lock", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin", "coordinator"}), orderLockController.ReleaseOrderEditLock)
 
 	// Ribbon routes
 	qcRibbonRoutes := protected.Group("/ribbons")
@@ -290,9 +481,13 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	qcRibbonRoutes.Get("/qc-ribbons", qcRibbonController.GetQCRibbons)
 	qcRibbonRoutes.Get("/qc-ribbons/:id", qcRibbonController.GetQCRibbon)
 	qcRibbonRoutes.Post("/qc-ribbons/start", qcRibbonController.QCRibbonStart)
-	qcRibbonRoutes.Put("/qc-ribbons/:id/validate", qcRibbonController.ValidateQCRibbonProduct)
-	qcRibbonRoutes.Put("/qc-ribbons/:id/complete", qcRibbonController.CompleteQcRibbon)
+	qcRibbonRoutes.Put("/qc-ribbons/:id/validate", qcController.Shadow("ribbon", "validate", qcRibbonController.ValidateQCRibbonProduct))
+	qcRibbonRoutes.Put("/qc-ribbons/:id/scan", qcController.Shadow("ribbon", "scan", qcRibbonController.ScanQCRibbonProduct))
+	qcRibbonRoutes.Get("/qc-ribbons/:id/progress", qcRibbonController.GetQCRibbonProgress)
+	qcRibbonRoutes.Post("/qc-ribbons/:id/boxes", qcRibbonController.AddQCRibbonBox)
+	qcRibbonRoutes.Put("/qc-ribbons/:id/complete", imageUploadLimit, qcRibbonController.CompleteQcRibbon)
 	qcRibbonRoutes.Put("/qc-ribbons/:id/pending", qcRibbonController.PendingQCRibbon)
+	qcRibbonRoutes.Put("/qc-ribbons/:id/void", coordinatorRateLimit, coordinatorGuard, qcRibbonController.VoidQCRibbon)
 
 	// Ribbon flow routes
 	qcRibbonRoutes.Get("/flows", ribbonFlowController.GetRibbonFlows)
@@ -305,14 +500,60 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	qcOnlineRoutes.Get("/qc-onlines/", qcOnlineController.GetQCOnlines)
 	qcOnlineRoutes.Get("/qc-onlines/:id", qcOnlineController.GetQCOnline)
 	qcOnlineRoutes.Post("/qc-onlines/start", qcOnlineController.QCOnlineStart)
-	qcOnlineRoutes.Put("/qc-onlines/:id/validate", qcOnlineController.ValidateQCOnlineProduct)
-	qcOnlineRoutes.Put("/qc-onlines/:id/complete", qcOnlineController.CompleteQcOnline)
+	qcOnlineRoutes.Put("/qc-onlines/:id/validate", qcController.Shadow("online", "validate", qcOnlineController.ValidateQCOnlineProduct))
+	qcOnlineRoutes.Put("/qc-onlines/:id/scan", qcController.Shadow("online", "scan", qcOnlineController.ScanQCOnlineProduct))
+	qcOnlineRoutes.Get("/qc-onlines/:id/progress", qcOnlineController.GetQCOnlineProgress)
+	qcOnlineRoutes.Post("/qc-onlines/:id/boxes", qcOnlineController.AddQCOnlineBox)
+	qcOnlineRoutes.Put("/qc-onlines/:id/complete", imageUploadLimit, qcOnlineController.CompleteQcOnline)
 	qcOnlineRoutes.Put("/qc-onlines/:id/pending", qcOnlineController.PendingQCOnline)
+	qcOnlineRoutes.Put("/qc-onlines/:id/void", coordinatorRateLimit, coordinatorGuard, qcOnlineController.VoidQCOnline)
 
 	// Online flow routes
 	qcOnlineRoutes.Get("/flows", onlineFlowController.GetOnlineFlows)
 	qcOnlineRoutes.Get("/flows/:trackingNumber", onlineFlowController.GetOnlineFlow)
 
+	// Unified QC routes
+	qcRoutes := protected.Group("/qc")
+	qcRoutes.Post("/start", qcController.QCStart)
+	qcRoutes.Get("/print/:trackingNumber", qcController.PrintQCDocuments)
+	qcRoutes.Get("/context/:trackingNumber", qcController.GetQCContext)
+
+	// Pick batch routes
+	pickBatchRoutes := protected.Group("/pick-batches")
+	pickBatchRoutes.Post("/print", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), pickBatchController.PrintPickBatches)
+
+	// QC station routes
+	qcStationRoutes := protected.Group("/qc-stations")
+	qcStationRoutes.Get("/", qcStationController.GetQCStations)
+	qcStationRoutes.Post("/release", qcStationController.ReleaseQCStation)
+	qcStationRoutes.Get("/:id", qcStationController.GetQCStation)
+	qcStationRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), qcStationController.CreateQCStation)
+	qcStationRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), qcStationController.UpdateQCStation)
+	qcStationRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), qcStationController.DeleteQCStation)
+	qcStationRoutes.Post("/:id/claim", qcStationController.ClaimQCStation)
+
+	// Warehouse zone routes
+	zoneRoutes := protected.Group("/zones")
+	zoneRoutes.Get("/", zoneController.GetZones)
+	zoneRoutes.Get("/heatmap", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "admin"}), zoneController.GetZoneHeatmap)
+	zoneRoutes.Get("/visits", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "admin"}), zoneController.GetZoneVisits)
+	zoneRoutes.Get("/:id", zoneController.GetZone)
+	zoneRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), zoneController.CreateZone)
+	zoneRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), zoneController.UpdateZone)
+	zoneRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), zoneController.DeleteZone)
+
+	// Staging area routes (QC outbound lanes and courier staging bins)
+	stagingAreaRoutes := protected.Group("/staging-areas")
+	stagingAreaRoutes.Get("/", stagingAreaController.GetStagingAreas)
+	stagingAreaRoutes.Get("/occupancy", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "admin"}), stagingAreaController.GetStagingOccupancy)
+	stagingAreaRoutes.Get("/lookup/:trackingNumber", stagingAreaController.LookupStagingParcel)
+	stagingAreaRoutes.Post("/remove", stagingAreaController.RemoveStagingParcel)
+	stagingAreaRoutes.Get("/:id", stagingAreaController.GetStagingArea)
+	stagingAreaRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), stagingAreaController.CreateStagingArea)
+	stagingAreaRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), stagingAreaController.UpdateStagingArea)
+	stagingAreaRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), stagingAreaController.DeleteStagingArea)
+	stagingAreaRoutes.Post("/:id/place", stagingAreaController.PlaceStagingParcel)
+
 	// Outbound routes
 	outboundRoutes := protected.Group("/outbounds")
 	outboundRoutes.Get("/", outboundController.GetOutbounds)
@@ -321,14 +562,93 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	outboundRoutes.Get("/:id", outboundController.GetOutbound)
 	outboundRoutes.Post("/", outboundController.CreateOutbound)
 	outboundRoutes.Put("/:id", outboundController.UpdateOutbound)
+	outboundRoutes.Post("/courier-invoices", middleware.RoleMiddleware([]string{"developer", "superadmin", "finance"}), importUploadLimit, outboundController.ImportCourierInvoice)
+
+	// Handover session routes
+	handoverRoutes := protected.Group("/handovers")
+	handoverRoutes.Get("/", handoverController.GetHandoverSessions)
+	handoverRoutes.Get("/acks", handoverController.GetHandoverAcks)
+	handoverRoutes.Get("/cutoffs", handoverController.GetHandoverCutoffs)
+	handoverRoutes.Get("/:id", handoverController.GetHandoverSession)
+	handoverRoutes.Get("/:id/report", handoverController.GetHandoverReport)
+	handoverRoutes.Post("/", handoverController.OpenHandoverSession)
+	handoverRoutes.Post("/:id/scan", handoverController.ScanHandoverOutbound)
+	handoverRoutes.Delete("/:id/items/:itemId", handoverController.RemoveHandoverOutbound)
+	handoverRoutes.Put("/:id/close", handoverController.CloseHandoverSession)
+
+	// 3PL handover acknowledgment, sent with an API key scoped to handovers:write
+	protected.Post("/handover/ack", middleware.WebhookMiddleware(cfg, "handover_ack", "3pl"), handoverController.AcknowledgeHandover)
+
+	// Signed server time for offline capture
+	protected.Get("/time/token", timeController.GetTimeToken)
+
+	// Inventory routes
+	inventoryRoutes := protected.Group("/inventories")
+	inventoryRoutes.Get("/", inventoryController.GetInventories)
+	inventoryRoutes.Post("/receipts", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), inventoryController.ReceiveInventory)
+	inventoryRoutes.Get("/:sku/movements", inventoryController.GetInventoryMovements)
+	inventoryRoutes.Get("/:sku/batches", inventoryController.GetInventoryBatches)
+
+	// Inbound (supplier receiving) routes
+	inboundRoutes := protected.Group("/inbounds")
+	inboundRoutes.Get("/", inboundController.GetInboundShipments)
+	inboundRoutes.Get("/:id", inboundController.GetInboundShipment)
+	inboundRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), inboundController.CreateInboundShipment)
+	inboundRoutes.Put("/:id/receive", inboundController.ReceiveInboundShipment)
+	inboundRoutes.Put("/:id/complete", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), inboundController.CompleteInboundShipment)
+	inboundRoutes.Put("/:id/cancel", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), inboundController.CancelInboundShipment)
+
+	// Stock take (cycle counting) routes
+	stockTakeRoutes := protected.Group("/stock-takes")
+	stockTakeRoutes.Get("/", stockTakeController.GetStockTakes)
+	stockTakeRoutes.Get("/:id", stockTakeController.GetStockTake)
+	stockTakeRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), stockTakeController.CreateStockTake)
+	stockTakeRoutes.Post("/:id/count", stockTakeController.CountStockTakeItem)
+	stockTakeRoutes.Put("/:id/approve", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), stockTakeController.ApproveStockTake)
+	stockTakeRoutes.Put("/:id/cancel", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), stockTakeController.CancelStockTake)
 
 	// Report routes
 	reportRoutes := protected.Group("/reports")
-	reportRoutes.Get("/boxes", reportController.GetBoxReports)
-	reportRoutes.Get("/outbounds", reportController.GetOutboundReports)
-	reportRoutes.Get("/returns", reportController.GetReturnReports)
-	reportRoutes.Get("/complains", reportController.GetComplainReports)
-	reportRoutes.Get("/user-fees", reportController.GetUserFeeReports)
+	reportRoutes.Get("/boxes", middleware.ReportAccessMiddleware("boxes"), reportController.GetBoxReports)
+	reportRoutes.Get("/boxes/:boxId/details", middleware.ReportAccessMiddleware("boxes"), reportController.GetBoxUsageDetails)
+	reportRoutes.Get("/access-policies", middleware.RoleMiddleware([]string{"developer", "superadmin"}), reportAccessController.GetReportAccessPolicies)
+	reportRoutes.Get("/access-denials", middleware.RoleMiddleware([]string{"developer", "superadmin"}), reportAccessController.GetReportAccessDenials)
+	reportRoutes.Get("/schedules/types", reportScheduleController.GetReportScheduleTypes)
+	reportRoutes.Get("/schedules", reportScheduleController.GetReportSchedules)
+	reportRoutes.Post("/schedules", reportScheduleController.CreateReportSchedule)
+	reportRoutes.Get("/schedules/:id/download", reportScheduleController.DownloadReportSchedule)
+	reportRoutes.Post("/schedules/:id/send", reportScheduleController.SendReportSchedule)
+	reportRoutes.Put("/schedules/:id", reportScheduleController.UpdateReportSchedule)
+	reportRoutes.Delete("/schedules/:id", reportScheduleController.DeleteReportSchedule)
+	reportRoutes.Get("/outbounds", middleware.ReportAccessMiddleware("outbounds"), reportController.GetOutboundReports)
+	reportRoutes.Get("/returns", middleware.ReportAccessMiddleware("returns"), reportController.GetReturnReports)
+	reportRoutes.Get("/return-valuation", middleware.ReportAccessMiddleware("return_valuation"), reportController.GetReturnValuationReports)
+	reportRoutes.Get("/complains", middleware.ReportAccessMiddleware("complains"), reportController.GetComplainReports)
+	reportRoutes.Get("/complains/root-causes", middleware.ReportAccessMiddleware("complain_root_causes"), reportController.GetComplainRootCauseReports)
+	reportRoutes.Get("/complains/repeat-offenders", middleware.ReportAccessMiddleware("complain_repeat_offenders"), reportController.GetComplainRepeatOffenderReports)
+	reportRoutes.Get("/user-fees", middleware.ReportAccessMiddleware("user_fees"), reportController.GetUserFeeReports)
+	reportRoutes.Get("/near-expiry", middleware.ReportAccessMiddleware("near_expiry"), reportController.GetNearExpiryReports)
+	reportRoutes.Get("/error-hotspots", middleware.ReportAccessMiddleware("error_hotspots"), reportController.GetErrorHotspotReports)
+	reportRoutes.Get("/pick-anomalies", middleware.ReportAccessMiddleware("pick_anomalies"), reportController.GetPickAnomalyReports)
+	reportRoutes.Get("/qc-stations", middleware.ReportAccessMiddleware("qc_stations"), reportController.GetQCStationReports)
+	reportRoutes.Get("/picker-performance", middleware.ReportAccessMiddleware("picker_performance"), reportController.GetPickerPerformanceReports)
+	reportRoutes.Get("/shortages", middleware.ReportAccessMiddleware("shortages"), reportController.GetShortageReports)
+	reportRoutes.Get("/backorders", middleware.ReportAccessMiddleware("backorders"), reportController.GetBackorderReports)
+	reportRoutes.Get("/sla-breaches", middleware.ReportAccessMiddleware("sla_breaches"), reportController.GetSLABreachReports)
+	reportRoutes.Get("/outbound-forecast", middleware.ReportAccessMiddleware("outbound_forecast"), reportController.GetOutboundForecastReports)
+	reportRoutes.Get("/cancel-inconsistencies", middleware.ReportAccessMiddleware("cancel_inconsistencies"), reportController.GetCancelInconsistencyReports)
+	reportRoutes.Get("/data-quality", middleware.ReportAccessMiddleware("data_quality"), reportController.GetDataQualityReports)
+	reportRoutes.Get("/courier-weights", middleware.ReportAccessMiddleware("courier_weights"), reportController.GetCourierWeightReports)
+	reportRoutes.Get("/billing", middleware.ReportAccessMiddleware("billing"), reportController.GetBillingReports)
+	reportRoutes.Get("/order-reconciliation", middleware.ReportAccessMiddleware("order_reconciliation"), reportController.GetOrderReconciliationReports)
+	reportRoutes.Get("/order-volume", middleware.ReportAccessMiddleware("order_volume"), reportController.GetOrderVolumeReports)
+
+	// Throughput chart routes
+	chartRoutes := protected.Group("/charts")
+	chartRoutes.Get("/qc", chartController.GetQCThroughputChart)
+	chartRoutes.Get("/picking", chartController.GetPickingThroughputChart)
+	chartRoutes.Get("/outbounds", chartController.GetOutboundThroughputChart)
+	chartRoutes.Get("/orders", chartController.GetOrderStatusChart)
 
 	// Lost and Found routes
 	lostFoundRoutes := protected.Group("/lost-founds")
@@ -341,9 +661,13 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	// Return routes
 	returnRoutes := protected.Group("/returns")
 	returnRoutes.Get("/", returnController.GetReturns)
+	returnRoutes.Get("/unknown", returnController.GetUnknownReturns)
 	returnRoutes.Get("/:id", returnController.GetReturn)
 	returnRoutes.Post("/", returnController.CreateReturn)
+	returnRoutes.Post("/scan", returnController.ScanReturn)
 	returnRoutes.Put("/:id", returnController.UpdateReturn)
+	returnRoutes.Patch("/:id", returnController.UpdateReturn)
+	returnRoutes.Put("/:id/disposition", returnController.SetReturnDisposition)
 
 	// Picked Order routes
 	pickedOrderRoutes := protected.Group("/picked-orders")
@@ -353,17 +677,93 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	// Complain routes
 	complainRoutes := protected.Group("/complains")
 	complainRoutes.Get("/", complainController.GetComplains)
+	// Complain fee dispute routes, registered before /:id
+	complainRoutes.Get("/fee-disputes", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "hrd"}), complainFeeDisputeController.GetComplainFeeDisputes)
+	complainRoutes.Get("/fee-disputes/mine", complainFeeDisputeController.GetMyComplainFeeDisputes)
+	complainRoutes.Put("/fee-disputes/:id/review", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "hrd"}), complainFeeDisputeController.ReviewComplainFeeDispute)
+	complainRoutes.Get("/queue", complainController.GetComplainQueue)
 	complainRoutes.Get("/:id", complainController.GetComplain)
+	complainRoutes.Get("/:id/assignments", complainController.GetComplainAssignments)
+	complainRoutes.Get("/:id/dispute-package", complainController.GetComplainDisputePackage)
+	complainRoutes.Get("/:id/qc-photos", complainController.GetComplainQCPhotos)
+	complainRoutes.Get("/:id/fee-changes", complainFeeDisputeController.GetComplainFeeChanges)
+	complainRoutes.Post("/:id/fee-disputes", complainFeeDisputeController.CreateComplainFeeDispute)
 	complainRoutes.Post("/", complainController.CreateComplain)
+	complainRoutes.Post("/import", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "admin"}), importUploadLimit, complainController.ImportComplains)
+	// Marketplace dispute webhook, sent with an API key scoped to complains:write
+	complainRoutes.Post("/webhooks/:marketplace", middleware.FeatureFlagMiddleware(models.FeatureFlagMarketplaceDisputeWebhooks, true), middleware.WebhookMiddleware(cfg, "complain_dispute", ":marketplace"), complainController.ReceiveMarketplaceDispute)
 	complainRoutes.Put("/:id", complainController.UpdateComplain)
 	complainRoutes.Put("/:id/check", complainController.UpdateComplainCheck)
+	complainRoutes.Put("/:id/assign", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), complainController.AssignComplain)
+	complainRoutes.Post("/:id/reopen", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "admin"}), complainController.ReopenComplain)
+
+	// Complain settlement routes
+	complainSettlementRoutes := protected.Group("/complain-settlements")
+	complainSettlementRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "hrd"}), complainSettlementController.GetComplainSettlements)
+	complainSettlementRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), complainSettlementController.CreateComplainSettlement)
+	complainSettlementRoutes.Get("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "hrd"}), complainSettlementController.GetComplainSettlement)
+	complainSettlementRoutes.Get("/:id/statements/:userId", complainSettlementController.GetComplainSettlementStatement)
+	complainSettlementRoutes.Put("/:id/settle", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), complainSettlementController.SettleComplainSettlement)
+
+	// Training task routes
+	trainingTaskRoutes := protected.Group("/training-tasks")
+	trainingTaskRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "hrd"}), trainingTaskController.GetTrainingTasks)
+	trainingTaskRoutes.Put("/:id/clear", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "hrd"}), trainingTaskController.ClearTrainingTask)
+
+	// Accounting sync routes
+	accountingRoutes := protected.Group("/accounting")
+	accountingRoutes.Get("/mappings", middleware.RoleMiddleware([]string{"developer", "superadmin", "finance"}), accountingController.GetAccountingMappings)
+	accountingRoutes.Put("/mappings/:storeId", middleware.RoleMiddleware([]string{"developer", "superadmin", "finance"}), accountingController.SaveAccountingMapping)
+	accountingRoutes.Delete("/mappings/:storeId", middleware.RoleMiddleware([]string{"developer", "superadmin", "finance"}), accountingController.DeleteAccountingMapping)
+	accountingRoutes.Get("/syncs", middleware.RoleMiddleware([]string{"developer", "superadmin", "finance"}), accountingController.GetAccountingSyncs)
+	accountingRoutes.Get("/syncs/dashboard", middleware.RoleMiddleware([]string{"developer", "superadmin", "finance"}), accountingController.GetAccountingSyncDashboard)
+	accountingRoutes.Post("/syncs/:id/retry", middleware.RoleMiddleware([]string{"developer", "superadmin", "finance"}), accountingController.RetryAccountingSync)
+
+	// Order volume alert routes
+	orderVolumeRoutes := protected.Group("/order-volume")
+	orderVolumeRoutes.Get("/thresholds", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderVolumeController.GetOrderVolumeThresholds)
+	orderVolumeRoutes.Put("/thresholds/:scope/:referenceId", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderVolumeController.SaveOrderVolumeThreshold)
+	orderVolumeRoutes.Delete("/thresholds/:scope/:referenceId", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderVolumeController.DeleteOrderVolumeThreshold)
+	orderVolumeRoutes.Get("/alerts", middleware.RoleMiddleware([]string{"developer", "superadmin", "admin"}), orderVolumeController.GetOrderVolumeAlerts)
+
+	// Export job routes
+	exportRoutes := protected.Group("/exports")
+	exportRoutes.Get("/", exportJobController.GetExportJobs)
+	exportRoutes.Get("/:id", exportJobController.GetExportJob)
+	exportRoutes.Get("/:id/download", exportJobController.DownloadExportJob)
+	exportRoutes.Get("/:id/download-url", exportJobController.GetExportJobDownloadURL)
+
+	// Change feed routes
+	changeRoutes := protected.Group("/changes")
+	changeRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), changeFeedController.GetChangeFeed)
+	changeRoutes.Get("/schemas", middleware.RoleMiddleware([]string{"developer", "superadmin"}), changeFeedController.GetChangeFeedSchemas)
+	changeRoutes.Post("/replay", middleware.RoleMiddleware([]string{"developer", "superadmin"}), changeFeedController.ReplayChangeFeed)
+
+	// Complain root cause routes
+	complainRootCauseRoutes := protected.Group("/complain-root-causes")
+	complainRootCauseRoutes.Get("/", complainRootCauseController.GetComplainRootCauses)
+	complainRootCauseRoutes.Get("/:id", complainRootCauseController.GetComplainRootCause)
+	complainRootCauseRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), complainRootCauseController.CreateComplainRootCause)
+	complainRootCauseRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), complainRootCauseController.UpdateComplainRootCause)
+	complainRootCauseRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), complainRootCauseController.DeleteComplainRootCause)
 
 	// Mobile Orders routes
 	mobileOrders := api.Group("/mobile-orders")
 	mobileOrders.Get("/my-picking-orders", mobileOrderController.GetMyPickingOrders)
+	mobileOrders.Get("/my-picking-orders/print", mobileOrderController.PrintMyPickingOrders)
 	mobileOrders.Get("/my-picking-orders/:id", mobileOrderController.GetMyPickingOrder)
+	mobileOrders.Get("/my-stats", mobileOrderController.GetMyStats)
+	mobileOrders.Get("/my-pick-batches", pickBatchController.GetMyPickBatches)
+	mobileOrders.Get("/my-pick-batches/:id", pickBatchController.GetMyPickBatch)
+	mobileOrders.Post("/my-pick-batches", pickBatchController.CreatePickBatch)
+	mobileOrders.Put("/my-pick-batches/:id/items/pick", pickBatchController.PickBatchItem)
+	mobileOrders.Put("/my-pick-batches/:id/sort", pickBatchController.ConfirmBatchSortation)
+	mobileOrders.Put("/my-pick-batches/:id/cancel", pickBatchController.CancelPickBatch)
+	mobileOrders.Put("/my-picking-order/:id/items/pick", mobileOrderController.PickOrderItem)
+	mobileOrders.Post("/my-picking-order/:id/shortage", mobileOrderController.DeclareShortage)
 	mobileOrders.Put("/my-picking-order/:id/complete", mobileOrderController.CompletePickingOrder)
-	mobileOrders.Put("/my-picking-order/:id/pending", mobileOrderController.PendingPickOrder)
+	mobileOrders.Put("/my-picking-order/:id/pending", coordinatorRateLimit, coordinatorGuard, mobileOrderController.PendingPickOrder)
+	mobileOrders.Post("/sync", mobileOrderController.SyncOfflineActions)
 	mobileOrders.Put("/bulk-assign-picker", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), mobileOrderController.BulkAssignPicker)
 	mobileOrders.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), mobileOrderController.GetMobilePickedOrders)
 	mobileOrders.Get("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), mobileOrderController.GetMobilePickedOrder)
@@ -376,9 +776,212 @@ func SetupRoutes(app *fiber.App, cfg *config.Config, db *gorm.DB) {
 	locationRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), locationController.UpdateLocation)
 	locationRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), locationController.DeleteLocation)
 
+	// Team routes
+	teams := protected.Group("/teams")
+	teams.Get("/", teamController.GetTeams)
+	teams.Get("/:id", teamController.GetTeam)
+	teams.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), teamController.CreateTeam)
+	teams.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), teamController.UpdateTeam)
+	teams.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), teamController.DeleteTeam)
+	teams.Post("/:id/members", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), teamController.AddTeamMembers)
+	teams.Delete("/:id/members/:userId", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), teamController.RemoveTeamMember)
+
+	// Skill routes, certifications gating QC lanes and products such as fragile goods
+	skills := protected.Group("/skills")
+	skills.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd", "coordinator"}), skillController.GetSkills)
+	skills.Get("/coverage", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd", "coordinator"}), skillController.GetSkillCoverage)
+	skills.Get("/users/:userId", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd", "coordinator"}), skillController.GetUserSkills)
+	skills.Get("/:id/users", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd", "coordinator"}), skillController.GetSkillHolders)
+	skills.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), skillController.CreateSkill)
+	skills.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), skillController.UpdateSkill)
+	skills.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), skillController.DeleteSkill)
+	skills.Post("/:id/users", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), skillController.AssignSkill)
+	skills.Delete("/:id/users/:userId", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), skillController.RevokeSkill)
+
+	// Product substitution routes
+	substitutions := protected.Group("/substitutions")
+	substitutions.Get("/", substitutionController.GetProductSubstitutions)
+	substitutions.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), substitutionController.CreateProductSubstitution)
+	substitutions.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), substitutionController.UpdateProductSubstitution)
+	substitutions.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), substitutionController.DeleteProductSubstitution)
+
+	// Product name normalization routes
+	productNames := protected.Group("/product-names")
+	productNames.Get("/", productNameController.GetProductDisplayNames)
+	productNames.Get("/preview", productNameController.PreviewProductName)
+	productNames.Get("/variants", productNameController.GetProductVariantMappings)
+	productNames.Post("/variants", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), productNameController.CreateProductVariantMapping)
+	productNames.Put("/variants/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), productNameController.UpdateProductVariantMapping)
+	productNames.Delete("/variants/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), productNameController.DeleteProductVariantMapping)
+	productNames.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), productNameController.CreateProductDisplayName)
+	productNames.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), productNameController.UpdateProductDisplayName)
+	productNames.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), productNameController.DeleteProductDisplayName)
+
+	// Pick shortage routes
+	shortages := protected.Group("/shortages")
+	shortages.Put("/:id/substitute", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), substitutionController.SubstituteShortage)
+
+	// Backorder routes, orders awaiting stock after a pick shortage
+	backorderRoutes := protected.Group("/backorders")
+	backorderRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), backorderController.GetBackorders)
+
+	// Approval routes
+	approvals := protected.Group("/approvals")
+	approvals.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), approvalController.GetApprovals)
+	approvals.Get("/delegations", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), approvalController.GetApprovalDelegations)
+	approvals.Post("/delegations", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), approvalController.CreateApprovalDelegation)
+	approvals.Put("/delegations/:id/revoke", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), approvalController.RevokeApprovalDelegation)
+	approvals.Get("/codes", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), approvalController.GetApprovalCodes)
+	approvals.Post("/codes", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator"}), approvalController.CreateApprovalCode)
+
 	// Attendance management routes (protected - developer and hrd only)
 	attendanceManagement := protected.Group("/attendances")
 	attendanceManagement.Get("/", middleware.RoleMiddleware([]string{"developer", "hrd"}), attendanceController.GetAttendances)
+	attendanceManagement.Get("/suspicious", middleware.RoleMiddleware([]string{"developer", "hrd"}), attendanceController.GetSuspiciousAttendances)
+	attendanceManagement.Get("/face-confidence", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), attendanceController.GetLowConfidenceAttendances)
+	attendanceManagement.Get("/summary", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), attendanceController.GetAttendanceSummary)
+	attendanceManagement.Post("/export", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), attendanceController.ExportAttendances)
+	attendanceManagement.Get("/overtime/pending", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "hrd"}), attendanceController.GetPendingOvertimes)
+	attendanceManagement.Get("/missing-checkouts", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), attendanceController.GetMissingCheckouts)
+	attendanceManagement.Get("/break-violations", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), attendanceController.GetBreakViolations)
+	attendanceManagement.Get("/fallbacks", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), attendanceController.GetFallbackAttendances)
+	attendanceManagement.Get("/backfills", middleware.RoleMiddleware([]string{"developer", "hrd"}), attendanceController.GetAttendanceBackfills)
+	attendanceManagement.Post("/backfills", middleware.RoleMiddleware([]string{"developer", "hrd"}), attendanceController.BackfillAttendances)
+	attendanceManagement.Get("/backfills/:id", middleware.RoleMiddleware([]string{"developer", "hrd"}), attendanceController.GetAttendanceBackfill)
+	attendanceManagement.Put("/:id/overtime", middleware.RoleMiddleware([]string{"developer", "superadmin", "coordinator", "hrd"}), attendanceController.ReviewOvertime)
+	attendanceManagement.Put("/:id/fallback-review", middleware.RoleMiddleware([]string{"developer", "superadmin", "hrd"}), attendanceController.ReviewFallbackAttendance)
 	attendanceManagement.Get("/:id", middleware.RoleMiddleware([]string{"developer", "hrd"}), attendanceController.GetAttendanceByID)
 
+	// Data retention routes (protected - developer and superadmin only)
+	retentionRoutes := protected.Group("/retention")
+	retentionRoutes.Get("/policy", middleware.RoleMiddleware([]string{"developer", "superadmin"}), retentionController.GetRetentionPolicy)
+	retentionRoutes.Get("/runs", middleware.RoleMiddleware([]string{"developer", "superadmin"}), retentionController.GetPurgeRuns)
+	retentionRoutes.Post("/purge", middleware.RoleMiddleware([]string{"developer", "superadmin"}), retentionController.RunRetentionPurge)
+
+	// Data repair routes (protected - developer and superadmin only)
+	dataRepairs := protected.Group("/data-repairs")
+	dataRepairs.Get("/rules", middleware.RoleMiddleware([]string{"developer", "superadmin"}), dataRepairController.GetDataRepairRules)
+	dataRepairs.Get("/runs", middleware.RoleMiddleware([]string{"developer", "superadmin"}), dataRepairController.GetDataRepairRuns)
+	dataRepairs.Post("/runs", middleware.RoleMiddleware([]string{"developer", "superadmin"}), dataRepairController.RunDataRepair)
+	dataRepairs.Get("/runs/:id/changes", middleware.RoleMiddleware([]string{"developer", "superadmin"}), dataRepairController.GetDataRepairChanges)
+	dataRepairs.Post("/runs/:id/revert", middleware.RoleMiddleware([]string{"developer", "superadmin"}), dataRepairController.RevertDataRepair)
+
+	// Legacy import routes (protected - developer and superadmin only)
+	legacyImports := protected.Group("/legacy-imports")
+	legacyImports.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.GetLegacyImportRuns)
+	legacyImports.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.RunLegacyImport)
+	legacyImports.Post("/validate", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.ValidateLegacyImport)
+	legacyImports.Get("/fields", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.GetLegacyImportFields)
+	legacyImports.Get("/templates", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.GetLegacyImportTemplates)
+	legacyImports.Post("/templates", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.CreateLegacyImportTemplate)
+	legacyImports.Put("/templates/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.UpdateLegacyImportTemplate)
+	legacyImports.Delete("/templates/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.DeleteLegacyImportTemplate)
+	legacyImports.Get("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), legacyImportController.GetLegacyImportRun)
+
+	// Admin runbook routes (protected - developer and superadmin only)
+	adminRoutes := protected.Group("/admin")
+	adminRoutes.Get("/stuck", middleware.RoleMiddleware([]string{"developer", "superadmin"}), adminController.GetStuckEntities)
+	adminRoutes.Get("/token-keys", middleware.RoleMiddleware([]string{"developer", "superadmin"}), adminController.GetTokenKeys)
+	adminRoutes.Post("/token-keys/rotate", middleware.RoleMiddleware([]string{"developer", "superadmin"}), adminController.RotateTokenKey)
+
+	// Metrics routes (protected - developer and superadmin only)
+	protected.Get("/metrics", middleware.RoleMiddleware([]string{"developer", "superadmin"}), metricsController.GetMetrics)
+	protected.Put("/maintenance", middleware.RoleMiddleware([]string{"developer", "superadmin"}), maintenanceController.SetMaintenanceMode)
+
+	// Fault injection routes (protected - developer only, staging only)
+	protected.Get("/fault-injection", middleware.RoleMiddleware([]string{"developer"}), faultInjectionController.GetFaultInjection)
+	protected.Put("/fault-injection", middleware.RoleMiddleware([]string{"developer"}), faultInjectionController.SetFaultInjection)
+
+	// Access log routes (protected - developer and superadmin only)
+	accessLogRoutes := protected.Group("/access-logs")
+	accessLogRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), accessLogController.GetAccessLogs)
+	accessLogRoutes.Get("/analytics", middleware.RoleMiddleware([]string{"developer", "superadmin"}), accessLogController.GetAccessLogAnalytics)
+	accessLogRoutes.Get("/analytics/timeline", middleware.RoleMiddleware([]string{"developer", "superadmin"}), accessLogController.GetAccessLogTimeline)
+
+	// Security event routes (protected - developer and superadmin only)
+	securityEventRoutes := protected.Group("/security-events")
+	securityEventRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), securityEventController.GetSecurityEvents)
+	securityEventRoutes.Get("/alert-rules", middleware.RoleMiddleware([]string{"developer", "superadmin"}), securityEventController.GetSecurityAlertRules)
+
+	// Inbound webhook log routes (protected - developer and superadmin only)
+	webhookEventRoutes := protected.Group("/webhook-events")
+	webhookEventRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookEventController.GetWebhookEvents)
+	webhookEventRoutes.Get("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookEventController.GetWebhookEvent)
+	webhookEventRoutes.Post("/:id/reprocess", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookEventController.ReprocessWebhookEvent)
+
+	// Outbound order event webhook subscription routes (protected - developer and superadmin only)
+	webhookSubscriptionRoutes := protected.Group("/webhook-subscriptions")
+	webhookSubscriptionRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookSubscriptionController.GetWebhookSubscriptions)
+	webhookSubscriptionRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookSubscriptionController.CreateWebhookSubscription)
+	webhookSubscriptionRoutes.Get("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookSubscriptionController.GetWebhookSubscription)
+	webhookSubscriptionRoutes.Put("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookSubscriptionController.UpdateWebhookSubscription)
+	webhookSubscriptionRoutes.Delete("/:id", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookSubscriptionController.DeleteWebhookSubscription)
+	webhookSubscriptionRoutes.Post("/:id/test", middleware.RoleMiddleware([]string{"developer", "superadmin"}), webhookSubscriptionController.TestWebhookSubscription)
+
+	// Shadow mode divergence routes (protected - developer and superadmin only)
+	shadowDivergenceRoutes := protected.Group("/shadow-divergences")
+	shadowDivergenceRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), shadowDivergenceController.GetShadowDivergences)
+	shadowDivergenceRoutes.Get("/stats", middleware.RoleMiddleware([]string{"developer", "superadmin"}), shadowDivergenceController.GetShadowStats)
+
+	// Feature flag routes (evaluated flags for every user, managing them developer and superadmin only)
+	featureFlagRoutes := protected.Group("/feature-flags")
+	featureFlagRoutes.Get("/me", featureFlagController.GetMyFeatureFlags)
+	featureFlagRoutes.Get("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), featureFlagController.GetFeatureFlags)
+	featureFlagRoutes.Get("/audits", middleware.RoleMiddleware([]string{"developer", "superadmin"}), featureFlagController.GetFeatureFlagAudits)
+	featureFlagRoutes.Post("/", middleware.RoleMiddleware([]string{"developer", "superadmin"}), featureFlagController.CreateFeatureFlag)
+	featureFlagRoutes.Put("/:key", middleware.RoleMiddleware([]string{"developer", "superadmin"}), featureFlagController.UpdateFeatureFlag)
+	featureFlagRoutes.Delete("/:key", middleware.RoleMiddleware([]string{"developer", "superadmin"}), featureFlagController.DeleteFeatureFlag)
+}
+
+// setupPublicDocsRoutes serves the public spec embedded in the binary, so it does not depend on the working directory
+func setupPublicDocsRoutes(app *fiber.App) {
+	app.Get("/docs/public/swagger.json", func(c fiber.Ctx) error {
+		c.Set("Content-Type", "application/json")
+		return c.Send(publicdocs.SwaggerJSON)
+	})
+
+	app.Get("/docs/public/swagger.yaml", func(c fiber.Ctx) error {
+		c.Set("Content-Type", "application/yaml")
+		return c.Send(publicdocs.SwaggerYAML)
+	})
+
+	app.Get("/docs/public", func(c fiber.Ctx) error {
+		c.Set("Content-Type", "text/html")
+		return c.SendString(swaggerUIPage("/docs/public/swagger.json"))
+	})
+}
+
+// swaggerUIPage renders the Swagger UI HTML page for the given spec URL
+func swaggerUIPage(specURL string) string {
+	return `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8" />
+  <meta name="viewport" content="width=device-width, initial-scale=1" />
+  <meta name="description" content="SwaggerUI" />
+  <title>Livo API - Swagger UI</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui.css" />
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5.11.0/swagger-ui-bundle.js" crossorigin></script>
+<script>
+  window.onload = () => {
+    window.ui = SwaggerUIBundle({
+      url: '` + specURL + `',
+      dom_id: '#swagger-ui',
+    });
+  };
+</script>
+</body>
+</html>`
+}
+
+// docsSpecURL forwards the docs API key used to open a docs page to its spec URL,
+// so the browser can load the protected spec without an Authorization header
+func docsSpecURL(c fiber.Ctx, specURL string) string {
+	if key := c.Query("key"); key != "" {
+		return specURL + "?key=" + url.QueryEscape(key)
+	}
+	return specURL
 }
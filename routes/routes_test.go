@@ -0,0 +1,56 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestPublicDocsSpec(t *testing.T) {
+	app := fiber.New()
+	setupPublicDocsRoutes(app)
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/docs/public/swagger.json", nil))
+	if err != nil {
+		t.Fatalf("GET /docs/public/swagger.json failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	var spec struct {
+		Paths map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("invalid spec: %v", err)
+	}
+
+	for _, path := range []string{"/api/auth/login", "/api/public/track/{trackingNumber}"} {
+		if _, ok := spec.Paths[path]; !ok {
+			t.Errorf("public spec lacks %s", path)
+		}
+	}
+
+	// Only the authentication, invitation and public tracking endpoints are documented publicly
+	publicPrefixes := []string{"/api/auth/", "/api/invitations/", "/api/public/"}
+	for path := range spec.Paths {
+		public := false
+		for _, prefix := range publicPrefixes {
+			if strings.HasPrefix(path, prefix) {
+				public = true
+			}
+		}
+		if !public {
+			t.Errorf("public spec documents internal path %s", path)
+		}
+	}
+	for _, internal := range []string{"/api/users", "/api/orders", "/api/webhook-events", "/api/reports/billing"} {
+		if _, ok := spec.Paths[internal]; ok {
+			t.Errorf("public spec documents internal path %s", internal)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package testutil
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"livo-fiber-backend/utils"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FactoryPassword is the password of every user created by a factory
+const FactoryPassword = "12345678"
+
+// factorySequence numbers the records of every factory of the test binary, keeping identifiers unique
+var factorySequence atomic.Int64
+
+// runPrefix keeps identifiers unique across runs against the same TEST_DATABASE_DSN
+var runPrefix = "T" + time.Now().UTC().Format("060102150405")
+
+// Factory creates records with valid defaults in a test transaction, failing the test when a record cannot be created
+type Factory struct {
+	tb testing.TB
+	tx *gorm.DB
+}
+
+// NewFactory returns a factory creating records in tx, usually the transaction of Begin
+func NewFactory(tb testing.TB, tx *gorm.DB) *Factory {
+	return &Factory{tb: tb, tx: tx}
+}
+
+// next returns a unique identifier with the prefix
+func (f *Factory) next(prefix string) string {
+	return fmt.Sprintf("%s%s%06d", prefix, runPrefix, factorySequence.Add(1))
+}
+
+// create inserts the record or fails the test
+func (f *Factory) create(record interface{}, name string) {
+	f.tb.Helper()
+	if err := f.tx.Create(record).Error; err != nil {
+		f.tb.Fatalf("failed to create %s: %v", name, err)
+	}
+}
+
+// User creates an active user with the seeded role, the password is FactoryPassword
+func (f *Factory) User(roleName string) models.User {
+	f.tb.Helper()
+
+	var role models.Role
+	if err := f.tx.Where("role_name = ?", roleName).First(&role).Error; err != nil {
+		f.tb.Fatalf("role %s not found: %v", roleName, err)
+	}
+	hashedPassword, err := utils.HashPassword(FactoryPassword)
+	if err != nil {
+		f.tb.Fatalf("failed to hash factory password: %v", err)
+	}
+
+	// Usernames and emails are stored normalized, lookups by the handlers lowercase them
+	username := strings.ToLower(f.next("user"))
+	user := models.User{
+		Username: username,
+		Password: hashedPassword,
+		FullName: "Test " + roleName,
+		Email:    username + "@test.local",
+		IsActive: true,
+		Roles:    []models.Role{role},
+	}
+	f.create(&user, "user")
+	return user
+}
+
+// OrderOption customizes an order before it is created
+type OrderOption func(*models.Order)
+
+// WithStatus sets the processing and event status of the order
+func WithStatus(processingStatus, eventStatus string) OrderOption {
+	return func(o *models.Order) {
+		o.ProcessingStatus = processingStatus
+		o.EventStatus = eventStatus
+	}
+}
+
+// WithChannel sets the channel of the order, by name or code
+func WithChannel(channel string) OrderOption {
+	return func(o *models.Order) {
+		o.Channel = channel
+	}
+}
+
+// WithDetails replaces the default order line
+func WithDetails(details ...models.OrderDetail) OrderOption {
+	return func(o *models.Order) {
+		o.OrderDetails = details
+	}
+}
+
+// WithCreatedAt backdates the order
+func WithCreatedAt(createdAt time.Time) OrderOption {
+	return func(o *models.Order) {
+		o.CreatedAt = createdAt
+		o.UpdatedAt = createdAt
+		o.SentBefore = createdAt.Add(24 * time.Hour)
+	}
+}
+
+// Order creates a ready to pick order with one line on the first seeded channel and store
+func (f *Factory) Order(options ...OrderOption) models.Order {
+	f.tb.Helper()
+
+	var channel models.Channel
+	if err := f.tx.Order("id").First(&channel).Error; err != nil {
+		f.tb.Fatalf("no channel found, run the initial seeds first: %v", err)
+	}
+	var store models.Store
+	if err := f.tx.Order("id").First(&store).Error; err != nil {
+		f.tb.Fatalf("no store found, run the initial seeds first: %v", err)
+	}
+
+	now := time.Now()
+	order := models.Order{
+		OrderGineeID:     f.next("ORD"),
+		ProcessingStatus: models.ProcessingStatusReadyToPick,
+		EventStatus:      models.EventStatusInProgress,
+		Channel:          channel.ChannelName,
+		Store:            store.StoreName,
+		Buyer:            "Test Buyer",
+		Address:          "Jl. Soekarno Hatta No. 12, Lowokwaru, Kota Malang, Jawa Timur 65141",
+		Courier:          "JNE",
+		TrackingNumber:   f.next("TN"),
+		SentBefore:       now.Add(24 * time.Hour),
+		Currency:         "IDR",
+		OrderDetails: []models.OrderDetail{
+			{SKU: f.next("SKU"), ProductName: "Test Product", Quantity: 1, Price: 50000},
+		},
+	}
+	for _, option := range options {
+		option(&order)
+	}
+	order.CalculateTotals()
+
+	f.create(&order, "order")
+	return order
+}
+
+// QCRibbon creates a QC Ribbon of the order
+func (f *Factory) QCRibbon(order models.Order, qcBy models.User, status models.QCStatus) models.QCRibbon {
+	f.tb.Helper()
+
+	box := f.box()
+	qc := models.QCRibbon{
+		TrackingNumber:  order.TrackingNumber,
+		QCBy:            qcBy.ID,
+		Status:          status,
+		QCRibbonDetails: []models.QCRibbonDetail{{BoxID: box.ID, Quantity: 1}},
+	}
+	f.create(&qc, "QC ribbon")
+	return qc
+}
+
+// QCOnline creates a QC Online of the order
+func (f *Factory) QCOnline(order models.Order, qcBy models.User, status models.QCStatus) models.QCOnline {
+	f.tb.Helper()
+
+	box := f.box()
+	qc := models.QCOnline{
+		TrackingNumber:  order.TrackingNumber,
+		QCBy:            qcBy.ID,
+		Status:          status,
+		QCOnlineDetails: []models.QCOnlineDetail{{BoxID: box.ID, Quantity: 1}},
+	}
+	f.create(&qc, "QC online")
+	return qc
+}
+
+// Outbound creates the outbound scan of the order
+func (f *Factory) Outbound(order models.Order, outboundBy models.User) models.Outbound {
+	f.tb.Helper()
+
+	outbound := models.Outbound{
+		TrackingNumber: order.TrackingNumber,
+		OutboundBy:     outboundBy.ID,
+		Expedition:     order.Courier,
+	}
+	f.create(&outbound, "outbound")
+	return outbound
+}
+
+// box returns the first seeded box
+func (f *Factory) box() models.Box {
+	f.tb.Helper()
+
+	var box models.Box
+	if err := f.tx.Order("id").First(&box).Error; err != nil {
+		f.tb.Fatalf("no box found, run the initial seeds first: %v", err)
+	}
+	return box
+}
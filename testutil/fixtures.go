@@ -0,0 +1,119 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/database"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// initialSeeds are the seeds main runs on startup, in the same order
+var initialSeeds = []struct {
+	Name string
+	Run  func() error
+}{
+	{"roles", database.SeedInitialRole},
+	{"boxes", database.SeedInitialBox},
+	{"channels", database.SeedInitialChannel},
+	{"expeditions", database.SeedInitialExpedition},
+	{"stores", database.SeedInitialStore},
+	{"users", database.SeedInitialUser},
+	{"locations", database.SeedInitialLocation},
+	{"root-causes", database.SeedInitialComplainRootCause},
+}
+
+// Config returns the configuration tests run with: the environment defaults with a UTC database
+func Config() *config.Config {
+	cfg := config.LoadConfig()
+	cfg.DbTz = "UTC"
+	return cfg
+}
+
+// OpenDatabase connects to the test database, migrates it and runs the initial seeds.
+// database.DB is pointed at the connection, the seeds and a few helpers use it.
+func OpenDatabase(pg *Postgres, cfg *config.Config) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(pg.DSN), &gorm.Config{
+		Logger:  logger.Default.LogMode(logger.Silent),
+		NowFunc: func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to test database: %w", err)
+	}
+	database.DB = db
+
+	if err := database.MigrateDatabase(cfg); err != nil {
+		return nil, err
+	}
+	for _, seed := range initialSeeds {
+		if err := seed.Run(); err != nil {
+			return nil, fmt.Errorf("failed to seed %s: %w", seed.Name, err)
+		}
+	}
+	return db, nil
+}
+
+// RunMain starts the test database, migrates and seeds it, runs the tests of the package and removes the database.
+// Without docker and TEST_DATABASE_DSN the tests run with a nil db, tests needing it are skipped by RequireDatabase.
+// Call it from TestMain, the connection is stored in db before the tests run:
+//
+//	var testDB *gorm.DB
+//
+//	func TestMain(m *testing.M) { testutil.RunMain(m, &testDB) }
+func RunMain(m *testing.M, db **gorm.DB) {
+	pg, err := StartPostgres(context.Background())
+	if errors.Is(err, ErrNoTestDatabase) {
+		log.Println("Skipping database tests:", err)
+		os.Exit(m.Run())
+	}
+	if err != nil {
+		log.Fatalf("Failed to start test database: %v", err)
+	}
+
+	*db, err = OpenDatabase(pg, Config())
+	if err != nil {
+		pg.Stop()
+		log.Fatalf("Failed to prepare test database: %v", err)
+	}
+
+	code := m.Run()
+	if err := pg.Stop(); err != nil {
+		log.Println("Failed to stop test database:", err)
+	}
+	os.Exit(code)
+}
+
+// RequireDatabase skips the test when RunMain found no test database
+func RequireDatabase(tb testing.TB, db *gorm.DB) {
+	tb.Helper()
+	if db == nil {
+		tb.Skipf("no test database, install docker or set %s", TestDatabaseDSNEnv)
+	}
+}
+
+// Begin starts a transaction rolled back when the test ends, so every test starts from the seeded database.
+// Transactions the code under test opens with db.Transaction become savepoints of it. Handlers calling DB.Begin
+// cannot run in it, test those against db itself: the factories keep the identifiers of every run unique.
+func Begin(tb testing.TB, db *gorm.DB) *gorm.DB {
+	tb.Helper()
+	RequireDatabase(tb, db)
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		tb.Fatalf("failed to begin test transaction: %v", tx.Error)
+	}
+	tb.Cleanup(func() {
+		if err := tx.Rollback().Error; err != nil {
+			tb.Errorf("failed to roll back test transaction: %v", err)
+		}
+	})
+	return tx
+}
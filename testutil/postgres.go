@@ -0,0 +1,132 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Postgres image and credentials of the throwaway test database
+const (
+	PostgresImage    = "postgres:16-alpine"
+	postgresUser     = "livo"
+	postgresPassword = "livo"
+	postgresDatabase = "livo_test"
+)
+
+// TestDatabaseDSNEnv points the harness at an existing database instead of starting a container,
+// e.g. the Postgres service of a CI pipeline
+const TestDatabaseDSNEnv = "TEST_DATABASE_DSN"
+
+// ErrNoTestDatabase is returned by StartPostgres when neither docker nor TEST_DATABASE_DSN is available
+var ErrNoTestDatabase = errors.New("no test database available")
+
+// postgresStartTimeout is how long a fresh container gets to accept connections
+const postgresStartTimeout = 60 * time.Second
+
+// Postgres is a test database, started in a docker container or given through TEST_DATABASE_DSN
+type Postgres struct {
+	DSN         string
+	containerID string // empty when the database was not started by the harness
+}
+
+// StartPostgres starts a Postgres container and waits until it accepts connections.
+// When TEST_DATABASE_DSN is set that database is used as is and nothing is started.
+func StartPostgres(ctx context.Context) (*Postgres, error) {
+	if dsn := strings.TrimSpace(os.Getenv(TestDatabaseDSNEnv)); dsn != "" {
+		return &Postgres{DSN: dsn}, nil
+	}
+
+	if _, err := exec.LookPath("docker"); err != nil {
+		return nil, fmt.Errorf("%w, install docker or set %s: %v", ErrNoTestDatabase, TestDatabaseDSNEnv, err)
+	}
+
+	output, err := exec.CommandContext(ctx, "docker", "run", "-d", "--rm",
+		"-e", "POSTGRES_USER="+postgresUser,
+		"-e", "POSTGRES_PASSWORD="+postgresPassword,
+		"-e", "POSTGRES_DB="+postgresDatabase,
+		"-p", "127.0.0.1::5432",
+		PostgresImage,
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start postgres container: %w", commandError(err))
+	}
+	pg := &Postgres{containerID: strings.TrimSpace(string(output))}
+
+	// Docker picks a free host port, look it up
+	output, err = exec.CommandContext(ctx, "docker", "port", pg.containerID, "5432/tcp").Output()
+	if err != nil {
+		pg.Stop()
+		return nil, fmt.Errorf("failed to get postgres container port: %w", commandError(err))
+	}
+	address := strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+	host, port, found := strings.Cut(address, ":")
+	if !found {
+		pg.Stop()
+		return nil, fmt.Errorf("unexpected postgres container port %q", address)
+	}
+	pg.DSN = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=UTC",
+		host, port, postgresUser, postgresPassword, postgresDatabase)
+
+	if err := pg.waitReady(ctx); err != nil {
+		pg.Stop()
+		return nil, err
+	}
+	return pg, nil
+}
+
+// waitReady polls the database until it accepts connections or postgresStartTimeout passes
+func (pg *Postgres) waitReady(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, postgresStartTimeout)
+	defer cancel()
+
+	for {
+		db, err := gorm.Open(postgres.Open(pg.DSN), &gorm.Config{Logger: logger.Discard})
+		if err == nil {
+			sqlDB, dbErr := db.DB()
+			if dbErr == nil {
+				err = sqlDB.PingContext(ctx)
+				sqlDB.Close()
+			} else {
+				err = dbErr
+			}
+		}
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("postgres did not accept connections within %s: %w", postgresStartTimeout, err)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// Stop removes the container, databases given through TEST_DATABASE_DSN are left alone
+func (pg *Postgres) Stop() error {
+	if pg.containerID == "" {
+		return nil
+	}
+	if err := exec.Command("docker", "rm", "-f", pg.containerID).Run(); err != nil {
+		return fmt.Errorf("failed to remove postgres container %s: %w", pg.containerID, commandError(err))
+	}
+	return nil
+}
+
+// commandError adds the stderr of a failed command to its error
+func commandError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package testutil
+
+import (
+	"livo-fiber-backend/models"
+	"time"
+)
+
+// OrderSeed is an order with the records of the stages it went through, nil for stages it has not reached
+type OrderSeed struct {
+	Order    models.Order
+	Picker   *models.User
+	QCRibbon *models.QCRibbon
+	QCOnline *models.QCOnline
+	Outbound *models.Outbound
+}
+
+// lifecycleStages are the processing statuses in the order an order goes through them
+var lifecycleStages = []string{
+	models.ProcessingStatusReadyToPick,
+	models.ProcessingStatusPickingProgress,
+	models.ProcessingStatusPickingCompleted,
+	models.ProcessingStatusQCProgress,
+	models.ProcessingStatusQCCompleted,
+	models.ProcessingStatusOutboundCompleted,
+}
+
+// reached reports whether an order in stage went through target
+func reached(stage, target string) bool {
+	for _, lifecycleStage := range lifecycleStages {
+		if lifecycleStage == target {
+			return true
+		}
+		if lifecycleStage == stage {
+			return false
+		}
+	}
+	return false
+}
+
+// OrderAtStage creates an order in a processing status of the happy path together with the records
+// the earlier stages leave behind: picker assignment, picked order, QC on the channel's lane and outbound scan.
+// The options are applied to the order before the stage fields, WithStatus is overridden.
+func (f *Factory) OrderAtStage(stage string, options ...OrderOption) OrderSeed {
+	f.tb.Helper()
+
+	if !reached(stage, stage) {
+		f.tb.Fatalf("%s is not a lifecycle stage", stage)
+	}
+
+	eventStatus := models.EventStatusInProgress
+	if stage == models.ProcessingStatusOutboundCompleted {
+		eventStatus = models.EventStatusCompleted
+	}
+
+	seed := OrderSeed{}
+	now := time.Now()
+	if reached(stage, models.ProcessingStatusPickingProgress) {
+		picker := f.User("picker")
+		seed.Picker = &picker
+		options = append(options, func(o *models.Order) {
+			o.AssignedAt = &now
+			o.PickedBy = &picker.ID
+		})
+	}
+	if reached(stage, models.ProcessingStatusPickingCompleted) {
+		options = append(options, func(o *models.Order) {
+			o.PickedAt = &now
+			for d := range o.OrderDetails {
+				o.OrderDetails[d].PickedQuantity = o.OrderDetails[d].Quantity
+				o.OrderDetails[d].IsPicked = true
+				o.OrderDetails[d].ItemPickedAt = &now
+			}
+		})
+	}
+	options = append(options, WithStatus(stage, eventStatus))
+	seed.Order = f.Order(options...)
+
+	if reached(stage, models.ProcessingStatusPickingCompleted) {
+		f.create(&models.PickedOrder{OrderID: seed.Order.ID, PickedBy: seed.Picker.ID}, "picked order")
+	}
+
+	if reached(stage, models.ProcessingStatusQCProgress) {
+		qcStatus := models.QCStatusCompleted
+		if stage == models.ProcessingStatusQCProgress {
+			qcStatus = models.QCStatusInProgress
+		}
+
+		var channel models.Channel
+		f.tx.Where("LOWER(channel_name) = LOWER(?) OR LOWER(channel_code) = LOWER(?)", seed.Order.Channel, seed.Order.Channel).First(&channel)
+		if channel.QCLane == "ribbon" {
+			qc := f.QCRibbon(seed.Order, f.User("qc-ribbon"), qcStatus)
+			seed.QCRibbon = &qc
+		} else {
+			qc := f.QCOnline(seed.Order, f.User("qc-online"), qcStatus)
+			seed.QCOnline = &qc
+		}
+	}
+
+	if reached(stage, models.ProcessingStatusOutboundCompleted) {
+		outbound := f.Outbound(seed.Order, f.User("outbound"))
+		seed.Outbound = &outbound
+	}
+
+	return seed
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Access log writer tuning, entries are inserted in batches so logging never waits on the database
+const (
+	accessLogBufferSize    = 5000
+	accessLogBatchSize     = 500
+	accessLogFlushInterval = 2 * time.Second
+)
+
+var (
+	accessLogQueue   chan models.AccessLog
+	accessLogDropped atomic.Int64
+)
+
+// RecordAccessLog queues an access log entry for persistence.
+// Entries are dropped, and counted, when the writer is not started or cannot keep up.
+func RecordAccessLog(entry models.AccessLog) {
+	if accessLogQueue == nil {
+		return
+	}
+	select {
+	case accessLogQueue <- entry:
+	default:
+		accessLogDropped.Add(1)
+	}
+}
+
+// AccessLogDropped returns the number of access log entries dropped since startup
+func AccessLogDropped() int64 {
+	return accessLogDropped.Load()
+}
+
+// StartAccessLogWriter persists queued access logs in batches and deletes entries older than the retention days
+// once a day so the table rotates. retentionDays 0 keeps every entry.
+func StartAccessLogWriter(db *gorm.DB, retentionDays int) {
+	accessLogQueue = make(chan models.AccessLog, accessLogBufferSize)
+
+	go func() {
+		ticker := time.NewTicker(accessLogFlushInterval)
+		defer ticker.Stop()
+
+		batch := make([]models.AccessLog, 0, accessLogBatchSize)
+		flush := func() {
+			if len(batch) == 0 {
+				return
+			}
+			if err := db.CreateInBatches(batch, accessLogBatchSize).Error; err != nil {
+				log.Println("StartAccessLogWriter - Failed to persist access logs:", err)
+				accessLogDropped.Add(int64(len(batch)))
+			}
+			batch = batch[:0]
+		}
+
+		var lastRotation time.Time
+		for {
+			select {
+			case entry := <-accessLogQueue:
+				batch = append(batch, entry)
+				if len(batch) >= accessLogBatchSize {
+					flush()
+				}
+			case now := <-ticker.C:
+				flush()
+				if retentionDays > 0 && now.Sub(lastRotation) >= 24*time.Hour {
+					rotateAccessLogs(db, now.AddDate(0, 0, -retentionDays))
+					lastRotation = now
+				}
+			}
+		}
+	}()
+}
+
+// rotateAccessLogs deletes the access logs created before the cutoff
+func rotateAccessLogs(db *gorm.DB, cutoff time.Time) {
+	result := db.Where("created_at < ?", cutoff).Delete(&models.AccessLog{})
+	if result.Error != nil {
+		log.Println("StartAccessLogWriter - Failed to rotate access logs:", result.Error)
+		return
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("StartAccessLogWriter - Rotated %d access logs older than %s\n", result.RowsAffected, cutoff.Format("02-01-2006 15:04:05"))
+	}
+}
@@ -0,0 +1,406 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountingSyncNotifyRoles are notified when an order could not be pushed to the accounting system
+var AccountingSyncNotifyRoles = []string{"finance"}
+
+// accountingSyncBatchSize bounds the syncs pushed per run of the worker
+const accountingSyncBatchSize = 50
+
+// Delay before a failed sync is retried, doubled on every failed attempt
+const (
+	accountingRetryBaseDelay = time.Minute
+	accountingRetryMaxDelay  = 6 * time.Hour
+)
+
+var accountingClient = &http.Client{Timeout: 30 * time.Second}
+
+// AccountingSale is a completed order booked as a sales invoice, normalized across accounting systems
+type AccountingSale struct {
+	Number        string // marketplace order ID, the invoice number in the accounting system
+	Date          time.Time
+	CustomerNo    string
+	WarehouseName string
+	BranchName    string
+	TaxIncluded   bool
+	Description   string
+	Lines         []AccountingSaleLine
+}
+
+// AccountingSaleLine is an order line of a sale, booked under its SKU
+type AccountingSaleLine struct {
+	ItemNo    string
+	Name      string
+	Quantity  int
+	UnitPrice int
+}
+
+// accountingAdapters books a sale in each supported accounting system and returns its transaction number
+var accountingAdapters = map[string]func(ctx context.Context, cfg *config.Config, sale AccountingSale) (string, error){
+	models.AccountingProviderAccurate: pushAccurateSale,
+	models.AccountingProviderJurnal:   pushJurnalSale,
+}
+
+// IsAccountingProviderConfigured reports whether the credentials of the accounting system are set
+func IsAccountingProviderConfigured(cfg *config.Config, provider string) bool {
+	switch provider {
+	case models.AccountingProviderAccurate:
+		return cfg.AccurateAPIURL != "" && cfg.AccurateAccessToken != "" && cfg.AccurateSessionID != ""
+	case models.AccountingProviderJurnal:
+		return cfg.JurnalAPIURL != "" && cfg.JurnalAPIKey != ""
+	}
+	return false
+}
+
+// EnqueueAccountingSync queues a completed order for the accounting system of its store.
+// Orders of stores without an enabled mapping are not queued, an order is queued at most once.
+func EnqueueAccountingSync(db *gorm.DB, order *models.Order) error {
+	var store models.Store
+	err := db.Where("LOWER(store_name) = LOWER(?) OR LOWER(store_code) = LOWER(?)", order.Store, order.Store).First(&store).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var mapping models.AccountingMapping
+	err = db.Where("store_id = ? AND enabled = ?", store.ID, true).First(&mapping).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "order_id"}}, DoNothing: true}).Create(&models.AccountingSync{
+		OrderID:       order.ID,
+		StoreID:       store.ID,
+		Provider:      mapping.Provider,
+		Status:        models.AccountingSyncStatusPending,
+		NextAttemptAt: &now,
+	}).Error
+}
+
+// accountingRetryDelay returns the delay before the attempt following the given number of failed attempts
+func accountingRetryDelay(attempts int) time.Duration {
+	delay := accountingRetryBaseDelay
+	for i := 1; i < attempts && delay < accountingRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, accountingRetryMaxDelay)
+}
+
+// RunDueAccountingSyncs pushes the queued orders whose attempt is due and returns how many were synced
+func RunDueAccountingSyncs(cfg *config.Config, db *gorm.DB) (int, error) {
+	now := time.Now()
+
+	var syncs []models.AccountingSync
+	if err := db.Where("status = ? AND next_attempt_at <= ?", models.AccountingSyncStatusPending, now).
+		Order("next_attempt_at ASC").Limit(accountingSyncBatchSize).Find(&syncs).Error; err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for i := range syncs {
+		sync := &syncs[i]
+
+		// Claim the attempt by moving the next attempt to the retry time, so a concurrent run skips it
+		attempts := sync.Attempts + 1
+		result := db.Model(&models.AccountingSync{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", sync.ID, models.AccountingSyncStatusPending, *sync.NextAttemptAt).
+			Updates(map[string]interface{}{
+				"attempts":        attempts,
+				"next_attempt_at": now.Add(accountingRetryDelay(attempts)),
+				"last_attempt_at": now,
+			})
+		if result.Error != nil {
+			log.Println("RunDueAccountingSyncs - Failed to claim accounting sync", sync.ID, ":", result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		sync.Attempts = attempts
+
+		externalID, err := pushAccountingSync(cfg, db, sync)
+		if err != nil {
+			log.Printf("RunDueAccountingSyncs - Accounting sync %d failed (attempt %d): %v\n", sync.ID, attempts, err)
+			recordAccountingSyncFailure(cfg, db, sync, err)
+			continue
+		}
+
+		if err := db.Model(&models.AccountingSync{}).Where("id = ?", sync.ID).Updates(map[string]interface{}{
+			"status":          models.AccountingSyncStatusSynced,
+			"external_id":     externalID,
+			"synced_at":       time.Now(),
+			"next_attempt_at": nil,
+			"last_error":      "",
+		}).Error; err != nil {
+			log.Println("RunDueAccountingSyncs - Failed to record accounting sync", sync.ID, ":", err)
+			continue
+		}
+		synced++
+	}
+	return synced, nil
+}
+
+// recordAccountingSyncFailure stores the error of a failed attempt. The sync stays queued for its retry
+// until the attempts run out, it is then marked failed and finance is notified.
+func recordAccountingSyncFailure(cfg *config.Config, db *gorm.DB, sync *models.AccountingSync, syncErr error) {
+	updates := map[string]interface{}{"last_error": syncErr.Error()}
+	exhausted := sync.Attempts >= cfg.AccountingSyncMaxAttempts
+	if exhausted {
+		updates["status"] = models.AccountingSyncStatusFailed
+		updates["next_attempt_at"] = nil
+	}
+	if err := db.Model(&models.AccountingSync{}).Where("id = ?", sync.ID).Updates(updates).Error; err != nil {
+		log.Println("recordAccountingSyncFailure - Failed to record accounting sync failure", sync.ID, ":", err)
+		return
+	}
+	if !exhausted {
+		return
+	}
+
+	var order models.Order
+	db.Select("id", "order_ginee_id").Where("id = ?", sync.OrderID).First(&order)
+	if err := NotifyRoles(db, AccountingSyncNotifyRoles, "accounting_sync_failed", "Accounting sync failed",
+		fmt.Sprintf("Order %s could not be pushed to %s after %d attempts: %s", order.OrderGineeID, sync.Provider, sync.Attempts, syncErr.Error()),
+		"accounting_sync", sync.ID); err != nil {
+		log.Println("recordAccountingSyncFailure - Failed to notify finance:", err)
+	}
+}
+
+// pushAccountingSync books the order of a sync in the accounting system of its store mapping
+func pushAccountingSync(cfg *config.Config, db *gorm.DB, sync *models.AccountingSync) (string, error) {
+	adapter, ok := accountingAdapters[sync.Provider]
+	if !ok {
+		return "", fmt.Errorf("unsupported accounting provider %s", sync.Provider)
+	}
+	if !IsAccountingProviderConfigured(cfg, sync.Provider) {
+		return "", fmt.Errorf("%s credentials are not configured", sync.Provider)
+	}
+
+	var mapping models.AccountingMapping
+	if err := db.Where("store_id = ?", sync.StoreID).First(&mapping).Error; err != nil {
+		return "", fmt.Errorf("store has no accounting mapping: %w", err)
+	}
+	if mapping.Provider != sync.Provider {
+		return "", fmt.Errorf("store is now mapped to %s, retry the sync to push it there", mapping.Provider)
+	}
+
+	var order models.Order
+	if err := db.Preload("OrderDetails").Where("id = ?", sync.OrderID).First(&order).Error; err != nil {
+		return "", fmt.Errorf("failed to load order: %w", err)
+	}
+
+	sale := AccountingSale{
+		Number:        order.OrderGineeID,
+		Date:          sync.CreatedAt, // queued when the order was outbound scanned
+		CustomerNo:    mapping.CustomerNo,
+		WarehouseName: mapping.WarehouseName,
+		BranchName:    mapping.BranchName,
+		TaxIncluded:   mapping.TaxIncluded,
+		Description:   strings.TrimSpace(fmt.Sprintf("%s %s %s", order.Channel, order.Store, order.TrackingNumber)),
+	}
+	for _, detail := range order.OrderDetails {
+		if detail.Quantity <= 0 {
+			continue
+		}
+		name := detail.ProductName
+		if detail.Variant != "" {
+			name += " - " + detail.Variant
+		}
+		sale.Lines = append(sale.Lines, AccountingSaleLine{
+			ItemNo:    detail.SKU,
+			Name:      name,
+			Quantity:  detail.Quantity,
+			UnitPrice: detail.Price,
+		})
+	}
+	if len(sale.Lines) == 0 {
+		return "", errors.New("order has no lines to book")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), accountingClient.Timeout)
+	defer cancel()
+	return adapter(ctx, cfg, sale)
+}
+
+// postAccountingJSON posts a JSON payload to an accounting system and returns the response body
+func postAccountingJSON(ctx context.Context, provider, url string, headers map[string]string, payload interface{}) ([]byte, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := accountingClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned %d: %s", provider, resp.StatusCode, truncateLabel(string(body), 512))
+	}
+	return body, nil
+}
+
+// accurateSaveResponse is the Accurate Online save response, d holds the messages when s is false
+type accurateSaveResponse struct {
+	S bool            `json:"s"`
+	D json.RawMessage `json:"d"`
+	R struct {
+		Number string `json:"number"`
+	} `json:"r"`
+}
+
+// pushAccurateSale saves the sale as an Accurate Online sales invoice
+func pushAccurateSale(ctx context.Context, cfg *config.Config, sale AccountingSale) (string, error) {
+	details := make([]map[string]interface{}, len(sale.Lines))
+	for i, line := range sale.Lines {
+		detail := map[string]interface{}{
+			"itemNo":     line.ItemNo,
+			"detailName": line.Name,
+			"quantity":   line.Quantity,
+			"unitPrice":  line.UnitPrice,
+		}
+		if sale.WarehouseName != "" {
+			detail["warehouseName"] = sale.WarehouseName
+		}
+		details[i] = detail
+	}
+	payload := map[string]interface{}{
+		"number":       sale.Number,
+		"transDate":    sale.Date.Format("02/01/2006"),
+		"customerNo":   sale.CustomerNo,
+		"description":  sale.Description,
+		"inclusiveTax": sale.TaxIncluded,
+		"detailItem":   details,
+	}
+	if sale.BranchName != "" {
+		payload["branchName"] = sale.BranchName
+	}
+
+	body, err := postAccountingJSON(ctx, models.AccountingProviderAccurate, strings.TrimRight(cfg.AccurateAPIURL, "/")+"/api/sales-invoice/save.do", map[string]string{
+		"Authorization": "Bearer " + cfg.AccurateAccessToken,
+		"X-Session-ID":  cfg.AccurateSessionID,
+	}, payload)
+	if err != nil {
+		return "", err
+	}
+
+	var response accurateSaveResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", errors.New("invalid Accurate response")
+	}
+	if !response.S {
+		return "", fmt.Errorf("accurate rejected the sale: %s", truncateLabel(string(response.D), 512))
+	}
+	if response.R.Number != "" {
+		return response.R.Number, nil
+	}
+	return sale.Number, nil
+}
+
+// jurnalSalesInvoiceResponse is the Jurnal sales invoice response
+type jurnalSalesInvoiceResponse struct {
+	SalesInvoice struct {
+		TransactionNo string `json:"transaction_no"`
+	} `json:"sales_invoice"`
+}
+
+// pushJurnalSale creates the sale as a Jurnal sales invoice, products are matched by name against the SKU
+func pushJurnalSale(ctx context.Context, cfg *config.Config, sale AccountingSale) (string, error) {
+	lines := make([]map[string]interface{}, len(sale.Lines))
+	for i, line := range sale.Lines {
+		lines[i] = map[string]interface{}{
+			"product_name": line.ItemNo,
+			"description":  line.Name,
+			"quantity":     line.Quantity,
+			"rate":         line.UnitPrice,
+		}
+	}
+	invoice := map[string]interface{}{
+		"transaction_no":               sale.Number,
+		"reference_no":                 sale.Number,
+		"transaction_date":             sale.Date.Format("2006-01-02"),
+		"person_name":                  sale.CustomerNo,
+		"memo":                         sale.Description,
+		"transaction_lines_attributes": lines,
+	}
+	if sale.WarehouseName != "" {
+		invoice["warehouse_name"] = sale.WarehouseName
+	}
+
+	body, err := postAccountingJSON(ctx, models.AccountingProviderJurnal, strings.TrimRight(cfg.JurnalAPIURL, "/")+"/sales_invoices", map[string]string{
+		"apikey": cfg.JurnalAPIKey,
+	}, map[string]interface{}{"sales_invoice": invoice})
+	if err != nil {
+		return "", err
+	}
+
+	var response jurnalSalesInvoiceResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", errors.New("invalid Jurnal response")
+	}
+	if response.SalesInvoice.TransactionNo != "" {
+		return response.SalesInvoice.TransactionNo, nil
+	}
+	return sale.Number, nil
+}
+
+// StartAccountingSyncWorker pushes the queued orders to the accounting systems periodically in the background
+func StartAccountingSyncWorker(cfg *config.Config, db *gorm.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if GetMaintenance().Enabled {
+				log.Println("StartAccountingSyncWorker - Skipping accounting sync during maintenance mode")
+				continue
+			}
+
+			synced, err := RunDueAccountingSyncs(cfg, db)
+			if err != nil {
+				log.Println("StartAccountingSyncWorker - Accounting sync failed:", err)
+				continue
+			}
+			if synced > 0 {
+				log.Printf("StartAccountingSyncWorker - %d orders pushed to accounting\n", synced)
+			}
+		}
+	}()
+}
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"context"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Address normalization statuses stored on orders
+const (
+	AddressStatusNormalized = "normalized" // province, city, district and postal code resolved
+	AddressStatusPartial    = "partial"    // some of the fields resolved
+	AddressStatusUnresolved = "unresolved" // nothing could be resolved
+)
+
+// addressNormalizeTimeout bounds a single provider lookup so order creation is never held up for long
+const addressNormalizeTimeout = 5 * time.Second
+
+// indonesianPostalCode matches a 5 digit Indonesian postal code
+var indonesianPostalCode = regexp.MustCompile(`\b[1-9][0-9]{4}\b`)
+
+// ParsedAddress represents the regional parts of a shipping address
+type ParsedAddress struct {
+	Province   string
+	City       string // city or regency (kota/kabupaten)
+	District   string // kecamatan
+	PostalCode string
+}
+
+// Status returns how complete the parsed address is
+func (a *ParsedAddress) Status() string {
+	if a == nil {
+		return AddressStatusUnresolved
+	}
+	resolved := 0
+	for _, part := range []string{a.Province, a.City, a.District, a.PostalCode} {
+		if part != "" {
+			resolved++
+		}
+	}
+	switch resolved {
+	case 4:
+		return AddressStatusNormalized
+	case 0:
+		return AddressStatusUnresolved
+	default:
+		return AddressStatusPartial
+	}
+}
+
+// AddressProvider parses a free text shipping address into its regional parts
+type AddressProvider interface {
+	Name() string
+	Normalize(ctx context.Context, address string) (*ParsedAddress, error)
+}
+
+// NewAddressProvider returns the address provider configured with ADDRESS_PROVIDER (google, here or postcode),
+// or nil when address normalization is disabled or the provider cannot be set up
+func NewAddressProvider(cfg *config.Config) AddressProvider {
+	switch strings.ToLower(cfg.AddressProvider) {
+	case "":
+		return nil
+	case "google":
+		if cfg.AddressProviderAPIKey == "" {
+			log.Println("NewAddressProvider - ADDRESS_PROVIDER_API_KEY is required for the google provider, address normalization disabled")
+			return nil
+		}
+		return &googleAddressProvider{apiKey: cfg.AddressProviderAPIKey}
+	case "here":
+		if cfg.AddressProviderAPIKey == "" {
+			log.Println("NewAddressProvider - ADDRESS_PROVIDER_API_KEY is required for the here provider, address normalization disabled")
+			return nil
+		}
+		return &hereAddressProvider{apiKey: cfg.AddressProviderAPIKey}
+	case "postcode":
+		provider, err := newPostcodeAddressProvider(cfg.AddressPostcodeDataset)
+		if err != nil {
+			log.Println("NewAddressProvider - Failed to load postcode dataset, address normalization disabled:", err)
+			return nil
+		}
+		return provider
+	default:
+		log.Println("NewAddressProvider - Unknown address provider", cfg.AddressProvider, ", address normalization disabled")
+		return nil
+	}
+}
+
+// NormalizeOrderAddress fills the regional address fields of an order using the provider.
+// Normalization is best effort: failures are logged and the order is marked unresolved, never rejected.
+func NormalizeOrderAddress(ctx context.Context, provider AddressProvider, order *models.Order) {
+	if provider == nil || strings.TrimSpace(order.Address) == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, addressNormalizeTimeout)
+	defer cancel()
+
+	parsed, err := provider.Normalize(ctx, order.Address)
+	if err != nil {
+		log.Printf("NormalizeOrderAddress - %s provider failed for order %s: %v\n", provider.Name(), order.OrderGineeID, err)
+		parsed = nil
+	}
+
+	// Keep a postal code typed in the address when the provider did not return one
+	if parsed == nil {
+		parsed = &ParsedAddress{}
+	}
+	if parsed.PostalCode == "" {
+		parsed.PostalCode = ExtractPostalCode(order.Address)
+	}
+
+	order.Province = parsed.Province
+	order.City = parsed.City
+	order.District = parsed.District
+	order.PostalCode = parsed.PostalCode
+	order.AddressStatus = parsed.Status()
+}
+
+// ExtractPostalCode returns the last 5 digit postal code found in an address, or an empty string
+func ExtractPostalCode(address string) string {
+	matches := indonesianPostalCode.FindAllString(address, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[len(matches)-1]
+}
+
+// normalizeRegionName trims administrative prefixes so names from different sources compare equal
+func normalizeRegionName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, prefix := range []string{"provinsi ", "kabupaten ", "kab. ", "kab ", "kota ", "kecamatan ", "kec. ", "kec "} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var addressGeocoderClient = &http.Client{Timeout: 10 * time.Second}
+
+// getGeocoderJSON performs a GET request against a geocoding API and decodes the JSON response
+func getGeocoderJSON(ctx context.Context, endpoint string, params url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := addressGeocoderClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach geocoder: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("geocoder returned %d: %s", resp.StatusCode, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// googleAddressProvider resolves addresses with the Google Geocoding API
+type googleAddressProvider struct {
+	apiKey string
+}
+
+func (p *googleAddressProvider) Name() string {
+	return "google"
+}
+
+func (p *googleAddressProvider) Normalize(ctx context.Context, address string) (*ParsedAddress, error) {
+	var result struct {
+		Status  string `json:"status"`
+		Results []struct {
+			AddressComponents []struct {
+				LongName string   `json:"long_name"`
+				Types    []string `json:"types"`
+			} `json:"address_components"`
+		} `json:"results"`
+	}
+
+	params := url.Values{}
+	params.Set("address", address)
+	params.Set("region", "id")
+	params.Set("language", "id")
+	params.Set("key", p.apiKey)
+	if err := getGeocoderJSON(ctx, "https://maps.googleapis.com/maps/api/geocode/json", params, &result); err != nil {
+		return nil, err
+	}
+	if result.Status == "ZERO_RESULTS" || len(result.Results) == 0 {
+		return &ParsedAddress{}, nil
+	}
+	if result.Status != "OK" {
+		return nil, fmt.Errorf("google geocoder status %s", result.Status)
+	}
+
+	// Indonesian administrative levels: 1 province, 2 city/regency, 3 district (kecamatan)
+	parsed := &ParsedAddress{}
+	for _, component := range result.Results[0].AddressComponents {
+		for _, componentType := range component.Types {
+			switch componentType {
+			case "administrative_area_level_1":
+				parsed.Province = component.LongName
+			case "administrative_area_level_2":
+				parsed.City = component.LongName
+			case "administrative_area_level_3":
+				parsed.District = strings.TrimPrefix(component.LongName, "Kecamatan ")
+			case "postal_code":
+				parsed.PostalCode = component.LongName
+			}
+		}
+	}
+	return parsed, nil
+}
+
+// hereAddressProvider resolves addresses with the HERE Geocoding and Search API
+type hereAddressProvider struct {
+	apiKey string
+}
+
+func (p *hereAddressProvider) Name() string {
+	return "here"
+}
+
+func (p *hereAddressProvider) Normalize(ctx context.Context, address string) (*ParsedAddress, error) {
+	var result struct {
+		Items []struct {
+			Address struct {
+				State      string `json:"state"`
+				County     string `json:"county"`
+				City       string `json:"city"`
+				District   string `json:"district"`
+				PostalCode string `json:"postalCode"`
+			} `json:"address"`
+		} `json:"items"`
+	}
+
+	params := url.Values{}
+	params.Set("q", address)
+	params.Set("in", "countryCode:IDN")
+	params.Set("lang", "id")
+	params.Set("limit", "1")
+	params.Set("apiKey", p.apiKey)
+	if err := getGeocoderJSON(ctx, "https://geocode.search.hereapi.com/v1/geocode", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Items) == 0 {
+		return &ParsedAddress{}, nil
+	}
+
+	// HERE reports regencies as county and cities as city
+	found := result.Items[0].Address
+	city := found.City
+	if city == "" {
+		city = found.County
+	}
+	return &ParsedAddress{
+		Province:   found.State,
+		City:       city,
+		District:   found.District,
+		PostalCode: found.PostalCode,
+	}, nil
+}
@@ -0,0 +1,134 @@
+package utils
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// postcodeEntry is a single row of the Indonesian postcode dataset
+type postcodeEntry struct {
+	PostalCode string
+	Province   string
+	City       string
+	District   string
+}
+
+// postcodeAddressProvider resolves addresses offline from an Indonesian postcode dataset
+type postcodeAddressProvider struct {
+	byPostalCode map[string]postcodeEntry
+	districts    []postcodeEntry // one entry per district, longest names first
+}
+
+// newPostcodeAddressProvider loads a CSV dataset with the columns postal_code,province,city,district
+func newPostcodeAddressProvider(path string) (*postcodeAddressProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset header: %w", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"postal_code", "province", "city", "district"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("dataset is missing the %s column", required)
+		}
+	}
+
+	provider := &postcodeAddressProvider{byPostalCode: make(map[string]postcodeEntry)}
+	seenDistricts := make(map[string]bool)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dataset: %w", err)
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		entry := postcodeEntry{
+			PostalCode: field("postal_code"),
+			Province:   field("province"),
+			City:       field("city"),
+			District:   field("district"),
+		}
+		if entry.PostalCode == "" {
+			continue
+		}
+
+		// Several villages share a postal code, the first row wins
+		if _, exists := provider.byPostalCode[entry.PostalCode]; !exists {
+			provider.byPostalCode[entry.PostalCode] = entry
+		}
+		districtKey := normalizeRegionName(entry.City) + "|" + normalizeRegionName(entry.District)
+		if entry.District != "" && !seenDistricts[districtKey] {
+			seenDistricts[districtKey] = true
+			provider.districts = append(provider.districts, entry)
+		}
+	}
+
+	// Match longer district names first so "Kebayoran Baru" wins over "Baru"
+	sort.SliceStable(provider.districts, func(i, j int) bool {
+		return len(provider.districts[i].District) > len(provider.districts[j].District)
+	})
+
+	return provider, nil
+}
+
+func (p *postcodeAddressProvider) Name() string {
+	return "postcode"
+}
+
+func (p *postcodeAddressProvider) Normalize(ctx context.Context, address string) (*ParsedAddress, error) {
+	// A postal code in the address resolves every field directly
+	if entry, ok := p.byPostalCode[ExtractPostalCode(address)]; ok {
+		return &ParsedAddress{
+			Province:   entry.Province,
+			City:       entry.City,
+			District:   entry.District,
+			PostalCode: entry.PostalCode,
+		}, nil
+	}
+
+	// Otherwise look for a district and its city both named in the address
+	normalized := " " + normalizeRegionName(strings.NewReplacer(",", " ", ".", " ").Replace(address)) + " "
+	for _, entry := range p.districts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		district := normalizeRegionName(entry.District)
+		city := normalizeRegionName(entry.City)
+		if district == "" || city == "" {
+			continue
+		}
+		if strings.Contains(normalized, " "+district+" ") && strings.Contains(normalized, " "+city+" ") {
+			return &ParsedAddress{
+				Province: entry.Province,
+				City:     entry.City,
+				District: entry.District,
+			}, nil
+		}
+	}
+
+	return &ParsedAddress{}, nil
+}
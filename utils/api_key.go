@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// apiKeyWindow counts the requests of an API key in the current minute
+type apiKeyWindow struct {
+	start time.Time
+	count int
+}
+
+var (
+	apiKeyLimiterMu sync.Mutex
+	apiKeyWindows   = make(map[uint]*apiKeyWindow)
+)
+
+// GenerateAPIKey returns a new API key, the prefix shown to identify it and the hash to store
+func GenerateAPIKey() (string, string, string, error) {
+	token, _, err := GenerateSecureToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	key := models.APIKeyPrefix + token
+	return key, key[:len(models.APIKeyPrefix)+8], HashSecureToken(key), nil
+}
+
+// AllowAPIKeyRequest counts a request of an API key against its per-minute limit.
+// It returns false and the time until the window resets when the limit is reached.
+func AllowAPIKeyRequest(keyID uint, limit int, now time.Time) (bool, time.Duration) {
+	if limit <= 0 {
+		limit = models.DefaultAPIKeyRateLimit
+	}
+
+	apiKeyLimiterMu.Lock()
+	defer apiKeyLimiterMu.Unlock()
+
+	window, ok := apiKeyWindows[keyID]
+	if !ok || now.Sub(window.start) >= time.Minute {
+		window = &apiKeyWindow{start: now}
+		apiKeyWindows[keyID] = window
+	}
+	if window.count >= limit {
+		return false, window.start.Add(time.Minute).Sub(now)
+	}
+	window.count++
+	return true, 0
+}
+
+// GetAPIKeyCurrentUsage returns the number of requests an API key made in the current minute
+func GetAPIKeyCurrentUsage(keyID uint, now time.Time) int {
+	apiKeyLimiterMu.Lock()
+	defer apiKeyLimiterMu.Unlock()
+
+	window, ok := apiKeyWindows[keyID]
+	if !ok || now.Sub(window.start) >= time.Minute {
+		return 0
+	}
+	return window.count
+}
+
+// ResetAPIKeyLimit drops the rate limit window of an API key, used when it is rotated or revoked
+func ResetAPIKeyLimit(keyID uint) {
+	apiKeyLimiterMu.Lock()
+	defer apiKeyLimiterMu.Unlock()
+	delete(apiKeyWindows, keyID)
+}
+
+// RecordAPIKeyUsage adds a request to the daily usage of an API key.
+// outcome is "allowed", "rate_limited" or "forbidden"; only allowed requests update the key last use.
+// Failures are logged and never block the request.
+func RecordAPIKeyUsage(db *gorm.DB, keyID uint, outcome, ip string, now time.Time) {
+	usage := models.APIKeyUsage{
+		APIKeyID: keyID,
+		Date:     time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()),
+	}
+	column := "requests"
+	switch outcome {
+	case "rate_limited":
+		column = "rate_limited"
+		usage.RateLimited = 1
+	case "forbidden":
+		column = "forbidden"
+		usage.Forbidden = 1
+	default:
+		usage.Requests = 1
+	}
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "api_key_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{column: gorm.Expr("api_key_usages." + column + " + 1")}),
+	}).Create(&usage).Error; err != nil {
+		log.Println("RecordAPIKeyUsage - Failed to record API key usage:", err)
+	}
+
+	if outcome != "allowed" {
+		return
+	}
+	if err := db.Model(&models.APIKey{}).Where("id = ?", keyID).UpdateColumns(map[string]interface{}{
+		"request_count": gorm.Expr("request_count + 1"),
+		"last_used_at":  now,
+		"last_used_ip":  ip,
+	}).Error; err != nil {
+		log.Println("RecordAPIKeyUsage - Failed to update API key last use:", err)
+	}
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"errors"
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CoordinatorApprovalRoles are the roles allowed to approve sensitive operations on behalf of workers
+var CoordinatorApprovalRoles = []string{"developer", "superadmin", "coordinator"}
+
+var (
+	ErrInvalidApproverCredentials = errors.New("invalid coordinator credentials")
+	ErrApproverNotPermitted       = errors.New("user does not have required permissions")
+)
+
+// VerifyApprover authenticates the given credentials and checks that the user has one of the allowed roles
+func VerifyApprover(db *gorm.DB, username, password string, allowedRoles []string) (*models.User, error) {
+	var approver models.User
+	if err := db.Preload("Roles").Where("username = ?", NormalizeUsername(username)).First(&approver).Error; err != nil {
+		return nil, ErrInvalidApproverCredentials
+	}
+
+	if !CheckPasswordHash(password, approver.Password) {
+		return nil, ErrInvalidApproverCredentials
+	}
+
+	for _, role := range approver.Roles {
+		for _, allowedRole := range allowedRoles {
+			if role.RoleName == allowedRole {
+				return &approver, nil
+			}
+		}
+	}
+
+	return nil, ErrApproverNotPermitted
+}
+
+// ApprovalGrant is an authenticated approval, with the delegation or one-time code it was granted through
+type ApprovalGrant struct {
+	Approver   *models.User
+	Delegation *models.ApprovalDelegation // set when a delegate approved on behalf of a coordinator
+	Code       *models.ApprovalCode       // set when approved with a one-time code
+}
+
+// ApprovalCodeDigits is the length of one-time approval codes
+const ApprovalCodeDigits = 6
+
+var ErrInvalidApprovalCode = errors.New("invalid or expired approval code")
+
+// ResolveApprover authenticates the given credentials like VerifyApprover. Users without one of the allowed roles
+// may still approve while they hold an active delegation, which is returned so the approval records the delegator.
+func ResolveApprover(db *gorm.DB, username, password string, allowedRoles []string) (*ApprovalGrant, error) {
+	approver, err := VerifyApprover(db, username, password, allowedRoles)
+	if err != ErrApproverNotPermitted {
+		if err != nil {
+			return nil, err
+		}
+		return &ApprovalGrant{Approver: approver}, nil
+	}
+
+	var approverUser models.User
+	if err := db.Where("username = ?", NormalizeUsername(username)).First(&approverUser).Error; err != nil {
+		return nil, ErrInvalidApproverCredentials
+	}
+
+	var delegation models.ApprovalDelegation
+	if err := db.Preload("Delegator").
+		Where("delegate_id = ? AND revoked_at IS NULL AND expires_at > ?", approverUser.ID, time.Now()).
+		Order("expires_at DESC").First(&delegation).Error; err != nil {
+		return nil, ErrApproverNotPermitted
+	}
+	return &ApprovalGrant{Approver: &approverUser, Delegation: &delegation}, nil
+}
+
+// RedeemApprovalCode marks a one-time approval code as used by the given user for the action.
+// The update is conditional so a code can only ever be redeemed once, even by concurrent requests.
+func RedeemApprovalCode(db *gorm.DB, code, action string, usedBy uint) (*ApprovalGrant, error) {
+	now := time.Now()
+	var approvalCode models.ApprovalCode
+	if err := db.Preload("IssueUser").
+		Where("code_hash = ? AND used_at IS NULL AND expires_at > ? AND (action = '' OR action = ?)", HashSecureToken(code), now, action).
+		First(&approvalCode).Error; err != nil || approvalCode.IssueUser == nil {
+		return nil, ErrInvalidApprovalCode
+	}
+
+	result := db.Model(&models.ApprovalCode{}).Where("id = ? AND used_at IS NULL", approvalCode.ID).
+		Updates(map[string]interface{}{"used_by": usedBy, "used_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidApprovalCode
+	}
+	approvalCode.UsedBy = &usedBy
+	approvalCode.UsedAt = &now
+
+	return &ApprovalGrant{Approver: approvalCode.IssueUser, Code: &approvalCode}, nil
+}
+
+// AuthorizeApproval approves an action with a one-time code when given, otherwise with coordinator or delegate credentials
+func AuthorizeApproval(db *gorm.DB, username, password, code, action string, requestedBy uint, allowedRoles []string) (*ApprovalGrant, error) {
+	if code != "" {
+		return RedeemApprovalCode(db, code, action, requestedBy)
+	}
+	return ResolveApprover(db, username, password, allowedRoles)
+}
+
+// RecordApproval writes the audit record of an approval, with the delegator or code it was granted through
+func RecordApproval(db *gorm.DB, action, referenceType string, referenceID, requestedBy uint, grant *ApprovalGrant) error {
+	approval := models.Approval{
+		Action:        action,
+		ReferenceType: referenceType,
+		ReferenceID:   referenceID,
+		Method:        models.ApprovalMethodCredentials,
+		RequestedBy:   requestedBy,
+		ApprovedBy:    grant.Approver.ID,
+	}
+	if grant.Delegation != nil {
+		approval.Method = models.ApprovalMethodDelegation
+		approval.DelegatorID = &grant.Delegation.DelegatorID
+		approval.DelegationID = &grant.Delegation.ID
+	}
+	if grant.Code != nil {
+		approval.Method = models.ApprovalMethodCode
+		approval.CodeID = &grant.Code.ID
+	}
+	return db.Create(&approval).Error
+}
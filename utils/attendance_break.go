@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BreakViolationNotifyRoles are notified of attendances whose breaks exceed the allowed maximum
+var BreakViolationNotifyRoles = []string{"hrd"}
+
+var (
+	ErrBreakInProgress   = errors.New("a break is already in progress")
+	ErrNoBreakInProgress = errors.New("no break in progress")
+)
+
+// BreakPolicy holds the break duration allowed per attendance
+type BreakPolicy struct {
+	MaxMinutes int // total break minutes allowed per attendance, 0 disables the check
+}
+
+// BreakPolicyFromConfig builds the break policy from the application config
+func BreakPolicyFromConfig(cfg *config.Config) BreakPolicy {
+	return BreakPolicy{MaxMinutes: cfg.MaxBreakMinutes}
+}
+
+// StartAttendanceBreak starts a break on an attendance that is checked in, only one break can be in progress
+func StartAttendanceBreak(db *gorm.DB, attendance *models.Attendance, method string) (*models.AttendanceBreak, error) {
+	attendanceBreak := models.AttendanceBreak{
+		AttendanceID: attendance.ID,
+		UserID:       attendance.UserID,
+		StartedAt:    time.Now(),
+		StartMethod:  method,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", attendance.ID).First(&models.Attendance{}).Error; err != nil {
+			return err
+		}
+		var open int64
+		if err := tx.Model(&models.AttendanceBreak{}).Where("attendance_id = ? AND ended_at IS NULL", attendance.ID).Count(&open).Error; err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrBreakInProgress
+		}
+		return tx.Create(&attendanceBreak).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &attendanceBreak, nil
+}
+
+// EndAttendanceBreak ends the break in progress of an attendance and adds its minutes to the attendance
+func (p BreakPolicy) EndAttendanceBreak(db *gorm.DB, attendance *models.Attendance, method string) (*models.AttendanceBreak, error) {
+	var attendanceBreak *models.AttendanceBreak
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		attendanceBreak, err = p.endOpenBreak(tx, attendance, time.Now(), method)
+		if err != nil {
+			return err
+		}
+		if attendanceBreak == nil {
+			return ErrNoBreakInProgress
+		}
+		return tx.Model(&models.Attendance{}).Where("id = ?", attendance.ID).Updates(map[string]interface{}{
+			"break_minutes":   attendance.BreakMinutes,
+			"break_violation": attendance.BreakViolation,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attendanceBreak, nil
+}
+
+// CloseOpenBreak ends the break still in progress when the attendance checks out, if any.
+// The attendance break fields are updated in memory only, to be saved with the checkout.
+func (p BreakPolicy) CloseOpenBreak(tx *gorm.DB, attendance *models.Attendance, at time.Time) error {
+	_, err := p.endOpenBreak(tx, attendance, at, "")
+	return err
+}
+
+// endOpenBreak ends the break in progress at the given time, adds its minutes to the attendance and notifies HR
+// the first time the total exceeds the maximum. Returns nil when no break is in progress.
+func (p BreakPolicy) endOpenBreak(tx *gorm.DB, attendance *models.Attendance, at time.Time, method string) (*models.AttendanceBreak, error) {
+	// Lock the attendance first, like starting a break, and read its current violation flag
+	var locked models.Attendance
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "break_violation").Where("id = ?", attendance.ID).First(&locked).Error; err != nil {
+		return nil, err
+	}
+	attendance.BreakViolation = locked.BreakViolation
+
+	var attendanceBreak models.AttendanceBreak
+	err := tx.Where("attendance_id = ? AND ended_at IS NULL", attendance.ID).First(&attendanceBreak).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if at.Before(attendanceBreak.StartedAt) {
+		at = attendanceBreak.StartedAt
+	}
+	attendanceBreak.EndedAt = &at
+	attendanceBreak.Minutes = int(at.Sub(attendanceBreak.StartedAt).Minutes())
+	attendanceBreak.EndMethod = method
+	attendanceBreak.AutoEnded = method == ""
+	if err := tx.Save(&attendanceBreak).Error; err != nil {
+		return nil, err
+	}
+
+	// Total of the ended breaks of the attendance
+	var total int
+	if err := tx.Model(&models.AttendanceBreak{}).Select("COALESCE(SUM(minutes), 0)").Where("attendance_id = ? AND ended_at IS NOT NULL", attendance.ID).Scan(&total).Error; err != nil {
+		return nil, err
+	}
+	attendance.BreakMinutes = total
+
+	if p.MaxMinutes > 0 && total > p.MaxMinutes && !attendance.BreakViolation {
+		attendance.BreakViolation = true
+		var user models.User
+		tx.Select("id", "full_name").Where("id = ?", attendance.UserID).First(&user)
+		if err := NotifyRoles(tx, BreakViolationNotifyRoles, "break_violation", "Attendance break too long",
+			fmt.Sprintf("%s took %d minutes of break on %s, more than the allowed %d minutes", user.FullName, total, attendance.CheckedIn.Format("02-01-2006"), p.MaxMinutes),
+			"attendance", attendance.ID); err != nil {
+			return nil, err
+		}
+	}
+	return &attendanceBreak, nil
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MissingCheckoutNotifyRoles are notified of attendances left open after the shift ended
+var MissingCheckoutNotifyRoles = []string{"hrd"}
+
+// AttendanceShiftEnd returns the regular checkout time of the day the attendance checked in
+func AttendanceShiftEnd(checkedIn time.Time) time.Time {
+	return time.Date(checkedIn.Year(), checkedIn.Month(), checkedIn.Day(), 17, 0, 0, 0, checkedIn.Location())
+}
+
+// FlagMissingCheckouts flags every attendance still open after its shift ended and notifies HR.
+// With autoClose the attendance is also checked out at the shift end without overtime, so it no longer stays open,
+// ending the break still in progress.
+// Returns the number of newly flagged attendances.
+func FlagMissingCheckouts(db *gorm.DB, autoClose bool, breaks BreakPolicy) (int, error) {
+	now := time.Now()
+
+	// Attendances of today only count once today's shift ended
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if now.After(AttendanceShiftEnd(now)) {
+		cutoff = now
+	}
+
+	var attendances []models.Attendance
+	if err := db.Preload("User").Where("checked_out IS NULL AND missing_checkout = ? AND checked_in < ?", false, cutoff).
+		Order("checked_in ASC").Find(&attendances).Error; err != nil {
+		return 0, err
+	}
+
+	flagged := 0
+	for _, attendance := range attendances {
+		updates := map[string]interface{}{"missing_checkout": true}
+		message := fmt.Sprintf("%s checked in at %s but never checked out", attendance.User.FullName, attendance.CheckedIn.Format("02-01-2006 15:04"))
+		if autoClose {
+			shiftEnd := AttendanceShiftEnd(attendance.CheckedIn)
+			if shiftEnd.Before(attendance.CheckedIn) {
+				shiftEnd = attendance.CheckedIn
+			}
+			updates["checked_out"] = shiftEnd
+			updates["checked"] = false
+			updates["overtime"] = 0
+			updates["approved_overtime"] = 0
+			updates["overtime_status"] = models.OvertimeStatusNone
+			updates["auto_closed_at"] = now
+			message += fmt.Sprintf(", the attendance was closed at %s", shiftEnd.Format("15:04"))
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if autoClose {
+				if err := breaks.CloseOpenBreak(tx, &attendance, updates["checked_out"].(time.Time)); err != nil {
+					return err
+				}
+				updates["break_minutes"] = attendance.BreakMinutes
+				updates["break_violation"] = attendance.BreakViolation
+			}
+			if err := tx.Model(&models.Attendance{}).Where("id = ?", attendance.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+			return NotifyRoles(tx, MissingCheckoutNotifyRoles, "missing_checkout", "Attendance missing checkout", message, "attendance", attendance.ID)
+		})
+		if err != nil {
+			log.Println("FlagMissingCheckouts - Failed to flag attendance", attendance.ID, ":", err)
+			continue
+		}
+		flagged++
+	}
+
+	return flagged, nil
+}
+
+// StartMissingCheckoutScheduler flags missing checkouts every night at the given hour in the background
+func StartMissingCheckoutScheduler(db *gorm.DB, hour int, autoClose bool, breaks BreakPolicy) {
+	go func() {
+		for {
+			now := time.Now()
+			next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			time.Sleep(time.Until(next))
+
+			if GetMaintenance().Enabled {
+				log.Println("StartMissingCheckoutScheduler - Skipping missing checkout check during maintenance mode")
+				continue
+			}
+
+			flagged, err := FlagMissingCheckouts(db, autoClose, breaks)
+			if err != nil {
+				log.Println("StartMissingCheckoutScheduler - Missing checkout check failed:", err)
+				continue
+			}
+			if flagged > 0 {
+				log.Printf("StartMissingCheckoutScheduler - %d attendances flagged with a missing checkout\n", flagged)
+			}
+		}
+	}()
+}
@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PickShortageResolutionRestocked resolves a pick shortage with stock received after it was declared
+const PickShortageResolutionRestocked = "restocked"
+
+// BackorderRestock describes stock received for a SKU that can fulfill its backorders
+type BackorderRestock struct {
+	SKU           string
+	Quantity      int // accepted quantity of the receipt
+	ReferenceType string
+	ReferenceID   uint
+	ReceivedBy    *uint
+}
+
+// BackorderFulfillment is a backorder fulfilled by a stock receipt
+type BackorderFulfillment struct {
+	ShortageID     uint
+	OrderID        uint
+	TrackingNumber string
+	SKU            string
+	Quantity       int
+	PickerID       *uint
+	Resumed        bool // the order has no other open shortage and went back to picking
+}
+
+// FulfillBackorders allocates stock received for a SKU to the open pick shortages of the SKU, oldest first,
+// so orders waiting for the stock are served before new orders. A shortage is only fulfilled when the rest
+// of the receipt covers its whole short quantity, smaller younger shortages may still be served.
+// The item goes back on the picking checklist, the order resumes picking once it has no other open shortage
+// and the assigned picker, or the coordinators when no picker is assigned, are notified.
+// It must run inside a transaction.
+func FulfillBackorders(tx *gorm.DB, restock BackorderRestock) ([]BackorderFulfillment, error) {
+	if restock.Quantity <= 0 {
+		return nil, nil
+	}
+
+	var shortages []models.PickShortage
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("sku = ? AND status = ?", restock.SKU, "open").
+		Order("created_at ASC, id ASC").Find(&shortages).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	remaining := restock.Quantity
+	var fulfillments []BackorderFulfillment
+	for _, shortage := range shortages {
+		short := shortage.RequiredQuantity - shortage.PickedQuantity
+		if short > remaining {
+			continue
+		}
+
+		var order models.Order
+		if err := tx.Where("id = ?", shortage.OrderID).First(&order).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Model(&models.PickShortage{}).Where("id = ?", shortage.ID).Updates(map[string]interface{}{
+			"status":           "resolved",
+			"resolution":       PickShortageResolutionRestocked,
+			"restock_ref_type": restock.ReferenceType,
+			"restock_ref_id":   restock.ReferenceID,
+			"resolved_by":      restock.ReceivedBy,
+			"resolved_at":      now,
+		}).Error; err != nil {
+			return nil, err
+		}
+
+		// The item goes back on the picking checklist
+		if err := tx.Model(&models.OrderDetail{}).Where("id = ?", shortage.OrderDetailID).Updates(map[string]interface{}{
+			"is_shortage":     false,
+			"shortage_reason": "",
+		}).Error; err != nil {
+			return nil, err
+		}
+
+		// Resume picking once the order has no other open shortages
+		var openShortages int64
+		if err := tx.Model(&models.PickShortage{}).Where("order_id = ? AND status = ?", order.ID, "open").Count(&openShortages).Error; err != nil {
+			return nil, err
+		}
+		resumed := false
+		if openShortages == 0 && order.ProcessingStatus == models.ProcessingStatusAwaitingStock {
+			nextStatus := models.ProcessingStatusReadyToPick
+			if order.PickedBy != nil {
+				nextStatus = models.ProcessingStatusPickingProgress
+			}
+			if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Update("processing_status", nextStatus).Error; err != nil {
+				return nil, err
+			}
+			resumed = true
+		}
+
+		message := fmt.Sprintf("%d of SKU %s arrived for order %s", short, shortage.SKU, order.TrackingNumber)
+		if resumed {
+			message += ", the order is ready to continue picking"
+		} else {
+			message += ", the order is still waiting for other items"
+		}
+		if order.PickedBy != nil {
+			err := NotifyUsers(tx, []uint{*order.PickedBy}, "backorder_restocked", "Stock arrived for "+order.TrackingNumber, message, "pick_shortage", shortage.ID)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			err := NotifyRoles(tx, CoordinatorApprovalRoles, "backorder_restocked", "Stock arrived for "+order.TrackingNumber, message+", assign a picker", "pick_shortage", shortage.ID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		remaining -= short
+		fulfillments = append(fulfillments, BackorderFulfillment{
+			ShortageID:     shortage.ID,
+			OrderID:        order.ID,
+			TrackingNumber: order.TrackingNumber,
+			SKU:            shortage.SKU,
+			Quantity:       short,
+			PickerID:       order.PickedBy,
+			Resumed:        resumed,
+		})
+		if remaining == 0 {
+			break
+		}
+	}
+
+	return fulfillments, nil
+}
+
+// BackorderSupply is the stock on hand and the stock expected on open inbound shipments of a SKU
+type BackorderSupply struct {
+	SKU      string
+	OnHand   int
+	Incoming int
+}
+
+// GetBackorderSupply returns the supply of each given SKU, keyed by SKU
+func GetBackorderSupply(db *gorm.DB, skus []string) (map[string]BackorderSupply, error) {
+	supply := make(map[string]BackorderSupply, len(skus))
+	if len(skus) == 0 {
+		return supply, nil
+	}
+	for _, sku := range skus {
+		supply[sku] = BackorderSupply{SKU: sku}
+	}
+
+	var onHand []struct {
+		SKU      string
+		Quantity int
+	}
+	if err := db.Model(&models.Inventory{}).Select("sku, quantity").Where("sku IN ?", skus).Scan(&onHand).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range onHand {
+		entry := supply[row.SKU]
+		entry.OnHand = row.Quantity
+		supply[row.SKU] = entry
+	}
+
+	var incoming []struct {
+		SKU      string
+		Quantity int
+	}
+	if err := db.Table("inbound_details").
+		Select("inbound_details.sku, SUM(inbound_details.expected_quantity) AS quantity").
+		Joins("JOIN inbound_shipments ON inbound_shipments.id = inbound_details.inbound_shipment_id").
+		Where("inbound_shipments.status IN ? AND inbound_details.sku IN ?", []string{"registered", "receiving"}, skus).
+		Group("inbound_details.sku").Scan(&incoming).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range incoming {
+		entry := supply[row.SKU]
+		entry.Incoming = row.Quantity
+		supply[row.SKU] = entry
+	}
+
+	return supply, nil
+}
@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// businessCalendarMaxDays bounds the search for business hours on calendars open only a few hours a year
+const businessCalendarMaxDays = 3660
+
+var businessWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// BusinessHours are the operating hours of a day, as offsets from midnight
+type BusinessHours struct {
+	Open  time.Duration
+	Close time.Duration
+}
+
+// BusinessCalendar holds the warehouse operating hours SLA and aging durations are counted in.
+// Without hours every day is open around the clock, holidays are closed all day.
+type BusinessCalendar struct {
+	Hours    map[time.Weekday]BusinessHours // days missing from the map are closed, nil means always open
+	Holidays map[string]bool                // local dates as YYYY-MM-DD
+}
+
+var (
+	businessCalendarMu sync.RWMutex
+	businessCalendar   BusinessCalendar
+)
+
+// InitBusinessCalendar loads the operating hours and holidays from the configuration
+func InitBusinessCalendar(cfg *config.Config) error {
+	calendar, err := ParseBusinessCalendar(cfg.BusinessHours, cfg.BusinessHolidays)
+	if err != nil {
+		return err
+	}
+
+	businessCalendarMu.Lock()
+	defer businessCalendarMu.Unlock()
+	businessCalendar = calendar
+	return nil
+}
+
+// GetBusinessCalendar returns the configured business calendar, always open until InitBusinessCalendar is called
+func GetBusinessCalendar() BusinessCalendar {
+	businessCalendarMu.RLock()
+	defer businessCalendarMu.RUnlock()
+	return businessCalendar
+}
+
+// ParseBusinessCalendar parses operating hours as day=HH:MM-HH:MM entries, where the day is a weekday
+// (mon..sun) or a range of weekdays (mon-fri), and holidays as YYYY-MM-DD dates
+func ParseBusinessCalendar(hours, holidays []string) (BusinessCalendar, error) {
+	calendar := BusinessCalendar{Holidays: make(map[string]bool, len(holidays))}
+
+	for _, entry := range hours {
+		days, window, ok := strings.Cut(strings.ToLower(strings.TrimSpace(entry)), "=")
+		if !ok {
+			return BusinessCalendar{}, fmt.Errorf("invalid business hours %q, use day=HH:MM-HH:MM", entry)
+		}
+		weekdays, err := parseBusinessWeekdays(days)
+		if err != nil {
+			return BusinessCalendar{}, err
+		}
+		openClock, closeClock, ok := strings.Cut(window, "-")
+		if !ok {
+			return BusinessCalendar{}, fmt.Errorf("invalid business hours %q, use day=HH:MM-HH:MM", entry)
+		}
+		openAt, err := parseBusinessClock(openClock)
+		if err != nil {
+			return BusinessCalendar{}, err
+		}
+		closeAt, err := parseBusinessClock(closeClock)
+		if err != nil {
+			return BusinessCalendar{}, err
+		}
+		if closeAt <= openAt {
+			return BusinessCalendar{}, fmt.Errorf("business hours %q close before they open", entry)
+		}
+
+		if calendar.Hours == nil {
+			calendar.Hours = make(map[time.Weekday]BusinessHours)
+		}
+		for _, weekday := range weekdays {
+			calendar.Hours[weekday] = BusinessHours{Open: openAt, Close: closeAt}
+		}
+	}
+
+	for _, holiday := range holidays {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(holiday))
+		if err != nil {
+			return BusinessCalendar{}, fmt.Errorf("invalid holiday %q, use YYYY-MM-DD", holiday)
+		}
+		calendar.Holidays[date.Format("2006-01-02")] = true
+	}
+
+	return calendar, nil
+}
+
+// parseBusinessWeekdays parses a weekday or a weekday range such as fri-mon
+func parseBusinessWeekdays(days string) ([]time.Weekday, error) {
+	first, last, isRange := strings.Cut(days, "-")
+	from, ok := businessWeekdays[strings.TrimSpace(first)]
+	if !ok {
+		return nil, fmt.Errorf("invalid business day %q, use mon, tue, wed, thu, fri, sat or sun", first)
+	}
+	if !isRange {
+		return []time.Weekday{from}, nil
+	}
+	to, ok := businessWeekdays[strings.TrimSpace(last)]
+	if !ok {
+		return nil, fmt.Errorf("invalid business day %q, use mon, tue, wed, thu, fri, sat or sun", last)
+	}
+
+	weekdays := []time.Weekday{from}
+	for day := from; day != to; {
+		day = (day + 1) % 7
+		weekdays = append(weekdays, day)
+	}
+	return weekdays, nil
+}
+
+// parseBusinessClock parses HH:MM as an offset from midnight, 24:00 closes at midnight
+func parseBusinessClock(clock string) (time.Duration, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(strings.TrimSpace(clock), "%d:%d", &hour, &minute); err != nil ||
+		hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute > 0) {
+		return 0, fmt.Errorf("invalid business hour %q, use HH:MM", clock)
+	}
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, nil
+}
+
+// AlwaysOpen reports whether the calendar counts every hour, so durations equal wall clock time
+func (bc BusinessCalendar) AlwaysOpen() bool {
+	return bc.Hours == nil && len(bc.Holidays) == 0
+}
+
+// window returns the business hours of the local day starting at midnight, ok is false when the day is closed
+func (bc BusinessCalendar) window(midnight time.Time) (time.Time, time.Time, bool) {
+	if bc.Holidays[midnight.Format("2006-01-02")] {
+		return time.Time{}, time.Time{}, false
+	}
+	if bc.Hours == nil {
+		return midnight, midnight.AddDate(0, 0, 1), true
+	}
+	hours, ok := bc.Hours[midnight.Weekday()]
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	return midnight.Add(hours.Open), midnight.Add(hours.Close), true
+}
+
+// localMidnight returns the start of the local day of t
+func localMidnight(t time.Time) time.Time {
+	local := t.In(time.Local)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// Duration returns the business time between from and to, counting only operating hours outside holidays
+func (bc BusinessCalendar) Duration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+	if bc.AlwaysOpen() {
+		return to.Sub(from)
+	}
+
+	var total time.Duration
+	for day := localMidnight(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		start, end, ok := bc.window(day)
+		if !ok {
+			continue
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// Before returns the latest time whose business duration until now is at least d, the cutoff of
+// "older than d business time" checks. Returns the zero time when the calendar has no hours that far back.
+func (bc BusinessCalendar) Before(now time.Time, d time.Duration) time.Time {
+	if bc.AlwaysOpen() {
+		return now.Add(-d)
+	}
+
+	remaining := d
+	day := localMidnight(now)
+	for i := 0; i < businessCalendarMaxDays; i++ {
+		start, end, ok := bc.window(day)
+		if ok && start.Before(now) {
+			if end.After(now) {
+				end = now
+			}
+			available := end.Sub(start)
+			if remaining <= available {
+				return end.Add(-remaining)
+			}
+			remaining -= available
+		}
+		day = day.AddDate(0, 0, -1)
+	}
+	return time.Time{}
+}
+
+// PickShortageAging returns the business time a shortage has been open, or took to resolve
+func PickShortageAging(shortage *models.PickShortage, now time.Time) time.Duration {
+	end := now
+	if shortage.ResolvedAt != nil {
+		end = *shortage.ResolvedAt
+	}
+	return GetBusinessCalendar().Duration(shortage.CreatedAt, end)
+}
@@ -0,0 +1,253 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NDJSONContentType is the content type of the newline delimited JSON change feed
+const NDJSONContentType = "application/x-ndjson"
+
+// ChangeFeedField is a column exported in the change feed payload of an entity
+type ChangeFeedField struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"` // integer, number, string, boolean or timestamp
+	Nullable bool   `json:"nullable"`
+}
+
+// ChangeFeedEntity is a table whose changes are exported to the BI warehouse.
+// Version is bumped whenever Fields change so the loader can migrate its tables.
+// Table and columns are never taken from user input.
+type ChangeFeedEntity struct {
+	Name         string            `json:"entity"`
+	Version      int               `json:"version"`
+	Table        string            `json:"-"`
+	ReplayColumn string            `json:"-"` // time column a replay selects the rows by
+	CompareSkip  string            `json:"-"` // column ignored when deciding whether an update changed the payload
+	Fields       []ChangeFeedField `json:"fields"`
+}
+
+// ChangeFeedEntities lists the entities of the change feed. Buyer names, addresses and GPS positions are left out.
+var ChangeFeedEntities = []ChangeFeedEntity{
+	{
+		Name: "orders", Version: 2, Table: "orders", ReplayColumn: "updated_at", CompareSkip: "updated_at",
+		Fields: []ChangeFeedField{
+			{Name: "id", Type: "integer"},
+			{Name: "order_ginee_id", Type: "string"},
+			{Name: "processing_status", Type: "string"},
+			{Name: "event_status", Type: "string"},
+			{Name: "channel", Type: "string"},
+			{Name: "store", Type: "string"},
+			{Name: "province", Type: "string"},
+			{Name: "city", Type: "string"},
+			{Name: "postal_code", Type: "string"},
+			{Name: "courier", Type: "string"},
+			{Name: "tracking_number", Type: "string"},
+			{Name: "sent_before", Type: "timestamp"},
+			{Name: "assigned_by", Type: "integer", Nullable: true},
+			{Name: "assigned_at", Type: "timestamp", Nullable: true},
+			{Name: "picked_by", Type: "integer", Nullable: true},
+			{Name: "picked_at", Type: "timestamp", Nullable: true},
+			{Name: "pending_at", Type: "timestamp", Nullable: true},
+			{Name: "canceled_at", Type: "timestamp", Nullable: true},
+			{Name: "held_at", Type: "timestamp", Nullable: true},
+			{Name: "parent_order_id", Type: "integer", Nullable: true},
+			{Name: "merged_into_id", Type: "integer", Nullable: true},
+			{Name: "total_items", Type: "integer"},
+			{Name: "total_quantity", Type: "integer"},
+			{Name: "total_value", Type: "integer"},
+			{Name: "currency", Type: "string"},
+			{Name: "order_source", Type: "string"},
+			{Name: "priority", Type: "string"},
+			{Name: "complained", Type: "boolean"},
+			{Name: "created_at", Type: "timestamp"},
+			{Name: "updated_at", Type: "timestamp"},
+		},
+	},
+	{
+		Name: "qc_ribbons", Version: 1, Table: "qc_ribbons", ReplayColumn: "updated_at", CompareSkip: "updated_at",
+		Fields: changeFeedQCFields,
+	},
+	{
+		Name: "qc_onlines", Version: 1, Table: "qc_onlines", ReplayColumn: "updated_at", CompareSkip: "updated_at",
+		Fields: changeFeedQCFields,
+	},
+	{
+		Name: "outbounds", Version: 1, Table: "outbounds", ReplayColumn: "updated_at", CompareSkip: "updated_at",
+		Fields: []ChangeFeedField{
+			{Name: "id", Type: "integer"},
+			{Name: "tracking_number", Type: "string"},
+			{Name: "outbound_by", Type: "integer"},
+			{Name: "expedition", Type: "string"},
+			{Name: "expedition_slug", Type: "string"},
+			{Name: "weight_grams", Type: "integer", Nullable: true},
+			{Name: "length_cm", Type: "number", Nullable: true},
+			{Name: "width_cm", Type: "number", Nullable: true},
+			{Name: "height_cm", Type: "number", Nullable: true},
+			{Name: "invoiced_weight_grams", Type: "integer", Nullable: true},
+			{Name: "invoiced_amount", Type: "integer", Nullable: true},
+			{Name: "invoice_ref", Type: "string"},
+			{Name: "handed_over_at", Type: "timestamp", Nullable: true},
+			{Name: "complained", Type: "boolean"},
+			{Name: "order_canceled", Type: "boolean"},
+			{Name: "created_at", Type: "timestamp"},
+			{Name: "updated_at", Type: "timestamp"},
+		},
+	},
+	{
+		Name: "products", Version: 1, Table: "products", ReplayColumn: "updated_at", CompareSkip: "updated_at",
+		Fields: []ChangeFeedField{
+			{Name: "id", Type: "integer"},
+			{Name: "sku", Type: "string"},
+			{Name: "name", Type: "string"},
+			{Name: "variant", Type: "string"},
+			{Name: "location", Type: "string"},
+			{Name: "barcode", Type: "string"},
+			{Name: "need_check", Type: "boolean"},
+			{Name: "serial_required", Type: "boolean"},
+			{Name: "weight_grams", Type: "integer", Nullable: true},
+			{Name: "length_cm", Type: "number", Nullable: true},
+			{Name: "width_cm", Type: "number", Nullable: true},
+			{Name: "height_cm", Type: "number", Nullable: true},
+			{Name: "required_skill_id", Type: "integer", Nullable: true},
+			{Name: "created_at", Type: "timestamp"},
+			{Name: "updated_at", Type: "timestamp"},
+		},
+	},
+	{
+		Name: "attendances", Version: 2, Table: "attendances", ReplayColumn: "checked_in",
+		Fields: []ChangeFeedField{
+			{Name: "id", Type: "integer"},
+			{Name: "user_id", Type: "integer"},
+			{Name: "location_id", Type: "integer"},
+			{Name: "status", Type: "string"},
+			{Name: "late", Type: "integer"},
+			{Name: "overtime", Type: "integer"},
+			{Name: "overtime_status", Type: "string"},
+			{Name: "approved_overtime", Type: "integer"},
+			{Name: "checked_in", Type: "timestamp"},
+			{Name: "checked_out", Type: "timestamp", Nullable: true},
+			{Name: "entry_method", Type: "string"},
+			{Name: "fallback_status", Type: "string"},
+			{Name: "fraud_score", Type: "integer"},
+			{Name: "suspicious", Type: "boolean"},
+			{Name: "missing_checkout", Type: "boolean"},
+			{Name: "auto_closed_at", Type: "timestamp", Nullable: true},
+			{Name: "break_minutes", Type: "integer"},
+			{Name: "break_violation", Type: "boolean"},
+		},
+	},
+}
+
+// changeFeedQCFields are the payload columns shared by both QC lanes
+var changeFeedQCFields = []ChangeFeedField{
+	{Name: "id", Type: "integer"},
+	{Name: "tracking_number", Type: "string"},
+	{Name: "qc_by", Type: "integer"},
+	{Name: "status", Type: "string"},
+	{Name: "qc_station_id", Type: "integer", Nullable: true},
+	{Name: "complained", Type: "boolean"},
+	{Name: "order_canceled", Type: "boolean"},
+	{Name: "created_at", Type: "timestamp"},
+	{Name: "updated_at", Type: "timestamp"},
+}
+
+// FindChangeFeedEntity returns the change feed entity with the given name
+func FindChangeFeedEntity(name string) (*ChangeFeedEntity, bool) {
+	for i := range ChangeFeedEntities {
+		if ChangeFeedEntities[i].Name == name {
+			return &ChangeFeedEntities[i], true
+		}
+	}
+	return nil, false
+}
+
+// payloadSQL returns the SQL building the payload of a row, row is NEW or OLD in a trigger or a table alias
+func (e *ChangeFeedEntity) payloadSQL(row string) string {
+	pairs := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		pairs[i] = fmt.Sprintf("'%s', %s.%s", field.Name, row, field.Name)
+	}
+	return "jsonb_build_object(" + strings.Join(pairs, ", ") + ")"
+}
+
+// InstallChangeFeedTriggers creates or replaces the triggers writing a change event for every insert, update and
+// delete of the change feed entities. Updates leaving the payload unchanged are skipped.
+func InstallChangeFeedTriggers(db *gorm.DB) error {
+	for _, entity := range ChangeFeedEntities {
+		name := "change_feed_" + entity.Table
+		compareNew, compareOld := "payload", entity.payloadSQL("OLD")
+		if entity.CompareSkip != "" {
+			compareNew = "payload - '" + entity.CompareSkip + "'"
+			compareOld = "(" + compareOld + ") - '" + entity.CompareSkip + "'"
+		}
+
+		function := fmt.Sprintf(`CREATE OR REPLACE FUNCTION %[1]s() RETURNS trigger AS $$
+DECLARE
+	payload jsonb;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		INSERT INTO change_events (entity, entity_id, operation, schema_version, payload, changed_at)
+		VALUES ('%[2]s', OLD.id, '%[3]s', %[4]d, jsonb_build_object('id', OLD.id), clock_timestamp());
+		RETURN OLD;
+	END IF;
+	payload := %[5]s;
+	IF TG_OP = 'UPDATE' AND %[6]s = %[7]s THEN
+		RETURN NEW;
+	END IF;
+	INSERT INTO change_events (entity, entity_id, operation, schema_version, payload, changed_at)
+	VALUES ('%[2]s', NEW.id, '%[8]s', %[4]d, payload, clock_timestamp());
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql`, name, entity.Name, models.ChangeEventDelete, entity.Version, entity.payloadSQL("NEW"), compareNew, compareOld, models.ChangeEventUpsert)
+
+		if err := db.Exec(function).Error; err != nil {
+			return fmt.Errorf("failed to create %s function: %w", name, err)
+		}
+		if err := db.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", name, entity.Table)).Error; err != nil {
+			return fmt.Errorf("failed to drop %s trigger: %w", name, err)
+		}
+		if err := db.Exec(fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT OR UPDATE OR DELETE ON %s FOR EACH ROW EXECUTE FUNCTION %s()", name, entity.Table, name)).Error; err != nil {
+			return fmt.Errorf("failed to create %s trigger: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ReplayChangeFeed queues a snapshot event with the current row of every entity row changed since the given time,
+// so the warehouse can be reloaded without reading the production tables. Returns the number of events queued.
+func ReplayChangeFeed(db *gorm.DB, entity *ChangeFeedEntity, since time.Time) (int64, error) {
+	result := db.Exec(fmt.Sprintf(`INSERT INTO change_events (entity, entity_id, operation, schema_version, payload, changed_at)
+SELECT ?, t.id, ?, ?, %s, clock_timestamp() FROM %s t WHERE t.%s >= ? ORDER BY t.id`, entity.payloadSQL("t"), entity.Table, entity.ReplayColumn),
+		entity.Name, models.ChangeEventSnapshot, entity.Version, since)
+	return result.RowsAffected, result.Error
+}
+
+// StartChangeFeedPurgeScheduler deletes change events older than the retention once a day in the background
+func StartChangeFeedPurgeScheduler(db *gorm.DB, retentionDays int) {
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if GetMaintenance().Enabled {
+				log.Println("StartChangeFeedPurgeScheduler - Skipping purge during maintenance mode")
+				continue
+			}
+
+			result := db.Where("changed_at < ?", time.Now().AddDate(0, 0, -retentionDays)).Delete(&models.ChangeEvent{})
+			if result.Error != nil {
+				log.Println("StartChangeFeedPurgeScheduler - Purge failed:", result.Error)
+				continue
+			}
+			if result.RowsAffected > 0 {
+				log.Printf("StartChangeFeedPurgeScheduler - %d change events purged\n", result.RowsAffected)
+			}
+		}
+	}()
+}
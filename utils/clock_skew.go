@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+// ClockSkewPolicy holds the limits applied to timestamps captured on client devices
+type ClockSkewPolicy struct {
+	Tolerance     time.Duration // allowed difference between the device clock and the server clock
+	OfflineWindow time.Duration // how long a device may capture actions after it last synced server time
+	tokenKey      string        // signs the time tokens
+}
+
+// ClockSkewPolicyFromConfig builds the clock skew policy from the application config
+func ClockSkewPolicyFromConfig(cfg *config.Config) ClockSkewPolicy {
+	return ClockSkewPolicy{
+		Tolerance:     time.Duration(cfg.ClockSkewToleranceSeconds) * time.Second,
+		OfflineWindow: time.Duration(cfg.OfflineCaptureWindowHours) * time.Hour,
+		tokenKey:      cfg.PasetoSymmetricKey,
+	}
+}
+
+// GenerateTimeToken signs the current server time for the user so offline captures can later prove
+// when the device last synced its clock
+func (p ClockSkewPolicy) GenerateTimeToken(userID string) (string, time.Time, error) {
+	now := time.Now()
+	token := paseto.NewToken()
+	token.SetIssuedAt(now)
+	token.SetString("userId", userID)
+	token.SetString("type", "time")
+
+	key, err := paseto.V4SymmetricKeyFromBytes([]byte(p.tokenKey))
+	if err != nil {
+		return "", now, err
+	}
+	return token.V4Encrypt(key, nil), now, nil
+}
+
+// ParseTimeToken returns the server time signed into a time token issued to the user.
+// Expiry is not enforced here, the offline window is checked by CheckClientTimestamp.
+func (p ClockSkewPolicy) ParseTimeToken(tokenString, userID string) (time.Time, error) {
+	key, err := paseto.V4SymmetricKeyFromBytes([]byte(p.tokenKey))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	token, err := paseto.NewParser().ParseV4Local(key, tokenString, nil)
+	if err != nil {
+		return time.Time{}, errors.New("invalid time token")
+	}
+	if tokenType, _ := token.GetString("type"); tokenType != "time" {
+		return time.Time{}, errors.New("invalid time token")
+	}
+	if tokenUserID, _ := token.GetString("userId"); tokenUserID != userID {
+		return time.Time{}, errors.New("time token was issued to another user")
+	}
+	return token.GetIssuedAt()
+}
+
+// CheckClientTimestamp validates a timestamp captured on a device against the time it reached the server
+// and, when known, the server time the device last synced. Returns the reason the timestamp is implausible,
+// empty when it is plausible.
+func (p ClockSkewPolicy) CheckClientTimestamp(clientTime, receivedAt time.Time, syncedAt *time.Time) string {
+	if ahead := clientTime.Sub(receivedAt); ahead > p.Tolerance {
+		return fmt.Sprintf("Client time is %s ahead of the server", ahead.Round(time.Second))
+	}
+
+	if syncedAt == nil {
+		if p.OfflineWindow > 0 && receivedAt.Sub(clientTime) > p.OfflineWindow {
+			return fmt.Sprintf("Client time is more than %s before it reached the server", p.OfflineWindow)
+		}
+		return ""
+	}
+
+	if before := syncedAt.Sub(clientTime); before > p.Tolerance {
+		return fmt.Sprintf("Client time is %s before the device last synced server time", before.Round(time.Second))
+	}
+	if p.OfflineWindow > 0 && clientTime.Sub(*syncedAt) > p.OfflineWindow {
+		return fmt.Sprintf("Client time is more than %s after the device last synced server time", p.OfflineWindow)
+	}
+	return ""
+}
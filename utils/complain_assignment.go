@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoComplainAgent is returned when a complain is auto-assigned but no active user holds a CS agent role
+var ErrNoComplainAgent = errors.New("no active complain agent")
+
+// ErrNotComplainAgent is returned when a complain is assigned to a user who is not an active CS agent
+var ErrNotComplainAgent = errors.New("user is not an active complain agent")
+
+// ComplainAssignmentPolicy decides who complains are routed to
+type ComplainAssignmentPolicy struct {
+	AgentRoles []string // roles of the CS agents complains are assigned to
+	AutoAssign bool     // assign new complains round-robin on creation
+}
+
+// ComplainAssignmentPolicyFromConfig builds the complain assignment policy from the application config
+func ComplainAssignmentPolicyFromConfig(cfg *config.Config) ComplainAssignmentPolicy {
+	return ComplainAssignmentPolicy{
+		AgentRoles: cfg.ComplainAgentRoles,
+		AutoAssign: cfg.ComplainAutoAssign,
+	}
+}
+
+// ComplainAgentIDs returns the IDs of the active users holding one of the CS agent roles, by ID
+func ComplainAgentIDs(db *gorm.DB, policy ComplainAssignmentPolicy) ([]uint, error) {
+	var agentIDs []uint
+	if len(policy.AgentRoles) == 0 {
+		return agentIDs, nil
+	}
+	err := db.Model(&models.User{}).
+		Distinct("users.id").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.role_name IN ? AND users.is_active = ?", policy.AgentRoles, true).
+		Order("users.id ASC").
+		Pluck("users.id", &agentIDs).Error
+	return agentIDs, err
+}
+
+// IsComplainAgent reports whether the user is an active CS agent
+func IsComplainAgent(db *gorm.DB, policy ComplainAssignmentPolicy, userID uint) (bool, error) {
+	agentIDs, err := ComplainAgentIDs(db, policy)
+	if err != nil {
+		return false, err
+	}
+	for _, agentID := range agentIDs {
+		if agentID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NextComplainAgent picks the agent for a complain round-robin: the agent assigned a complain the longest ago,
+// agents never assigned one first
+func NextComplainAgent(db *gorm.DB, policy ComplainAssignmentPolicy) (uint, error) {
+	agentIDs, err := ComplainAgentIDs(db, policy)
+	if err != nil {
+		return 0, err
+	}
+	if len(agentIDs) == 0 {
+		return 0, ErrNoComplainAgent
+	}
+
+	var lastAssigned []struct {
+		ToUserID uint
+		LastAt   time.Time
+	}
+	if err := db.Model(&models.ComplainAssignment{}).
+		Select("to_user_id, MAX(created_at) as last_at").
+		Where("to_user_id IN ?", agentIDs).
+		Group("to_user_id").
+		Scan(&lastAssigned).Error; err != nil {
+		return 0, err
+	}
+	lastAt := make(map[uint]time.Time, len(lastAssigned))
+	for _, row := range lastAssigned {
+		lastAt[row.ToUserID] = row.LastAt
+	}
+
+	next := agentIDs[0]
+	for _, agentID := range agentIDs[1:] {
+		if lastAt[agentID].Before(lastAt[next]) {
+			next = agentID
+		}
+	}
+	return next, nil
+}
+
+// AssignComplain hands a complain to an agent, records the assignment history and notifies the agent.
+// assignedBy is nil for automatic assignments.
+func AssignComplain(tx *gorm.DB, complain *models.Complain, agentID uint, method string, reason *string, assignedBy *uint) error {
+	now := time.Now()
+	if err := tx.Create(&models.ComplainAssignment{
+		ComplainID: complain.ID,
+		FromUserID: complain.AssignedTo,
+		ToUserID:   agentID,
+		Method:     method,
+		Reason:     reason,
+		AssignedBy: assignedBy,
+	}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Complain{}).Where("id = ?", complain.ID).Updates(map[string]interface{}{
+		"assigned_to": agentID,
+		"assigned_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	complain.AssignedTo = &agentID
+	complain.AssignedAt = &now
+
+	return NotifyUsers(tx, []uint{agentID}, "complain_assigned", "Complain assigned to you",
+		fmt.Sprintf("Complain %s on parcel %s was assigned to you: %s", complain.Code, complain.TrackingNumber, complain.Reason), "complain", complain.ID)
+}
+
+// AutoAssignComplain assigns a new complain round-robin when the policy and the complain_auto_assign feature flag
+// for the user creating it enable it. Without any agent the complain is left unassigned.
+func AutoAssignComplain(tx *gorm.DB, policy ComplainAssignmentPolicy, complain *models.Complain, subject FeatureSubject) error {
+	if !policy.AutoAssign || !FeatureEnabled(tx, models.FeatureFlagComplainAutoAssign, subject, true) {
+		return nil
+	}
+	agentID, err := NextComplainAgent(tx, policy)
+	if errors.Is(err, ErrNoComplainAgent) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return AssignComplain(tx, complain, agentID, models.ComplainAssignmentAuto, nil, nil)
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ComplainSettlementNotifyRoles are notified when a settlement is generated
+var ComplainSettlementNotifyRoles = []string{"hrd"}
+
+var (
+	ErrComplainSettled          = errors.New("complain fees are settled")
+	ErrComplainSettlementExists = errors.New("settlement already generated for the period")
+)
+
+// ComplainSettlementPeriodEnd returns the first moment after the month of a YYYY-MM period
+func ComplainSettlementPeriodEnd(period string) (time.Time, error) {
+	start, err := time.ParseInLocation("2006-01", period, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return start.AddDate(0, 1, 0), nil
+}
+
+// LockUnsettledComplain locks the complain row for the transaction and returns ErrComplainSettled when its fees
+// are frozen in a settlement, so fee edits cannot race with the settlement generation
+func LockUnsettledComplain(tx *gorm.DB, complainID uint) error {
+	var complain models.Complain
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "settlement_id").Where("id = ?", complainID).First(&complain).Error; err != nil {
+		return err
+	}
+	if complain.SettlementID != nil {
+		return ErrComplainSettled
+	}
+	return nil
+}
+
+// GenerateComplainSettlement freezes the fees of every resolved complain created before the end of the period
+// and not settled yet into a new settlement. Complains with an open fee dispute are left for a later settlement.
+// The complains are claimed before their fees are read, so fee edits committed meanwhile are either included or rejected.
+func GenerateComplainSettlement(db *gorm.DB, period string, generatedBy uint) (*models.ComplainSettlement, error) {
+	periodEnd, err := ComplainSettlementPeriodEnd(period)
+	if err != nil {
+		return nil, err
+	}
+
+	settlement := models.ComplainSettlement{
+		Period:      period,
+		PeriodEnd:   periodEnd,
+		Status:      models.ComplainSettlementStatusOpen,
+		GeneratedBy: generatedBy,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.ComplainSettlement{}).Where("period = ?", period).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrComplainSettlementExists
+		}
+		if err := tx.Create(&settlement).Error; err != nil {
+			return err
+		}
+
+		// Claim the complains charging a fee
+		if err := tx.Model(&models.Complain{}).
+			Where("settlement_id IS NULL AND total_fee IS NOT NULL AND created_at < ?", periodEnd).
+			Where("id IN (?)", tx.Model(&models.ComplainUserDetail{}).Select("complain_id").Where("fee_charge > 0")).
+			Where("id NOT IN (?)", tx.Model(&models.ComplainFeeDispute{}).Select("complain_id").Where("status = ?", models.FeeDisputeStatusOpen)).
+			Update("settlement_id", settlement.ID).Error; err != nil {
+			return err
+		}
+
+		// Freeze the fee per complain and user
+		var rows []struct {
+			ComplainID     uint
+			UserID         uint
+			Code           string
+			TrackingNumber string
+			CreatedAt      time.Time
+			FeeCharge      int
+		}
+		if err := tx.Table("complain_user_details").
+			Select("complains.id as complain_id, complain_user_details.user_id, complains.code, complains.tracking_number, complains.created_at, SUM(complain_user_details.fee_charge) as fee_charge").
+			Joins("JOIN complains ON complains.id = complain_user_details.complain_id").
+			Where("complains.settlement_id = ?", settlement.ID).
+			Group("complains.id, complain_user_details.user_id, complains.code, complains.tracking_number, complains.created_at").
+			Having("SUM(complain_user_details.fee_charge) > 0").
+			Order("complain_user_details.user_id ASC, complains.created_at ASC").
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		complains := make(map[uint]bool)
+		users := make(map[uint]bool)
+		lines := make([]models.ComplainSettlementLine, len(rows))
+		for i, row := range rows {
+			lines[i] = models.ComplainSettlementLine{
+				SettlementID:      settlement.ID,
+				ComplainID:        row.ComplainID,
+				UserID:            row.UserID,
+				ComplainCode:      row.Code,
+				TrackingNumber:    row.TrackingNumber,
+				FeeCharge:         row.FeeCharge,
+				ComplainCreatedAt: row.CreatedAt,
+			}
+			complains[row.ComplainID] = true
+			users[row.UserID] = true
+			settlement.TotalFee += int64(row.FeeCharge)
+		}
+		if len(lines) > 0 {
+			if err := tx.CreateInBatches(&lines, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		settlement.ComplainCount = len(complains)
+		settlement.UserCount = len(users)
+		if err := tx.Model(&settlement).Updates(map[string]interface{}{
+			"complain_count": settlement.ComplainCount,
+			"user_count":     settlement.UserCount,
+			"total_fee":      settlement.TotalFee,
+		}).Error; err != nil {
+			return err
+		}
+
+		return NotifyRoles(tx, ComplainSettlementNotifyRoles, "complain_settlement", "Complain settlement generated",
+			fmt.Sprintf("Complain settlement %s freezes %d complains charging %d users a total of %d", period, settlement.ComplainCount, settlement.UserCount, settlement.TotalFee),
+			"complain_settlement", settlement.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &settlement, nil
+}
+
+// BuildComplainSettlementStatementDocument renders the deduction statement of a user as a printable document
+func BuildComplainSettlementStatementDocument(settlement *models.ComplainSettlement, statement models.ComplainSettlementStatement) PDFDocument {
+	lines := []string{
+		"COMPLAIN FEE DEDUCTION STATEMENT",
+		"",
+		fmt.Sprintf("Period    : %s", settlement.Period),
+		fmt.Sprintf("Status    : %s", settlement.Status),
+		fmt.Sprintf("Employee  : %s (%s)", statement.FullName, statement.Username),
+		fmt.Sprintf("Generated : %s", settlement.CreatedAt.Format("02-01-2006 15:04")),
+		"",
+		fmt.Sprintf("%-20s %-30s %-19s %12s", "Complain", "Tracking Number", "Created At", "Fee"),
+		strings.Repeat("-", 84),
+	}
+	for _, line := range statement.Lines {
+		lines = append(lines, fmt.Sprintf("%-20s %-30s %-19s %12d", truncateLabel(line.ComplainCode, 20), truncateLabel(line.TrackingNumber, 30), line.ComplainCreatedAt, line.FeeCharge))
+	}
+	lines = append(lines,
+		strings.Repeat("-", 84),
+		fmt.Sprintf("%-71s %12d", fmt.Sprintf("Total deduction (%d complains)", statement.ComplainCount), statement.TotalFee),
+	)
+	return PDFDocument{Lines: lines}
+}
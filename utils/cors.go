@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"net/url"
+	"strings"
+)
+
+// CORS profiles, each with its own defaults and validation rules
+const (
+	CORSProfileDev     = "dev"     // groups without origins allow any origin
+	CORSProfileStaging = "staging" // groups without origins fall back to the admin origins
+	CORSProfileProd    = "prod"    // groups without origins allow no cross-origin requests, wildcards are rejected
+)
+
+// CORS route groups
+const (
+	CORSGroupKiosk  = "kiosk"
+	CORSGroupMobile = "mobile"
+	CORSGroupAdmin  = "admin"
+)
+
+// CORSGroup is the CORS configuration applied to the routes under its path prefixes
+type CORSGroup struct {
+	Name             string   `json:"name"`
+	PathPrefixes     []string `json:"pathPrefixes"` // empty matches every route not claimed by another group
+	AllowOrigins     []string `json:"allowOrigins"` // exact origins, patterns with a single * or "*" alone for any origin
+	AllowCredentials bool     `json:"allowCredentials"`
+}
+
+// CORSPolicy is the effective CORS configuration per route group
+type CORSPolicy struct {
+	Profile string      `json:"profile"`
+	Groups  []CORSGroup `json:"groups"` // matched in order, the last group is the default
+}
+
+// CORSPolicyFromConfig builds and validates the CORS policy from the application config.
+// The kiosk and the mobile WebView authenticate with headers, so they never get credentials
+// unless CORS_MOBILE_CREDENTIALS enables them for the mobile routes.
+func CORSPolicyFromConfig(cfg *config.Config) (*CORSPolicy, error) {
+	profile := strings.ToLower(strings.TrimSpace(cfg.CorsProfile))
+	if profile != CORSProfileDev && profile != CORSProfileStaging && profile != CORSProfileProd {
+		return nil, fmt.Errorf("unknown CORS profile %q, use dev, staging or prod", cfg.CorsProfile)
+	}
+
+	admin := CORSGroup{
+		Name:             CORSGroupAdmin,
+		AllowOrigins:     cfg.CorsOrigins,
+		AllowCredentials: !isAnyOrigin(cfg.CorsOrigins),
+	}
+	defaultOrigins := func(origins []string) []string {
+		if len(origins) > 0 {
+			return origins
+		}
+		switch profile {
+		case CORSProfileDev:
+			return []string{"*"}
+		case CORSProfileStaging:
+			return admin.AllowOrigins
+		default:
+			return []string{}
+		}
+	}
+
+	policy := &CORSPolicy{
+		Profile: profile,
+		Groups: []CORSGroup{
+			{
+				Name:         CORSGroupKiosk,
+				PathPrefixes: []string{"/api/attendances/kiosk-summary"},
+				AllowOrigins: defaultOrigins(cfg.CorsKioskOrigins),
+			},
+			{
+				Name:             CORSGroupMobile,
+				PathPrefixes:     []string{"/api/mobile-"},
+				AllowOrigins:     defaultOrigins(cfg.CorsMobileOrigins),
+				AllowCredentials: cfg.CorsMobileCredentials,
+			},
+			admin,
+		},
+	}
+
+	for _, group := range policy.Groups {
+		if err := validateCORSGroup(profile, group); err != nil {
+			return nil, fmt.Errorf("CORS group %s: %w", group.Name, err)
+		}
+	}
+	return policy, nil
+}
+
+// validateCORSGroup checks the origins of a group against the rules of the profile
+func validateCORSGroup(profile string, group CORSGroup) error {
+	if isAnyOrigin(group.AllowOrigins) {
+		if profile == CORSProfileProd {
+			return fmt.Errorf("any origin (*) is not allowed in the prod profile")
+		}
+		if group.AllowCredentials {
+			return fmt.Errorf("credentials cannot be allowed for any origin (*)")
+		}
+		return nil
+	}
+
+	for _, origin := range group.AllowOrigins {
+		if origin == "*" {
+			return fmt.Errorf("* must be the only origin when used")
+		}
+		if strings.Count(origin, "*") > 1 {
+			return fmt.Errorf("origin %q may contain a single * wildcard", origin)
+		}
+		parsed, err := url.Parse(strings.Replace(origin, "*", "wildcard", 1))
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" {
+			return fmt.Errorf("origin %q must be scheme://host[:port]", origin)
+		}
+	}
+	return nil
+}
+
+// GroupFor returns the CORS group applied to the request path
+func (p *CORSPolicy) GroupFor(path string) *CORSGroup {
+	for i := range p.Groups {
+		group := &p.Groups[i]
+		if len(group.PathPrefixes) == 0 {
+			return group
+		}
+		for _, prefix := range group.PathPrefixes {
+			if strings.HasPrefix(path, prefix) {
+				return group
+			}
+		}
+	}
+	return nil
+}
+
+// AllowsAnyOrigin reports whether the group accepts requests from every origin
+func (g *CORSGroup) AllowsAnyOrigin() bool {
+	return isAnyOrigin(g.AllowOrigins)
+}
+
+// AllowsOrigin reports whether the group accepts requests from the origin
+func (g *CORSGroup) AllowsOrigin(origin string) bool {
+	if g.AllowsAnyOrigin() {
+		return true
+	}
+	for _, allowedOrigin := range g.AllowOrigins {
+		// Exact match
+		if origin == allowedOrigin {
+			return true
+		}
+		// Pattern match (e.g., http://192.168.41.*:8081)
+		if MatchOriginPattern(allowedOrigin, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchOriginPattern checks if an origin matches a pattern with a single wildcard
+func MatchOriginPattern(pattern, origin string) bool {
+	if !strings.Contains(pattern, "*") {
+		return false
+	}
+
+	// Split by wildcard
+	parts := strings.Split(pattern, "*")
+	if len(parts) != 2 {
+		return false
+	}
+
+	// Check if origin starts with the part before * and ends with the part after *
+	return strings.HasPrefix(origin, parts[0]) && strings.HasSuffix(origin, parts[1])
+}
+
+func isAnyOrigin(origins []string) bool {
+	return len(origins) == 1 && origins[0] == "*"
+}
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+)
+
+// CourierShipment is the shipment of an order booked through a courier API
+type CourierShipment struct {
+	Reference     string                `json:"reference"` // marketplace order ID, or the internal order number
+	CourierCode   string                `json:"courierCode"`
+	Shipper       CourierParty          `json:"shipper"`
+	Receiver      CourierParty          `json:"receiver"`
+	Parcel        CourierParcel         `json:"parcel"`
+	Items         []CourierShipmentItem `json:"items"`
+	DeclaredValue int64                 `json:"declaredValue"`
+	Currency      string                `json:"currency"`
+}
+
+// CourierParty is the shipper or receiver of a shipment
+type CourierParty struct {
+	Name       string `json:"name"`
+	Phone      string `json:"phone,omitempty"`
+	Address    string `json:"address"`
+	Province   string `json:"province,omitempty"`
+	City       string `json:"city,omitempty"`
+	District   string `json:"district,omitempty"`
+	PostalCode string `json:"postalCode,omitempty"`
+}
+
+// CourierParcel is the parcel a shipment is booked with
+type CourierParcel struct {
+	WeightGrams int     `json:"weightGrams"` // chargeable weight
+	VolumeCm3   float64 `json:"volumeCm3,omitempty"`
+}
+
+// CourierShipmentItem is an order line packed in the parcel
+type CourierShipmentItem struct {
+	SKU      string `json:"sku"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+// CourierBookingResult is the answer of a courier API to a booking
+type CourierBookingResult struct {
+	TrackingNumber   string `json:"trackingNumber"`
+	BookingReference string `json:"bookingReference"`
+}
+
+// IsCourierBookingConfigured reports whether shipments of the expedition can be booked through its courier API
+func IsCourierBookingConfigured(cfg *config.Config, expeditionSlug string) bool {
+	return cfg.CourierBookingURLs[expeditionSlug] != "" && cfg.CourierBookingAPIKeys[expeditionSlug] != ""
+}
+
+// NewCourierShipment builds the shipment of an order, shipped by its store, parcel is the weight it is booked with
+func NewCourierShipment(order *models.Order, store *models.Store, expedition *models.Expedition, parcel CourierParcel) CourierShipment {
+	shipper := CourierParty{Name: order.Store}
+	if store != nil {
+		shipper.Name = store.StoreName
+		if store.InvoiceName != "" {
+			shipper.Name = store.InvoiceName
+		}
+		shipper.Phone = store.InvoicePhone
+		shipper.Address = store.InvoiceAddress
+	}
+
+	items := make([]CourierShipmentItem, 0, len(order.OrderDetails))
+	for _, detail := range order.OrderDetails {
+		if detail.Quantity <= 0 {
+			continue
+		}
+		items = append(items, CourierShipmentItem{SKU: detail.SKU, Name: detail.ProductName, Quantity: detail.Quantity})
+	}
+
+	return CourierShipment{
+		Reference:   order.OrderGineeID,
+		CourierCode: expedition.ExpeditionCode,
+		Shipper:     shipper,
+		Receiver: CourierParty{
+			Name:       order.Buyer,
+			Address:    order.Address,
+			Province:   order.Province,
+			City:       order.City,
+			District:   order.District,
+			PostalCode: order.PostalCode,
+		},
+		Parcel:        parcel,
+		Items:         items,
+		DeclaredValue: order.TotalValue,
+		Currency:      order.Currency,
+	}
+}
+
+// BookCourierShipment books the shipment through the courier API of the expedition and returns the tracking number
+// it issued
+func BookCourierShipment(ctx context.Context, cfg *config.Config, expeditionSlug string, shipment CourierShipment) (*CourierBookingResult, error) {
+	if !IsCourierBookingConfigured(cfg, expeditionSlug) {
+		return nil, errors.New("courier booking is not configured for " + expeditionSlug)
+	}
+
+	body, err := postAccountingJSON(ctx, expeditionSlug, cfg.CourierBookingURLs[expeditionSlug], map[string]string{
+		"Authorization": "Bearer " + cfg.CourierBookingAPIKeys[expeditionSlug],
+	}, shipment)
+	if err != nil {
+		return nil, err
+	}
+
+	var result CourierBookingResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, errors.New(expeditionSlug + " returned an invalid booking response: " + truncateLabel(string(body), 512))
+	}
+	result.TrackingNumber = models.NormalizeIdentifier(result.TrackingNumber)
+	if result.TrackingNumber == "" {
+		return nil, errors.New(expeditionSlug + " returned no tracking number: " + truncateLabel(string(body), 512))
+	}
+	return &result, nil
+}
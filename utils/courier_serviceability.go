@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CourierServiceability is the result of checking that the courier of an order delivers to its address
+type CourierServiceability struct {
+	Courier     string `json:"courier"`
+	Expedition  string `json:"expedition,omitempty"` // expedition the courier matched, empty when it is not set up
+	Province    string `json:"province,omitempty"`
+	Serviceable bool   `json:"serviceable"`
+	Reason      string `json:"reason,omitempty"` // why the courier does not deliver, or why the check was skipped
+}
+
+// CheckCourierServiceability checks the courier of an order against the provinces its expedition delivers to.
+// Couriers that are not set up, expeditions without service provinces and addresses without a resolved province
+// pass, the reason says why the check was skipped.
+func CheckCourierServiceability(db *gorm.DB, order *models.Order) (CourierServiceability, error) {
+	result := CourierServiceability{Courier: order.Courier, Province: order.Province, Serviceable: true}
+
+	expedition, err := FindOrderExpedition(db, order)
+	if err != nil {
+		return result, err
+	}
+	if expedition == nil {
+		result.Reason = "courier is not set up as an expedition"
+		return result, nil
+	}
+	result.Expedition = expedition.ExpeditionName
+
+	provinces := expedition.ServiceProvinceList()
+	if len(provinces) == 0 {
+		return result, nil
+	}
+	if order.Province == "" {
+		result.Reason = "address province could not be resolved"
+		return result, nil
+	}
+
+	province := normalizeRegionName(order.Province)
+	for _, served := range provinces {
+		if normalizeRegionName(served) == province {
+			return result, nil
+		}
+	}
+	result.Serviceable = false
+	result.Reason = expedition.ExpeditionName + " does not deliver to " + order.Province
+	return result, nil
+}
+
+// FindOrderExpedition returns the expedition of an order by courier name, code or slug, falling back to the
+// expedition code the tracking number starts with. Returns nil when none matches.
+func FindOrderExpedition(db *gorm.DB, order *models.Order) (*models.Expedition, error) {
+	var expeditions []models.Expedition
+	if err := db.Find(&expeditions).Error; err != nil {
+		return nil, err
+	}
+	return MatchOrderExpedition(expeditions, order), nil
+}
+
+// MatchOrderExpedition returns the expedition of an order among the given ones, like FindOrderExpedition. Returns
+// nil when none matches.
+func MatchOrderExpedition(expeditions []models.Expedition, order *models.Order) *models.Expedition {
+	courier := strings.ToLower(strings.TrimSpace(order.Courier))
+	if courier != "" {
+		for i, expedition := range expeditions {
+			if strings.ToLower(expedition.ExpeditionName) == courier || strings.ToLower(expedition.ExpeditionCode) == courier || expedition.ExpeditionSlug == GenerateSlug(courier) {
+				return &expeditions[i]
+			}
+		}
+	}
+
+	// Longer codes are matched first so "JX" does not take "JXE" parcels
+	var matched *models.Expedition
+	trackingNumber := strings.ToUpper(order.TrackingNumber)
+	for i, expedition := range expeditions {
+		if expedition.ExpeditionCode == "" || !strings.HasPrefix(trackingNumber, expedition.ExpeditionCode) {
+			continue
+		}
+		if matched == nil || len(expedition.ExpeditionCode) > len(matched.ExpeditionCode) {
+			matched = &expeditions[i]
+		}
+	}
+	return matched
+}
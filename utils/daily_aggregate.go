@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// dailyAggregateSource is a grouped count of a transactional table stored as a daily aggregate metric.
+// Table and columns are never taken from user input.
+type dailyAggregateSource struct {
+	Metric          string
+	Table           string
+	DimensionColumn string // selected as the dimension, a quoted constant for a fixed dimension
+	UserColumn      string // empty when the metric is not per user
+}
+
+var dailyAggregateSources = []dailyAggregateSource{
+	{Metric: models.DailyAggregateOrdersByStatus, Table: "orders", DimensionColumn: "processing_status"},
+	{Metric: models.DailyAggregateQCByUser, Table: "qc_ribbons", DimensionColumn: "'" + models.DailyAggregateLaneRibbon + "'", UserColumn: "qc_by"},
+	{Metric: models.DailyAggregateQCByUser, Table: "qc_onlines", DimensionColumn: "'" + models.DailyAggregateLaneOnline + "'", UserColumn: "qc_by"},
+	{Metric: models.DailyAggregateOutboundByExpedition, Table: "outbounds", DimensionColumn: "COALESCE(expedition_slug, '')"},
+}
+
+// RefreshDailyAggregates recomputes the daily aggregates of every day from start to end, both inclusive.
+// Days are recomputed as a whole so counts that moved between dimensions, like order statuses, stay correct.
+func RefreshDailyAggregates(db *gorm.DB, start, end time.Time) error {
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	now := time.Now()
+
+	var aggregates []models.DailyAggregate
+	for _, source := range dailyAggregateSources {
+		userColumn := "0"
+		if source.UserColumn != "" {
+			userColumn = source.UserColumn
+		}
+
+		var rows []struct {
+			Date      time.Time
+			Dimension string
+			UserID    uint
+			Count     int64
+		}
+		if err := db.Table(source.Table).
+			Select("DATE(created_at) as date, "+source.DimensionColumn+" as dimension, "+userColumn+" as user_id, COUNT(*) as count").
+			Where("created_at >= ? AND created_at < ?", start, end).
+			Group("DATE(created_at), dimension, user_id").
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			aggregates = append(aggregates, models.DailyAggregate{
+				Date:        row.Date,
+				Metric:      source.Metric,
+				Dimension:   row.Dimension,
+				UserID:      row.UserID,
+				Count:       row.Count,
+				RefreshedAt: now,
+			})
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("date >= ? AND date < ?", start, end).Delete(&models.DailyAggregate{}).Error; err != nil {
+			return err
+		}
+		if len(aggregates) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(aggregates, 500).Error
+	})
+}
+
+// StartDailyAggregateScheduler recomputes the daily aggregates of the last days at startup and periodically in the background.
+// Older days are only recomputed by the aggregates admin command.
+func StartDailyAggregateScheduler(db *gorm.DB, interval time.Duration, lookbackDays int) {
+	refresh := func() {
+		if GetMaintenance().Enabled {
+			log.Println("StartDailyAggregateScheduler - Skipping aggregate refresh during maintenance mode")
+			return
+		}
+
+		now := time.Now()
+		if err := RefreshDailyAggregates(db, now.AddDate(0, 0, -lookbackDays), now); err != nil {
+			log.Println("StartDailyAggregateScheduler - Aggregate refresh failed:", err)
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		refresh()
+		for range ticker.C {
+			refresh()
+		}
+	}()
+}
+
+// DailyAggregateQuery selects the daily aggregates of a metric from start to end, end exclusive, dimension empty for every dimension
+func DailyAggregateQuery(db *gorm.DB, metric, dimension string, start, end time.Time) *gorm.DB {
+	query := db.Model(&models.DailyAggregate{}).Where("metric = ? AND date >= ? AND date < ?", metric, start, end)
+	if dimension != "" {
+		query = query.Where("dimension = ?", dimension)
+	}
+	return query
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Suspicious order data found by the data quality check
+const (
+	DataQualityMissingCourier          = "missing_courier"
+	DataQualityMissingTrackingNumber   = "missing_tracking_number"
+	DataQualityMalformedTrackingNumber = "malformed_tracking_number"
+	DataQualityZeroPrice               = "zero_price"
+	DataQualityBuyerAnomaly            = "buyer_anomaly"
+	DataQualityAddressAnomaly          = "address_anomaly"
+	DataQualityDuplicateSKU            = "duplicate_sku"
+	DataQualityUnknownChannel          = "unknown_channel"
+)
+
+// DataQualityIssueTypes lists the data quality issues in report order
+var DataQualityIssueTypes = []string{
+	DataQualityMissingCourier,
+	DataQualityMissingTrackingNumber,
+	DataQualityMalformedTrackingNumber,
+	DataQualityZeroPrice,
+	DataQualityBuyerAnomaly,
+	DataQualityAddressAnomaly,
+	DataQualityDuplicateSKU,
+	DataQualityUnknownChannel,
+}
+
+// IsDataQualityIssueType reports whether the value is a known data quality issue
+func IsDataQualityIssueType(value string) bool {
+	for _, issueType := range DataQualityIssueTypes {
+		if issueType == value {
+			return true
+		}
+	}
+	return false
+}
+
+// minAddressLength is the shortest address that can hold a street, district and city
+const minAddressLength = 15
+
+// DataQualityIssue is a suspicious value found on an order
+type DataQualityIssue struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+}
+
+// DataQualityChecker holds the reference data orders are checked against, so many orders can be checked without a query each
+type DataQualityChecker struct {
+	channels map[string]bool // lower-cased channel names and codes
+	formats  *TrackingFormatSet
+}
+
+// LoadDataQualityChecker loads the channels and the active tracking formats
+func LoadDataQualityChecker(db *gorm.DB) (*DataQualityChecker, error) {
+	var channels []models.Channel
+	if err := db.Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	formats, err := LoadTrackingFormats(db)
+	if err != nil {
+		return nil, err
+	}
+
+	checker := &DataQualityChecker{channels: make(map[string]bool, len(channels)*2), formats: formats}
+	for _, channel := range channels {
+		checker.channels[strings.ToLower(channel.ChannelName)] = true
+		checker.channels[strings.ToLower(channel.ChannelCode)] = true
+	}
+	return checker, nil
+}
+
+// Check returns the data quality issues of an order, its details must be loaded
+func (dq *DataQualityChecker) Check(order *models.Order) []DataQualityIssue {
+	var issues []DataQualityIssue
+	add := func(issueType, detail string) {
+		issues = append(issues, DataQualityIssue{Type: issueType, Detail: detail})
+	}
+
+	if strings.TrimSpace(order.Courier) == "" {
+		add(DataQualityMissingCourier, "No courier")
+	}
+
+	trackingNumber := order.TrackingNumber
+	switch {
+	case strings.TrimSpace(trackingNumber) == "":
+		add(DataQualityMissingTrackingNumber, "No tracking number")
+	case strings.IndexFunc(trackingNumber, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' }) >= 0:
+		add(DataQualityMalformedTrackingNumber, fmt.Sprintf("Tracking number %q contains spaces or symbols", trackingNumber))
+	default:
+		if err := dq.formats.Validate(trackingNumber); err != nil {
+			add(DataQualityMalformedTrackingNumber, err.Error())
+		}
+	}
+
+	buyer := strings.TrimSpace(order.Buyer)
+	switch {
+	case buyer == "":
+		add(DataQualityBuyerAnomaly, "No buyer name")
+	case strings.IndexFunc(buyer, unicode.IsLetter) < 0:
+		add(DataQualityBuyerAnomaly, fmt.Sprintf("Buyer name %q has no letters", buyer))
+	}
+
+	address := strings.TrimSpace(order.Address)
+	switch {
+	case address == "":
+		add(DataQualityAddressAnomaly, "No address")
+	case len([]rune(address)) < minAddressLength:
+		add(DataQualityAddressAnomaly, fmt.Sprintf("Address %q is too short", address))
+	case strings.IndexFunc(address, unicode.IsLetter) < 0:
+		add(DataQualityAddressAnomaly, fmt.Sprintf("Address %q has no letters", address))
+	case order.AddressStatus == AddressStatusUnresolved:
+		add(DataQualityAddressAnomaly, "Address could not be matched to a province or city")
+	}
+
+	// Merged orders may carry the same SKU once per marketplace order
+	type skuKey struct {
+		sourceOrderID uint
+		sku           string
+	}
+	skuLines := make(map[skuKey]int)
+	for _, detail := range order.OrderDetails {
+		if detail.Price <= 0 {
+			add(DataQualityZeroPrice, fmt.Sprintf("SKU %s has price %d", detail.SKU, detail.Price))
+		}
+		key := skuKey{sku: strings.ToLower(detail.SKU)}
+		if detail.SourceOrderID != nil {
+			key.sourceOrderID = *detail.SourceOrderID
+		}
+		skuLines[key]++
+		if skuLines[key] == 2 {
+			add(DataQualityDuplicateSKU, fmt.Sprintf("SKU %s appears on more than one line", detail.SKU))
+		}
+	}
+
+	channel := strings.TrimSpace(order.Channel)
+	switch {
+	case channel == "":
+		add(DataQualityUnknownChannel, "No channel")
+	case !dq.channels[strings.ToLower(channel)]:
+		add(DataQualityUnknownChannel, fmt.Sprintf("Channel %q is not set up", channel))
+	}
+
+	return issues
+}
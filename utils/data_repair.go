@@ -0,0 +1,489 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Data repair rules
+const (
+	DataRepairStatusSpelling = "status_spelling"      // case, space and spelling variants of order statuses, e.g. "Cancelled"
+	DataRepairIdentifierCase = "identifier_case"      // tracking numbers and Ginee order IDs not trimmed and uppercased
+	DataRepairChannelVariant = "channel_name_variant" // order channels matching a channel by another case or its code
+)
+
+// Bounds of the batches a data repair run changes rows in, each batch in its own transaction
+const (
+	DefaultDataRepairBatchSize = 500
+	MaxDataRepairBatchSize     = 5000
+)
+
+// dataRepairPreviewLimit caps the value changes listed per column by a dry run
+const dataRepairPreviewLimit = 20
+
+// IdentifierColumn is a column holding tracking numbers or Ginee order IDs, stored normalized (see models.NormalizeIdentifier)
+type IdentifierColumn struct {
+	Table  string
+	Column string
+	Unique bool
+}
+
+// IdentifierColumns lists the identifier columns normalized at startup and by the identifier_case repair rule
+var IdentifierColumns = []IdentifierColumn{
+	{"orders", "order_ginee_id", true},
+	{"orders", "tracking_number", false},
+	{"complains", "tracking_number", true},
+	{"complains", "order_ginee_id", false},
+	{"complain_settlement_lines", "tracking_number", false},
+	{"outbounds", "tracking_number", true},
+	{"qc_ribbons", "tracking_number", true},
+	{"qc_onlines", "tracking_number", true},
+	{"qc_voids", "tracking_number", false},
+	{"qc_mismatches", "tracking_number", false},
+	{"qc_parcel_photos", "tracking_number", false},
+	{"handover_session_items", "tracking_number", false},
+	{"handover_ack_items", "tracking_number", false},
+	{"order_edit_overrides", "tracking_number", false},
+	{"pick_anomalies", "tracking_number", false},
+	{"pick_shortages", "tracking_number", false},
+	{"returns", "new_tracking_number", false},
+	{"returns", "tracking_number", false},
+	{"returns", "order_ginee_id", false},
+	{"unknown_returns", "tracking_number", true},
+	{"sla_breaches", "tracking_number", false},
+	{"sla_breaches", "order_ginee_id", false},
+}
+
+// dataRepairTarget is a column repaired by a rule. Tables, columns and SQL are never taken from user input.
+type dataRepairTarget struct {
+	Table  string
+	Column string
+	Fixed  func(row string) string // SQL of the repaired value from the columns of the row alias, NULL when the row cannot be repaired
+	Unique bool                    // a repaired value held by another row is left for manual review, the lowest id takes a shared one
+}
+
+// DataRepairRule is a data fix applied column by column
+type DataRepairRule struct {
+	Name        string
+	Description string
+	targets     []dataRepairTarget
+}
+
+// Columns returns the table.column names the rule changes
+func (r *DataRepairRule) Columns() []string {
+	columns := make([]string, len(r.targets))
+	for i, target := range r.targets {
+		columns[i] = target.Table + "." + target.Column
+	}
+	return columns
+}
+
+// target returns the target of the rule changing the column
+func (r *DataRepairRule) target(table, column string) (dataRepairTarget, bool) {
+	for _, target := range r.targets {
+		if target.Table == table && target.Column == column {
+			return target, true
+		}
+	}
+	return dataRepairTarget{}, false
+}
+
+// statusSpellingFix returns the SQL mapping the case, space and dash variants of the statuses, and their known
+// misspellings, to the status
+func statusSpellingFix(column string, definitions []models.StatusDefinition, misspellings map[string]string) func(row string) string {
+	mapping := make(map[string]string, len(definitions)+len(misspellings))
+	for _, definition := range definitions {
+		mapping[definition.Value] = definition.Value
+	}
+	for misspelling, status := range misspellings {
+		mapping[misspelling] = status
+	}
+	variants := make([]string, 0, len(mapping))
+	for variant := range mapping {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+
+	return func(row string) string {
+		cases := make([]string, len(variants))
+		for i, variant := range variants {
+			cases[i] = fmt.Sprintf("WHEN '%s' THEN '%s'", variant, mapping[variant])
+		}
+		return fmt.Sprintf("CASE REPLACE(REPLACE(LOWER(TRIM(%s.%s)), ' ', '_'), '-', '_') %s END", row, column, strings.Join(cases, " "))
+	}
+}
+
+// dataRepairRules are the data fixes that can be previewed, run and reverted
+var dataRepairRules = func() []DataRepairRule {
+	identifierTargets := make([]dataRepairTarget, len(IdentifierColumns))
+	for i, identifier := range IdentifierColumns {
+		column := identifier.Column
+		identifierTargets[i] = dataRepairTarget{
+			Table:  identifier.Table,
+			Column: column,
+			Fixed: func(row string) string {
+				return fmt.Sprintf("UPPER(TRIM(%s.%s))", row, column)
+			},
+			Unique: identifier.Unique,
+		}
+	}
+
+	return []DataRepairRule{
+		{
+			Name:        DataRepairStatusSpelling,
+			Description: "Rewrite order processing and event statuses stored in another case, with spaces or dashes, or misspelled as \"cancelled\", to their status",
+			targets: []dataRepairTarget{
+				{Table: "orders", Column: "event_status", Fixed: statusSpellingFix("event_status", models.EventStatusDefinitions(), map[string]string{"cancelled": models.EventStatusCanceled})},
+				{Table: "orders", Column: "processing_status", Fixed: statusSpellingFix("processing_status", models.ProcessingStatusDefinitions(), nil)},
+			},
+		},
+		{
+			Name:        DataRepairIdentifierCase,
+			Description: "Trim and uppercase tracking numbers and Ginee order IDs. On unique columns a value already held by another row is left for manual review",
+			targets:     identifierTargets,
+		},
+		{
+			Name:        DataRepairChannelVariant,
+			Description: "Rewrite order channels matching a registered channel in another case, with surrounding spaces or by its code, to the channel name",
+			targets: []dataRepairTarget{
+				{Table: "orders", Column: "channel", Fixed: func(row string) string {
+					return fmt.Sprintf("(SELECT channels.channel_name FROM channels WHERE LOWER(channels.channel_name) = LOWER(TRIM(%[1]s.channel)) OR LOWER(channels.channel_code) = LOWER(TRIM(%[1]s.channel)) ORDER BY LOWER(channels.channel_name) = LOWER(TRIM(%[1]s.channel)) DESC, channels.id LIMIT 1)", row)
+				}},
+			},
+		},
+	}
+}()
+
+// DataRepairRules returns the data repair rules
+func DataRepairRules() []DataRepairRule {
+	return dataRepairRules
+}
+
+// FindDataRepairRule returns the data repair rule with the given name
+func FindDataRepairRule(name string) (*DataRepairRule, bool) {
+	for i := range dataRepairRules {
+		if dataRepairRules[i].Name == name {
+			return &dataRepairRules[i], true
+		}
+	}
+	return nil, false
+}
+
+// DataRepairPreview is a value change a dry run found, with the number of rows it applies to
+type DataRepairPreview struct {
+	Entity   string  `json:"entity"`
+	Column   string  `json:"column"`
+	OldValue *string `json:"oldValue"`
+	NewValue string  `json:"newValue"`
+	Rows     int64   `json:"rows"`
+}
+
+// dataRepairCandidate is a row a target would change
+type dataRepairCandidate struct {
+	ID       uint
+	OldValue *string
+	NewValue string
+}
+
+// candidatesSQL returns the SQL selecting the rows the target would change with their current and repaired value.
+// Conflict is set on rows of unique columns that must be left as they are.
+func (t dataRepairTarget) candidatesSQL() string {
+	fixed := t.Fixed(t.Table)
+	candidates := fmt.Sprintf("SELECT %[1]s.id, %[1]s.%[2]s AS old_value, %[3]s AS new_value FROM %[1]s WHERE %[3]s IS NOT NULL AND %[1]s.%[2]s IS DISTINCT FROM %[3]s",
+		t.Table, t.Column, fixed)
+	conflict := "false"
+	if t.Unique {
+		conflict = fmt.Sprintf("(EXISTS (SELECT 1 FROM %s AS other WHERE other.%s = candidate.new_value) OR ROW_NUMBER() OVER (PARTITION BY candidate.new_value ORDER BY candidate.id) > 1)",
+			t.Table, t.Column)
+	}
+	return fmt.Sprintf("SELECT candidate.id, candidate.old_value, candidate.new_value, %s AS conflict FROM (%s) AS candidate", conflict, candidates)
+}
+
+// previewDataRepairTarget counts the rows the target would change and those left for review, and lists the most
+// frequent value changes
+func previewDataRepairTarget(db *gorm.DB, target dataRepairTarget) (models.DataRepairRunDetail, []DataRepairPreview, error) {
+	detail := models.DataRepairRunDetail{Entity: target.Table, Column: target.Column}
+	candidates := target.candidatesSQL()
+
+	var counts struct {
+		Affected  int64
+		Conflicts int64
+	}
+	if err := db.Raw("SELECT COUNT(*) FILTER (WHERE NOT conflict) AS affected, COUNT(*) FILTER (WHERE conflict) AS conflicts FROM (" + candidates + ") AS repair").
+		Scan(&counts).Error; err != nil {
+		return detail, nil, fmt.Errorf("%s.%s: %w", target.Table, target.Column, err)
+	}
+	detail.AffectedRows = counts.Affected
+	detail.ConflictRows = counts.Conflicts
+
+	var previews []DataRepairPreview
+	if counts.Affected > 0 {
+		if err := db.Raw("SELECT old_value, new_value, COUNT(*) AS rows FROM ("+candidates+") AS repair WHERE NOT conflict GROUP BY old_value, new_value ORDER BY rows DESC, old_value LIMIT ?", dataRepairPreviewLimit).
+			Scan(&previews).Error; err != nil {
+			return detail, nil, fmt.Errorf("%s.%s: %w", target.Table, target.Column, err)
+		}
+		for i := range previews {
+			previews[i].Entity = target.Table
+			previews[i].Column = target.Column
+		}
+	}
+	return detail, previews, nil
+}
+
+// applyDataRepairTarget changes the rows of the target in batches of ascending id, each batch in its own
+// transaction together with the backup of the changed values. A row is only changed while it still holds the
+// value read, rows changed meanwhile or rejected by a unique index are skipped. Stops after remaining rows.
+func applyDataRepairTarget(db *gorm.DB, run *models.DataRepairRun, target dataRepairTarget, remaining int) (models.DataRepairRunDetail, error) {
+	detail := models.DataRepairRunDetail{Entity: target.Table, Column: target.Column}
+	candidates := target.candidatesSQL()
+	update := fmt.Sprintf("UPDATE %[1]s SET %[2]s = ? WHERE id = ? AND %[2]s IS NOT DISTINCT FROM ?", target.Table, target.Column)
+
+	var lastID uint
+	for remaining != 0 {
+		limit := run.BatchSize
+		if remaining > 0 && remaining < limit {
+			limit = remaining
+		}
+
+		var batch []dataRepairCandidate
+		if err := db.Raw("SELECT id, old_value, new_value FROM ("+candidates+") AS repair WHERE NOT conflict AND id > ? ORDER BY id LIMIT ?", lastID, limit).
+			Scan(&batch).Error; err != nil {
+			return detail, fmt.Errorf("%s.%s: %w", target.Table, target.Column, err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		lastID = batch[len(batch)-1].ID
+
+		var changed []models.DataRepairChange
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, candidate := range batch {
+				// Each row in its own savepoint, a unique violation only skips the row
+				rowErr := tx.Transaction(func(rowTx *gorm.DB) error {
+					result := rowTx.Exec(update, candidate.NewValue, candidate.ID, candidate.OldValue)
+					if result.Error != nil {
+						return result.Error
+					}
+					if result.RowsAffected == 0 {
+						return gorm.ErrRecordNotFound
+					}
+					return nil
+				})
+				if rowErr != nil {
+					if !errors.Is(rowErr, gorm.ErrRecordNotFound) {
+						log.Printf("applyDataRepairTarget - Skipped %s %d: %v\n", target.Table, candidate.ID, rowErr)
+					}
+					continue
+				}
+				changed = append(changed, models.DataRepairChange{
+					DataRepairRunID: run.ID,
+					Entity:          target.Table,
+					Column:          target.Column,
+					RowID:           candidate.ID,
+					OldValue:        candidate.OldValue,
+					NewValue:        candidate.NewValue,
+				})
+			}
+			if len(changed) == 0 {
+				return nil
+			}
+			if err := tx.CreateInBatches(&changed, 500).Error; err != nil {
+				return err
+			}
+			return tx.Model(run).Updates(map[string]interface{}{
+				"batches":       gorm.Expr("batches + 1"),
+				"affected_rows": gorm.Expr("affected_rows + ?", len(changed)),
+			}).Error
+		})
+		if err != nil {
+			return detail, fmt.Errorf("%s.%s: %w", target.Table, target.Column, err)
+		}
+
+		detail.AffectedRows += int64(len(changed))
+		run.AffectedRows += int64(len(changed))
+		if len(changed) > 0 {
+			run.Batches++
+		}
+		if remaining > 0 {
+			remaining -= len(changed)
+		}
+	}
+
+	// Rows of unique columns left for manual review
+	if target.Unique {
+		if err := db.Raw("SELECT COUNT(*) FROM (" + candidates + ") AS repair WHERE conflict").Scan(&detail.ConflictRows).Error; err != nil {
+			return detail, fmt.Errorf("%s.%s: %w", target.Table, target.Column, err)
+		}
+	}
+	return detail, nil
+}
+
+// RunDataRepair runs a data repair rule and records the run. A dry run only counts the rows each column would change
+// and lists the most frequent value changes. Otherwise the rows are changed in batches, at most maxRows when set,
+// with a backup of every changed value so the run can be reverted. The run is recorded even when it failed.
+func RunDataRepair(db *gorm.DB, rule *DataRepairRule, dryRun bool, batchSize, maxRows int, triggeredBy *uint) (*models.DataRepairRun, []DataRepairPreview, error) {
+	run := models.DataRepairRun{
+		Rule:        rule.Name,
+		DryRun:      dryRun,
+		Status:      models.DataRepairStatusCompleted,
+		BatchSize:   batchSize,
+		MaxRows:     maxRows,
+		TriggeredBy: triggeredBy,
+	}
+
+	if dryRun {
+		var previews []DataRepairPreview
+		var err error
+		for _, target := range rule.targets {
+			detail, targetPreviews, targetErr := previewDataRepairTarget(db, target)
+			if targetErr != nil {
+				err = targetErr
+				break
+			}
+			run.Details = append(run.Details, detail)
+			run.AffectedRows += detail.AffectedRows
+			previews = append(previews, targetPreviews...)
+		}
+		if err != nil {
+			run.Status = models.DataRepairStatusFailed
+			run.Error = err.Error()
+		}
+		if auditErr := db.Create(&run).Error; auditErr != nil {
+			log.Println("RunDataRepair - Failed to record data repair run:", auditErr)
+			if err == nil {
+				err = auditErr
+			}
+		}
+		return &run, previews, err
+	}
+
+	// The run is recorded first, the backups of every batch reference it
+	run.Status = models.DataRepairStatusRunning
+	if err := db.Create(&run).Error; err != nil {
+		return nil, nil, err
+	}
+
+	remaining := -1
+	if maxRows > 0 {
+		remaining = maxRows
+	}
+	var err error
+	var details []models.DataRepairRunDetail
+	for _, target := range rule.targets {
+		if remaining == 0 {
+			break
+		}
+		detail, targetErr := applyDataRepairTarget(db, &run, target, remaining)
+		details = append(details, detail)
+		if remaining > 0 {
+			remaining -= int(detail.AffectedRows)
+		}
+		if targetErr != nil {
+			err = targetErr
+			break
+		}
+	}
+
+	run.Status = models.DataRepairStatusCompleted
+	if err != nil {
+		run.Status = models.DataRepairStatusFailed
+		run.Error = err.Error()
+	}
+	for i := range details {
+		details[i].DataRepairRunID = run.ID
+	}
+	if len(details) > 0 {
+		if auditErr := db.Create(&details).Error; auditErr != nil {
+			log.Println("RunDataRepair - Failed to record data repair run details:", auditErr)
+		}
+	}
+	run.Details = details
+	if auditErr := db.Model(&run).Updates(map[string]interface{}{"status": run.Status, "error": run.Error}).Error; auditErr != nil {
+		log.Println("RunDataRepair - Failed to record data repair run:", auditErr)
+		if err == nil {
+			err = auditErr
+		}
+	}
+	return &run, nil, err
+}
+
+// RevertDataRepair restores the backed up values of a data repair run in batches. A value changed again since the
+// run, or whose restore is rejected by a unique index, is kept and left unreverted. Returns the number of values
+// restored and kept.
+func RevertDataRepair(db *gorm.DB, run *models.DataRepairRun, batchSize int, revertedBy *uint) (int64, int64, error) {
+	rule, ok := FindDataRepairRule(run.Rule)
+	if !ok {
+		return 0, 0, fmt.Errorf("unknown data repair rule %s", run.Rule)
+	}
+
+	var reverted, kept int64
+	var lastID uint
+	for {
+		var changes []models.DataRepairChange
+		if err := db.Where("data_repair_run_id = ? AND reverted_at IS NULL AND id > ?", run.ID, lastID).
+			Order("id ASC").Limit(batchSize).Find(&changes).Error; err != nil {
+			return reverted, kept, err
+		}
+		if len(changes) == 0 {
+			break
+		}
+		lastID = changes[len(changes)-1].ID
+
+		now := time.Now()
+		var restored []uint
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, change := range changes {
+				target, ok := rule.target(change.Entity, change.Column)
+				if !ok {
+					kept++
+					continue
+				}
+				restore := fmt.Sprintf("UPDATE %[1]s SET %[2]s = ? WHERE id = ? AND %[2]s = ?", target.Table, target.Column)
+				rowErr := tx.Transaction(func(rowTx *gorm.DB) error {
+					result := rowTx.Exec(restore, change.OldValue, change.RowID, change.NewValue)
+					if result.Error != nil {
+						return result.Error
+					}
+					if result.RowsAffected == 0 {
+						return gorm.ErrRecordNotFound
+					}
+					return nil
+				})
+				if rowErr != nil {
+					if !errors.Is(rowErr, gorm.ErrRecordNotFound) {
+						log.Printf("RevertDataRepair - Kept %s %d: %v\n", target.Table, change.RowID, rowErr)
+					}
+					kept++
+					continue
+				}
+				restored = append(restored, change.ID)
+			}
+			if len(restored) == 0 {
+				return nil
+			}
+			return tx.Model(&models.DataRepairChange{}).Where("id IN ?", restored).Update("reverted_at", now).Error
+		})
+		if err != nil {
+			return reverted, kept, err
+		}
+		reverted += int64(len(restored))
+	}
+
+	now := time.Now()
+	if err := db.Model(run).Updates(map[string]interface{}{
+		"status":        models.DataRepairStatusReverted,
+		"reverted_rows": gorm.Expr("reverted_rows + ?", reverted),
+		"reverted_by":   revertedBy,
+		"reverted_at":   now,
+	}).Error; err != nil {
+		return reverted, kept, err
+	}
+	return reverted, kept, nil
+}
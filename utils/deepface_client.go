@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,9 +13,18 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// deepFaceClient propagates the trace context to the DeepFace service and records a span per call
+var deepFaceClient = &http.Client{
+	Timeout:   30 * time.Second,
+	Transport: otelhttp.NewTransport(http.DefaultTransport),
+}
+
 type RegisterResult struct {
 	Status string `json:"status"`
 	UserID string `json:"userId"`
@@ -32,7 +42,83 @@ type SearchResult struct {
 	Confidence float64 `json:"confidence"`
 }
 
-func SendToDeepFaceRegister(userID uint, imagePath string) error {
+// deepFaceHealthTTL is how long a DeepFace health check result is reused before checking again
+const deepFaceHealthTTL = 30 * time.Second
+
+// FaceProviderStatus is the last known health of the DeepFace service
+type FaceProviderStatus struct {
+	Available bool      `json:"available"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+var (
+	deepFaceHealthMu     sync.Mutex
+	deepFaceHealthStatus FaceProviderStatus
+)
+
+// CheckDeepFaceHealth returns the health of the DeepFace service, checking its /health endpoint
+// when the cached result is older than deepFaceHealthTTL
+func CheckDeepFaceHealth(ctx context.Context) FaceProviderStatus {
+	deepFaceHealthMu.Lock()
+	defer deepFaceHealthMu.Unlock()
+
+	if !deepFaceHealthStatus.CheckedAt.IsZero() && time.Since(deepFaceHealthStatus.CheckedAt) < deepFaceHealthTTL {
+		return deepFaceHealthStatus
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	status := FaceProviderStatus{Available: true, CheckedAt: time.Now()}
+	req, err := http.NewRequestWithContext(ctx, "GET", os.Getenv("DEEPFACE_URL")+"/health", nil)
+	if err == nil {
+		req.Header.Set("X-SERVICE-KEY", os.Getenv("DEEPFACE_SERVICE_KEY"))
+		var resp *http.Response
+		resp, err = deepFaceClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				err = fmt.Errorf("deepface health check returned status %d", resp.StatusCode)
+			}
+		}
+	}
+	if err != nil {
+		status.Available = false
+		status.Error = err.Error()
+	}
+
+	deepFaceHealthStatus = status
+	return status
+}
+
+// MarkDeepFaceUnavailable records a failed DeepFace call so the fallback applies without waiting for the next health check
+func MarkDeepFaceUnavailable(err error) {
+	deepFaceHealthMu.Lock()
+	defer deepFaceHealthMu.Unlock()
+
+	deepFaceHealthStatus = FaceProviderStatus{Available: false, Error: err.Error(), CheckedAt: time.Now()}
+}
+
+// doDeepFaceRequest sends a request to the DeepFace service, marking it unavailable when it cannot be reached or fails with a server error.
+// Staging fault rules of the request are applied first, injected faults do not mark the service unavailable for other requests.
+func doDeepFaceRequest(req *http.Request) (*http.Response, error) {
+	if err := InjectFault(req.Context(), FaultTargetDeepFace); err != nil {
+		return nil, err
+	}
+
+	resp, err := deepFaceClient.Do(req)
+	if err != nil {
+		MarkDeepFaceUnavailable(err)
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		MarkDeepFaceUnavailable(fmt.Errorf("deepface returned status %d", resp.StatusCode))
+	}
+	return resp, nil
+}
+
+func SendToDeepFaceRegister(ctx context.Context, userID uint, imagePath string) error {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
@@ -63,7 +149,7 @@ func SendToDeepFaceRegister(userID uint, imagePath string) error {
 
 	writer.Close()
 
-	req, err := http.NewRequest("POST", os.Getenv("DEEPFACE_URL")+"/register", body)
+	req, err := http.NewRequestWithContext(ctx, "POST", os.Getenv("DEEPFACE_URL")+"/register", body)
 	if err != nil {
 		return err
 	}
@@ -71,8 +157,7 @@ func SendToDeepFaceRegister(userID uint, imagePath string) error {
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("X-SERVICE-KEY", os.Getenv("DEEPFACE_SERVICE_KEY"))
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := doDeepFaceRequest(req)
 	if err != nil {
 		return err
 	}
@@ -86,7 +171,7 @@ func SendToDeepFaceRegister(userID uint, imagePath string) error {
 	return nil
 }
 
-func SendToDeepFaceVerify(userID uint, imagePath string) (*VerifyResult, error) {
+func SendToDeepFaceVerify(ctx context.Context, userID uint, imagePath string) (*VerifyResult, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
@@ -118,7 +203,8 @@ func SendToDeepFaceVerify(userID uint, imagePath string) (*VerifyResult, error)
 
 	writer.Close()
 
-	req, err := http.NewRequest(
+	req, err := http.NewRequestWithContext(
+		ctx,
 		"POST",
 		os.Getenv("DEEPFACE_URL")+"/verify",
 		body,
@@ -130,8 +216,7 @@ func SendToDeepFaceVerify(userID uint, imagePath string) (*VerifyResult, error)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("X-SERVICE-KEY", os.Getenv("DEEPFACE_SERVICE_KEY"))
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := doDeepFaceRequest(req)
 	if err != nil {
 		return nil, err
 	}
@@ -154,7 +239,7 @@ func SendToDeepFaceVerify(userID uint, imagePath string) (*VerifyResult, error)
 	return &result, nil
 }
 
-func SendToDeepFaceSearch(imagePath string) (*SearchResult, error) {
+func SendToDeepFaceSearch(ctx context.Context, imagePath string) (*SearchResult, error) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
 
@@ -182,7 +267,7 @@ func SendToDeepFaceSearch(imagePath string) (*SearchResult, error) {
 
 	writer.Close()
 
-	req, err := http.NewRequest("POST", os.Getenv("DEEPFACE_URL")+"/search", body)
+	req, err := http.NewRequestWithContext(ctx, "POST", os.Getenv("DEEPFACE_URL")+"/search", body)
 	if err != nil {
 		return nil, err
 	}
@@ -190,8 +275,7 @@ func SendToDeepFaceSearch(imagePath string) (*SearchResult, error) {
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 	req.Header.Set("X-SERVICE-KEY", os.Getenv("DEEPFACE_SERVICE_KEY"))
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := doDeepFaceRequest(req)
 	if err != nil {
 		return nil, err
 	}
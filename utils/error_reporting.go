@@ -0,0 +1,300 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"livo-fiber-backend/config"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// errorReportedLocal marks a request whose error was already reported, so the 5xx capture does not report it twice
+const errorReportedLocal = "errorReported"
+
+var errorReportingClient = &http.Client{Timeout: 10 * time.Second}
+
+// ErrorReporter sends errors to a Sentry compatible store endpoint (Sentry or GlitchTip)
+type ErrorReporter struct {
+	StoreURL    string  // https://host/api/<project>/store/
+	PublicKey   string  // key from the DSN
+	SecretKey   string  // legacy DSN secret, usually empty
+	Environment string  // environment tag of every event
+	Release     string  // release tag, empty when unknown
+	SampleRate  float64 // fraction of errors sent, 0-1
+	ServerName  string
+}
+
+var (
+	errorReporterMu sync.RWMutex
+	errorReporter   *ErrorReporter
+)
+
+// InitErrorReporting configures error reporting from the DSN. Without a DSN errors are only logged.
+func InitErrorReporting(cfg *config.Config) error {
+	if cfg.ErrorReportingDSN == "" {
+		return nil
+	}
+
+	reporter, err := ParseErrorReportingDSN(cfg.ErrorReportingDSN)
+	if err != nil {
+		return err
+	}
+	reporter.Environment = cfg.ErrorReportingEnvironment
+	if reporter.Environment == "" {
+		reporter.Environment = cfg.Env
+	}
+	reporter.Release = cfg.ErrorReportingRelease
+	reporter.SampleRate = cfg.ErrorReportingSampleRate
+	reporter.ServerName, _ = os.Hostname()
+
+	errorReporterMu.Lock()
+	defer errorReporterMu.Unlock()
+	errorReporter = reporter
+
+	log.Printf("✅ Error reporting enabled (endpoint: %s, environment: %s, sample rate: %g)", reporter.StoreURL, reporter.Environment, reporter.SampleRate)
+	return nil
+}
+
+// ParseErrorReportingDSN parses a DSN of the form https://<key>[:<secret>]@<host>[/<path>]/<project>
+func ParseErrorReportingDSN(dsn string) (*ErrorReporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid error reporting DSN: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Host == "" || parsed.User == nil || parsed.User.Username() == "" {
+		return nil, errors.New("invalid error reporting DSN, expected https://<key>@<host>/<project>")
+	}
+
+	path := strings.TrimSuffix(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	project := path[slash+1:]
+	if project == "" {
+		return nil, errors.New("invalid error reporting DSN, the project ID is missing")
+	}
+
+	secret, _ := parsed.User.Password()
+	return &ErrorReporter{
+		StoreURL:  fmt.Sprintf("%s://%s%s/api/%s/store/", parsed.Scheme, parsed.Host, path[:slash], project),
+		PublicKey: parsed.User.Username(),
+		SecretKey: secret,
+	}, nil
+}
+
+// getErrorReporter returns the configured reporter, nil when error reporting is disabled
+func getErrorReporter() *ErrorReporter {
+	errorReporterMu.RLock()
+	defer errorReporterMu.RUnlock()
+	return errorReporter
+}
+
+// CaptureError reports an error handled by a request, with the route, user and trace of the request
+func CaptureError(c fiber.Ctx, err error) {
+	if err == nil {
+		return
+	}
+	c.Locals(errorReportedLocal, true)
+	reporter := getErrorReporter()
+	if reporter == nil {
+		return
+	}
+	event := reporter.newRequestEvent(c, "error")
+	event["exception"] = map[string]interface{}{
+		"values": []map[string]interface{}{{
+			"type":       fmt.Sprintf("%T", err),
+			"value":      err.Error(),
+			"stacktrace": map[string]interface{}{"frames": stackFrames(3)},
+		}},
+	}
+	reporter.send(event)
+}
+
+// CaptureResponseError reports a request that ended in a server error without the error being reported by its
+// handler, with the error message the client got
+func CaptureResponseError(c fiber.Ctx, status int, message string) {
+	if reported, _ := c.Locals(errorReportedLocal).(bool); reported {
+		return
+	}
+	reporter := getErrorReporter()
+	if reporter == nil {
+		return
+	}
+	event := reporter.newRequestEvent(c, "error")
+	event["message"] = map[string]interface{}{"formatted": fmt.Sprintf("HTTP %d: %s", status, message)}
+	event["tags"].(map[string]string)["http.status_code"] = strconv.Itoa(status)
+	reporter.send(event)
+}
+
+// CapturePanic logs and reports a panic recovered from a request, used as the stack trace handler of the recover
+// middleware
+func CapturePanic(c fiber.Ctx, recovered any) {
+	log.Printf("panic: %v on %s %s\n", recovered, c.Method(), c.Path())
+	c.Locals(errorReportedLocal, true)
+	reporter := getErrorReporter()
+	if reporter == nil {
+		return
+	}
+	event := reporter.newRequestEvent(c, "fatal")
+	event["exception"] = map[string]interface{}{
+		"values": []map[string]interface{}{{
+			"type":       "panic",
+			"value":      fmt.Sprint(recovered),
+			"mechanism":  map[string]interface{}{"type": "recover", "handled": false},
+			"stacktrace": map[string]interface{}{"frames": stackFrames(3)},
+		}},
+	}
+	reporter.send(event)
+}
+
+// newRequestEvent builds an event with the request, the user and the trace it belongs to
+func (r *ErrorReporter) newRequestEvent(c fiber.Ctx, level string) map[string]interface{} {
+	eventID := make([]byte, 16)
+	rand.Read(eventID)
+
+	transaction := c.Method() + " " + c.Path()
+	if route := c.Route(); route != nil && route.Path != "" {
+		transaction = c.Method() + " " + route.Path
+	}
+
+	tags := map[string]string{"route": transaction}
+	contexts := map[string]interface{}{}
+	if traceID, spanID := requestTrace(c); traceID != "" {
+		tags["trace_id"] = traceID
+		traceContext := map[string]string{"trace_id": traceID}
+		if spanID != "" {
+			traceContext["span_id"] = spanID
+		}
+		contexts["trace"] = traceContext
+	}
+
+	user := map[string]string{"ip_address": c.IP()}
+	if userID, ok := c.Locals("userId").(string); ok {
+		user["id"] = userID
+	}
+	if username, ok := c.Locals("username").(string); ok {
+		user["username"] = username
+	}
+
+	event := map[string]interface{}{
+		"event_id":    hex.EncodeToString(eventID),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"level":       level,
+		"platform":    "go",
+		"logger":      TracerName,
+		"server_name": r.ServerName,
+		"environment": r.Environment,
+		"transaction": transaction,
+		"tags":        tags,
+		"contexts":    contexts,
+		"user":        user,
+		"request": map[string]interface{}{
+			"method":       c.Method(),
+			"url":          c.BaseURL() + c.Path(),
+			"query_string": string(c.Request().URI().QueryString()),
+			"headers":      map[string]string{"User-Agent": c.Get(fiber.HeaderUserAgent)},
+		},
+	}
+	if r.Release != "" {
+		event["release"] = r.Release
+	}
+	return event
+}
+
+// requestTrace returns the trace and span of the request, falling back to the X-Trace-Id response header once the
+// tracing middleware has ended its span (panics are recovered outside of it)
+func requestTrace(c fiber.Ctx) (string, string) {
+	if spanContext := trace.SpanContextFromContext(c.Context()); spanContext.HasTraceID() {
+		return spanContext.TraceID().String(), spanContext.SpanID().String()
+	}
+	return c.GetRespHeader("X-Trace-Id"), ""
+}
+
+// stackFrames returns the calling goroutine's stack in the order Sentry expects, oldest call first,
+// skipping the reporting functions
+func stackFrames(skip int) []map[string]interface{} {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []map[string]interface{}
+	for {
+		frame, more := frames.Next()
+		stack = append([]map[string]interface{}{{
+			"function": frame.Function,
+			"abs_path": frame.File,
+			"filename": frame.File,
+			"lineno":   frame.Line,
+			"in_app":   strings.HasPrefix(frame.Function, "livo-fiber-backend/"),
+		}}, stack...)
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// send posts the event in the background when it is sampled
+func (r *ErrorReporter) send(event map[string]interface{}) {
+	if r.SampleRate < 1 {
+		sample, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+		if err != nil || float64(sample.Int64())/1_000_000 >= r.SampleRate {
+			return
+		}
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Println("ErrorReporter - Failed to encode event:", err)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), errorReportingClient.Timeout)
+		defer cancel()
+		if err := r.post(ctx, payload); err != nil {
+			log.Println("ErrorReporter - Failed to send event:", err)
+		}
+	}()
+}
+
+// post sends an encoded event to the store endpoint
+func (r *ErrorReporter) post(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", r.StoreURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	auth := "Sentry sentry_version=7, sentry_client=" + TracerName + "/1.0, sentry_key=" + r.PublicKey
+	if r.SecretKey != "" {
+		auth += ", sentry_secret=" + r.SecretKey
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", auth)
+
+	resp, err := errorReportingClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach error reporting endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("error reporting endpoint returned %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
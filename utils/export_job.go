@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"livo-fiber-backend/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// exportJobTimeout bounds the time an export job may spend generating its file
+const exportJobTimeout = 15 * time.Minute
+
+// ExportResult is the file generated by an export job
+type ExportResult struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+	RowCount    int
+}
+
+// ExportBuilder generates the file of an export job
+type ExportBuilder func(ctx context.Context, db *gorm.DB) (*ExportResult, error)
+
+// StartExportJob records a pending export job and generates its file in the background,
+// the requesting user is notified once the file is ready or the export failed
+func StartExportJob(db *gorm.DB, exportType string, filters map[string]string, requestedBy uint, build ExportBuilder) (*models.ExportJob, error) {
+	encoded, err := json.Marshal(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	job := models.ExportJob{
+		ExportType:  exportType,
+		Filters:     string(encoded),
+		Status:      models.ExportJobStatusPending,
+		RequestedBy: requestedBy,
+	}
+	if err := db.Create(&job).Error; err != nil {
+		return nil, err
+	}
+
+	go runExportJob(db, job, build)
+	return &job, nil
+}
+
+// runExportJob generates the file of an export job and stores the outcome
+func runExportJob(db *gorm.DB, job models.ExportJob, build ExportBuilder) {
+	result, err := func() (result *ExportResult, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("export panicked: %v", r)
+			}
+		}()
+
+		if err := db.Model(&models.ExportJob{}).Where("id = ?", job.ID).
+			Updates(map[string]interface{}{"status": models.ExportJobStatusRunning, "started_at": time.Now()}).Error; err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), exportJobTimeout)
+		defer cancel()
+		return build(ctx, db)
+	}()
+
+	// Keep the generated file in the file storage
+	storageKey := ""
+	if err == nil {
+		storageKey = fmt.Sprintf("exports/%d/%s", job.ID, result.FileName)
+		ctx, cancel := context.WithTimeout(context.Background(), exportJobTimeout)
+		if putErr := GetStorage().Put(ctx, storageKey, result.Content, result.ContentType); putErr != nil {
+			err = fmt.Errorf("failed to store export file: %w", putErr)
+		}
+		cancel()
+	}
+
+	if err != nil {
+		log.Println("runExportJob - Export job", job.ID, "failed:", err)
+		if updateErr := db.Model(&models.ExportJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"status":       models.ExportJobStatusFailed,
+			"error":        err.Error(),
+			"completed_at": time.Now(),
+		}).Error; updateErr != nil {
+			log.Println("runExportJob - Failed to record export job failure:", updateErr)
+		}
+		if notifyErr := NotifyUsers(db, []uint{job.RequestedBy}, "export_failed", "Export failed",
+			fmt.Sprintf("Your %s export could not be generated: %s", job.ExportType, err.Error()), "export_job", job.ID); notifyErr != nil {
+			log.Println("runExportJob - Failed to notify user:", notifyErr)
+		}
+		return
+	}
+
+	if err := db.Model(&models.ExportJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":       models.ExportJobStatusCompleted,
+		"file_name":    result.FileName,
+		"content_type": result.ContentType,
+		"storage_key":  storageKey,
+		"row_count":    result.RowCount,
+		"completed_at": time.Now(),
+	}).Error; err != nil {
+		log.Println("runExportJob - Failed to store export job", job.ID, ":", err)
+		return
+	}
+	if err := NotifyUsers(db, []uint{job.RequestedBy}, "export_ready", "Export ready",
+		fmt.Sprintf("Your %s export %s is ready to download", job.ExportType, result.FileName), "export_job", job.ID); err != nil {
+		log.Println("runExportJob - Failed to notify user:", err)
+	}
+}
+
+// FailStaleExportJobs marks the export jobs pending or running for longer than the export timeout as failed,
+// they were interrupted by a restart of the process generating them
+func FailStaleExportJobs(db *gorm.DB) error {
+	return db.Model(&models.ExportJob{}).
+		Where("status IN ? AND updated_at < ?", []string{models.ExportJobStatusPending, models.ExportJobStatusRunning}, time.Now().Add(-exportJobTimeout)).
+		Updates(map[string]interface{}{
+			"status":       models.ExportJobStatusFailed,
+			"error":        "Export was interrupted, request it again",
+			"completed_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"context"
+	"livo-fiber-backend/config"
+	"sync"
+)
+
+// FaceBatchPolicy holds the limits of batch face check-ins at the kiosk
+type FaceBatchPolicy struct {
+	MaxImages   int // images accepted per batch
+	Concurrency int // face searches sent to DeepFace at once
+}
+
+// FaceBatchPolicyFromConfig builds the face batch policy from the application config
+func FaceBatchPolicyFromConfig(cfg *config.Config) FaceBatchPolicy {
+	return FaceBatchPolicy{
+		MaxImages:   cfg.FaceBatchMaxImages,
+		Concurrency: cfg.FaceBatchConcurrency,
+	}
+}
+
+// FaceSearchOutcome is the DeepFace search result of one image of a batch
+type FaceSearchOutcome struct {
+	Result *SearchResult
+	Err    error
+}
+
+// SearchFaces searches the faces of the images with at most Concurrency searches in flight.
+// Outcomes are returned in the order of the images, an empty path is skipped and left without outcome.
+func (p FaceBatchPolicy) SearchFaces(ctx context.Context, imagePaths []string) []FaceSearchOutcome {
+	concurrency := p.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	outcomes := make([]FaceSearchOutcome, len(imagePaths))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, imagePath := range imagePaths {
+		if imagePath == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, imagePath string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			result, err := SendToDeepFaceSearch(ctx, imagePath)
+			outcomes[i] = FaceSearchOutcome{Result: result, Err: err}
+		}(i, imagePath)
+	}
+	wg.Wait()
+	return outcomes
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+)
+
+// FaceThresholdPolicy holds the minimum confidence of face matches accepted for attendance
+type FaceThresholdPolicy struct {
+	MinConfidence    float64 // global minimum, a location can set its own
+	ReviewConfidence float64 // accepted matches below it are listed for review
+}
+
+// FaceThresholdPolicyFromConfig builds the face threshold policy from the application config
+func FaceThresholdPolicyFromConfig(cfg *config.Config) FaceThresholdPolicy {
+	return FaceThresholdPolicy{
+		MinConfidence:    cfg.FaceMinConfidence,
+		ReviewConfidence: cfg.FaceReviewConfidence,
+	}
+}
+
+// Threshold returns the minimum face match confidence accepted at the location
+func (p FaceThresholdPolicy) Threshold(location *models.Location) float64 {
+	if location != nil && location.MinFaceConfidence != nil {
+		return *location.MinFaceConfidence
+	}
+	return p.MinConfidence
+}
+
+// Check returns the threshold applied at the location and an error when the confidence is below it
+func (p FaceThresholdPolicy) Check(confidence float64, location *models.Location) (float64, error) {
+	threshold := p.Threshold(location)
+	if confidence < threshold {
+		return threshold, fmt.Errorf("face match confidence %.2f is below the required %.2f", confidence, threshold)
+	}
+	return threshold, nil
+}
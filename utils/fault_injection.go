@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault injection targets, the dependency a fault is injected into
+const (
+	FaultTargetDB       = "db"
+	FaultTargetDeepFace = "deepface"
+)
+
+// FaultInjectionEnv is the only environment fault injection can be enabled in
+const FaultInjectionEnv = "staging"
+
+// ErrInjectedFault is returned in place of the result of a call a fault was injected into
+var ErrInjectedFault = errors.New("injected fault")
+
+// FaultRule injects latency and errors into a dependency for the requests of a route
+type FaultRule struct {
+	Route        string  `json:"route" example:"POST /api/mobile-orders"` // method and path prefix, or a path prefix for any method, "*" matches every route
+	Target       string  `json:"target" example:"db"`                     // db or deepface
+	LatencyMs    int     `json:"latencyMs" example:"1500"`                // added before the call
+	ErrorRate    float64 `json:"errorRate" example:"0.25"`                // fraction of calls that fail, 0-1
+	AfterHandler bool    `json:"afterHandler"`                            // db only, fail after the handler ran so its writes are committed but the client sees an error
+}
+
+// Matches reports whether the rule applies to the request
+func (r FaultRule) Matches(method, path string) bool {
+	if r.Route == "*" {
+		return true
+	}
+	route := r.Route
+	if ruleMethod, rulePath, found := strings.Cut(route, " "); found {
+		if !strings.EqualFold(ruleMethod, method) {
+			return false
+		}
+		route = rulePath
+	}
+	return strings.HasPrefix(path, strings.TrimSpace(route))
+}
+
+// Validate checks the rule is well formed
+func (r FaultRule) Validate() error {
+	if strings.TrimSpace(r.Route) == "" {
+		return errors.New("route is required")
+	}
+	if r.Target != FaultTargetDB && r.Target != FaultTargetDeepFace {
+		return fmt.Errorf("unknown target %q, use %s or %s", r.Target, FaultTargetDB, FaultTargetDeepFace)
+	}
+	if r.LatencyMs < 0 {
+		return errors.New("latencyMs must not be negative")
+	}
+	if r.ErrorRate < 0 || r.ErrorRate > 1 {
+		return errors.New("errorRate must be between 0 and 1")
+	}
+	if r.AfterHandler && r.Target != FaultTargetDB {
+		return errors.New("afterHandler only applies to the db target")
+	}
+	return nil
+}
+
+// Latency returns the latency the rule adds
+func (r FaultRule) Latency() time.Duration {
+	return time.Duration(r.LatencyMs) * time.Millisecond
+}
+
+// Fail reports whether this call fails, drawn from the error rate of the rule
+func (r FaultRule) Fail() bool {
+	return r.ErrorRate > 0 && rand.Float64() < r.ErrorRate
+}
+
+// FaultInjectionState describes whether faults are injected and by which rules
+type FaultInjectionState struct {
+	Enabled   bool        `json:"enabled"`
+	Rules     []FaultRule `json:"rules"`
+	UpdatedBy string      `json:"updatedBy,omitempty"`
+	UpdatedAt *time.Time  `json:"updatedAt,omitempty"`
+}
+
+var (
+	faultInjectionMu      sync.RWMutex
+	faultInjectionAllowed bool
+	faultInjectionState   = FaultInjectionState{Rules: []FaultRule{}}
+)
+
+// InitFaultInjection loads the fault rules of the configuration. Fault injection stays off outside staging
+// even when enabled in the configuration.
+func InitFaultInjection(cfg *config.Config) error {
+	if !cfg.FaultInjectionEnabled {
+		return nil
+	}
+	if cfg.Env != FaultInjectionEnv {
+		log.Printf("⚠️ Fault injection is only available in %s, ignored in %s", FaultInjectionEnv, cfg.Env)
+		return nil
+	}
+
+	rules := []FaultRule{}
+	if strings.TrimSpace(cfg.FaultInjectionRules) != "" {
+		if err := json.Unmarshal([]byte(cfg.FaultInjectionRules), &rules); err != nil {
+			return fmt.Errorf("invalid FAULT_INJECTION_RULES: %w", err)
+		}
+	}
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid fault rule %d: %w", i+1, err)
+		}
+	}
+
+	faultInjectionMu.Lock()
+	defer faultInjectionMu.Unlock()
+	faultInjectionAllowed = true
+	faultInjectionState = FaultInjectionState{Enabled: len(rules) > 0, Rules: rules, UpdatedBy: "config"}
+
+	log.Printf("⚠️ Fault injection available (%d rules)", len(rules))
+	return nil
+}
+
+// FaultInjectionAvailable reports whether fault injection may be switched on in this environment
+func FaultInjectionAvailable() bool {
+	faultInjectionMu.RLock()
+	defer faultInjectionMu.RUnlock()
+	return faultInjectionAllowed
+}
+
+// SetFaultInjection replaces the fault rules at runtime, an empty list or disabled stops injecting faults
+func SetFaultInjection(enabled bool, rules []FaultRule, updatedBy string) FaultInjectionState {
+	faultInjectionMu.Lock()
+	defer faultInjectionMu.Unlock()
+
+	if rules == nil {
+		rules = []FaultRule{}
+	}
+	now := time.Now()
+	faultInjectionState = FaultInjectionState{
+		Enabled:   enabled && len(rules) > 0,
+		Rules:     rules,
+		UpdatedBy: updatedBy,
+		UpdatedAt: &now,
+	}
+	return faultInjectionState
+}
+
+// GetFaultInjection returns the current fault injection state
+func GetFaultInjection() FaultInjectionState {
+	faultInjectionMu.RLock()
+	defer faultInjectionMu.RUnlock()
+	return faultInjectionState
+}
+
+// MatchFaultRules returns the rules that apply to the request, none while fault injection is off
+func MatchFaultRules(method, path string) []FaultRule {
+	faultInjectionMu.RLock()
+	defer faultInjectionMu.RUnlock()
+
+	if !faultInjectionAllowed || !faultInjectionState.Enabled {
+		return nil
+	}
+	var matched []FaultRule
+	for _, rule := range faultInjectionState.Rules {
+		if rule.Matches(method, path) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+type faultRulesContextKey struct{}
+
+// WithFaultRules returns a copy of ctx carrying the fault rules of the request
+func WithFaultRules(ctx context.Context, rules []FaultRule) context.Context {
+	return context.WithValue(ctx, faultRulesContextKey{}, rules)
+}
+
+// InjectFault applies the rules of ctx for the target: it waits for their latency, then returns ErrInjectedFault
+// when one of them fails the call. Returns nil right away when ctx carries no rule for the target.
+func InjectFault(ctx context.Context, target string) error {
+	if ctx == nil {
+		return nil
+	}
+	rules, _ := ctx.Value(faultRulesContextKey{}).([]FaultRule)
+	for _, rule := range rules {
+		if rule.Target != target {
+			continue
+		}
+		if latency := rule.Latency(); latency > 0 {
+			timer := time.NewTimer(latency)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if !rule.AfterHandler && rule.Fail() {
+			return fmt.Errorf("%w: %s", ErrInjectedFault, target)
+		}
+	}
+	return nil
+}
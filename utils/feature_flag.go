@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"hash/fnv"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// FeatureSubject is who a feature flag is evaluated for
+type FeatureSubject struct {
+	UserID uint     // buckets the user into the percentage rollout, 0 for system jobs
+	Roles  []string // roles of the user, none for system jobs
+}
+
+// FeatureSubjectFromContext returns the logged in user of a request as a feature flag subject
+func FeatureSubjectFromContext(c fiber.Ctx) FeatureSubject {
+	var subject FeatureSubject
+	if userIDStr, ok := c.Locals("userId").(string); ok {
+		userID, _ := strconv.ParseUint(userIDStr, 10, 32)
+		subject.UserID = uint(userID)
+	}
+	subject.Roles, _ = c.Locals("userRoles").([]string)
+	return subject
+}
+
+var (
+	featureFlagMu        sync.RWMutex
+	featureFlagEnv       string
+	featureFlagTTL       time.Duration
+	featureFlagCache     map[string]models.FeatureFlag
+	featureFlagExpiresAt time.Time
+)
+
+// InitFeatureFlags sets the environment flags are evaluated in and how long the flags are cached
+func InitFeatureFlags(cfg *config.Config) {
+	featureFlagMu.Lock()
+	defer featureFlagMu.Unlock()
+	featureFlagEnv = cfg.Env
+	featureFlagTTL = time.Duration(cfg.FeatureFlagCacheSeconds) * time.Second
+	featureFlagCache = nil
+}
+
+// InvalidateFeatureFlags drops the cached flags so changes apply on the next evaluation
+func InvalidateFeatureFlags() {
+	featureFlagMu.Lock()
+	defer featureFlagMu.Unlock()
+	featureFlagCache = nil
+}
+
+// loadFeatureFlags returns the flags by key, read from the database when the cache expired
+func loadFeatureFlags(db *gorm.DB) (map[string]models.FeatureFlag, error) {
+	featureFlagMu.RLock()
+	if featureFlagCache != nil && time.Now().Before(featureFlagExpiresAt) {
+		flags := featureFlagCache
+		featureFlagMu.RUnlock()
+		return flags, nil
+	}
+	featureFlagMu.RUnlock()
+
+	var list []models.FeatureFlag
+	if err := db.Find(&list).Error; err != nil {
+		return nil, err
+	}
+	flags := make(map[string]models.FeatureFlag, len(list))
+	for _, flag := range list {
+		flags[flag.FlagKey] = flag
+	}
+
+	featureFlagMu.Lock()
+	defer featureFlagMu.Unlock()
+	featureFlagCache = flags
+	featureFlagExpiresAt = time.Now().Add(featureFlagTTL)
+	return flags, nil
+}
+
+// FeatureEnabled reports whether the feature is on for the subject. Features without a flag, or whose flags
+// cannot be loaded, fall back to the given default so existing behaviour holds until a flag is set up.
+func FeatureEnabled(db *gorm.DB, key string, subject FeatureSubject, fallback bool) bool {
+	flags, err := loadFeatureFlags(db)
+	if err != nil {
+		log.Printf("FeatureEnabled - Failed to load feature flags, using default for %s: %v\n", key, err)
+		return fallback
+	}
+	flag, ok := flags[key]
+	if !ok {
+		return fallback
+	}
+
+	featureFlagMu.RLock()
+	env := featureFlagEnv
+	featureFlagMu.RUnlock()
+	return EvaluateFeatureFlag(flag, env, subject)
+}
+
+// EvaluateFeatureFlag reports whether the flag is on in the environment for the subject
+func EvaluateFeatureFlag(flag models.FeatureFlag, env string, subject FeatureSubject) bool {
+	if !flag.Enabled {
+		return false
+	}
+	if environments := flag.EnvironmentList(); len(environments) > 0 && !slices.Contains(environments, env) {
+		return false
+	}
+	if roles := flag.RoleList(); len(roles) > 0 && !slices.ContainsFunc(subject.Roles, func(role string) bool {
+		return slices.Contains(roles, role)
+	}) {
+		return false
+	}
+	return featureRolloutBucket(flag.FlagKey, subject.UserID) < flag.RolloutPercentage
+}
+
+// featureRolloutBucket places a user in 0..99 per flag, so a user stays in or out of a rollout as it grows and
+// different flags roll out to different users
+func featureRolloutBucket(key string, userID uint) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key + ":" + strconv.FormatUint(uint64(userID), 10)))
+	return int(hash.Sum32() % 100)
+}
+
+// EnabledFeatures returns every flag with whether it is on for the subject
+func EnabledFeatures(db *gorm.DB, subject FeatureSubject) (map[string]bool, error) {
+	flags, err := loadFeatureFlags(db)
+	if err != nil {
+		return nil, err
+	}
+
+	featureFlagMu.RLock()
+	env := featureFlagEnv
+	featureFlagMu.RUnlock()
+
+	features := make(map[string]bool, len(flags))
+	for key, flag := range flags {
+		features[key] = EvaluateFeatureFlag(flag, env, subject)
+	}
+	return features, nil
+}
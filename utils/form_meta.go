@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formDatetimeReference is the moment datetime examples are formatted at, so clients see what a valid value looks like
+var formDatetimeReference = time.Date(2026, time.January, 31, 17, 0, 0, 0, time.UTC)
+
+// FormField describes the validation constraints of a request field, derived from its validate tag
+type FormField struct {
+	Name            string      `json:"name,omitempty"` // JSON name of the field, empty for array items
+	Type            string      `json:"type"`           // string, integer, number, boolean, array or object
+	Required        bool        `json:"required"`
+	MinLength       *int        `json:"minLength,omitempty"` // strings, in characters
+	MaxLength       *int        `json:"maxLength,omitempty"`
+	Length          *int        `json:"length,omitempty"` // exact length of a string or size of an array
+	Minimum         *float64    `json:"minimum,omitempty"`
+	Maximum         *float64    `json:"maximum,omitempty"`
+	ExclusiveMin    *float64    `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMax    *float64    `json:"exclusiveMaximum,omitempty"`
+	MinItems        *int        `json:"minItems,omitempty"` // arrays
+	MaxItems        *int        `json:"maxItems,omitempty"`
+	Format          string      `json:"format,omitempty"`         // email, url, uuid or datetime
+	DatetimeLayout  string      `json:"datetimeLayout,omitempty"` // Go layout of a datetime field
+	DatetimeExample string      `json:"datetimeExample,omitempty"`
+	Enum            []string    `json:"enum,omitempty"`
+	Example         string      `json:"example,omitempty"`
+	Items           *FormField  `json:"items,omitempty"`  // element constraints of an array
+	Fields          []FormField `json:"fields,omitempty"` // fields of an object or of array elements that are objects
+}
+
+// DescribeForm returns the field constraints of a request struct from its json and validate tags
+func DescribeForm(request interface{}) []FormField {
+	return describeFormStruct(reflect.TypeOf(request))
+}
+
+func describeFormStruct(t reflect.Type) []FormField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []FormField
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		// Embedded structs are flattened like encoding/json does
+		if structField.Anonymous && structField.Tag.Get("json") == "" {
+			fields = append(fields, describeFormStruct(structField.Type)...)
+			continue
+		}
+
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+
+		field := describeFormType(structField.Type)
+		field.Name = name
+		field.Example = structField.Tag.Get("example")
+		applyFormRules(&field, structField.Type, strings.Split(structField.Tag.Get("validate"), ","))
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// describeFormType returns the JSON type of a Go type, with the fields of structs and the items of slices
+func describeFormType(t reflect.Type) FormField {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var field FormField
+	switch t.Kind() {
+	case reflect.String:
+		field.Type = "string"
+	case reflect.Bool:
+		field.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.Type = "integer"
+	case reflect.Float32, reflect.Float64:
+		field.Type = "number"
+	case reflect.Slice, reflect.Array:
+		field.Type = "array"
+		items := describeFormType(t.Elem())
+		field.Items = &items
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			field.Type = "string"
+			field.Format = "datetime"
+			field.DatetimeLayout = time.RFC3339
+			field.DatetimeExample = formDatetimeReference.Format(time.RFC3339)
+		} else {
+			field.Type = "object"
+			field.Fields = describeFormStruct(t)
+		}
+	default:
+		field.Type = "object"
+	}
+	return field
+}
+
+// applyFormRules sets the constraints of validate rules on a field. Rules after dive apply to the array items.
+func applyFormRules(field *FormField, t reflect.Type, rules []string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			field.Required = true
+		case "dive":
+			if field.Items != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				applyFormRules(field.Items, t.Elem(), rules[i+1:])
+			}
+			return
+		case "min", "max", "len":
+			applyFormSize(field, name, param)
+		case "gt", "gte", "lt", "lte":
+			if value, err := strconv.ParseFloat(param, 64); err == nil {
+				switch name {
+				case "gt":
+					field.ExclusiveMin = &value
+				case "gte":
+					field.Minimum = &value
+				case "lt":
+					field.ExclusiveMax = &value
+				case "lte":
+					field.Maximum = &value
+				}
+			}
+		case "oneof":
+			field.Enum = strings.Fields(param)
+		case "email", "url", "uuid":
+			field.Format = name
+		case "datetime":
+			field.Format = "datetime"
+			field.DatetimeLayout = param
+			field.DatetimeExample = formDatetimeReference.Format(param)
+		}
+	}
+}
+
+// applyFormSize sets a min, max or len rule, which limits the length of strings, the size of arrays and the
+// value of numbers
+func applyFormSize(field *FormField, name, param string) {
+	switch field.Type {
+	case "string", "array":
+		size, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		switch {
+		case name == "len":
+			field.Length = &size
+		case field.Type == "string" && name == "min":
+			field.MinLength = &size
+		case field.Type == "string":
+			field.MaxLength = &size
+		case name == "min":
+			field.MinItems = &size
+		default:
+			field.MaxItems = &size
+		}
+	case "integer", "number":
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		switch name {
+		case "min":
+			field.Minimum = &value
+		case "max":
+			field.Maximum = &value
+		default:
+			field.Minimum = &value
+			field.Maximum = &value
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Fraud score thresholds
+// Records at or above FraudSuspiciousScore are flagged for HR review,
+// records at or above FraudRejectScore are rejected outright.
+const (
+	FraudSuspiciousScore = 40
+	FraudRejectScore     = 100
+)
+
+// DeviceSignals represents the anti-fraud signals reported by the mobile device
+type DeviceSignals struct {
+	IsMockLocation bool
+	DeviceID       string
+	OSBuild        string
+	// Why the device clock looks wrong, empty when the client timestamp is plausible
+	ClockSkewReason string
+}
+
+// PreviousCheck represents a previous attendance check used as a baseline for heuristics
+type PreviousCheck struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64
+	DeviceID  string
+	CheckedAt time.Time
+}
+
+// FraudAssessment represents the result of combining all fraud heuristics
+type FraudAssessment struct {
+	Score   int
+	Reasons []string
+}
+
+// Suspicious returns true if the assessment should be reviewed by HR
+func (fa FraudAssessment) Suspicious() bool {
+	return fa.Score >= FraudSuspiciousScore
+}
+
+// Rejected returns true if the assessment is too risky to be accepted
+func (fa FraudAssessment) Rejected() bool {
+	return fa.Score >= FraudRejectScore
+}
+
+// CalculateFraudScore combines device signals with the GPS heuristics into a single fraud score
+// previous must be ordered from the most recent check
+func CalculateFraudScore(latitude, longitude, accuracy float64, signals DeviceSignals, previous []PreviousCheck) FraudAssessment {
+	var assessment FraudAssessment
+	add := func(score int, reason string) {
+		assessment.Score += score
+		assessment.Reasons = append(assessment.Reasons, reason)
+	}
+
+	// 1. Device reported mock location
+	if signals.IsMockLocation {
+		add(60, "Device reported mock location enabled")
+	}
+
+	// 2. Missing device identifier
+	if signals.DeviceID == "" {
+		add(10, "Device ID not reported")
+	}
+
+	// 3. Device clock disagrees with the server
+	if signals.ClockSkewReason != "" {
+		add(20, signals.ClockSkewReason)
+	}
+
+	if len(previous) > 0 {
+		last := previous[0]
+
+		// 4. Sudden accuracy jumps (more than 50 meters)
+		if accuracyDiff := math.Abs(accuracy - last.Accuracy); accuracyDiff > 50 {
+			add(30, fmt.Sprintf("Accuracy suddenly changed from %.1f to %.1f meters", last.Accuracy, accuracy))
+		}
+
+		// 5. Impossible speed (more than 50 m/s or 180 km/h) within the last hour
+		timeDiff := time.Since(last.CheckedAt).Seconds()
+		if timeDiff < 3600 && timeDiff > 60 {
+			speed := CalculateDistance(latitude, longitude, last.Latitude, last.Longitude) / timeDiff
+			if speed > 50 {
+				add(50, fmt.Sprintf("Impossible travel speed (%.2f km/h)", speed*3.6))
+			}
+		}
+
+		// 6. Device changed since last check
+		if signals.DeviceID != "" && last.DeviceID != "" && signals.DeviceID != last.DeviceID {
+			add(20, "Device ID differs from previous check")
+		}
+	}
+
+	// 7. Fixed accuracy (always the same value)
+	if len(previous) >= 3 && accuracy > 0 {
+		allSame := true
+		for _, check := range previous[:3] {
+			if check.Accuracy != accuracy {
+				allSame = false
+				break
+			}
+		}
+		if allSame {
+			add(30, "Accuracy values are suspiciously consistent")
+		}
+	}
+
+	return assessment
+}
@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalculateFraudScore(t *testing.T) {
+	const latitude, longitude = -7.9666, 112.6326 // warehouse in Malang
+	now := time.Now()
+
+	// check returns a previous check at the warehouse, the given time ago
+	check := func(ago time.Duration, accuracy float64) PreviousCheck {
+		return PreviousCheck{Latitude: latitude, Longitude: longitude, Accuracy: accuracy, DeviceID: "device-1", CheckedAt: now.Add(-ago)}
+	}
+	// far returns the check moved about 55 km north
+	far := func(c PreviousCheck) PreviousCheck {
+		c.Latitude += 0.5
+		return c
+	}
+	device := DeviceSignals{DeviceID: "device-1"}
+
+	tests := []struct {
+		name      string
+		accuracy  float64
+		signals   DeviceSignals
+		previous  []PreviousCheck
+		wantScore int
+	}{
+		{"clean check", 12, device, []PreviousCheck{check(30*time.Minute, 10)}, 0},
+		{"first check without history", 12, device, nil, 0},
+		{"mock location", 12, DeviceSignals{DeviceID: "device-1", IsMockLocation: true}, nil, 60},
+		{"missing device id", 12, DeviceSignals{}, nil, 10},
+		{"device clock skewed", 12, DeviceSignals{DeviceID: "device-1", ClockSkewReason: "Device clock is 15 minutes ahead"}, nil, 20},
+		{"accuracy jump over 50 meters", 70, device, []PreviousCheck{check(30*time.Minute, 10)}, 30},
+		{"accuracy jump of exactly 50 meters", 60, device, []PreviousCheck{check(30*time.Minute, 10)}, 0},
+		{"impossible speed", 12, device, []PreviousCheck{far(check(10*time.Minute, 10))}, 50},
+		{"distant check over an hour ago", 12, device, []PreviousCheck{far(check(2*time.Hour, 10))}, 0},
+		{"distant check within a minute is not timed", 12, device, []PreviousCheck{far(check(30*time.Second, 10))}, 0},
+		{"device changed", 12, DeviceSignals{DeviceID: "device-2"}, []PreviousCheck{check(30*time.Minute, 10)}, 20},
+		{"fixed accuracy", 12, device, []PreviousCheck{check(30*time.Minute, 12), check(2*time.Hour, 12), check(24*time.Hour, 12)}, 30},
+		{"fixed accuracy needs three checks", 12, device, []PreviousCheck{check(30*time.Minute, 12), check(2*time.Hour, 12)}, 0},
+		{"zero accuracy is not fixed", 0, device, []PreviousCheck{check(30*time.Minute, 0), check(2*time.Hour, 0), check(24*time.Hour, 0)}, 0},
+		{"signals add up", 70, DeviceSignals{IsMockLocation: true}, []PreviousCheck{far(check(10*time.Minute, 10))}, 60 + 10 + 30 + 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assessment := CalculateFraudScore(latitude, longitude, tt.accuracy, tt.signals, tt.previous)
+			if assessment.Score != tt.wantScore {
+				t.Errorf("score = %d, want %d (reasons: %v)", assessment.Score, tt.wantScore, assessment.Reasons)
+			}
+			if (assessment.Score == 0) != (len(assessment.Reasons) == 0) {
+				t.Errorf("score %d with reasons %v", assessment.Score, assessment.Reasons)
+			}
+		})
+	}
+}
+
+func TestFraudAssessmentThresholds(t *testing.T) {
+	tests := []struct {
+		score          int
+		wantSuspicious bool
+		wantRejected   bool
+	}{
+		{0, false, false},
+		{FraudSuspiciousScore - 1, false, false},
+		{FraudSuspiciousScore, true, false},
+		{FraudRejectScore - 1, true, false},
+		{FraudRejectScore, true, true},
+		{FraudRejectScore + 50, true, true},
+	}
+
+	for _, tt := range tests {
+		assessment := FraudAssessment{Score: tt.score}
+		if got := assessment.Suspicious(); got != tt.wantSuspicious {
+			t.Errorf("score %d suspicious = %v, want %v", tt.score, got, tt.wantSuspicious)
+		}
+		if got := assessment.Rejected(); got != tt.wantRejected {
+			t.Errorf("score %d rejected = %v, want %v", tt.score, got, tt.wantRejected)
+		}
+	}
+
+	// Mock location alone is reviewed, combined with impossible travel it is rejected
+	mock := CalculateFraudScore(0, 0, 10, DeviceSignals{DeviceID: "device-1", IsMockLocation: true}, nil)
+	if !mock.Suspicious() || mock.Rejected() {
+		t.Errorf("mock location scored %d, want suspicious but not rejected", mock.Score)
+	}
+	travelled := CalculateFraudScore(0.5, 0, 10, DeviceSignals{DeviceID: "device-1", IsMockLocation: true},
+		[]PreviousCheck{{Accuracy: 10, DeviceID: "device-1", CheckedAt: time.Now().Add(-10 * time.Minute)}})
+	if !travelled.Rejected() {
+		t.Errorf("mock location with impossible travel scored %d, want rejected", travelled.Score)
+	}
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GenerateInboundCode generates an inbound shipment code with format: IB + YYYYMMDD + 3-digit auto increment
+// Example: IB20251008001, IB20251008002, etc.
+func GenerateInboundCode(db *gorm.DB) string {
+	// Get current date in YYYYMMDD format
+	now := time.Now()
+	datePrefix := now.Format("20060102")
+
+	// Count inbound shipments for current date to get auto increment number
+	var count int64
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, now.Location())
+	db.Model(&models.InboundShipment{}).Where("created_at >= ? AND created_at <= ?", startOfDay, endOfDay).Count(&count)
+
+	// Format auto increment as 3-digit with leading zeros
+	return fmt.Sprintf("IB%s%03d", datePrefix, count+1)
+}
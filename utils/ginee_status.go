@@ -0,0 +1,27 @@
+package utils
+
+import "strings"
+
+// gineeEventStatuses maps Ginee external order statuses to our order event statuses
+var gineeEventStatuses = map[string]string{
+	"PENDING_PAYMENT": "in_progress",
+	"PAID":            "in_progress",
+	"READY_TO_SHIP":   "in_progress",
+	"PROCESSING":      "in_progress",
+	"SHIPPING":        "completed",
+	"SHIPPED":         "completed",
+	"DELIVERED":       "completed",
+	"COMPLETED":       "completed",
+	"CANCELLED":       "canceled",
+	"CANCELED":        "canceled",
+	"CANCEL":          "canceled",
+}
+
+// MapGineeStatus maps a Ginee external order status to our order event status
+// Statuses are matched case-insensitively, spaces and dashes are treated as underscores
+func MapGineeStatus(externalStatus string) (string, bool) {
+	normalized := strings.ToUpper(strings.TrimSpace(externalStatus))
+	normalized = strings.NewReplacer(" ", "_", "-", "_").Replace(normalized)
+	eventStatus, ok := gineeEventStatuses[normalized]
+	return eventStatus, ok
+}
@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
 	"math"
 )
 
@@ -25,3 +28,87 @@ func CalculateDistance(lat1, lon1, lat2, lon2 float64) float64 {
 	distance := earthRadius * c
 	return distance
 }
+
+// MaxGeofenceRadius is the largest check-in radius in meters a location can have
+const MaxGeofenceRadius = 1000.0
+
+// maxGeofenceSpan is how far in meters polygon points may lie from the location coordinates
+const maxGeofenceSpan = 5000.0
+
+// ValidateCoordinates checks the coordinates are plausible: within range and not the 0,0 default of unset GPS values
+func ValidateCoordinates(latitude, longitude float64) error {
+	if math.IsNaN(latitude) || math.IsNaN(longitude) || latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+	}
+	if latitude == 0 && longitude == 0 {
+		return errors.New("coordinates 0,0 are not a valid location")
+	}
+	return nil
+}
+
+// ValidateGeofence checks the radius and optional polygon of a location around its coordinates
+func ValidateGeofence(latitude, longitude, radius float64, polygon [][2]float64) error {
+	if radius <= 0 || radius > MaxGeofenceRadius {
+		return fmt.Errorf("geofence radius must be greater than 0 and at most %.0f meters", MaxGeofenceRadius)
+	}
+	if len(polygon) == 0 {
+		return nil
+	}
+	if len(polygon) < 3 {
+		return errors.New("geofence polygon needs at least 3 points")
+	}
+	for i, point := range polygon {
+		if err := ValidateCoordinates(point[0], point[1]); err != nil {
+			return fmt.Errorf("geofence polygon point %d: %w", i+1, err)
+		}
+		if CalculateDistance(latitude, longitude, point[0], point[1]) > maxGeofenceSpan {
+			return fmt.Errorf("geofence polygon point %d is more than %.0f meters from the location", i+1, maxGeofenceSpan)
+		}
+	}
+	if !PointInPolygon(latitude, longitude, polygon) {
+		return errors.New("location coordinates must be inside the geofence polygon")
+	}
+	return nil
+}
+
+// PointInPolygon reports whether the point lies inside the polygon of [latitude, longitude] points, using ray casting
+func PointInPolygon(latitude, longitude float64, polygon [][2]float64) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		latI, lonI := polygon[i][0], polygon[i][1]
+		latJ, lonJ := polygon[j][0], polygon[j][1]
+		if (lonI > longitude) != (lonJ > longitude) &&
+			latitude < (latJ-latI)*(longitude-lonI)/(lonJ-lonI)+latI {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// WithinGeofence reports whether the coordinates are inside the geofence of the location, the polygon when set or else the radius.
+// Also returns the distance in meters to the location coordinates.
+func WithinGeofence(location *models.Location, latitude, longitude float64) (bool, float64) {
+	distance := CalculateDistance(latitude, longitude, location.Latitude, location.Longitude)
+	if polygon := location.PolygonPoints(); len(polygon) >= 3 {
+		return PointInPolygon(latitude, longitude, polygon), distance
+	}
+	radius := location.GeofenceRadius
+	if radius <= 0 {
+		radius = models.DefaultGeofenceRadius
+	}
+	return distance <= radius, distance
+}
+
+// NearestGeofenceLocation returns the nearest of the locations whose geofence contains the coordinates and the
+// distance in meters to it, nil when the coordinates are inside none of them
+func NearestGeofenceLocation(locations []models.Location, latitude, longitude float64) (*models.Location, float64) {
+	var nearest *models.Location
+	nearestDistance := 0.0
+	for i := range locations {
+		inside, distance := WithinGeofence(&locations[i], latitude, longitude)
+		if inside && (nearest == nil || distance < nearestDistance) {
+			nearest, nearestDistance = &locations[i], distance
+		}
+	}
+	return nearest, nearestDistance
+}
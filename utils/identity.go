@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeUsername returns the canonical form usernames are stored and looked up in:
+// Unicode NFKC normalized, trimmed and lowercased, so visually identical or case-variant usernames match
+func NormalizeUsername(username string) string {
+	return normalizeIdentity(username)
+}
+
+// NormalizeEmail returns the canonical form emails are stored and looked up in:
+// Unicode NFKC normalized, trimmed and lowercased
+func NormalizeEmail(email string) string {
+	return normalizeIdentity(email)
+}
+
+// normalizeIdentity applies NFKC before lowercasing so compatibility characters (fullwidth letters, ligatures)
+// fold to the same value as their plain spelling
+func normalizeIdentity(value string) string {
+	return strings.ToLower(strings.TrimSpace(norm.NFKC.String(value)))
+}
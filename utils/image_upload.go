@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png" // register PNG decoder
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+)
+
+// MaxImagePixels caps decoded image dimensions to protect against decompression bombs
+const MaxImagePixels = 40_000_000
+
+// MaxLogoSide is the longest side in pixels of store logos, larger logos are scaled down
+const MaxLogoSide = 600
+
+// ErrInvalidImage is returned when an uploaded file is not a usable JPEG or PNG image
+var ErrInvalidImage = errors.New("invalid image file")
+
+// SaveSanitizedImage validates an uploaded image by sniffing its content (the client supplied
+// Content-Type is not trusted), accepts only JPEG and PNG, and re-encodes it as JPEG to destPath.
+// Re-encoding drops all metadata such as EXIF and GPS tags.
+func SaveSanitizedImage(file *multipart.FileHeader, destPath string) error {
+	img, err := decodeUploadedImage(file)
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	if err := jpeg.Encode(dst, img, &jpeg.Options{Quality: 90}); err != nil {
+		os.Remove(destPath)
+		return err
+	}
+
+	return nil
+}
+
+// SanitizeLogoImage validates an uploaded logo like SaveSanitizedImage and returns it as a JPEG, scaled down to
+// MaxLogoSide pixels. Transparent areas are printed white since JPEG has no transparency.
+func SanitizeLogoImage(file *multipart.FileHeader) ([]byte, error) {
+	img, err := decodeUploadedImage(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nearest neighbour scaling is enough for the small print size of logos
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > MaxLogoSide || height > MaxLogoSide {
+		if width >= height {
+			width, height = MaxLogoSide, max(1, height*MaxLogoSide/width)
+		} else {
+			width, height = max(1, width*MaxLogoSide/height), MaxLogoSide
+		}
+	}
+	logo := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			// Premultiplied colors over white
+			r, g, b, a := img.At(bounds.Min.X+x*bounds.Dx()/width, bounds.Min.Y+y*bounds.Dy()/height).RGBA()
+			white := 0xffff - a
+			logo.Set(x, y, color.RGBA64{R: uint16(r + white), G: uint16(g + white), B: uint16(b + white), A: 0xffff})
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := jpeg.Encode(&buffer, logo, &jpeg.Options{Quality: 90}); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// decodeUploadedImage sniffs the content of an uploaded JPEG or PNG image, checks its dimensions and decodes it
+func decodeUploadedImage(file *multipart.FileHeader) (image.Image, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	// Sniff the actual content type
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: unable to read file", ErrInvalidImage)
+	}
+	contentType := http.DetectContentType(head[:n])
+	if contentType != "image/jpeg" && contentType != "image/png" {
+		return nil, fmt.Errorf("%w: only JPEG and PNG images are allowed (got %s)", ErrInvalidImage, contentType)
+	}
+
+	// Check dimensions before decoding the full image
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	cfg, _, err := image.DecodeConfig(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read image header", ErrInvalidImage)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > MaxImagePixels {
+		return nil, fmt.Errorf("%w: image dimensions %dx%d are not allowed", ErrInvalidImage, cfg.Width, cfg.Height)
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(src)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to decode image", ErrInvalidImage)
+	}
+	return img, nil
+}
@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Inventory movement types
+const (
+	InventoryMovementStockTake = "stocktake_adjustment"
+	InventoryMovementReceipt   = "receipt"
+	InventoryMovementInbound   = "inbound_receipt"
+	InventoryMovementReturn    = "return_restock"
+)
+
+// InventoryAdjustment describes a single change to the system stock of a SKU
+type InventoryAdjustment struct {
+	SKU           string
+	Quantity      int // signed change
+	Type          string
+	LotNumber     string
+	ReferenceType string
+	ReferenceID   uint
+	Note          string
+	CreatedBy     *uint
+}
+
+// GetInventoryQuantity returns the system stock of a SKU, 0 when no inventory record exists
+func GetInventoryQuantity(db *gorm.DB, sku string) int {
+	var inventory models.Inventory
+	if err := db.Where("sku = ?", sku).First(&inventory).Error; err != nil {
+		return 0
+	}
+	return inventory.Quantity
+}
+
+// AdjustInventory applies a stock change and records it in the movement ledger.
+// It must run inside a transaction; the inventory row is locked for the duration.
+func AdjustInventory(tx *gorm.DB, adjustment InventoryAdjustment) (*models.InventoryMovement, error) {
+	var inventory models.Inventory
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("sku = ?", adjustment.SKU).First(&inventory).Error
+	if err == gorm.ErrRecordNotFound {
+		inventory = models.Inventory{SKU: adjustment.SKU}
+		if err := tx.Create(&inventory).Error; err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	movement := models.InventoryMovement{
+		SKU:           adjustment.SKU,
+		Quantity:      adjustment.Quantity,
+		BalanceBefore: inventory.Quantity,
+		BalanceAfter:  inventory.Quantity + adjustment.Quantity,
+		Type:          adjustment.Type,
+		LotNumber:     adjustment.LotNumber,
+		ReferenceType: adjustment.ReferenceType,
+		ReferenceID:   adjustment.ReferenceID,
+		Note:          adjustment.Note,
+		CreatedBy:     adjustment.CreatedBy,
+	}
+
+	if err := tx.Model(&inventory).Update("quantity", movement.BalanceAfter).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, err
+	}
+
+	return &movement, nil
+}
+
+// BatchReceipt describes stock received into a lot
+type BatchReceipt struct {
+	SKU           string
+	Type          string // defaults to InventoryMovementReceipt
+	LotNumber     string
+	ExpiryDate    *time.Time
+	Quantity      int
+	ReferenceType string
+	ReferenceID   uint
+	Note          string
+	ReceivedBy    *uint
+}
+
+// ReceiveInventoryBatch adds received stock to a lot (creating it when new) and to the SKU inventory.
+// It must run inside a transaction.
+func ReceiveInventoryBatch(tx *gorm.DB, receipt BatchReceipt) (*models.InventoryBatch, error) {
+	if receipt.Quantity <= 0 {
+		return nil, fmt.Errorf("received quantity must be greater than 0")
+	}
+
+	var batch models.InventoryBatch
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("sku = ? AND lot_number = ?", receipt.SKU, receipt.LotNumber).First(&batch).Error
+	if err == gorm.ErrRecordNotFound {
+		batch = models.InventoryBatch{
+			SKU:              receipt.SKU,
+			LotNumber:        receipt.LotNumber,
+			ExpiryDate:       receipt.ExpiryDate,
+			ReceivedQuantity: receipt.Quantity,
+			Quantity:         receipt.Quantity,
+			ReceivedBy:       receipt.ReceivedBy,
+			ReceivedAt:       time.Now(),
+		}
+		if err := tx.Create(&batch).Error; err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	} else {
+		// Same lot received again, the expiry date must match
+		if receipt.ExpiryDate != nil && batch.ExpiryDate != nil && !receipt.ExpiryDate.Equal(*batch.ExpiryDate) {
+			return nil, fmt.Errorf("lot %s of SKU %s is already recorded with expiry date %s", batch.LotNumber, batch.SKU, batch.ExpiryDate.Format("2006-01-02"))
+		}
+		updates := map[string]interface{}{
+			"received_quantity": batch.ReceivedQuantity + receipt.Quantity,
+			"quantity":          batch.Quantity + receipt.Quantity,
+		}
+		if batch.ExpiryDate == nil && receipt.ExpiryDate != nil {
+			updates["expiry_date"] = receipt.ExpiryDate
+		}
+		if err := tx.Model(&batch).Updates(updates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	movementType := receipt.Type
+	if movementType == "" {
+		movementType = InventoryMovementReceipt
+	}
+
+	if _, err := AdjustInventory(tx, InventoryAdjustment{
+		SKU:           receipt.SKU,
+		Quantity:      receipt.Quantity,
+		Type:          movementType,
+		LotNumber:     receipt.LotNumber,
+		ReferenceType: receipt.ReferenceType,
+		ReferenceID:   receipt.ReferenceID,
+		Note:          receipt.Note,
+		CreatedBy:     receipt.ReceivedBy,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// SuggestBatchesFEFO suggests which lots to pick a quantity of a SKU from, first expiry first out.
+// Expired lots are never suggested; lots without an expiry date come last.
+func SuggestBatchesFEFO(db *gorm.DB, sku string, quantity int) []models.BatchPick {
+	var batches []models.InventoryBatch
+	today := time.Now().Format("2006-01-02")
+	if err := db.Where("sku = ? AND quantity > 0 AND (expiry_date IS NULL OR expiry_date >= ?)", sku, today).
+		Order("expiry_date ASC NULLS LAST, received_at ASC").Find(&batches).Error; err != nil {
+		return nil
+	}
+
+	var picks []models.BatchPick
+	remaining := quantity
+	for _, batch := range batches {
+		if remaining <= 0 {
+			break
+		}
+		take := batch.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		picks = append(picks, models.BatchPick{Batch: batch, Quantity: take})
+		remaining -= take
+	}
+	return picks
+}
@@ -0,0 +1,324 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Languages of the printed order documents (packing slip and invoice) per channel
+const (
+	LabelLanguageIndonesian = "id"
+	LabelLanguageEnglish    = "en"
+)
+
+// labelTexts are the headings of the printed order documents per language
+var labelTexts = map[string]map[string]string{
+	LabelLanguageIndonesian: {
+		"invoice":       "FAKTUR",
+		"packingSlip":   "DAFTAR ISI PAKET",
+		"number":        "No",
+		"date":          "Tanggal",
+		"order":         "Pesanan",
+		"channel":       "Channel",
+		"tracking":      "Resi",
+		"courier":       "Kurir",
+		"billTo":        "Kepada",
+		"phone":         "Telp",
+		"product":       "PRODUK",
+		"quantity":      "JML",
+		"price":         "HARGA",
+		"subtotal":      "SUBTOTAL",
+		"total":         "TOTAL",
+		"items":         "Jumlah barang",
+		"serials":       "No. seri",
+		"thanks":        "Terima kasih telah berbelanja di",
+		"shippingLabel": "LABEL PENGIRIMAN",
+		"shipTo":        "Penerima",
+		"sender":        "Pengirim",
+	},
+	LabelLanguageEnglish: {
+		"invoice":       "INVOICE",
+		"packingSlip":   "PACKING SLIP",
+		"number":        "No",
+		"date":          "Date",
+		"order":         "Order",
+		"channel":       "Channel",
+		"tracking":      "Tracking",
+		"courier":       "Courier",
+		"billTo":        "Bill to",
+		"phone":         "Phone",
+		"product":       "PRODUCT",
+		"quantity":      "QTY",
+		"price":         "PRICE",
+		"subtotal":      "SUBTOTAL",
+		"total":         "TOTAL",
+		"items":         "Total items",
+		"serials":       "Serial no.",
+		"thanks":        "Thank you for shopping at",
+		"shippingLabel": "SHIPPING LABEL",
+		"shipTo":        "Ship to",
+		"sender":        "Sender",
+	},
+}
+
+// IsLabelLanguage reports whether the printed order documents are available in the language
+func IsLabelLanguage(language string) bool {
+	_, ok := labelTexts[language]
+	return ok
+}
+
+// labelText returns the heading in the language, falling back to Indonesian
+func labelText(language, key string) string {
+	texts, ok := labelTexts[language]
+	if !ok {
+		texts = labelTexts[LabelLanguageIndonesian]
+	}
+	return texts[key]
+}
+
+// FindOrderChannel returns the channel the order belongs to, matched by channel name or code, nil when unknown
+func FindOrderChannel(db *gorm.DB, order *models.Order) *models.Channel {
+	if order.Channel == "" {
+		return nil
+	}
+	var channel models.Channel
+	if err := db.Where("LOWER(channel_name) = LOWER(?) OR LOWER(channel_code) = LOWER(?)", order.Channel, order.Channel).First(&channel).Error; err != nil {
+		return nil
+	}
+	return &channel
+}
+
+// FindOrderStore returns the store the order belongs to, matched by store name or code, nil when unknown
+func FindOrderStore(db *gorm.DB, order *models.Order) *models.Store {
+	if order.Store == "" {
+		return nil
+	}
+	var store models.Store
+	if err := db.Where("LOWER(store_name) = LOWER(?) OR LOWER(store_code) = LOWER(?)", order.Store, order.Store).First(&store).Error; err != nil {
+		return nil
+	}
+	return &store
+}
+
+// OrderLabelLanguage returns the language the order documents are printed in, configured on the order's channel
+func OrderLabelLanguage(channel *models.Channel) string {
+	if channel != nil && IsLabelLanguage(channel.LabelLanguage) {
+		return channel.LabelLanguage
+	}
+	return LabelLanguageIndonesian
+}
+
+// InvoiceNumber returns the invoice number of an order, derived from its marketplace order id so reprints match
+func InvoiceNumber(order *models.Order) string {
+	return "INV/" + order.OrderGineeID
+}
+
+// FormatMoney formats an amount with its currency and thousands separators of the language
+func FormatMoney(amount int64, currency, language string) string {
+	separator := "."
+	if language == LabelLanguageEnglish {
+		separator = ","
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	sign := ""
+	if amount < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var grouped strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteString(separator)
+		}
+		grouped.WriteRune(digit)
+	}
+	return strings.TrimSpace(currency + " " + sign + grouped.String())
+}
+
+// BuildPackingSlipDocument lays out the list of items packed in the parcel of an order
+func BuildPackingSlipDocument(order *models.Order, language string) PDFDocument {
+	lines := []string{
+		labelText(language, "packingSlip") + " " + order.TrackingNumber,
+		fmt.Sprintf("%s: %s   %s: %s   %s: %s", labelText(language, "order"), order.OrderGineeID, labelText(language, "channel"), order.Channel, labelText(language, "courier"), order.Courier),
+		fmt.Sprintf("%s: %s", labelText(language, "billTo"), order.Buyer),
+		"",
+		fmt.Sprintf("%-24s %5s  %s", "SKU", labelText(language, "quantity"), labelText(language, "product")),
+	}
+
+	totalQuantity := 0
+	for _, detail := range order.OrderDetails {
+		totalQuantity += detail.Quantity
+		lines = append(lines, fmt.Sprintf("%-24s %5d  %s", detail.SKU, detail.Quantity, invoiceProductLabel(detail)))
+		lines = append(lines, serialNumberLines(detail, language, 31, 96)...)
+	}
+	lines = append(lines, "", fmt.Sprintf("%s: %d", labelText(language, "items"), totalQuantity))
+
+	return PDFDocument{Lines: lines}
+}
+
+// BuildInvoiceDocument lays out the invoice of an order with its items and prices, branded with the store's invoice details
+// and its invoice print template
+func BuildInvoiceDocument(order *models.Order, store *models.Store, branding *PrintBranding, language string) PDFDocument {
+	// Store branding, falling back to the order's store name
+	brandName := order.Store
+	var lines []string
+	if store != nil {
+		brandName = store.StoreName
+		if store.InvoiceName != "" {
+			brandName = store.InvoiceName
+		}
+	}
+	lines = append(lines, brandName)
+	if store != nil {
+		for _, addressLine := range strings.Split(store.InvoiceAddress, "\n") {
+			if addressLine = strings.TrimSpace(addressLine); addressLine != "" {
+				lines = append(lines, addressLine)
+			}
+		}
+		if store.InvoicePhone != "" {
+			lines = append(lines, labelText(language, "phone")+": "+store.InvoicePhone)
+		}
+	}
+
+	lines = append(lines,
+		strings.Repeat("=", 96),
+		fmt.Sprintf("%-60s %s: %s", labelText(language, "invoice"), labelText(language, "number"), InvoiceNumber(order)),
+		fmt.Sprintf("%s: %s   %s: %s   %s: %s", labelText(language, "date"), order.CreatedAt.Format("02-01-2006"), labelText(language, "order"), order.OrderGineeID, labelText(language, "channel"), order.Channel),
+		fmt.Sprintf("%s: %s   %s: %s", labelText(language, "tracking"), order.TrackingNumber, labelText(language, "courier"), order.Courier),
+		"",
+		labelText(language, "billTo")+": "+order.Buyer,
+	)
+	for _, addressPart := range []string{order.Address, strings.Join(nonEmpty(order.District, order.City, order.Province, order.PostalCode), ", ")} {
+		lines = append(lines, wrapLabel(addressPart, 96)...)
+	}
+
+	// Item lines
+	lines = append(lines,
+		"",
+		fmt.Sprintf("%-20s %-38s %5s %14s %15s", "SKU", labelText(language, "product"), labelText(language, "quantity"), labelText(language, "price"), labelText(language, "subtotal")),
+		strings.Repeat("-", 96),
+	)
+	var total int64
+	totalQuantity := 0
+	for _, detail := range order.OrderDetails {
+		subtotal := int64(detail.Quantity) * int64(detail.Price)
+		total += subtotal
+		totalQuantity += detail.Quantity
+		lines = append(lines, fmt.Sprintf("%-20s %-38s %5d %14s %15s", truncateLabel(detail.SKU, 20), truncateLabel(invoiceProductLabel(detail), 38), detail.Quantity,
+			FormatMoney(int64(detail.Price), "", language), FormatMoney(subtotal, "", language)))
+		lines = append(lines, serialNumberLines(detail, language, 21, 96)...)
+	}
+	lines = append(lines,
+		strings.Repeat("-", 96),
+		fmt.Sprintf("%-65s %30s", fmt.Sprintf("%s: %d", labelText(language, "items"), totalQuantity), labelText(language, "total")+" "+FormatMoney(total, order.Currency, language)),
+	)
+
+	// Footer, the template's footer replaces the store's invoice footer
+	if !branding.hasFooter() {
+		if store != nil && store.InvoiceFooter != "" {
+			lines = append(lines, "")
+			lines = append(lines, strings.Split(store.InvoiceFooter, "\n")...)
+		} else {
+			lines = append(lines, "", labelText(language, "thanks")+" "+brandName)
+		}
+	}
+
+	return branding.Apply(PDFDocument{Lines: lines})
+}
+
+// BuildShippingLabelDocument lays out the shipping label of an order with the courier, recipient address and sender store,
+// branded with the store's label print template
+func BuildShippingLabelDocument(order *models.Order, store *models.Store, branding *PrintBranding, language string) PDFDocument {
+	sender := order.Store
+	senderPhone := ""
+	if store != nil {
+		sender = store.StoreName
+		if store.InvoiceName != "" {
+			sender = store.InvoiceName
+		}
+		senderPhone = store.InvoicePhone
+	}
+
+	lines := []string{
+		fmt.Sprintf("%-60s %s: %s", labelText(language, "shippingLabel"), labelText(language, "courier"), order.Courier),
+		strings.Repeat("=", 96),
+		fmt.Sprintf("%s: %s", labelText(language, "tracking"), order.TrackingNumber),
+		fmt.Sprintf("%s: %s   %s: %s", labelText(language, "order"), order.OrderGineeID, labelText(language, "channel"), order.Channel),
+		"",
+		labelText(language, "shipTo") + ": " + order.Buyer,
+	}
+	for _, addressPart := range []string{order.Address, strings.Join(nonEmpty(order.District, order.City, order.Province, order.PostalCode), ", ")} {
+		lines = append(lines, wrapLabel(addressPart, 96)...)
+	}
+
+	lines = append(lines, "", labelText(language, "sender")+": "+sender)
+	if senderPhone != "" {
+		lines = append(lines, labelText(language, "phone")+": "+senderPhone)
+	}
+
+	return branding.Apply(PDFDocument{Lines: lines})
+}
+
+// serialNumberLines lists the serial numbers captured for an item, indented under its product column
+func serialNumberLines(detail models.OrderDetail, language string, indent, width int) []string {
+	if len(detail.SerialNumbers) == 0 {
+		return nil
+	}
+	var lines []string
+	for _, line := range wrapLabel(labelText(language, "serials")+": "+strings.Join(detail.SerialNumbers, ", "), width-indent) {
+		lines = append(lines, strings.Repeat(" ", indent)+line)
+	}
+	return lines
+}
+
+// invoiceProductLabel joins the product name and its variant
+func invoiceProductLabel(detail models.OrderDetail) string {
+	if detail.Variant == "" {
+		return detail.ProductName
+	}
+	return detail.ProductName + " (" + detail.Variant + ")"
+}
+
+// truncateLabel cuts a value to the column width
+func truncateLabel(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width-1]) + "~"
+}
+
+// wrapLabel splits a value into lines of at most the width, breaking between words
+func wrapLabel(value string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(value) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// nonEmpty returns the trimmed values that are not empty
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,1193 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LegacyImportField is a field of a legacy entity, read from the spreadsheet column the template maps to it
+type LegacyImportField struct {
+	Name        string `json:"name"`
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+}
+
+var legacyImportFields = map[string][]LegacyImportField{
+	models.LegacyEntityOrders: {
+		{Name: "orderGineeId", Required: true, Description: "Marketplace order ID, repeated on every item row of the order"},
+		{Name: "channel", Required: true, Description: "Channel name or code"},
+		{Name: "store", Required: true, Description: "Store name or code"},
+		{Name: "buyer", Required: true},
+		{Name: "address"},
+		{Name: "courier", Description: "Expedition name or code"},
+		{Name: "trackingNumber"},
+		{Name: "orderDate", Required: true, Description: "Date the order was placed, before the go-live date"},
+		{Name: "shippedAt", Description: "Date the parcel was handed to the courier, the order date when empty"},
+		{Name: "canceled", Description: "yes when the order was canceled instead of shipped"},
+		{Name: "picker", Description: "Username of the picker"},
+		{Name: "outboundBy", Description: "Username of the user who scanned the parcel out, the outbound is only recorded with a tracking number and this user"},
+		{Name: "currency", Description: "ISO 4217 code, the default currency when empty"},
+		{Name: "sku", Required: true},
+		{Name: "productName", Required: true},
+		{Name: "variant"},
+		{Name: "quantity", Required: true},
+		{Name: "price", Required: true, Description: "Unit price"},
+	},
+	models.LegacyEntityComplains: {
+		{Name: "code", Required: true, Description: "Complain code of the legacy system, kept as is"},
+		{Name: "trackingNumber", Required: true},
+		{Name: "orderGineeId", Description: "Looked up from the order of the tracking number when empty"},
+		{Name: "channel", Required: true, Description: "Channel name or code"},
+		{Name: "store", Required: true, Description: "Store name or code"},
+		{Name: "createdAt", Required: true, Description: "Date the complain was filed, before the go-live date"},
+		{Name: "createdBy", Required: true, Description: "Username of the user who filed the complain"},
+		{Name: "reason", Required: true},
+		{Name: "solution", Description: "The complain is resolved at its creation date when set"},
+		{Name: "totalFee", Description: "Total fee charged, the sum of the attributed fees when empty"},
+		{Name: "attributedUsers", Description: "Users charged for the complain as username:fee separated by semicolons"},
+	},
+	models.LegacyEntityAttendances: {
+		{Name: "username", Required: true},
+		{Name: "date", Required: true, Description: "Attendance date, before the go-live date"},
+		{Name: "checkIn", Required: true, Description: "Check-in time (HH:MM) on the date"},
+		{Name: "checkOut", Description: "Check-out time (HH:MM), on the next day when before the check-in"},
+		{Name: "status", Description: "fullday or halfday, fullday when empty"},
+		{Name: "location", Required: true, Description: "Location name"},
+		{Name: "late", Description: "Late minutes"},
+		{Name: "overtime", Description: "Overtime minutes, imported as approved"},
+	},
+}
+
+// LegacyImportFields returns the import fields of a legacy entity
+func LegacyImportFields(entity string) []LegacyImportField {
+	return legacyImportFields[entity]
+}
+
+// legacyDateLayouts are tried in order on date columns when the template has no date layout
+var legacyDateLayouts = []string{
+	"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02",
+	"02/01/2006 15:04:05", "02/01/2006 15:04", "02/01/2006",
+	"02-01-2006 15:04:05", "02-01-2006 15:04", "02-01-2006",
+}
+
+// maxLegacyImportReportErrors caps the row errors stored in the report of a run
+const maxLegacyImportReportErrors = 1000
+
+// legacyImportLookupChunk keeps lookups below the query parameter limit
+const legacyImportLookupChunk = 1000
+
+// LegacyImportCutoff returns the configured go-live date legacy records must be dated before, nil when not set
+func LegacyImportCutoff(cfg *config.Config) (*time.Time, error) {
+	if cfg.LegacyGoLiveDate == "" {
+		return nil, nil
+	}
+	cutoff, err := time.ParseInLocation("2006-01-02", cfg.LegacyGoLiveDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEGACY_GO_LIVE_DATE %q, use YYYY-MM-DD", cfg.LegacyGoLiveDate)
+	}
+	return &cutoff, nil
+}
+
+// ReadLegacyImportFile reads the rows of a legacy CSV or XLSX file and returns them with the SHA-256 of the file
+func ReadLegacyImportFile(filename string, reader io.Reader) ([][]string, string, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(data)
+	records, err := ReadSpreadsheetRows(filename, bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	return records, hex.EncodeToString(sum[:]), nil
+}
+
+// LegacyImportRow is a data row of a legacy spreadsheet keyed by import field
+type LegacyImportRow struct {
+	Row    int
+	Values map[string]string
+}
+
+// ParseLegacyImportRows maps the spreadsheet rows to the import fields of the entity using the header row, through
+// the template mapping when given. Returns an error when a required field has no column.
+func ParseLegacyImportRows(entity string, template *models.LegacyImportTemplate, records [][]string) ([]LegacyImportRow, error) {
+	fields := LegacyImportFields(entity)
+	if fields == nil {
+		return nil, fmt.Errorf("unknown entity %q", entity)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	normalize := func(value string) string {
+		return strings.NewReplacer("_", "", " ", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(value)))
+	}
+
+	mapping := map[string]string{}
+	if template != nil {
+		mapping = template.MappingColumns()
+	}
+
+	columnIndex := make(map[string]int)
+	var missing []string
+	for _, field := range fields {
+		header := field.Name
+		if mapped := mapping[field.Name]; mapped != "" {
+			header = mapped
+		}
+		for i, column := range records[0] {
+			if normalize(column) == normalize(header) {
+				columnIndex[field.Name] = i
+				break
+			}
+		}
+		if _, ok := columnIndex[field.Name]; !ok && field.Required {
+			missing = append(missing, header)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	rows := make([]LegacyImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		row := LegacyImportRow{Row: i + 2, Values: make(map[string]string, len(columnIndex))}
+		empty := true
+		for field, index := range columnIndex {
+			if index < len(record) {
+				row.Values[field] = strings.TrimSpace(record[index])
+				if row.Values[field] != "" {
+					empty = false
+				}
+			}
+		}
+		// Skip blank lines left at the end of spreadsheets
+		if empty {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ValidateLegacyImportMapping checks that a template mapping only names fields of the entity and maps no two
+// fields to the same column
+func ValidateLegacyImportMapping(entity string, mapping map[string]string) error {
+	known := map[string]bool{}
+	for _, field := range LegacyImportFields(entity) {
+		known[field.Name] = true
+	}
+	columns := map[string]string{}
+	for field, column := range mapping {
+		if !known[field] {
+			return fmt.Errorf("unknown %s field %q", entity, field)
+		}
+		column = strings.ToLower(strings.TrimSpace(column))
+		if column == "" {
+			return fmt.Errorf("column of field %q is empty", field)
+		}
+		if other, ok := columns[column]; ok {
+			return fmt.Errorf("fields %q and %q are mapped to the same column", other, field)
+		}
+		columns[column] = field
+	}
+	return nil
+}
+
+// LegacyImportOptions configures a legacy import run
+type LegacyImportOptions struct {
+	Entity          string
+	Template        *models.LegacyImportTemplate
+	FileName        string
+	FileHash        string
+	DryRun          bool
+	Source          string
+	StartedBy       *uint
+	DefaultCurrency string
+	Cutoff          *time.Time // go-live date, records on or after it are rejected, nil accepts any date
+}
+
+// legacyImportRecord is an order, complain or attendance of the file with the rows it was read from
+type legacyImportRecord struct {
+	Key    string
+	Rows   []LegacyImportRow
+	Errors []models.LegacyImportRowError
+	Model  any // record to create, built by the importer when the rows are valid
+}
+
+func (r *legacyImportRecord) addError(row LegacyImportRow, field, message string) {
+	r.Errors = append(r.Errors, models.LegacyImportRowError{Row: row.Row, Key: r.Key, Field: field, Message: message})
+}
+
+// legacyImporter validates and creates the records of a legacy entity
+type legacyImporter interface {
+	// key returns the legacy key of the record a row belongs to
+	key(row LegacyImportRow) string
+	// load looks up the references and existing data of the records
+	load(db *gorm.DB, records []*legacyImportRecord) error
+	// build validates the record and sets its model when valid
+	build(record *legacyImportRecord)
+	// create saves the model of the record and returns its ID
+	create(tx *gorm.DB, record *legacyImportRecord) (uint, error)
+}
+
+// RunLegacyImport validates the rows of a legacy spreadsheet and, unless on a dry run, creates the valid records
+// that are not in the ledger yet. Each record is created in its own transaction with its ledger entry, so a re-run
+// of the same file only creates the records that failed or were added since. The run is saved with its report.
+func RunLegacyImport(db *gorm.DB, rows []LegacyImportRow, opts LegacyImportOptions) (*models.LegacyImportRun, error) {
+	run := &models.LegacyImportRun{
+		Entity:    opts.Entity,
+		FileName:  truncateLabel(opts.FileName, 255),
+		FileHash:  opts.FileHash,
+		Source:    opts.Source,
+		DryRun:    opts.DryRun,
+		Status:    models.LegacyImportStatusCompleted,
+		StartedBy: opts.StartedBy,
+	}
+	if opts.DryRun {
+		run.Status = models.LegacyImportStatusValidated
+	}
+	if opts.Template != nil {
+		run.TemplateID = &opts.Template.ID
+	}
+
+	var importer legacyImporter
+	switch opts.Entity {
+	case models.LegacyEntityOrders:
+		importer = &legacyOrderImporter{opts: opts}
+	case models.LegacyEntityComplains:
+		importer = &legacyComplainImporter{opts: opts}
+	case models.LegacyEntityAttendances:
+		importer = &legacyAttendanceImporter{opts: opts}
+	default:
+		return nil, fmt.Errorf("unknown entity %q", opts.Entity)
+	}
+
+	// Save the run first so the ledger entries can point at it
+	if err := db.Create(run).Error; err != nil {
+		return nil, err
+	}
+
+	var rowErrors []models.LegacyImportRowError
+	finish := func(err error) (*models.LegacyImportRun, error) {
+		if err != nil {
+			run.Status = models.LegacyImportStatusFailed
+			run.Error = err.Error()
+		}
+		if len(rowErrors) > maxLegacyImportReportErrors {
+			rowErrors = rowErrors[:maxLegacyImportReportErrors]
+		}
+		if len(rowErrors) > 0 {
+			report, _ := json.Marshal(rowErrors)
+			run.Report = string(report)
+		}
+		now := time.Now()
+		run.CompletedAt = &now
+		if saveErr := db.Save(run).Error; saveErr != nil {
+			return nil, saveErr
+		}
+		return run, err
+	}
+
+	// Group the rows by legacy key, only orders span several rows
+	var records []*legacyImportRecord
+	byKey := map[string]*legacyImportRecord{}
+	for _, row := range rows {
+		key := importer.key(row)
+		if key == "" {
+			run.Invalid++
+			rowErrors = append(rowErrors, models.LegacyImportRowError{Row: row.Row, Message: "Key columns are missing or invalid"})
+			continue
+		}
+		if record, ok := byKey[key]; ok {
+			if opts.Entity != models.LegacyEntityOrders {
+				record.addError(row, "", fmt.Sprintf("Also listed on row %d", record.Rows[0].Row))
+				continue
+			}
+			record.Rows = append(record.Rows, row)
+			continue
+		}
+		record := &legacyImportRecord{Key: key, Rows: []LegacyImportRow{row}}
+		byKey[key] = record
+		records = append(records, record)
+	}
+	run.TotalRecords = len(records) + run.Invalid
+
+	// Skip the records an earlier run already imported
+	keys := make(map[string]bool, len(records))
+	for _, record := range records {
+		keys[record.Key] = true
+	}
+	imported := map[string]bool{}
+	if err := lookupLegacyValues(keys, func(chunk []string) error {
+		var ledgered []string
+		if err := db.Model(&models.LegacyImportRecord{}).Where("entity = ? AND legacy_key IN ?", opts.Entity, chunk).
+			Pluck("legacy_key", &ledgered).Error; err != nil {
+			return err
+		}
+		for _, key := range ledgered {
+			imported[key] = true
+		}
+		return nil
+	}); err != nil {
+		return finish(fmt.Errorf("failed to look up imported records: %w", err))
+	}
+	pending := make([]*legacyImportRecord, 0, len(records))
+	for _, record := range records {
+		if imported[record.Key] {
+			run.Skipped++
+			continue
+		}
+		pending = append(pending, record)
+	}
+
+	if err := importer.load(db, pending); err != nil {
+		return finish(fmt.Errorf("failed to look up references: %w", err))
+	}
+
+	var valid []*legacyImportRecord
+	for _, record := range pending {
+		if len(record.Errors) == 0 {
+			importer.build(record)
+		}
+		if len(record.Errors) > 0 {
+			run.Invalid++
+			rowErrors = append(rowErrors, record.Errors...)
+			continue
+		}
+		valid = append(valid, record)
+	}
+
+	if opts.DryRun {
+		run.Imported = len(valid)
+		return finish(nil)
+	}
+
+	for _, record := range valid {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			id, err := importer.create(tx, record)
+			if err != nil {
+				return err
+			}
+			return tx.Create(&models.LegacyImportRecord{Entity: opts.Entity, LegacyKey: record.Key, RecordID: id, RunID: run.ID}).Error
+		})
+		if err != nil {
+			run.Invalid++
+			rowErrors = append(rowErrors, models.LegacyImportRowError{Row: record.Rows[0].Row, Key: record.Key, Message: "Failed to import: " + err.Error()})
+			continue
+		}
+		run.Imported++
+	}
+	return finish(nil)
+}
+
+// parseLegacyDate parses a date column with the template layout, or the common layouts when the template has none
+func (opts LegacyImportOptions) parseLegacyDate(value string) (time.Time, error) {
+	layouts := legacyDateLayouts
+	if opts.Template != nil && opts.Template.DateLayout != "" {
+		layouts = []string{opts.Template.DateLayout}
+	}
+	for _, layout := range layouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, errors.New("Invalid date " + value)
+}
+
+// checkCutoff reports an error when the date is on or after the go-live date
+func (opts LegacyImportOptions) checkCutoff(date time.Time) error {
+	if opts.Cutoff != nil && !date.Before(*opts.Cutoff) {
+		return errors.New("Date is not before the go-live date " + opts.Cutoff.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// parseLegacyBool reads yes/no style spreadsheet flags
+func parseLegacyBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "0", "no", "n", "false", "tidak":
+		return false, true
+	case "1", "yes", "y", "true", "ya":
+		return true, true
+	}
+	return false, false
+}
+
+// lookupLegacyValues runs the query for the values in chunks
+func lookupLegacyValues(values map[string]bool, query func(chunk []string) error) error {
+	list := make([]string, 0, len(values))
+	for value := range values {
+		list = append(list, value)
+	}
+	for start := 0; start < len(list); start += legacyImportLookupChunk {
+		end := min(start+legacyImportLookupChunk, len(list))
+		if err := query(list[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadLegacyUsers returns the users with the usernames, keyed by normalized username
+func loadLegacyUsers(db *gorm.DB, usernames map[string]bool) (map[string]models.User, error) {
+	users := map[string]models.User{}
+	err := lookupLegacyValues(usernames, func(chunk []string) error {
+		var found []models.User
+		if err := db.Where("username IN ?", chunk).Find(&found).Error; err != nil {
+			return err
+		}
+		for _, user := range found {
+			users[user.Username] = user
+		}
+		return nil
+	})
+	return users, err
+}
+
+// legacyChannelsAndStores maps the lowercased codes and names of channels and stores to them
+func legacyChannelsAndStores(db *gorm.DB) (map[string]models.Channel, map[string]models.Store, error) {
+	var channels []models.Channel
+	var stores []models.Store
+	if err := db.Find(&channels).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := db.Find(&stores).Error; err != nil {
+		return nil, nil, err
+	}
+	channelMap, storeMap := map[string]models.Channel{}, map[string]models.Store{}
+	for _, channel := range channels {
+		channelMap[strings.ToLower(channel.ChannelCode)] = channel
+		channelMap[strings.ToLower(channel.ChannelName)] = channel
+	}
+	for _, store := range stores {
+		storeMap[strings.ToLower(store.StoreCode)] = store
+		storeMap[strings.ToLower(store.StoreName)] = store
+	}
+	return channelMap, storeMap, nil
+}
+
+// legacyOrderImporter imports shipped and canceled orders, one row per order detail
+type legacyOrderImporter struct {
+	opts             LegacyImportOptions
+	channels         map[string]models.Channel
+	stores           map[string]models.Store
+	expeditions      map[string]models.Expedition
+	users            map[string]models.User
+	existingOrders   map[string]bool
+	existingTracking map[string]bool // tracking numbers of orders and outbounds already in the system
+	fileTracking     map[string]string
+}
+
+// legacyOrderColumns must be equal on all rows of the same order
+var legacyOrderColumns = []string{"channel", "store", "buyer", "address", "courier", "trackingNumber", "orderDate", "shippedAt", "canceled", "picker", "outboundBy", "currency"}
+
+// legacyOrder is an order to import with its outbound
+type legacyOrder struct {
+	Order    models.Order
+	Outbound *models.Outbound
+}
+
+func (im *legacyOrderImporter) key(row LegacyImportRow) string {
+	row.Values["orderGineeId"] = models.NormalizeIdentifier(row.Values["orderGineeId"])
+	row.Values["trackingNumber"] = models.NormalizeIdentifier(row.Values["trackingNumber"])
+	return row.Values["orderGineeId"]
+}
+
+func (im *legacyOrderImporter) load(db *gorm.DB, records []*legacyImportRecord) error {
+	var err error
+	if im.channels, im.stores, err = legacyChannelsAndStores(db); err != nil {
+		return err
+	}
+
+	var expeditions []models.Expedition
+	if err := db.Find(&expeditions).Error; err != nil {
+		return err
+	}
+	im.expeditions = map[string]models.Expedition{}
+	for _, expedition := range expeditions {
+		im.expeditions[strings.ToLower(expedition.ExpeditionCode)] = expedition
+		im.expeditions[strings.ToLower(expedition.ExpeditionName)] = expedition
+	}
+
+	orderIDs, trackingNumbers, usernames := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, record := range records {
+		orderIDs[record.Key] = true
+		for _, row := range record.Rows {
+			if row.Values["trackingNumber"] != "" {
+				trackingNumbers[row.Values["trackingNumber"]] = true
+			}
+			for _, field := range []string{"picker", "outboundBy"} {
+				if username := NormalizeUsername(row.Values[field]); username != "" {
+					usernames[username] = true
+				}
+			}
+		}
+	}
+
+	im.existingOrders, im.existingTracking, im.fileTracking = map[string]bool{}, map[string]bool{}, map[string]string{}
+	if err := lookupLegacyValues(orderIDs, func(chunk []string) error {
+		var ids []string
+		if err := db.Model(&models.Order{}).Where("order_ginee_id IN ?", chunk).Pluck("order_ginee_id", &ids).Error; err != nil {
+			return err
+		}
+		for _, id := range ids {
+			im.existingOrders[id] = true
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := lookupLegacyValues(trackingNumbers, func(chunk []string) error {
+		var numbers, outbounds []string
+		if err := db.Model(&models.Order{}).Where("tracking_number IN ?", chunk).Pluck("tracking_number", &numbers).Error; err != nil {
+			return err
+		}
+		if err := db.Model(&models.Outbound{}).Where("tracking_number IN ?", chunk).Pluck("tracking_number", &outbounds).Error; err != nil {
+			return err
+		}
+		for _, number := range append(numbers, outbounds...) {
+			im.existingTracking[number] = true
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	im.users, err = loadLegacyUsers(db, usernames)
+	return err
+}
+
+func (im *legacyOrderImporter) build(record *legacyImportRecord) {
+	first := record.Rows[0]
+	values := first.Values
+
+	for _, row := range record.Rows[1:] {
+		for _, column := range legacyOrderColumns {
+			if row.Values[column] != "" && !strings.EqualFold(row.Values[column], values[column]) {
+				record.addError(row, column, fmt.Sprintf("Differs from row %d of the same order", first.Row))
+			}
+		}
+	}
+	for _, column := range []string{"channel", "store", "buyer", "orderDate"} {
+		if values[column] == "" {
+			record.addError(first, column, column+" is required")
+		}
+	}
+
+	order := models.Order{
+		OrderGineeID:   record.Key,
+		Buyer:          values["buyer"],
+		Address:        values["address"],
+		Courier:        values["courier"],
+		TrackingNumber: values["trackingNumber"],
+		OrderSource:    models.OrderSourceMarketplace,
+		Priority:       models.OrderPriorityNormal,
+	}
+	if im.existingOrders[record.Key] {
+		record.addError(first, "orderGineeId", "Order already exists in the system")
+	}
+
+	if channel, ok := im.channels[strings.ToLower(values["channel"])]; ok {
+		order.Channel = channel.ChannelName
+		order.OrderSource = channel.OrderSource
+	} else if values["channel"] != "" {
+		record.addError(first, "channel", "Unknown channel")
+	}
+	if store, ok := im.stores[strings.ToLower(values["store"])]; ok {
+		order.Store = store.StoreName
+	} else if values["store"] != "" {
+		record.addError(first, "store", "Unknown store")
+	}
+
+	currency := strings.ToUpper(values["currency"])
+	if currency == "" {
+		currency = im.opts.DefaultCurrency
+	}
+	if len(currency) != 3 || strings.IndexFunc(currency, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		record.addError(first, "currency", "Invalid currency")
+	}
+	order.Currency = currency
+
+	var orderDate time.Time
+	if values["orderDate"] != "" {
+		date, err := im.opts.parseLegacyDate(values["orderDate"])
+		if err == nil {
+			err = im.opts.checkCutoff(date)
+		}
+		if err != nil {
+			record.addError(first, "orderDate", err.Error())
+		}
+		orderDate = date
+	}
+	shippedAt := orderDate
+	if values["shippedAt"] != "" {
+		date, err := im.opts.parseLegacyDate(values["shippedAt"])
+		if err == nil {
+			err = im.opts.checkCutoff(date)
+		}
+		if err == nil && date.Before(orderDate) {
+			err = errors.New("Shipped before the order date")
+		}
+		if err != nil {
+			record.addError(first, "shippedAt", err.Error())
+		}
+		shippedAt = date
+	}
+	order.CreatedAt = orderDate
+	order.SentBefore = orderDate
+
+	canceled, ok := parseLegacyBool(values["canceled"])
+	if !ok {
+		record.addError(first, "canceled", "Use yes or no")
+	}
+
+	if tracking := order.TrackingNumber; tracking != "" {
+		if im.existingTracking[tracking] {
+			record.addError(first, "trackingNumber", "Tracking number is already used in the system")
+		} else if other, ok := im.fileTracking[tracking]; ok && other != record.Key {
+			record.addError(first, "trackingNumber", "Tracking number is used by order "+other+" in this file")
+		} else {
+			im.fileTracking[tracking] = record.Key
+		}
+	}
+
+	if username := NormalizeUsername(values["picker"]); username != "" {
+		if picker, ok := im.users[username]; ok {
+			order.PickedBy = &picker.ID
+			order.PickedAt = &shippedAt
+		} else {
+			record.addError(first, "picker", "Unknown user")
+		}
+	}
+
+	var outboundUser *models.User
+	if username := NormalizeUsername(values["outboundBy"]); username != "" {
+		if user, ok := im.users[username]; ok {
+			outboundUser = &user
+		} else {
+			record.addError(first, "outboundBy", "Unknown user")
+		}
+	}
+
+	if canceled {
+		order.ProcessingStatus = models.ProcessingStatusReadyToPick
+		order.EventStatus = models.EventStatusCanceled
+		order.CanceledAt = &orderDate
+	} else {
+		order.ProcessingStatus = models.ProcessingStatusOutboundCompleted
+		order.EventStatus = models.EventStatusCompleted
+	}
+
+	skus := map[string]int{}
+	for _, row := range record.Rows {
+		detail := models.OrderDetail{
+			SKU:         row.Values["sku"],
+			ProductName: row.Values["productName"],
+			Variant:     row.Values["variant"],
+			IsValid:     true,
+		}
+		for _, column := range []string{"sku", "productName", "quantity", "price"} {
+			if row.Values[column] == "" {
+				record.addError(row, column, column+" is required")
+			}
+		}
+		if previous, ok := skus[detail.SKU]; ok && detail.SKU != "" {
+			record.addError(row, "sku", fmt.Sprintf("SKU already listed for this order on row %d", previous))
+		}
+		skus[detail.SKU] = row.Row
+
+		if row.Values["quantity"] != "" {
+			quantity, err := strconv.Atoi(row.Values["quantity"])
+			if err != nil || quantity <= 0 {
+				record.addError(row, "quantity", "Quantity must be a whole number greater than zero")
+			}
+			detail.Quantity = quantity
+		}
+		if row.Values["price"] != "" {
+			price, err := strconv.Atoi(row.Values["price"])
+			if err != nil || price < 0 {
+				record.addError(row, "price", "Price must be a whole number of at least zero")
+			}
+			detail.Price = price
+		}
+		if !canceled {
+			detail.PickedQuantity = detail.Quantity
+			detail.ScannedQuantity = detail.Quantity
+			detail.IsPicked = true
+		}
+		order.OrderDetails = append(order.OrderDetails, detail)
+	}
+	if len(record.Errors) > 0 {
+		return
+	}
+
+	order.CalculateTotals()
+	imported := &legacyOrder{Order: order}
+	if !canceled && order.TrackingNumber != "" && outboundUser != nil {
+		outbound := &models.Outbound{
+			TrackingNumber: order.TrackingNumber,
+			OutboundBy:     outboundUser.ID,
+			Expedition:     order.Courier,
+			CreatedAt:      shippedAt,
+			UpdatedAt:      shippedAt,
+		}
+		if expedition, ok := im.expeditions[strings.ToLower(order.Courier)]; ok {
+			outbound.Expedition = expedition.ExpeditionName
+			outbound.ExpeditionSlug = expedition.ExpeditionSlug
+			outbound.ExpeditionColor = expedition.ExpeditionColor
+		}
+		imported.Outbound = outbound
+	}
+	record.Model = imported
+}
+
+func (im *legacyOrderImporter) create(tx *gorm.DB, record *legacyImportRecord) (uint, error) {
+	imported := record.Model.(*legacyOrder)
+	order := imported.Order
+	if err := tx.Create(&order).Error; err != nil {
+		return 0, err
+	}
+	if imported.Outbound != nil {
+		outbound := *imported.Outbound
+		if err := tx.Create(&outbound).Error; err != nil {
+			return 0, err
+		}
+	}
+	return order.ID, nil
+}
+
+// legacyComplainImporter imports complains with their fee attributions, one row per complain
+type legacyComplainImporter struct {
+	opts             LegacyImportOptions
+	channels         map[string]models.Channel
+	stores           map[string]models.Store
+	users            map[string]models.User
+	existingCodes    map[string]bool
+	existingTracking map[string]bool // tracking numbers that already have a complain
+	trackingOrders   map[string]string
+	fileTracking     map[string]string
+}
+
+// legacyAttribution is a user charged for a complain
+type legacyAttribution struct {
+	Username string
+	Fee      int
+}
+
+// parseLegacyAttributions reads "username:fee; username:fee" lists, a missing fee is zero
+func parseLegacyAttributions(value string) ([]legacyAttribution, error) {
+	var attributions []legacyAttribution
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		username, feeValue, _ := strings.Cut(part, ":")
+		attribution := legacyAttribution{Username: NormalizeUsername(username)}
+		if attribution.Username == "" {
+			return nil, errors.New("Attribution " + part + " has no username")
+		}
+		if feeValue = strings.TrimSpace(feeValue); feeValue != "" {
+			fee, err := strconv.Atoi(feeValue)
+			if err != nil || fee < 0 {
+				return nil, errors.New("Invalid fee in attribution " + part)
+			}
+			attribution.Fee = fee
+		}
+		attributions = append(attributions, attribution)
+	}
+	return attributions, nil
+}
+
+func (im *legacyComplainImporter) key(row LegacyImportRow) string {
+	row.Values["code"] = models.NormalizeIdentifier(row.Values["code"])
+	row.Values["trackingNumber"] = models.NormalizeIdentifier(row.Values["trackingNumber"])
+	row.Values["orderGineeId"] = models.NormalizeIdentifier(row.Values["orderGineeId"])
+	return row.Values["code"]
+}
+
+func (im *legacyComplainImporter) load(db *gorm.DB, records []*legacyImportRecord) error {
+	var err error
+	if im.channels, im.stores, err = legacyChannelsAndStores(db); err != nil {
+		return err
+	}
+
+	codes, trackingNumbers, usernames := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	for _, record := range records {
+		codes[record.Key] = true
+		values := record.Rows[0].Values
+		if values["trackingNumber"] != "" {
+			trackingNumbers[values["trackingNumber"]] = true
+		}
+		if username := NormalizeUsername(values["createdBy"]); username != "" {
+			usernames[username] = true
+		}
+		if attributions, err := parseLegacyAttributions(values["attributedUsers"]); err == nil {
+			for _, attribution := range attributions {
+				usernames[attribution.Username] = true
+			}
+		}
+	}
+
+	im.existingCodes, im.existingTracking, im.trackingOrders, im.fileTracking = map[string]bool{}, map[string]bool{}, map[string]string{}, map[string]string{}
+	if err := lookupLegacyValues(codes, func(chunk []string) error {
+		var found []string
+		if err := db.Model(&models.Complain{}).Where("code IN ?", chunk).Pluck("code", &found).Error; err != nil {
+			return err
+		}
+		for _, code := range found {
+			im.existingCodes[code] = true
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := lookupLegacyValues(trackingNumbers, func(chunk []string) error {
+		var complained []string
+		if err := db.Model(&models.Complain{}).Where("tracking_number IN ?", chunk).Pluck("tracking_number", &complained).Error; err != nil {
+			return err
+		}
+		for _, number := range complained {
+			im.existingTracking[number] = true
+		}
+		var orders []models.Order
+		if err := db.Select("order_ginee_id", "tracking_number").Where("tracking_number IN ?", chunk).Find(&orders).Error; err != nil {
+			return err
+		}
+		for _, order := range orders {
+			im.trackingOrders[order.TrackingNumber] = order.OrderGineeID
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	im.users, err = loadLegacyUsers(db, usernames)
+	return err
+}
+
+func (im *legacyComplainImporter) build(record *legacyImportRecord) {
+	row := record.Rows[0]
+	values := row.Values
+
+	for _, column := range []string{"trackingNumber", "channel", "store", "createdAt", "createdBy", "reason"} {
+		if values[column] == "" {
+			record.addError(row, column, column+" is required")
+		}
+	}
+
+	complain := models.Complain{
+		Code:           record.Key,
+		TrackingNumber: values["trackingNumber"],
+		OrderGineeID:   values["orderGineeId"],
+		Reason:         values["reason"],
+		Checked:        true,
+	}
+	if im.existingCodes[record.Key] {
+		record.addError(row, "code", "Complain code already exists in the system")
+	}
+	if tracking := complain.TrackingNumber; tracking != "" {
+		if im.existingTracking[tracking] {
+			record.addError(row, "trackingNumber", "Tracking number already has a complain")
+		} else if other, ok := im.fileTracking[tracking]; ok {
+			record.addError(row, "trackingNumber", "Tracking number is used by complain "+other+" in this file")
+		} else {
+			im.fileTracking[tracking] = record.Key
+		}
+		if complain.OrderGineeID == "" {
+			complain.OrderGineeID = im.trackingOrders[tracking]
+		}
+	}
+	if complain.OrderGineeID == "" && complain.TrackingNumber != "" {
+		record.addError(row, "orderGineeId", "No order has the tracking number, the order ID is required")
+	}
+
+	if channel, ok := im.channels[strings.ToLower(values["channel"])]; ok {
+		complain.ChannelID = channel.ID
+	} else if values["channel"] != "" {
+		record.addError(row, "channel", "Unknown channel")
+	}
+	if store, ok := im.stores[strings.ToLower(values["store"])]; ok {
+		complain.StoreID = store.ID
+	} else if values["store"] != "" {
+		record.addError(row, "store", "Unknown store")
+	}
+	if username := NormalizeUsername(values["createdBy"]); username != "" {
+		if user, ok := im.users[username]; ok {
+			complain.CreatedBy = user.ID
+		} else {
+			record.addError(row, "createdBy", "Unknown user")
+		}
+	}
+
+	if values["createdAt"] != "" {
+		date, err := im.opts.parseLegacyDate(values["createdAt"])
+		if err == nil {
+			err = im.opts.checkCutoff(date)
+		}
+		if err != nil {
+			record.addError(row, "createdAt", err.Error())
+		}
+		complain.CreatedAt = date
+		complain.UpdatedAt = date
+	}
+	if solution := values["solution"]; solution != "" {
+		complain.Solution = &solution
+		complain.ResolvedAt = &complain.CreatedAt
+	}
+
+	attributions, err := parseLegacyAttributions(values["attributedUsers"])
+	if err != nil {
+		record.addError(row, "attributedUsers", err.Error())
+	}
+	totalFee := 0
+	attributed := map[uint]bool{}
+	for _, attribution := range attributions {
+		user, ok := im.users[attribution.Username]
+		if !ok {
+			record.addError(row, "attributedUsers", "Unknown user "+attribution.Username)
+			continue
+		}
+		if attributed[user.ID] {
+			record.addError(row, "attributedUsers", "User "+attribution.Username+" is attributed twice")
+			continue
+		}
+		attributed[user.ID] = true
+		totalFee += attribution.Fee
+		complain.ComplainUserDetails = append(complain.ComplainUserDetails, models.ComplainUserDetail{UserID: user.ID, FeeCharge: attribution.Fee})
+	}
+	if values["totalFee"] != "" {
+		fee, err := strconv.Atoi(values["totalFee"])
+		if err != nil || fee < 0 {
+			record.addError(row, "totalFee", "Total fee must be a whole number of at least zero")
+		}
+		complain.TotalFee = &fee
+	} else if len(complain.ComplainUserDetails) > 0 {
+		complain.TotalFee = &totalFee
+	}
+
+	if len(record.Errors) == 0 {
+		record.Model = &complain
+	}
+}
+
+func (im *legacyComplainImporter) create(tx *gorm.DB, record *legacyImportRecord) (uint, error) {
+	complain := *record.Model.(*models.Complain)
+	if err := tx.Create(&complain).Error; err != nil {
+		return 0, err
+	}
+
+	// Copy the order lines when the order is known, like complains filed in the system
+	var order models.Order
+	if err := tx.Preload("OrderDetails").Where("order_ginee_id = ?", complain.OrderGineeID).First(&order).Error; err == nil {
+		for _, detail := range order.OrderDetails {
+			if err := tx.Create(&models.ComplainProductDetail{
+				ComplainID: complain.ID,
+				ProductSKU: detail.SKU,
+				Quantity:   detail.Quantity,
+				Price:      detail.Price,
+			}).Error; err != nil {
+				return 0, err
+			}
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	if err := tx.Model(&models.Outbound{}).Where("tracking_number = ?", complain.TrackingNumber).Update("complained", true).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&models.Order{}).Where("tracking_number = ? OR order_ginee_id = ?", complain.TrackingNumber, complain.OrderGineeID).
+		Update("complained", true).Error; err != nil {
+		return 0, err
+	}
+	return complain.ID, nil
+}
+
+// legacyAttendanceImporter imports the daily attendances of users, one row per user and day
+type legacyAttendanceImporter struct {
+	opts      LegacyImportOptions
+	users     map[string]models.User
+	locations map[string]models.Location
+	existing  map[string]bool // user ID and date of attendances already in the system
+}
+
+// legacyAttendanceDateLayout is the date part of the legacy key of attendances
+const legacyAttendanceDateLayout = "2006-01-02"
+
+func (im *legacyAttendanceImporter) key(row LegacyImportRow) string {
+	username := NormalizeUsername(row.Values["username"])
+	date, err := im.opts.parseLegacyDate(row.Values["date"])
+	if username == "" || err != nil {
+		return ""
+	}
+	return username + "@" + date.Format(legacyAttendanceDateLayout)
+}
+
+func (im *legacyAttendanceImporter) load(db *gorm.DB, records []*legacyImportRecord) error {
+	usernames := map[string]bool{}
+	var first, last time.Time
+	for _, record := range records {
+		username, date, _ := strings.Cut(record.Key, "@")
+		usernames[username] = true
+		day, _ := time.ParseInLocation(legacyAttendanceDateLayout, date, time.Local)
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+
+	var err error
+	if im.users, err = loadLegacyUsers(db, usernames); err != nil {
+		return err
+	}
+
+	var locations []models.Location
+	if err := db.Find(&locations).Error; err != nil {
+		return err
+	}
+	im.locations = map[string]models.Location{}
+	for _, location := range locations {
+		im.locations[strings.ToLower(location.Name)] = location
+	}
+
+	im.existing = map[string]bool{}
+	if len(im.users) == 0 {
+		return nil
+	}
+	userIDs := make([]uint, 0, len(im.users))
+	for _, user := range im.users {
+		userIDs = append(userIDs, user.ID)
+	}
+	var attendances []models.Attendance
+	if err := db.Select("user_id", "checked_in").
+		Where("user_id IN ? AND checked_in >= ? AND checked_in < ?", userIDs, first, last.AddDate(0, 0, 1)).
+		Find(&attendances).Error; err != nil {
+		return err
+	}
+	for _, attendance := range attendances {
+		im.existing[fmt.Sprintf("%d@%s", attendance.UserID, attendance.CheckedIn.In(time.Local).Format(legacyAttendanceDateLayout))] = true
+	}
+	return nil
+}
+
+// parseLegacyClock reads an HH:MM or HH:MM:SS time on the day
+func parseLegacyClock(day time.Time, value string) (time.Time, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return time.Date(day.Year(), day.Month(), day.Day(), parsed.Hour(), parsed.Minute(), parsed.Second(), 0, time.Local), nil
+		}
+	}
+	return time.Time{}, errors.New("Invalid time " + value + ", use HH:MM")
+}
+
+func (im *legacyAttendanceImporter) build(record *legacyImportRecord) {
+	row := record.Rows[0]
+	values := row.Values
+
+	username, date, _ := strings.Cut(record.Key, "@")
+	day, _ := time.ParseInLocation(legacyAttendanceDateLayout, date, time.Local)
+
+	attendance := models.Attendance{
+		Status:         "fullday",
+		Checked:        true,
+		EntryMethod:    models.EntryMethodLegacy,
+		OvertimeStatus: models.OvertimeStatusNone,
+	}
+	if err := im.opts.checkCutoff(day); err != nil {
+		record.addError(row, "date", err.Error())
+	}
+
+	user, ok := im.users[username]
+	if !ok {
+		record.addError(row, "username", "Unknown user")
+	} else {
+		attendance.UserID = user.ID
+		if im.existing[fmt.Sprintf("%d@%s", user.ID, date)] {
+			record.addError(row, "date", "User already has an attendance on this date")
+		}
+	}
+
+	if location, ok := im.locations[strings.ToLower(values["location"])]; ok {
+		attendance.LocationID = location.ID
+		attendance.Latitude = location.Latitude
+		attendance.Longitude = location.Longitude
+	} else if values["location"] == "" {
+		record.addError(row, "location", "location is required")
+	} else {
+		record.addError(row, "location", "Unknown location")
+	}
+
+	if status := strings.ToLower(values["status"]); status != "" {
+		if status != "fullday" && status != "halfday" {
+			record.addError(row, "status", "Status must be fullday or halfday")
+		}
+		attendance.Status = status
+	}
+
+	if values["checkIn"] == "" {
+		record.addError(row, "checkIn", "checkIn is required")
+	} else if checkIn, err := parseLegacyClock(day, values["checkIn"]); err != nil {
+		record.addError(row, "checkIn", err.Error())
+	} else {
+		attendance.CheckedIn = checkIn
+	}
+	if values["checkOut"] != "" {
+		checkOut, err := parseLegacyClock(day, values["checkOut"])
+		if err != nil {
+			record.addError(row, "checkOut", err.Error())
+		} else {
+			// Night shifts check out on the next day
+			if !attendance.CheckedIn.IsZero() && checkOut.Before(attendance.CheckedIn) {
+				checkOut = checkOut.AddDate(0, 0, 1)
+			}
+			attendance.CheckedOut = &checkOut
+		}
+	}
+
+	for _, column := range []string{"late", "overtime"} {
+		if values[column] == "" {
+			continue
+		}
+		minutes, err := strconv.Atoi(values[column])
+		if err != nil || minutes < 0 {
+			record.addError(row, column, column+" must be a whole number of minutes")
+			continue
+		}
+		if column == "late" {
+			attendance.Late = minutes
+		} else if minutes > 0 {
+			// Overtime was approved in the legacy system, only approved minutes were recorded
+			attendance.Overtime = minutes
+			attendance.ApprovedOvertime = minutes
+			attendance.OvertimeStatus = models.OvertimeStatusApproved
+		}
+	}
+
+	if len(record.Errors) == 0 {
+		record.Model = &attendance
+	}
+}
+
+func (im *legacyAttendanceImporter) create(tx *gorm.DB, record *legacyImportRecord) (uint, error) {
+	attendance := *record.Model.(*models.Attendance)
+	if err := tx.Create(&attendance).Error; err != nil {
+		return 0, err
+	}
+	return attendance.ID, nil
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxLoginGuardEntries triggers a sweep of stale entries when the tracker grows past it
+const maxLoginGuardEntries = 10000
+
+// maxLoginGuardBan caps the escalating ban duration
+const maxLoginGuardBan = 24 * time.Hour
+
+// LoginGuardScopeStats represents the brute-force counters of one protected endpoint
+type LoginGuardScopeStats struct {
+	Scope           string `json:"scope"`
+	Failures        int64  `json:"failures"`
+	Bans            int64  `json:"bans"`
+	BlockedRequests int64  `json:"blockedRequests"`
+	RateLimited     int64  `json:"rateLimited"`
+	ActiveBans      int    `json:"activeBans"`
+}
+
+// LoginGuardStats represents the brute-force counters exposed on the metrics endpoint
+type LoginGuardStats struct {
+	Scopes []LoginGuardScopeStats `json:"scopes"`
+}
+
+// loginAttempt tracks failed credential checks for one scope + username + IP
+type loginAttempt struct {
+	scope       string
+	failures    int
+	windowStart time.Time
+	bans        int
+	bannedUntil time.Time
+}
+
+var (
+	loginGuardMu       sync.Mutex
+	loginGuardAttempts = make(map[string]*loginAttempt)
+	loginGuardCounters = make(map[string]*LoginGuardScopeStats)
+)
+
+func loginGuardKey(scope, username, ip string) string {
+	return scope + "|" + username + "|" + ip
+}
+
+func loginGuardScope(scope string) *LoginGuardScopeStats {
+	stats, ok := loginGuardCounters[scope]
+	if !ok {
+		stats = &LoginGuardScopeStats{Scope: scope}
+		loginGuardCounters[scope] = stats
+	}
+	return stats
+}
+
+// LoginBannedUntil returns when the ban on a scope + username + IP ends, or the zero time when it is not banned
+func LoginBannedUntil(scope, username, ip string) time.Time {
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+
+	attempt, ok := loginGuardAttempts[loginGuardKey(scope, username, ip)]
+	if !ok || time.Now().After(attempt.bannedUntil) {
+		return time.Time{}
+	}
+	loginGuardScope(scope).BlockedRequests++
+	return attempt.bannedUntil
+}
+
+// RecordLoginFailure counts a failed credential check and bans the scope + username + IP once
+// maxFailures is reached within the window. Each repeated ban doubles in length.
+func RecordLoginFailure(scope, username, ip string, maxFailures int, window, ban time.Duration) {
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+
+	now := time.Now()
+	key := loginGuardKey(scope, username, ip)
+	attempt, ok := loginGuardAttempts[key]
+	if !ok {
+		if len(loginGuardAttempts) >= maxLoginGuardEntries {
+			sweepLoginGuard(now, window)
+		}
+		attempt = &loginAttempt{scope: scope, windowStart: now}
+		loginGuardAttempts[key] = attempt
+	}
+
+	if now.Sub(attempt.windowStart) > window {
+		attempt.failures = 0
+		attempt.windowStart = now
+	}
+	attempt.failures++
+
+	stats := loginGuardScope(scope)
+	stats.Failures++
+
+	if attempt.failures >= maxFailures {
+		attempt.bans++
+		duration := ban << (attempt.bans - 1)
+		if duration > maxLoginGuardBan || duration <= 0 {
+			duration = maxLoginGuardBan
+		}
+		attempt.bannedUntil = now.Add(duration)
+		attempt.failures = 0
+		attempt.windowStart = now
+		stats.Bans++
+	}
+}
+
+// RecordLoginSuccess clears the failure count of a scope + username + IP after a successful credential check
+func RecordLoginSuccess(scope, username, ip string) {
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+	delete(loginGuardAttempts, loginGuardKey(scope, username, ip))
+}
+
+// RecordRateLimited counts a request rejected by the per-endpoint rate limiter
+func RecordRateLimited(scope string) {
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+	loginGuardScope(scope).RateLimited++
+}
+
+// sweepLoginGuard removes entries that are neither banned nor within the failure window. Caller holds the lock.
+func sweepLoginGuard(now time.Time, window time.Duration) {
+	for key, attempt := range loginGuardAttempts {
+		if now.After(attempt.bannedUntil) && now.Sub(attempt.windowStart) > window {
+			delete(loginGuardAttempts, key)
+		}
+	}
+}
+
+// GetLoginGuardStats returns the brute-force counters per protected endpoint
+func GetLoginGuardStats() LoginGuardStats {
+	loginGuardMu.Lock()
+	defer loginGuardMu.Unlock()
+
+	now := time.Now()
+	active := make(map[string]int)
+	for _, attempt := range loginGuardAttempts {
+		if now.Before(attempt.bannedUntil) {
+			active[attempt.scope]++
+		}
+	}
+
+	stats := LoginGuardStats{Scopes: make([]LoginGuardScopeStats, 0, len(loginGuardCounters))}
+	for scope, counters := range loginGuardCounters {
+		scopeStats := *counters
+		scopeStats.ActiveBans = active[scope]
+		stats.Scopes = append(stats.Scopes, scopeStats)
+	}
+	sort.Slice(stats.Scopes, func(i, j int) bool {
+		return stats.Scopes[i].Scope < stats.Scopes[j].Scope
+	})
+	return stats
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"livo-fiber-backend/config"
+	"log"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+)
+
+// SendEmail sends a plain text email through the configured SMTP server.
+// When no SMTP host is configured the email is logged instead, which keeps development setups working.
+func SendEmail(cfg *config.Config, to, subject, body string) error {
+	if cfg.SMTPHost == "" {
+		log.Printf("SendEmail - SMTP not configured, email to %s not sent. Subject: %s\n%s", to, subject, body)
+		return nil
+	}
+
+	headers := []string{
+		"From: " + cfg.MailFrom,
+		"To: " + to,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+	}
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + body
+
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	addr := fmt.Sprintf("%s:%s", cfg.SMTPHost, cfg.SMTPPort)
+	if err := smtp.SendMail(addr, auth, cfg.MailFrom, []string{to}, []byte(message)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// SendEmailWithAttachment sends a plain text email with a single file attachment to several recipients.
+// When no SMTP host is configured the email is logged without the attachment instead.
+func SendEmailWithAttachment(cfg *config.Config, to []string, subject, body string, attachment EmailAttachment) error {
+	if cfg.SMTPHost == "" {
+		log.Printf("SendEmailWithAttachment - SMTP not configured, email to %s not sent. Subject: %s, attachment: %s (%d bytes)\n%s",
+			strings.Join(to, ", "), subject, attachment.Filename, len(attachment.Data), body)
+		return nil
+	}
+
+	var content bytes.Buffer
+	writer := multipart.NewWriter(&content)
+
+	// Plain text body
+	bodyPart, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain; charset=\"utf-8\""}})
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	if _, err := bodyPart.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	// Base64 encoded attachment, wrapped at 76 characters per line
+	attachmentPart, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {attachment.ContentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {fmt.Sprintf("attachment; filename=\"%s\"", attachment.Filename)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	for len(encoded) > 76 {
+		if _, err := attachmentPart.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return fmt.Errorf("failed to build email: %w", err)
+		}
+		encoded = encoded[76:]
+	}
+	if _, err := attachmentPart.Write([]byte(encoded)); err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+
+	headers := []string{
+		"From: " + cfg.MailFrom,
+		"To: " + strings.Join(to, ", "),
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary=\"" + writer.Boundary() + "\"",
+	}
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + content.String()
+
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	addr := fmt.Sprintf("%s:%s", cfg.SMTPHost, cfg.SMTPPort)
+	if err := smtp.SendMail(addr, auth, cfg.MailFrom, to, []byte(message)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultMaintenanceMessage is shown to clients when no custom message is set
+const DefaultMaintenanceMessage = "The system is under maintenance and is read-only. Please try again later."
+
+// MaintenanceState describes whether the API currently rejects mutating requests
+type MaintenanceState struct {
+	Enabled   bool       `json:"enabled"`
+	Message   string     `json:"message"`
+	Since     *time.Time `json:"since,omitempty"`
+	EnabledBy string     `json:"enabledBy,omitempty"`
+}
+
+var (
+	maintenanceMu    sync.RWMutex
+	maintenanceState MaintenanceState
+)
+
+// SetMaintenance switches maintenance (read-only) mode on or off at runtime
+func SetMaintenance(enabled bool, message, enabledBy string) MaintenanceState {
+	maintenanceMu.Lock()
+	defer maintenanceMu.Unlock()
+
+	if message == "" {
+		message = DefaultMaintenanceMessage
+	}
+
+	if !enabled {
+		maintenanceState = MaintenanceState{Enabled: false, Message: message}
+		return maintenanceState
+	}
+
+	// Keep the original start time when only the message changes
+	since := time.Now()
+	if maintenanceState.Enabled && maintenanceState.Since != nil {
+		since = *maintenanceState.Since
+	}
+	maintenanceState = MaintenanceState{
+		Enabled:   true,
+		Message:   message,
+		Since:     &since,
+		EnabledBy: enabledBy,
+	}
+	return maintenanceState
+}
+
+// GetMaintenance returns the current maintenance state
+func GetMaintenance() MaintenanceState {
+	maintenanceMu.RLock()
+	defer maintenanceMu.RUnlock()
+	return maintenanceState
+}
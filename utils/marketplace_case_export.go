@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// caseExportColumns lists the accepted headers of each field of a marketplace case export,
+// in the English and Indonesian seller center languages
+type caseExportColumns struct {
+	CaseID         []string
+	TrackingNumber []string
+	Reason         []string
+	BuyerText      []string
+}
+
+// marketplaceCaseExport parses the weekly case export CSV of a marketplace
+type marketplaceCaseExport struct {
+	Columns   caseExportColumns
+	Dispute   func(caseID, trackingNumber, reason, buyerText string) *MarketplaceDispute
+	Reasons   map[string]disputeReasonMapping
+	ReasonKey func(string) string // case of the reason codes
+}
+
+var marketplaceCaseExports = map[string]marketplaceCaseExport{
+	"shopee": {
+		Columns: caseExportColumns{
+			CaseID:         []string{"return id", "return sn", "no. pengembalian", "id pengembalian"},
+			TrackingNumber: []string{"tracking number", "no. resi", "nomor resi"},
+			Reason:         []string{"return reason", "reason", "alasan pengembalian"},
+			BuyerText:      []string{"reason description", "buyer's reason", "text reason", "deskripsi alasan"},
+		},
+		Dispute:   shopeeDispute,
+		Reasons:   shopeeDisputeReasons,
+		ReasonKey: strings.ToUpper,
+	},
+	"tokopedia": {
+		Columns: caseExportColumns{
+			CaseID:         []string{"resolution id", "complaint id", "no. komplain", "id komplain"},
+			TrackingNumber: []string{"awb", "airway bill", "no. resi", "nomor resi"},
+			Reason:         []string{"trouble type", "problem", "jenis masalah"},
+			BuyerText:      []string{"buyer complaint", "complaint detail", "keluhan pembeli"},
+		},
+		Dispute:   tokopediaDispute,
+		Reasons:   tokopediaDisputeReasons,
+		ReasonKey: strings.ToLower,
+	},
+}
+
+// SupportsMarketplaceCaseExport reports whether case exports of the marketplace can be parsed
+func SupportsMarketplaceCaseExport(marketplace string) bool {
+	_, ok := marketplaceCaseExports[strings.ToLower(marketplace)]
+	return ok
+}
+
+// MarketplaceCaseExportRow is a case of a marketplace case export, Error is set when the row cannot be imported
+type MarketplaceCaseExportRow struct {
+	Row     int // spreadsheet row number, the header is row 1
+	Dispute *MarketplaceDispute
+	Error   string
+}
+
+// ParseMarketplaceCaseExport maps the rows of a marketplace case export to disputes using its header row.
+// Blank rows are skipped, rows missing the case ID or tracking number are returned with an error.
+func ParseMarketplaceCaseExport(marketplace string, records [][]string) ([]MarketplaceCaseExportRow, error) {
+	export, ok := marketplaceCaseExports[strings.ToLower(marketplace)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported marketplace %s", marketplace)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	headers := make(map[string]int, len(records[0]))
+	for i, header := range records[0] {
+		headers[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header, "\ufeff")))] = i
+	}
+	findColumn := func(names []string) int {
+		for _, name := range names {
+			if index, ok := headers[name]; ok {
+				return index
+			}
+		}
+		return -1
+	}
+	caseIDColumn := findColumn(export.Columns.CaseID)
+	trackingColumn := findColumn(export.Columns.TrackingNumber)
+	reasonColumn := findColumn(export.Columns.Reason)
+	buyerTextColumn := findColumn(export.Columns.BuyerText)
+
+	var missing []string
+	if caseIDColumn < 0 {
+		missing = append(missing, export.Columns.CaseID[0])
+	}
+	if trackingColumn < 0 {
+		missing = append(missing, export.Columns.TrackingNumber[0])
+	}
+	if reasonColumn < 0 {
+		missing = append(missing, export.Columns.Reason[0])
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+	}
+
+	value := func(record []string, index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	rows := make([]MarketplaceCaseExportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		caseID, trackingNumber := value(record, caseIDColumn), value(record, trackingColumn)
+		reason, buyerText := value(record, reasonColumn), value(record, buyerTextColumn)
+		// Skip blank lines left at the end of exports
+		if caseID == "" && trackingNumber == "" && reason == "" {
+			continue
+		}
+
+		// Exports show the reason as a label, matched against the reason codes of the webhooks
+		reasonCode := export.ReasonKey(caseExportReasonCode(reason))
+		if _, mapped := export.Reasons[reasonCode]; !mapped && reason != "" {
+			// Keep the marketplace's own reason in the complain when it has no mapping
+			if buyerText == "" {
+				buyerText = reason
+			} else {
+				buyerText = reason + " - " + buyerText
+			}
+		}
+
+		row := MarketplaceCaseExportRow{Row: i + 2, Dispute: export.Dispute(caseID, trackingNumber, reasonCode, buyerText)}
+		if err := normalizeDispute(row.Dispute); err != nil {
+			row.Error = err.Error()
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// caseExportReasonCode turns a reason label like "Wrong Product" into the code form "wrong_product"
+func caseExportReasonCode(reason string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(reason), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), "_")
+}
@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"strings"
+)
+
+// MarketplaceDispute is a marketplace dispute (return/refund case) notification normalized across channels
+type MarketplaceDispute struct {
+	CaseID         string // marketplace case ID, unique per channel
+	TrackingNumber string
+	Reason         string // complain reason mapped from the marketplace case
+	RootCause      string // complain root cause code mapped from the marketplace case
+}
+
+// disputeReasonMapping is the complain reason and root cause code of a marketplace return reason
+type disputeReasonMapping struct {
+	Reason    string
+	RootCause string
+}
+
+// unmappedDisputeReason is used for marketplace reasons without a mapping
+var unmappedDisputeReason = disputeReasonMapping{"Marketplace dispute", models.ComplainRootCauseOther}
+
+// marketplaceDisputeAdapters parses the dispute webhook payload of each supported marketplace
+var marketplaceDisputeAdapters = map[string]func(body []byte) (*MarketplaceDispute, error){
+	"shopee":    parseShopeeDispute,
+	"tokopedia": parseTokopediaDispute,
+}
+
+// SupportsMarketplaceDispute reports whether dispute webhooks of the marketplace can be parsed
+func SupportsMarketplaceDispute(marketplace string) bool {
+	_, ok := marketplaceDisputeAdapters[strings.ToLower(marketplace)]
+	return ok
+}
+
+// ParseMarketplaceDispute normalizes a dispute webhook payload of the given marketplace
+func ParseMarketplaceDispute(marketplace string, body []byte) (*MarketplaceDispute, error) {
+	adapter, ok := marketplaceDisputeAdapters[strings.ToLower(marketplace)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported marketplace %s", marketplace)
+	}
+
+	dispute, err := adapter(body)
+	if err != nil {
+		return nil, err
+	}
+	if err := normalizeDispute(dispute); err != nil {
+		return nil, fmt.Errorf("%w from the payload", err)
+	}
+	return dispute, nil
+}
+
+// normalizeDispute trims the case ID and normalizes the tracking number, both are required
+func normalizeDispute(dispute *MarketplaceDispute) error {
+	dispute.CaseID = strings.TrimSpace(dispute.CaseID)
+	dispute.TrackingNumber = models.NormalizeIdentifier(dispute.TrackingNumber)
+	if dispute.CaseID == "" {
+		return errors.New("case ID is missing")
+	}
+	if dispute.TrackingNumber == "" {
+		return errors.New("tracking number is missing")
+	}
+	return nil
+}
+
+// disputeReason builds the complain reason from the mapped marketplace reason and the buyer's own words
+func disputeReason(marketplace, caseID, mapped, buyerText string) string {
+	reason := fmt.Sprintf("%s (%s case %s)", mapped, marketplace, caseID)
+	if buyerText = strings.TrimSpace(buyerText); buyerText != "" {
+		reason += ": " + buyerText
+	}
+	return reason
+}
+
+// shopeeDisputeReasons maps Shopee return reasons to complain reasons and root causes
+var shopeeDisputeReasons = map[string]disputeReasonMapping{
+	"NOT_RECEIPT":           {"Item not received", models.ComplainRootCauseCourierIssue},
+	"WRONG_ITEM":            {"Wrong item sent", models.ComplainRootCauseWrongItem},
+	"ITEM_DAMAGED":          {"Item damaged", models.ComplainRootCauseDamaged},
+	"PHYSICAL_DMG":          {"Item damaged", models.ComplainRootCauseDamaged},
+	"FUNCTIONAL_DMG":        {"Item not working", models.ComplainRootCauseProductQuality},
+	"ITEM_MISSING":          {"Item missing from parcel", models.ComplainRootCauseMissingItem},
+	"DIFFERENT_DESCRIPTION": {"Item differs from description", models.ComplainRootCauseWrongItem},
+	"ITEM_FAKE":             {"Item reported as counterfeit", models.ComplainRootCauseProductQuality},
+	"EXPECTATION_FAILED":    {"Item did not meet expectation", models.ComplainRootCauseBuyerRelated},
+	"CHANGE_MIND":           {"Buyer changed mind", models.ComplainRootCauseBuyerRelated},
+	"MUTUAL_AGREE":          {"Return agreed with buyer", models.ComplainRootCauseBuyerRelated},
+}
+
+// shopeeDisputePayload is the Shopee return push notification
+type shopeeDisputePayload struct {
+	Data struct {
+		ReturnSN       string `json:"return_sn"`
+		TrackingNumber string `json:"tracking_number"`
+		Reason         string `json:"reason"`
+		TextReason     string `json:"text_reason"`
+	} `json:"data"`
+}
+
+func parseShopeeDispute(body []byte) (*MarketplaceDispute, error) {
+	var payload shopeeDisputePayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid Shopee payload")
+	}
+
+	return shopeeDispute(payload.Data.ReturnSN, payload.Data.TrackingNumber, payload.Data.Reason, payload.Data.TextReason), nil
+}
+
+// shopeeDispute builds the dispute of a Shopee return from its return reason code
+func shopeeDispute(returnSN, trackingNumber, reason, textReason string) *MarketplaceDispute {
+	mapped, ok := shopeeDisputeReasons[strings.ToUpper(reason)]
+	if !ok {
+		mapped = unmappedDisputeReason
+	}
+	return &MarketplaceDispute{
+		CaseID:         returnSN,
+		TrackingNumber: trackingNumber,
+		Reason:         disputeReason("Shopee", returnSN, mapped.Reason, textReason),
+		RootCause:      mapped.RootCause,
+	}
+}
+
+// tokopediaDisputeReasons maps Tokopedia resolution center trouble types to complain reasons and root causes
+var tokopediaDisputeReasons = map[string]disputeReasonMapping{
+	"product_not_received":  {"Item not received", models.ComplainRootCauseCourierIssue},
+	"wrong_product":         {"Wrong item sent", models.ComplainRootCauseWrongItem},
+	"damaged_product":       {"Item damaged", models.ComplainRootCauseDamaged},
+	"defective_product":     {"Item not working", models.ComplainRootCauseProductQuality},
+	"incomplete_product":    {"Item missing from parcel", models.ComplainRootCauseMissingItem},
+	"product_not_as_listed": {"Item differs from description", models.ComplainRootCauseWrongItem},
+	"counterfeit_product":   {"Item reported as counterfeit", models.ComplainRootCauseProductQuality},
+}
+
+// tokopediaDisputePayload is the Tokopedia resolution center webhook
+type tokopediaDisputePayload struct {
+	ResolutionID   json.Number `json:"resolution_id"`
+	AWB            string      `json:"awb"`
+	TroubleType    string      `json:"trouble_type"`
+	BuyerComplaint string      `json:"buyer_complaint"`
+}
+
+func parseTokopediaDispute(body []byte) (*MarketplaceDispute, error) {
+	var payload tokopediaDisputePayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, errors.New("invalid Tokopedia payload")
+	}
+
+	return tokopediaDispute(payload.ResolutionID.String(), payload.AWB, payload.TroubleType, payload.BuyerComplaint), nil
+}
+
+// tokopediaDispute builds the dispute of a Tokopedia resolution from its trouble type
+func tokopediaDispute(resolutionID, awb, troubleType, buyerComplaint string) *MarketplaceDispute {
+	mapped, ok := tokopediaDisputeReasons[strings.ToLower(troubleType)]
+	if !ok {
+		mapped = unmappedDisputeReason
+	}
+	return &MarketplaceDispute{
+		CaseID:         resolutionID,
+		TrackingNumber: awb,
+		Reason:         disputeReason("Tokopedia", resolutionID, mapped.Reason, buyerComplaint),
+		RootCause:      mapped.RootCause,
+	}
+}
@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"strings"
+)
+
+// Masking profiles applied to buyer personal data in exports and reports, from least to most masked
+const (
+	MaskingFull       = "full"       // values as recorded
+	MaskingPartial    = "partial"    // enough left to recognize the buyer, e.g. B*** S*** and the city of the address
+	MaskingAnonymized = "anonymized" // buyer replaced by a stable pseudonym, address removed
+)
+
+// maskingLevels orders the masking profiles from least to most masked
+var maskingLevels = map[string]int{MaskingFull: 0, MaskingPartial: 1, MaskingAnonymized: 2}
+
+var (
+	ErrInvalidMaskingProfile    = errors.New("invalid masking profile")
+	ErrMaskingProfileNotAllowed = errors.New("masking profile not allowed for your role")
+)
+
+// IsMaskingProfile reports whether the value is a known masking profile
+func IsMaskingProfile(value string) bool {
+	_, ok := maskingLevels[value]
+	return ok
+}
+
+// MaskingPolicy holds the least masked profile each role may export buyer data with, other roles get anonymized data
+type MaskingPolicy struct {
+	FullRoles    []string
+	PartialRoles []string
+}
+
+// MaskingPolicyFromConfig builds the masking policy from the application config
+func MaskingPolicyFromConfig(cfg *config.Config) MaskingPolicy {
+	return MaskingPolicy{
+		FullRoles:    cfg.MaskingFullRoles,
+		PartialRoles: cfg.MaskingPartialRoles,
+	}
+}
+
+// ProfileForRoles returns the least masked profile any of the roles is allowed
+func (p MaskingPolicy) ProfileForRoles(roles []string) string {
+	profile := MaskingAnonymized
+	for _, role := range roles {
+		for _, fullRole := range p.FullRoles {
+			if role == fullRole {
+				return MaskingFull
+			}
+		}
+		for _, partialRole := range p.PartialRoles {
+			if role == partialRole {
+				profile = MaskingPartial
+			}
+		}
+	}
+	return profile
+}
+
+// Resolve returns the masking profile to export with, the roles' profile when none is requested.
+// A requested profile may mask more than the roles' profile but not less.
+func (p MaskingPolicy) Resolve(roles []string, requested string) (string, error) {
+	allowed := p.ProfileForRoles(roles)
+	if requested == "" {
+		return allowed, nil
+	}
+	if !IsMaskingProfile(requested) {
+		return "", ErrInvalidMaskingProfile
+	}
+	if maskingLevels[requested] < maskingLevels[allowed] {
+		return "", ErrMaskingProfileNotAllowed
+	}
+	return requested, nil
+}
+
+// MaskName masks a buyer name, partial keeps the first letter of each word and anonymized returns a pseudonym
+// that stays the same for the same name so orders of one buyer can still be grouped
+func MaskName(profile, name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return name
+	}
+	switch profile {
+	case MaskingFull:
+		return name
+	case MaskingPartial:
+		words := strings.Fields(name)
+		for i, word := range words {
+			words[i] = string([]rune(word)[0]) + "***"
+		}
+		return strings.Join(words, " ")
+	default:
+		sum := sha256.Sum256([]byte(strings.ToLower(name)))
+		return "Buyer " + hex.EncodeToString(sum[:4])
+	}
+}
+
+// MaskAddress masks a shipping address, partial keeps the last two comma separated parts, usually the city and
+// province, and anonymized removes it
+func MaskAddress(profile, address string) string {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return address
+	}
+	switch profile {
+	case MaskingFull:
+		return address
+	case MaskingPartial:
+		parts := strings.Split(address, ",")
+		if len(parts) <= 2 {
+			return "***"
+		}
+		kept := make([]string, 0, 2)
+		for _, part := range parts[len(parts)-2:] {
+			kept = append(kept, strings.TrimSpace(part))
+		}
+		return "***, " + strings.Join(kept, ", ")
+	default:
+		return "***"
+	}
+}
+
+// MaskOrderResponse masks the buyer data of an order response, partial keeps the city and province of the
+// normalized address and anonymized clears every regional field
+func MaskOrderResponse(profile string, order *models.OrderResponse) {
+	if order == nil || profile == MaskingFull {
+		return
+	}
+	order.Buyer = MaskName(profile, order.Buyer)
+	order.Address = MaskAddress(profile, order.Address)
+	order.District = ""
+	order.PostalCode = ""
+	if profile != MaskingPartial {
+		order.City = ""
+		order.Province = ""
+	}
+}
+
+// MaskOrderAddressChangeResponse masks the previous and new addresses of an address change like MaskOrderResponse
+func MaskOrderAddressChangeResponse(profile string, change *models.OrderAddressChangeResponse) {
+	if change == nil || profile == MaskingFull {
+		return
+	}
+	change.PreviousAddress = MaskAddress(profile, change.PreviousAddress)
+	change.NewAddress = MaskAddress(profile, change.NewAddress)
+	change.PreviousDistrict = ""
+	change.PreviousPostalCode = ""
+	change.NewDistrict = ""
+	change.NewPostalCode = ""
+	if profile != MaskingPartial {
+		change.PreviousCity = ""
+		change.PreviousProvince = ""
+		change.NewCity = ""
+		change.NewProvince = ""
+	}
+}
+
+// MaskText masks the buyer name and address wherever they appear in a free text such as an issue description
+func MaskText(profile, text, name, address string) string {
+	if profile == MaskingFull {
+		return text
+	}
+	if address = strings.TrimSpace(address); address != "" {
+		text = strings.ReplaceAll(text, address, MaskAddress(profile, address))
+	}
+	if name = strings.TrimSpace(name); name != "" {
+		text = strings.ReplaceAll(text, name, MaskName(profile, name))
+	}
+	return text
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// NotifyRoles creates a notification for every active user holding one of the given roles
+func NotifyRoles(db *gorm.DB, roles []string, notificationType, title, message, referenceType string, referenceID uint) error {
+	var userIDs []uint
+	if err := db.Model(&models.User{}).
+		Distinct("users.id").
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.role_name IN ? AND users.is_active = ?", roles, true).
+		Pluck("users.id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	return NotifyUsers(db, userIDs, notificationType, title, message, referenceType, referenceID)
+}
+
+// NotifyUsers creates the same notification in the inbox of each given user
+func NotifyUsers(db *gorm.DB, userIDs []uint, notificationType, title, message, referenceType string, referenceID uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	notifications := make([]models.Notification, len(userIDs))
+	for i, userID := range userIDs {
+		notifications[i] = models.Notification{
+			UserID:        userID,
+			Type:          notificationType,
+			Title:         title,
+			Message:       message,
+			ReferenceType: referenceType,
+			ReferenceID:   referenceID,
+		}
+	}
+
+	return db.Create(&notifications).Error
+}
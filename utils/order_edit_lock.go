@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ActiveOrderEditLock returns the unexpired edit lock of the order with its holder, nil when nobody is editing the order
+func ActiveOrderEditLock(db *gorm.DB, orderID uint) *models.OrderEditLock {
+	var lock models.OrderEditLock
+	if err := db.Preload("LockUser").Where("order_id = ? AND expires_at > ?", orderID, time.Now()).First(&lock).Error; err != nil {
+		return nil
+	}
+	return &lock
+}
+
+// AcquireOrderEditLock takes the edit lock of the order for the user when it is free or expired, or renews it when the user already holds it.
+// Returns false when another user holds an unexpired lock.
+func AcquireOrderEditLock(db *gorm.DB, orderID, userID uint, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	lock := models.OrderEditLock{
+		OrderID:     orderID,
+		LockedBy:    userID,
+		AcquiredAt:  now,
+		HeartbeatAt: now,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	// Take over only expired locks, a renewal by the holder keeps its acquire time
+	result := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "order_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"locked_by":    gorm.Expr("excluded.locked_by"),
+			"acquired_at":  gorm.Expr("CASE WHEN order_edit_locks.locked_by = excluded.locked_by AND order_edit_locks.expires_at > excluded.heartbeat_at THEN order_edit_locks.acquired_at ELSE excluded.acquired_at END"),
+			"heartbeat_at": gorm.Expr("excluded.heartbeat_at"),
+			"expires_at":   gorm.Expr("excluded.expires_at"),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			gorm.Expr("order_edit_locks.locked_by = excluded.locked_by OR order_edit_locks.expires_at <= excluded.heartbeat_at"),
+		}},
+	}).Create(&lock)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// HeartbeatOrderEditLock extends the unexpired edit lock the user holds on the order.
+// Returns false when the user does not hold the lock anymore.
+func HeartbeatOrderEditLock(db *gorm.DB, orderID, userID uint, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	result := db.Model(&models.OrderEditLock{}).
+		Where("order_id = ? AND locked_by = ? AND expires_at > ?", orderID, userID, now).
+		Updates(map[string]interface{}{"heartbeat_at": now, "expires_at": now.Add(ttl)})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseOrderEditLock removes the edit lock the user holds on the order, returns false when the user does not hold it
+func ReleaseOrderEditLock(db *gorm.DB, orderID, userID uint) (bool, error) {
+	result := db.Where("order_id = ? AND locked_by = ?", orderID, userID).Delete(&models.OrderEditLock{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
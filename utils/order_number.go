@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// orderNumberPrefixPattern allows uppercase letters, digits and inner dashes, e.g. LIVO or LIVO-TKP
+var orderNumberPrefixPattern = regexp.MustCompile(`^[A-Z0-9]+(-[A-Z0-9]+)*$`)
+
+// maxOrderNumberAttempts bounds the sequence values skipped because an order already uses the number
+const maxOrderNumberAttempts = 20
+
+// InternalTrackingPrefix starts the internal tracking numbers given to duplicated orders, e.g. LVINT2026000123
+const InternalTrackingPrefix = "LVINT"
+
+// internalTrackingSequence is the sequence row of internal tracking numbers, it is not a valid order number prefix
+// so it never shares a sequence with the order numbers
+const internalTrackingSequence = "#tracking"
+
+// NormalizeOrderNumberPrefix uppercases and trims an order number prefix, reporting whether it is valid.
+// An empty prefix is valid and means the prefix is inherited.
+func NormalizeOrderNumberPrefix(prefix string) (string, bool) {
+	prefix = strings.ToUpper(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return prefix, true
+	}
+	return prefix, len(prefix) <= 20 && orderNumberPrefixPattern.MatchString(prefix)
+}
+
+// OrderNumberPrefix returns the prefix of internally generated order numbers of an order:
+// the prefix of its store, otherwise of its channel, otherwise the default prefix
+func OrderNumberPrefix(db *gorm.DB, order *models.Order, defaultPrefix string) string {
+	if store := FindOrderStore(db, order); store != nil && store.OrderNumberPrefix != "" {
+		return store.OrderNumberPrefix
+	}
+	if channel := FindOrderChannel(db, order); channel != nil && channel.OrderNumberPrefix != "" {
+		return channel.OrderNumberPrefix
+	}
+	return defaultPrefix
+}
+
+// NextOrderNumber issues the next internal order number of a prefix, formatted as PREFIX-YYYY-000123.
+// The sequence row is incremented atomically and stays locked until the transaction ends, so concurrent
+// orders never get the same number. Numbers already used by an order, e.g. typed in by hand, are skipped.
+func NextOrderNumber(tx *gorm.DB, prefix string, at time.Time) (string, error) {
+	year := at.Year()
+	for attempt := 0; attempt < maxOrderNumberAttempts; attempt++ {
+		value, err := nextSequenceValue(tx, prefix, year)
+		if err != nil {
+			return "", err
+		}
+
+		number := fmt.Sprintf("%s-%d-%06d", prefix, year, value)
+		var count int64
+		if err := tx.Model(&models.Order{}).Where("order_ginee_id = ?", number).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return number, nil
+		}
+	}
+	return "", errors.New("no free order number for prefix " + prefix)
+}
+
+// NextInternalTrackingNumber issues the next internal tracking number, formatted as LVINT2026000123.
+// Numbers already used by an order are skipped, like order numbers.
+func NextInternalTrackingNumber(tx *gorm.DB, at time.Time) (string, error) {
+	year := at.Year()
+	for attempt := 0; attempt < maxOrderNumberAttempts; attempt++ {
+		value, err := nextSequenceValue(tx, internalTrackingSequence, year)
+		if err != nil {
+			return "", err
+		}
+
+		trackingNumber := fmt.Sprintf("%s%d%06d", InternalTrackingPrefix, year, value)
+		var count int64
+		if err := tx.Model(&models.Order{}).Where("tracking_number = ?", trackingNumber).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return trackingNumber, nil
+		}
+	}
+	return "", errors.New("no free internal tracking number")
+}
+
+// nextSequenceValue increments the sequence of a prefix in a year and returns its new value,
+// the sequence row stays locked until the transaction ends
+func nextSequenceValue(tx *gorm.DB, prefix string, year int) (int64, error) {
+	var value int64
+	err := tx.Raw(`INSERT INTO order_number_sequences (prefix, year, last_value, updated_at) VALUES (?, ?, 1, NOW())
+		ON CONFLICT (prefix, year) DO UPDATE SET last_value = order_number_sequences.last_value + 1, updated_at = NOW()
+		RETURNING last_value`, prefix, year).Scan(&value).Error
+	return value, err
+}
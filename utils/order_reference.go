@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Policies for orders naming a channel or store that is neither set up nor aliased
+const (
+	OrderReferencePolicyKeep   = "keep"   // store the name as given
+	OrderReferencePolicyCreate = "create" // create the channel or store flagged for admin review
+	OrderReferencePolicyReject = "reject" // refuse the order
+)
+
+// OrderReferencePolicies lists the unknown channel and store policies
+var OrderReferencePolicies = []string{OrderReferencePolicyKeep, OrderReferencePolicyCreate, OrderReferencePolicyReject}
+
+// OrderReferenceReviewRoles are notified of channels and stores created for review
+var OrderReferenceReviewRoles = []string{"developer", "superadmin", "admin"}
+
+// OrderReferencePolicy decides what happens to orders naming an unknown channel or store
+type OrderReferencePolicy struct {
+	Channel string
+	Store   string
+}
+
+// OrderReferencePolicyFromConfig builds the unknown channel and store policy, unknown values keep the name as given
+func OrderReferencePolicyFromConfig(cfg *config.Config) OrderReferencePolicy {
+	policy := OrderReferencePolicy{Channel: OrderReferencePolicyKeep, Store: OrderReferencePolicyKeep}
+	for _, known := range OrderReferencePolicies {
+		if cfg.UnknownChannelPolicy == known {
+			policy.Channel = known
+		}
+		if cfg.UnknownStorePolicy == known {
+			policy.Store = known
+		}
+	}
+	return policy
+}
+
+// UnknownReferenceError is returned when an order names an unknown channel or store under the reject policy
+type UnknownReferenceError struct {
+	Kind string
+	Name string
+}
+
+func (e *UnknownReferenceError) Error() string {
+	return fmt.Sprintf("Unknown %s %q, set it up or map it to an existing %s with an alias", e.Kind, e.Name, e.Kind)
+}
+
+// ResolveOrderReferences rewrites the channel and store of a new order to the name of the channel or store
+// they match by name, code or alias. Unknown names are handled by the policy.
+func ResolveOrderReferences(db *gorm.DB, order *models.Order, policy OrderReferencePolicy) error {
+	channel, err := resolveOrderReference(db, models.ReferenceKindChannel, order.Channel, policy.Channel)
+	if err != nil {
+		return err
+	}
+	store, err := resolveOrderReference(db, models.ReferenceKindStore, order.Store, policy.Store)
+	if err != nil {
+		return err
+	}
+	order.Channel = channel
+	order.Store = store
+	return nil
+}
+
+// resolveOrderReference returns the canonical name of a channel or store name
+func resolveOrderReference(db *gorm.DB, kind, name, policy string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return name, nil
+	}
+
+	canonical, found, err := FindReferenceName(db, kind, name)
+	if err != nil {
+		return name, err
+	}
+	if found {
+		return canonical, nil
+	}
+
+	switch policy {
+	case OrderReferencePolicyReject:
+		return name, &UnknownReferenceError{Kind: kind, Name: name}
+	case OrderReferencePolicyCreate:
+		if err := createPendingReference(db, kind, name); err != nil {
+			// Another order may have created it at the same time
+			if canonical, found, _ := FindReferenceName(db, kind, name); found {
+				return canonical, nil
+			}
+			return name, err
+		}
+	}
+	return name, nil
+}
+
+// FindReferenceName returns the name of the channel or store matching a name or code, directly or through an alias
+func FindReferenceName(db *gorm.DB, kind, name string) (string, bool, error) {
+	table, nameColumn, codeColumn := referenceColumns(kind)
+
+	var names []string
+	if err := db.Table(table).Where("LOWER("+nameColumn+") = LOWER(?) OR LOWER("+codeColumn+") = LOWER(?)", name, name).
+		Order("id ASC").Limit(1).Pluck(nameColumn, &names).Error; err != nil {
+		return "", false, err
+	}
+	if len(names) > 0 {
+		return names[0], true, nil
+	}
+
+	if err := db.Table(table).Where("id = (?)",
+		db.Model(&models.ReferenceAlias{}).Select("reference_id").Where("kind = ? AND alias = ?", kind, models.NormalizeReferenceAlias(name))).
+		Pluck(nameColumn, &names).Error; err != nil {
+		return "", false, err
+	}
+	if len(names) > 0 {
+		return names[0], true, nil
+	}
+	return "", false, nil
+}
+
+// ReferenceName returns the name of a channel or store by ID
+func ReferenceName(db *gorm.DB, kind string, id uint) (string, error) {
+	table, nameColumn, _ := referenceColumns(kind)
+	var names []string
+	if err := db.Table(table).Where("id = ?", id).Pluck(nameColumn, &names).Error; err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return names[0], nil
+}
+
+// RemapOrderReferences rewrites the channel or store of orders stored with a name to the canonical name and returns
+// the number of orders rewritten. The kind names the order column, it must be a valid reference kind.
+func RemapOrderReferences(db *gorm.DB, kind, name, canonical string) (int64, error) {
+	result := db.Model(&models.Order{}).
+		Where("LOWER(TRIM("+kind+")) = ?", models.NormalizeReferenceAlias(name)).
+		Where(kind+" <> ?", canonical).
+		Update(kind, canonical)
+	return result.RowsAffected, result.Error
+}
+
+// referenceColumns returns the table and the name and code columns of a reference kind
+func referenceColumns(kind string) (string, string, string) {
+	if kind == models.ReferenceKindStore {
+		return "stores", "store_name", "store_code"
+	}
+	return "channels", "channel_name", "channel_code"
+}
+
+// createPendingReference creates a channel or store flagged for review and notifies the admins
+func createPendingReference(db *gorm.DB, kind, name string) error {
+	table, _, codeColumn := referenceColumns(kind)
+
+	// Derive a unique code from the name, admins can change it when approving
+	base := referenceCode(name)
+	code := base
+	for suffix := 2; ; suffix++ {
+		var count int64
+		if err := db.Table(table).Where(codeColumn+" = ?", code).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			break
+		}
+		code = fmt.Sprintf("%s-%d", base, suffix)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var id uint
+		if kind == models.ReferenceKindStore {
+			store := models.Store{StoreCode: code, StoreName: name, PendingReview: true}
+			if err := tx.Create(&store).Error; err != nil {
+				return err
+			}
+			id = store.ID
+		} else {
+			channel := models.Channel{ChannelCode: code, ChannelName: name, PendingReview: true}
+			if err := tx.Create(&channel).Error; err != nil {
+				return err
+			}
+			id = channel.ID
+		}
+
+		message := fmt.Sprintf("An order arrived with the unknown %s %q, it was created as %s and waits for review. Approve it or merge it into an existing %s.", kind, name, code, kind)
+		return NotifyRoles(tx, OrderReferenceReviewRoles, "reference_pending_review", "New "+kind+" "+name+" needs review", message, kind, id)
+	})
+}
+
+// referenceCode derives an upper-case code from a name, e.g. "Toko Livo Official" becomes TOKO-LIVO-OFFICIAL
+func referenceCode(name string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToUpper(name) {
+		switch {
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			builder.WriteRune(r)
+			dash = false
+		case !dash && builder.Len() > 0:
+			builder.WriteRune('-')
+			dash = true
+		}
+	}
+	code := strings.TrimSuffix(builder.String(), "-")
+	if len(code) > 40 {
+		code = strings.TrimSuffix(code[:40], "-")
+	}
+	if code == "" {
+		code = "NEW"
+	}
+	return code
+}
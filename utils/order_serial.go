@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrSerialNumberRequired is returned when a unit of a serialized product is captured without its serial number
+var ErrSerialNumberRequired = errors.New("serial number is required")
+
+// SerialConflictError is returned when a serial number is already captured on an order that still holds it
+type SerialConflictError struct {
+	SerialNumber   string
+	TrackingNumber string // order the serial is captured on
+}
+
+func (e *SerialConflictError) Error() string {
+	return fmt.Sprintf("Serial number %s is already captured on order %s", e.SerialNumber, e.TrackingNumber)
+}
+
+// SerialRequiredSKUs returns the SKUs of the list whose products require a serial number per unit
+func SerialRequiredSKUs(db *gorm.DB, skus []string) (map[string]bool, error) {
+	required := make(map[string]bool)
+	if len(skus) == 0 {
+		return required, nil
+	}
+	var serialSKUs []string
+	if err := db.Model(&models.Product{}).Where("sku IN ? AND serial_required = ?", skus, true).Pluck("sku", &serialSKUs).Error; err != nil {
+		return nil, err
+	}
+	for _, sku := range serialSKUs {
+		required[sku] = true
+	}
+	return required, nil
+}
+
+// CaptureOrderSerials records the serial numbers of units of an order detail. A serial number can only be held by one
+// order at a time, serials released from canceled orders or voided QCs can be captured again.
+func CaptureOrderSerials(tx *gorm.DB, order *models.Order, detail *models.OrderDetail, serialNumbers []string, lane string, userID uint) error {
+	normalized := make([]string, len(serialNumbers))
+	seen := make(map[string]bool, len(serialNumbers))
+	for i, serialNumber := range serialNumbers {
+		normalized[i] = models.NormalizeSerialNumber(serialNumber)
+		if normalized[i] == "" {
+			return ErrSerialNumberRequired
+		}
+		if seen[normalized[i]] {
+			return &SerialConflictError{SerialNumber: normalized[i], TrackingNumber: order.TrackingNumber}
+		}
+		seen[normalized[i]] = true
+	}
+
+	var held []models.OrderItemSerial
+	if err := tx.Where("sku = ? AND serial_number IN ? AND released_at IS NULL", detail.SKU, normalized).Limit(1).Find(&held).Error; err != nil {
+		return err
+	}
+	if len(held) > 0 {
+		return &SerialConflictError{SerialNumber: held[0].SerialNumber, TrackingNumber: held[0].TrackingNumber}
+	}
+
+	for _, serialNumber := range normalized {
+		if err := tx.Create(&models.OrderItemSerial{
+			OrderID:        order.ID,
+			OrderDetailID:  detail.ID,
+			TrackingNumber: order.TrackingNumber,
+			SKU:            detail.SKU,
+			SerialNumber:   serialNumber,
+			Lane:           lane,
+			CapturedBy:     userID,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteOrderDetailSerials drops the serial numbers held by an order detail before they are captured again
+func DeleteOrderDetailSerials(tx *gorm.DB, detailID uint) error {
+	return tx.Where("order_detail_id = ? AND released_at IS NULL", detailID).Delete(&models.OrderItemSerial{}).Error
+}
+
+// ReleaseOrderSerials releases the serial numbers held by the order with the tracking number, they stay on record
+// for complaints and can be captured on another order
+func ReleaseOrderSerials(tx *gorm.DB, trackingNumber, reason string, now time.Time) error {
+	if trackingNumber == "" {
+		return nil
+	}
+	return tx.Model(&models.OrderItemSerial{}).Where("tracking_number = ? AND released_at IS NULL", trackingNumber).Updates(map[string]interface{}{
+		"released_at":    now,
+		"release_reason": reason,
+	}).Error
+}
+
+// LoadOrderSerials sets the serial numbers held by each loaded order detail, in capture order
+func LoadOrderSerials(db *gorm.DB, order *models.Order) {
+	var serials []models.OrderItemSerial
+	if err := db.Where("order_id = ? AND released_at IS NULL", order.ID).Order("id ASC").Find(&serials).Error; err != nil {
+		return
+	}
+	byDetail := make(map[uint][]string)
+	for _, serial := range serials {
+		byDetail[serial.OrderDetailID] = append(byDetail[serial.OrderDetailID], serial.SerialNumber)
+	}
+	for i := range order.OrderDetails {
+		order.OrderDetails[i].SerialNumbers = byDetail[order.OrderDetails[i].ID]
+	}
+}
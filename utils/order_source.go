@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ResolveOrderSource returns the source of a new order: the requested source when given,
+// otherwise the source of the order's channel, otherwise marketplace.
+// Returns false when the requested source is unknown.
+func ResolveOrderSource(db *gorm.DB, order *models.Order, requested string) (string, bool) {
+	if requested != "" {
+		return requested, models.IsValidOrderSource(requested)
+	}
+	if channel := FindOrderChannel(db, order); channel != nil && models.IsValidOrderSource(channel.OrderSource) {
+		return channel.OrderSource, true
+	}
+	return models.OrderSourceMarketplace, true
+}
@@ -0,0 +1,278 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OrderVolumeNotifyRoles are notified when the orders of a store or channel deviate sharply from its baseline
+var OrderVolumeNotifyRoles = []string{"superadmin", "admin"}
+
+// Statuses of the order volume of a store or channel
+const (
+	OrderVolumeStatusNormal      = "normal"
+	OrderVolumeStatusSpike       = models.OrderVolumeDirectionSpike
+	OrderVolumeStatusDrop        = models.OrderVolumeDirectionDrop
+	OrderVolumeStatusLowBaseline = "low_baseline" // baseline too small to judge deviations
+	OrderVolumeStatusDisabled    = "disabled"     // alerts disabled by the threshold of the store or channel
+)
+
+// OrderVolumePolicy holds the baseline period and the default alert thresholds,
+// stores and channels with an OrderVolumeThreshold use their own thresholds
+type OrderVolumePolicy struct {
+	BaselineDays int
+	SpikePercent int
+	DropPercent  int
+	MinBaseline  int
+}
+
+// OrderVolumePolicyFromConfig builds the order volume policy from the application config
+func OrderVolumePolicyFromConfig(cfg *config.Config) OrderVolumePolicy {
+	return OrderVolumePolicy{
+		BaselineDays: cfg.OrderVolumeBaselineDays,
+		SpikePercent: cfg.OrderVolumeSpikePercent,
+		DropPercent:  cfg.OrderVolumeDropPercent,
+		MinBaseline:  cfg.OrderVolumeMinBaseline,
+	}
+}
+
+// OrderVolumeVariance compares the orders of a store or channel on a day with its baseline
+type OrderVolumeVariance struct {
+	Scope            string  `json:"scope"`
+	ReferenceID      uint    `json:"referenceId"`
+	Code             string  `json:"code"`
+	Name             string  `json:"name"`
+	Count            int64   `json:"count"`            // orders of the day up to the check time
+	Baseline         float64 `json:"baseline"`         // average orders of the previous days up to the same time of day
+	DeviationPercent float64 `json:"deviationPercent"` // positive above the baseline, negative below
+	Status           string  `json:"status"`
+	Enabled          bool    `json:"enabled"`
+	SpikePercent     int     `json:"spikePercent"`
+	DropPercent      int     `json:"dropPercent"`
+	MinBaseline      int     `json:"minBaseline"`
+	CustomThreshold  bool    `json:"customThreshold"` // thresholds come from the store or channel instead of the defaults
+}
+
+// orderVolumeEntity is a store or channel orders are counted for
+type orderVolumeEntity struct {
+	ID   uint
+	Code string
+	Name string
+}
+
+// orderVolumeCount is the order count of a store or channel value of the orders table
+type orderVolumeCount struct {
+	Key   string
+	Count int64
+}
+
+// ComputeVariance compares the orders of every store or channel created on the day of at, up to at,
+// with the average orders of the previous BaselineDays days up to the same time of day.
+// Split shipments are not counted, they are not new orders.
+func (p OrderVolumePolicy) ComputeVariance(db *gorm.DB, scope string, at time.Time) ([]OrderVolumeVariance, error) {
+	var entities []orderVolumeEntity
+	var column string
+	switch scope {
+	case models.OrderVolumeScopeStore:
+		column = "orders.store"
+		if err := db.Model(&models.Store{}).Select("id, store_code AS code, store_name AS name").Order("store_name ASC").Scan(&entities).Error; err != nil {
+			return nil, err
+		}
+	case models.OrderVolumeScopeChannel:
+		column = "orders.channel"
+		if err := db.Model(&models.Channel{}).Select("id, channel_code AS code, channel_name AS name").Order("channel_name ASC").Scan(&entities).Error; err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown order volume scope %q", scope)
+	}
+
+	var thresholds []models.OrderVolumeThreshold
+	if err := db.Where("scope = ?", scope).Find(&thresholds).Error; err != nil {
+		return nil, err
+	}
+	thresholdsByReference := make(map[uint]models.OrderVolumeThreshold, len(thresholds))
+	for _, threshold := range thresholds {
+		thresholdsByReference[threshold.ReferenceID] = threshold
+	}
+
+	dayStart := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	elapsed := at.Sub(dayStart)
+	countQuery := func() *gorm.DB {
+		return db.Model(&models.Order{}).Select("LOWER(" + column + ") AS key, COUNT(*) AS count").Where("orders.parent_order_id IS NULL").Group("key")
+	}
+
+	var today []orderVolumeCount
+	if err := countQuery().Where("orders.created_at >= ? AND orders.created_at < ?", dayStart, at).Scan(&today).Error; err != nil {
+		return nil, err
+	}
+
+	// The same part of each previous day, so a morning check is not compared with whole days
+	var history []orderVolumeCount
+	if p.BaselineDays > 0 {
+		ranges := make([]string, p.BaselineDays)
+		args := make([]interface{}, 0, p.BaselineDays*2)
+		for day := 1; day <= p.BaselineDays; day++ {
+			start := dayStart.AddDate(0, 0, -day)
+			ranges[day-1] = "(orders.created_at >= ? AND orders.created_at < ?)"
+			args = append(args, start, start.Add(elapsed))
+		}
+		if err := countQuery().Where(strings.Join(ranges, " OR "), args...).Scan(&history).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	todayByKey := make(map[string]int64, len(today))
+	for _, count := range today {
+		todayByKey[count.Key] = count.Count
+	}
+	historyByKey := make(map[string]int64, len(history))
+	for _, count := range history {
+		historyByKey[count.Key] = count.Count
+	}
+	// Orders name their store or channel by name or by code
+	countOf := func(counts map[string]int64, entity orderVolumeEntity) int64 {
+		name, code := strings.ToLower(entity.Name), strings.ToLower(entity.Code)
+		if name == code {
+			return counts[name]
+		}
+		return counts[name] + counts[code]
+	}
+
+	variances := make([]OrderVolumeVariance, 0, len(entities))
+	for _, entity := range entities {
+		variance := OrderVolumeVariance{
+			Scope:        scope,
+			ReferenceID:  entity.ID,
+			Code:         entity.Code,
+			Name:         entity.Name,
+			Count:        countOf(todayByKey, entity),
+			Enabled:      true,
+			SpikePercent: p.SpikePercent,
+			DropPercent:  p.DropPercent,
+			MinBaseline:  p.MinBaseline,
+		}
+		if threshold, ok := thresholdsByReference[entity.ID]; ok {
+			variance.Enabled = threshold.Enabled
+			variance.SpikePercent = threshold.SpikePercent
+			variance.DropPercent = threshold.DropPercent
+			variance.MinBaseline = threshold.MinBaseline
+			variance.CustomThreshold = true
+		}
+		if p.BaselineDays > 0 {
+			variance.Baseline = math.Round(float64(countOf(historyByKey, entity))/float64(p.BaselineDays)*10) / 10
+		}
+		if variance.Baseline > 0 {
+			variance.DeviationPercent = math.Round((float64(variance.Count)-variance.Baseline)/variance.Baseline*1000) / 10
+		}
+
+		switch {
+		case !variance.Enabled:
+			variance.Status = OrderVolumeStatusDisabled
+		case variance.Baseline <= 0 || variance.Baseline < float64(variance.MinBaseline):
+			variance.Status = OrderVolumeStatusLowBaseline
+		case variance.SpikePercent > 0 && variance.DeviationPercent > float64(variance.SpikePercent):
+			variance.Status = OrderVolumeStatusSpike
+		case variance.DropPercent > 0 && -variance.DeviationPercent > float64(variance.DropPercent):
+			variance.Status = OrderVolumeStatusDrop
+		default:
+			variance.Status = OrderVolumeStatusNormal
+		}
+		variances = append(variances, variance)
+	}
+
+	return variances, nil
+}
+
+// DetectOrderVolumeAlerts records an alert for every store and channel whose orders of today deviate from its baseline
+// beyond its thresholds and notifies the alert roles. A store or channel is alerted once per day and direction.
+// Returns the number of new alerts.
+func DetectOrderVolumeAlerts(db *gorm.DB, policy OrderVolumePolicy) (int, error) {
+	now := time.Now()
+	date := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	detected := 0
+	for _, scope := range models.OrderVolumeScopes() {
+		variances, err := policy.ComputeVariance(db, scope, now)
+		if err != nil {
+			return detected, err
+		}
+
+		for _, variance := range variances {
+			if variance.Status != OrderVolumeStatusSpike && variance.Status != OrderVolumeStatusDrop {
+				continue
+			}
+			alert := models.OrderVolumeAlert{
+				Scope:            scope,
+				ReferenceID:      variance.ReferenceID,
+				Name:             variance.Name,
+				Date:             date,
+				Direction:        variance.Status,
+				Count:            variance.Count,
+				Baseline:         variance.Baseline,
+				DeviationPercent: variance.DeviationPercent,
+				DetectedAt:       now,
+			}
+
+			created := false
+			err := db.Transaction(func(tx *gorm.DB) error {
+				result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&alert)
+				if result.Error != nil || result.RowsAffected == 0 {
+					return result.Error
+				}
+				created = true
+
+				title := fmt.Sprintf("Order spike at %s %s", scope, variance.Name)
+				message := fmt.Sprintf("%s %s has %d orders today, %.1f%% above its baseline of %.1f at this time of day",
+					strings.ToUpper(scope[:1])+scope[1:], variance.Name, variance.Count, variance.DeviationPercent, variance.Baseline)
+				if variance.Status == OrderVolumeStatusDrop {
+					title = fmt.Sprintf("Order drop at %s %s", scope, variance.Name)
+					message = fmt.Sprintf("%s %s has %d orders today, %.1f%% below its baseline of %.1f at this time of day, check the marketplace sync",
+						strings.ToUpper(scope[:1])+scope[1:], variance.Name, variance.Count, -variance.DeviationPercent, variance.Baseline)
+				}
+				return NotifyRoles(tx, OrderVolumeNotifyRoles, "order_volume_"+variance.Status, title, message, "order_volume_alert", alert.ID)
+			})
+			if err != nil {
+				log.Println("DetectOrderVolumeAlerts - Failed to record alert for", scope, variance.ReferenceID, ":", err)
+				continue
+			}
+			if created {
+				detected++
+			}
+		}
+	}
+
+	return detected, nil
+}
+
+// StartOrderVolumeScheduler checks the order volume of the stores and channels periodically in the background
+func StartOrderVolumeScheduler(db *gorm.DB, policy OrderVolumePolicy, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if GetMaintenance().Enabled {
+				log.Println("StartOrderVolumeScheduler - Skipping order volume check during maintenance mode")
+				continue
+			}
+
+			detected, err := DetectOrderVolumeAlerts(db, policy)
+			if err != nil {
+				log.Println("StartOrderVolumeScheduler - Order volume check failed:", err)
+				continue
+			}
+			if detected > 0 {
+				log.Printf("StartOrderVolumeScheduler - %d new order volume alerts\n", detected)
+			}
+		}
+	}()
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "encoding/json"
+
+// PresentFields returns the top-level keys of a JSON object request body.
+// Partial updates use it to tell a field sent as null, which clears the value, apart from an omitted field, which is kept.
+func PresentFields(body []byte) (map[string]bool, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]bool, len(raw))
+	for key := range raw {
+		fields[key] = true
+	}
+	return fields, nil
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPresentFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    map[string]bool
+		wantErr bool
+	}{
+		{"empty object", `{}`, map[string]bool{}, false},
+		{"field with a value", `{"fullName":"Jane Doe"}`, map[string]bool{"fullName": true}, false},
+		{"field sent as null is present", `{"phone":null}`, map[string]bool{"phone": true}, false},
+		{"empty string and false are present", `{"phone":"","isActive":false}`, map[string]bool{"phone": true, "isActive": true}, false},
+		{"only top-level keys", `{"details":{"sku":"CASE-BLK"}}`, map[string]bool{"details": true}, false},
+		{"keys are case sensitive", `{"FullName":"Jane Doe"}`, map[string]bool{"FullName": true}, false},
+		{"invalid json", `{"fullName":`, nil, true},
+		{"array body", `[{"fullName":"Jane Doe"}]`, nil, true},
+		{"empty body", ``, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PresentFields([]byte(tt.body))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PresentFields(%s) error = %v, want error %v", tt.body, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PresentFields(%s) = %v, want %v", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/jpeg"
+	"strings"
+)
+
+// PDFContentType is the MIME type of PDF exports
+const PDFContentType = "application/pdf"
+
+// Text PDF page layout, A4 in points with a monospaced font so columns line up
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 40
+	pdfFontSize     = 9
+	pdfLineHeight   = 11
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+	pdfCharsPerLine = 100
+)
+
+// Barcode layout, barcodes are drawn at the right margin and shrink their modules to fit the maximum width
+const (
+	pdfBarcodeLines    = 3   // lines of text a barcode spans
+	pdfBarcodeMaxWidth = 220 // points
+	pdfBarcodeModule   = 1.0 // widest module in points
+)
+
+// Logo layout, logos are drawn at the top left of a document's first page over the lines left empty for them
+const (
+	pdfLogoLines    = 5   // lines of text a logo spans
+	pdfLogoMaxWidth = 160 // points
+)
+
+// PDFDocument is a printable text document, each document starts on a new page
+type PDFDocument struct {
+	Lines    []string
+	Barcodes []PDFBarcode
+	Logo     *PDFImage
+}
+
+// PDFImage is a JPEG image embedded in the PDF as is, documents sharing an image embed it once
+type PDFImage struct {
+	JPEG       []byte
+	Width      int // pixels
+	Height     int // pixels
+	ColorSpace string
+}
+
+// NewPDFImage reads the dimensions and color space of a JPEG image to embed
+func NewPDFImage(content []byte) (*PDFImage, error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	colorSpace := "DeviceRGB"
+	switch cfg.ColorModel {
+	case color.GrayModel:
+		colorSpace = "DeviceGray"
+	case color.CMYKModel:
+		return nil, fmt.Errorf("CMYK images are not supported")
+	}
+	return &PDFImage{JPEG: content, Width: cfg.Width, Height: cfg.Height, ColorSpace: colorSpace}, nil
+}
+
+// PDFBarcode is a Code 128 barcode drawn at the right margin, spanning three lines from the given line.
+// Text on those lines should stay short enough not to run under the barcode.
+type PDFBarcode struct {
+	Line  int // index in the document lines
+	Value string
+}
+
+// AppendBlock appends lines that are kept on the same page, with an optional barcode next to the first line.
+// The block starts on a new page when it does not fit on the current one.
+func (d *PDFDocument) AppendBlock(lines []string, barcode string) {
+	if barcode != "" {
+		for len(lines) < pdfBarcodeLines {
+			lines = append(lines, "")
+		}
+	}
+	if used := len(d.Lines) % pdfLinesPerPage; used > 0 && used+len(lines) > pdfLinesPerPage && len(lines) <= pdfLinesPerPage {
+		for ; used < pdfLinesPerPage; used++ {
+			d.Lines = append(d.Lines, "")
+		}
+	}
+	if barcode != "" {
+		d.Barcodes = append(d.Barcodes, PDFBarcode{Line: len(d.Lines), Value: barcode})
+	}
+	d.Lines = append(d.Lines, lines...)
+}
+
+// SetLogo prints the image at the top of the document, the first lines are moved down to leave room for it
+func (d *PDFDocument) SetLogo(logo *PDFImage) {
+	if logo == nil {
+		return
+	}
+	d.Logo = logo
+	d.prependLines(make([]string, pdfLogoLines))
+}
+
+// prependLines inserts lines at the top of the document, moving the barcodes down with their lines
+func (d *PDFDocument) prependLines(lines []string) {
+	d.Lines = append(lines, d.Lines...)
+	for i := range d.Barcodes {
+		d.Barcodes[i].Line += len(lines)
+	}
+}
+
+// BuildTextPDF renders the documents into a single PDF in the given order.
+// Documents longer than a page continue on the next page, long lines are cut and
+// characters outside Latin-1 are replaced since only the standard Courier font is embedded.
+// Barcodes whose value cannot be encoded are left out, the value is expected in the text as well.
+func BuildTextPDF(documents []PDFDocument) (*bytes.Buffer, error) {
+	var pages [][]string
+	var pageBarcodes [][]PDFBarcode
+	var pageLogos []*PDFImage
+	for _, document := range documents {
+		lines := document.Lines
+		if len(lines) == 0 {
+			lines = []string{""}
+		}
+		for start := 0; start < len(lines); start += pdfLinesPerPage {
+			end := start + pdfLinesPerPage
+			if end > len(lines) {
+				end = len(lines)
+			}
+			pages = append(pages, lines[start:end])
+
+			// Barcodes of the page, positioned by their line on the page
+			var barcodes []PDFBarcode
+			for _, barcode := range document.Barcodes {
+				if barcode.Line >= start && barcode.Line < end {
+					barcodes = append(barcodes, PDFBarcode{Line: barcode.Line - start, Value: barcode.Value})
+				}
+			}
+			pageBarcodes = append(pageBarcodes, barcodes)
+
+			// The logo is only printed on the first page of the document
+			if start == 0 {
+				pageLogos = append(pageLogos, document.Logo)
+			} else {
+				pageLogos = append(pageLogos, nil)
+			}
+		}
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no pages to render")
+	}
+
+	buffer := new(bytes.Buffer)
+	var offsets []int
+	writeObject := func(body string) {
+		offsets = append(offsets, buffer.Len())
+		fmt.Fprintf(buffer, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buffer.WriteString("%PDF-1.4\n")
+
+	// Object 1 is the catalog, 2 the page tree, 3 the font, then a page and its content per page, then the images
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	var images []*PDFImage
+	imageObjects := make(map[*PDFImage]int)
+	for _, logo := range pageLogos {
+		if _, ok := imageObjects[logo]; logo != nil && !ok {
+			imageObjects[logo] = 4 + len(pages)*2 + len(images)
+			images = append(images, logo)
+		}
+	}
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) Tj T*\n", pdfEscape(line))
+		}
+		content.WriteString("ET")
+		for _, barcode := range pageBarcodes[i] {
+			writeBarcode(&content, barcode)
+		}
+		resources := "/Font << /F1 3 0 R >>"
+		if logo := pageLogos[i]; logo != nil {
+			writeLogo(&content, logo)
+			resources += fmt.Sprintf(" /XObject << /Logo %d 0 R >>", imageObjects[logo])
+		}
+
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << %s >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, resources, 5+i*2))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+	for _, image := range images {
+		writeObject(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream",
+			image.Width, image.Height, image.ColorSpace, len(image.JPEG), image.JPEG))
+	}
+
+	// Cross-reference table and trailer
+	xrefOffset := buffer.Len()
+	fmt.Fprintf(buffer, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	return buffer, nil
+}
+
+// writeBarcode draws a Code 128 barcode as filled rectangles at the right margin, from the top of its line down
+func writeBarcode(content *strings.Builder, barcode PDFBarcode) {
+	widths, err := Code128Widths(barcode.Value)
+	if err != nil {
+		return
+	}
+	modules := 0
+	for _, width := range widths {
+		modules += width
+	}
+	module := pdfBarcodeModule
+	if float64(modules)*module > pdfBarcodeMaxWidth {
+		module = pdfBarcodeMaxWidth / float64(modules)
+	}
+
+	height := float64(pdfBarcodeLines*pdfLineHeight - 2)
+	top := float64(pdfPageHeight - pdfMargin - barcode.Line*pdfLineHeight)
+	x := float64(pdfPageWidth-pdfMargin) - float64(modules)*module
+	content.WriteString("\n0 g\n")
+	for i, width := range widths {
+		// Even positions are bars, odd positions spaces
+		if i%2 == 0 {
+			fmt.Fprintf(content, "%.2f %.2f %.2f %.2f re\n", x, top-height, float64(width)*module, height)
+		}
+		x += float64(width) * module
+	}
+	content.WriteString("f")
+}
+
+// writeLogo draws the logo at the top left of the page, scaled to the lines left empty for it and the maximum width
+func writeLogo(content *strings.Builder, logo *PDFImage) {
+	if logo.Width <= 0 || logo.Height <= 0 {
+		return
+	}
+	height := float64(pdfLogoLines*pdfLineHeight - 2)
+	width := height * float64(logo.Width) / float64(logo.Height)
+	if width > pdfLogoMaxWidth {
+		height = height * pdfLogoMaxWidth / width
+		width = pdfLogoMaxWidth
+	}
+	top := float64(pdfPageHeight - pdfMargin)
+	fmt.Fprintf(content, "\nq %.2f 0 0 %.2f %d %.2f cm /Logo Do Q", width, height, pdfMargin, top-height)
+}
+
+// pdfEscape cuts a line to the page width and escapes it for a PDF string literal
+func pdfEscape(line string) string {
+	var escaped strings.Builder
+	count := 0
+	for _, r := range line {
+		if count == pdfCharsPerLine {
+			break
+		}
+		count++
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r == '\t':
+			escaped.WriteByte(' ')
+		case r < 0x20 || r > 0xff:
+			escaped.WriteByte('?')
+		case r < 0x80:
+			escaped.WriteRune(r)
+		default:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		}
+	}
+	return escaped.String()
+}
@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PickAnomalyNotifyRoles are notified when a picked order has anomalously fast or slow items
+var PickAnomalyNotifyRoles = []string{"coordinator"}
+
+// PickAnomalyPolicy holds when an item pick counts as anomalously fast or slow
+type PickAnomalyPolicy struct {
+	FastSecondsPerUnit int // picks faster than this per unit are flagged, 0 disables
+	SlowItemSeconds    int // items taking longer than this are flagged, 0 disables
+}
+
+// PickAnomalyPolicyFromConfig builds the pick anomaly policy from the application config
+func PickAnomalyPolicyFromConfig(cfg *config.Config) PickAnomalyPolicy {
+	return PickAnomalyPolicy{
+		FastSecondsPerUnit: cfg.PickAnomalyFastSeconds,
+		SlowItemSeconds:    cfg.PickAnomalySlowSeconds,
+	}
+}
+
+// PickAnomalies measures every confirmed item of an individually picked order from the previous confirmation,
+// or from the assignment for the first item, and returns the items outside the policy.
+// The first item is never flagged as slow since pickers may be assigned orders before they start picking.
+func PickAnomalies(policy PickAnomalyPolicy, order *models.Order, details []models.OrderDetail, detectedAt time.Time) []models.PickAnomaly {
+	if order.PickedBy == nil || (policy.FastSecondsPerUnit <= 0 && policy.SlowItemSeconds <= 0) {
+		return nil
+	}
+
+	confirmed := make([]models.OrderDetail, 0, len(details))
+	for _, detail := range details {
+		if detail.ItemPickedAt != nil && detail.PickedQuantity > 0 {
+			confirmed = append(confirmed, detail)
+		}
+	}
+	sort.SliceStable(confirmed, func(i, j int) bool {
+		return confirmed[i].ItemPickedAt.Before(*confirmed[j].ItemPickedAt)
+	})
+
+	var anomalies []models.PickAnomaly
+	previous := order.AssignedAt
+	for i, detail := range confirmed {
+		if previous == nil {
+			previous = detail.ItemPickedAt
+			continue
+		}
+		seconds := int(detail.ItemPickedAt.Sub(*previous).Seconds())
+		anomaly := models.PickAnomaly{
+			OrderID:        order.ID,
+			OrderDetailID:  detail.ID,
+			TrackingNumber: order.TrackingNumber,
+			PickedBy:       *order.PickedBy,
+			SKU:            detail.SKU,
+			Quantity:       detail.PickedQuantity,
+			Seconds:        seconds,
+			StartedAt:      *previous,
+			ItemPickedAt:   *detail.ItemPickedAt,
+			DetectedAt:     detectedAt,
+		}
+		switch {
+		case policy.FastSecondsPerUnit > 0 && seconds < policy.FastSecondsPerUnit*detail.PickedQuantity:
+			anomaly.Type = models.PickAnomalyFast
+			anomaly.ThresholdSeconds = policy.FastSecondsPerUnit * detail.PickedQuantity
+			anomalies = append(anomalies, anomaly)
+		case policy.SlowItemSeconds > 0 && i > 0 && seconds > policy.SlowItemSeconds:
+			anomaly.Type = models.PickAnomalySlow
+			anomaly.ThresholdSeconds = policy.SlowItemSeconds
+			anomalies = append(anomalies, anomaly)
+		}
+		previous = detail.ItemPickedAt
+	}
+
+	return anomalies
+}
+
+// RecordPickAnomalies detects the pick anomalies of a completed order, stores them and notifies the coordinators.
+// Orders picked on a pick cart are skipped, their items are confirmed for the whole cart at once.
+// Returns the recorded anomalies.
+func RecordPickAnomalies(db *gorm.DB, policy PickAnomalyPolicy, orderID uint) ([]models.PickAnomaly, error) {
+	var order models.Order
+	if err := db.Preload("PickUser").Where("id = ?", orderID).First(&order).Error; err != nil {
+		return nil, err
+	}
+
+	var batchOrders int64
+	if err := db.Model(&models.PickBatchOrder{}).Where("order_id = ?", orderID).Count(&batchOrders).Error; err != nil {
+		return nil, err
+	}
+	if batchOrders > 0 {
+		return nil, nil
+	}
+
+	var details []models.OrderDetail
+	if err := db.Where("order_id = ? AND is_shortage = ?", orderID, false).Find(&details).Error; err != nil {
+		return nil, err
+	}
+
+	anomalies := PickAnomalies(policy, &order, details, time.Now())
+	if len(anomalies) == 0 {
+		return nil, nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Items picked again after a pending pick keep their first anomaly
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "order_detail_id"}}, DoNothing: true}).Create(&anomalies).Error; err != nil {
+			return err
+		}
+
+		var picker string
+		if order.PickUser != nil {
+			picker = order.PickUser.FullName
+		}
+		items := make([]string, len(anomalies))
+		for i, anomaly := range anomalies {
+			items[i] = fmt.Sprintf("%s x%d in %ds (%s)", anomaly.SKU, anomaly.Quantity, anomaly.Seconds, anomaly.Type)
+		}
+		message := fmt.Sprintf("%s picked order %s with %d anomalous items: %s", picker, order.TrackingNumber, len(anomalies), strings.Join(items, ", "))
+		return NotifyRoles(tx, PickAnomalyNotifyRoles, "pick_anomaly", "Picking anomaly detected", message, "order", order.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return anomalies, nil
+}
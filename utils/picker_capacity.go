@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// PickerOpenOrders counts the orders each picker has in picking progress, pickers without any are left out
+func PickerOpenOrders(db *gorm.DB, pickerIDs []uint) (map[uint]int, error) {
+	open := make(map[uint]int, len(pickerIDs))
+	if len(pickerIDs) == 0 {
+		return open, nil
+	}
+
+	var rows []struct {
+		PickedBy uint
+		Orders   int
+	}
+	if err := db.Model(&models.Order{}).Select("picked_by, COUNT(*) AS orders").
+		Where("picked_by IN ? AND processing_status = ? AND event_status NOT IN ?", pickerIDs, models.ProcessingStatusPickingProgress,
+			[]string{models.EventStatusCanceled, models.EventStatusMerged}).
+		Group("picked_by").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		open[row.PickedBy] = row.Orders
+	}
+	return open, nil
+}
+
+// PickerRemainingCapacity returns how many more orders a picker with the open orders can be assigned under the quota,
+// nil when the quota is disabled
+func PickerRemainingCapacity(openOrders, maxOpenOrders int) *int {
+	if maxOpenOrders <= 0 {
+		return nil
+	}
+	remaining := max(maxOpenOrders-openOrders, 0)
+	return &remaining
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PresentUsersQuery returns a subquery selecting the ids of users checked in today and not yet checked out,
+// limited to the given warehouse location when one is set
+func PresentUsersQuery(db *gorm.DB, locationID *uint) *gorm.DB {
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	query := db.Model(&models.Attendance{}).Select("user_id").
+		Where("checked_in >= ? AND checked_out IS NULL AND checked = ?", startOfDay, true)
+	if locationID != nil {
+		query = query.Where("location_id = ?", *locationID)
+	}
+	return query
+}
+
+// OrderLocationID returns the warehouse location the order is picked at, taken from its store.
+// Returns nil when the store is unknown or not tied to a location, any location then counts.
+func OrderLocationID(db *gorm.DB, order *models.Order) *uint {
+	store := FindOrderStore(db, order)
+	if store == nil {
+		return nil
+	}
+	return store.LocationID
+}
+
+// IsUserPresent reports whether the user has an open attendance today, at the given location when one is set
+func IsUserPresent(db *gorm.DB, userID uint, locationID *uint) (bool, error) {
+	var count int64
+	if err := PresentUsersQuery(db, locationID).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
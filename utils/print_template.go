@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"context"
+	"livo-fiber-backend/models"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxPrintTemplateLines caps the header and footer text of a print template so the document itself keeps the page
+const MaxPrintTemplateLines = 10
+
+// PrintBranding is the template and logo printed on a document type of a store
+type PrintBranding struct {
+	Template *models.PrintTemplate
+	Logo     *PDFImage
+}
+
+// LoadPrintBranding returns the branding of a store's document type, nil when the store is unknown.
+// The logo is printed unless the template hides it, a logo that cannot be read is left out so printing never fails on it.
+func LoadPrintBranding(ctx context.Context, db *gorm.DB, store *models.Store, documentType string) *PrintBranding {
+	if store == nil {
+		return nil
+	}
+
+	branding := &PrintBranding{}
+	var template models.PrintTemplate
+	if err := db.Where("store_id = ? AND document_type = ?", store.ID, documentType).First(&template).Error; err == nil {
+		branding.Template = &template
+	}
+	if store.LogoKey != "" && (branding.Template == nil || branding.Template.ShowLogo) {
+		content, err := GetStorage().Get(ctx, store.LogoKey)
+		if err == nil {
+			branding.Logo, err = NewPDFImage(content)
+		}
+		if err != nil {
+			log.Printf("LoadPrintBranding - Failed to load logo of store %s: %v", store.StoreCode, err)
+		}
+	}
+	return branding
+}
+
+// PrintBrandingCache loads the branding of a document type once per store, for documents printed for many orders
+type PrintBrandingCache struct {
+	ctx          context.Context
+	db           *gorm.DB
+	documentType string
+	brandings    map[uint]*PrintBranding
+}
+
+// NewPrintBrandingCache returns an empty branding cache of the document type
+func NewPrintBrandingCache(ctx context.Context, db *gorm.DB, documentType string) *PrintBrandingCache {
+	return &PrintBrandingCache{ctx: ctx, db: db, documentType: documentType, brandings: make(map[uint]*PrintBranding)}
+}
+
+// ForOrder returns the branding of the order's store, nil when the store is unknown
+func (pbc *PrintBrandingCache) ForOrder(order *models.Order) *PrintBranding {
+	store := FindOrderStore(pbc.db, order)
+	if store == nil {
+		return nil
+	}
+	branding, ok := pbc.brandings[store.ID]
+	if !ok {
+		branding = LoadPrintBranding(pbc.ctx, pbc.db, store, pbc.documentType)
+		pbc.brandings[store.ID] = branding
+	}
+	return branding
+}
+
+// Apply prints the logo and the template header at the top of the document and the template footer at the bottom
+func (pb *PrintBranding) Apply(document PDFDocument) PDFDocument {
+	if pb == nil {
+		return document
+	}
+	if pb.Template != nil {
+		if header := PrintTemplateLines(pb.Template.HeaderText); len(header) > 0 {
+			document.prependLines(append(header, ""))
+		}
+		if footer := PrintTemplateLines(pb.Template.FooterText); len(footer) > 0 {
+			document.Lines = append(append(document.Lines, ""), footer...)
+		}
+	}
+	document.SetLogo(pb.Logo)
+	return document
+}
+
+// hasFooter reports whether the template replaces the default footer of the document
+func (pb *PrintBranding) hasFooter() bool {
+	return pb != nil && pb.Template != nil && len(PrintTemplateLines(pb.Template.FooterText)) > 0
+}
+
+// PrintTemplateLines splits a template text block into lines, leading and trailing empty lines are dropped
+func PrintTemplateLines(text string) []string {
+	text = strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], " \t\r")
+	}
+	return lines
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"regexp"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// productNameTag matches a marketplace promotion tag in front of a product name, like "[READY STOCK]" or "【PROMO】"
+var productNameTag = regexp.MustCompile(`^(\[[^\]]*\]|【[^】]*】)\s*`)
+
+// variantSeparator splits the options of a marketplace variant, like "Merah, XL" or "merah/xl"
+var variantSeparator = regexp.MustCompile(`\s*[,/|;]\s*`)
+
+// NormalizeProductName strips promotion tags and repeated whitespace from a marketplace product name
+func NormalizeProductName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+	for {
+		stripped := productNameTag.ReplaceAllString(name, "")
+		if stripped == name || stripped == "" {
+			return name
+		}
+		name = stripped
+	}
+}
+
+// NormalizeVariant joins the options of a marketplace variant with a single separator
+func NormalizeVariant(variant string) string {
+	var options []string
+	for _, option := range variantSeparator.Split(strings.Join(strings.Fields(variant), " "), -1) {
+		if option != "" {
+			options = append(options, option)
+		}
+	}
+	return strings.Join(options, ", ")
+}
+
+// VariantKey returns the key variant mappings are matched by, equal for variants differing only in case and separators
+func VariantKey(variant string) string {
+	return strings.ToLower(NormalizeVariant(variant))
+}
+
+// RequestLanguage returns the language product names are displayed in, from the lang query or the Accept-Language header
+func RequestLanguage(c fiber.Ctx) string {
+	language := strings.ToLower(strings.TrimSpace(c.Query("lang", "")))
+	if language == "" {
+		acceptLanguage := c.Get(fiber.HeaderAcceptLanguage)
+		if len(acceptLanguage) >= 2 {
+			language = strings.ToLower(acceptLanguage[:2])
+		}
+	}
+	if IsLabelLanguage(language) {
+		return language
+	}
+	return LabelLanguageIndonesian
+}
+
+// ProductNames resolves the display name and variant of SKUs from the curated names and variant mappings
+type ProductNames struct {
+	language string
+	names    map[string]map[string]string // sku -> language -> name
+	variants map[string]string            // sku + variant key -> variant, sku is empty for every SKU
+}
+
+// LoadProductNames loads the curated names and variant mappings of the SKUs
+func LoadProductNames(db *gorm.DB, language string, skus []string) *ProductNames {
+	names := &ProductNames{
+		language: language,
+		names:    make(map[string]map[string]string),
+		variants: make(map[string]string),
+	}
+	if len(skus) == 0 {
+		return names
+	}
+
+	var displayNames []models.ProductDisplayName
+	db.Where("sku IN ? AND language IN ?", skus, []string{language, LabelLanguageIndonesian}).Find(&displayNames)
+	for _, displayName := range displayNames {
+		if names.names[displayName.SKU] == nil {
+			names.names[displayName.SKU] = make(map[string]string)
+		}
+		names.names[displayName.SKU][displayName.Language] = displayName.Name
+	}
+
+	var mappings []models.ProductVariantMapping
+	db.Where("sku IN ? OR sku = ?", skus, "").Find(&mappings)
+	for _, mapping := range mappings {
+		names.variants[mapping.SKU+"\x00"+mapping.RawVariant] = mapping.Variant
+	}
+
+	return names
+}
+
+// Display returns the name and variant of an SKU to display, falling back to the normalized marketplace values
+func (pn *ProductNames) Display(sku, name, variant string) (string, string) {
+	displayName := NormalizeProductName(name)
+	if curated, ok := pn.names[sku][pn.language]; ok {
+		displayName = curated
+	} else if curated, ok := pn.names[sku][LabelLanguageIndonesian]; ok {
+		displayName = curated
+	}
+
+	key := VariantKey(variant)
+	displayVariant := NormalizeVariant(variant)
+	if mapped, ok := pn.variants[sku+"\x00"+key]; ok {
+		displayVariant = mapped
+	} else if mapped, ok := pn.variants["\x00"+key]; ok {
+		displayVariant = mapped
+	}
+
+	return displayName, displayVariant
+}
+
+// ApplyProductNames sets the display name and variant of order details for picking lists and QC screens
+func ApplyProductNames(db *gorm.DB, language string, details []models.OrderDetail) {
+	skus := make([]string, 0, len(details))
+	for _, detail := range details {
+		skus = append(skus, detail.SKU)
+	}
+	names := LoadProductNames(db, language, skus)
+	for i := range details {
+		details[i].DisplayName, details[i].DisplayVariant = names.Display(details[i].SKU, details[i].ProductName, details[i].Variant)
+	}
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimitWindow is the window of the global per-client rate limit
+const RateLimitWindow = time.Minute
+
+// ClientRateLimit is the state of a client's current window under the global rate limit
+type ClientRateLimit struct {
+	Limit          int `json:"limit"`
+	Used           int `json:"used"`
+	Remaining      int `json:"remaining"`
+	ResetInSeconds int `json:"resetInSeconds"` // seconds until the window resets
+	WindowSeconds  int `json:"windowSeconds"`
+}
+
+// clientWindow counts the requests of a client in the current window
+type clientWindow struct {
+	start time.Time
+	count int
+}
+
+var (
+	clientLimiterMu    sync.Mutex
+	clientWindows      = make(map[string]*clientWindow)
+	clientWindowsSwept time.Time
+)
+
+// AllowClientRequest counts a request of a client against the limit per window.
+// It returns false when the limit is reached, the returned state then tells when to retry.
+func AllowClientRequest(key string, limit int, window time.Duration, now time.Time) (bool, ClientRateLimit) {
+	clientLimiterMu.Lock()
+	defer clientLimiterMu.Unlock()
+
+	// Drop expired windows once per window so idle clients do not pile up
+	if now.Sub(clientWindowsSwept) >= window {
+		for clientKey, clientWin := range clientWindows {
+			if now.Sub(clientWin.start) >= window {
+				delete(clientWindows, clientKey)
+			}
+		}
+		clientWindowsSwept = now
+	}
+
+	current, ok := clientWindows[key]
+	if !ok || now.Sub(current.start) >= window {
+		current = &clientWindow{start: now}
+		clientWindows[key] = current
+	}
+	allowed := current.count < limit
+	if allowed {
+		current.count++
+	}
+	return allowed, clientRateLimitState(current, limit, window, now)
+}
+
+// GetClientRateLimit returns the state of a client's current window without counting a request
+func GetClientRateLimit(key string, limit int, window time.Duration, now time.Time) ClientRateLimit {
+	clientLimiterMu.Lock()
+	defer clientLimiterMu.Unlock()
+
+	current, ok := clientWindows[key]
+	if !ok || now.Sub(current.start) >= window {
+		current = &clientWindow{start: now}
+	}
+	return clientRateLimitState(current, limit, window, now)
+}
+
+// clientRateLimitState builds the state of a window. Caller holds the lock.
+func clientRateLimitState(current *clientWindow, limit int, window time.Duration, now time.Time) ClientRateLimit {
+	remaining := limit - current.count
+	if remaining < 0 {
+		remaining = 0
+	}
+	// Round up so clients never retry a moment before the window resets
+	resetIn := int((current.start.Add(window).Sub(now) + time.Second - 1) / time.Second)
+	return ClientRateLimit{
+		Limit:          limit,
+		Used:           current.count,
+		Remaining:      remaining,
+		ResetInSeconds: resetIn,
+		WindowSeconds:  int(window / time.Second),
+	}
+}
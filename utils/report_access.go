@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// ReportAccessPolicies is the report permission matrix: the roles allowed to pull each report, by report key.
+// Salary-adjacent reports (user fees, billing) are finance only, operational reports are for coordinators and admins.
+var ReportAccessPolicies = map[string][]string{
+	"boxes":                     {"developer", "superadmin", "coordinator", "admin"},
+	"outbounds":                 {"developer", "superadmin", "coordinator", "admin"},
+	"returns":                   {"developer", "superadmin", "coordinator", "admin"},
+	"return_valuation":          {"developer", "superadmin", "coordinator", "finance"},
+	"complains":                 {"developer", "superadmin", "coordinator", "admin"},
+	"complain_root_causes":      {"developer", "superadmin", "coordinator"},
+	"complain_repeat_offenders": {"developer", "superadmin", "coordinator", "admin"},
+	"user_fees":                 {"developer", "superadmin", "finance"},
+	"near_expiry":               {"developer", "superadmin", "coordinator"},
+	"error_hotspots":            {"developer", "superadmin", "coordinator"},
+	"pick_anomalies":            {"developer", "superadmin", "coordinator"},
+	"qc_stations":               {"developer", "superadmin", "coordinator"},
+	"picker_performance":        {"developer", "superadmin", "coordinator", "hrd"},
+	"shortages":                 {"developer", "superadmin", "coordinator"},
+	"sla_breaches":              {"developer", "superadmin", "coordinator", "admin"},
+	"outbound_forecast":         {"developer", "superadmin", "coordinator"},
+	"cancel_inconsistencies":    {"developer", "superadmin"},
+	"data_quality":              {"developer", "superadmin", "coordinator", "admin"},
+	"courier_weights":           {"developer", "superadmin", "coordinator", "finance"},
+	"billing":                   {"developer", "superadmin", "finance"},
+	"order_reconciliation":      {"developer", "superadmin", "coordinator", "admin"},
+	"order_volume":              {"developer", "superadmin", "admin"},
+	"backorders":                {"developer", "superadmin", "coordinator"},
+}
+
+// ReportAccessRoles returns the roles allowed to pull a report, none for unknown reports
+func ReportAccessRoles(report string) []string {
+	return ReportAccessPolicies[report]
+}
+
+// CanAccessReport reports whether the current user holds one of the roles allowed to pull the report
+func CanAccessReport(c fiber.Ctx, report string) bool {
+	return HasPermission(c, ReportAccessRoles(report))
+}
+
+// RecordReportAccessDenial writes the audit record of the current user being denied a report.
+// Failures are only logged, the request is denied either way.
+func RecordReportAccessDenial(db *gorm.DB, c fiber.Ctx, report string) {
+	userIDStr, _ := c.Locals("userId").(string)
+	userID, err := strconv.ParseUint(userIDStr, 10, 32)
+	if err != nil {
+		return
+	}
+	userRoles, _ := c.Locals("userRoles").([]string)
+
+	denial := models.ReportAccessDenial{
+		UserID:    uint(userID),
+		Report:    report,
+		Method:    c.Method(),
+		Path:      c.OriginalURL(),
+		Roles:     strings.Join(userRoles, ","),
+		IPAddress: c.IP(),
+	}
+	if err := db.Create(&denial).Error; err != nil {
+		log.Println("RecordReportAccessDenial - Failed to record report access denial:", err)
+	}
+}
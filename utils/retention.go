@@ -0,0 +1,246 @@
+package utils
+
+import (
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AnonymizedValue replaces personal data removed by the retention purge
+const AnonymizedValue = "REDACTED"
+
+// Retention purge categories
+const (
+	RetentionCategoryBuyerPII      = "buyer_pii"
+	RetentionCategoryFaceImage     = "face_image"
+	RetentionCategoryFaceImageFile = "face_image_file"
+	RetentionCategoryAttendanceGPS = "attendance_gps"
+	RetentionCategoryQCParcelPhoto = "qc_parcel_photo"
+	RetentionCategoryHandoverPhoto = "handover_photo"
+	RetentionTriggerManual         = "manual"
+	RetentionTriggerScheduled      = "scheduled"
+	retentionActionAnonymize       = "anonymize"
+	retentionActionDelete          = "delete"
+	retentionStatusCompleted       = "completed"
+	retentionStatusFailed          = "failed"
+)
+
+// RetentionPolicy holds the retention period in days per data category, 0 disables the category
+type RetentionPolicy struct {
+	BuyerPIIDays      int `json:"buyerPiiDays"`
+	FaceImageDays     int `json:"faceImageDays"`
+	AttendanceGPSDays int `json:"attendanceGpsDays"`
+	MediaPhotoDays    int `json:"mediaPhotoDays"` // QC parcel photos and courier handover photos
+}
+
+// RetentionPolicyFromConfig builds the retention policy from the application config
+func RetentionPolicyFromConfig(cfg *config.Config) RetentionPolicy {
+	return RetentionPolicy{
+		BuyerPIIDays:      cfg.RetentionBuyerPIIDays,
+		FaceImageDays:     cfg.RetentionFaceImageDays,
+		AttendanceGPSDays: cfg.RetentionAttendanceGPSDays,
+		MediaPhotoDays:    cfg.RetentionMediaPhotoDays,
+	}
+}
+
+// RunRetentionPurge anonymizes or deletes data older than the retention policy and records an audit entry
+// With dryRun only the affected rows are counted and nothing is modified
+func RunRetentionPurge(db *gorm.DB, policy RetentionPolicy, dryRun bool, trigger string, triggeredBy *uint) (*models.PurgeRun, error) {
+	now := time.Now()
+	run := models.PurgeRun{
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		DryRun:      dryRun,
+		Status:      retentionStatusCompleted,
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 1. Anonymize buyer PII of finished orders
+		if policy.BuyerPIIDays > 0 {
+			cutoff := now.AddDate(0, 0, -policy.BuyerPIIDays)
+			query := tx.Model(&models.Order{}).
+				Where("created_at < ?", cutoff).
+				Where("event_status IN ?", []string{"completed", "canceled"}).
+				Where("buyer <> ? OR address <> ?", AnonymizedValue, "")
+
+			affected, err := countOrUpdate(query, dryRun, map[string]interface{}{
+				"buyer":   AnonymizedValue,
+				"address": "",
+			})
+			if err != nil {
+				return err
+			}
+			run.Details = append(run.Details, models.PurgeRunDetail{
+				Category:      RetentionCategoryBuyerPII,
+				Action:        retentionActionAnonymize,
+				RetentionDays: policy.BuyerPIIDays,
+				Cutoff:        cutoff,
+				AffectedRows:  affected,
+			})
+		}
+
+		// 2. Delete inactive face registrations
+		if policy.FaceImageDays > 0 {
+			cutoff := now.AddDate(0, 0, -policy.FaceImageDays)
+			query := tx.Model(&models.UserFace{}).Where("is_active = ? AND updated_at < ?", false, cutoff)
+
+			var affected int64
+			if err := query.Count(&affected).Error; err != nil {
+				return err
+			}
+			if !dryRun && affected > 0 {
+				if err := tx.Where("is_active = ? AND updated_at < ?", false, cutoff).Delete(&models.UserFace{}).Error; err != nil {
+					return err
+				}
+			}
+			run.Details = append(run.Details, models.PurgeRunDetail{
+				Category:      RetentionCategoryFaceImage,
+				Action:        retentionActionDelete,
+				RetentionDays: policy.FaceImageDays,
+				Cutoff:        cutoff,
+				AffectedRows:  affected,
+			})
+		}
+
+		// 3. Remove attendance GPS coordinates
+		if policy.AttendanceGPSDays > 0 {
+			cutoff := now.AddDate(0, 0, -policy.AttendanceGPSDays)
+			query := tx.Model(&models.Attendance{}).
+				Where("checked_in < ?", cutoff).
+				Where("latitude <> 0 OR longitude <> 0 OR accuracy <> 0")
+
+			affected, err := countOrUpdate(query, dryRun, map[string]interface{}{
+				"latitude":  0,
+				"longitude": 0,
+				"accuracy":  0,
+			})
+			if err != nil {
+				return err
+			}
+			run.Details = append(run.Details, models.PurgeRunDetail{
+				Category:      RetentionCategoryAttendanceGPS,
+				Action:        retentionActionAnonymize,
+				RetentionDays: policy.AttendanceGPSDays,
+				Cutoff:        cutoff,
+				AffectedRows:  affected,
+			})
+		}
+
+		// 4. Delete QC parcel photos and clear courier handover photos
+		if policy.MediaPhotoDays > 0 {
+			cutoff := now.AddDate(0, 0, -policy.MediaPhotoDays)
+			query := tx.Model(&models.QCParcelPhoto{}).Where("created_at < ?", cutoff)
+
+			var affected int64
+			if err := query.Count(&affected).Error; err != nil {
+				return err
+			}
+			if !dryRun && affected > 0 {
+				if err := tx.Where("created_at < ?", cutoff).Delete(&models.QCParcelPhoto{}).Error; err != nil {
+					return err
+				}
+			}
+			run.Details = append(run.Details, models.PurgeRunDetail{
+				Category:      RetentionCategoryQCParcelPhoto,
+				Action:        retentionActionDelete,
+				RetentionDays: policy.MediaPhotoDays,
+				Cutoff:        cutoff,
+				AffectedRows:  affected,
+			})
+
+			handoverQuery := tx.Model(&models.HandoverSession{}).
+				Where("closed_at < ?", cutoff).
+				Where("driver_photo <> ? OR driver_signature <> ?", "", "")
+
+			affected, err := countOrUpdate(handoverQuery, dryRun, map[string]interface{}{
+				"driver_photo":     "",
+				"driver_signature": "",
+			})
+			if err != nil {
+				return err
+			}
+			run.Details = append(run.Details, models.PurgeRunDetail{
+				Category:      RetentionCategoryHandoverPhoto,
+				Action:        retentionActionAnonymize,
+				RetentionDays: policy.MediaPhotoDays,
+				Cutoff:        cutoff,
+				AffectedRows:  affected,
+			})
+		}
+
+		return nil
+	})
+
+	// 5. Remove leftover face image files, only once the database purge succeeded
+	if err == nil && policy.FaceImageDays > 0 {
+		cutoff := now.AddDate(0, 0, -policy.FaceImageDays)
+		affected, fileErr := purgeFaceImageFiles(cutoff, dryRun)
+		if fileErr != nil {
+			err = fileErr
+		}
+		run.Details = append(run.Details, models.PurgeRunDetail{
+			Category:      RetentionCategoryFaceImageFile,
+			Action:        retentionActionDelete,
+			RetentionDays: policy.FaceImageDays,
+			Cutoff:        cutoff,
+			AffectedRows:  affected,
+		})
+	}
+
+	if err != nil {
+		run.Status = retentionStatusFailed
+		run.Error = err.Error()
+	}
+
+	// Always record the audit entry, even when the purge failed
+	if auditErr := db.Create(&run).Error; auditErr != nil {
+		log.Println("RunRetentionPurge - Failed to record purge run:", auditErr)
+		if err == nil {
+			err = auditErr
+		}
+	}
+
+	return &run, err
+}
+
+// countOrUpdate counts the rows matched by the query and applies the updates unless dryRun is set
+func countOrUpdate(query *gorm.DB, dryRun bool, updates map[string]interface{}) (int64, error) {
+	if dryRun {
+		var count int64
+		err := query.Count(&count).Error
+		return count, err
+	}
+
+	result := query.Updates(updates)
+	return result.RowsAffected, result.Error
+}
+
+// purgeFaceImageFiles removes temporary face image files older than the cutoff
+func purgeFaceImageFiles(cutoff time.Time, dryRun bool) (int64, error) {
+	return CleanupTempFiles(cutoff, dryRun)
+}
+
+// StartRetentionScheduler runs the retention purge periodically in the background
+func StartRetentionScheduler(db *gorm.DB, policy RetentionPolicy, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if GetMaintenance().Enabled {
+				log.Println("StartRetentionScheduler - Skipping scheduled purge during maintenance mode")
+				continue
+			}
+
+			run, err := RunRetentionPurge(db, policy, false, RetentionTriggerScheduled, nil)
+			if err != nil {
+				log.Println("StartRetentionScheduler - Scheduled purge failed:", err)
+				continue
+			}
+			log.Printf("StartRetentionScheduler - Scheduled purge run %d completed\n", run.ID)
+		}
+	}()
+}
@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// Sources of role changes recorded in the role audit
+const (
+	RoleChangeSourceUser         = "user"
+	RoleChangeSourceBulk         = "bulk"
+	RoleChangeSourceInvitation   = "invitation"
+	RoleChangeSourceRegistration = "registration"
+	RoleChangeSourceRoleDelete   = "role-delete"
+)
+
+// RecordRoleChange writes the audit record and security event of a role granted to or revoked from users, changedBy is nil
+// for self registration
+func RecordRoleChange(db *gorm.DB, role models.Role, action, source string, changedBy *uint, userIDs ...uint) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	audits := make([]models.RoleAudit, len(userIDs))
+	for i, userID := range userIDs {
+		audits[i] = models.RoleAudit{
+			UserID:    userID,
+			RoleID:    role.ID,
+			RoleName:  role.RoleName,
+			Action:    action,
+			Source:    source,
+			ChangedBy: changedBy,
+		}
+	}
+	if err := db.Create(&audits).Error; err != nil {
+		return err
+	}
+
+	// Mirror the change in the security events, with the rest of the account activity
+	events := make([]models.SecurityEvent, len(userIDs))
+	for i := range userIDs {
+		events[i] = models.SecurityEvent{
+			EventType:    models.SecurityEventRoleChange,
+			UserID:       changedBy,
+			TargetUserID: &userIDs[i],
+			Detail:       action + " " + role.RoleName + " (" + source + ")",
+		}
+	}
+	return db.Create(&events).Error
+}
+
+// MinRoleHierarchy returns the highest privilege (lowest hierarchy number) of the role names, 999 when none is known
+func MinRoleHierarchy(db *gorm.DB, roleNames []string) int {
+	minHierarchy := 999
+	var roles []models.Role
+	if len(roleNames) == 0 || db.Where("role_name IN ?", roleNames).Find(&roles).Error != nil {
+		return minHierarchy
+	}
+	for _, role := range roles {
+		if role.Hierarchy < minHierarchy {
+			minHierarchy = role.Hierarchy
+		}
+	}
+	return minHierarchy
+}
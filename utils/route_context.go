@@ -0,0 +1,19 @@
+package utils
+
+import "context"
+
+type routeContextKey struct{}
+
+// WithRoute returns a copy of ctx carrying the HTTP route that triggered the work
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeContextKey{}, route)
+}
+
+// RouteFromContext returns the HTTP route stored in ctx, or an empty string if none
+func RouteFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	route, _ := ctx.Value(routeContextKey{}).(string)
+	return route
+}
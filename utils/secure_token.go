@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+)
+
+// GenerateSecureToken returns a random URL-safe token and its SHA-256 hash.
+// Only the hash should be stored; the token is handed to the user once.
+func GenerateSecureToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := hex.EncodeToString(buf)
+	return token, HashSecureToken(token), nil
+}
+
+// HashSecureToken returns the SHA-256 hex digest used to look up a token
+func HashSecureToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateOTP returns a random numeric one-time password with the given number of digits.
+// Each digit is drawn uniformly, a random byte modulo 10 would favor the digits 0 to 5.
+func GenerateOTP(digits int) (string, error) {
+	otp := make([]byte, digits)
+	for i := range otp {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		otp[i] = '0' + byte(digit.Int64())
+	}
+	return string(otp), nil
+}
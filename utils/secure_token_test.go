@@ -0,0 +1,35 @@
+package utils
+
+import "testing"
+
+func TestGenerateOTP(t *testing.T) {
+	otp, err := GenerateOTP(6)
+	if err != nil {
+		t.Fatalf("GenerateOTP(6) failed: %v", err)
+	}
+	if len(otp) != 6 {
+		t.Fatalf("GenerateOTP(6) = %q, want 6 digits", otp)
+	}
+
+	// A million digits, every digit is expected 100000 times with a standard deviation of 300.
+	// A random byte modulo 10 draws 0 to 5 about 101560 times and 6 to 9 about 97660 times.
+	const codes, digits = 1000, 1000
+	var counts [10]int
+	for i := 0; i < codes; i++ {
+		otp, err := GenerateOTP(digits)
+		if err != nil {
+			t.Fatalf("GenerateOTP(%d) failed: %v", digits, err)
+		}
+		for _, digit := range otp {
+			if digit < '0' || digit > '9' {
+				t.Fatalf("GenerateOTP returned non digit %q", digit)
+			}
+			counts[digit-'0']++
+		}
+	}
+	for digit, count := range counts {
+		if count < 98500 || count > 101500 {
+			t.Errorf("digit %d drawn %d times, want about 100000", digit, count)
+		}
+	}
+}
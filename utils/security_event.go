@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// SecurityAlertRoles are notified when a security alert rule is triggered
+var SecurityAlertRoles = []string{"developer", "superadmin"}
+
+// Subjects security events are counted by in alert rules
+const (
+	SecurityAlertByUsername = "username"
+	SecurityAlertByUser     = "user"
+	SecurityAlertByIP       = "ip"
+)
+
+// SecurityAlertRule raises an alert when Threshold events of a type share a subject within the window
+type SecurityAlertRule struct {
+	Name          string `json:"name"`
+	EventType     string `json:"eventType"`
+	GroupBy       string `json:"groupBy"` // username, user or ip
+	Threshold     int    `json:"threshold"`
+	WindowMinutes int    `json:"windowMinutes"`
+}
+
+var (
+	securityAlertMu    sync.RWMutex
+	securityAlertRules []SecurityAlertRule
+)
+
+// InitSecurityAlertRules loads the alert rules from the configuration, rules with a threshold of 0 are disabled
+func InitSecurityAlertRules(cfg *config.Config) {
+	candidates := []SecurityAlertRule{
+		{Name: "failed_logins_per_account", EventType: models.SecurityEventFailedLogin, GroupBy: SecurityAlertByUsername, Threshold: cfg.SecurityAlertFailedLogins},
+		{Name: "failed_logins_per_ip", EventType: models.SecurityEventFailedLogin, GroupBy: SecurityAlertByIP, Threshold: cfg.SecurityAlertFailedLoginsPerIP},
+		{Name: "permission_denials_per_user", EventType: models.SecurityEventPermissionDenied, GroupBy: SecurityAlertByUser, Threshold: cfg.SecurityAlertPermissionDenials},
+	}
+
+	rules := make([]SecurityAlertRule, 0, len(candidates))
+	for _, rule := range candidates {
+		if rule.Threshold > 0 && cfg.SecurityAlertWindowMinutes > 0 {
+			rule.WindowMinutes = cfg.SecurityAlertWindowMinutes
+			rules = append(rules, rule)
+		}
+	}
+
+	securityAlertMu.Lock()
+	defer securityAlertMu.Unlock()
+	securityAlertRules = rules
+}
+
+// GetSecurityAlertRules returns the enabled alert rules, none until InitSecurityAlertRules is called
+func GetSecurityAlertRules() []SecurityAlertRule {
+	securityAlertMu.RLock()
+	defer securityAlertMu.RUnlock()
+	return append([]SecurityAlertRule(nil), securityAlertRules...)
+}
+
+// RecordFailedLogin writes the security event of a failed login, userID is nil when the account does not exist
+func RecordFailedLogin(db *gorm.DB, c fiber.Ctx, username string, userID *uint, reason string) {
+	event := securityEventFromRequest(c, models.SecurityEventFailedLogin)
+	event.Username = NormalizeUsername(username)
+	if len(event.Username) > 50 {
+		event.Username = event.Username[:50]
+	}
+	event.UserID = userID
+	event.Detail = reason
+	RecordSecurityEvent(db, event)
+}
+
+// RecordPermissionDenied writes the security event of the current user being denied a route or resource
+func RecordPermissionDenied(db *gorm.DB, c fiber.Ctx, detail string) {
+	event := securityEventFromRequest(c, models.SecurityEventPermissionDenied)
+	userIDStr, _ := c.Locals("userId").(string)
+	if userID, err := strconv.ParseUint(userIDStr, 10, 32); err == nil {
+		id := uint(userID)
+		event.UserID = &id
+	}
+	event.Username, _ = c.Locals("username").(string)
+	event.Detail = detail
+	RecordSecurityEvent(db, event)
+}
+
+// RecordSecurityEvent writes a security event and raises the alerts it triggers.
+// Failures are only logged, the action the event is about goes on either way.
+func RecordSecurityEvent(db *gorm.DB, event models.SecurityEvent) {
+	if err := db.Create(&event).Error; err != nil {
+		log.Println("RecordSecurityEvent - Failed to record security event:", err)
+		return
+	}
+
+	for _, rule := range GetSecurityAlertRules() {
+		if rule.EventType != event.EventType {
+			continue
+		}
+		if err := evaluateSecurityAlertRule(db, rule, event); err != nil {
+			log.Println("RecordSecurityEvent - Failed to evaluate alert rule "+rule.Name+":", err)
+		}
+	}
+}
+
+// evaluateSecurityAlertRule raises an alert once the rule threshold is reached for the subject of the event.
+// A subject is alerted at most once per window.
+func evaluateSecurityAlertRule(db *gorm.DB, rule SecurityAlertRule, event models.SecurityEvent) error {
+	column, subject := securityAlertSubject(rule.GroupBy, event)
+	if subject == "" {
+		return nil
+	}
+	since := time.Now().Add(-time.Duration(rule.WindowMinutes) * time.Minute)
+
+	var count int64
+	if err := db.Model(&models.SecurityEvent{}).
+		Where("event_type = ? AND "+column+" = ? AND created_at >= ?", rule.EventType, subject, since).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count < int64(rule.Threshold) {
+		return nil
+	}
+
+	var alerted int64
+	if err := db.Model(&models.SecurityEvent{}).
+		Where("event_type = ? AND detail = ? AND "+column+" = ? AND created_at >= ?", models.SecurityEventAlert, rule.Name, subject, since).
+		Count(&alerted).Error; err != nil {
+		return err
+	}
+	if alerted > 0 {
+		return nil
+	}
+
+	alert := models.SecurityEvent{
+		EventType: models.SecurityEventAlert,
+		Detail:    rule.Name,
+		Method:    event.Method,
+		Path:      event.Path,
+	}
+	switch rule.GroupBy {
+	case SecurityAlertByUsername:
+		alert.Username = event.Username
+		alert.UserID = event.UserID
+	case SecurityAlertByUser:
+		alert.UserID = event.UserID
+		alert.Username = event.Username
+	case SecurityAlertByIP:
+		alert.IPAddress = event.IPAddress
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&alert).Error; err != nil {
+			return err
+		}
+		title := fmt.Sprintf("Security alert on %s %s", rule.GroupBy, subject)
+		message := fmt.Sprintf("%d %s events from %s %s in the last %d minutes triggered the %s rule. Review the security events and disable the account or block the address if the activity is not expected.",
+			count, strings.ReplaceAll(rule.EventType, "_", " "), rule.GroupBy, subject, rule.WindowMinutes, rule.Name)
+		return NotifyRoles(tx, SecurityAlertRoles, "security_alert", title, message, "security_event", alert.ID)
+	})
+}
+
+// securityAlertSubject returns the column and value an alert rule counts events by
+func securityAlertSubject(groupBy string, event models.SecurityEvent) (string, string) {
+	switch groupBy {
+	case SecurityAlertByUsername:
+		return "username", event.Username
+	case SecurityAlertByUser:
+		if event.UserID == nil {
+			return "user_id", ""
+		}
+		return "user_id", strconv.FormatUint(uint64(*event.UserID), 10)
+	case SecurityAlertByIP:
+		return "ip_address", event.IPAddress
+	}
+	return "", ""
+}
+
+// securityEventFromRequest starts a security event with the request details
+func securityEventFromRequest(c fiber.Ctx, eventType string) models.SecurityEvent {
+	return models.SecurityEvent{
+		EventType: eventType,
+		Method:    c.Method(),
+		Path:      c.OriginalURL(),
+		IPAddress: c.IP(),
+		UserAgent: c.Get("User-Agent"),
+	}
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// shadowResponseLogLimit is how much of the primary response is kept with a divergence
+const shadowResponseLogLimit = 2048
+
+// ShadowResult is the outcome of a request as decided by one implementation of a shadow experiment
+type ShadowResult struct {
+	Status  int    `json:"status"`           // HTTP status the implementation answers with
+	Outcome string `json:"outcome"`          // e.g. scanned, wrong_sku, over_scan
+	Detail  string `json:"detail,omitempty"` // why, for divergences
+}
+
+// ShadowStat counts the requests of an experiment operation compared since the server started
+type ShadowStat struct {
+	Experiment string  `json:"experiment"`
+	Operation  string  `json:"operation"`
+	Compared   int64   `json:"compared"`
+	Diverged   int64   `json:"diverged"`
+	Failed     int64   `json:"failed"`    // shadow runs that errored or panicked, not counted as compared
+	MatchRate  float64 `json:"matchRate"` // percentage of compared requests where both implementations agreed
+}
+
+var (
+	shadowStatsMu sync.Mutex
+	shadowStats   = make(map[string]*ShadowStat) // experiment:operation -> counters
+)
+
+// ShadowSampled returns true for the given percentage of calls
+func ShadowSampled(percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	return percent >= 100 || rand.Intn(100) < percent
+}
+
+func shadowStat(experiment, operation string) *ShadowStat {
+	key := experiment + ":" + operation
+	stat, ok := shadowStats[key]
+	if !ok {
+		stat = &ShadowStat{Experiment: experiment, Operation: operation}
+		shadowStats[key] = stat
+	}
+	return stat
+}
+
+// ShadowPrimaryResponse returns the status and the truncated body of the response the primary handler gave
+func ShadowPrimaryResponse(c fiber.Ctx, err error) (int, string) {
+	return handlerResponse(c, err, shadowResponseLogLimit)
+}
+
+// RecordShadowFailure counts a shadow run that could not produce a result
+func RecordShadowFailure(experiment, operation string, reason interface{}) {
+	log.Println("Shadow -", experiment, operation, "failed:", reason)
+
+	shadowStatsMu.Lock()
+	shadowStat(experiment, operation).Failed++
+	shadowStatsMu.Unlock()
+}
+
+// RecordShadowComparison counts a compared request and logs it as a divergence when the shadow answered with another
+// status than the primary implementation. The divergence is stored in the background, never delaying the response.
+func RecordShadowComparison(db *gorm.DB, divergence models.ShadowDivergence) {
+	diverged := divergence.PrimaryStatus != divergence.ShadowStatus
+
+	shadowStatsMu.Lock()
+	stat := shadowStat(divergence.Experiment, divergence.Operation)
+	stat.Compared++
+	if diverged {
+		stat.Diverged++
+	}
+	shadowStatsMu.Unlock()
+
+	if !diverged {
+		return
+	}
+
+	log.Printf("Shadow - %s %s diverged on %s (primary=%d, shadow=%d %s)\n", divergence.Experiment, divergence.Operation, divergence.Subject, divergence.PrimaryStatus, divergence.ShadowStatus, divergence.ShadowOutcome)
+	go func() {
+		if err := db.Create(&divergence).Error; err != nil {
+			log.Println("RecordShadowComparison - Failed to store divergence:", err)
+		}
+	}()
+}
+
+// GetShadowStats returns the comparison counters of every experiment operation, sorted by experiment and operation
+func GetShadowStats() []ShadowStat {
+	shadowStatsMu.Lock()
+	defer shadowStatsMu.Unlock()
+
+	stats := make([]ShadowStat, 0, len(shadowStats))
+	for _, stat := range shadowStats {
+		snapshot := *stat
+		if snapshot.Compared > 0 {
+			snapshot.MatchRate = float64(snapshot.Compared-snapshot.Diverged) / float64(snapshot.Compared) * 100
+		}
+		stats = append(stats, snapshot)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Experiment != stats[j].Experiment {
+			return stats[i].Experiment < stats[j].Experiment
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
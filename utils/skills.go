@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MissingUserSkills returns the skills among the given ones the user holds no valid assignment of
+func MissingUserSkills(db *gorm.DB, userID uint, skills []models.Skill) ([]models.Skill, error) {
+	if len(skills) == 0 {
+		return nil, nil
+	}
+	skillIDs := make([]uint, len(skills))
+	for i, skill := range skills {
+		skillIDs[i] = skill.ID
+	}
+
+	var held []uint
+	if err := db.Model(&models.UserSkill{}).
+		Where("user_id = ? AND skill_id IN ? AND (expires_at IS NULL OR expires_at > ?)", userID, skillIDs, time.Now()).
+		Pluck("skill_id", &held).Error; err != nil {
+		return nil, err
+	}
+	heldSet := make(map[uint]bool, len(held))
+	for _, id := range held {
+		heldSet[id] = true
+	}
+
+	var missing []models.Skill
+	for _, skill := range skills {
+		if !heldSet[skill.ID] {
+			missing = append(missing, skill)
+		}
+	}
+	return missing, nil
+}
+
+// OrderRequiredSkills returns the skills the products of the order require from its picker.
+// The SKUs are read from the stored order details, so callers need not preload them.
+func OrderRequiredSkills(db *gorm.DB, order *models.Order) ([]models.Skill, error) {
+	skus := db.Model(&models.OrderDetail{}).Select("sku").Where("order_id = ?", order.ID)
+
+	var skills []models.Skill
+	err := db.Where("id IN (?)", db.Model(&models.Product{}).Select("required_skill_id").Where("sku IN (?) AND required_skill_id IS NOT NULL", skus)).
+		Order("code ASC").Find(&skills).Error
+	return skills, err
+}
+
+// QCLaneRequiredSkills returns the skills required to work the QC lane
+func QCLaneRequiredSkills(db *gorm.DB, lane string) ([]models.Skill, error) {
+	var skills []models.Skill
+	err := db.Where("qc_lane = ?", lane).Order("code ASC").Find(&skills).Error
+	return skills, err
+}
+
+// MissingOrderSkills returns the skills required by the order that the picker does not hold
+func MissingOrderSkills(db *gorm.DB, pickerID uint, order *models.Order) ([]models.Skill, error) {
+	required, err := OrderRequiredSkills(db, order)
+	if err != nil {
+		return nil, err
+	}
+	return MissingUserSkills(db, pickerID, required)
+}
+
+// MissingQCLaneSkills returns the skills required by the QC lane that the user does not hold
+func MissingQCLaneSkills(db *gorm.DB, userID uint, lane string) ([]models.Skill, error) {
+	required, err := QCLaneRequiredSkills(db, lane)
+	if err != nil {
+		return nil, err
+	}
+	return MissingUserSkills(db, userID, required)
+}
+
+// SkillNames joins the names of the skills for messages
+func SkillNames(skills []models.Skill) string {
+	names := make([]string, len(skills))
+	for i, skill := range skills {
+		names[i] = skill.Name
+	}
+	return strings.Join(names, ", ")
+}
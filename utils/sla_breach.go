@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SLABreachNotifyRoles are notified when an order misses its SentBefore deadline, admin is customer service
+var SLABreachNotifyRoles = []string{"admin", "coordinator"}
+
+// Resolutions of an SLA breach
+const (
+	SLABreachResolutionShipped  = "shipped"
+	SLABreachResolutionCanceled = "canceled"
+)
+
+// slaBreachStageReasons explains a breach from the processing status the order was stuck in
+var slaBreachStageReasons = map[string]string{
+	models.ProcessingStatusReadyToPick:      "Never assigned to a picker",
+	models.ProcessingStatusPickingProgress:  "Picking not completed",
+	models.ProcessingStatusPickingPending:   "Picking pending coordinator follow-up",
+	models.ProcessingStatusAwaitingStock:    "Waiting for stock after a pick shortage",
+	models.ProcessingStatusPickingCompleted: "Picked but QC not started",
+	models.ProcessingStatusQCProgress:       "QC not completed",
+	models.ProcessingStatusQCCompleted:      "QC completed but not scanned for outbound",
+}
+
+// SLABreachReason derives the breach reason from the stage the order was stuck in
+func SLABreachReason(order *models.Order) string {
+	reason, ok := slaBreachStageReasons[order.ProcessingStatus]
+	if !ok {
+		reason = "Not scanned for outbound"
+	}
+	if order.EventStatus == models.EventStatusHeld {
+		reason = "On hold: " + order.HoldReason + " (" + reason + ")"
+	}
+	return reason
+}
+
+// DetectSLABreaches records a breach for every open order past its SentBefore deadline without an outbound scan,
+// notifies customer service and coordinators, and resolves earlier breaches whose order has since shipped or been canceled.
+// Returns the number of new breaches.
+func DetectSLABreaches(db *gorm.DB) (int, error) {
+	now := time.Now()
+
+	var orders []models.Order
+	if err := db.Where("sent_before < ? AND event_status IN ? AND processing_status <> ?",
+		now, []string{models.EventStatusInProgress, models.EventStatusHeld}, models.ProcessingStatusOutboundCompleted).
+		Where("id NOT IN (?)", db.Model(&models.SLABreach{}).Select("order_id")).
+		Order("sent_before ASC").Find(&orders).Error; err != nil {
+		return 0, err
+	}
+
+	detected := 0
+	for _, order := range orders {
+		breach := models.SLABreach{
+			OrderID:        order.ID,
+			OrderGineeID:   order.OrderGineeID,
+			TrackingNumber: order.TrackingNumber,
+			SentBefore:     order.SentBefore,
+			Stage:          order.ProcessingStatus,
+			Held:           order.EventStatus == models.EventStatusHeld,
+			Reason:         SLABreachReason(&order),
+			DetectedAt:     now,
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&breach).Error; err != nil {
+				return err
+			}
+			message := fmt.Sprintf("Order %s (%s) missed its send deadline of %s: %s",
+				order.OrderGineeID, order.TrackingNumber, order.SentBefore.Format("02-01-2006 15:04"), breach.Reason)
+			return NotifyRoles(tx, SLABreachNotifyRoles, "sla_breach", "Order missed send deadline", message, "order", order.ID)
+		})
+		if err != nil {
+			log.Println("DetectSLABreaches - Failed to record breach for order", order.ID, ":", err)
+			continue
+		}
+		detected++
+	}
+
+	// Close breaches whose order has since shipped or been canceled
+	resolutions := []struct {
+		Resolution string
+		Condition  string
+		Value      string
+	}{
+		{SLABreachResolutionShipped, "processing_status = ?", models.ProcessingStatusOutboundCompleted},
+		{SLABreachResolutionCanceled, "event_status = ?", models.EventStatusCanceled},
+	}
+	for _, resolution := range resolutions {
+		if err := db.Model(&models.SLABreach{}).
+			Where("resolved_at IS NULL AND order_id IN (?)", db.Model(&models.Order{}).Select("id").Where(resolution.Condition, resolution.Value)).
+			Updates(map[string]interface{}{"resolved_at": now, "resolution": resolution.Resolution}).Error; err != nil {
+			return detected, err
+		}
+	}
+
+	return detected, nil
+}
+
+// StartSLABreachScheduler checks for SentBefore breaches periodically in the background
+func StartSLABreachScheduler(db *gorm.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if GetMaintenance().Enabled {
+				log.Println("StartSLABreachScheduler - Skipping breach check during maintenance mode")
+				continue
+			}
+
+			detected, err := DetectSLABreaches(db)
+			if err != nil {
+				log.Println("StartSLABreachScheduler - Breach check failed:", err)
+				continue
+			}
+			if detected > 0 {
+				log.Printf("StartSLABreachScheduler - %d new SLA breaches detected\n", detected)
+			}
+		}
+	}()
+}
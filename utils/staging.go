@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReleaseStagingPlacement closes the open staging placement of a parcel when it leaves its staging area. With area
+// types given, only a placement in an area of one of those types is closed.
+func ReleaseStagingPlacement(db *gorm.DB, trackingNumber, reason string, removedBy uint, areaTypes ...string) error {
+	query := db.Model(&models.StagingPlacement{}).Where("tracking_number = ? AND removed_at IS NULL", trackingNumber)
+	if len(areaTypes) > 0 {
+		query = query.Where("staging_area_id IN (?)", db.Model(&models.StagingArea{}).Select("id").Where("area_type IN ?", areaTypes))
+	}
+	return query.Updates(map[string]interface{}{
+		"removed_at":     time.Now(),
+		"removed_by":     removedBy,
+		"removal_reason": reason,
+	}).Error
+}
+
+// CountStagingOccupancy returns the number of parcels in each staging area, by area ID
+func CountStagingOccupancy(db *gorm.DB) (map[uint]int, error) {
+	var rows []struct {
+		StagingAreaID uint
+		Occupied      int
+	}
+	if err := db.Model(&models.StagingPlacement{}).
+		Select("staging_area_id, COUNT(*) AS occupied").
+		Where("removed_at IS NULL").
+		Group("staging_area_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	occupancy := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		occupancy[row.StagingAreaID] = row.Occupied
+	}
+	return occupancy, nil
+}
@@ -0,0 +1,235 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// File storage drivers
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+// ErrStorageObjectNotFound is returned when a stored file does not exist
+var ErrStorageObjectNotFound = errors.New("stored file not found")
+
+// Storage keeps media files under slash separated keys, e.g. exports/12/orders.xlsx
+type Storage interface {
+	Put(ctx context.Context, key string, content []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+var (
+	storageMu     sync.RWMutex
+	mediaStorage  Storage = &LocalStorage{Dir: "storage"}
+	storageTmpDir         = "tmp"
+)
+
+// InitStorage sets up the configured storage driver and creates the temp and local storage directories
+func InitStorage(cfg *config.Config) error {
+	var storage Storage
+	switch cfg.StorageDriver {
+	case StorageDriverLocal:
+		if err := os.MkdirAll(cfg.StorageLocalDir, 0o750); err != nil {
+			return fmt.Errorf("failed to create storage directory %s: %w", cfg.StorageLocalDir, err)
+		}
+		storage = &LocalStorage{Dir: cfg.StorageLocalDir}
+	case StorageDriverS3:
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return errors.New("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for the s3 storage driver")
+		}
+		storage = &S3Storage{
+			Endpoint:        strings.TrimSuffix(cfg.S3Endpoint, "/"),
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+		}
+	default:
+		return fmt.Errorf("unknown storage driver %q, use %s or %s", cfg.StorageDriver, StorageDriverLocal, StorageDriverS3)
+	}
+
+	if err := os.MkdirAll(cfg.TempDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create temp directory %s: %w", cfg.TempDir, err)
+	}
+
+	storageMu.Lock()
+	defer storageMu.Unlock()
+	mediaStorage = storage
+	storageTmpDir = cfg.TempDir
+	return nil
+}
+
+// GetStorage returns the configured storage driver
+func GetStorage() Storage {
+	storageMu.RLock()
+	defer storageMu.RUnlock()
+	return mediaStorage
+}
+
+// TempDir returns the directory of temporary upload files
+func TempDir() string {
+	storageMu.RLock()
+	defer storageMu.RUnlock()
+	return storageTmpDir
+}
+
+// CreateTempFile reserves a uniquely named empty file in the temp directory and returns its path,
+// the pattern works like os.CreateTemp. The caller removes the file once done with it,
+// files left behind by a crash are removed by the temp cleanup.
+func CreateTempFile(pattern string) (string, error) {
+	dir := TempDir()
+	file, err := os.CreateTemp(dir, pattern)
+	if errors.Is(err, os.ErrNotExist) {
+		// The directory was removed while running, provision it again
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return "", err
+		}
+		file, err = os.CreateTemp(dir, pattern)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// CleanupTempFiles removes the files of the temp directory last modified before the cutoff
+func CleanupTempFiles(cutoff time.Time, dryRun bool) (int64, error) {
+	dir := TempDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var affected int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		affected++
+		if !dryRun {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return affected, err
+			}
+		}
+	}
+
+	return affected, nil
+}
+
+// StartTempCleanupWorker removes orphaned temporary files on startup and then periodically in the background.
+// Files older than maxAge were left behind by interrupted requests, in-flight uploads are much younger.
+func StartTempCleanupWorker(interval, maxAge time.Duration) {
+	cleanup := func() {
+		removed, err := CleanupTempFiles(time.Now().Add(-maxAge), false)
+		if err != nil {
+			log.Println("StartTempCleanupWorker - Failed to remove orphaned temp files:", err)
+			return
+		}
+		if removed > 0 {
+			log.Printf("StartTempCleanupWorker - Removed %d orphaned temp files", removed)
+		}
+	}
+
+	go func() {
+		cleanup()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			cleanup()
+		}
+	}()
+}
+
+// LocalStorage stores files in a directory of the local filesystem
+type LocalStorage struct {
+	Dir string
+}
+
+// path resolves a key inside the storage directory, rejecting keys escaping it
+func (ls *LocalStorage) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(ls.Dir, filepath.FromSlash(cleaned)), nil
+}
+
+// Put writes the file, replacing an existing file of the key
+func (ls *LocalStorage) Put(ctx context.Context, key string, content []byte, contentType string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	// Write next to the target and rename, readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Get reads the file
+func (ls *LocalStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := ls.path(key)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrStorageObjectNotFound
+	}
+	return content, err
+}
+
+// Delete removes the file, deleting a missing file is not an error
+func (ls *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/models"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Rule checks finding entities left in an inconsistent state
+const (
+	StuckRuleQCInProgress          = "qc_in_progress"                // QC Ribbon or QC Online still in progress long after it started
+	StuckRuleQCCompletedNoOutbound = "qc_completed_without_outbound" // QC completed but the parcel was never scanned out
+	StuckRuleOrphanOutbound        = "orphan_outbound"               // outbound scan whose tracking number matches no order
+)
+
+// StuckRules lists the stuck entity rules in report order
+var StuckRules = []string{
+	StuckRuleQCInProgress,
+	StuckRuleQCCompletedNoOutbound,
+	StuckRuleOrphanOutbound,
+}
+
+// IsStuckRule reports whether the value is a known stuck entity rule
+func IsStuckRule(value string) bool {
+	for _, rule := range StuckRules {
+		if rule == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Age after which an entity counts as stuck, in business time of the business calendar
+const (
+	StuckQCInProgressAfter          = 24 * time.Hour
+	StuckQCCompletedNoOutboundAfter = 48 * time.Hour
+)
+
+// stuckEntityLimit caps the entities listed per rule, the count still covers all of them
+const stuckEntityLimit = 200
+
+// StuckAction is the suggested remediation of a stuck entity, an existing API endpoint
+type StuckAction struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Description string `json:"description"`
+}
+
+// StuckEntity is an entity found by a stuck entity rule
+type StuckEntity struct {
+	Rule           string      `json:"rule"`
+	EntityType     string      `json:"entityType"`
+	EntityID       uint        `json:"entityId"`
+	TrackingNumber string      `json:"trackingNumber"`
+	Detail         string      `json:"detail"`
+	Since          string      `json:"since"`
+	Action         StuckAction `json:"action"`
+}
+
+// StuckRuleResult holds the entities found by one rule
+type StuckRuleResult struct {
+	Rule     string        `json:"rule"`
+	Count    int64         `json:"count"`
+	Entities []StuckEntity `json:"entities"`
+}
+
+// stuckRow is the row scanned by the rule queries
+type stuckRow struct {
+	ID             uint
+	TrackingNumber string
+	Since          time.Time
+}
+
+// FindStuckEntities runs the rule checks, all of them when rule is empty
+func FindStuckEntities(db *gorm.DB, rule string, now time.Time) ([]StuckRuleResult, error) {
+	checks := map[string]func(*gorm.DB, time.Time) (StuckRuleResult, error){
+		StuckRuleQCInProgress:          findStuckQCInProgress,
+		StuckRuleQCCompletedNoOutbound: findQCCompletedWithoutOutbound,
+		StuckRuleOrphanOutbound:        findOrphanOutbounds,
+	}
+
+	results := []StuckRuleResult{}
+	for _, stuckRule := range StuckRules {
+		if rule != "" && rule != stuckRule {
+			continue
+		}
+		result, err := checks[stuckRule](db, now)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", stuckRule, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// findStuckQCInProgress finds QC Ribbons and QC Onlines in progress for longer than StuckQCInProgressAfter
+func findStuckQCInProgress(db *gorm.DB, now time.Time) (StuckRuleResult, error) {
+	result := StuckRuleResult{Rule: StuckRuleQCInProgress, Entities: []StuckEntity{}}
+	calendar := GetBusinessCalendar()
+	cutoff := calendar.Before(now, StuckQCInProgressAfter)
+
+	sources := []struct {
+		model      interface{}
+		entityType string
+		voidPath   string
+	}{
+		{&models.QCRibbon{}, "qc_ribbon", "/api/ribbons/qc-ribbons/%d/void"},
+		{&models.QCOnline{}, "qc_online", "/api/onlines/qc-onlines/%d/void"},
+	}
+	for _, source := range sources {
+		query := db.Model(source.model).Where("status = ? AND created_at < ?", models.QCStatusInProgress, cutoff)
+
+		var count int64
+		if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil {
+			return result, err
+		}
+		result.Count += count
+
+		var rows []stuckRow
+		if err := query.Select("id, tracking_number, created_at AS since").Order("created_at ASC").Limit(stuckEntityLimit).Scan(&rows).Error; err != nil {
+			return result, err
+		}
+		for _, row := range rows {
+			result.Entities = append(result.Entities, StuckEntity{
+				Rule:           StuckRuleQCInProgress,
+				EntityType:     source.entityType,
+				EntityID:       row.ID,
+				TrackingNumber: row.TrackingNumber,
+				Detail:         fmt.Sprintf("QC in progress for %s", formatStuckAge(calendar.Duration(row.Since, now))),
+				Since:          row.Since.Format("02-01-2006 15:04:05"),
+				Action: StuckAction{
+					Method:      "PUT",
+					Path:        fmt.Sprintf(source.voidPath, row.ID),
+					Description: "Void the QC with coordinator approval, the order returns to picking_completed and can be QC'd again",
+				},
+			})
+		}
+	}
+	return result, nil
+}
+
+// findQCCompletedWithoutOutbound finds orders QC completed longer than StuckQCCompletedNoOutboundAfter ago that have no outbound.
+// The QC completion time is taken from the completed QC, falling back to the last order update.
+func findQCCompletedWithoutOutbound(db *gorm.DB, now time.Time) (StuckRuleResult, error) {
+	result := StuckRuleResult{Rule: StuckRuleQCCompletedNoOutbound, Entities: []StuckEntity{}}
+	calendar := GetBusinessCalendar()
+	cutoff := calendar.Before(now, StuckQCCompletedNoOutboundAfter)
+
+	completedAt := `COALESCE(
+		(SELECT MAX(qr.updated_at) FROM qc_ribbons qr WHERE qr.tracking_number = orders.tracking_number AND qr.status = ?),
+		(SELECT MAX(qo.updated_at) FROM qc_onlines qo WHERE qo.tracking_number = orders.tracking_number AND qo.status = ?),
+		orders.updated_at)`
+	query := db.Model(&models.Order{}).
+		Where("orders.processing_status = ? AND orders.event_status <> ?", models.ProcessingStatusQCCompleted, models.EventStatusCanceled).
+		Where("NOT EXISTS (SELECT 1 FROM outbounds WHERE outbounds.tracking_number = orders.tracking_number)").
+		Where(completedAt+" < ?", models.QCStatusCompleted, models.QCStatusCompleted, cutoff)
+
+	if err := query.Session(&gorm.Session{}).Count(&result.Count).Error; err != nil {
+		return result, err
+	}
+
+	var rows []stuckRow
+	if err := query.Select("orders.id, orders.tracking_number, "+completedAt+" AS since", models.QCStatusCompleted, models.QCStatusCompleted).
+		Order("since ASC").Limit(stuckEntityLimit).Scan(&rows).Error; err != nil {
+		return result, err
+	}
+	for _, row := range rows {
+		result.Entities = append(result.Entities, StuckEntity{
+			Rule:           StuckRuleQCCompletedNoOutbound,
+			EntityType:     "order",
+			EntityID:       row.ID,
+			TrackingNumber: row.TrackingNumber,
+			Detail:         fmt.Sprintf("QC completed %s ago without an outbound scan", formatStuckAge(calendar.Duration(row.Since, now))),
+			Since:          row.Since.Format("02-01-2006 15:04:05"),
+			Action: StuckAction{
+				Method:      "POST",
+				Path:        "/api/outbounds",
+				Description: "Scan the parcel out at the outbound station, or cancel the order when it will not ship",
+			},
+		})
+	}
+	return result, nil
+}
+
+// findOrphanOutbounds finds outbounds whose tracking number matches no order
+func findOrphanOutbounds(db *gorm.DB, now time.Time) (StuckRuleResult, error) {
+	result := StuckRuleResult{Rule: StuckRuleOrphanOutbound, Entities: []StuckEntity{}}
+
+	query := db.Model(&models.Outbound{}).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.tracking_number = outbounds.tracking_number)")
+
+	if err := query.Session(&gorm.Session{}).Count(&result.Count).Error; err != nil {
+		return result, err
+	}
+
+	var rows []stuckRow
+	if err := query.Select("outbounds.id, outbounds.tracking_number, outbounds.created_at AS since").
+		Order("outbounds.created_at ASC").Limit(stuckEntityLimit).Scan(&rows).Error; err != nil {
+		return result, err
+	}
+	for _, row := range rows {
+		result.Entities = append(result.Entities, StuckEntity{
+			Rule:           StuckRuleOrphanOutbound,
+			EntityType:     "outbound",
+			EntityID:       row.ID,
+			TrackingNumber: row.TrackingNumber,
+			Detail:         fmt.Sprintf("Outbound scanned %s ago matches no order", formatStuckAge(now.Sub(row.Since))),
+			Since:          row.Since.Format("02-01-2006 15:04:05"),
+			Action: StuckAction{
+				Method:      "GET",
+				Path:        "/api/orders?search=" + url.QueryEscape(row.TrackingNumber),
+				Description: "Look the order up, its tracking number may have been changed or duplicated after the outbound scan",
+			},
+		})
+	}
+	return result, nil
+}
+
+// formatStuckAge formats an age in whole days and hours
+func formatStuckAge(age time.Duration) string {
+	hours := int(age.Hours())
+	if hours < 24 {
+		return fmt.Sprintf("%dh", hours)
+	}
+	return fmt.Sprintf("%dd %dh", hours/24, hours%24)
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// unscopedTeamRoles see every team even when they are members of one
+var unscopedTeamRoles = []string{"developer", "superadmin"}
+
+// TeamMembersQuery returns a subquery selecting the user ids of the members of the given teams
+func TeamMembersQuery(db *gorm.DB, teamIDs ...uint) *gorm.DB {
+	return db.Model(&models.TeamMember{}).Select("user_id").Where("team_id IN ?", teamIDs)
+}
+
+// CoordinatorTeamScope returns the teams a coordinator is limited to, or nil when the user is not scoped.
+// Coordinators who belong to a team only manage the users and orders of their teams,
+// coordinators without a team and developers or superadmins manage everyone.
+func CoordinatorTeamScope(db *gorm.DB, userID uint, roles []string) ([]uint, error) {
+	isCoordinator := false
+	for _, role := range roles {
+		for _, unscoped := range unscopedTeamRoles {
+			if role == unscoped {
+				return nil, nil
+			}
+		}
+		if role == "coordinator" {
+			isCoordinator = true
+		}
+	}
+	if !isCoordinator {
+		return nil, nil
+	}
+
+	var teamIDs []uint
+	if err := db.Model(&models.TeamMember{}).Where("user_id = ?", userID).Pluck("team_id", &teamIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+	return teamIDs, nil
+}
+
+// IsTeamMember reports whether the user belongs to one of the given teams
+func IsTeamMember(db *gorm.DB, userID uint, teamIDs []uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.TeamMember{}).Where("user_id = ? AND team_id IN ?", userID, teamIDs).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// CanManageUser reports whether the current user may manage the target user, coordinators with a team only manage its members
+func CanManageUser(db *gorm.DB, userID uint, roles []string, targetUserID uint) (bool, error) {
+	teamIDs, err := CoordinatorTeamScope(db, userID, roles)
+	if err != nil || teamIDs == nil {
+		return err == nil, err
+	}
+	return IsTeamMember(db, targetUserID, teamIDs)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"livo-fiber-backend/config"
 	"time"
 
@@ -24,11 +25,7 @@ func GenerateAccessToken(claims TokenClaims, cfg *config.Config) (string, error)
 	token.Set("roles", claims.Roles)
 	token.SetString("type", "access")
 
-	key, err := paseto.V4SymmetricKeyFromBytes([]byte(cfg.PasetoSymmetricKey))
-	if err != nil {
-		return "", err
-	}
-	return token.V4Encrypt(key, nil), nil
+	return encryptToken(token, cfg)
 }
 
 func GenerateRefreshToken(claims TokenClaims, cfg *config.Config) (string, error) {
@@ -41,16 +38,27 @@ func GenerateRefreshToken(claims TokenClaims, cfg *config.Config) (string, error
 	token.SetString("username", claims.Username)
 	token.SetString("type", "refresh")
 
-	key, err := paseto.V4SymmetricKeyFromBytes([]byte(cfg.PasetoSymmetricKey))
+	return encryptToken(token, cfg)
+}
+
+// encryptToken encrypts the token with the current key, naming the key in the footer
+func encryptToken(token paseto.Token, cfg *config.Config) (string, error) {
+	key, err := currentTokenKey(cfg)
+	if err != nil {
+		return "", err
+	}
+	footer, err := json.Marshal(tokenFooter{KeyID: key.ID})
 	if err != nil {
 		return "", err
 	}
+	token.SetFooter(footer)
 
-	return token.V4Encrypt(key, nil), nil
+	return token.V4Encrypt(key.Key, nil), nil
 }
 
 func ValidateToken(tokenString string, cfg *config.Config) (*paseto.Token, error) {
-	key, err := paseto.V4SymmetricKeyFromBytes([]byte(cfg.PasetoSymmetricKey))
+	// Tokens issued before key rotation have no footer and belong to the legacy key
+	key, err := tokenKeyByID(cfg, tokenKeyIDOf(tokenString))
 	if err != nil {
 		return nil, err
 	}
@@ -58,6 +66,6 @@ func ValidateToken(tokenString string, cfg *config.Config) (*paseto.Token, error
 	parser := paseto.NewParser()
 	parser.AddRule(paseto.NotExpired())
 
-	token, err := parser.ParseV4Local(key, tokenString, nil)
+	token, err := parser.ParseV4Local(key.Key, tokenString, nil)
 	return token, err
 }
@@ -0,0 +1,400 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"livo-fiber-backend/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"aidanwoods.dev/go-paseto"
+)
+
+// Token key providers
+const (
+	TokenKeyProviderEnv  = "env"
+	TokenKeyProviderFile = "file"
+)
+
+// LegacyTokenKeyID is the key ID of tokens issued without a key ID in their footer
+const LegacyTokenKeyID = "default"
+
+// tokenKeyReloadInterval throttles re-reading the key ring file when a token names an unknown key,
+// so keys rotated by another instance are picked up without letting forged key IDs hammer the disk
+const tokenKeyReloadInterval = 5 * time.Second
+
+// ErrTokenKeyRotationUnsupported is returned when rotating keys that come from the environment
+var ErrTokenKeyRotationUnsupported = errors.New("token keys from the environment cannot be rotated, use TOKEN_KEY_PROVIDER=file or replace PASETO_SYMMETRIC_KEY and list the old key in PASETO_PREVIOUS_KEYS")
+
+// tokenKey is a symmetric key tokens are encrypted with
+type tokenKey struct {
+	ID        string
+	Key       paseto.V4SymmetricKey
+	CreatedAt time.Time
+	RetireAt  *time.Time // validation stops after this time, nil while the key is in use
+}
+
+// tokenKeyRing holds the current key new tokens are issued with and the previous keys still accepted
+type tokenKeyRing struct {
+	Provider string
+	Current  string
+	Keys     map[string]tokenKey
+	LoadedAt time.Time
+}
+
+// TokenKeyInfo describes a token key without its key material
+type TokenKeyInfo struct {
+	KeyID     string  `json:"keyId"`
+	Current   bool    `json:"current"`
+	CreatedAt string  `json:"createdAt,omitempty"`
+	RetireAt  *string `json:"retireAt,omitempty"`
+}
+
+// tokenKeyFileContent is the JSON layout of the key ring file, keys are hex encoded
+type tokenKeyFileContent struct {
+	Current string             `json:"current"`
+	Keys    []tokenKeyFileItem `json:"keys"`
+}
+
+type tokenKeyFileItem struct {
+	KeyID     string     `json:"kid"`
+	Key       string     `json:"key"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RetireAt  *time.Time `json:"retireAt,omitempty"`
+}
+
+// tokenFooter is the footer of issued tokens, it names the key the token is encrypted with
+type tokenFooter struct {
+	KeyID string `json:"kid"`
+}
+
+var (
+	tokenKeysMu sync.RWMutex
+	tokenKeys   *tokenKeyRing
+)
+
+// InitTokenKeys loads the token keys from the configured provider. The file provider creates the key ring
+// on first use, keeping PASETO_SYMMETRIC_KEY as the current key so issued tokens stay valid.
+func InitTokenKeys(cfg *config.Config) error {
+	ring, err := loadTokenKeys(cfg)
+	if err != nil {
+		return err
+	}
+
+	tokenKeysMu.Lock()
+	defer tokenKeysMu.Unlock()
+	tokenKeys = ring
+	return nil
+}
+
+// loadTokenKeys reads the key ring of the configured provider
+func loadTokenKeys(cfg *config.Config) (*tokenKeyRing, error) {
+	switch cfg.TokenKeyProvider {
+	case TokenKeyProviderEnv, "":
+		return loadEnvTokenKeys(cfg)
+	case TokenKeyProviderFile:
+		return loadFileTokenKeys(cfg)
+	default:
+		return nil, fmt.Errorf("unknown token key provider %q, use %s or %s", cfg.TokenKeyProvider, TokenKeyProviderEnv, TokenKeyProviderFile)
+	}
+}
+
+// loadEnvTokenKeys builds the key ring from PASETO_SYMMETRIC_KEY and PASETO_PREVIOUS_KEYS
+func loadEnvTokenKeys(cfg *config.Config) (*tokenKeyRing, error) {
+	keyID := cfg.PasetoKeyID
+	if keyID == "" {
+		keyID = LegacyTokenKeyID
+	}
+	current, err := paseto.V4SymmetricKeyFromBytes([]byte(cfg.PasetoSymmetricKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PASETO_SYMMETRIC_KEY: %w", err)
+	}
+
+	ring := &tokenKeyRing{
+		Provider: TokenKeyProviderEnv,
+		Current:  keyID,
+		Keys:     map[string]tokenKey{keyID: {ID: keyID, Key: current}},
+		LoadedAt: time.Now(),
+	}
+	for _, pair := range cfg.PasetoPreviousKeys {
+		previousID, secret, ok := strings.Cut(pair, ":")
+		if !ok || previousID == "" {
+			return nil, fmt.Errorf("invalid PASETO_PREVIOUS_KEYS entry, use kid:key")
+		}
+		if previousID == keyID {
+			return nil, fmt.Errorf("PASETO_PREVIOUS_KEYS repeats the current key ID %s", keyID)
+		}
+		key, err := paseto.V4SymmetricKeyFromBytes([]byte(secret))
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous key %s: %w", previousID, err)
+		}
+		ring.Keys[previousID] = tokenKey{ID: previousID, Key: key}
+	}
+	return ring, nil
+}
+
+// loadFileTokenKeys reads the key ring file, creating it when missing. Keys past their retirement are dropped.
+func loadFileTokenKeys(cfg *config.Config) (*tokenKeyRing, error) {
+	data, err := os.ReadFile(cfg.TokenKeyFile)
+	if os.IsNotExist(err) {
+		content, err := newTokenKeyFile(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeTokenKeyFile(cfg.TokenKeyFile, content); err != nil {
+			return nil, err
+		}
+		return tokenKeyRingFromFile(content, time.Now())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token key file %s: %w", cfg.TokenKeyFile, err)
+	}
+
+	var content tokenKeyFileContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("invalid token key file %s: %w", cfg.TokenKeyFile, err)
+	}
+	return tokenKeyRingFromFile(content, time.Now())
+}
+
+// newTokenKeyFile starts a key ring, migrating PASETO_SYMMETRIC_KEY when it is a valid key
+func newTokenKeyFile(cfg *config.Config) (tokenKeyFileContent, error) {
+	now := time.Now().UTC()
+	if len(cfg.PasetoSymmetricKey) == 32 {
+		keyID := cfg.PasetoKeyID
+		if keyID == "" {
+			keyID = LegacyTokenKeyID
+		}
+		return tokenKeyFileContent{
+			Current: keyID,
+			Keys:    []tokenKeyFileItem{{KeyID: keyID, Key: hex.EncodeToString([]byte(cfg.PasetoSymmetricKey)), CreatedAt: now}},
+		}, nil
+	}
+
+	item, err := generateTokenKeyItem(now)
+	if err != nil {
+		return tokenKeyFileContent{}, err
+	}
+	return tokenKeyFileContent{Current: item.KeyID, Keys: []tokenKeyFileItem{item}}, nil
+}
+
+// generateTokenKeyItem creates a random key named after its creation time
+func generateTokenKeyItem(now time.Time) (tokenKeyFileItem, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return tokenKeyFileItem{}, err
+	}
+	return tokenKeyFileItem{
+		KeyID:     "k" + now.Format("20060102150405"),
+		Key:       hex.EncodeToString(secret),
+		CreatedAt: now,
+	}, nil
+}
+
+// tokenKeyRingFromFile decodes the keys of the key ring file that are not retired at now
+func tokenKeyRingFromFile(content tokenKeyFileContent, now time.Time) (*tokenKeyRing, error) {
+	ring := &tokenKeyRing{
+		Provider: TokenKeyProviderFile,
+		Current:  content.Current,
+		Keys:     make(map[string]tokenKey, len(content.Keys)),
+		LoadedAt: now,
+	}
+	for _, item := range content.Keys {
+		if item.RetireAt != nil && !item.RetireAt.After(now) {
+			continue
+		}
+		secret, err := hex.DecodeString(item.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token key %s: %w", item.KeyID, err)
+		}
+		key, err := paseto.V4SymmetricKeyFromBytes(secret)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token key %s: %w", item.KeyID, err)
+		}
+		ring.Keys[item.KeyID] = tokenKey{ID: item.KeyID, Key: key, CreatedAt: item.CreatedAt, RetireAt: item.RetireAt}
+	}
+	if _, ok := ring.Keys[ring.Current]; !ok {
+		return nil, fmt.Errorf("current token key %q is missing from the token key file", ring.Current)
+	}
+	return ring, nil
+}
+
+// writeTokenKeyFile replaces the key ring file atomically, readable by the owner only
+func writeTokenKeyFile(path string, content tokenKeyFileContent) error {
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create token key directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".token_keys-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o600); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// getTokenKeys returns the loaded key ring, loading it on first use for callers that skipped InitTokenKeys
+func getTokenKeys(cfg *config.Config) (*tokenKeyRing, error) {
+	tokenKeysMu.RLock()
+	ring := tokenKeys
+	tokenKeysMu.RUnlock()
+	if ring != nil {
+		return ring, nil
+	}
+	if err := InitTokenKeys(cfg); err != nil {
+		return nil, err
+	}
+	tokenKeysMu.RLock()
+	defer tokenKeysMu.RUnlock()
+	return tokenKeys, nil
+}
+
+// currentTokenKey returns the key new tokens are encrypted with
+func currentTokenKey(cfg *config.Config) (tokenKey, error) {
+	ring, err := getTokenKeys(cfg)
+	if err != nil {
+		return tokenKey{}, err
+	}
+	return ring.Keys[ring.Current], nil
+}
+
+// tokenKeyByID returns the key a token names. Unknown keys of the file provider trigger a throttled reload,
+// another instance may have rotated the key ring.
+func tokenKeyByID(cfg *config.Config, keyID string) (tokenKey, error) {
+	ring, err := getTokenKeys(cfg)
+	if err != nil {
+		return tokenKey{}, err
+	}
+	if key, ok := ring.Keys[keyID]; ok && (key.RetireAt == nil || key.RetireAt.After(time.Now())) {
+		return key, nil
+	}
+
+	if ring.Provider == TokenKeyProviderFile && time.Since(ring.LoadedAt) > tokenKeyReloadInterval {
+		if err := InitTokenKeys(cfg); err != nil {
+			return tokenKey{}, err
+		}
+		ring, _ = getTokenKeys(cfg)
+		if key, ok := ring.Keys[keyID]; ok {
+			return key, nil
+		}
+	}
+	return tokenKey{}, fmt.Errorf("unknown token key %q", keyID)
+}
+
+// tokenKeyIDOf reads the key ID from the unverified footer of a token
+func tokenKeyIDOf(tokenString string) string {
+	footer, err := paseto.NewParser().UnsafeParseFooter(paseto.V4Local, tokenString)
+	if err != nil || len(footer) == 0 {
+		return LegacyTokenKeyID
+	}
+	var parsed tokenFooter
+	if err := json.Unmarshal(footer, &parsed); err != nil || parsed.KeyID == "" {
+		return LegacyTokenKeyID
+	}
+	return parsed.KeyID
+}
+
+// RotateTokenKeys adds a new current key to the key ring file. The previous keys keep validating tokens
+// until the longest token lifetime has passed, then they are removed from the file.
+func RotateTokenKeys(cfg *config.Config) (TokenKeyInfo, error) {
+	if cfg.TokenKeyProvider != TokenKeyProviderFile {
+		return TokenKeyInfo{}, ErrTokenKeyRotationUnsupported
+	}
+
+	// Load the file as stored, another instance may have rotated it since startup
+	if _, err := loadFileTokenKeys(cfg); err != nil {
+		return TokenKeyInfo{}, err
+	}
+	data, err := os.ReadFile(cfg.TokenKeyFile)
+	if err != nil {
+		return TokenKeyInfo{}, err
+	}
+	var content tokenKeyFileContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return TokenKeyInfo{}, err
+	}
+
+	now := time.Now().UTC()
+	retireAt := now.Add(time.Duration(cfg.RefreshTokenTTL)*24*time.Hour + time.Duration(cfg.AccessTokenTTL)*time.Minute)
+	item, err := generateTokenKeyItem(now)
+	if err != nil {
+		return TokenKeyInfo{}, err
+	}
+
+	keys := make([]tokenKeyFileItem, 0, len(content.Keys)+1)
+	for _, existing := range content.Keys {
+		if existing.KeyID == item.KeyID {
+			return TokenKeyInfo{}, fmt.Errorf("token key %s already exists, retry in a second", item.KeyID)
+		}
+		if existing.RetireAt != nil && !existing.RetireAt.After(now) {
+			continue // past its last token, drop it
+		}
+		if existing.RetireAt == nil {
+			existing.RetireAt = &retireAt
+		}
+		keys = append(keys, existing)
+	}
+	content.Current = item.KeyID
+	content.Keys = append(keys, item)
+
+	if err := writeTokenKeyFile(cfg.TokenKeyFile, content); err != nil {
+		return TokenKeyInfo{}, err
+	}
+	if err := InitTokenKeys(cfg); err != nil {
+		return TokenKeyInfo{}, err
+	}
+
+	return TokenKeyInfo{KeyID: item.KeyID, Current: true, CreatedAt: item.CreatedAt.In(time.Local).Format("02-01-2006 15:04:05")}, nil
+}
+
+// GetTokenKeys lists the keys of the key ring, current key first
+func GetTokenKeys(cfg *config.Config) ([]TokenKeyInfo, error) {
+	ring, err := getTokenKeys(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]TokenKeyInfo, 0, len(ring.Keys))
+	for _, key := range ring.Keys {
+		info := TokenKeyInfo{KeyID: key.ID, Current: key.ID == ring.Current}
+		if !key.CreatedAt.IsZero() {
+			info.CreatedAt = key.CreatedAt.In(time.Local).Format("02-01-2006 15:04:05")
+		}
+		if key.RetireAt != nil {
+			formatted := key.RetireAt.In(time.Local).Format("02-01-2006 15:04:05")
+			info.RetireAt = &formatted
+		}
+		if info.Current {
+			infos = append([]TokenKeyInfo{info}, infos...)
+		} else {
+			infos = append(infos, info)
+		}
+	}
+	return infos, nil
+}
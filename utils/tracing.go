@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"context"
+	"livo-fiber-backend/config"
+	"log"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName is the instrumentation name used for spans created by this service
+const TracerName = "livo-fiber-backend"
+
+// InitTracing configures the global OpenTelemetry tracer provider with an OTLP/HTTP exporter.
+// When tracing is disabled the global no-op provider is kept and the returned shutdown does nothing.
+func InitTracing(cfg *config.Config) (func(context.Context) error, error) {
+	// Always propagate incoming trace context so upstream traces are continued
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if !cfg.OtelEnabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OtelExporterEndpoint)}
+	if cfg.OtelExporterInsecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := resource.NewSchemaless(
+		attribute.String("service.name", cfg.OtelServiceName),
+		attribute.String("deployment.environment", cfg.Env),
+	)
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.OtelSampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	log.Printf("✅ OpenTelemetry tracing enabled (exporter: %s, service: %s)", cfg.OtelExporterEndpoint, cfg.OtelServiceName)
+	return provider.Shutdown, nil
+}
+
+// StartSpan starts a child span of the span carried by ctx
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
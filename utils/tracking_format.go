@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"livo-fiber-backend/models"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// trackingLookalikes are characters commonly mistyped for each other when tracking numbers are keyed in by hand
+var trackingLookalikes = map[rune]rune{
+	'O': '0', '0': 'O',
+	'I': '1', '1': 'I',
+	'L': '1',
+	'S': '5', '5': 'S',
+	'B': '8', '8': 'B',
+	'Z': '2', '2': 'Z',
+}
+
+// CompileTrackingFormat compiles a tracking format pattern so it has to match the whole tracking number
+func CompileTrackingFormat(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// trackingExpeditionFormats are the compiled active formats of an expedition
+type trackingExpeditionFormats struct {
+	Expedition models.Expedition
+	Formats    []models.TrackingFormat
+	Patterns   []*regexp.Regexp
+}
+
+// TrackingFormatSet holds the active tracking formats of all expeditions so many tracking numbers can be validated
+// without a query each
+type TrackingFormatSet struct {
+	expeditions []trackingExpeditionFormats // longest expedition code first
+}
+
+// LoadTrackingFormats loads the active tracking formats of all expeditions
+func LoadTrackingFormats(db *gorm.DB) (*TrackingFormatSet, error) {
+	var formats []models.TrackingFormat
+	if err := db.Preload("Expedition").Where("is_active = ?", true).Order("id ASC").Find(&formats).Error; err != nil {
+		return nil, err
+	}
+
+	indexByExpedition := make(map[uint]int)
+	set := &TrackingFormatSet{}
+	for _, format := range formats {
+		if format.Expedition == nil || format.Expedition.ExpeditionCode == "" {
+			continue
+		}
+		pattern, err := CompileTrackingFormat(format.Pattern)
+		if err != nil {
+			// Patterns are checked when saved, an invalid one must not block order entry
+			continue
+		}
+		index, ok := indexByExpedition[format.ExpeditionID]
+		if !ok {
+			index = len(set.expeditions)
+			indexByExpedition[format.ExpeditionID] = index
+			set.expeditions = append(set.expeditions, trackingExpeditionFormats{Expedition: *format.Expedition})
+		}
+		set.expeditions[index].Formats = append(set.expeditions[index].Formats, format)
+		set.expeditions[index].Patterns = append(set.expeditions[index].Patterns, pattern)
+	}
+
+	// The longest code wins when expedition codes share a prefix
+	sort.SliceStable(set.expeditions, func(i, j int) bool {
+		return len(set.expeditions[i].Expedition.ExpeditionCode) > len(set.expeditions[j].Expedition.ExpeditionCode)
+	})
+
+	return set, nil
+}
+
+// Validate checks the tracking number against the formats of the expedition whose code it starts with.
+// Tracking numbers of expeditions without active formats, or not starting with any expedition code, are accepted.
+// The error suggests the corrected tracking number when swapping lookalike characters makes it match.
+func (s *TrackingFormatSet) Validate(trackingNumber string) error {
+	if s == nil || trackingNumber == "" {
+		return nil
+	}
+
+	for _, entry := range s.expeditions {
+		code := entry.Expedition.ExpeditionCode
+		if !strings.HasPrefix(trackingNumber, code) {
+			continue
+		}
+
+		matches := func(candidate string) bool {
+			for _, pattern := range entry.Patterns {
+				if pattern.MatchString(candidate) {
+					return true
+				}
+			}
+			return false
+		}
+		if matches(trackingNumber) {
+			return nil
+		}
+
+		var expected []string
+		for _, format := range entry.Formats {
+			switch {
+			case format.Description != "" && format.Example != "":
+				expected = append(expected, format.Description+", e.g. "+format.Example)
+			case format.Description != "":
+				expected = append(expected, format.Description)
+			case format.Example != "":
+				expected = append(expected, "e.g. "+format.Example)
+			}
+		}
+		message := fmt.Sprintf("Tracking number %s does not match the %s format", trackingNumber, entry.Expedition.ExpeditionName)
+		if len(expected) > 0 {
+			message += " (" + strings.Join(expected, " | ") + ")"
+		}
+		if suggestion := suggestTrackingNumber(trackingNumber, len(code), matches); suggestion != "" {
+			message += ". Did you mean " + suggestion + "?"
+		}
+		return errors.New(message)
+	}
+
+	return nil
+}
+
+// suggestTrackingNumber swaps lookalike characters after the expedition code, first one at a time and then all at once
+// towards digits or letters, and returns the first candidate that matches, empty when none does
+func suggestTrackingNumber(trackingNumber string, codeLength int, matches func(string) bool) string {
+	chars := []rune(trackingNumber)
+
+	for i := codeLength; i < len(chars); i++ {
+		swap, ok := trackingLookalikes[chars[i]]
+		if !ok {
+			continue
+		}
+		candidate := make([]rune, len(chars))
+		copy(candidate, chars)
+		candidate[i] = swap
+		if matches(string(candidate)) {
+			return string(candidate)
+		}
+	}
+
+	for _, toDigits := range []bool{true, false} {
+		candidate := make([]rune, len(chars))
+		copy(candidate, chars)
+		for i := codeLength; i < len(candidate); i++ {
+			swap, ok := trackingLookalikes[candidate[i]]
+			if ok && (swap >= '0' && swap <= '9') == toDigits {
+				candidate[i] = swap
+			}
+		}
+		if string(candidate) != trackingNumber && matches(string(candidate)) {
+			return string(candidate)
+		}
+	}
+
+	return ""
+}
+
+// ValidateTrackingNumber checks a single tracking number against the active formats of its expedition
+func ValidateTrackingNumber(db *gorm.DB, trackingNumber string) error {
+	if trackingNumber == "" {
+		return nil
+	}
+	set, err := LoadTrackingFormats(db)
+	if err != nil {
+		// Format rules are a typo guard, a failed lookup must not block the flow
+		return nil
+	}
+	return set.Validate(trackingNumber)
+}
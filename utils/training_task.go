@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"fmt"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetrainingNotifyRoles are notified when a user is flagged for retraining
+var RetrainingNotifyRoles = []string{"coordinator"}
+
+// RetrainingPolicy holds when users are flagged for retraining from the complaints attributed to them
+type RetrainingPolicy struct {
+	ComplaintThreshold int // a user is flagged with more attributed complaints than this in the window, 0 disables flagging
+	WindowDays         int // rolling window in days
+}
+
+// RetrainingPolicyFromConfig builds the retraining policy from the application config
+func RetrainingPolicyFromConfig(cfg *config.Config) RetrainingPolicy {
+	return RetrainingPolicy{
+		ComplaintThreshold: cfg.RetrainingComplaintThreshold,
+		WindowDays:         cfg.RetrainingWindowDays,
+	}
+}
+
+// FlagRetraining creates a training task for every user attributed to the complain who exceeds the complaint threshold
+// in the rolling window and has no open task, and notifies the coordinators.
+// Complaints before the user's last cleared task do not count again. Returns the created tasks.
+func FlagRetraining(db *gorm.DB, policy RetrainingPolicy, complainID uint) ([]models.TrainingTask, error) {
+	if policy.ComplaintThreshold <= 0 || policy.WindowDays <= 0 {
+		return nil, nil
+	}
+
+	var userIDs []uint
+	if err := db.Model(&models.ComplainUserDetail{}).Where("complain_id = ?", complainID).Distinct("user_id").Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+
+	var tasks []models.TrainingTask
+	windowStart := time.Now().AddDate(0, 0, -policy.WindowDays)
+	for _, userID := range userIDs {
+		var openTasks int64
+		if err := db.Model(&models.TrainingTask{}).Where("user_id = ? AND status = ?", userID, models.TrainingTaskStatusOpen).Count(&openTasks).Error; err != nil {
+			return tasks, err
+		}
+		if openTasks > 0 {
+			continue
+		}
+
+		since := windowStart
+		var lastCleared models.TrainingTask
+		if err := db.Where("user_id = ? AND status = ?", userID, models.TrainingTaskStatusCleared).Order("cleared_at DESC").First(&lastCleared).Error; err == nil &&
+			lastCleared.ClearedAt != nil && lastCleared.ClearedAt.After(since) {
+			since = *lastCleared.ClearedAt
+		}
+
+		var complaints int64
+		if err := db.Table("complain_user_details").
+			Joins("JOIN complains ON complains.id = complain_user_details.complain_id").
+			Where("complain_user_details.user_id = ? AND complains.created_at >= ?", userID, since).
+			Distinct("complain_user_details.complain_id").Count(&complaints).Error; err != nil {
+			return tasks, err
+		}
+		if complaints <= int64(policy.ComplaintThreshold) {
+			continue
+		}
+
+		task := models.TrainingTask{
+			UserID:         userID,
+			Reason:         fmt.Sprintf("%d complaints attributed in the last %d days, more than the allowed %d", complaints, policy.WindowDays, policy.ComplaintThreshold),
+			ComplaintCount: int(complaints),
+			WindowDays:     policy.WindowDays,
+			Status:         models.TrainingTaskStatusOpen,
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+			var user models.User
+			tx.Select("full_name").Where("id = ?", userID).First(&user)
+			message := fmt.Sprintf("%s is flagged for retraining: %s", user.FullName, task.Reason)
+			return NotifyRoles(tx, RetrainingNotifyRoles, "retraining", "User flagged for retraining", message, "training_task", task.ID)
+		})
+		if err != nil {
+			return tasks, err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Activity types of the user activity feed
+const (
+	UserActivityOrderChanged       = "order_changed"
+	UserActivityOrderPicked        = "order_picked"
+	UserActivityOrderCanceled      = "order_canceled"
+	UserActivityOrderHeld          = "order_held"
+	UserActivityPickShortage       = "pick_shortage"
+	UserActivityQCRibbon           = "qc_ribbon"
+	UserActivityQCOnline           = "qc_online"
+	UserActivityOutbound           = "outbound"
+	UserActivityCheckIn            = "attendance_check_in"
+	UserActivityCheckOut           = "attendance_check_out"
+	UserActivityComplainAttributed = "complain_attributed"
+)
+
+// userActivitySources selects the actions of a user per activity type, each with the columns
+// reference_type, reference_id, reference, detail and occurred_at. The user ID is the only parameter.
+var userActivitySources = []struct {
+	Type  string
+	Query string
+}{
+	{UserActivityOrderChanged, `SELECT 'order' AS reference_type, id AS reference_id, tracking_number AS reference, processing_status AS detail, changed_at AS occurred_at
+		FROM orders WHERE changed_by = ? AND changed_at IS NOT NULL`},
+	{UserActivityOrderPicked, `SELECT 'order' AS reference_type, id AS reference_id, tracking_number AS reference, processing_status AS detail, picked_at AS occurred_at
+		FROM orders WHERE picked_by = ? AND picked_at IS NOT NULL`},
+	{UserActivityOrderCanceled, `SELECT 'order' AS reference_type, id AS reference_id, tracking_number AS reference, '' AS detail, canceled_at AS occurred_at
+		FROM orders WHERE canceled_by = ? AND canceled_at IS NOT NULL`},
+	{UserActivityOrderHeld, `SELECT 'order' AS reference_type, id AS reference_id, tracking_number AS reference, hold_reason AS detail, held_at AS occurred_at
+		FROM orders WHERE held_by = ? AND held_at IS NOT NULL`},
+	{UserActivityPickShortage, `SELECT 'pick_shortage' AS reference_type, id AS reference_id, tracking_number AS reference, sku AS detail, created_at AS occurred_at
+		FROM pick_shortages WHERE declared_by = ?`},
+	{UserActivityQCRibbon, `SELECT 'qc_ribbon' AS reference_type, id AS reference_id, tracking_number AS reference, status AS detail, updated_at AS occurred_at
+		FROM qc_ribbons WHERE qc_by = ?`},
+	{UserActivityQCOnline, `SELECT 'qc_online' AS reference_type, id AS reference_id, tracking_number AS reference, status AS detail, updated_at AS occurred_at
+		FROM qc_onlines WHERE qc_by = ?`},
+	{UserActivityOutbound, `SELECT 'outbound' AS reference_type, id AS reference_id, tracking_number AS reference, expedition AS detail, created_at AS occurred_at
+		FROM outbounds WHERE outbound_by = ?`},
+	{UserActivityCheckIn, `SELECT 'attendance' AS reference_type, id AS reference_id, '' AS reference, status AS detail, checked_in AS occurred_at
+		FROM attendances WHERE user_id = ?`},
+	{UserActivityCheckOut, `SELECT 'attendance' AS reference_type, id AS reference_id, '' AS reference, status AS detail, checked_out AS occurred_at
+		FROM attendances WHERE user_id = ? AND checked_out IS NOT NULL`},
+	{UserActivityComplainAttributed, `SELECT 'complain' AS reference_type, complains.id AS reference_id, complains.code AS reference, CAST(complain_user_details.fee_charge AS TEXT) AS detail, complains.created_at AS occurred_at
+		FROM complain_user_details JOIN complains ON complains.id = complain_user_details.complain_id WHERE complain_user_details.user_id = ?`},
+}
+
+// userActivityTexts describe an activity per language, %[1]s is the reference and %[2]s the detail
+var userActivityTexts = map[string]map[string]string{
+	LabelLanguageIndonesian: {
+		UserActivityOrderChanged:       "Mengubah pesanan %[1]s (status %[2]s)",
+		UserActivityOrderPicked:        "Mengambil pesanan %[1]s (status %[2]s)",
+		UserActivityOrderCanceled:      "Membatalkan pesanan %[1]s",
+		UserActivityOrderHeld:          "Menahan pesanan %[1]s: %[2]s",
+		UserActivityPickShortage:       "Melaporkan stok kurang pada pesanan %[1]s (SKU %[2]s)",
+		UserActivityQCRibbon:           "QC Ribbon paket %[1]s (status %[2]s)",
+		UserActivityQCOnline:           "QC Online paket %[1]s (status %[2]s)",
+		UserActivityOutbound:           "Outbound paket %[1]s (ekspedisi %[2]s)",
+		UserActivityCheckIn:            "Absen masuk (status %[2]s)",
+		UserActivityCheckOut:           "Absen pulang (status %[2]s)",
+		UserActivityComplainAttributed: "Dibebankan komplain %[1]s (biaya %[2]s)",
+	},
+	LabelLanguageEnglish: {
+		UserActivityOrderChanged:       "Changed order %[1]s (status %[2]s)",
+		UserActivityOrderPicked:        "Picked order %[1]s (status %[2]s)",
+		UserActivityOrderCanceled:      "Canceled order %[1]s",
+		UserActivityOrderHeld:          "Held order %[1]s: %[2]s",
+		UserActivityPickShortage:       "Declared a stock shortage on order %[1]s (SKU %[2]s)",
+		UserActivityQCRibbon:           "QC Ribbon of parcel %[1]s (status %[2]s)",
+		UserActivityQCOnline:           "QC Online of parcel %[1]s (status %[2]s)",
+		UserActivityOutbound:           "Outbound of parcel %[1]s (expedition %[2]s)",
+		UserActivityCheckIn:            "Checked in (status %[2]s)",
+		UserActivityCheckOut:           "Checked out (status %[2]s)",
+		UserActivityComplainAttributed: "Attributed complain %[1]s (fee %[2]s)",
+	},
+}
+
+// UserActivityTypes returns the activity types of the user activity feed
+func UserActivityTypes() []string {
+	types := make([]string, len(userActivitySources))
+	for i, source := range userActivitySources {
+		types[i] = source.Type
+	}
+	return types
+}
+
+// IsUserActivityType reports whether activityType is a known activity type
+func IsUserActivityType(activityType string) bool {
+	for _, source := range userActivitySources {
+		if source.Type == activityType {
+			return true
+		}
+	}
+	return false
+}
+
+// UserActivity is an action of a user in one of the modules
+type UserActivity struct {
+	Type          string    `json:"type"`
+	ReferenceType string    `json:"referenceType"`
+	ReferenceID   uint      `json:"referenceId"`
+	Reference     string    `json:"reference"` // tracking number or complain code
+	Detail        string    `json:"detail"`
+	OccurredAt    time.Time `json:"-"`
+	Description   string    `json:"description"`
+	Time          string    `json:"occurredAt"`
+}
+
+// UserActivityFilter narrows the user activity feed
+type UserActivityFilter struct {
+	UserID uint
+	Types  []string   // all types when empty
+	Start  *time.Time // inclusive
+	End    *time.Time // exclusive
+	Limit  int
+	Offset int
+}
+
+// ListUserActivity returns the actions of a user across modules, latest first, described in the language,
+// and the total number of actions matching the filter
+func ListUserActivity(db *gorm.DB, filter UserActivityFilter, language string) ([]UserActivity, int64, error) {
+	var queries []string
+	var args []interface{}
+	for _, source := range userActivitySources {
+		if len(filter.Types) > 0 && !slices.Contains(filter.Types, source.Type) {
+			continue
+		}
+		queries = append(queries, fmt.Sprintf("SELECT '%s' AS type, source.* FROM (%s) source", source.Type, source.Query))
+		args = append(args, filter.UserID)
+	}
+	if len(queries) == 0 {
+		return []UserActivity{}, 0, nil
+	}
+
+	feed := "(" + strings.Join(queries, " UNION ALL ") + ") activity"
+	var conditions []string
+	if filter.Start != nil {
+		conditions = append(conditions, "activity.occurred_at >= ?")
+		args = append(args, *filter.Start)
+	}
+	if filter.End != nil {
+		conditions = append(conditions, "activity.occurred_at < ?")
+		args = append(args, *filter.End)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := db.Raw("SELECT COUNT(*) FROM "+feed+where, args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var activities []UserActivity
+	pageArgs := append(append([]interface{}{}, args...), filter.Limit, filter.Offset)
+	if err := db.Raw("SELECT activity.type, activity.reference_type, activity.reference_id, COALESCE(activity.reference, '') AS reference, COALESCE(activity.detail, '') AS detail, activity.occurred_at FROM "+
+		feed+where+" ORDER BY activity.occurred_at DESC, activity.type ASC, activity.reference_id DESC LIMIT ? OFFSET ?", pageArgs...).Scan(&activities).Error; err != nil {
+		return nil, 0, err
+	}
+
+	texts, ok := userActivityTexts[language]
+	if !ok {
+		texts = userActivityTexts[LabelLanguageIndonesian]
+	}
+	for i := range activities {
+		activities[i].Description = fmt.Sprintf(texts[activities[i].Type], activities[i].Reference, activities[i].Detail)
+		activities[i].Time = activities[i].OccurredAt.Format("02-01-2006 15:04:05")
+	}
+
+	return activities, total, nil
+}
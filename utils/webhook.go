@@ -0,0 +1,192 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/gorm"
+)
+
+// Errors of the inbound webhook checks
+var (
+	ErrWebhookUnsigned         = errors.New("webhook signature is required")
+	ErrWebhookInvalidSignature = errors.New("webhook signature does not match the payload")
+	ErrWebhookTimestamp        = errors.New("webhook timestamp is missing or invalid")
+	ErrWebhookExpired          = errors.New("webhook timestamp is outside the accepted tolerance")
+	ErrWebhookReplayed         = errors.New("webhook was already received")
+)
+
+// webhookParamsLocal holds the route parameters of a webhook replayed outside of its own route
+const webhookParamsLocal = "webhookParams"
+
+// WebhookPolicy holds how inbound webhooks are authenticated
+type WebhookPolicy struct {
+	Secrets          map[string]string // HMAC secret per source
+	RequireSignature bool              // sources without a secret are rejected instead of accepted unsigned
+	Tolerance        time.Duration     // allowed difference between the webhook timestamp and the server clock
+}
+
+// WebhookPolicyFromConfig builds the webhook policy from the application config
+func WebhookPolicyFromConfig(cfg *config.Config) WebhookPolicy {
+	return WebhookPolicy{
+		Secrets:          cfg.WebhookSecrets,
+		RequireSignature: cfg.WebhookRequireSignature,
+		Tolerance:        time.Duration(cfg.WebhookTimestampToleranceSeconds) * time.Second,
+	}
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "<timestamp>.<payload>", the signature senders put in the
+// signature header
+func SignWebhookPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NormalizeWebhookSignature returns the lowercase hex digest of a signature header. Senders may prefix the digest
+// with the algorithm, as in "sha256=<hex>", both spellings are the same signature.
+func NormalizeWebhookSignature(signature string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(signature), "sha256="))
+}
+
+// VerifyWebhook checks the signature and timestamp of a webhook of the source. It returns the signature status
+// recorded with the event, unsigned when the source has no secret and signatures are not required.
+func (p WebhookPolicy) VerifyWebhook(source, signature, timestamp string, payload []byte, now time.Time) (string, error) {
+	secret := p.Secrets[strings.ToLower(source)]
+	if secret == "" {
+		if p.RequireSignature {
+			return models.WebhookSignatureInvalid, ErrWebhookUnsigned
+		}
+		return models.WebhookSignatureUnsigned, nil
+	}
+
+	if signature == "" {
+		return models.WebhookSignatureInvalid, ErrWebhookUnsigned
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(timestamp), 10, 64)
+	if err != nil {
+		return models.WebhookSignatureInvalid, ErrWebhookTimestamp
+	}
+	sentAt := time.Unix(seconds, 0)
+	if sentAt.Before(now.Add(-p.Tolerance)) || sentAt.After(now.Add(p.Tolerance)) {
+		return models.WebhookSignatureInvalid, ErrWebhookExpired
+	}
+
+	expected := SignWebhookPayload(secret, strings.TrimSpace(timestamp), payload)
+	if !hmac.Equal([]byte(NormalizeWebhookSignature(signature)), []byte(expected)) {
+		return models.WebhookSignatureInvalid, ErrWebhookInvalidSignature
+	}
+	return models.WebhookSignatureVerified, nil
+}
+
+var (
+	webhookNonceMu sync.Mutex
+	webhookNonces  = make(map[string]time.Time) // source:replay key -> expiry
+)
+
+// ClaimWebhookReplayKeys records the replay keys of a webhook (its signature and nonce), returning false without
+// recording any of them when one of the keys of the source was already seen within the ttl. Expired keys are
+// dropped as new ones are claimed.
+func ClaimWebhookReplayKeys(source string, replayKeys []string, ttl time.Duration, now time.Time) bool {
+	webhookNonceMu.Lock()
+	defer webhookNonceMu.Unlock()
+
+	for key, expiresAt := range webhookNonces {
+		if now.After(expiresAt) {
+			delete(webhookNonces, key)
+		}
+	}
+
+	for _, replayKey := range replayKeys {
+		if expiresAt, ok := webhookNonces[source+":"+replayKey]; ok && now.Before(expiresAt) {
+			return false
+		}
+	}
+	for _, replayKey := range replayKeys {
+		webhookNonces[source+":"+replayKey] = now.Add(ttl)
+	}
+	return true
+}
+
+// WebhookParam returns a route parameter of a webhook, taken from the logged event when the webhook is reprocessed
+func WebhookParam(c fiber.Ctx, name string) string {
+	if params, ok := c.Locals(webhookParamsLocal).(map[string]string); ok {
+		return params[name]
+	}
+	return c.Params(name)
+}
+
+// SetWebhookReplayParams makes the route parameters of a logged webhook available to its handler on reprocess
+func SetWebhookReplayParams(c fiber.Ctx, params map[string]string) {
+	c.Locals(webhookParamsLocal, params)
+}
+
+// webhookResponseLogLimit is how much of the handler response is kept with a logged webhook
+const webhookResponseLogLimit = 4096
+
+// WebhookOutcome returns the response status, the truncated response body and the event status of a handled
+// webhook
+func WebhookOutcome(c fiber.Ctx, err error) (int, string, string) {
+	status, body := handlerResponse(c, err, webhookResponseLogLimit)
+
+	eventStatus := models.WebhookEventFailed
+	if status >= 200 && status < 300 {
+		eventStatus = models.WebhookEventProcessed
+	}
+	return status, body, eventStatus
+}
+
+// handlerResponse returns the status and the body truncated to limit bytes of the response a handler gave. Errors
+// returned by the handler are not written to the response yet, their code is used instead.
+func handlerResponse(c fiber.Ctx, err error, limit int) (int, string) {
+	status := c.Response().StatusCode()
+	body := string(c.Response().Body())
+	if fiberErr, ok := err.(*fiber.Error); ok {
+		status = fiberErr.Code
+		body = fiberErr.Message
+	} else if err != nil {
+		status = fiber.StatusInternalServerError
+		body = err.Error()
+	}
+	if len(body) > limit {
+		// Cut multi-byte characters are dropped, Postgres rejects invalid UTF-8
+		body = strings.ToValidUTF8(body[:limit], "")
+	}
+	return status, body
+}
+
+// StartWebhookEventPurgeScheduler deletes logged webhooks older than the retention period once a day
+func StartWebhookEventPurgeScheduler(db *gorm.DB, retentionDays int) {
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if GetMaintenance().Enabled {
+				log.Println("StartWebhookEventPurgeScheduler - Skipping purge during maintenance mode")
+				continue
+			}
+
+			result := db.Where("created_at < ?", time.Now().AddDate(0, 0, -retentionDays)).Delete(&models.InboundWebhookEvent{})
+			if result.Error != nil {
+				log.Println("StartWebhookEventPurgeScheduler - Purge failed:", result.Error)
+				continue
+			}
+			if result.RowsAffected > 0 {
+				log.Printf("StartWebhookEventPurgeScheduler - %d webhook events purged\n", result.RowsAffected)
+			}
+		}
+	}()
+}
@@ -0,0 +1,324 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"livo-fiber-backend/config"
+	"livo-fiber-backend/models"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Headers outbound webhooks are sent with, signed like the inbound webhooks are verified
+const (
+	webhookDeliverySignatureHeader = "X-Webhook-Signature" // hex HMAC-SHA256 of "<timestamp>.<raw body>" with the subscription secret
+	webhookDeliveryTimestampHeader = "X-Webhook-Timestamp"
+	webhookDeliveryEventHeader     = "X-Webhook-Event"
+	webhookDeliveryIDHeader        = "X-Webhook-Delivery" // cursor of the event, the same on every retry
+)
+
+// webhookDeliveryBatchSize bounds the change events read per subscription per run of the worker
+const webhookDeliveryBatchSize = 100
+
+// Delay before a failed delivery is retried, doubled on every failed attempt
+const (
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = time.Hour
+)
+
+// webhookDeliveryResponseLimit is how much of the subscriber response is kept
+const webhookDeliveryResponseLimit = 512
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// OrderEvent is the payload delivered to webhook subscriptions
+type OrderEvent struct {
+	Event      string          `json:"event" example:"qc_completed"` // order_created, order_updated, order_deleted or the processing status the order moved to
+	Cursor     uint64          `json:"cursor"`                       // change feed cursor, unique per event
+	OrderID    uint            `json:"orderId"`
+	OccurredAt string          `json:"occurredAt"` // RFC 3339 with the server offset
+	Test       bool            `json:"test,omitempty"`
+	Data       json.RawMessage `json:"data"` // order fields of the change feed schema, only the id for deleted orders
+}
+
+// WebhookDeliveryResult describes how a subscriber answered a delivery
+type WebhookDeliveryResult struct {
+	StatusCode int    `json:"statusCode"`
+	DurationMs int64  `json:"durationMs"`
+	Response   string `json:"response,omitempty"` // truncated response body
+}
+
+// orderEventFields are the change feed fields order events are classified and filtered by
+type orderEventFields struct {
+	ProcessingStatus string `json:"processing_status"`
+	Channel          string `json:"channel"`
+	Store            string `json:"store"`
+	CreatedAt        string `json:"created_at"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+// classifiedOrderEvent is an order change event with its event type, skipped events are not delivered
+type classifiedOrderEvent struct {
+	EventType string
+	Fields    orderEventFields
+	Skip      bool
+}
+
+// classifyOrderEvent returns the event type of an order change event. An upsert is the processing status the order
+// moved to when it differs from the previous event of the order, order_created for the insert and order_updated
+// otherwise. Replay snapshots are skipped, they only reload the warehouse.
+func classifyOrderEvent(db *gorm.DB, event *models.ChangeEvent) (*classifiedOrderEvent, error) {
+	switch event.Operation {
+	case models.ChangeEventDelete:
+		return &classifiedOrderEvent{EventType: models.OrderEventDeleted}, nil
+	case models.ChangeEventSnapshot:
+		return &classifiedOrderEvent{Skip: true}, nil
+	}
+
+	classified := &classifiedOrderEvent{}
+	if err := json.Unmarshal([]byte(event.Payload), &classified.Fields); err != nil {
+		return nil, fmt.Errorf("invalid payload of change event %d: %w", event.ID, err)
+	}
+
+	var previous models.ChangeEvent
+	err := db.Where("entity = ? AND entity_id = ? AND id < ? AND operation <> ?", event.Entity, event.EntityID, event.ID, models.ChangeEventDelete).
+		Order("id DESC").First(&previous).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Earlier events may have been purged, only an order never updated is new
+		if classified.Fields.CreatedAt == classified.Fields.UpdatedAt {
+			classified.EventType = models.OrderEventCreated
+		} else {
+			classified.EventType = classified.Fields.ProcessingStatus
+		}
+		return classified, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var previousFields orderEventFields
+	_ = json.Unmarshal([]byte(previous.Payload), &previousFields)
+	classified.EventType = models.OrderEventUpdated
+	if previousFields.ProcessingStatus != classified.Fields.ProcessingStatus {
+		classified.EventType = classified.Fields.ProcessingStatus
+	}
+	return classified, nil
+}
+
+// DeliverOrderEvent posts an order event to the URL of a subscription, signed with its secret.
+// Answers outside 2xx are returned as errors together with the result.
+func DeliverOrderEvent(ctx context.Context, subscription *models.WebhookSubscription, event OrderEvent) (WebhookDeliveryResult, error) {
+	var result WebhookDeliveryResult
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return result, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, "POST", subscription.URL, bytes.NewReader(payload))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhookDeliveryTimestampHeader, timestamp)
+	req.Header.Set(webhookDeliverySignatureHeader, "sha256="+SignWebhookPayload(subscription.Secret, timestamp, payload))
+	req.Header.Set(webhookDeliveryEventHeader, event.Event)
+	req.Header.Set(webhookDeliveryIDHeader, strconv.FormatUint(event.Cursor, 10))
+
+	start := time.Now()
+	resp, err := webhookClient.Do(req)
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		return result, fmt.Errorf("failed to reach %s: %w", subscription.URL, err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookDeliveryResponseLimit))
+	result.StatusCode = resp.StatusCode
+	result.Response = strings.ToValidUTF8(string(body), "")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("subscriber returned %d: %s", resp.StatusCode, result.Response)
+	}
+	return result, nil
+}
+
+// SampleOrderEvent returns a test event of the subscription, an order with placeholder values for every field of
+// the change feed schema in the first channel, store and status of its filters
+func SampleOrderEvent(subscription *models.WebhookSubscription) OrderEvent {
+	now := time.Now()
+	data := map[string]interface{}{}
+	if entity, ok := FindChangeFeedEntity("orders"); ok {
+		for _, field := range entity.Fields {
+			switch {
+			case field.Nullable:
+				data[field.Name] = nil
+			case field.Type == "integer" || field.Type == "number":
+				data[field.Name] = 0
+			case field.Type == "boolean":
+				data[field.Name] = false
+			case field.Type == "timestamp":
+				data[field.Name] = now.Format(time.RFC3339)
+			default:
+				data[field.Name] = ""
+			}
+		}
+	}
+
+	first := func(values []string, fallback string) string {
+		if len(values) > 0 {
+			return values[0]
+		}
+		return fallback
+	}
+	status := first(subscription.StatusList(), models.ProcessingStatusQCCompleted)
+	data["order_ginee_id"] = "TEST-ORDER"
+	data["tracking_number"] = "TEST-TRACKING"
+	data["channel"] = first(subscription.ChannelList(), "Sample Channel")
+	data["store"] = first(subscription.StoreList(), "Sample Store")
+	data["processing_status"] = status
+	data["event_status"] = models.EventStatusInProgress
+	encoded, _ := json.Marshal(data)
+
+	return OrderEvent{
+		Event:      first(subscription.EventTypeList(), status),
+		OccurredAt: now.Format(time.RFC3339Nano),
+		Test:       true,
+		Data:       encoded,
+	}
+}
+
+// webhookRetryDelay returns the delay before the attempt following the given number of failed attempts
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 1; i < attempts && delay < webhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMaxDelay)
+}
+
+// deliverSubscriptionEvents delivers the settled order events after the cursor of a subscription in cursor order,
+// stopping at the first failed delivery so it is retried first. Events outside the filters only move the cursor.
+// Classifications are shared between the subscriptions of a run. Returns the number of events delivered.
+func deliverSubscriptionEvents(db *gorm.DB, settleSeconds int, subscription *models.WebhookSubscription, classified map[uint64]*classifiedOrderEvent) (int, error) {
+	var events []models.ChangeEvent
+	if err := db.Where("entity = ? AND id > ?", "orders", subscription.Cursor).
+		Where("changed_at < now() - make_interval(secs => ?)", settleSeconds).
+		Order("id ASC").Limit(webhookDeliveryBatchSize).Find(&events).Error; err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	cursor := subscription.Cursor
+	delivered := 0
+	var deliveryErr error
+	for i := range events {
+		event := &events[i]
+		orderEvent, ok := classified[event.ID]
+		if !ok {
+			var err error
+			if orderEvent, err = classifyOrderEvent(db, event); err != nil {
+				deliveryErr = err
+				break
+			}
+			classified[event.ID] = orderEvent
+		}
+
+		if !orderEvent.Skip && subscription.Matches(orderEvent.EventType, orderEvent.Fields.Channel, orderEvent.Fields.Store, orderEvent.Fields.ProcessingStatus) {
+			ctx, cancel := context.WithTimeout(context.Background(), webhookClient.Timeout)
+			_, err := DeliverOrderEvent(ctx, subscription, OrderEvent{
+				Event:      orderEvent.EventType,
+				Cursor:     event.ID,
+				OrderID:    event.EntityID,
+				OccurredAt: event.ChangedAt.Format(time.RFC3339Nano),
+				Data:       json.RawMessage(event.Payload),
+			})
+			cancel()
+			if err != nil {
+				deliveryErr = err
+				break
+			}
+			delivered++
+		}
+		cursor = event.ID
+	}
+
+	// Record the progress, only when no other instance moved the cursor meanwhile
+	now := time.Now()
+	updates := map[string]interface{}{"cursor": cursor}
+	if deliveryErr != nil {
+		attempts := subscription.FailedAttempts + 1
+		updates["failed_attempts"] = attempts
+		updates["next_attempt_at"] = now.Add(webhookRetryDelay(attempts))
+		updates["last_delivery_at"] = now
+		updates["last_status"] = models.WebhookDeliveryFailed
+		updates["last_error"] = deliveryErr.Error()
+	} else {
+		updates["failed_attempts"] = 0
+		updates["next_attempt_at"] = nil
+		if delivered > 0 {
+			updates["last_delivery_at"] = now
+			updates["last_status"] = models.WebhookDeliverySent
+			updates["last_error"] = ""
+		}
+	}
+	if err := db.Model(&models.WebhookSubscription{}).Where("id = ? AND cursor = ?", subscription.ID, subscription.Cursor).Updates(updates).Error; err != nil {
+		log.Println("deliverSubscriptionEvents - Failed to record delivery of webhook subscription", subscription.ID, ":", err)
+	}
+	return delivered, deliveryErr
+}
+
+// RunWebhookDeliveries delivers the pending order events of every active subscription whose retry is due and
+// returns the number of events delivered. Deliveries are at least once, subscribers dedupe by cursor.
+func RunWebhookDeliveries(cfg *config.Config, db *gorm.DB) (int, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := db.Where("active = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", true, time.Now()).
+		Order("id ASC").Find(&subscriptions).Error; err != nil {
+		return 0, err
+	}
+
+	classified := make(map[uint64]*classifiedOrderEvent)
+	delivered := 0
+	for i := range subscriptions {
+		sent, err := deliverSubscriptionEvents(db, cfg.ChangeFeedSettleSeconds, &subscriptions[i], classified)
+		delivered += sent
+		if err != nil {
+			log.Println("RunWebhookDeliveries - Delivery failed for webhook subscription", subscriptions[i].ID, ":", err)
+		}
+	}
+	return delivered, nil
+}
+
+// StartWebhookDeliveryWorker delivers the order events of the webhook subscriptions periodically in the background
+func StartWebhookDeliveryWorker(cfg *config.Config, db *gorm.DB, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if GetMaintenance().Enabled {
+				log.Println("StartWebhookDeliveryWorker - Skipping webhook delivery during maintenance mode")
+				continue
+			}
+
+			delivered, err := RunWebhookDeliveries(cfg, db)
+			if err != nil {
+				log.Println("StartWebhookDeliveryWorker - Webhook delivery failed:", err)
+				continue
+			}
+			if delivered > 0 {
+				log.Printf("StartWebhookDeliveryWorker - %d order events delivered\n", delivered)
+			}
+		}
+	}()
+}
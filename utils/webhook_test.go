@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"errors"
+	"livo-fiber-backend/models"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	payload := []byte(`{"orderGineeId":"2508150001","externalStatus":"CANCELLED"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := SignWebhookPayload("ginee-secret", timestamp, payload)
+
+	policy := WebhookPolicy{
+		Secrets:          map[string]string{"ginee": "ginee-secret"},
+		RequireSignature: true,
+		Tolerance:        300 * time.Second,
+	}
+
+	tests := []struct {
+		name       string
+		policy     WebhookPolicy
+		source     string
+		signature  string
+		timestamp  string
+		payload    []byte
+		wantStatus string
+		wantErr    error
+	}{
+		{"valid signature", policy, "ginee", signature, timestamp, payload, models.WebhookSignatureVerified, nil},
+		{"source is case insensitive", policy, "Ginee", signature, timestamp, payload, models.WebhookSignatureVerified, nil},
+		{"algorithm prefix and uppercase hex", policy, "ginee", "sha256=" + strings.ToUpper(signature), timestamp, payload, models.WebhookSignatureVerified, nil},
+		{"tampered payload", policy, "ginee", signature, timestamp, []byte(`{"orderGineeId":"2508150002"}`), models.WebhookSignatureInvalid, ErrWebhookInvalidSignature},
+		{"timestamp not signed", policy, "ginee", signature, strconv.FormatInt(now.Unix()+1, 10), payload, models.WebhookSignatureInvalid, ErrWebhookInvalidSignature},
+		{"missing signature", policy, "ginee", "", timestamp, payload, models.WebhookSignatureInvalid, ErrWebhookUnsigned},
+		{"missing timestamp", policy, "ginee", signature, "", payload, models.WebhookSignatureInvalid, ErrWebhookTimestamp},
+		{"timestamp too old", policy, "ginee", signature, strconv.FormatInt(now.Unix()-301, 10), payload, models.WebhookSignatureInvalid, ErrWebhookExpired},
+		{"timestamp too far ahead", policy, "ginee", signature, strconv.FormatInt(now.Unix()+301, 10), payload, models.WebhookSignatureInvalid, ErrWebhookExpired},
+		{"source without secret is rejected", policy, "3pl", "", timestamp, payload, models.WebhookSignatureInvalid, ErrWebhookUnsigned},
+		{"source without secret accepted when signatures are optional", WebhookPolicy{Tolerance: 300 * time.Second}, "3pl", "", timestamp, payload, models.WebhookSignatureUnsigned, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := tt.policy.VerifyWebhook(tt.source, tt.signature, tt.timestamp, tt.payload, now)
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeWebhookSignature(t *testing.T) {
+	for _, signature := range []string{"abc123", "ABC123", "sha256=abc123", " sha256=ABC123 "} {
+		if got := NormalizeWebhookSignature(signature); got != "abc123" {
+			t.Errorf("NormalizeWebhookSignature(%q) = %q, want abc123", signature, got)
+		}
+	}
+}
+
+func TestClaimWebhookReplayKeys(t *testing.T) {
+	now := time.Now()
+	ttl := 10 * time.Minute
+	source := "test-claim-" + strconv.FormatInt(now.UnixNano(), 10)
+
+	if !ClaimWebhookReplayKeys(source, []string{"signature:s1", "nonce:n1"}, ttl, now) {
+		t.Fatal("first webhook was rejected")
+	}
+
+	tests := []struct {
+		name string
+		keys []string
+		want bool
+	}{
+		{"same signature with a new nonce is a replay", []string{"signature:s1", "nonce:n2"}, false},
+		{"same nonce with a new signature is a retry of the same webhook", []string{"signature:s2", "nonce:n1"}, false},
+		{"new signature and nonce are accepted", []string{"signature:s3", "nonce:n3"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClaimWebhookReplayKeys(source, tt.keys, ttl, now); got != tt.want {
+				t.Errorf("ClaimWebhookReplayKeys(%v) = %v, want %v", tt.keys, got, tt.want)
+			}
+		})
+	}
+
+	// A rejected webhook claims none of its keys
+	if !ClaimWebhookReplayKeys(source, []string{"nonce:n2"}, ttl, now) {
+		t.Error("nonce of a rejected replay was claimed")
+	}
+
+	// Keys are forgotten once the ttl passed, and are kept per source
+	if !ClaimWebhookReplayKeys(source, []string{"signature:s1"}, ttl, now.Add(ttl+time.Second)) {
+		t.Error("expired signature was still rejected")
+	}
+	if !ClaimWebhookReplayKeys(source+"-other", []string{"signature:s3"}, ttl, now) {
+		t.Error("signature of another source was rejected")
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"livo-fiber-backend/config"
+	"log"
+	"net/http"
+	"time"
+)
+
+var whatsAppClient = &http.Client{Timeout: 15 * time.Second}
+
+// SendWhatsAppMessage sends a text message through the configured WhatsApp gateway.
+// The gateway receives {"phone", "message"} as JSON with the token as a Bearer credential.
+// When no gateway is configured the message is logged instead.
+func SendWhatsAppMessage(ctx context.Context, cfg *config.Config, phone, message string) error {
+	if cfg.WhatsAppGatewayURL == "" {
+		log.Printf("SendWhatsAppMessage - gateway not configured, message to %s not sent:\n%s", phone, message)
+		return nil
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"phone":   phone,
+		"message": message,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.WhatsAppGatewayURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.WhatsAppGatewayToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.WhatsAppGatewayToken)
+	}
+
+	resp, err := whatsAppClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach whatsapp gateway: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("whatsapp gateway returned %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
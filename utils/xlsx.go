@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// XLSXContentType is the MIME type of XLSX exports
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// BuildXLSX builds a single sheet XLSX workbook with a bold header row followed by the given rows
+func BuildXLSX(sheetName string, headers []string, rows [][]interface{}) (*bytes.Buffer, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	// Rename the default sheet
+	if err := file.SetSheetName("Sheet1", sheetName); err != nil {
+		return nil, err
+	}
+
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+
+	// Write header row
+	for col, header := range headers {
+		cell, err := excelize.CoordinatesToCellName(col+1, 1)
+		if err != nil {
+			return nil, err
+		}
+		if err := file.SetCellValue(sheetName, cell, header); err != nil {
+			return nil, err
+		}
+	}
+	if len(headers) > 0 {
+		lastHeader, _ := excelize.CoordinatesToCellName(len(headers), 1)
+		if err := file.SetCellStyle(sheetName, "A1", lastHeader, headerStyle); err != nil {
+			return nil, err
+		}
+	}
+
+	// Write data rows
+	for rowIdx, row := range rows {
+		for col, value := range row {
+			cell, err := excelize.CoordinatesToCellName(col+1, rowIdx+2)
+			if err != nil {
+				return nil, err
+			}
+			if err := file.SetCellValue(sheetName, cell, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return file.WriteToBuffer()
+}
+
+// ReadSpreadsheetRows reads all rows of an uploaded CSV file or of the first sheet of an XLSX workbook,
+// the format is taken from the file extension
+func ReadSpreadsheetRows(filename string, reader io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		csvReader := csv.NewReader(reader)
+		csvReader.FieldsPerRecord = -1
+		csvReader.TrimLeadingSpace = true
+		return csvReader.ReadAll()
+
+	case ".xlsx":
+		file, err := excelize.OpenReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		sheets := file.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, errors.New("workbook has no sheets")
+		}
+		return file.GetRows(sheets[0])
+
+	default:
+		return nil, errors.New("unsupported file type, upload a .csv or .xlsx file")
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ZIPContentType is the MIME type of ZIP exports
+const ZIPContentType = "application/zip"
+
+// ZIPEntry is a single file written into a ZIP archive
+type ZIPEntry struct {
+	Name string
+	Data []byte
+}
+
+// BuildZIP builds a ZIP archive containing the given entries in order
+func BuildZIP(entries []ZIPEntry) (*bytes.Buffer, error) {
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+
+	now := time.Now()
+	for _, entry := range entries {
+		file, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     entry.Name,
+			Method:   zip.Deflate,
+			Modified: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write(entry.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer, nil
+}
+
+// DecodeImageDataURL decodes a base64 image data URL (data:image/png;base64,...) as captured by the web clients.
+// Returns the image bytes and the file extension matching its MIME type.
+func DecodeImageDataURL(value string) ([]byte, string, error) {
+	header, payload, found := strings.Cut(value, ",")
+	if !found || !strings.HasPrefix(header, "data:image/") || !strings.HasSuffix(header, ";base64") {
+		return nil, "", errors.New("not a base64 image data URL")
+	}
+
+	extension := strings.TrimSuffix(strings.TrimPrefix(header, "data:image/"), ";base64")
+	switch extension {
+	case "jpeg", "jpg":
+		extension = "jpg"
+	case "png", "gif", "webp":
+	default:
+		return nil, "", errors.New("unsupported image type " + extension)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, extension, nil
+}
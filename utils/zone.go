@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"livo-fiber-backend/models"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ZonePick is a picked quantity of a SKU for an order, recorded as a zone visit
+type ZonePick struct {
+	OrderID  uint
+	SKU      string
+	Quantity int
+}
+
+// ZoneForLocation returns the zone owning a rack location, nil when the location is in no zone.
+// Prefixes match case-insensitively and the longest matching prefix wins, so "A-01" can be carved out of "A-".
+func ZoneForLocation(zones []models.Zone, location string) *models.Zone {
+	location = strings.ToUpper(strings.TrimSpace(location))
+	if location == "" {
+		return nil
+	}
+
+	var match *models.Zone
+	longest := 0
+	for i := range zones {
+		for _, prefix := range zones[i].PrefixList() {
+			if len(prefix) > longest && strings.HasPrefix(location, strings.ToUpper(prefix)) {
+				match = &zones[i]
+				longest = len(prefix)
+			}
+		}
+	}
+	return match
+}
+
+// RecordPickZoneVisits records the zone of every pick, resolved from the rack location of the picked SKU
+func RecordPickZoneVisits(db *gorm.DB, pickedBy uint, pickBatchID *uint, picks []ZonePick) error {
+	if len(picks) == 0 {
+		return nil
+	}
+
+	var zones []models.Zone
+	if err := db.Find(&zones).Error; err != nil {
+		return err
+	}
+
+	skus := make([]string, len(picks))
+	for i, pick := range picks {
+		skus[i] = strings.ToUpper(pick.SKU)
+	}
+	var products []models.Product
+	if err := db.Where("UPPER(sku) IN ?", skus).Find(&products).Error; err != nil {
+		return err
+	}
+	locations := make(map[string]string, len(products))
+	for _, product := range products {
+		locations[strings.ToUpper(product.SKU)] = product.Location
+	}
+
+	visits := make([]models.PickZoneVisit, len(picks))
+	for i, pick := range picks {
+		location := locations[strings.ToUpper(pick.SKU)]
+		visits[i] = models.PickZoneVisit{
+			OrderID:     pick.OrderID,
+			PickBatchID: pickBatchID,
+			PickedBy:    pickedBy,
+			SKU:         pick.SKU,
+			Location:    location,
+			Quantity:    pick.Quantity,
+		}
+		if zone := ZoneForLocation(zones, location); zone != nil {
+			visits[i].ZoneID = &zone.ID
+		}
+	}
+	return db.Create(&visits).Error
+}